@@ -6,6 +6,7 @@
   const submitButton = d.getElementById('confirm-flagship-submit')
   const codeInput = d.getElementById('code-input')
   const tokenInput = d.getElementById('confirm-token')
+  const csrfTokenInput = form.querySelector('input[name="csrf_token"]')
 
   const onSubmitCode = function (event) {
     event.preventDefault()
@@ -15,6 +16,7 @@
     const data = new URLSearchParams()
     data.append('code', codeInput.value)
     data.append('token', tokenInput.value)
+    data.append('csrf_token', csrfTokenInput.value)
 
     const headers = new Headers()
     headers.append('Content-Type', 'application/x-www-form-urlencoded')
@@ -5,7 +5,7 @@
   const passphraseInput = d.getElementById('password')
   const submitButton = d.getElementById('login-submit')
   const redirectInput = d.getElementById('redirect')
-  const csrfTokenInput = d.getElementById('csrf_token')
+  const csrfTokenInput = loginForm.querySelector('input[name="csrf_token"]')
   const stateInput = d.getElementById('state')
   const clientIdInput = d.getElementById('client_id')
   const loginField = d.getElementById('login-field')
@@ -12,7 +12,7 @@
   const iterationsInput = d.getElementById('iterations')
   const registerTokenInput = d.getElementById('register-token')
   const resetTokenInput = d.getElementById('reset-token')
-  const csrfTokenInput = d.getElementById('csrf_token')
+  const csrfTokenInput = form.querySelector('input[name="csrf_token"]')
 
   const querystring = new URLSearchParams(w.location.search)
   const redirection = querystring.get('redirection')
@@ -12,6 +12,7 @@
   const tokenInput = d.getElementById('two-factor-token')
   const trustCheckbox = d.getElementById('two-factor-trust-device')
   const longRunCheckbox = d.getElementById('long-run-session')
+  const csrfTokenInput = twofaForm.querySelector('input[name="csrf_token"]')
 
   const storage = w.localStorage
 
@@ -32,6 +33,7 @@
     data.append('two-factor-token', token)
     data.append('two-factor-generate-trusted-device-token', trustDevice)
     data.append('redirect', redirect)
+    data.append('csrf_token', csrfTokenInput.value)
 
     // When 2FA is checked for moving a Cozy to this instance
     if (stateInput) {
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/cozy/cozy-stack/client/request"
+	"github.com/cozy/cozy-stack/model/diskusage"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/move"
 	"github.com/cozy/cozy-stack/pkg/consts"
@@ -43,6 +44,7 @@ type Instance struct {
 		OnboardingFinished   bool      `json:"onboarding_finished"`
 		PasswordDefined      *bool     `json:"password_defined"`
 		MagicLink            bool      `json:"magic_link,omitempty"`
+		Organization         bool      `json:"organization,omitempty"`
 		BytesDiskQuota       int64     `json:"disk_quota,string,omitempty"`
 		IndexViewsVersion    int       `json:"indexes_version"`
 		CouchCluster         int       `json:"couch_cluster,omitempty"`
@@ -76,6 +78,7 @@ type InstanceOptions struct {
 	Passphrase         string
 	KdfIterations      int
 	MagicLink          *bool
+	Organization       *bool
 	Debug              *bool
 	Blocked            *bool
 	Deleting           *bool
@@ -167,6 +170,9 @@ func (ac *AdminClient) CreateInstance(opts *InstanceOptions) (*Instance, error)
 	if opts.MagicLink != nil && *opts.MagicLink {
 		q.Add("MagicLink", "true")
 	}
+	if opts.Organization != nil && *opts.Organization {
+		q.Add("Organization", "true")
+	}
 	if opts.Trace != nil && *opts.Trace {
 		q.Add("Trace", "true")
 	}
@@ -240,6 +246,9 @@ func (ac *AdminClient) ModifyInstance(opts *InstanceOptions) (*Instance, error)
 	if opts.MagicLink != nil {
 		q.Add("MagicLink", strconv.FormatBool(*opts.MagicLink))
 	}
+	if opts.Organization != nil {
+		q.Add("Organization", strconv.FormatBool(*opts.Organization))
+	}
 	if opts.Debug != nil {
 		q.Add("Debug", strconv.FormatBool(*opts.Debug))
 	}
@@ -327,6 +336,69 @@ func (ac *AdminClient) CleanSessions(domain string) error {
 	return err
 }
 
+// PurgeTombstones purges the tombstones of the deleted documents of an
+// instance. With dryRun, the tombstones are only counted and the reports are
+// returned. Else, the purge is made by a job, which is returned.
+func (ac *AdminClient) PurgeTombstones(domain string, doctypes []string, dryRun bool) (json.RawMessage, error) {
+	if !validDomain(domain) {
+		return nil, fmt.Errorf("Invalid domain: %s", domain)
+	}
+	res, err := ac.Req(&request.Options{
+		Method: "POST",
+		Path:   "/instances/" + domain + "/tombstones/purge",
+		Queries: url.Values{
+			"doctypes": {strings.Join(doctypes, ",")},
+			"dry-run":  {strconv.FormatBool(dryRun)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var out json.RawMessage
+	if err = json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StandbyReplication calls the admin API of the stack for the warm standby
+// replication of an instance. The action can be "status", "enable", or
+// "disable" on the primary stack, and "promote" on the secondary stack.
+func (ac *AdminClient) StandbyReplication(domain, action string) (json.RawMessage, error) {
+	if !validDomain(domain) {
+		return nil, fmt.Errorf("Invalid domain: %s", domain)
+	}
+	opts := &request.Options{
+		Path: "/instances/" + domain + "/standby",
+	}
+	switch action {
+	case "status":
+		opts.Method = "GET"
+	case "enable":
+		opts.Method = "POST"
+	case "disable":
+		opts.Method = "DELETE"
+		opts.NoResponse = true
+	case "promote":
+		opts.Method = "POST"
+		opts.Path += "/promote"
+		opts.NoResponse = true
+	default:
+		return nil, fmt.Errorf("Unknown action: %s", action)
+	}
+	res, err := ac.Req(opts)
+	if err != nil || opts.NoResponse {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var out json.RawMessage
+	if err = json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DisableDebug disables the debug mode for the logger of an instance.
 func (ac *AdminClient) DisableDebug(domain string) error {
 	if !validDomain(domain) {
@@ -556,6 +628,25 @@ func (ac *AdminClient) DiskUsage(domain string, includeTrash bool) (map[string]i
 	return info, nil
 }
 
+// DiskUsageTrend returns the trend of the disk usage over the given number of
+// last days, and a forecast of when the quota will be reached.
+func (ac *AdminClient) DiskUsageTrend(domain string, days int) (*diskusage.Trend, error) {
+	res, err := ac.Req(&request.Options{
+		Method:  "GET",
+		Path:    "/instances/" + url.PathEscape(domain) + "/disk-usage/trend",
+		Queries: url.Values{"days": {strconv.Itoa(days)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var trend diskusage.Trend
+	if err = json.NewDecoder(res.Body).Decode(&trend); err != nil {
+		return nil, err
+	}
+	return &trend, nil
+}
+
 func readInstance(res *http.Response) (*Instance, error) {
 	in := &Instance{}
 	if err := readJSONAPI(res.Body, &in); err != nil {
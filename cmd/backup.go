@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/backup"
+	"github.com/spf13/cobra"
+)
+
+var flagBackupDest string
+var flagBackupFollow bool
+var flagBackupInterval time.Duration
+var flagBackupDoctypes []string
+var flagRestoreAt string
+var flagRestoreDir string
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <domain>...",
+	Short: "Backup incrementally the documents and files of instances",
+	Long: `
+This command saves the changes of the documents and the contents of the new
+files of the given instances since the last backup. The destination can be a
+local directory, or a Swift container with a swift://container/prefix URL (the
+credentials are taken from the ST_* or OS_* environment variables).
+
+The backups are append-only: each pass adds new segments of changes, and the
+contents of the files are stored once by their md5sum. With --follow, the
+command runs until it is interrupted and saves the new changes periodically.
+
+The 'cozy-stack backup restore' command can be used to restore the state of
+an instance at a point in time.
+`,
+	Example: `$ cozy-stack backup --dest /var/backups/cozy --follow alice.cozy.localhost bob.cozy.localhost`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+		if flagBackupDest == "" {
+			return errors.New("missing --dest")
+		}
+		store, err := backup.NewStore(flagBackupDest)
+		if err != nil {
+			return err
+		}
+		opts := &backup.Options{
+			Store:     store,
+			Doctypes:  flagBackupDoctypes,
+			NewClient: newClientSafe,
+			Logf: func(format string, args ...interface{}) {
+				fmt.Printf(format+"\n", args...)
+			},
+		}
+
+		if !flagBackupFollow {
+			for _, domain := range args {
+				if err := backup.Run(opts, domain); err != nil {
+					return fmt.Errorf("%s: %w", domain, err)
+				}
+			}
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt)
+		go func() {
+			<-sigs
+			cancel()
+		}()
+		return backup.Follow(ctx, opts, args, flagBackupInterval)
+	},
+}
+
+var restoreBackupCmd = &cobra.Command{
+	Use:   "restore <domain>",
+	Short: "Restore the backup of an instance at a point in time",
+	Long: `
+This command writes the state of an instance, as saved by 'cozy-stack backup',
+in a local directory: the documents are in <dir>/<doctype>/<id>.json, and the
+files are in <dir>/files/ with their path in the instance. The --at flag can be
+used to ignore the changes saved after a point in time.
+`,
+	Example: `$ cozy-stack backup restore --dest /var/backups/cozy --at 2023-11-20T10:00:00Z --dir /tmp/alice alice.cozy.localhost`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Usage()
+		}
+		if flagBackupDest == "" || flagRestoreDir == "" {
+			return errors.New("missing --dest or --dir")
+		}
+		var at time.Time
+		if flagRestoreAt != "" {
+			var err error
+			at, err = time.Parse(time.RFC3339, flagRestoreAt)
+			if err != nil {
+				return err
+			}
+		}
+		store, err := backup.NewStore(flagBackupDest)
+		if err != nil {
+			return err
+		}
+		stats, err := backup.Restore(&backup.RestoreOptions{
+			Store:  store,
+			Domain: args[0],
+			At:     at,
+			Dir:    flagRestoreDir,
+			Logf: func(format string, args ...interface{}) {
+				errPrintfln(format, args...)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d documents and %d files restored in %s\n", stats.Documents, stats.Files, flagRestoreDir)
+		return nil
+	},
+}
+
+func init() {
+	backupCmd.PersistentFlags().StringVar(&flagBackupDest, "dest", "", "The local directory or swift://container/prefix URL of the backups")
+	backupCmd.Flags().BoolVar(&flagBackupFollow, "follow", false, "Keep running and save the new changes periodically")
+	backupCmd.Flags().DurationVar(&flagBackupInterval, "interval", time.Minute, "The interval between two passes with --follow")
+	backupCmd.Flags().StringSliceVar(&flagBackupDoctypes, "doctypes", nil, "The doctypes to backup (all the readable doctypes by default)")
+	restoreBackupCmd.Flags().StringVar(&flagRestoreAt, "at", "", "The point in time of the restore (RFC3339), the last state by default")
+	restoreBackupCmd.Flags().StringVar(&flagRestoreDir, "dir", "", "The local directory where the documents and files are written")
+
+	backupCmd.AddCommand(restoreBackupCmd)
+	RootCmd.AddCommand(backupCmd)
+}
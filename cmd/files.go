@@ -42,6 +42,7 @@ var flagImportTo string
 var flagImportDryRun bool
 var flagImportMatch string
 var flagIncludeTrash bool
+var flagTrendDays int
 
 // filesCmdGroup represents the instances command
 var filesCmdGroup = &cobra.Command{
@@ -502,6 +503,44 @@ func importFiles(c *client.Client, from, to string, match *regexp.Regexp) error
 	})
 }
 
+var trendFilesCmd = &cobra.Command{
+	Use:   "trend [--domain domain] [--days days]",
+	Short: "Show the trend of the usage of the files of this instance",
+	Long: `
+cozy-stack files trend shows how the disk usage of the instance has evolved
+over the last days, from the daily snapshots, and a forecast of when the quota
+will be reached at the current rate.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagDomain == "" {
+			errPrintfln("%s", errMissingDomain)
+			return cmd.Usage()
+		}
+		ac := newAdminClient()
+		trend, err := ac.DiskUsageTrend(flagDomain, flagTrendDays)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Snapshots: %d in the last %d days\n", len(trend.Snapshots), trend.Days)
+		for _, snapshot := range trend.Snapshots {
+			fmt.Fprintf(os.Stdout, "  %s: %s\n", snapshot.Date, humanize.Bytes(uint64(snapshot.Used)))
+		}
+		fmt.Fprintf(os.Stdout, "Usage: %s\n", humanize.Bytes(uint64(trend.Used)))
+		if trend.Quota > 0 {
+			fmt.Fprintf(os.Stdout, "Quota: %s\n", humanize.Bytes(uint64(trend.Quota)))
+		}
+		if trend.DailyGrowth < 0 {
+			fmt.Fprintf(os.Stdout, "Daily growth: -%s\n", humanize.Bytes(uint64(-trend.DailyGrowth)))
+		} else {
+			fmt.Fprintf(os.Stdout, "Daily growth: %s\n", humanize.Bytes(uint64(trend.DailyGrowth)))
+		}
+		if trend.FullAt != nil && trend.DaysUntilFull != nil {
+			fmt.Fprintf(os.Stdout, "Full in %d days (%s)\n", *trend.DaysUntilFull, trend.FullAt.Format("2006-01-02"))
+		}
+		return nil
+	},
+}
+
 func splitArgs(command string) []string {
 	args := regexp.MustCompile("'.+'|\".+\"|\\S+").FindAllString(command, -1)
 	for i, a := range args {
@@ -527,10 +566,12 @@ func init() {
 	importFilesCmd.Flags().StringVar(&flagImportMatch, "match", "", "pattern that the imported files must match")
 
 	usageFilesCmd.Flags().BoolVar(&flagIncludeTrash, "trash", false, "Include trashed files total size")
+	trendFilesCmd.Flags().IntVar(&flagTrendDays, "days", 30, "Number of days of history used to compute the trend")
 
 	filesCmdGroup.AddCommand(execFilesCmd)
 	filesCmdGroup.AddCommand(importFilesCmd)
 	filesCmdGroup.AddCommand(usageFilesCmd)
+	filesCmdGroup.AddCommand(trendFilesCmd)
 
 	RootCmd.AddCommand(filesCmdGroup)
 }
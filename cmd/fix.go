@@ -355,7 +355,8 @@ var indexesFixer = &cobra.Command{
 	Short: "Rebuild the CouchDB views and indexes",
 	Long: `
 This fixer ensures that the CouchDB views and indexes used by the stack for
-this instance are correctly set.
+this instance are correctly set. The indexes of the custom doctypes declared in
+the config of the context of the instance are also created.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
@@ -372,6 +373,64 @@ this instance are correctly set.
 	},
 }
 
+var passphraseKDFFixer = &cobra.Command{
+	Use:   "passphrase-kdf [domain]",
+	Short: "Report the instances with a passphrase hash on the old KDF",
+	Long: `
+This fixer reports the instances where the passphrase is still hashed with a
+KDF that is not the one configured for their context (scrypt or argon2id). The
+hash is migrated transparently on the next successful login of the user.
+
+If no domain is given, all the instances are checked.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return cmd.Usage()
+		}
+		ac := newAdminClient()
+		var domains []string
+		if len(args) == 1 {
+			domains = args
+		} else {
+			instances, err := ac.ListInstances()
+			if err != nil {
+				return err
+			}
+			for _, inst := range instances {
+				domains = append(domains, inst.Attrs.Domain)
+			}
+		}
+
+		outdated := 0
+		for _, domain := range domains {
+			res, err := ac.Req(&request.Options{
+				Method: "POST",
+				Path:   fmt.Sprintf("/instances/%s/fixers/passphrase-kdf", domain),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error for %s: %s\n", domain, err)
+				continue
+			}
+			var report struct {
+				KDF      string `json:"kdf"`
+				Expected string `json:"expected"`
+				Outdated bool   `json:"outdated"`
+			}
+			err = json.NewDecoder(res.Body).Decode(&report)
+			res.Body.Close()
+			if err != nil {
+				return err
+			}
+			if report.Outdated {
+				outdated++
+				fmt.Printf("%s\t%s (expected %s)\n", domain, report.KDF, report.Expected)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d instance(s) with an outdated KDF\n", outdated)
+		return nil
+	},
+}
+
 func init() {
 	thumbnailsFixer.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Dry run")
 	thumbnailsFixer.Flags().BoolVar(&withMetadataFlag, "with-metadata", false, "Recalculate images metadata")
@@ -385,6 +444,7 @@ func init() {
 	fixerCmdGroup.AddCommand(orphanAccountFixer)
 	fixerCmdGroup.AddCommand(serviceTriggersFixer)
 	fixerCmdGroup.AddCommand(indexesFixer)
+	fixerCmdGroup.AddCommand(passphraseKDFFixer)
 
 	RootCmd.AddCommand(fixerCmdGroup)
 }
@@ -48,6 +48,7 @@ var flagUUID string
 var flagOIDCID string
 var flagFranceConnectID string
 var flagMagicLink bool
+var flagOrganization bool
 var flagTOSSigned string
 var flagTOS string
 var flagTOSLatest string
@@ -62,6 +63,8 @@ var flagOnboardingApp string
 var flagOnboardingPermissions string
 var flagOnboardingState string
 var flagPath string
+var flagDoctypes []string
+var flagDryRun bool
 
 // instanceCmdGroup represents the instances command
 var instanceCmdGroup = &cobra.Command{
@@ -197,6 +200,7 @@ be used as the error message.
 			Apps:            flagApps,
 			Passphrase:      flagPassphrase,
 			MagicLink:       &flagMagicLink,
+			Organization:    &flagOrganization,
 			Trace:           &flagTrace,
 		})
 		if err != nil {
@@ -283,6 +287,9 @@ settings for a specified domain.
 			DiskQuota:       diskQuota,
 			MagicLink:       &flagMagicLink,
 		}
+		if flag := cmd.Flag("organization"); flag.Changed {
+			opts.Organization = &flagOrganization
+		}
 		if flag := cmd.Flag("blocked"); flag.Changed {
 			opts.Blocked = &flagBlocked
 		}
@@ -1051,6 +1058,75 @@ var cleanSessionsCmd = &cobra.Command{
 	},
 }
 
+var purgeTombstonesCmd = &cobra.Command{
+	Use:   "purge-tombstones <domain>",
+	Short: "Purge the tombstones of the deleted documents",
+	Long: `
+cozy-stack instances purge-tombstones can be used to remove the tombstones of
+the documents deleted for a long time from the CouchDB databases of an
+instance. The tombstones still needed by an active sharing are kept, and the
+databases with a running replication are skipped.
+
+With --dry-run, the tombstones are only counted. Else, a job is pushed for the
+purge.
+`,
+	Example: "$ cozy-stack instances purge-tombstones cozy.localhost:8080 --doctypes io.cozy.files --dry-run",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+		ac := newAdminClient()
+		out, err := ac.PurgeTombstones(args[0], flagDoctypes, flagDryRun)
+		if err != nil {
+			return err
+		}
+		json, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(json))
+		return nil
+	},
+}
+
+var standbyCmdGroup = &cobra.Command{
+	Use:   "standby <command>",
+	Short: "Manage the warm standby replication of an instance",
+	Long: `
+cozy-stack instances standby can be used to replicate an instance to a secondary
+stack, configured in the standby section of the configuration file. The
+secondary stack continuously receives the documents and files of the instance,
+and keeps its copy blocked until it is promoted.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+func newStandbyCmd(action, short, example string) *cobra.Command {
+	return &cobra.Command{
+		Use:     action + " <domain>",
+		Short:   short,
+		Example: example,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return cmd.Usage()
+			}
+			ac := newAdminClient()
+			out, err := ac.StandbyReplication(args[0], action)
+			if err != nil || out == nil {
+				return err
+			}
+			json, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(json))
+			return nil
+		},
+	}
+}
+
 func init() {
 	instanceCmdGroup.AddCommand(showInstanceCmd)
 	instanceCmdGroup.AddCommand(showDBPrefixInstanceCmd)
@@ -1076,12 +1152,27 @@ func init() {
 	instanceCmdGroup.AddCommand(updateInstancePassphraseCmd)
 	instanceCmdGroup.AddCommand(setAuthModeCmd)
 	instanceCmdGroup.AddCommand(cleanSessionsCmd)
+	instanceCmdGroup.AddCommand(purgeTombstonesCmd)
+	standbyCmdGroup.AddCommand(newStandbyCmd("status",
+		"Show the state of the standby replication (on the primary)",
+		"$ cozy-stack instances standby status cozy.localhost:8080"))
+	standbyCmdGroup.AddCommand(newStandbyCmd("enable",
+		"Start the standby replication of an instance (on the primary)",
+		"$ cozy-stack instances standby enable cozy.localhost:8080"))
+	standbyCmdGroup.AddCommand(newStandbyCmd("disable",
+		"Stop the standby replication of an instance (on the primary)",
+		"$ cozy-stack instances standby disable cozy.localhost:8080"))
+	standbyCmdGroup.AddCommand(newStandbyCmd("promote",
+		"Promote the copy of an instance after a failover (on the secondary)",
+		"$ cozy-stack instances standby promote cozy.localhost:8080"))
+	instanceCmdGroup.AddCommand(standbyCmdGroup)
 	addInstanceCmd.Flags().StringSliceVar(&flagDomainAliases, "domain-aliases", nil, "Specify one or more aliases domain for the instance (separated by ',')")
 	addInstanceCmd.Flags().StringVar(&flagLocale, "locale", consts.DefaultLocale, "Locale of the new cozy instance")
 	addInstanceCmd.Flags().StringVar(&flagUUID, "uuid", "", "The UUID of the instance")
 	addInstanceCmd.Flags().StringVar(&flagOIDCID, "oidc_id", "", "The identifier for checking authentication from OIDC")
 	addInstanceCmd.Flags().StringVar(&flagFranceConnectID, "franceconnect_id", "", "The identifier for checking authentication with FranceConnect")
 	addInstanceCmd.Flags().BoolVar(&flagMagicLink, "magic_link", false, "Enable authentication with magic links sent by email")
+	addInstanceCmd.Flags().BoolVar(&flagOrganization, "organization", false, "Enable the organization mode, where several users can log in")
 	addInstanceCmd.Flags().StringVar(&flagTOS, "tos", "", "The TOS version signed")
 	addInstanceCmd.Flags().StringVar(&flagTimezone, "tz", "", "The timezone for the user")
 	addInstanceCmd.Flags().StringVar(&flagContextName, "context-name", "", "Context name of the instance")
@@ -1101,6 +1192,7 @@ func init() {
 	modifyInstanceCmd.Flags().StringVar(&flagOIDCID, "oidc_id", "", "New identifier for checking authentication from OIDC")
 	modifyInstanceCmd.Flags().StringVar(&flagFranceConnectID, "franceconnect_id", "", "The identifier for checking authentication with FranceConnect")
 	modifyInstanceCmd.Flags().BoolVar(&flagMagicLink, "magic_link", false, "Enable authentication with magic links sent by email")
+	modifyInstanceCmd.Flags().BoolVar(&flagOrganization, "organization", false, "Enable (or disable) the organization mode, where several users can log in")
 	modifyInstanceCmd.Flags().StringVar(&flagTOS, "tos", "", "Update the TOS version signed")
 	modifyInstanceCmd.Flags().StringVar(&flagTOSLatest, "tos-latest", "", "Update the latest TOS version")
 	modifyInstanceCmd.Flags().StringVar(&flagTimezone, "tz", "", "New timezone")
@@ -1135,6 +1227,8 @@ func init() {
 	exportCmd.Flags().StringVar(&flagPath, "path", "", "Specify the local path where to store the export archive")
 	importCmd.Flags().StringVar(&flagDomain, "domain", "", "Specify the domain name of the instance")
 	importCmd.Flags().BoolVar(&flagForce, "force", false, "Force the import without asking for confirmation")
+	purgeTombstonesCmd.Flags().StringSliceVar(&flagDoctypes, "doctypes", nil, "The doctypes of the databases to purge (all by default)")
+	purgeTombstonesCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Only count the tombstones that would be purged")
 	_ = exportCmd.MarkFlagRequired("domain")
 	_ = importCmd.MarkFlagRequired("domain")
 	RootCmd.AddCommand(instanceCmdGroup)
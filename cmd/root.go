@@ -96,11 +96,14 @@ func newAdminClient() *client.AdminClient {
 
 	return &client.AdminClient{
 		Client: client.Client{
-			Scheme:     adminURL.Scheme,
-			Addr:       adminURL.Host,
-			Domain:     adminURL.Host,
-			Client:     httpClient,
-			Authorizer: &request.BasicAuthorizer{Password: string(pass)},
+			Scheme: adminURL.Scheme,
+			Addr:   adminURL.Host,
+			Domain: adminURL.Host,
+			Client: httpClient,
+			Authorizer: &request.BasicAuthorizer{
+				Username: os.Getenv("COZY_ADMIN_ACCOUNT"),
+				Password: string(pass),
+			},
 		},
 	}
 }
@@ -245,6 +245,15 @@ func init() {
 	flags.String("password-reset-interval", "15m", "minimal duration between two password reset")
 	checkNoErr(viper.BindPFlag("password_reset_interval", flags.Lookup("password-reset-interval")))
 
+	flags.Bool("acme", false, "Obtain the TLS certificates with ACME (Let's Encrypt) and serve HTTPS")
+	checkNoErr(viper.BindPFlag("acme.enabled", flags.Lookup("acme")))
+
+	flags.String("acme-email", "", "Contact address for the ACME account")
+	checkNoErr(viper.BindPFlag("acme.email", flags.Lookup("acme-email")))
+
+	flags.String("acme-directory-url", "", "URL of the ACME directory (Let's Encrypt by default)")
+	checkNoErr(viper.BindPFlag("acme.directory_url", flags.Lookup("acme-directory-url")))
+
 	flags.BoolVar(&flagMailhog, "mailhog", false, "Alias of --mail-disable-tls --mail-port 1025, useful for MailHog")
 	flags.BoolVar(&flagDevMode, "dev", false, "Allow to run in dev mode for a prod release (disabled by default)")
 	flags.BoolVar(&flagAllowRoot, "allow-root", false, "Allow to start as root (disabled by default)")
@@ -0,0 +1,294 @@
+// Package accessstats is used to count the reads and writes made by the apps
+// on the doctypes, to show to the user which apps have used their data. The
+// counts are kept per day, without the identifiers of the documents, and only
+// a sample of the accesses is recorded.
+package accessstats
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// DefaultDays is the default number of days for the statistics.
+const DefaultDays = 30
+
+// Retention is the number of days during which the statistics are kept, and
+// so the maximal number of days that can be asked.
+const Retention = 90
+
+// maxConflicts is the number of times the update of the statistics of a day
+// is retried on a conflict.
+const maxConflicts = 5
+
+// dateLayout is the format of the date of the daily statistics, also used as
+// their identifier.
+const dateLayout = "2006-01-02"
+
+// Counts is the number of reads and writes.
+type Counts struct {
+	Reads  int64 `json:"reads"`
+	Writes int64 `json:"writes"`
+}
+
+func (c *Counts) add(other *Counts) {
+	c.Reads += other.Reads
+	c.Writes += other.Writes
+}
+
+func (c *Counts) total() int64 {
+	return c.Reads + c.Writes
+}
+
+// Daily is the statistics of the accesses for a day, indexed by the source
+// (the app, konnector or OAuth client) and then by the doctype.
+type Daily struct {
+	DocID   string                        `json:"_id,omitempty"`
+	DocRev  string                        `json:"_rev,omitempty"`
+	Date    string                        `json:"date"`
+	Sources map[string]map[string]*Counts `json:"sources"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (d *Daily) ID() string { return d.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (d *Daily) Rev() string { return d.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (d *Daily) DocType() string { return consts.AccessStats }
+
+// Clone implements couchdb.Doc
+func (d *Daily) Clone() couchdb.Doc {
+	cloned := *d
+	cloned.Sources = make(map[string]map[string]*Counts, len(d.Sources))
+	for source, doctypes := range d.Sources {
+		m := make(map[string]*Counts, len(doctypes))
+		for doctype, counts := range doctypes {
+			c := *counts
+			m[doctype] = &c
+		}
+		cloned.Sources[source] = m
+	}
+	return &cloned
+}
+
+// SetID is used to implement the couchdb.Doc interface
+func (d *Daily) SetID(id string) { d.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (d *Daily) SetRev(rev string) { d.DocRev = rev }
+
+func (d *Daily) inc(source, doctype string, counts *Counts) {
+	if d.Sources == nil {
+		d.Sources = make(map[string]map[string]*Counts)
+	}
+	doctypes, ok := d.Sources[source]
+	if !ok {
+		doctypes = make(map[string]*Counts)
+		d.Sources[source] = doctypes
+	}
+	if c, ok := doctypes[doctype]; ok {
+		c.add(counts)
+	} else {
+		c := *counts
+		doctypes[doctype] = &c
+	}
+}
+
+// Source returns the identifier of the app, konnector or OAuth client for the
+// given permission, or an empty string if the accesses made with this
+// permission are not counted (CLI, sharings, etc.).
+func Source(pdoc *permission.Permission) string {
+	switch pdoc.Type {
+	case permission.TypeWebapp, permission.TypeKonnector:
+		return pdoc.SourceID
+	case permission.TypeOauth:
+		return consts.OAuthClients + "/" + pdoc.SourceID
+	}
+	return ""
+}
+
+// Record counts an access by the source on the doctype. Only one access in
+// the sample rate of the configuration is recorded, and the statistics are
+// updated in the background, so that it doesn't slow down the request.
+func Record(db prefixer.Prefixer, source, doctype string, v permission.Verb) {
+	cfg := config.GetConfig().AccessStats
+	if cfg.Disabled || source == "" || doctype == "" || doctype == consts.AccessStats {
+		return
+	}
+	weight := int64(1)
+	if cfg.SampleRate > 1 {
+		if rand.Intn(cfg.SampleRate) != 0 {
+			return
+		}
+		weight = int64(cfg.SampleRate)
+	}
+	counts := &Counts{}
+	if v == permission.GET {
+		counts.Reads = weight
+	} else {
+		counts.Writes = weight
+	}
+	go func() {
+		if err := add(db, time.Now().UTC(), source, doctype, counts); err != nil {
+			logger.WithDomain(db.DomainName()).WithNamespace("access-stats").
+				Warnf("Cannot record the access of %s to %s: %s", source, doctype, err)
+		}
+	}()
+}
+
+func add(db prefixer.Prefixer, now time.Time, source, doctype string, counts *Counts) error {
+	date := now.Format(dateLayout)
+	var err error
+	for i := 0; i < maxConflicts; i++ {
+		daily := &Daily{}
+		err = couchdb.GetDoc(db, consts.AccessStats, date, daily)
+		if couchdb.IsNotFoundError(err) {
+			daily = &Daily{DocID: date, Date: date}
+			daily.inc(source, doctype, counts)
+			err = couchdb.CreateNamedDocWithDB(db, daily)
+			if err == nil {
+				return cleanOld(db, now)
+			}
+		} else if err == nil {
+			daily.inc(source, doctype, counts)
+			err = couchdb.UpdateDoc(db, daily)
+		}
+		if !couchdb.IsConflictError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// cleanOld removes the statistics older than the retention period. It is
+// called once a day, when the statistics of the day are created.
+func cleanOld(db prefixer.Prefixer, now time.Time) error {
+	var dailies []*Daily
+	limit := now.AddDate(0, 0, -Retention).Format(dateLayout)
+	req := &couchdb.AllDocsRequest{EndKey: limit, Limit: 1000}
+	if err := couchdb.GetAllDocs(db, consts.AccessStats, req, &dailies); err != nil {
+		return err
+	}
+	docs := make([]couchdb.Doc, 0, len(dailies))
+	for _, daily := range dailies {
+		if daily.Date < limit {
+			docs = append(docs, daily)
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	return couchdb.BulkDeleteDocs(db, consts.AccessStats, docs)
+}
+
+// SourceUsage is the number of accesses by a source on a doctype.
+type SourceUsage struct {
+	Source string `json:"source"`
+	Counts
+}
+
+// DoctypeUsage is the number of accesses on a doctype, in total and by
+// source.
+type DoctypeUsage struct {
+	Doctype string `json:"doctype"`
+	Counts
+	Sources []*SourceUsage `json:"sources"`
+}
+
+// Summary is the statistics of the accesses over the last days. The numbers
+// are estimated from a sample of the accesses.
+type Summary struct {
+	Days       int             `json:"days"`
+	Since      string          `json:"since"`
+	SampleRate int             `json:"sample_rate"`
+	Doctypes   []*DoctypeUsage `json:"doctypes"`
+}
+
+// GetSummary returns the statistics of the accesses over the last days. If a
+// doctype is given, only the accesses on this doctype are returned.
+func GetSummary(db prefixer.Prefixer, days int, doctype string) (*Summary, error) {
+	if days <= 0 {
+		days = DefaultDays
+	}
+	if days > Retention {
+		days = Retention
+	}
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Format(dateLayout)
+	var dailies []*Daily
+	req := &couchdb.AllDocsRequest{StartKey: since}
+	err := couchdb.GetAllDocs(db, consts.AccessStats, req, &dailies)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	summary := summarize(dailies, doctype)
+	summary.Days = days
+	summary.Since = since
+	summary.SampleRate = config.GetConfig().AccessStats.SampleRate
+	if summary.SampleRate < 1 {
+		summary.SampleRate = 1
+	}
+	return summary, nil
+}
+
+// summarize aggregates the daily statistics by doctype, and sorts the
+// doctypes and sources with the most used first.
+func summarize(dailies []*Daily, filter string) *Summary {
+	byDoctype := make(map[string]map[string]*Counts)
+	for _, daily := range dailies {
+		for source, doctypes := range daily.Sources {
+			for doctype, counts := range doctypes {
+				if filter != "" && doctype != filter {
+					continue
+				}
+				sources, ok := byDoctype[doctype]
+				if !ok {
+					sources = make(map[string]*Counts)
+					byDoctype[doctype] = sources
+				}
+				if c, ok := sources[source]; ok {
+					c.add(counts)
+				} else {
+					c := *counts
+					sources[source] = &c
+				}
+			}
+		}
+	}
+
+	summary := &Summary{Doctypes: make([]*DoctypeUsage, 0, len(byDoctype))}
+	for doctype, sources := range byDoctype {
+		usage := &DoctypeUsage{
+			Doctype: doctype,
+			Sources: make([]*SourceUsage, 0, len(sources)),
+		}
+		for source, counts := range sources {
+			usage.Counts.add(counts)
+			usage.Sources = append(usage.Sources, &SourceUsage{Source: source, Counts: *counts})
+		}
+		sort.Slice(usage.Sources, func(i, j int) bool {
+			a, b := usage.Sources[i], usage.Sources[j]
+			if a.total() != b.total() {
+				return a.total() > b.total()
+			}
+			return a.Source < b.Source
+		})
+		summary.Doctypes = append(summary.Doctypes, usage)
+	}
+	sort.Slice(summary.Doctypes, func(i, j int) bool {
+		a, b := summary.Doctypes[i], summary.Doctypes[j]
+		if a.total() != b.total() {
+			return a.total() > b.total()
+		}
+		return a.Doctype < b.Doctype
+	})
+	return summary
+}
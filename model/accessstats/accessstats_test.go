@@ -0,0 +1,45 @@
+package accessstats
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	app := &permission.Permission{Type: permission.TypeWebapp, SourceID: "io.cozy.apps/banks"}
+	assert.Equal(t, "io.cozy.apps/banks", Source(app))
+	client := &permission.Permission{Type: permission.TypeOauth, SourceID: "client1"}
+	assert.Equal(t, consts.OAuthClients+"/client1", Source(client))
+	cli := &permission.Permission{Type: permission.TypeCLI}
+	assert.Empty(t, Source(cli))
+}
+
+func TestSummarize(t *testing.T) {
+	day1 := &Daily{Date: "2024-03-01"}
+	day1.inc("io.cozy.apps/banks", "io.cozy.bank.operations", &Counts{Reads: 10})
+	day1.inc("io.cozy.konnectors/bank", "io.cozy.bank.operations", &Counts{Writes: 20})
+	day1.inc("io.cozy.apps/drive", "io.cozy.files", &Counts{Reads: 5})
+	day2 := &Daily{Date: "2024-03-02"}
+	day2.inc("io.cozy.apps/banks", "io.cozy.bank.operations", &Counts{Reads: 30, Writes: 10})
+
+	summary := summarize([]*Daily{day1, day2}, "")
+	require.Len(t, summary.Doctypes, 2)
+	ops := summary.Doctypes[0]
+	assert.Equal(t, "io.cozy.bank.operations", ops.Doctype)
+	assert.EqualValues(t, 40, ops.Reads)
+	assert.EqualValues(t, 30, ops.Writes)
+	require.Len(t, ops.Sources, 2)
+	assert.Equal(t, "io.cozy.apps/banks", ops.Sources[0].Source)
+	assert.EqualValues(t, 40, ops.Sources[0].Reads)
+	assert.EqualValues(t, 10, ops.Sources[0].Writes)
+	assert.Equal(t, "io.cozy.konnectors/bank", ops.Sources[1].Source)
+	assert.Equal(t, "io.cozy.files", summary.Doctypes[1].Doctype)
+
+	summary = summarize([]*Daily{day1, day2}, "io.cozy.files")
+	require.Len(t, summary.Doctypes, 1)
+	assert.EqualValues(t, 5, summary.Doctypes[0].Reads)
+}
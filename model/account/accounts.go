@@ -282,6 +282,9 @@ func init() {
 				WithField("account_id", old.ID()).
 				Info("Executing account deletion hook")
 
+			// The copies of the account on the other instances are removed
+			revokeAccountDelegations(db, old.ID())
+
 			manualCleaning := false
 			switch v := doc.(type) {
 			case *Account:
@@ -0,0 +1,169 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+)
+
+// BackupKeyLen is the length of the key used to encrypt a backup of the
+// accounts: 32 bytes for AES-256, and 32 bytes for HMAC-SHA-256.
+const BackupKeyLen = 64
+
+// backupVersion is the version of the format of the backups.
+const backupVersion = 1
+
+var (
+	// ErrInvalidBackupKey is used when the key for a backup has not the
+	// expected length.
+	ErrInvalidBackupKey = errors.New("accounts: the backup key must be 64 bytes long")
+	// ErrInvalidBackup is used when a backup can't be decrypted with the given
+	// key, or has an unknown format.
+	ErrInvalidBackup = errors.New("accounts: the backup can't be decrypted")
+)
+
+// backedUpFields are the fields of an account that are saved in a backup. The
+// other fields (state, folder, etc.) are managed by the konnectors, and can be
+// recomputed on the next execution.
+var backedUpFields = []string{
+	"account_type",
+	"name",
+	"identifier",
+	"auth",
+	"oauth",
+	"oauth_callback_results",
+}
+
+// backup is the content of a backup, before its encryption.
+type backup struct {
+	Version  int                      `json:"version"`
+	Accounts []map[string]interface{} `json:"accounts"`
+}
+
+// ExportBackup returns an encrypted bundle with the secrets of the accounts
+// of the instance. The key is derived on the client side from the passphrase
+// of the user, and the stack never stores it, so that the bundle can be kept
+// offline and used to restore the accounts after a reinstallation.
+func ExportBackup(inst *instance.Instance, key []byte) (string, error) {
+	if len(key) != BackupKeyLen {
+		return "", ErrInvalidBackupKey
+	}
+	var docs []*couchdb.JSONDoc
+	err := couchdb.GetAllDocs(inst, consts.Accounts, nil, &docs)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return "", err
+	}
+
+	content := backup{
+		Version:  backupVersion,
+		Accounts: make([]map[string]interface{}, 0, len(docs)),
+	}
+	for _, doc := range docs {
+		if strings.HasPrefix(doc.ID(), "_design") {
+			continue
+		}
+		if _, ok := doc.M["delegated_from"]; ok {
+			// The delegated accounts are managed by another instance
+			continue
+		}
+		plain := couchdb.JSONDoc{Type: consts.Accounts, M: make(map[string]interface{})}
+		for _, field := range backedUpFields {
+			if v, ok := doc.M[field]; ok {
+				plain.M[field] = v
+			}
+		}
+		Decrypt(plain)
+		plain.M["_id"] = doc.ID()
+		content.Accounts = append(content.Accounts, plain.M)
+	}
+	return sealBackup(key, &content)
+}
+
+// RestoreBackup decrypts a bundle made by ExportBackup, and restores the
+// secrets of the accounts. The accounts that still exist are updated, and the
+// missing ones are recreated with the same identifiers. It returns the number
+// of restored accounts.
+func RestoreBackup(inst *instance.Instance, key []byte, bundle string) (int, error) {
+	if len(key) != BackupKeyLen {
+		return 0, ErrInvalidBackupKey
+	}
+	content, err := openBackup(key, bundle)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, saved := range content.Accounts {
+		id, _ := saved["_id"].(string)
+		if id == "" {
+			continue
+		}
+		plain := couchdb.JSONDoc{Type: consts.Accounts, M: make(map[string]interface{})}
+		for _, field := range backedUpFields {
+			if v, ok := saved[field]; ok {
+				plain.M[field] = v
+			}
+		}
+		Encrypt(plain)
+
+		var doc couchdb.JSONDoc
+		err := couchdb.GetDoc(inst, consts.Accounts, id, &doc)
+		if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+			return restored, err
+		}
+		if doc.M == nil {
+			doc.M = map[string]interface{}{
+				"_id":          id,
+				"cozyMetadata": metadata.New(),
+			}
+		}
+		doc.Type = consts.Accounts
+		for _, field := range backedUpFields {
+			if v, ok := plain.M[field]; ok {
+				doc.M[field] = v
+			}
+		}
+		if doc.Rev() != "" {
+			err = couchdb.UpdateDoc(inst, &doc)
+		} else {
+			err = createNamedDocWithDB(inst, &doc)
+		}
+		if err != nil {
+			return restored, err
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// sealBackup serializes and encrypts the backup, as a bitwarden cipher string.
+func sealBackup(key []byte, content *backup) (string, error) {
+	payload, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	iv := crypto.GenerateRandomBytes(16)
+	return crypto.EncryptWithAES256HMAC(key[:32], key[32:], payload, iv)
+}
+
+// openBackup decrypts and parses a backup.
+func openBackup(key []byte, bundle string) (*backup, error) {
+	payload, err := crypto.DecryptWithAES256HMAC(key[:32], key[32:], bundle)
+	if err != nil {
+		return nil, ErrInvalidBackup
+	}
+	var content backup
+	if err := json.Unmarshal(payload, &content); err != nil {
+		return nil, ErrInvalidBackup
+	}
+	if content.Version != backupVersion {
+		return nil, ErrInvalidBackup
+	}
+	return &content, nil
+}
@@ -0,0 +1,43 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpenBackup(t *testing.T) {
+	key := crypto.GenerateRandomBytes(BackupKeyLen)
+	content := &backup{
+		Version: backupVersion,
+		Accounts: []map[string]interface{}{
+			{
+				"_id":          "1b8e2c2e",
+				"account_type": "foo",
+				"auth": map[string]interface{}{
+					"login":    "alice",
+					"password": "s3cr3t",
+				},
+			},
+		},
+	}
+	bundle, err := sealBackup(key, content)
+	require.NoError(t, err)
+	assert.NotContains(t, bundle, "s3cr3t")
+
+	opened, err := openBackup(key, bundle)
+	require.NoError(t, err)
+	assert.Equal(t, content, opened)
+
+	other := crypto.GenerateRandomBytes(BackupKeyLen)
+	_, err = openBackup(other, bundle)
+	assert.Equal(t, ErrInvalidBackup, err)
+
+	content.Version = 42
+	bundle, err = sealBackup(key, content)
+	require.NoError(t, err)
+	_, err = openBackup(key, bundle)
+	assert.Equal(t, ErrInvalidBackup, err)
+}
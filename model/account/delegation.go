@@ -0,0 +1,273 @@
+package account
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+var (
+	// ErrDelegationDisabled is used when the context of the instance doesn't
+	// allow the delegation of accounts.
+	ErrDelegationDisabled = errors.New("accounts: the delegation is not enabled for this context")
+	// ErrInvalidRecipient is used when an account can't be delegated to the
+	// given instance.
+	ErrInvalidRecipient = errors.New("accounts: the recipient must be another instance of the same context")
+	// ErrDelegatedAccount is used when trying to delegate an account that has
+	// itself been delegated by another instance.
+	ErrDelegatedAccount = errors.New("accounts: a delegated account can't be delegated again")
+)
+
+// delegatedFields are the fields of an account that are copied to the
+// recipient of a delegation. The other fields (state, folder, etc.) are
+// managed by the recipient.
+var delegatedFields = []string{
+	"account_type",
+	"name",
+	"identifier",
+	"auth",
+	"oauth",
+	"oauth_callback_results",
+}
+
+// Delegation is a copy of an account on another instance of the same context
+// (a family or an organization), so that the konnector can be used on this
+// instance without entering the credentials again. The copy is kept in sync
+// when the account is updated, and it is removed when the delegation is
+// revoked or when the account is deleted.
+type Delegation struct {
+	DocID              string    `json:"_id,omitempty"`
+	DocRev             string    `json:"_rev,omitempty"`
+	AccountID          string    `json:"account_id"`
+	AccountType        string    `json:"account_type"`
+	Recipient          string    `json:"recipient"`
+	RecipientAccountID string    `json:"recipient_account_id"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (d *Delegation) ID() string { return d.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (d *Delegation) Rev() string { return d.DocRev }
+
+// SetID is used to implement the couchdb.Doc interface
+func (d *Delegation) SetID(id string) { d.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (d *Delegation) SetRev(rev string) { d.DocRev = rev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (d *Delegation) DocType() string { return consts.AccountsDelegations }
+
+// Clone implements couchdb.Doc
+func (d *Delegation) Clone() couchdb.Doc {
+	cloned := *d
+	return &cloned
+}
+
+// DelegationEnabled returns true if the accounts of the instance can be
+// delegated to the other instances of its context. It is enabled with the
+// accounts_delegation parameter of the context in the config.
+func DelegationEnabled(inst *instance.Instance) bool {
+	settings, ok := inst.SettingsContext()
+	if !ok {
+		return false
+	}
+	enabled, _ := settings["accounts_delegation"].(bool)
+	return enabled
+}
+
+// ListDelegations returns the delegations made by the instance.
+func ListDelegations(db prefixer.Prefixer) ([]*Delegation, error) {
+	var delegations []*Delegation
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	err := couchdb.GetAllDocs(db, consts.AccountsDelegations, req, &delegations)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+// GetDelegation returns the delegation with the given ID.
+func GetDelegation(db prefixer.Prefixer, id string) (*Delegation, error) {
+	d := &Delegation{}
+	if err := couchdb.GetDoc(db, consts.AccountsDelegations, id, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func delegationsForAccount(db prefixer.Prefixer, accountID string) ([]*Delegation, error) {
+	all, err := ListDelegations(db)
+	if err != nil {
+		return nil, err
+	}
+	var delegations []*Delegation
+	for _, d := range all {
+		if d.AccountID == accountID {
+			delegations = append(delegations, d)
+		}
+	}
+	return delegations, nil
+}
+
+// Delegate copies an account to another instance of the same context. The
+// credentials are decrypted and encrypted again for the recipient. If the
+// account has already been delegated to this instance, the copy is updated.
+func Delegate(inst *instance.Instance, accountID, recipient string) (*Delegation, error) {
+	if !DelegationEnabled(inst) {
+		return nil, ErrDelegationDisabled
+	}
+	other, err := instance.Get(recipient)
+	if err != nil || other.Domain == inst.Domain || other.ContextName != inst.ContextName {
+		return nil, ErrInvalidRecipient
+	}
+	var doc couchdb.JSONDoc
+	if err := couchdb.GetDoc(inst, consts.Accounts, accountID, &doc); err != nil {
+		return nil, err
+	}
+	if _, ok := doc.M["delegated_from"]; ok {
+		return nil, ErrDelegatedAccount
+	}
+
+	delegations, err := delegationsForAccount(inst, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range delegations {
+		if d.Recipient == other.Domain {
+			if err := pushDelegatedAccount(inst, other, d, doc); err != nil {
+				return nil, err
+			}
+			d.UpdatedAt = time.Now().UTC()
+			return d, couchdb.UpdateDoc(inst, d)
+		}
+	}
+
+	now := time.Now().UTC()
+	d := &Delegation{
+		AccountID: accountID,
+		Recipient: other.Domain,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	d.AccountType, _ = doc.M["account_type"].(string)
+	if err := pushDelegatedAccount(inst, other, d, doc); err != nil {
+		return nil, err
+	}
+	if err := couchdb.CreateDoc(inst, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// pushDelegatedAccount creates or updates the copy of the account on the
+// recipient instance.
+func pushDelegatedAccount(inst, other *instance.Instance, d *Delegation, src couchdb.JSONDoc) error {
+	plain := couchdb.JSONDoc{Type: consts.Accounts, M: make(map[string]interface{})}
+	for _, field := range delegatedFields {
+		if v, ok := src.M[field]; ok {
+			plain.M[field] = v
+		}
+	}
+	// Round-trip via the encryption, so that the copy has its own ciphertexts
+	Decrypt(plain)
+	Encrypt(plain)
+
+	var copied couchdb.JSONDoc
+	if d.RecipientAccountID != "" {
+		err := couchdb.GetDoc(other, consts.Accounts, d.RecipientAccountID, &copied)
+		if err != nil && !couchdb.IsNotFoundError(err) {
+			return err
+		}
+	}
+	if copied.M == nil {
+		copied.M = map[string]interface{}{
+			"cozyMetadata": metadata.New(),
+		}
+	}
+	copied.Type = consts.Accounts
+	for _, field := range delegatedFields {
+		if v, ok := plain.M[field]; ok {
+			copied.M[field] = v
+		} else {
+			delete(copied.M, field)
+		}
+	}
+	copied.M["delegated_from"] = map[string]interface{}{
+		"domain":     inst.Domain,
+		"account_id": src.ID(),
+	}
+	if copied.Rev() != "" {
+		return couchdb.UpdateDoc(other, &copied)
+	}
+	if err := couchdb.CreateDoc(other, &copied); err != nil {
+		return err
+	}
+	d.RecipientAccountID = copied.ID()
+	return nil
+}
+
+// SyncDelegations updates the copies of an account on the instances to which
+// it has been delegated.
+func SyncDelegations(inst *instance.Instance, doc couchdb.JSONDoc) {
+	delegations, err := delegationsForAccount(inst, doc.ID())
+	if err != nil || len(delegations) == 0 {
+		return
+	}
+	for _, d := range delegations {
+		other, err := instance.Get(d.Recipient)
+		if err == nil {
+			err = pushDelegatedAccount(inst, other, d, doc)
+		}
+		if err != nil {
+			inst.Logger().WithNamespace("accounts").
+				Warnf("Cannot sync delegation %s to %s: %s", d.DocID, d.Recipient, err)
+		}
+	}
+}
+
+// RevokeDelegation removes the copy of the account on the recipient
+// instance, and the delegation.
+func RevokeDelegation(inst *instance.Instance, d *Delegation) error {
+	if other, err := instance.Get(d.Recipient); err == nil {
+		var copied couchdb.JSONDoc
+		err := couchdb.GetDoc(other, consts.Accounts, d.RecipientAccountID, &copied)
+		if err == nil {
+			copied.Type = consts.Accounts
+			if err := couchdb.DeleteDoc(other, &copied); err != nil {
+				return err
+			}
+		} else if !couchdb.IsNotFoundError(err) {
+			return err
+		}
+	}
+	return couchdb.DeleteDoc(inst, d)
+}
+
+func revokeAccountDelegations(db prefixer.Prefixer, accountID string) {
+	inst, ok := db.(*instance.Instance)
+	if !ok {
+		var err error
+		if inst, err = instance.Get(db.DomainName()); err != nil {
+			return
+		}
+	}
+	delegations, err := delegationsForAccount(inst, accountID)
+	if err != nil {
+		return
+	}
+	for _, d := range delegations {
+		if err := RevokeDelegation(inst, d); err != nil {
+			inst.Logger().WithNamespace("accounts").
+				Warnf("Cannot revoke delegation %s: %s", d.DocID, err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegationEnabled(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Contexts = map[string]interface{}{
+		"family": map[string]interface{}{
+			"accounts_delegation": true,
+		},
+		"other": map[string]interface{}{},
+	}
+
+	assert.True(t, DelegationEnabled(&instance.Instance{ContextName: "family"}))
+	assert.False(t, DelegationEnabled(&instance.Instance{ContextName: "other"}))
+	assert.False(t, DelegationEnabled(&instance.Instance{ContextName: "unknown"}))
+}
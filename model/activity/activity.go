@@ -0,0 +1,217 @@
+// Package activity is for the timeline of the notable events of an instance:
+// files added by the konnectors, sharings accepted, new devices connected and
+// applications installed. The events are recorded by the stack when they
+// happen, so that a client can show what happened on the cozy without
+// querying all the doctypes.
+package activity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// The kinds of activities.
+const (
+	// KindFilesAdded is used for the files added by a konnector. There is one
+	// activity per konnector and per day, with the number of files.
+	KindFilesAdded = "files_added"
+	// KindSharingAccepted is used when a sharing has been accepted, by a
+	// recipient for the sharer, or by the user for a recipient.
+	KindSharingAccepted = "sharing_accepted"
+	// KindDeviceConnected is used when a new OAuth client (mobile, desktop,
+	// etc.) has been connected to the instance.
+	KindDeviceConnected = "device_connected"
+	// KindAppInstalled is used when a webapp or a konnector has been
+	// installed.
+	KindAppInstalled = "app_installed"
+	// KindTokensRevoked is used when the tokens of an OAuth client have been
+	// revoked, without deleting the client.
+	KindTokensRevoked = "tokens_revoked"
+	// KindAppDataRemoved is used when the documents created by an
+	// application have been removed after its uninstallation.
+	KindAppDataRemoved = "app_data_removed"
+)
+
+// maxRetries is the number of tries to update an aggregated activity when
+// there are conflicts.
+const maxRetries = 3
+
+// Activity is a notable event on an instance.
+type Activity struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+
+	Kind string `json:"kind"`
+	// Doctype is the doctype of the document related to the event, and it
+	// can be used to filter the timeline.
+	Doctype   string    `json:"doctype"`
+	RelatedID string    `json:"related_id,omitempty"`
+	Slug      string    `json:"slug,omitempty"`
+	Label     string    `json:"label,omitempty"`
+	Member    string    `json:"member,omitempty"`
+	Count     int       `json:"count,omitempty"`
+	Date      time.Time `json:"date"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (a *Activity) ID() string { return a.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (a *Activity) Rev() string { return a.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (a *Activity) DocType() string { return consts.Activities }
+
+// SetID is used to implement the couchdb.Doc interface
+func (a *Activity) SetID(id string) { a.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (a *Activity) SetRev(rev string) { a.DocRev = rev }
+
+// Clone implements couchdb.Doc
+func (a *Activity) Clone() couchdb.Doc {
+	cloned := *a
+	return &cloned
+}
+
+// Fetch implements permission.Fetcher
+func (a *Activity) Fetch(field string) []string {
+	switch field {
+	case "kind":
+		return []string{a.Kind}
+	case "doctype":
+		return []string{a.Doctype}
+	case "slug":
+		return []string{a.Slug}
+	}
+	return nil
+}
+
+// Record saves an activity in the timeline. It is best effort: the errors are
+// only logged, as they must not make the action fail.
+func Record(db prefixer.Prefixer, a *Activity) {
+	if a.Date.IsZero() {
+		a.Date = time.Now().UTC()
+	}
+	if err := couchdb.CreateDoc(db, a); err != nil {
+		logger.WithDomain(db.DomainName()).WithNamespace("activities").
+			Warnf("Cannot record %s activity: %s", a.Kind, err)
+	}
+}
+
+// RecordFilesAdded increments the number of files added today by the given
+// konnector.
+func RecordFilesAdded(db prefixer.Prefixer, slug string) {
+	now := time.Now().UTC()
+	id := fmt.Sprintf("%s-%s-%s", KindFilesAdded, slug, now.Format("2006-01-02"))
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		a := &Activity{}
+		err = couchdb.GetDoc(db, consts.Activities, id, a)
+		switch {
+		case err == nil:
+			a.Count++
+			a.Date = now
+			err = couchdb.UpdateDoc(db, a)
+		case couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err):
+			a = &Activity{
+				Kind:    KindFilesAdded,
+				Doctype: consts.Files,
+				Slug:    slug,
+				Count:   1,
+				Date:    now,
+			}
+			a.SetID(id)
+			err = couchdb.CreateNamedDocWithDB(db, a)
+		}
+		if !couchdb.IsConflictError(err) {
+			break
+		}
+	}
+	if err != nil {
+		logger.WithDomain(db.DomainName()).WithNamespace("activities").
+			Warnf("Cannot record %s activity: %s", KindFilesAdded, err)
+	}
+}
+
+// List returns a page of the timeline, from the most recent activities to
+// the oldest ones. If doctypes is not empty, only the activities for these
+// doctypes are returned. The bookmark can be used to fetch the next page.
+func List(db prefixer.Prefixer, doctypes []string, limit int, bookmark string) ([]*Activity, string, error) {
+	req := &couchdb.FindRequest{
+		Bookmark: bookmark,
+		Limit:    limit,
+	}
+	switch len(doctypes) {
+	case 0:
+		req.UseIndex = "by-date"
+		req.Selector = mango.Gt("date", "")
+		req.Sort = mango.SortBy{{Field: "date", Direction: mango.Desc}}
+	case 1:
+		req.UseIndex = "by-doctype-and-date"
+		req.Selector = mango.And(mango.Equal("doctype", doctypes[0]), mango.Gt("date", ""))
+		req.Sort = mango.SortBy{
+			{Field: "doctype", Direction: mango.Desc},
+			{Field: "date", Direction: mango.Desc},
+		}
+	default:
+		values := make([]interface{}, len(doctypes))
+		for i, doctype := range doctypes {
+			values[i] = doctype
+		}
+		req.UseIndex = "by-date"
+		req.Selector = mango.And(mango.Gt("date", ""), mango.In("doctype", values))
+		req.Sort = mango.SortBy{{Field: "date", Direction: mango.Desc}}
+	}
+
+	var activities []*Activity
+	res, err := couchdb.FindDocsRaw(db, consts.Activities, req, &activities)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return activities, res.Bookmark, nil
+}
+
+// ListBetween returns all the activities of the timeline that have happened
+// between the start (included) and the end (excluded), from the oldest to the
+// most recent.
+func ListBetween(db prefixer.Prefixer, start, end time.Time) ([]*Activity, error) {
+	var all []*Activity
+	bookmark := ""
+	for {
+		req := &couchdb.FindRequest{
+			UseIndex: "by-date",
+			Selector: mango.And(
+				mango.Gte("date", start.UTC().Format(time.RFC3339)),
+				mango.Lt("date", end.UTC().Format(time.RFC3339)),
+			),
+			Sort:     mango.SortBy{{Field: "date", Direction: mango.Asc}},
+			Limit:    1000,
+			Bookmark: bookmark,
+		}
+		var activities []*Activity
+		res, err := couchdb.FindDocsRaw(db, consts.Activities, req, &activities)
+		if err != nil {
+			if couchdb.IsNoDatabaseError(err) {
+				return all, nil
+			}
+			return nil, err
+		}
+		all = append(all, activities...)
+		if len(activities) < req.Limit || res.Bookmark == "" {
+			return all, nil
+		}
+		bookmark = res.Bookmark
+	}
+}
+
+var _ couchdb.Doc = &Activity{}
@@ -0,0 +1,52 @@
+package activity_test
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/activity"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivity(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+
+	t.Run("Timeline", func(t *testing.T) {
+		activity.RecordFilesAdded(inst, "edf")
+		activity.RecordFilesAdded(inst, "edf")
+		activity.Record(inst, &activity.Activity{
+			Kind:      activity.KindSharingAccepted,
+			Doctype:   consts.Sharings,
+			RelatedID: "sharing-id",
+			Label:     "Holidays",
+		})
+
+		list, _, err := activity.List(inst, nil, 10, "")
+		require.NoError(t, err)
+		kinds := make(map[string]*activity.Activity)
+		for _, a := range list {
+			kinds[a.Kind] = a
+		}
+		require.Contains(t, kinds, activity.KindFilesAdded)
+		assert.Equal(t, 2, kinds[activity.KindFilesAdded].Count)
+		assert.Equal(t, "edf", kinds[activity.KindFilesAdded].Slug)
+		require.Contains(t, kinds, activity.KindSharingAccepted)
+
+		list, _, err = activity.List(inst, []string{consts.Sharings}, 10, "")
+		require.NoError(t, err)
+		for _, a := range list {
+			assert.Equal(t, consts.Sharings, a.Doctype)
+		}
+		assert.NotEmpty(t, list)
+	})
+}
@@ -0,0 +1,111 @@
+// Package antivirus is for scanning the content of the new files with a ClamAV
+// daemon. The files where a virus has been found are flagged in their
+// metadata, their download is blocked, and the user is notified.
+package antivirus
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+)
+
+// WorkerType is the type of the worker that scans the files.
+const WorkerType = "scan-file"
+
+// ErrDisabled is used when the antivirus is not enabled in the config.
+var ErrDisabled = errors.New("The antivirus is not enabled")
+
+// Enabled returns true if the scan of the files is enabled in the config.
+func Enabled() bool {
+	return config.GetConfig().Antivirus.Address != ""
+}
+
+// ScanFile scans the content of the file, and updates its metadata with the
+// result. The user is notified when a virus is found.
+func ScanFile(inst *instance.Instance, doc *vfs.FileDoc) error {
+	cfg := config.GetConfig().Antivirus
+	if cfg.Address == "" {
+		return ErrDisabled
+	}
+	if cfg.MaxFileSize > 0 && doc.ByteSize > cfg.MaxFileSize {
+		return nil
+	}
+	client, err := NewClamd(cfg.Address, cfg.Timeout)
+	if err != nil {
+		return err
+	}
+
+	fs := inst.VFS()
+	content, err := fs.OpenFile(doc)
+	if err != nil {
+		return err
+	}
+	res, err := client.Scan(content)
+	if errc := content.Close(); errc != nil && err == nil {
+		err = errc
+	}
+	if err != nil {
+		return err
+	}
+	return applyResult(inst, doc, res)
+}
+
+// applyResult flags the file when a virus has been found, or removes the flag
+// when the new content of a file is clean.
+func applyResult(inst *instance.Instance, olddoc *vfs.FileDoc, res *Result) error {
+	if !res.Infected {
+		if _, ok := olddoc.Metadata[vfs.AntivirusKey]; !ok {
+			return nil
+		}
+		newdoc := olddoc.Clone().(*vfs.FileDoc)
+		delete(newdoc.Metadata, vfs.AntivirusKey)
+		return inst.VFS().UpdateFileDoc(olddoc, newdoc)
+	}
+
+	inst.Logger().WithNamespace("antivirus").
+		Infof("Virus %s found in file %s", res.Signature, olddoc.DocID)
+	newdoc := olddoc.Clone().(*vfs.FileDoc)
+	if newdoc.Metadata == nil {
+		newdoc.Metadata = vfs.NewMetadata()
+	}
+	newdoc.Metadata[vfs.AntivirusKey] = map[string]interface{}{
+		"status":     vfs.AntivirusInfected,
+		"signature":  res.Signature,
+		"scanned_at": time.Now().UTC(),
+	}
+	if err := inst.VFS().UpdateFileDoc(olddoc, newdoc); err != nil {
+		return err
+	}
+	if err := notify(inst, newdoc, res.Signature); err != nil {
+		inst.Logger().WithNamespace("antivirus").
+			Warnf("Cannot notify the infected file %s: %s", newdoc.DocID, err)
+	}
+	return nil
+}
+
+func notify(inst *instance.Instance, doc *vfs.FileDoc, signature string) error {
+	title := inst.Translate("Notifications Infected File Title")
+	message := inst.Translate("Notifications Infected File Message", doc.DocName, signature)
+	n := &notification.Notification{
+		Title:       title,
+		Message:     message,
+		Slug:        consts.DriveSlug,
+		CategoryID:  doc.DocID,
+		Content:     fmt.Sprintf("%s\n\n%s\n", title, message),
+		ContentHTML: fmt.Sprintf("<p>%s</p><p>%s</p>", html.EscapeString(title), html.EscapeString(message)),
+		Data: map[string]interface{}{
+			"file_id":   doc.DocID,
+			"signature": signature,
+		},
+		PreferredChannels: []string{"mail", "mobile"},
+	}
+	return center.PushStack(inst.DomainName(), center.NotificationInfectedFile, n)
+}
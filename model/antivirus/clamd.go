@@ -0,0 +1,120 @@
+package antivirus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// chunkSize is the size of the chunks sent to clamd. It must be lower than
+// the StreamMaxLength of the clamd configuration.
+const chunkSize = 64 * 1024
+
+// ErrInvalidAddress is used when the address of clamd is not a valid unix or
+// tcp address.
+var ErrInvalidAddress = errors.New("Invalid address for clamd")
+
+// Result is the result of the scan of a content.
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// Clamd is a client for the ClamAV daemon.
+type Clamd struct {
+	Network string
+	Address string
+	Timeout time.Duration
+}
+
+// NewClamd returns a client for the clamd listening on the given address,
+// like unix:///run/clamav/clamd.ctl or tcp://localhost:3310.
+func NewClamd(address string, timeout time.Duration) (*Clamd, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return nil, ErrInvalidAddress
+		}
+		return &Clamd{Network: "unix", Address: u.Path, Timeout: timeout}, nil
+	case "tcp":
+		if u.Host == "" {
+			return nil, ErrInvalidAddress
+		}
+		return &Clamd{Network: "tcp", Address: u.Host, Timeout: timeout}, nil
+	}
+	return nil, ErrInvalidAddress
+}
+
+// Scan sends the content to clamd with the INSTREAM command, and returns the
+// result of the scan.
+func (c *Clamd) Scan(r io.Reader) (*Result, error) {
+	conn, err := net.DialTimeout(c.Network, c.Address, c.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if c.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, errw := conn.Write(size); errw != nil {
+				return nil, errw
+			}
+			if _, errw := conn.Write(buf[:n]); errw != nil {
+				return nil, errw
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return parseReply(reply)
+}
+
+// parseReply parses the reply of clamd, like "stream: OK" or
+// "stream: Eicar-Signature FOUND".
+func parseReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{Infected: false}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(reply, " FOUND")
+		return &Result{Infected: true, Signature: signature}, nil
+	}
+	return nil, fmt.Errorf("unexpected reply from clamd: %q", reply)
+}
@@ -0,0 +1,108 @@
+package antivirus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd answers to the INSTREAM commands, and finds a virus if the
+// content contains the word "virus".
+func fakeClamd(t *testing.T) string {
+	sock := filepath.Join(t.TempDir(), "clamd.sock")
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				cmd, err := r.ReadString('\x00')
+				if err != nil || cmd != "zINSTREAM\x00" {
+					return
+				}
+				var content bytes.Buffer
+				size := make([]byte, 4)
+				for {
+					if _, err := io.ReadFull(r, size); err != nil {
+						return
+					}
+					n := binary.BigEndian.Uint32(size)
+					if n == 0 {
+						break
+					}
+					if _, err := io.CopyN(&content, r, int64(n)); err != nil {
+						return
+					}
+				}
+				if strings.Contains(content.String(), "virus") {
+					_, _ = conn.Write([]byte("stream: Test-Signature FOUND\x00"))
+				} else {
+					_, _ = conn.Write([]byte("stream: OK\x00"))
+				}
+			}(conn)
+		}
+	}()
+	return "unix://" + sock
+}
+
+func TestNewClamd(t *testing.T) {
+	c, err := NewClamd("unix:///run/clamav/clamd.ctl", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", c.Network)
+	assert.Equal(t, "/run/clamav/clamd.ctl", c.Address)
+
+	c, err = NewClamd("tcp://localhost:3310", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", c.Network)
+	assert.Equal(t, "localhost:3310", c.Address)
+
+	_, err = NewClamd("localhost:3310", time.Minute)
+	assert.Equal(t, ErrInvalidAddress, err)
+	_, err = NewClamd("http://localhost:3310", time.Minute)
+	assert.Equal(t, ErrInvalidAddress, err)
+}
+
+func TestScan(t *testing.T) {
+	c, err := NewClamd(fakeClamd(t), 5*time.Second)
+	require.NoError(t, err)
+
+	res, err := c.Scan(strings.NewReader("Hello world"))
+	require.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	big := strings.Repeat("a", 3*chunkSize) + "virus"
+	res, err = c.Scan(strings.NewReader(big))
+	require.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Test-Signature", res.Signature)
+}
+
+func TestParseReply(t *testing.T) {
+	res, err := parseReply("stream: OK\x00")
+	require.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	res, err = parseReply("stream: Eicar-Test-Signature FOUND\x00")
+	require.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", res.Signature)
+
+	_, err = parseReply("INSTREAM size limit exceeded. ERROR\x00")
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/doctype"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/vfs"
@@ -198,3 +199,39 @@ func KonnectorsFileServer(i *instance.Instance) appfs.FileServer {
 		panic(fmt.Sprintf("instance: unknown storage provider %s", fsURL.Scheme))
 	}
 }
+
+// manifestSchemas returns the JSON schemas declared in the manifest of an app
+// or a konnector, in the "schemas" field. The app must have a permission to
+// write on the doctypes of these schemas. It is called before saving the app,
+// so that an invalid schema has no side effect.
+func manifestSchemas(man Manifest, doc *couchdb.JSONDoc) (map[string]map[string]interface{}, error) {
+	schemas := make(map[string]map[string]interface{})
+	if doc == nil {
+		return schemas, nil
+	}
+	declared, _ := doc.M["schemas"].(map[string]interface{})
+	for typ, value := range declared {
+		schema, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid schema for %s", ErrBadManifest, typ)
+		}
+		writable := man.Permissions().Some(func(r permission.Rule) bool {
+			return r.Type == typ && !r.Verbs.ReadOnly()
+		})
+		if !writable {
+			return nil, fmt.Errorf("%w: no permission to write on %s for its schema", ErrBadManifest, typ)
+		}
+		if err := doctype.CheckSchema(typ, schema); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBadManifest, err)
+		}
+		schemas[typ] = schema
+	}
+	return schemas, nil
+}
+
+// syncSchemas registers the JSON schemas of an app or a konnector, and
+// removes the schemas that it no longer declares.
+func syncSchemas(db prefixer.Prefixer, man Manifest, schemas map[string]map[string]interface{}) error {
+	source := doctype.AppSource(man.AppType(), man.Slug())
+	return doctype.SyncAppSchemas(db, source, schemas)
+}
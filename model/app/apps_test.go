@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/cozy/cozy-stack/pkg/consts"
@@ -135,3 +136,36 @@ func Test_GetBySlug(t *testing.T) {
 		assert.ErrorIs(t, err, ErrInvalidAppType)
 	})
 }
+
+func TestManifestSchemas(t *testing.T) {
+	read := func(manifest string) *WebappManifest {
+		man := &WebappManifest{}
+		assert.NoError(t, json.Unmarshal([]byte(manifest), man))
+		return man
+	}
+
+	man := read(`{
+		"permissions": {"notes": {"type": "com.example.notes"}},
+		"schemas": {"com.example.notes": {"type": "object", "required": ["title"]}}
+	}`)
+	schemas, err := manifestSchemas(man, man.doc)
+	assert.NoError(t, err)
+	assert.Contains(t, schemas, "com.example.notes")
+
+	// The app must have a permission to write on the doctype
+	man = read(`{
+		"permissions": {"notes": {"type": "com.example.notes", "verbs": ["GET"]}},
+		"schemas": {"com.example.notes": {"type": "object"}}
+	}`)
+	_, err = manifestSchemas(man, man.doc)
+	assert.ErrorIs(t, err, ErrBadManifest)
+
+	// The schema is checked before the app is saved
+	man = read(`{
+		"permissions": {"notes": {"type": "com.example.notes"}},
+		"schemas": {"com.example.notes": {"type": 42}}
+	}`)
+	_, err = manifestSchemas(man, man.doc)
+	assert.ErrorIs(t, err, ErrBadManifest)
+	assert.ErrorIs(t, man.Create(nil), ErrBadManifest)
+}
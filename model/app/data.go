@@ -0,0 +1,311 @@
+package app
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/activity"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// DataWorkerType is the type of the worker that removes the data of an
+// uninstalled application.
+const DataWorkerType = "app-data"
+
+// The choices for the data of an application that is uninstalled.
+const (
+	// DataKeep is used to keep the documents created by the application (it
+	// is the default).
+	DataKeep = "keep"
+	// DataExport is used to export the documents in a zip file in the Cozy
+	// before deleting them.
+	DataExport = "export"
+	// DataDelete is used to delete the documents immediately.
+	DataDelete = "delete"
+)
+
+// dataBatchSize is the number of documents deleted in one bulk request.
+const dataBatchSize = 100
+
+// ErrInvalidDataDisposition is used for an unknown choice for the data of an
+// application.
+var ErrInvalidDataDisposition = errors.New("Invalid choice for the data of the application")
+
+// CheckDataDisposition returns an error if the choice for the data is not
+// known.
+func CheckDataDisposition(disposition string) error {
+	switch disposition {
+	case DataKeep, DataExport, DataDelete:
+		return nil
+	}
+	return ErrInvalidDataDisposition
+}
+
+// dataKeptDoctypes are the doctypes where the documents are never removed
+// with an application: the files stay in the Drive of the user, the accounts
+// are removed with the konnector, and the settings are shared with the stack.
+var dataKeptDoctypes = map[string]struct{}{
+	consts.Files:    {},
+	consts.Accounts: {},
+	consts.Settings: {},
+}
+
+// DataDoctypes returns the doctypes where the application can have created
+// documents: the doctypes of its permissions that are writable.
+func DataDoctypes(man Manifest) []string {
+	seen := make(map[string]struct{})
+	var doctypes []string
+	for _, rule := range man.Permissions() {
+		doctype := rule.Type
+		if _, ok := dataKeptDoctypes[doctype]; ok {
+			continue
+		}
+		if permission.CheckWritable(doctype) != nil {
+			continue
+		}
+		if _, ok := seen[doctype]; ok {
+			continue
+		}
+		seen[doctype] = struct{}{}
+		doctypes = append(doctypes, doctype)
+	}
+	sort.Strings(doctypes)
+	return doctypes
+}
+
+// DataCount is the number of documents created by an application for a
+// doctype.
+type DataCount struct {
+	Doctype string `json:"doctype"`
+	Count   int    `json:"count"`
+}
+
+// DataMessage is the message of the app-data worker.
+type DataMessage struct {
+	Slug        string   `json:"slug"`
+	Type        string   `json:"type"`
+	Doctypes    []string `json:"doctypes"`
+	Disposition string   `json:"disposition"`
+}
+
+// DataReport is what has been done by the app-data worker.
+type DataReport struct {
+	Slug     string      `json:"slug"`
+	Removed  []DataCount `json:"removed"`
+	ExportID string      `json:"export_id,omitempty"`
+}
+
+// createdByApp is used to read the application that has created a document.
+type createdByApp struct {
+	ID       string `json:"_id"`
+	Rev      string `json:"_rev"`
+	Metadata *struct {
+		CreatedByApp string `json:"createdByApp"`
+	} `json:"cozyMetadata"`
+}
+
+func (d *createdByApp) is(slug string) bool {
+	return d.Metadata != nil && d.Metadata.CreatedByApp == slug
+}
+
+// forEachAppDoc calls fn for each document of the doctype created by the
+// application.
+func forEachAppDoc(inst *instance.Instance, doctype, slug string, fn func(doc *createdByApp, raw json.RawMessage) error) error {
+	err := couchdb.ForeachDocs(inst, doctype, func(_ string, raw json.RawMessage) error {
+		var doc createdByApp
+		if err := json.Unmarshal(raw, &doc); err != nil || !doc.is(slug) {
+			return nil
+		}
+		return fn(&doc, raw)
+	})
+	if couchdb.IsNoDatabaseError(err) {
+		return nil
+	}
+	return err
+}
+
+// CountData returns the number of documents created by the application, for
+// each doctype.
+func CountData(inst *instance.Instance, slug string, doctypes []string) ([]DataCount, error) {
+	counts := make([]DataCount, 0, len(doctypes))
+	for _, doctype := range doctypes {
+		count := DataCount{Doctype: doctype}
+		err := forEachAppDoc(inst, doctype, slug, func(_ *createdByApp, _ json.RawMessage) error {
+			count.Count++
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// PushDataJob pushes a job for the data of an uninstalled application. There
+// is nothing to do when the data are kept.
+func PushDataJob(inst *instance.Instance, msg *DataMessage) (*job.Job, error) {
+	if msg.Disposition == DataKeep || len(msg.Doctypes) == 0 {
+		return nil, nil
+	}
+	m, err := job.NewMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: DataWorkerType,
+		Message:    m,
+	})
+}
+
+// DisposeData exports (if asked) and deletes the documents created by an
+// application, and records an activity with what has been removed.
+func DisposeData(inst *instance.Instance, msg *DataMessage) (*DataReport, error) {
+	if err := CheckDataDisposition(msg.Disposition); err != nil {
+		return nil, err
+	}
+	report := &DataReport{Slug: msg.Slug}
+	if msg.Disposition == DataKeep {
+		return report, nil
+	}
+	if err := inst.CheckLegalHold(); err != nil {
+		return nil, err
+	}
+
+	if msg.Disposition == DataExport {
+		fileID, err := exportData(inst, msg)
+		if err != nil {
+			return nil, err
+		}
+		report.ExportID = fileID
+	}
+
+	total := 0
+	for _, doctype := range msg.Doctypes {
+		count, err := deleteData(inst, doctype, msg.Slug)
+		if err != nil {
+			return nil, err
+		}
+		report.Removed = append(report.Removed, DataCount{Doctype: doctype, Count: count})
+		total += count
+	}
+
+	act := &activity.Activity{
+		Kind:    activity.KindAppDataRemoved,
+		Doctype: msg.Type,
+		Slug:    msg.Slug,
+		Count:   total,
+	}
+	if report.ExportID != "" {
+		act.Doctype = consts.Files
+		act.RelatedID = report.ExportID
+	}
+	activity.Record(inst, act)
+	return report, nil
+}
+
+// deleteData deletes the documents of the doctype created by the
+// application. They are listed before being deleted, as the pagination of
+// _all_docs would skip some documents if they are deleted in the loop.
+func deleteData(inst *instance.Instance, doctype, slug string) (int, error) {
+	var docs []couchdb.Doc
+	err := forEachAppDoc(inst, doctype, slug, func(doc *createdByApp, _ json.RawMessage) error {
+		docs = append(docs, &couchdb.JSONDoc{
+			Type: doctype,
+			M:    map[string]interface{}{"_id": doc.ID, "_rev": doc.Rev},
+		})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for len(docs) > 0 {
+		n := dataBatchSize
+		if n > len(docs) {
+			n = len(docs)
+		}
+		if err := couchdb.BulkDeleteDocs(inst, doctype, docs[:n]); err != nil {
+			return count, err
+		}
+		count += n
+		docs = docs[n:]
+	}
+	return count, nil
+}
+
+// exportData writes the documents created by the application in a zip file,
+// with a JSON file per doctype, at the root of the Drive of the user.
+func exportData(inst *instance.Instance, msg *DataMessage) (string, error) {
+	fs := inst.VFS()
+	name := fmt.Sprintf("%s - %s.zip", msg.Slug, time.Now().Format("2006-01-02"))
+	if exists, err := fs.GetIndexer().DirChildExists(consts.RootDirID, name); err == nil && exists {
+		name = vfs.ConflictName(fs, consts.RootDirID, name, true)
+	}
+	now := time.Now()
+	doc, err := vfs.NewFileDoc(name, consts.RootDirID, -1, nil,
+		"application/zip", "zip", now, false, false, false, nil)
+	if err != nil {
+		return "", err
+	}
+	file, err := fs.CreateFile(doc, nil)
+	if err != nil {
+		return "", err
+	}
+
+	zw := zip.NewWriter(file)
+	for _, doctype := range msg.Doctypes {
+		if err = exportDoctype(inst, zw, doctype, msg.Slug); err != nil {
+			break
+		}
+	}
+	if cerr := zw.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return doc.ID(), nil
+}
+
+func exportDoctype(inst *instance.Instance, zw *zip.Writer, doctype, slug string) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     doctype + ".json",
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+	first := true
+	err = forEachAppDoc(inst, doctype, slug, func(_ *createdByApp, raw json.RawMessage) error {
+		if !first {
+			if _, err := w.Write([]byte(",\n")); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err := w.Write(raw)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]byte("]\n"))
+	return err
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataDoctypes(t *testing.T) {
+	man := &WebappManifest{}
+	man.val.Permissions = permission.Set{
+		permission.Rule{Type: "io.cozy.tasky.tasks"},
+		permission.Rule{Type: "io.cozy.files"},
+		permission.Rule{Type: "io.cozy.tasky.lists"},
+		permission.Rule{Type: "io.cozy.settings"},
+		permission.Rule{Type: "io.cozy.tasky.tasks", Verbs: permission.ALL},
+	}
+	assert.Equal(t, []string{"io.cozy.tasky.lists", "io.cozy.tasky.tasks"}, DataDoctypes(man))
+}
+
+func TestCheckDataDisposition(t *testing.T) {
+	assert.NoError(t, CheckDataDisposition(DataKeep))
+	assert.NoError(t, CheckDataDisposition(DataExport))
+	assert.NoError(t, CheckDataDisposition(DataDelete))
+	assert.ErrorIs(t, CheckDataDisposition("archive"), ErrInvalidDataDisposition)
+}
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/cozy/cozy-stack/pkg/appfs"
 	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/egress"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/cozy-stack/pkg/utils"
@@ -25,24 +27,28 @@ import (
 )
 
 var httpClient = http.Client{
-	Timeout: 60 * time.Second,
+	Timeout:   60 * time.Second,
+	Transport: egress.Wrap(http.DefaultTransport.(*http.Transport)),
 }
 
 type httpFetcher struct {
 	manFilename string
+	contextName string
 	prefix      string
 	log         logger.Logger
 }
 
-func newHTTPFetcher(manFilename string, log logger.Logger) *httpFetcher {
+func newHTTPFetcher(manFilename, contextName string, log logger.Logger) *httpFetcher {
 	return &httpFetcher{
 		manFilename: manFilename,
+		contextName: contextName,
 		log:         log,
 	}
 }
 
 func (f *httpFetcher) FetchManifest(src *url.URL) (r io.ReadCloser, err error) {
-	req, err := http.NewRequest(http.MethodGet, src.String(), nil)
+	ctx := egress.WithContextName(context.Background(), f.contextName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -106,10 +112,10 @@ func (f *httpFetcher) Fetch(src *url.URL, fs appfs.Copier, man Manifest) (err er
 	if frag := src.Fragment; frag != "" {
 		shasum, _ = hex.DecodeString(frag)
 	}
-	return fetchHTTP(src, shasum, fs, man, f.prefix)
+	return fetchHTTP(src, shasum, fs, man, f.prefix, f.contextName)
 }
 
-func fetchHTTP(src *url.URL, shasum []byte, fs appfs.Copier, man Manifest, prefix string) (err error) {
+func fetchHTTP(src *url.URL, shasum []byte, fs appfs.Copier, man Manifest, prefix, contextName string) (err error) {
 	// Happy path: it exists and we don't need to acquire the lock.
 	exists, err := fs.Exist(man.Slug(), man.Version(), man.Checksum())
 	if err != nil || exists {
@@ -140,7 +146,8 @@ func fetchHTTP(src *url.URL, shasum []byte, fs appfs.Copier, man Manifest, prefi
 		}
 	}()
 
-	req, err := http.NewRequest(http.MethodGet, src.String(), nil)
+	ctx := egress.WithContextName(context.Background(), contextName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.String(), nil)
 	if err != nil {
 		return err
 	}
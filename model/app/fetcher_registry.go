@@ -14,13 +14,14 @@ import (
 )
 
 type registryFetcher struct {
-	log        logger.Logger
-	registries []*url.URL
-	version    *registry.Version
+	log         logger.Logger
+	registries  []*url.URL
+	contextName string
+	version     *registry.Version
 }
 
-func newRegistryFetcher(registries []*url.URL, log logger.Logger) Fetcher {
-	return &registryFetcher{log: log, registries: registries}
+func newRegistryFetcher(registries []*url.URL, contextName string, log logger.Logger) Fetcher {
+	return &registryFetcher{log: log, registries: registries, contextName: contextName}
 }
 
 func (f *registryFetcher) FetchManifest(src *url.URL) (io.ReadCloser, error) {
@@ -63,7 +64,7 @@ func (f *registryFetcher) Fetch(src *url.URL, fs appfs.Copier, man Manifest) err
 	}
 	man.SetVersion(v.Version)
 	man.SetChecksum(v.Sha256)
-	return fetchHTTP(u, shasum, fs, man, v.TarPrefix)
+	return fetchHTTP(u, shasum, fs, man, v.TarPrefix, f.contextName)
 }
 
 func getRegistryChannel(src *url.URL) (string, string) {
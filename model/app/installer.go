@@ -11,6 +11,7 @@ import (
 	"time"
 
 	semver "github.com/Masterminds/semver/v3"
+	"github.com/cozy/cozy-stack/model/activity"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/pkg/appfs"
@@ -158,9 +159,9 @@ func NewInstaller(in *instance.Instance, fs appfs.Copier, opts *InstallerOptions
 	case "git", "git+ssh", "ssh+git", "git+https":
 		fetcher = newGitFetcher(manFilename, log)
 	case "http", "https":
-		fetcher = newHTTPFetcher(manFilename, log)
+		fetcher = newHTTPFetcher(manFilename, in.ContextName, log)
 	case "registry":
-		fetcher = newRegistryFetcher(opts.Registries, log)
+		fetcher = newRegistryFetcher(opts.Registries, in.ContextName, log)
 	case "file":
 		fetcher = newFileFetcher(manFilename, log)
 	default:
@@ -321,7 +322,17 @@ func (i *Installer) install() error {
 		return err
 	}
 	i.man.SetState(i.endState)
-	return i.man.Create(i.db)
+	if err := i.man.Create(i.db); err != nil {
+		return err
+	}
+	activity.Record(i.db, &activity.Activity{
+		Kind:      activity.KindAppInstalled,
+		Doctype:   i.man.DocType(),
+		RelatedID: i.man.ID(),
+		Slug:      i.man.Slug(),
+		Label:     i.man.Name(),
+	})
+	return nil
 }
 
 // checkSkipPermissions checks if the instance contexts is configured to skip
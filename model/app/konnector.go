@@ -45,6 +45,9 @@ type KonnManifest struct {
 		Permissions   permission.Set `json:"permissions"`
 		Terms         Terms          `json:"terms"`
 		Notifications Notifications  `json:"notifications"`
+
+		// The domains that the konnector can reach via the konnectors proxy
+		AllowedDomains []string `json:"allowed_domains,omitempty"`
 	}
 }
 
@@ -165,6 +168,11 @@ func (m *KonnManifest) Language() string { return m.val.Language }
 // when an account associated with the konnector is deleted.
 func (m *KonnManifest) OnDeleteAccount() string { return m.val.OnDeleteAccount }
 
+// AllowedDomains returns the list of the domains that the konnector can reach
+// via the konnectors proxy. An empty list means that all the domains are
+// allowed.
+func (m *KonnManifest) AllowedDomains() []string { return m.val.AllowedDomains }
+
 // VendorLink returns the vendor link.
 func (m *KonnManifest) VendorLink() interface{} {
 	return m.doc.M["vendor_link"]
@@ -236,6 +244,10 @@ func (m *KonnManifest) ReadManifest(r io.Reader, slug, sourceURL string) (Manife
 
 // Create is part of the Manifest interface
 func (m *KonnManifest) Create(db prefixer.Prefixer) error {
+	schemas, err := manifestSchemas(m, m.doc)
+	if err != nil {
+		return err
+	}
 	m.SetID(consts.Konnectors + "/" + m.Slug())
 	m.val.CreatedAt = time.Now()
 	m.val.UpdatedAt = time.Now()
@@ -243,17 +255,22 @@ func (m *KonnManifest) Create(db prefixer.Prefixer) error {
 		return err
 	}
 
-	_, err := permission.CreateKonnectorSet(db, m.Slug(), m.Permissions(), m.Version())
-	return err
+	if _, err := permission.CreateKonnectorSet(db, m.Slug(), m.Permissions(), m.Version()); err != nil {
+		return err
+	}
+	return syncSchemas(db, m, schemas)
 }
 
 // Update is part of the Manifest interface
 func (m *KonnManifest) Update(db prefixer.Prefixer, extraPerms permission.Set) error {
-	m.val.UpdatedAt = time.Now()
-	err := couchdb.UpdateDoc(db, m)
+	schemas, err := manifestSchemas(m, m.doc)
 	if err != nil {
 		return err
 	}
+	m.val.UpdatedAt = time.Now()
+	if err = couchdb.UpdateDoc(db, m); err != nil {
+		return err
+	}
 
 	perms := m.Permissions()
 
@@ -264,8 +281,10 @@ func (m *KonnManifest) Update(db prefixer.Prefixer, extraPerms permission.Set) e
 			return err
 		}
 	}
-	_, err = permission.UpdateKonnectorSet(db, m.Slug(), perms)
-	return err
+	if _, err = permission.UpdateKonnectorSet(db, m.Slug(), perms); err != nil {
+		return err
+	}
+	return syncSchemas(db, m, schemas)
 }
 
 // Delete is part of the Manifest interface
@@ -274,6 +293,9 @@ func (m *KonnManifest) Delete(db prefixer.Prefixer) error {
 	if err != nil && !couchdb.IsNotFoundError(err) {
 		return err
 	}
+	if err := syncSchemas(db, m, nil); err != nil {
+		return err
+	}
 	return couchdb.DeleteDoc(db, m)
 }
 
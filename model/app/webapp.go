@@ -314,6 +314,10 @@ func (m *WebappManifest) ReadManifest(r io.Reader, slug, sourceURL string) (Mani
 
 // Create is part of the Manifest interface
 func (m *WebappManifest) Create(db prefixer.Prefixer) error {
+	schemas, err := manifestSchemas(m, m.doc)
+	if err != nil {
+		return err
+	}
 	m.SetID(consts.Apps + "/" + m.val.Slug)
 	m.val.CreatedAt = time.Now()
 	m.val.UpdatedAt = time.Now()
@@ -328,12 +332,18 @@ func (m *WebappManifest) Create(db prefixer.Prefixer) error {
 		_ = couchdb.UpdateDoc(db, m)
 	}
 
-	_, err := permission.CreateWebappSet(db, m.Slug(), m.Permissions(), m.Version())
-	return err
+	if _, err := permission.CreateWebappSet(db, m.Slug(), m.Permissions(), m.Version()); err != nil {
+		return err
+	}
+	return syncSchemas(db, m, schemas)
 }
 
 // Update is part of the Manifest interface
 func (m *WebappManifest) Update(db prefixer.Prefixer, extraPerms permission.Set) error {
+	schemas, err := manifestSchemas(m, m.doc)
+	if err != nil {
+		return err
+	}
 	if err := diffServices(db, m.Slug(), m.oldServices, m.val.Services); err != nil {
 		return err
 	}
@@ -342,7 +352,6 @@ func (m *WebappManifest) Update(db prefixer.Prefixer, extraPerms permission.Set)
 		return err
 	}
 
-	var err error
 	perms := m.Permissions()
 
 	// Merging the potential extra permissions
@@ -353,8 +362,10 @@ func (m *WebappManifest) Update(db prefixer.Prefixer, extraPerms permission.Set)
 		}
 	}
 
-	_, err = permission.UpdateWebappSet(db, m.Slug(), perms)
-	return err
+	if _, err = permission.UpdateWebappSet(db, m.Slug(), perms); err != nil {
+		return err
+	}
+	return syncSchemas(db, m, schemas)
 }
 
 // Delete is part of the Manifest interface
@@ -367,6 +378,9 @@ func (m *WebappManifest) Delete(db prefixer.Prefixer) error {
 	if err != nil && !couchdb.IsNotFoundError(err) {
 		return err
 	}
+	if err := syncSchemas(db, m, nil); err != nil {
+		return err
+	}
 	return couchdb.DeleteDoc(db, m)
 }
 
@@ -1,14 +1,17 @@
 package app_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/doctype"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/stack"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/tests/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -82,4 +85,76 @@ func TestWebapp(t *testing.T) {
 		assert.Equal(t, 3, len(apps))
 		assert.Equal(t, "", next)
 	})
+	t.Run("Schemas", func(t *testing.T) {
+		of := true
+		testInstance, err := lifecycle.Create(&lifecycle.Options{
+			Domain:             "test-webapp-schemas",
+			ContextName:        "foocontext",
+			OnboardingFinished: &of,
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = lifecycle.Destroy(testInstance.Domain)
+		}()
+
+		manifest := func(slug, schema string) *app.WebappManifest {
+			man := &app.WebappManifest{}
+			require.NoError(t, json.Unmarshal([]byte(`{
+				"slug": "`+slug+`",
+				"permissions": {"notes": {"type": "com.example.notes"}},
+				"schemas": {"com.example.notes": `+schema+`}
+			}`), man))
+			return man
+		}
+		validate := func(doc map[string]interface{}) error {
+			v, err := doctype.FindValidator(testInstance, testInstance.ContextName, "com.example.notes")
+			require.NoError(t, err)
+			require.NotNil(t, v)
+			return v.Validate(doc)
+		}
+
+		// Install
+		notes := manifest("notes", `{"required": ["title"]}`)
+		require.NoError(t, notes.Create(testInstance))
+		s, err := doctype.GetSchema(testInstance, "com.example.notes")
+		require.NoError(t, err)
+		assert.Equal(t, "webapp:notes", s.Source)
+		assert.Error(t, validate(map[string]interface{}{}))
+
+		// An invalid schema is rejected before the app is updated
+		rev := notes.Rev()
+		bad := manifest("notes", `{"type": 42}`)
+		bad.SetRev(rev)
+		assert.ErrorIs(t, bad.Update(testInstance, nil), app.ErrBadManifest)
+		current, err := app.GetWebappBySlug(testInstance, "notes")
+		require.NoError(t, err)
+		assert.Equal(t, rev, current.Rev())
+
+		// Another app can declare a schema for the same doctype
+		other := manifest("other", `{"required": ["author"]}`)
+		require.NoError(t, other.Create(testInstance))
+		s, err = doctype.GetSchema(testInstance, "com.example.notes")
+		require.NoError(t, err)
+		assert.Equal(t, doctype.SourceMerged, s.Source)
+		assert.Error(t, validate(map[string]interface{}{"title": "foo"}))
+		assert.NoError(t, validate(map[string]interface{}{"title": "foo", "author": "bar"}))
+
+		// Update
+		updated := manifest("notes", `{"required": ["content"]}`)
+		updated.SetRev(rev)
+		require.NoError(t, updated.Update(testInstance, nil))
+		assert.Error(t, validate(map[string]interface{}{"title": "foo", "author": "bar"}))
+		assert.NoError(t, validate(map[string]interface{}{"content": "foo", "author": "bar"}))
+
+		// Uninstall
+		require.NoError(t, updated.Delete(testInstance))
+		s, err = doctype.GetSchema(testInstance, "com.example.notes")
+		require.NoError(t, err)
+		assert.Equal(t, "webapp:other", s.Source)
+		assert.NoError(t, validate(map[string]interface{}{"author": "bar"}))
+
+		require.NoError(t, other.Delete(testInstance))
+		_, err = doctype.GetSchema(testInstance, "com.example.notes")
+		assert.True(t, couchdb.IsNotFoundError(err))
+	})
 }
@@ -0,0 +1,241 @@
+// Package apppassword is for the app-specific passwords: random credentials
+// that the user can give to a protocol client (WebDAV, CalDAV, etc.) instead
+// of their passphrase. An app password is scoped to a protocol and to some
+// doctypes, and can be revoked at any time.
+package apppassword
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// The protocols that can be used with an app password.
+const (
+	ProtocolWebDAV  = "webdav"
+	ProtocolCalDAV  = "caldav"
+	ProtocolCardDAV = "carddav"
+	ProtocolSFTP    = "sftp"
+)
+
+// defaultDoctypes are the doctypes given to an app password when none are
+// asked explicitly.
+var defaultDoctypes = map[string][]string{
+	ProtocolWebDAV:  {consts.Files},
+	ProtocolCalDAV:  {consts.CalendarEvents},
+	ProtocolCardDAV: {consts.Contacts},
+	ProtocolSFTP:    {consts.Files},
+}
+
+// secretGroups and secretGroupLen are used for the format of the generated
+// passwords, like abcd-efgh-ijkl-mnop-qrst-uvwx.
+const (
+	secretGroups   = 6
+	secretGroupLen = 4
+)
+
+// lastUsedPrecision is the minimal delay between two updates of the
+// last_used_at field, to avoid a write on each request.
+const lastUsedPrecision = time.Hour
+
+var (
+	// ErrInvalidProtocol is used when the protocol is unknown.
+	ErrInvalidProtocol = errors.New("invalid protocol")
+	// ErrMissingName is used when an app password is created without name.
+	ErrMissingName = errors.New("the name is missing")
+	// ErrInvalidPassword is used when no app password matches the given
+	// credentials.
+	ErrInvalidPassword = errors.New("invalid app password")
+)
+
+// AppPassword is a password for a protocol client. Only a hash of the secret
+// is persisted: the secret is shown once to the user, on creation.
+type AppPassword struct {
+	DocID      string     `json:"_id,omitempty"`
+	DocRev     string     `json:"_rev,omitempty"`
+	Name       string     `json:"name"`
+	Protocol   string     `json:"protocol"`
+	Doctypes   []string   `json:"doctypes"`
+	ReadOnly   bool       `json:"read_only,omitempty"`
+	SecretHash string     `json:"secret_hash"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (a *AppPassword) ID() string { return a.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (a *AppPassword) Rev() string { return a.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (a *AppPassword) DocType() string { return consts.AppPasswords }
+
+// Clone implements the couchdb.Doc interface
+func (a *AppPassword) Clone() couchdb.Doc {
+	cloned := *a
+	cloned.Doctypes = make([]string, len(a.Doctypes))
+	copy(cloned.Doctypes, a.Doctypes)
+	if a.LastUsedAt != nil {
+		at := *a.LastUsedAt
+		cloned.LastUsedAt = &at
+	}
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (a *AppPassword) SetID(id string) { a.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (a *AppPassword) SetRev(rev string) { a.DocRev = rev }
+
+// Relationships implements the jsonapi.Object interface
+func (a *AppPassword) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Included implements the jsonapi.Object interface
+func (a *AppPassword) Included() []jsonapi.Object { return nil }
+
+// Links implements the jsonapi.Object interface
+func (a *AppPassword) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/settings/app-passwords/" + a.DocID}
+}
+
+// Permissions returns the permission set given by the app password.
+func (a *AppPassword) Permissions() permission.Set {
+	verbs := permission.ALL
+	if a.ReadOnly {
+		verbs = permission.Verbs(permission.GET)
+	}
+	set := make(permission.Set, len(a.Doctypes))
+	for i, doctype := range a.Doctypes {
+		set[i] = permission.Rule{Type: doctype, Verbs: verbs}
+	}
+	return set
+}
+
+// Validate checks the app password, and fills the default doctypes.
+func (a *AppPassword) Validate() error {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" {
+		return ErrMissingName
+	}
+	defaults, ok := defaultDoctypes[a.Protocol]
+	if !ok {
+		return ErrInvalidProtocol
+	}
+	if len(a.Doctypes) == 0 {
+		a.Doctypes = defaults
+	}
+	for _, doctype := range a.Doctypes {
+		if err := permission.CheckReadable(doctype); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create generates the secret for a new app password, and persists it. The
+// secret is returned, as it cannot be retrieved later.
+func Create(db prefixer.Prefixer, a *AppPassword) (string, error) {
+	if err := a.Validate(); err != nil {
+		return "", err
+	}
+	secret := generateSecret()
+	a.SecretHash = hashSecret(secret)
+	a.CreatedAt = time.Now().UTC()
+	a.LastUsedAt = nil
+	if err := couchdb.CreateDoc(db, a); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Find returns the app password with the given identifier.
+func Find(db prefixer.Prefixer, id string) (*AppPassword, error) {
+	doc := &AppPassword{}
+	if err := couchdb.GetDoc(db, consts.AppPasswords, id, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// List returns all the app passwords of the instance.
+func List(db prefixer.Prefixer) ([]*AppPassword, error) {
+	var docs []*AppPassword
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	err := couchdb.GetAllDocs(db, consts.AppPasswords, req, &docs)
+	if couchdb.IsNoDatabaseError(err) {
+		return []*AppPassword{}, nil
+	}
+	return docs, err
+}
+
+// Revoke deletes the app password: it can no longer be used.
+func Revoke(db prefixer.Prefixer, a *AppPassword) error {
+	return couchdb.DeleteDoc(db, a)
+}
+
+// Authenticate returns the app password for the protocol that matches the
+// given secret, or ErrInvalidPassword.
+func Authenticate(db prefixer.Prefixer, protocol, secret string) (*AppPassword, error) {
+	if secret == "" {
+		return nil, ErrInvalidPassword
+	}
+	hash := []byte(hashSecret(secret))
+	docs, err := List(db)
+	if err != nil {
+		return nil, err
+	}
+	var found *AppPassword
+	for _, doc := range docs {
+		if subtle.ConstantTimeCompare(hash, []byte(doc.SecretHash)) == 1 {
+			found = doc
+		}
+	}
+	if found == nil || found.Protocol != protocol {
+		return nil, ErrInvalidPassword
+	}
+	now := time.Now().UTC()
+	if found.LastUsedAt == nil || now.Sub(*found.LastUsedAt) > lastUsedPrecision {
+		found.LastUsedAt = &now
+		_ = couchdb.UpdateDoc(db, found)
+	}
+	return found, nil
+}
+
+func generateSecret() string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	random := crypto.GenerateRandomBytes(secretGroups * secretGroupLen)
+	var sb strings.Builder
+	for i, b := range random {
+		if i > 0 && i%secretGroupLen == 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(letters[int(b)%len(letters)])
+	}
+	return sb.String()
+}
+
+// hashSecret returns the hash of a secret. The secrets are random with a lot
+// of entropy, so a fast hash is enough (no need for a KDF). The dashes and
+// spaces are ignored, as some clients make it hard to paste a password.
+func hashSecret(secret string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(secret))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
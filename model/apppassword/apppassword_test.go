@@ -0,0 +1,56 @@
+package apppassword
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	secret := generateSecret()
+	assert.Regexp(t, regexp.MustCompile(`^([a-z]{4}-){5}[a-z]{4}$`), secret)
+	assert.NotEqual(t, secret, generateSecret())
+}
+
+func TestHashSecret(t *testing.T) {
+	secret := "abcd-efgh-ijkl-mnop-qrst-uvwx"
+	hash := hashSecret(secret)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashSecret("abcdefghijklmnopqrstuvwx"))
+	assert.Equal(t, hash, hashSecret(strings.ToUpper(secret)))
+	assert.Equal(t, hash, hashSecret("abcd efgh ijkl mnop qrst uvwx"))
+	assert.NotEqual(t, hash, hashSecret("abcd-efgh-ijkl-mnop-qrst-uvwy"))
+}
+
+func TestValidate(t *testing.T) {
+	a := &AppPassword{Name: " Thunderbird ", Protocol: ProtocolCardDAV}
+	require.NoError(t, a.Validate())
+	assert.Equal(t, "Thunderbird", a.Name)
+	assert.Equal(t, []string{consts.Contacts}, a.Doctypes)
+
+	a = &AppPassword{Protocol: ProtocolWebDAV}
+	assert.Equal(t, ErrMissingName, a.Validate())
+
+	a = &AppPassword{Name: "ftp", Protocol: "ftp"}
+	assert.Equal(t, ErrInvalidProtocol, a.Validate())
+
+	a = &AppPassword{Name: "sneaky", Protocol: ProtocolWebDAV, Doctypes: []string{consts.OAuthClients}}
+	assert.Error(t, a.Validate())
+}
+
+func TestPermissions(t *testing.T) {
+	a := &AppPassword{Doctypes: []string{consts.Files}, ReadOnly: true}
+	set := a.Permissions()
+	assert.True(t, set.AllowWholeType(permission.GET, consts.Files))
+	assert.False(t, set.AllowWholeType(permission.PUT, consts.Files))
+	assert.False(t, set.AllowWholeType(permission.GET, consts.Contacts))
+
+	a.ReadOnly = false
+	set = a.Permissions()
+	assert.True(t, set.AllowWholeType(permission.PUT, consts.Files))
+}
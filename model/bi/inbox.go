@@ -0,0 +1,329 @@
+package bi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// WorkerType is the type of the worker used to replay the webhooks that have
+// failed.
+const WorkerType = "bi-webhook"
+
+const (
+	// MaxAttempts is the maximal number of times that the stack tries to
+	// process a webhook before giving up.
+	MaxAttempts = 6
+	// RetryDelay is the delay before the first replay of a webhook that has
+	// failed. It is doubled for each new attempt.
+	RetryDelay = 5 * time.Minute
+)
+
+// The states of a webhook in the inbox.
+const (
+	// StateReceived is used for a webhook that is being processed.
+	StateReceived = "received"
+	// StateDone is used for a webhook that has been processed successfully.
+	StateDone = "done"
+	// StateFailed is used for a webhook that has failed and will be replayed.
+	StateFailed = "failed"
+	// StateDead is used for a webhook that has failed too many times.
+	StateDead = "dead"
+)
+
+// InboxEntry is a webhook received from BI, persisted before being processed
+// to detect the duplicates and to replay it if it fails. The payload is
+// removed once the webhook has been processed successfully.
+type InboxEntry struct {
+	DocID         string                 `json:"_id,omitempty"`
+	DocRev        string                 `json:"_rev,omitempty"`
+	Event         EventBI                `json:"event"`
+	BIurl         string                 `json:"bi_url,omitempty"`
+	ConnectionID  int                    `json:"connection_id,omitempty"`
+	Payload       map[string]interface{} `json:"payload,omitempty"`
+	State         string                 `json:"state"`
+	Attempts      int                    `json:"attempts"`
+	NextAttemptAt *time.Time             `json:"next_attempt_at,omitempty"`
+	LastError     string                 `json:"last_error,omitempty"`
+	ReceivedAt    time.Time              `json:"received_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (e *InboxEntry) ID() string { return e.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (e *InboxEntry) Rev() string { return e.DocRev }
+
+// SetID is used to implement the couchdb.Doc interface
+func (e *InboxEntry) SetID(id string) { e.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (e *InboxEntry) SetRev(rev string) { e.DocRev = rev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (e *InboxEntry) DocType() string { return consts.BIWebhooks }
+
+// Clone implements couchdb.Doc
+func (e *InboxEntry) Clone() couchdb.Doc {
+	cloned := *e
+	if e.Payload != nil {
+		cloned.Payload = make(map[string]interface{}, len(e.Payload))
+		for k, v := range e.Payload {
+			cloned.Payload[k] = v
+		}
+	}
+	if e.NextAttemptAt != nil {
+		at := *e.NextAttemptAt
+		cloned.NextAttemptAt = &at
+	}
+	return &cloned
+}
+
+// InboxID returns the identifier of the inbox entry for a webhook. The
+// idempotency key sent by BI is used when there is one, and the body of the
+// request otherwise, so that a webhook sent twice has the same identifier.
+func InboxID(event EventBI, idempotencyKey string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(string(event) + ":"))
+	if idempotencyKey != "" {
+		h.Write([]byte(idempotencyKey))
+	} else {
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Handle is used when the stack receives a call for a BI webhook. The token is
+// checked, the webhook is persisted in the inbox, and then it is processed,
+// unless it is a duplicate of a webhook already processed. If the processing
+// fails, the webhook is replayed later, with an exponential backoff.
+func (c *WebhookCall) Handle(inboxID string) error {
+	// The stack will create or delete accounts and triggers on some webhooks,
+	// so it is safer to avoid concurrency on this part of the code.
+	mutex := config.Lock().ReadWrite(c.Instance, "bi")
+	if err := mutex.Lock(); err != nil {
+		return err
+	}
+	defer mutex.Unlock()
+
+	if err := c.authenticate(); err != nil {
+		return err
+	}
+
+	entry := &InboxEntry{}
+	err := couchdb.GetDoc(c.Instance, consts.BIWebhooks, inboxID, entry)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return err
+	}
+	if err == nil && entry.State == StateDone {
+		c.Instance.Logger().WithNamespace("webhook").
+			Infof("Duplicate webhook %s for %s ignored", inboxID, c.Event)
+		return nil
+	}
+
+	now := time.Now().UTC()
+	entry.DocID = inboxID
+	entry.Event = c.Event
+	entry.BIurl = c.BIurl
+	entry.ConnectionID = extractConnectionID(c.Event, c.Payload)
+	entry.Payload = c.Payload
+	entry.State = StateReceived
+	entry.NextAttemptAt = nil
+	entry.UpdatedAt = now
+	if entry.DocRev == "" {
+		entry.ReceivedAt = now
+		err = couchdb.CreateNamedDocWithDB(c.Instance, entry)
+	} else {
+		// A webhook that has failed is processed again when BI sends it
+		// again, and it gets a fresh set of attempts.
+		entry.Attempts = 0
+		err = couchdb.UpdateDoc(c.Instance, entry)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.process(entry)
+}
+
+// Replay is called by the bi-webhook worker to process again a webhook that
+// has failed.
+func Replay(inst *instance.Instance, inboxID string) error {
+	mutex := config.Lock().ReadWrite(inst, "bi")
+	if err := mutex.Lock(); err != nil {
+		return err
+	}
+	defer mutex.Unlock()
+
+	entry := &InboxEntry{}
+	err := couchdb.GetDoc(inst, consts.BIWebhooks, inboxID, entry)
+	if couchdb.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if entry.State != StateFailed {
+		return nil
+	}
+
+	c := &WebhookCall{
+		Instance: inst,
+		BIurl:    entry.BIurl,
+		Event:    entry.Event,
+		Payload:  entry.Payload,
+	}
+	if err := couchdb.GetAllDocs(inst, consts.Accounts, nil, &c.accounts); err != nil {
+		return err
+	}
+	if aggregator := findAccountByID(c.accounts, aggregatorID); aggregator != nil {
+		c.Token = aggregator.Token
+	}
+	if err := c.process(entry); err != nil && entry.State != StateDead {
+		inst.Logger().WithNamespace("webhook").
+			Infof("Webhook %s will be replayed: %s", inboxID, err)
+	}
+	return nil
+}
+
+// process fires the webhook and saves its new state in the inbox.
+func (c *WebhookCall) process(entry *InboxEntry) error {
+	err := c.dispatch()
+	now := time.Now().UTC()
+	entry.Attempts++
+	entry.UpdatedAt = now
+	if err == nil {
+		entry.State = StateDone
+		entry.Payload = nil
+		entry.LastError = ""
+		entry.NextAttemptAt = nil
+		return couchdb.UpdateDoc(c.Instance, entry)
+	}
+
+	entry.LastError = err.Error()
+	if entry.Attempts >= MaxAttempts {
+		entry.State = StateDead
+		entry.NextAttemptAt = nil
+		if uerr := couchdb.UpdateDoc(c.Instance, entry); uerr != nil {
+			return uerr
+		}
+		c.Instance.Logger().WithNamespace("webhook").
+			Warnf("Webhook %s for %s has failed too many times: %s", entry.DocID, entry.Event, err)
+		return err
+	}
+
+	entry.State = StateFailed
+	next := now.Add(RetryDelay << uint(entry.Attempts-1))
+	entry.NextAttemptAt = &next
+	if uerr := couchdb.UpdateDoc(c.Instance, entry); uerr != nil {
+		return uerr
+	}
+	if serr := scheduleReplay(c.Instance, entry); serr != nil {
+		return serr
+	}
+	return err
+}
+
+func scheduleReplay(inst *instance.Instance, entry *InboxEntry) error {
+	msg, err := job.NewMessage(map[string]string{"inbox_id": entry.DocID})
+	if err != nil {
+		return err
+	}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: WorkerType,
+		Arguments:  entry.NextAttemptAt.Format(time.RFC3339),
+	}, msg)
+	if err != nil {
+		return err
+	}
+	return job.System().AddTrigger(t)
+}
+
+// extractConnectionID returns the identifier of the BI connection concerned
+// by a webhook, or 0 if it is not in the payload.
+func extractConnectionID(event EventBI, payload map[string]interface{}) int {
+	var id int
+	switch event {
+	case EventConnectionSynced:
+		id, _ = extractPayloadConnID(payload)
+	case EventConnectionDeleted:
+		id, _ = extractPayloadID(payload)
+	case EventAccountEnabled, EventAccountDisabled:
+		id, _ = extractPayloadIDConnection(payload)
+	}
+	return id
+}
+
+// ConnectionStatus is a summary of the webhooks received for a BI connection.
+type ConnectionStatus struct {
+	ConnectionID   int        `json:"connection_id"`
+	LastEvent      EventBI    `json:"last_event"`
+	LastState      string     `json:"last_state"`
+	LastReceivedAt time.Time  `json:"last_received_at"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	// Pending is the number of webhooks that have failed and will be
+	// replayed.
+	Pending int `json:"pending"`
+	// Dead is the number of webhooks that have failed too many times.
+	Dead int `json:"dead"`
+}
+
+// ListConnectionStatuses returns the status of the webhooks for each BI
+// connection of the instance, sorted by connection identifier.
+func ListConnectionStatuses(inst *instance.Instance) ([]*ConnectionStatus, error) {
+	byConn := make(map[int]*ConnectionStatus)
+	err := couchdb.ForeachDocs(inst, consts.BIWebhooks, func(_ string, data json.RawMessage) error {
+		entry := &InboxEntry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			return err
+		}
+		if entry.ConnectionID == 0 {
+			return nil
+		}
+		status, ok := byConn[entry.ConnectionID]
+		if !ok {
+			status = &ConnectionStatus{ConnectionID: entry.ConnectionID}
+			byConn[entry.ConnectionID] = status
+		}
+		if entry.ReceivedAt.After(status.LastReceivedAt) {
+			status.LastEvent = entry.Event
+			status.LastState = entry.State
+			status.LastReceivedAt = entry.ReceivedAt
+			status.LastError = entry.LastError
+		}
+		switch entry.State {
+		case StateDone:
+			if status.LastSuccessAt == nil || entry.UpdatedAt.After(*status.LastSuccessAt) {
+				at := entry.UpdatedAt
+				status.LastSuccessAt = &at
+			}
+		case StateFailed:
+			status.Pending++
+		case StateDead:
+			status.Dead++
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	statuses := make([]*ConnectionStatus, 0, len(byConn))
+	for _, status := range byConn {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ConnectionID < statuses[j].ConnectionID
+	})
+	return statuses, nil
+}
@@ -0,0 +1,65 @@
+package bi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// SignatureMaxAge is the maximal difference between the date of a signed
+// webhook and the current time, to limit the replay of a captured webhook.
+const SignatureMaxAge = 5 * time.Minute
+
+// ErrInvalidSignature is used when the signature of a webhook is missing or
+// not valid.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// webhookSecret returns the secret used to sign the webhooks for the given
+// context, or an empty string if the webhooks are not signed.
+func webhookSecret(contextName string) string {
+	secrets := config.GetConfig().Konnectors.BIWebhookSecrets
+	if secret, ok := secrets[contextName]; ok {
+		return secret
+	}
+	return secrets[config.DefaultInstanceContext]
+}
+
+// VerifySignature checks the signature of a webhook, if a secret has been
+// configured for the context. The signature is sent by BI in the BI-Signature
+// header: it is the base64 of the HMAC-SHA256 of the method, the path, the
+// BI-Signature-Date header and the body, separated by dots.
+func VerifySignature(contextName string, r *http.Request, body []byte) error {
+	secret := webhookSecret(contextName)
+	if secret == "" {
+		return nil
+	}
+	given, err := base64.StdEncoding.DecodeString(r.Header.Get("BI-Signature"))
+	if err != nil || len(given) == 0 {
+		return ErrInvalidSignature
+	}
+	date := r.Header.Get("BI-Signature-Date")
+	at, err := http.ParseTime(date)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(at); age > SignatureMaxAge || age < -SignatureMaxAge {
+		return ErrInvalidSignature
+	}
+	expected := sign(secret, r.Method, r.URL.Path, date, body)
+	if !hmac.Equal(given, expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func sign(secret, method, path, date string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "." + path + "." + date + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,72 @@
+package bi
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	config.UseTestFile(t)
+	body := []byte(`{"connection":{"id":42}}`)
+	newRequest := func(date time.Time, secret string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/jobs/webhooks/bi?event=CONNECTION_SYNCED", strings.NewReader(string(body)))
+		d := date.UTC().Format(http.TimeFormat)
+		r.Header.Set("BI-Signature-Date", d)
+		sig := sign(secret, http.MethodPost, "/jobs/webhooks/bi", d, body)
+		r.Header.Set("BI-Signature", base64.StdEncoding.EncodeToString(sig))
+		return r
+	}
+
+	t.Run("NoSecret", func(t *testing.T) {
+		config.GetConfig().Konnectors.BIWebhookSecrets = nil
+		r := httptest.NewRequest(http.MethodPost, "/jobs/webhooks/bi", nil)
+		assert.NoError(t, VerifySignature("foo", r, body))
+	})
+
+	config.GetConfig().Konnectors.BIWebhookSecrets = map[string]string{
+		config.DefaultInstanceContext: "default-secret",
+		"foo":                         "foo-secret",
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, VerifySignature("foo", newRequest(time.Now(), "foo-secret"), body))
+		assert.NoError(t, VerifySignature("bar", newRequest(time.Now(), "default-secret"), body))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.ErrorIs(t, VerifySignature("foo", newRequest(time.Now(), "default-secret"), body), ErrInvalidSignature)
+		assert.ErrorIs(t, VerifySignature("foo", newRequest(time.Now(), "foo-secret"), []byte(`{}`)), ErrInvalidSignature)
+		old := time.Now().Add(-10 * time.Minute)
+		assert.ErrorIs(t, VerifySignature("foo", newRequest(old, "foo-secret"), body), ErrInvalidSignature)
+		r := httptest.NewRequest(http.MethodPost, "/jobs/webhooks/bi", nil)
+		assert.ErrorIs(t, VerifySignature("foo", r, body), ErrInvalidSignature)
+	})
+}
+
+func TestInboxID(t *testing.T) {
+	body := []byte(`{"id":42}`)
+	a := InboxID(EventConnectionDeleted, "", body)
+	assert.Equal(t, a, InboxID(EventConnectionDeleted, "", body))
+	assert.NotEqual(t, a, InboxID(EventConnectionSynced, "", body))
+	assert.NotEqual(t, a, InboxID(EventConnectionDeleted, "", []byte(`{"id":43}`)))
+	b := InboxID(EventConnectionDeleted, "key-1", body)
+	assert.Equal(t, b, InboxID(EventConnectionDeleted, "key-1", []byte(`{"id":43}`)))
+	assert.NotEqual(t, a, b)
+}
+
+func TestExtractConnectionID(t *testing.T) {
+	synced := map[string]interface{}{"connection": map[string]interface{}{"id": float64(12)}}
+	assert.Equal(t, 12, extractConnectionID(EventConnectionSynced, synced))
+	deleted := map[string]interface{}{"id": float64(13)}
+	assert.Equal(t, 13, extractConnectionID(EventConnectionDeleted, deleted))
+	enabled := map[string]interface{}{"id_connection": float64(14)}
+	assert.Equal(t, 14, extractConnectionID(EventAccountEnabled, enabled))
+	assert.Equal(t, 0, extractConnectionID(EventAccountDisabled, deleted))
+}
@@ -69,16 +69,23 @@ type WebhookCall struct {
 // token and a JSON payload. It will try to find a matching io.cozy.account and
 // a io.cozy.trigger, and launch a job for them if needed.
 func (c *WebhookCall) Fire() error {
-	var accounts []*account.Account
-	if err := couchdb.GetAllDocs(c.Instance, consts.Accounts, nil, &accounts); err != nil {
+	if err := c.authenticate(); err != nil {
 		return err
 	}
-	c.accounts = accounts
+	return c.dispatch()
+}
 
-	if err := c.checkToken(); err != nil {
+// authenticate loads the accounts and checks the bearer token of the call.
+func (c *WebhookCall) authenticate() error {
+	var accounts []*account.Account
+	if err := couchdb.GetAllDocs(c.Instance, consts.Accounts, nil, &accounts); err != nil {
 		return err
 	}
+	c.accounts = accounts
+	return c.checkToken()
+}
 
+func (c *WebhookCall) dispatch() error {
 	switch c.Event {
 	case EventConnectionSynced:
 		return c.handleConnectionSynced()
@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"errors"
+	"io"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+)
+
+// batchSize is the number of ciphers inserted in CouchDB with a single bulk
+// request.
+const batchSize = 500
+
+// Import parses an export in the given format, and saves its entries as
+// ciphers in the Cozy organization.
+func Import(inst *instance.Instance, format string, r io.Reader) (*Result, error) {
+	items, errs, err := Parse(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	setting, err := settings.Get(inst)
+	if err != nil {
+		return nil, err
+	}
+	orgKey, err := setting.OrganizationKey()
+	if errors.Is(err, settings.ErrMissingOrgKey) {
+		if err = setting.EnsureCozyOrganization(inst); err == nil {
+			if err = couchdb.UpdateDoc(inst, setting); err == nil {
+				orgKey, err = setting.OrganizationKey()
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Errors: errs}
+	var docs []interface{}
+	flush := func() error {
+		olds := make([]interface{}, len(docs))
+		if err := couchdb.BulkUpdateDocs(inst, consts.BitwardenCiphers, docs, olds); err != nil {
+			return err
+		}
+		res.Imported += len(docs)
+		docs = docs[:0]
+		return nil
+	}
+	for _, item := range items {
+		cipher, err := item.toCipher(orgKey, setting)
+		if err != nil {
+			res.Errors = append(res.Errors, EntryError{Index: item.Index, Name: item.Name, Error: err.Error()})
+			continue
+		}
+		docs = append(docs, cipher)
+		if len(docs) >= batchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(docs) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	if res.Errors == nil {
+		res.Errors = []EntryError{}
+	}
+
+	if res.Imported > 0 {
+		_ = settings.UpdateRevisionDate(inst, setting)
+	}
+	inst.Logger().WithNamespace("bitwarden").
+		Infof("Import from %s: %d ciphers, %d errors", format, res.Imported, len(res.Errors))
+	return res, nil
+}
+
+// toCipher converts an item to a cipher in the Cozy organization, with the
+// fields encrypted with the organization key.
+func (item *Item) toCipher(orgKey []byte, setting *settings.Settings) (*bitwarden.Cipher, error) {
+	if len(orgKey) != 64 {
+		return nil, errors.New("invalid organization key")
+	}
+	enc := &encrypter{key: orgKey[:32], hmac: orgKey[32:]}
+
+	md := metadata.New()
+	md.DocTypeVersion = bitwarden.DocTypeVersion
+	c := &bitwarden.Cipher{
+		Type:           item.Type,
+		SharedWithCozy: true,
+		Favorite:       item.Favorite,
+		Name:           enc.encrypt(item.Name),
+		Notes:          enc.encrypt(item.Notes),
+		OrganizationID: setting.OrganizationID,
+		CollectionID:   setting.CollectionID,
+		Fields:         make([]bitwarden.Field, 0, len(item.Fields)),
+		Metadata:       md,
+	}
+	for _, f := range item.Fields {
+		typ := bitwarden.FieldTypeText
+		if f.Hidden {
+			typ = bitwarden.FieldTypeHidden
+		}
+		c.Fields = append(c.Fields, bitwarden.Field{
+			Type:  typ,
+			Name:  enc.encrypt(f.Name),
+			Value: enc.encrypt(f.Value),
+		})
+	}
+
+	switch item.Type {
+	case bitwarden.LoginType:
+		login := &bitwarden.LoginData{
+			Username: enc.encrypt(item.Username),
+			Password: enc.encrypt(item.Password),
+			TOTP:     enc.encrypt(item.TOTP),
+		}
+		for _, u := range item.URLs {
+			login.URIs = append(login.URIs, bitwarden.LoginURI{URI: enc.encrypt(u)})
+		}
+		c.Login = login
+	case bitwarden.SecureNoteType:
+		// 0 is the generic type of secure notes for Bitwarden
+		c.Data = &bitwarden.MapData{"type": 0}
+	default:
+		return nil, errors.New("unsupported type")
+	}
+
+	if enc.err != nil {
+		return nil, enc.err
+	}
+	return c, nil
+}
+
+// encrypter encrypts the fields of a cipher, and keeps the first error to
+// avoid checking it for each field.
+type encrypter struct {
+	key  []byte
+	hmac []byte
+	err  error
+}
+
+func (e *encrypter) encrypt(value string) string {
+	if value == "" || e.err != nil {
+		return ""
+	}
+	iv := crypto.GenerateRandomBytes(16)
+	encrypted, err := crypto.EncryptWithAES256HMAC(e.key, e.hmac, []byte(value), iv)
+	if err != nil {
+		e.err = err
+		return ""
+	}
+	return encrypted
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+)
+
+// row is a line of a CSV export, with the values indexed by the lowercased
+// names of the columns.
+type row struct {
+	index  int
+	header []string
+	values map[string]string
+}
+
+// get returns the value of the first column with one of the given names.
+func (r *row) get(names ...string) string {
+	for _, name := range names {
+		if v, ok := r.values[name]; ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// extraFields returns the non-empty values of the columns that are not in the
+// known list, as additional fields.
+func (r *row) extraFields(known ...string) []Field {
+	var fields []Field
+	for _, name := range r.header {
+		if name == "" || contains(known, name) {
+			continue
+		}
+		if v := r.values[name]; v != "" {
+			fields = append(fields, Field{Name: name, Value: v})
+		}
+	}
+	return fields
+}
+
+// readCSV parses a CSV export with a header line. The lines that cannot be
+// parsed are reported as errors.
+func readCSV(data []byte) ([]*row, []EntryError, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, ErrInvalidFile
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	var rows []*row
+	var errs []EntryError
+	for index := 1; ; index++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(rows)+len(errs) > MaxEntries {
+			return nil, nil, ErrTooManyEntries
+		}
+		if err != nil {
+			errs = append(errs, EntryError{Index: index, Error: err.Error()})
+			continue
+		}
+		if len(record) > len(header) {
+			errs = append(errs, EntryError{Index: index, Error: "too many columns"})
+			continue
+		}
+		r := &row{index: index, header: header, values: make(map[string]string, len(record))}
+		for i, value := range record {
+			r.values[header[i]] = value
+		}
+		rows = append(rows, r)
+	}
+	return rows, errs, nil
+}
+
+// parseLastPass converts a CSV export of LastPass, with the columns url,
+// username, password, totp, extra, name, grouping, fav.
+func parseLastPass(data []byte) ([]*Item, []EntryError, error) {
+	rows, errs, err := readCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]*Item, 0, len(rows))
+	for _, r := range rows {
+		item := &Item{
+			Index:    r.index,
+			Name:     r.get("name"),
+			Notes:    r.get("extra"),
+			Favorite: r.get("fav") == "1",
+		}
+		// LastPass uses a fake URL for the secure notes
+		if u := r.get("url"); u == "http://sn" {
+			item.Type = bitwarden.SecureNoteType
+		} else {
+			item.Type = bitwarden.LoginType
+			item.URLs = splitURLs(u)
+			item.Username = r.get("username")
+			item.Password = r.get("password")
+			item.TOTP = r.get("totp")
+		}
+		items = append(items, item)
+	}
+	return items, errs, nil
+}
+
+var onePasswordColumns = []string{
+	"title", "name",
+	"url", "urls", "website", "login url",
+	"username", "login username",
+	"password", "login password",
+	"otpauth", "one-time password",
+	"notes", "notesplain",
+	"favorite", "archived", "tags", "type", "category",
+}
+
+// parse1PasswordCSV converts a CSV export of 1Password. The columns are not
+// the same for all the versions of 1Password, so the known names are looked
+// up, and the other columns are kept as additional fields.
+func parse1PasswordCSV(data []byte) ([]*Item, []EntryError, error) {
+	rows, errs, err := readCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]*Item, 0, len(rows))
+	for _, r := range rows {
+		item := &Item{
+			Index:    r.index,
+			Name:     r.get("title", "name"),
+			Notes:    r.get("notes", "notesplain"),
+			URLs:     splitURLs(r.get("url", "urls", "website", "login url")),
+			Username: r.get("username", "login username"),
+			Password: r.get("password", "login password"),
+			TOTP:     r.get("otpauth", "one-time password"),
+			Favorite: isTrue(r.get("favorite")),
+			Fields:   r.extraFields(onePasswordColumns...),
+		}
+		if len(item.URLs) == 0 && item.Username == "" && item.Password == "" {
+			item.Type = bitwarden.SecureNoteType
+		}
+		items = append(items, item)
+	}
+	return items, errs, nil
+}
+
+var keePassColumns = []string{
+	"group", "title", "account",
+	"username", "user name", "login name",
+	"password",
+	"url", "web site",
+	"notes", "comments",
+	"totp", "icon", "last modified", "created",
+}
+
+// parseKeePassCSV converts a CSV export of KeePassXC or KeePass.
+func parseKeePassCSV(data []byte) ([]*Item, []EntryError, error) {
+	rows, errs, err := readCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]*Item, 0, len(rows))
+	for _, r := range rows {
+		item := &Item{
+			Index:    r.index,
+			Type:     bitwarden.LoginType,
+			Name:     r.get("title", "account"),
+			Notes:    r.get("notes", "comments"),
+			URLs:     splitURLs(r.get("url", "web site")),
+			Username: r.get("username", "user name", "login name"),
+			Password: r.get("password"),
+			TOTP:     r.get("totp"),
+			Fields:   r.extraFields(keePassColumns...),
+		}
+		items = append(items, item)
+	}
+	return items, errs, nil
+}
+
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, v := range haystack {
+		if v == needle {
+			return true
+		}
+	}
+	return false
+}
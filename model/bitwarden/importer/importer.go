@@ -0,0 +1,210 @@
+// Package importer is used for importing the exports of other password
+// managers (1Password, LastPass, KeePass) in the vault. The export is parsed
+// on the server, and each entry is converted to a cipher encrypted with the
+// key of the Cozy organization. The parsing is sandboxed: the size of the
+// export and the number of entries are limited, a malformed entry is reported
+// without stopping the import, and a crash of a parser is recovered as an
+// invalid file.
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+)
+
+// The supported formats.
+const (
+	// FormatLastPass is for the CSV export of LastPass.
+	FormatLastPass = "lastpass"
+	// Format1Password is for the CSV and 1PUX exports of 1Password.
+	Format1Password = "1password"
+	// FormatKeePass is for the XML and CSV exports of KeePass and KeePassXC.
+	FormatKeePass = "keepass"
+)
+
+// MaxImportSize is the maximal size in bytes of an export (and of the
+// uncompressed data for a 1PUX archive).
+var MaxImportSize int64 = 50 << 20
+
+// MaxEntries is the maximal number of entries in an export.
+var MaxEntries = 20000
+
+// maxFieldLength is the maximal length of a field of an entry. It is large
+// enough for the notes, but avoids storing some binary blobs in a cipher.
+const maxFieldLength = 100 << 10
+
+var (
+	// ErrUnknownFormat is used when the format is not supported
+	ErrUnknownFormat = errors.New("Unknown format")
+	// ErrTooLarge is used when the export is larger than MaxImportSize
+	ErrTooLarge = errors.New("The export is too large")
+	// ErrTooManyEntries is used when the export has more than MaxEntries
+	// entries
+	ErrTooManyEntries = errors.New("The export has too many entries")
+	// ErrInvalidFile is used when the export cannot be parsed
+	ErrInvalidFile = errors.New("Invalid file")
+)
+
+// Field is an additional field of an entry.
+type Field struct {
+	Name   string
+	Value  string
+	Hidden bool
+}
+
+// Item is an entry of an export, in clear, before being converted to a
+// cipher.
+type Item struct {
+	// Index is the position of the entry in the export, starting at 1.
+	Index    int
+	Type     bitwarden.CipherType
+	Name     string
+	Notes    string
+	URLs     []string
+	Username string
+	Password string
+	TOTP     string
+	Favorite bool
+	Fields   []Field
+}
+
+// EntryError is an error for an entry that has not been imported.
+type EntryError struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+// Result is the report of an import.
+type Result struct {
+	Imported int          `json:"imported"`
+	Errors   []EntryError `json:"errors"`
+}
+
+// IsSupported returns true if the given format can be imported.
+func IsSupported(format string) bool {
+	switch format {
+	case FormatLastPass, Format1Password, FormatKeePass:
+		return true
+	}
+	return false
+}
+
+// Parse reads an export in the given format, and returns the entries that can
+// be imported, and the errors for the other entries.
+func Parse(format string, r io.Reader) (items []*Item, errs []EntryError, err error) {
+	if !IsSupported(format) {
+		return nil, nil, ErrUnknownFormat
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxImportSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > MaxImportSize {
+		return nil, nil, ErrTooLarge
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	defer func() {
+		if r := recover(); r != nil {
+			items, errs = nil, nil
+			err = fmt.Errorf("%w: %v", ErrInvalidFile, r)
+		}
+	}()
+	var parsed []*Item
+	switch format {
+	case FormatLastPass:
+		parsed, errs, err = parseLastPass(data)
+	case Format1Password:
+		if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+			parsed, errs, err = parse1PUX(data)
+		} else {
+			parsed, errs, err = parse1PasswordCSV(data)
+		}
+	case FormatKeePass:
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+			parsed, errs, err = parseKeePassXML(data)
+		} else {
+			parsed, errs, err = parseKeePassCSV(data)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(parsed)+len(errs) > MaxEntries {
+		return nil, nil, ErrTooManyEntries
+	}
+
+	for _, item := range parsed {
+		if err := item.normalize(); err != nil {
+			errs = append(errs, EntryError{Index: item.Index, Name: item.Name, Error: err.Error()})
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, errs, nil
+}
+
+// normalize checks that the item can be converted to a cipher, and gives it a
+// name if it has none.
+func (item *Item) normalize() error {
+	values := []string{item.Name, item.Notes, item.Username, item.Password, item.TOTP}
+	values = append(values, item.URLs...)
+	for _, f := range item.Fields {
+		values = append(values, f.Name, f.Value)
+	}
+	empty := true
+	for _, v := range values {
+		if len(v) > maxFieldLength {
+			return errors.New("a field is too long")
+		}
+		if !utf8.ValidString(v) {
+			return errors.New("a field is not valid UTF-8")
+		}
+		if v != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return errors.New("the entry is empty")
+	}
+
+	if item.Type == 0 {
+		item.Type = bitwarden.LoginType
+	}
+	if item.Name == "" {
+		item.Name = defaultName(item)
+	}
+	return nil
+}
+
+func defaultName(item *Item) string {
+	for _, raw := range item.URLs {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			return u.Host
+		}
+	}
+	if item.Username != "" {
+		return item.Username
+	}
+	return "--"
+}
+
+// splitURLs returns the non-empty URLs of a field that can have several URLs,
+// one per line.
+func splitURLs(value string) []string {
+	var urls []string
+	for _, u := range strings.Split(value, "\n") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
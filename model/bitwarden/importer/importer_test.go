@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLastPass(t *testing.T) {
+	export := "url,username,password,totp,extra,name,grouping,fav\n" +
+		"https://example.org/login,alice,s3cr3t,,some notes,Example,Work,1\n" +
+		"http://sn,,,,the secret note,Note,,0\n" +
+		",,,,,,,\n" +
+		"https://example.com,bob,pass,,,,,0\n"
+	items, errs, err := Parse(FormatLastPass, strings.NewReader(export))
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	require.Len(t, errs, 1)
+
+	assert.Equal(t, "Example", items[0].Name)
+	assert.Equal(t, bitwarden.CipherType(bitwarden.LoginType), items[0].Type)
+	assert.Equal(t, []string{"https://example.org/login"}, items[0].URLs)
+	assert.Equal(t, "alice", items[0].Username)
+	assert.Equal(t, "s3cr3t", items[0].Password)
+	assert.Equal(t, "some notes", items[0].Notes)
+	assert.True(t, items[0].Favorite)
+
+	assert.Equal(t, bitwarden.CipherType(bitwarden.SecureNoteType), items[1].Type)
+	assert.Equal(t, "the secret note", items[1].Notes)
+	assert.Empty(t, items[1].URLs)
+
+	assert.Equal(t, 3, errs[0].Index)
+	assert.Equal(t, "the entry is empty", errs[0].Error)
+
+	// The name is taken from the URL when it is missing
+	assert.Equal(t, "example.com", items[2].Name)
+	assert.Equal(t, 4, items[2].Index)
+}
+
+func TestParse1PasswordCSV(t *testing.T) {
+	export := "Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes,PIN\n" +
+		"Bank,https://bank.example,alice,p4ss,otpauth://totp/bank?secret=ABC,true,false,,,1234\n" +
+		"Wifi code,,,,,false,false,,The code is 42,\n"
+	items, errs, err := Parse(Format1Password, strings.NewReader(export))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "Bank", items[0].Name)
+	assert.Equal(t, "otpauth://totp/bank?secret=ABC", items[0].TOTP)
+	assert.True(t, items[0].Favorite)
+	assert.Equal(t, []Field{{Name: "pin", Value: "1234"}}, items[0].Fields)
+
+	assert.Equal(t, bitwarden.CipherType(bitwarden.SecureNoteType), items[1].Type)
+	assert.Equal(t, "The code is 42", items[1].Notes)
+}
+
+func TestParse1PUX(t *testing.T) {
+	data := `{"accounts":[{"vaults":[{"items":[
+	  {"favIndex":1,"state":"active","categoryUuid":"001",
+	   "overview":{"title":"Mail","urls":[{"url":"https://mail.example"}]},
+	   "details":{"loginFields":[
+	     {"name":"email","value":"alice@example.org","fieldType":"E","designation":"username"},
+	     {"name":"password","value":"hunter2","fieldType":"P","designation":"password"}],
+	     "notesPlain":"",
+	     "sections":[{"fields":[
+	       {"title":"one-time password","value":{"totp":"otpauth://totp/mail?secret=XYZ"}},
+	       {"title":"recovery","value":{"concealed":"r3c0very"}},
+	       {"title":"backup email","value":{"email":{"email_address":"bob@example.org"}}}]}]}},
+	  {"item":{"state":"archived","categoryUuid":"003",
+	   "overview":{"title":"Secret"},"details":{"notesPlain":"Nothing to see"}}}
+	]}]}]}`
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("export.attributes")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte(`{"version":3}`))
+	w, err = zw.Create("export.data")
+	require.NoError(t, err)
+	_, _ = w.Write([]byte(data))
+	require.NoError(t, zw.Close())
+
+	items, errs, err := Parse(Format1Password, &buf)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, items, 2)
+
+	mail := items[0]
+	assert.Equal(t, "Mail", mail.Name)
+	assert.Equal(t, []string{"https://mail.example"}, mail.URLs)
+	assert.Equal(t, "alice@example.org", mail.Username)
+	assert.Equal(t, "hunter2", mail.Password)
+	assert.Equal(t, "otpauth://totp/mail?secret=XYZ", mail.TOTP)
+	assert.True(t, mail.Favorite)
+	assert.ElementsMatch(t, []Field{
+		{Name: "recovery", Value: "r3c0very", Hidden: true},
+		{Name: "backup email", Value: "bob@example.org"},
+	}, mail.Fields)
+
+	note := items[1]
+	assert.Equal(t, bitwarden.CipherType(bitwarden.SecureNoteType), note.Type)
+	assert.Equal(t, "Secret", note.Name)
+	assert.Equal(t, "Nothing to see", note.Notes)
+}
+
+func TestParseKeePass(t *testing.T) {
+	export := `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+  <Meta><RecycleBinUUID>trash</RecycleBinUUID></Meta>
+  <Root>
+    <Group>
+      <UUID>root</UUID>
+      <Name>Database</Name>
+      <Entry>
+        <String><Key>Title</Key><Value>Forum</Value></String>
+        <String><Key>UserName</Key><Value>alice</Value></String>
+        <String><Key>Password</Key><Value ProtectInMemory="True">secret</Value></String>
+        <String><Key>URL</Key><Value>https://forum.example</Value></String>
+        <String><Key>Security answer</Key><Value ProtectInMemory="True">blue</Value></String>
+        <History>
+          <Entry><String><Key>Password</Key><Value>old</Value></String></Entry>
+        </History>
+      </Entry>
+      <Group>
+        <UUID>trash</UUID>
+        <Name>Recycle Bin</Name>
+        <Entry><String><Key>Title</Key><Value>Deleted</Value></String></Entry>
+      </Group>
+      <Group>
+        <UUID>sub</UUID>
+        <Name>Sub</Name>
+        <Entry><String><Key>Title</Key><Value>Nested</Value></String></Entry>
+      </Group>
+    </Group>
+  </Root>
+</KeePassFile>`
+	items, errs, err := Parse(FormatKeePass, strings.NewReader(export))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Forum", items[0].Name)
+	assert.Equal(t, "alice", items[0].Username)
+	assert.Equal(t, "secret", items[0].Password)
+	assert.Equal(t, []string{"https://forum.example"}, items[0].URLs)
+	assert.Equal(t, []Field{{Name: "Security answer", Value: "blue", Hidden: true}}, items[0].Fields)
+	assert.Equal(t, "Nested", items[1].Name)
+
+	csv := "\"Group\",\"Title\",\"Username\",\"Password\",\"URL\",\"Notes\",\"TOTP\"\n" +
+		"\"Root\",\"Forum\",\"alice\",\"secret\",\"https://forum.example\",\"\",\"\"\n"
+	items, errs, err = Parse(FormatKeePass, strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	require.Len(t, items, 1)
+	assert.Equal(t, "alice", items[0].Username)
+
+	_, _, err = Parse(FormatKeePass, strings.NewReader("<KeePassFile><Root>"))
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestParseLimits(t *testing.T) {
+	_, _, err := Parse("dashlane", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	size := MaxImportSize
+	MaxImportSize = 100
+	defer func() { MaxImportSize = size }()
+	export := "url,username,password,totp,extra,name,grouping,fav\n" +
+		strings.Repeat("https://example.org,alice,secret,,,,,0\n", 10)
+	_, _, err = Parse(FormatLastPass, strings.NewReader(export))
+	assert.ErrorIs(t, err, ErrTooLarge)
+	MaxImportSize = size
+
+	entries := MaxEntries
+	MaxEntries = 5
+	defer func() { MaxEntries = entries }()
+	_, _, err = Parse(FormatLastPass, strings.NewReader(export))
+	assert.ErrorIs(t, err, ErrTooManyEntries)
+}
+
+func TestToCipher(t *testing.T) {
+	orgKey := crypto.GenerateRandomBytes(64)
+	setting := &settings.Settings{OrganizationID: "org", CollectionID: "coll"}
+	item := &Item{
+		Type:     bitwarden.LoginType,
+		Name:     "Example",
+		URLs:     []string{"https://example.org"},
+		Username: "alice",
+		Password: "secret",
+		Fields:   []Field{{Name: "pin", Value: "1234", Hidden: true}},
+	}
+	c, err := item.toCipher(orgKey, setting)
+	require.NoError(t, err)
+	assert.True(t, c.SharedWithCozy)
+	assert.Equal(t, "org", c.OrganizationID)
+	assert.Equal(t, "coll", c.CollectionID)
+	assert.True(t, strings.HasPrefix(c.Name, "2."))
+	assert.NotContains(t, c.Name, "Example")
+	require.NotNil(t, c.Login)
+	assert.True(t, strings.HasPrefix(c.Login.Password, "2."))
+	assert.Empty(t, c.Login.TOTP)
+	require.Len(t, c.Login.URIs, 1)
+	require.Len(t, c.Fields, 1)
+	assert.Equal(t, bitwarden.FieldTypeHidden, c.Fields[0].Type)
+
+	_, err = item.toCipher(orgKey[:32], setting)
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+)
+
+type keePassFile struct {
+	Meta struct {
+		RecycleBinUUID string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+// keePassEntry is an entry of a KeePass XML export. The History of the entry
+// is not decoded, as only the last version is imported.
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Text      string `xml:",chardata"`
+			Protected string `xml:"ProtectInMemory,attr"`
+		} `xml:"Value"`
+	} `xml:"String"`
+}
+
+// parseKeePassXML converts a KeePass XML export (unencrypted). The entries in
+// the recycle bin are ignored.
+func parseKeePassXML(data []byte) ([]*Item, []EntryError, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	var file keePassFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, nil, ErrInvalidFile
+	}
+
+	var items []*Item
+	var walk func(groups []keePassGroup) error
+	walk = func(groups []keePassGroup) error {
+		for _, group := range groups {
+			if file.Meta.RecycleBinUUID != "" && group.UUID == file.Meta.RecycleBinUUID {
+				continue
+			}
+			for _, entry := range group.Entries {
+				if len(items) >= MaxEntries {
+					return ErrTooManyEntries
+				}
+				items = append(items, entry.toItem(len(items)+1))
+			}
+			if err := walk(group.Groups); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(file.Root.Groups); err != nil {
+		return nil, nil, err
+	}
+	return items, nil, nil
+}
+
+func (entry *keePassEntry) toItem(index int) *Item {
+	item := &Item{Index: index, Type: bitwarden.LoginType}
+	for _, s := range entry.Strings {
+		value := s.Value.Text
+		switch s.Key {
+		case "Title":
+			item.Name = value
+		case "UserName":
+			item.Username = value
+		case "Password":
+			item.Password = value
+		case "URL":
+			item.URLs = splitURLs(value)
+		case "Notes":
+			item.Notes = value
+		case "otp", "TOTP Seed":
+			if item.TOTP == "" {
+				item.TOTP = value
+			}
+		default:
+			if value == "" {
+				continue
+			}
+			item.Fields = append(item.Fields, Field{
+				Name:   s.Key,
+				Value:  value,
+				Hidden: strings.EqualFold(s.Value.Protected, "true"),
+			})
+		}
+	}
+	return item
+}
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/cozy/cozy-stack/model/bitwarden"
+)
+
+// The categories of the 1Password items that can be converted to logins. The
+// items of the other categories (credit cards, identities, etc.) are
+// converted to secure notes with additional fields.
+const (
+	onePasswordLogin    = "001"
+	onePasswordNote     = "003"
+	onePasswordPassword = "005"
+)
+
+type onePUXExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Items []onePUXEntry `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+// onePUXEntry is an item of a 1PUX export. The first versions of the format
+// have wrapped the items in an "item" key.
+type onePUXEntry struct {
+	onePUXItem
+	Item *onePUXItem `json:"item"`
+}
+
+type onePUXItem struct {
+	FavIndex     int    `json:"favIndex"`
+	State        string `json:"state"`
+	CategoryUUID string `json:"categoryUuid"`
+	Overview     struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		URLs  []struct {
+			URL string `json:"url"`
+		} `json:"urls"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Name        string `json:"name"`
+			Value       string `json:"value"`
+			FieldType   string `json:"fieldType"`
+			Designation string `json:"designation"`
+		} `json:"loginFields"`
+		NotesPlain string `json:"notesPlain"`
+		Password   string `json:"password"`
+		Sections   []struct {
+			Fields []struct {
+				Title string                     `json:"title"`
+				Value map[string]json.RawMessage `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// parse1PUX converts a 1PUX export of 1Password. It is a zip archive, with
+// the items in the export.data JSON file.
+func parse1PUX(data []byte) ([]*Item, []EntryError, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, ErrInvalidFile
+	}
+	var content []byte
+	for _, f := range zr.File {
+		if f.Name != "export.data" {
+			continue
+		}
+		if f.UncompressedSize64 > uint64(MaxImportSize) {
+			return nil, nil, ErrTooLarge
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, ErrInvalidFile
+		}
+		content, err = io.ReadAll(io.LimitReader(rc, MaxImportSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, nil, ErrInvalidFile
+		}
+		if int64(len(content)) > MaxImportSize {
+			return nil, nil, ErrTooLarge
+		}
+	}
+	if content == nil {
+		return nil, nil, ErrInvalidFile
+	}
+
+	var export onePUXExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return nil, nil, ErrInvalidFile
+	}
+	var items []*Item
+	index := 0
+	for _, account := range export.Accounts {
+		for _, vault := range account.Vaults {
+			for _, entry := range vault.Items {
+				index++
+				if index > MaxEntries {
+					return nil, nil, ErrTooManyEntries
+				}
+				it := &entry.onePUXItem
+				if entry.Item != nil {
+					it = entry.Item
+				}
+				if it.State == "deleted" {
+					continue
+				}
+				items = append(items, it.toItem(index))
+			}
+		}
+	}
+	return items, nil, nil
+}
+
+func (it *onePUXItem) toItem(index int) *Item {
+	item := &Item{
+		Index:    index,
+		Name:     it.Overview.Title,
+		Notes:    it.Details.NotesPlain,
+		Favorite: it.FavIndex > 0,
+	}
+	switch it.CategoryUUID {
+	case onePasswordLogin, onePasswordPassword:
+		item.Type = bitwarden.LoginType
+	default:
+		item.Type = bitwarden.SecureNoteType
+	}
+
+	if item.Type == bitwarden.LoginType {
+		for _, u := range it.Overview.URLs {
+			if u.URL != "" {
+				item.URLs = append(item.URLs, u.URL)
+			}
+		}
+		if len(item.URLs) == 0 && it.Overview.URL != "" {
+			item.URLs = []string{it.Overview.URL}
+		}
+		item.Password = it.Details.Password
+		for _, f := range it.Details.LoginFields {
+			switch {
+			case f.Designation == "username" && item.Username == "":
+				item.Username = f.Value
+			case f.Designation == "password" && item.Password == "":
+				item.Password = f.Value
+			case f.Value != "" && f.Name != "":
+				item.Fields = append(item.Fields, Field{
+					Name:   f.Name,
+					Value:  f.Value,
+					Hidden: f.FieldType == "P",
+				})
+			}
+		}
+	}
+
+	for _, section := range it.Details.Sections {
+		for _, f := range section.Fields {
+			for kind, raw := range f.Value {
+				value := sectionValue(raw)
+				if value == "" {
+					continue
+				}
+				if kind == "totp" && item.TOTP == "" && item.Type == bitwarden.LoginType {
+					item.TOTP = value
+					continue
+				}
+				item.Fields = append(item.Fields, Field{
+					Name:   f.Title,
+					Value:  value,
+					Hidden: kind == "concealed" || kind == "totp",
+				})
+			}
+		}
+	}
+	return item
+}
+
+// sectionValue returns the value of a field of a section, as a string. Most
+// values are strings, but some are objects (like the emails).
+func sectionValue(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	var email struct {
+		Address string `json:"email_address"`
+	}
+	if err := json.Unmarshal(raw, &email); err == nil {
+		return email.Address
+	}
+	return ""
+}
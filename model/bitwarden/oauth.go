@@ -114,8 +114,9 @@ func CreateAccessJWT(i *instance.Instance, c *oauth.Client) (string, error) {
 				ExpiresAt: jwt.NewNumericDate(now.Add(consts.AccessTokenValidityDuration)),
 				Subject:   i.ID(),
 			},
-			SStamp: stamp,
-			Scope:  BitwardenScope,
+			SStamp:     stamp,
+			Scope:      BitwardenScope,
+			Generation: c.TokenGeneration,
 		},
 		ClientID: c.CouchID,
 		Name:     name,
@@ -144,8 +145,9 @@ func CreateRefreshJWT(i *instance.Instance, c *oauth.Client) (string, error) {
 			IssuedAt: jwt.NewNumericDate(time.Now()),
 			Subject:  c.CouchID,
 		},
-		SStamp: stamp,
-		Scope:  BitwardenScope,
+		SStamp:     stamp,
+		Scope:      BitwardenScope,
+		Generation: c.TokenGeneration,
 	})
 	if err != nil {
 		i.Logger().WithNamespace("oauth").
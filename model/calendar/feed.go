@@ -0,0 +1,187 @@
+// Package calendar is for the public ICS feeds of the calendars: a feed has a
+// secret URL that the user can give to another calendar service (Google
+// Calendar, Outlook, etc.) to subscribe to their events, in read-only.
+package calendar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// The privacy levels of a feed.
+const (
+	// PrivacyBusy is used for a feed that only shows the busy time slots,
+	// without the title, the description or the location of the events.
+	PrivacyBusy = "busy"
+	// PrivacyFull is used for a feed with all the details of the events.
+	PrivacyFull = "full"
+)
+
+// tokenLen is the number of characters of the token of a feed.
+const tokenLen = 32
+
+// lastFetchedPrecision is the minimal delay between two updates of the
+// last_fetched_at field, as the calendar services poll the feeds often.
+const lastFetchedPrecision = time.Hour
+
+var (
+	// ErrMissingName is used when a feed is created without name.
+	ErrMissingName = errors.New("the name is missing")
+	// ErrInvalidPrivacy is used for an unknown privacy level.
+	ErrInvalidPrivacy = errors.New("invalid privacy level")
+	// ErrInvalidToken is used when no feed matches the token of the URL.
+	ErrInvalidToken = errors.New("invalid feed token")
+)
+
+// Feed is a public ICS feed. Only a hash of its token is persisted (and used
+// as its identifier): the URL of the feed is shown once to the user, on
+// creation.
+type Feed struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+	Name   string `json:"name"`
+	// CalendarID can be used to restrict the feed to the events of a
+	// calendar. All the events are in the feed if it is empty.
+	CalendarID    string     `json:"calendar_id,omitempty"`
+	Privacy       string     `json:"privacy"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (f *Feed) ID() string { return f.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (f *Feed) Rev() string { return f.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (f *Feed) DocType() string { return consts.CalendarFeeds }
+
+// Clone implements the couchdb.Doc interface
+func (f *Feed) Clone() couchdb.Doc {
+	cloned := *f
+	if f.LastFetchedAt != nil {
+		at := *f.LastFetchedAt
+		cloned.LastFetchedAt = &at
+	}
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (f *Feed) SetID(id string) { f.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (f *Feed) SetRev(rev string) { f.DocRev = rev }
+
+// Relationships implements the jsonapi.Object interface
+func (f *Feed) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Included implements the jsonapi.Object interface
+func (f *Feed) Included() []jsonapi.Object { return nil }
+
+// Links implements the jsonapi.Object interface
+func (f *Feed) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/calendar/feeds/" + f.DocID}
+}
+
+// Validate checks the feed, and fills the default privacy level.
+func (f *Feed) Validate() error {
+	f.Name = strings.TrimSpace(f.Name)
+	if f.Name == "" {
+		return ErrMissingName
+	}
+	switch f.Privacy {
+	case "":
+		f.Privacy = PrivacyBusy
+	case PrivacyBusy, PrivacyFull:
+	default:
+		return ErrInvalidPrivacy
+	}
+	return nil
+}
+
+// URL returns the public URL of the feed, for the given token.
+func URL(inst *instance.Instance, token string) string {
+	return inst.PageURL("/public/calendar/"+token+".ics", nil)
+}
+
+// Create saves a new feed, and returns its token.
+func Create(db prefixer.Prefixer, f *Feed) (string, error) {
+	if err := f.Validate(); err != nil {
+		return "", err
+	}
+	token := crypto.GenerateRandomString(tokenLen)
+	f.DocID = hashToken(token)
+	f.DocRev = ""
+	f.CreatedAt = time.Now().UTC()
+	f.LastFetchedAt = nil
+	if err := couchdb.CreateNamedDocWithDB(db, f); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// List returns all the feeds of the instance.
+func List(db prefixer.Prefixer) ([]*Feed, error) {
+	var feeds []*Feed
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	if err := couchdb.GetAllDocs(db, consts.CalendarFeeds, req, &feeds); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// Get returns the feed with the given ID.
+func Get(db prefixer.Prefixer, id string) (*Feed, error) {
+	f := &Feed{}
+	if err := couchdb.GetDoc(db, consts.CalendarFeeds, id, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Revoke deletes the feed: its URL can no longer be used.
+func Revoke(db prefixer.Prefixer, f *Feed) error {
+	return couchdb.DeleteDoc(db, f)
+}
+
+// FindByToken returns the feed for the token of a public URL, and updates
+// its last_fetched_at field.
+func FindByToken(db prefixer.Prefixer, token string) (*Feed, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	f, err := Get(db, hashToken(token))
+	if err != nil {
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if f.LastFetchedAt == nil || now.Sub(*f.LastFetchedAt) > lastFetchedPrecision {
+		f.LastFetchedAt = &now
+		_ = couchdb.UpdateDoc(db, f)
+	}
+	return f, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ couchdb.Doc = &Feed{}
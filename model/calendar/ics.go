@@ -0,0 +1,192 @@
+package calendar
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// maxEvents is the maximal number of events in a feed.
+const maxEvents = 10000
+
+// pastWindow is how long the events stay in a feed after their end (the
+// recurring events are always kept).
+const pastWindow = 365 * 24 * time.Hour
+
+// maxLineLen is the maximal length of a line in an ICS file, in octets, before
+// it must be folded (RFC 5545, section 3.1).
+const maxLineLen = 75
+
+// dateLayout and dateTimeLayout are the formats of the dates in an ICS file.
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Event is an event of a calendar, as stored in the io.cozy.calendar.events
+// doctype. The start and end are RFC 3339 dates, or just a day (2006-01-02)
+// for the all-day events.
+type Event struct {
+	DocID       string `json:"_id,omitempty"`
+	Summary     string `json:"summary"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Start       string `json:"start"`
+	End         string `json:"end,omitempty"`
+	RRule       string `json:"rrule,omitempty"`
+	CalendarID  string `json:"calendar_id,omitempty"`
+	// UID is the identifier of the event in the ICS file it has been imported
+	// from.
+	UID      string `json:"uid,omitempty"`
+	Metadata *struct {
+		UpdatedAt time.Time `json:"updatedAt"`
+	} `json:"cozyMetadata,omitempty"`
+	// Line is the number of the line where the event starts in the ICS file
+	// it has been parsed from.
+	Line int `json:"-"`
+}
+
+// eventDate is a parsed date of an event.
+type eventDate struct {
+	t      time.Time
+	allDay bool
+}
+
+func parseEventDate(s string) (eventDate, bool) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return eventDate{t: t, allDay: true}, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return eventDate{t: t.UTC()}, true
+	}
+	return eventDate{}, false
+}
+
+func (d eventDate) format() string {
+	if d.allDay {
+		return ";VALUE=DATE:" + d.t.Format(dateLayout)
+	}
+	return ":" + d.t.Format(dateTimeLayout)
+}
+
+// Events returns the events for the feed, sorted by the CouchDB order. The old
+// events are skipped.
+func Events(db prefixer.Prefixer, f *Feed) ([]*Event, error) {
+	var events []*Event
+	limit := time.Now().Add(-pastWindow)
+	err := couchdb.ForeachDocs(db, consts.CalendarEvents, func(_ string, raw json.RawMessage) error {
+		var ev Event
+		if err := json.Unmarshal(raw, &ev); err != nil {
+			return nil
+		}
+		if f.CalendarID != "" && ev.CalendarID != f.CalendarID {
+			return nil
+		}
+		if ev.RRule == "" {
+			end := ev.End
+			if end == "" {
+				end = ev.Start
+			}
+			if d, ok := parseEventDate(end); ok && d.t.Before(limit) {
+				return nil
+			}
+		}
+		if len(events) < maxEvents {
+			events = append(events, &ev)
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return events, nil
+}
+
+// WriteICS writes the events in the iCalendar format (RFC 5545). With the
+// busy privacy level, only the time slots of the events are written.
+func WriteICS(w io.Writer, domain string, f *Feed, events []*Event) error {
+	bw := bufio.NewWriter(w)
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:-//Cozy Cloud//Cozy Stack//EN")
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	writeLine(bw, "X-WR-CALNAME:"+escapeText(f.Name))
+
+	now := time.Now().UTC().Format(dateTimeLayout)
+	for _, ev := range events {
+		start, ok := parseEventDate(ev.Start)
+		if !ok {
+			continue
+		}
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escapeText(ev.DocID+"@"+domain))
+		stamp := now
+		if ev.Metadata != nil && !ev.Metadata.UpdatedAt.IsZero() {
+			stamp = ev.Metadata.UpdatedAt.UTC().Format(dateTimeLayout)
+		}
+		writeLine(bw, "DTSTAMP:"+stamp)
+		writeLine(bw, "DTSTART"+start.format())
+		if end, ok := parseEventDate(ev.End); ok {
+			writeLine(bw, "DTEND"+end.format())
+		}
+		if ev.RRule != "" {
+			writeLine(bw, "RRULE:"+strings.TrimPrefix(ev.RRule, "RRULE:"))
+		}
+		if f.Privacy == PrivacyFull {
+			writeLine(bw, "SUMMARY:"+escapeText(ev.Summary))
+			if ev.Description != "" {
+				writeLine(bw, "DESCRIPTION:"+escapeText(ev.Description))
+			}
+			if ev.Location != "" {
+				writeLine(bw, "LOCATION:"+escapeText(ev.Location))
+			}
+		} else {
+			writeLine(bw, "SUMMARY:Busy")
+			writeLine(bw, "CLASS:PRIVATE")
+		}
+		writeLine(bw, "TRANSP:OPAQUE")
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// escapeText escapes a TEXT value (RFC 5545, section 3.3.11).
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeLine writes a content line, folded at 75 octets without splitting a
+// UTF-8 character, and terminated by CRLF.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLen
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		_, _ = w.WriteString(line[:cut])
+		_, _ = w.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts in its length
+		limit = maxLineLen - 1
+	}
+	_, _ = w.WriteString(line)
+	_, _ = w.WriteString("\r\n")
+}
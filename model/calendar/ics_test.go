@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteICS(t *testing.T) {
+	events := []*Event{
+		{
+			DocID:       "event1",
+			Summary:     "Lunch, with Bob; at noon",
+			Description: "Don't forget\nthe cake",
+			Start:       "2024-03-01T12:00:00+01:00",
+			End:         "2024-03-01T13:00:00+01:00",
+		},
+		{
+			DocID:   "event2",
+			Summary: "Holidays",
+			Start:   "2024-08-01",
+			End:     "2024-08-15",
+			RRule:   "FREQ=YEARLY",
+		},
+	}
+
+	t.Run("Full", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		f := &Feed{Name: "Personal", Privacy: PrivacyFull}
+		require.NoError(t, WriteICS(buf, "alice.example.net", f, events))
+		ics := buf.String()
+		assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+		assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+		assert.Contains(t, ics, "UID:event1@alice.example.net\r\n")
+		assert.Contains(t, ics, "DTSTART:20240301T110000Z\r\n")
+		assert.Contains(t, ics, `SUMMARY:Lunch\, with Bob\; at noon`+"\r\n")
+		assert.Contains(t, ics, `DESCRIPTION:Don't forget\nthe cake`+"\r\n")
+		assert.Contains(t, ics, "DTSTART;VALUE=DATE:20240801\r\n")
+		assert.Contains(t, ics, "RRULE:FREQ=YEARLY\r\n")
+	})
+
+	t.Run("Busy", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		f := &Feed{Name: "Personal", Privacy: PrivacyBusy}
+		require.NoError(t, WriteICS(buf, "alice.example.net", f, events))
+		ics := buf.String()
+		assert.Contains(t, ics, "SUMMARY:Busy\r\n")
+		assert.NotContains(t, ics, "Lunch")
+		assert.NotContains(t, ics, "DESCRIPTION")
+	})
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &Feed{Name: strings.Repeat("é", 60), Privacy: PrivacyBusy}
+	require.NoError(t, WriteICS(buf, "alice.example.net", f, nil))
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLen)
+	}
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	assert.Contains(t, unfolded, "X-WR-CALNAME:"+strings.Repeat("é", 60))
+}
+
+func TestParseICS(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc@example.net\r\n" +
+		`SUMMARY:Lunch\, with Bob` + "\r\n" +
+		"DESCRIPTION:Don't forget\\n the cake\r\n" +
+		"DTSTART;TZID=Europe/Paris:20240301T120000\r\n" +
+		"DTEND:20240301T120000Z\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"DESCRIPTION:Reminder\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Holidays\r\n" +
+		"DTSTART;VALUE=DATE:20240801\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Broken\r\n" +
+		"DTSTART:tomorrow\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := ParseICS(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "abc@example.net", events[0].UID)
+	assert.Equal(t, "Lunch, with Bob", events[0].Summary)
+	assert.Equal(t, "Don't forget\n the cake", events[0].Description)
+	assert.Equal(t, "2024-03-01T12:00:00+01:00", events[0].Start)
+	assert.Equal(t, "2024-03-01T12:00:00Z", events[0].End)
+	assert.Equal(t, 3, events[0].Line)
+
+	assert.Equal(t, "2024-08-01", events[1].Start)
+	assert.Equal(t, "FREQ=YEARLY", events[1].RRule)
+
+	assert.Equal(t, "", events[2].Start)
+
+	_, err = ParseICS(strings.NewReader("BEGIN:VCARD\r\nEND:VCARD\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidICS)
+}
@@ -0,0 +1,93 @@
+package calendar
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/contentline"
+)
+
+// ErrInvalidICS is used when a file is not in the iCalendar format.
+var ErrInvalidICS = errors.New("the file is not a valid iCalendar file")
+
+// ParseICS reads the events of an iCalendar file (RFC 5545). The dates with a
+// time zone are converted to RFC 3339 dates with an offset, and the floating
+// dates are considered to be in UTC. The start of an event is left empty if
+// its date cannot be parsed.
+func ParseICS(r io.Reader) ([]*Event, error) {
+	lines, err := contentline.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || lines[0].Name != "BEGIN" || !strings.EqualFold(lines[0].Value, "VCALENDAR") {
+		return nil, ErrInvalidICS
+	}
+
+	var events []*Event
+	var current *Event
+	// depth is used to skip the components inside an event, like VALARM
+	depth := 0
+	for _, line := range lines {
+		switch {
+		case line.Name == "BEGIN" && strings.EqualFold(line.Value, "VEVENT") && current == nil:
+			current = &Event{Line: line.Number}
+		case current == nil:
+		case line.Name == "BEGIN":
+			depth++
+		case line.Name == "END" && depth > 0:
+			depth--
+		case line.Name == "END" && strings.EqualFold(line.Value, "VEVENT"):
+			events = append(events, current)
+			current = nil
+		case depth > 0:
+		case line.Name == "UID":
+			current.UID = line.Value
+		case line.Name == "SUMMARY":
+			current.Summary = contentline.Unescape(line.Value)
+		case line.Name == "DESCRIPTION":
+			current.Description = contentline.Unescape(line.Value)
+		case line.Name == "LOCATION":
+			current.Location = contentline.Unescape(line.Value)
+		case line.Name == "DTSTART":
+			current.Start = parseICSDate(line)
+		case line.Name == "DTEND":
+			current.End = parseICSDate(line)
+		case line.Name == "RRULE":
+			current.RRule = line.Value
+		}
+	}
+	return events, nil
+}
+
+// parseICSDate returns the date of the line in the format of the
+// io.cozy.calendar.events doctype, or an empty string if it is invalid.
+func parseICSDate(line *contentline.Line) string {
+	value := line.Value
+	if line.Param("VALUE") == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeLayout, value)
+		if err != nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	loc := time.UTC
+	if tzid := line.Param("TZID"); tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(strings.TrimSuffix(dateTimeLayout, "Z"), value, loc)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
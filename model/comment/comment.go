@@ -0,0 +1,362 @@
+// Package comment is for the comments that the users can write on any
+// document or file, like a review on a note or a remark on a photo. The
+// comments can be threaded, and the owner of the instance is notified when
+// they are mentioned.
+package comment
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/contact"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+)
+
+// MaxContentLength is the maximal length of the content of a comment.
+const MaxContentLength = 10000
+
+// MaxMentions is the maximal number of mentions in a comment.
+const MaxMentions = 50
+
+var (
+	// ErrMissingContent is used when a comment has no content.
+	ErrMissingContent = errors.New("The comment has no content")
+	// ErrContentTooLong is used when the content of a comment is too long.
+	ErrContentTooLong = errors.New("The content of the comment is too long")
+	// ErrInvalidTarget is used when a comment is not attached to a document.
+	ErrInvalidTarget = errors.New("The comment must be attached to a document")
+	// ErrTooManyMentions is used when a comment has too many mentions.
+	ErrTooManyMentions = errors.New("The comment has too many mentions")
+	// ErrInvalidParent is used when the parent of a reply is not a comment
+	// on the same document.
+	ErrInvalidParent = errors.New("The parent comment is invalid")
+	// ErrResolveReply is used when a reply is marked as resolved, as only the
+	// threads can be resolved.
+	ErrResolveReply = errors.New("Only the first comment of a thread can be resolved")
+)
+
+// Target is the document on which a comment has been written.
+type Target struct {
+	DocType string `json:"doctype"`
+	ID      string `json:"id"`
+}
+
+// Author is the person who has written a comment.
+type Author struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Owner is true when the comment has been written by the owner of the
+	// instance (and not via a sharing link).
+	Owner bool `json:"owner,omitempty"`
+}
+
+// Mention is a person mentioned in a comment.
+type Mention struct {
+	ContactID string `json:"contact_id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Comment is a message attached to a document or a file.
+type Comment struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+
+	Target Target `json:"target"`
+	// ParentID is the identifier of the first comment of the thread, for a
+	// reply.
+	ParentID string    `json:"parent_id,omitempty"`
+	Author   Author    `json:"author"`
+	Content  string    `json:"content"`
+	Mentions []Mention `json:"mentions,omitempty"`
+	// Resolved can be set on the first comment of a thread to close it.
+	Resolved bool `json:"resolved,omitempty"`
+
+	// SourceID is the identifier of the app or of the sharing link that has
+	// created the comment.
+	SourceID  string     `json:"source_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (c *Comment) ID() string { return c.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (c *Comment) Rev() string { return c.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (c *Comment) DocType() string { return consts.Comments }
+
+// SetID is used to implement the couchdb.Doc interface
+func (c *Comment) SetID(id string) { c.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (c *Comment) SetRev(rev string) { c.DocRev = rev }
+
+// Clone implements couchdb.Doc
+func (c *Comment) Clone() couchdb.Doc {
+	cloned := *c
+	cloned.Mentions = make([]Mention, len(c.Mentions))
+	copy(cloned.Mentions, c.Mentions)
+	if c.EditedAt != nil {
+		at := *c.EditedAt
+		cloned.EditedAt = &at
+	}
+	return &cloned
+}
+
+// Patch contains the changes for a comment. The nil fields are left
+// unchanged.
+type Patch struct {
+	Content  *string
+	Mentions *[]Mention
+	Resolved *bool
+}
+
+func (c *Comment) validate() error {
+	if c.Target.DocType == "" || c.Target.ID == "" {
+		return ErrInvalidTarget
+	}
+	if strings.TrimSpace(c.Content) == "" {
+		return ErrMissingContent
+	}
+	if len(c.Content) > MaxContentLength {
+		return ErrContentTooLong
+	}
+	if len(c.Mentions) > MaxMentions {
+		return ErrTooManyMentions
+	}
+	return nil
+}
+
+// Get returns the comment with the given ID.
+func Get(inst *instance.Instance, id string) (*Comment, error) {
+	c := &Comment{}
+	if err := couchdb.GetDoc(inst, consts.Comments, id, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// List returns a page of the comments on the given document, from the oldest
+// to the most recent. The bookmark can be used to fetch the next page.
+func List(inst *instance.Instance, target Target, limit int, bookmark string) ([]*Comment, string, error) {
+	req := &couchdb.FindRequest{
+		UseIndex: "by-target",
+		Selector: mango.And(
+			mango.Equal("target.doctype", target.DocType),
+			mango.Equal("target.id", target.ID),
+			mango.Gt("created_at", ""),
+		),
+		Sort: mango.SortBy{
+			{Field: "target.doctype", Direction: mango.Asc},
+			{Field: "target.id", Direction: mango.Asc},
+			{Field: "created_at", Direction: mango.Asc},
+		},
+		Bookmark: bookmark,
+		Limit:    limit,
+	}
+	var comments []*Comment
+	res, err := couchdb.FindDocsRaw(inst, consts.Comments, req, &comments)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return comments, res.Bookmark, nil
+}
+
+// Create checks the comment, saves it, and notifies the owner of the instance
+// if they are mentioned. A reply to a reply is attached to the first comment
+// of the thread.
+func Create(inst *instance.Instance, c *Comment) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	if c.ParentID != "" {
+		parent, err := Get(inst, c.ParentID)
+		if err != nil {
+			if couchdb.IsNotFoundError(err) {
+				return ErrInvalidParent
+			}
+			return err
+		}
+		if parent.Target != c.Target {
+			return ErrInvalidParent
+		}
+		if parent.ParentID != "" {
+			c.ParentID = parent.ParentID
+		}
+	}
+	now := time.Now().UTC()
+	c.DocID = ""
+	c.DocRev = ""
+	c.Resolved = false
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	c.EditedAt = nil
+	if err := couchdb.CreateDoc(inst, c); err != nil {
+		return err
+	}
+	notifyMentions(inst, c, c.Mentions)
+	return nil
+}
+
+// Update applies the patch to the comment. Only the persons who have been
+// added to the mentions are notified.
+func Update(inst *instance.Instance, c *Comment, patch *Patch) error {
+	var added []Mention
+	now := time.Now().UTC()
+	if patch.Content != nil && *patch.Content != c.Content {
+		c.Content = *patch.Content
+		c.EditedAt = &now
+	}
+	if patch.Mentions != nil {
+		added = newMentions(c.Mentions, *patch.Mentions)
+		c.Mentions = *patch.Mentions
+	}
+	if patch.Resolved != nil {
+		if c.ParentID != "" && *patch.Resolved {
+			return ErrResolveReply
+		}
+		c.Resolved = *patch.Resolved
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	c.UpdatedAt = now
+	if err := couchdb.UpdateDoc(inst, c); err != nil {
+		return err
+	}
+	notifyMentions(inst, c, added)
+	return nil
+}
+
+// Delete removes the comment. For the first comment of a thread, the replies
+// are also deleted.
+func Delete(inst *instance.Instance, c *Comment) error {
+	if c.ParentID == "" {
+		req := &couchdb.FindRequest{
+			UseIndex: "by-parent-id",
+			Selector: mango.Equal("parent_id", c.DocID),
+			Limit:    consts.MaxItemsPerPageForMango,
+		}
+		for {
+			var replies []*Comment
+			if err := couchdb.FindDocs(inst, consts.Comments, req, &replies); err != nil {
+				return err
+			}
+			docs := make([]couchdb.Doc, len(replies))
+			for i, r := range replies {
+				docs[i] = r
+			}
+			if err := couchdb.BulkDeleteDocs(inst, consts.Comments, docs); err != nil {
+				return err
+			}
+			if len(replies) < req.Limit {
+				break
+			}
+		}
+	}
+	return couchdb.DeleteDoc(inst, c)
+}
+
+// newMentions returns the mentions that are in after but not in before.
+func newMentions(before, after []Mention) []Mention {
+	var added []Mention
+	for _, m := range after {
+		found := false
+		for _, b := range before {
+			if m == b {
+				found = true
+				break
+			}
+		}
+		if !found {
+			added = append(added, m)
+		}
+	}
+	return added
+}
+
+// isOwner returns true if the mention is for the owner of the instance.
+func isOwner(inst *instance.Instance, m Mention) bool {
+	if m.ContactID != "" {
+		if myself, err := contact.GetMyself(inst); err == nil && myself.ID() == m.ContactID {
+			return true
+		}
+	}
+	if m.Email != "" {
+		if email, err := inst.SettingsEMail(); err == nil && strings.EqualFold(email, m.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyMentions sends a notification to the owner of the instance if they
+// are mentioned by someone else. The other persons are not notified by the
+// stack: they can see the comment via a sharing.
+func notifyMentions(inst *instance.Instance, c *Comment, mentions []Mention) {
+	if c.Author.Owner {
+		return
+	}
+	for _, m := range mentions {
+		if !isOwner(inst, m) {
+			continue
+		}
+		if err := notify(inst, c); err != nil {
+			inst.Logger().WithNamespace("comments").
+				Warnf("Cannot notify the mention in %s: %s", c.DocID, err)
+		}
+		return
+	}
+}
+
+func notify(inst *instance.Instance, c *Comment) error {
+	author := c.Author.Name
+	if author == "" {
+		author = inst.Translate("Notifications Comment Mention Anonymous")
+	}
+	title := inst.Translate("Notifications Comment Mention Title", author)
+	n := &notification.Notification{
+		Title:       title,
+		Message:     c.Content,
+		Slug:        slugForTarget(c.Target),
+		CategoryID:  c.DocID,
+		Content:     fmt.Sprintf("%s\n\n%s\n", title, c.Content),
+		ContentHTML: fmt.Sprintf("<p>%s</p><p>%s</p>", html.EscapeString(title), html.EscapeString(c.Content)),
+		Data: map[string]interface{}{
+			"doctype":    c.Target.DocType,
+			"id":         c.Target.ID,
+			"comment_id": c.DocID,
+		},
+		PreferredChannels: []string{"mobile"},
+	}
+	return center.PushStack(inst.DomainName(), center.NotificationCommentMention, n)
+}
+
+// slugForTarget returns the slug of the app where the commented document can
+// be opened.
+func slugForTarget(target Target) string {
+	switch target.DocType {
+	case consts.Files:
+		return consts.DriveSlug
+	case consts.PhotosAlbums:
+		return consts.PhotosSlug
+	}
+	return ""
+}
+
+var _ couchdb.Doc = &Comment{}
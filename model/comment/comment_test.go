@@ -0,0 +1,40 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	c := &Comment{
+		Target:  Target{DocType: "io.cozy.files", ID: "123"},
+		Content: "Nice picture!",
+	}
+	assert.NoError(t, c.validate())
+
+	c.Content = "  \n"
+	assert.ErrorIs(t, c.validate(), ErrMissingContent)
+
+	c.Content = strings.Repeat("a", MaxContentLength+1)
+	assert.ErrorIs(t, c.validate(), ErrContentTooLong)
+
+	c.Content = "Nice picture!"
+	c.Mentions = make([]Mention, MaxMentions+1)
+	assert.ErrorIs(t, c.validate(), ErrTooManyMentions)
+
+	c.Mentions = nil
+	c.Target.ID = ""
+	assert.ErrorIs(t, c.validate(), ErrInvalidTarget)
+}
+
+func TestNewMentions(t *testing.T) {
+	alice := Mention{ContactID: "alice", Name: "Alice"}
+	bob := Mention{Email: "bob@example.net", Name: "Bob"}
+	carol := Mention{ContactID: "carol", Name: "Carol"}
+
+	assert.Equal(t, []Mention{alice, bob}, newMentions(nil, []Mention{alice, bob}))
+	assert.Equal(t, []Mention{carol}, newMentions([]Mention{alice, bob}, []Mention{bob, carol}))
+	assert.Empty(t, newMentions([]Mention{alice, bob}, []Mention{alice}))
+}
@@ -8,4 +8,6 @@ var (
 	ErrNoMailAddress = errors.New("The contact has no email address")
 	// ErrNotFound is returned when no contact has been found for a query
 	ErrNotFound = errors.New("No contact has been found")
+	// ErrInvalidVCard is returned when a file is not in the vCard format
+	ErrInvalidVCard = errors.New("The file is not a valid vCard file")
 )
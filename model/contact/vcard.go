@@ -0,0 +1,152 @@
+package contact
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/contentline"
+)
+
+// Card is a contact read from a vCard file.
+type Card struct {
+	Contact *Contact
+	// Line is the number of the line where the card starts in the file.
+	Line int
+}
+
+// ParseVCard reads the contacts of a vCard file (RFC 6350, or the older 2.1
+// and 3.0 versions), and converts them to the format of the io.cozy.contacts
+// doctype.
+func ParseVCard(r io.Reader) ([]*Card, error) {
+	lines, err := contentline.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || lines[0].Name != "BEGIN" || !strings.EqualFold(lines[0].Value, "VCARD") {
+		return nil, ErrInvalidVCard
+	}
+
+	var cards []*Card
+	var current *Card
+	for _, line := range lines {
+		switch {
+		case line.Name == "BEGIN" && strings.EqualFold(line.Value, "VCARD"):
+			current = &Card{Contact: New(), Line: line.Number}
+		case current == nil:
+		case line.Name == "END" && strings.EqualFold(line.Value, "VCARD"):
+			if _, ok := current.Contact.M["fullname"]; !ok {
+				if name := current.Contact.PrimaryName(); name != "" {
+					current.Contact.M["fullname"] = name
+				}
+			}
+			cards = append(cards, current)
+			current = nil
+		default:
+			addVCardProperty(current.Contact, line)
+		}
+	}
+	return cards, nil
+}
+
+func addVCardProperty(c *Contact, line *contentline.Line) {
+	m := c.M
+	switch line.Name {
+	case "FN":
+		if fullname := contentline.Unescape(line.Value); fullname != "" {
+			m["fullname"] = fullname
+		}
+	case "N":
+		parts := contentline.SplitValue(line.Value, ';')
+		name := make(map[string]interface{})
+		for i, key := range []string{"familyName", "givenName", "additionalName", "namePrefix", "nameSuffix"} {
+			if i < len(parts) && parts[i] != "" {
+				name[key] = parts[i]
+			}
+		}
+		if len(name) > 0 {
+			m["name"] = name
+		}
+	case "EMAIL":
+		if address := strings.TrimSpace(line.Value); address != "" {
+			appendItem(m, "email", vcardItem(line, "address", address))
+		}
+	case "TEL":
+		number := strings.TrimPrefix(strings.TrimSpace(line.Value), "tel:")
+		if number != "" {
+			appendItem(m, "phone", vcardItem(line, "number", number))
+		}
+	case "ADR":
+		parts := contentline.SplitValue(line.Value, ';')
+		fields := make(map[string]interface{})
+		for i, key := range []string{"pobox", "", "street", "city", "region", "postcode", "country"} {
+			if key != "" && i < len(parts) && parts[i] != "" {
+				fields[key] = parts[i]
+			}
+		}
+		// The extended address (like the apartment number) is kept with
+		// the street
+		if len(parts) > 1 && parts[1] != "" {
+			if street, ok := fields["street"].(string); ok {
+				fields["street"] = street + ", " + parts[1]
+			} else {
+				fields["street"] = parts[1]
+			}
+		}
+		if len(fields) > 0 {
+			addr := vcardItem(line, "", "")
+			for k, v := range fields {
+				addr[k] = v
+			}
+			appendItem(m, "address", addr)
+		}
+	case "ORG":
+		if org := contentline.SplitValue(line.Value, ';')[0]; org != "" {
+			m["company"] = org
+		}
+	case "TITLE":
+		m["jobTitle"] = contentline.Unescape(line.Value)
+	case "NOTE":
+		m["note"] = contentline.Unescape(line.Value)
+	case "BDAY":
+		for _, layout := range []string{"2006-01-02", "20060102"} {
+			if t, err := time.Parse(layout, line.Value); err == nil {
+				m["birthday"] = t.Format("2006-01-02")
+				break
+			}
+		}
+	}
+}
+
+// vcardItem returns an item for a property with a type (email, phone,
+// address), with the type and the preference of the line.
+func vcardItem(line *contentline.Line, key, value string) map[string]interface{} {
+	item := make(map[string]interface{})
+	if key != "" {
+		item[key] = value
+	}
+	var types []string
+	for _, typ := range strings.Split(strings.ToLower(line.Param("TYPE")), ",") {
+		switch typ {
+		case "":
+		case "pref":
+			item["primary"] = true
+		case "internet", "voice":
+			// These types don't bring useful information
+		default:
+			types = append(types, typ)
+		}
+	}
+	if line.Param("PREF") == "1" {
+		item["primary"] = true
+	}
+	if len(types) > 0 {
+		item["type"] = strings.Join(types, ",")
+	}
+	return item
+}
+
+func appendItem(m map[string]interface{}, key string, item map[string]interface{}) {
+	items, _ := m[key].([]interface{})
+	m[key] = append(items, item)
+}
@@ -0,0 +1,66 @@
+package contact
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVCard(t *testing.T) {
+	input := "BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"N:Doe;John;;Dr.;\r\n" +
+		"FN:Dr. John Doe\r\n" +
+		"ORG:Example Corp;Sales\r\n" +
+		"EMAIL;TYPE=INTERNET,WORK,pref:john@example.com\r\n" +
+		"item1.EMAIL;TYPE=HOME:john@home.example\r\n" +
+		"TEL;TYPE=CELL:+33 6 12 34 56 78\r\n" +
+		"ADR;TYPE=HOME:;Apt 2;1 rue de la Paix;Paris;;75002;France\r\n" +
+		"BDAY:19800412\r\n" +
+		`NOTE:Met at the conference\, in 2019` + "\r\n" +
+		"END:VCARD\r\n" +
+		"BEGIN:VCARD\r\n" +
+		"VERSION:4.0\r\n" +
+		"N:Martin;Alice;;;\r\n" +
+		"END:VCARD\r\n"
+	cards, err := ParseVCard(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+
+	john := cards[0].Contact
+	assert.Equal(t, 1, cards[0].Line)
+	assert.Equal(t, "Dr. John Doe", john.Get("fullname"))
+	assert.Equal(t, map[string]interface{}{
+		"familyName": "Doe",
+		"givenName":  "John",
+		"namePrefix": "Dr.",
+	}, john.Get("name"))
+	assert.Equal(t, "Example Corp", john.Get("company"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"address": "john@example.com", "type": "work", "primary": true},
+		map[string]interface{}{"address": "john@home.example", "type": "home"},
+	}, john.Get("email"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"number": "+33 6 12 34 56 78", "type": "cell"},
+	}, john.Get("phone"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"street":   "1 rue de la Paix, Apt 2",
+			"city":     "Paris",
+			"postcode": "75002",
+			"country":  "France",
+			"type":     "home",
+		},
+	}, john.Get("address"))
+	assert.Equal(t, "1980-04-12", john.Get("birthday"))
+	assert.Equal(t, "Met at the conference, in 2019", john.Get("note"))
+
+	alice := cards[1].Contact
+	assert.Equal(t, 13, cards[1].Line)
+	assert.Equal(t, "Alice Martin", alice.Get("fullname"))
+
+	_, err = ParseVCard(strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidVCard)
+}
@@ -0,0 +1,201 @@
+package dataimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The types that can be used in the mapping of a CSV column.
+const (
+	typeString = "string"
+	typeNumber = "number"
+	typeBool   = "bool"
+)
+
+// maxArrayIndex is the limit for the indexes of the arrays in the paths.
+const maxArrayIndex = 100
+
+// column is where the value of a CSV column goes in the documents.
+type column struct {
+	path []string
+	kind string
+}
+
+// parseMapping checks the mapping of the CSV columns, and returns the path and
+// type of each column.
+func parseMapping(mapping map[string]string) (map[string]column, error) {
+	if len(mapping) == 0 {
+		return nil, ErrInvalidMapping
+	}
+	columns := make(map[string]column, len(mapping))
+	for name, target := range mapping {
+		kind := typeString
+		if idx := strings.LastIndexByte(target, ':'); idx >= 0 {
+			kind = target[idx+1:]
+			target = target[:idx]
+		}
+		if kind != typeString && kind != typeNumber && kind != typeBool {
+			return nil, fmt.Errorf("%w: unknown type %q for %q", ErrInvalidMapping, kind, name)
+		}
+		path := strings.Split(target, ".")
+		for i, part := range path {
+			idx, err := strconv.Atoi(part)
+			isIndex := err == nil
+			switch {
+			case part == "",
+				i == 0 && (isIndex || strings.HasPrefix(part, "_")),
+				isIndex && (idx < 0 || idx >= maxArrayIndex):
+				return nil, fmt.Errorf("%w: invalid path for %q", ErrInvalidMapping, name)
+			}
+		}
+		columns[name] = column{path: path, kind: kind}
+	}
+	return columns, nil
+}
+
+func (o *Options) separator() (rune, error) {
+	if o.Separator == "" {
+		return ',', nil
+	}
+	r, size := utf8.DecodeRuneInString(o.Separator)
+	if size != len(o.Separator) || r == '"' || r == '\r' || r == '\n' {
+		return 0, ErrInvalidSeparator
+	}
+	return r, nil
+}
+
+// parseCSV reads the records of a CSV file. The first line is the header with
+// the names of the columns.
+func parseCSV(opts *Options, content io.Reader) ([]*record, error) {
+	columns, err := parseMapping(opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	sep, err := opts.separator()
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(content)
+	reader.Comma = sep
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	found := false
+	for _, name := range header {
+		if _, ok := columns[name]; ok {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: no column of the file is in the mapping", ErrInvalidMapping)
+	}
+
+	var records []*record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) >= MaxRecords {
+			return nil, ErrTooManyRecords
+		}
+		line, _ := reader.FieldPos(0)
+		doc := make(map[string]interface{})
+		var convErr error
+		for i, value := range fields {
+			if i >= len(header) {
+				break
+			}
+			col, ok := columns[header[i]]
+			value = strings.TrimSpace(value)
+			if !ok || value == "" {
+				continue
+			}
+			v, err := convert(value, col.kind)
+			if err != nil && convErr == nil {
+				convErr = fmt.Errorf("invalid value for %q: %s", header[i], err)
+			}
+			setPath(doc, col.path, v)
+		}
+		records = append(records, &record{line: line, doc: doc, err: convErr})
+	}
+	return records, nil
+}
+
+func convert(value, kind string) (interface{}, error) {
+	switch kind {
+	case typeNumber:
+		// Accept the decimal comma used in many locales
+		return strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	case typeBool:
+		switch strings.ToLower(value) {
+		case "true", "yes", "1", "y", "x":
+			return true, nil
+		case "false", "no", "0", "n":
+			return false, nil
+		}
+		return nil, errors.New("not a boolean")
+	}
+	return value, nil
+}
+
+// setPath sets the value at the given path in the container, and returns the
+// container (that is created if nil). A numeric part in the path is an index
+// in an array.
+func setPath(container interface{}, path []string, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	if idx, err := strconv.Atoi(path[0]); err == nil {
+		arr, _ := container.([]interface{})
+		for len(arr) <= idx {
+			arr = append(arr, nil)
+		}
+		arr[idx] = setPath(arr[idx], path[1:], value)
+		return arr
+	}
+	m, ok := container.(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+	}
+	m[path[0]] = setPath(m[path[0]], path[1:], value)
+	return m
+}
+
+// getPath returns the value at the given path in the document, or nil.
+func getPath(doc map[string]interface{}, path []string) interface{} {
+	var current interface{} = doc
+	for _, part := range path {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			current = c[part]
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return nil
+			}
+			current = c[idx]
+		default:
+			return nil
+		}
+	}
+	return current
+}
@@ -0,0 +1,453 @@
+// Package dataimport is for importing documents from files in the CSV, ICS
+// (iCalendar) and vCard formats into the doctypes of an instance. The file is
+// parsed, each record is validated and deduplicated against the existing
+// documents, and the documents are created in bulk by a job. A dry-run gives a
+// preview of what would be imported, without writing anything.
+package dataimport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/calendar"
+	"github.com/cozy/cozy-stack/model/contact"
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+)
+
+// WorkerType is the type of the worker that imports the documents.
+const WorkerType = "data-import"
+
+// The supported formats.
+const (
+	FormatCSV   = "csv"
+	FormatICS   = "ics"
+	FormatVCard = "vcard"
+)
+
+// The deduplication modes, for the records that match an existing document
+// (or a previous record of the same file).
+const (
+	// DedupNone creates a document for each record.
+	DedupNone = "none"
+	// DedupSkip ignores the records that match an existing document.
+	DedupSkip = "skip"
+	// DedupUpdate updates the existing document with the fields of the
+	// record.
+	DedupUpdate = "update"
+)
+
+// MaxRecords is the maximal number of records in an imported file.
+const MaxRecords = 10000
+
+// MaxFileSize is the maximal size in bytes of an imported file.
+const MaxFileSize = 20 << 20
+
+const (
+	// maxErrors is the maximal number of errors kept in a report.
+	maxErrors = 50
+	// previewSize is the number of documents in the preview of a dry-run.
+	previewSize = 10
+	// batchSize is the number of documents written in a bulk request.
+	batchSize = 500
+)
+
+var (
+	// ErrInvalidFormat is used when the format is not supported.
+	ErrInvalidFormat = errors.New("The format is not supported")
+	// ErrInvalidDoctype is used when the doctype cannot be used with the
+	// format.
+	ErrInvalidDoctype = errors.New("The doctype cannot be used with this format")
+	// ErrInvalidMapping is used when the mapping of the columns of a CSV file
+	// is missing or invalid.
+	ErrInvalidMapping = errors.New("The mapping of the columns is invalid")
+	// ErrInvalidSeparator is used when the separator for a CSV file is not a
+	// single character that can be used as a separator.
+	ErrInvalidSeparator = errors.New("The separator is invalid")
+	// ErrInvalidDedup is used when the deduplication mode is unknown, or
+	// when no field is given to compare the documents.
+	ErrInvalidDedup = errors.New("The deduplication options are invalid")
+	// ErrTooManyRecords is used when the file has more than MaxRecords
+	// records.
+	ErrTooManyRecords = fmt.Errorf("The file has more than %d records", MaxRecords)
+	// ErrFileTooLarge is used when the file is larger than MaxFileSize.
+	ErrFileTooLarge = errors.New("The file is too large to be imported")
+	// ErrNotAFile is used when the identifier is not the one of a file.
+	ErrNotAFile = errors.New("The identifier is not the one of a file")
+)
+
+// Options are the parameters of an import.
+type Options struct {
+	FileID string `json:"file_id"`
+	Format string `json:"format"`
+	// Doctype is the doctype of the created documents. It is implied for the
+	// ICS (io.cozy.calendar.events) and vCard (io.cozy.contacts) formats.
+	Doctype string `json:"doctype"`
+	// Mapping is used for the CSV files: the keys are the names of the
+	// columns, and the values are the paths of the fields in the documents
+	// (like name.givenName or email.0.address), with an optional type
+	// (amount:number). The columns that are not in the mapping are ignored.
+	Mapping map[string]string `json:"mapping,omitempty"`
+	// Separator is the separator of the fields of a CSV file (a comma by
+	// default).
+	Separator string `json:"separator,omitempty"`
+	// Dedup is the deduplication mode (none by default).
+	Dedup string `json:"dedup,omitempty"`
+	// DedupFields are the paths of the fields used to compare a record with
+	// the existing documents. There are defaults for the ICS (uid) and vCard
+	// (fullname) formats.
+	DedupFields []string `json:"dedup_fields,omitempty"`
+	DryRun      bool     `json:"dry_run,omitempty"`
+}
+
+// RecordError is an error for a record of the imported file.
+type RecordError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Report is the result of an import (or of a dry-run).
+type Report struct {
+	Total   int           `json:"total"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Invalid int           `json:"invalid"`
+	Errors  []RecordError `json:"errors,omitempty"`
+	// Preview are the first documents that would be created or updated, for
+	// a dry-run.
+	Preview []map[string]interface{} `json:"preview,omitempty"`
+	DryRun  bool                     `json:"dry_run,omitempty"`
+}
+
+// record is a document read from the imported file.
+type record struct {
+	line int
+	doc  map[string]interface{}
+	// err is set when a value of the record cannot be read
+	err error
+}
+
+// Check validates the options, and fills the defaults.
+func (o *Options) Check() error {
+	if o.FileID == "" {
+		return ErrNotAFile
+	}
+	switch o.Format {
+	case FormatCSV:
+		if o.Doctype == "" || o.Doctype == consts.Files {
+			return ErrInvalidDoctype
+		}
+		if _, err := parseMapping(o.Mapping); err != nil {
+			return err
+		}
+		if _, err := o.separator(); err != nil {
+			return err
+		}
+	case FormatICS:
+		if o.Doctype != "" && o.Doctype != consts.CalendarEvents {
+			return ErrInvalidDoctype
+		}
+		o.Doctype = consts.CalendarEvents
+		if len(o.DedupFields) == 0 {
+			o.DedupFields = []string{"uid"}
+		}
+	case FormatVCard:
+		if o.Doctype != "" && o.Doctype != consts.Contacts {
+			return ErrInvalidDoctype
+		}
+		o.Doctype = consts.Contacts
+		if len(o.DedupFields) == 0 {
+			o.DedupFields = []string{"fullname"}
+		}
+	default:
+		return ErrInvalidFormat
+	}
+
+	switch o.Dedup {
+	case "":
+		o.Dedup = DedupNone
+	case DedupNone:
+	case DedupSkip, DedupUpdate:
+		if len(o.DedupFields) == 0 {
+			return ErrInvalidDedup
+		}
+	default:
+		return ErrInvalidDedup
+	}
+	return nil
+}
+
+// Import reads the file, and creates the documents. The progress function, if
+// not nil, is called after each batch of documents written.
+func Import(inst *instance.Instance, opts *Options, progress func(done, total int)) (*Report, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+	fs := inst.VFS()
+	file, err := fs.FileByID(opts.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.ByteSize > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	content, err := fs.OpenFile(file)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	records, err := parse(opts, content)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := doctype.FindValidator(inst, inst.ContextName, opts.Doctype)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]map[string]interface{})
+	if opts.Dedup != DedupNone {
+		existing, err = loadExisting(inst, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &Report{Total: len(records), DryRun: opts.DryRun}
+	var creations, updates, olds []interface{}
+	seen := make(map[string]struct{})
+	for _, r := range records {
+		if r.err != nil {
+			report.addError(r.line, r.err)
+			continue
+		}
+		if err := validate(opts, validator, r.doc); err != nil {
+			report.addError(r.line, err)
+			continue
+		}
+		key := dedupKey(r.doc, opts.DedupFields)
+		if opts.Dedup != DedupNone && key != "" {
+			if _, ok := seen[key]; ok {
+				report.Skipped++
+				continue
+			}
+			seen[key] = struct{}{}
+			if old, ok := existing[key]; ok {
+				if opts.Dedup == DedupSkip {
+					report.Skipped++
+					continue
+				}
+				updated := merge(old, r.doc)
+				updates = append(updates, &couchdb.JSONDoc{Type: opts.Doctype, M: updated})
+				olds = append(olds, &couchdb.JSONDoc{Type: opts.Doctype, M: old})
+				report.Updated++
+				report.addPreview(updated)
+				continue
+			}
+		}
+		addMetadata(r.doc, nil)
+		creations = append(creations, &couchdb.JSONDoc{Type: opts.Doctype, M: r.doc})
+		report.Created++
+		report.addPreview(r.doc)
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	report.Preview = nil
+
+	total := len(creations) + len(updates)
+	done := 0
+	write := func(docs, olddocs []interface{}) error {
+		for len(docs) > 0 {
+			n := batchSize
+			if len(docs) < n {
+				n = len(docs)
+			}
+			if err := couchdb.BulkUpdateDocs(inst, opts.Doctype, docs[:n], olddocs[:n]); err != nil {
+				return err
+			}
+			docs, olddocs = docs[n:], olddocs[n:]
+			done += n
+			if progress != nil {
+				progress(done, total)
+			}
+		}
+		return nil
+	}
+	if err := write(creations, make([]interface{}, len(creations))); err != nil {
+		return nil, err
+	}
+	if err := write(updates, olds); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *Report) addError(line int, err error) {
+	r.Invalid++
+	if len(r.Errors) < maxErrors {
+		r.Errors = append(r.Errors, RecordError{Line: line, Error: err.Error()})
+	}
+}
+
+func (r *Report) addPreview(doc map[string]interface{}) {
+	if r.DryRun && len(r.Preview) < previewSize {
+		r.Preview = append(r.Preview, doc)
+	}
+}
+
+// parse reads the records of the file.
+func parse(opts *Options, content io.Reader) ([]*record, error) {
+	var records []*record
+	switch opts.Format {
+	case FormatCSV:
+		return parseCSV(opts, content)
+	case FormatICS:
+		events, err := calendar.ParseICS(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) > MaxRecords {
+			return nil, ErrTooManyRecords
+		}
+		for _, ev := range events {
+			doc, err := toMap(ev)
+			if err != nil {
+				return nil, err
+			}
+			if ev.Start == "" {
+				delete(doc, "start")
+			}
+			records = append(records, &record{line: ev.Line, doc: doc})
+		}
+	case FormatVCard:
+		cards, err := contact.ParseVCard(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(cards) > MaxRecords {
+			return nil, ErrTooManyRecords
+		}
+		for _, card := range cards {
+			records = append(records, &record{line: card.Line, doc: card.Contact.M})
+		}
+	}
+	return records, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// validate checks the mandatory fields of the doctypes known by the stack, and
+// the JSON schema of the doctype if it has one.
+func validate(opts *Options, validator *doctype.Validator, doc map[string]interface{}) error {
+	if len(doc) == 0 {
+		return errors.New("the record is empty")
+	}
+	switch opts.Doctype {
+	case consts.CalendarEvents:
+		start, _ := doc["start"].(string)
+		if start == "" {
+			return errors.New("the start date is missing or invalid")
+		}
+	case consts.Contacts:
+		fullname, _ := doc["fullname"].(string)
+		_, hasEmail := doc["email"]
+		_, hasPhone := doc["phone"]
+		if fullname == "" && !hasEmail && !hasPhone {
+			return errors.New("the contact has no name, email, or phone")
+		}
+	}
+	if validator != nil {
+		return validator.Validate(doc)
+	}
+	return nil
+}
+
+// loadExisting returns the existing documents of the doctype, indexed by
+// their deduplication key.
+func loadExisting(inst *instance.Instance, opts *Options) (map[string]map[string]interface{}, error) {
+	existing := make(map[string]map[string]interface{})
+	err := couchdb.ForeachDocs(inst, opts.Doctype, func(_ string, raw json.RawMessage) error {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil
+		}
+		if key := dedupKey(doc, opts.DedupFields); key != "" {
+			if _, ok := existing[key]; !ok {
+				existing[key] = doc
+			}
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// dedupKey returns the key used to compare the documents. It is empty if the
+// document has none of the fields.
+func dedupKey(doc map[string]interface{}, fields []string) string {
+	values := make([]string, len(fields))
+	empty := true
+	for i, field := range fields {
+		if v := getPath(doc, strings.Split(field, ".")); v != nil {
+			values[i] = strings.ToLower(strings.TrimSpace(fmt.Sprint(v)))
+			if values[i] != "" {
+				empty = false
+			}
+		}
+	}
+	if empty {
+		return ""
+	}
+	return strings.Join(values, "\x00")
+}
+
+// merge returns a copy of the existing document, with the fields of the
+// record.
+func merge(old, doc map[string]interface{}) map[string]interface{} {
+	updated := make(map[string]interface{}, len(old)+len(doc))
+	for k, v := range old {
+		updated[k] = v
+	}
+	for k, v := range doc {
+		updated[k] = v
+	}
+	addMetadata(updated, old)
+	return updated
+}
+
+// addMetadata sets the cozyMetadata of a created or updated document.
+func addMetadata(doc, old map[string]interface{}) {
+	if old != nil {
+		if meta, ok := old["cozyMetadata"].(map[string]interface{}); ok {
+			updated := make(map[string]interface{}, len(meta))
+			for k, v := range meta {
+				updated[k] = v
+			}
+			updated["updatedAt"] = time.Now()
+			doc["cozyMetadata"] = updated
+			return
+		}
+	}
+	doc["cozyMetadata"] = metadata.New()
+}
@@ -0,0 +1,117 @@
+package dataimport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOptions(t *testing.T) {
+	opts := &Options{FileID: "123", Format: FormatVCard}
+	require.NoError(t, opts.Check())
+	assert.Equal(t, consts.Contacts, opts.Doctype)
+	assert.Equal(t, DedupNone, opts.Dedup)
+	assert.Equal(t, []string{"fullname"}, opts.DedupFields)
+
+	opts = &Options{FileID: "123", Format: FormatICS, Doctype: consts.Contacts}
+	assert.ErrorIs(t, opts.Check(), ErrInvalidDoctype)
+
+	opts = &Options{FileID: "123", Format: "xlsx"}
+	assert.ErrorIs(t, opts.Check(), ErrInvalidFormat)
+
+	opts = &Options{FileID: "123", Format: FormatCSV, Doctype: "io.cozy.bank.operations"}
+	assert.ErrorIs(t, opts.Check(), ErrInvalidMapping)
+
+	opts.Mapping = map[string]string{"Amount": "amount:number"}
+	opts.Dedup = DedupSkip
+	assert.ErrorIs(t, opts.Check(), ErrInvalidDedup)
+	opts.DedupFields = []string{"amount"}
+	assert.NoError(t, opts.Check())
+
+	for _, target := range []string{"", "a..b", "0.a", "_id", "a:date", "a.1000"} {
+		_, err := parseMapping(map[string]string{"col": target})
+		assert.ErrorIs(t, err, ErrInvalidMapping, target)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	content := "\ufeffName;Email;Phone;Age;Member\n" +
+		"Alice;alice@example.net;0102030405;42;yes\n" +
+		"\"Bob \"\"the builder\"\"\";bob@example.net;;;no\n" +
+		"Carol;;;not a number;\n"
+	opts := &Options{
+		FileID:    "123",
+		Format:    FormatCSV,
+		Doctype:   consts.Contacts,
+		Separator: ";",
+		Mapping: map[string]string{
+			"Name":   "fullname",
+			"Email":  "email.0.address",
+			"Phone":  "phone.0.number",
+			"Age":    "age:number",
+			"Member": "member:bool",
+		},
+	}
+	require.NoError(t, opts.Check())
+	records, err := parseCSV(opts, strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	alice := records[0]
+	assert.Equal(t, 2, alice.line)
+	assert.NoError(t, alice.err)
+	assert.Equal(t, map[string]interface{}{
+		"fullname": "Alice",
+		"email":    []interface{}{map[string]interface{}{"address": "alice@example.net"}},
+		"phone":    []interface{}{map[string]interface{}{"number": "0102030405"}},
+		"age":      float64(42),
+		"member":   true,
+	}, alice.doc)
+
+	bob := records[1]
+	assert.Equal(t, `Bob "the builder"`, bob.doc["fullname"])
+	assert.NotContains(t, bob.doc, "phone")
+	assert.Equal(t, false, bob.doc["member"])
+
+	carol := records[2]
+	assert.Equal(t, 4, carol.line)
+	assert.Error(t, carol.err)
+
+	opts.Mapping = map[string]string{"Unknown": "unknown"}
+	_, err = parseCSV(opts, strings.NewReader(content))
+	assert.ErrorIs(t, err, ErrInvalidMapping)
+}
+
+func TestDedupKey(t *testing.T) {
+	doc := map[string]interface{}{
+		"fullname": " Alice ",
+		"email":    []interface{}{map[string]interface{}{"address": "ALICE@example.net"}},
+	}
+	assert.Equal(t, "alice", dedupKey(doc, []string{"fullname"}))
+	assert.Equal(t, "alice\x00alice@example.net", dedupKey(doc, []string{"fullname", "email.0.address"}))
+	assert.Equal(t, "", dedupKey(doc, []string{"uid"}))
+
+	old := map[string]interface{}{"_id": "123", "_rev": "1-abc", "fullname": "Alice", "note": "old"}
+	updated := merge(old, map[string]interface{}{"fullname": "Alice", "note": "new"})
+	assert.Equal(t, "123", updated["_id"])
+	assert.Equal(t, "1-abc", updated["_rev"])
+	assert.Equal(t, "new", updated["note"])
+	assert.Equal(t, "old", old["note"])
+}
+
+func TestValidate(t *testing.T) {
+	contacts := &Options{Doctype: consts.Contacts}
+	assert.Error(t, validate(contacts, nil, map[string]interface{}{}))
+	assert.Error(t, validate(contacts, nil, map[string]interface{}{"note": "foo"}))
+	assert.NoError(t, validate(contacts, nil, map[string]interface{}{"fullname": "Alice"}))
+
+	events := &Options{Doctype: consts.CalendarEvents}
+	assert.Error(t, validate(events, nil, map[string]interface{}{"summary": "Meeting"}))
+	assert.NoError(t, validate(events, nil, map[string]interface{}{
+		"summary": "Meeting",
+		"start":   "2024-05-02T10:00:00Z",
+	}))
+}
@@ -0,0 +1,163 @@
+// Package diskusage is used to keep a daily history of the disk usage of an
+// instance, and to compute the trend and a forecast from it.
+package diskusage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// SnapshotWorkerType is the type of the worker that takes the daily snapshots
+// of the disk usage.
+const SnapshotWorkerType = "disk-usage-snapshot"
+
+// SnapshotsRetention is the duration during which the snapshots are kept.
+const SnapshotsRetention = 365 * 24 * time.Hour
+
+// dateLayout is the format of the date of a snapshot, also used as its
+// identifier. It means that there is at most one snapshot per day, and that
+// the snapshots are sorted by date in the _all_docs index.
+const dateLayout = "2006-01-02"
+
+// Snapshot is the disk usage of an instance for a day.
+type Snapshot struct {
+	DocID     string    `json:"_id,omitempty"`
+	DocRev    string    `json:"_rev,omitempty"`
+	Date      string    `json:"date"`
+	Used      int64     `json:"used"`
+	Files     int64     `json:"files"`
+	Versions  int64     `json:"versions"`
+	Trash     int64     `json:"trash"`
+	Quota     int64     `json:"quota,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (s *Snapshot) ID() string { return s.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (s *Snapshot) Rev() string { return s.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (s *Snapshot) DocType() string { return consts.StorageSnapshots }
+
+// Clone implements couchdb.Doc
+func (s *Snapshot) Clone() couchdb.Doc {
+	cloned := *s
+	return &cloned
+}
+
+// SetID is used to implement the couchdb.Doc interface
+func (s *Snapshot) SetID(id string) { s.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (s *Snapshot) SetRev(rev string) { s.DocRev = rev }
+
+// Day returns the day of the snapshot.
+func (s *Snapshot) Day() time.Time {
+	day, err := time.Parse(dateLayout, s.Date)
+	if err != nil {
+		return s.CreatedAt.UTC().Truncate(24 * time.Hour)
+	}
+	return day
+}
+
+// TakeSnapshot saves the current disk usage of the instance. If a snapshot
+// has already been taken today, it is replaced.
+func TakeSnapshot(inst *instance.Instance) (*Snapshot, error) {
+	fs := inst.VFS()
+	files, err := fs.FilesUsage()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := fs.VersionsUsage()
+	if err != nil {
+		return nil, err
+	}
+	trash, err := fs.TrashUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	date := now.Format(dateLayout)
+	snapshot := &Snapshot{
+		DocID:     date,
+		Date:      date,
+		Used:      files + versions,
+		Files:     files,
+		Versions:  versions,
+		Trash:     trash,
+		Quota:     fs.DiskQuota(),
+		CreatedAt: now,
+	}
+	if err := couchdb.Upsert(inst, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ListSnapshots returns the snapshots taken since the given date, sorted from
+// the oldest to the most recent.
+func ListSnapshots(inst *instance.Instance, since time.Time) ([]*Snapshot, error) {
+	var snapshots []*Snapshot
+	req := &couchdb.AllDocsRequest{StartKey: since.UTC().Format(dateLayout)}
+	err := couchdb.GetAllDocs(inst, consts.StorageSnapshots, req, &snapshots)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// CleanOldSnapshots removes the snapshots older than the retention period.
+func CleanOldSnapshots(inst *instance.Instance) error {
+	var snapshots []*Snapshot
+	limit := time.Now().Add(-SnapshotsRetention).UTC().Format(dateLayout)
+	req := &couchdb.AllDocsRequest{EndKey: limit, Limit: 1000}
+	if err := couchdb.GetAllDocs(inst, consts.StorageSnapshots, req, &snapshots); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil
+		}
+		return err
+	}
+	docs := make([]couchdb.Doc, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Date < limit {
+			docs = append(docs, snapshot)
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	return couchdb.BulkDeleteDocs(inst, consts.StorageSnapshots, docs)
+}
+
+// EnsureSnapshotTrigger creates the daily trigger for the disk-usage-snapshot
+// worker if the instance does not have it yet.
+func EnsureSnapshotTrigger(inst *instance.Instance) {
+	sched := job.System()
+	infos := job.TriggerInfos{
+		Type:       "@cron",
+		WorkerType: SnapshotWorkerType,
+	}
+	if sched.HasTrigger(inst, infos) {
+		return
+	}
+
+	now := time.Now()
+	hours := (now.Hour() + 12) % 24
+	infos.Arguments = fmt.Sprintf("0 %d %d * * *", now.Minute(), hours)
+	trigger, err := job.NewTrigger(inst, infos, nil)
+	if err != nil {
+		inst.Logger().Errorf("Cannot create disk-usage-snapshot trigger: %s", err)
+		return
+	}
+	if err = sched.AddTrigger(trigger); err != nil {
+		inst.Logger().Errorf("Cannot create disk-usage-snapshot trigger: %s", err)
+	}
+}
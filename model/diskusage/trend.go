@@ -0,0 +1,138 @@
+package diskusage
+
+import (
+	"math"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/justincampbell/bigduration"
+)
+
+// DefaultTrendDays is the default number of days of history used to compute
+// the trend.
+const DefaultTrendDays = 30
+
+// MaxTrendDays is the maximal number of days of history that can be used to
+// compute the trend.
+const MaxTrendDays = 365
+
+// minSnapshotsForForecast is the minimal number of snapshots needed to make a
+// forecast: with less snapshots, the growth rate is not meaningful.
+const minSnapshotsForForecast = 3
+
+// Trend is the evolution of the disk usage of an instance over the last days,
+// with a forecast of the date when the quota will be reached at the current
+// rate.
+type Trend struct {
+	Days int `json:"days"`
+	// Used and Quota are the values of the most recent snapshot
+	Used  int64 `json:"used,string"`
+	Quota int64 `json:"quota,string,omitempty"`
+	// DailyGrowth is the average number of bytes added each day, it can be
+	// negative if the user has made some cleanup
+	DailyGrowth int64 `json:"daily_growth,string"`
+	// FullAt and DaysUntilFull are only set if there is a quota, and the disk
+	// usage is growing
+	FullAt        *time.Time  `json:"full_at,omitempty"`
+	DaysUntilFull *int        `json:"days_until_full,omitempty"`
+	Snapshots     []*Snapshot `json:"snapshots"`
+}
+
+// GetTrend returns the trend of the disk usage of the instance, computed from
+// the snapshots of the given number of last days.
+func GetTrend(inst *instance.Instance, days int) (*Trend, error) {
+	if days <= 0 {
+		days = DefaultTrendDays
+	}
+	if days > MaxTrendDays {
+		days = MaxTrendDays
+	}
+	now := time.Now().UTC()
+	snapshots, err := ListSnapshots(inst, now.AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	trend := ComputeTrend(snapshots, now)
+	trend.Days = days
+	return trend, nil
+}
+
+// ComputeTrend computes the growth rate of the disk usage with a linear
+// regression on the snapshots, and uses it to forecast when the quota will be
+// reached.
+func ComputeTrend(snapshots []*Snapshot, now time.Time) *Trend {
+	trend := &Trend{Snapshots: snapshots}
+	if trend.Snapshots == nil {
+		trend.Snapshots = []*Snapshot{}
+	}
+	if len(snapshots) == 0 {
+		return trend
+	}
+	last := snapshots[len(snapshots)-1]
+	trend.Used = last.Used
+	trend.Quota = last.Quota
+	if len(snapshots) < minSnapshotsForForecast {
+		return trend
+	}
+
+	// Least squares on (days since the first snapshot, used bytes)
+	first := snapshots[0].Day()
+	n := float64(len(snapshots))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range snapshots {
+		x := s.Day().Sub(first).Hours() / 24
+		y := float64(s.Used)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return trend
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	trend.DailyGrowth = int64(math.Round(slope))
+
+	if trend.Quota <= 0 || trend.DailyGrowth <= 0 {
+		return trend
+	}
+	remaining := trend.Quota - trend.Used
+	daysUntilFull := 0
+	if remaining > 0 {
+		daysUntilFull = int(math.Ceil(float64(remaining) / slope))
+	}
+	fullAt := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, daysUntilFull)
+	trend.DaysUntilFull = &daysUntilFull
+	trend.FullAt = &fullAt
+	return trend
+}
+
+// ForecastAlertDelay returns the delay before the quota is reached under
+// which the user should be warned, for the given context. The boolean is
+// false if there is no such alert for this context.
+func ForecastAlertDelay(contextName string) (time.Duration, bool) {
+	cfg := config.GetConfig().Fs.StorageForecastAlert
+	delay, ok := cfg[contextName]
+	if !ok {
+		delay, ok = cfg[config.DefaultInstanceContext]
+	}
+	if !ok || delay == "" {
+		return 0, false
+	}
+	d, err := bigduration.ParseDuration(delay)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// ShouldAlert returns true if the quota will be reached before the given
+// delay at the current rate.
+func (t *Trend) ShouldAlert(delay time.Duration) bool {
+	if t.DaysUntilFull == nil {
+		return false
+	}
+	return time.Duration(*t.DaysUntilFull)*24*time.Hour <= delay
+}
@@ -0,0 +1,69 @@
+package diskusage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotsFor(start time.Time, quota int64, used ...int64) []*Snapshot {
+	var snapshots []*Snapshot
+	for i, u := range used {
+		date := start.AddDate(0, 0, i).Format(dateLayout)
+		snapshots = append(snapshots, &Snapshot{DocID: date, Date: date, Used: u, Quota: quota})
+	}
+	return snapshots
+}
+
+func TestComputeTrend(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	start := now.AddDate(0, 0, -4)
+
+	t.Run("NoSnapshots", func(t *testing.T) {
+		trend := ComputeTrend(nil, now)
+		assert.Equal(t, int64(0), trend.Used)
+		assert.NotNil(t, trend.Snapshots)
+		assert.Nil(t, trend.FullAt)
+	})
+
+	t.Run("NotEnoughSnapshots", func(t *testing.T) {
+		trend := ComputeTrend(snapshotsFor(start, 1000, 100, 200), now)
+		assert.Equal(t, int64(200), trend.Used)
+		assert.Equal(t, int64(1000), trend.Quota)
+		assert.Equal(t, int64(0), trend.DailyGrowth)
+		assert.Nil(t, trend.DaysUntilFull)
+	})
+
+	t.Run("Growing", func(t *testing.T) {
+		trend := ComputeTrend(snapshotsFor(start, 1000, 100, 200, 300, 400, 500), now)
+		assert.Equal(t, int64(500), trend.Used)
+		assert.Equal(t, int64(100), trend.DailyGrowth)
+		require.NotNil(t, trend.DaysUntilFull)
+		assert.Equal(t, 5, *trend.DaysUntilFull)
+		require.NotNil(t, trend.FullAt)
+		assert.Equal(t, "2024-03-15", trend.FullAt.Format(dateLayout))
+		assert.True(t, trend.ShouldAlert(7*24*time.Hour))
+		assert.False(t, trend.ShouldAlert(3*24*time.Hour))
+	})
+
+	t.Run("Shrinking", func(t *testing.T) {
+		trend := ComputeTrend(snapshotsFor(start, 1000, 500, 400, 300), now)
+		assert.Equal(t, int64(-100), trend.DailyGrowth)
+		assert.Nil(t, trend.DaysUntilFull)
+		assert.False(t, trend.ShouldAlert(365*24*time.Hour))
+	})
+
+	t.Run("NoQuota", func(t *testing.T) {
+		trend := ComputeTrend(snapshotsFor(start, 0, 100, 200, 300), now)
+		assert.Equal(t, int64(100), trend.DailyGrowth)
+		assert.Nil(t, trend.DaysUntilFull)
+	})
+
+	t.Run("AlreadyFull", func(t *testing.T) {
+		trend := ComputeTrend(snapshotsFor(start, 1000, 900, 1000, 1100), now)
+		require.NotNil(t, trend.DaysUntilFull)
+		assert.Equal(t, 0, *trend.DaysUntilFull)
+	})
+}
@@ -0,0 +1,233 @@
+// Package doctype is for the custom doctypes that the hosters can declare in
+// the config of a context, with their mango indexes, the permissions that the
+// apps can have on them, and an optional JSON schema for the documents.
+package doctype
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ErrInvalidConfig is used when a custom doctype is not correctly declared in
+// the config.
+var ErrInvalidConfig = errors.New("invalid custom doctype")
+
+// ErrInvalidSchema is used when a JSON schema can't be compiled.
+var ErrInvalidSchema = errors.New("invalid JSON schema")
+
+// Custom is a doctype declared in the config of a context.
+type Custom struct {
+	Doctype     string
+	Description string
+	// Verbs are the verbs that the apps, konnectors and OAuth clients can
+	// have on the documents of this doctype. The CLI is not restricted.
+	Verbs   permission.VerbSet
+	Indexes []*mango.Index
+	Schema  *gojsonschema.Schema
+	// Trash is true if the documents can be put in the trash before being
+	// destroyed.
+	Trash bool
+}
+
+func init() {
+	couchdb.ContextIndexes = contextIndexes
+}
+
+// ForContext returns the custom doctypes declared for the given context. The
+// doctypes of the default context are used for the instances whose context
+// has no config.
+func ForContext(contextName string) (map[string]*Custom, error) {
+	settings := contextSettings(contextName)
+	raw, ok := settings["doctypes"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	customs := make(map[string]*Custom, len(raw))
+	for name, value := range raw {
+		decl, _ := value.(map[string]interface{})
+		custom, err := Parse(name, decl)
+		if err != nil {
+			return nil, err
+		}
+		customs[name] = custom
+	}
+	return customs, nil
+}
+
+// Find returns the custom doctype with the given name for the context, if it
+// has been declared.
+func Find(contextName, doctype string) (*Custom, bool) {
+	customs, err := ForContext(contextName)
+	if err != nil {
+		return nil, false
+	}
+	custom, ok := customs[doctype]
+	return custom, ok
+}
+
+// Parse checks and transforms the declaration of a custom doctype from the
+// config.
+func Parse(doctype string, decl map[string]interface{}) (*Custom, error) {
+	if err := permission.CheckDoctypeName(doctype, false); err != nil {
+		return nil, fmt.Errorf("%w: %s is not a valid doctype name", ErrInvalidConfig, doctype)
+	}
+	if err := permission.CheckWritable(doctype); err != nil {
+		return nil, fmt.Errorf("%w: %s is a reserved doctype", ErrInvalidConfig, doctype)
+	}
+	if strings.HasPrefix(doctype, "io.cozy.") {
+		return nil, fmt.Errorf("%w: io.cozy.* doctypes can't be declared", ErrInvalidConfig)
+	}
+
+	custom := &Custom{Doctype: doctype, Verbs: permission.ALL}
+	custom.Description, _ = decl["description"].(string)
+	custom.Trash, _ = decl["trash"].(bool)
+
+	if perms, ok := decl["permissions"].(map[string]interface{}); ok {
+		if verbs, ok := perms["verbs"].([]interface{}); ok {
+			custom.Verbs = permission.VerbSet{}
+			for _, v := range verbs {
+				verb, _ := v.(string)
+				set := permission.VerbSplit(strings.ToUpper(verb))
+				if verb == "" || !permission.ALL.ContainsAll(set) {
+					return nil, fmt.Errorf("%w: invalid verb %v for %s", ErrInvalidConfig, v, doctype)
+				}
+				custom.Verbs.Merge(&set)
+			}
+		}
+	}
+
+	if indexes, ok := decl["indexes"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(indexes))
+		for name := range indexes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fields, _ := indexes[name].([]interface{})
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("%w: index %s of %s has no fields", ErrInvalidConfig, name, doctype)
+			}
+			def := mango.IndexDef{Fields: make([]string, len(fields))}
+			for i, f := range fields {
+				def.Fields[i] = fmt.Sprintf("%v", f)
+			}
+			custom.Indexes = append(custom.Indexes, mango.MakeIndex(doctype, "by-"+name, def))
+		}
+	}
+
+	if schema, ok := decl["schema"]; ok && schema != nil {
+		compiled, err := compileSchema(doctype, schema)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+		}
+		custom.Schema = compiled
+	}
+
+	return custom, nil
+}
+
+// Allows returns true if the apps can use the given verb on the documents of
+// this doctype.
+func (c *Custom) Allows(verb permission.Verb) bool {
+	return c.Verbs.Contains(verb)
+}
+
+// ValidationError is returned when a document doesn't match the JSON schema
+// of its doctype. The details explain which fields are invalid.
+type ValidationError struct {
+	Doctype string
+	Details []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("the document doesn't match the schema of %s: %s",
+		e.Doctype, strings.Join(e.Details, ", "))
+}
+
+// Validate checks that the given document matches the JSON schema of the
+// doctype, if there is one. The CouchDB special fields (like _id and _rev)
+// are ignored.
+func (c *Custom) Validate(doc map[string]interface{}) error {
+	if c.Schema == nil {
+		return nil
+	}
+	return validateWithSchema(c.Doctype, c.Schema, doc)
+}
+
+func validateWithSchema(doctype string, schema *gojsonschema.Schema, doc map[string]interface{}) error {
+	fields := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		if !strings.HasPrefix(k, "_") {
+			fields[k] = v
+		}
+	}
+	// Round-trip via JSON, to have the same types as the documents in CouchDB
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(buf))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+	verr := &ValidationError{Doctype: doctype}
+	for _, e := range result.Errors() {
+		verr.Details = append(verr.Details, e.String())
+	}
+	return verr
+}
+
+// DefineIndexes creates the mango indexes of the custom doctypes for the
+// given instance. The indexes are also created with the database, so it is
+// only useful for the databases that existed before the indexes were added to
+// the config.
+func DefineIndexes(db prefixer.Prefixer, contextName string) error {
+	customs, err := ForContext(contextName)
+	if err != nil {
+		return err
+	}
+	for _, custom := range customs {
+		for _, index := range custom.Indexes {
+			if err := couchdb.DefineIndex(db, index); err != nil && !couchdb.IsNoDatabaseError(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func contextIndexes(db prefixer.Prefixer, doctype string) []*mango.Index {
+	contexter, ok := db.(interface{ GetContextName() string })
+	if !ok {
+		return nil
+	}
+	custom, ok := Find(contexter.GetContextName(), doctype)
+	if !ok {
+		return nil
+	}
+	return custom.Indexes
+}
+
+func contextSettings(contextName string) map[string]interface{} {
+	contexts := config.GetConfig().Contexts
+	if contextName != "" {
+		if settings, ok := contexts[contextName].(map[string]interface{}); ok {
+			return settings
+		}
+	}
+	settings, _ := contexts[config.DefaultInstanceContext].(map[string]interface{})
+	return settings
+}
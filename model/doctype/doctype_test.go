@@ -0,0 +1,147 @@
+package doctype
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomDoctypes(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Contexts = map[string]interface{}{
+		config.DefaultInstanceContext: map[string]interface{}{
+			"doctypes": map[string]interface{}{
+				"com.example.notes": map[string]interface{}{
+					"description": "Notes of the in-house app",
+					"trash":       true,
+					"permissions": map[string]interface{}{
+						"verbs": []interface{}{"GET", "POST"},
+					},
+					"indexes": map[string]interface{}{
+						"title": []interface{}{"title", "created_at"},
+					},
+					"schema": `{
+						"type": "object",
+						"required": ["title"],
+						"properties": {"title": {"type": "string"}}
+					}`,
+				},
+			},
+		},
+		"empty": map[string]interface{}{},
+	}
+
+	t.Run("Find", func(t *testing.T) {
+		custom, ok := Find("foo", "com.example.notes")
+		require.True(t, ok)
+		assert.Equal(t, "Notes of the in-house app", custom.Description)
+		assert.True(t, custom.Allows(permission.GET))
+		assert.True(t, custom.Allows(permission.POST))
+		assert.False(t, custom.Allows(permission.DELETE))
+		require.Len(t, custom.Indexes, 1)
+		assert.Equal(t, "com.example.notes", custom.Indexes[0].Doctype)
+		assert.Equal(t, []string{"title", "created_at"}, custom.Indexes[0].Request.Index.Fields)
+
+		_, ok = Find("empty", "com.example.notes")
+		assert.False(t, ok)
+		_, ok = Find("foo", "com.example.other")
+		assert.False(t, ok)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		assert.True(t, HasTrash("foo", "io.cozy.contacts"))
+		assert.True(t, HasTrash("foo", "com.example.notes"))
+		assert.False(t, HasTrash("empty", "com.example.notes"))
+		assert.False(t, HasTrash("foo", "io.cozy.files"))
+
+		delay, ok := AutoCleanTrashedAfter("foo")
+		assert.True(t, ok)
+		assert.Equal(t, 30*24*time.Hour, delay)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		custom, ok := Find("", "com.example.notes")
+		require.True(t, ok)
+		assert.NoError(t, custom.Validate(map[string]interface{}{
+			"_id":   "123",
+			"title": "Hello",
+		}))
+		err := custom.Validate(map[string]interface{}{"title": 42})
+		require.Error(t, err)
+		verr, ok := err.(*ValidationError)
+		require.True(t, ok)
+		assert.Len(t, verr.Details, 1)
+		assert.Error(t, custom.Validate(map[string]interface{}{}))
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		_, err := Parse("io.cozy.files", nil)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		_, err = Parse("io.cozy.notes", nil)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		_, err = Parse("com.example.foo", map[string]interface{}{
+			"permissions": map[string]interface{}{
+				"verbs": []interface{}{"FOO"},
+			},
+		})
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+		custom, err := Parse("com.example.foo", nil)
+		assert.NoError(t, err)
+		assert.True(t, custom.Allows(permission.DELETE))
+	})
+
+	t.Run("Validator", func(t *testing.T) {
+		_, err := compileSchema("com.example.foo", map[string]interface{}{"type": 42})
+		assert.ErrorIs(t, err, ErrInvalidSchema)
+
+		compiled, err := compileSchema("com.example.foo", map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"count": map[string]interface{}{"type": "integer"}},
+		})
+		require.NoError(t, err)
+		v := &Validator{Doctype: "com.example.foo", schema: compiled}
+		assert.NoError(t, v.Validate(map[string]interface{}{"count": 3}))
+		assert.Error(t, v.Validate(map[string]interface{}{"count": "three"}))
+	})
+}
+
+func TestMergeSchemas(t *testing.T) {
+	notes := map[string]interface{}{"required": []interface{}{"title"}}
+	tasks := map[string]interface{}{"required": []interface{}{"done"}}
+
+	// A schema saved with only its source
+	s := &Schema{Schema: notes, Source: "webapp:notes"}
+	assert.True(t, s.mergeApps())
+	assert.Equal(t, "webapp:notes", s.Source)
+	assert.Equal(t, notes, s.Schema)
+
+	s.Apps["webapp:tasks"] = tasks
+	assert.True(t, s.mergeApps())
+	assert.Equal(t, SourceMerged, s.Source)
+	assert.Equal(t, map[string]interface{}{"allOf": []interface{}{notes, tasks}}, s.Schema)
+	compiled, err := compileSchema("com.example.notes", s.Schema)
+	require.NoError(t, err)
+	v := &Validator{Doctype: "com.example.notes", schema: compiled}
+	assert.Error(t, v.Validate(map[string]interface{}{"title": "foo"}))
+	assert.NoError(t, v.Validate(map[string]interface{}{"title": "foo", "done": true}))
+
+	delete(s.Apps, "webapp:notes")
+	assert.True(t, s.mergeApps())
+	assert.Equal(t, "webapp:tasks", s.Source)
+	assert.Equal(t, tasks, s.Schema)
+
+	// The schema of the admin has the priority
+	admin := map[string]interface{}{"type": "object"}
+	s.Schema = admin
+	s.Source = SourceAdmin
+	assert.True(t, s.mergeApps())
+	assert.Equal(t, admin, s.Schema)
+
+	s = &Schema{Source: "webapp:tasks", Apps: map[string]map[string]interface{}{}}
+	assert.False(t, s.mergeApps())
+}
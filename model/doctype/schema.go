@@ -0,0 +1,377 @@
+package doctype
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	// SourceAdmin is the source of the schemas registered via the admin API.
+	SourceAdmin = "admin"
+	// SourceMerged is the source of the schemas declared by several apps: the
+	// documents must match the schemas of all these apps.
+	SourceMerged = "merged"
+)
+
+const (
+	validatorsCacheSize = 1024
+	// validatorsCacheTTL is how long a validator is kept in memory. The cache
+	// is invalidated when a schema is registered or removed, but only in the
+	// current process: the other processes see the change after this delay.
+	validatorsCacheTTL = 1 * time.Minute
+)
+
+var validatorsCache *expirable.LRU[string, *Validator]
+var initValidatorsCacheOnce sync.Once
+
+// Schema is a JSON schema registered on an instance for a doctype, by an app
+// manifest or via the admin API. The identifier is the doctype.
+type Schema struct {
+	DocID  string                 `json:"_id,omitempty"`
+	DocRev string                 `json:"_rev,omitempty"`
+	Schema map[string]interface{} `json:"schema"`
+	Source string                 `json:"source"`
+	// Apps are the schemas declared by the apps for this doctype, by source.
+	// They are kept when the admin registers a schema, even if it has the
+	// priority.
+	Apps      map[string]map[string]interface{} `json:"apps,omitempty"`
+	UpdatedAt time.Time                         `json:"updated_at"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (s *Schema) ID() string { return s.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (s *Schema) Rev() string { return s.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (s *Schema) DocType() string { return consts.DoctypesSchemas }
+
+// Clone implements couchdb.Doc
+func (s *Schema) Clone() couchdb.Doc {
+	cloned := *s
+	cloned.Schema = make(map[string]interface{}, len(s.Schema))
+	for k, v := range s.Schema {
+		cloned.Schema[k] = v
+	}
+	if s.Apps != nil {
+		cloned.Apps = make(map[string]map[string]interface{}, len(s.Apps))
+		for k, v := range s.Apps {
+			cloned.Apps[k] = v
+		}
+	}
+	return &cloned
+}
+
+// SetID is used to implement the couchdb.Doc interface
+func (s *Schema) SetID(id string) { s.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (s *Schema) SetRev(rev string) { s.DocRev = rev }
+
+// AppSource returns the source for the schemas declared in the manifest of an
+// app or a konnector.
+func AppSource(appType consts.AppType, slug string) string {
+	return fmt.Sprintf("%s:%s", appType, slug)
+}
+
+// declarations returns the schemas declared by the apps. A schema saved with
+// only its source was declared by a single app.
+func (s *Schema) declarations() map[string]map[string]interface{} {
+	if s.Apps == nil {
+		s.Apps = make(map[string]map[string]interface{})
+		if s.Source != "" && s.Source != SourceAdmin && s.Source != SourceMerged {
+			s.Apps[s.Source] = s.Schema
+		}
+	}
+	return s.Apps
+}
+
+// mergeApps computes the schema from the declarations of the apps, unless it
+// has been registered via the admin API. When several apps declare a schema,
+// the documents must match all of them. It returns false if there is no
+// schema left.
+func (s *Schema) mergeApps() bool {
+	if s.Source == SourceAdmin {
+		return true
+	}
+	apps := s.declarations()
+	sources := make([]string, 0, len(apps))
+	for source := range apps {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	switch len(sources) {
+	case 0:
+		return false
+	case 1:
+		s.Source = sources[0]
+		s.Schema = apps[sources[0]]
+	default:
+		all := make([]interface{}, len(sources))
+		for i, source := range sources {
+			all[i] = apps[source]
+		}
+		s.Source = SourceMerged
+		s.Schema = map[string]interface{}{"allOf": all}
+	}
+	return true
+}
+
+// Validator checks the documents of a doctype against its JSON schema.
+type Validator struct {
+	Doctype string
+	schema  *gojsonschema.Schema
+}
+
+// Validate returns a ValidationError if the document doesn't match the JSON
+// schema. The CouchDB special fields (like _id and _rev) are ignored.
+func (v *Validator) Validate(doc map[string]interface{}) error {
+	return validateWithSchema(v.Doctype, v.schema, doc)
+}
+
+// FindValidator returns the validator for the documents of the given doctype
+// on an instance. The schema registered on the instance has the priority over
+// the schema of a custom doctype declared in the context config. It returns
+// nil if the doctype has no schema. The validators are cached in memory.
+func FindValidator(db prefixer.Prefixer, contextName, doctype string) (*Validator, error) {
+	cache := getValidatorsCache()
+	key := validatorKey(db, doctype)
+	if v, ok := cache.Get(key); ok {
+		return v, nil
+	}
+	v, err := findValidator(db, contextName, doctype)
+	if err != nil {
+		return nil, err
+	}
+	cache.Add(key, v)
+	return v, nil
+}
+
+func findValidator(db prefixer.Prefixer, contextName, doctype string) (*Validator, error) {
+	s, err := GetSchema(db, doctype)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return nil, err
+	}
+	if s != nil {
+		compiled, err := compileSchema(doctype, s.Schema)
+		if err != nil {
+			return nil, err
+		}
+		return &Validator{Doctype: doctype, schema: compiled}, nil
+	}
+	if custom, ok := Find(contextName, doctype); ok && custom.Schema != nil {
+		return &Validator{Doctype: doctype, schema: custom.Schema}, nil
+	}
+	return nil, nil
+}
+
+// GetSchema returns the schema registered on the instance for the doctype.
+func GetSchema(db prefixer.Prefixer, doctype string) (*Schema, error) {
+	s := &Schema{}
+	if err := couchdb.GetDoc(db, consts.DoctypesSchemas, doctype, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListSchemas returns all the schemas registered on the instance.
+func ListSchemas(db prefixer.Prefixer) ([]*Schema, error) {
+	var schemas []*Schema
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	if err := couchdb.GetAllDocs(db, consts.DoctypesSchemas, req, &schemas); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return []*Schema{}, nil
+		}
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// CheckSchema returns an error if the JSON schema for the doctype is invalid.
+func CheckSchema(doctype string, schema map[string]interface{}) error {
+	_, err := compileSchema(doctype, schema)
+	return err
+}
+
+// RegisterSchema checks and saves the JSON schema for a doctype. The schema
+// registered via the admin API has the priority over the schemas declared by
+// the apps, and when several apps declare a schema for the same doctype, the
+// documents must match all of them.
+func RegisterSchema(db prefixer.Prefixer, doctype, source string, schema map[string]interface{}) (*Schema, error) {
+	if err := CheckSchema(doctype, schema); err != nil {
+		return nil, err
+	}
+	s, err := GetSchema(db, doctype)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return nil, err
+	}
+	if s == nil {
+		s = &Schema{DocID: doctype}
+	}
+	apps := s.declarations()
+	if source == SourceAdmin {
+		s.Schema = schema
+		s.Source = SourceAdmin
+	} else {
+		apps[source] = schema
+		s.mergeApps()
+	}
+	s.UpdatedAt = time.Now()
+	if s.DocRev == "" {
+		err = couchdb.CreateNamedDocWithDB(db, s)
+	} else {
+		err = couchdb.UpdateDoc(db, s)
+	}
+	getValidatorsCache().Remove(validatorKey(db, doctype))
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UnregisterSchema removes the schema of a doctype. For the admin, the schema
+// is removed with the declarations of the apps. For an app, only its
+// declaration is removed, and the schema is kept if other apps still declare
+// one.
+func UnregisterSchema(db prefixer.Prefixer, doctype, source string) error {
+	s, err := GetSchema(db, doctype)
+	if err != nil {
+		return err
+	}
+	if source == SourceAdmin {
+		err = couchdb.DeleteDoc(db, s)
+	} else {
+		apps := s.declarations()
+		if _, ok := apps[source]; !ok {
+			return nil
+		}
+		delete(apps, source)
+		if s.mergeApps() {
+			s.UpdatedAt = time.Now()
+			err = couchdb.UpdateDoc(db, s)
+		} else {
+			err = couchdb.DeleteDoc(db, s)
+		}
+	}
+	getValidatorsCache().Remove(validatorKey(db, doctype))
+	return err
+}
+
+// SyncAppSchemas registers the schemas declared in the manifest of an app,
+// and removes the schemas that the previous version of the app had declared
+// and that are no longer in the manifest.
+func SyncAppSchemas(db prefixer.Prefixer, source string, schemas map[string]map[string]interface{}) error {
+	existing, err := ListSchemas(db)
+	if err != nil {
+		return err
+	}
+	declared := make(map[string]map[string]interface{})
+	for _, s := range existing {
+		if schema, ok := s.declarations()[source]; ok {
+			declared[s.ID()] = schema
+		}
+	}
+	for doctype := range declared {
+		if _, ok := schemas[doctype]; !ok {
+			if err := UnregisterSchema(db, doctype, source); err != nil {
+				return err
+			}
+		}
+	}
+	for doctype, schema := range schemas {
+		if reflect.DeepEqual(declared[doctype], schema) {
+			continue
+		}
+		if _, err := RegisterSchema(db, doctype, source, schema); err != nil {
+			return fmt.Errorf("cannot register the schema for %s: %w", doctype, err)
+		}
+	}
+	return nil
+}
+
+// Violation is a document that doesn't match the JSON schema of its doctype.
+type Violation struct {
+	ID     string   `json:"id"`
+	Rev    string   `json:"rev"`
+	Errors []string `json:"errors"`
+}
+
+// Report is the result of the check of the existing documents of a doctype
+// against its JSON schema.
+type Report struct {
+	Doctype    string       `json:"doctype"`
+	Total      int          `json:"total"`
+	Invalid    int          `json:"invalid"`
+	Violations []*Violation `json:"violations"`
+}
+
+// CheckDocuments validates all the documents of the doctype and returns a
+// report. At most limit violations are listed, but they are all counted.
+func (v *Validator) CheckDocuments(db prefixer.Prefixer, limit int) (*Report, error) {
+	report := &Report{Doctype: v.Doctype, Violations: []*Violation{}}
+	err := couchdb.ForeachDocs(db, v.Doctype, func(id string, raw json.RawMessage) error {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return err
+		}
+		report.Total++
+		err := v.Validate(doc)
+		if err == nil {
+			return nil
+		}
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			return err
+		}
+		report.Invalid++
+		if len(report.Violations) < limit {
+			rev, _ := doc["_rev"].(string)
+			report.Violations = append(report.Violations, &Violation{
+				ID:     id,
+				Rev:    rev,
+				Errors: verr.Details,
+			})
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return report, nil
+}
+
+func compileSchema(doctype string, schema interface{}) (*gojsonschema.Schema, error) {
+	var loader gojsonschema.JSONLoader
+	if str, ok := schema.(string); ok {
+		loader = gojsonschema.NewStringLoader(str)
+	} else {
+		loader = gojsonschema.NewGoLoader(schema)
+	}
+	compiled, err := gojsonschema.NewSchema(loader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid schema for %s: %s", ErrInvalidSchema, doctype, err)
+	}
+	return compiled, nil
+}
+
+func getValidatorsCache() *expirable.LRU[string, *Validator] {
+	initValidatorsCacheOnce.Do(func() {
+		validatorsCache = expirable.NewLRU[string, *Validator](validatorsCacheSize, nil, validatorsCacheTTL)
+	})
+	return validatorsCache
+}
+
+func validatorKey(db prefixer.Prefixer, doctype string) string {
+	return db.DBPrefix() + "/" + doctype
+}
@@ -0,0 +1,147 @@
+package doctype
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/justincampbell/bigduration"
+)
+
+// The fields used to mark a document as trashed.
+const (
+	TrashedField   = "trashed"
+	TrashedAtField = "trashed_at"
+)
+
+var (
+	// ErrNoTrash is used when the trash is not enabled for a doctype.
+	ErrNoTrash = errors.New("the trash is not enabled for this doctype")
+	// ErrAlreadyTrashed is used when trying to trash a document that is
+	// already in the trash.
+	ErrAlreadyTrashed = errors.New("the document is already in the trash")
+	// ErrNotTrashed is used when trying to restore a document that is not in
+	// the trash.
+	ErrNotTrashed = errors.New("the document is not in the trash")
+)
+
+// TrashDoctypes returns the doctypes of the data API that can be put in the
+// trash for the given context: the doctypes from the data_trash config, and
+// the custom doctypes declared with trash: true.
+func TrashDoctypes(contextName string) []string {
+	doctypes := append([]string{}, config.GetConfig().DataTrash.Doctypes...)
+	customs, _ := ForContext(contextName)
+	for name, custom := range customs {
+		if custom.Trash {
+			doctypes = append(doctypes, name)
+		}
+	}
+	return doctypes
+}
+
+// HasTrash returns true if the documents of the doctype can be put in the
+// trash.
+func HasTrash(contextName, doctype string) bool {
+	for _, typ := range TrashDoctypes(contextName) {
+		if typ == doctype {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTrashed returns true if the document is in the trash.
+func IsTrashed(doc *couchdb.JSONDoc) bool {
+	trashed, _ := doc.M[TrashedField].(bool)
+	return trashed
+}
+
+// Trash puts a document in the trash. It is kept in CouchDB with the trashed
+// flag, until it is restored or destroyed.
+func Trash(db prefixer.Prefixer, doc *couchdb.JSONDoc) error {
+	if IsTrashed(doc) {
+		return ErrAlreadyTrashed
+	}
+	doc.M[TrashedField] = true
+	doc.M[TrashedAtField] = time.Now().UTC()
+	return couchdb.UpdateDoc(db, doc)
+}
+
+// Restore takes a document out of the trash.
+func Restore(db prefixer.Prefixer, doc *couchdb.JSONDoc) error {
+	if !IsTrashed(doc) {
+		return ErrNotTrashed
+	}
+	delete(doc.M, TrashedField)
+	delete(doc.M, TrashedAtField)
+	return couchdb.UpdateDoc(db, doc)
+}
+
+// ListTrashed returns the documents of the doctype that are in the trash.
+func ListTrashed(db prefixer.Prefixer, doctype string) ([]*couchdb.JSONDoc, error) {
+	var docs []*couchdb.JSONDoc
+	err := couchdb.ForeachDocs(db, doctype, func(id string, raw json.RawMessage) error {
+		if strings.HasPrefix(id, "_design/") {
+			return nil
+		}
+		doc := &couchdb.JSONDoc{Type: doctype}
+		if err := json.Unmarshal(raw, &doc.M); err != nil {
+			return err
+		}
+		if IsTrashed(doc) {
+			docs = append(docs, doc)
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// PurgeTrashed destroys the documents of the doctype that have been put in
+// the trash before the given time. All the trashed documents are destroyed
+// if the time is zero. It returns the number of destroyed documents.
+func PurgeTrashed(db prefixer.Prefixer, doctype string, before time.Time) (int, error) {
+	trashed, err := ListTrashed(db, doctype)
+	if err != nil {
+		return 0, err
+	}
+	var docs []couchdb.Doc
+	for _, doc := range trashed {
+		if !before.IsZero() {
+			at, _ := doc.M[TrashedAtField].(string)
+			trashedAt, err := time.Parse(time.RFC3339Nano, at)
+			if err == nil && trashedAt.After(before) {
+				continue
+			}
+		}
+		docs = append(docs, doc)
+	}
+	if err := couchdb.BulkDeleteDocs(db, doctype, docs); err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+// AutoCleanTrashedAfter returns the delay after which the trashed documents
+// are destroyed for the given context, from the data_trash config.
+func AutoCleanTrashedAfter(contextName string) (time.Duration, bool) {
+	cfg := config.GetConfig().DataTrash.AutoCleanTrashedAfter
+	after, ok := cfg[contextName]
+	if !ok {
+		after, ok = cfg[config.DefaultInstanceContext]
+	}
+	if !ok || after == "" {
+		return 0, false
+	}
+	delay, err := bigduration.ParseDuration(after)
+	if err != nil {
+		return 0, false
+	}
+	return delay, true
+}
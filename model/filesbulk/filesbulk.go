@@ -0,0 +1,313 @@
+// Package filesbulk is for executing a batch of operations (move, copy, trash
+// and restore) on the files and directories of an instance. The caller is
+// expected to check all the operations (with Check and Load) before executing
+// the batch, so that a batch with an invalid operation is rejected as a whole.
+// But there is no transaction: an operation can still fail during the
+// execution, and the next ones are executed anyway. The large batches are
+// executed asynchronously by a job.
+package filesbulk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+)
+
+// WorkerType is the type of the worker that executes the large batches.
+const WorkerType = "files-bulk"
+
+// The supported operations.
+const (
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTrash   = "trash"
+	OpRestore = "restore"
+)
+
+// MaxOperations is the maximal number of operations in a batch.
+const MaxOperations = 10000
+
+// SyncLimit is the maximal number of operations of a batch executed during
+// the request. The larger batches are executed by a job.
+const SyncLimit = 100
+
+const (
+	// maxErrors is the maximal number of errors kept in a report.
+	maxErrors = 50
+	// progressStep is the number of operations between two progress events.
+	progressStep = 50
+)
+
+var (
+	// ErrNoOperation is used when the batch is empty.
+	ErrNoOperation = errors.New("The batch has no operation")
+	// ErrTooManyOperations is used when the batch has more than MaxOperations
+	// operations.
+	ErrTooManyOperations = fmt.Errorf("The batch has more than %d operations", MaxOperations)
+	// ErrInvalidOperation is used for an unknown operation, or an operation
+	// without the identifier of a file or directory.
+	ErrInvalidOperation = errors.New("The operation is invalid")
+	// ErrMissingDestination is used when a move or a copy has no destination
+	// directory.
+	ErrMissingDestination = errors.New("The destination directory is missing")
+	// ErrCopyDir is used when trying to copy a directory.
+	ErrCopyDir = errors.New("Only the files can be copied")
+)
+
+// Operation is an operation on a file or directory.
+type Operation struct {
+	Op string `json:"op"`
+	ID string `json:"id"`
+	// DirID is the destination directory for a move or a copy.
+	DirID string `json:"dir_id,omitempty"`
+	// Name is an optional new name for a move or a copy.
+	Name string `json:"name,omitempty"`
+}
+
+// Message is the message of the job that executes a batch.
+type Message struct {
+	Operations []Operation `json:"operations"`
+	// UpdatedBy is the app that has requested the operations, for the
+	// cozyMetadata of the files.
+	UpdatedBy *metadata.UpdatedByAppEntry `json:"updated_by,omitempty"`
+}
+
+// OperationError is an error for an operation of a batch.
+type OperationError struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// Report is the result of the execution of a batch.
+type Report struct {
+	Total  int              `json:"total"`
+	Done   int              `json:"done"`
+	Failed int              `json:"failed"`
+	Errors []OperationError `json:"errors,omitempty"`
+	// Copies are the identifiers of the files created by the copy
+	// operations, by index of the operation.
+	Copies map[int]string `json:"copies,omitempty"`
+}
+
+// Check validates the number of operations and their fields, without looking
+// at the files.
+func (m *Message) Check() error {
+	if len(m.Operations) == 0 {
+		return ErrNoOperation
+	}
+	if len(m.Operations) > MaxOperations {
+		return ErrTooManyOperations
+	}
+	for i := range m.Operations {
+		if err := m.Operations[i].check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasTrash returns true if the batch has an operation that puts a file or a
+// directory in the trash. Such a batch is refused while the instance is under
+// a legal hold: it is a mass deletion, and the trashed files would be purged
+// as soon as the hold is released.
+func (m *Message) HasTrash() bool {
+	for i := range m.Operations {
+		if m.Operations[i].Op == OpTrash {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Operation) check() error {
+	if o.ID == "" {
+		return ErrInvalidOperation
+	}
+	switch o.Op {
+	case OpMove, OpCopy:
+		if o.DirID == "" {
+			return ErrMissingDestination
+		}
+	case OpTrash, OpRestore:
+	default:
+		return ErrInvalidOperation
+	}
+	return nil
+}
+
+// Load returns the directory or file targeted by the operation, and checks
+// that the operation can be applied to it. It also returns the destination
+// directory for a move or a copy.
+func (o *Operation) Load(fs vfs.VFS) (*vfs.DirDoc, *vfs.FileDoc, *vfs.DirDoc, error) {
+	dir, file, err := fs.DirOrFileByID(o.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if dir != nil && (dir.ID() == consts.RootDirID || dir.ID() == consts.TrashDirID) {
+		return nil, nil, nil, vfs.ErrForbiddenDocMove
+	}
+	var inTrash bool
+	if dir != nil {
+		inTrash = strings.HasPrefix(dir.Fullpath, vfs.TrashDirName)
+	} else {
+		inTrash = file.Trashed
+	}
+
+	switch o.Op {
+	case OpTrash:
+		if inTrash {
+			return nil, nil, nil, vfs.ErrFileInTrash
+		}
+		return dir, file, nil, nil
+	case OpRestore:
+		if !inTrash {
+			return nil, nil, nil, vfs.ErrFileNotInTrash
+		}
+		return dir, file, nil, nil
+	}
+
+	if o.Op == OpCopy && dir != nil {
+		return nil, nil, nil, ErrCopyDir
+	}
+	dest, err := fs.DirByID(o.DirID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil, vfs.ErrParentDoesNotExist
+		}
+		return nil, nil, nil, err
+	}
+	if strings.HasPrefix(dest.Fullpath, vfs.TrashDirName) {
+		return nil, nil, nil, vfs.ErrParentInTrash
+	}
+	if dir != nil && (dest.ID() == dir.ID() || strings.HasPrefix(dest.Fullpath, dir.Fullpath+"/")) {
+		return nil, nil, nil, vfs.ErrForbiddenDocMove
+	}
+	return dir, file, dest, nil
+}
+
+// Execute executes the operations of the batch, in order. An operation that
+// fails doesn't stop the execution of the next ones, and the errors are
+// collected in the report. The progress function, if not nil, is called
+// regularly with the number of executed operations.
+func Execute(inst *instance.Instance, msg *Message, progress func(done, total int)) *Report {
+	fs := inst.VFS()
+	total := len(msg.Operations)
+	report := &Report{Total: total}
+	for i := range msg.Operations {
+		op := &msg.Operations[i]
+		newID, err := execute(inst, fs, op, msg.UpdatedBy)
+		if err != nil {
+			report.Failed++
+			if len(report.Errors) < maxErrors {
+				report.Errors = append(report.Errors, OperationError{
+					Index: i,
+					ID:    op.ID,
+					Error: err.Error(),
+				})
+			}
+		} else {
+			report.Done++
+			if newID != "" {
+				if report.Copies == nil {
+					report.Copies = make(map[int]string)
+				}
+				report.Copies[i] = newID
+			}
+		}
+		if progress != nil && ((i+1)%progressStep == 0 || i+1 == total) {
+			progress(i+1, total)
+		}
+	}
+	return report
+}
+
+func execute(inst *instance.Instance, fs vfs.VFS, op *Operation, by *metadata.UpdatedByAppEntry) (string, error) {
+	if op.Op == OpTrash {
+		if err := inst.CheckLegalHold(); err != nil {
+			return "", err
+		}
+	}
+	dir, file, dest, err := op.Load(fs)
+	if err != nil {
+		return "", err
+	}
+	if op.Op == OpCopy {
+		return copyFile(inst, fs, file, dest, op.Name, by)
+	}
+	if dir != nil {
+		dir.CozyMetadata = cozyMetadata(inst, dir.CozyMetadata, dir.CreatedAt, by)
+	} else {
+		file.CozyMetadata = cozyMetadata(inst, file.CozyMetadata, file.CreatedAt, by)
+	}
+
+	switch op.Op {
+	case OpMove:
+		patch := &vfs.DocPatch{DirID: &op.DirID}
+		if op.Name != "" {
+			patch.Name = &op.Name
+		}
+		if dir != nil {
+			_, err = vfs.ModifyDirMetadata(fs, dir, patch)
+		} else {
+			_, err = vfs.ModifyFileMetadata(fs, file, patch)
+		}
+	case OpTrash:
+		if dir != nil {
+			_, err = vfs.TrashDir(fs, dir)
+		} else {
+			_, err = vfs.TrashFile(fs, file)
+		}
+	case OpRestore:
+		if dir != nil {
+			_, err = vfs.RestoreDir(fs, dir)
+		} else {
+			_, err = vfs.RestoreFile(fs, file)
+		}
+	}
+	return "", err
+}
+
+// copyFile copies the file in the destination directory, and returns the
+// identifier of the copy. A suffix is added to the name in case of conflict.
+func copyFile(inst *instance.Instance, fs vfs.VFS, olddoc *vfs.FileDoc, dest *vfs.DirDoc, name string, by *metadata.UpdatedByAppEntry) (string, error) {
+	if name == "" {
+		name = olddoc.DocName
+	}
+	newdoc := vfs.CreateFileDocCopy(olddoc, dest.ID(), name)
+	exists, err := fs.GetIndexer().DirChildExists(newdoc.DirID, newdoc.DocName)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		newdoc.DocName = vfs.ConflictName(fs, newdoc.DirID, newdoc.DocName, true)
+	}
+	newdoc.ResetFullpath()
+	newdoc.CozyMetadata = cozyMetadata(inst, nil, newdoc.CreatedAt, by)
+	if err := fs.CopyFile(olddoc, newdoc); err != nil {
+		return "", err
+	}
+	return newdoc.ID(), nil
+}
+
+// cozyMetadata returns the cozyMetadata of a file or directory updated for
+// an operation made by the given app.
+func cozyMetadata(inst *instance.Instance, fcm *vfs.FilesCozyMetadata, createdAt time.Time, by *metadata.UpdatedByAppEntry) *vfs.FilesCozyMetadata {
+	if fcm == nil {
+		fcm = vfs.NewCozyMetadata(inst.PageURL("/", nil))
+		fcm.CreatedAt = createdAt
+	} else {
+		fcm.UpdatedAt = time.Now()
+	}
+	if by != nil {
+		fcm.UpdatedByApp(by)
+	}
+	return fcm
+}
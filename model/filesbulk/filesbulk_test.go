@@ -0,0 +1,43 @@
+package filesbulk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	msg := &Message{}
+	assert.Equal(t, ErrNoOperation, msg.Check())
+
+	msg.Operations = make([]Operation, MaxOperations+1)
+	assert.Equal(t, ErrTooManyOperations, msg.Check())
+
+	msg.Operations = []Operation{
+		{Op: OpMove, ID: "a", DirID: "dir"},
+		{Op: OpCopy, ID: "b", DirID: "dir", Name: "copy.txt"},
+		{Op: OpTrash, ID: "c"},
+		{Op: OpRestore, ID: "d"},
+	}
+	assert.NoError(t, msg.Check())
+
+	msg.Operations = []Operation{{Op: OpMove, ID: "a"}}
+	assert.Equal(t, ErrMissingDestination, msg.Check())
+	msg.Operations = []Operation{{Op: OpCopy, ID: "a"}}
+	assert.Equal(t, ErrMissingDestination, msg.Check())
+	msg.Operations = []Operation{{Op: OpTrash}}
+	assert.Equal(t, ErrInvalidOperation, msg.Check())
+	msg.Operations = []Operation{{Op: "delete", ID: "a"}}
+	assert.Equal(t, ErrInvalidOperation, msg.Check())
+}
+
+func TestHasTrash(t *testing.T) {
+	msg := &Message{Operations: []Operation{
+		{Op: OpMove, ID: "a", DirID: "dir"},
+		{Op: OpRestore, ID: "b"},
+	}}
+	assert.False(t, msg.HasTrash())
+
+	msg.Operations = append(msg.Operations, Operation{Op: OpTrash, ID: "c"})
+	assert.True(t, msg.HasTrash())
+}
@@ -0,0 +1,345 @@
+package gdrive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FolderMimeType is the mime-type used by Google Drive for the folders.
+const FolderMimeType = "application/vnd.google-apps.folder"
+
+// googleAppsPrefix is the prefix of the mime-types for the native Google
+// documents (Docs, Sheets, etc.), that have no binary content.
+const googleAppsPrefix = "application/vnd.google-apps."
+
+const fileFields = "id,name,mimeType,parents,md5Checksum,version,trashed,size,modifiedTime"
+
+var (
+	apiURL    = "https://www.googleapis.com/drive/v3"
+	uploadURL = "https://www.googleapis.com/upload/drive/v3"
+)
+
+// ErrUnauthorized is returned when the access token is no longer valid.
+var ErrUnauthorized = errors.New("gdrive: unauthorized")
+
+var driveClient = &http.Client{
+	Timeout: 5 * time.Minute,
+}
+
+// File is the representation of a file or folder in the Google Drive API.
+type File struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	MimeType     string    `json:"mimeType"`
+	Parents      []string  `json:"parents"`
+	MD5Checksum  string    `json:"md5Checksum"`
+	Version      string    `json:"version"`
+	Trashed      bool      `json:"trashed"`
+	Size         string    `json:"size"`
+	ModifiedTime time.Time `json:"modifiedTime"`
+}
+
+// IsDir returns true for a folder.
+func (f *File) IsDir() bool { return f.MimeType == FolderMimeType }
+
+// IsNative returns true for the Google documents that cannot be downloaded.
+func (f *File) IsNative() bool {
+	return !f.IsDir() && strings.HasPrefix(f.MimeType, googleAppsPrefix)
+}
+
+// ByteSize returns the size of the file in bytes.
+func (f *File) ByteSize() int64 {
+	size, _ := strconv.ParseInt(f.Size, 10, 64)
+	return size
+}
+
+// Parent returns the identifier of the parent folder.
+func (f *File) Parent() string {
+	if len(f.Parents) == 0 {
+		return ""
+	}
+	return f.Parents[0]
+}
+
+// Change is an item of the changes feed of Google Drive.
+type Change struct {
+	FileID  string `json:"fileId"`
+	Removed bool   `json:"removed"`
+	File    *File  `json:"file"`
+}
+
+// StorageQuota is the quota of the Google Drive account. The limit is 0 when
+// the storage is unlimited.
+type StorageQuota struct {
+	Limit int64
+	Usage int64
+}
+
+// Client is a minimal client for the v3 REST API of Google Drive.
+type Client struct {
+	token string
+}
+
+// NewClient returns a client that uses the given OAuth access token.
+func NewClient(accessToken string) *Client {
+	return &Client{token: accessToken}
+}
+
+func (c *Client) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	res, err := driveClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	if res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("gdrive: %s %s: %d %s", req.Method, req.URL.Path, res.StatusCode, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func (c *Client) get(path string, query url.Values, out interface{}) error {
+	u := apiURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) sendJSON(method, u string, body, out interface{}) error {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, out)
+}
+
+// StartPageToken returns the token to use for listing the future changes.
+func (c *Client) StartPageToken() (string, error) {
+	var res struct {
+		Token string `json:"startPageToken"`
+	}
+	if err := c.get("/changes/startPageToken", nil, &res); err != nil {
+		return "", err
+	}
+	return res.Token, nil
+}
+
+// Changes returns all the changes since the given page token, and the token
+// to use for the next call.
+func (c *Client) Changes(pageToken string) ([]Change, string, error) {
+	var changes []Change
+	for {
+		var res struct {
+			NextPageToken     string   `json:"nextPageToken"`
+			NewStartPageToken string   `json:"newStartPageToken"`
+			Changes           []Change `json:"changes"`
+		}
+		query := url.Values{
+			"pageToken": {pageToken},
+			"pageSize":  {"1000"},
+			"fields":    {"nextPageToken,newStartPageToken,changes(fileId,removed,file(" + fileFields + "))"},
+		}
+		if err := c.get("/changes", query, &res); err != nil {
+			return nil, "", err
+		}
+		changes = append(changes, res.Changes...)
+		if res.NewStartPageToken != "" {
+			return changes, res.NewStartPageToken, nil
+		}
+		if res.NextPageToken == "" {
+			return changes, pageToken, nil
+		}
+		pageToken = res.NextPageToken
+	}
+}
+
+// ListChildren returns the files and folders inside the given folder.
+func (c *Client) ListChildren(folderID string) ([]*File, error) {
+	var files []*File
+	pageToken := ""
+	for {
+		var res struct {
+			NextPageToken string  `json:"nextPageToken"`
+			Files         []*File `json:"files"`
+		}
+		query := url.Values{
+			"q":        {fmt.Sprintf("'%s' in parents and trashed = false", folderID)},
+			"pageSize": {"1000"},
+			"fields":   {"nextPageToken,files(" + fileFields + ")"},
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		if err := c.get("/files", query, &res); err != nil {
+			return nil, err
+		}
+		files = append(files, res.Files...)
+		if res.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = res.NextPageToken
+	}
+}
+
+// Download returns the content of a file. The caller must close it.
+func (c *Client) Download(fileID string) (io.ReadCloser, error) {
+	u := apiURL + "/files/" + url.PathEscape(fileID) + "?alt=media"
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	res, err := driveClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		res.Body.Close()
+		return nil, ErrUnauthorized
+	}
+	if res.StatusCode >= 300 {
+		res.Body.Close()
+		return nil, fmt.Errorf("gdrive: download %s: %d", fileID, res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+// CreateFolder creates a folder on Google Drive.
+func (c *Client) CreateFolder(name, parentID string) (*File, error) {
+	body := map[string]interface{}{
+		"name":     name,
+		"mimeType": FolderMimeType,
+		"parents":  []string{parentID},
+	}
+	var file File
+	u := apiURL + "/files?fields=" + url.QueryEscape(fileFields)
+	if err := c.sendJSON(http.MethodPost, u, body, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Upload creates a new file on Google Drive with the given content.
+func (c *Client) Upload(name, parentID, mime string, content io.Reader) (*File, error) {
+	meta, err := json.Marshal(map[string]interface{}{
+		"name":    name,
+		"parents": []string{parentID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(meta); err != nil {
+		return nil, err
+	}
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	part, err = w.CreatePart(textproto.MIMEHeader{"Content-Type": {mime}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	u := uploadURL + "/files?uploadType=multipart&fields=" + url.QueryEscape(fileFields)
+	req, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+w.Boundary())
+	var file File
+	if err := c.do(req, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// UpdateContent replaces the content of an existing file on Google Drive.
+func (c *Client) UpdateContent(fileID, mime string, content io.Reader) (*File, error) {
+	u := uploadURL + "/files/" + url.PathEscape(fileID) + "?uploadType=media&fields=" + url.QueryEscape(fileFields)
+	req, err := http.NewRequest(http.MethodPatch, u, content)
+	if err != nil {
+		return nil, err
+	}
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", mime)
+	var file File
+	if err := c.do(req, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Rename changes the name of a file or folder on Google Drive.
+func (c *Client) Rename(fileID, name string) (*File, error) {
+	var file File
+	u := apiURL + "/files/" + url.PathEscape(fileID) + "?fields=" + url.QueryEscape(fileFields)
+	if err := c.sendJSON(http.MethodPatch, u, map[string]interface{}{"name": name}, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Trash moves a file or folder to the trash of Google Drive.
+func (c *Client) Trash(fileID string) error {
+	u := apiURL + "/files/" + url.PathEscape(fileID)
+	return c.sendJSON(http.MethodPatch, u, map[string]interface{}{"trashed": true}, nil)
+}
+
+// Quota returns the storage quota of the Google Drive account.
+func (c *Client) Quota() (*StorageQuota, error) {
+	var res struct {
+		StorageQuota struct {
+			Limit string `json:"limit"`
+			Usage string `json:"usage"`
+		} `json:"storageQuota"`
+	}
+	query := url.Values{"fields": {"storageQuota(limit,usage)"}}
+	if err := c.get("/about", query, &res); err != nil {
+		return nil, err
+	}
+	quota := &StorageQuota{}
+	quota.Limit, _ = strconv.ParseInt(res.StorageQuota.Limit, 10, 64)
+	quota.Usage, _ = strconv.ParseInt(res.StorageQuota.Usage, 10, 64)
+	return quota, nil
+}
@@ -0,0 +1,676 @@
+package gdrive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/account"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/logger"
+)
+
+// engine is used for a single execution of a synchronization.
+type engine struct {
+	inst   *instance.Instance
+	fs     vfs.VFS
+	sync   *Sync
+	client *Client
+	log    logger.Logger
+
+	byRemote map[string]*Entry
+	byLocal  map[string]*Entry
+
+	quota          *StorageQuota
+	localFull      bool
+	remoteFull     bool
+	changedEntries map[string]*Entry
+}
+
+// Run executes the synchronization for the given account: the changes on
+// Google Drive are applied to the VFS, and then the changes in the VFS are
+// sent to Google Drive.
+func Run(inst *instance.Instance, syncID string) error {
+	s, err := Find(inst, syncID)
+	if err != nil {
+		return err
+	}
+	if !s.Enabled {
+		return nil
+	}
+
+	token, err := accessToken(inst, s.DocID)
+	if err == nil {
+		e := &engine{
+			inst:           inst,
+			fs:             inst.VFS(),
+			sync:           s,
+			client:         NewClient(token),
+			log:            inst.Logger().WithNamespace("gdrive"),
+			changedEntries: make(map[string]*Entry),
+		}
+		err = e.run()
+	}
+
+	now := time.Now().UTC()
+	s.LastSyncAt = &now
+	switch {
+	case err != nil:
+		s.Status = StatusErrored
+		s.LastError = err.Error()
+	default:
+		s.LastError = ""
+	}
+	if uerr := couchdb.UpdateDoc(inst, s); uerr != nil && err == nil {
+		err = uerr
+	}
+	return err
+}
+
+// accessToken returns a valid access token for the account, refreshing it if
+// needed.
+func accessToken(inst *instance.Instance, accountID string) (string, error) {
+	acc := &account.Account{}
+	if err := couchdb.GetDoc(inst, consts.Accounts, accountID, acc); err != nil {
+		return "", err
+	}
+	if acc.Oauth == nil || acc.Oauth.AccessToken == "" {
+		return "", ErrNoOAuth
+	}
+	if acc.Oauth.ExpiresAt.IsZero() || acc.Oauth.ExpiresAt.After(time.Now().Add(time.Minute)) {
+		return acc.Oauth.AccessToken, nil
+	}
+	typ, err := account.TypeInfo(acc.AccountType, inst.ContextName)
+	if err != nil {
+		return "", err
+	}
+	if err := typ.RefreshAccount(*acc); err != nil {
+		return "", err
+	}
+	if err := couchdb.UpdateDoc(inst, acc); err != nil {
+		return "", err
+	}
+	return acc.Oauth.AccessToken, nil
+}
+
+func (e *engine) run() error {
+	entries, err := listEntries(e.inst, e.sync.DocID)
+	if err != nil {
+		return err
+	}
+	e.byRemote = make(map[string]*Entry, len(entries))
+	e.byLocal = make(map[string]*Entry, len(entries))
+	for _, entry := range entries {
+		e.byRemote[entry.RemoteID] = entry
+		e.byLocal[entry.LocalID] = entry
+	}
+
+	root, err := e.fs.DirByID(e.sync.DirID)
+	if err != nil {
+		return err
+	}
+
+	if e.sync.PageToken == "" {
+		// First synchronization: the changes feed is not usable for the
+		// files that already exist, so the remote tree is walked.
+		token, err := e.client.StartPageToken()
+		if err != nil {
+			return err
+		}
+		if err := e.pullTree(e.sync.RemoteFolderID, root); err != nil {
+			return err
+		}
+		e.sync.PageToken = token
+	} else {
+		changes, token, err := e.client.Changes(e.sync.PageToken)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := e.applyRemoteChange(change); err != nil {
+				e.log.Warnf("Cannot apply change for %s: %s", change.FileID, err)
+			}
+		}
+		e.sync.PageToken = token
+	}
+
+	if err := e.pushTree(root, e.sync.RemoteFolderID); err != nil {
+		return err
+	}
+	if err := e.pushDeletions(); err != nil {
+		return err
+	}
+	if err := e.saveEntries(); err != nil {
+		return err
+	}
+
+	switch {
+	case e.localFull:
+		e.sync.Status = StatusQuotaExceeded
+	case e.remoteFull:
+		e.sync.Status = StatusRemoteQuotaExceeded
+	default:
+		e.sync.Status = StatusIdle
+	}
+	return nil
+}
+
+func (e *engine) saveEntries() error {
+	if len(e.changedEntries) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, 0, len(e.changedEntries))
+	olds := make([]interface{}, 0, len(e.changedEntries))
+	var deleted []couchdb.Doc
+	for _, entry := range e.changedEntries {
+		if entry.LocalID == "" {
+			if entry.DocRev != "" {
+				deleted = append(deleted, entry)
+			}
+			continue
+		}
+		docs = append(docs, entry)
+		olds = append(olds, nil)
+	}
+	if len(docs) > 0 {
+		if err := couchdb.EnsureDBExist(e.inst, consts.GDriveEntries); err != nil {
+			return err
+		}
+		if err := couchdb.BulkUpdateDocs(e.inst, consts.GDriveEntries, docs, olds); err != nil {
+			return err
+		}
+	}
+	if len(deleted) > 0 {
+		return couchdb.BulkDeleteDocs(e.inst, consts.GDriveEntries, deleted)
+	}
+	return nil
+}
+
+func (e *engine) track(entry *Entry) {
+	if entry.DocID == "" {
+		entry.DocID = entryID(e.sync.DocID, entry.RemoteID)
+	}
+	e.byRemote[entry.RemoteID] = entry
+	e.byLocal[entry.LocalID] = entry
+	e.changedEntries[entry.DocID] = entry
+}
+
+func (e *engine) untrack(entry *Entry) {
+	delete(e.byRemote, entry.RemoteID)
+	delete(e.byLocal, entry.LocalID)
+	entry.LocalID = ""
+	e.changedEntries[entry.DocID] = entry
+}
+
+// pullTree walks the remote folder and creates the missing files and
+// directories in the VFS.
+func (e *engine) pullTree(remoteID string, dir *vfs.DirDoc) error {
+	children, err := e.client.ListChildren(remoteID)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if child.IsNative() {
+			continue
+		}
+		if child.IsDir() {
+			sub, err := e.pullDir(child, dir)
+			if err != nil {
+				e.log.Warnf("Cannot create dir for %s: %s", child.ID, err)
+				continue
+			}
+			if err := e.pullTree(child.ID, sub); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.pullFile(child, dir); err != nil {
+			e.log.Warnf("Cannot download %s: %s", child.ID, err)
+		}
+	}
+	return nil
+}
+
+func (e *engine) pullDir(remote *File, parent *vfs.DirDoc) (*vfs.DirDoc, error) {
+	if entry, ok := e.byRemote[remote.ID]; ok {
+		return e.fs.DirByID(entry.LocalID)
+	}
+	name := remote.Name
+	if exists, _ := e.fs.DirChildExists(parent.DocID, name); exists {
+		fullpath := path.Join(parent.Fullpath, name)
+		if dir, err := e.fs.DirByPath(fullpath); err == nil {
+			if _, tracked := e.byLocal[dir.DocID]; !tracked {
+				// Reuse a directory that already exists with the same name
+				e.track(&Entry{RemoteID: remote.ID, LocalID: dir.DocID, IsDir: true, Name: name})
+				return dir, nil
+			}
+		}
+		name = vfs.ConflictName(e.fs, parent.DocID, name, false)
+	}
+	dir, err := vfs.NewDirDocWithParent(name, parent, nil)
+	if err != nil {
+		return nil, err
+	}
+	dir.CozyMetadata = vfs.NewCozyMetadata(e.inst.PageURL("/", nil))
+	if err := e.fs.CreateDir(dir); err != nil {
+		return nil, err
+	}
+	e.track(&Entry{RemoteID: remote.ID, LocalID: dir.DocID, IsDir: true, Name: name})
+	return dir, nil
+}
+
+// download writes the content of a remote file in the VFS. olddoc is nil
+// for a new file.
+func (e *engine) download(remote *File, dirID, name string, olddoc *vfs.FileDoc) (*vfs.FileDoc, error) {
+	if e.localFull {
+		return nil, vfs.ErrFileTooBig
+	}
+	mime, class := vfs.ExtractMimeAndClassFromFilename(name)
+	if remote.MimeType != "" {
+		mime, class = vfs.ExtractMimeAndClass(remote.MimeType)
+	}
+	newdoc, err := vfs.NewFileDoc(name, dirID, remote.ByteSize(), nil, mime, class,
+		remote.ModifiedTime, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	if olddoc != nil {
+		newdoc.SetID(olddoc.ID())
+		newdoc.CreatedAt = olddoc.CreatedAt
+		newdoc.Tags = olddoc.Tags
+		newdoc.ReferencedBy = olddoc.ReferencedBy
+		if olddoc.CozyMetadata != nil {
+			newdoc.CozyMetadata = olddoc.CozyMetadata.Clone()
+		}
+	}
+	if newdoc.CozyMetadata == nil {
+		newdoc.CozyMetadata = vfs.NewCozyMetadata(e.inst.PageURL("/", nil))
+	}
+	now := time.Now()
+	newdoc.CozyMetadata.UpdatedAt = now
+	newdoc.CozyMetadata.UploadedAt = &now
+	newdoc.CozyMetadata.UploadedOn = e.inst.PageURL("/", nil)
+	newdoc.CozyMetadata.UploadedBy = &vfs.UploadedByEntry{Slug: WorkerType}
+
+	// Quota awareness: the download is skipped if the file would not fit in
+	// the disk quota of the instance.
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(e.fs, newdoc); err != nil {
+		if errors.Is(err, vfs.ErrFileTooBig) {
+			e.localFull = true
+		}
+		return nil, err
+	}
+
+	content, err := e.client.Download(remote.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	file, err := e.fs.CreateFile(newdoc, olddoc)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(file, content)
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newdoc, nil
+}
+
+func (e *engine) pullFile(remote *File, parent *vfs.DirDoc) error {
+	if _, ok := e.byRemote[remote.ID]; ok {
+		return nil
+	}
+	name := remote.Name
+	if exists, _ := e.fs.DirChildExists(parent.DocID, name); exists {
+		fullpath := path.Join(parent.Fullpath, name)
+		if file, err := e.fs.FileByPath(fullpath); err == nil {
+			if _, tracked := e.byLocal[file.DocID]; !tracked && md5Equal(file.MD5Sum, remote.MD5Checksum) {
+				// Same content on both sides: no need to download it
+				e.track(&Entry{
+					RemoteID:      remote.ID,
+					LocalID:       file.DocID,
+					Name:          name,
+					RemoteMD5:     remote.MD5Checksum,
+					RemoteVersion: remote.Version,
+					LocalMD5:      file.MD5Sum,
+				})
+				return nil
+			}
+		}
+		name = vfs.ConflictName(e.fs, parent.DocID, name, true)
+	}
+	doc, err := e.download(remote, parent.DocID, name, nil)
+	if err != nil {
+		return err
+	}
+	e.track(&Entry{
+		RemoteID:      remote.ID,
+		LocalID:       doc.DocID,
+		Name:          name,
+		RemoteMD5:     remote.MD5Checksum,
+		RemoteVersion: remote.Version,
+		LocalMD5:      doc.MD5Sum,
+	})
+	return nil
+}
+
+// localParent returns the local directory for a remote parent, if it is
+// inside the synchronized tree.
+func (e *engine) localParent(remoteParentID string) (*vfs.DirDoc, bool) {
+	if remoteParentID == e.sync.RemoteFolderID {
+		dir, err := e.fs.DirByID(e.sync.DirID)
+		return dir, err == nil
+	}
+	entry, ok := e.byRemote[remoteParentID]
+	if !ok || !entry.IsDir {
+		return nil, false
+	}
+	dir, err := e.fs.DirByID(entry.LocalID)
+	if err != nil || dir.DirID == consts.TrashDirID || strings.HasPrefix(dir.Fullpath, vfs.TrashDirName) {
+		return nil, false
+	}
+	return dir, true
+}
+
+func (e *engine) applyRemoteChange(change Change) error {
+	entry, known := e.byRemote[change.FileID]
+	remote := change.File
+	removed := change.Removed || remote == nil || remote.Trashed
+	var parent *vfs.DirDoc
+	inTree := false
+	if !removed {
+		parent, inTree = e.localParent(remote.Parent())
+	}
+
+	if !known {
+		if removed || !inTree || remote.IsNative() {
+			return nil
+		}
+		if remote.IsDir() {
+			dir, err := e.pullDir(remote, parent)
+			if err != nil {
+				return err
+			}
+			return e.pullTree(remote.ID, dir)
+		}
+		return e.pullFile(remote, parent)
+	}
+
+	// The file has been removed from Google Drive, or moved outside of the
+	// synchronized folder.
+	if removed || !inTree {
+		return e.trashLocal(entry)
+	}
+
+	if entry.IsDir {
+		dir, err := e.fs.DirByID(entry.LocalID)
+		if err != nil {
+			e.untrack(entry)
+			return err
+		}
+		if dir.DocName == remote.Name && dir.DirID == parent.DocID {
+			return nil
+		}
+		name, dirID := remote.Name, parent.DocID
+		if _, err := vfs.ModifyDirMetadata(e.fs, dir, &vfs.DocPatch{Name: &name, DirID: &dirID}); err != nil {
+			return err
+		}
+		entry.Name = name
+		e.track(entry)
+		return nil
+	}
+
+	file, err := e.fs.FileByID(entry.LocalID)
+	if err != nil {
+		e.untrack(entry)
+		return err
+	}
+	if file.DocName != remote.Name || file.DirID != parent.DocID {
+		name, dirID := remote.Name, parent.DocID
+		file, err = vfs.ModifyFileMetadata(e.fs, file, &vfs.DocPatch{Name: &name, DirID: &dirID})
+		if err != nil {
+			return err
+		}
+		entry.Name = name
+		e.track(entry)
+	}
+	if remote.MD5Checksum == entry.RemoteMD5 {
+		return nil
+	}
+
+	localChanged := !bytes.Equal(file.MD5Sum, entry.LocalMD5)
+	if localChanged && !md5Equal(file.MD5Sum, remote.MD5Checksum) {
+		return e.resolveConflict(entry, remote, file)
+	}
+	doc, err := e.download(remote, file.DirID, file.DocName, file)
+	if err != nil {
+		return err
+	}
+	entry.RemoteMD5 = remote.MD5Checksum
+	entry.RemoteVersion = remote.Version
+	entry.LocalMD5 = doc.MD5Sum
+	e.track(entry)
+	return nil
+}
+
+// resolveConflict is called when a file has been modified on both sides
+// since the last synchronization.
+func (e *engine) resolveConflict(entry *Entry, remote *File, file *vfs.FileDoc) error {
+	policy := e.sync.ConflictPolicy
+	if policy == NewestWins {
+		if remote.ModifiedTime.After(file.UpdatedAt) {
+			policy = DriveWins
+		} else {
+			policy = CozyWins
+		}
+	}
+	e.log.Infof("Conflict on %s, resolved with %s", file.DocID, policy)
+
+	switch policy {
+	case DriveWins:
+		doc, err := e.download(remote, file.DirID, file.DocName, file)
+		if err != nil {
+			return err
+		}
+		entry.LocalMD5 = doc.MD5Sum
+	case CozyWins:
+		// The local version will be uploaded by pushTree
+	default: // KeepBoth
+		ext := path.Ext(file.DocName)
+		base := strings.TrimSuffix(file.DocName, ext)
+		name := fmt.Sprintf("%s (Google Drive conflict)%s", base, ext)
+		if exists, _ := e.fs.DirChildExists(file.DirID, name); exists {
+			name = vfs.ConflictName(e.fs, file.DirID, name, true)
+		}
+		if _, err := e.download(remote, file.DirID, name, nil); err != nil {
+			return err
+		}
+	}
+	entry.RemoteMD5 = remote.MD5Checksum
+	entry.RemoteVersion = remote.Version
+	e.track(entry)
+	return nil
+}
+
+func (e *engine) trashLocal(entry *Entry) error {
+	defer e.untrack(entry)
+	if entry.IsDir {
+		dir, err := e.fs.DirByID(entry.LocalID)
+		if err != nil {
+			return nil
+		}
+		_, err = vfs.TrashDir(e.fs, dir)
+		return err
+	}
+	file, err := e.fs.FileByID(entry.LocalID)
+	if err != nil || file.Trashed {
+		return nil
+	}
+	if !bytes.Equal(file.MD5Sum, entry.LocalMD5) {
+		// The file has been modified in the Cozy: it is kept, and it will be
+		// uploaded again as a new file on the next synchronization.
+		return nil
+	}
+	_, err = vfs.TrashFile(e.fs, file)
+	return err
+}
+
+// pushTree walks the local directory and sends the new and modified files
+// and directories to Google Drive.
+func (e *engine) pushTree(dir *vfs.DirDoc, remoteID string) error {
+	iter := e.fs.DirIterator(dir, nil)
+	for {
+		d, f, err := iter.Next()
+		if errors.Is(err, vfs.ErrIteratorDone) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			subRemoteID, err := e.pushDir(d, remoteID)
+			if err != nil {
+				e.log.Warnf("Cannot push dir %s: %s", d.DocID, err)
+				continue
+			}
+			if err := e.pushTree(d, subRemoteID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.pushFile(f, remoteID); err != nil {
+			e.log.Warnf("Cannot push file %s: %s", f.DocID, err)
+		}
+	}
+}
+
+func (e *engine) pushDir(dir *vfs.DirDoc, parentRemoteID string) (string, error) {
+	if entry, ok := e.byLocal[dir.DocID]; ok {
+		if entry.Name != dir.DocName {
+			if _, err := e.client.Rename(entry.RemoteID, dir.DocName); err != nil {
+				return "", err
+			}
+			entry.Name = dir.DocName
+			e.track(entry)
+		}
+		return entry.RemoteID, nil
+	}
+	remote, err := e.client.CreateFolder(dir.DocName, parentRemoteID)
+	if err != nil {
+		return "", err
+	}
+	e.track(&Entry{RemoteID: remote.ID, LocalID: dir.DocID, IsDir: true, Name: dir.DocName})
+	return remote.ID, nil
+}
+
+func (e *engine) pushFile(file *vfs.FileDoc, parentRemoteID string) error {
+	entry, known := e.byLocal[file.DocID]
+	if known && entry.Name != file.DocName {
+		if _, err := e.client.Rename(entry.RemoteID, file.DocName); err != nil {
+			return err
+		}
+		entry.Name = file.DocName
+		e.track(entry)
+	}
+	if known && bytes.Equal(file.MD5Sum, entry.LocalMD5) {
+		return nil
+	}
+	if e.remoteFull || !e.hasRemoteSpace(file.ByteSize) {
+		e.remoteFull = true
+		return errors.New("not enough space on Google Drive")
+	}
+
+	content, err := e.fs.OpenFile(file)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	var remote *File
+	if known {
+		remote, err = e.client.UpdateContent(entry.RemoteID, file.Mime, content)
+	} else {
+		remote, err = e.client.Upload(file.DocName, parentRemoteID, file.Mime, content)
+	}
+	if err != nil {
+		return err
+	}
+	if e.quota != nil {
+		e.quota.Usage += file.ByteSize
+	}
+	if !known {
+		entry = &Entry{RemoteID: remote.ID, LocalID: file.DocID, Name: file.DocName}
+	}
+	entry.RemoteMD5 = remote.MD5Checksum
+	entry.RemoteVersion = remote.Version
+	entry.LocalMD5 = file.MD5Sum
+	e.track(entry)
+	return nil
+}
+
+func (e *engine) hasRemoteSpace(size int64) bool {
+	if e.quota == nil {
+		quota, err := e.client.Quota()
+		if err != nil {
+			return true
+		}
+		e.quota = quota
+	}
+	return e.quota.Limit <= 0 || e.quota.Usage+size <= e.quota.Limit
+}
+
+// pushDeletions trashes on Google Drive the files and directories that have
+// been removed from the synchronized directory in the Cozy.
+func (e *engine) pushDeletions() error {
+	root, err := e.fs.DirByID(e.sync.DirID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range e.byRemote {
+		var fullpath string
+		if entry.IsDir {
+			dir, err := e.fs.DirByID(entry.LocalID)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if dir != nil {
+				fullpath = dir.Fullpath
+			}
+		} else {
+			file, err := e.fs.FileByID(entry.LocalID)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if file != nil && !file.Trashed {
+				fullpath, _ = file.Path(e.fs)
+			}
+		}
+		if fullpath != "" && strings.HasPrefix(fullpath, root.Fullpath+"/") {
+			continue
+		}
+		if err := e.client.Trash(entry.RemoteID); err != nil {
+			e.log.Warnf("Cannot trash %s: %s", entry.RemoteID, err)
+			continue
+		}
+		e.untrack(entry)
+	}
+	return nil
+}
+
+// md5Equal compares a md5sum from the VFS with a hex-encoded md5 checksum
+// from Google Drive.
+func md5Equal(sum []byte, checksum string) bool {
+	return len(sum) > 0 && fmt.Sprintf("%x", sum) == checksum
+}
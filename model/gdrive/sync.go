@@ -0,0 +1,295 @@
+// Package gdrive is for the two-way synchronization between a folder of
+// Google Drive and a directory of the Cozy VFS. The synchronization is
+// configured per io.cozy.accounts document (the OAuth tokens of the account
+// are used to access the Google Drive API), and is executed periodically by
+// the gdrive-sync worker.
+package gdrive
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// WorkerType is the type of the worker that executes the synchronizations.
+const WorkerType = "gdrive-sync"
+
+// The rules to apply when a file has been modified on both sides since the
+// last synchronization.
+const (
+	// KeepBoth keeps the version of the Cozy, and the version of Google Drive
+	// is saved next to it with a conflict suffix.
+	KeepBoth = "keep_both"
+	// CozyWins keeps the version of the Cozy, and overwrites Google Drive.
+	CozyWins = "cozy_wins"
+	// DriveWins keeps the version of Google Drive, and overwrites the Cozy
+	// (the previous content is still available as an old version).
+	DriveWins = "drive_wins"
+	// NewestWins keeps the version that has been modified the last.
+	NewestWins = "newest_wins"
+)
+
+// The status of a synchronization.
+const (
+	StatusIdle                = "idle"
+	StatusErrored             = "errored"
+	StatusQuotaExceeded       = "quota_exceeded"
+	StatusRemoteQuotaExceeded = "remote_quota_exceeded"
+)
+
+// DefaultFrequency is the default delay between two synchronizations.
+const DefaultFrequency = "30m"
+
+var (
+	// ErrInvalidConflictPolicy is used when the conflict policy is unknown.
+	ErrInvalidConflictPolicy = errors.New("invalid conflict policy")
+	// ErrNoOAuth is used when the account has no OAuth tokens.
+	ErrNoOAuth = errors.New("the account has no OAuth tokens")
+)
+
+// Sync is the configuration and the state of the synchronization for an
+// account. Its identifier is the identifier of the account.
+type Sync struct {
+	DocID          string     `json:"_id,omitempty"`
+	DocRev         string     `json:"_rev,omitempty"`
+	RemoteFolderID string     `json:"remote_folder_id"`
+	DirID          string     `json:"dir_id"`
+	ConflictPolicy string     `json:"conflict_policy"`
+	Frequency      string     `json:"frequency"`
+	Enabled        bool       `json:"enabled"`
+	TriggerID      string     `json:"trigger_id,omitempty"`
+	PageToken      string     `json:"page_token,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	LastSyncAt     *time.Time `json:"last_sync_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (s *Sync) ID() string { return s.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (s *Sync) Rev() string { return s.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (s *Sync) DocType() string { return consts.GDriveSyncs }
+
+// Clone implements the couchdb.Doc interface
+func (s *Sync) Clone() couchdb.Doc {
+	cloned := *s
+	if s.LastSyncAt != nil {
+		at := *s.LastSyncAt
+		cloned.LastSyncAt = &at
+	}
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (s *Sync) SetID(id string) { s.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (s *Sync) SetRev(rev string) { s.DocRev = rev }
+
+// Relationships implements the jsonapi.Object interface
+func (s *Sync) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Included implements the jsonapi.Object interface
+func (s *Sync) Included() []jsonapi.Object { return nil }
+
+// Links implements the jsonapi.Object interface
+func (s *Sync) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/settings/gdrive/" + s.DocID}
+}
+
+// Validate checks the configuration of the synchronization, and fills the
+// default values.
+func (s *Sync) Validate() error {
+	if s.RemoteFolderID == "" {
+		s.RemoteFolderID = "root"
+	}
+	if s.DirID == "" {
+		return errors.New("the dir_id is missing")
+	}
+	switch s.ConflictPolicy {
+	case "":
+		s.ConflictPolicy = KeepBoth
+	case KeepBoth, CozyWins, DriveWins, NewestWins:
+	default:
+		return ErrInvalidConflictPolicy
+	}
+	if s.Frequency == "" {
+		s.Frequency = DefaultFrequency
+	}
+	if d, err := time.ParseDuration(s.Frequency); err != nil || d < 5*time.Minute {
+		return errors.New("the frequency must be a duration of at least 5 minutes")
+	}
+	return nil
+}
+
+// Entry is the state of a file or folder at the last synchronization. It is
+// used to detect the changes on both sides. Its identifier is the identifier
+// of the sync, followed by a slash and the identifier of the remote file.
+type Entry struct {
+	DocID    string `json:"_id,omitempty"`
+	DocRev   string `json:"_rev,omitempty"`
+	RemoteID string `json:"remote_id"`
+	LocalID  string `json:"local_id"`
+	IsDir    bool   `json:"is_dir,omitempty"`
+	Name     string `json:"name"`
+	// RemoteMD5 and RemoteVersion are the md5 and version of the file on
+	// Google Drive at the last synchronization.
+	RemoteMD5     string `json:"remote_md5,omitempty"`
+	RemoteVersion string `json:"remote_version,omitempty"`
+	// LocalMD5 is the md5sum of the file in the VFS at the last
+	// synchronization.
+	LocalMD5 []byte `json:"local_md5,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (e *Entry) ID() string { return e.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (e *Entry) Rev() string { return e.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (e *Entry) DocType() string { return consts.GDriveEntries }
+
+// Clone implements the couchdb.Doc interface
+func (e *Entry) Clone() couchdb.Doc {
+	cloned := *e
+	cloned.LocalMD5 = make([]byte, len(e.LocalMD5))
+	copy(cloned.LocalMD5, e.LocalMD5)
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (e *Entry) SetID(id string) { e.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (e *Entry) SetRev(rev string) { e.DocRev = rev }
+
+// Find returns the synchronization for the given account.
+func Find(db prefixer.Prefixer, accountID string) (*Sync, error) {
+	doc := &Sync{}
+	if err := couchdb.GetDoc(db, consts.GDriveSyncs, accountID, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// List returns all the synchronizations of the instance.
+func List(db prefixer.Prefixer) ([]*Sync, error) {
+	var docs []*Sync
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	err := couchdb.GetAllDocs(db, consts.GDriveSyncs, req, &docs)
+	if couchdb.IsNoDatabaseError(err) {
+		return []*Sync{}, nil
+	}
+	return docs, err
+}
+
+// Save creates or updates the synchronization, and its trigger.
+func Save(inst *instance.Instance, s *Sync) error {
+	if err := ensureTrigger(inst, s); err != nil {
+		return err
+	}
+	if s.DocRev == "" {
+		return couchdb.CreateNamedDocWithDB(inst, s)
+	}
+	return couchdb.UpdateDoc(inst, s)
+}
+
+// Delete removes the synchronization, its trigger and its entries. The files
+// are kept on both sides.
+func Delete(inst *instance.Instance, s *Sync) error {
+	if s.TriggerID != "" {
+		err := job.System().DeleteTrigger(inst, s.TriggerID)
+		if err != nil && err != job.ErrNotFoundTrigger {
+			return err
+		}
+	}
+	entries, err := listEntries(inst, s.DocID)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		docs := make([]couchdb.Doc, len(entries))
+		for i, e := range entries {
+			docs[i] = e
+		}
+		if err := couchdb.BulkDeleteDocs(inst, consts.GDriveEntries, docs); err != nil {
+			return err
+		}
+	}
+	return couchdb.DeleteDoc(inst, s)
+}
+
+// PushJob asks the worker to run the synchronization now.
+func PushJob(inst *instance.Instance, s *Sync) (*job.Job, error) {
+	msg, err := job.NewMessage(map[string]string{"sync_id": s.DocID})
+	if err != nil {
+		return nil, err
+	}
+	return job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: WorkerType,
+		Message:    msg,
+		Manual:     true,
+	})
+}
+
+// ensureTrigger creates the @every trigger for an enabled synchronization,
+// and removes it for a disabled one.
+func ensureTrigger(inst *instance.Instance, s *Sync) error {
+	sched := job.System()
+	if s.TriggerID != "" {
+		t, err := sched.GetTrigger(inst, s.TriggerID)
+		if err == nil && s.Enabled && t.Infos().Arguments == s.Frequency {
+			return nil
+		}
+		if err == nil {
+			if err := sched.DeleteTrigger(inst, s.TriggerID); err != nil {
+				return err
+			}
+		}
+		s.TriggerID = ""
+	}
+	if !s.Enabled {
+		return nil
+	}
+	infos := job.TriggerInfos{
+		Type:       "@every",
+		WorkerType: WorkerType,
+		Arguments:  s.Frequency,
+	}
+	t, err := job.NewTrigger(inst, infos, map[string]string{"sync_id": s.DocID})
+	if err != nil {
+		return err
+	}
+	if err := sched.AddTrigger(t); err != nil {
+		return err
+	}
+	s.TriggerID = t.ID()
+	return nil
+}
+
+func entryID(syncID, remoteID string) string {
+	return syncID + "/" + remoteID
+}
+
+func listEntries(db prefixer.Prefixer, syncID string) ([]*Entry, error) {
+	var entries []*Entry
+	req := &couchdb.AllDocsRequest{
+		StartKey: syncID + "/",
+		EndKey:   syncID + "0", // 0 is the next character after / in ascii
+	}
+	err := couchdb.GetAllDocs(db, consts.GDriveEntries, req, &entries)
+	if couchdb.IsNoDatabaseError(err) {
+		return nil, nil
+	}
+	return entries, err
+}
@@ -0,0 +1,37 @@
+package gdrive
+
+import (
+	"crypto/md5"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	s := &Sync{DirID: "dir-id"}
+	require.NoError(t, s.Validate())
+	assert.Equal(t, "root", s.RemoteFolderID)
+	assert.Equal(t, KeepBoth, s.ConflictPolicy)
+	assert.Equal(t, DefaultFrequency, s.Frequency)
+
+	s = &Sync{}
+	assert.Error(t, s.Validate())
+
+	s = &Sync{DirID: "dir-id", ConflictPolicy: "foo"}
+	assert.Equal(t, ErrInvalidConflictPolicy, s.Validate())
+
+	s = &Sync{DirID: "dir-id", Frequency: "1m"}
+	assert.Error(t, s.Validate())
+
+	s = &Sync{DirID: "dir-id", ConflictPolicy: NewestWins, Frequency: "2h"}
+	assert.NoError(t, s.Validate())
+}
+
+func TestMD5Equal(t *testing.T) {
+	sum := md5.Sum([]byte("foo"))
+	assert.True(t, md5Equal(sum[:], fmt.Sprintf("%x", sum)))
+	assert.False(t, md5Equal(sum[:], "acbd18db4cc2f85cedef654fccc4a4d9"))
+	assert.False(t, md5Equal(nil, ""))
+}
@@ -31,4 +31,7 @@ var (
 	ErrInvalidSwiftLayout = errors.New("Invalid Swift layout")
 	// ErrDeletionAlreadyRequested is returned when a deletion has already been requested.
 	ErrDeletionAlreadyRequested = errors.New("The deletion has already been requested")
+	// ErrLegalHold is returned when some data can't be destroyed because the
+	// instance is under a legal hold.
+	ErrLegalHold = errors.New("The instance is under a legal hold, its data can't be destroyed")
 )
@@ -0,0 +1,46 @@
+package instance
+
+// The consent rules for the data escrow.
+const (
+	// EscrowConsentOptIn is used when the shared folders are transferred only
+	// if the user has accepted it.
+	EscrowConsentOptIn = "opt_in"
+	// EscrowConsentOptOut is used when the shared folders are transferred
+	// unless the user has refused it.
+	EscrowConsentOptOut = "opt_out"
+)
+
+// DataEscrowOwner returns the domain of the instance that receives the shared
+// folders of this instance when it is destroyed, or an empty string if they
+// must not be transferred. It is configured with the data_escrow parameter of
+// the context, and the consent of the user is read from the
+// data_escrow_consent field of the instance settings.
+func (i *Instance) DataEscrowOwner() string {
+	ctxSettings, ok := i.SettingsContext()
+	if !ok {
+		return ""
+	}
+	cfg, ok := ctxSettings["data_escrow"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	owner, _ := cfg["owner"].(string)
+	if owner == "" || owner == i.Domain {
+		return ""
+	}
+
+	var consent, given bool
+	if doc, err := i.SettingsDocument(); err == nil {
+		consent, given = doc.M["data_escrow_consent"].(bool)
+	}
+	if rule, _ := cfg["consent"].(string); rule == EscrowConsentOptOut {
+		if given && !consent {
+			return ""
+		}
+		return owner
+	}
+	if !consent {
+		return ""
+	}
+	return owner
+}
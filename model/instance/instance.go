@@ -3,6 +3,7 @@ package instance
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
@@ -22,6 +23,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/lock"
 	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/cozy-stack/pkg/realtime"
 	"github.com/golang-jwt/jwt/v5"
@@ -56,6 +58,7 @@ type Instance struct {
 	TOSLatest       string   `json:"tos_latest,omitempty"`       // Terms of Service latest version
 	AuthMode        AuthMode `json:"auth_mode,omitempty"`        // 2 factor authentication
 	MagicLink       bool     `json:"magic_link,omitempty"`       // Authentication via a link sent by email
+	Organization    bool     `json:"organization,omitempty"`     // Several users can log in, with their own credentials
 	Deleting        bool     `json:"deleting,omitempty"`
 	Moved           bool     `json:"moved,omitempty"`           // If the instance has been moved to a new place
 	Blocked         bool     `json:"blocked,omitempty"`         // Whether or not the instance is blocked
@@ -99,12 +102,24 @@ type Instance struct {
 	OAuthSecret []byte `json:"oauth_secret,omitempty"`
 	// CLISecret is used to authenticate request from the CLI
 	CLISecret []byte `json:"cli_secret,omitempty"`
+	// OIDCKey is the private RSA key (PKCS #8) used to sign the ID tokens of
+	// OpenID Connect. It is generated on the first use.
+	OIDCKey []byte `json:"oidc_key,omitempty"`
 
 	// FeatureFlags is the feature flags that are specific to this instance
 	FeatureFlags map[string]interface{} `json:"feature_flags,omitempty"`
 	// FeatureSets is a list of feature sets from the manager
 	FeatureSets []string `json:"feature_sets,omitempty"`
 
+	// NetworkAccess is the list of the IP ranges allowed or denied for this
+	// instance, in addition to the rules of its context
+	NetworkAccess *netaccess.Policy `json:"network_access,omitempty"`
+
+	// LegalHold is set by an admin when the data of the instance must be
+	// preserved: the documents and files can still be put in the trash, but
+	// they can't be destroyed while the hold is active.
+	LegalHold *LegalHold `json:"legal_hold,omitempty"`
+
 	vfs              vfs.VFS
 	contextualDomain string
 }
@@ -153,6 +168,14 @@ func (i *Instance) Clone() couchdb.Doc {
 
 	cloned.CLISecret = make([]byte, len(i.CLISecret))
 	copy(cloned.CLISecret, i.CLISecret)
+
+	cloned.OIDCKey = make([]byte, len(i.OIDCKey))
+	copy(cloned.OIDCKey, i.OIDCKey)
+
+	if i.LegalHold != nil {
+		tmp := *i.LegalHold
+		cloned.LegalHold = &tmp
+	}
 	return &cloned
 }
 
@@ -349,6 +372,56 @@ func (i *Instance) SettingsContext() (map[string]interface{}, bool) {
 	return settings, true
 }
 
+// PassphraseHashParams returns the parameters for hashing the passphrase of
+// the instance, from the passphrase_hash settings of its context. scrypt is
+// used by default.
+func (i *Instance) PassphraseHashParams() crypto.HashParams {
+	params := crypto.DefaultHashParams
+	ctxSettings, ok := i.SettingsContext()
+	if !ok {
+		return params
+	}
+	cfg, ok := ctxSettings["passphrase_hash"].(map[string]interface{})
+	if !ok {
+		return params
+	}
+	if kdf, ok := cfg["kdf"].(string); ok && kdf == crypto.KDFArgon2id {
+		params.KDF = kdf
+	}
+	if memory, ok := cfg["memory"].(int); ok && memory > 0 {
+		params.Argon2Memory = uint32(memory)
+	}
+	if iterations, ok := cfg["iterations"].(int); ok && iterations > 0 {
+		params.Argon2Iterations = uint32(iterations)
+	}
+	if parallelism, ok := cfg["parallelism"].(int); ok && parallelism > 0 && parallelism < 256 {
+		params.Argon2Parallelism = uint8(parallelism)
+	}
+	return params
+}
+
+// CheckNetworkAccess returns netaccess.ErrForbidden if the IP address is not
+// allowed to access the routes of the given category, by the rules of the
+// context or by the rules of the instance.
+func (i *Instance) CheckNetworkAccess(category netaccess.Category, ip net.IP) error {
+	if err := i.contextNetworkAccess().Check(category, ip); err != nil {
+		return err
+	}
+	return i.NetworkAccess.Check(category, ip)
+}
+
+// contextNetworkAccess returns the network access policy of the context of
+// the instance, as parsed when the config was loaded.
+func (i *Instance) contextNetworkAccess() *netaccess.Policy {
+	policies := config.GetConfig().NetworkAccess.Contexts
+	if i.ContextName != "" {
+		if policy, ok := policies[i.ContextName]; ok {
+			return policy
+		}
+	}
+	return policies[config.DefaultInstanceContext]
+}
+
 // SupportEmailAddress returns the email address that can be used to contact
 // the support.
 func (i *Instance) SupportEmailAddress() string {
@@ -645,7 +718,8 @@ func PaginatedList(limit int, startKey string, skip int) ([]*Instance, string, e
 // PickKey choose which of the Instance keys to use depending on token audience
 func (i *Instance) PickKey(audience string) ([]byte, error) {
 	switch audience {
-	case consts.AppAudience, consts.KonnectorAudience:
+	case consts.AppAudience, consts.KonnectorAudience, consts.WebviewAudience,
+		consts.DownloadAudience:
 		return i.SessionSecret(), nil
 	case consts.RefreshTokenAudience, consts.AccessTokenAudience, consts.ShareAudience:
 		return i.OAuthSecret, nil
@@ -696,6 +770,27 @@ func (i *Instance) BuildKonnectorToken(slug string) string {
 	return token
 }
 
+// BuildWebviewToken is used to build a short-lived token for an app opened in
+// a webview of the flagship app. The scope can restrict the permissions of the
+// app, and the token is only valid while the OAuth client of the flagship app
+// exists.
+func (i *Instance) BuildWebviewToken(slug, clientID, scope string) (string, error) {
+	secret, err := i.PickKey(consts.WebviewAudience)
+	if err != nil {
+		return "", err
+	}
+	return crypto.NewJWT(secret, permission.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience: jwt.ClaimStrings{consts.WebviewAudience},
+			Issuer:   i.Domain,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			Subject:  slug,
+		},
+		Scope:           scope,
+		AuthorizedParty: clientID,
+	})
+}
+
 // CreateShareCode returns a new sharecode to put the codes field of a
 // permissions document
 func (i *Instance) CreateShareCode(subject string) (string, error) {
@@ -2,6 +2,7 @@ package instance_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/pkg/config/config"
@@ -51,4 +52,21 @@ func TestInstance(t *testing.T) {
 		assert.Equal(t, "test-ctx-token.example.com", claims["iss"])
 		assert.Equal(t, "my-app", claims["sub"])
 	})
+	t.Run("LegalHold", func(t *testing.T) {
+		inst := &instance.Instance{Domain: "legal-hold.example.com"}
+		assert.False(t, inst.IsUnderLegalHold())
+		assert.NoError(t, inst.CheckLegalHold())
+
+		inst.LegalHold = &instance.LegalHold{
+			Reason: "Case 42",
+			SetAt:  time.Now(),
+			SetBy:  "jane",
+		}
+		assert.True(t, inst.IsUnderLegalHold())
+		assert.ErrorIs(t, inst.CheckLegalHold(), instance.ErrLegalHold)
+
+		cloned := inst.Clone().(*instance.Instance)
+		cloned.LegalHold.Reason = "Case 43"
+		assert.Equal(t, "Case 42", inst.LegalHold.Reason)
+	})
 }
@@ -0,0 +1,63 @@
+package instance
+
+import "time"
+
+// LegalHold describes why and by whom the data of an instance is held.
+type LegalHold struct {
+	Reason string    `json:"reason,omitempty"`
+	SetAt  time.Time `json:"set_at"`
+	SetBy  string    `json:"set_by,omitempty"`
+}
+
+// IsUnderLegalHold returns true if the data of the instance can't be
+// destroyed.
+func (i *Instance) IsUnderLegalHold() bool {
+	return i.LegalHold != nil
+}
+
+// CheckLegalHold returns ErrLegalHold if the instance is under a legal hold.
+// It must be called before destroying documents or files, and before purging
+// the trash.
+func (i *Instance) CheckLegalHold() error {
+	if i.IsUnderLegalHold() {
+		i.Logger().WithNamespace("legalhold").
+			Infof("Destruction refused (hold set by %s at %s)",
+				i.LegalHold.SetBy, i.LegalHold.SetAt.Format(time.RFC3339))
+		return ErrLegalHold
+	}
+	return nil
+}
+
+// SetLegalHold puts the instance under a legal hold. If the instance was
+// already held, the reason is updated.
+func SetLegalHold(inst *Instance, reason, by string) error {
+	hold := &LegalHold{Reason: reason, SetAt: time.Now().UTC(), SetBy: by}
+	if inst.LegalHold != nil {
+		hold.SetAt = inst.LegalHold.SetAt
+	}
+	inst.LegalHold = hold
+	if err := Update(inst); err != nil {
+		return err
+	}
+	inst.Logger().WithNamespace("legalhold").
+		WithField("set_by", by).
+		Infof("Legal hold set: %s", reason)
+	return nil
+}
+
+// ReleaseLegalHold removes the legal hold of the instance. The purges that
+// were deferred will be done by the next executions of the trash cleaning
+// workers.
+func ReleaseLegalHold(inst *Instance, by string) error {
+	if inst.LegalHold == nil {
+		return nil
+	}
+	inst.LegalHold = nil
+	if err := Update(inst); err != nil {
+		return err
+	}
+	inst.Logger().WithNamespace("legalhold").
+		WithField("released_by", by).
+		Infof("Legal hold released")
+	return nil
+}
@@ -49,6 +49,7 @@ type Options struct {
 	Apps               []string
 	AutoUpdate         *bool
 	MagicLink          *bool
+	Organization       *bool
 	Debug              *bool
 	Traced             *bool
 	OnboardingFinished *bool
@@ -194,6 +195,9 @@ func Create(opts *Options) (*instance.Instance, error) {
 	if magicLink := opts.MagicLink; magicLink != nil {
 		i.MagicLink = *magicLink
 	}
+	if organization := opts.Organization; organization != nil {
+		i.Organization = *organization
+	}
 
 	passwordDefined := opts.Passphrase != ""
 
@@ -253,7 +257,7 @@ func Create(opts *Options) (*instance.Instance, error) {
 		done := make(chan struct{})
 		for _, app := range opts.Apps {
 			go func(app string) {
-				if err := installApp(i, app); err != nil {
+				if err := InstallApp(i, app); err != nil {
 					i.Logger().Errorf("Failed to install %s: %s", app, err)
 				}
 				done <- struct{}{}
@@ -10,6 +10,7 @@ import (
 	"github.com/cozy/cozy-stack/model/app"
 	"github.com/cozy/cozy-stack/model/instance"
 	job "github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/search"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -64,11 +65,24 @@ func Destroy(domain string) error {
 	if inst.Deleting {
 		return instance.ErrDeletionAlreadyRequested
 	}
+	if err := inst.CheckLegalHold(); err != nil {
+		return err
+	}
 	inst.Deleting = true
 	if err := instance.Update(inst); err != nil {
 		return err
 	}
 
+	// The shared folders can be transferred to the escrow owner of the
+	// context, instead of just breaking the sharings. It is made on a best
+	// effort basis: a failure must not prevent the instance from being
+	// destroyed.
+	if err := transferToEscrow(inst); err != nil {
+		inst.Logger().WithNamespace("escrow").
+			Errorf("Cannot transfer the shared folders: %s", err)
+		sendAlert(inst, err)
+	}
+
 	// Deleting accounts manually to invoke the "account deletion hook" which may
 	// launch a worker in order to clean the account.
 	if err := deleteAccounts(inst); err != nil {
@@ -97,6 +111,12 @@ func Destroy(domain string) error {
 		return err
 	}
 
+	if search.Enabled() {
+		if err = search.DeleteIndex(inst); err != nil {
+			inst.Logger().Warnf("Could not delete the search index: %s", err.Error())
+		}
+	}
+
 	err = instance.Delete(inst)
 	if couchdb.IsConflictError(err) {
 		// We may need to try again as CouchDB can return an old version of
@@ -113,6 +133,37 @@ func Destroy(domain string) error {
 	return err
 }
 
+// escrowTimeout is the maximal duration of the transfer of the shared folders
+// to the escrow owner, as the request for destroying the instance waits for
+// it. It is a bit longer than the timeout of the escrow worker, to let the job
+// be dequeued.
+const escrowTimeout = 6 * time.Minute
+
+func transferToEscrow(inst *instance.Instance) error {
+	if inst.DataEscrowOwner() == "" {
+		return nil
+	}
+	msg, err := job.NewMessage(struct{}{})
+	if err != nil {
+		return err
+	}
+	j, err := job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: "escrow",
+		Message:    msg,
+	})
+	if err != nil {
+		return err
+	}
+	state, err := j.Wait(inst, escrowTimeout)
+	if err != nil {
+		return err
+	}
+	if state == job.Errored {
+		return errors.New("The transfer of the shared folders to the escrow owner has failed")
+	}
+	return nil
+}
+
 func deleteAccounts(inst *instance.Instance) error {
 	var accounts []*account.Account
 	if err := couchdb.GetAllDocs(inst, consts.Accounts, nil, &accounts); err != nil {
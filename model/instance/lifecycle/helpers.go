@@ -26,7 +26,9 @@ func update(inst *instance.Instance) error {
 	return nil
 }
 
-func installApp(inst *instance.Instance, slug string) error {
+// InstallApp installs a webapp from the stable channel of the registries of
+// the instance.
+func InstallApp(inst *instance.Instance, slug string) error {
 	source := "registry://" + slug + "/stable"
 	installer, err := app.NewInstaller(inst, app.Copier(consts.WebappType, inst), &app.InstallerOptions{
 		Operation:  app.Install,
@@ -50,7 +50,7 @@ func registerPassphrase(inst *instance.Instance, tok []byte, params PassParamete
 			return err
 		}
 	}
-	hash, err := crypto.GenerateFromPassphrase(params.Pass)
+	hash, err := crypto.GenerateFromPassphraseWithParams(params.Pass, inst.PassphraseHashParams())
 	if err != nil {
 		return err
 	}
@@ -167,7 +167,7 @@ func PassphraseRenew(inst *instance.Instance, tok []byte, params PassParameters)
 			return err
 		}
 	}
-	hash, err := crypto.GenerateFromPassphrase(params.Pass)
+	hash, err := crypto.GenerateFromPassphraseWithParams(params.Pass, inst.PassphraseHashParams())
 	if err != nil {
 		return err
 	}
@@ -202,12 +202,12 @@ func UpdatePassphrase(
 	} else {
 		// the needUpdate flag is not checked against since the passphrase will be
 		// regenerated with updated parameters just after, if the passphrase match.
-		_, err := crypto.CompareHashAndPassphrase(inst.PassphraseHash, current)
+		_, err := crypto.CompareHashAndPassphraseWithParams(inst.PassphraseHash, current, inst.PassphraseHashParams())
 		if err != nil {
 			return instance.ErrInvalidPassphrase
 		}
 	}
-	hash, err := crypto.GenerateFromPassphrase(params.Pass)
+	hash, err := crypto.GenerateFromPassphraseWithParams(params.Pass, inst.PassphraseHashParams())
 	if err != nil {
 		return err
 	}
@@ -236,7 +236,7 @@ func ForceUpdatePassphrase(inst *instance.Instance, newPassword []byte, params P
 	if err != nil {
 		return nil
 	}
-	hash, err := crypto.GenerateFromPassphrase(params.Pass)
+	hash, err := crypto.GenerateFromPassphraseWithParams(params.Pass, inst.PassphraseHashParams())
 	if err != nil {
 		return err
 	}
@@ -87,6 +87,11 @@ func Patch(i *instance.Instance, opts *Options) error {
 			needUpdate = true
 		}
 
+		if opts.Organization != nil && *opts.Organization != i.Organization {
+			i.Organization = *opts.Organization
+			needUpdate = true
+		}
+
 		if opts.ContextName != "" && opts.ContextName != i.ContextName {
 			i.ContextName = opts.ContextName
 			needUpdate = true
@@ -78,7 +78,7 @@ func Reset(inst *instance.Instance) error {
 	}
 
 	for _, app := range []string{"home", "store", "settings"} {
-		if err = installApp(inst, app); err != nil {
+		if err = InstallApp(inst, app); err != nil {
 			inst.Logger().Errorf("Failed to install %s: %s", app, err)
 		}
 	}
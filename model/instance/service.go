@@ -111,7 +111,10 @@ func (s *InstanceService) CheckPassphrase(inst *Instance, pass []byte) error {
 		return ErrMissingPassphrase
 	}
 
-	needUpdate, err := crypto.CompareHashAndPassphrase(inst.PassphraseHash, pass)
+	// The hash is computed again when its KDF or its parameters are not the
+	// ones configured for the context of the instance.
+	params := inst.PassphraseHashParams()
+	needUpdate, err := crypto.CompareHashAndPassphraseWithParams(inst.PassphraseHash, pass, params)
 	if err != nil {
 		return err
 	}
@@ -120,7 +123,7 @@ func (s *InstanceService) CheckPassphrase(inst *Instance, pass []byte) error {
 		return nil
 	}
 
-	newHash, err := crypto.GenerateFromPassphrase(pass)
+	newHash, err := crypto.GenerateFromPassphraseWithParams(pass, params)
 	if err != nil {
 		return err
 	}
@@ -21,6 +21,9 @@ var (
 	BlockedImporting = BlockingReason{Code: "IMPORTING", Message: "Instance Blocked Importing"}
 	// BlockedMoving is used when moving data from another instance
 	BlockedMoving = BlockingReason{Code: "MOVING", Message: "Instance Blocked Moving"}
+	// BlockedStandby is used for the copy of an instance on the secondary
+	// stack of a warm standby replication, until it is promoted
+	BlockedStandby = BlockingReason{Code: "STANDBY", Message: "Instance Blocked Standby"}
 	// BlockedUnknown is used when an instance is blocked but the reason is unknown
 	BlockedUnknown = BlockingReason{Code: "UNKNOWN", Message: "Instance Blocked Unknown"}
 )
@@ -41,6 +41,7 @@ type Intent struct {
 	Client        string         `json:"client"`
 	Services      []Service      `json:"services"`
 	AvailableApps []AvailableApp `json:"availableApps"`
+	Transfer      *Transfer      `json:"transfer,omitempty"`
 }
 
 // ID is used to implement the couchdb.Doc interface
@@ -61,6 +62,9 @@ func (in *Intent) Clone() couchdb.Doc {
 	copy(cloned.Services, in.Services)
 	cloned.AvailableApps = make([]AvailableApp, len(in.AvailableApps))
 	copy(cloned.AvailableApps, in.AvailableApps)
+	if in.Transfer != nil {
+		cloned.Transfer = in.Transfer.clone()
+	}
 	return &cloned
 }
 
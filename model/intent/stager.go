@@ -0,0 +1,147 @@
+package intent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/ncw/swift/v2"
+	"github.com/spf13/afero"
+)
+
+// Stager is the server-side staging area where the payloads of the transfer
+// intents are stored until the service has read them.
+type Stager interface {
+	CreatePart(domain string, in *Intent, name, contentType string) (io.WriteCloser, error)
+	OpenPart(domain string, in *Intent, name string) (io.ReadCloser, error)
+	RemoveParts(domain string, in *Intent) error
+}
+
+// SystemStager returns the global staging area, corresponding to the user's
+// configuration.
+func SystemStager() Stager {
+	fsURL := config.FsURL()
+	switch fsURL.Scheme {
+	case config.SchemeFile, config.SchemeMem:
+		fs := afero.NewBasePathFs(afero.NewOsFs(), path.Join(fsURL.Path, "intents"))
+		return newAferoStager(fs)
+	case config.SchemeSwift, config.SchemeSwiftSecure:
+		return newSwiftStager()
+	default:
+		panic(fmt.Errorf("intents: unknown storage provider %s", fsURL.Scheme))
+	}
+}
+
+func newAferoStager(fs afero.Fs) Stager {
+	return aferoStager{fs}
+}
+
+type aferoStager struct {
+	fs afero.Fs
+}
+
+func (s aferoStager) dirName(domain string, in *Intent) string {
+	return path.Join("/", domain, in.ID())
+}
+
+func (s aferoStager) CreatePart(domain string, in *Intent, name, contentType string) (io.WriteCloser, error) {
+	dir := s.dirName(domain, in)
+	if err := s.fs.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	s.removeExpired(domain)
+	return s.fs.OpenFile(path.Join(dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+}
+
+func (s aferoStager) OpenPart(domain string, in *Intent, name string) (io.ReadCloser, error) {
+	return s.fs.Open(path.Join(s.dirName(domain, in), name))
+}
+
+func (s aferoStager) RemoveParts(domain string, in *Intent) error {
+	return s.fs.RemoveAll(s.dirName(domain, in))
+}
+
+// removeExpired removes the staged parts of the old transfers of the
+// instance, as the local filesystem has no expiration mechanism.
+func (s aferoStager) removeExpired(domain string) {
+	infos, err := afero.ReadDir(s.fs, path.Join("/", domain))
+	if err != nil {
+		return
+	}
+	limit := time.Now().Add(-TransferTTL)
+	for _, info := range infos {
+		if info.IsDir() && info.ModTime().Before(limit) {
+			_ = s.fs.RemoveAll(path.Join("/", domain, info.Name()))
+		}
+	}
+}
+
+func newSwiftStager() Stager {
+	return &swiftStager{
+		c:         config.GetSwiftConnection(),
+		container: "intents",
+		ctx:       context.Background(),
+	}
+}
+
+type swiftStager struct {
+	c         *swift.Connection
+	container string
+	ctx       context.Context
+}
+
+func (s *swiftStager) init() error {
+	if _, _, err := s.c.Container(s.ctx, s.container); errors.Is(err, swift.ContainerNotFound) {
+		if err = s.c.ContainerCreate(s.ctx, s.container, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *swiftStager) objectName(domain string, in *Intent, name string) string {
+	return domain + "/" + in.ID() + "/" + name
+}
+
+func (s *swiftStager) CreatePart(domain string, in *Intent, name, contentType string) (io.WriteCloser, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	headers := swift.Headers{
+		"X-Delete-At": strconv.FormatInt(in.Transfer.ExpiresAt.Unix(), 10),
+	}
+	return s.c.ObjectCreate(s.ctx, s.container, s.objectName(domain, in, name),
+		false, "", contentType, headers)
+}
+
+func (s *swiftStager) OpenPart(domain string, in *Intent, name string) (io.ReadCloser, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	f, _, err := s.c.ObjectOpen(s.ctx, s.container, s.objectName(domain, in, name), false, nil)
+	if errors.Is(err, swift.ObjectNotFound) {
+		return nil, os.ErrNotExist
+	}
+	return f, err
+}
+
+func (s *swiftStager) RemoveParts(domain string, in *Intent) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+	var objectNames []string
+	for _, part := range in.Transfer.Parts {
+		objectNames = append(objectNames, s.objectName(domain, in, part.Name))
+	}
+	if len(objectNames) > 0 {
+		_, err := s.c.BulkDelete(s.ctx, s.container, objectNames)
+		return err
+	}
+	return nil
+}
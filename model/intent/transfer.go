@@ -0,0 +1,243 @@
+package intent
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// TransferTTL is the duration during which the payload of a transfer intent
+// is kept in the staging area.
+const TransferTTL = 24 * time.Hour
+
+// MaxTransferSize is the maximal size in bytes of the parts staged for a
+// transfer intent.
+const MaxTransferSize = 1 << 30
+
+var (
+	// ErrNoTransfer is used when the intent has no payload
+	ErrNoTransfer = errors.New("The intent has no payload")
+	// ErrTransferExpired is used when the payload of the intent has expired
+	ErrTransferExpired = errors.New("The payload of the intent has expired")
+	// ErrTransferTooLarge is used when the payload is larger than allowed
+	ErrTransferTooLarge = errors.New("The payload of the intent is too large")
+	// ErrInvalidPartName is used when the name of a part is not valid
+	ErrInvalidPartName = errors.New("Invalid name for the part")
+	// ErrPartNotFound is used when the part is not in the payload
+	ErrPartNotFound = errors.New("The part is not in the payload of the intent")
+)
+
+// Transfer is the payload handed by the client of an intent to the service.
+// Instead of passing the data through the memory of the browser, the client
+// stages it on the server, and the service reads it from there, before the
+// expiration.
+type Transfer struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	// Size is the total size of the staged parts
+	Size int64 `json:"size"`
+	// Parts are the raw payloads uploaded by the client
+	Parts []TransferPart `json:"parts,omitempty"`
+	// Files are the identifiers of the io.cozy.files given to the service
+	Files []string `json:"files,omitempty"`
+	// Documents are the identifiers of the documents given to the service,
+	// by doctype
+	Documents map[string][]string `json:"documents,omitempty"`
+}
+
+// TransferPart is a raw payload staged for a transfer intent.
+type TransferPart struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+func (t *Transfer) clone() *Transfer {
+	cloned := *t
+	cloned.Parts = make([]TransferPart, len(t.Parts))
+	copy(cloned.Parts, t.Parts)
+	cloned.Files = make([]string, len(t.Files))
+	copy(cloned.Files, t.Files)
+	if t.Documents != nil {
+		cloned.Documents = make(map[string][]string, len(t.Documents))
+		for doctype, ids := range t.Documents {
+			cloned.Documents[doctype] = append([]string{}, ids...)
+		}
+	}
+	return &cloned
+}
+
+// CheckTransfer returns an error if the intent has no payload, or if it has
+// expired.
+func (in *Intent) CheckTransfer() error {
+	if in.Transfer == nil {
+		return ErrNoTransfer
+	}
+	if time.Now().After(in.Transfer.ExpiresAt) {
+		return ErrTransferExpired
+	}
+	return nil
+}
+
+func (in *Intent) startTransfer() error {
+	if in.Transfer == nil {
+		in.Transfer = &Transfer{ExpiresAt: time.Now().Add(TransferTTL)}
+		return nil
+	}
+	return in.CheckTransfer()
+}
+
+// AddPart stages a raw payload for the service, with the given name. If a
+// part with the same name was already staged, it is replaced.
+func (in *Intent) AddPart(inst *instance.Instance, name, contentType string, body io.Reader) (*TransferPart, error) {
+	if name == "" || name == "." || name == ".." || len(name) > 255 ||
+		strings.ContainsAny(name, "/\\\x00") {
+		return nil, ErrInvalidPartName
+	}
+	if err := in.startTransfer(); err != nil {
+		return nil, err
+	}
+
+	idx := -1
+	available := MaxTransferSize - in.Transfer.Size
+	for i, part := range in.Transfer.Parts {
+		if part.Name == name {
+			idx = i
+			available += part.Size
+		}
+	}
+
+	stager := SystemStager()
+	w, err := stager.CreatePart(inst.Domain, in, name, contentType)
+	if err != nil {
+		return nil, err
+	}
+	size, err := io.Copy(w, io.LimitReader(body, available+1))
+	if errc := w.Close(); err == nil {
+		err = errc
+	}
+	if err == nil && size > available {
+		err = ErrTransferTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	part := TransferPart{Name: name, ContentType: contentType, Size: size}
+	if idx >= 0 {
+		in.Transfer.Size -= in.Transfer.Parts[idx].Size
+		in.Transfer.Parts[idx] = part
+	} else {
+		in.Transfer.Parts = append(in.Transfer.Parts, part)
+	}
+	in.Transfer.Size += size
+	if err := in.Save(inst); err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+// OpenPart returns the part with the given name, and a reader for its
+// content.
+func (in *Intent) OpenPart(inst *instance.Instance, name string) (*TransferPart, io.ReadCloser, error) {
+	if err := in.CheckTransfer(); err != nil {
+		return nil, nil, err
+	}
+	for _, part := range in.Transfer.Parts {
+		if part.Name == name {
+			r, err := SystemStager().OpenPart(inst.Domain, in, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &part, r, nil
+		}
+	}
+	return nil, nil, ErrPartNotFound
+}
+
+// AddFiles gives some files to the service. The caller must check that the
+// client can read them.
+func (in *Intent) AddFiles(inst *instance.Instance, ids []string) error {
+	if err := in.startTransfer(); err != nil {
+		return err
+	}
+	in.Transfer.Files = appendIDs(in.Transfer.Files, ids)
+	return in.Save(inst)
+}
+
+// HasFile returns true if the file has been given to the service.
+func (in *Intent) HasFile(id string) bool {
+	if in.Transfer == nil {
+		return false
+	}
+	for _, fileID := range in.Transfer.Files {
+		if fileID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AddDocuments gives some documents to the service. The caller must check
+// that the client can read them.
+func (in *Intent) AddDocuments(inst *instance.Instance, doctype string, ids []string) error {
+	if err := in.startTransfer(); err != nil {
+		return err
+	}
+	if in.Transfer.Documents == nil {
+		in.Transfer.Documents = make(map[string][]string)
+	}
+	in.Transfer.Documents[doctype] = appendIDs(in.Transfer.Documents[doctype], ids)
+	return in.Save(inst)
+}
+
+// GetDocuments returns the documents of the given doctype that have been
+// given to the service.
+func (in *Intent) GetDocuments(inst *instance.Instance, doctype string) ([]couchdb.JSONDoc, error) {
+	if err := in.CheckTransfer(); err != nil {
+		return nil, err
+	}
+	ids := in.Transfer.Documents[doctype]
+	if len(ids) == 0 {
+		return []couchdb.JSONDoc{}, nil
+	}
+	var docs []couchdb.JSONDoc
+	req := &couchdb.AllDocsRequest{Keys: ids}
+	if err := couchdb.GetAllDocs(inst, doctype, req, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// EndTransfer removes the payload of the intent from the staging area.
+func (in *Intent) EndTransfer(inst *instance.Instance) error {
+	if in.Transfer == nil {
+		return nil
+	}
+	if len(in.Transfer.Parts) > 0 {
+		if err := SystemStager().RemoveParts(inst.Domain, in); err != nil {
+			return err
+		}
+	}
+	in.Transfer = nil
+	return in.Save(inst)
+}
+
+func appendIDs(list, ids []string) []string {
+	for _, id := range ids {
+		found := false
+		for _, existing := range list {
+			if existing == id {
+				found = true
+				break
+			}
+		}
+		if !found && id != "" {
+			list = append(list, id)
+		}
+	}
+	return list
+}
@@ -0,0 +1,63 @@
+package intent
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTransfer(t *testing.T) {
+	in := &Intent{IID: "6fba9dd6-1487-11e7-b90d-130a5dedd6d6"}
+	assert.ErrorIs(t, in.CheckTransfer(), ErrNoTransfer)
+
+	in.Transfer = &Transfer{ExpiresAt: time.Now().Add(time.Hour)}
+	assert.NoError(t, in.CheckTransfer())
+
+	in.Transfer.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.ErrorIs(t, in.CheckTransfer(), ErrTransferExpired)
+}
+
+func TestAddPartInvalidName(t *testing.T) {
+	inst := &instance.Instance{Domain: "cozy.example.net"}
+	in := &Intent{IID: "6fba9dd6-1487-11e7-b90d-130a5dedd6d6"}
+	for _, name := range []string{"", ".", "..", "../secret", "a/b", "a\\b"} {
+		_, err := in.AddPart(inst, name, "text/plain", bytes.NewReader(nil))
+		assert.ErrorIs(t, err, ErrInvalidPartName, name)
+	}
+	assert.Nil(t, in.Transfer)
+}
+
+func TestAppendIDs(t *testing.T) {
+	list := appendIDs(nil, []string{"a", "b", "a", ""})
+	assert.Equal(t, []string{"a", "b"}, list)
+	list = appendIDs(list, []string{"c", "b"})
+	assert.Equal(t, []string{"a", "b", "c"}, list)
+}
+
+func TestAferoStager(t *testing.T) {
+	stager := newAferoStager(afero.NewMemMapFs())
+	in := &Intent{IID: "6fba9dd6-1487-11e7-b90d-130a5dedd6d6"}
+
+	w, err := stager.CreatePart("cozy.example.net", in, "foo.txt", "text/plain")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("foo"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, err := stager.OpenPart("cozy.example.net", in, "foo.txt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "foo", string(content))
+
+	require.NoError(t, stager.RemoveParts("cozy.example.net", in))
+	_, err = stager.OpenPart("cozy.example.net", in, "foo.txt")
+	assert.Error(t, err)
+}
@@ -5,16 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/limits"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/cozy-stack/pkg/realtime"
+	"github.com/gofrs/uuid/v5"
 )
 
 const (
@@ -52,6 +57,12 @@ type (
 		// This method is asynchronous.
 		PushJob(db prefixer.Prefixer, request *JobRequest) (*Job, error)
 
+		// PushJobs is like PushJob for a batch of job requests: the job
+		// documents are created with a single bulk request in CouchDB. The
+		// rate limits are checked for the whole batch before pushing the
+		// jobs. It returns the jobs that have been created.
+		PushJobs(db prefixer.Prefixer, requests []*JobRequest) ([]*Job, error)
+
 		// WorkerQueueLen returns the total element in the queue of the specified
 		// worker type.
 		WorkerQueueLen(workerType string) (int, error)
@@ -92,10 +103,19 @@ type (
 		Options     *JobOptions `json:"options,omitempty"`
 		State       State       `json:"state"`
 		QueuedAt    time.Time   `json:"queued_at"`
+		ScheduledAt *time.Time  `json:"scheduled_at,omitempty"`
 		StartedAt   time.Time   `json:"started_at"`
 		FinishedAt  time.Time   `json:"finished_at"`
 		Error       string      `json:"error,omitempty"`
 		ForwardLogs bool        `json:"forward_logs,omitempty"`
+		// Result is a small JSON document attached to the job by the worker
+		Result json.RawMessage `json:"result,omitempty"`
+		// Host is the hostname of the server where the job has been executed
+		Host string `json:"host,omitempty"`
+
+		// release is called by the worker when the job has been processed,
+		// to free the slot of the instance for the worker type.
+		release func()
 	}
 
 	// JobRequest struct is used to represent a new job request.
@@ -110,17 +130,25 @@ type (
 		Debounced   bool
 		ForwardLogs bool
 		Options     *JobOptions
+		// ScheduledAt can be used to delay the execution of the job: it will
+		// not be started before this time. The zero value means as soon as
+		// possible.
+		ScheduledAt time.Time
 	}
 
 	// JobOptions struct contains the execution properties of the jobs.
 	JobOptions struct {
 		MaxExecCount int           `json:"max_exec_count"`
 		Timeout      time.Duration `json:"timeout"`
+		Retry        *RetryPolicy  `json:"retry,omitempty"`
 	}
 )
 
 var joblog = logger.WithNamespace("jobs")
 
+// hostname is the name of the server, saved on the jobs that it executes.
+var hostname, _ = os.Hostname()
+
 // DBCluster implements the prefixer.Prefixer interface.
 func (j *Job) DBCluster() int {
 	return j.Cluster
@@ -152,6 +180,10 @@ func (j *Job) Clone() couchdb.Doc {
 		tmp := *j.Options
 		cloned.Options = &tmp
 	}
+	if j.ScheduledAt != nil {
+		tmp := *j.ScheduledAt
+		cloned.ScheduledAt = &tmp
+	}
 	if j.Message != nil {
 		tmp := j.Message
 		j.Message = make([]byte, len(tmp))
@@ -217,6 +249,7 @@ func (j *Job) AckConsumed() error {
 	j.Logger().Debugf("ack_consume %s", j.ID())
 	j.StartedAt = time.Now()
 	j.State = Running
+	j.Host = hostname
 	return j.Update()
 }
 
@@ -264,10 +297,35 @@ func (j *Job) Create() error {
 // WaitUntilDone will wait until the job is done. It will return an error if
 // the job has failed. And there is a timeout (10 minutes).
 func (j *Job) WaitUntilDone(db prefixer.Prefixer) error {
+	state, err := j.Wait(db, 10*time.Minute)
+	if err == nil && state == Errored {
+		return errors.New("The konnector failed on account deletion")
+	}
+	return nil
+}
+
+// Wait waits until the job is finished, and returns its final state (Done or
+// Errored). ErrWaitTimeout is returned if the job is still queued or running
+// after the timeout.
+func (j *Job) Wait(db prefixer.Prefixer, timeout time.Duration) (State, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return j.WaitContext(ctx, db)
+}
+
+// WaitContext is like Wait, but it stops waiting when the context is done.
+// ErrWaitTimeout is returned if the deadline of the context is exceeded.
+func (j *Job) WaitContext(ctx context.Context, db prefixer.Prefixer) (State, error) {
 	sub := realtime.GetHub().Subscriber(db)
 	defer sub.Close()
 	sub.Watch(j.DocType(), j.ID())
-	timeout := time.After(10 * time.Minute)
+	// The job may have finished before the subscription
+	if current, err := Get(db, j.ID()); err == nil {
+		switch current.State {
+		case Done, Errored:
+			return current.State, nil
+		}
+	}
 	for {
 		select {
 		case e := <-sub.Channel:
@@ -282,13 +340,14 @@ func (j *Job) WaitUntilDone(db prefixer.Prefixer) error {
 				state = doc.State
 			}
 			switch state {
-			case Done:
-				return nil
-			case Errored:
-				return errors.New("The konnector failed on account deletion")
+			case Done, Errored:
+				return state, nil
+			}
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrWaitTimeout
 			}
-		case <-timeout:
-			return nil
+			return "", ctx.Err()
 		}
 	}
 }
@@ -325,7 +384,7 @@ func (m Message) MarshalJSON() ([]byte, error) {
 
 // NewJob creates a new Job instance from a job request.
 func NewJob(db prefixer.Prefixer, req *JobRequest) *Job {
-	return &Job{
+	job := &Job{
 		Cluster:     db.DBCluster(),
 		Domain:      db.DomainName(),
 		Prefix:      db.DBPrefix(),
@@ -341,6 +400,79 @@ func NewJob(db prefixer.Prefixer, req *JobRequest) *Job {
 		State:       Queued,
 		QueuedAt:    time.Now(),
 	}
+	if req.ScheduledAt.After(job.QueuedAt) {
+		at := req.ScheduledAt
+		job.ScheduledAt = &at
+	}
+	return job
+}
+
+// prepareJob checks a job request (the worker type and the rate limits) and
+// makes the job. The worker is nil for the client jobs, and skip is true when
+// the before hook of the worker has discarded the job.
+func prepareJob(db prefixer.Prefixer, workers []*Worker, req *JobRequest) (job *Job, worker *Worker, skip bool, err error) {
+	for _, w := range workers {
+		if w.Type == req.WorkerType {
+			worker = w
+			break
+		}
+	}
+	if worker == nil && req.WorkerType != "client" {
+		return nil, nil, false, ErrUnknownWorker
+	}
+
+	// Check for limits
+	ct, err := GetCounterTypeFromWorkerType(req.WorkerType)
+	if err == nil {
+		err := config.GetRateLimiter().CheckRateLimit(db, ct)
+		if errors.Is(err, limits.ErrRateLimitReached) {
+			joblog.WithFields(logger.Fields{
+				"worker_type": req.WorkerType,
+				"instance":    db.DomainName(),
+			}).Warn(err.Error())
+			return nil, nil, false, err
+		}
+		if limits.IsLimitReachedOrExceeded(err) {
+			return nil, nil, false, err
+		}
+	}
+
+	job = NewJob(db, req)
+	if worker != nil && worker.Conf.BeforeHook != nil {
+		ok, err := worker.Conf.BeforeHook(job)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !ok {
+			return job, worker, true, nil
+		}
+	}
+	return job, worker, false, nil
+}
+
+// createJobs saves the documents of several jobs with a bulk request, and
+// returns the jobs that have been created. The identifiers are generated
+// before, so that a retry of the bulk request can't create the same job twice.
+func createJobs(db prefixer.Prefixer, jobs []*Job) ([]*Job, error) {
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	docs := make([]interface{}, len(jobs))
+	for i, j := range jobs {
+		j.SetID(strings.ReplaceAll(uuid.Must(uuid.NewV7()).String(), "-", ""))
+		docs[i] = j
+	}
+	olddocs := make([]interface{}, len(jobs))
+	if err := couchdb.BulkUpdateDocs(db, consts.Jobs, docs, olddocs); err != nil {
+		return nil, err
+	}
+	created := make([]*Job, 0, len(jobs))
+	for _, j := range jobs {
+		if j.Rev() != "" {
+			created = append(created, j)
+		}
+	}
+	return created, nil
 }
 
 // Get returns the informations about a job.
@@ -43,6 +43,17 @@ func (m *BrokerMock) PushJob(db prefixer.Prefixer, request *JobRequest) (*Job, e
 	return args.Get(0).(*Job), args.Error(1)
 }
 
+// PushJobs mock method.
+func (m *BrokerMock) PushJobs(db prefixer.Prefixer, requests []*JobRequest) ([]*Job, error) {
+	args := m.Called(db, requests)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*Job), args.Error(1)
+}
+
 // WorkerQueueLen mock method.
 func (m *BrokerMock) WorkerQueueLen(workerType string) (int, error) {
 	args := m.Called(workerType)
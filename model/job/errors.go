@@ -23,6 +23,9 @@ var (
 	// ErrAbort can be used to abort the execution of the job without causing
 	// errors.
 	ErrAbort = errors.New("jobs: abort")
+	// ErrWaitTimeout is used when a job is not finished at the end of the
+	// time allowed to wait for it.
+	ErrWaitTimeout = errors.New("jobs: timeout while waiting for the job")
 
 	// ErrUnknownTrigger is used when the trigger type is not recognized
 	ErrUnknownTrigger = errors.New("Unknown trigger type")
@@ -33,6 +36,12 @@ var (
 	// ErrNotCronTrigger is used when a @cron trigger is expected, but it is
 	// not the case
 	ErrNotCronTrigger = errors.New("Invalid type for trigger (@cron expected)")
+	// ErrInvalidSignature is used when the HMAC signature of a request on a
+	// webhook is missing or not valid
+	ErrInvalidSignature = errors.New("Invalid signature for the webhook")
+	// ErrInvalidEventFilter is used when the filter of an @event trigger is
+	// not valid
+	ErrInvalidEventFilter = errors.New("Invalid filter for the @event trigger")
 )
 
 // BadTriggerError is an error conveying the information of a trigger that is not
@@ -3,14 +3,12 @@ package job
 import (
 	"container/list"
 	"context"
-	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/cozy/cozy-stack/pkg/config/config"
-	"github.com/cozy/cozy-stack/pkg/limits"
-	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	multierror "github.com/hashicorp/go-multierror"
 )
@@ -22,9 +20,10 @@ type (
 		Jobs        chan *Job
 		closed      chan struct{}
 
-		list *list.List
-		run  bool
-		jmu  sync.RWMutex
+		list    *list.List
+		delayed map[*time.Timer]struct{}
+		run     bool
+		jmu     sync.RWMutex
 	}
 
 	// memBroker is an in-memory broker implementation of the Broker interface.
@@ -39,9 +38,10 @@ type (
 // newMemQueue creates and a new in-memory queue.
 func newMemQueue(workerType string) *memQueue {
 	return &memQueue{
-		list:   list.New(),
-		Jobs:   make(chan *Job),
-		closed: make(chan struct{}),
+		list:    list.New(),
+		delayed: make(map[*time.Timer]struct{}),
+		Jobs:    make(chan *Job),
+		closed:  make(chan struct{}),
 	}
 }
 
@@ -57,6 +57,22 @@ func (q *memQueue) Enqueue(job *Job) error {
 	return nil
 }
 
+// EnqueueAt adds the job to the queue when the given time has come.
+func (q *memQueue) EnqueueAt(job *Job, at time.Time) error {
+	q.jmu.Lock()
+	defer q.jmu.Unlock()
+	cloned := job.Clone().(*Job)
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(at), func() {
+		q.jmu.Lock()
+		delete(q.delayed, timer)
+		q.jmu.Unlock()
+		_ = q.Enqueue(cloned)
+	})
+	q.delayed[timer] = struct{}{}
+	return nil
+}
+
 func (q *memQueue) send() {
 	for {
 		q.jmu.Lock()
@@ -79,6 +95,10 @@ func (q *memQueue) send() {
 func (q *memQueue) close() {
 	q.jmu.Lock()
 	defer q.jmu.Unlock()
+	for timer := range q.delayed {
+		timer.Stop()
+	}
+	q.delayed = make(map[*time.Timer]struct{})
 	if !q.run {
 		return
 	}
@@ -176,59 +196,70 @@ func (b *memBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, error)
 		return nil, ErrClosed
 	}
 
-	workerType := req.WorkerType
-	var worker *Worker
-	for _, w := range b.workers {
-		if w.Type == workerType {
-			worker = w
-			break
-		}
+	job, worker, skip, err := prepareJob(db, b.workers, req)
+	if err != nil {
+		return nil, err
 	}
-	if worker == nil && workerType != "client" {
-		return nil, ErrUnknownWorker
+	if skip {
+		return job, nil
 	}
 
-	// Check for limits
-	ct, err := GetCounterTypeFromWorkerType(req.WorkerType)
-	if err == nil {
-		err := config.GetRateLimiter().CheckRateLimit(db, ct)
-		if errors.Is(err, limits.ErrRateLimitReached) {
-			joblog.WithFields(logger.Fields{
-				"worker_type": req.WorkerType,
-				"instance":    db.DomainName(),
-			}).Warn(err.Error())
-			return nil, err
-		}
-		if limits.IsLimitReachedOrExceeded(err) {
-			return nil, err
-		}
+	if err := job.Create(); err != nil {
+		return nil, err
+	}
+
+	// For client jobs, we don't need to enqueue the job.
+	if worker == nil {
+		return job, nil
+	}
+	if err := b.enqueue(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// PushJobs will produce the jobs for the given requests, create their
+// documents with a single bulk request, and enqueue them.
+func (b *memBroker) PushJobs(db prefixer.Prefixer, reqs []*JobRequest) ([]*Job, error) {
+	if atomic.LoadUint32(&b.running) == 0 {
+		return nil, ErrClosed
+	}
+	if err := checkBatchRateLimits(db, reqs); err != nil {
+		return nil, err
 	}
 
-	job := NewJob(db, req)
-	if worker != nil && worker.Conf.BeforeHook != nil {
-		ok, err := worker.Conf.BeforeHook(job)
+	jobs := make([]*Job, 0, len(reqs))
+	for _, req := range reqs {
+		job, _, skip, err := prepareJob(db, b.workers, req)
 		if err != nil {
 			return nil, err
 		}
-		if !ok {
-			return job, nil
+		if !skip {
+			jobs = append(jobs, job)
 		}
 	}
 
-	if err := job.Create(); err != nil {
+	created, err := createJobs(db, jobs)
+	if err != nil {
 		return nil, err
 	}
-
-	// For client jobs, we don't need to enqueue the job.
-	if workerType == "client" {
-		return job, nil
+	for _, job := range created {
+		if job.WorkerType == "client" {
+			continue
+		}
+		if err := b.enqueue(job); err != nil {
+			return nil, err
+		}
 	}
+	return created, nil
+}
 
-	q := b.queues[workerType]
-	if err := q.Enqueue(job); err != nil {
-		return nil, err
+func (b *memBroker) enqueue(job *Job) error {
+	q := b.queues[job.WorkerType]
+	if job.ScheduledAt != nil {
+		return q.EnqueueAt(job, *job.ScheduledAt)
 	}
-	return job, nil
+	return q.Enqueue(job)
 }
 
 // WorkerQueueLen returns the size of the number of elements in queue of the
@@ -1,6 +1,7 @@
 package job_test
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 	"strings"
@@ -142,6 +143,43 @@ func TestMemBroker(t *testing.T) {
 		w.Wait()
 	})
 
+	t.Run("ScheduledAt", func(t *testing.T) {
+		done := make(chan time.Time, 1)
+		workersTestList := job.WorkersList{
+			{
+				WorkerType:  "test",
+				Concurrency: 1,
+				WorkerFunc: func(ctx *job.WorkerContext) error {
+					done <- time.Now()
+					return nil
+				},
+			},
+		}
+		broker := job.NewMemBroker()
+		assert.NoError(t, broker.StartWorkers(workersTestList))
+
+		at := time.Now().Add(500 * time.Millisecond)
+		msg, _ := job.NewMessage("delayed")
+		j, err := broker.PushJob(testInstance, &job.JobRequest{
+			WorkerType:  "test",
+			Message:     msg,
+			ScheduledAt: at,
+		})
+		assert.NoError(t, err)
+		if assert.NotNil(t, j.ScheduledAt) {
+			assert.True(t, j.ScheduledAt.Equal(at))
+		}
+		n, _ := broker.WorkerQueueLen("test")
+		assert.Equal(t, 0, n)
+
+		select {
+		case startedAt := <-done:
+			assert.False(t, startedAt.Before(at))
+		case <-time.After(5 * time.Second):
+			t.Fatal("the delayed job has not been executed")
+		}
+	})
+
 	t.Run("UnknownWorkerError", func(t *testing.T) {
 		broker := job.NewMemBroker()
 		assert.NoError(t, broker.StartWorkers(job.WorkersList{}))
@@ -365,4 +403,78 @@ func TestMemBroker(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, j)
 	})
+	t.Run("MemPushJobs", func(t *testing.T) {
+		broker := job.NewMemBroker()
+		var w sync.WaitGroup
+		workersTestList := job.WorkersList{
+			{
+				WorkerType:  "test",
+				Concurrency: 4,
+				WorkerFunc: func(ctx *job.WorkerContext) error {
+					w.Done()
+					return nil
+				},
+			},
+			{
+				WorkerType:  "thumbnail",
+				Concurrency: 4,
+				WorkerFunc: func(ctx *job.WorkerContext) error {
+					return nil
+				},
+			},
+		}
+		assert.NoError(t, broker.StartWorkers(workersTestList))
+		t.Cleanup(func() { _ = broker.ShutdownWorkers(context.Background()) })
+
+		msg, _ := job.NewMessage("batch")
+		reqs := make([]*job.JobRequest, 3)
+		for i := range reqs {
+			reqs[i] = &job.JobRequest{WorkerType: "test", Message: msg}
+		}
+		w.Add(len(reqs))
+		jobs, err := broker.PushJobs(testInstance, reqs)
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 3)
+		for _, j := range jobs {
+			assert.NotEmpty(t, j.ID())
+			assert.NotEmpty(t, j.Rev())
+		}
+		w.Wait()
+
+		// A batch with an unknown worker is refused
+		_, err = broker.PushJobs(testInstance, []*job.JobRequest{
+			{WorkerType: "test", Message: msg},
+			{WorkerType: "nope", Message: msg},
+		})
+		assert.ErrorIs(t, err, job.ErrUnknownWorker)
+
+		// A batch over the rate limit is refused without being counted
+		ct := limits.JobThumbnailType
+		oldLimit := limits.GetMaximumLimit(ct)
+		limits.SetMaximumLimit(ct, 5)
+		t.Cleanup(func() { limits.SetMaximumLimit(ct, oldLimit) })
+		limiter := config.GetRateLimiter()
+		limiter.ResetCounter(testInstance, ct)
+
+		thumbs := make([]*job.JobRequest, 6)
+		for i := range thumbs {
+			thumbs[i] = &job.JobRequest{WorkerType: "thumbnail", Message: msg}
+		}
+		jobs, err = broker.PushJobs(testInstance, thumbs)
+		assert.ErrorIs(t, err, limits.ErrRateLimitExceeded)
+		assert.Nil(t, jobs)
+		count, err := limiter.GetCountKey(testInstance.DomainName(), ct)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, count)
+
+		jobs, err = broker.PushJobs(testInstance, thumbs[:5])
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 5)
+		count, err = limiter.GetCountKey(testInstance.DomainName(), ct)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 5, count)
+
+		_, err = broker.PushJobs(testInstance, thumbs[:1])
+		assert.ErrorIs(t, err, limits.ErrRateLimitExceeded)
+	})
 }
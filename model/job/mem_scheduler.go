@@ -22,10 +22,14 @@ import (
 type memScheduler struct {
 	broker Broker
 
-	ts    map[string]Trigger
-	thumb *ThumbnailTrigger
-	mu    sync.RWMutex
-	log   *logger.Entry
+	ts     map[string]Trigger
+	thumb  *ThumbnailTrigger
+	qualif *QualificationTrigger
+	search *SearchTrigger
+	scan   *AntivirusTrigger
+	usage  *DirUsageTrigger
+	mu     sync.RWMutex
+	log    *logger.Entry
 }
 
 // NewMemScheduler creates a new in-memory scheduler that will load all
@@ -51,6 +55,14 @@ func (s *memScheduler) StartScheduler(b Broker) error {
 
 	s.thumb = NewThumbnailTrigger(s.broker)
 	go s.thumb.Schedule()
+	s.qualif = NewQualificationTrigger(s.broker)
+	go s.qualif.Schedule()
+	s.search = NewSearchTrigger(s.broker)
+	go s.search.Schedule()
+	s.scan = NewAntivirusTrigger(s.broker)
+	go s.scan.Schedule()
+	s.usage = NewDirUsageTrigger()
+	go s.usage.Schedule()
 
 	// XXX The memory scheduler loads the triggers from CouchDB when the stack
 	// is started. This can cause some stability issues when running system
@@ -117,6 +129,10 @@ func (s *memScheduler) ShutdownScheduler(ctx context.Context) error {
 		t.Unschedule()
 	}
 	s.thumb.Unschedule()
+	s.qualif.Unschedule()
+	s.search.Unschedule()
+	s.scan.Unschedule()
+	s.usage.Unschedule()
 	fmt.Println("ok.")
 	return nil
 }
@@ -0,0 +1,59 @@
+package job
+
+import (
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// PublishProgress sends a realtime event with the progress of a job, in the
+// io.cozy.jobs.progress synthetic doctype. The identifier of the event is the
+// identifier of the job, and the data are free, but the fields _id, worker
+// and slug are set by the stack.
+func PublishProgress(inst *instance.Instance, j *Job, data map[string]interface{}) {
+	doc := couchdb.JSONDoc{Type: consts.JobsProgress, M: make(map[string]interface{})}
+	for k, v := range data {
+		doc.M[k] = v
+	}
+	doc.M["_id"] = j.ID()
+	doc.M["worker"] = j.WorkerType
+	if slug := j.Slug(); slug != "" {
+		doc.M["slug"] = slug
+	} else {
+		delete(doc.M, "slug")
+	}
+	delete(doc.M, "_rev")
+	realtime.GetHub().Publish(inst, realtime.EventNotify, &doc, nil)
+}
+
+// PublishProgress sends a realtime event with the progress of the job
+// executed with this context.
+func (c *WorkerContext) PublishProgress(data map[string]interface{}) {
+	PublishProgress(c.Instance, c.job, data)
+}
+
+// Slug returns the slug of the konnector or of the app of the service run
+// by the job, or an empty string for the other workers.
+func (j *Job) Slug() string {
+	var msg struct {
+		Konnector string `json:"konnector"`
+		Slug      string `json:"slug"`
+	}
+	if err := j.Message.Unmarshal(&msg); err != nil {
+		return ""
+	}
+	switch j.WorkerType {
+	case "konnector":
+		return msg.Konnector
+	case "service":
+		return msg.Slug
+	}
+	return ""
+}
+
+// IsRunningKonnector returns true if the job is running the given konnector.
+// It is used to let a konnector publish the progress of its own jobs.
+func (j *Job) IsRunningKonnector(slug string) bool {
+	return j.WorkerType == "konnector" && j.State == Running && j.Slug() == slug
+}
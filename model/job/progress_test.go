@@ -0,0 +1,24 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobSlug(t *testing.T) {
+	konn := &Job{WorkerType: "konnector", State: Running, Message: Message(`{"konnector":"bank","account":"123"}`)}
+	assert.Equal(t, "bank", konn.Slug())
+	assert.True(t, konn.IsRunningKonnector("bank"))
+	assert.False(t, konn.IsRunningKonnector("other"))
+
+	konn.State = Done
+	assert.False(t, konn.IsRunningKonnector("bank"))
+
+	service := &Job{WorkerType: "service", State: Running, Message: Message(`{"slug":"photos","name":"onPhotoUpload"}`)}
+	assert.Equal(t, "photos", service.Slug())
+	assert.False(t, service.IsRunningKonnector("photos"))
+
+	thumb := &Job{WorkerType: "thumbnail", Message: Message(`{"file":"123"}`)}
+	assert.Equal(t, "", thumb.Slug())
+}
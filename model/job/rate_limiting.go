@@ -3,7 +3,9 @@ package job
 import (
 	"errors"
 
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
 )
 
 // GetCounterTypeFromWorkerType returns the CounterTypeFromWorkerType
@@ -35,3 +37,26 @@ func GetCounterTypeFromWorkerType(workerType string) (limits.CounterType, error)
 		return -1, errors.New("CounterType was not found")
 	}
 }
+
+// checkBatchRateLimits checks the rate limits for all the requests of a batch
+// before any of them is counted, so that a batch that would go over a limit
+// is refused as a whole, instead of being partially pushed.
+func checkBatchRateLimits(db prefixer.Prefixer, reqs []*JobRequest) error {
+	counts := make(map[limits.CounterType]int64)
+	for _, req := range reqs {
+		if ct, err := GetCounterTypeFromWorkerType(req.WorkerType); err == nil {
+			counts[ct]++
+		}
+	}
+	limiter := config.GetRateLimiter()
+	for ct, n := range counts {
+		used, err := limiter.GetCountKey(db.DomainName(), ct)
+		if err != nil {
+			return err
+		}
+		if used+n > limits.GetMaximumLimit(ct) {
+			return limits.ErrRateLimitExceeded
+		}
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package job
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -11,8 +10,6 @@ import (
 	"time"
 
 	"github.com/cozy/cozy-stack/pkg/config/config"
-	"github.com/cozy/cozy-stack/pkg/limits"
-	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/redis/go-redis/v9"
@@ -23,8 +20,49 @@ const (
 	redisPrefix = "j/"
 	// redisHighPrioritySuffix suffix is the suffix used for prioritized queue.
 	redisHighPrioritySuffix = "/p0"
+	// redisDelayedSuffix is the suffix used for the sorted set of the jobs
+	// that are scheduled for later, with their scheduled time as score.
+	redisDelayedSuffix = "/delayed"
+	// redisRunningPrefix is the prefix for the counters of the jobs running
+	// for an instance and a worker type.
+	redisRunningPrefix = "j/running/"
 )
 
+// redisRequeueDelay is the delay before a job is put back in the queue when
+// the instance has already reached its maximal number of running jobs for the
+// worker type.
+var redisRequeueDelay = 5 * time.Second
+
+// redisPromoteInterval is the minimal interval between two checks of the
+// delayed jobs that must be moved to the queue.
+var redisPromoteInterval = 1 * time.Second
+
+// promoteScript moves the delayed jobs that are due from the sorted set
+// (KEYS[1]) to the queue (KEYS[2]). It is a script to ensure that a job is
+// moved only once, even if several stacks are polling the same queue.
+var promoteScript = redis.NewScript(`
+local vals = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 100)
+for _, val in ipairs(vals) do
+  redis.call("ZREM", KEYS[1], val)
+  redis.call("LPUSH", KEYS[2], val)
+end
+return #vals
+`)
+
+// acquireScript increments the counter of running jobs (KEYS[1]) if it is
+// below the limit (ARGV[1]), and returns 1 in this case, or 0 if the limit
+// has been reached. The counter expires after ARGV[2] seconds, so that the
+// slots are freed if a stack crashes while running jobs.
+var acquireScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n > tonumber(ARGV[1]) then
+  redis.call("DECR", KEYS[1])
+  return 0
+end
+redis.call("EXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
 type redisBroker struct {
 	client         redis.UniversalClient
 	ctx            context.Context
@@ -63,7 +101,7 @@ func (b *redisBroker) StartWorkers(ws WorkersList) error {
 		if err := w.Start(ch); err != nil {
 			return err
 		}
-		go b.pollLoop(redisPrefix+conf.WorkerType, ch)
+		go b.pollLoop(w, ch)
 	}
 
 	if len(b.workersRunning) > 0 {
@@ -127,22 +165,32 @@ func (b *redisBroker) ShutdownWorkers(ctx context.Context) error {
 var redisBRPopTimeout = 10 * time.Second
 
 // SetRedisTimeoutForTest is used by unit test to avoid waiting 10 seconds on
-// cleanup.
+// cleanup, and 5 seconds for the jobs that have been requeued.
 func SetRedisTimeoutForTest() {
 	redisBRPopTimeout = 1 * time.Second
+	redisRequeueDelay = 1 * time.Second
 }
 
-func (b *redisBroker) pollLoop(key string, ch chan<- *Job) {
+func (b *redisBroker) pollLoop(w *Worker, ch chan<- *Job) {
 	defer func() {
 		b.closed <- struct{}{}
 	}()
 
+	key := redisPrefix + w.Type
+	conf := w.defaultedConf(nil)
+
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var promotedAt time.Time
 	for {
 		if atomic.LoadUint32(&b.running) == 0 {
 			return
 		}
 
+		if time.Since(promotedAt) >= redisPromoteInterval {
+			promotedAt = time.Now()
+			b.promoteDelayed(key)
+		}
+
 		// The brpop redis command will always take elements in priority from the
 		// first key containing elements at the call. By always priorizing the
 		// manual queue, this would cause a starvation for our main queue if too
@@ -160,9 +208,9 @@ func (b *redisBroker) pollLoop(key string, ch chan<- *Job) {
 			continue
 		}
 
-		key, val := results[0], results[1]
-		if len(key) < len(redisPrefix) {
-			joblog.Warnf("Invalid key %s", key)
+		queue, val := results[0], results[1]
+		if len(queue) < len(redisPrefix) {
+			joblog.Warnf("Invalid key %s", queue)
 			continue
 		}
 
@@ -186,10 +234,69 @@ func (b *redisBroker) pollLoop(key string, ch chan<- *Job) {
 			continue
 		}
 
+		if conf.MaxPerInstance > 0 {
+			ok, err := b.acquireSlot(conf, parts[0])
+			if err != nil {
+				joblog.Warnf("Cannot check the running jobs of %s: %s", prefix, err)
+			} else if !ok {
+				b.requeue(key, val)
+				continue
+			} else {
+				job.release = func() { b.releaseSlot(conf, parts[0]) }
+			}
+		}
+
 		ch <- job
 	}
 }
 
+func runningKey(workerType, prefix string) string {
+	return redisRunningPrefix + workerType + "/" + prefix
+}
+
+// acquireSlot returns true if the instance has not reached the maximal number
+// of running jobs for the worker type, and reserves a slot in this case.
+func (b *redisBroker) acquireSlot(conf *WorkerConfig, prefix string) (bool, error) {
+	// The counter must not expire while a job is running, even with retries.
+	ttl := conf.Timeout*time.Duration(conf.MaxExecCount) +
+		conf.RetryDelay*time.Duration(conf.MaxExecCount) + time.Minute
+	keys := []string{runningKey(conf.WorkerType, prefix)}
+	limit := strconv.Itoa(conf.MaxPerInstance)
+	secs := strconv.Itoa(int(ttl.Seconds()))
+	n, err := acquireScript.Run(b.ctx, b.client, keys, limit, secs).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// releaseSlot frees a slot reserved with acquireSlot.
+func (b *redisBroker) releaseSlot(conf *WorkerConfig, prefix string) {
+	key := runningKey(conf.WorkerType, prefix)
+	if err := b.client.Decr(b.ctx, key).Err(); err != nil {
+		joblog.Warnf("Cannot release the slot of %s: %s", key, err)
+	}
+}
+
+// requeue puts back a job in the sorted set of the delayed jobs, so that it
+// will be retried later without blocking the jobs of the other instances.
+func (b *redisBroker) requeue(key, val string) {
+	at := time.Now().Add(redisRequeueDelay)
+	z := redis.Z{Score: float64(at.Unix()), Member: val}
+	if err := b.client.ZAdd(b.ctx, key+redisDelayedSuffix, z).Err(); err != nil {
+		joblog.Errorf("Cannot requeue %s: %s", val, err)
+	}
+}
+
+// promoteDelayed moves the delayed jobs that are due to the queue.
+func (b *redisBroker) promoteDelayed(key string) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	keys := []string{key + redisDelayedSuffix, key}
+	if err := promoteScript.Run(b.ctx, b.client, keys, now).Err(); err != nil {
+		joblog.Warnf("Cannot promote the delayed jobs of %s: %s", key, err)
+	}
+}
+
 // PushJob will produce a new Job with the given options and enqueue the job in
 // the proper queue.
 func (b *redisBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, error) {
@@ -197,53 +304,73 @@ func (b *redisBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, erro
 		return nil, ErrClosed
 	}
 
-	var worker *Worker
-	for _, w := range b.workers {
-		if w.Type == req.WorkerType {
-			worker = w
-			break
-		}
+	job, worker, skip, err := prepareJob(db, b.workers, req)
+	if err != nil {
+		return nil, err
 	}
-	if worker == nil && req.WorkerType != "client" {
-		return nil, ErrUnknownWorker
+	if skip {
+		return job, nil
 	}
 
-	// Check for limits
-	ct, err := GetCounterTypeFromWorkerType(req.WorkerType)
-	if err == nil {
-		err := config.GetRateLimiter().CheckRateLimit(db, ct)
-		if errors.Is(err, limits.ErrRateLimitReached) {
-			joblog.WithFields(logger.Fields{
-				"worker_type": req.WorkerType,
-				"instance":    db.DomainName(),
-			}).Warn(err.Error())
-			return nil, err
-		}
-		if limits.IsLimitReachedOrExceeded(err) {
-			return nil, err
-		}
+	if err := job.Create(); err != nil {
+		return nil, err
 	}
 
-	job := NewJob(db, req)
-	if worker != nil && worker.Conf.BeforeHook != nil {
-		ok, err := worker.Conf.BeforeHook(job)
+	// For client jobs, we don't need to enqueue the job in redis.
+	if worker == nil {
+		return job, nil
+	}
+
+	if err := b.enqueue(b.client, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// PushJobs will produce the jobs for the given requests, create their
+// documents with a single bulk request in CouchDB, and enqueue them in redis
+// with a pipeline.
+func (b *redisBroker) PushJobs(db prefixer.Prefixer, reqs []*JobRequest) ([]*Job, error) {
+	if atomic.LoadUint32(&b.running) == 0 {
+		return nil, ErrClosed
+	}
+	if err := checkBatchRateLimits(db, reqs); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(reqs))
+	for _, req := range reqs {
+		job, _, skip, err := prepareJob(db, b.workers, req)
 		if err != nil {
 			return nil, err
 		}
-		if !ok {
-			return job, nil
+		if !skip {
+			jobs = append(jobs, job)
 		}
 	}
 
-	if err := job.Create(); err != nil {
+	created, err := createJobs(db, jobs)
+	if err != nil {
 		return nil, err
 	}
-
-	// For client jobs, we don't need to enqueue the job in redis.
-	if worker == nil {
-		return job, nil
+	pipe := b.client.Pipeline()
+	for _, job := range created {
+		if job.WorkerType == "client" {
+			continue
+		}
+		if err := b.enqueue(pipe, job); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := pipe.Exec(b.ctx); err != nil {
+		return nil, err
 	}
+	return created, nil
+}
 
+// enqueue adds the job to the queue of its worker in redis. The client can be
+// a pipeline, and in that case, the errors are only returned by Exec.
+func (b *redisBroker) enqueue(client redis.Cmdable, job *Job) error {
 	key := redisPrefix + job.WorkerType
 	prefix := job.DBPrefix()
 	if cluster := job.DBCluster(); cluster > 0 {
@@ -251,17 +378,20 @@ func (b *redisBroker) PushJob(db prefixer.Prefixer, req *JobRequest) (*Job, erro
 	}
 	val := prefix + "/" + job.JobID
 
+	// When the job is scheduled for later, it is put in a sorted set, and
+	// it will be moved to the queue by the polling loop when it is due.
+	if job.ScheduledAt != nil {
+		z := redis.Z{Score: float64(job.ScheduledAt.Unix()), Member: val}
+		return client.ZAdd(b.ctx, key+redisDelayedSuffix, z).Err()
+	}
+
 	// When the job is manual, it is being pushed in a specific prioritized
 	// queue.
 	if job.Manual {
 		key += redisHighPrioritySuffix
 	}
 
-	if err := b.client.LPush(b.ctx, key, val).Err(); err != nil {
-		return nil, err
-	}
-
-	return job, nil
+	return client.LPush(b.ctx, key, val).Err()
 }
 
 // QueueLen returns the size of the number of elements in queue of the
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -120,6 +121,55 @@ func TestRedisBroker(t *testing.T) {
 		time.Sleep(1 * time.Second)
 	})
 
+	t.Run("RedisMaxPerInstance", func(t *testing.T) {
+		job.SetRedisTimeoutForTest()
+		opts1, _ := redis.ParseURL(redisURL1)
+		client1 := redis.NewClient(opts1)
+
+		n := 3
+		var w sync.WaitGroup
+		w.Add(n)
+
+		var running, maxRunning int32
+		workersTestList := job.WorkersList{
+			{
+				WorkerType:     "test-max-per-instance",
+				Concurrency:    4,
+				MaxPerInstance: 1,
+				WorkerFunc: func(ctx *job.WorkerContext) error {
+					r := atomic.AddInt32(&running, 1)
+					if r > atomic.LoadInt32(&maxRunning) {
+						atomic.StoreInt32(&maxRunning, r)
+					}
+					time.Sleep(200 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+					w.Done()
+					return nil
+				},
+			},
+		}
+
+		broker := job.NewRedisBroker(client1)
+		err := broker.StartWorkers(workersTestList)
+		assert.NoError(t, err)
+
+		for i := 0; i < n; i++ {
+			msg, _ := job.NewMessage("m-" + strconv.Itoa(i))
+			_, err = broker.PushJob(testInstance, &job.JobRequest{
+				WorkerType: "test-max-per-instance",
+				Message:    msg,
+			})
+			assert.NoError(t, err)
+		}
+
+		w.Wait()
+		assert.EqualValues(t, 1, atomic.LoadInt32(&maxRunning))
+
+		err = broker.ShutdownWorkers(context.Background())
+		assert.NoError(t, err)
+		time.Sleep(1 * time.Second)
+	})
+
 	t.Run("RedisAddJobRateLimitExceeded", func(t *testing.T) {
 		opts1, _ := redis.ParseURL(redisURL1)
 		client1 := redis.NewClient(opts1)
@@ -176,6 +226,83 @@ func TestRedisBroker(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, j)
 	})
+
+	t.Run("RedisPushJobs", func(t *testing.T) {
+		opts1, _ := redis.ParseURL(redisURL1)
+		client1 := redis.NewClient(opts1)
+		broker := job.NewRedisBroker(client1)
+		var w sync.WaitGroup
+		workersTestList := job.WorkersList{
+			{
+				WorkerType:  "test",
+				Concurrency: 4,
+				WorkerFunc: func(ctx *job.WorkerContext) error {
+					w.Done()
+					return nil
+				},
+			},
+			{
+				WorkerType:  "thumbnail",
+				Concurrency: 4,
+				WorkerFunc: func(ctx *job.WorkerContext) error {
+					return nil
+				},
+			},
+		}
+		assert.NoError(t, broker.StartWorkers(workersTestList))
+		t.Cleanup(func() { _ = broker.ShutdownWorkers(context.Background()) })
+
+		msg, _ := job.NewMessage("batch")
+		reqs := make([]*job.JobRequest, 3)
+		for i := range reqs {
+			reqs[i] = &job.JobRequest{WorkerType: "test", Message: msg}
+		}
+		w.Add(len(reqs))
+		jobs, err := broker.PushJobs(testInstance, reqs)
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 3)
+		for _, j := range jobs {
+			assert.NotEmpty(t, j.ID())
+			assert.NotEmpty(t, j.Rev())
+		}
+		w.Wait()
+
+		// A batch with an unknown worker is refused
+		_, err = broker.PushJobs(testInstance, []*job.JobRequest{
+			{WorkerType: "test", Message: msg},
+			{WorkerType: "nope", Message: msg},
+		})
+		assert.ErrorIs(t, err, job.ErrUnknownWorker)
+
+		// A batch over the rate limit is refused without being counted
+		ct := limits.JobThumbnailType
+		oldLimit := limits.GetMaximumLimit(ct)
+		limits.SetMaximumLimit(ct, 5)
+		t.Cleanup(func() { limits.SetMaximumLimit(ct, oldLimit) })
+		limiter := config.GetRateLimiter()
+		limiter.ResetCounter(testInstance, ct)
+
+		thumbs := make([]*job.JobRequest, 6)
+		for i := range thumbs {
+			thumbs[i] = &job.JobRequest{WorkerType: "thumbnail", Message: msg}
+		}
+		jobs, err = broker.PushJobs(testInstance, thumbs)
+		assert.ErrorIs(t, err, limits.ErrRateLimitExceeded)
+		assert.Nil(t, jobs)
+		count, err := limiter.GetCountKey(testInstance.DomainName(), ct)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, count)
+
+		jobs, err = broker.PushJobs(testInstance, thumbs[:5])
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 5)
+		count, err = limiter.GetCountKey(testInstance.DomainName(), ct)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 5, count)
+
+		_, err = broker.PushJobs(testInstance, thumbs[:1])
+		assert.ErrorIs(t, err, limits.ErrRateLimitExceeded)
+	})
 }
 
 func randomMicro(min, max int) time.Duration {
@@ -59,6 +59,10 @@ type redisScheduler struct {
 	client  redis.UniversalClient
 	ctx     context.Context
 	thumb   *ThumbnailTrigger
+	qualif  *QualificationTrigger
+	search  *SearchTrigger
+	scan    *AntivirusTrigger
+	usage   *DirUsageTrigger
 	closed  chan struct{}
 	stopped chan struct{}
 	log     *logger.Entry
@@ -99,6 +103,14 @@ func (s *redisScheduler) StartScheduler(b Broker) error {
 	s.startEventDispatcher()
 	s.thumb = NewThumbnailTrigger(s.broker)
 	go s.thumb.Schedule()
+	s.qualif = NewQualificationTrigger(s.broker)
+	go s.qualif.Schedule()
+	s.search = NewSearchTrigger(s.broker)
+	go s.search.Schedule()
+	s.scan = NewAntivirusTrigger(s.broker)
+	go s.scan.Schedule()
+	s.usage = NewDirUsageTrigger()
+	go s.usage.Schedule()
 	go s.pollLoop()
 	return nil
 }
@@ -252,6 +264,10 @@ func (s *redisScheduler) ShutdownScheduler(ctx context.Context) error {
 	fmt.Print("  shutting down redis scheduler...")
 	close(s.closed)
 	s.thumb.Unschedule()
+	s.qualif.Unschedule()
+	s.search.Unschedule()
+	s.scan.Unschedule()
+	s.usage.Unschedule()
 	select {
 	case <-ctx.Done():
 		fmt.Println("failed: ", ctx.Err())
@@ -496,6 +496,15 @@ func (b *mockBroker) PushJob(db prefixer.Prefixer, request *job.JobRequest) (*jo
 	return nil, nil
 }
 
+func (b *mockBroker) PushJobs(db prefixer.Prefixer, requests []*job.JobRequest) ([]*job.Job, error) {
+	b.l.Lock()
+
+	b.jobs = append(b.jobs, requests...)
+
+	b.l.Unlock()
+	return nil, nil
+}
+
 func (b *mockBroker) WorkerQueueLen(workerType string) (int, error) {
 	count := 0
 	b.l.Lock()
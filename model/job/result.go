@@ -0,0 +1,37 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// MaxResultSize is the maximal size in bytes of the JSON result of a job.
+const MaxResultSize = 16 * 1024
+
+// ErrResultTooLarge is used when a worker tries to attach a result that is
+// larger than MaxResultSize to its job.
+var ErrResultTooLarge = errors.New("jobs: the result is too large")
+
+// SetResult attaches a small JSON result to the job executed with this
+// context. It is saved in the job document when the job is finished, and so it
+// can be retrieved by the client that has pushed the job (via the API or the
+// realtime events on io.cozy.jobs).
+func (c *WorkerContext) SetResult(v interface{}) error {
+	var raw json.RawMessage
+	if r, ok := v.(json.RawMessage); ok {
+		raw = r
+	} else {
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	if len(raw) > MaxResultSize {
+		return ErrResultTooLarge
+	}
+	if !json.Valid(raw) {
+		return errors.New("jobs: the result is not valid JSON")
+	}
+	c.job.Result = raw
+	return nil
+}
@@ -0,0 +1,30 @@
+package job
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetResult(t *testing.T) {
+	j := &Job{JobID: "123", Domain: "alice.cozy.localhost", WorkerType: "konnector"}
+	ctx := NewWorkerContext("worker-1", j, nil)
+
+	require.NoError(t, ctx.SetResult(map[string]interface{}{"bills": 3}))
+	assert.JSONEq(t, `{"bills": 3}`, string(j.Result))
+
+	require.NoError(t, ctx.SetResult(json.RawMessage(`["a","b"]`)))
+	assert.JSONEq(t, `["a","b"]`, string(j.Result))
+
+	assert.Error(t, ctx.SetResult(json.RawMessage(`{"invalid`)))
+	big := strings.Repeat("x", MaxResultSize)
+	assert.ErrorIs(t, ctx.SetResult(big), ErrResultTooLarge)
+	assert.JSONEq(t, `["a","b"]`, string(j.Result))
+
+	buf, err := json.Marshal(j)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), `"result":["a","b"]`)
+}
@@ -0,0 +1,94 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The backoff strategies for the delay between two executions of a job.
+const (
+	// BackoffExponential doubles the delay after each failure (default).
+	BackoffExponential = "exponential"
+	// BackoffConstant keeps the same delay between the executions.
+	BackoffConstant = "constant"
+)
+
+// maxRetryAttempts is the maximal number of executions of a job that can be
+// asked by a retry policy.
+const maxRetryAttempts = 10
+
+// maxRetryDelay is the maximal delay between two executions of a job that can
+// be asked by a retry policy, as the job is retried by the same goroutine.
+const maxRetryDelay = 30 * time.Minute
+
+// ErrInvalidRetryPolicy is used when the retry policy of a trigger is not
+// valid.
+var ErrInvalidRetryPolicy = errors.New("Invalid retry policy")
+
+// RetryPolicy can be set in the options of a trigger to override the defaults
+// of the worker for the retries of its jobs. For example, a konnector for a
+// bank with a rate-limited API can use an exponential backoff, while other
+// jobs should fail fast.
+type RetryPolicy struct {
+	// MaxAttempts is the maximal number of executions of the job (1 means no
+	// retry).
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// Backoff is the strategy for the delay between two executions.
+	Backoff string `json:"backoff,omitempty"`
+	// Delay is the delay before the first retry, like "30s".
+	Delay string `json:"delay,omitempty"`
+	// MaxDelay is the upper bound for the delay between two executions.
+	MaxDelay string `json:"max_delay,omitempty"`
+}
+
+// Validate checks that the retry policy is valid.
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 || p.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("%w: max_attempts must be between 1 and %d",
+			ErrInvalidRetryPolicy, maxRetryAttempts)
+	}
+	switch p.Backoff {
+	case "", BackoffExponential, BackoffConstant:
+	default:
+		return fmt.Errorf("%w: unknown backoff %q", ErrInvalidRetryPolicy, p.Backoff)
+	}
+	if _, err := parseRetryDelay(p.Delay); err != nil {
+		return fmt.Errorf("%w: delay: %s", ErrInvalidRetryPolicy, err)
+	}
+	if _, err := parseRetryDelay(p.MaxDelay); err != nil {
+		return fmt.Errorf("%w: max_delay: %s", ErrInvalidRetryPolicy, err)
+	}
+	return nil
+}
+
+// apply overrides the configuration of the worker with the retry policy.
+// The invalid values are ignored.
+func (p *RetryPolicy) apply(c *WorkerConfig) {
+	if p.MaxAttempts > 0 && p.MaxAttempts <= maxRetryAttempts {
+		c.MaxExecCount = p.MaxAttempts
+	}
+	if p.Backoff == BackoffExponential || p.Backoff == BackoffConstant {
+		c.Backoff = p.Backoff
+	}
+	if delay, err := parseRetryDelay(p.Delay); err == nil && delay > 0 {
+		c.RetryDelay = delay
+	}
+	if delay, err := parseRetryDelay(p.MaxDelay); err == nil && delay > 0 {
+		c.MaxRetryDelay = delay
+	}
+}
+
+func parseRetryDelay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 || d > maxRetryDelay {
+		return 0, fmt.Errorf("must be between 0 and %s", maxRetryDelay)
+	}
+	return d, nil
+}
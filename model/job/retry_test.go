@@ -0,0 +1,65 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyValidate(t *testing.T) {
+	assert.NoError(t, (&RetryPolicy{}).Validate())
+	assert.NoError(t, (&RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     BackoffExponential,
+		Delay:       "30s",
+		MaxDelay:    "10m",
+	}).Validate())
+
+	assert.ErrorIs(t, (&RetryPolicy{MaxAttempts: 100}).Validate(), ErrInvalidRetryPolicy)
+	assert.ErrorIs(t, (&RetryPolicy{Backoff: "linear"}).Validate(), ErrInvalidRetryPolicy)
+	assert.ErrorIs(t, (&RetryPolicy{Delay: "soon"}).Validate(), ErrInvalidRetryPolicy)
+	assert.ErrorIs(t, (&RetryPolicy{MaxDelay: "24h"}).Validate(), ErrInvalidRetryPolicy)
+}
+
+func TestRetryPolicyNextDelay(t *testing.T) {
+	w := &Worker{Conf: &WorkerConfig{
+		MaxExecCount: 2,
+		RetryDelay:   time.Second,
+	}}
+
+	conf := w.defaultedConf(&JobOptions{Retry: &RetryPolicy{
+		MaxAttempts: 5,
+		Delay:       "10s",
+		MaxDelay:    "30s",
+	}})
+	assert.Equal(t, 5, conf.MaxExecCount)
+	tsk := &task{conf: conf}
+	var delays []time.Duration
+	for {
+		retry, delay, _ := tsk.nextDelay(nil)
+		if !retry {
+			break
+		}
+		delays = append(delays, delay)
+		tsk.execCount++
+	}
+	assert.Len(t, delays, 5)
+	assert.Equal(t, time.Duration(0), delays[0])
+	assert.InDelta(t, 10*time.Second, delays[1], float64(time.Second))
+	assert.InDelta(t, 20*time.Second, delays[2], float64(2*time.Second))
+	assert.InDelta(t, 30*time.Second, delays[3], float64(3*time.Second))
+	assert.InDelta(t, 30*time.Second, delays[4], float64(3*time.Second))
+
+	// Fail fast
+	conf = w.defaultedConf(&JobOptions{Retry: &RetryPolicy{MaxAttempts: 1}})
+	assert.Equal(t, 1, conf.MaxExecCount)
+
+	conf = w.defaultedConf(&JobOptions{Retry: &RetryPolicy{
+		Backoff: BackoffConstant,
+		Delay:   "5s",
+	}})
+	tsk = &task{conf: conf, execCount: 1}
+	_, delay, _ := tsk.nextDelay(nil)
+	assert.InDelta(t, 5*time.Second, delay, float64(time.Second))
+}
@@ -3,6 +3,7 @@ package job
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/cozy/cozy-stack/model/permission"
@@ -70,7 +71,9 @@ type (
 		Arguments    string                 `json:"arguments"`
 		Debounce     string                 `json:"debounce"`
 		Options      *JobOptions            `json:"options"`
+		Filter       *EventFilter           `json:"filter,omitempty"`
 		Message      Message                `json:"message"`
+		Secret       string                 `json:"secret,omitempty"`
 		CurrentState *TriggerState          `json:"current_state,omitempty"`
 		Metadata     *metadata.CozyMetadata `json:"cozyMetadata,omitempty"`
 	}
@@ -129,6 +132,15 @@ func NewTrigger(db prefixer.Prefixer, infos TriggerInfos, data interface{}) (Tri
 	infos.Prefix = db.DBPrefix()
 	infos.Domain = db.DomainName()
 
+	if infos.Filter != nil && infos.Type != "@event" {
+		return nil, fmt.Errorf("%w: only for @event triggers", ErrInvalidEventFilter)
+	}
+	if infos.Options != nil && infos.Options.Retry != nil {
+		if err := infos.Options.Retry.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Adding metadata
 	md := metadata.New()
 	md.DocTypeVersion = DocTypeVersionTrigger
@@ -0,0 +1,104 @@
+package job
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// statsMaxJobs is the maximal number of jobs used to compute the statistics.
+const statsMaxJobs = 1000
+
+// Durations are the median and the 95th percentile of some durations, in
+// seconds.
+type Durations struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+}
+
+// Stats are the statistics about the jobs of a worker type that have finished
+// since a given time.
+type Stats struct {
+	WorkerType string    `json:"worker"`
+	Since      time.Time `json:"since"`
+	Done       int       `json:"done"`
+	Errored    int       `json:"errored"`
+	// Execution is the time between the start and the end of the jobs
+	Execution Durations `json:"execution"`
+	// QueueWait is the time between the push and the start of the jobs
+	QueueWait Durations `json:"queue_wait"`
+}
+
+// GetStats returns the statistics about the jobs of the given worker type that
+// have been queued since the given time. Only the most recent jobs are used
+// if there are a lot of them.
+func GetStats(db prefixer.Prefixer, workerType string, since time.Time) (*Stats, error) {
+	var jobs []*Job
+	req := &couchdb.FindRequest{
+		UseIndex: "by-queued-at",
+		Selector: mango.And(
+			mango.Gt("queued_at", since),
+			mango.Equal("worker", workerType),
+			mango.In("state", []interface{}{Done, Errored}),
+		),
+		Sort:  mango.SortBy{{Field: "queued_at", Direction: mango.Desc}},
+		Limit: statsMaxJobs,
+	}
+	err := couchdb.FindDocs(db, consts.Jobs, req, &jobs)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return computeStats(workerType, since, jobs), nil
+}
+
+func computeStats(workerType string, since time.Time, jobs []*Job) *Stats {
+	stats := &Stats{WorkerType: workerType, Since: since}
+	var executions, waits []float64
+	for _, j := range jobs {
+		switch j.State {
+		case Done:
+			stats.Done++
+		case Errored:
+			stats.Errored++
+		default:
+			continue
+		}
+		if j.StartedAt.IsZero() || j.FinishedAt.IsZero() {
+			continue
+		}
+		queuedAt := j.QueuedAt
+		if j.ScheduledAt != nil && j.ScheduledAt.After(queuedAt) {
+			queuedAt = *j.ScheduledAt
+		}
+		executions = append(executions, j.FinishedAt.Sub(j.StartedAt).Seconds())
+		waits = append(waits, math.Max(0, j.StartedAt.Sub(queuedAt).Seconds()))
+	}
+	stats.Execution = percentiles(executions)
+	stats.QueueWait = percentiles(waits)
+	return stats
+}
+
+func percentiles(values []float64) Durations {
+	if len(values) == 0 {
+		return Durations{}
+	}
+	sort.Float64s(values)
+	return Durations{
+		P50: percentile(values, 0.50),
+		P95: percentile(values, 0.95),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
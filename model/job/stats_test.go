@@ -0,0 +1,37 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStats(t *testing.T) {
+	now := time.Now()
+	var jobs []*Job
+	for i := 1; i <= 20; i++ {
+		queuedAt := now.Add(-time.Hour)
+		startedAt := queuedAt.Add(time.Duration(i) * time.Second)
+		jobs = append(jobs, &Job{
+			State:      Done,
+			QueuedAt:   queuedAt,
+			StartedAt:  startedAt,
+			FinishedAt: startedAt.Add(time.Duration(10*i) * time.Second),
+		})
+	}
+	jobs[0].State = Errored
+	jobs = append(jobs, &Job{State: Running, QueuedAt: now})
+
+	stats := computeStats("konnector", now.Add(-24*time.Hour), jobs)
+	assert.Equal(t, "konnector", stats.WorkerType)
+	assert.Equal(t, 19, stats.Done)
+	assert.Equal(t, 1, stats.Errored)
+	assert.Equal(t, 100.0, stats.Execution.P50)
+	assert.Equal(t, 190.0, stats.Execution.P95)
+	assert.Equal(t, 10.0, stats.QueueWait.P50)
+	assert.Equal(t, 19.0, stats.QueueWait.P95)
+
+	empty := computeStats("konnector", now, nil)
+	assert.Equal(t, Durations{}, empty.Execution)
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// AntivirusTrigger pushes a job for the scan-file worker when a file is
+// created or updated, if the antivirus is enabled.
+type AntivirusTrigger struct {
+	broker      Broker
+	log         *logger.Entry
+	unscheduled chan struct{}
+}
+
+// NewAntivirusTrigger returns a new AntivirusTrigger.
+func NewAntivirusTrigger(broker Broker) *AntivirusTrigger {
+	return &AntivirusTrigger{
+		broker:      broker,
+		log:         logger.WithNamespace("scheduler"),
+		unscheduled: make(chan struct{}),
+	}
+}
+
+// Schedule listens to the realtime events until the trigger is unscheduled.
+func (t *AntivirusTrigger) Schedule() {
+	if config.GetConfig().Antivirus.Address == "" {
+		return
+	}
+	sub := realtime.GetHub().SubscribeFirehose()
+	defer sub.Close()
+	for {
+		select {
+		case e := <-sub.Channel:
+			if t.match(e) {
+				t.pushJob(e)
+			}
+		case <-t.unscheduled:
+			return
+		}
+	}
+}
+
+func (t *AntivirusTrigger) match(e *realtime.Event) bool {
+	if e.Doc.DocType() != consts.Files {
+		return false
+	}
+	if e.Verb != realtime.EventCreate && e.Verb != realtime.EventUpdate {
+		return false
+	}
+	if doc, ok := e.Doc.(permission.Fetcher); ok {
+		for _, typ := range doc.Fetch("type") {
+			if typ == consts.FileType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *AntivirusTrigger) pushJob(e *realtime.Event) {
+	event, err := NewEvent(e)
+	if err != nil {
+		return
+	}
+	req := &JobRequest{
+		WorkerType: "scan-file",
+		Message:    Message("{}"),
+		Event:      event,
+	}
+	log := t.log.WithField("domain", e.Domain)
+	log.Debugf("trigger antivirus: Pushing new job")
+	if _, err := t.broker.PushJob(e, req); err != nil {
+		log.Errorf("trigger antivirus: Could not schedule a new job: %s", err.Error())
+	}
+}
+
+// Unschedule stops the trigger.
+func (t *AntivirusTrigger) Unschedule() {
+	close(t.unscheduled)
+}
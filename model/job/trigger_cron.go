@@ -2,8 +2,12 @@ package job
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/robfig/cron/v3"
 )
 
@@ -20,9 +24,17 @@ var (
 	periodicParser = NewPeriodicParser()
 )
 
-// NewCronTrigger returns a new instance of CronTrigger given the specified options.
+// NewCronTrigger returns a new instance of CronTrigger given the specified
+// options. The expression is evaluated in the timezone of the instance, unless
+// it starts with an explicit CRON_TZ= (or TZ=).
 func NewCronTrigger(infos *TriggerInfos) (*CronTrigger, error) {
-	schedule, err := cronParser.Parse(infos.Arguments)
+	spec := strings.TrimSpace(infos.Arguments)
+	if !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		if tz := instanceTimezone(infos); tz != "" {
+			spec = "CRON_TZ=" + tz + " " + spec
+		}
+	}
+	schedule, err := cronParser.Parse(spec)
 	if err != nil {
 		return nil, ErrMalformedTrigger
 	}
@@ -33,6 +45,27 @@ func NewCronTrigger(infos *TriggerInfos) (*CronTrigger, error) {
 	}, nil
 }
 
+// instanceTimezone returns the name of the timezone from the settings of the
+// instance, or an empty string if it is missing or invalid. In this case, the
+// local timezone of the server is used.
+var instanceTimezone = func(db prefixer.Prefixer) string {
+	if db.DomainName() == "" {
+		return ""
+	}
+	var doc couchdb.JSONDoc
+	if err := couchdb.GetDoc(db, consts.Settings, consts.InstanceSettingsID, &doc); err != nil {
+		return ""
+	}
+	tz, _ := doc.M["tz"].(string)
+	if tz == "" {
+		return ""
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return ""
+	}
+	return tz
+}
+
 // NewEveryTrigger returns a new instance of CronTrigger given the specified
 // options as @every.
 func NewEveryTrigger(infos *TriggerInfos) (*CronTrigger, error) {
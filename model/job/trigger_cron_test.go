@@ -0,0 +1,50 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronTriggerTimezone(t *testing.T) {
+	prev := instanceTimezone
+	t.Cleanup(func() { instanceTimezone = prev })
+	instanceTimezone = func(db prefixer.Prefixer) string { return "Europe/Paris" }
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	infos := &TriggerInfos{
+		Domain:    "cron.cozy.localhost",
+		Type:      "@cron",
+		Arguments: "0 7 * * MON-FRI",
+	}
+	trigger, err := NewCronTrigger(infos)
+	require.NoError(t, err)
+
+	// Friday, March 17, 2023 at 12:00 UTC -> Monday at 7:00 in Paris (CET)
+	last := time.Date(2023, time.March, 17, 12, 0, 0, 0, time.UTC)
+	next := trigger.NextExecution(last)
+	assert.Equal(t, time.Date(2023, time.March, 20, 7, 0, 0, 0, paris), next.In(paris))
+	assert.Equal(t, 6, next.UTC().Hour())
+
+	// Summer time starts on March 26, 2023 in Paris (CEST)
+	last = time.Date(2023, time.March, 24, 12, 0, 0, 0, time.UTC)
+	next = trigger.NextExecution(last)
+	assert.Equal(t, time.Date(2023, time.March, 27, 7, 0, 0, 0, paris), next.In(paris))
+	assert.Equal(t, 5, next.UTC().Hour())
+
+	// An explicit timezone is kept
+	infos.Arguments = "CRON_TZ=America/New_York 0 7 * * MON-FRI"
+	trigger, err = NewCronTrigger(infos)
+	require.NoError(t, err)
+	next = trigger.NextExecution(time.Date(2023, time.March, 24, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, 11, next.UTC().Hour())
+
+	infos.Arguments = "CRON_TZ=Nowhere/Unknown 0 7 * * *"
+	_, err = NewCronTrigger(infos)
+	assert.Equal(t, ErrMalformedTrigger, err)
+}
@@ -0,0 +1,52 @@
+package job
+
+import (
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// DirUsageTrigger updates the usage of the directories with a quota when a
+// file or directory is changed. It doesn't push jobs: the usages are updated
+// directly from the realtime events.
+type DirUsageTrigger struct {
+	log         *logger.Entry
+	unscheduled chan struct{}
+}
+
+// NewDirUsageTrigger returns a new DirUsageTrigger.
+func NewDirUsageTrigger() *DirUsageTrigger {
+	return &DirUsageTrigger{
+		log:         logger.WithNamespace("scheduler"),
+		unscheduled: make(chan struct{}),
+	}
+}
+
+// Schedule listens to the realtime events until the trigger is unscheduled.
+func (t *DirUsageTrigger) Schedule() {
+	sub := realtime.GetHub().SubscribeFirehose()
+	defer sub.Close()
+	for {
+		select {
+		case e := <-sub.Channel:
+			if e.Doc.DocType() == consts.Files {
+				t.update(e)
+			}
+		case <-t.unscheduled:
+			return
+		}
+	}
+}
+
+func (t *DirUsageTrigger) update(e *realtime.Event) {
+	if err := vfs.UpdateDirUsages(e, e.Verb, e.Doc, e.OldDoc); err != nil {
+		t.log.WithField("domain", e.Domain).
+			Errorf("trigger dir usage: Could not update the usages: %s", err.Error())
+	}
+}
+
+// Unschedule stops the trigger.
+func (t *DirUsageTrigger) Unschedule() {
+	close(t.unscheduled)
+}
@@ -1,13 +1,17 @@
 package job
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/realtime"
 )
@@ -19,9 +23,102 @@ type EventTrigger struct {
 	mask        []permission.Rule
 }
 
+// EventFilter can be used to restrict the events that fire an @event trigger,
+// by looking at the old and new revisions of the document. It avoids to
+// spawn jobs for irrelevant updates on the doctypes with many changes, like
+// io.cozy.files. All the conditions must be true to fire the trigger.
+type EventFilter struct {
+	// Selector is a mango selector that the new revision of the document
+	// must match.
+	Selector mango.Map `json:"selector,omitempty"`
+	// OldSelector is a mango selector that the old revision of the document
+	// must match. The documents that have just been created have no old
+	// revision, and won't match.
+	OldSelector mango.Map `json:"old_selector,omitempty"`
+	// Changed is a list of fields (with a dotted path for the nested fields),
+	// and at least one of them must have a different value in the old and
+	// new revisions. A created or deleted document is considered as changed.
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Validate checks that the filter can be evaluated.
+func (f *EventFilter) Validate() error {
+	if f.Selector == nil && f.OldSelector == nil && len(f.Changed) == 0 {
+		return fmt.Errorf("%w: the filter is empty", ErrInvalidEventFilter)
+	}
+	if f.Selector != nil {
+		if err := mango.ValidateSelector(f.Selector); err != nil {
+			return fmt.Errorf("%w: selector: %s", ErrInvalidEventFilter, err)
+		}
+	}
+	if f.OldSelector != nil {
+		if err := mango.ValidateSelector(f.OldSelector); err != nil {
+			return fmt.Errorf("%w: old_selector: %s", ErrInvalidEventFilter, err)
+		}
+	}
+	for _, field := range f.Changed {
+		if field == "" {
+			return fmt.Errorf("%w: changed: empty field", ErrInvalidEventFilter)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the event passes the filter.
+func (f *EventFilter) Match(e *realtime.Event) bool {
+	doc := docToMap(e.Doc)
+	old := docToMap(e.OldDoc)
+	if f.Selector != nil && (doc == nil || !mango.Matches(f.Selector, doc)) {
+		return false
+	}
+	if f.OldSelector != nil && (old == nil || !mango.Matches(f.OldSelector, old)) {
+		return false
+	}
+	if len(f.Changed) > 0 && e.Verb == realtime.EventUpdate {
+		if doc == nil || old == nil {
+			return false
+		}
+		changed := false
+		for _, field := range f.Changed {
+			if mango.FieldChanged(old, doc, field) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+	return true
+}
+
+// docToMap returns the document as decoded from JSON, or nil.
+func docToMap(doc realtime.Doc) map[string]interface{} {
+	if doc == nil {
+		return nil
+	}
+	if v := reflect.ValueOf(doc); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
 // NewEventTrigger returns a new instance of EventTrigger given the specified
 // options.
 func NewEventTrigger(infos *TriggerInfos) (*EventTrigger, error) {
+	if infos.Filter != nil {
+		if err := infos.Filter.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	args := strings.Split(infos.Arguments, " ")
 	rules := make([]permission.Rule, len(args))
 	for i, arg := range args {
@@ -65,6 +162,11 @@ func (t *EventTrigger) Schedule() <-chan *JobRequest {
 						break
 					}
 				}
+				// The filter is evaluated before the debounce, to not
+				// delay a job for an irrelevant event.
+				if found && t.Filter != nil {
+					found = t.Filter.Match(e)
+				}
 				if found {
 					if evt, err := t.Infos().JobRequestWithEvent(e); err == nil {
 						ch <- evt
@@ -221,3 +221,66 @@ func makeMessage(t *testing.T, msg string) job.Message {
 	assert.NoError(t, err)
 	return out
 }
+
+func TestEventFilter(t *testing.T) {
+	newDoc := func(m map[string]interface{}) *couchdb.JSONDoc {
+		return &couchdb.JSONDoc{Type: "io.cozy.files", M: m}
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		assert.ErrorIs(t, (&job.EventFilter{}).Validate(), job.ErrInvalidEventFilter)
+		f := &job.EventFilter{Selector: map[string]interface{}{"$where": "true"}}
+		assert.ErrorIs(t, f.Validate(), job.ErrInvalidEventFilter)
+		f = &job.EventFilter{Changed: []string{"trashed"}}
+		assert.NoError(t, f.Validate())
+	})
+
+	t.Run("TrashedTransition", func(t *testing.T) {
+		f := &job.EventFilter{
+			Selector:    map[string]interface{}{"trashed": true},
+			OldSelector: map[string]interface{}{"trashed": false},
+		}
+		require.NoError(t, f.Validate())
+
+		assert.True(t, f.Match(&realtime.Event{
+			Verb:   realtime.EventUpdate,
+			Doc:    newDoc(map[string]interface{}{"_id": "foo", "trashed": true}),
+			OldDoc: newDoc(map[string]interface{}{"_id": "foo", "trashed": false}),
+		}))
+		assert.False(t, f.Match(&realtime.Event{
+			Verb:   realtime.EventUpdate,
+			Doc:    newDoc(map[string]interface{}{"_id": "foo", "trashed": true, "name": "bar"}),
+			OldDoc: newDoc(map[string]interface{}{"_id": "foo", "trashed": true, "name": "baz"}),
+		}))
+		assert.False(t, f.Match(&realtime.Event{
+			Verb: realtime.EventCreate,
+			Doc:  newDoc(map[string]interface{}{"_id": "foo", "trashed": true}),
+		}))
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		f := &job.EventFilter{Changed: []string{"name", "metadata.datetime"}}
+		require.NoError(t, f.Validate())
+
+		assert.True(t, f.Match(&realtime.Event{
+			Verb: realtime.EventCreate,
+			Doc:  newDoc(map[string]interface{}{"_id": "foo", "name": "bar"}),
+		}))
+		assert.True(t, f.Match(&realtime.Event{
+			Verb: realtime.EventUpdate,
+			Doc: newDoc(map[string]interface{}{
+				"_id":      "foo",
+				"metadata": map[string]interface{}{"datetime": "2024-01-02"},
+			}),
+			OldDoc: newDoc(map[string]interface{}{
+				"_id":      "foo",
+				"metadata": map[string]interface{}{"datetime": "2024-01-01"},
+			}),
+		}))
+		assert.False(t, f.Match(&realtime.Event{
+			Verb:   realtime.EventUpdate,
+			Doc:    newDoc(map[string]interface{}{"_id": "foo", "name": "bar", "size": 2}),
+			OldDoc: newDoc(map[string]interface{}{"_id": "foo", "name": "bar", "size": 1}),
+		}))
+	})
+}
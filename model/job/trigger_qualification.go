@@ -0,0 +1,99 @@
+package job
+
+import (
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// QualificationRulesKey is the key in the context of the configuration for
+// the rules used to qualify automatically the new files.
+const QualificationRulesKey = "qualification_rules"
+
+// QualificationTrigger pushes a job for the qualification worker when a new
+// file is created, if some rules of qualification have been configured.
+type QualificationTrigger struct {
+	broker      Broker
+	log         *logger.Entry
+	unscheduled chan struct{}
+}
+
+// NewQualificationTrigger returns a new QualificationTrigger.
+func NewQualificationTrigger(broker Broker) *QualificationTrigger {
+	return &QualificationTrigger{
+		broker:      broker,
+		log:         logger.WithNamespace("scheduler"),
+		unscheduled: make(chan struct{}),
+	}
+}
+
+// Schedule listens to the realtime events until the trigger is unscheduled.
+func (t *QualificationTrigger) Schedule() {
+	sub := realtime.GetHub().SubscribeFirehose()
+	defer sub.Close()
+	for {
+		select {
+		case e := <-sub.Channel:
+			if t.match(e) {
+				t.pushJob(e)
+			}
+		case <-t.unscheduled:
+			return
+		}
+	}
+}
+
+func (t *QualificationTrigger) match(e *realtime.Event) bool {
+	if e.Doc.DocType() != consts.Files || e.Verb != realtime.EventCreate {
+		return false
+	}
+	if !hasQualificationRules() {
+		return false
+	}
+	if doc, ok := e.Doc.(permission.Fetcher); ok {
+		for _, typ := range doc.Fetch("type") {
+			if typ == consts.FileType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasQualificationRules returns true if at least one context of the
+// configuration has some rules of qualification. The worker checks the
+// context of the instance, but it avoids pushing useless jobs.
+func hasQualificationRules() bool {
+	for _, ctx := range config.GetConfig().Contexts {
+		if settings, ok := ctx.(map[string]interface{}); ok {
+			if _, ok := settings[QualificationRulesKey]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *QualificationTrigger) pushJob(e *realtime.Event) {
+	event, err := NewEvent(e)
+	if err != nil {
+		return
+	}
+	req := &JobRequest{
+		WorkerType: "qualification",
+		Message:    Message("{}"),
+		Event:      event,
+	}
+	log := t.log.WithField("domain", e.Domain)
+	log.Debugf("trigger qualification: Pushing new job")
+	if _, err := t.broker.PushJob(e, req); err != nil {
+		log.Errorf("trigger qualification: Could not schedule a new job: %s", err.Error())
+	}
+}
+
+// Unschedule stops the trigger.
+func (t *QualificationTrigger) Unschedule() {
+	close(t.unscheduled)
+}
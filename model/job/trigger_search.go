@@ -0,0 +1,81 @@
+package job
+
+import (
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// SearchTrigger pushes a job for the search-index worker when a file is
+// created, updated or deleted, if the full-text search is enabled.
+type SearchTrigger struct {
+	broker      Broker
+	log         *logger.Entry
+	unscheduled chan struct{}
+}
+
+// NewSearchTrigger returns a new SearchTrigger.
+func NewSearchTrigger(broker Broker) *SearchTrigger {
+	return &SearchTrigger{
+		broker:      broker,
+		log:         logger.WithNamespace("scheduler"),
+		unscheduled: make(chan struct{}),
+	}
+}
+
+// Schedule listens to the realtime events until the trigger is unscheduled.
+func (t *SearchTrigger) Schedule() {
+	if config.GetConfig().Search.IndexDir == "" {
+		return
+	}
+	sub := realtime.GetHub().SubscribeFirehose()
+	defer sub.Close()
+	for {
+		select {
+		case e := <-sub.Channel:
+			if t.match(e) {
+				t.pushJob(e)
+			}
+		case <-t.unscheduled:
+			return
+		}
+	}
+}
+
+func (t *SearchTrigger) match(e *realtime.Event) bool {
+	if e.Doc.DocType() != consts.Files || e.Verb == realtime.EventNotify {
+		return false
+	}
+	if doc, ok := e.Doc.(permission.Fetcher); ok {
+		for _, typ := range doc.Fetch("type") {
+			if typ == consts.FileType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *SearchTrigger) pushJob(e *realtime.Event) {
+	event, err := NewEvent(e)
+	if err != nil {
+		return
+	}
+	req := &JobRequest{
+		WorkerType: "search-index",
+		Message:    Message("{}"),
+		Event:      event,
+	}
+	log := t.log.WithField("domain", e.Domain)
+	log.Debugf("trigger search: Pushing new job")
+	if _, err := t.broker.PushJob(e, req); err != nil {
+		log.Errorf("trigger search: Could not schedule a new job: %s", err.Error())
+	}
+}
+
+// Unschedule stops the trigger.
+func (t *SearchTrigger) Unschedule() {
+	close(t.unscheduled)
+}
@@ -46,7 +46,7 @@ func (t *ThumbnailTrigger) match(e *realtime.Event) bool {
 
 	if doc, ok := e.Doc.(permission.Fetcher); ok {
 		for _, class := range doc.Fetch("class") {
-			if class == "image" || class == "pdf" {
+			if class == "image" || class == "pdf" || class == "video" {
 				return true
 			}
 		}
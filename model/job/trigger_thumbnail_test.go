@@ -0,0 +1,31 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnailTriggerMatch(t *testing.T) {
+	trigger := NewThumbnailTrigger(nil)
+	event := func(verb, doctype, class string) *realtime.Event {
+		doc := &couchdb.JSONDoc{
+			Type: doctype,
+			M:    map[string]interface{}{"_id": "123", "class": class},
+		}
+		return &realtime.Event{Domain: "cozy.example.com", Verb: verb, Doc: doc}
+	}
+
+	assert.True(t, trigger.match(event(realtime.EventCreate, consts.Files, "image")))
+	assert.True(t, trigger.match(event(realtime.EventUpdate, consts.Files, "pdf")))
+	assert.True(t, trigger.match(event(realtime.EventCreate, consts.Files, "video")))
+	assert.True(t, trigger.match(event(realtime.EventDelete, consts.Files, "video")))
+
+	assert.False(t, trigger.match(event(realtime.EventCreate, consts.Files, "audio")))
+	assert.False(t, trigger.match(event(realtime.EventCreate, consts.Files, "text")))
+	assert.False(t, trigger.match(event(realtime.EventNotify, consts.Files, "video")))
+	assert.False(t, trigger.match(event(realtime.EventCreate, consts.Contacts, "video")))
+}
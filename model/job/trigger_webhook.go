@@ -1,6 +1,25 @@
 package job
 
-import "sync"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cozy/cozy-stack/pkg/crypto"
+)
+
+// WebhookHMACArgument is the argument of a @webhook trigger for requiring a
+// HMAC-SHA256 signature of the payload.
+const WebhookHMACArgument = "hmac"
+
+// WebhookSignatureHeader is the HTTP header where the signature of the payload
+// is sent, as sha256=<hex of the HMAC-SHA256 of the body>.
+const WebhookSignatureHeader = "X-Cozy-Signature-256"
+
+const webhookSecretLength = 32
 
 type firer interface {
 	fire(trigger Trigger, request *JobRequest)
@@ -15,8 +34,18 @@ type WebhookTrigger struct {
 	cb firer
 }
 
-// NewWebhookTrigger returns a new instance of WebhookTrigger.
+// NewWebhookTrigger returns a new instance of WebhookTrigger. When the hmac
+// argument is given, a secret is generated for signing the payloads.
 func NewWebhookTrigger(infos *TriggerInfos) (*WebhookTrigger, error) {
+	switch strings.TrimSpace(infos.Arguments) {
+	case "":
+	case WebhookHMACArgument:
+		if infos.Secret == "" {
+			infos.Secret = crypto.GenerateRandomString(webhookSecretLength)
+		}
+	default:
+		return nil, fmt.Errorf("invalid argument for @webhook: %q", infos.Arguments)
+	}
 	return &WebhookTrigger{TriggerInfos: infos}, nil
 }
 
@@ -59,6 +88,25 @@ func (w *WebhookTrigger) SetCallback(cb firer) {
 	w.cb = cb
 }
 
+// VerifySignature checks the signature of the payload, if the webhook
+// requires one. The signature is the hex of the HMAC-SHA256 of the payload
+// with the secret of the trigger, prefixed by sha256=.
+func (w *WebhookTrigger) VerifySignature(signature string, payload []byte) error {
+	if w.Secret == "" {
+		return nil
+	}
+	given, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(given) == 0 {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write(payload)
+	if !hmac.Equal(given, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
 // Fire is called with a payload when the webhook has been requested.
 func (w *WebhookTrigger) Fire(payload Payload, manual bool) {
 	w.mu.Lock()
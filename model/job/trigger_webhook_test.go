@@ -0,0 +1,41 @@
+package job
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookTrigger(t *testing.T) {
+	payload := []byte(`{"event":"transactions"}`)
+
+	w, err := NewWebhookTrigger(&TriggerInfos{Type: "@webhook"})
+	require.NoError(t, err)
+	assert.Empty(t, w.Secret)
+	assert.NoError(t, w.VerifySignature("", payload))
+
+	_, err = NewWebhookTrigger(&TriggerInfos{Type: "@webhook", Arguments: "foo"})
+	assert.Error(t, err)
+
+	w, err = NewWebhookTrigger(&TriggerInfos{Type: "@webhook", Arguments: "hmac"})
+	require.NoError(t, err)
+	require.Len(t, w.Secret, webhookSecretLength)
+	secret := w.Secret
+
+	// The secret is kept when the trigger is loaded again
+	w, err = NewWebhookTrigger(w.TriggerInfos)
+	require.NoError(t, err)
+	assert.Equal(t, secret, w.Secret)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	assert.NoError(t, w.VerifySignature(signature, payload))
+	assert.ErrorIs(t, w.VerifySignature("", payload), ErrInvalidSignature)
+	assert.ErrorIs(t, w.VerifySignature("sha256=zz", payload), ErrInvalidSignature)
+	assert.ErrorIs(t, w.VerifySignature(signature, []byte(`{}`)), ErrInvalidSignature)
+}
@@ -67,9 +67,19 @@ type (
 		ErrorHook    JobErrorCheckerHook
 		Concurrency  int
 		MaxExecCount int
-		Reserved     bool // true when the clients must not push jobs for this worker
-		Timeout      time.Duration
-		RetryDelay   time.Duration
+		// MaxPerInstance is the maximal number of jobs of this type that
+		// can run at the same time for a single instance (0 means no limit).
+		// It is only enforced by the redis broker.
+		MaxPerInstance int
+		Reserved       bool // true when the clients must not push jobs for this worker
+		Timeout        time.Duration
+		RetryDelay     time.Duration
+		// Backoff is the strategy for the delay between two executions of a
+		// job (exponential by default).
+		Backoff string
+		// MaxRetryDelay is the upper bound for the delay between two
+		// executions of a job (0 means no limit).
+		MaxRetryDelay time.Duration
 	}
 
 	// Worker is a unit of work that will consume from a queue and execute the do
@@ -175,6 +185,11 @@ func (c *WorkerContext) ID() string {
 	return c.id
 }
 
+// JobID returns the identifier of the job executed with this context.
+func (c *WorkerContext) JobID() string {
+	return c.job.ID()
+}
+
 // Logger return the logger associated with the worker context.
 func (c *WorkerContext) Logger() logger.Logger {
 	return c.log
@@ -265,90 +280,102 @@ func (w *Worker) Shutdown(ctx context.Context) error {
 
 func (w *Worker) work(workerID string, closed chan<- struct{}) {
 	for job := range w.jobs {
-		domain := job.Domain
-		if domain == "" {
-			joblog.Errorf("%s: missing domain from job request", workerID)
-			continue
+		w.process(workerID, job)
+		if job.release != nil {
+			job.release()
 		}
-		var inst *instance.Instance
-		if domain != prefixer.GlobalPrefixer.DomainName() {
-			var err error
-			inst, err = instance.Get(job.Domain)
-			if err != nil {
-				joblog.Errorf("Instance not found for %s: %s", job.Domain, err)
-				continue
-			}
-			// Do not execute jobs for instances with blocking not signed TOS,
-			// except for:
-			// - mails because the user may needs a mail to login and accept
-			//   the new TOS (2FA, password reset, etc.)
-			// - migrations because the old version may be no longer supported
-			//   when the user will sign the TOS
-			if w.Type != "sendmail" && w.Type != "migrations" {
-				notSigned, deadline := inst.CheckTOSNotSignedAndDeadline()
-				if notSigned && deadline == instance.TOSBlocked {
-					continue
-				}
-			}
-		}
-		parentCtx := NewWorkerContext(workerID, job, inst)
-		if err := job.AckConsumed(); err != nil {
-			parentCtx.Logger().Errorf("error acking consume job: %s",
-				err.Error())
-			continue
-		}
-		t := &task{
-			w:    w,
-			ctx:  parentCtx,
-			job:  job,
-			conf: w.defaultedConf(job.Options),
+	}
+	joblog.Debugf("%s: worker shut down", workerID)
+	closed <- struct{}{}
+}
+
+func (w *Worker) process(workerID string, job *Job) {
+	domain := job.Domain
+	if domain == "" {
+		joblog.Errorf("%s: missing domain from job request", workerID)
+		return
+	}
+	var inst *instance.Instance
+	if domain != prefixer.GlobalPrefixer.DomainName() {
+		var err error
+		inst, err = instance.Get(job.Domain)
+		if err != nil {
+			joblog.Errorf("Instance not found for %s: %s", job.Domain, err)
+			return
 		}
-		var runResultLabel string
-		var errAck error
-		errRun := t.run()
-		if errRun == ErrAbort {
-			errRun = nil
+		// Do not execute jobs for the copy of an instance on the secondary
+		// stack of a warm standby replication.
+		if inst.Blocked && inst.BlockingReason == instance.BlockedStandby.Code {
+			return
 		}
-		if errRun != nil {
-			parentCtx.Logger().Errorf("error while performing job: %s",
-				errRun.Error())
-			runResultLabel = metrics.WorkerExecResultErrored
-			errAck = job.Nack(errRun.Error())
-		} else {
-			runResultLabel = metrics.WorkerExecResultSuccess
-			errAck = job.Ack()
+		// Do not execute jobs for instances with blocking not signed TOS,
+		// except for:
+		// - mails (and their retries) because the user may needs a mail
+		//   to login and accept the new TOS (2FA, password reset, etc.)
+		// - migrations because the old version may be no longer supported
+		//   when the user will sign the TOS
+		if w.Type != "sendmail" && w.Type != "mailqueue" && w.Type != "migrations" {
+			notSigned, deadline := inst.CheckTOSNotSignedAndDeadline()
+			if notSigned && deadline == instance.TOSBlocked {
+				return
+			}
 		}
+	}
+	parentCtx := NewWorkerContext(workerID, job, inst)
+	if err := job.AckConsumed(); err != nil {
+		parentCtx.Logger().Errorf("error acking consume job: %s",
+			err.Error())
+		return
+	}
+	t := &task{
+		w:    w,
+		ctx:  parentCtx,
+		job:  job,
+		conf: w.defaultedConf(job.Options),
+	}
+	var runResultLabel string
+	var errAck error
+	errRun := t.run()
+	if errRun == ErrAbort {
+		errRun = nil
+	}
+	if errRun != nil {
+		parentCtx.Logger().Errorf("error while performing job: %s",
+			errRun.Error())
+		runResultLabel = metrics.WorkerExecResultErrored
+		errAck = job.Nack(errRun.Error())
+	} else {
+		runResultLabel = metrics.WorkerExecResultSuccess
+		errAck = job.Ack()
+	}
 
-		// Distinguish classic job execution and konnector/account deletion
-		msg := struct {
-			Account        string `json:"account"`
-			AccountRev     string `json:"account_rev"`
-			Konnector      string `json:"konnector"`
-			AccountDeleted bool   `json:"account_deleted"`
-		}{}
-		err := json.Unmarshal(job.Message, &msg)
-
-		if err == nil && w.Type == "konnector" && msg.AccountDeleted {
-			metrics.WorkerKonnectorExecDeleteCounter.WithLabelValues(w.Type, runResultLabel).Inc()
-		} else {
-			metrics.WorkerExecCounter.WithLabelValues(w.Type, runResultLabel).Inc()
-		}
+	// Distinguish classic job execution and konnector/account deletion
+	msg := struct {
+		Account        string `json:"account"`
+		AccountRev     string `json:"account_rev"`
+		Konnector      string `json:"konnector"`
+		AccountDeleted bool   `json:"account_deleted"`
+	}{}
+	err := json.Unmarshal(job.Message, &msg)
+
+	if err == nil && w.Type == "konnector" && msg.AccountDeleted {
+		metrics.WorkerKonnectorExecDeleteCounter.WithLabelValues(w.Type, runResultLabel).Inc()
+	} else {
+		metrics.WorkerExecCounter.WithLabelValues(w.Type, runResultLabel).Inc()
+	}
 
-		if errAck != nil {
-			parentCtx.Logger().Errorf("error while acking job done: %s",
-				errAck.Error())
-		}
+	if errAck != nil {
+		parentCtx.Logger().Errorf("error while acking job done: %s",
+			errAck.Error())
+	}
 
-		// Delete the trigger associated with the job (if any) when we receive a
-		// ErrBadTrigger.
-		if job.TriggerID != "" && globalJobSystem != nil {
-			if _, ok := errRun.(BadTriggerError); ok {
-				_ = globalJobSystem.DeleteTrigger(job, job.TriggerID)
-			}
+	// Delete the trigger associated with the job (if any) when we receive a
+	// ErrBadTrigger.
+	if job.TriggerID != "" && globalJobSystem != nil {
+		if _, ok := errRun.(BadTriggerError); ok {
+			_ = globalJobSystem.DeleteTrigger(job, job.TriggerID)
 		}
 	}
-	joblog.Debugf("%s: worker shut down", workerID)
-	closed <- struct{}{}
 }
 
 func (w *Worker) defaultedConf(opts *JobOptions) *WorkerConfig {
@@ -374,6 +401,10 @@ func (w *Worker) defaultedConf(opts *JobOptions) *WorkerConfig {
 	if opts.Timeout > 0 && opts.Timeout < c.Timeout {
 		c.Timeout = opts.Timeout
 	}
+	// The retry policy of a trigger overrides the defaults of the worker.
+	if opts.Retry != nil {
+		opts.Retry.apply(c)
+	}
 	return c
 }
 
@@ -538,11 +569,19 @@ func (t *task) nextDelay(prevError error) (bool, time.Duration, time.Duration) {
 		// on first execution, execute immediately
 		nextDelay = 0
 	} else {
-		nextDelay = c.RetryDelay << uint(t.execCount-1)
+		nextDelay = c.RetryDelay
+		if c.Backoff != BackoffConstant {
+			nextDelay = c.RetryDelay << uint(t.execCount-1)
+		}
+		if c.MaxRetryDelay > 0 && nextDelay > c.MaxRetryDelay {
+			nextDelay = c.MaxRetryDelay
+		}
 
 		// fuzzDelay number between delay * (1 +/- 0.1)
 		fuzzDelay := int(0.1 * float64(nextDelay))
-		nextDelay += time.Duration((rand.Intn(2*fuzzDelay) - fuzzDelay))
+		if fuzzDelay > 0 {
+			nextDelay += time.Duration((rand.Intn(2*fuzzDelay) - fuzzDelay))
+		}
 	}
 
 	return true, nextDelay, timeout
@@ -0,0 +1,300 @@
+// Package konnectorstats is used to count the successes and failures of the
+// konnectors for each version, and to report them to the registry, so that a
+// version that breaks a konnector can be spotted quickly.
+package konnectorstats
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/registry"
+)
+
+// ReportWorkerType is the type of the worker that sends the statistics to the
+// registry.
+const ReportWorkerType = "konnectors-stats"
+
+// UnknownError is the type used for the errors that don't follow the
+// conventions of the konnectors (like LOGIN_FAILED or VENDOR_DOWN).
+const UnknownError = "UNKNOWN_ERROR"
+
+// errorTypeRegexp matches the types of error of the konnectors. Only the type
+// is kept, as the rest of the message can contain personal data.
+var errorTypeRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// ErrorType returns the anonymized type of a konnector error: the first part
+// of the error message if it follows the conventions, or UnknownError.
+func ErrorType(err error) string {
+	msg := strings.TrimSpace(err.Error())
+	if idx := strings.IndexAny(msg, ". :"); idx >= 0 {
+		msg = msg[:idx]
+	}
+	if !errorTypeRegexp.MatchString(msg) {
+		return UnknownError
+	}
+	return msg
+}
+
+// Counts are the number of successes and failures, by type of error.
+type Counts struct {
+	Success int            `json:"success"`
+	Errors  map[string]int `json:"errors,omitempty"`
+}
+
+// Failures returns the total number of failures.
+func (c *Counts) Failures() int {
+	n := 0
+	for _, v := range c.Errors {
+		n += v
+	}
+	return n
+}
+
+func (c *Counts) clone() Counts {
+	cloned := Counts{Success: c.Success}
+	if c.Errors != nil {
+		cloned.Errors = make(map[string]int, len(c.Errors))
+		for k, v := range c.Errors {
+			cloned.Errors[k] = v
+		}
+	}
+	return cloned
+}
+
+// Stats are the statistics of the executions of a version of a konnector on
+// an instance. The document identifier is slug@version.
+type Stats struct {
+	DocID         string     `json:"_id,omitempty"`
+	DocRev        string     `json:"_rev,omitempty"`
+	Slug          string     `json:"slug"`
+	Version       string     `json:"version"`
+	Counts                   // Since the installation of this version
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	// Reported are the counts that have already been sent to the registry.
+	Reported   Counts     `json:"reported"`
+	ReportedAt *time.Time `json:"reported_at,omitempty"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (s *Stats) ID() string { return s.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (s *Stats) Rev() string { return s.DocRev }
+
+// SetID is used to implement the couchdb.Doc interface
+func (s *Stats) SetID(id string) { s.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (s *Stats) SetRev(rev string) { s.DocRev = rev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (s *Stats) DocType() string { return consts.KonnectorsStats }
+
+// Clone implements couchdb.Doc
+func (s *Stats) Clone() couchdb.Doc {
+	cloned := *s
+	cloned.Counts = s.Counts.clone()
+	cloned.Reported = s.Reported.clone()
+	if s.LastSuccessAt != nil {
+		at := *s.LastSuccessAt
+		cloned.LastSuccessAt = &at
+	}
+	if s.LastErrorAt != nil {
+		at := *s.LastErrorAt
+		cloned.LastErrorAt = &at
+	}
+	if s.ReportedAt != nil {
+		at := *s.ReportedAt
+		cloned.ReportedAt = &at
+	}
+	return &cloned
+}
+
+// SuccessRate returns the ratio of successes, between 0 and 1.
+func (s *Stats) SuccessRate() float64 {
+	total := s.Success + s.Failures()
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Success) / float64(total)
+}
+
+// Pending returns the counts that have not been reported yet.
+func (s *Stats) Pending() Counts {
+	pending := Counts{Success: s.Success - s.Reported.Success}
+	for k, v := range s.Errors {
+		if n := v - s.Reported.Errors[k]; n > 0 {
+			if pending.Errors == nil {
+				pending.Errors = make(map[string]int)
+			}
+			pending.Errors[k] = n
+		}
+	}
+	return pending
+}
+
+func statsID(slug, version string) string {
+	return slug + "@" + version
+}
+
+// Enabled returns true if the statistics are reported to the registry for
+// the instance. It is enabled with the konnectors_stats parameter of the
+// context in the config.
+func Enabled(inst *instance.Instance) bool {
+	settings, ok := inst.SettingsContext()
+	if !ok {
+		return false
+	}
+	enabled, _ := settings["konnectors_stats"].(bool)
+	return enabled
+}
+
+// Record counts an execution of a konnector, with the error if it has
+// failed.
+func Record(inst *instance.Instance, slug, version string, errjob error) error {
+	if slug == "" || version == "" {
+		return nil
+	}
+	id := statsID(slug, version)
+	now := time.Now().UTC()
+	// Several konnectors can finish at the same time, for different
+	// accounts, so we may need to retry on conflicts.
+	var err error
+	leftRetries := 3
+	for {
+		stats := &Stats{}
+		err = couchdb.GetDoc(inst, consts.KonnectorsStats, id, stats)
+		if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+			return err
+		}
+		stats.DocID = id
+		stats.Slug = slug
+		stats.Version = version
+		if errjob == nil {
+			stats.Success++
+			stats.LastSuccessAt = &now
+		} else {
+			errType := ErrorType(errjob)
+			if stats.Errors == nil {
+				stats.Errors = make(map[string]int)
+			}
+			stats.Errors[errType]++
+			stats.LastErrorAt = &now
+			stats.LastError = errType
+		}
+		if stats.DocRev == "" {
+			err = couchdb.CreateNamedDocWithDB(inst, stats)
+		} else {
+			err = couchdb.UpdateDoc(inst, stats)
+		}
+		if !couchdb.IsConflictError(err) || leftRetries == 0 {
+			break
+		}
+		leftRetries--
+	}
+	if err != nil {
+		return err
+	}
+	if Enabled(inst) {
+		EnsureReportTrigger(inst)
+	}
+	return nil
+}
+
+// List returns the statistics of the konnectors, optionally filtered by
+// slug, sorted by slug and version.
+func List(inst *instance.Instance, slug string) ([]*Stats, error) {
+	var all []*Stats
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	if slug != "" {
+		req.StartKey = slug + "@"
+		req.EndKey = slug + "@" + couchdb.MaxString
+	}
+	err := couchdb.GetAllDocs(inst, consts.KonnectorsStats, req, &all)
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Slug != all[j].Slug {
+			return all[i].Slug < all[j].Slug
+		}
+		return all[i].Version < all[j].Version
+	})
+	return all, nil
+}
+
+// Report is the anonymized statistics sent to the registry for a version of
+// a konnector: only the counts since the previous report are sent.
+type Report struct {
+	Counts
+	Since *time.Time `json:"since,omitempty"`
+	Until time.Time  `json:"until"`
+}
+
+// SendReports sends the statistics that have not been reported yet to the
+// registry, if it is enabled for the context of the instance.
+func SendReports(inst *instance.Instance) error {
+	if !Enabled(inst) {
+		return nil
+	}
+	all, err := List(inst, "")
+	if err != nil {
+		return err
+	}
+	registries := inst.Registries()
+	log := inst.Logger().WithNamespace("konnectors-stats")
+	now := time.Now().UTC()
+	for _, stats := range all {
+		pending := stats.Pending()
+		if pending.Success <= 0 && pending.Failures() == 0 {
+			continue
+		}
+		report := Report{Counts: pending, Until: now}
+		report.Since = stats.ReportedAt
+		if err := registry.SendStats(stats.Slug, stats.Version, report, registries); err != nil {
+			log.Infof("Cannot send the stats of %s: %s", stats.ID(), err)
+			continue
+		}
+		stats.Reported = stats.Counts.clone()
+		stats.ReportedAt = &now
+		if err := couchdb.UpdateDoc(inst, stats); err != nil {
+			// The counts will be sent again next time, it is not a big deal
+			log.Warnf("Cannot update the stats of %s: %s", stats.ID(), err)
+		}
+	}
+	return nil
+}
+
+// EnsureReportTrigger creates the daily trigger for the konnectors-stats
+// worker if the instance does not have it yet.
+func EnsureReportTrigger(inst *instance.Instance) {
+	sched := job.System()
+	infos := job.TriggerInfos{
+		Type:       "@cron",
+		WorkerType: ReportWorkerType,
+	}
+	if sched.HasTrigger(inst, infos) {
+		return
+	}
+
+	now := time.Now()
+	hours := (now.Hour() + 12) % 24
+	infos.Arguments = fmt.Sprintf("0 %d %d * * *", now.Minute(), hours)
+	trigger, err := job.NewTrigger(inst, infos, nil)
+	if err != nil {
+		inst.Logger().Errorf("Cannot create konnectors-stats trigger: %s", err)
+		return
+	}
+	if err = sched.AddTrigger(trigger); err != nil {
+		inst.Logger().Errorf("Cannot create konnectors-stats trigger: %s", err)
+	}
+}
@@ -0,0 +1,34 @@
+package konnectorstats
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorType(t *testing.T) {
+	assert.Equal(t, "LOGIN_FAILED", ErrorType(errors.New("LOGIN_FAILED")))
+	assert.Equal(t, "LOGIN_FAILED", ErrorType(errors.New("LOGIN_FAILED.NEEDS_SECRET")))
+	assert.Equal(t, "VENDOR_DOWN", ErrorType(errors.New("VENDOR_DOWN: bank.example.org is down")))
+	assert.Equal(t, UnknownError, ErrorType(errors.New("Cannot login as alice@example.com")))
+	assert.Equal(t, UnknownError, ErrorType(errors.New("")))
+}
+
+func TestPending(t *testing.T) {
+	stats := &Stats{
+		Counts: Counts{
+			Success: 10,
+			Errors:  map[string]int{"LOGIN_FAILED": 3, "VENDOR_DOWN": 1},
+		},
+		Reported: Counts{
+			Success: 7,
+			Errors:  map[string]int{"LOGIN_FAILED": 3},
+		},
+	}
+	pending := stats.Pending()
+	assert.Equal(t, 3, pending.Success)
+	assert.Equal(t, map[string]int{"VENDOR_DOWN": 1}, pending.Errors)
+	assert.Equal(t, 1, pending.Failures())
+	assert.InDelta(t, 10.0/14.0, stats.SuccessRate(), 0.001)
+}
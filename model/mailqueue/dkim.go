@@ -0,0 +1,297 @@
+package mailqueue
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/dkim"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"golang.org/x/net/publicsuffix"
+)
+
+var (
+	// ErrNoPendingKey is used when activating a DKIM key for a domain that
+	// has no pending key.
+	ErrNoPendingKey = errors.New("No pending DKIM key for this domain")
+	// ErrInvalidSelector is used when the DKIM selector is not a valid DNS
+	// label.
+	ErrInvalidSelector = errors.New("Invalid DKIM selector")
+)
+
+var selectorRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// DKIMKey is the DKIM configuration for a mail domain. A new key starts as
+// pending, so that the DNS record can be published before the key is used,
+// and it must then be activated. It allows to rotate the keys without mails
+// with an invalid signature. The identifier of the document is the domain.
+type DKIMKey struct {
+	DocID     string       `json:"_id,omitempty"`
+	DocRev    string       `json:"_rev,omitempty"`
+	Domain    string       `json:"domain"`
+	Active    *DKIMKeyPair `json:"active,omitempty"`
+	Pending   *DKIMKeyPair `json:"pending,omitempty"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// DKIMKeyPair is a private key with its selector.
+type DKIMKeyPair struct {
+	Selector    string     `json:"selector"`
+	Algorithm   string     `json:"algorithm"`
+	PrivateKey  string     `json:"private_key"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (k *DKIMKey) ID() string { return k.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (k *DKIMKey) Rev() string { return k.DocRev }
+
+// SetID is used to implement the couchdb.Doc interface
+func (k *DKIMKey) SetID(id string) { k.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (k *DKIMKey) SetRev(rev string) { k.DocRev = rev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (k *DKIMKey) DocType() string { return consts.MailsDKIM }
+
+// Clone implements couchdb.Doc
+func (k *DKIMKey) Clone() couchdb.Doc {
+	cloned := *k
+	if k.Active != nil {
+		active := *k.Active
+		cloned.Active = &active
+	}
+	if k.Pending != nil {
+		pending := *k.Pending
+		cloned.Pending = &pending
+	}
+	return &cloned
+}
+
+// Signer returns the crypto.Signer for the private key.
+func (p *DKIMKeyPair) Signer() (crypto.Signer, error) {
+	return dkim.ParseKey(p.PrivateKey)
+}
+
+// DNSName returns the name of the TXT record for this key.
+func (p *DKIMKeyPair) DNSName(domain string) string {
+	return fmt.Sprintf("%s._domainkey.%s", p.Selector, domain)
+}
+
+// DNSRecord returns the value of the TXT record for this key.
+func (p *DKIMKeyPair) DNSRecord() (string, error) {
+	signer, err := p.Signer()
+	if err != nil {
+		return "", err
+	}
+	return dkim.DNSRecord(signer)
+}
+
+// GetDKIMKey returns the DKIM configuration for the given domain.
+func GetDKIMKey(domain string) (*DKIMKey, error) {
+	key := &DKIMKey{}
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.MailsDKIM, strings.ToLower(domain), key)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListDKIMKeys returns the DKIM configurations for all the domains.
+func ListDKIMKeys() ([]*DKIMKey, error) {
+	keys := []*DKIMKey{}
+	err := couchdb.ForeachDocs(prefixer.GlobalPrefixer, consts.MailsDKIM, func(_ string, data json.RawMessage) error {
+		key := &DKIMKey{}
+		if err := json.Unmarshal(data, key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GenerateDKIMKey generates a new pending key for the domain. If no selector
+// is given, one is made from the current date.
+func GenerateDKIMKey(domain, algorithm, selector string) (*DKIMKey, error) {
+	domain = strings.ToLower(domain)
+	if algorithm == "" {
+		algorithm = dkim.AlgorithmRSA
+	}
+	key, err := GetDKIMKey(domain)
+	if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	if key == nil {
+		key = &DKIMKey{DocID: domain, Domain: domain}
+	}
+
+	if selector == "" {
+		selector = "cozy" + time.Now().UTC().Format("20060102")
+		if key.Active != nil && key.Active.Selector == selector {
+			selector += "b"
+		}
+	} else if !selectorRegexp.MatchString(selector) {
+		return nil, ErrInvalidSelector
+	}
+	encoded, err := dkim.GenerateKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	key.Pending = &DKIMKeyPair{
+		Selector:   selector,
+		Algorithm:  algorithm,
+		PrivateKey: encoded,
+		CreatedAt:  now,
+	}
+	key.UpdatedAt = now
+	if key.DocRev == "" {
+		err = couchdb.CreateNamedDocWithDB(prefixer.GlobalPrefixer, key)
+	} else {
+		err = couchdb.UpdateDoc(prefixer.GlobalPrefixer, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ActivateDKIMKey replaces the active key of the domain by the pending key.
+func ActivateDKIMKey(domain string) (*DKIMKey, error) {
+	key, err := GetDKIMKey(domain)
+	if err != nil {
+		return nil, err
+	}
+	if key.Pending == nil {
+		return nil, ErrNoPendingKey
+	}
+	now := time.Now().UTC()
+	key.Active = key.Pending
+	key.Active.ActivatedAt = &now
+	key.Pending = nil
+	key.UpdatedAt = now
+	if err := couchdb.UpdateDoc(prefixer.GlobalPrefixer, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeleteDKIMKey removes the DKIM configuration of the domain: the mails won't
+// be signed anymore.
+func DeleteDKIMKey(domain string) error {
+	key, err := GetDKIMKey(domain)
+	if err != nil {
+		return err
+	}
+	return couchdb.DeleteDoc(prefixer.GlobalPrefixer, key)
+}
+
+// findDKIMKey looks for an active key for the domain, or else for one of its
+// parent domains (up to the registrable domain), as DKIM allows a relaxed
+// alignment between the From domain and the signing domain.
+func findDKIMKey(domain string) *DKIMKey {
+	domain = strings.ToLower(domain)
+	org, err := publicsuffix.EffectiveTLDPlusOne(domain)
+	if err != nil {
+		org = domain
+	}
+	for {
+		key, err := GetDKIMKey(domain)
+		if err == nil && key.Active != nil {
+			return key
+		}
+		if domain == org {
+			return nil
+		}
+		i := strings.IndexByte(domain, '.')
+		if i < 0 {
+			return nil
+		}
+		domain = domain[i+1:]
+	}
+}
+
+// signDKIM adds a DKIM signature to the raw message if a key is configured
+// for the domain of the sender. The message is returned without signature
+// if there is no key or if the signature fails.
+func signDKIM(from string, raw []byte) ([]byte, error) {
+	domain := addressDomain(from)
+	if domain == "" {
+		return raw, nil
+	}
+	key := findDKIMKey(domain)
+	if key == nil {
+		return raw, nil
+	}
+	signer, err := key.Active.Signer()
+	if err != nil {
+		return raw, err
+	}
+	signed, err := dkim.Sign(raw, &dkim.Options{
+		Domain:   key.Domain,
+		Selector: key.Active.Selector,
+		Signer:   signer,
+	})
+	if err != nil {
+		return raw, err
+	}
+	return signed, nil
+}
+
+// ReturnPath returns the address to use as the envelope sender for the
+// instances of the given context, or an empty string to use the From
+// address.
+func ReturnPath(contextName string) string {
+	cfgPerContext := config.GetConfig().MailPerContext
+	if ctxConfig, ok := cfgPerContext[contextName].(map[string]interface{}); ok {
+		if rp, ok := ctxConfig["return_path"].(string); ok && rp != "" {
+			return rp
+		}
+	}
+	return config.GetConfig().ReturnPath
+}
+
+// aligned returns true if the two addresses have the same registrable
+// domain, as required by DMARC for the relaxed alignment.
+func aligned(a, b string) bool {
+	da, db := addressDomain(a), addressDomain(b)
+	if da == "" || db == "" {
+		return false
+	}
+	oa, err := publicsuffix.EffectiveTLDPlusOne(da)
+	if err != nil {
+		oa = da
+	}
+	ob, err := publicsuffix.EffectiveTLDPlusOne(db)
+	if err != nil {
+		ob = db
+	}
+	return oa == ob
+}
+
+func addressDomain(addr string) string {
+	if parsed, err := mail.ParseAddress(addr); err == nil {
+		addr = parsed.Address
+	}
+	i := strings.LastIndexByte(addr, '@')
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(addr[i+1:])
+}
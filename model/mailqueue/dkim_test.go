@@ -0,0 +1,21 @@
+package mailqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAligned(t *testing.T) {
+	assert.True(t, aligned("bounces@cozy.example", "Cozy <noreply@alice.cozy.example>"))
+	assert.True(t, aligned("bounces@mail.example.co.uk", "noreply@example.co.uk"))
+	assert.False(t, aligned("bounces@other.example", "noreply@cozy.example"))
+	assert.False(t, aligned("bounces@a.co.uk", "noreply@b.co.uk"))
+	assert.False(t, aligned("invalid", "noreply@cozy.example"))
+}
+
+func TestAddressDomain(t *testing.T) {
+	assert.Equal(t, "cozy.example", addressDomain("noreply@Cozy.Example"))
+	assert.Equal(t, "cozy.example", addressDomain(`"My Cozy" <noreply@cozy.example>`))
+	assert.Empty(t, addressDomain("noreply"))
+}
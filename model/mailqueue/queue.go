@@ -0,0 +1,420 @@
+// Package mailqueue is a persistent queue for the outgoing mails. The mails
+// are rendered by the sendmail worker, saved in the database of the instance,
+// and then delivered by SMTP. A mail that cannot be delivered is retried
+// later, with an exponential backoff, and it is kept as a dead letter when it
+// has failed too many times. The bounces and complaints reported by the mail
+// providers are used to fill a suppression list per context.
+package mailqueue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/egress"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/gomail"
+)
+
+// WorkerType is the type of the worker used for the retries.
+const WorkerType = "mailqueue"
+
+// The states of a message in the queue.
+const (
+	// StateQueued is used for a message waiting to be delivered.
+	StateQueued = "queued"
+	// StateSent is used for a message accepted by the SMTP server.
+	StateSent = "sent"
+	// StateDead is used for a message that has failed too many times, or
+	// with a permanent error. It can be retried manually.
+	StateDead = "dead"
+	// StateSuppressed is used for a message where all the recipients are in
+	// the suppression list.
+	StateSuppressed = "suppressed"
+	// StateBounced is used for a message that has been sent, but that the
+	// mail provider has reported as bounced.
+	StateBounced = "bounced"
+	// StateComplained is used for a message that has been sent, but that a
+	// recipient has reported as spam.
+	StateComplained = "complained"
+)
+
+var (
+	// ErrAllSuppressed is used when all the recipients of a message are in
+	// the suppression list.
+	ErrAllSuppressed = errors.New("All the recipients are in the suppression list")
+	// ErrNotDead is used when trying to retry a message that is not a dead
+	// letter.
+	ErrNotDead = errors.New("The message is not a dead letter")
+	// ErrInvalidMessageID is used when a message-id cannot be associated to a
+	// message of the queue.
+	ErrInvalidMessageID = errors.New("Invalid message-id")
+)
+
+// Message is a mail in the queue. Its raw content is kept until the mail has
+// been delivered.
+type Message struct {
+	DocID         string     `json:"_id,omitempty"`
+	DocRev        string     `json:"_rev,omitempty"`
+	JobID         string     `json:"job_id,omitempty"`
+	From          string     `json:"from"`
+	To            []string   `json:"to"`
+	Suppressed    []string   `json:"suppressed,omitempty"`
+	Raw           []byte     `json:"raw,omitempty"`
+	State         string     `json:"state"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (m *Message) ID() string { return m.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (m *Message) Rev() string { return m.DocRev }
+
+// SetID is used to implement the couchdb.Doc interface
+func (m *Message) SetID(id string) { m.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (m *Message) SetRev(rev string) { m.DocRev = rev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (m *Message) DocType() string { return consts.MailsQueue }
+
+// Clone implements couchdb.Doc
+func (m *Message) Clone() couchdb.Doc {
+	cloned := *m
+	cloned.To = make([]string, len(m.To))
+	copy(cloned.To, m.To)
+	if m.Suppressed != nil {
+		cloned.Suppressed = make([]string, len(m.Suppressed))
+		copy(cloned.Suppressed, m.Suppressed)
+	}
+	if m.Raw != nil {
+		cloned.Raw = make([]byte, len(m.Raw))
+		copy(cloned.Raw, m.Raw)
+	}
+	if m.NextAttemptAt != nil {
+		at := *m.NextAttemptAt
+		cloned.NextAttemptAt = &at
+	}
+	if m.SentAt != nil {
+		at := *m.SentAt
+		cloned.SentAt = &at
+	}
+	return &cloned
+}
+
+// MessageID returns the value of the Message-ID header for this message. The
+// domain of the instance is used on the right side, which allows to find the
+// message when a bounce is reported by the mail provider.
+func (m *Message) MessageID(inst *instance.Instance) string {
+	return fmt.Sprintf("<%s@%s>", m.DocID, utils.StripPort(inst.Domain))
+}
+
+// Enqueue saves the mail in the queue. The recipients in the suppression list
+// of the context of the instance are removed, and ErrAllSuppressed is
+// returned if there is no recipient left.
+func Enqueue(inst *instance.Instance, jobID string, email *gomail.Message) (*Message, error) {
+	from, to, err := envelope(email)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	msg := &Message{
+		DocID:     crypto.GenerateRandomString(32),
+		JobID:     jobID,
+		From:      from,
+		State:     StateQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if rp := ReturnPath(inst.ContextName); rp != "" {
+		if aligned(rp, from) {
+			msg.From = rp
+		} else {
+			inst.Logger().WithNamespace("mailqueue").
+				Warnf("The return-path %s is not aligned with %s", rp, from)
+		}
+	}
+	for _, addr := range to {
+		if IsSuppressed(inst.ContextName, addr) {
+			msg.Suppressed = append(msg.Suppressed, addr)
+		} else {
+			msg.To = append(msg.To, addr)
+		}
+	}
+
+	if len(msg.To) == 0 {
+		msg.State = StateSuppressed
+		msg.To = []string{}
+		if err := couchdb.CreateNamedDocWithDB(inst, msg); err != nil {
+			return nil, err
+		}
+		return msg, ErrAllSuppressed
+	}
+
+	email.SetHeader("Message-ID", msg.MessageID(inst))
+	var buf bytes.Buffer
+	if _, err := email.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	msg.Raw, err = signDKIM(from, buf.Bytes())
+	if err != nil {
+		inst.Logger().WithNamespace("mailqueue").
+			Errorf("Cannot sign the message with DKIM: %s", err)
+	}
+	if err := couchdb.CreateNamedDocWithDB(inst, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Get returns the message with the given identifier from the queue.
+func Get(inst *instance.Instance, id string) (*Message, error) {
+	msg := &Message{}
+	if err := couchdb.GetDoc(inst, consts.MailsQueue, id, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ListDeadLetters returns the messages of the instance that have failed too
+// many times.
+func ListDeadLetters(inst *instance.Instance) ([]*Message, error) {
+	var msgs []*Message
+	err := couchdb.ForeachDocs(inst, consts.MailsQueue, func(_ string, data json.RawMessage) error {
+		msg := &Message{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		if msg.State == StateDead {
+			msgs = append(msgs, msg)
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// NewDialer returns a dialer for the SMTP server, that trusts the CA of the
+// egress proxy of the context for the TLS connections. Note that the SMTP
+// connections are not tunneled through the egress proxy.
+func NewDialer(contextName string, opts *gomail.DialerOptions) *gomail.Dialer {
+	tlsConfig, err := egress.TLSConfig(contextName)
+	if err != nil {
+		logger.WithNamespace("mailqueue").
+			Warnf("Cannot load the CA of the egress proxy: %s", err)
+	}
+	if tlsConfig == nil {
+		return gomail.NewDialer(opts)
+	}
+	tlsConfig.ServerName = opts.Host
+	tlsConfig.InsecureSkipVerify = opts.SkipCertificateValidation
+	return gomail.NewDialerWithTLSConfig(opts, tlsConfig)
+}
+
+// Deliver sends the message to the SMTP server. When it fails, the message is
+// scheduled for a retry, or moved to the dead letters if the error is
+// permanent or if there were too many attempts. The returned error is the
+// error of the SMTP server, and the message is updated in CouchDB in all
+// cases.
+func Deliver(ctx context.Context, inst *instance.Instance, msg *Message) error {
+	dialerOptions := InstanceDialerOptions(inst)
+	if dialerOptions.Host == "-" {
+		return markSent(inst, msg)
+	}
+
+	dialer := NewDialer(inst.ContextName, dialerOptions)
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.SetDeadline(deadline)
+	}
+	err := send(dialer, msg)
+	if err == nil {
+		return markSent(inst, msg)
+	}
+
+	cfg := config.GetConfig().MailQueue
+	msg.Attempts++
+	msg.LastError = err.Error()
+	msg.UpdatedAt = time.Now().UTC()
+	if isPermanent(err) || msg.Attempts >= cfg.MaxAttempts {
+		msg.State = StateDead
+		msg.NextAttemptAt = nil
+		if uerr := couchdb.UpdateDoc(inst, msg); uerr != nil {
+			return uerr
+		}
+		inst.Logger().WithNamespace("mailqueue").
+			Warnf("Message %s moved to the dead letters: %s", msg.DocID, err)
+		return err
+	}
+
+	delay := cfg.RetryDelay << uint(msg.Attempts-1)
+	next := msg.UpdatedAt.Add(delay)
+	msg.NextAttemptAt = &next
+	if uerr := couchdb.UpdateDoc(inst, msg); uerr != nil {
+		return uerr
+	}
+	if serr := scheduleRetry(inst, msg); serr != nil {
+		return serr
+	}
+	return err
+}
+
+// Process is called by the mailqueue worker to retry the delivery of a
+// message. The failures are reported on the sendmail job that has put the
+// message in the queue.
+func Process(ctx context.Context, inst *instance.Instance, id string) error {
+	msg, err := Get(inst, id)
+	if couchdb.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if msg.State != StateQueued {
+		return nil
+	}
+	if err := Deliver(ctx, inst, msg); err != nil {
+		if msg.State != StateDead {
+			inst.Logger().WithNamespace("mailqueue").
+				Infof("Message %s will be retried: %s", msg.DocID, err)
+			return nil
+		}
+		failJob(inst, msg, "mail delivery has failed: "+err.Error())
+	}
+	return nil
+}
+
+// Retry puts a dead letter back in the queue, and delivers it.
+func Retry(ctx context.Context, inst *instance.Instance, msg *Message) error {
+	if msg.State != StateDead || len(msg.Raw) == 0 {
+		return ErrNotDead
+	}
+	msg.State = StateQueued
+	msg.Attempts = 0
+	msg.NextAttemptAt = nil
+	return Deliver(ctx, inst, msg)
+}
+
+// DialerOptions returns the options for connecting to the SMTP server for the
+// given context.
+func DialerOptions(contextName string) *gomail.DialerOptions {
+	cfgPerContext := config.GetConfig().MailPerContext
+	if ctxConfig, ok := cfgPerContext[contextName].(map[string]interface{}); ok {
+		if host, ok := ctxConfig["host"].(string); ok && host != "" {
+			port, _ := ctxConfig["port"].(int)
+			username, _ := ctxConfig["username"].(string)
+			password, _ := ctxConfig["password"].(string)
+			useSSL, _ := ctxConfig["use_ssl"].(bool)
+			disableTLS, _ := ctxConfig["disable_tls"].(bool)
+			skipCertValid, _ := ctxConfig["skip_certificate_validation"].(bool)
+			localName, _ := ctxConfig["local_name"].(string)
+			return &gomail.DialerOptions{
+				Host:                      host,
+				Port:                      port,
+				Username:                  username,
+				Password:                  password,
+				NativeTLS:                 useSSL,
+				DisableTLS:                disableTLS,
+				SkipCertificateValidation: skipCertValid,
+				LocalName:                 localName,
+			}
+		}
+	}
+	return config.GetConfig().Mail
+}
+
+func send(dialer *gomail.Dialer, msg *Message) error {
+	sender, err := dialer.Dial()
+	if err != nil {
+		return err
+	}
+	err = sender.Send(msg.From, msg.To, bytes.NewReader(msg.Raw))
+	if cerr := sender.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func markSent(inst *instance.Instance, msg *Message) error {
+	now := time.Now().UTC()
+	msg.State = StateSent
+	msg.Raw = nil
+	msg.NextAttemptAt = nil
+	msg.SentAt = &now
+	msg.UpdatedAt = now
+	return couchdb.UpdateDoc(inst, msg)
+}
+
+// isPermanent returns true if the SMTP server has rejected the message with a
+// 5xx code: retrying it will give the same error.
+func isPermanent(err error) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code >= 500
+}
+
+func scheduleRetry(inst *instance.Instance, msg *Message) error {
+	jobMsg, err := job.NewMessage(map[string]string{"message_id": msg.DocID})
+	if err != nil {
+		return err
+	}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: WorkerType,
+		Arguments:  msg.NextAttemptAt.Format(time.RFC3339),
+	}, jobMsg)
+	if err != nil {
+		return err
+	}
+	return job.System().AddTrigger(t)
+}
+
+// failJob reports the delivery failure on the sendmail job that has put the
+// message in the queue.
+func failJob(inst *instance.Instance, msg *Message, reason string) {
+	if msg.JobID == "" {
+		return
+	}
+	j, err := job.Get(inst, msg.JobID)
+	if err != nil {
+		return
+	}
+	if err := j.Nack(reason); err != nil {
+		inst.Logger().WithNamespace("mailqueue").
+			Infof("Cannot update the job %s: %s", msg.JobID, err)
+	}
+}
+
+// envelope returns the sender and the recipients of a mail, as used by the
+// SMTP protocol.
+func envelope(email *gomail.Message) (from string, to []string, err error) {
+	err = gomail.Send(gomail.SendFunc(func(f string, t []string, _ io.WriterTo) error {
+		from, to = f, t
+		return nil
+	}), email)
+	return
+}
+
+func normalizeAddress(addr string) string {
+	return strings.ToLower(strings.TrimSpace(addr))
+}
@@ -0,0 +1,238 @@
+package mailqueue
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// The kinds of reports sent by the mail providers.
+const (
+	ReportBounce    = "bounce"
+	ReportComplaint = "complaint"
+)
+
+// ErrInvalidSignature is used when the signature of a webhook is not valid.
+var ErrInvalidSignature = errors.New("Invalid signature")
+
+// Report is a bounce or a complaint reported by a mail provider for a message
+// sent by the stack.
+type Report struct {
+	Kind        string
+	MessageID   string
+	Recipients  []string
+	Permanent   bool
+	Description string
+}
+
+// HandleReport updates the message of the queue for the report, and adds the
+// recipients to the suppression list for a permanent bounce or a complaint.
+// For a bounce, the sendmail job is also marked as errored.
+func HandleReport(r *Report) error {
+	id, domain := splitMessageID(r.MessageID)
+	if id == "" {
+		return ErrInvalidMessageID
+	}
+	inst, err := instance.Get(domain)
+	if err != nil {
+		return ErrInvalidMessageID
+	}
+	msg, err := Get(inst, id)
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return ErrInvalidMessageID
+	}
+	if err != nil {
+		return err
+	}
+
+	log := inst.Logger().WithNamespace("mailqueue")
+	msg.LastError = r.Description
+	msg.UpdatedAt = time.Now().UTC()
+	switch r.Kind {
+	case ReportBounce:
+		if r.Permanent {
+			msg.State = StateBounced
+			for _, rcpt := range r.Recipients {
+				if err := Suppress(inst.ContextName, rcpt, ReasonBounce); err != nil {
+					log.Warnf("Cannot add %s to the suppression list: %s", rcpt, err)
+				}
+			}
+		}
+	case ReportComplaint:
+		msg.State = StateComplained
+		for _, rcpt := range r.Recipients {
+			if err := Suppress(inst.ContextName, rcpt, ReasonComplaint); err != nil {
+				log.Warnf("Cannot add %s to the suppression list: %s", rcpt, err)
+			}
+		}
+	}
+	if err := couchdb.UpdateDoc(inst, msg); err != nil {
+		return err
+	}
+	if r.Kind == ReportBounce && r.Permanent {
+		failJob(inst, msg, "mail has bounced: "+r.Description)
+	}
+	log.Infof("Message %s: %s reported for %v", msg.DocID, r.Kind, r.Recipients)
+	return nil
+}
+
+func splitMessageID(messageID string) (string, string) {
+	messageID = strings.TrimSpace(messageID)
+	messageID = strings.TrimSuffix(strings.TrimPrefix(messageID, "<"), ">")
+	parts := strings.SplitN(messageID, "@", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// SNSConfirmation is returned by ParseSES when the request is the
+// confirmation of the subscription to an Amazon SNS topic.
+type SNSConfirmation struct {
+	SubscribeURL string
+}
+
+// ParseSES parses a notification from Amazon SES, sent via SNS. It returns
+// nil for a notification that is neither a bounce nor a complaint (like a
+// delivery). See https://docs.aws.amazon.com/ses/latest/dg/notification-contents.html
+func ParseSES(body []byte) (*Report, *SNSConfirmation, error) {
+	var envelope struct {
+		Type         string `json:"Type"`
+		Message      string `json:"Message"`
+		SubscribeURL string `json:"SubscribeURL"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, nil, err
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		u, err := url.Parse(envelope.SubscribeURL)
+		if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+			return nil, nil, errors.New("Invalid SubscribeURL")
+		}
+		return nil, &SNSConfirmation{SubscribeURL: u.String()}, nil
+	case "Notification":
+	default:
+		return nil, nil, nil
+	}
+
+	var notif struct {
+		NotificationType string `json:"notificationType"`
+		Bounce           struct {
+			BounceType        string `json:"bounceType"`
+			BounceSubType     string `json:"bounceSubType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			ComplaintFeedbackType string `json:"complaintFeedbackType"`
+			ComplainedRecipients  []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+		Mail struct {
+			CommonHeaders struct {
+				MessageID string `json:"messageId"`
+			} `json:"commonHeaders"`
+		} `json:"mail"`
+	}
+	if err := json.Unmarshal([]byte(envelope.Message), &notif); err != nil {
+		return nil, nil, err
+	}
+
+	r := &Report{MessageID: notif.Mail.CommonHeaders.MessageID}
+	switch notif.NotificationType {
+	case "Bounce":
+		r.Kind = ReportBounce
+		r.Permanent = notif.Bounce.BounceType == "Permanent"
+		r.Description = notif.Bounce.BounceType + " " + notif.Bounce.BounceSubType
+		for _, rcpt := range notif.Bounce.BouncedRecipients {
+			r.Recipients = append(r.Recipients, rcpt.EmailAddress)
+			if rcpt.DiagnosticCode != "" {
+				r.Description = rcpt.DiagnosticCode
+			}
+		}
+	case "Complaint":
+		r.Kind = ReportComplaint
+		r.Description = notif.Complaint.ComplaintFeedbackType
+		for _, rcpt := range notif.Complaint.ComplainedRecipients {
+			r.Recipients = append(r.Recipients, rcpt.EmailAddress)
+		}
+	default:
+		return nil, nil, nil
+	}
+	return r, nil, nil
+}
+
+// ParseMailgun parses a webhook from Mailgun, and checks its signature with
+// the signing key. It returns nil for an event that is neither a failure nor
+// a complaint. See https://documentation.mailgun.com/en/latest/user_manual.html#webhooks
+func ParseMailgun(body []byte, signingKey string) (*Report, error) {
+	var payload struct {
+		Signature struct {
+			Timestamp string `json:"timestamp"`
+			Token     string `json:"token"`
+			Signature string `json:"signature"`
+		} `json:"signature"`
+		EventData struct {
+			Event          string `json:"event"`
+			Severity       string `json:"severity"`
+			Reason         string `json:"reason"`
+			Recipient      string `json:"recipient"`
+			DeliveryStatus struct {
+				Description string `json:"description"`
+				Message     string `json:"message"`
+			} `json:"delivery-status"`
+			Message struct {
+				Headers struct {
+					MessageID string `json:"message-id"`
+				} `json:"headers"`
+			} `json:"message"`
+		} `json:"event-data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	sig := payload.Signature
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(sig.Timestamp + sig.Token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if signingKey == "" || !hmac.Equal([]byte(expected), []byte(sig.Signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	data := payload.EventData
+	r := &Report{
+		MessageID:  data.Message.Headers.MessageID,
+		Recipients: []string{data.Recipient},
+	}
+	switch data.Event {
+	case "failed":
+		r.Kind = ReportBounce
+		r.Permanent = data.Severity == "permanent"
+		r.Description = data.DeliveryStatus.Message
+		if r.Description == "" {
+			r.Description = data.DeliveryStatus.Description
+		}
+		if r.Description == "" {
+			r.Description = data.Reason
+		}
+	case "complained":
+		r.Kind = ReportComplaint
+		r.Description = "complaint"
+	default:
+		return nil, nil
+	}
+	return r, nil
+}
@@ -0,0 +1,139 @@
+package mailqueue
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMessageID(t *testing.T) {
+	id, domain := splitMessageID("<abc123@alice.cozy.example>")
+	assert.Equal(t, "abc123", id)
+	assert.Equal(t, "alice.cozy.example", domain)
+
+	id, domain = splitMessageID("abc123@alice.cozy.example")
+	assert.Equal(t, "abc123", id)
+	assert.Equal(t, "alice.cozy.example", domain)
+
+	id, _ = splitMessageID("<no-domain>")
+	assert.Empty(t, id)
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert.True(t, isPermanent(&textproto.Error{Code: 550, Msg: "No such user"}))
+	assert.True(t, isPermanent(fmt.Errorf("wrapped: %w", &textproto.Error{Code: 554})))
+	assert.False(t, isPermanent(&textproto.Error{Code: 451, Msg: "Try again later"}))
+	assert.False(t, isPermanent(errors.New("dial tcp: connection refused")))
+}
+
+func TestParseSES(t *testing.T) {
+	bounce := `{"notificationType":"Bounce",
+	  "bounce":{"bounceType":"Permanent","bounceSubType":"General",
+	    "bouncedRecipients":[{"emailAddress":"bob@example.org","diagnosticCode":"smtp; 550 5.1.1 user unknown"}]},
+	  "mail":{"commonHeaders":{"messageId":"<abc@alice.cozy.example>"}}}`
+	body, _ := json.Marshal(map[string]string{"Type": "Notification", "Message": bounce})
+	r, confirm, err := ParseSES(body)
+	require.NoError(t, err)
+	assert.Nil(t, confirm)
+	require.NotNil(t, r)
+	assert.Equal(t, ReportBounce, r.Kind)
+	assert.True(t, r.Permanent)
+	assert.Equal(t, "<abc@alice.cozy.example>", r.MessageID)
+	assert.Equal(t, []string{"bob@example.org"}, r.Recipients)
+	assert.Equal(t, "smtp; 550 5.1.1 user unknown", r.Description)
+
+	complaint := `{"notificationType":"Complaint",
+	  "complaint":{"complaintFeedbackType":"abuse","complainedRecipients":[{"emailAddress":"bob@example.org"}]},
+	  "mail":{"commonHeaders":{"messageId":"<abc@alice.cozy.example>"}}}`
+	body, _ = json.Marshal(map[string]string{"Type": "Notification", "Message": complaint})
+	r, _, err = ParseSES(body)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, ReportComplaint, r.Kind)
+
+	delivery := `{"notificationType":"Delivery","mail":{}}`
+	body, _ = json.Marshal(map[string]string{"Type": "Notification", "Message": delivery})
+	r, _, err = ParseSES(body)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	body, _ = json.Marshal(map[string]string{
+		"Type":         "SubscriptionConfirmation",
+		"SubscribeURL": "https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription&Token=foo",
+	})
+	_, confirm, err = ParseSES(body)
+	require.NoError(t, err)
+	require.NotNil(t, confirm)
+
+	body, _ = json.Marshal(map[string]string{
+		"Type":         "SubscriptionConfirmation",
+		"SubscribeURL": "https://evil.example/amazonaws.com",
+	})
+	_, _, err = ParseSES(body)
+	assert.Error(t, err)
+}
+
+func TestParseMailgun(t *testing.T) {
+	key := "signing-key"
+	sign := func(timestamp, token string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(timestamp + token))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	payload := func(signature, event, severity string) []byte {
+		body, _ := json.Marshal(map[string]interface{}{
+			"signature": map[string]string{
+				"timestamp": "1529006854",
+				"token":     "a8ce0edb2dd8301dee6c2405235584e45aa91d1e9f979f3de0",
+				"signature": signature,
+			},
+			"event-data": map[string]interface{}{
+				"event":     event,
+				"severity":  severity,
+				"recipient": "bob@example.org",
+				"delivery-status": map[string]string{
+					"message": "No such mailbox",
+				},
+				"message": map[string]interface{}{
+					"headers": map[string]string{"message-id": "abc@alice.cozy.example"},
+				},
+			},
+		})
+		return body
+	}
+	valid := sign("1529006854", "a8ce0edb2dd8301dee6c2405235584e45aa91d1e9f979f3de0")
+
+	r, err := ParseMailgun(payload(valid, "failed", "permanent"), key)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, ReportBounce, r.Kind)
+	assert.True(t, r.Permanent)
+	assert.Equal(t, "abc@alice.cozy.example", r.MessageID)
+	assert.Equal(t, []string{"bob@example.org"}, r.Recipients)
+	assert.Equal(t, "No such mailbox", r.Description)
+
+	r, err = ParseMailgun(payload(valid, "failed", "temporary"), key)
+	require.NoError(t, err)
+	assert.False(t, r.Permanent)
+
+	r, err = ParseMailgun(payload(valid, "complained", ""), key)
+	require.NoError(t, err)
+	assert.Equal(t, ReportComplaint, r.Kind)
+
+	r, err = ParseMailgun(payload(valid, "delivered", ""), key)
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	_, err = ParseMailgun(payload("bad", "failed", "permanent"), key)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = ParseMailgun(payload(valid, "failed", "permanent"), "")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
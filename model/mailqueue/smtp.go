@@ -0,0 +1,230 @@
+package mailqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/account"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/gomail"
+)
+
+var (
+	// ErrSMTPMissingHost is used when the host of the SMTP server is missing.
+	ErrSMTPMissingHost = errors.New("The host of the SMTP server is missing")
+	// ErrSMTPInvalidPort is used for a port that is not between 1 and 65535.
+	ErrSMTPInvalidPort = errors.New("Invalid port for the SMTP server")
+	// ErrSMTPInvalidFrom is used when the from address cannot be parsed.
+	ErrSMTPInvalidFrom = errors.New("Invalid from address")
+	// ErrSMTPNotConfigured is used when the instance has no SMTP server.
+	ErrSMTPNotConfigured = errors.New("No SMTP server is configured for this instance")
+)
+
+// SMTPSettings is the outgoing mail server configured by the user for their
+// instance, to send the mails from their own domain instead of the relay of
+// the context. The password is encrypted with the vault key, like the
+// credentials of the accounts.
+type SMTPSettings struct {
+	DocID             string    `json:"_id,omitempty"`
+	DocRev            string    `json:"_rev,omitempty"`
+	Host              string    `json:"host"`
+	Port              int       `json:"port,omitempty"`
+	Username          string    `json:"username,omitempty"`
+	EncryptedPassword string    `json:"password_encrypted,omitempty"`
+	UseSSL            bool      `json:"use_ssl,omitempty"`
+	DisableTLS        bool      `json:"disable_tls,omitempty"`
+	FromAddress       string    `json:"from_address,omitempty"`
+	FromName          string    `json:"from_name,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ID implements the couchdb.Doc interface
+func (s *SMTPSettings) ID() string { return s.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (s *SMTPSettings) Rev() string { return s.DocRev }
+
+// SetID implements the couchdb.Doc interface
+func (s *SMTPSettings) SetID(id string) { s.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (s *SMTPSettings) SetRev(rev string) { s.DocRev = rev }
+
+// DocType implements the couchdb.Doc interface
+func (s *SMTPSettings) DocType() string { return consts.Settings }
+
+// Clone implements the couchdb.Doc interface
+func (s *SMTPSettings) Clone() couchdb.Doc {
+	cloned := *s
+	return &cloned
+}
+
+// Validate checks the settings, and fills the default port.
+func (s *SMTPSettings) Validate() error {
+	s.Host = strings.TrimSpace(s.Host)
+	if s.Host == "" {
+		return ErrSMTPMissingHost
+	}
+	if s.Port == 0 {
+		if s.UseSSL {
+			s.Port = 465
+		} else {
+			s.Port = 587
+		}
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		return ErrSMTPInvalidPort
+	}
+	s.FromAddress = strings.TrimSpace(s.FromAddress)
+	if s.FromAddress != "" {
+		addr, err := mail.ParseAddress(s.FromAddress)
+		if err != nil || addr.Name != "" {
+			return ErrSMTPInvalidFrom
+		}
+	}
+	return nil
+}
+
+// SetPassword encrypts the password and keeps it in the settings. An empty
+// password removes it.
+func (s *SMTPSettings) SetPassword(password string) error {
+	if password == "" {
+		s.EncryptedPassword = ""
+		return nil
+	}
+	encrypted, err := account.EncryptCredentialsData(password)
+	if err != nil {
+		return err
+	}
+	s.EncryptedPassword = encrypted
+	return nil
+}
+
+// Password returns the password in clear.
+func (s *SMTPSettings) Password() (string, error) {
+	if s.EncryptedPassword == "" {
+		return "", nil
+	}
+	decrypted, err := account.DecryptCredentialsData(s.EncryptedPassword)
+	if err != nil {
+		return "", err
+	}
+	password, ok := decrypted.(string)
+	if !ok {
+		return "", account.ErrBadCredentials
+	}
+	return password, nil
+}
+
+// DialerOptions returns the options for connecting to the SMTP server.
+func (s *SMTPSettings) DialerOptions() (*gomail.DialerOptions, error) {
+	password, err := s.Password()
+	if err != nil {
+		return nil, err
+	}
+	return &gomail.DialerOptions{
+		Host:       s.Host,
+		Port:       s.Port,
+		Username:   s.Username,
+		Password:   password,
+		NativeTLS:  s.UseSSL,
+		DisableTLS: s.DisableTLS,
+	}, nil
+}
+
+// GetSMTPSettings returns the SMTP server configured for the instance, or nil
+// if there is none.
+func GetSMTPSettings(inst *instance.Instance) (*SMTPSettings, error) {
+	s := &SMTPSettings{}
+	err := couchdb.GetDoc(inst, consts.Settings, consts.SMTPSettingsID, s)
+	if couchdb.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveSMTPSettings persists the SMTP server for the instance.
+func SaveSMTPSettings(inst *instance.Instance, s *SMTPSettings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	s.DocID = consts.SMTPSettingsID
+	s.UpdatedAt = time.Now().UTC()
+	if s.DocRev == "" {
+		return couchdb.CreateNamedDocWithDB(inst, s)
+	}
+	return couchdb.UpdateDoc(inst, s)
+}
+
+// DeleteSMTPSettings removes the SMTP server of the instance: the mails will
+// be sent again via the relay of the context.
+func DeleteSMTPSettings(inst *instance.Instance) error {
+	s, err := GetSMTPSettings(inst)
+	if err != nil || s == nil {
+		return err
+	}
+	return couchdb.DeleteDoc(inst, s)
+}
+
+// InstanceDialerOptions returns the options for connecting to the SMTP server
+// for the given instance: the server configured by the user if any, or else
+// the server of its context.
+func InstanceDialerOptions(inst *instance.Instance) *gomail.DialerOptions {
+	s, err := GetSMTPSettings(inst)
+	if err != nil {
+		inst.Logger().WithNamespace("mailqueue").
+			Warnf("Cannot load the SMTP settings: %s", err)
+	}
+	if s != nil {
+		opts, err := s.DialerOptions()
+		if err == nil {
+			return opts
+		}
+		inst.Logger().WithNamespace("mailqueue").
+			Warnf("Cannot decrypt the SMTP password: %s", err)
+	}
+	return DialerOptions(inst.ContextName)
+}
+
+// SendSMTPTest sends a test mail to the given address with the SMTP server
+// of the instance, without using the queue, so that the error of the server
+// can be shown to the user.
+func SendSMTPTest(ctx context.Context, inst *instance.Instance, s *SMTPSettings, to string) error {
+	opts, err := s.DialerOptions()
+	if err != nil {
+		return err
+	}
+	from := s.FromAddress
+	if from == "" {
+		from = "noreply@" + utils.StripPort(inst.Domain)
+	}
+	email := gomail.NewMessage()
+	email.SetHeaders(map[string][]string{
+		"From":    {email.FormatAddress(from, s.FromName)},
+		"To":      {to},
+		"Subject": {"Test of the SMTP server"},
+		"X-Cozy":  {inst.Domain},
+	})
+	email.SetDateHeader("Date", time.Now())
+	body := fmt.Sprintf("This mail has been sent by %s via %s to check the SMTP server.\n",
+		inst.Domain, s.Host)
+	email.SetBody("text/plain", body)
+
+	dialer := NewDialer(inst.ContextName, opts)
+	if deadline, ok := ctx.Deadline(); ok {
+		dialer.SetDeadline(deadline)
+	}
+	return dialer.DialAndSend(email)
+}
+
+var _ couchdb.Doc = &SMTPSettings{}
@@ -0,0 +1,31 @@
+package mailqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPSettingsValidate(t *testing.T) {
+	s := &SMTPSettings{Host: " smtp.alice.example "}
+	require.NoError(t, s.Validate())
+	assert.Equal(t, "smtp.alice.example", s.Host)
+	assert.Equal(t, 587, s.Port)
+
+	s = &SMTPSettings{Host: "smtp.alice.example", UseSSL: true}
+	require.NoError(t, s.Validate())
+	assert.Equal(t, 465, s.Port)
+
+	s = &SMTPSettings{}
+	assert.ErrorIs(t, s.Validate(), ErrSMTPMissingHost)
+
+	s = &SMTPSettings{Host: "smtp.alice.example", Port: 70000}
+	assert.ErrorIs(t, s.Validate(), ErrSMTPInvalidPort)
+
+	s = &SMTPSettings{Host: "smtp.alice.example", FromAddress: "Alice <cozy@alice.example>"}
+	assert.ErrorIs(t, s.Validate(), ErrSMTPInvalidFrom)
+
+	s = &SMTPSettings{Host: "smtp.alice.example", FromAddress: "cozy@alice.example"}
+	assert.NoError(t, s.Validate())
+}
@@ -0,0 +1,110 @@
+package mailqueue
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// The reasons for adding an address to the suppression list.
+const (
+	ReasonBounce    = "bounce"
+	ReasonComplaint = "complaint"
+	ReasonManual    = "manual"
+)
+
+// Suppression is an address that must not receive mails anymore for the
+// instances of a context. It is saved in a global database, with the context
+// and the address in its identifier.
+type Suppression struct {
+	DocID       string    `json:"_id,omitempty"`
+	DocRev      string    `json:"_rev,omitempty"`
+	ContextName string    `json:"context"`
+	Email       string    `json:"email"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (s *Suppression) ID() string { return s.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (s *Suppression) Rev() string { return s.DocRev }
+
+// SetID is used to implement the couchdb.Doc interface
+func (s *Suppression) SetID(id string) { s.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (s *Suppression) SetRev(rev string) { s.DocRev = rev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (s *Suppression) DocType() string { return consts.MailsSuppressions }
+
+// Clone implements couchdb.Doc
+func (s *Suppression) Clone() couchdb.Doc {
+	cloned := *s
+	return &cloned
+}
+
+func suppressionID(contextName, email string) string {
+	return contextName + "/" + normalizeAddress(email)
+}
+
+// IsSuppressed returns true if the address is in the suppression list of the
+// context.
+func IsSuppressed(contextName, email string) bool {
+	var doc Suppression
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.MailsSuppressions, suppressionID(contextName, email), &doc)
+	return err == nil
+}
+
+// Suppress adds an address to the suppression list of the context.
+func Suppress(contextName, email, reason string) error {
+	if IsSuppressed(contextName, email) {
+		return nil
+	}
+	doc := &Suppression{
+		DocID:       suppressionID(contextName, email),
+		ContextName: contextName,
+		Email:       normalizeAddress(email),
+		Reason:      reason,
+		CreatedAt:   time.Now().UTC(),
+	}
+	err := couchdb.CreateNamedDocWithDB(prefixer.GlobalPrefixer, doc)
+	if couchdb.IsConflictError(err) {
+		return nil
+	}
+	return err
+}
+
+// Unsuppress removes an address from the suppression list of the context.
+func Unsuppress(contextName, email string) error {
+	doc := &Suppression{}
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.MailsSuppressions, suppressionID(contextName, email), doc)
+	if err != nil {
+		return err
+	}
+	return couchdb.DeleteDoc(prefixer.GlobalPrefixer, doc)
+}
+
+// ListSuppressions returns the suppression list of the context.
+func ListSuppressions(contextName string) ([]*Suppression, error) {
+	docs := []*Suppression{}
+	err := couchdb.ForeachDocs(prefixer.GlobalPrefixer, consts.MailsSuppressions, func(_ string, data json.RawMessage) error {
+		doc := &Suppression{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return err
+		}
+		if doc.ContextName == contextName {
+			docs = append(docs, doc)
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return docs, nil
+}
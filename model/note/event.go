@@ -69,12 +69,19 @@ func (e Event) publish(inst *instance.Instance) {
 	go realtime.GetHub().Publish(inst, realtime.EventUpdate, e, nil)
 }
 
-// PutTelepointer sends the position of a pointer in the realtime hub.
+// PutTelepointer sends the position of a pointer in the realtime hub, with
+// the color of the user. It also records the activity of the user for the
+// presence.
 func PutTelepointer(inst *instance.Instance, t Event) error {
-	if t["sessionID"] == nil || t["sessionID"] == "" {
+	sessionID, _ := t["sessionID"].(string)
+	if sessionID == "" {
 		return ErrMissingSessionID
 	}
 	t["doctype"] = consts.NotesTelepointers
+	t["color"] = ColorFor(sessionID)
+	if err := touchPresence(inst, t.ID(), sessionID, t); err != nil {
+		return err
+	}
 	t.publish(inst)
 	return nil
 }
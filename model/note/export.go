@@ -0,0 +1,466 @@
+package note
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/prosemirror-go/model"
+	"github.com/go-pdf/fpdf"
+)
+
+// The formats that can be used to export a note.
+const (
+	ExportMarkdown = "md"
+	ExportPDF      = "pdf"
+)
+
+// ErrInvalidExportFormat is used when a note is exported to an unknown format.
+var ErrInvalidExportFormat = errors.New("Invalid format for exporting a note")
+
+// Export is the result of the export of a note.
+type Export struct {
+	Filename string
+	Mime     string
+	Content  []byte
+}
+
+// ExportNote renders a note in the given format. For markdown, if the note
+// has some images, the markdown file and the images are put in a zip.
+func ExportNote(inst *instance.Instance, file *vfs.FileDoc, format string) (*Export, error) {
+	if format != ExportMarkdown && format != ExportPDF {
+		return nil, ErrInvalidExportFormat
+	}
+
+	lock := inst.NotesLock()
+	if err := lock.Lock(); err != nil {
+		return nil, err
+	}
+	doc, err := get(inst, file)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	images, err := getImages(inst, file.ID())
+	lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	basename := strings.TrimSuffix(file.DocName, path.Ext(file.DocName))
+	if format == ExportPDF {
+		content, err := doc.Content()
+		if err != nil {
+			return nil, err
+		}
+		r := newPDFRenderer(doc.Title, func(img *Image) (io.ReadCloser, error) {
+			return inst.ThumbsFS().OpenNoteThumb(img.ID(), consts.NoteImageOriginalFormat)
+		}, images)
+		pdf, err := r.Render(content)
+		if err != nil {
+			return nil, err
+		}
+		return &Export{Filename: basename + ".pdf", Mime: "application/pdf", Content: pdf}, nil
+	}
+
+	md, err := doc.Markdown(images)
+	if err != nil {
+		return nil, err
+	}
+	if !hasImages(images) {
+		return &Export{Filename: basename + ".md", Mime: "text/markdown", Content: md}, nil
+	}
+	archive, err := buildMarkdownZip(inst, basename, md, images)
+	if err != nil {
+		return nil, err
+	}
+	return &Export{Filename: basename + ".zip", Mime: "application/zip", Content: archive}, nil
+}
+
+// buildMarkdownZip makes a zip with the markdown and the images of a note. The
+// links to the images are rewritten to use the relative path of the image
+// files.
+func buildMarkdownZip(inst *instance.Instance, basename string, md []byte, images []*Image) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+
+	for _, image := range images {
+		if !image.seen {
+			continue
+		}
+		name := image.ID() + path.Ext(image.Name)
+		src := "](" + image.ID() + ")"
+		dst := "](images/" + url.PathEscape(name) + ")"
+		md = bytes.ReplaceAll(md, []byte(src), []byte(dst))
+
+		th, err := inst.ThumbsFS().OpenNoteThumb(image.ID(), consts.NoteImageOriginalFormat)
+		if err != nil {
+			return nil, err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     basename + "/images/" + name,
+			Method:   zip.Store,
+			Modified: now,
+		})
+		if err == nil {
+			_, err = io.Copy(w, th)
+		}
+		if errc := th.Close(); err == nil && errc != nil {
+			err = errc
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     basename + "/" + basename + ".md",
+		Method:   zip.Deflate,
+		Modified: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(md); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Some dimensions (in mm) and font sizes used for the PDF.
+const (
+	pdfFontSize   = 11
+	pdfLineHeight = 5.5
+	pdfIndent     = 7
+)
+
+var pdfHeadingSizes = map[int]float64{1: 22, 2: 18, 3: 15, 4: 13, 5: 12, 6: 11}
+
+type imageOpener func(img *Image) (io.ReadCloser, error)
+
+// pdfRenderer transforms the prosemirror document of a note to a PDF.
+type pdfRenderer struct {
+	pdf    *fpdf.Fpdf
+	tr     func(string) string
+	title  string
+	open   imageOpener
+	images []*Image
+	size   float64
+}
+
+func newPDFRenderer(title string, open imageOpener, images []*Image) *pdfRenderer {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Cozy", true)
+	return &pdfRenderer{
+		pdf:    pdf,
+		tr:     pdf.UnicodeTranslatorFromDescriptor(""),
+		title:  title,
+		open:   open,
+		images: images,
+		size:   pdfFontSize,
+	}
+}
+
+// Render returns the PDF for the given document.
+func (r *pdfRenderer) Render(doc *model.Node) ([]byte, error) {
+	r.pdf.AddPage()
+	if r.title != "" {
+		r.pdf.SetFont("Helvetica", "B", pdfHeadingSizes[1])
+		r.pdf.MultiCell(0, pdfHeadingSizes[1]/2, r.tr(r.title), "", "L", false)
+		r.pdf.Ln(pdfLineHeight)
+	}
+	r.renderBlocks(doc)
+
+	var buf bytes.Buffer
+	if err := r.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *pdfRenderer) renderBlocks(node *model.Node) {
+	node.ForEach(func(child *model.Node, _ int, _ int) {
+		r.renderBlock(child)
+	})
+}
+
+func (r *pdfRenderer) renderBlock(node *model.Node) {
+	switch node.Type.Name {
+	case "heading":
+		level := 1
+		if l, ok := node.Attrs["level"].(float64); ok {
+			level = int(l)
+		} else if l, ok := node.Attrs["level"].(int); ok {
+			level = l
+		}
+		size, ok := pdfHeadingSizes[level]
+		if !ok {
+			size = pdfFontSize
+		}
+		r.pdf.Ln(pdfLineHeight / 2)
+		r.withSize(size, func() { r.renderInline(node, "B") })
+		r.pdf.Ln(size/2 + pdfLineHeight/2)
+	case "paragraph":
+		r.renderInline(node, "")
+		r.pdf.Ln(pdfLineHeight * 1.5)
+	case "bulletList", "orderedList", "taskList", "decisionList":
+		r.renderList(node)
+	case "blockquote", "panel", "nestedExpand", "expand":
+		r.indent(func() {
+			if title, ok := node.Attrs["title"].(string); ok && title != "" {
+				r.pdf.SetFont("Helvetica", "B", r.size)
+				r.pdf.Write(pdfLineHeight, r.tr(title))
+				r.pdf.Ln(pdfLineHeight * 1.5)
+			}
+			r.renderBlocks(node)
+		})
+	case "codeBlock":
+		r.pdf.SetFont("Courier", "", r.size-1)
+		r.pdf.SetFillColor(240, 240, 240)
+		r.pdf.MultiCell(0, pdfLineHeight, r.tr(node.TextContent()), "", "L", true)
+		r.pdf.Ln(pdfLineHeight)
+	case "rule":
+		y := r.pdf.GetY() + pdfLineHeight/2
+		w, _ := r.pdf.GetPageSize()
+		_, _, right, _ := r.pdf.GetMargins()
+		r.pdf.SetDrawColor(180, 180, 180)
+		r.pdf.Line(r.pdf.GetX(), y, w-right, y)
+		r.pdf.Ln(pdfLineHeight * 1.5)
+	case "table":
+		r.renderTable(node)
+	case "mediaSingle", "mediaGroup":
+		r.renderBlocks(node)
+	case "media":
+		r.renderImage(node)
+	default:
+		if node.IsBlock() && node.ChildCount() > 0 && node.FirstChild().IsBlock() {
+			r.renderBlocks(node)
+		} else {
+			r.renderInline(node, "")
+			r.pdf.Ln(pdfLineHeight * 1.5)
+		}
+	}
+}
+
+func (r *pdfRenderer) renderList(list *model.Node) {
+	r.indent(func() {
+		order := 1
+		if o, ok := list.Attrs["order"].(float64); ok {
+			order = int(o)
+		}
+		list.ForEach(func(item *model.Node, _ int, index int) {
+			var prefix string
+			switch list.Type.Name {
+			case "orderedList":
+				prefix = strconv.Itoa(order+index) + ". "
+			case "taskList":
+				prefix = "[ ] "
+				if item.Attrs["state"] == "DONE" {
+					prefix = "[X] "
+				}
+			case "decisionList":
+				prefix = "> "
+			default:
+				prefix = "- "
+			}
+			r.pdf.SetFont("Helvetica", "", r.size)
+			r.pdf.SetTextColor(0, 0, 0)
+			r.pdf.Write(pdfLineHeight, r.tr(prefix))
+			if item.ChildCount() > 0 && item.FirstChild().IsBlock() {
+				r.renderBlocks(item)
+			} else {
+				r.renderInline(item, "")
+				r.pdf.Ln(pdfLineHeight * 1.5)
+			}
+		})
+	})
+}
+
+func (r *pdfRenderer) renderTable(table *model.Node) {
+	table.ForEach(func(row *model.Node, _ int, _ int) {
+		var cells []string
+		header := false
+		row.ForEach(func(cell *model.Node, _ int, _ int) {
+			if cell.Type.Name == "tableHeader" {
+				header = true
+			}
+			cells = append(cells, strings.TrimSpace(cell.TextContent()))
+		})
+		style := ""
+		if header {
+			style = "B"
+		}
+		r.pdf.SetFont("Helvetica", style, r.size)
+		r.pdf.SetTextColor(0, 0, 0)
+		r.pdf.MultiCell(0, pdfLineHeight, r.tr("| "+strings.Join(cells, " | ")+" |"), "", "L", false)
+	})
+	r.pdf.Ln(pdfLineHeight)
+}
+
+func (r *pdfRenderer) renderImage(node *model.Node) {
+	src, _ := node.Attrs["url"].(string)
+	var image *Image
+	for _, img := range r.images {
+		if img.DocID == src {
+			image = img
+		}
+	}
+	if image == nil || r.open == nil {
+		return
+	}
+	var typ string
+	switch image.Mime {
+	case "image/jpeg":
+		typ = "JPG"
+	case "image/png":
+		typ = "PNG"
+	case "image/gif":
+		typ = "GIF"
+	default:
+		r.pdf.SetFont("Helvetica", "I", r.size)
+		r.pdf.Write(pdfLineHeight, r.tr(image.Name))
+		r.pdf.Ln(pdfLineHeight * 1.5)
+		return
+	}
+
+	f, err := r.open(image)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	opts := fpdf.ImageOptions{ImageType: typ, ReadDpi: true}
+	info := r.pdf.RegisterImageOptionsReader(image.ID(), opts, f)
+	if info == nil || r.pdf.Err() {
+		r.pdf.ClearError()
+		return
+	}
+
+	pageWidth, _ := r.pdf.GetPageSize()
+	_, _, right, _ := r.pdf.GetMargins()
+	available := pageWidth - right - r.pdf.GetX()
+	width, _ := info.Extent()
+	if width > available {
+		width = available
+	}
+	r.pdf.ImageOptions(image.ID(), r.pdf.GetX(), r.pdf.GetY(), width, 0, true, opts, 0, "")
+	r.pdf.Ln(pdfLineHeight)
+}
+
+// renderInline writes the inline content of a node (text with marks, hard
+// breaks, emojis, dates, etc.).
+func (r *pdfRenderer) renderInline(node *model.Node, baseStyle string) {
+	node.ForEach(func(child *model.Node, _ int, _ int) {
+		if child.IsText() {
+			r.renderText(*child.Text, child.Marks, baseStyle)
+			return
+		}
+		switch child.Type.Name {
+		case "hardBreak":
+			r.pdf.Ln(pdfLineHeight)
+		case "emoji":
+			text, _ := child.Attrs["text"].(string)
+			if text == "" {
+				text, _ = child.Attrs["shortName"].(string)
+			}
+			r.renderText(text, child.Marks, baseStyle)
+		case "mention", "status":
+			text, _ := child.Attrs["text"].(string)
+			r.renderText(text, child.Marks, baseStyle)
+		case "date":
+			text := ""
+			if ts, ok := child.Attrs["timestamp"].(string); ok {
+				if ms, err := strconv.ParseInt(ts, 10, 64); err == nil {
+					text = time.UnixMilli(ms).UTC().Format("2006-01-02")
+				}
+			}
+			r.renderText(text, child.Marks, baseStyle)
+		case "inlineCard":
+			href, _ := child.Attrs["url"].(string)
+			r.setStyle(baseStyle, child.Marks)
+			r.pdf.SetTextColor(41, 126, 242)
+			r.pdf.WriteLinkString(pdfLineHeight, r.tr(href), href)
+			r.pdf.SetTextColor(0, 0, 0)
+		default:
+			r.renderText(child.TextContent(), child.Marks, baseStyle)
+		}
+	})
+}
+
+func (r *pdfRenderer) renderText(text string, marks []*model.Mark, baseStyle string) {
+	if text == "" {
+		return
+	}
+	r.setStyle(baseStyle, marks)
+	for _, mark := range marks {
+		if mark.Type.Name == "link" {
+			href, _ := mark.Attrs["href"].(string)
+			r.pdf.SetTextColor(41, 126, 242)
+			r.pdf.WriteLinkString(pdfLineHeight, r.tr(text), href)
+			r.pdf.SetTextColor(0, 0, 0)
+			return
+		}
+	}
+	r.pdf.Write(pdfLineHeight, r.tr(text))
+	r.pdf.SetTextColor(0, 0, 0)
+}
+
+func (r *pdfRenderer) setStyle(baseStyle string, marks []*model.Mark) {
+	family := "Helvetica"
+	style := baseStyle
+	r.pdf.SetTextColor(0, 0, 0)
+	for _, mark := range marks {
+		switch mark.Type.Name {
+		case "strong":
+			if !strings.Contains(style, "B") {
+				style += "B"
+			}
+		case "em":
+			style += "I"
+		case "underline":
+			style += "U"
+		case "strike":
+			style += "S"
+		case "code":
+			family = "Courier"
+		case "textColor":
+			if color, ok := mark.Attrs["color"].(string); ok {
+				var red, green, blue int
+				if _, err := fmt.Sscanf(color, "#%02x%02x%02x", &red, &green, &blue); err == nil {
+					r.pdf.SetTextColor(red, green, blue)
+				}
+			}
+		}
+	}
+	r.pdf.SetFont(family, style, r.size)
+}
+
+func (r *pdfRenderer) withSize(size float64, fn func()) {
+	previous := r.size
+	r.size = size
+	fn()
+	r.size = previous
+}
+
+func (r *pdfRenderer) indent(fn func()) {
+	left, top, right, _ := r.pdf.GetMargins()
+	r.pdf.SetMargins(left+pdfIndent, top, right)
+	r.pdf.SetX(left + pdfIndent)
+	fn()
+	r.pdf.SetMargins(left, top, right)
+	r.pdf.SetX(left)
+}
@@ -0,0 +1,58 @@
+package note
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cozy/prosemirror-go/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPDF(t *testing.T) {
+	initial := `# My title
+
+foobar **bold** _italic_ ` + "`code`" + ` [link](https://cozy.io/)
+
+- [ ] a todo task
+- [X] a done task
+
+1. first
+2. second
+
+![logo.png](6b3e4a60-2b7d-013b-2ec6-543d7eb8149c)`
+
+	schemaSpecs := DefaultSchemaSpecs()
+	specs := model.SchemaSpecFromJSON(schemaSpecs)
+	schema, err := model.NewSchema(&specs)
+	require.NoError(t, err)
+
+	node, err := parseFile(strings.NewReader(initial), schema)
+	require.NoError(t, err)
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(5, 5, color.Black)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	images := []*Image{{
+		DocID: "6b3e4a60-2b7d-013b-2ec6-543d7eb8149c",
+		Name:  "logo.png",
+		Mime:  "image/png",
+	}}
+	opened := 0
+	open := func(img *Image) (io.ReadCloser, error) {
+		opened++
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}
+
+	pdf, err := newPDFRenderer("My note", open, images).Render(node)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+	assert.Equal(t, 1, opened)
+}
@@ -0,0 +1,274 @@
+package note
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+)
+
+// The status of a user viewing a note.
+const (
+	PresenceActive = "active"
+	PresenceIdle   = "idle"
+	PresenceLeft   = "left"
+)
+
+// PresenceIdleDelay is the delay after which a user without activity on a
+// note is considered as idle.
+var PresenceIdleDelay = 1 * time.Minute
+
+// PresenceLeaveDelay is the delay after which a user without activity on a
+// note is considered as gone. The clients are expected to send a heartbeat
+// more often than that.
+var PresenceLeaveDelay = 3 * time.Minute
+
+// presenceColors is the palette used for the colors of the collaborators.
+var presenceColors = []string{
+	"#297ef2", // blue
+	"#08b442", // green
+	"#fc6d00", // orange
+	"#b449e7", // purple
+	"#f52d2d", // red
+	"#1fa8f1", // light blue
+	"#fd7461", // salmon
+	"#e4a400", // yellow
+}
+
+// Presence is a user viewing a note.
+type Presence struct {
+	SessionID  string                 `json:"sessionID"`
+	Name       string                 `json:"name,omitempty"`
+	Color      string                 `json:"color"`
+	Status     string                 `json:"status"`
+	Pointer    map[string]interface{} `json:"pointer,omitempty"`
+	LastSeenAt time.Time              `json:"last_seen_at"`
+}
+
+// ID returns the presence identifier, which is the session ID
+func (p *Presence) ID() string { return p.SessionID }
+
+// Rev returns the presence revision
+func (p *Presence) Rev() string { return "" }
+
+// DocType returns the document type
+func (p *Presence) DocType() string { return consts.NotesPresences }
+
+// Clone implements couchdb.Doc
+func (p *Presence) Clone() couchdb.Doc {
+	cloned := *p
+	cloned.Pointer = make(map[string]interface{}, len(p.Pointer))
+	for k, v := range p.Pointer {
+		cloned.Pointer[k] = v
+	}
+	return &cloned
+}
+
+// SetID changes the presence identifier
+func (p *Presence) SetID(id string) { p.SessionID = id }
+
+// SetRev changes the presence revision
+func (p *Presence) SetRev(rev string) {}
+
+// Included is part of the jsonapi.Object interface
+func (p *Presence) Included() []jsonapi.Object { return nil }
+
+// Links is part of the jsonapi.Object interface
+func (p *Presence) Links() *jsonapi.LinksList { return nil }
+
+// Relationships is part of the jsonapi.Object interface
+func (p *Presence) Relationships() jsonapi.RelationshipMap { return nil }
+
+// ColorFor returns the color for the given session. It is stable, so that a
+// user keeps the same color for all the clients.
+func ColorFor(sessionID string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(sessionID))
+	return presenceColors[h.Sum32()%uint32(len(presenceColors))]
+}
+
+func presenceKey(inst *instance.Instance, fileID string) string {
+	return "note-presences:" + inst.DBPrefix() + ":" + fileID
+}
+
+func loadPresences(inst *instance.Instance, fileID string) map[string]*Presence {
+	presences := make(map[string]*Presence)
+	cache := config.GetConfig().CacheStorage
+	if buf, ok := cache.Get(presenceKey(inst, fileID)); ok {
+		if err := json.Unmarshal(buf, &presences); err != nil {
+			inst.Logger().WithNamespace("notes").
+				Warnf("Cannot read presences for %s: %s", fileID, err)
+		}
+	}
+	return presences
+}
+
+func savePresences(inst *instance.Instance, fileID string, presences map[string]*Presence) {
+	cache := config.GetConfig().CacheStorage
+	key := presenceKey(inst, fileID)
+	if len(presences) == 0 {
+		cache.Clear(key)
+		return
+	}
+	buf, err := json.Marshal(presences)
+	if err != nil {
+		return
+	}
+	cache.Set(key, buf, PresenceLeaveDelay)
+}
+
+// sweepPresences marks as idle the users without recent activity, and
+// removes the users that have gone.
+func sweepPresences(inst *instance.Instance, fileID string, presences map[string]*Presence) {
+	now := time.Now()
+	for id, p := range presences {
+		inactive := now.Sub(p.LastSeenAt)
+		switch {
+		case inactive > PresenceLeaveDelay:
+			delete(presences, id)
+			p.Status = PresenceLeft
+			publishPresence(inst, fileID, p)
+		case inactive > PresenceIdleDelay && p.Status == PresenceActive:
+			p.Status = PresenceIdle
+			publishPresence(inst, fileID, p)
+		}
+	}
+}
+
+func publishPresence(inst *instance.Instance, fileID string, p *Presence) {
+	event := Event{
+		"doctype":      consts.NotesPresences,
+		"sessionID":    p.SessionID,
+		"color":        p.Color,
+		"status":       p.Status,
+		"last_seen_at": p.LastSeenAt,
+	}
+	if p.Name != "" {
+		event["name"] = p.Name
+	}
+	event.SetID(fileID)
+	event.publish(inst)
+}
+
+func sortedPresences(presences map[string]*Presence) []*Presence {
+	list := make([]*Presence, 0, len(presences))
+	for _, p := range presences {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SessionID < list[j].SessionID
+	})
+	return list
+}
+
+// withPresences calls fn with the presences of the note, and saves them
+// after.
+func withPresences(inst *instance.Instance, fileID string, fn func(map[string]*Presence)) error {
+	mu := config.Lock().ReadWrite(inst, "notes-presences/"+fileID)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	presences := loadPresences(inst, fileID)
+	sweepPresences(inst, fileID, presences)
+	fn(presences)
+	savePresences(inst, fileID, presences)
+	return nil
+}
+
+// UpdatePresence is used by a client to say that a user is viewing the note.
+// It must be called regularly as a heartbeat. The client can also say that
+// the user is idle (no keyboard or mouse events for example). It returns the
+// list of the users viewing the note.
+func UpdatePresence(inst *instance.Instance, fileID string, update *Presence) ([]*Presence, error) {
+	if update.SessionID == "" {
+		return nil, ErrMissingSessionID
+	}
+	var list []*Presence
+	err := withPresences(inst, fileID, func(presences map[string]*Presence) {
+		status := PresenceActive
+		if update.Status == PresenceIdle {
+			status = PresenceIdle
+		}
+		p, ok := presences[update.SessionID]
+		if !ok {
+			p = &Presence{
+				SessionID: update.SessionID,
+				Color:     ColorFor(update.SessionID),
+			}
+			presences[p.SessionID] = p
+		}
+		changed := !ok || p.Status != status || (update.Name != "" && p.Name != update.Name)
+		if update.Name != "" {
+			p.Name = update.Name
+		}
+		p.Status = status
+		p.LastSeenAt = time.Now().UTC()
+		if changed {
+			publishPresence(inst, fileID, p)
+		}
+		list = sortedPresences(presences)
+	})
+	return list, err
+}
+
+// LeavePresence is used by a client when the user stops viewing a note.
+func LeavePresence(inst *instance.Instance, fileID, sessionID string) error {
+	return withPresences(inst, fileID, func(presences map[string]*Presence) {
+		p, ok := presences[sessionID]
+		if !ok {
+			return
+		}
+		delete(presences, sessionID)
+		p.Status = PresenceLeft
+		publishPresence(inst, fileID, p)
+	})
+}
+
+// ListPresences returns the list of the users viewing a note, with the last
+// position of their pointers.
+func ListPresences(inst *instance.Instance, fileID string) ([]*Presence, error) {
+	var list []*Presence
+	err := withPresences(inst, fileID, func(presences map[string]*Presence) {
+		list = sortedPresences(presences)
+	})
+	return list, err
+}
+
+// touchPresence records the activity of a user when their pointer has moved.
+func touchPresence(inst *instance.Instance, fileID, sessionID string, pointer Event) error {
+	return withPresences(inst, fileID, func(presences map[string]*Presence) {
+		p, ok := presences[sessionID]
+		if !ok {
+			// The telepointer event is enough to tell the other clients that
+			// there is a new user.
+			p = &Presence{
+				SessionID: sessionID,
+				Color:     ColorFor(sessionID),
+				Status:    PresenceActive,
+			}
+			presences[sessionID] = p
+		}
+		changed := p.Status != PresenceActive
+		p.Status = PresenceActive
+		p.LastSeenAt = time.Now().UTC()
+		p.Pointer = make(map[string]interface{}, len(pointer))
+		for k, v := range pointer {
+			if k != "_id" && k != "_rev" && k != "doctype" && k != "sessionID" {
+				p.Pointer[k] = v
+			}
+		}
+		if changed {
+			publishPresence(inst, fileID, p)
+		}
+	})
+}
+
+var _ jsonapi.Object = &Presence{}
@@ -0,0 +1,64 @@
+package note
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorFor(t *testing.T) {
+	assert.Equal(t, ColorFor("543781490137"), ColorFor("543781490137"))
+	assert.Contains(t, presenceColors, ColorFor("543781490137"))
+	assert.Contains(t, presenceColors, ColorFor(""))
+}
+
+func TestPresences(t *testing.T) {
+	config.UseTestFile(t)
+	inst := &instance.Instance{Domain: "presence.example.net", Prefix: "presence-test"}
+	noteID := "d3f6a5a0-4c1c-11ee-be56-0242ac120002"
+
+	_, err := UpdatePresence(inst, noteID, &Presence{})
+	assert.Equal(t, ErrMissingSessionID, err)
+
+	list, err := UpdatePresence(inst, noteID, &Presence{SessionID: "alice", Name: "Alice"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Alice", list[0].Name)
+	assert.Equal(t, PresenceActive, list[0].Status)
+	assert.Equal(t, ColorFor("alice"), list[0].Color)
+
+	pointer := Event{"sessionID": "bob", "anchor": 7, "head": 12}
+	pointer.SetID(noteID)
+	require.NoError(t, PutTelepointer(inst, pointer))
+	assert.Equal(t, ColorFor("bob"), pointer["color"])
+
+	list, err = ListPresences(inst, noteID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "bob", list[1].SessionID)
+	assert.EqualValues(t, 7, list[1].Pointer["anchor"])
+
+	list, err = UpdatePresence(inst, noteID, &Presence{SessionID: "alice", Status: PresenceIdle})
+	require.NoError(t, err)
+	assert.Equal(t, PresenceIdle, list[0].Status)
+	assert.Equal(t, "Alice", list[0].Name)
+
+	// Idle detection
+	idleDelay := PresenceIdleDelay
+	PresenceIdleDelay = 0
+	time.Sleep(time.Millisecond)
+	list, err = ListPresences(inst, noteID)
+	PresenceIdleDelay = idleDelay
+	require.NoError(t, err)
+	assert.Equal(t, PresenceIdle, list[1].Status)
+
+	require.NoError(t, LeavePresence(inst, noteID, "alice"))
+	list, err = ListPresences(inst, noteID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "bob", list[0].SessionID)
+}
@@ -25,9 +25,24 @@ const (
 	// NotificationDiskQuota category for sending alert when reaching 90% of disk
 	// usage quota.
 	NotificationDiskQuota = "disk-quota"
+	// NotificationDiskForecast category for sending alert when the disk usage
+	// quota will be reached soon at the current rate.
+	NotificationDiskForecast = "disk-forecast"
 	// NotificationOAuthClients category for sending alert when exceeding the
 	// connected OAuth clients limit.
 	NotificationOAuthClients = "oauth-clients"
+	// NotificationKonnectorAction category for sending alert when a konnector
+	// needs an action from the user.
+	NotificationKonnectorAction = "konnector-action"
+	// NotificationReminder category for delivering the reminders created by
+	// the apps.
+	NotificationReminder = "reminder"
+	// NotificationCommentMention category for warning the user that they have
+	// been mentioned in a comment.
+	NotificationCommentMention = "comment-mention"
+	// NotificationInfectedFile category for warning the user that a virus
+	// has been found in one of their files.
+	NotificationInfectedFile = "infected-file"
 )
 
 var (
@@ -39,12 +54,34 @@ var (
 			MailTemplate: "notifications_diskquota",
 			MinInterval:  7 * 24 * time.Hour,
 		},
+		NotificationDiskForecast: {
+			Description:  "Warn about the diskquota that will be reached soon",
+			Collapsible:  true,
+			Stateful:     true,
+			MailTemplate: "notifications_diskforecast",
+		},
 		NotificationOAuthClients: {
 			Description:  "Warn about the connected OAuth clients count exceeding the offer limit",
 			Collapsible:  false,
 			Stateful:     false,
 			MailTemplate: "notifications_oauthclients",
 		},
+		NotificationKonnectorAction: {
+			Description: "Warn about a konnector that needs an action from the user",
+			Collapsible: true,
+		},
+		NotificationReminder: {
+			Description: "Deliver a reminder created by an app",
+			Multiple:    true,
+		},
+		NotificationCommentMention: {
+			Description: "Warn about a mention of the user in a comment",
+			Multiple:    true,
+		},
+		NotificationInfectedFile: {
+			Description: "Warn about a virus found in a file",
+			Multiple:    true,
+		},
 	}
 )
 
@@ -111,6 +148,42 @@ func init() {
 	})
 }
 
+// PushDiskForecast warns the user that their disk quota will be reached in
+// the given number of days at the current rate. As the notification is
+// stateful, it must also be called with soon=false when it is no longer the
+// case, so that the user can be warned again later.
+func PushDiskForecast(i *instance.Instance, daysUntilFull int, soon bool) error {
+	var offersLink string
+	if i.HasPremiumLinksEnabled() {
+		var err error
+		offersLink, err = i.ManagerURL(instance.ManagerPremiumURL)
+		if err != nil {
+			i.Logger().Errorf("Could not get instance Premium Manager URL: %s", err.Error())
+		}
+	}
+	cozyDriveLink := i.SubDomain(consts.DriveSlug)
+	redirectLink := consts.SettingsSlug + "/#/storage"
+
+	n := &notification.Notification{
+		Title:   i.Translate("Notifications Disk Forecast Title"),
+		Message: i.Translate("Notifications Disk Forecast Message", daysUntilFull),
+		Slug:    consts.SettingsSlug,
+		State:   soon,
+		Data: map[string]interface{}{
+			// For email notification
+			"DaysUntilFull": daysUntilFull,
+			"OffersLink":    offersLink,
+			"CozyDriveLink": cozyDriveLink.String(),
+
+			// For mobile push notification
+			"appName":      "",
+			"redirectLink": redirectLink,
+		},
+		PreferredChannels: []string{"mobile"},
+	}
+	return PushStack(i.Domain, NotificationDiskForecast, n)
+}
+
 // PushStack creates and sends a new notification where the source is the stack.
 func PushStack(domain string, category string, n *notification.Notification) error {
 	inst, err := lifecycle.GetInstance(domain)
@@ -17,6 +17,7 @@ type AccessCode struct {
 	IssuedAt  int64  `json:"issued_at"`
 	Scope     string `json:"scope"`
 	Challenge string `json:"code_challenge,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
 }
 
 // ID returns the access code qualified identifier
@@ -39,6 +40,12 @@ func (ac *AccessCode) SetRev(rev string) { ac.CouchRev = rev }
 
 // CreateAccessCode an access code for the given clientID, persisted in CouchDB
 func CreateAccessCode(i *instance.Instance, client *Client, scope, challenge string) (*AccessCode, error) {
+	return CreateOpenIDAccessCode(i, client, scope, challenge, "")
+}
+
+// CreateOpenIDAccessCode is like CreateAccessCode, but it also keeps the nonce
+// of an authorization request of OpenID Connect, for the ID token.
+func CreateOpenIDAccessCode(i *instance.Instance, client *Client, scope, challenge, nonce string) (*AccessCode, error) {
 	if client.Pending {
 		client.Pending = false
 		client.ClientID = ""
@@ -51,6 +58,7 @@ func CreateAccessCode(i *instance.Instance, client *Client, scope, challenge str
 		IssuedAt:  crypto.Timestamp(),
 		Scope:     scope,
 		Challenge: challenge,
+		Nonce:     nonce,
 	}
 	if err := couchdb.CreateDoc(i, ac); err != nil {
 		return nil, err
@@ -16,11 +16,6 @@ import (
 // checkAndroidAttestation will check an attestation made by the SafetyNet API.
 // Cf https://developer.android.com/training/safetynet/attestation#use-response-server
 func (c *Client) checkAndroidAttestation(inst *instance.Instance, req AttestationRequest) error {
-	store := GetStore()
-	if ok := store.CheckAndClearChallenge(inst, c.ID(), req.Challenge); !ok {
-		return errors.New("invalid challenge")
-	}
-
 	token, err := jwt.Parse(req.Attestation, androidKeyFunc)
 	if err != nil {
 		return fmt.Errorf("cannot parse attestation: %s", err)
@@ -0,0 +1,142 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	// ErrAssertionRequired is used when a client attested with App Attest
+	// has not sent an assertion on a route that requires it.
+	ErrAssertionRequired = errors.New("app attest assertion required")
+	// ErrInvalidAssertion is used when the assertion cannot be verified with
+	// the public key of the client.
+	ErrInvalidAssertion = errors.New("invalid app attest assertion")
+	// ErrClonedCredential is used when the counter of an assertion has not
+	// increased: the key has probably been extracted and used on another
+	// device.
+	ErrClonedCredential = errors.New("app attest counter has not increased")
+)
+
+type appleAssertionObject struct {
+	Signature   []byte `codec:"signature"`
+	RawAuthData []byte `codec:"authenticatorData"`
+	AuthData    authenticatorData
+}
+
+// RequiresAssertion returns true if the client must send an App Attest
+// assertion on the sensitive routes, like the refresh of its token.
+func (c *Client) RequiresAssertion(inst *instance.Instance) bool {
+	if len(c.AttestationPublicKey) == 0 {
+		return false
+	}
+	contextName := inst.ContextName
+	if contextName == "" {
+		contextName = config.DefaultInstanceContext
+	}
+	cfg, ok := config.GetConfig().Flagship.Contexts[contextName].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	required, _ := cfg["app_attest_assertions"].(bool)
+	return required
+}
+
+// CheckAssertion verifies an assertion made by the DeviceCheck API with the
+// key attested for this client. The client data is the challenge, that must
+// have been created by the stack for this client.
+// Cf https://developer.apple.com/documentation/devicecheck/validating_apps_that_connect_to_your_server#3576644
+func (c *Client) CheckAssertion(inst *instance.Instance, challenge, assertion string) error {
+	if challenge == "" || assertion == "" {
+		return ErrAssertionRequired
+	}
+	if ok := GetStore().CheckAndClearChallenge(inst, c.ID(), challenge); !ok {
+		return ErrInvalidChallenge
+	}
+	counter, err := c.verifyAssertion(challenge, assertion)
+	if err == ErrClonedCredential {
+		inst.Logger().WithNamespace("oauth").
+			Warnf("Cloned credential detected for client %s: counter %d <= %d",
+				c.ID(), counter, c.AttestationCounter)
+	}
+	if err != nil {
+		return err
+	}
+	c.AttestationCounter = counter
+	return couchdb.UpdateDoc(inst, c)
+}
+
+// verifyAssertion checks the signature of the assertion, and returns its
+// counter.
+func (c *Client) verifyAssertion(challenge, assertion string) (uint32, error) {
+	obj, err := parseAppleAssertion(assertion)
+	if err != nil {
+		return 0, ErrInvalidAssertion
+	}
+	if err := obj.checkSignature(c.AttestationPublicKey, challenge); err != nil {
+		return 0, err
+	}
+
+	// The RP ID of the authenticator data must be the App ID of the app.
+	if err := checkAppID(obj.AuthData.RPIDHash); err != nil {
+		return 0, ErrInvalidAssertion
+	}
+
+	// The counter must be greater than the one of the last assertion.
+	counter := obj.AuthData.Counter
+	if counter <= c.AttestationCounter {
+		return counter, ErrClonedCredential
+	}
+	return counter, nil
+}
+
+func parseAppleAssertion(assertion string) (*appleAssertionObject, error) {
+	raw, err := base64.StdEncoding.DecodeString(assertion)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding base64: %s", err)
+	}
+	obj := appleAssertionObject{}
+	cborHandler := codec.CborHandle{}
+	err = codec.NewDecoderBytes(raw, &cborHandler).Decode(&obj)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding cbor: %s", err)
+	}
+	obj.AuthData, err = parseAuthData(obj.RawAuthData)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding auth data: %v", err)
+	}
+	return &obj, nil
+}
+
+func (obj *appleAssertionObject) checkSignature(publicKey []byte, clientData string) error {
+	// 1. Compute clientDataHash as the SHA256 hash of clientData.
+	clientDataHash := sha256.Sum256([]byte(clientData))
+
+	// 2. Concatenate authenticatorData and clientDataHash, and apply a SHA256
+	// hash over the result to form nonce.
+	composite := append([]byte{}, obj.RawAuthData...)
+	composite = append(composite, clientDataHash[:]...)
+	nonce := sha256.Sum256(composite)
+
+	// 3. Use the public key that you store from the attestation object to
+	// verify that the assertion's signature is valid for nonce.
+	x, y := elliptic.Unmarshal(elliptic.P256(), publicKey)
+	if x == nil {
+		return ErrInvalidAssertion
+	}
+	pub := ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	digest := sha256.Sum256(nonce[:])
+	if !ecdsa.VerifyASN1(&pub, digest[:], obj.Signature) {
+		return ErrInvalidAssertion
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+func makeAssertion(t *testing.T, key *ecdsa.PrivateKey, appID, challenge string, counter uint32) string {
+	rpIDHash := sha256.Sum256([]byte(appID))
+	authData := make([]byte, 37)
+	copy(authData, rpIDHash[:])
+	binary.BigEndian.PutUint32(authData[33:], counter)
+
+	clientDataHash := sha256.Sum256([]byte(challenge))
+	nonce := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	digest := sha256.Sum256(nonce[:])
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	var raw []byte
+	obj := map[string]interface{}{
+		"signature":         sig,
+		"authenticatorData": authData,
+	}
+	err = codec.NewEncoderBytes(&raw, &codec.CborHandle{}).Encode(obj)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestAppleAssertion(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Flagship.AppleAppIDs = []string{"3AKXFMV43J.io.cozy.flagship.mobile"}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	appID := conf.Flagship.AppleAppIDs[0]
+	client := &Client{
+		AttestationPublicKey: elliptic.Marshal(elliptic.P256(), key.X, key.Y),
+		AttestationCounter:   3,
+	}
+
+	t.Run("RequiresAssertion", func(t *testing.T) {
+		inst := &instance.Instance{ContextName: "ios"}
+		assert.False(t, client.RequiresAssertion(inst))
+		conf.Flagship.Contexts = map[string]interface{}{
+			"ios": map[string]interface{}{"app_attest_assertions": true},
+		}
+		t.Cleanup(func() { conf.Flagship.Contexts = nil })
+		assert.True(t, client.RequiresAssertion(inst))
+		assert.False(t, (&Client{}).RequiresAssertion(inst))
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		assertion := makeAssertion(t, key, appID, "challenge", 4)
+		counter, err := client.verifyAssertion("challenge", assertion)
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, counter)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		assertion := makeAssertion(t, other, appID, "challenge", 4)
+		_, err := client.verifyAssertion("challenge", assertion)
+		assert.ErrorIs(t, err, ErrInvalidAssertion)
+
+		assertion = makeAssertion(t, key, appID, "challenge", 4)
+		_, err = client.verifyAssertion("another challenge", assertion)
+		assert.ErrorIs(t, err, ErrInvalidAssertion)
+	})
+
+	t.Run("InvalidAppID", func(t *testing.T) {
+		assertion := makeAssertion(t, key, "io.cozy.other", "challenge", 4)
+		_, err := client.verifyAssertion("challenge", assertion)
+		assert.ErrorIs(t, err, ErrInvalidAssertion)
+	})
+
+	t.Run("ClonedCredential", func(t *testing.T) {
+		assertion := makeAssertion(t, key, appID, "challenge", 3)
+		_, err := client.verifyAssertion("challenge", assertion)
+		assert.ErrorIs(t, err, ErrClonedCredential)
+	})
+}
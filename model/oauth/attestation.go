@@ -0,0 +1,175 @@
+package oauth
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/utils"
+)
+
+// The formats of the attestations, with a provider for each of them.
+const (
+	// AttestationSafetyNet is used for the legacy SafetyNet JWS responses.
+	AttestationSafetyNet = "safetynet"
+	// AttestationPlayIntegrity is used for the Play Integrity verdict tokens.
+	AttestationPlayIntegrity = "play_integrity"
+	// AttestationApple is used for the App Attest objects of the DeviceCheck
+	// API.
+	AttestationApple = "apple"
+)
+
+var (
+	// ErrInvalidPlatform is used when no attestation provider can be used for
+	// the platform of the request.
+	ErrInvalidPlatform = errors.New("invalid platform")
+	// ErrInvalidAttestationFormat is used for an attestation format that is
+	// unknown, or not enabled for the context of the instance.
+	ErrInvalidAttestationFormat = errors.New("invalid attestation format")
+	// ErrInvalidChallenge is used when the challenge of the attestation has
+	// not been created by the stack, or has already been used.
+	ErrInvalidChallenge = errors.New("invalid challenge")
+)
+
+// AttestationRequest is what an OAuth client can send to attest that it is the
+// flagship app.
+type AttestationRequest struct {
+	Platform    string `json:"platform"`
+	Challenge   string `json:"challenge"`
+	Attestation string `json:"attestation"`
+	KeyID       []byte `json:"keyId"`
+	// Format is the name of the attestation provider. When it is empty, the
+	// default provider for the platform is used.
+	Format string `json:"attestation_format,omitempty"`
+}
+
+// AttestationProvider is the interface for checking an attestation sent by a
+// mobile app to be certified as the flagship app. The challenge of the
+// request has already been checked when Verify is called.
+type AttestationProvider interface {
+	// Platform returns the platform of the apps, like android or ios.
+	Platform() string
+	// Verify returns an error if the attestation is not valid.
+	Verify(inst *instance.Instance, c *Client, req AttestationRequest) error
+}
+
+// builtinProvider is an attestation provider for a check made by a method of
+// the client.
+type builtinProvider struct {
+	platform string
+	verify   func(c *Client, inst *instance.Instance, req AttestationRequest) error
+}
+
+func (p builtinProvider) Platform() string { return p.platform }
+
+func (p builtinProvider) Verify(inst *instance.Instance, c *Client, req AttestationRequest) error {
+	return p.verify(c, inst, req)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]AttestationProvider{
+		AttestationSafetyNet:     builtinProvider{"android", (*Client).checkAndroidAttestation},
+		AttestationPlayIntegrity: builtinProvider{"android", (*Client).checkPlayIntegrityAttestation},
+		AttestationApple:         builtinProvider{"ios", (*Client).checkAppleAttestation},
+	}
+	// defaultFormats are the formats used for the requests without format,
+	// when the context has no list of attestation providers.
+	defaultFormats = map[string]string{
+		"android": AttestationSafetyNet,
+		"ios":     AttestationApple,
+	}
+)
+
+// RegisterAttestationProvider adds a provider for the given format, or
+// replaces the provider of a built-in format. It can be used to plug a
+// custom attestation for a self-hosted distribution of the mobile app.
+func RegisterAttestationProvider(format string, provider AttestationProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[format] = provider
+}
+
+func getAttestationProvider(format string) (AttestationProvider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[format]
+	return p, ok
+}
+
+// enabledAttestationFormats returns the list of the attestation formats
+// enabled for the context, in the order of preference, or nil if all the
+// formats are enabled.
+func enabledAttestationFormats(contextName string) []string {
+	if contextName == "" {
+		contextName = config.DefaultInstanceContext
+	}
+	cfg, ok := config.GetConfig().Flagship.Contexts[contextName].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	list, ok := cfg["attestation_providers"].([]interface{})
+	if !ok {
+		return nil
+	}
+	formats := make([]string, 0, len(list))
+	for _, item := range list {
+		if format, ok := item.(string); ok {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// attestationProvider returns the format and the provider to use for the
+// request.
+func attestationProvider(inst *instance.Instance, req AttestationRequest) (string, AttestationProvider, error) {
+	enabled := enabledAttestationFormats(inst.ContextName)
+	format := req.Format
+	if format == "" {
+		if enabled == nil {
+			format = defaultFormats[req.Platform]
+		}
+		for _, f := range enabled {
+			if p, ok := getAttestationProvider(f); ok && p.Platform() == req.Platform {
+				format = f
+				break
+			}
+		}
+		if format == "" {
+			return "", nil, ErrInvalidPlatform
+		}
+	} else if enabled != nil && !utils.IsInArray(format, enabled) {
+		return "", nil, ErrInvalidAttestationFormat
+	}
+
+	p, ok := getAttestationProvider(format)
+	if !ok {
+		return "", nil, ErrInvalidAttestationFormat
+	}
+	if p.Platform() != req.Platform {
+		return "", nil, ErrInvalidPlatform
+	}
+	return format, p, nil
+}
+
+// Attest can be used to check an attestation for certifying the app.
+func (c *Client) Attest(inst *instance.Instance, req AttestationRequest) error {
+	format, provider, err := attestationProvider(inst, req)
+	if err != nil {
+		return err
+	}
+	inst.Logger().WithNamespace("oauth").
+		Debugf("Attestation of client %s with %s", c.ID(), format)
+	store := GetStore()
+	if ok := store.CheckAndClearChallenge(inst, c.ID(), req.Challenge); !ok {
+		return ErrInvalidChallenge
+	}
+	if err := provider.Verify(inst, c, req); err != nil {
+		return err
+	}
+
+	c.CertifiedFromStore = true
+	return c.SetFlagship(inst)
+}
@@ -0,0 +1,65 @@
+package oauth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signatureProvider struct{}
+
+func (signatureProvider) Platform() string { return "android" }
+
+func (signatureProvider) Verify(inst *instance.Instance, c *Client, req AttestationRequest) error {
+	if req.Attestation != "signed" {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func TestAttestationProvider(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Flagship.Contexts = map[string]interface{}{
+		"selfhosted": map[string]interface{}{
+			"attestation_providers": []interface{}{"signature", AttestationApple},
+		},
+	}
+	RegisterAttestationProvider("signature", signatureProvider{})
+	t.Cleanup(func() {
+		providersMu.Lock()
+		delete(providers, "signature")
+		providersMu.Unlock()
+	})
+
+	inst := &instance.Instance{}
+	format, p, err := attestationProvider(inst, AttestationRequest{Platform: "android"})
+	require.NoError(t, err)
+	assert.Equal(t, AttestationSafetyNet, format)
+	assert.Equal(t, "android", p.Platform())
+	format, p, err = attestationProvider(inst, AttestationRequest{Platform: "android", Format: AttestationPlayIntegrity})
+	require.NoError(t, err)
+	assert.Equal(t, AttestationPlayIntegrity, format)
+	_, _, err = attestationProvider(inst, AttestationRequest{Platform: "ios", Format: AttestationSafetyNet})
+	assert.ErrorIs(t, err, ErrInvalidPlatform)
+	_, _, err = attestationProvider(inst, AttestationRequest{Platform: "windows"})
+	assert.ErrorIs(t, err, ErrInvalidPlatform)
+	_, _, err = attestationProvider(inst, AttestationRequest{Platform: "android", Format: "unknown"})
+	assert.ErrorIs(t, err, ErrInvalidAttestationFormat)
+
+	inst = &instance.Instance{ContextName: "selfhosted"}
+	format, p, err = attestationProvider(inst, AttestationRequest{Platform: "android"})
+	require.NoError(t, err)
+	assert.Equal(t, "signature", format)
+	assert.Equal(t, signatureProvider{}, p)
+	_, _, err = attestationProvider(inst, AttestationRequest{Platform: "android", Format: AttestationSafetyNet})
+	assert.ErrorIs(t, err, ErrInvalidAttestationFormat)
+	format, p, err = attestationProvider(inst, AttestationRequest{Platform: "ios"})
+	require.NoError(t, err)
+	assert.Equal(t, AttestationApple, format)
+	assert.Equal(t, "ios", p.Platform())
+}
@@ -1,6 +1,7 @@
 package oauth
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,12 +12,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/activity"
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/feature"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/notification"
 	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
@@ -36,6 +39,15 @@ const (
 	PlatformHuawei = "huawei"
 )
 
+// The methods that a client can use to authenticate on the token endpoint
+// (token_endpoint_auth_method in RFC 7591).
+const (
+	// AuthMethodSecretPost is used by the clients that send their secret
+	AuthMethodSecretPost = "client_secret_post"
+	// AuthMethodNone is used by the public clients, with PKCE
+	AuthMethodNone = "none"
+)
+
 // DocTypeVersion represents the doctype version. Each time this document
 // structure is modified, update this value
 const DocTypeVersion = "1"
@@ -53,7 +65,10 @@ const ScopeLogin = "login"
 
 // CleanMessage is used for messages to the clean-clients worker.
 type CleanMessage struct {
-	ClientID string `json:"client_id"`
+	ClientID string `json:"client_id,omitempty"`
+	// DeviceCode is used to remove a device code that has expired without
+	// having been polled by the device.
+	DeviceCode string `json:"device_code,omitempty"`
 }
 
 // Client is a struct for OAuth2 client. Most of the fields are described in
@@ -75,6 +90,30 @@ type Client struct {
 	AllowLoginScope   bool   `json:"allow_login_scope,omitempty"`         // Allow to generate token for a "login" scope (no permissions)
 	Pending           bool   `json:"pending,omitempty"`                   // True until a token is generated
 
+	// After a rotation of the secret, the previous secret is still accepted
+	// until PreviousSecretExpiresAt (a unix timestamp)
+	PreviousSecret          string `json:"previous_client_secret,omitempty"`
+	PreviousSecretExpiresAt int64  `json:"previous_client_secret_expires_at,omitempty"`
+
+	// TokenEndpointAuthMethod is "none" for the public clients (they use PKCE
+	// instead of the client secret), and "client_secret_post" (or empty) for
+	// the others.
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method,omitempty"`
+
+	// TokenGeneration is incremented when the tokens of the client are
+	// revoked: the tokens issued for a previous generation are rejected.
+	TokenGeneration int `json:"token_generation,omitempty"`
+
+	// OpenIDScopes are the scopes of OpenID Connect that the user has
+	// consented to on the authorize page, the last time the client has
+	// exchanged an authorization code.
+	OpenIDScopes []string `json:"openid_scopes,omitempty"`
+
+	// UserID is the identifier of the user who has authorized the client, for
+	// the instances in organization mode. The client can only be used by
+	// this user, and with the permissions of their role.
+	UserID string `json:"user_id,omitempty"`
+
 	RedirectURIs    []string `json:"redirect_uris"`              // Declared by the client (mandatory)
 	GrantTypes      []string `json:"grant_types"`                // Forced by the server to ["authorization_code", "refresh_token"]
 	ResponseTypes   []string `json:"response_types"`             // Forced by the server to ["code"]
@@ -96,11 +135,28 @@ type Client struct {
 	// XXX omitempty does not work for time.Time, thus the interface{} type
 	SynchronizedAt  interface{} `json:"synchronized_at,omitempty"`   // Date of the last synchronization, updated by /settings/synchronized
 	LastRefreshedAt interface{} `json:"last_refreshed_at,omitempty"` // Date of the last refresh of the OAuth token
+	// LastActivity is where the token has been refreshed for the last time,
+	// so that the user can spot a suspicious device.
+	LastActivity *ClientActivity `json:"last_activity,omitempty"`
+	// StaleNotifiedAt is the date when the user has been warned that the
+	// client is stale and will be deleted.
+	StaleNotifiedAt *time.Time `json:"stale_notified_at,omitempty"`
 
 	Flagship            bool `json:"flagship,omitempty"`
 	CertifiedFromStore  bool `json:"certified_from_store,omitempty"`
 	CreatedAtOnboarding bool `json:"created_at_onboarding,omitempty"`
 
+	// The key generated by App Attest on iOS, and the counter of its last
+	// assertion, for checking the assertions on the sensitive routes.
+	AttestationKeyID     []byte `json:"attestation_key_id,omitempty"`
+	AttestationPublicKey []byte `json:"attestation_public_key,omitempty"`
+	AttestationCounter   uint32 `json:"attestation_counter,omitempty"`
+
+	// APIQuota is the number of requests per day that the client can make on
+	// the API (0 for the default of the context, -1 for no limit). It can
+	// only be set by the hoster, via the admin API.
+	APIQuota int64 `json:"api_quota,omitempty"`
+
 	OnboardingSecret      string `json:"onboarding_secret,omitempty"`
 	OnboardingApp         string `json:"onboarding_app,omitempty"`
 	OnboardingPermissions string `json:"onboarding_permissions,omitempty"`
@@ -168,6 +224,7 @@ func GetAll(inst *instance.Instance, limit int, bookmark string) ([]*Client, str
 			return nil, "", err
 		}
 		client.ClientSecret = ""
+		client.PreviousSecret = ""
 		clients[i] = &client
 	}
 	return clients, res.Bookmark, nil
@@ -211,6 +268,7 @@ func GetConnectedUserClients(i *instance.Instance, limit int, bookmark string) (
 
 	for _, client := range clients {
 		client.ClientSecret = ""
+		client.PreviousSecret = ""
 	}
 
 	return clients, res.Bookmark, nil
@@ -342,6 +400,15 @@ func (c *Client) checkMandatoryFields(i *instance.Instance) *ClientRegistrationE
 			Description: "software_id is mandatory",
 		}
 	}
+	switch c.TokenEndpointAuthMethod {
+	case "", AuthMethodSecretPost, AuthMethodNone:
+	default:
+		return &ClientRegistrationError{
+			Code:        http.StatusBadRequest,
+			Error:       "invalid_client_metadata",
+			Description: "token_endpoint_auth_method is invalid",
+		}
+	}
 	c.NotificationPlatform = strings.ToLower(c.NotificationPlatform)
 	switch c.NotificationPlatform {
 	case "", PlatformFirebase, PlatformAPNS, PlatformHuawei:
@@ -470,9 +537,15 @@ func (c *Client) Create(i *instance.Instance, opts ...CreateOptions) *ClientRegi
 	secret := crypto.GenerateRandomBytes(ClientSecretLen)
 	c.ClientSecret = string(crypto.Base64Encode(secret))
 	c.SecretExpiresAt = 0
+	c.PreviousSecret = ""
+	c.PreviousSecretExpiresAt = 0
 	c.RegistrationToken = ""
 	c.GrantTypes = []string{"authorization_code", "refresh_token"}
 	c.ResponseTypes = []string{"code"}
+	c.APIQuota = 0
+	c.UserID = ""
+	c.TokenGeneration = 0
+	c.OpenIDScopes = nil
 
 	// Adding Metadata
 	md := metadata.New()
@@ -498,6 +571,7 @@ func (c *Client) Create(i *instance.Instance, opts ...CreateOptions) *ClientRegi
 				Warnf("Cannot create trigger: %s", err)
 		}
 	}
+	ensureCleanStaleClientsTrigger(i)
 
 	var err error
 	c.RegistrationToken, err = crypto.NewJWT(i.OAuthSecret, jwt.RegisteredClaims{
@@ -584,6 +658,9 @@ func (c *Client) Update(i *instance.Instance, old *Client) *ClientRegistrationEr
 	c.CouchRev = old.CouchRev
 	c.ClientID = ""
 	c.SecretExpiresAt = 0
+	c.PreviousSecret = old.PreviousSecret
+	c.PreviousSecretExpiresAt = old.PreviousSecretExpiresAt
+	c.TokenEndpointAuthMethod = old.TokenEndpointAuthMethod
 	c.RegistrationToken = ""
 	c.GrantTypes = []string{"authorization_code", "refresh_token"}
 	c.ResponseTypes = []string{"code"}
@@ -604,6 +681,13 @@ func (c *Client) Update(i *instance.Instance, old *Client) *ClientRegistrationEr
 
 	c.Flagship = old.Flagship
 	c.CertifiedFromStore = old.CertifiedFromStore
+	c.AttestationKeyID = old.AttestationKeyID
+	c.AttestationPublicKey = old.AttestationPublicKey
+	c.AttestationCounter = old.AttestationCounter
+	c.APIQuota = old.APIQuota
+	c.UserID = old.UserID
+	c.TokenGeneration = old.TokenGeneration
+	c.OpenIDScopes = old.OpenIDScopes
 
 	// Updating metadata
 	md := metadata.New()
@@ -637,6 +721,53 @@ func (c *Client) Update(i *instance.Instance, old *Client) *ClientRegistrationEr
 	return nil
 }
 
+// RotateSecret generates a new secret for the client. The previous secret is
+// still accepted during the grace period from the config, so that the
+// deployed clients can roll over without being logged out.
+func (c *Client) RotateSecret(i *instance.Instance) error {
+	grace := config.GetConfig().OAuth.SecretRotationGracePeriod
+	if grace > 0 {
+		c.PreviousSecret = c.ClientSecret
+		c.PreviousSecretExpiresAt = time.Now().Add(grace).Unix()
+	} else {
+		c.PreviousSecret = ""
+		c.PreviousSecretExpiresAt = 0
+	}
+	secret := crypto.GenerateRandomBytes(ClientSecretLen)
+	c.ClientSecret = string(crypto.Base64Encode(secret))
+	if c.Metadata != nil {
+		c.Metadata.ChangeUpdatedAt()
+	}
+	c.ClientID = ""
+	if err := couchdb.UpdateDoc(i, c); err != nil {
+		c.ClientID = c.CouchID
+		return err
+	}
+	c.TransformIDAndRev()
+	return nil
+}
+
+// CheckSecret returns true if the given secret is the secret of the client,
+// or its previous secret during the grace period after a rotation.
+func (c *Client) CheckSecret(secret string) bool {
+	if secret == "" {
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(c.ClientSecret)) == 1 {
+		return true
+	}
+	if c.PreviousSecret == "" || time.Now().Unix() >= c.PreviousSecretExpiresAt {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(c.PreviousSecret)) == 1
+}
+
+// IsPublic returns true if the client can't keep a secret (a mobile app for
+// example), and must use PKCE instead.
+func (c *Client) IsPublic() bool {
+	return c.TokenEndpointAuthMethod == AuthMethodNone
+}
+
 // Delete is a function that unregister a client
 func (c *Client) Delete(i *instance.Instance) *ClientRegistrationError {
 	if err := couchdb.DeleteDoc(i, c); err != nil {
@@ -659,32 +790,29 @@ func (c *Client) CreateChallenge(inst *instance.Instance) (string, error) {
 	return nonce, nil
 }
 
-// AttestationRequest is what an OAuth client can send to attest that it is the
-// flagship app.
-type AttestationRequest struct {
-	Platform    string `json:"platform"`
-	Challenge   string `json:"challenge"`
-	Attestation string `json:"attestation"`
-	KeyID       []byte `json:"keyId"`
+// ClientActivity is the network information about the last refresh of the
+// token of an OAuth client.
+type ClientActivity struct {
+	IP          string `json:"ip,omitempty"`
+	UserAgent   string `json:"user_agent,omitempty"`
+	City        string `json:"city,omitempty"`
+	Subdivision string `json:"subdivision,omitempty"`
+	Country     string `json:"country,omitempty"`
 }
 
-// Attest can be used to check an attestation for certifying the app.
-func (c *Client) Attest(inst *instance.Instance, req AttestationRequest) error {
-	var err error
-	switch req.Platform {
-	case "android":
-		err = c.checkAndroidAttestation(inst, req)
-	case "ios":
-		err = c.checkAppleAttestation(inst, req)
-	default:
-		err = errors.New("invalid platform")
-	}
-	if err != nil {
-		return err
-	}
+// SameNetwork returns true if the activity comes from the given IP address,
+// and its geolocation can be kept.
+func (a *ClientActivity) SameNetwork(ip string) bool {
+	return a != nil && a.IP == ip
+}
 
-	c.CertifiedFromStore = true
-	return c.SetFlagship(inst)
+// UpdateLastActivity updates the client in CouchDB with the date and the
+// network information of the last refresh of its token.
+func (c *Client) UpdateLastActivity(inst *instance.Instance, act *ClientActivity) error {
+	c.LastRefreshedAt = time.Now()
+	c.LastActivity = act
+	c.StaleNotifiedAt = nil
+	return couchdb.UpdateDoc(inst, c)
 }
 
 // SetFlagship updates the client in CouchDB with flagship set to true.
@@ -736,7 +864,8 @@ func (c *Client) CreateJWT(i *instance.Instance, audience, scope string) (string
 			IssuedAt: jwt.NewNumericDate(time.Now()),
 			Subject:  c.CouchID,
 		},
-		Scope: scope,
+		Scope:      scope,
+		Generation: c.TokenGeneration,
 	})
 	if err != nil {
 		i.Logger().WithNamespace("oauth").
@@ -813,9 +942,79 @@ func (c *Client) ValidToken(i *instance.Instance, audience, token string) (permi
 			Errorf("Expected %s subject for %s token, but was: %s", audience, c.CouchID, claims.Subject)
 		return claims, false
 	}
+	// The registration token is kept when the tokens are revoked, as it is
+	// needed by the client to manage its registration.
+	if audience != consts.RegistrationTokenAudience && !c.CheckTokenGeneration(claims) {
+		i.Logger().WithNamespace("oauth").
+			Infof("Revoked %s token for client %s", audience, c.CouchID)
+		return claims, false
+	}
 	return claims, true
 }
 
+// ErrScopeExpansion is used when a client asks for a scope that has not been
+// granted by the user.
+var ErrScopeExpansion = errors.New("consent_required")
+
+// NarrowScope checks that the requested scope is a reduction of the granted
+// scope (from a valid refresh token), and revokes the previous tokens of the
+// client, so that it can only use the tokens for the narrowed scope. A
+// scope expansion requires the consent of the user, via the authorize page.
+func (c *Client) NarrowScope(i *instance.Instance, granted, requested string) error {
+	grantedSet, grantedOpenID, err := permission.SplitScopeString(granted)
+	if err != nil {
+		return err
+	}
+	requestedSet, _, err := permission.SplitScopeString(requested)
+	if err != nil {
+		return err
+	}
+	if !requestedSet.IsSubSetOf(grantedSet) {
+		return ErrScopeExpansion
+	}
+	if err := CheckOpenIDScopes(requested, grantedOpenID); err != nil {
+		return err
+	}
+	return c.incrementTokenGeneration(i)
+}
+
+// CheckTokenGeneration returns false if the token has been issued before the
+// last revocation of the tokens of the client.
+func (c *Client) CheckTokenGeneration(claims permission.Claims) bool {
+	return claims.Generation == c.TokenGeneration
+}
+
+// RevokeTokens invalidates all the access and refresh tokens issued to the
+// client, without deleting its registration. The client will have to go
+// through the OAuth dance again to get new tokens.
+func (c *Client) RevokeTokens(i *instance.Instance) error {
+	if err := c.incrementTokenGeneration(i); err != nil {
+		return err
+	}
+	activity.Record(i, &activity.Activity{
+		Kind:      activity.KindTokensRevoked,
+		Doctype:   consts.OAuthClients,
+		RelatedID: c.CouchID,
+		Label:     c.ClientName,
+	})
+	return nil
+}
+
+func (c *Client) incrementTokenGeneration(i *instance.Instance) error {
+	c.TokenGeneration++
+	if c.Metadata != nil {
+		c.Metadata.ChangeUpdatedAt()
+	}
+	c.ClientID = ""
+	if err := couchdb.UpdateDoc(i, c); err != nil {
+		c.TokenGeneration--
+		c.ClientID = c.CouchID
+		return err
+	}
+	c.ClientID = c.CouchID
+	return nil
+}
+
 // IsLinkedApp checks if an OAuth client has a linked app
 func IsLinkedApp(softwareID string) bool {
 	return strings.HasPrefix(softwareID, "registry://")
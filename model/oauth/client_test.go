@@ -146,6 +146,51 @@ func TestClient(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateKeepsTokenGeneration", func(t *testing.T) {
+		goodClient := &oauth.Client{
+			ClientName:   "client-revoked",
+			RedirectURIs: []string{"https://foobar"},
+			SoftwareID:   "bar",
+		}
+		require.Nil(t, goodClient.Create(testInstance))
+		goodClient, err := oauth.FindClient(testInstance, goodClient.ClientID)
+		require.NoError(t, err)
+		require.NoError(t, goodClient.RevokeTokens(testInstance))
+		assert.Equal(t, 1, goodClient.TokenGeneration)
+
+		client := goodClient.Clone().(*oauth.Client)
+		client.TokenGeneration = 0
+		require.Nil(t, client.Update(testInstance, goodClient))
+		assert.Equal(t, 1, client.TokenGeneration)
+	})
+
+	t.Run("NarrowScope", func(t *testing.T) {
+		client := &oauth.Client{
+			ClientName:   "client-narrow",
+			RedirectURIs: []string{"https://foobar"},
+			SoftwareID:   "bar",
+		}
+		require.Nil(t, client.Create(testInstance))
+		client, err := oauth.FindClient(testInstance, client.ClientID)
+		require.NoError(t, err)
+		refresh, err := client.CreateJWT(testInstance, consts.RefreshTokenAudience, "io.cozy.files io.cozy.contacts")
+		require.NoError(t, err)
+		claims, ok := client.ValidToken(testInstance, consts.RefreshTokenAudience, refresh)
+		require.True(t, ok)
+
+		err = client.NarrowScope(testInstance, claims.Scope, "io.cozy.files io.cozy.photos.albums")
+		assert.ErrorIs(t, err, oauth.ErrScopeExpansion)
+		err = client.NarrowScope(testInstance, claims.Scope, "io.cozy.files openid")
+		assert.ErrorIs(t, err, oauth.ErrScopeExpansion)
+		assert.Equal(t, 0, client.TokenGeneration)
+
+		err = client.NarrowScope(testInstance, claims.Scope, "io.cozy.files:GET")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, client.TokenGeneration)
+		_, ok = client.ValidToken(testInstance, consts.RefreshTokenAudience, refresh)
+		assert.False(t, ok)
+	})
+
 	t.Run("CreateClientWithClientsLimit", func(t *testing.T) {
 		var pending, notPending, notificationWithoutPremium, notificationWithPremium *oauth.Client
 		t.Cleanup(func() {
@@ -288,6 +333,28 @@ func TestClient(t *testing.T) {
 		assert.False(t, ok, "The token should be invalid")
 	})
 
+	t.Run("ParseJWTRevokedGeneration", func(t *testing.T) {
+		other := &oauth.Client{
+			CouchID: "my-client-id",
+		}
+		tokenString, err := other.CreateJWT(testInstance, "refresh", "foo:read")
+		assert.NoError(t, err)
+		registration, err := other.CreateJWT(testInstance, "registration", "")
+		assert.NoError(t, err)
+
+		other.TokenGeneration++
+		_, ok := other.ValidToken(testInstance, consts.RefreshTokenAudience, tokenString)
+		assert.False(t, ok, "The token should be invalid")
+		_, ok = other.ValidToken(testInstance, consts.RegistrationTokenAudience, registration)
+		assert.True(t, ok, "The registration token should still be valid")
+
+		tokenString, err = other.CreateJWT(testInstance, "refresh", "foo:read")
+		assert.NoError(t, err)
+		claims, ok := other.ValidToken(testInstance, consts.RefreshTokenAudience, tokenString)
+		assert.True(t, ok, "The token must be valid")
+		assert.Equal(t, 1, claims.Generation)
+	})
+
 	t.Run("ParseGoodSoftwareID", func(t *testing.T) {
 		goodClient := &oauth.Client{
 			ClientName:   "client-5",
@@ -385,6 +452,34 @@ func assertClientsLimitAlertMailWasNotSent(t *testing.T, instance *instance.Inst
 	assert.Len(t, jobs, 0)
 }
 
+func TestCheckSecret(t *testing.T) {
+	client := &oauth.Client{ClientSecret: "new-secret"}
+	assert.True(t, client.CheckSecret("new-secret"))
+	assert.False(t, client.CheckSecret("old-secret"))
+	assert.False(t, client.CheckSecret(""))
+
+	client.PreviousSecret = "old-secret"
+	client.PreviousSecretExpiresAt = time.Now().Add(time.Hour).Unix()
+	assert.True(t, client.CheckSecret("new-secret"))
+	assert.True(t, client.CheckSecret("old-secret"))
+
+	client.PreviousSecretExpiresAt = time.Now().Add(-time.Hour).Unix()
+	assert.False(t, client.CheckSecret("old-secret"))
+}
+
+func TestDeviceUserCode(t *testing.T) {
+	assert.Equal(t, "BCDFGHJK", oauth.NormalizeUserCode("bcdf-ghjk"))
+	assert.Equal(t, "BCDFGHJK", oauth.NormalizeUserCode(" BCDF GHJK"))
+
+	dc := &oauth.DeviceCode{UserCode: "BCDFGHJK"}
+	assert.Equal(t, "BCDF-GHJK", dc.FormattedUserCode())
+
+	dc.ExpiresAt = time.Now().Add(time.Minute).Unix()
+	assert.False(t, dc.Expired())
+	dc.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	assert.True(t, dc.Expired())
+}
+
 func assertClientsLimitAlertMailWasSent(t *testing.T, instance *instance.Instance, clientName string, clientsLimit int) string {
 	var jobs []job.Job
 	couchReq := &couchdb.FindRequest{
@@ -0,0 +1,262 @@
+package oauth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+)
+
+// DeviceCodeGrantType is the grant_type used on the token endpoint for the
+// device authorization grant (RFC 8628).
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	// DeviceCodeTTL is the lifetime of a device code.
+	DeviceCodeTTL = 10 * time.Minute
+	// DeviceCodeInterval is the minimal number of seconds that the client
+	// should wait between two polling requests.
+	DeviceCodeInterval = 5
+
+	deviceCodeLen = 32
+	// The user codes are typed by the user on another device: they are made
+	// of consonants only (no ambiguous characters, no words), like BDFG-HJKL.
+	userCodeLetters = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLen     = 8
+)
+
+// The statuses of a device code.
+const (
+	DeviceCodePending  = "pending"
+	DeviceCodeApproved = "approved"
+	DeviceCodeDenied   = "denied"
+)
+
+// The errors for the polling of the token endpoint, as defined by RFC 8628.
+var (
+	// ErrAuthorizationPending is used when the user has not yet approved or
+	// denied the request.
+	ErrAuthorizationPending = errors.New("authorization_pending")
+	// ErrSlowDown is used when the client polls too often.
+	ErrSlowDown = errors.New("slow_down")
+	// ErrAccessDenied is used when the user has denied the request.
+	ErrAccessDenied = errors.New("access_denied")
+	// ErrExpiredToken is used when the device code has expired.
+	ErrExpiredToken = errors.New("expired_token")
+)
+
+// DeviceCode is used for the device authorization grant: a client without a
+// browser (a TV, a CLI, etc.) asks for a device code, and the user approves
+// it on another device by typing the user code on the verification page.
+type DeviceCode struct {
+	Code         string `json:"_id,omitempty"`
+	CouchRev     string `json:"_rev,omitempty"`
+	UserCode     string `json:"user_code"`
+	ClientID     string `json:"client_id"`
+	Scope        string `json:"scope"`
+	Status       string `json:"status"`
+	IssuedAt     int64  `json:"issued_at"`
+	ExpiresAt    int64  `json:"expires_at"`
+	Interval     int    `json:"interval"`
+	LastPolledAt int64  `json:"last_polled_at,omitempty"`
+}
+
+// ID returns the device code qualified identifier
+func (dc *DeviceCode) ID() string { return dc.Code }
+
+// Rev returns the device code revision
+func (dc *DeviceCode) Rev() string { return dc.CouchRev }
+
+// DocType returns the device code document type
+func (dc *DeviceCode) DocType() string { return consts.OAuthDeviceCodes }
+
+// Clone implements couchdb.Doc
+func (dc *DeviceCode) Clone() couchdb.Doc { cloned := *dc; return &cloned }
+
+// SetID changes the device code qualified identifier
+func (dc *DeviceCode) SetID(id string) { dc.Code = id }
+
+// SetRev changes the device code revision
+func (dc *DeviceCode) SetRev(rev string) { dc.CouchRev = rev }
+
+// Expired returns true if the device code can no longer be used.
+func (dc *DeviceCode) Expired() bool {
+	return crypto.Timestamp() > dc.ExpiresAt
+}
+
+// FormattedUserCode returns the user code with a dash in the middle, to make
+// it easier to read and type.
+func (dc *DeviceCode) FormattedUserCode() string {
+	half := len(dc.UserCode) / 2
+	return dc.UserCode[:half] + "-" + dc.UserCode[half:]
+}
+
+// CreateDeviceCode creates a device code for the given client and scope,
+// persisted in CouchDB.
+func CreateDeviceCode(i *instance.Instance, client *Client, scope string) (*DeviceCode, error) {
+	now := crypto.Timestamp()
+	dc := &DeviceCode{
+		Code:      crypto.GenerateRandomString(deviceCodeLen),
+		UserCode:  generateUserCode(),
+		ClientID:  client.ClientID,
+		Scope:     scope,
+		Status:    DeviceCodePending,
+		IssuedAt:  now,
+		ExpiresAt: now + int64(DeviceCodeTTL.Seconds()),
+		Interval:  DeviceCodeInterval,
+	}
+	if err := couchdb.CreateNamedDocWithDB(i, dc); err != nil {
+		return nil, err
+	}
+	if err := setupDeviceCodeTrigger(i, dc.Code); err != nil {
+		i.Logger().WithNamespace("oauth").
+			Warnf("Cannot create trigger: %s", err)
+	}
+	return dc, nil
+}
+
+// setupDeviceCodeTrigger removes the device code after its expiration, in
+// case the device has stopped polling before.
+func setupDeviceCodeTrigger(inst *instance.Instance, code string) error {
+	sched := job.System()
+	msg := &CleanMessage{DeviceCode: code}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@in",
+		WorkerType: "clean-clients",
+		Arguments:  (DeviceCodeTTL + time.Minute).String(),
+	}, msg)
+	if err != nil {
+		return err
+	}
+	return sched.AddTrigger(t)
+}
+
+// CleanDeviceCode removes the device code if it has expired.
+func CleanDeviceCode(i *instance.Instance, code string) error {
+	dc := &DeviceCode{}
+	if err := couchdb.GetDoc(i, consts.OAuthDeviceCodes, code, dc); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	if !dc.Expired() {
+		return nil
+	}
+	return couchdb.DeleteDoc(i, dc)
+}
+
+// generateUserCode picks the letters with a rejection sampling: the random
+// bytes above the largest multiple of the number of letters are discarded, so
+// that all the letters have the same probability.
+func generateUserCode() string {
+	n := len(userCodeLetters)
+	limit := byte(256 / n * n)
+	code := make([]byte, 0, userCodeLen)
+	for len(code) < userCodeLen {
+		for _, b := range crypto.GenerateRandomBytes(userCodeLen) {
+			if b < limit && len(code) < userCodeLen {
+				code = append(code, userCodeLetters[int(b)%n])
+			}
+		}
+	}
+	return string(code)
+}
+
+// NormalizeUserCode removes the dashes and spaces that the user may have
+// typed, and puts the letters in upper case.
+func NormalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// FindDeviceCodeByUserCode returns the pending device code for the given
+// user code.
+func FindDeviceCodeByUserCode(i *instance.Instance, userCode string) (*DeviceCode, error) {
+	userCode = NormalizeUserCode(userCode)
+	if len(userCode) != userCodeLen {
+		return nil, ErrExpiredToken
+	}
+	var codes []*DeviceCode
+	req := &couchdb.FindRequest{
+		UseIndex: "by-user-code",
+		Selector: mango.Equal("user_code", userCode),
+		Limit:    10,
+	}
+	if err := couchdb.FindDocs(i, consts.OAuthDeviceCodes, req, &codes); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, ErrExpiredToken
+		}
+		return nil, err
+	}
+	for _, dc := range codes {
+		if dc.Status == DeviceCodePending && !dc.Expired() {
+			return dc, nil
+		}
+	}
+	return nil, ErrExpiredToken
+}
+
+// Approve is called when the user has accepted to give access to the client.
+func (dc *DeviceCode) Approve(i *instance.Instance, client *Client) error {
+	if client.Pending {
+		client.Pending = false
+		client.ClientID = ""
+		_ = couchdb.UpdateDoc(i, client)
+		client.ClientID = client.CouchID
+	}
+	dc.Status = DeviceCodeApproved
+	return couchdb.UpdateDoc(i, dc)
+}
+
+// Deny is called when the user has refused to give access to the client.
+func (dc *DeviceCode) Deny(i *instance.Instance) error {
+	dc.Status = DeviceCodeDenied
+	return couchdb.UpdateDoc(i, dc)
+}
+
+// Poll is called when the client asks for a token with the device code. It
+// returns nil if the user has approved the request, and the device code is
+// deleted, as it can be used only once. Else, it returns one of the errors
+// defined by RFC 8628.
+func (dc *DeviceCode) Poll(i *instance.Instance) error {
+	if dc.Expired() {
+		_ = couchdb.DeleteDoc(i, dc)
+		return ErrExpiredToken
+	}
+	switch dc.Status {
+	case DeviceCodeApproved:
+		return couchdb.DeleteDoc(i, dc)
+	case DeviceCodeDenied:
+		_ = couchdb.DeleteDoc(i, dc)
+		return ErrAccessDenied
+	}
+
+	now := crypto.Timestamp()
+	tooSoon := now-dc.LastPolledAt < int64(dc.Interval)
+	dc.LastPolledAt = now
+	if tooSoon {
+		// The client must increase its polling interval by 5 seconds
+		dc.Interval += DeviceCodeInterval
+	}
+	if err := couchdb.UpdateDoc(i, dc); err != nil {
+		return err
+	}
+	if tooSoon {
+		return ErrSlowDown
+	}
+	return ErrAuthorizationPending
+}
+
+var _ couchdb.Doc = &DeviceCode{}
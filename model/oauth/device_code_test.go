@@ -0,0 +1,29 @@
+package oauth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateUserCode(t *testing.T) {
+	counts := make(map[rune]int)
+	for i := 0; i < 1000; i++ {
+		code := generateUserCode()
+		assert.Len(t, code, userCodeLen)
+		for _, r := range code {
+			assert.True(t, strings.ContainsRune(userCodeLetters, r))
+			counts[r]++
+		}
+	}
+	// All the letters are used
+	assert.Len(t, counts, len(userCodeLetters))
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode("bcdf-ghjk"))
+	assert.Equal(t, "BCDFGHJK", NormalizeUserCode("BCDF GHJK"))
+	dc := &DeviceCode{UserCode: "BCDFGHJK"}
+	assert.Equal(t, "BCDF-GHJK", dc.FormattedUserCode())
+}
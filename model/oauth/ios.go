@@ -70,23 +70,24 @@ const (
 // checkAppleAttestation will check an attestation made by the DeviceCheck API.
 // Cf https://developer.apple.com/documentation/devicecheck/validating_apps_that_connect_to_your_server#3576643
 func (c *Client) checkAppleAttestation(inst *instance.Instance, req AttestationRequest) error {
-	store := GetStore()
-	if ok := store.CheckAndClearChallenge(inst, c.ID(), req.Challenge); !ok {
-		return errors.New("invalid challenge")
-	}
-
 	obj, err := parseAppleAttestation(req.Attestation)
 	if err != nil {
 		return fmt.Errorf("cannot parse attestation: %s", err)
 	}
 	inst.Logger().Debugf("checkAppleAttestation claims = %#v", obj)
 
-	if err := obj.checkCertificate(req.Challenge, req.KeyID); err != nil {
+	pubKey, err := obj.checkCertificate(req.Challenge, req.KeyID)
+	if err != nil {
 		return err
 	}
 	if err := obj.checkAttestationData(req.KeyID); err != nil {
 		return err
 	}
+
+	// The public key is kept to verify the assertions sent later by the app
+	c.AttestationKeyID = req.KeyID
+	c.AttestationPublicKey = pubKey
+	c.AttestationCounter = 0
 	return nil
 }
 
@@ -141,17 +142,17 @@ func parseAuthData(raw []byte) (authenticatorData, error) {
 	return data, nil
 }
 
-func (obj *appleAttestationObject) checkCertificate(challenge string, keyID []byte) error {
+func (obj *appleAttestationObject) checkCertificate(challenge string, keyID []byte) ([]byte, error) {
 	// 1. Verify that the x5c array contains the intermediate and leaf
 	// certificates for App Attest, starting from the credential certificate
 	// stored in the first data buffer in the array (credcert). Verify the
 	// validity of the certificates using Apple’s root certificate.
 	credCert, opts, err := obj.setupAppleCertificates()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if _, err := credCert.Verify(*opts); err != nil {
-		return err
+		return nil, err
 	}
 
 	// 2. Create clientDataHash as the SHA256 hash of the one-time challenge
@@ -169,24 +170,24 @@ func (obj *appleAttestationObject) checkCertificate(challenge string, keyID []by
 	// Verify that the string equals nonce.
 	extracted, err := extractNonceFromCertificate(credCert)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !bytes.Equal(nonce[:], extracted) {
-		return errors.New("invalid nonce")
+		return nil, errors.New("invalid nonce")
 	}
 
 	// 5. Create the SHA256 hash of the public key in credCert, and verify that
 	// it matches the key identifier from your app.
 	pub, ok := credCert.PublicKey.(*ecdsa.PublicKey)
 	if !ok {
-		return errors.New("invalid algorithm for credCert")
+		return nil, errors.New("invalid algorithm for credCert")
 	}
 	pubKey := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
 	pubKeyHash := sha256.Sum256(pubKey)
 	if !bytes.Equal(pubKeyHash[:], keyID) {
-		return errors.New("invalid keyId")
+		return nil, errors.New("invalid keyId")
 	}
-	return nil
+	return pubKey, nil
 }
 
 func (obj *appleAttestationObject) setupAppleCertificates() (*x509.Certificate, *x509.VerifyOptions, error) {
@@ -0,0 +1,219 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenTTL is the validity duration of the ID tokens.
+const IDTokenTTL = time.Hour
+
+// oidcKeySize is the size in bits of the RSA keys used to sign the ID tokens.
+const oidcKeySize = 2048
+
+// UserInfo are the claims about the user of the instance. The name is given
+// for the profile scope, and the email for the email scope.
+type UserInfo struct {
+	Subject           string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	Website           string `json:"website,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+// IDTokenClaims are the claims of an ID token of OpenID Connect.
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	Website           string `json:"website,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+// JWK is a public key in the JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// HasOpenIDScope returns true if the scope string asks for an ID token.
+func HasOpenIDScope(scope string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == permission.ScopeOpenID {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenIDScopes returns the scopes of OpenID Connect in the scope string.
+func OpenIDScopes(scope string) []string {
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if permission.IsOpenIDScope(s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// CheckOpenIDScopes returns ErrScopeExpansion if the scope string has a scope
+// of OpenID Connect that is not in the granted ones. These scopes are not
+// rules of a permission set, and must be checked separately.
+func CheckOpenIDScopes(scope string, granted []string) error {
+	for _, s := range OpenIDScopes(scope) {
+		found := false
+		for _, g := range granted {
+			if s == g {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrScopeExpansion
+		}
+	}
+	return nil
+}
+
+// Issuer returns the issuer of the ID tokens for the instance: it is also the
+// base URL of the discovery document.
+func Issuer(inst *instance.Instance) string {
+	return inst.PageURL("", nil)
+}
+
+// GetUserInfo returns the claims about the user that can be given for the
+// OpenID Connect scopes in the scope string.
+func GetUserInfo(inst *instance.Instance, scope string) UserInfo {
+	info := UserInfo{Subject: inst.ID()}
+	for _, s := range strings.Fields(scope) {
+		switch s {
+		case permission.ScopeProfile:
+			info.Name, _ = inst.SettingsPublicName()
+			info.PreferredUsername = inst.Domain
+			info.Locale = inst.Locale
+			info.Website = inst.PageURL("/", nil)
+		case permission.ScopeEmail:
+			info.Email, _ = inst.SettingsEMail()
+		}
+	}
+	return info
+}
+
+// CreateIDToken creates an ID token for the client, signed with the key of
+// the instance. The nonce is the one sent by the client in the authorization
+// request, if any.
+func (c *Client) CreateIDToken(inst *instance.Instance, scope, nonce string) (string, error) {
+	key, err := oidcSigningKey(inst)
+	if err != nil {
+		return "", err
+	}
+	info := GetUserInfo(inst, scope)
+	now := time.Now()
+	claims := IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer(inst),
+			Subject:   info.Subject,
+			Audience:  jwt.ClaimStrings{c.CouchID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(IDTokenTTL)),
+		},
+		Nonce:             nonce,
+		Name:              info.Name,
+		PreferredUsername: info.PreferredUsername,
+		Locale:            info.Locale,
+		Website:           info.Website,
+		Email:             info.Email,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID(&key.PublicKey)
+	return token.SignedString(key)
+}
+
+// GetJWKS returns the public keys used to sign the ID tokens of the instance,
+// in the JSON Web Key Set format.
+func GetJWKS(inst *instance.Instance) ([]JWK, error) {
+	key, err := oidcSigningKey(inst)
+	if err != nil {
+		return nil, err
+	}
+	pub := &key.PublicKey
+	return []JWK{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		KeyID:     keyID(pub),
+		Modulus:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}, nil
+}
+
+func keyID(pub *rsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
+
+// oidcSigningKey returns the private key of the instance for the ID tokens,
+// and generates it if the instance has none.
+func oidcSigningKey(inst *instance.Instance) (*rsa.PrivateKey, error) {
+	if len(inst.OIDCKey) == 0 {
+		mu := config.Lock().ReadWrite(inst, "oidc-key")
+		if err := mu.Lock(); err != nil {
+			return nil, err
+		}
+		defer mu.Unlock()
+
+		// Another request may have generated the key while we were waiting
+		fresh, err := instance.GetFromCouch(inst.Domain)
+		if err != nil {
+			return nil, err
+		}
+		if len(fresh.OIDCKey) == 0 {
+			key, err := rsa.GenerateKey(rand.Reader, oidcKeySize)
+			if err != nil {
+				return nil, err
+			}
+			fresh.OIDCKey, err = x509.MarshalPKCS8PrivateKey(key)
+			if err != nil {
+				return nil, err
+			}
+			if err := instance.Update(fresh); err != nil {
+				return nil, err
+			}
+		}
+		inst.OIDCKey = fresh.OIDCKey
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(inst.OIDCKey)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the OIDC key of the instance is not a RSA key")
+	}
+	return key, nil
+}
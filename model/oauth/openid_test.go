@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasOpenIDScope(t *testing.T) {
+	assert.True(t, HasOpenIDScope("openid profile"))
+	assert.True(t, HasOpenIDScope("io.cozy.files openid"))
+	assert.False(t, HasOpenIDScope("profile email"))
+	assert.False(t, HasOpenIDScope("io.cozy.files"))
+}
+
+func TestCheckOpenIDScopes(t *testing.T) {
+	assert.Equal(t, []string{"openid", "email"}, OpenIDScopes("openid io.cozy.files email"))
+	assert.NoError(t, CheckOpenIDScopes("io.cozy.files", nil))
+	assert.NoError(t, CheckOpenIDScopes("openid io.cozy.files", []string{"openid", "profile"}))
+	assert.Equal(t, ErrScopeExpansion, CheckOpenIDScopes("openid email", []string{"openid"}))
+	assert.Equal(t, ErrScopeExpansion, CheckOpenIDScopes("openid", nil))
+}
+
+func TestNarrowScopeOpenID(t *testing.T) {
+	inst := &instance.Instance{Domain: "alice.cozy.localhost"}
+	client := &Client{CouchID: "client-id"}
+
+	// The scopes of OpenID Connect can't be added without a new consent
+	err := client.NarrowScope(inst, "io.cozy.files", "io.cozy.files openid")
+	assert.Equal(t, ErrScopeExpansion, err)
+	err = client.NarrowScope(inst, "openid io.cozy.files", "openid profile io.cozy.files")
+	assert.Equal(t, ErrScopeExpansion, err)
+	err = client.NarrowScope(inst, "io.cozy.files", "email")
+	assert.Equal(t, ErrScopeExpansion, err)
+	assert.Equal(t, 0, client.TokenGeneration)
+}
+
+func TestCreateIDToken(t *testing.T) {
+	config.UseTestFile(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	inst := &instance.Instance{
+		DocID:   "instance-id",
+		Domain:  "alice.cozy.localhost",
+		Locale:  "fr",
+		OIDCKey: der,
+	}
+	client := &Client{CouchID: "client-id"}
+
+	token, err := client.CreateIDToken(inst, "openid", "n-0S6_WzA2Mj")
+	require.NoError(t, err)
+
+	keys, err := GetJWKS(inst)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	n, err := base64.RawURLEncoding.DecodeString(keys[0].Modulus)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(keys[0].Exponent)
+	require.NoError(t, err)
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	claims := IDTokenClaims{}
+	parsed, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, keys[0].KeyID, token.Header["kid"])
+		return pub, nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+	assert.Equal(t, Issuer(inst), claims.Issuer)
+	assert.Equal(t, "instance-id", claims.Subject)
+	assert.Equal(t, jwt.ClaimStrings{"client-id"}, claims.Audience)
+	assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	assert.Empty(t, claims.Name)
+	assert.Empty(t, claims.Email)
+}
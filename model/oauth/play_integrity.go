@@ -0,0 +1,323 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	jwt "github.com/golang-jwt/jwt/v5"
+	googlejwt "golang.org/x/oauth2/jwt"
+)
+
+// playIntegrityScope is the OAuth scope for the Play Integrity API.
+const playIntegrityScope = "https://www.googleapis.com/auth/playintegrity"
+
+// playIntegrityURL is the URL of the decodeIntegrityToken API.
+const playIntegrityURL = "https://playintegrity.googleapis.com/v1/%s:decodeIntegrityToken"
+
+// googleTokenURL is the default URL for getting an OAuth token for a Google
+// service account.
+const googleTokenURL = "https://oauth2.googleapis.com/token"
+
+// playIntegrityMaxAge is the maximal age of a verdict.
+const playIntegrityMaxAge = 10 * time.Minute
+
+// playIntegrityVerdict is the payload of a Play Integrity token.
+// Cf https://developer.android.com/google/play/integrity/verdicts
+type playIntegrityVerdict struct {
+	RequestDetails struct {
+		RequestPackageName string `json:"requestPackageName"`
+		Nonce              string `json:"nonce"`
+		TimestampMillis    string `json:"timestampMillis"`
+	} `json:"requestDetails"`
+	AppIntegrity struct {
+		AppRecognitionVerdict   string   `json:"appRecognitionVerdict"`
+		PackageName             string   `json:"packageName"`
+		CertificateSha256Digest []string `json:"certificateSha256Digest"`
+	} `json:"appIntegrity"`
+	DeviceIntegrity struct {
+		DeviceRecognitionVerdict []string `json:"deviceRecognitionVerdict"`
+	} `json:"deviceIntegrity"`
+}
+
+// checkPlayIntegrityAttestation will check a verdict token from the Play
+// Integrity API.
+// Cf https://developer.android.com/google/play/integrity/classic
+func (c *Client) checkPlayIntegrityAttestation(inst *instance.Instance, req AttestationRequest) error {
+	cfg := config.GetConfig().Flagship.PlayIntegrity
+	var verdict *playIntegrityVerdict
+	var err error
+	switch {
+	case cfg.DecryptionKey != "" && cfg.VerificationKey != "":
+		verdict, err = decodePlayIntegrityLocally(cfg, req.Attestation)
+	case cfg.CredentialsFile != "":
+		verdict, err = decodePlayIntegrityWithGoogle(cfg, req.Attestation)
+	default:
+		err = errors.New("play integrity is not configured")
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decode attestation: %w", err)
+	}
+	inst.Logger().Debugf("checkPlayIntegrityAttestation verdict = %#v", verdict)
+	return verdict.check(req.Challenge)
+}
+
+func (v *playIntegrityVerdict) check(challenge string) error {
+	nonce := strings.TrimRight(v.RequestDetails.Nonce, "=")
+	if nonce == "" {
+		return errors.New("missing nonce")
+	}
+	if nonce != strings.TrimRight(challenge, "=") {
+		return errors.New("invalid nonce")
+	}
+
+	millis, err := parseMillis(v.RequestDetails.TimestampMillis)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if time.Since(time.UnixMilli(millis)) > playIntegrityMaxAge {
+		return errors.New("the verdict is too old")
+	}
+
+	if v.AppIntegrity.AppRecognitionVerdict != "PLAY_RECOGNIZED" {
+		return fmt.Errorf("app not recognized: %s", v.AppIntegrity.AppRecognitionVerdict)
+	}
+	if err := checkPackageName(jwt.MapClaims{
+		"apkPackageName": v.AppIntegrity.PackageName,
+	}); err != nil {
+		return err
+	}
+	if !checkPlayIntegrityDigests(v.AppIntegrity.CertificateSha256Digest) {
+		return errors.New("invalid certificate digest")
+	}
+
+	for _, verdict := range v.DeviceIntegrity.DeviceRecognitionVerdict {
+		if verdict == "MEETS_DEVICE_INTEGRITY" || verdict == "MEETS_STRONG_INTEGRITY" {
+			return nil
+		}
+	}
+	return errors.New("the device doesn't meet the integrity requirements")
+}
+
+// checkPlayIntegrityDigests compares the digests of the verdict (base64url)
+// with the digests from the config (standard base64).
+func checkPlayIntegrityDigests(digests []string) bool {
+	expected := config.GetConfig().Flagship.APKCertificateDigests
+	for _, digest := range digests {
+		got, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(digest, "="))
+		if err != nil {
+			continue
+		}
+		for _, exp := range expected {
+			want, err := base64.StdEncoding.DecodeString(exp)
+			if err == nil && subtle.ConstantTimeCompare(got, want) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseMillis(s string) (int64, error) {
+	var millis int64
+	if err := json.Unmarshal([]byte(s), &millis); err != nil {
+		return 0, err
+	}
+	return millis, nil
+}
+
+// decodePlayIntegrityLocally decrypts the token (JWE with A256KW and
+// A256GCM), and verifies the signature of the nested JWS (ES256).
+func decodePlayIntegrityLocally(cfg config.PlayIntegrity, token string) (*playIntegrityVerdict, error) {
+	decryptionKey, err := base64.StdEncoding.DecodeString(cfg.DecryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid decryption key: %w", err)
+	}
+	rawKey, err := base64.StdEncoding.DecodeString(cfg.VerificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verification key: %w", err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(rawKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verification key: %w", err)
+	}
+	verificationKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid verification key: not an EC key")
+	}
+
+	jws, err := decryptJWE(decryptionKey, token)
+	if err != nil {
+		return nil, err
+	}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}))
+	parsed, err := parser.ParseWithClaims(string(jws), &playIntegrityClaims{}, func(*jwt.Token) (interface{}, error) {
+		return verificationKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &parsed.Claims.(*playIntegrityClaims).playIntegrityVerdict, nil
+}
+
+// playIntegrityClaims is used to parse the verdict with the jwt library: the
+// payload has no registered claims, and the timestamp is checked later.
+type playIntegrityClaims struct {
+	playIntegrityVerdict
+	jwt.RegisteredClaims
+}
+
+// decryptJWE decrypts a JWE in compact serialization, with the A256KW key
+// management algorithm and the A256GCM content encryption.
+func decryptJWE(kek []byte, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, errors.New("invalid JWE")
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("invalid JWE header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Enc string `json:"enc"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, errors.New("invalid JWE header")
+	}
+	if header.Alg != "A256KW" || header.Enc != "A256GCM" {
+		return nil, fmt.Errorf("unsupported JWE algorithms: %s %s", header.Alg, header.Enc)
+	}
+
+	var chunks [4][]byte
+	for i := range chunks {
+		if chunks[i], err = base64.RawURLEncoding.DecodeString(parts[i+1]); err != nil {
+			return nil, errors.New("invalid JWE encoding")
+		}
+	}
+	wrapped, iv, ciphertext, tag := chunks[0], chunks[1], chunks[2], chunks[3]
+
+	cek, err := aesKeyUnwrap(kek, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	sealed := append(ciphertext, tag...)
+	return gcm.Open(nil, iv, sealed, []byte(parts[0]))
+}
+
+// aesKeyUnwrap implements the AES key unwrap algorithm of RFC 3394.
+func aesKeyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, errors.New("invalid wrapped key")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	r := make([]byte, n*8)
+	copy(r, wrapped[8:])
+
+	buf := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(a)^t)
+			copy(buf[8:], r[(i-1)*8:i*8])
+			block.Decrypt(buf, buf)
+			copy(a, buf[:8])
+			copy(r[(i-1)*8:i*8], buf[8:])
+		}
+	}
+
+	iv := []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+	if subtle.ConstantTimeCompare(a, iv) != 1 {
+		return nil, errors.New("invalid wrapped key")
+	}
+	return r, nil
+}
+
+// decodePlayIntegrityWithGoogle uses the decodeIntegrityToken API of Google,
+// with the credentials of a service account. The package name is needed in
+// the URL, so the configured package names are tried in turn.
+func decodePlayIntegrityWithGoogle(cfg config.PlayIntegrity, token string) (*playIntegrityVerdict, error) {
+	credentials, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var account struct {
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("invalid credentials file: %w", err)
+	}
+	jwtConfig := &googlejwt.Config{
+		Email:        account.ClientEmail,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+		Scopes:       []string{playIntegrityScope},
+		TokenURL:     account.TokenURI,
+	}
+	if jwtConfig.TokenURL == "" {
+		jwtConfig.TokenURL = googleTokenURL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := jwtConfig.Client(ctx)
+
+	body, err := json.Marshal(map[string]string{"integrity_token": token})
+	if err != nil {
+		return nil, err
+	}
+	err = errors.New("no package name")
+	for _, name := range config.GetConfig().Flagship.APKPackageNames {
+		u := fmt.Sprintf(playIntegrityURL, url.PathEscape(name))
+		var res *http.Response
+		res, err = client.Post(u, "application/json", bytes.NewReader(body))
+		if err != nil {
+			continue
+		}
+		var out struct {
+			Payload playIntegrityVerdict `json:"tokenPayloadExternal"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&out)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			err = fmt.Errorf("unexpected status code: %d", res.StatusCode)
+			continue
+		}
+		if err == nil {
+			return &out.Payload, nil
+		}
+	}
+	return nil, err
+}
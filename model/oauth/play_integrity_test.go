@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayIntegrityLocalVerdict(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Flagship.APKPackageNames = []string{"io.cozy.flagship.mobile"}
+	conf.Flagship.APKCertificateDigests = []string{"xNnH7T1BSDh6erMzNysfakBVLLacbSbOMxVk8jEPgdU="}
+
+	kek := make([]byte, 32)
+	_, err := rand.Read(kek)
+	require.NoError(t, err)
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+	require.NoError(t, err)
+	cfg := config.PlayIntegrity{
+		DecryptionKey:   base64.StdEncoding.EncodeToString(kek),
+		VerificationKey: base64.StdEncoding.EncodeToString(pub),
+	}
+
+	challenge := "Zm9vYmFyYmF6cXV4"
+	payload := fmt.Sprintf(`{
+		"requestDetails": {"requestPackageName": "io.cozy.flagship.mobile", "nonce": %q, "timestampMillis": "%d"},
+		"appIntegrity": {"appRecognitionVerdict": "PLAY_RECOGNIZED", "packageName": "io.cozy.flagship.mobile",
+			"certificateSha256Digest": ["xNnH7T1BSDh6erMzNysfakBVLLacbSbOMxVk8jEPgdU"]},
+		"deviceIntegrity": {"deviceRecognitionVerdict": ["MEETS_DEVICE_INTEGRITY"]}
+	}`, challenge, time.Now().UnixMilli())
+	token := encryptPlayIntegrityToken(t, kek, signingKey, payload)
+
+	verdict, err := decodePlayIntegrityLocally(cfg, token)
+	require.NoError(t, err)
+	assert.Equal(t, "io.cozy.flagship.mobile", verdict.AppIntegrity.PackageName)
+	assert.NoError(t, verdict.check(challenge))
+	assert.Error(t, verdict.check("another-challenge"))
+
+	verdict.DeviceIntegrity.DeviceRecognitionVerdict = []string{"MEETS_BASIC_INTEGRITY"}
+	assert.Error(t, verdict.check(challenge))
+
+	parts := strings.Split(token, ".")
+	parts[3] = base64.RawURLEncoding.EncodeToString([]byte("tampered"))
+	_, err = decodePlayIntegrityLocally(cfg, strings.Join(parts, "."))
+	assert.Error(t, err)
+}
+
+func encryptPlayIntegrityToken(t *testing.T, kek []byte, key *ecdsa.PrivateKey, payload string) string {
+	jws, err := jwt.NewWithClaims(jwt.SigningMethodES256, rawClaims(payload)).SignedString(key)
+	require.NoError(t, err)
+
+	cek := make([]byte, 32)
+	iv := make([]byte, 12)
+	_, _ = rand.Read(cek)
+	_, _ = rand.Read(iv)
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"A256KW","enc":"A256GCM"}`))
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	sealed := gcm.Seal(nil, iv, []byte(jws), []byte(header))
+	ciphertext, tag := sealed[:len(sealed)-16], sealed[len(sealed)-16:]
+
+	enc := base64.RawURLEncoding.EncodeToString
+	return strings.Join([]string{header, enc(aesKeyWrap(t, kek, cek)), enc(iv), enc(ciphertext), enc(tag)}, ".")
+}
+
+// rawClaims is used to sign a JSON payload as is.
+type rawClaims string
+
+func (r rawClaims) MarshalJSON() ([]byte, error)                 { return []byte(r), nil }
+func (r rawClaims) GetExpirationTime() (*jwt.NumericDate, error) { return nil, nil }
+func (r rawClaims) GetIssuedAt() (*jwt.NumericDate, error)       { return nil, nil }
+func (r rawClaims) GetNotBefore() (*jwt.NumericDate, error)      { return nil, nil }
+func (r rawClaims) GetIssuer() (string, error)                   { return "", nil }
+func (r rawClaims) GetSubject() (string, error)                  { return "", nil }
+func (r rawClaims) GetAudience() (jwt.ClaimStrings, error)       { return nil, nil }
+
+// aesKeyWrap implements the AES key wrap algorithm of RFC 3394.
+func aesKeyWrap(t *testing.T, kek, key []byte) []byte {
+	block, err := aes.NewCipher(kek)
+	require.NoError(t, err)
+	n := len(key) / 8
+	a := []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+	r := make([]byte, len(key))
+	copy(r, key)
+	buf := make([]byte, 16)
+	for j := 0; j <= 5; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf[:8], a)
+			copy(buf[8:], r[(i-1)*8:i*8])
+			block.Encrypt(buf, buf)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(buf[:8])^uint64(n*j+i))
+			copy(r[(i-1)*8:i*8], buf[8:])
+		}
+	}
+	return append(a, r...)
+}
@@ -0,0 +1,126 @@
+package oauth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/limits"
+)
+
+// UnlimitedAPIQuota can be used as the API quota of a client to disable the
+// quota for it, even if there is a default quota in the context.
+const UnlimitedAPIQuota = -1
+
+// ErrAPIQuotaExceeded is used when an OAuth client has made more requests
+// than its daily quota.
+var ErrAPIQuotaExceeded = errors.New("The API quota of the client has been exceeded")
+
+// APIUsage is the usage of the API by an OAuth client for the current day.
+type APIUsage struct {
+	// Limit is the number of requests allowed per day (-1 if there is no
+	// limit).
+	Limit int64 `json:"limit"`
+	// Used is the number of requests made since the beginning of the day.
+	Used int64 `json:"used"`
+	// Remaining is the number of requests that can still be made today (-1 if
+	// there is no limit).
+	Remaining int64 `json:"remaining"`
+	// ResetAt is when the counter will be reset (midnight UTC).
+	ResetAt time.Time `json:"reset_at"`
+}
+
+// Unlimited returns true if the client has no quota.
+func (u *APIUsage) Unlimited() bool {
+	return u.Limit < 0
+}
+
+// DailyAPIQuota returns the number of requests per day that the client can
+// make on the API of the instance, or -1 if there is no limit. The quota of
+// the client has the priority over the default quota of the context
+// (oauth_api_quota), and the flagship app and the clients for the sharings
+// have no quota.
+func (c *Client) DailyAPIQuota(inst *instance.Instance) int64 {
+	if c.Flagship || c.ClientKind == "sharing" {
+		return UnlimitedAPIQuota
+	}
+	if c.APIQuota != 0 {
+		if c.APIQuota < 0 {
+			return UnlimitedAPIQuota
+		}
+		return c.APIQuota
+	}
+	if settings, ok := inst.SettingsContext(); ok {
+		switch quota := settings["oauth_api_quota"].(type) {
+		case int:
+			if quota > 0 {
+				return int64(quota)
+			}
+		case float64:
+			if quota > 0 {
+				return int64(quota)
+			}
+		}
+	}
+	return UnlimitedAPIQuota
+}
+
+// CountAPIRequest increments the counter of the requests made by the client
+// for the current day, and returns the usage. ErrAPIQuotaExceeded is returned
+// with the usage if the quota has been exceeded.
+func (c *Client) CountAPIRequest(inst *instance.Instance) (*APIUsage, error) {
+	usage := newAPIUsage(c.DailyAPIQuota(inst))
+	if usage.Unlimited() {
+		return usage, nil
+	}
+	used, err := config.GetRateLimiter().IncrementKey(apiQuotaKey(inst, c, usage.ResetAt), limits.OAuthClientAPIType)
+	if err != nil {
+		return nil, err
+	}
+	usage.setUsed(used)
+	if used > usage.Limit {
+		return usage, ErrAPIQuotaExceeded
+	}
+	return usage, nil
+}
+
+// GetAPIUsage returns the usage of the API by the client for the current day.
+func (c *Client) GetAPIUsage(inst *instance.Instance) (*APIUsage, error) {
+	usage := newAPIUsage(c.DailyAPIQuota(inst))
+	key := apiQuotaKey(inst, c, usage.ResetAt)
+	used, err := config.GetRateLimiter().GetCountKey(key, limits.OAuthClientAPIType)
+	if err != nil {
+		return nil, err
+	}
+	usage.setUsed(used)
+	return usage, nil
+}
+
+func newAPIUsage(limit int64) *APIUsage {
+	now := time.Now().UTC()
+	year, month, day := now.Date()
+	return &APIUsage{
+		Limit:     limit,
+		Remaining: limit,
+		ResetAt:   time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (u *APIUsage) setUsed(used int64) {
+	u.Used = used
+	if u.Unlimited() {
+		return
+	}
+	u.Remaining = u.Limit - used
+	if u.Remaining < 0 {
+		u.Remaining = 0
+	}
+}
+
+// apiQuotaKey returns the key of the counter, with the day, so that the
+// counter is reset at midnight.
+func apiQuotaKey(inst *instance.Instance, c *Client, resetAt time.Time) string {
+	day := resetAt.Add(-24 * time.Hour).Format("20060102")
+	return inst.Domain + ":" + c.ID() + ":" + day
+}
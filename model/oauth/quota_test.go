@@ -0,0 +1,64 @@
+package oauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyAPIQuota(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Contexts = map[string]interface{}{
+		"opened": map[string]interface{}{"oauth_api_quota": 1000},
+	}
+	inst := &instance.Instance{Domain: "quota.example.net", ContextName: "opened"}
+
+	client := &Client{CouchID: "client1", ClientKind: "web"}
+	assert.EqualValues(t, 1000, client.DailyAPIQuota(inst))
+	client.APIQuota = 50
+	assert.EqualValues(t, 50, client.DailyAPIQuota(inst))
+	client.APIQuota = UnlimitedAPIQuota
+	assert.EqualValues(t, UnlimitedAPIQuota, client.DailyAPIQuota(inst))
+
+	flagship := &Client{CouchID: "client2", Flagship: true}
+	assert.EqualValues(t, UnlimitedAPIQuota, flagship.DailyAPIQuota(inst))
+	sharing := &Client{CouchID: "client3", ClientKind: "sharing"}
+	assert.EqualValues(t, UnlimitedAPIQuota, sharing.DailyAPIQuota(inst))
+
+	other := &instance.Instance{Domain: "other.example.net", ContextName: "default"}
+	assert.EqualValues(t, UnlimitedAPIQuota, (&Client{CouchID: "client4"}).DailyAPIQuota(other))
+}
+
+func TestCountAPIRequest(t *testing.T) {
+	config.UseTestFile(t)
+	inst := &instance.Instance{Domain: "count-quota.example.net"}
+	client := &Client{CouchID: "counted-client", APIQuota: 2}
+
+	usage, err := client.CountAPIRequest(inst)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, usage.Limit)
+	assert.EqualValues(t, 1, usage.Used)
+	assert.EqualValues(t, 1, usage.Remaining)
+	assert.True(t, usage.ResetAt.After(time.Now()))
+	assert.True(t, usage.ResetAt.Before(time.Now().Add(24*time.Hour)))
+
+	_, err = client.CountAPIRequest(inst)
+	require.NoError(t, err)
+	usage, err = client.CountAPIRequest(inst)
+	assert.ErrorIs(t, err, ErrAPIQuotaExceeded)
+	assert.EqualValues(t, 0, usage.Remaining)
+
+	usage, err = client.GetAPIUsage(inst)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, usage.Used)
+
+	unlimited := &Client{CouchID: "unlimited-client", Flagship: true}
+	usage, err = unlimited.CountAPIRequest(inst)
+	require.NoError(t, err)
+	assert.True(t, usage.Unlimited())
+}
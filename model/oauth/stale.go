@@ -0,0 +1,192 @@
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/emailer"
+	"github.com/cozy/cozy-stack/pkg/i18n"
+	"github.com/justincampbell/bigduration"
+)
+
+// CleanStaleClientsWorkerType is the type of the worker that removes the
+// clients that have not been used for a long time.
+const CleanStaleClientsWorkerType = "clean-stale-clients"
+
+// staleClientKinds are the kinds of the clients that can be stale: the
+// devices of the user. The other clients, like the ones used for the
+// sharings, are never removed by the clean-stale-clients worker.
+var staleClientKinds = map[string]struct{}{
+	"mobile":  {},
+	"browser": {},
+	"desktop": {},
+}
+
+// StaleClientsReport is what has been done by the clean-stale-clients worker.
+type StaleClientsReport struct {
+	Notified []string `json:"notified,omitempty"`
+	Deleted  []string `json:"deleted,omitempty"`
+}
+
+// CleanStaleClientsAfter returns the delay after which a client that has not
+// refreshed its token is considered as stale, for the given context. The
+// boolean is false if the stale clients are not cleaned for this context.
+func CleanStaleClientsAfter(contextName string) (time.Duration, bool) {
+	cfg := config.GetConfig().OAuth.CleanStaleClientsAfter
+	after, ok := cfg[contextName]
+	if !ok {
+		after, ok = cfg[config.DefaultInstanceContext]
+	}
+	if !ok || after == "" {
+		return 0, false
+	}
+	delay, err := bigduration.ParseDuration(after)
+	if err != nil || delay <= 0 {
+		return 0, false
+	}
+	return delay, true
+}
+
+// LastUsedAt returns the date of the last refresh of the token of the client,
+// or of its creation if the token has never been refreshed.
+func (c *Client) LastUsedAt() time.Time {
+	var last time.Time
+	switch at := c.LastRefreshedAt.(type) {
+	case time.Time:
+		last = at
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, at); err == nil {
+			last = t
+		}
+	}
+	if last.IsZero() && c.Metadata != nil {
+		last = c.Metadata.CreatedAt
+	}
+	return last
+}
+
+// IsStale returns true if the client has not been used since the given delay.
+func (c *Client) IsStale(after time.Duration, now time.Time) bool {
+	if _, ok := staleClientKinds[c.ClientKind]; !ok || c.Pending {
+		return false
+	}
+	last := c.LastUsedAt()
+	return !last.IsZero() && now.Sub(last) >= after
+}
+
+// CleanStaleClients looks for the clients that have not been used for a long
+// time. The user is warned by mail when a client becomes stale, and the
+// client is deleted if it is still unused after the notice delay.
+func CleanStaleClients(inst *instance.Instance) (*StaleClientsReport, error) {
+	report := &StaleClientsReport{}
+	after, ok := CleanStaleClientsAfter(inst.ContextName)
+	if !ok {
+		return report, nil
+	}
+	notice := config.GetConfig().OAuth.StaleClientsNotice
+	now := time.Now()
+
+	// The clients are listed before being modified, as the pagination of
+	// _all_docs would skip some documents if they are deleted in the loop.
+	var clients []*Client
+	err := couchdb.ForeachDocs(inst, consts.OAuthClients, func(_ string, data json.RawMessage) error {
+		var client Client
+		if err := json.Unmarshal(data, &client); err != nil {
+			return err
+		}
+		clients = append(clients, &client)
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+
+	var names []string
+	log := inst.Logger().WithNamespace("oauth")
+	for _, client := range clients {
+		if !client.IsStale(after, now) {
+			// The client has been used again since the mail
+			if client.StaleNotifiedAt != nil {
+				client.StaleNotifiedAt = nil
+				if err := couchdb.UpdateDoc(inst, client); err != nil {
+					log.Warnf("Cannot update client %s: %s", client.ID(), err)
+				}
+			}
+			continue
+		}
+
+		if client.StaleNotifiedAt == nil {
+			notifiedAt := now
+			client.StaleNotifiedAt = &notifiedAt
+			if err := couchdb.UpdateDoc(inst, client); err != nil {
+				log.Warnf("Cannot update client %s: %s", client.ID(), err)
+				continue
+			}
+			report.Notified = append(report.Notified, client.ID())
+			names = append(names, client.ClientName)
+		} else if now.Sub(*client.StaleNotifiedAt) >= notice {
+			if err := client.Delete(inst); err != nil {
+				log.Warnf("Cannot delete client %s: %s", client.ID(), err.Error)
+				continue
+			}
+			report.Deleted = append(report.Deleted, client.ID())
+		}
+	}
+
+	if len(names) > 0 {
+		if err := sendStaleClientsMail(inst, names, now.Add(notice)); err != nil {
+			log.Warnf("Cannot send the mail for the stale clients: %s", err)
+		}
+	}
+	return report, nil
+}
+
+func sendStaleClientsMail(inst *instance.Instance, names []string, deletedAt time.Time) error {
+	devicesLink := inst.SubDomain(consts.SettingsSlug)
+	devicesLink.Fragment = "/connectedDevices"
+	layout := inst.Translate("Time Format Long")
+	return emailer.SendEmail(inst, &emailer.SendEmailCmd{
+		TemplateName: "stale_clients",
+		TemplateValues: map[string]interface{}{
+			"Clients":      names,
+			"DeletionDate": i18n.LocalizeTime(deletedAt, inst.Locale, layout),
+			"DevicesLink":  devicesLink.String(),
+		},
+	})
+}
+
+func ensureCleanStaleClientsTrigger(inst *instance.Instance) {
+	// 1. Check if we need a trigger for clean-stale-clients worker
+	if _, ok := CleanStaleClientsAfter(inst.ContextName); !ok {
+		return
+	}
+
+	// 2. Check if the trigger already exists
+	sched := job.System()
+	infos := job.TriggerInfos{
+		Type:       "@cron",
+		WorkerType: CleanStaleClientsWorkerType,
+	}
+	if sched.HasTrigger(inst, infos) {
+		return
+	}
+
+	// 3. Create the trigger
+	now := time.Now()
+	hours := (now.Hour() + 12) % 24
+	infos.Arguments = fmt.Sprintf("0 %d %d * * *", now.Minute(), hours)
+	trigger, err := job.NewTrigger(inst, infos, nil)
+	if err != nil {
+		inst.Logger().Errorf("Cannot create clean-stale-clients trigger: %s", err)
+		return
+	}
+	if err = sched.AddTrigger(trigger); err != nil {
+		inst.Logger().Errorf("Cannot create clean-stale-clients trigger: %s", err)
+	}
+}
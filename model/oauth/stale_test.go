@@ -0,0 +1,54 @@
+package oauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanStaleClientsAfter(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.OAuth.CleanStaleClientsAfter = map[string]string{
+		"default":  "1Y",
+		"disabled": "",
+	}
+
+	after, ok := CleanStaleClientsAfter("")
+	assert.True(t, ok)
+	assert.Equal(t, 365*24*time.Hour, after)
+	after, ok = CleanStaleClientsAfter("other")
+	assert.True(t, ok)
+	assert.Equal(t, 365*24*time.Hour, after)
+	_, ok = CleanStaleClientsAfter("disabled")
+	assert.False(t, ok)
+}
+
+func TestIsStale(t *testing.T) {
+	now := time.Now()
+	after := 30 * 24 * time.Hour
+	old := now.Add(-60 * 24 * time.Hour)
+
+	refreshed := &Client{ClientKind: "mobile", LastRefreshedAt: now.Add(-time.Hour)}
+	assert.False(t, refreshed.IsStale(after, now))
+
+	unused := &Client{ClientKind: "mobile", LastRefreshedAt: old.Format(time.RFC3339Nano)}
+	assert.True(t, unused.IsStale(after, now))
+
+	md := metadata.New()
+	md.CreatedAt = old
+	neverRefreshed := &Client{ClientKind: "desktop", Metadata: md}
+	assert.True(t, neverRefreshed.IsStale(after, now))
+
+	sharing := &Client{ClientKind: "sharing", LastRefreshedAt: old}
+	assert.False(t, sharing.IsStale(after, now))
+
+	pending := &Client{ClientKind: "browser", Pending: true, LastRefreshedAt: old}
+	assert.False(t, pending.IsStale(after, now))
+
+	unknown := &Client{ClientKind: "mobile"}
+	assert.False(t, unknown.IsStale(after, now))
+}
@@ -23,9 +23,15 @@ import (
 
 // Status list is described on https://api.onlyoffice.com/editors/callback#status
 const (
+	// StatusEditing is used when the document is being edited, and a user
+	// has connected or disconnected.
+	StatusEditing = 1
 	// StatusReadyForSaving is used when the file should be saved after being
 	// edited.
 	StatusReadyForSaving = 2
+	// StatusClosedWithoutChanges is used when the document has been closed
+	// without changes.
+	StatusClosedWithoutChanges = 4
 	// StatusForceSaveRequested is used when the file has been modified and
 	// should be saved, even if the document is still opened and can be edited
 	// by users.
@@ -40,10 +46,11 @@ const OOSlug = "onlyoffice-server"
 // server to the stack.
 // Cf https://api.onlyoffice.com/editors/callback
 type CallbackParameters struct {
-	Key    string `json:"key"`
-	Status int    `json:"status"`
-	URL    string `json:"url"`
-	Token  string `json:"-"` // From the Authorization header
+	Key    string   `json:"key"`
+	Status int      `json:"status"`
+	URL    string   `json:"url"`
+	Users  []string `json:"users"`
+	Token  string   `json:"-"` // From the Authorization header
 }
 
 var docserverClient = &http.Client{
@@ -52,9 +59,10 @@ var docserverClient = &http.Client{
 
 type callbackClaims struct {
 	Payload struct {
-		Key    string `json:"key"`
-		Status int    `json:"status"`
-		URL    string `json:"url"`
+		Key    string   `json:"key"`
+		Status int      `json:"status"`
+		URL    string   `json:"url"`
+		Users  []string `json:"users"`
 	} `json:"payload"`
 }
 
@@ -65,16 +73,28 @@ func (c *callbackClaims) GetIssuer() (string, error)                   { return
 func (c *callbackClaims) GetSubject() (string, error)                  { return "", nil }
 func (c *callbackClaims) GetAudience() (jwt.ClaimStrings, error)       { return nil, nil }
 
-// Callback will manage the callback from the document server.
+// Callback will manage the callback from the document server. The callbacks
+// for the same key are handled one at a time, and the document server can
+// send the same callback several times: the content is saved only once.
 func Callback(inst *instance.Instance, params CallbackParameters) error {
 	cfg := getConfig(inst.ContextName)
-	if err := checkToken(cfg, params); err != nil {
+	if err := checkToken(cfg, &params); err != nil {
 		return err
 	}
 
+	mu := config.Lock().ReadWrite(inst, "office/"+params.Key)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
 	switch params.Status {
+	case StatusEditing:
+		return updateEditors(inst, cfg, params.Key, params.Users)
 	case StatusReadyForSaving:
 		return finalSaveFile(inst, params.Key, params.URL)
+	case StatusClosedWithoutChanges:
+		return closeSession(inst, params.Key)
 	case StatusForceSaveRequested:
 		return forceSaveFile(inst, params.Key, params.URL)
 	default:
@@ -82,7 +102,7 @@ func Callback(inst *instance.Instance, params CallbackParameters) error {
 	}
 }
 
-func checkToken(cfg *config.Office, params CallbackParameters) error {
+func checkToken(cfg *config.Office, params *CallbackParameters) error {
 	if cfg == nil || cfg.OutboxSecret == "" {
 		return nil
 	}
@@ -100,6 +120,7 @@ func checkToken(cfg *config.Office, params CallbackParameters) error {
 	if params.URL != claims.Payload.URL || params.Key != claims.Payload.Key || params.Status != claims.Payload.Status {
 		return permission.ErrInvalidToken
 	}
+	params.Users = claims.Payload.Users
 	return nil
 }
 
@@ -108,10 +129,21 @@ func finalSaveFile(inst *instance.Instance, key, downloadURL string) error {
 	if err != nil || detector == nil || detector.ID == "" || detector.Rev == "" {
 		return ErrInvalidKey
 	}
+	if detector.SavedURL == downloadURL {
+		// The content has already been saved, the callback has been sent
+		// again by the document server
+		return nil
+	}
 
-	_, err = saveFile(inst, *detector, downloadURL)
+	updated, err := saveFile(inst, *detector, downloadURL)
 	if err == nil {
-		_ = GetStore().RemoveDoc(inst, key)
+		updated.SavedURL = downloadURL
+		followConflict(inst, key, detector, updated)
+		if err := GetStore().CloseDoc(inst, key, *updated); err != nil {
+			inst.Logger().WithNamespace("office").
+				Infof("Cannot close the session for %s: %s", updated.ID, err)
+		}
+		publishSession(inst, &conflictDetector{ID: detector.ID, Closed: true})
 	}
 	return err
 }
@@ -121,14 +153,47 @@ func forceSaveFile(inst *instance.Instance, key, downloadURL string) error {
 	if err != nil || detector == nil || detector.ID == "" || detector.Rev == "" {
 		return ErrInvalidKey
 	}
+	if detector.Closed || detector.SavedURL == downloadURL {
+		return nil
+	}
 
 	updated, err := saveFile(inst, *detector, downloadURL)
 	if err == nil {
+		updated.Editors = detector.Editors
+		updated.SavedURL = downloadURL
+		followConflict(inst, key, detector, updated)
 		_ = GetStore().UpdateDoc(inst, key, *updated)
 	}
 	return err
 }
 
+// followConflict associates the key to the new file when the content has been
+// saved in a new file because of a conflict, so that the next saves for this
+// session are made on the same file.
+func followConflict(inst *instance.Instance, key string, detector, updated *conflictDetector) {
+	if updated.ID != detector.ID {
+		_ = GetStore().UpdateSecret(inst, key, detector.ID, updated.ID)
+	}
+}
+
+// closeSession is called when the document has been closed without changes.
+// The key is no longer given for opening the document, so that the next
+// session will start with the current content of the file.
+func closeSession(inst *instance.Instance, key string) error {
+	detector, err := GetStore().GetDoc(inst, key)
+	if err != nil {
+		return err
+	}
+	if detector == nil || detector.Closed {
+		return nil
+	}
+	if err := GetStore().CloseDoc(inst, key, *detector); err != nil {
+		return err
+	}
+	publishSession(inst, &conflictDetector{ID: detector.ID, Closed: true})
+	return nil
+}
+
 // saveFile saves the file with content from the given URL and returns the new revision.
 func saveFile(inst *instance.Instance, detector conflictDetector, downloadURL string) (*conflictDetector, error) {
 	fs := inst.VFS()
@@ -322,7 +322,7 @@ func getConfig(contextName string) *config.Office {
 }
 
 func shouldOpenANewVersion(file *vfs.FileDoc, detector *conflictDetector) bool {
-	if detector == nil {
+	if detector == nil || detector.Closed {
 		return true
 	}
 	cm := file.CozyMetadata
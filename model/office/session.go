@@ -0,0 +1,158 @@
+package office
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Session is the information about an editing session of an office document
+// that is sent via the realtime: the users that are editing the document.
+type Session struct {
+	FileID  string   `json:"_id"`
+	Editors []string `json:"editors"`
+	Count   int      `json:"count"`
+}
+
+// ID returns the identifier of the file
+func (s *Session) ID() string { return s.FileID }
+
+// DocType returns the document type
+func (s *Session) DocType() string { return consts.OfficeSessions }
+
+// publishSession sends the editors of a document via the realtime. A session
+// without editors is sent as a deletion.
+func publishSession(inst *instance.Instance, detector *conflictDetector) {
+	editors := detector.Editors
+	if detector.Closed || editors == nil {
+		editors = []string{}
+	}
+	doc := &Session{FileID: detector.ID, Editors: editors, Count: len(editors)}
+	verb := realtime.EventUpdate
+	if len(editors) == 0 {
+		verb = realtime.EventDelete
+	}
+	go realtime.GetHub().Publish(inst, verb, doc, nil)
+}
+
+// updateEditors is called when the document server says that a user has
+// connected to or disconnected from an editing session. The editors are sent
+// via the realtime, and when the session continues with other users, a save
+// is forced to keep the content of the file up-to-date.
+func updateEditors(inst *instance.Instance, cfg *config.Office, key string, users []string) error {
+	detector, err := GetStore().GetDoc(inst, key)
+	if err != nil {
+		return err
+	}
+	if detector == nil || detector.Closed {
+		return nil
+	}
+	previous := detector.Editors
+	detector.Editors = normalizeEditors(users)
+	if sameEditors(previous, detector.Editors) {
+		return nil
+	}
+	if err := GetStore().UpdateDoc(inst, key, *detector); err != nil {
+		return err
+	}
+	publishSession(inst, detector)
+
+	if len(previous) > 0 && len(detector.Editors) > 0 {
+		// The command is sent in a goroutine, as the document server can call
+		// the callback for the force save before answering to the command.
+		go func() {
+			if err := sendCommand(cfg, "forcesave", key); err != nil {
+				inst.Logger().WithNamespace("office").
+					Infof("Cannot force save %s: %s", detector.ID, err)
+			}
+		}()
+	}
+	return nil
+}
+
+func normalizeEditors(users []string) []string {
+	editors := make([]string, 0, len(users))
+	seen := make(map[string]struct{}, len(users))
+	for _, user := range users {
+		if _, ok := seen[user]; ok || user == "" {
+			continue
+		}
+		seen[user] = struct{}{}
+		editors = append(editors, user)
+	}
+	sort.Strings(editors)
+	return editors
+}
+
+func sameEditors(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// commandPath is the path of the command service on the document server.
+// Cf https://api.onlyoffice.com/editors/command/
+const commandPath = "/coauthoring/CommandService.ashx"
+
+// sendCommand sends a command, like forcesave, for the editing session with
+// the given key to the document server.
+func sendCommand(cfg *config.Office, command, key string) error {
+	if cfg == nil || cfg.OnlyOfficeURL == "" {
+		return ErrNoServer
+	}
+	u, err := url.Parse(cfg.OnlyOfficeURL)
+	if err != nil {
+		return err
+	}
+	u = u.JoinPath(commandPath)
+
+	body := map[string]interface{}{"c": command, "key": key}
+	if cfg.InboxSecret != "" {
+		claims := jwt.MapClaims{"c": command, "key": key}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		signed, err := token.SignedString([]byte(cfg.InboxSecret))
+		if err != nil {
+			return err
+		}
+		body["token"] = signed
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	res, err := docserverClient.Post(u.String(), "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	var result struct {
+		Error int `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return err
+	}
+	// The error 4 means that there are no changes to save
+	if result.Error != 0 && result.Error != 4 {
+		return fmt.Errorf("error %d from the command service", result.Error)
+	}
+	return nil
+}
@@ -14,10 +14,22 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// conflictDetector is the state of an editing session on the document
+// server, identified by its key.
 type conflictDetector struct {
 	ID     string
 	Rev    string
 	MD5Sum []byte
+	// Editors is the list of the users currently editing the document, as
+	// sent by the document server.
+	Editors []string `json:",omitempty"`
+	// SavedURL is the URL of the last content saved for this session. It is
+	// used to ignore the callbacks sent again by the document server.
+	SavedURL string `json:",omitempty"`
+	// Closed is true when the document server has closed the session: the key
+	// is kept for a short time to answer to the callbacks sent again, but it
+	// is no longer given for opening the document.
+	Closed bool `json:",omitempty"`
 }
 
 // Store is an object to store and retrieve document server keys <-> id,rev
@@ -27,12 +39,16 @@ type Store interface {
 	AddDoc(db prefixer.Prefixer, payload conflictDetector) (string, error)
 	GetDoc(db prefixer.Prefixer, secret string) (*conflictDetector, error)
 	UpdateDoc(db prefixer.Prefixer, secret string, payload conflictDetector) error
+	CloseDoc(db prefixer.Prefixer, secret string, payload conflictDetector) error
 	RemoveDoc(db prefixer.Prefixer, secret string) error
 }
 
 // storeTTL is the time an entry stay alive
 var storeTTL = 30 * 24 * time.Hour
 
+// closedTTL is the time an entry for a closed session stay alive
+var closedTTL = 24 * time.Hour
+
 // storeCleanInterval is the time interval between each cleanup.
 var storeCleanInterval = 1 * time.Hour
 
@@ -148,6 +164,21 @@ func (s *memStore) UpdateDoc(db prefixer.Prefixer, secret string, payload confli
 	return nil
 }
 
+func (s *memStore) CloseDoc(db prefixer.Prefixer, secret string, payload conflictDetector) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byID[payload.ID] == secret {
+		delete(s.byID, payload.ID)
+	}
+	payload.Closed = true
+	key := docKey(db, secret)
+	s.vals[key] = &memRef{
+		val: payload,
+		exp: time.Now().Add(closedTTL),
+	}
+	return nil
+}
+
 func (s *memStore) RemoveDoc(db prefixer.Prefixer, secret string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -234,6 +265,20 @@ func (s *redisStore) UpdateDoc(db prefixer.Prefixer, secret string, payload conf
 	return nil
 }
 
+func (s *redisStore) CloseDoc(db prefixer.Prefixer, secret string, payload conflictDetector) error {
+	idKey := docKey(db, payload.ID)
+	if result, err := s.c.Get(s.ctx, idKey).Result(); err == nil && result == secret {
+		_ = s.c.Del(s.ctx, idKey)
+	}
+	payload.Closed = true
+	v, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	key := docKey(db, secret)
+	return s.c.Set(s.ctx, key, v, closedTTL).Err()
+}
+
 func (s *redisStore) RemoveDoc(db prefixer.Prefixer, secret string) error {
 	payload, _ := s.GetDoc(db, secret)
 	if payload != nil {
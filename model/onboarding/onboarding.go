@@ -0,0 +1,262 @@
+// Package onboarding keeps the state of the onboarding of a new instance, so
+// that the flagship app can drive it with JSON APIs (passphrase registration,
+// installation of the apps), follow its progress via the realtime, and resume
+// it if the app has been killed in the middle of the onboarding.
+package onboarding
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/utils"
+)
+
+// Step is a step of the onboarding.
+type Step string
+
+const (
+	// StepPassphrase is the first step: the user has to choose their
+	// passphrase.
+	StepPassphrase Step = "passphrase"
+	// StepApps is when the apps chosen by the user are installed.
+	StepApps Step = "apps"
+	// StepFinished is when the onboarding is complete.
+	StepFinished Step = "finished"
+)
+
+// The states of an app installed during the onboarding.
+const (
+	AppPending    = "pending"
+	AppInstalling = "installing"
+	AppInstalled  = "installed"
+	AppErrored    = "errored"
+)
+
+// MaxApps is the maximal number of apps that can be installed during the
+// onboarding.
+const MaxApps = 20
+
+var (
+	// ErrPassphraseNotRegistered is used when the apps are installed before
+	// the passphrase has been chosen.
+	ErrPassphraseNotRegistered = errors.New("the passphrase has not been registered")
+	// ErrAlreadyFinished is used when the onboarding is already finished.
+	ErrAlreadyFinished = errors.New("the onboarding is already finished")
+	// ErrTooManyApps is used when too many apps are asked to be installed.
+	ErrTooManyApps = errors.New("too many apps")
+)
+
+// AppState is the state of the installation of an app.
+type AppState struct {
+	Slug  string `json:"slug"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// State is the document, in the io.cozy.settings database, with the state of
+// the onboarding. Its changes are sent via the realtime, and it can be
+// fetched again when the flagship app has been restarted.
+type State struct {
+	DocID     string     `json:"_id,omitempty"`
+	DocRev    string     `json:"_rev,omitempty"`
+	Step      Step       `json:"step"`
+	Apps      []AppState `json:"apps,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (s *State) ID() string { return s.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (s *State) Rev() string { return s.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (s *State) DocType() string { return consts.Settings }
+
+// Clone implements the couchdb.Doc interface
+func (s *State) Clone() couchdb.Doc {
+	cloned := *s
+	cloned.Apps = make([]AppState, len(s.Apps))
+	copy(cloned.Apps, s.Apps)
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (s *State) SetID(id string) { s.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (s *State) SetRev(rev string) { s.DocRev = rev }
+
+// Relationships implements the jsonapi.Object interface
+func (s *State) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Included implements the jsonapi.Object interface
+func (s *State) Included() []jsonapi.Object { return nil }
+
+// Links implements the jsonapi.Object interface
+func (s *State) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/settings/onboarding"}
+}
+
+// Get returns the state of the onboarding. When there is no document yet, the
+// state is guessed from the instance.
+func Get(inst *instance.Instance) (*State, error) {
+	var state State
+	err := couchdb.GetDoc(inst, consts.Settings, consts.OnboardingSettingsID, &state)
+	if couchdb.IsNotFoundError(err) {
+		state = State{DocID: consts.OnboardingSettingsID}
+		switch {
+		case len(inst.PassphraseHash) == 0 && !inst.OnboardingFinished:
+			state.Step = StepPassphrase
+		case inst.OnboardingFinished:
+			state.Step = StepFinished
+		default:
+			state.Step = StepApps
+		}
+		return &state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// PassphraseRegistered is called when the passphrase has been chosen via the
+// flagship app: the onboarding continues with the installation of the apps.
+func PassphraseRegistered(inst *instance.Instance) error {
+	_, err := update(inst, func(state *State) error {
+		if state.Step == StepPassphrase || state.Step == StepFinished {
+			state.Step = StepApps
+		}
+		return nil
+	})
+	return err
+}
+
+// InstallApps installs the given webapps in background. The apps that are
+// already installed are skipped, so it can be called again to resume an
+// onboarding that has been interrupted.
+func InstallApps(inst *instance.Instance, slugs []string) (*State, error) {
+	if len(inst.PassphraseHash) == 0 {
+		return nil, ErrPassphraseNotRegistered
+	}
+	slugs = utils.UniqueStrings(slugs)
+	if len(slugs) > MaxApps {
+		return nil, ErrTooManyApps
+	}
+	var toInstall []string
+	state, err := update(inst, func(state *State) error {
+		if state.Step == StepFinished {
+			return ErrAlreadyFinished
+		}
+		state.Step = StepApps
+		for _, slug := range slugs {
+			if _, err := app.GetWebappBySlug(inst, slug); err == nil {
+				state.setApp(slug, AppInstalled, "")
+				continue
+			}
+			state.setApp(slug, AppPending, "")
+			toInstall = append(toInstall, slug)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(toInstall) > 0 {
+		go installApps(inst, toInstall)
+	}
+	return state, nil
+}
+
+// Finish marks the onboarding as complete.
+func Finish(inst *instance.Instance) (*State, error) {
+	state, err := update(inst, func(state *State) error {
+		state.Step = StepFinished
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !inst.OnboardingFinished {
+		finished := true
+		if err := lifecycle.Patch(inst, &lifecycle.Options{OnboardingFinished: &finished}); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// installApps installs the apps one by one, and updates the state after each
+// step to let the flagship app follow the progress.
+func installApps(inst *instance.Instance, slugs []string) {
+	log := inst.Logger().WithNamespace("onboarding")
+	for _, slug := range slugs {
+		setAppState(inst, slug, AppInstalling, "")
+		err := lifecycle.InstallApp(inst, slug)
+		if err != nil && !errors.Is(err, app.ErrAlreadyExists) {
+			log.Warnf("Cannot install %s: %s", slug, err)
+			setAppState(inst, slug, AppErrored, err.Error())
+			continue
+		}
+		setAppState(inst, slug, AppInstalled, "")
+	}
+}
+
+func setAppState(inst *instance.Instance, slug, appState, errMsg string) {
+	_, err := update(inst, func(state *State) error {
+		state.setApp(slug, appState, errMsg)
+		return nil
+	})
+	if err != nil {
+		inst.Logger().WithNamespace("onboarding").
+			Warnf("Cannot update the state of %s: %s", slug, err)
+	}
+}
+
+func (s *State) setApp(slug, appState, errMsg string) {
+	for i := range s.Apps {
+		if s.Apps[i].Slug == slug {
+			s.Apps[i].State = appState
+			s.Apps[i].Error = errMsg
+			return
+		}
+	}
+	s.Apps = append(s.Apps, AppState{Slug: slug, State: appState, Error: errMsg})
+}
+
+// update applies the changes to the state of the onboarding, with a lock to
+// avoid conflicts between the requests and the installation of the apps.
+func update(inst *instance.Instance, fn func(state *State) error) (*State, error) {
+	mu := config.Lock().ReadWrite(inst, "onboarding")
+	if err := mu.Lock(); err != nil {
+		return nil, err
+	}
+	defer mu.Unlock()
+
+	state, err := Get(inst)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(state); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	state.UpdatedAt = &now
+	if state.DocRev == "" {
+		err = couchdb.CreateNamedDocWithDB(inst, state)
+	} else {
+		err = couchdb.UpdateDoc(inst, state)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,24 @@
+package onboarding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetApp(t *testing.T) {
+	state := &State{Step: StepApps}
+	state.setApp("drive", AppPending, "")
+	state.setApp("photos", AppPending, "")
+	state.setApp("drive", AppErrored, "timeout")
+	state.setApp("drive", AppInstalled, "")
+
+	assert.Equal(t, []AppState{
+		{Slug: "drive", State: AppInstalled},
+		{Slug: "photos", State: AppPending},
+	}, state.Apps)
+
+	cloned := state.Clone().(*State)
+	cloned.setApp("photos", AppInstalling, "")
+	assert.Equal(t, AppPending, state.Apps[1].State)
+}
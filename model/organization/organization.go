@@ -0,0 +1,364 @@
+// Package organization is for the instances in organization mode: several
+// users, each with their own credentials and role, can log in the same
+// instance. The owner of the instance manages these users.
+package organization
+
+import (
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/session"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/logger"
+)
+
+// Role is the permission profile of a user of an organization.
+type Role string
+
+const (
+	// RoleAdmin is for the users with the same permissions as the owner.
+	RoleAdmin Role = "admin"
+	// RoleMember is for the users that can read and write the documents, but
+	// cannot manage the instance (settings, apps, users).
+	RoleMember Role = "member"
+	// RoleReader is for the users that can only read the documents.
+	RoleReader Role = "reader"
+)
+
+// adminDoctypes are the doctypes that only the admins can modify.
+var adminDoctypes = []string{
+	consts.Settings,
+	consts.Apps,
+	consts.Konnectors,
+	consts.Triggers,
+	consts.OrganizationUsers,
+}
+
+// minPassphraseLength is the minimal length of the passphrase of a user.
+const minPassphraseLength = 8
+
+var (
+	// ErrNotEnabled is used when the instance is not in organization mode.
+	ErrNotEnabled = errors.New("the organization mode is not enabled on this instance")
+	// ErrInvalidEmail is used when the email of a user is missing or invalid.
+	ErrInvalidEmail = errors.New("invalid email")
+	// ErrEmailTaken is used when another user already has the same email.
+	ErrEmailTaken = errors.New("a user already exists with this email")
+	// ErrInvalidRole is used when the role of a user is unknown.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrPassphraseTooShort is used when the passphrase of a user is too
+	// short.
+	ErrPassphraseTooShort = errors.New("the passphrase is too short")
+	// ErrUserNotFound is used when no user has the given email.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidCredentials is used when no active user matches the email
+	// and passphrase.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// User is a person who can log in an instance in organization mode.
+type User struct {
+	DocID          string     `json:"_id,omitempty"`
+	DocRev         string     `json:"_rev,omitempty"`
+	Email          string     `json:"email"`
+	Name           string     `json:"name,omitempty"`
+	Role           Role       `json:"role"`
+	Disabled       bool       `json:"disabled,omitempty"`
+	PassphraseHash []byte     `json:"passphrase_hash,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	LastLoginAt    *time.Time `json:"last_login_at,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (u *User) ID() string { return u.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (u *User) Rev() string { return u.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (u *User) DocType() string { return consts.OrganizationUsers }
+
+// Clone implements the couchdb.Doc interface
+func (u *User) Clone() couchdb.Doc {
+	cloned := *u
+	cloned.PassphraseHash = make([]byte, len(u.PassphraseHash))
+	copy(cloned.PassphraseHash, u.PassphraseHash)
+	if u.LastLoginAt != nil {
+		at := *u.LastLoginAt
+		cloned.LastLoginAt = &at
+	}
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (u *User) SetID(id string) { u.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (u *User) SetRev(rev string) { u.DocRev = rev }
+
+// Relationships implements the jsonapi.Object interface
+func (u *User) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Included implements the jsonapi.Object interface
+func (u *User) Included() []jsonapi.Object { return nil }
+
+// Links implements the jsonapi.Object interface
+func (u *User) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/organization/users/" + u.DocID}
+}
+
+// Validate normalizes the email of the user, and checks their role.
+func (u *User) Validate() error {
+	addr, err := mail.ParseAddress(strings.TrimSpace(u.Email))
+	if err != nil {
+		return ErrInvalidEmail
+	}
+	u.Email = strings.ToLower(addr.Address)
+	u.Name = strings.TrimSpace(u.Name)
+	switch u.Role {
+	case RoleAdmin, RoleMember, RoleReader:
+		return nil
+	case "":
+		u.Role = RoleMember
+		return nil
+	default:
+		return ErrInvalidRole
+	}
+}
+
+// Restrict returns the permission set, limited to what the role of the user
+// allows. The maximal permissions of the flagship app are kept only for the
+// admins, as they give access to every endpoint.
+func (u *User) Restrict(set permission.Set) permission.Set {
+	if u.Role == RoleAdmin {
+		return set
+	}
+	restricted := make(permission.Set, 0, len(set))
+	for _, rule := range set {
+		if single := (permission.Set{rule}); single.IsMaximal() {
+			continue
+		}
+		if u.Role == RoleReader || isAdminRule(rule) {
+			rule.Verbs = permission.Verbs(permission.GET)
+		}
+		restricted = append(restricted, rule)
+	}
+	return restricted
+}
+
+func isAdminRule(rule permission.Rule) bool {
+	for _, doctype := range adminDoctypes {
+		if permission.MatchType(rule, doctype) {
+			return true
+		}
+	}
+	return false
+}
+
+// Logger returns a logger for the audit of the actions of the user.
+func (u *User) Logger(inst *instance.Instance) logger.Logger {
+	return inst.Logger().WithNamespace("orgaudit").WithField("user_id", u.DocID)
+}
+
+// Create adds a user to the organization, with the given passphrase.
+func Create(inst *instance.Instance, u *User, passphrase []byte) error {
+	if !inst.Organization {
+		return ErrNotEnabled
+	}
+	if err := u.Validate(); err != nil {
+		return err
+	}
+	if _, err := FindByEmail(inst, u.Email); err == nil {
+		return ErrEmailTaken
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+	if err := u.setPassphrase(passphrase); err != nil {
+		return err
+	}
+	u.DocID = ""
+	u.DocRev = ""
+	u.CreatedAt = time.Now().UTC()
+	u.UpdatedAt = u.CreatedAt
+	u.LastLoginAt = nil
+	if err := couchdb.CreateDoc(inst, u); err != nil {
+		return err
+	}
+	u.Logger(inst).Infof("User %s created with the role %s", u.Email, u.Role)
+	return nil
+}
+
+// Find returns the user with the given identifier.
+func Find(inst *instance.Instance, id string) (*User, error) {
+	u := &User{}
+	if err := couchdb.GetDoc(inst, consts.OrganizationUsers, id, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// FindByEmail returns the user with the given email.
+func FindByEmail(inst *instance.Instance, email string) (*User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	users, err := List(inst)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// List returns all the users of the organization.
+func List(inst *instance.Instance) ([]*User, error) {
+	var users []*User
+	err := couchdb.ForeachDocs(inst, consts.OrganizationUsers, func(_ string, data json.RawMessage) error {
+		var u User
+		if err := json.Unmarshal(data, &u); err != nil {
+			return err
+		}
+		users = append(users, &u)
+		return nil
+	})
+	if couchdb.IsNoDatabaseError(err) {
+		return []*User{}, nil
+	}
+	return users, err
+}
+
+// Update persists the changes of the name, role and disabled flag of the
+// user. When a user is disabled, their sessions and OAuth clients are revoked.
+func Update(inst *instance.Instance, u *User) error {
+	old, err := Find(inst, u.DocID)
+	if err != nil {
+		return err
+	}
+	u.Email = old.Email
+	if err := u.Validate(); err != nil {
+		return err
+	}
+	u.PassphraseHash = old.PassphraseHash
+	u.CreatedAt = old.CreatedAt
+	u.LastLoginAt = old.LastLoginAt
+	u.UpdatedAt = time.Now().UTC()
+	if err := couchdb.UpdateDoc(inst, u); err != nil {
+		return err
+	}
+	if u.Disabled && !old.Disabled {
+		u.Logger(inst).Infof("User %s disabled", u.Email)
+		return revoke(inst, u)
+	}
+	if u.Role != old.Role {
+		u.Logger(inst).Infof("Role of %s changed from %s to %s", u.Email, old.Role, u.Role)
+	}
+	return nil
+}
+
+// SetPassphrase changes the passphrase of the user, and revokes their other
+// sessions.
+func SetPassphrase(inst *instance.Instance, u *User, passphrase []byte) error {
+	if err := u.setPassphrase(passphrase); err != nil {
+		return err
+	}
+	u.UpdatedAt = time.Now().UTC()
+	if err := couchdb.UpdateDoc(inst, u); err != nil {
+		return err
+	}
+	u.Logger(inst).Infof("Passphrase of %s changed", u.Email)
+	return session.DeleteForUser(inst, u.DocID)
+}
+
+func (u *User) setPassphrase(passphrase []byte) error {
+	if len(passphrase) < minPassphraseLength {
+		return ErrPassphraseTooShort
+	}
+	hash, err := crypto.GenerateFromPassphrase(passphrase)
+	if err != nil {
+		return err
+	}
+	u.PassphraseHash = hash
+	return nil
+}
+
+// Delete removes the user from the organization, with their sessions and
+// OAuth clients.
+func Delete(inst *instance.Instance, u *User) error {
+	if err := revoke(inst, u); err != nil {
+		return err
+	}
+	if err := couchdb.DeleteDoc(inst, u); err != nil {
+		return err
+	}
+	u.Logger(inst).Infof("User %s deleted", u.Email)
+	return nil
+}
+
+// revoke deletes the sessions and OAuth clients of the user.
+func revoke(inst *instance.Instance, u *User) error {
+	if err := session.DeleteForUser(inst, u.DocID); err != nil {
+		return err
+	}
+	var clients []*oauth.Client
+	err := couchdb.ForeachDocs(inst, consts.OAuthClients, func(_ string, data json.RawMessage) error {
+		var client oauth.Client
+		if err := json.Unmarshal(data, &client); err != nil {
+			return err
+		}
+		if client.UserID == u.DocID {
+			clients = append(clients, &client)
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+	for _, client := range clients {
+		if err := client.Delete(inst); err != nil {
+			return errors.New(err.Error)
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the active user with the given email and passphrase,
+// or ErrInvalidCredentials.
+func Authenticate(inst *instance.Instance, email string, passphrase []byte) (*User, error) {
+	if !inst.Organization {
+		return nil, ErrNotEnabled
+	}
+	u, err := FindByEmail(inst, email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	needUpdate, err := crypto.CompareHashAndPassphrase(u.PassphraseHash, passphrase)
+	if err != nil || u.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+	if needUpdate {
+		if hash, err := crypto.GenerateFromPassphrase(passphrase); err == nil {
+			u.PassphraseHash = hash
+		}
+	}
+	now := time.Now().UTC()
+	u.LastLoginAt = &now
+	if err := couchdb.UpdateDoc(inst, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
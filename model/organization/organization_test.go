@@ -0,0 +1,54 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	u := &User{Email: " Alice <Alice@Example.org> ", Name: " Alice "}
+	require.NoError(t, u.Validate())
+	assert.Equal(t, "alice@example.org", u.Email)
+	assert.Equal(t, "Alice", u.Name)
+	assert.Equal(t, RoleMember, u.Role)
+
+	u = &User{Email: "not an email"}
+	assert.Equal(t, ErrInvalidEmail, u.Validate())
+
+	u = &User{Email: "bob@example.org", Role: "owner"}
+	assert.Equal(t, ErrInvalidRole, u.Validate())
+}
+
+func TestRestrict(t *testing.T) {
+	set := permission.Set{
+		permission.Rule{Type: consts.Files},
+		permission.Rule{Type: consts.Settings},
+		permission.Rule{Type: "io.cozy.apps.*"},
+	}
+
+	admin := &User{Role: RoleAdmin}
+	restricted := admin.Restrict(set)
+	assert.True(t, restricted.AllowWholeType(permission.PUT, consts.Settings))
+	maximal := admin.Restrict(permission.MaximalSet())
+	assert.True(t, maximal.IsMaximal())
+
+	member := &User{Role: RoleMember}
+	restricted = member.Restrict(set)
+	assert.True(t, restricted.AllowWholeType(permission.PUT, consts.Files))
+	assert.True(t, restricted.AllowWholeType(permission.GET, consts.Settings))
+	assert.False(t, restricted.AllowWholeType(permission.PUT, consts.Settings))
+	assert.False(t, restricted.AllowWholeType(permission.POST, consts.Apps))
+	assert.Empty(t, member.Restrict(permission.MaximalSet()))
+
+	reader := &User{Role: RoleReader}
+	restricted = reader.Restrict(set)
+	assert.True(t, restricted.AllowWholeType(permission.GET, consts.Files))
+	assert.False(t, restricted.AllowWholeType(permission.POST, consts.Files))
+
+	// The original set is not modified
+	assert.True(t, set.AllowWholeType(permission.POST, consts.Files))
+}
@@ -13,6 +13,12 @@ type Claims struct {
 	Scope     string `json:"scope,omitempty"`
 	SessionID string `json:"session_id,omitempty"`
 	SStamp    string `json:"stamp,omitempty"`
+	// AuthorizedParty is the ID of the OAuth client that has requested the
+	// token, for the webview tokens.
+	AuthorizedParty string `json:"azp,omitempty"`
+	// Generation is the token generation of the OAuth client when the token
+	// was issued. The tokens of a previous generation have been revoked.
+	Generation int `json:"gen,omitempty"`
 }
 
 // IssuedAtUTC returns a time.Time struct of the IssuedAt field in UTC
@@ -50,6 +56,9 @@ func (claims *Claims) Expired() bool {
 	case consts.KonnectorAudience:
 		validityDuration = consts.KonnectorTokenValidityDuration
 
+	case consts.WebviewAudience:
+		validityDuration = consts.WebviewTokenValidityDuration
+
 	case consts.CLIAudience:
 		validityDuration = consts.CLITokenValidityDuration
 
@@ -0,0 +1,26 @@
+package permission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimsExpired(t *testing.T) {
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience: jwt.ClaimStrings{consts.WebviewAudience},
+			IssuedAt: jwt.NewNumericDate(time.Now().Add(-10 * time.Minute)),
+		},
+	}
+	assert.False(t, claims.Expired())
+
+	claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-20 * time.Minute))
+	assert.True(t, claims.Expired())
+
+	claims.Audience = jwt.ClaimStrings{consts.KonnectorAudience}
+	assert.False(t, claims.Expired())
+}
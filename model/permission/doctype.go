@@ -19,6 +19,9 @@ var blockList = map[string]bool{
 	consts.AccountTypes:          none,
 	consts.KonnectorsMaintenance: none,
 	consts.RemoteSecrets:         none,
+	consts.MailsSuppressions:     none,
+	consts.MailsDKIM:             none,
+	consts.ACMECertificates:      none,
 
 	// Only stack can manipulate them
 	consts.Sessions:            none,
@@ -26,10 +29,19 @@ var blockList = map[string]bool{
 	consts.Intents:             none,
 	consts.OAuthClients:        none,
 	consts.OAuthAccessCodes:    none,
+	consts.OAuthDeviceCodes:    none,
 	consts.Archives:            none,
 	consts.Sharings:            none,
 	consts.Shared:              none,
 	consts.SoftDeletedAccounts: none,
+	consts.AccountsDelegations: none,
+	consts.MailsQueue:          none,
+	consts.BIWebhooks:          none,
+	consts.AppPasswords:        none,
+	consts.CalendarFeeds:       none,
+	consts.OrganizationUsers:   none,
+	consts.DirUsages:           none,
+	consts.FilesBlobs:          none,
 
 	// Synthetic doctypes (API only)
 	consts.CertifiedCarbonCopy:     none,
@@ -43,6 +55,7 @@ var blockList = map[string]bool{
 	consts.OfficeURL:               none,
 	consts.NotesURL:                none,
 	consts.AppsOpenParameters:      none,
+	consts.AppsData:                none,
 
 	// Synthetic doctypes (realtime events only)
 	consts.AuthConfirmations:   none,
@@ -50,7 +63,9 @@ var blockList = map[string]bool{
 	consts.SharingsInitialSync: none,
 	consts.NotesEvents:         none,
 	consts.NotesTelepointers:   none,
+	consts.NotesPresences:      none,
 	consts.Thumbnails:          none,
+	consts.FilesQualifications: none,
 	consts.AppLogs:             none,
 
 	// Only stack can write them
@@ -65,7 +80,19 @@ var blockList = map[string]bool{
 	consts.SessionsLogins:    readable,
 	consts.NotesSteps:        readable,
 	consts.NotesImages:       readable,
+	consts.PhotosAlbumsLinks: readable,
+	consts.PhotosHashes:      readable,
+	consts.PhotosLocations:   readable,
+	consts.StorageSnapshots:  readable,
+	consts.AccessStats:       none,
+	consts.PhotosGeoClusters: readable,
 	consts.BitwardenContacts: readable,
+	consts.UserActions:       readable,
+	consts.DoctypesSchemas:   readable,
+	consts.Reminders:         readable,
+	consts.Comments:          readable,
+	consts.Activities:        readable,
+	consts.KonnectorsStats:   readable,
 }
 
 // CheckReadable will abort the context and returns false if the doctype
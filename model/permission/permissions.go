@@ -67,6 +67,10 @@ const (
 	// TypeShareInteract is the value of Permission.Type for reading and
 	// writing a note in a shared folder.
 	TypeShareInteract = "share-interact"
+
+	// TypeAppPassword is the value of Permission.Type for the requests of a
+	// protocol client authenticated with an app password.
+	TypeAppPassword = "app-password"
 )
 
 // ID implements jsonapi.Doc
@@ -249,6 +249,20 @@ func TestStringToSet(t *testing.T) {
 	assert.Len(t, set[1].Values, 1)
 	assert.Equal(t, "io.cozy.files.music-dir", set[1].Values[0])
 
+	set, err = UnmarshalScopeString("openid profile io.cozy.contacts email")
+	assert.NoError(t, err)
+	assert.Len(t, set, 1)
+	assert.Equal(t, "io.cozy.contacts", set[0].Type)
+
+	set, err = UnmarshalScopeString("openid")
+	assert.NoError(t, err)
+	assert.Len(t, set, 0)
+
+	set, openid, err := SplitScopeString("openid profile io.cozy.contacts email")
+	assert.NoError(t, err)
+	assert.Len(t, set, 1)
+	assert.Equal(t, []string{"openid", "profile", "email"}, openid)
+
 	rule, err := UnmarshalRuleString("io.cozy.events:GET:mygreatcalendar,othercalendar:calendar-id")
 	assert.NoError(t, err)
 	assert.Equal(t, "io.cozy.events", rule.Type)
@@ -30,24 +30,54 @@ func (s Set) MarshalScopeString() (string, error) {
 	return out[1:], nil
 }
 
-// UnmarshalScopeString parse a Scope string into a permission Set
+// The scopes of OpenID Connect. They are not rules on a doctype, but they say
+// which claims about the user can be given to the client.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// IsOpenIDScope returns true if the given scope is a scope of OpenID Connect.
+func IsOpenIDScope(scope string) bool {
+	switch scope {
+	case ScopeOpenID, ScopeProfile, ScopeEmail:
+		return true
+	}
+	return false
+}
+
+// UnmarshalScopeString parse a Scope string into a permission Set. The scopes
+// of OpenID Connect are ignored, SplitScopeString can be used to get them.
 func UnmarshalScopeString(in string) (Set, error) {
+	set, _, err := SplitScopeString(in)
+	return set, err
+}
+
+// SplitScopeString parse a Scope string into a permission Set for the rules,
+// and a list with the scopes of OpenID Connect.
+func SplitScopeString(in string) (Set, []string, error) {
 	if in == "" {
-		return nil, ErrBadScope
+		return nil, nil, ErrBadScope
 	}
 
 	parts := strings.Split(in, ruleSep)
-	out := make(Set, len(parts))
+	out := make(Set, 0, len(parts))
+	var openid []string
 
-	for i, p := range parts {
+	for _, p := range parts {
+		if IsOpenIDScope(p) {
+			openid = append(openid, p)
+			continue
+		}
 		s, err := UnmarshalRuleString(p)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		out[i] = s
+		out = append(out, s)
 	}
 
-	return out, nil
+	return out, openid, nil
 }
 
 // MarshalJSON implements json.Marshaller on Set. Note that the JSON
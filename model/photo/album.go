@@ -0,0 +1,185 @@
+package photo
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// AlbumLinkCode is the name of the code for the share-by-link permissions of
+// the public album links.
+const AlbumLinkCode = "album"
+
+// AlbumLink is a public link to an album. The access is given by a
+// share-by-link permission, and this document has the same identifier as the
+// permission. It keeps the options of the public page, and the number of
+// views.
+type AlbumLink struct {
+	DocID        string     `json:"_id,omitempty"`
+	DocRev       string     `json:"_rev,omitempty"`
+	AlbumID      string     `json:"album_id"`
+	Download     bool       `json:"download"`
+	Views        int        `json:"views"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ID returns the link identifier
+func (l *AlbumLink) ID() string { return l.DocID }
+
+// Rev returns the link revision
+func (l *AlbumLink) Rev() string { return l.DocRev }
+
+// DocType returns the link document type
+func (l *AlbumLink) DocType() string { return consts.PhotosAlbumsLinks }
+
+// Clone implements couchdb.Doc
+func (l *AlbumLink) Clone() couchdb.Doc {
+	cloned := *l
+	if l.LastViewedAt != nil {
+		at := *l.LastViewedAt
+		cloned.LastViewedAt = &at
+	}
+	return &cloned
+}
+
+// SetID changes the link identifier
+func (l *AlbumLink) SetID(id string) { l.DocID = id }
+
+// SetRev changes the link revision
+func (l *AlbumLink) SetRev(rev string) { l.DocRev = rev }
+
+// AlbumRules returns the permission rules for a public link to an album:
+// the album and the photos referenced by it can be read.
+func AlbumRules(albumID string) permission.Set {
+	return permission.Set{
+		permission.Rule{
+			Type:   consts.PhotosAlbums,
+			Verbs:  permission.Verbs(permission.GET),
+			Values: []string{albumID},
+		},
+		permission.Rule{
+			Type:     consts.Files,
+			Verbs:    permission.Verbs(permission.GET),
+			Selector: couchdb.SelectorReferencedBy,
+			Values:   []string{consts.PhotosAlbums + "/" + albumID},
+		},
+	}
+}
+
+// CreateAlbumLink saves the options for the public link of an album, given
+// by the share-by-link permission.
+func CreateAlbumLink(inst *instance.Instance, perm *permission.Permission, albumID string, download bool) (*AlbumLink, error) {
+	link := &AlbumLink{
+		DocID:     perm.ID(),
+		AlbumID:   albumID,
+		Download:  download,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := couchdb.CreateNamedDocWithDB(inst, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// GetAlbumLink returns the public link with the given identifier.
+func GetAlbumLink(inst *instance.Instance, id string) (*AlbumLink, error) {
+	var link AlbumLink
+	if err := couchdb.GetDoc(inst, consts.PhotosAlbumsLinks, id, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListAlbumLinks returns the public links of an album.
+func ListAlbumLinks(inst *instance.Instance, albumID string) ([]*AlbumLink, error) {
+	var links []*AlbumLink
+	err := couchdb.ForeachDocs(inst, consts.PhotosAlbumsLinks, func(_ string, data json.RawMessage) error {
+		var link AlbumLink
+		if err := json.Unmarshal(data, &link); err != nil {
+			return err
+		}
+		if link.AlbumID == albumID {
+			links = append(links, &link)
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return links, nil
+}
+
+// DeleteAlbumLink revokes the permission of a public link, and deletes it.
+func DeleteAlbumLink(inst *instance.Instance, link *AlbumLink) error {
+	var perm permission.Permission
+	err := couchdb.GetDoc(inst, consts.Permissions, link.DocID, &perm)
+	if err == nil {
+		err = perm.Revoke(inst)
+	}
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return err
+	}
+	return couchdb.DeleteDoc(inst, link)
+}
+
+// RecordAlbumView increments the number of views of a public link.
+func RecordAlbumView(inst *instance.Instance, id string) (*AlbumLink, error) {
+	mu := config.Lock().ReadWrite(inst, "album-links/"+id)
+	if err := mu.Lock(); err != nil {
+		return nil, err
+	}
+	defer mu.Unlock()
+
+	link, err := GetAlbumLink(inst, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	link.Views++
+	link.LastViewedAt = &now
+	if err := couchdb.UpdateDoc(inst, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// AlbumArchive returns the archive for downloading all the photos of an
+// album. The photos in the trash are excluded.
+func AlbumArchive(inst *instance.Instance, albumID, name string) (*vfs.Archive, error) {
+	key := []string{consts.PhotosAlbums, albumID}
+	req := &couchdb.ViewRequest{
+		Key:         key,
+		IncludeDocs: true,
+		Reduce:      false,
+	}
+	var res couchdb.ViewResponse
+	if err := couchdb.ExecView(inst, couchdb.FilesReferencedByView, req, &res); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		var doc struct {
+			Type    string `json:"type"`
+			Trashed bool   `json:"trashed"`
+		}
+		if err := json.Unmarshal(row.Doc, &doc); err != nil {
+			continue
+		}
+		if doc.Type == consts.FileType && !doc.Trashed {
+			ids = append(ids, row.ID)
+		}
+	}
+	name = strings.ReplaceAll(name, "/", "-")
+	if name == "" {
+		name = "album"
+	}
+	return &vfs.Archive{Name: name, IDs: ids}, nil
+}
@@ -0,0 +1,28 @@
+package photo
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumRules(t *testing.T) {
+	rules := AlbumRules("album1")
+	assert.True(t, rules.AllowID(permission.GET, consts.PhotosAlbums, "album1"))
+	assert.False(t, rules.AllowID(permission.GET, consts.PhotosAlbums, "album2"))
+	assert.False(t, rules.AllowID(permission.PUT, consts.PhotosAlbums, "album1"))
+	assert.False(t, rules.AllowWholeType(permission.GET, consts.Files))
+
+	in := &vfs.FileDoc{Type: consts.FileType, DocName: "in.jpg"}
+	in.AddReferencedBy(couchdb.DocReference{Type: consts.PhotosAlbums, ID: "album1"})
+	assert.True(t, rules.Allow(permission.GET, in))
+
+	out := &vfs.FileDoc{Type: consts.FileType, DocName: "out.jpg"}
+	out.AddReferencedBy(couchdb.DocReference{Type: consts.PhotosAlbums, ID: "album2"})
+	assert.False(t, rules.Allow(permission.GET, out))
+	assert.False(t, rules.Allow(permission.DELETE, in))
+}
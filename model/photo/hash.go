@@ -0,0 +1,73 @@
+package photo
+
+import (
+	"errors"
+	"image"
+	"math/bits"
+	"strconv"
+
+	// Register the decoders for the image formats that can be hashed
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxHashableSize is the maximal size (in bytes) of an image for computing its
+// perceptual hash.
+const MaxHashableSize = 50 * 1024 * 1024
+
+// ErrNotHashable is used when the perceptual hash of a file cannot be computed
+// (not an image, unsupported format, too large, etc.).
+var ErrNotHashable = errors.New("The perceptual hash of this file cannot be computed")
+
+// DHash computes the difference hash of an image: the image is reduced to a
+// 9x8 grayscale image, and each bit of the hash tells if a pixel is brighter
+// than its right neighbour. Similar images have hashes with a small hamming
+// distance.
+func DHash(img image.Image) uint64 {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.CatmullRom.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if small.GrayAt(x, y).Y > small.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// Distance returns the hamming distance between two perceptual hashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func formatHash(hash uint64) string {
+	return strconv.FormatUint(hash, 16)
+}
+
+func parseHash(hash string) (uint64, error) {
+	return strconv.ParseUint(hash, 16, 64)
+}
+
+func computeHash(fs vfs.VFS, file *vfs.FileDoc) (uint64, error) {
+	if file.Class != "image" || file.ByteSize > MaxHashableSize {
+		return 0, ErrNotHashable
+	}
+	f, err := fs.OpenFile(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, ErrNotHashable
+	}
+	return DHash(img), nil
+}
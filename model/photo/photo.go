@@ -0,0 +1,194 @@
+// Package photo is for detecting the near-duplicates and the bursts in the
+// photos of a Cozy. A perceptual hash is computed for each image, and the
+// similar images are grouped in suggestions that the Photos application can
+// present to the user for cleaning them.
+package photo
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// WorkerType is the type of the worker that detects the duplicates and the
+// bursts.
+const WorkerType = "photos-duplicates"
+
+// The types of suggestions.
+const (
+	// SuggestionDuplicate is a group of photos that look the same.
+	SuggestionDuplicate = "duplicate"
+	// SuggestionBurst is a group of similar photos taken in a few seconds.
+	SuggestionBurst = "burst"
+)
+
+// DuplicateDistance is the maximal distance between the perceptual hashes of
+// two photos for them to be considered as duplicates.
+var DuplicateDistance = 6
+
+// BurstDistance is the maximal distance between the perceptual hashes of two
+// consecutive photos of a burst.
+var BurstDistance = 20
+
+// BurstInterval is the maximal delay between two consecutive photos of a
+// burst.
+var BurstInterval = 3 * time.Second
+
+// MinBurstSize is the minimal number of photos for a burst.
+var MinBurstSize = 3
+
+// Hash is the perceptual hash of an image, with some information about the
+// file. Its identifier is the same as the file.
+type Hash struct {
+	DocID   string    `json:"_id,omitempty"`
+	DocRev  string    `json:"_rev,omitempty"`
+	PHash   string    `json:"phash"`
+	MD5Sum  []byte    `json:"md5sum"`
+	DirID   string    `json:"dir_id"`
+	TakenAt time.Time `json:"taken_at"`
+
+	value uint64
+}
+
+// ID returns the hash identifier
+func (h *Hash) ID() string { return h.DocID }
+
+// Rev returns the hash revision
+func (h *Hash) Rev() string { return h.DocRev }
+
+// DocType returns the hash document type
+func (h *Hash) DocType() string { return consts.PhotosHashes }
+
+// Clone implements couchdb.Doc
+func (h *Hash) Clone() couchdb.Doc {
+	cloned := *h
+	cloned.MD5Sum = make([]byte, len(h.MD5Sum))
+	copy(cloned.MD5Sum, h.MD5Sum)
+	return &cloned
+}
+
+// SetID changes the hash identifier
+func (h *Hash) SetID(id string) { h.DocID = id }
+
+// SetRev changes the hash revision
+func (h *Hash) SetRev(rev string) { h.DocRev = rev }
+
+// Suggestion is a group of photos that the user may want to clean up. The
+// user can dismiss it, and it won't be suggested again, unless some new
+// photos are added to the group.
+type Suggestion struct {
+	DocID     string    `json:"_id,omitempty"`
+	DocRev    string    `json:"_rev,omitempty"`
+	Type      string    `json:"type"`
+	FileIDs   []string  `json:"file_ids"`
+	Dismissed bool      `json:"dismissed,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ID returns the suggestion identifier
+func (s *Suggestion) ID() string { return s.DocID }
+
+// Rev returns the suggestion revision
+func (s *Suggestion) Rev() string { return s.DocRev }
+
+// DocType returns the suggestion document type
+func (s *Suggestion) DocType() string { return consts.PhotosSuggestions }
+
+// Clone implements couchdb.Doc
+func (s *Suggestion) Clone() couchdb.Doc {
+	cloned := *s
+	cloned.FileIDs = make([]string, len(s.FileIDs))
+	copy(cloned.FileIDs, s.FileIDs)
+	return &cloned
+}
+
+// SetID changes the suggestion identifier
+func (s *Suggestion) SetID(id string) { s.DocID = id }
+
+// SetRev changes the suggestion revision
+func (s *Suggestion) SetRev(rev string) { s.DocRev = rev }
+
+// Contains returns true if the given file is in the suggestion.
+func (s *Suggestion) Contains(fileID string) bool {
+	for _, id := range s.FileIDs {
+		if id == fileID {
+			return true
+		}
+	}
+	return false
+}
+
+// Message is the message for the photos-duplicates worker.
+type Message struct {
+	FileID string `json:"file_id,omitempty"`
+	Rescan bool   `json:"rescan,omitempty"`
+}
+
+// PushJob pushes a job for the photos-duplicates worker.
+func PushJob(inst *instance.Instance, msg *Message) (*job.Job, error) {
+	m, err := job.NewMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: WorkerType,
+		Message:    m,
+	})
+}
+
+// takenAt returns the date when the photo has been taken, or the creation
+// date of the file if it is not known.
+func takenAt(file *vfs.FileDoc) time.Time {
+	switch date := file.Metadata["datetime"].(type) {
+	case time.Time:
+		return date.UTC()
+	case string:
+		if t, err := time.Parse(time.RFC3339, date); err == nil {
+			return t.UTC()
+		}
+	}
+	return file.CreatedAt.UTC()
+}
+
+func loadHashes(inst *instance.Instance) ([]*Hash, error) {
+	var hashes []*Hash
+	err := couchdb.ForeachDocs(inst, consts.PhotosHashes, func(_ string, data json.RawMessage) error {
+		var h Hash
+		if err := json.Unmarshal(data, &h); err != nil {
+			return err
+		}
+		value, err := parseHash(h.PHash)
+		if err != nil {
+			return nil
+		}
+		h.value = value
+		hashes = append(hashes, &h)
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+func loadSuggestions(inst *instance.Instance) ([]*Suggestion, error) {
+	var suggestions []*Suggestion
+	err := couchdb.ForeachDocs(inst, consts.PhotosSuggestions, func(_ string, data json.RawMessage) error {
+		var s Suggestion
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		suggestions = append(suggestions, &s)
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return suggestions, nil
+}
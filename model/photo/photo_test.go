@@ -0,0 +1,73 @@
+package photo
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gradient(width, height int, reversed bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8((x*255/width + y*64/height) % 256)
+			if reversed {
+				v = 255 - v
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	a := DHash(gradient(640, 480, false))
+	b := DHash(gradient(320, 240, false))
+	c := DHash(gradient(640, 480, true))
+	assert.LessOrEqual(t, Distance(a, b), DuplicateDistance)
+	assert.Greater(t, Distance(a, c), BurstDistance)
+
+	h, err := parseHash(formatHash(a))
+	assert.NoError(t, err)
+	assert.Equal(t, a, h)
+}
+
+func TestGroupDuplicates(t *testing.T) {
+	hashes := []*Hash{
+		{DocID: "a", value: 0x0000000000000000},
+		{DocID: "b", value: 0x0000000000000003},
+		{DocID: "c", value: 0xffffffffffffffff},
+		{DocID: "d", value: 0x000000000000003f},
+		{DocID: "e", value: 0x00000000ffff0000},
+	}
+	groups := groupDuplicates(hashes)
+	assert.Equal(t, [][]string{{"a", "b", "d"}}, groups)
+}
+
+func TestGroupBursts(t *testing.T) {
+	now := time.Date(2023, 7, 14, 10, 0, 0, 0, time.UTC)
+	hashes := []*Hash{
+		{DocID: "a", DirID: "dir", TakenAt: now, value: 0x0},
+		{DocID: "b", DirID: "dir", TakenAt: now.Add(1 * time.Second), value: 0xff},
+		{DocID: "c", DirID: "dir", TakenAt: now.Add(2 * time.Second), value: 0xfff},
+		// Too long after the previous one
+		{DocID: "d", DirID: "dir", TakenAt: now.Add(1 * time.Minute), value: 0xfff},
+		{DocID: "e", DirID: "dir", TakenAt: now.Add(61 * time.Second), value: 0xfff},
+		// Another directory
+		{DocID: "f", DirID: "other", TakenAt: now.Add(62 * time.Second), value: 0xfff},
+	}
+	groups := groupBursts(hashes)
+	assert.Equal(t, [][]string{{"a", "b", "c"}}, groups)
+}
+
+func TestIsDismissed(t *testing.T) {
+	dismissed := []*Suggestion{
+		{Type: SuggestionDuplicate, FileIDs: []string{"a", "b", "c"}, Dismissed: true},
+	}
+	assert.True(t, isDismissed(SuggestionDuplicate, []string{"a", "c"}, dismissed))
+	assert.False(t, isDismissed(SuggestionBurst, []string{"a", "c"}, dismissed))
+	assert.False(t, isDismissed(SuggestionDuplicate, []string{"a", "d"}, dismissed))
+}
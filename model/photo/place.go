@@ -0,0 +1,560 @@
+package photo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/geocoding"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+)
+
+// GeoWorkerType is the type of the worker that geocodes the photos and
+// computes the geo-clusters.
+const GeoWorkerType = "photos-geo"
+
+// MaxClusterPrecision is the length of the geohash for the smallest clusters
+// (~1.2km x 0.6km).
+const MaxClusterPrecision = 6
+
+// locationPrecision is the length of the geohash saved for each photo.
+const locationPrecision = 8
+
+// Location is the GPS coordinates of a photo, with the name of the place
+// where it has been taken. Its identifier is the same as the file.
+type Location struct {
+	DocID    string           `json:"_id,omitempty"`
+	DocRev   string           `json:"_rev,omitempty"`
+	Lat      float64          `json:"lat"`
+	Long     float64          `json:"long"`
+	Geohash  string           `json:"geohash"`
+	Place    *geocoding.Place `json:"place,omitempty"`
+	Geocoded bool             `json:"geocoded,omitempty"`
+}
+
+// ID returns the location identifier
+func (l *Location) ID() string { return l.DocID }
+
+// Rev returns the location revision
+func (l *Location) Rev() string { return l.DocRev }
+
+// DocType returns the location document type
+func (l *Location) DocType() string { return consts.PhotosLocations }
+
+// Clone implements couchdb.Doc
+func (l *Location) Clone() couchdb.Doc {
+	cloned := *l
+	if l.Place != nil {
+		place := *l.Place
+		cloned.Place = &place
+	}
+	return &cloned
+}
+
+// SetID changes the location identifier
+func (l *Location) SetID(id string) { l.DocID = id }
+
+// SetRev changes the location revision
+func (l *Location) SetRev(rev string) { l.DocRev = rev }
+
+// Cluster is a group of photos taken in the same geohash cell. There are
+// clusters for each precision from 1 to MaxClusterPrecision, and the map view
+// can use the precision that fits its zoom level.
+type Cluster struct {
+	DocID     string  `json:"_id,omitempty"`
+	DocRev    string  `json:"_rev,omitempty"`
+	Precision int     `json:"precision"`
+	Geohash   string  `json:"geohash"`
+	Count     int     `json:"count"`
+	Lat       float64 `json:"lat"`
+	Long      float64 `json:"long"`
+	CoverID   string  `json:"cover_id,omitempty"`
+	Place     string  `json:"place,omitempty"`
+}
+
+// ID returns the cluster identifier
+func (c *Cluster) ID() string { return c.DocID }
+
+// Rev returns the cluster revision
+func (c *Cluster) Rev() string { return c.DocRev }
+
+// DocType returns the cluster document type
+func (c *Cluster) DocType() string { return consts.PhotosGeoClusters }
+
+// Clone implements couchdb.Doc
+func (c *Cluster) Clone() couchdb.Doc {
+	cloned := *c
+	return &cloned
+}
+
+// SetID changes the cluster identifier
+func (c *Cluster) SetID(id string) { c.DocID = id }
+
+// SetRev changes the cluster revision
+func (c *Cluster) SetRev(rev string) { c.DocRev = rev }
+
+// Included is part of the jsonapi.Object interface
+func (c *Cluster) Included() []jsonapi.Object { return nil }
+
+// Links is part of the jsonapi.Object interface
+func (c *Cluster) Links() *jsonapi.LinksList { return nil }
+
+// Relationships is part of the jsonapi.Object interface
+func (c *Cluster) Relationships() jsonapi.RelationshipMap {
+	if c.CoverID == "" {
+		return nil
+	}
+	return jsonapi.RelationshipMap{
+		"cover": jsonapi.Relationship{
+			Data: couchdb.DocReference{ID: c.CoverID, Type: consts.Files},
+		},
+	}
+}
+
+func clusterID(precision int, geohash string) string {
+	return fmt.Sprintf("%d-%s", precision, geohash[:precision])
+}
+
+// BBox is a bounding box, used to filter the clusters for a map view.
+type BBox struct {
+	West, South, East, North float64
+}
+
+// Contains returns true if the point is in the bounding box.
+func (b *BBox) Contains(lat, long float64) bool {
+	if lat < b.South || lat > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return long >= b.West && long <= b.East
+	}
+	// The bounding box crosses the antimeridian
+	return long >= b.West || long <= b.East
+}
+
+// PrecisionForZoom returns the precision of the clusters to use for a zoom
+// level of a map (0 for the whole world, 18+ for a street).
+func PrecisionForZoom(zoom int) int {
+	switch {
+	case zoom <= 2:
+		return 1
+	case zoom <= 5:
+		return 2
+	case zoom <= 7:
+		return 3
+	case zoom <= 10:
+		return 4
+	case zoom <= 12:
+		return 5
+	}
+	return MaxClusterPrecision
+}
+
+// GeoMessage is the message for the photos-geo worker.
+type GeoMessage struct {
+	FileID  string `json:"file_id,omitempty"`
+	Rebuild bool   `json:"rebuild,omitempty"`
+}
+
+// PushGeoJob pushes a job for the photos-geo worker.
+func PushGeoJob(inst *instance.Instance, msg *GeoMessage) (*job.Job, error) {
+	m, err := job.NewMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: GeoWorkerType,
+		Message:    m,
+	})
+}
+
+// HasGPS returns true if the file has some GPS coordinates in its metadata.
+func HasGPS(file *vfs.FileDoc) bool {
+	_, _, ok := gpsOf(file)
+	return ok
+}
+
+func gpsOf(file *vfs.FileDoc) (float64, float64, bool) {
+	if file == nil || file.Metadata == nil {
+		return 0, 0, false
+	}
+	switch gps := file.Metadata["gps"].(type) {
+	case map[string]float64:
+		lat, okLat := gps["lat"]
+		long, okLong := gps["long"]
+		return lat, long, okLat && okLong
+	case map[string]interface{}:
+		lat, okLat := gps["lat"].(float64)
+		long, okLong := gps["long"].(float64)
+		return lat, long, okLat && okLong
+	}
+	return 0, 0, false
+}
+
+// Locate adds the given photo to the geo-clusters, and enriches it with the
+// name of the place where it has been taken. If the photo has been deleted,
+// or has no longer GPS coordinates, it is removed from the clusters.
+func Locate(inst *instance.Instance, fileID string) error {
+	mu := config.Lock().ReadWrite(inst, "photos-geo")
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	var prev *Location
+	var loc Location
+	err := couchdb.GetDoc(inst, consts.PhotosLocations, fileID, &loc)
+	if err == nil {
+		prev = &loc
+	} else if !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+
+	fs := inst.VFS()
+	file, err := fs.FileByID(fileID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	lat, long, ok := gpsOf(file)
+	if !ok || file.Trashed {
+		if prev == nil {
+			return nil
+		}
+		if err := removeFromClusters(inst, prev); err != nil {
+			return err
+		}
+		return couchdb.DeleteDoc(inst, prev)
+	}
+
+	moved := prev == nil || prev.Lat != lat || prev.Long != long
+	if prev != nil && !moved && prev.Geocoded {
+		return nil
+	}
+	if prev != nil && moved {
+		if err := removeFromClusters(inst, prev); err != nil {
+			return err
+		}
+	}
+	current := &Location{DocID: fileID, Lat: lat, Long: long}
+	if prev != nil {
+		current = prev
+		if moved {
+			current.Lat, current.Long = lat, long
+			current.Place, current.Geocoded = nil, false
+		}
+	}
+	current.Geohash = Geohash(lat, long, locationPrecision)
+	if !current.Geocoded {
+		reverseGeocode(inst, current)
+	}
+	if !moved && !current.Geocoded {
+		return nil
+	}
+	if current.DocRev == "" {
+		err = couchdb.CreateNamedDocWithDB(inst, current)
+	} else {
+		err = couchdb.UpdateDoc(inst, current)
+	}
+	if err != nil {
+		return err
+	}
+	if moved {
+		if err := addToClusters(inst, current); err != nil {
+			return err
+		}
+	}
+	return savePlace(fs, file, current.Place)
+}
+
+// RebuildClusters computes the locations of all the photos, and rebuilds the
+// geo-clusters from scratch. The places already known are kept, to avoid
+// calling the geocoder again.
+func RebuildClusters(inst *instance.Instance) error {
+	mu := config.Lock().ReadWrite(inst, "photos-geo")
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	log := inst.Logger().WithNamespace("photos")
+	locations := make(map[string]*Location)
+	err := couchdb.ForeachDocs(inst, consts.PhotosLocations, func(_ string, data json.RawMessage) error {
+		var loc Location
+		if err := json.Unmarshal(data, &loc); err != nil {
+			return err
+		}
+		locations[loc.DocID] = &loc
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+
+	var previous []couchdb.Doc
+	err = couchdb.ForeachDocs(inst, consts.PhotosGeoClusters, func(_ string, data json.RawMessage) error {
+		var c Cluster
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		previous = append(previous, &c)
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+	if len(previous) > 0 {
+		if err := couchdb.BulkDeleteDocs(inst, consts.PhotosGeoClusters, previous); err != nil {
+			return err
+		}
+	}
+
+	clusters := make(map[string]*Cluster)
+	fs := inst.VFS()
+	err = vfs.Walk(fs, "/", func(_ string, dir *vfs.DirDoc, file *vfs.FileDoc, err error) error {
+		if err != nil {
+			return err
+		}
+		if dir != nil || file.Trashed {
+			return nil
+		}
+		lat, long, ok := gpsOf(file)
+		if !ok {
+			return nil
+		}
+		loc, found := locations[file.ID()]
+		delete(locations, file.ID())
+		changed := !found
+		if !found {
+			loc = &Location{DocID: file.ID()}
+		}
+		if loc.Lat != lat || loc.Long != long {
+			loc.Lat, loc.Long = lat, long
+			loc.Place, loc.Geocoded = nil, false
+			changed = true
+		}
+		loc.Geohash = Geohash(lat, long, locationPrecision)
+		if !loc.Geocoded {
+			reverseGeocode(inst, loc)
+			changed = true
+		}
+		if changed {
+			if loc.DocRev == "" {
+				err = couchdb.CreateNamedDocWithDB(inst, loc)
+			} else {
+				err = couchdb.UpdateDoc(inst, loc)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := savePlace(fs, file, loc.Place); err != nil {
+			log.Infof("Cannot save the place of %s: %s", file.ID(), err)
+		}
+		for precision := 1; precision <= MaxClusterPrecision; precision++ {
+			id := clusterID(precision, loc.Geohash)
+			c, ok := clusters[id]
+			if !ok {
+				c = newCluster(precision, loc.Geohash)
+				clusters[id] = c
+			}
+			c.add(loc)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, loc := range locations {
+		if err := couchdb.DeleteDoc(inst, loc); err != nil {
+			return err
+		}
+	}
+	for _, c := range clusters {
+		if err := couchdb.CreateNamedDocWithDB(inst, c); err != nil {
+			return err
+		}
+	}
+	log.Infof("Rebuild of the geo-clusters: %d clusters", len(clusters))
+	return nil
+}
+
+// ListClusters returns the clusters for the given precision, in the bounding
+// box if it is not nil.
+func ListClusters(inst *instance.Instance, precision int, bbox *BBox) ([]*Cluster, error) {
+	var clusters []*Cluster
+	req := couchdb.AllDocsRequest{
+		Limit:    1000,
+		StartKey: fmt.Sprintf("%d-", precision),
+		EndKey:   fmt.Sprintf("%d-%s", precision, couchdb.MaxString),
+	}
+	for {
+		var page []*Cluster
+		if err := couchdb.GetAllDocs(inst, consts.PhotosGeoClusters, &req, &page); err != nil {
+			if couchdb.IsNoDatabaseError(err) {
+				return clusters, nil
+			}
+			return nil, err
+		}
+		for _, c := range page {
+			if bbox == nil || bbox.Contains(c.Lat, c.Long) {
+				clusters = append(clusters, c)
+			}
+		}
+		if len(page) < req.Limit {
+			break
+		}
+		req.StartKey = page[len(page)-1].DocID
+		req.Skip = 1
+	}
+	return clusters, nil
+}
+
+func newCluster(precision int, geohash string) *Cluster {
+	return &Cluster{
+		DocID:     clusterID(precision, geohash),
+		Precision: precision,
+		Geohash:   geohash[:precision],
+	}
+}
+
+func (c *Cluster) add(loc *Location) {
+	n := float64(c.Count)
+	c.Lat = (c.Lat*n + loc.Lat) / (n + 1)
+	c.Long = (c.Long*n + loc.Long) / (n + 1)
+	c.Count++
+	if c.CoverID == "" {
+		c.CoverID = loc.DocID
+	}
+	if c.Place == "" && loc.Place != nil {
+		c.Place = loc.Place.Name()
+	}
+}
+
+func (c *Cluster) remove(loc *Location) {
+	c.Count--
+	if c.Count <= 0 {
+		return
+	}
+	n := float64(c.Count)
+	c.Lat = (c.Lat*(n+1) - loc.Lat) / n
+	c.Long = (c.Long*(n+1) - loc.Long) / n
+}
+
+func addToClusters(inst *instance.Instance, loc *Location) error {
+	for precision := 1; precision <= MaxClusterPrecision; precision++ {
+		var c Cluster
+		err := couchdb.GetDoc(inst, consts.PhotosGeoClusters, clusterID(precision, loc.Geohash), &c)
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			cluster := newCluster(precision, loc.Geohash)
+			cluster.add(loc)
+			err = couchdb.CreateNamedDocWithDB(inst, cluster)
+		} else if err == nil {
+			c.add(loc)
+			err = couchdb.UpdateDoc(inst, &c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeFromClusters(inst *instance.Instance, loc *Location) error {
+	for precision := 1; precision <= MaxClusterPrecision; precision++ {
+		var c Cluster
+		err := couchdb.GetDoc(inst, consts.PhotosGeoClusters, clusterID(precision, loc.Geohash), &c)
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		c.remove(loc)
+		if c.Count <= 0 {
+			err = couchdb.DeleteDoc(inst, &c)
+		} else {
+			if c.CoverID == loc.DocID {
+				c.CoverID = findCover(inst, c.Geohash, loc.DocID)
+			}
+			err = couchdb.UpdateDoc(inst, &c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findCover returns the identifier of another photo in the cluster.
+func findCover(inst *instance.Instance, geohash, excluded string) string {
+	var locations []*Location
+	req := &couchdb.FindRequest{
+		UseIndex: "by-geohash",
+		Selector: mango.And(
+			mango.Gte("geohash", geohash),
+			mango.Lt("geohash", geohash+couchdb.MaxString),
+		),
+		Limit: 2,
+	}
+	if err := couchdb.FindDocs(inst, consts.PhotosLocations, req, &locations); err != nil {
+		return ""
+	}
+	for _, loc := range locations {
+		if loc.DocID != excluded {
+			return loc.DocID
+		}
+	}
+	return ""
+}
+
+func reverseGeocode(inst *instance.Instance, loc *Location) {
+	geocoder, err := geocoding.Get()
+	if err != nil {
+		if !errors.Is(err, geocoding.ErrNotConfigured) {
+			inst.Logger().WithNamespace("photos").Warnf("Cannot use the geocoder: %s", err)
+		}
+		return
+	}
+	place, err := geocoder.Reverse(loc.Lat, loc.Long)
+	if err != nil && !errors.Is(err, geocoding.ErrNoPlace) {
+		inst.Logger().WithNamespace("photos").
+			Infof("Cannot find the place for %s: %s", loc.DocID, err)
+		return
+	}
+	loc.Place = place
+	loc.Geocoded = true
+}
+
+// savePlace adds the place to the metadata of the file, if it has changed.
+func savePlace(fs vfs.VFS, file *vfs.FileDoc, place *geocoding.Place) error {
+	if place == nil {
+		return nil
+	}
+	if old, ok := file.Metadata["place"].(map[string]interface{}); ok {
+		if old["city"] == place.City && old["country_code"] == place.CountryCode {
+			return nil
+		}
+	}
+	newFile := file.Clone().(*vfs.FileDoc)
+	newFile.Metadata["place"] = map[string]interface{}{
+		"name":         place.Name(),
+		"city":         place.City,
+		"region":       place.Region,
+		"country":      place.Country,
+		"country_code": place.CountryCode,
+	}
+	if newFile.CozyMetadata != nil {
+		newFile.CozyMetadata.UpdatedAt = time.Now()
+	}
+	return fs.UpdateFileDoc(file, newFile)
+}
@@ -0,0 +1,61 @@
+package photo
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/geocoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", Geohash(57.64911, 10.40744, 11))
+	assert.Equal(t, "u09tvw", Geohash(48.8566, 2.3522, 6))
+	assert.Equal(t, "1-u", clusterID(1, "u09tvw0r"))
+	assert.Equal(t, "6-u09tvw", clusterID(6, "u09tvw0r"))
+}
+
+func TestCluster(t *testing.T) {
+	paris := &Location{DocID: "a", Lat: 48.8, Long: 2.4, Place: &geocoding.Place{City: "Paris", Country: "France"}}
+	lyon := &Location{DocID: "b", Lat: 45.8, Long: 4.8}
+
+	c := newCluster(1, "u09tvw0r")
+	assert.Equal(t, "u", c.Geohash)
+	c.add(lyon)
+	c.add(paris)
+	assert.Equal(t, 2, c.Count)
+	assert.Equal(t, "b", c.CoverID)
+	assert.Equal(t, "Paris, France", c.Place)
+	assert.InDelta(t, 47.3, c.Lat, 0.0001)
+	assert.InDelta(t, 3.6, c.Long, 0.0001)
+
+	c.remove(lyon)
+	assert.Equal(t, 1, c.Count)
+	assert.InDelta(t, 48.8, c.Lat, 0.0001)
+	assert.InDelta(t, 2.4, c.Long, 0.0001)
+}
+
+func TestBBox(t *testing.T) {
+	bbox := &BBox{West: -5, South: 41, East: 10, North: 51}
+	assert.True(t, bbox.Contains(48.8, 2.4))
+	assert.False(t, bbox.Contains(51.5, -0.1))
+
+	pacific := &BBox{West: 170, South: -50, East: -170, North: 0}
+	assert.True(t, pacific.Contains(-17.5, -175))
+	assert.True(t, pacific.Contains(-17.5, 175))
+	assert.False(t, pacific.Contains(-17.5, 0))
+
+	assert.Equal(t, 1, PrecisionForZoom(0))
+	assert.Equal(t, 4, PrecisionForZoom(9))
+	assert.Equal(t, MaxClusterPrecision, PrecisionForZoom(18))
+}
+
+func TestHasGPS(t *testing.T) {
+	file := &vfs.FileDoc{Metadata: vfs.Metadata{
+		"gps": map[string]interface{}{"lat": 48.8, "long": 2.4},
+	}}
+	assert.True(t, HasGPS(file))
+	file.Metadata["gps"] = map[string]float64{"lat": 48.8}
+	assert.False(t, HasGPS(file))
+	assert.False(t, HasGPS(&vfs.FileDoc{}))
+}
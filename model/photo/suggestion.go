@@ -0,0 +1,424 @@
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// Process computes the perceptual hash of the given file, and adds it to the
+// suggestions of duplicates and bursts with the similar photos. If the file
+// has been deleted or trashed, it is removed from the suggestions.
+func Process(inst *instance.Instance, fileID string) error {
+	mu := config.Lock().ReadWrite(inst, "photos-duplicates")
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	suggestions, err := loadSuggestions(inst)
+	if err != nil {
+		return err
+	}
+	fs := inst.VFS()
+	file, err := fs.FileByID(fileID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if file == nil || file.Trashed || file.Class != "image" {
+		return forget(inst, fileID, suggestions)
+	}
+
+	hashes, err := loadHashes(inst)
+	if err != nil {
+		return err
+	}
+	var old *Hash
+	for _, h := range hashes {
+		if h.DocID == fileID {
+			old = h
+		}
+	}
+	if old != nil && !bytes.Equal(old.MD5Sum, file.MD5Sum) {
+		// The content has changed, the photo may no longer be similar to
+		// the other photos of its groups.
+		if err := removeFromSuggestions(inst, fileID, suggestions); err != nil {
+			return err
+		}
+	}
+	h, err := updateHash(inst, fs, file, old)
+	if errors.Is(err, ErrNotHashable) {
+		return forget(inst, fileID, suggestions)
+	}
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		hashes = append(hashes, h)
+	}
+
+	var duplicates []string
+	for _, other := range hashes {
+		if other.DocID != h.DocID && Distance(other.value, h.value) <= DuplicateDistance {
+			duplicates = append(duplicates, other.DocID)
+		}
+	}
+	if len(duplicates) > 0 {
+		duplicates = append(duplicates, h.DocID)
+		if err := mergeSuggestion(inst, SuggestionDuplicate, duplicates, suggestions); err != nil {
+			return err
+		}
+	}
+
+	for _, burst := range groupBursts(hashes) {
+		if contains(burst, h.DocID) {
+			if err := mergeSuggestion(inst, SuggestionBurst, burst, suggestions); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Rescan computes the perceptual hashes of all the images, and rebuilds the
+// suggestions. The suggestions dismissed by the user are kept, and their
+// groups are not suggested again.
+func Rescan(inst *instance.Instance) error {
+	mu := config.Lock().ReadWrite(inst, "photos-duplicates")
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	log := inst.Logger().WithNamespace("photos")
+	previous, err := loadHashes(inst)
+	if err != nil {
+		return err
+	}
+	stale := make(map[string]*Hash, len(previous))
+	for _, h := range previous {
+		stale[h.DocID] = h
+	}
+
+	var hashes []*Hash
+	fs := inst.VFS()
+	err = vfs.Walk(fs, "/", func(_ string, dir *vfs.DirDoc, file *vfs.FileDoc, err error) error {
+		if err != nil {
+			return err
+		}
+		if dir != nil || file.Trashed || file.Class != "image" {
+			return nil
+		}
+		h, err := updateHash(inst, fs, file, stale[file.ID()])
+		if err != nil {
+			if !errors.Is(err, ErrNotHashable) {
+				log.Infof("Cannot compute the hash of %s: %s", file.ID(), err)
+			}
+			return nil
+		}
+		delete(stale, file.ID())
+		hashes = append(hashes, h)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, h := range stale {
+		if err := couchdb.DeleteDoc(inst, h); err != nil {
+			return err
+		}
+	}
+
+	suggestions, err := loadSuggestions(inst)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		known[h.DocID] = true
+	}
+	var dismissed []*Suggestion
+	for _, s := range suggestions {
+		if !s.Dismissed {
+			if err := couchdb.DeleteDoc(inst, s); err != nil {
+				return err
+			}
+			continue
+		}
+		ids := s.FileIDs[:0]
+		for _, id := range s.FileIDs {
+			if known[id] {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) < 2 {
+			if err := couchdb.DeleteDoc(inst, s); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(ids) != len(s.FileIDs) {
+			s.FileIDs = ids
+			if err := couchdb.UpdateDoc(inst, s); err != nil {
+				return err
+			}
+		}
+		dismissed = append(dismissed, s)
+	}
+
+	created := 0
+	groups := map[string][][]string{
+		SuggestionDuplicate: groupDuplicates(hashes),
+		SuggestionBurst:     groupBursts(hashes),
+	}
+	for typ, list := range groups {
+		for _, group := range list {
+			if isDismissed(typ, group, dismissed) {
+				continue
+			}
+			if err := createSuggestion(inst, typ, group); err != nil {
+				return err
+			}
+			created++
+		}
+	}
+	log.Infof("Rescan of %d photos: %d suggestions", len(hashes), created)
+	return nil
+}
+
+// groupDuplicates returns the groups of photos whose perceptual hashes are
+// close.
+func groupDuplicates(hashes []*Hash) [][]string {
+	parents := make([]int, len(hashes))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if Distance(hashes[i].value, hashes[j].value) <= DuplicateDistance {
+				parents[find(j)] = find(i)
+			}
+		}
+	}
+
+	byRoot := make(map[int][]string)
+	for i, h := range hashes {
+		root := find(i)
+		byRoot[root] = append(byRoot[root], h.DocID)
+	}
+	var groups [][]string
+	for _, ids := range byRoot {
+		if len(ids) > 1 {
+			sort.Strings(ids)
+			groups = append(groups, ids)
+		}
+	}
+	sortGroups(groups)
+	return groups
+}
+
+// groupBursts returns the groups of similar photos that have been taken in
+// the same directory with only a few seconds between them.
+func groupBursts(hashes []*Hash) [][]string {
+	sorted := make([]*Hash, len(hashes))
+	copy(sorted, hashes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].TakenAt.Equal(sorted[j].TakenAt) {
+			return sorted[i].DocID < sorted[j].DocID
+		}
+		return sorted[i].TakenAt.Before(sorted[j].TakenAt)
+	})
+
+	var groups [][]string
+	var current []*Hash
+	flush := func() {
+		if len(current) >= MinBurstSize {
+			ids := make([]string, len(current))
+			for i, h := range current {
+				ids[i] = h.DocID
+			}
+			sort.Strings(ids)
+			groups = append(groups, ids)
+		}
+		current = nil
+	}
+	for _, h := range sorted {
+		if len(current) > 0 {
+			prev := current[len(current)-1]
+			if prev.DirID != h.DirID ||
+				h.TakenAt.Sub(prev.TakenAt) > BurstInterval ||
+				Distance(prev.value, h.value) > BurstDistance {
+				flush()
+			}
+		}
+		current = append(current, h)
+	}
+	flush()
+	sortGroups(groups)
+	return groups
+}
+
+func sortGroups(groups [][]string) {
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+}
+
+func updateHash(inst *instance.Instance, fs vfs.VFS, file *vfs.FileDoc, h *Hash) (*Hash, error) {
+	if h != nil && bytes.Equal(h.MD5Sum, file.MD5Sum) {
+		if h.DirID == file.DirID {
+			return h, nil
+		}
+		h.DirID = file.DirID
+		return h, couchdb.UpdateDoc(inst, h)
+	}
+
+	value, err := computeHash(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		h = &Hash{DocID: file.ID()}
+	}
+	h.PHash = formatHash(value)
+	h.value = value
+	h.MD5Sum = file.MD5Sum
+	h.DirID = file.DirID
+	h.TakenAt = takenAt(file)
+	if h.DocRev == "" {
+		err = couchdb.CreateNamedDocWithDB(inst, h)
+	} else {
+		err = couchdb.UpdateDoc(inst, h)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// forget removes a file from the hashes and the suggestions.
+func forget(inst *instance.Instance, fileID string, suggestions []*Suggestion) error {
+	var h Hash
+	err := couchdb.GetDoc(inst, h.DocType(), fileID, &h)
+	if err == nil {
+		err = couchdb.DeleteDoc(inst, &h)
+	}
+	if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+	return removeFromSuggestions(inst, fileID, suggestions)
+}
+
+func removeFromSuggestions(inst *instance.Instance, fileID string, suggestions []*Suggestion) error {
+	for _, s := range suggestions {
+		if !s.Contains(fileID) {
+			continue
+		}
+		ids := make([]string, 0, len(s.FileIDs))
+		for _, id := range s.FileIDs {
+			if id != fileID {
+				ids = append(ids, id)
+			}
+		}
+		s.FileIDs = ids
+		s.UpdatedAt = time.Now().UTC()
+		var err error
+		if len(ids) < 2 {
+			err = couchdb.DeleteDoc(inst, s)
+			s.FileIDs = nil
+		} else {
+			err = couchdb.UpdateDoc(inst, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeSuggestion adds the group to an existing suggestion of the same type
+// with some photos in common, or creates a new suggestion.
+func mergeSuggestion(inst *instance.Instance, typ string, group []string, suggestions []*Suggestion) error {
+	for _, s := range suggestions {
+		if s.Type != typ {
+			continue
+		}
+		common := false
+		var added []string
+		for _, id := range group {
+			if s.Contains(id) {
+				common = true
+			} else {
+				added = append(added, id)
+			}
+		}
+		if !common {
+			continue
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		s.FileIDs = append(s.FileIDs, added...)
+		sort.Strings(s.FileIDs)
+		s.Dismissed = false
+		s.UpdatedAt = time.Now().UTC()
+		return couchdb.UpdateDoc(inst, s)
+	}
+	return createSuggestion(inst, typ, group)
+}
+
+func createSuggestion(inst *instance.Instance, typ string, group []string) error {
+	ids := make([]string, len(group))
+	copy(ids, group)
+	sort.Strings(ids)
+	now := time.Now().UTC()
+	s := &Suggestion{
+		Type:      typ,
+		FileIDs:   ids,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return couchdb.CreateDoc(inst, s)
+}
+
+func isDismissed(typ string, group []string, dismissed []*Suggestion) bool {
+	for _, s := range dismissed {
+		if s.Type != typ {
+			continue
+		}
+		all := true
+		for _, id := range group {
+			if !s.Contains(id) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, v := range haystack {
+		if v == needle {
+			return true
+		}
+	}
+	return false
+}
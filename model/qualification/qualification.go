@@ -0,0 +1,249 @@
+// Package qualification is used to qualify automatically the new files, with
+// some rules configured in the context of the instance. It allows to qualify
+// the files from the konnectors, the uploads, etc. without waiting for an
+// application to do it.
+package qualification
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// WorkerType is the type of the worker that qualifies the new files.
+const WorkerType = "qualification"
+
+// QualificationKey is the key in the metadata of a file for its
+// qualification.
+const QualificationKey = "qualification"
+
+// OCRKey is the key in the metadata of a file where an OCR service can put
+// the text of the file.
+const OCRKey = "ocr"
+
+// maxTextSize is the maximal number of bytes read from a text file to match
+// the text of a rule.
+const maxTextSize = 64 * 1024
+
+// Rule is a rule to qualify a file: if the file matches all the criteria of
+// the rule, the qualification is added to its metadata. The criteria that are
+// empty are ignored.
+type Rule struct {
+	// Qualification is the value set in the metadata of the file, like
+	// {"label": "bank_statement"}.
+	Qualification map[string]interface{} `json:"qualification"`
+	// Mime is a prefix of the mime type (image/ or application/pdf)
+	Mime string `json:"mime,omitempty"`
+	// Class is the class of the file (image, pdf, text, etc.)
+	Class string `json:"class,omitempty"`
+	// Name is a regular expression for the name of the file
+	Name string `json:"name,omitempty"`
+	// Sources are the slugs of the apps and konnectors that can have created
+	// the file
+	Sources []string `json:"sources,omitempty"`
+	// Metadata are some regular expressions for the metadata of the file,
+	// like the ones extracted from EXIF (datetime, flash, etc.)
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Text is a regular expression for the text of the file: the content for
+	// the text files, or the ocr metadata for the other files.
+	Text string `json:"text,omitempty"`
+
+	name     *regexp.Regexp
+	metadata map[string]*regexp.Regexp
+	text     *regexp.Regexp
+}
+
+func (r *Rule) compile() error {
+	var err error
+	if len(r.Qualification) == 0 {
+		return fmt.Errorf("qualification: missing qualification for a rule")
+	}
+	if r.Name != "" {
+		if r.name, err = regexp.Compile(r.Name); err != nil {
+			return err
+		}
+	}
+	if r.Text != "" {
+		if r.text, err = regexp.Compile(r.Text); err != nil {
+			return err
+		}
+	}
+	r.metadata = make(map[string]*regexp.Regexp, len(r.Metadata))
+	for k, v := range r.Metadata {
+		if r.metadata[k], err = regexp.Compile(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rules returns the rules of qualification from the context of the instance.
+func Rules(inst *instance.Instance) ([]*Rule, error) {
+	settings, ok := inst.SettingsContext()
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := settings[job.QualificationRulesKey]
+	if !ok {
+		return nil, nil
+	}
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*Rule
+	if err := json.Unmarshal(buf, &rules); err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if err := r.compile(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// Sources returns the slugs of the apps and konnectors that have created or
+// uploaded the file.
+func Sources(doc *vfs.FileDoc) []string {
+	var sources []string
+	if doc.CozyMetadata == nil {
+		return nil
+	}
+	if slug := doc.CozyMetadata.CreatedByApp; slug != "" {
+		sources = append(sources, slug)
+	}
+	if by := doc.CozyMetadata.UploadedBy; by != nil && by.Slug != "" {
+		sources = append(sources, by.Slug)
+	}
+	return sources
+}
+
+// Match returns true if the file matches the rule. The text function is
+// called only if the rule has a criterion on the text.
+func (r *Rule) Match(doc *vfs.FileDoc, text func() string) bool {
+	if r.Mime != "" && !strings.HasPrefix(doc.Mime, r.Mime) {
+		return false
+	}
+	if r.Class != "" && doc.Class != r.Class {
+		return false
+	}
+	if r.name != nil && !r.name.MatchString(doc.DocName) {
+		return false
+	}
+	if len(r.Sources) > 0 && !hasSource(r.Sources, Sources(doc)) {
+		return false
+	}
+	for k, re := range r.metadata {
+		value, ok := doc.Metadata[k]
+		if !ok || !re.MatchString(fmt.Sprint(value)) {
+			return false
+		}
+	}
+	if r.text != nil && !r.text.MatchString(text()) {
+		return false
+	}
+	return true
+}
+
+func hasSource(expected, sources []string) bool {
+	for _, e := range expected {
+		for _, s := range sources {
+			if e == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Qualify applies the rules of qualification to the file, if it has not been
+// qualified yet. The first rule that matches is used, and a realtime event is
+// sent in the io.cozy.files.qualifications doctype. It returns true if the
+// file has been qualified.
+func Qualify(inst *instance.Instance, fileID string) (bool, error) {
+	rules, err := Rules(inst)
+	if err != nil || len(rules) == 0 {
+		return false, err
+	}
+
+	fs := inst.VFS()
+	olddoc, err := fs.FileByID(fileID)
+	if err != nil {
+		return false, err
+	}
+	if olddoc.Trashed {
+		return false, nil
+	}
+	if _, ok := olddoc.Metadata[QualificationKey]; ok {
+		return false, nil
+	}
+
+	var text *string
+	getText := func() string {
+		if text == nil {
+			t := extractText(fs, olddoc)
+			text = &t
+		}
+		return *text
+	}
+	for _, rule := range rules {
+		if !rule.Match(olddoc, getText) {
+			continue
+		}
+		newdoc := olddoc.Clone().(*vfs.FileDoc)
+		if newdoc.Metadata == nil {
+			newdoc.Metadata = vfs.NewMetadata()
+		}
+		newdoc.Metadata[QualificationKey] = rule.Qualification
+		if err := fs.UpdateFileDoc(olddoc, newdoc); err != nil {
+			return false, err
+		}
+		publish(inst, newdoc, rule)
+		return true, nil
+	}
+	return false, nil
+}
+
+// extractText returns the text used to match the rules: the beginning of the
+// content for a text file, or the text put by an OCR service in the metadata.
+func extractText(fs vfs.VFS, doc *vfs.FileDoc) string {
+	if ocr, ok := doc.Metadata[OCRKey].(string); ok {
+		return ocr
+	}
+	if !strings.HasPrefix(doc.Mime, "text/") {
+		return ""
+	}
+	content, err := fs.OpenFile(doc)
+	if err != nil {
+		return ""
+	}
+	defer content.Close()
+	buf, err := io.ReadAll(io.LimitReader(content, maxTextSize))
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
+
+func publish(inst *instance.Instance, doc *vfs.FileDoc, rule *Rule) {
+	event := couchdb.JSONDoc{
+		Type: consts.FilesQualifications,
+		M: map[string]interface{}{
+			"_id":           doc.ID(),
+			"dir_id":        doc.DirID,
+			"name":          doc.DocName,
+			"qualification": rule.Qualification,
+		},
+	}
+	realtime.GetHub().Publish(inst, realtime.EventCreate, &event, nil)
+}
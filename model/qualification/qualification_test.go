@@ -0,0 +1,56 @@
+package qualification
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	doc := &vfs.FileDoc{
+		DocName: "releve-2023-03.pdf",
+		Mime:    "application/pdf",
+		Class:   "pdf",
+		Metadata: vfs.Metadata{
+			"datetime": "2023-03-02T10:00:00Z",
+		},
+		CozyMetadata: &vfs.FilesCozyMetadata{
+			CozyMetadata: metadata.CozyMetadata{CreatedByApp: "boursorama83"},
+		},
+	}
+	noText := func() string { return "" }
+
+	r := &Rule{
+		Qualification: map[string]interface{}{"label": "bank_statements"},
+		Mime:          "application/",
+		Name:          `^releve-`,
+		Sources:       []string{"boursorama83"},
+		Metadata:      map[string]string{"datetime": `^2023-`},
+	}
+	require.NoError(t, r.compile())
+	assert.True(t, r.Match(doc, noText))
+
+	r.Class = "image"
+	assert.False(t, r.Match(doc, noText))
+	r.Class = ""
+
+	r.Sources = []string{"drive"}
+	assert.False(t, r.Match(doc, noText))
+	r.Sources = nil
+
+	r.Metadata = map[string]string{"flash": "true"}
+	require.NoError(t, r.compile())
+	assert.False(t, r.Match(doc, noText))
+	r.Metadata = nil
+
+	r.Text = `(?i)account statement`
+	require.NoError(t, r.compile())
+	assert.False(t, r.Match(doc, noText))
+	assert.True(t, r.Match(doc, func() string { return "Your Account Statement" }))
+
+	r = &Rule{Qualification: map[string]interface{}{"label": "x"}, Name: `[`}
+	assert.Error(t, r.compile())
+}
@@ -0,0 +1,112 @@
+package reminder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences is the maximal number of occurrences that are computed to
+// find the next one.
+const maxOccurrences = 100000
+
+// Recurrence is a subset of the recurrence rules of the iCalendar format
+// (RFC 5545): FREQ, INTERVAL, COUNT and UNTIL.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+}
+
+// ParseRecurrence parses a recurrence rule, like FREQ=WEEKLY;INTERVAL=2.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrence, part)
+		}
+		key, value := strings.ToUpper(kv[0]), kv[1]
+		switch key {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: invalid interval %q", ErrInvalidRecurrence, value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: invalid count %q", ErrInvalidRecurrence, value)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid until %q", ErrInvalidRecurrence, value)
+			}
+			r.Until = until
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalidRecurrence, key)
+		}
+	}
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("%w: invalid frequency %q", ErrInvalidRecurrence, r.Freq)
+	}
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return t, err
+	}
+	// A date without time includes the whole day
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// Occurrence returns the nth occurrence (starting at 0) of the recurrence
+// for the given start. The computation is done with the wall clock of the
+// location of the start, so that a reminder at 9am stays at 9am after a
+// daylight saving time change.
+func (r *Recurrence) Occurrence(start time.Time, n int) time.Time {
+	step := n * r.Interval
+	switch r.Freq {
+	case "DAILY":
+		return start.AddDate(0, 0, step)
+	case "WEEKLY":
+		return start.AddDate(0, 0, 7*step)
+	case "MONTHLY":
+		return start.AddDate(0, step, 0)
+	default:
+		return start.AddDate(step, 0, 0)
+	}
+}
+
+// Next returns the first occurrence strictly after the given time, or false
+// if the recurrence has ended.
+func (r *Recurrence) Next(start, after time.Time) (time.Time, bool) {
+	for n := 0; n < maxOccurrences; n++ {
+		if r.Count > 0 && n >= r.Count {
+			return time.Time{}, false
+		}
+		occ := r.Occurrence(start, n)
+		if !r.Until.IsZero() && occ.After(r.Until) {
+			return time.Time{}, false
+		}
+		if occ.After(after) {
+			return occ, true
+		}
+	}
+	return time.Time{}, false
+}
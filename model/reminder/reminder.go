@@ -0,0 +1,368 @@
+// Package reminder is for the reminders that the apps can create to notify
+// the user at a given time. The stack schedules them with @at triggers, and
+// delivers them via the notification center. A reminder can be recurrent,
+// and it can be snoozed.
+package reminder
+
+import (
+	"errors"
+	"html"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// WorkerType is the type of the worker that delivers the reminders.
+const WorkerType = "reminder"
+
+// The states of a reminder.
+const (
+	// StateScheduled is used for a reminder with a next delivery.
+	StateScheduled = "scheduled"
+	// StateDone is used for a reminder that has been delivered, and has no
+	// other occurrence.
+	StateDone = "done"
+)
+
+// localLayout is the layout of the date of a reminder, in the wall clock of
+// its timezone.
+const localLayout = "2006-01-02T15:04:05"
+
+var (
+	// ErrMissingTitle is used when a reminder has no title.
+	ErrMissingTitle = errors.New("The reminder has no title")
+	// ErrInvalidDate is used when the date of a reminder can't be parsed.
+	ErrInvalidDate = errors.New("The date of the reminder is invalid")
+	// ErrInvalidTimezone is used for an unknown timezone.
+	ErrInvalidTimezone = errors.New("The timezone of the reminder is invalid")
+	// ErrInvalidRecurrence is used for a recurrence rule that can't be parsed.
+	ErrInvalidRecurrence = errors.New("The recurrence of the reminder is invalid")
+	// ErrInvalidChannel is used for an unknown notification channel.
+	ErrInvalidChannel = errors.New("The channel of the reminder is invalid")
+	// ErrInPast is used when a reminder has no occurrence in the future.
+	ErrInPast = errors.New("The reminder has no occurrence in the future")
+	// ErrInvalidSnooze is used when a reminder is snoozed until a time in the
+	// past, or when it is already done.
+	ErrInvalidSnooze = errors.New("The reminder can't be snoozed until this time")
+)
+
+// Reminder is a notification that is delivered to the user at a given time.
+type Reminder struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
+	// Date is the date and time of the first occurrence, in the wall clock of
+	// the timezone (2006-01-02T15:04:05), or with an offset (RFC3339).
+	Date string `json:"date"`
+	// Timezone is the name of the timezone in the IANA database, like
+	// Europe/Paris. UTC is used by default.
+	Timezone string `json:"timezone,omitempty"`
+	// Recurrence is an optional recurrence rule (RFC 5545), with only the
+	// FREQ, INTERVAL, COUNT and UNTIL parts.
+	Recurrence string `json:"recurrence,omitempty"`
+	// Channels are the preferred channels for the notification (mobile,
+	// mail, sms).
+	Channels []string `json:"channels,omitempty"`
+	// Data is sent with the notification (for example, a redirectLink).
+	Data map[string]interface{} `json:"data,omitempty"`
+
+	SourceID     string     `json:"source_id"`
+	Slug         string     `json:"slug,omitempty"`
+	State        string     `json:"state"`
+	NextAt       *time.Time `json:"next_at,omitempty"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	Occurrences  int        `json:"occurrences"`
+	TriggerID    string     `json:"trigger_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ID is used to implement the couchdb.Doc interface
+func (r *Reminder) ID() string { return r.DocID }
+
+// Rev is used to implement the couchdb.Doc interface
+func (r *Reminder) Rev() string { return r.DocRev }
+
+// DocType is used to implement the couchdb.Doc interface
+func (r *Reminder) DocType() string { return consts.Reminders }
+
+// SetID is used to implement the couchdb.Doc interface
+func (r *Reminder) SetID(id string) { r.DocID = id }
+
+// SetRev is used to implement the couchdb.Doc interface
+func (r *Reminder) SetRev(rev string) { r.DocRev = rev }
+
+// Clone implements couchdb.Doc
+func (r *Reminder) Clone() couchdb.Doc {
+	cloned := *r
+	cloned.Channels = make([]string, len(r.Channels))
+	copy(cloned.Channels, r.Channels)
+	cloned.Data = make(map[string]interface{}, len(r.Data))
+	for k, v := range r.Data {
+		cloned.Data[k] = v
+	}
+	if r.NextAt != nil {
+		at := *r.NextAt
+		cloned.NextAt = &at
+	}
+	if r.SnoozedUntil != nil {
+		until := *r.SnoozedUntil
+		cloned.SnoozedUntil = &until
+	}
+	return &cloned
+}
+
+// Fetch implements permission.Fetcher
+func (r *Reminder) Fetch(field string) []string {
+	switch field {
+	case "source_id":
+		return []string{r.SourceID}
+	case "slug":
+		return []string{r.Slug}
+	}
+	return nil
+}
+
+// start returns the first occurrence of the reminder, in its timezone.
+func (r *Reminder) start() (time.Time, error) {
+	loc := time.UTC
+	if r.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(r.Timezone)
+		if err != nil {
+			return time.Time{}, ErrInvalidTimezone
+		}
+	}
+	if t, err := time.ParseInLocation(localLayout, r.Date, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, r.Date)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	return t.In(loc), nil
+}
+
+// next returns the first occurrence of the reminder after the given time.
+func (r *Reminder) next(after time.Time) (time.Time, bool, error) {
+	start, err := r.start()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if r.Recurrence == "" {
+		return start, start.After(after), nil
+	}
+	rec, err := ParseRecurrence(r.Recurrence)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	next, ok := rec.Next(start, after)
+	return next, ok, nil
+}
+
+// dueAt returns the time of the next delivery of the reminder.
+func (r *Reminder) dueAt() *time.Time {
+	if r.SnoozedUntil != nil {
+		return r.SnoozedUntil
+	}
+	return r.NextAt
+}
+
+func (r *Reminder) validate() error {
+	if r.Title == "" {
+		return ErrMissingTitle
+	}
+	for _, channel := range r.Channels {
+		switch channel {
+		case "mobile", "mail", "sms":
+		default:
+			return ErrInvalidChannel
+		}
+	}
+	_, _, err := r.next(time.Now())
+	return err
+}
+
+// Get returns the reminder with the given ID.
+func Get(inst *instance.Instance, id string) (*Reminder, error) {
+	r := &Reminder{}
+	if err := couchdb.GetDoc(inst, consts.Reminders, id, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// List returns the reminders created by the given source.
+func List(inst *instance.Instance, sourceID string) ([]*Reminder, error) {
+	var all []*Reminder
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	if err := couchdb.GetAllDocs(inst, consts.Reminders, req, &all); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	reminders := make([]*Reminder, 0, len(all))
+	for _, r := range all {
+		if r.SourceID == sourceID {
+			reminders = append(reminders, r)
+		}
+	}
+	return reminders, nil
+}
+
+// Create checks the reminder, saves it, and schedules its first delivery.
+func Create(inst *instance.Instance, r *Reminder) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	next, ok, _ := r.next(now)
+	if !ok {
+		return ErrInPast
+	}
+	at := next.UTC().Truncate(time.Second)
+	r.DocID = ""
+	r.DocRev = ""
+	r.State = StateScheduled
+	r.NextAt = &at
+	r.SnoozedUntil = nil
+	r.Occurrences = 0
+	r.TriggerID = ""
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	if err := couchdb.CreateDoc(inst, r); err != nil {
+		return err
+	}
+	if err := schedule(inst, r); err != nil {
+		_ = couchdb.DeleteDoc(inst, r)
+		return err
+	}
+	return couchdb.UpdateDoc(inst, r)
+}
+
+// Snooze postpones the next delivery of the reminder. The occurrences of a
+// recurrent reminder before the end of the snooze are skipped.
+func Snooze(inst *instance.Instance, r *Reminder, until time.Time) error {
+	if r.State != StateScheduled || !until.After(time.Now()) {
+		return ErrInvalidSnooze
+	}
+	unschedule(inst, r)
+	until = until.UTC().Truncate(time.Second)
+	r.SnoozedUntil = &until
+	if err := schedule(inst, r); err != nil {
+		return err
+	}
+	r.UpdatedAt = time.Now().UTC()
+	return couchdb.UpdateDoc(inst, r)
+}
+
+// Delete removes the reminder and its trigger.
+func Delete(inst *instance.Instance, r *Reminder) error {
+	unschedule(inst, r)
+	return couchdb.DeleteDoc(inst, r)
+}
+
+// Message is the message of the jobs for the reminder worker.
+type Message struct {
+	ReminderID string    `json:"reminder_id"`
+	At         time.Time `json:"at"`
+}
+
+func schedule(inst *instance.Instance, r *Reminder) error {
+	at := r.dueAt()
+	msg, err := job.NewMessage(&Message{ReminderID: r.DocID, At: *at})
+	if err != nil {
+		return err
+	}
+	t, err := job.NewTrigger(inst, job.TriggerInfos{
+		Type:       "@at",
+		WorkerType: WorkerType,
+		Arguments:  at.Format(time.RFC3339),
+	}, msg)
+	if err != nil {
+		return err
+	}
+	if err := job.System().AddTrigger(t); err != nil {
+		return err
+	}
+	r.TriggerID = t.Infos().TID
+	return nil
+}
+
+func unschedule(inst *instance.Instance, r *Reminder) {
+	if r.TriggerID == "" {
+		return
+	}
+	err := job.System().DeleteTrigger(inst, r.TriggerID)
+	if err != nil && err != job.ErrNotFoundTrigger {
+		inst.Logger().WithNamespace("reminders").
+			Warnf("Cannot delete trigger %s: %s", r.TriggerID, err)
+	}
+	r.TriggerID = ""
+}
+
+// Deliver sends the notification of a reminder, and schedules its next
+// occurrence. The messages for a time that is no longer the due time of the
+// reminder (it has been snoozed for example) are ignored.
+func Deliver(inst *instance.Instance, msg *Message) error {
+	r, err := Get(inst, msg.ReminderID)
+	if couchdb.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	due := r.dueAt()
+	if r.State != StateScheduled || due == nil || !due.Equal(msg.At) {
+		return nil
+	}
+
+	n := &notification.Notification{
+		Title:       r.Title,
+		Message:     r.Message,
+		Slug:        r.Slug,
+		Data:        r.Data,
+		Content:     r.Message,
+		ContentHTML: "<p>" + html.EscapeString(r.Message) + "</p>",
+	}
+	if len(r.Channels) > 0 {
+		n.PreferredChannels = r.Channels
+	} else {
+		n.PreferredChannels = []string{"mobile"}
+	}
+	errPush := center.PushStack(inst.Domain, center.NotificationReminder, n)
+
+	r.Occurrences++
+	r.SnoozedUntil = nil
+	r.TriggerID = ""
+	after := time.Now()
+	if r.NextAt != nil && r.NextAt.After(after) {
+		after = *r.NextAt
+	}
+	next, ok, err := r.next(after)
+	if err == nil && ok {
+		at := next.UTC().Truncate(time.Second)
+		r.NextAt = &at
+		if err := schedule(inst, r); err != nil {
+			return err
+		}
+	} else {
+		r.State = StateDone
+		r.NextAt = nil
+	}
+	r.UpdatedAt = time.Now().UTC()
+	if err := couchdb.UpdateDoc(inst, r); err != nil {
+		return err
+	}
+	return errPush
+}
+
+var _ couchdb.Doc = &Reminder{}
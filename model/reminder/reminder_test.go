@@ -0,0 +1,73 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	rec, err := ParseRecurrence("RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=3")
+	require.NoError(t, err)
+	assert.Equal(t, "WEEKLY", rec.Freq)
+	assert.Equal(t, 2, rec.Interval)
+	assert.Equal(t, 3, rec.Count)
+
+	rec, err = ParseRecurrence("FREQ=DAILY;UNTIL=20231231")
+	require.NoError(t, err)
+	assert.Equal(t, 1, rec.Interval)
+	assert.Equal(t, time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), rec.Until)
+
+	_, err = ParseRecurrence("FREQ=HOURLY")
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = ParseRecurrence("FREQ=DAILY;BYDAY=MO")
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = ParseRecurrence("FREQ=DAILY;INTERVAL=0")
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	r := &Reminder{
+		Date:       "2023-10-27T09:00:00",
+		Timezone:   "Europe/Paris",
+		Recurrence: "FREQ=DAILY;COUNT=5",
+	}
+	start := time.Date(2023, 10, 27, 7, 0, 0, 0, time.UTC)
+
+	next, ok, err := r.next(start.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, next.Equal(start))
+
+	// The wall clock is kept after the end of the daylight saving time
+	next, ok, err = r.next(start)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, next.Equal(time.Date(2023, 10, 28, 7, 0, 0, 0, time.UTC)))
+	next, ok, err = r.next(next)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, next.Equal(time.Date(2023, 10, 29, 8, 0, 0, 0, time.UTC)))
+
+	_, ok, err = r.next(time.Date(2023, 10, 31, 8, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	r = &Reminder{Date: "2023-10-27T09:00:00+02:00"}
+	next, ok, err = r.next(start.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, next.Equal(start))
+	_, ok, err = r.next(start)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	r = &Reminder{Date: "2023-10-27T09:00:00", Timezone: "Mars/Olympus"}
+	_, _, err = r.next(start)
+	assert.ErrorIs(t, err, ErrInvalidTimezone)
+	r = &Reminder{Date: "tomorrow"}
+	_, _, err = r.next(start)
+	assert.ErrorIs(t, err, ErrInvalidDate)
+}
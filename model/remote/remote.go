@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/egress"
 	"github.com/cozy/cozy-stack/pkg/filetype"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
@@ -53,7 +55,8 @@ var (
 const rawURL = "https://raw.githubusercontent.com/cozy/cozy-doctypes/master/%s/request"
 
 var remoteClient = &http.Client{
-	Timeout: 20 * time.Second,
+	Timeout:   20 * time.Second,
+	Transport: egress.Wrap(http.DefaultTransport.(*http.Transport)),
 }
 
 var assetsClient = &http.Client{
@@ -206,7 +209,8 @@ func Find(ins *instance.Instance, doctype string) (*Remote, error) {
 		if err != nil || dt.UpdatedAt.Add(24*time.Hour).Before(time.Now()) {
 			rev := dt.Rev()
 			u := fmt.Sprintf(rawURL, doctype)
-			req, err := http.NewRequest(http.MethodGet, u, nil)
+			ctx := egress.WithContextName(context.Background(), ins.ContextName)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -393,7 +397,8 @@ func (remote *Remote) ProxyTo(
 	if remote.Verb != "GET" && remote.Verb != "DELETE" {
 		body = strings.NewReader(remote.Body)
 	}
-	req, err := http.NewRequest(remote.Verb, remote.URL.String(), body)
+	ctx := egress.WithContextName(context.Background(), ins.ContextName)
+	req, err := http.NewRequestWithContext(ctx, remote.Verb, remote.URL.String(), body)
 	if err != nil {
 		return ErrInvalidRequest
 	}
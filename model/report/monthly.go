@@ -0,0 +1,224 @@
+// Package report is for the reports emailed to the user, with a summary of
+// what has happened on their cozy. They are built from the timeline of the
+// activities and from the history of the disk usage.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/activity"
+	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/diskusage"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/emailer"
+	"github.com/cozy/cozy-stack/pkg/i18n"
+	humanize "github.com/dustin/go-humanize"
+)
+
+// MonthlyWorkerType is the type of the worker that sends the monthly report.
+const MonthlyWorkerType = "monthly-report"
+
+// KonnectorFiles is the number of files added by a konnector.
+type KonnectorFiles struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name,omitempty"`
+	Count int    `json:"count"`
+}
+
+// StorageEvolution is the evolution of the disk usage during the month.
+type StorageEvolution struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Quota int64 `json:"quota,omitempty"`
+}
+
+// Delta returns the number of bytes added during the month. It is negative
+// if the disk usage has decreased.
+func (s *StorageEvolution) Delta() int64 {
+	return s.End - s.Start
+}
+
+// Monthly is the summary of what has happened on an instance during a month.
+type Monthly struct {
+	// Month is the first day of the month, at midnight UTC.
+	Month time.Time `json:"month"`
+	// Files are the new documents by konnector, sorted by decreasing count.
+	Files            []*KonnectorFiles `json:"files,omitempty"`
+	Storage          *StorageEvolution `json:"storage,omitempty"`
+	DevicesConnected []string          `json:"devices_connected,omitempty"`
+	DevicesRevoked   []string          `json:"devices_revoked,omitempty"`
+	SharingsAccepted []string          `json:"sharings_accepted,omitempty"`
+}
+
+// Empty returns true if nothing has happened during the month.
+func (m *Monthly) Empty() bool {
+	storageChanged := m.Storage != nil && m.Storage.Delta() != 0
+	return len(m.Files) == 0 && !storageChanged &&
+		len(m.DevicesConnected) == 0 && len(m.DevicesRevoked) == 0 &&
+		len(m.SharingsAccepted) == 0
+}
+
+// MonthOf returns the first day of the month of the given date.
+func MonthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// MonthlyEnabled returns true if the user wants to receive the monthly
+// report, or if it is enabled by default for their context.
+func MonthlyEnabled(inst *instance.Instance) bool {
+	prefs, err := settings.GetReportPrefs(inst)
+	if err != nil {
+		inst.Logger().WithNamespace("report").
+			Warnf("Cannot get the report preferences: %s", err)
+		return false
+	}
+	return prefs.Monthly
+}
+
+// BuildMonthly returns the report for the month starting at the given date.
+func BuildMonthly(inst *instance.Instance, month time.Time) (*Monthly, error) {
+	month = MonthOf(month)
+	end := month.AddDate(0, 1, 0)
+	activities, err := activity.ListBetween(inst, month, end)
+	if err != nil {
+		return nil, err
+	}
+	// The snapshot of the last day of the previous month is used as the
+	// start of the evolution when it exists.
+	snapshots, err := diskusage.ListSnapshots(inst, month.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	report := buildMonthly(month, activities, snapshots)
+	for _, files := range report.Files {
+		if man, err := app.GetKonnectorBySlug(inst, files.Slug); err == nil {
+			files.Name = man.Name()
+		}
+	}
+	return report, nil
+}
+
+func buildMonthly(month time.Time, activities []*activity.Activity, snapshots []*diskusage.Snapshot) *Monthly {
+	report := &Monthly{Month: month}
+	end := month.AddDate(0, 1, 0)
+
+	bySlug := make(map[string]*KonnectorFiles)
+	for _, a := range activities {
+		if a.Date.Before(month) || !a.Date.Before(end) {
+			continue
+		}
+		switch a.Kind {
+		case activity.KindFilesAdded:
+			files, ok := bySlug[a.Slug]
+			if !ok {
+				files = &KonnectorFiles{Slug: a.Slug}
+				bySlug[a.Slug] = files
+				report.Files = append(report.Files, files)
+			}
+			files.Count += a.Count
+		case activity.KindDeviceConnected:
+			report.DevicesConnected = append(report.DevicesConnected, a.Label)
+		case activity.KindTokensRevoked:
+			report.DevicesRevoked = append(report.DevicesRevoked, a.Label)
+		case activity.KindSharingAccepted:
+			label := a.Label
+			if a.Member != "" {
+				label = fmt.Sprintf("%s (%s)", a.Label, a.Member)
+			}
+			report.SharingsAccepted = append(report.SharingsAccepted, label)
+		}
+	}
+	sort.SliceStable(report.Files, func(i, j int) bool {
+		return report.Files[i].Count > report.Files[j].Count
+	})
+
+	var first, last *diskusage.Snapshot
+	for _, s := range snapshots {
+		if !s.Day().Before(end) {
+			break
+		}
+		if first == nil {
+			first = s
+		}
+		last = s
+	}
+	if first != nil {
+		report.Storage = &StorageEvolution{
+			Start: first.Used,
+			End:   last.Used,
+			Quota: last.Quota,
+		}
+	}
+	return report
+}
+
+// SendMonthly sends the report by mail to the user.
+func SendMonthly(inst *instance.Instance, report *Monthly) error {
+	locale := inst.Locale
+	files := make([]string, len(report.Files))
+	for i, f := range report.Files {
+		name := f.Name
+		if name == "" {
+			name = f.Slug
+		}
+		files[i] = inst.Translate("Mail Monthly Report Files Line", name, f.Count)
+	}
+
+	values := map[string]interface{}{
+		"Month":            i18n.LocalizeTime(report.Month, locale, "January 2006"),
+		"Files":            files,
+		"DevicesConnected": report.DevicesConnected,
+		"DevicesRevoked":   report.DevicesRevoked,
+		"SharingsAccepted": report.SharingsAccepted,
+		"SettingsLink":     inst.SubDomain(consts.SettingsSlug).String(),
+	}
+	if s := report.Storage; s != nil {
+		values["StorageUsed"] = humanize.Bytes(uint64(s.End))
+		delta := s.Delta()
+		if delta >= 0 {
+			values["StorageDelta"] = "+" + humanize.Bytes(uint64(delta))
+		} else {
+			values["StorageDelta"] = "-" + humanize.Bytes(uint64(-delta))
+		}
+		if s.Quota > 0 {
+			values["StorageQuota"] = humanize.Bytes(uint64(s.Quota))
+		}
+	}
+	return emailer.SendEmail(inst, &emailer.SendEmailCmd{
+		TemplateName:   "monthly_report",
+		TemplateValues: values,
+	})
+}
+
+// EnsureMonthlyTrigger creates the trigger for the monthly-report worker if
+// the instance does not have it yet. The worker checks the preferences of the
+// user, so the trigger can exist even if the report is disabled.
+func EnsureMonthlyTrigger(inst *instance.Instance) {
+	sched := job.System()
+	infos := job.TriggerInfos{
+		Type:       "@cron",
+		WorkerType: MonthlyWorkerType,
+	}
+	if sched.HasTrigger(inst, infos) {
+		return
+	}
+
+	// The report is sent on the first day of the month, during the day, at
+	// a time that depends on the instance to spread the load.
+	now := time.Now()
+	infos.Arguments = fmt.Sprintf("0 %d %d 1 * *", now.Minute(), now.Hour()%12+6)
+	trigger, err := job.NewTrigger(inst, infos, nil)
+	if err != nil {
+		inst.Logger().Errorf("Cannot create monthly-report trigger: %s", err)
+		return
+	}
+	if err = sched.AddTrigger(trigger); err != nil {
+		inst.Logger().Errorf("Cannot create monthly-report trigger: %s", err)
+	}
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/activity"
+	"github.com/cozy/cozy-stack/model/diskusage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthOf(t *testing.T) {
+	day := time.Date(2026, time.March, 17, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), MonthOf(day))
+}
+
+func TestBuildMonthly(t *testing.T) {
+	month := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return month.AddDate(0, 0, d-1).Add(10 * time.Hour) }
+	activities := []*activity.Activity{
+		{Kind: activity.KindFilesAdded, Slug: "edf", Count: 2, Date: day(3)},
+		{Kind: activity.KindFilesAdded, Slug: "orange", Count: 1, Date: day(5)},
+		{Kind: activity.KindFilesAdded, Slug: "edf", Count: 3, Date: day(20)},
+		{Kind: activity.KindDeviceConnected, Label: "Cozy Drive (Desktop)", Date: day(7)},
+		{Kind: activity.KindTokensRevoked, Label: "Cozy Pass (Android)", Date: day(8)},
+		{Kind: activity.KindSharingAccepted, Label: "Holidays", Member: "Alice", Date: day(9)},
+		{Kind: activity.KindAppInstalled, Slug: "notes", Date: day(10)},
+		// Outside of the month
+		{Kind: activity.KindFilesAdded, Slug: "orange", Count: 8, Date: day(31)},
+	}
+	snapshots := []*diskusage.Snapshot{
+		{Date: "2026-08-31", Used: 1000},
+		{Date: "2026-09-15", Used: 1500},
+		{Date: "2026-09-30", Used: 1200, Quota: 5000},
+		{Date: "2026-10-01", Used: 9000},
+	}
+
+	report := buildMonthly(month, activities, snapshots)
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, "edf", report.Files[0].Slug)
+	assert.Equal(t, 5, report.Files[0].Count)
+	assert.Equal(t, "orange", report.Files[1].Slug)
+	assert.Equal(t, 1, report.Files[1].Count)
+	assert.Equal(t, []string{"Cozy Drive (Desktop)"}, report.DevicesConnected)
+	assert.Equal(t, []string{"Cozy Pass (Android)"}, report.DevicesRevoked)
+	assert.Equal(t, []string{"Holidays (Alice)"}, report.SharingsAccepted)
+	require.NotNil(t, report.Storage)
+	assert.EqualValues(t, 1000, report.Storage.Start)
+	assert.EqualValues(t, 1200, report.Storage.End)
+	assert.EqualValues(t, 200, report.Storage.Delta())
+	assert.EqualValues(t, 5000, report.Storage.Quota)
+	assert.False(t, report.Empty())
+
+	empty := buildMonthly(month, nil, []*diskusage.Snapshot{{Date: "2026-09-02", Used: 42}})
+	assert.True(t, empty.Empty())
+}
@@ -0,0 +1,205 @@
+// Package risk is used to ask an external risk engine, configured by the
+// hoster for a context, what to do with some sensitive authentication
+// actions, like a login or the registration of an OAuth client.
+package risk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+)
+
+// DefaultTimeout is the maximal duration of a call to the risk hook, if the
+// timeout is not configured.
+const DefaultTimeout = 2 * time.Second
+
+// Event is the type of action submitted to the risk hook.
+type Event string
+
+const (
+	// EventLogin is used when the user has sent a valid passphrase
+	EventLogin Event = "login"
+	// EventOAuthClient is used when a new OAuth client is registered
+	EventOAuthClient Event = "oauth_client"
+	// EventPassphraseReset is used when a passphrase reset is requested
+	EventPassphraseReset Event = "passphrase_reset"
+)
+
+// Decision is the response of the risk hook.
+type Decision string
+
+const (
+	// Allow means that the action can continue
+	Allow Decision = "allow"
+	// Verify means that the user must prove their identity with a code sent
+	// by mail before the action can continue
+	Verify Decision = "verify"
+	// Block means that the action is refused
+	Block Decision = "block"
+)
+
+// Request is the payload sent to the risk hook.
+type Request struct {
+	Event     Event                  `json:"event"`
+	Domain    string                 `json:"domain"`
+	Context   string                 `json:"context,omitempty"`
+	IP        string                 `json:"ip,omitempty"`
+	UserAgent string                 `json:"user_agent,omitempty"`
+	Time      time.Time              `json:"time"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Response is the payload returned by the risk hook.
+type Response struct {
+	Decision Decision `json:"decision"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+// Hook is the interface for a risk engine.
+type Hook interface {
+	Evaluate(ctx context.Context, req *Request) (*Response, error)
+}
+
+// config is the risk_hook configuration of a context.
+type config struct {
+	hook     Hook
+	timeout  time.Duration
+	failOpen bool
+	events   map[Event]bool
+}
+
+// hookForInstance returns the configuration of the risk hook for the context
+// of the instance. It is a variable to allow the tests to plug a fake hook.
+var hookForInstance = func(inst *instance.Instance) (*config, error) {
+	ctxSettings, ok := inst.SettingsContext()
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := ctxSettings["risk_hook"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	return parseConfig(raw)
+}
+
+func parseConfig(raw map[string]interface{}) (*config, error) {
+	u, _ := raw["url"].(string)
+	if u == "" {
+		return nil, fmt.Errorf("risk_hook: missing url")
+	}
+	cfg := &config{
+		hook:     &httpHook{URL: u},
+		timeout:  DefaultTimeout,
+		failOpen: true,
+	}
+	if timeout, ok := raw["timeout"].(string); ok && timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("risk_hook: invalid timeout %q", timeout)
+		}
+		cfg.timeout = d
+	}
+	if failOpen, ok := raw["fail_open"].(bool); ok {
+		cfg.failOpen = failOpen
+	}
+	if events, ok := raw["events"].([]interface{}); ok {
+		cfg.events = make(map[Event]bool)
+		for _, event := range events {
+			e, _ := event.(string)
+			switch Event(e) {
+			case EventLogin, EventOAuthClient, EventPassphraseReset:
+				cfg.events[Event(e)] = true
+			default:
+				return nil, fmt.Errorf("risk_hook: unknown event %q", e)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// Evaluate asks the risk hook of the context of the instance what to do with
+// the action. If no hook is configured for the event, the action is allowed.
+// If the hook can't be reached in time or its response is invalid, the action
+// is allowed if the hook is configured to fail open (the default), and
+// blocked otherwise.
+func Evaluate(inst *instance.Instance, req *Request) Decision {
+	log := inst.Logger().WithNamespace("risk")
+	cfg, err := hookForInstance(inst)
+	if err != nil {
+		log.Errorf("Invalid configuration for context %q: %s", inst.ContextName, err)
+		return Allow
+	}
+	if cfg == nil || (cfg.events != nil && !cfg.events[req.Event]) {
+		return Allow
+	}
+
+	req.Domain = inst.Domain
+	req.Context = inst.ContextName
+	if req.Time.IsZero() {
+		req.Time = time.Now().UTC()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	defer cancel()
+	res, err := cfg.hook.Evaluate(ctx, req)
+	if err == nil {
+		switch res.Decision {
+		case Allow, Verify, Block:
+		default:
+			err = fmt.Errorf("unknown decision %q", res.Decision)
+		}
+	}
+	if err != nil {
+		if cfg.failOpen {
+			log.Warnf("Error for %s, the action is allowed: %s", req.Event, err)
+			return Allow
+		}
+		log.Warnf("Error for %s, the action is blocked: %s", req.Event, err)
+		return Block
+	}
+
+	if res.Decision != Allow {
+		log.WithField("ip", req.IP).
+			Infof("Decision %s for %s: %s", res.Decision, req.Event, res.Reason)
+	}
+	return res.Decision
+}
+
+// httpHook is a risk hook called via an HTTP POST request, with the JSON
+// request in the body. It must respond with a 2xx status code and a JSON
+// response.
+type httpHook struct {
+	URL string
+}
+
+func (h *httpHook) Evaluate(ctx context.Context, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	var out Response
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+var _ Hook = (*httpHook)(nil)
@@ -0,0 +1,93 @@
+package risk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	var received Request
+	var decision string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		switch decision {
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		case "error":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response{Decision: Decision(decision), Reason: "test"})
+	}))
+	defer ts.Close()
+
+	inst := &instance.Instance{Domain: "alice.cozy.example", ContextName: "company"}
+	raw := map[string]interface{}{
+		"url":     ts.URL,
+		"timeout": "50ms",
+		"events":  []interface{}{"login", "oauth_client"},
+	}
+	was := hookForInstance
+	defer func() { hookForInstance = was }()
+	hookForInstance = func(*instance.Instance) (*config, error) {
+		return parseConfig(raw)
+	}
+
+	t.Run("Decisions", func(t *testing.T) {
+		for _, d := range []Decision{Allow, Verify, Block} {
+			decision = string(d)
+			req := &Request{Event: EventLogin, IP: "203.0.113.4"}
+			assert.Equal(t, d, Evaluate(inst, req))
+		}
+		assert.Equal(t, EventLogin, received.Event)
+		assert.Equal(t, "alice.cozy.example", received.Domain)
+		assert.Equal(t, "company", received.Context)
+		assert.Equal(t, "203.0.113.4", received.IP)
+	})
+
+	t.Run("EventNotConfigured", func(t *testing.T) {
+		decision = string(Block)
+		req := &Request{Event: EventPassphraseReset}
+		assert.Equal(t, Allow, Evaluate(inst, req))
+	})
+
+	t.Run("FailOpen", func(t *testing.T) {
+		decision = "slow"
+		assert.Equal(t, Allow, Evaluate(inst, &Request{Event: EventLogin}))
+		decision = "error"
+		assert.Equal(t, Allow, Evaluate(inst, &Request{Event: EventLogin}))
+		decision = "maybe"
+		assert.Equal(t, Allow, Evaluate(inst, &Request{Event: EventLogin}))
+	})
+
+	t.Run("FailClosed", func(t *testing.T) {
+		raw["fail_open"] = false
+		defer delete(raw, "fail_open")
+		decision = "slow"
+		assert.Equal(t, Block, Evaluate(inst, &Request{Event: EventLogin}))
+		decision = "error"
+		assert.Equal(t, Block, Evaluate(inst, &Request{Event: EventOAuthClient}))
+	})
+}
+
+func TestParseConfig(t *testing.T) {
+	_, err := parseConfig(map[string]interface{}{})
+	assert.Error(t, err)
+	_, err = parseConfig(map[string]interface{}{"url": "http://risk", "timeout": "soon"})
+	assert.Error(t, err)
+	_, err = parseConfig(map[string]interface{}{"url": "http://risk", "events": []interface{}{"logout"}})
+	assert.Error(t, err)
+
+	cfg, err := parseConfig(map[string]interface{}{"url": "http://risk"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, cfg.timeout)
+	assert.True(t, cfg.failOpen)
+	assert.Nil(t, cfg.events)
+}
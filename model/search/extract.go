@@ -0,0 +1,192 @@
+package search
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxTextSize is the maximal size in bytes of the text extracted from a file.
+const maxTextSize = 1 << 20
+
+// Extractor extracts the text from the content of a file.
+type Extractor interface {
+	// Accepts returns true if the extractor can read the files with the
+	// given MIME type and class.
+	Accepts(mime, class string) bool
+	// Extract returns the text of the file.
+	Extract(content io.ReaderAt, size int64, mime string) (string, error)
+}
+
+// textExtractor reads the text files.
+type textExtractor struct{}
+
+var textMimes = map[string]bool{
+	"application/json":      true,
+	"application/xml":       true,
+	"application/x-yaml":    true,
+	"application/x-tex":     true,
+	"application/x-sh":      true,
+	"application/xhtml+xml": true,
+}
+
+func (textExtractor) Accepts(mime, class string) bool {
+	if strings.HasPrefix(mime, "text/") && mime != "text/rtf" {
+		return true
+	}
+	return textMimes[mime] || class == "text"
+}
+
+func (textExtractor) Extract(content io.ReaderAt, size int64, _ string) (string, error) {
+	buf, err := io.ReadAll(io.LimitReader(io.NewSectionReader(content, 0, size), maxTextSize))
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// officeExtractor reads the Office Open XML (docx, xlsx, pptx) and
+// OpenDocument (odt, ods, odp) files, that are zip archives of XML files.
+type officeExtractor struct{}
+
+// officeParts are the patterns of the names of the XML files with the text,
+// by MIME type.
+var officeParts = map[string]string{
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   "word/document.xml",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         "xl/sharedStrings.xml",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": "ppt/slides/slide*.xml",
+	"application/vnd.oasis.opendocument.text":                                   "content.xml",
+	"application/vnd.oasis.opendocument.spreadsheet":                            "content.xml",
+	"application/vnd.oasis.opendocument.presentation":                           "content.xml",
+}
+
+// officeBreaks are the XML elements after which a space is added, to not
+// join the words of two paragraphs, cells, etc.
+var officeBreaks = map[string]bool{
+	"p": true, "h": true, "br": true, "tab": true, "si": true, "tc": true,
+	"table-cell": true, "list-item": true, "line-break": true, "s": true,
+}
+
+func (officeExtractor) Accepts(mime, _ string) bool {
+	_, ok := officeParts[mime]
+	return ok
+}
+
+func (officeExtractor) Extract(content io.ReaderAt, size int64, mime string) (string, error) {
+	archive, err := zip.NewReader(content, size)
+	if err != nil {
+		return "", err
+	}
+	pattern := officeParts[mime]
+	var parts []*zip.File
+	for _, f := range archive.File {
+		if ok, _ := path.Match(pattern, f.Name); ok {
+			parts = append(parts, f)
+		}
+	}
+	// slide2.xml must come before slide10.xml
+	sort.Slice(parts, func(i, j int) bool {
+		a, b := parts[i].Name, parts[j].Name
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+
+	var b strings.Builder
+	for _, part := range parts {
+		if err := extractXMLText(&b, part); err != nil {
+			return "", err
+		}
+		if b.Len() >= maxTextSize {
+			break
+		}
+	}
+	return b.String(), nil
+}
+
+func extractXMLText(b *strings.Builder, part *zip.File) error {
+	r, err := part.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	decoder := xml.NewDecoder(io.LimitReader(r, 10*maxTextSize))
+	for b.Len() < maxTextSize {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.EndElement:
+			if officeBreaks[t.Name.Local] {
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return nil
+}
+
+// tikaExtractor sends the files to an Apache Tika server, for the PDF, the
+// images (with OCR) and the legacy office formats.
+type tikaExtractor struct {
+	URL string
+}
+
+var tikaClient = &http.Client{
+	Timeout: 2 * time.Minute,
+}
+
+var tikaMimes = map[string]bool{
+	"application/msword":            true,
+	"application/vnd.ms-excel":      true,
+	"application/vnd.ms-powerpoint": true,
+	"application/rtf":               true,
+	"text/rtf":                      true,
+}
+
+func (t *tikaExtractor) Accepts(mime, class string) bool {
+	return class == "pdf" || class == "image" || tikaMimes[mime]
+}
+
+func (t *tikaExtractor) Extract(content io.ReaderAt, size int64, mime string) (string, error) {
+	u := strings.TrimSuffix(t.URL, "/") + "/tika"
+	req, err := http.NewRequest(http.MethodPut, u, io.NewSectionReader(content, 0, size))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", mime)
+	req.Header.Set("Accept", "text/plain")
+	res, err := tikaClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusUnsupportedMediaType {
+		return "", ErrNoExtractor
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("tika: unexpected status code %d", res.StatusCode)
+	}
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxTextSize))
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// ErrNoExtractor is used when the text of a file cannot be extracted.
+var ErrNoExtractor = errors.New("no extractor for this type of file")
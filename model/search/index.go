@@ -0,0 +1,408 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Index is the full-text index of the files of an instance.
+type Index interface {
+	// Add indexes a file, or replaces it in the index.
+	Add(doc *Document) error
+	// Remove removes a file from the index.
+	Remove(id string) error
+	// Search returns the files that have all the words of the query, in
+	// their name or content, sorted by relevance.
+	Search(query string, limit int) ([]*Hit, error)
+	// Count returns the number of files in the index.
+	Count() (int, error)
+	// Drop removes all the files from the index.
+	Drop() error
+}
+
+// Document is a file to index.
+type Document struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// Hit is a file that matches a search.
+type Hit struct {
+	ID         string   `json:"id"`
+	Score      float64  `json:"score"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+const (
+	// nameBoost is the weight of a word of the name of a file, compared to a
+	// word of its content.
+	nameBoost = 5
+
+	// The parameters of the BM25 ranking function.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// compactMinGarbage is the number of obsolete records in the log before
+	// it can be compacted.
+	compactMinGarbage = 1000
+)
+
+// logIndex is an inverted index kept in memory. It is persisted in an
+// append-only log of JSON lines, one per added or removed file, that is
+// compacted when it has more obsolete records than live ones. The log can be
+// written by several processes (with a lock): the records written by the
+// other processes are read before each operation.
+type logIndex struct {
+	mu   sync.Mutex
+	path string
+
+	// info and offset are used to detect the changes of the log by another
+	// process: the records after offset are new, and a compaction replaces
+	// the file.
+	info   os.FileInfo
+	offset int64
+
+	docs     []*entry
+	byID     map[string]int
+	postings map[string][]posting
+	live     int
+	totalLen int
+	garbage  int
+}
+
+// entry is a file in the index. The text is not kept in memory: it is read
+// from the log, at the given offset, for the highlights.
+type entry struct {
+	id      string
+	offset  int64
+	length  int
+	deleted bool
+}
+
+type posting struct {
+	doc  int
+	freq int
+}
+
+type logRecord struct {
+	Document
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+func newLogIndex(path string) *logIndex {
+	idx := &logIndex{path: path}
+	idx.reset()
+	return idx
+}
+
+func (idx *logIndex) reset() {
+	idx.info = nil
+	idx.offset = 0
+	idx.docs = nil
+	idx.byID = make(map[string]int)
+	idx.postings = make(map[string][]posting)
+	idx.live = 0
+	idx.totalLen = 0
+	idx.garbage = 0
+}
+
+// refresh reads the records added to the log since the last call.
+func (idx *logIndex) refresh() error {
+	fi, err := os.Stat(idx.path)
+	if errors.Is(err, os.ErrNotExist) {
+		idx.reset()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if idx.info != nil && (!os.SameFile(idx.info, fi) || fi.Size() < idx.offset) {
+		idx.reset()
+	}
+	idx.info = fi
+	if fi.Size() == idx.offset {
+		return nil
+	}
+
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(idx.offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A partial line is being written by another process
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var rec logRecord
+		if err := json.Unmarshal(line, &rec); err == nil && rec.ID != "" {
+			idx.apply(&rec, idx.offset)
+		}
+		idx.offset += int64(len(line))
+	}
+}
+
+func (idx *logIndex) apply(rec *logRecord, offset int64) {
+	if n, ok := idx.byID[rec.ID]; ok {
+		old := idx.docs[n]
+		old.deleted = true
+		delete(idx.byID, rec.ID)
+		idx.live--
+		idx.totalLen -= old.length
+		idx.garbage++
+	}
+	if rec.Deleted {
+		idx.garbage++
+		return
+	}
+
+	freqs := make(map[string]int)
+	length := 0
+	for _, tok := range tokenize(rec.Name) {
+		freqs[tok.term] += nameBoost
+		length++
+	}
+	for _, tok := range tokenize(rec.Text) {
+		freqs[tok.term]++
+		length++
+	}
+	n := len(idx.docs)
+	idx.docs = append(idx.docs, &entry{id: rec.ID, offset: offset, length: length})
+	idx.byID[rec.ID] = n
+	for term, freq := range freqs {
+		idx.postings[term] = append(idx.postings[term], posting{doc: n, freq: freq})
+	}
+	idx.live++
+	idx.totalLen += length
+}
+
+// append writes a record at the end of the log.
+func (idx *logIndex) append(rec *logRecord) error {
+	if err := idx.refresh(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(idx.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if errc := f.Close(); err == nil {
+		err = errc
+	}
+	if err != nil {
+		return err
+	}
+	if err := idx.refresh(); err != nil {
+		return err
+	}
+	if idx.garbage > compactMinGarbage && idx.garbage > idx.live {
+		return idx.compact()
+	}
+	return nil
+}
+
+// compact rewrites the log with only the records of the files in the index.
+func (idx *logIndex) compact() error {
+	src, err := os.Open(idx.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := idx.path + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(dst)
+	for _, doc := range idx.docs {
+		if doc.deleted {
+			continue
+		}
+		line, err := readLine(src, doc.offset)
+		if err == nil {
+			_, err = w.Write(line)
+		}
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	err = w.Flush()
+	if err == nil {
+		err = dst.Sync()
+	}
+	if errc := dst.Close(); err == nil {
+		err = errc
+	}
+	if err == nil {
+		err = os.Rename(tmp, idx.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	idx.reset()
+	return idx.refresh()
+}
+
+// readLine reads the line of the log that starts at the given offset.
+func readLine(f *os.File, offset int64) ([]byte, error) {
+	reader := bufio.NewReader(io.NewSectionReader(f, offset, math.MaxInt64-offset))
+	line, err := reader.ReadBytes('\n')
+	if err != nil && !(err == io.EOF && len(line) > 0) {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (idx *logIndex) Add(doc *Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.append(&logRecord{Document: *doc})
+}
+
+func (idx *logIndex) Remove(id string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.refresh(); err != nil {
+		return err
+	}
+	if _, ok := idx.byID[id]; !ok {
+		return nil
+	}
+	return idx.append(&logRecord{Document: Document{ID: id}, Deleted: true})
+}
+
+func (idx *logIndex) Count() (int, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.refresh(); err != nil {
+		return 0, err
+	}
+	return idx.live, nil
+}
+
+func (idx *logIndex) Drop() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.reset()
+	if err := os.Remove(idx.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (idx *logIndex) Search(query string, limit int) ([]*Hit, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.refresh(); err != nil {
+		return nil, err
+	}
+
+	var terms []string
+	seen := make(map[string]bool)
+	for _, tok := range tokenize(query) {
+		if !seen[tok.term] {
+			seen[tok.term] = true
+			terms = append(terms, tok.term)
+		}
+	}
+	if len(terms) == 0 || idx.live == 0 {
+		return nil, nil
+	}
+
+	// The last word is a prefix, for the search as you type
+	avgLen := float64(idx.totalLen) / float64(idx.live)
+	var scores map[int]float64
+	for i, term := range terms {
+		freqs := make(map[int]int)
+		for t, list := range idx.postings {
+			if t != term && (i < len(terms)-1 || !strings.HasPrefix(t, term)) {
+				continue
+			}
+			for _, p := range list {
+				if !idx.docs[p.doc].deleted {
+					freqs[p.doc] += p.freq
+				}
+			}
+		}
+		idf := math.Log(1 + (float64(idx.live)-float64(len(freqs))+0.5)/(float64(len(freqs))+0.5))
+		next := make(map[int]float64, len(freqs))
+		for doc, freq := range freqs {
+			prev, ok := scores[doc]
+			if i > 0 && !ok {
+				continue
+			}
+			tf := float64(freq)
+			norm := 1 - bm25B + bm25B*float64(idx.docs[doc].length)/avgLen
+			next[doc] = prev + idf*tf*(bm25K1+1)/(tf+bm25K1*norm)
+		}
+		scores = next
+		if len(scores) == 0 {
+			return nil, nil
+		}
+	}
+
+	hits := make([]*Hit, 0, len(scores))
+	for doc, score := range scores {
+		hits = append(hits, &Hit{ID: idx.docs[doc].id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	matches := func(t string) bool {
+		if seen[t] {
+			return true
+		}
+		return strings.HasPrefix(t, terms[len(terms)-1])
+	}
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	for _, hit := range hits {
+		line, err := readLine(f, idx.docs[idx.byID[hit.ID]].offset)
+		if err != nil {
+			continue
+		}
+		var rec logRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err == nil {
+			hit.Highlights = highlight(rec.Text, matches)
+		}
+	}
+	return hits, nil
+}
@@ -0,0 +1,211 @@
+// Package search is for the full-text search in the files of the Drive. The
+// text of the files is extracted (by the stack, or by an Apache Tika server),
+// and indexed with the names of the files in an index per instance.
+package search
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/lock"
+)
+
+// WorkerType is the type of the worker that indexes the files.
+const WorkerType = "search-index"
+
+// maxOpenIndexes is the number of indexes kept in memory.
+const maxOpenIndexes = 50
+
+// ErrDisabled is used when the full-text search is not enabled in the config.
+var ErrDisabled = errors.New("The full-text search is not enabled")
+
+// Enabled returns true if the full-text search is enabled in the config.
+func Enabled() bool {
+	return config.GetConfig().Search.IndexDir != ""
+}
+
+// extractors returns the extractors for the text of the files, by order of
+// preference.
+func extractors() []Extractor {
+	list := []Extractor{textExtractor{}, officeExtractor{}}
+	if u := config.GetConfig().Search.TikaURL; u != "" {
+		list = append(list, &tikaExtractor{URL: u})
+	}
+	return list
+}
+
+var indexes = struct {
+	sync.Mutex
+	m        map[string]*logIndex
+	lastUsed map[string]time.Time
+}{
+	m:        make(map[string]*logIndex),
+	lastUsed: make(map[string]time.Time),
+}
+
+// OpenIndex returns the index for the files of the instance.
+func OpenIndex(inst *instance.Instance) (Index, error) {
+	dir := config.GetConfig().Search.IndexDir
+	if dir == "" {
+		return nil, ErrDisabled
+	}
+	key := inst.DBPrefix()
+	indexes.Lock()
+	defer indexes.Unlock()
+	indexes.lastUsed[key] = time.Now()
+	if idx, ok := indexes.m[key]; ok {
+		return idx, nil
+	}
+	if len(indexes.m) >= maxOpenIndexes {
+		var oldest string
+		for k, t := range indexes.lastUsed {
+			if _, ok := indexes.m[k]; ok && (oldest == "" || t.Before(indexes.lastUsed[oldest])) {
+				oldest = k
+			}
+		}
+		delete(indexes.m, oldest)
+		delete(indexes.lastUsed, oldest)
+	}
+	idx := newLogIndex(filepath.Join(dir, key, "files.jsonl"))
+	indexes.m[key] = idx
+	return idx, nil
+}
+
+// IndexFile adds the file to the index, with the text of its content if it
+// can be extracted. The trashed files are removed from the index.
+func IndexFile(inst *instance.Instance, doc *vfs.FileDoc) error {
+	idx, err := OpenIndex(inst)
+	if err != nil {
+		return err
+	}
+	var text string
+	if !doc.Trashed {
+		text, err = ExtractText(inst, doc)
+		if err != nil && !errors.Is(err, ErrNoExtractor) {
+			// The file is still indexed by its name
+			inst.Logger().WithNamespace("search").
+				Infof("Cannot extract the text of %s: %s", doc.ID(), err)
+		}
+	}
+
+	mu := lockIndex(inst)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+	if doc.Trashed {
+		return idx.Remove(doc.ID())
+	}
+	return idx.Add(&Document{
+		ID:   doc.ID(),
+		Name: doc.DocName,
+		Text: text,
+	})
+}
+
+// ExtractText returns the text of the content of a file.
+func ExtractText(inst *instance.Instance, doc *vfs.FileDoc) (string, error) {
+	if max := config.GetConfig().Search.MaxFileSize; max > 0 && doc.ByteSize > max {
+		return "", ErrNoExtractor
+	}
+	for _, extractor := range extractors() {
+		if !extractor.Accepts(doc.Mime, doc.Class) {
+			continue
+		}
+		content, err := inst.VFS().OpenFile(doc)
+		if err != nil {
+			return "", err
+		}
+		defer content.Close()
+		text, err := extractor.Extract(content, doc.ByteSize, doc.Mime)
+		if err != nil {
+			return "", err
+		}
+		return cleanText(text), nil
+	}
+	return "", ErrNoExtractor
+}
+
+// RemoveFile removes a file from the index.
+func RemoveFile(inst *instance.Instance, fileID string) error {
+	idx, err := OpenIndex(inst)
+	if err != nil {
+		return err
+	}
+	mu := lockIndex(inst)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+	return idx.Remove(fileID)
+}
+
+// lockIndex returns the lock for writing in the index of the instance, as it
+// can be shared by several stack processes.
+func lockIndex(inst *instance.Instance) lock.ErrorRWLocker {
+	return config.Lock().ReadWrite(inst, "search-index")
+}
+
+// Reindex rebuilds the index with all the files of the instance, except the
+// trashed ones.
+func Reindex(inst *instance.Instance) error {
+	idx, err := OpenIndex(inst)
+	if err != nil {
+		return err
+	}
+	mu := lockIndex(inst)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	err = idx.Drop()
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	fs := inst.VFS()
+	return vfs.Walk(fs, "/", func(_ string, dir *vfs.DirDoc, file *vfs.FileDoc, err error) error {
+		if err != nil {
+			return err
+		}
+		if dir != nil {
+			if dir.DocID == consts.TrashDirID {
+				return vfs.ErrSkipDir
+			}
+			return nil
+		}
+		return IndexFile(inst, file)
+	})
+}
+
+// Search returns the files that match the query, by order of relevance.
+func Search(inst *instance.Instance, query string, limit int) ([]*Hit, error) {
+	idx, err := OpenIndex(inst)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Search(query, limit)
+}
+
+// DeleteIndex removes the index of an instance.
+func DeleteIndex(inst *instance.Instance) error {
+	idx, err := OpenIndex(inst)
+	if err != nil {
+		return err
+	}
+	if err := idx.Drop(); err != nil {
+		return err
+	}
+	key := inst.DBPrefix()
+	indexes.Lock()
+	delete(indexes.m, key)
+	delete(indexes.lastUsed, key)
+	indexes.Unlock()
+	return os.RemoveAll(filepath.Join(config.GetConfig().Search.IndexDir, key))
+}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	var terms []string
+	for _, tok := range tokenize("L'été à Paris, c'est 2x plus CHAUD!") {
+		terms = append(terms, tok.term)
+	}
+	assert.Equal(t, []string{"ete", "paris", "est", "2x", "plus", "chaud"}, terms)
+
+	assert.Equal(t, "foo bar baz", cleanText("  foo\n\tbar \x00 baz  "))
+}
+
+func TestHighlight(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog. <Foxes> are clever."
+	matches := func(term string) bool { return term == "fox" || term == "foxes" }
+	fragments := highlight(text, matches)
+	require.Len(t, fragments, 1)
+	assert.Equal(t, "The quick brown <mark>fox</mark> jumps over the lazy dog. &lt;<mark>Foxes</mark>&gt; are clever.", fragments[0])
+
+	long := ""
+	for i := 0; i < 50; i++ {
+		long += "lorem ipsum dolor sit amet "
+	}
+	long += "needle " + long
+	fragments = highlight(long, func(term string) bool { return term == "needle" })
+	require.Len(t, fragments, 1)
+	assert.Contains(t, fragments[0], "<mark>needle</mark>")
+	assert.True(t, len(fragments[0]) < 200)
+	assert.Regexp(t, "^… [a-z]", fragments[0])
+}
+
+func TestIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefix", "files.jsonl")
+	idx := newLogIndex(path)
+
+	require.NoError(t, idx.Add(&Document{ID: "1", Name: "invoice-2024.pdf", Text: "Invoice for the electricity of March"}))
+	require.NoError(t, idx.Add(&Document{ID: "2", Name: "notes.md", Text: "Call the plumber about the invoice"}))
+	require.NoError(t, idx.Add(&Document{ID: "3", Name: "holidays.jpg"}))
+
+	hits, err := idx.Search("invoice", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	// The name has a bigger weight than the content
+	assert.Equal(t, "1", hits[0].ID)
+	assert.Equal(t, "2", hits[1].ID)
+	assert.Equal(t, []string{"Call the plumber about the <mark>invoice</mark>"}, hits[1].Highlights)
+
+	// All the words must match, and the last one is a prefix
+	hits, err = idx.Search("invoice electr", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "1", hits[0].ID)
+
+	hits, err = idx.Search("holiday", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "3", hits[0].ID)
+	assert.Empty(t, hits[0].Highlights)
+
+	// Replace and remove
+	require.NoError(t, idx.Add(&Document{ID: "2", Name: "notes.md", Text: "Call the plumber"}))
+	require.NoError(t, idx.Remove("1"))
+	hits, err = idx.Search("invoice", 10)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+	count, err := idx.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Another process can read the same log
+	other := newLogIndex(path)
+	hits, err = other.Search("plumber", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "2", hits[0].ID)
+
+	require.NoError(t, other.Add(&Document{ID: "4", Name: "plumber.txt"}))
+	hits, err = idx.Search("plumber", 10)
+	require.NoError(t, err)
+	assert.Len(t, hits, 2)
+
+	require.NoError(t, idx.Drop())
+	count, err = other.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestIndexCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.jsonl")
+	idx := newLogIndex(path)
+	for i := 0; i < compactMinGarbage+10; i++ {
+		require.NoError(t, idx.Add(&Document{ID: "1", Text: fmt.Sprintf("version %d", i)}))
+	}
+	assert.Less(t, idx.garbage, compactMinGarbage)
+	hits, err := idx.Search("version", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, []string{fmt.Sprintf("<mark>version</mark> %d", compactMinGarbage+9)}, hits[0].Highlights)
+}
+
+func TestOfficeExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body><w:p><w:r><w:t>Hello</w:t></w:r></w:p><w:p><w:r><w:t>World</w:t></w:r></w:p></w:body>
+</w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	mime := "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	extractor := officeExtractor{}
+	assert.True(t, extractor.Accepts(mime, "text"))
+	text, err := extractor.Extract(bytes.NewReader(buf.Bytes()), int64(buf.Len()), mime)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World", cleanText(text))
+}
@@ -0,0 +1,154 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// minTermLength and maxTermLength are the limits, in characters, for the
+	// length of the words that are indexed.
+	minTermLength = 2
+	maxTermLength = 40
+
+	// maxHighlights is the number of fragments of text in a search result.
+	maxHighlights = 3
+	// fragmentContext is the number of bytes of text kept around a match
+	// in a fragment.
+	fragmentContext = 60
+)
+
+// token is a word of a text, with its position.
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// tokenize splits the text in words, and normalizes them.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = appendToken(tokens, text, start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = appendToken(tokens, text, start, len(text))
+	}
+	return tokens
+}
+
+func appendToken(tokens []token, text string, start, end int) []token {
+	term := normalize(text[start:end])
+	n := utf8.RuneCountInString(term)
+	if n < minTermLength || n > maxTermLength {
+		return tokens
+	}
+	return append(tokens, token{term: term, start: start, end: end})
+}
+
+// normalize returns the word in lower case, and without the diacritics, so
+// that "Été" matches "ete".
+func normalize(word string) string {
+	var b strings.Builder
+	b.Grow(len(word))
+	for _, r := range word {
+		if r < utf8.RuneSelf {
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if !unicode.Is(unicode.Mn, d) {
+				b.WriteRune(unicode.ToLower(d))
+			}
+		}
+	}
+	return b.String()
+}
+
+// cleanText replaces the sequences of spaces and control characters by a
+// single space.
+func cleanText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+}
+
+// highlight returns some fragments of the text around the words that match,
+// with these words in <mark> elements. The rest of the text is HTML-escaped.
+func highlight(text string, matches func(term string) bool) []string {
+	tokens := tokenize(text)
+	var fragments []string
+	fragmentEnd := 0
+	for i, tok := range tokens {
+		if len(fragments) >= maxHighlights {
+			break
+		}
+		if tok.start < fragmentEnd || !matches(tok.term) {
+			continue
+		}
+		start := tok.start - fragmentContext
+		if start < 0 {
+			start = 0
+		}
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start++
+		}
+		end := tok.end + fragmentContext
+		if end > len(text) {
+			end = len(text)
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		// Don't cut a word at the boundaries of the fragment
+		first := sort.Search(i, func(j int) bool { return tokens[j].end > start })
+		if first < i && tokens[first].start < start {
+			start = tokens[first].end
+		}
+
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("…")
+		}
+		pos := start
+		for _, t := range tokens[first:] {
+			if t.end > end {
+				if t.start < end {
+					end = t.start
+				}
+				break
+			}
+			if t.start < pos || !matches(t.term) {
+				continue
+			}
+			b.WriteString(html.EscapeString(text[pos:t.start]))
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(text[t.start:t.end]))
+			b.WriteString("</mark>")
+			pos = t.end
+		}
+		b.WriteString(html.EscapeString(text[pos:end]))
+		if end < len(text) {
+			b.WriteString("…")
+		}
+		fragments = append(fragments, strings.TrimSpace(b.String()))
+		fragmentEnd = end
+	}
+	return fragments
+}
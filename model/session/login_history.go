@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/activity"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
@@ -37,6 +38,9 @@ type LoginEntry struct {
 	Browser            string    `json:"browser"`
 	ClientRegistration bool      `json:"client_registration"`
 	CreatedAt          time.Time `json:"created_at"`
+	// UserID is the identifier of the user who has logged in, for the
+	// instances in organization mode.
+	UserID string `json:"user_id,omitempty"`
 }
 
 // DocType implements couchdb.Doc
@@ -113,11 +117,8 @@ func lookupIP(ip, locale string) (city, subdivision, country, timezone string) {
 	return
 }
 
-// StoreNewLoginEntry creates a new login entry in the database associated with
-// the given instance.
-func StoreNewLoginEntry(i *instance.Instance, sessionID, clientID string,
-	req *http.Request, logMessage string, notifEnabled bool,
-) error {
+// ClientIP returns the IP address of the client that has made the request.
+func ClientIP(req *http.Request) string {
 	var ip string
 	if forwardedFor := req.Header.Get(echo.HeaderXForwardedFor); forwardedFor != "" {
 		ip = strings.TrimSpace(strings.SplitN(forwardedFor, ",", 2)[0])
@@ -125,7 +126,37 @@ func StoreNewLoginEntry(i *instance.Instance, sessionID, clientID string,
 	if ip == "" {
 		ip = strings.Split(req.RemoteAddr, ":")[0]
 	}
+	return ip
+}
+
+// Geolocate returns the approximate location of the IP address, with the
+// names in the given locale, if a geodb is configured.
+func Geolocate(ip, locale string) (city, subdivision, country string) {
+	city, subdivision, country, _ = lookupIP(ip, locale)
+	return
+}
 
+// StoreNewLoginEntry creates a new login entry in the database associated with
+// the given instance.
+func StoreNewLoginEntry(i *instance.Instance, sessionID, clientID string,
+	req *http.Request, logMessage string, notifEnabled bool,
+) error {
+	return storeLoginEntry(i, sessionID, "", clientID, req, logMessage, notifEnabled)
+}
+
+// StoreNewUserLoginEntry creates a new login entry for a user of an instance
+// in organization mode. No notification is sent to the owner of the instance
+// for these logins.
+func StoreNewUserLoginEntry(i *instance.Instance, sessionID, userID, clientID string,
+	req *http.Request, logMessage string,
+) error {
+	return storeLoginEntry(i, sessionID, userID, clientID, req, logMessage, false)
+}
+
+func storeLoginEntry(i *instance.Instance, sessionID, userID, clientID string,
+	req *http.Request, logMessage string, notifEnabled bool,
+) error {
+	ip := ClientIP(req)
 	city, subdivision, country, timezone := lookupIP(ip, i.Locale)
 	rawUserAgent := req.UserAgent()
 	ua := user_agent.New(rawUserAgent)
@@ -136,8 +167,11 @@ func StoreNewLoginEntry(i *instance.Instance, sessionID, clientID string,
 	}
 
 	createdAt := time.Now()
-	i.Logger().WithNamespace("loginaudit").
-		Infof("New connection from %s at %s (%s)", ip, createdAt, logMessage)
+	var log logger.Logger = i.Logger().WithNamespace("loginaudit")
+	if userID != "" {
+		log = log.WithField("user_id", userID)
+	}
+	log.Infof("New connection from %s at %s (%s)", ip, createdAt, logMessage)
 	if timezone != "" {
 		if loc, err := time.LoadLocation(timezone); err == nil {
 			createdAt = createdAt.In(loc)
@@ -155,6 +189,7 @@ func StoreNewLoginEntry(i *instance.Instance, sessionID, clientID string,
 		Browser:            browser,
 		ClientRegistration: clientID != "",
 		CreatedAt:          createdAt,
+		UserID:             userID,
 	}
 
 	if err := couchdb.CreateDoc(i, l); err != nil {
@@ -235,6 +270,8 @@ func sendLoginNotification(i *instance.Instance, l *LoginEntry) error {
 // SendNewRegistrationNotification is used to send a notification to the user
 // when a new OAuth client is registered.
 func SendNewRegistrationNotification(i *instance.Instance, clientRegistrationID string) error {
+	recordDeviceConnected(i, clientRegistrationID)
+
 	devicesLink := i.SubDomain(consts.SettingsSlug)
 	devicesLink.Fragment = "/connectedDevices"
 	revokeLink := i.SubDomain(consts.SettingsSlug)
@@ -249,3 +286,17 @@ func SendNewRegistrationNotification(i *instance.Instance, clientRegistrationID
 		TemplateValues: templateValues,
 	})
 }
+
+// recordDeviceConnected adds the new device to the timeline of the instance.
+func recordDeviceConnected(i *instance.Instance, clientID string) {
+	a := &activity.Activity{
+		Kind:      activity.KindDeviceConnected,
+		Doctype:   consts.OAuthClients,
+		RelatedID: clientID,
+	}
+	var client couchdb.JSONDoc
+	if err := couchdb.GetDoc(i, consts.OAuthClients, clientID, &client); err == nil {
+		a.Label, _ = client.M["client_name"].(string)
+	}
+	activity.Record(i, a)
+}
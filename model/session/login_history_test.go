@@ -0,0 +1,17 @@
+package session
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("POST", "/auth/access_token", nil)
+	req.RemoteAddr = "192.0.2.1:54321"
+	assert.Equal(t, "192.0.2.1", ClientIP(req))
+
+	req.Header.Set("X-Forwarded-For", "203.0.113.42, 10.0.0.1")
+	assert.Equal(t, "203.0.113.42", ClientIP(req))
+}
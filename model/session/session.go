@@ -41,6 +41,9 @@ type Session struct {
 	LastSeen  time.Time `json:"last_seen"`
 	LongRun   bool      `json:"long_run"`
 	ShortRun  bool      `json:"short_run"`
+	// UserID is the identifier of the user, for the instances in
+	// organization mode. It is empty for the owner of the instance.
+	UserID string `json:"user_id,omitempty"`
 }
 
 // DocType implements couchdb.Doc
@@ -102,6 +105,12 @@ func (s *Session) OlderThan(t time.Duration) bool {
 
 // New creates a session in couchdb for the given instance
 func New(i *instance.Instance, duration Duration) (*Session, error) {
+	return NewForUser(i, duration, "")
+}
+
+// NewForUser creates a session in couchdb for a user of an instance in
+// organization mode.
+func NewForUser(i *instance.Instance, duration Duration, userID string) (*Session, error) {
 	now := time.Now()
 	s := &Session{
 		instance:  i,
@@ -109,6 +118,7 @@ func New(i *instance.Instance, duration Duration) (*Session, error) {
 		CreatedAt: now,
 		ShortRun:  duration == ShortRun,
 		LongRun:   duration == LongRun,
+		UserID:    userID,
 	}
 	if err := couchdb.CreateDoc(i, s); err != nil {
 		return nil, err
@@ -302,6 +312,29 @@ func DeleteOthers(i *instance.Instance, selfSessionID string) error {
 	return nil
 }
 
+// DeleteForUser removes all the sessions of a user of an instance in
+// organization mode.
+func DeleteForUser(i *instance.Instance, userID string) error {
+	var sessions []*Session
+	err := couchdb.ForeachDocs(i, consts.Sessions, func(_ string, data json.RawMessage) error {
+		var s Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s.UserID == userID {
+			sessions = append(sessions, &s)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		s.Delete(i)
+	}
+	return nil
+}
+
 // cookieSessionMACConfig returns the options to authenticate the session
 // cookie.
 //
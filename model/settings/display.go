@@ -0,0 +1,151 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cozy/cozy-stack/model/instance"
+)
+
+// The values for the display preferences.
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+	// ThemeAuto follows the preference of the operating system.
+	ThemeAuto = "auto"
+
+	ContrastNormal = "normal"
+	ContrastHigh   = "high"
+
+	FontSizeSmall  = "small"
+	FontSizeMedium = "medium"
+	FontSizeLarge  = "large"
+	FontSizeXLarge = "x-large"
+)
+
+var (
+	ErrInvalidTheme    = errors.New("invalid theme")
+	ErrInvalidContrast = errors.New("invalid contrast")
+	ErrInvalidFontSize = errors.New("invalid font size")
+)
+
+// DisplayPrefs are the preferences of the user for the display (dark mode,
+// contrast, font size). They are applied to the pages rendered by the stack,
+// and the apps can read them to be consistent.
+type DisplayPrefs struct {
+	Theme    string `json:"theme"`
+	Contrast string `json:"contrast"`
+	FontSize string `json:"font_size"`
+}
+
+// UpdateDisplayPrefsCmd contains the display preferences to update. The nil
+// fields are left unchanged, and an empty string removes the preference of
+// the user to use the default of the context.
+type UpdateDisplayPrefsCmd struct {
+	Theme    *string
+	Contrast *string
+	FontSize *string
+}
+
+// ContextDisplayPrefs returns the default display preferences, configured in
+// the display section of the context of the instance.
+func ContextDisplayPrefs(inst *instance.Instance) *DisplayPrefs {
+	prefs := &DisplayPrefs{
+		Theme:    ThemeLight,
+		Contrast: ContrastNormal,
+		FontSize: FontSizeMedium,
+	}
+	if ctxSettings, ok := inst.SettingsContext(); ok {
+		if cfg, ok := ctxSettings["display"].(map[string]interface{}); ok {
+			prefs.merge(cfg)
+		}
+	}
+	return prefs
+}
+
+// GetDisplayPrefs returns the display preferences of the user, with the
+// defaults of the context for the missing ones.
+func (s *SettingsService) GetDisplayPrefs(inst *instance.Instance) (*DisplayPrefs, error) {
+	prefs := ContextDisplayPrefs(inst)
+	settings, err := s.storage.getInstanceSettings(inst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the settings: %w", err)
+	}
+	if display, ok := settings.M["display"].(map[string]interface{}); ok {
+		prefs.merge(display)
+	}
+	return prefs, nil
+}
+
+// UpdateDisplayPrefs changes the display preferences of the user.
+func (s *SettingsService) UpdateDisplayPrefs(inst *instance.Instance, cmd *UpdateDisplayPrefsCmd) (*DisplayPrefs, error) {
+	if cmd.Theme != nil && *cmd.Theme != "" && !validTheme(*cmd.Theme) {
+		return nil, ErrInvalidTheme
+	}
+	if cmd.Contrast != nil && *cmd.Contrast != "" && !validContrast(*cmd.Contrast) {
+		return nil, ErrInvalidContrast
+	}
+	if cmd.FontSize != nil && *cmd.FontSize != "" && !validFontSize(*cmd.FontSize) {
+		return nil, ErrInvalidFontSize
+	}
+
+	settings, err := s.storage.getInstanceSettings(inst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the settings: %w", err)
+	}
+
+	display, _ := settings.M["display"].(map[string]interface{})
+	if display == nil {
+		display = make(map[string]interface{})
+	}
+	set := func(key string, value *string) {
+		if value == nil {
+			return
+		}
+		if *value == "" {
+			delete(display, key)
+		} else {
+			display[key] = *value
+		}
+	}
+	set("theme", cmd.Theme)
+	set("contrast", cmd.Contrast)
+	set("font_size", cmd.FontSize)
+	settings.M["display"] = display
+
+	err = s.storage.setInstanceSettings(inst, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save the settings changes: %w", err)
+	}
+
+	return s.GetDisplayPrefs(inst)
+}
+
+// merge overrides the preferences with the valid values of the given map.
+func (p *DisplayPrefs) merge(m map[string]interface{}) {
+	if theme, ok := m["theme"].(string); ok && validTheme(theme) {
+		p.Theme = theme
+	}
+	if contrast, ok := m["contrast"].(string); ok && validContrast(contrast) {
+		p.Contrast = contrast
+	}
+	if size, ok := m["font_size"].(string); ok && validFontSize(size) {
+		p.FontSize = size
+	}
+}
+
+func validTheme(theme string) bool {
+	return theme == ThemeLight || theme == ThemeDark || theme == ThemeAuto
+}
+
+func validContrast(contrast string) bool {
+	return contrast == ContrastNormal || contrast == ContrastHigh
+}
+
+func validFontSize(size string) bool {
+	switch size {
+	case FontSizeSmall, FontSizeMedium, FontSizeLarge, FontSizeXLarge:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/cloudery"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/token"
+	build "github.com/cozy/cozy-stack/pkg/config"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/emailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetDisplayPrefs_with_context_defaults(t *testing.T) {
+	mode := build.BuildMode
+	t.Cleanup(func() { build.BuildMode = mode })
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Contexts = map[string]interface{}{
+		"custom": map[string]interface{}{
+			"display": map[string]interface{}{
+				"theme":     "dark",
+				"font_size": "huge",
+			},
+		},
+	}
+
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain:      "foo.mycozy.cloud",
+		ContextName: "custom",
+	}
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"display": map[string]interface{}{
+				"contrast": "high",
+			},
+		},
+	}, nil).Once()
+
+	prefs, err := svc.GetDisplayPrefs(&inst)
+	require.NoError(t, err)
+	assert.Equal(t, &DisplayPrefs{
+		Theme:    ThemeDark,
+		Contrast: ContrastHigh,
+		FontSize: FontSizeMedium,
+	}, prefs)
+}
+
+func Test_UpdateDisplayPrefs_success(t *testing.T) {
+	mode := build.BuildMode
+	t.Cleanup(func() { build.BuildMode = mode })
+	config.UseTestFile(t)
+
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"display": map[string]interface{}{
+				"theme":    "dark",
+				"contrast": "high",
+			},
+		},
+	}, nil)
+
+	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"display": map[string]interface{}{
+				"theme":     "dark",
+				"font_size": "large",
+			},
+		},
+	}).Return(nil).Once()
+
+	contrast := ""
+	size := FontSizeLarge
+	_, err := svc.UpdateDisplayPrefs(&inst, &UpdateDisplayPrefsCmd{
+		Contrast: &contrast,
+		FontSize: &size,
+	})
+	assert.NoError(t, err)
+}
+
+func Test_UpdateDisplayPrefs_with_an_invalid_theme(t *testing.T) {
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	theme := "pink"
+	_, err := svc.UpdateDisplayPrefs(&inst, &UpdateDisplayPrefsCmd{Theme: &theme})
+	assert.ErrorIs(t, err, ErrInvalidTheme)
+}
@@ -19,6 +19,12 @@ type Service interface {
 	ResendEmailUpdate(inst *instance.Instance) error
 	ConfirmEmailUpdate(inst *instance.Instance, tok string) error
 	CancelEmailUpdate(inst *instance.Instance) error
+	GetProfile(db prefixer.Prefixer) (*Profile, error)
+	UpdateProfile(inst *instance.Instance, cmd *UpdateProfileCmd) (*Profile, error)
+	GetDisplayPrefs(inst *instance.Instance) (*DisplayPrefs, error)
+	UpdateDisplayPrefs(inst *instance.Instance, cmd *UpdateDisplayPrefsCmd) (*DisplayPrefs, error)
+	GetReportPrefs(inst *instance.Instance) (*ReportPrefs, error)
+	UpdateReportPrefs(inst *instance.Instance, cmd *UpdateReportPrefsCmd) (*ReportPrefs, error)
 }
 
 func Init(
@@ -47,3 +53,24 @@ func PublicName(db prefixer.Prefixer) (string, error) {
 func SettingsDocument(inst prefixer.Prefixer) (*couchdb.JSONDoc, error) {
 	return service.GetInstanceSettings(inst)
 }
+
+// GetProfile returns the profile of the instance owner.
+//
+// Deprecated: Use [Service.GetProfile] instead.
+func GetProfile(db prefixer.Prefixer) (*Profile, error) {
+	return service.GetProfile(db)
+}
+
+// GetDisplayPrefs returns the display preferences of the user.
+//
+// Deprecated: Use [Service.GetDisplayPrefs] instead.
+func GetDisplayPrefs(inst *instance.Instance) (*DisplayPrefs, error) {
+	return service.GetDisplayPrefs(inst)
+}
+
+// GetReportPrefs returns the preferences of the user for the reports.
+//
+// Deprecated: Use [Service.GetReportPrefs] instead.
+func GetReportPrefs(inst *instance.Instance) (*ReportPrefs, error) {
+	return service.GetReportPrefs(inst)
+}
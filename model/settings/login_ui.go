@@ -0,0 +1,119 @@
+package settings
+
+import (
+	"net/url"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// The default values for the composition of the login pages.
+const (
+	defaultLoginLogo     = "/images/logo-dark.svg"
+	defaultLoginLogoLink = "https://cozy.io/"
+)
+
+// LoginUI describes the composition of the login and onboarding pages. It is
+// configured in the login_ui section of the context, and it is used by the
+// templates of the stack and by the flagship app, so that the hosters can
+// customize these pages without forking the templates.
+type LoginUI struct {
+	// Logo is the path of an asset, that can be customized for the context.
+	Logo     string `json:"logo"`
+	LogoLink string `json:"logo_link,omitempty"`
+	// OIDCButton is used to show a button to log in with the OpenID Connect
+	// provider of the context, next to the password field.
+	OIDCButton    *LoginLink  `json:"oidc_button,omitempty"`
+	FranceConnect bool        `json:"franceconnect"`
+	MagicLink     bool        `json:"magic_link"`
+	PasswordTip   bool        `json:"password_tip"`
+	Links         []LoginLink `json:"links,omitempty"`
+	LegalText     string      `json:"legal_text,omitempty"`
+}
+
+// LoginLink is a link shown on the login pages.
+type LoginLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// GetLoginUI returns the composition of the login pages for the instance.
+func GetLoginUI(inst *instance.Instance) *LoginUI {
+	ui := &LoginUI{
+		Logo:          defaultLoginLogo,
+		LogoLink:      defaultLoginLogoLink,
+		FranceConnect: inst.FranceConnectID != "",
+		MagicLink:     inst.MagicLink,
+		PasswordTip:   true,
+	}
+	ctxSettings, ok := inst.SettingsContext()
+	if !ok {
+		return ui
+	}
+	cfg, ok := ctxSettings["login_ui"].(map[string]interface{})
+	if !ok {
+		return ui
+	}
+
+	if logo, ok := cfg["logo"].(string); ok && logo != "" {
+		ui.Logo = logo
+	}
+	if link, ok := cfg["logo_link"].(string); ok {
+		if link == "" || isHTTPURL(link) {
+			ui.LogoLink = link
+		}
+	}
+	if tip, ok := cfg["password_tip"].(bool); ok {
+		ui.PasswordTip = tip
+	}
+	if button, ok := cfg["oidc_button"].(map[string]interface{}); ok {
+		if _, hasOIDC := config.GetOIDC(inst.ContextName); hasOIDC && !inst.HasForcedOIDC() {
+			label, _ := button["label"].(string)
+			if label != "" {
+				ui.OIDCButton = &LoginLink{
+					Label: localized(label, inst.Locale),
+					URL:   inst.PageURL("/oidc/start", nil),
+				}
+			}
+		}
+	}
+	if links, ok := cfg["links"].([]interface{}); ok {
+		for _, item := range links {
+			link, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			label := localized(link["label"], inst.Locale)
+			u, _ := link["url"].(string)
+			if label != "" && isHTTPURL(u) {
+				ui.Links = append(ui.Links, LoginLink{Label: label, URL: u})
+			}
+		}
+	}
+	ui.LegalText = localized(cfg["legal_text"], inst.Locale)
+	return ui
+}
+
+// localized returns the text for the locale: the text can be a string, or a
+// map with a text for each locale (and en as the fallback).
+func localized(text interface{}, locale string) string {
+	switch text := text.(type) {
+	case string:
+		return text
+	case map[string]interface{}:
+		if s, ok := text[locale].(string); ok {
+			return s
+		}
+		s, _ := text["en"].(string)
+		return s
+	}
+	return ""
+}
+
+func isHTTPURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
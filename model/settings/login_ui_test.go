@@ -0,0 +1,74 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	build "github.com/cozy/cozy-stack/pkg/config"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoginUI(t *testing.T) {
+	mode := build.BuildMode
+	t.Cleanup(func() { build.BuildMode = mode })
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Contexts = map[string]interface{}{
+		"custom": map[string]interface{}{
+			"login_ui": map[string]interface{}{
+				"logo":         "/images/custom-logo.svg",
+				"logo_link":    "javascript:alert(1)",
+				"password_tip": false,
+				"oidc_button": map[string]interface{}{
+					"label": "Log in with my company account",
+				},
+				"links": []interface{}{
+					map[string]interface{}{
+						"label": map[string]interface{}{"en": "Terms", "fr": "Conditions"},
+						"url":   "https://example.com/tos",
+					},
+					map[string]interface{}{
+						"label": "Invalid",
+						"url":   "/relative",
+					},
+				},
+				"legal_text": map[string]interface{}{"en": "Hosted by Example"},
+			},
+		},
+	}
+	conf.Authentication = map[string]interface{}{
+		"custom": map[string]interface{}{
+			"oidc": map[string]interface{}{"client_id": "foo"},
+		},
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		inst := &instance.Instance{Domain: "alice.cozy.localhost", Locale: "en"}
+		ui := GetLoginUI(inst)
+		assert.Equal(t, defaultLoginLogo, ui.Logo)
+		assert.Equal(t, defaultLoginLogoLink, ui.LogoLink)
+		assert.True(t, ui.PasswordTip)
+		assert.Nil(t, ui.OIDCButton)
+		assert.Empty(t, ui.Links)
+		assert.Empty(t, ui.LegalText)
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		inst := &instance.Instance{
+			Domain:      "bob.cozy.localhost",
+			ContextName: "custom",
+			Locale:      "fr",
+		}
+		ui := GetLoginUI(inst)
+		assert.Equal(t, "/images/custom-logo.svg", ui.Logo)
+		assert.Equal(t, defaultLoginLogoLink, ui.LogoLink)
+		assert.False(t, ui.PasswordTip)
+		require.NotNil(t, ui.OIDCButton)
+		assert.Equal(t, "Log in with my company account", ui.OIDCButton.Label)
+		assert.Contains(t, ui.OIDCButton.URL, "/oidc/start")
+		assert.Equal(t, []LoginLink{{Label: "Conditions", URL: "https://example.com/tos"}}, ui.Links)
+		assert.Equal(t, "Hosted by Example", ui.LegalText)
+	})
+}
@@ -0,0 +1,110 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// The privacy settings for the public profile.
+const (
+	// ProfilePublic means that the avatar and the bio are visible by anyone
+	// on the public profile.
+	ProfilePublic = "public"
+	// ProfilePrivate means that only the display name is visible on the
+	// public profile.
+	ProfilePrivate = "private"
+)
+
+// MaxBioLength is the maximal number of characters for the bio.
+const MaxBioLength = 500
+
+var (
+	ErrInvalidVisibility = errors.New("invalid profile visibility")
+	ErrBioTooLong        = errors.New("the bio is too long")
+)
+
+// Profile is the information about the owner of the instance that can be
+// shown to other people, like the recipients of a sharing.
+type Profile struct {
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio,omitempty"`
+	AvatarID    string `json:"avatar_id,omitempty"`
+	Visibility  string `json:"visibility"`
+}
+
+// IsPublic returns true if the avatar and the bio can be shown publicly.
+func (p *Profile) IsPublic() bool {
+	return p.Visibility != ProfilePrivate
+}
+
+// UpdateProfileCmd contains the fields of the profile to update. The nil
+// fields are left unchanged. The display name is the public_name of the
+// instance settings.
+type UpdateProfileCmd struct {
+	DisplayName *string
+	Bio         *string
+	AvatarID    *string
+	Visibility  *string
+}
+
+// GetProfile returns the profile of the instance owner.
+func (s *SettingsService) GetProfile(db prefixer.Prefixer) (*Profile, error) {
+	settings, err := s.storage.getInstanceSettings(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the settings: %w", err)
+	}
+
+	publicName, err := s.PublicName(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the instance settings: %w", err)
+	}
+
+	profile := &Profile{DisplayName: publicName, Visibility: ProfilePublic}
+	profile.Bio, _ = settings.M["bio"].(string)
+	profile.AvatarID, _ = settings.M["avatar_id"].(string)
+	if visibility, ok := settings.M["profile_visibility"].(string); ok && visibility != "" {
+		profile.Visibility = visibility
+	}
+	return profile, nil
+}
+
+// UpdateProfile changes the profile of the instance owner.
+//
+// The caller is responsible of checking that the avatar is an image of the
+// VFS.
+func (s *SettingsService) UpdateProfile(inst *instance.Instance, cmd *UpdateProfileCmd) (*Profile, error) {
+	if cmd.Visibility != nil && *cmd.Visibility != ProfilePublic && *cmd.Visibility != ProfilePrivate {
+		return nil, ErrInvalidVisibility
+	}
+	if cmd.Bio != nil && len([]rune(*cmd.Bio)) > MaxBioLength {
+		return nil, ErrBioTooLong
+	}
+
+	settings, err := s.storage.getInstanceSettings(inst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the settings: %w", err)
+	}
+
+	if cmd.DisplayName != nil {
+		settings.M["public_name"] = *cmd.DisplayName
+	}
+	if cmd.Bio != nil {
+		settings.M["bio"] = *cmd.Bio
+	}
+	if cmd.AvatarID != nil {
+		settings.M["avatar_id"] = *cmd.AvatarID
+	}
+	if cmd.Visibility != nil {
+		settings.M["profile_visibility"] = *cmd.Visibility
+	}
+
+	err = s.storage.setInstanceSettings(inst, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save the settings changes: %w", err)
+	}
+
+	return s.GetProfile(inst)
+}
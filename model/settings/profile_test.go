@@ -0,0 +1,104 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/cloudery"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/token"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/emailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetProfile_success(t *testing.T) {
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name": "Jane Doe",
+			"bio":         "Hello world",
+			"avatar_id":   "some-file-id",
+		},
+	}, nil).Twice()
+
+	profile, err := svc.GetProfile(&inst)
+	require.NoError(t, err)
+	assert.Equal(t, &Profile{
+		DisplayName: "Jane Doe",
+		Bio:         "Hello world",
+		AvatarID:    "some-file-id",
+		Visibility:  ProfilePublic,
+	}, profile)
+	assert.True(t, profile.IsPublic())
+}
+
+func Test_GetProfile_private(t *testing.T) {
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"profile_visibility": "private",
+		},
+	}, nil).Twice()
+
+	profile, err := svc.GetProfile(&inst)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", profile.DisplayName)
+	assert.False(t, profile.IsPublic())
+}
+
+func Test_UpdateProfile_success(t *testing.T) {
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name": "Jane Doe",
+		},
+	}, nil)
+
+	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name":        "Jane Doe",
+			"bio":                "Hello world",
+			"profile_visibility": "private",
+		},
+	}).Return(nil).Once()
+
+	bio := "Hello world"
+	visibility := ProfilePrivate
+	_, err := svc.UpdateProfile(&inst, &UpdateProfileCmd{
+		Bio:        &bio,
+		Visibility: &visibility,
+	})
+	assert.NoError(t, err)
+}
+
+func Test_UpdateProfile_with_an_invalid_visibility(t *testing.T) {
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	visibility := "friends"
+	_, err := svc.UpdateProfile(&inst, &UpdateProfileCmd{Visibility: &visibility})
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+}
@@ -0,0 +1,79 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/cozy/cozy-stack/model/instance"
+)
+
+// ReportPrefs are the preferences of the user for the reports emailed by the
+// stack.
+type ReportPrefs struct {
+	// Monthly is true if the user wants to receive a summary of the activity
+	// of their cozy each month.
+	Monthly bool `json:"monthly"`
+}
+
+// UpdateReportPrefsCmd contains the report preferences to update. The nil
+// fields are left unchanged.
+type UpdateReportPrefsCmd struct {
+	Monthly *bool
+}
+
+// ContextReportPrefs returns the default report preferences, configured in
+// the reports section of the context of the instance. The reports are opt-in,
+// so they are disabled if the context has no default.
+func ContextReportPrefs(inst *instance.Instance) *ReportPrefs {
+	prefs := &ReportPrefs{}
+	if ctxSettings, ok := inst.SettingsContext(); ok {
+		if cfg, ok := ctxSettings["reports"].(map[string]interface{}); ok {
+			prefs.merge(cfg)
+		}
+	}
+	return prefs
+}
+
+// GetReportPrefs returns the report preferences of the user, with the
+// defaults of the context for the missing ones.
+func (s *SettingsService) GetReportPrefs(inst *instance.Instance) (*ReportPrefs, error) {
+	prefs := ContextReportPrefs(inst)
+	settings, err := s.storage.getInstanceSettings(inst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the settings: %w", err)
+	}
+	if reports, ok := settings.M["reports"].(map[string]interface{}); ok {
+		prefs.merge(reports)
+	}
+	return prefs, nil
+}
+
+// UpdateReportPrefs changes the report preferences of the user.
+func (s *SettingsService) UpdateReportPrefs(inst *instance.Instance, cmd *UpdateReportPrefsCmd) (*ReportPrefs, error) {
+	settings, err := s.storage.getInstanceSettings(inst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the settings: %w", err)
+	}
+
+	reports, _ := settings.M["reports"].(map[string]interface{})
+	if reports == nil {
+		reports = make(map[string]interface{})
+	}
+	if cmd.Monthly != nil {
+		reports["monthly"] = *cmd.Monthly
+	}
+	settings.M["reports"] = reports
+
+	err = s.storage.setInstanceSettings(inst, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save the settings changes: %w", err)
+	}
+
+	return s.GetReportPrefs(inst)
+}
+
+// merge overrides the preferences with the valid values of the given map.
+func (p *ReportPrefs) merge(m map[string]interface{}) {
+	if monthly, ok := m["monthly"].(bool); ok {
+		p.Monthly = monthly
+	}
+}
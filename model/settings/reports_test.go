@@ -0,0 +1,80 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/cloudery"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/token"
+	build "github.com/cozy/cozy-stack/pkg/config"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/emailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetReportPrefs_with_context_defaults(t *testing.T) {
+	mode := build.BuildMode
+	t.Cleanup(func() { build.BuildMode = mode })
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+	conf.Contexts = map[string]interface{}{
+		"reported": map[string]interface{}{
+			"reports": map[string]interface{}{"monthly": true},
+		},
+	}
+
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{Domain: "foo.mycozy.cloud", ContextName: "reported"}
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{},
+	}, nil).Once()
+	prefs, err := svc.GetReportPrefs(&inst)
+	require.NoError(t, err)
+	assert.True(t, prefs.Monthly)
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"reports": map[string]interface{}{"monthly": false},
+		},
+	}, nil).Once()
+	prefs, err = svc.GetReportPrefs(&inst)
+	require.NoError(t, err)
+	assert.False(t, prefs.Monthly)
+
+	// The reports are opt-in by default
+	other := instance.Instance{Domain: "bar.mycozy.cloud"}
+	storage.On("getInstanceSettings", &other).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{},
+	}, nil).Once()
+	prefs, err = svc.GetReportPrefs(&other)
+	require.NoError(t, err)
+	assert.False(t, prefs.Monthly)
+}
+
+func Test_UpdateReportPrefs_success(t *testing.T) {
+	mode := build.BuildMode
+	t.Cleanup(func() { build.BuildMode = mode })
+	config.UseTestFile(t)
+
+	storage := newStorageMock(t)
+	svc := NewService(emailer.NewMock(t), instance.NewMock(t), token.NewMock(t), cloudery.NewMock(t), storage)
+
+	inst := instance.Instance{Domain: "foo.mycozy.cloud"}
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{"public_name": "Jane"},
+	}, nil)
+	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name": "Jane",
+			"reports":     map[string]interface{}{"monthly": true},
+		},
+	}).Return(nil).Once()
+
+	monthly := true
+	_, err := svc.UpdateReportPrefs(&inst, &UpdateReportPrefsCmd{Monthly: &monthly})
+	assert.NoError(t, err)
+}
@@ -86,8 +86,10 @@ type UpdateEmailCmd struct {
 // StartEmailUpdate will start the email updating process.
 //
 // This process consists of validating the user with a password and sending
-// a validation email to the new address with a validation link. This link
-// will allow the user to confirm its email.
+// a validation email to the new address with a validation link. If the
+// instance already has an email address, another validation link is sent to
+// this old address, and the change is effective only when both links have
+// been clicked.
 func (s *SettingsService) StartEmailUpdate(inst *instance.Instance, cmd *UpdateEmailCmd) error {
 	err := s.instance.CheckPassphrase(inst, cmd.Passphrase)
 	if err != nil {
@@ -99,16 +101,13 @@ func (s *SettingsService) StartEmailUpdate(inst *instance.Instance, cmd *UpdateE
 		return fmt.Errorf("failed to fetch the settings: %w", err)
 	}
 
-	publicName, err := s.PublicName(inst)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve the instance settings: %w", err)
-	}
-
 	settings.M["pending_email"] = cmd.Email
+	delete(settings.M, "pending_email_confirmed_new")
+	delete(settings.M, "pending_email_confirmed_old")
 
-	token, err := s.token.GenerateAndSave(inst, token.EmailUpdate, cmd.Email, TokenExpiration)
+	err = s.sendEmailUpdateLinks(inst, settings, cmd.Email)
 	if err != nil {
-		return fmt.Errorf("failed to generate and save the confirmation token: %w", err)
+		return err
 	}
 
 	err = s.storage.setInstanceSettings(inst, settings)
@@ -116,54 +115,77 @@ func (s *SettingsService) StartEmailUpdate(inst *instance.Instance, cmd *UpdateE
 		return fmt.Errorf("failed to save the settings changes: %w", err)
 	}
 
-	link := inst.PageURL("/settings/email/confirm", url.Values{
-		"token": []string{token},
-	})
-
-	err = s.emailer.SendPendingEmail(inst, &emailer.SendEmailCmd{
-		TemplateName: "update_email",
-		TemplateValues: map[string]interface{}{
-			"PublicName":      publicName,
-			"EmailUpdateLink": link,
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to send the email: %w", err)
-	}
+	inst.Logger().WithNamespace("loginaudit").
+		Infof("Email update to %s requested", cmd.Email)
 
 	return nil
 }
 
-// ResendEmailUpdate will resend the validation email.
+// ResendEmailUpdate will resend the validation emails that have not been
+// confirmed yet.
 func (s *SettingsService) ResendEmailUpdate(inst *instance.Instance) error {
 	settings, err := s.storage.getInstanceSettings(inst)
 	if err != nil {
 		return fmt.Errorf("failed to fetch the settings: %w", err)
 	}
 
+	pendingEmail, ok := settings.M["pending_email"].(string)
+	if !ok {
+		return ErrNoPendingEmail
+	}
+
+	return s.sendEmailUpdateLinks(inst, settings, pendingEmail)
+}
+
+// sendEmailUpdateLinks sends the validation links to the new address and to
+// the old one, except for the addresses that have already been confirmed.
+func (s *SettingsService) sendEmailUpdateLinks(inst *instance.Instance, settings *couchdb.JSONDoc, pendingEmail string) error {
 	publicName, err := s.PublicName(inst)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve the instance settings: %w", err)
 	}
 
-	pendingEmail, ok := settings.M["pending_email"].(string)
-	if !ok {
-		return ErrNoPendingEmail
+	if confirmed, _ := settings.M["pending_email_confirmed_new"].(bool); !confirmed {
+		tok, err := s.token.GenerateAndSave(inst, token.EmailUpdate, pendingEmail, TokenExpiration)
+		if err != nil {
+			return fmt.Errorf("failed to generate and save the confirmation token: %w", err)
+		}
+
+		link := inst.PageURL("/settings/email/confirm", url.Values{
+			"token": []string{tok},
+		})
+
+		err = s.emailer.SendPendingEmail(inst, &emailer.SendEmailCmd{
+			TemplateName: "update_email",
+			TemplateValues: map[string]interface{}{
+				"PublicName":      publicName,
+				"EmailUpdateLink": link,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send the email: %w", err)
+		}
+	}
+
+	if !needsOldEmailConfirmation(settings) {
+		return nil
 	}
 
-	token, err := s.token.GenerateAndSave(inst, token.EmailUpdate, pendingEmail, TokenExpiration)
+	tok, err := s.token.GenerateAndSave(inst, token.EmailUpdateOld, pendingEmail, TokenExpiration)
 	if err != nil {
 		return fmt.Errorf("failed to generate and save the confirmation token: %w", err)
 	}
 
 	link := inst.PageURL("/settings/email/confirm", url.Values{
-		"token": []string{token},
+		"token": []string{tok},
 	})
 
-	err = s.emailer.SendPendingEmail(inst, &emailer.SendEmailCmd{
-		TemplateName: "update_email",
+	// The mail is sent to the current address of the instance owner
+	err = s.emailer.SendEmail(inst, &emailer.SendEmailCmd{
+		TemplateName: "update_email_old",
 		TemplateValues: map[string]interface{}{
 			"PublicName":      publicName,
+			"NewEmail":        pendingEmail,
 			"EmailUpdateLink": link,
 		},
 	})
@@ -174,10 +196,21 @@ func (s *SettingsService) ResendEmailUpdate(inst *instance.Instance) error {
 	return nil
 }
 
+// needsOldEmailConfirmation returns true if the change must be confirmed
+// from the current address of the instance, and it has not been done yet.
+func needsOldEmailConfirmation(settings *couchdb.JSONDoc) bool {
+	if oldEmail, _ := settings.M["email"].(string); oldEmail == "" {
+		return false
+	}
+	confirmed, _ := settings.M["pending_email_confirmed_old"].(bool)
+	return !confirmed
+}
+
 // ConfirmEmailUpdate is the second step to the email update process.
 //
-// This step consiste to make the email change effectif and relay the change
-// into the cloudery.
+// The token can come from the link sent to the new address or from the link
+// sent to the old one. When both addresses have been confirmed, the email
+// change is made effective and relayed into the cloudery.
 func (s *SettingsService) ConfirmEmailUpdate(inst *instance.Instance, tok string) error {
 	settings, err := s.storage.getInstanceSettings(inst)
 	if err != nil {
@@ -190,18 +223,42 @@ func (s *SettingsService) ConfirmEmailUpdate(inst *instance.Instance, tok string
 	}
 
 	err = s.token.Validate(inst, token.EmailUpdate, pendingEmail, tok)
-	if err != nil {
+	if err == nil {
+		settings.M["pending_email_confirmed_new"] = true
+		inst.Logger().WithNamespace("loginaudit").
+			Infof("Email update to %s confirmed from the new address", pendingEmail)
+	} else if oldErr := s.token.Validate(inst, token.EmailUpdateOld, pendingEmail, tok); oldErr == nil {
+		settings.M["pending_email_confirmed_old"] = true
+		inst.Logger().WithNamespace("loginaudit").
+			Infof("Email update to %s confirmed from the old address", pendingEmail)
+	} else {
 		return fmt.Errorf("failed to validate the token: %w", err)
 	}
 
+	newConfirmed, _ := settings.M["pending_email_confirmed_new"].(bool)
+	if !newConfirmed || needsOldEmailConfirmation(settings) {
+		// Wait for the confirmation of the other address
+		err = s.storage.setInstanceSettings(inst, settings)
+		if err != nil {
+			return fmt.Errorf("failed to save the settings changes: %w", err)
+		}
+		return nil
+	}
+
+	oldEmail, _ := settings.M["email"].(string)
 	settings.M["email"] = pendingEmail
 	settings.M["pending_email"] = nil
+	delete(settings.M, "pending_email_confirmed_new")
+	delete(settings.M, "pending_email_confirmed_old")
 
 	err = s.storage.setInstanceSettings(inst, settings)
 	if err != nil {
 		return fmt.Errorf("failed to save the settings changes: %w", err)
 	}
 
+	inst.Logger().WithNamespace("loginaudit").
+		Infof("Email updated from %q to %q", oldEmail, pendingEmail)
+
 	publicName, _ := settings.M["public_name"].(string)
 	// if the public name is not defined, use the instance's domain
 	if publicName == "" {
@@ -236,11 +293,16 @@ func (s *SettingsService) CancelEmailUpdate(inst *instance.Instance) error {
 	}
 
 	settings.M["pending_email"] = nil
+	delete(settings.M, "pending_email_confirmed_new")
+	delete(settings.M, "pending_email_confirmed_old")
 
 	err = s.storage.setInstanceSettings(inst, settings)
 	if err != nil {
 		return fmt.Errorf("failed to save the settings changes: %w", err)
 	}
 
+	inst.Logger().WithNamespace("loginaudit").
+		Infof("Email update canceled")
+
 	return nil
 }
@@ -65,3 +65,69 @@ func (m *Mock) ConfirmEmailUpdate(inst *instance.Instance, tok string) error {
 func (m *Mock) CancelEmailUpdate(inst *instance.Instance) error {
 	return m.Called(inst).Error(0)
 }
+
+// GetProfile mock method.
+func (m *Mock) GetProfile(db prefixer.Prefixer) (*Profile, error) {
+	args := m.Called(db)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*Profile), args.Error(1)
+}
+
+// UpdateProfile mock method.
+func (m *Mock) UpdateProfile(inst *instance.Instance, cmd *UpdateProfileCmd) (*Profile, error) {
+	args := m.Called(inst, cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*Profile), args.Error(1)
+}
+
+// GetDisplayPrefs mock method.
+func (m *Mock) GetDisplayPrefs(inst *instance.Instance) (*DisplayPrefs, error) {
+	args := m.Called(inst)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*DisplayPrefs), args.Error(1)
+}
+
+// UpdateDisplayPrefs mock method.
+func (m *Mock) UpdateDisplayPrefs(inst *instance.Instance, cmd *UpdateDisplayPrefsCmd) (*DisplayPrefs, error) {
+	args := m.Called(inst, cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*DisplayPrefs), args.Error(1)
+}
+
+// GetReportPrefs mock method.
+func (m *Mock) GetReportPrefs(inst *instance.Instance) (*ReportPrefs, error) {
+	args := m.Called(inst)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*ReportPrefs), args.Error(1)
+}
+
+// UpdateReportPrefs mock method.
+func (m *Mock) UpdateReportPrefs(inst *instance.Instance, cmd *UpdateReportPrefsCmd) (*ReportPrefs, error) {
+	args := m.Called(inst, cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*ReportPrefs), args.Error(1)
+}
@@ -62,6 +62,67 @@ func Test_StartEmailUpdate_success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_StartEmailUpdate_with_an_old_email(t *testing.T) {
+	emailerSvc := emailer.NewMock(t)
+	instSvc := instance.NewMock(t)
+	tokenSvc := token.NewMock(t)
+	clouderySvc := cloudery.NewMock(t)
+	storage := newStorageMock(t)
+
+	svc := NewService(emailerSvc, instSvc, tokenSvc, clouderySvc, storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
+	cmd := &UpdateEmailCmd{
+		Passphrase: []byte("some-pass"),
+		Email:      "some@email.com",
+	}
+
+	instSvc.On("CheckPassphrase", &inst, cmd.Passphrase).Return(nil).Once()
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name": "Jane Doe",
+			"email":       "foo@bar.baz",
+		},
+	}, nil).Twice()
+
+	tokenSvc.On("GenerateAndSave", &inst, token.EmailUpdate, "some@email.com", TokenExpiration).
+		Return("some-token", nil).Once()
+	tokenSvc.On("GenerateAndSave", &inst, token.EmailUpdateOld, "some@email.com", TokenExpiration).
+		Return("old-token", nil).Once()
+
+	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name":   "Jane Doe",
+			"email":         "foo@bar.baz",
+			"pending_email": "some@email.com",
+		},
+	}).Return(nil).Once()
+
+	emailerSvc.On("SendPendingEmail", &inst, &emailer.SendEmailCmd{
+		TemplateName: "update_email",
+		TemplateValues: map[string]interface{}{
+			"PublicName":      "Jane Doe",
+			"EmailUpdateLink": "http://foo.mycozy.cloud/settings/email/confirm?token=some-token",
+		},
+	}).Return(nil).Once()
+
+	emailerSvc.On("SendEmail", &inst, &emailer.SendEmailCmd{
+		TemplateName: "update_email_old",
+		TemplateValues: map[string]interface{}{
+			"PublicName":      "Jane Doe",
+			"NewEmail":        "some@email.com",
+			"EmailUpdateLink": "http://foo.mycozy.cloud/settings/email/confirm?token=old-token",
+		},
+	}).Return(nil).Once()
+
+	err := svc.StartEmailUpdate(&inst, cmd)
+	assert.NoError(t, err)
+}
+
 func Test_StartEmailUpdate_with_an_invalid_password(t *testing.T) {
 	emailerSvc := emailer.NewMock(t)
 	instSvc := instance.NewMock(t)
@@ -147,6 +208,49 @@ func TestConfirmEmailUpdate_success(t *testing.T) {
 		Locale: "fr/FR",
 	}
 
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name":                 "Jane Doe",
+			"email":                       "foo@bar.baz",
+			"pending_email":               "some@email.com",
+			"pending_email_confirmed_old": true,
+		},
+	}, nil).Once()
+
+	tokenSvc.On("Validate", &inst, token.EmailUpdate, "some@email.com", "some-token").
+		Return(nil).Once()
+
+	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name":   "Jane Doe",
+			"email":         "some@email.com",
+			"pending_email": nil,
+		},
+	}).Return(nil).Once()
+
+	clouderySvc.On("SaveInstance", &inst, &cloudery.SaveCmd{
+		Locale:     "fr/FR",
+		Email:      "some@email.com",
+		PublicName: "Jane Doe",
+	}).Return(nil).Once()
+
+	err := svc.ConfirmEmailUpdate(&inst, "some-token")
+	assert.NoError(t, err)
+}
+
+func TestConfirmEmailUpdate_waits_for_the_old_address(t *testing.T) {
+	emailerSvc := emailer.NewMock(t)
+	instSvc := instance.NewMock(t)
+	tokenSvc := token.NewMock(t)
+	clouderySvc := cloudery.NewMock(t)
+	storage := newStorageMock(t)
+
+	svc := NewService(emailerSvc, instSvc, tokenSvc, clouderySvc, storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+	}
+
 	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
 		M: map[string]interface{}{
 			"public_name":   "Jane Doe",
@@ -158,6 +262,48 @@ func TestConfirmEmailUpdate_success(t *testing.T) {
 	tokenSvc.On("Validate", &inst, token.EmailUpdate, "some@email.com", "some-token").
 		Return(nil).Once()
 
+	// The email is not changed, and the cloudery is not called
+	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name":                 "Jane Doe",
+			"email":                       "foo@bar.baz",
+			"pending_email":               "some@email.com",
+			"pending_email_confirmed_new": true,
+		},
+	}).Return(nil).Once()
+
+	err := svc.ConfirmEmailUpdate(&inst, "some-token")
+	assert.NoError(t, err)
+}
+
+func TestConfirmEmailUpdate_from_the_old_address(t *testing.T) {
+	emailerSvc := emailer.NewMock(t)
+	instSvc := instance.NewMock(t)
+	tokenSvc := token.NewMock(t)
+	clouderySvc := cloudery.NewMock(t)
+	storage := newStorageMock(t)
+
+	svc := NewService(emailerSvc, instSvc, tokenSvc, clouderySvc, storage)
+
+	inst := instance.Instance{
+		Domain: "foo.mycozy.cloud",
+		Locale: "fr/FR",
+	}
+
+	storage.On("getInstanceSettings", &inst).Return(&couchdb.JSONDoc{
+		M: map[string]interface{}{
+			"public_name":                 "Jane Doe",
+			"email":                       "foo@bar.baz",
+			"pending_email":               "some@email.com",
+			"pending_email_confirmed_new": true,
+		},
+	}, nil).Once()
+
+	tokenSvc.On("Validate", &inst, token.EmailUpdate, "some@email.com", "old-token").
+		Return(token.ErrInvalidToken).Once()
+	tokenSvc.On("Validate", &inst, token.EmailUpdateOld, "some@email.com", "old-token").
+		Return(nil).Once()
+
 	storage.On("setInstanceSettings", &inst, &couchdb.JSONDoc{
 		M: map[string]interface{}{
 			"public_name":   "Jane Doe",
@@ -172,7 +318,7 @@ func TestConfirmEmailUpdate_success(t *testing.T) {
 		PublicName: "Jane Doe",
 	}).Return(nil).Once()
 
-	err := svc.ConfirmEmailUpdate(&inst, "some-token")
+	err := svc.ConfirmEmailUpdate(&inst, "old-token")
 	assert.NoError(t, err)
 }
 
@@ -199,6 +345,8 @@ func TestConfirmEmailUpdate_with_an_invalid_token(t *testing.T) {
 
 	tokenSvc.On("Validate", &inst, token.EmailUpdate, "some@email.com", "some-invalid-token").
 		Return(token.ErrInvalidToken).Once()
+	tokenSvc.On("Validate", &inst, token.EmailUpdateOld, "some@email.com", "some-invalid-token").
+		Return(token.ErrInvalidToken).Once()
 
 	err := svc.ConfirmEmailUpdate(&inst, "some-invalid-token")
 	assert.ErrorIs(t, err, token.ErrInvalidToken)
@@ -339,7 +487,7 @@ func Test_ResendEmailUpdate_with_no_pending_email(t *testing.T) {
 			"public_name": "Jane Doe",
 			// no pendin_email
 		},
-	}, nil).Twice()
+	}, nil).Once()
 
 	err := svc.ResendEmailUpdate(&inst)
 	assert.ErrorIs(t, err, ErrNoPendingEmail)
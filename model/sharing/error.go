@@ -17,6 +17,9 @@ var (
 	// ErrInvalidSharing is used when an action cannot be made on a sharing,
 	// because this sharing is not the expected state
 	ErrInvalidSharing = errors.New("Sharing is not in the expected state")
+	// ErrInvalidSelfSharing is used when a self-sharing is not between two
+	// instances of the same user
+	ErrInvalidSelfSharing = errors.New("A self-sharing must be between the instances of the same user")
 	// ErrMemberNotFound is used when trying to find a member, but there is no
 	// member with the expected value for the criterion
 	ErrMemberNotFound = errors.New("The member was not found")
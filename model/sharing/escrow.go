@@ -0,0 +1,168 @@
+package sharing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// EscrowDirName is the directory of the escrow owner where the shared folders
+// of the destroyed instances are transferred. Each instance has a
+// sub-directory named after its domain.
+const EscrowDirName = "/Escrow"
+
+// ErrInvalidEscrowOwner is used when the escrow owner configured for the
+// context is not an instance of this context.
+var ErrInvalidEscrowOwner = errors.New("sharing: the escrow owner must be an instance of the same context")
+
+// TransferToEscrow is called before an instance is destroyed, to copy the
+// folders that the user has shared to the escrow owner of the context (see
+// instance.DataEscrowOwner). The sharings of these folders are then revoked,
+// so that the members are told that they will no longer be synchronized
+// (they keep their copy of the files). The transfer stops when the context is
+// canceled.
+func TransferToEscrow(ctx context.Context, inst *instance.Instance) error {
+	domain := inst.DataEscrowOwner()
+	if domain == "" {
+		return nil
+	}
+	owner, err := instance.Get(domain)
+	if err != nil {
+		return err
+	}
+	if owner.ContextName != inst.ContextName {
+		return ErrInvalidEscrowOwner
+	}
+
+	sharings, err := GetSharingsByDocType(inst, consts.Files)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil
+		}
+		return err
+	}
+
+	log := inst.Logger().WithNamespace("escrow")
+	fs := inst.VFS()
+	var root *vfs.DirDoc
+	var errm error
+	for _, s := range sharings {
+		if !s.Owner || !s.Active {
+			continue
+		}
+		rule := s.FirstFilesRule()
+		if rule == nil || rule.Selector != "" {
+			continue
+		}
+		transferred := false
+		for _, id := range rule.Values {
+			if err := ctx.Err(); err != nil {
+				return multierror.Append(errm, err)
+			}
+			dir, err := fs.DirByID(id)
+			if err != nil {
+				if !errors.Is(err, os.ErrNotExist) {
+					errm = multierror.Append(errm, err)
+				}
+				continue
+			}
+			if strings.HasPrefix(dir.Fullpath, vfs.TrashDirName) {
+				continue
+			}
+			if root == nil {
+				root, err = vfs.MkdirAll(owner.VFS(), path.Join(EscrowDirName, inst.Domain))
+				if err != nil {
+					return err
+				}
+			}
+			if err := copyToEscrow(ctx, inst, owner, dir, root); err != nil {
+				errm = multierror.Append(errm, err)
+				continue
+			}
+			log.Infof("Folder %s of sharing %s transferred to %s", dir.ID(), s.SID, owner.Domain)
+			transferred = true
+		}
+		if transferred {
+			if err := s.Revoke(inst); err != nil {
+				log.Warnf("Cannot revoke sharing %s: %s", s.SID, err)
+			}
+		}
+	}
+	return errm
+}
+
+// copyToEscrow copies the directory and its content to the parent directory
+// on the instance of the escrow owner.
+func copyToEscrow(ctx context.Context, inst, owner *instance.Instance, dir, parent *vfs.DirDoc) error {
+	fs := inst.VFS()
+	ofs := owner.VFS()
+
+	name := dir.DocName
+	if exists, err := ofs.GetIndexer().DirChildExists(parent.ID(), name); err != nil {
+		return err
+	} else if exists {
+		name = vfs.ConflictName(ofs, parent.ID(), name, false)
+	}
+	copied := map[string]*vfs.DirDoc{}
+
+	return vfs.WalkByID(fs, dir.ID(), func(_ string, d *vfs.DirDoc, f *vfs.FileDoc, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d != nil {
+			dirParent, docName := copied[d.DirID], d.DocName
+			if d.ID() == dir.ID() {
+				dirParent, docName = parent, name
+			}
+			if dirParent == nil {
+				return os.ErrNotExist
+			}
+			newdir, err := vfs.NewDirDocWithParent(docName, dirParent, d.Tags)
+			if err != nil {
+				return err
+			}
+			newdir.CozyMetadata = vfs.NewCozyMetadata(owner.PageURL("/", nil))
+			if err := ofs.CreateDir(newdir); err != nil {
+				return err
+			}
+			copied[d.ID()] = newdir
+			return nil
+		}
+
+		fileParent := copied[f.DirID]
+		if fileParent == nil {
+			return os.ErrNotExist
+		}
+		newdoc, err := vfs.NewFileDoc(f.DocName, fileParent.ID(), f.ByteSize, f.MD5Sum,
+			f.Mime, f.Class, f.CreatedAt, f.Executable, false, f.Encrypted, f.Tags)
+		if err != nil {
+			return err
+		}
+		newdoc.CozyMetadata = vfs.NewCozyMetadata(owner.PageURL("/", nil))
+		content, err := fs.OpenFile(f)
+		if err != nil {
+			return err
+		}
+		file, err := ofs.CreateFile(newdoc, nil)
+		if err != nil {
+			content.Close()
+			return err
+		}
+		err = copyFileContent(owner, file, content)
+		if cerr := content.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		return err
+	})
+}
@@ -0,0 +1,136 @@
+package sharing
+
+import (
+	"context"
+	"io"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscrow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	ownerSetup := testutils.NewSetup(t, t.Name()+"_owner")
+	owner := ownerSetup.GetTestInstance(&lifecycle.Options{ContextName: "escrow"})
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance(&lifecycle.Options{ContextName: "escrow"})
+
+	conf := config.GetConfig()
+	escrowCfg := map[string]interface{}{"owner": owner.Domain}
+	conf.Contexts["escrow"] = map[string]interface{}{"data_escrow": escrowCfg}
+	t.Cleanup(func() { delete(conf.Contexts, "escrow") })
+
+	setConsent := func(t *testing.T, consent interface{}) {
+		doc, err := inst.SettingsDocument()
+		require.NoError(t, err)
+		if consent == nil {
+			delete(doc.M, "data_escrow_consent")
+		} else {
+			doc.M["data_escrow_consent"] = consent
+		}
+		require.NoError(t, couchdb.UpdateDoc(inst, doc))
+	}
+
+	createSharedDir := func(t *testing.T, name string) (*vfs.DirDoc, *Sharing) {
+		fs := inst.VFS()
+		dir := createTree(t, fs, H{name + "/": H{"sub/": H{}}}, consts.RootDirID)
+		filedoc, err := vfs.NewFileDoc("hello.txt", dir.ID(), 5, nil, "text/plain", "text", time.Now(), false, false, false, nil)
+		require.NoError(t, err)
+		f, err := fs.CreateFile(filedoc, nil)
+		require.NoError(t, err)
+		_, err = f.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		s := &Sharing{
+			Owner:       true,
+			Active:      true,
+			Description: name,
+			Rules: []Rule{{
+				Title:   name,
+				DocType: consts.Files,
+				Values:  []string{dir.ID()},
+			}},
+			Members: []Member{{Status: MemberStatusOwner, Instance: inst.PageURL("", nil)}},
+		}
+		require.NoError(t, couchdb.CreateDoc(inst, s))
+		return dir, s
+	}
+
+	t.Run("DataEscrowOwner", func(t *testing.T) {
+		escrowCfg["consent"] = instance.EscrowConsentOptIn
+		setConsent(t, nil)
+		assert.Equal(t, "", inst.DataEscrowOwner())
+		setConsent(t, false)
+		assert.Equal(t, "", inst.DataEscrowOwner())
+		setConsent(t, true)
+		assert.Equal(t, owner.Domain, inst.DataEscrowOwner())
+
+		escrowCfg["consent"] = instance.EscrowConsentOptOut
+		setConsent(t, nil)
+		assert.Equal(t, owner.Domain, inst.DataEscrowOwner())
+		setConsent(t, true)
+		assert.Equal(t, owner.Domain, inst.DataEscrowOwner())
+		setConsent(t, false)
+		assert.Equal(t, "", inst.DataEscrowOwner())
+
+		// The owner can't be the escrow owner of its own instance
+		assert.Equal(t, "", owner.DataEscrowOwner())
+	})
+
+	t.Run("TransferToEscrow", func(t *testing.T) {
+		escrowCfg["consent"] = instance.EscrowConsentOptIn
+		_, s := createSharedDir(t, "Shared")
+		ofs := owner.VFS()
+
+		// Without the consent of the user, nothing is transferred
+		setConsent(t, nil)
+		require.NoError(t, TransferToEscrow(context.Background(), inst))
+		_, err := ofs.DirByPath(path.Join(EscrowDirName, inst.Domain))
+		assert.Error(t, err)
+
+		setConsent(t, true)
+		require.NoError(t, TransferToEscrow(context.Background(), inst))
+		copied, err := ofs.DirByPath(path.Join(EscrowDirName, inst.Domain, "Shared"))
+		require.NoError(t, err)
+		_, err = ofs.DirByPath(path.Join(copied.Fullpath, "sub"))
+		assert.NoError(t, err)
+		file, err := ofs.FileByPath(path.Join(copied.Fullpath, "hello.txt"))
+		require.NoError(t, err)
+		content, err := ofs.OpenFile(file)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(content)
+		assert.NoError(t, err)
+		assert.NoError(t, content.Close())
+		assert.Equal(t, "hello", string(buf))
+
+		// The sharing has been revoked
+		var revoked Sharing
+		require.NoError(t, couchdb.GetDoc(inst, consts.Sharings, s.SID, &revoked))
+		assert.False(t, revoked.Active)
+
+		// The transfer stops when the context is canceled
+		createSharedDir(t, "Canceled")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = TransferToEscrow(ctx, inst)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = ofs.DirByPath(path.Join(EscrowDirName, inst.Domain, "Canceled"))
+		assert.Error(t, err)
+	})
+}
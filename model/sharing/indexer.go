@@ -344,8 +344,10 @@ func (s *sharingIndexer) UpdateDirDoc(olddoc, doc *vfs.DirDoc) error {
 		return err
 	}
 
-	if err := UpdateFileShared(s.db, s.shared, s.bulkRevs.Revisions); err != nil {
-		return err
+	if s.shared != nil {
+		if err := UpdateFileShared(s.db, s.shared, s.bulkRevs.Revisions); err != nil {
+			return err
+		}
 	}
 
 	if olddoc != nil {
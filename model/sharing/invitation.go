@@ -183,6 +183,7 @@ func (m *Member) SendMail(inst *instance.Instance, s *Sharing, sharer, descripti
 	mailValues := map[string]interface{}{
 		"SharerPublicName": sharer,
 		"SharerEmail":      sharerMail,
+		"SharerAvatarURL":  inst.PageURL("/public/avatar", url.Values{"fallback": {"initials"}}),
 		"Action":           action,
 		"Description":      description,
 		"DocType":          docType,
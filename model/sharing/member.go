@@ -66,6 +66,9 @@ type Member struct {
 	Email      string `json:"email,omitempty"`
 	Instance   string `json:"instance,omitempty"`
 	ReadOnly   bool   `json:"read_only,omitempty"`
+	// QuotaExceeded is set when the member has informed us that it can't
+	// receive the files of the sharing because of its disk quota
+	QuotaExceeded bool `json:"quota_exceeded,omitempty"`
 }
 
 // PrimaryName returns the main name of this member
@@ -95,6 +98,10 @@ type Credentials struct {
 	// InboundClientID is the OAuth ClientID used for authentifying incoming
 	// requests from the member
 	InboundClientID string `json:"inbound_client_id,omitempty"`
+
+	// Peer is what we know of the stack of the member (protocol version and
+	// capabilities), from the messages exchanged with it
+	Peer *PeerInfo `json:"peer,omitempty"`
 }
 
 // AddContacts adds a list of contacts on the sharer cozy
@@ -880,6 +887,15 @@ func (s *Sharing) RevokeMember(inst *instance.Instance, index int) error {
 	m := &s.Members[index]
 	c := &s.Credentials[index-1]
 
+	// A member that has not synchronized the sharing can still have a
+	// shortcut to it, that must be removed
+	if m.Status == MemberStatusPendingInvitation || m.Status == MemberStatusSeen {
+		if err := s.NotifyShortcutRevocation(inst, m); err != nil {
+			inst.Logger().WithNamespace("sharing").
+				Debugf("Error on shortcut revocation notification: %s", err)
+		}
+	}
+
 	// No need to contact the revoked member if the sharing is not ready
 	if m.Status == MemberStatusReady {
 		if err := s.NotifyMemberRevocation(inst, m, c); err != nil {
@@ -1022,8 +1038,10 @@ func (s *Sharing) NotifyRecipients(inst *instance.Instance, except *Member) {
 	}
 
 	var members struct {
-		Members []Member `json:"data"`
+		Members []Member        `json:"data"`
+		Meta    *RecipientsMeta `json:"meta,omitempty"`
 	}
+	members.Meta = &RecipientsMeta{Description: s.Description}
 	members.Members = make([]Member, len(s.Members))
 	for i, m := range s.Members {
 		members.Members[i] = Member{
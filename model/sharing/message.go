@@ -0,0 +1,291 @@
+package sharing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cozy/cozy-stack/client/request"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/labstack/echo/v4"
+)
+
+// ProtocolVersion is the version of the sharing protocol implemented by this
+// stack. The stacks that don't know the messages endpoint are considered to
+// speak the version 1.
+const ProtocolVersion = 2
+
+const legacyProtocolVersion = 1
+
+// The types of messages that can be exchanged between the stacks of the
+// members of a sharing.
+const (
+	// MessageHello is used to negotiate the protocol version and exchange the
+	// capabilities. It is also the type of the responses.
+	MessageHello = "hello"
+	// MessageMemberRenamed is sent when the public name of a member changes.
+	MessageMemberRenamed = "member_renamed"
+	// MessageQuotaExceeded is sent when a member can't receive the files of
+	// the sharing because its disk quota has been exceeded.
+	MessageQuotaExceeded = "quota_exceeded"
+)
+
+// Capabilities is the list of the features of the sharing protocol that this
+// stack supports. The types of messages that it can handle are included.
+var Capabilities = []string{
+	MessageHello,
+	MessageMemberRenamed,
+	MessageQuotaExceeded,
+}
+
+// ErrUnsupportedMessage is used when a message can't be sent, as the other
+// stack doesn't support it.
+var ErrUnsupportedMessage = errors.New("The message is not supported by the other Cozy")
+
+// Message is a message sent from the stack of a member of the sharing to the
+// stack of another member, with the sharing credentials. Every message
+// includes the protocol version and the capabilities of the sender, so that
+// the negotiation is done on each exchange.
+type Message struct {
+	Type            string                 `json:"type"`
+	ProtocolVersion int                    `json:"protocol_version"`
+	Capabilities    []string               `json:"capabilities"`
+	Data            map[string]interface{} `json:"data,omitempty"`
+}
+
+// NewMessage returns a message of the given type from this stack.
+func NewMessage(typ string, data map[string]interface{}) *Message {
+	return &Message{
+		Type:            typ,
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    Capabilities,
+		Data:            data,
+	}
+}
+
+// PeerInfo is what a stack knows of the stack of another member of the
+// sharing, from the last exchanged message.
+type PeerInfo struct {
+	ProtocolVersion int       `json:"protocol_version"`
+	Capabilities    []string  `json:"capabilities,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Supports returns true if the other stack has the given capability. When
+// nothing is known about the other stack, we assume that it may support it.
+func (p *PeerInfo) Supports(capability string) bool {
+	if p == nil {
+		return true
+	}
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Version returns the protocol version to use with the other stack: the
+// lowest version between the two stacks.
+func (p *PeerInfo) Version() int {
+	if p == nil || p.ProtocolVersion == 0 {
+		return legacyProtocolVersion
+	}
+	if p.ProtocolVersion < ProtocolVersion {
+		return p.ProtocolVersion
+	}
+	return ProtocolVersion
+}
+
+// SendMessage sends a message to the stack of the given member. The response
+// of the other stack is used to update what we know of it (protocol version
+// and capabilities).
+func (s *Sharing) SendMessage(inst *instance.Instance, m *Member, msg *Message) error {
+	creds := s.FindCredentials(m)
+	if creds == nil {
+		return ErrInvalidSharing
+	}
+	if creds.AccessToken == nil {
+		return ErrNoOAuthClient
+	}
+	if msg.Type != MessageHello && !creds.Peer.Supports(msg.Type) {
+		return ErrUnsupportedMessage
+	}
+	u, err := url.Parse(m.Instance)
+	if m.Instance == "" || err != nil {
+		return ErrInvalidSharing
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	opts := &request.Options{
+		Method: http.MethodPost,
+		Scheme: u.Scheme,
+		Domain: u.Host,
+		Path:   "/sharings/" + s.SID + "/messages",
+		Headers: request.Headers{
+			echo.HeaderAccept:        echo.MIMEApplicationJSON,
+			echo.HeaderContentType:   echo.MIMEApplicationJSON,
+			echo.HeaderAuthorization: "Bearer " + creds.AccessToken.AccessToken,
+		},
+		Body:       bytes.NewReader(body),
+		ParseError: ParseRequestError,
+	}
+	res, err := request.Req(opts)
+	if res != nil && res.StatusCode/100 == 4 && res.StatusCode != http.StatusNotFound {
+		res, err = RefreshToken(inst, err, s, m, creds, opts, body)
+	}
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		// The other stack doesn't know the messages endpoint
+		creds.Peer = &PeerInfo{
+			ProtocolVersion: legacyProtocolVersion,
+			UpdatedAt:       time.Now(),
+		}
+		_ = couchdb.UpdateDoc(inst, s)
+		return ErrUnsupportedMessage
+	}
+	if err != nil {
+		if res != nil {
+			return ErrRequestFailed
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	var reply Message
+	if err := json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return ErrRequestFailed
+	}
+	creds.Peer = &PeerInfo{
+		ProtocolVersion: reply.ProtocolVersion,
+		Capabilities:    reply.Capabilities,
+		UpdatedAt:       time.Now(),
+	}
+	return couchdb.UpdateDoc(inst, s)
+}
+
+// ReceiveMessage handles a message sent by the stack of the given member, and
+// returns the reply. The unknown types of messages are ignored, so that the
+// new types can be introduced without breaking the older stacks.
+func (s *Sharing) ReceiveMessage(inst *instance.Instance, m *Member, msg *Message) (*Message, error) {
+	creds := s.FindCredentials(m)
+	if creds == nil {
+		return nil, ErrMemberNotFound
+	}
+	creds.Peer = &PeerInfo{
+		ProtocolVersion: msg.ProtocolVersion,
+		Capabilities:    msg.Capabilities,
+		UpdatedAt:       time.Now(),
+	}
+	notify := false
+	switch msg.Type {
+	case MessageHello:
+		m.QuotaExceeded = false
+	case MessageMemberRenamed:
+		name, _ := msg.Data["public_name"].(string)
+		if name != "" && name != m.PublicName {
+			m.PublicName = name
+			notify = true
+		}
+	case MessageQuotaExceeded:
+		m.QuotaExceeded = true
+	default:
+		inst.Logger().WithNamespace("sharing").
+			Infof("Unknown message %q for sharing %s", msg.Type, s.SID)
+	}
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return nil, err
+	}
+	if notify && s.Owner {
+		cloned := s.Clone().(*Sharing)
+		for i := range s.Members {
+			if &s.Members[i] == m {
+				go cloned.NotifyRecipients(inst, &cloned.Members[i])
+			}
+		}
+	}
+	return NewMessage(MessageHello, nil), nil
+}
+
+// BroadcastMemberRenamed informs the other members of the active sharings
+// that the public name of the instance owner has changed. For the sharings
+// where the instance is a recipient, the sharer will forward the new name to
+// the other recipients.
+func BroadcastMemberRenamed(inst *instance.Instance, publicName string) {
+	data := map[string]interface{}{"public_name": publicName}
+	err := forEachActiveSharing(inst, func(s *Sharing) {
+		if !s.Owner {
+			s.sendMessageAndLog(inst, &s.Members[0], NewMessage(MessageMemberRenamed, data))
+			return
+		}
+		s.Members[0].PublicName = publicName
+		if err := couchdb.UpdateDoc(inst, s); err != nil {
+			inst.Logger().WithNamespace("sharing").
+				Warnf("Cannot save the new public name for sharing %s: %s", s.SID, err)
+			return
+		}
+		for i := range s.Members {
+			if i > 0 && s.Members[i].Status == MemberStatusReady {
+				s.sendMessageAndLog(inst, &s.Members[i], NewMessage(MessageMemberRenamed, data))
+			}
+		}
+	})
+	if err != nil {
+		inst.Logger().WithNamespace("sharing").
+			Warnf("Cannot broadcast the new public name: %s", err)
+	}
+}
+
+// NotifyQuotaExceeded informs the sharer that the files of the sharing can't
+// be received because the disk quota of this instance has been exceeded. The
+// message is sent at most once per day for each sharing.
+func (s *Sharing) NotifyQuotaExceeded(inst *instance.Instance) {
+	if s.Owner || len(s.Members) == 0 || len(s.Credentials) == 0 {
+		return
+	}
+	key := inst.Domain + "/" + s.SID
+	if err := config.GetRateLimiter().CheckRateLimitKey(key, limits.SharingQuotaMessageType); err != nil {
+		return
+	}
+	s.sendMessageAndLog(inst, &s.Members[0], NewMessage(MessageQuotaExceeded, nil))
+}
+
+func (s *Sharing) sendMessageAndLog(inst *instance.Instance, m *Member, msg *Message) {
+	err := s.SendMessage(inst, m, msg)
+	if err != nil && !errors.Is(err, ErrUnsupportedMessage) {
+		inst.Logger().WithNamespace("sharing").
+			Infof("Cannot send the %s message for sharing %s: %s", msg.Type, s.SID, err)
+	}
+}
+
+func forEachActiveSharing(inst *instance.Instance, fn func(s *Sharing)) error {
+	var sharings []*Sharing
+	err := couchdb.ForeachDocs(inst, consts.Sharings, func(_ string, data json.RawMessage) error {
+		s := &Sharing{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return err
+		}
+		if s.Active && len(s.Credentials) > 0 {
+			sharings = append(sharings, s)
+		}
+		return nil
+	})
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil
+		}
+		return err
+	}
+	for _, s := range sharings {
+		fn(s)
+	}
+	return nil
+}
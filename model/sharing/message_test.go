@@ -0,0 +1,22 @@
+package sharing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerInfo(t *testing.T) {
+	var unknown *PeerInfo
+	assert.True(t, unknown.Supports(MessageQuotaExceeded))
+	assert.Equal(t, 1, unknown.Version())
+
+	legacy := &PeerInfo{ProtocolVersion: 1}
+	assert.False(t, legacy.Supports(MessageMemberRenamed))
+	assert.Equal(t, 1, legacy.Version())
+
+	newer := &PeerInfo{ProtocolVersion: ProtocolVersion + 1, Capabilities: []string{MessageHello, MessageMemberRenamed}}
+	assert.True(t, newer.Supports(MessageMemberRenamed))
+	assert.False(t, newer.Supports(MessageQuotaExceeded))
+	assert.Equal(t, ProtocolVersion, newer.Version())
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/cozy/cozy-stack/client/auth"
 	"github.com/cozy/cozy-stack/client/request"
+	"github.com/cozy/cozy-stack/model/activity"
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/contact"
 	"github.com/cozy/cozy-stack/model/instance"
@@ -444,7 +445,17 @@ func (s *Sharing) SendAnswer(inst *instance.Instance, state string) error {
 	s.Credentials[0].Client = creds.Client
 	s.Active = true
 	s.Initial = s.NbFiles > 0
-	return couchdb.UpdateDoc(inst, s)
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		return err
+	}
+	activity.Record(inst, &activity.Activity{
+		Kind:      activity.KindSharingAccepted,
+		Doctype:   consts.Sharings,
+		RelatedID: s.SID,
+		Label:     s.Description,
+		Member:    s.Members[0].PrimaryName(),
+	})
+	return nil
 }
 
 // ProcessAnswer takes somes credentials and update the sharing with those.
@@ -518,6 +529,13 @@ func (s *Sharing) ProcessAnswer(inst *instance.Instance, creds *APICredentials)
 					return nil, err
 				}
 			}
+			activity.Record(inst, &activity.Activity{
+				Kind:      activity.KindSharingAccepted,
+				Doctype:   consts.Sharings,
+				RelatedID: s.SID,
+				Label:     s.Description,
+				Member:    s.Members[i+1].PrimaryName(),
+			})
 			go s.Setup(inst, &s.Members[i+1])
 			return &ac, nil
 		}
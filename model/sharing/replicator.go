@@ -674,7 +674,7 @@ func (s *Sharing) ApplyBulkDocs(inst *instance.Instance, payload DocsByDoctype)
 			}
 			continue
 		}
-		var okDocs, docsToUpdate DocsList
+		var okDocs, docsToUpdate, tombstones DocsList
 		var newRefs, existingRefs []*SharedRef
 		newDocs, existingDocs, err := partitionDocsPayload(inst, doctype, docs)
 		if err == nil {
@@ -683,6 +683,10 @@ func (s *Sharing) ApplyBulkDocs(inst *instance.Instance, payload DocsByDoctype)
 			if err != nil {
 				return err
 			}
+			docsToUpdate, tombstones, err = s.resolveConflicts(inst, doctype, docsToUpdate)
+			if err != nil {
+				return err
+			}
 			okDocs = append(okDocs, docsToUpdate...)
 		} else {
 			okDocs, newRefs = s.filterDocsToAdd(inst, doctype, docs)
@@ -696,6 +700,9 @@ func (s *Sharing) ApplyBulkDocs(inst *instance.Instance, payload DocsByDoctype)
 			if err = couchdb.BulkForceUpdateDocs(inst, doctype, okDocs); err != nil {
 				return err
 			}
+			if err = couchdb.BulkForceUpdateDocs(inst, doctype, tombstones); err != nil {
+				return err
+			}
 			for _, doc := range okDocs {
 				d := couchdb.JSONDoc{M: doc, Type: doctype}
 				event := realtime.EventUpdate
@@ -34,6 +34,10 @@ type Rule struct {
 	Add      string   `json:"add"`
 	Update   string   `json:"update"`
 	Remove   string   `json:"remove"`
+	// Conflict is the rule used when a document has been modified on both
+	// sides (ConflictRuleLastWrite or ConflictRuleOwner). When it is empty,
+	// CouchDB picks the winning revision.
+	Conflict string `json:"conflict,omitempty"`
 }
 
 // FilesByID returns true if the rule is for the files by doctype and the
@@ -56,6 +60,20 @@ func (s *Sharing) ValidateRules() error {
 		if rule.Title == "" || len(rule.Values) == 0 {
 			return ErrInvalidRule
 		}
+		if s.SelfSharing {
+			if !IsSelfShareable(rule.DocType) {
+				return ErrInvalidRule
+			}
+			if rule.Conflict == "" {
+				s.Rules[i].Conflict = ConflictRuleLastWrite
+				rule.Conflict = s.Rules[i].Conflict
+			}
+		}
+		if rule.Conflict != "" &&
+			rule.Conflict != ConflictRuleLastWrite &&
+			rule.Conflict != ConflictRuleOwner {
+			return ErrInvalidRule
+		}
 		if permission.CheckDoctypeName(rule.DocType, false) != nil {
 			return ErrInvalidRule
 		}
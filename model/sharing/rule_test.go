@@ -192,3 +192,42 @@ func TestTriggersArgs(t *testing.T) {
 	r.Local = true
 	assert.Equal(t, "", r.TriggerArgs())
 }
+
+func TestValidatesSelfSharingRules(t *testing.T) {
+	s := Sharing{SelfSharing: true}
+	s.Rules = []Rule{
+		{
+			Title:   "not a settings doctype",
+			DocType: "io.cozy.tests",
+			Values:  []string{"foo"},
+		},
+	}
+	assert.Equal(t, ErrInvalidRule, s.ValidateRules())
+	s.Rules = []Rule{
+		{
+			Title:   "io.cozy.settings is specific to an instance",
+			DocType: consts.Settings,
+			Values:  []string{consts.InstanceSettingsID},
+		},
+	}
+	assert.Equal(t, ErrInvalidRule, s.ValidateRules())
+	s.Rules = []Rule{
+		{
+			Title:    "conflict is invalid",
+			DocType:  "io.cozy.home.settings",
+			Values:   []string{"layout"},
+			Conflict: "flip",
+		},
+	}
+	assert.Equal(t, ErrInvalidRule, s.ValidateRules())
+	s.Rules = []Rule{
+		{
+			Title:   "home layout",
+			DocType: "io.cozy.home.settings",
+			Values:  []string{"layout"},
+			Update:  "sync",
+		},
+	}
+	assert.NoError(t, s.ValidateRules())
+	assert.Equal(t, ConflictRuleLastWrite, s.Rules[0].Conflict)
+}
@@ -0,0 +1,181 @@
+package sharing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/revision"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+)
+
+const (
+	// ConflictRuleLastWrite is used when the most recently updated version of
+	// a document must win on a conflict (it is the default for self-sharings)
+	ConflictRuleLastWrite = "last_write"
+	// ConflictRuleOwner is used when the version of the owner must win on a
+	// conflict
+	ConflictRuleOwner = "owner"
+)
+
+// selfShareableSuffix is the suffix of the doctypes that can be shared
+// between the instances of a user: the settings of the applications, like
+// io.cozy.home.settings.
+const selfShareableSuffix = ".settings"
+
+// IsSelfShareable returns true if the documents of the given doctype can be
+// synchronized between the instances of a user with a self-sharing.
+func IsSelfShareable(doctype string) bool {
+	// The io.cozy.settings doctype has the email, the keys for bitwarden,
+	// etc. which are specific to an instance.
+	if doctype == consts.Settings {
+		return false
+	}
+	return strings.HasSuffix(doctype, selfShareableSuffix)
+}
+
+// checkSelfSharingMembers returns an error if the sharing is a self-sharing,
+// but the recipient is not the owner on another instance.
+func (s *Sharing) checkSelfSharingMembers() error {
+	if !s.SelfSharing {
+		return nil
+	}
+	if len(s.Members) != 2 {
+		return ErrInvalidSelfSharing
+	}
+	owner, recipient := s.Members[0], s.Members[1]
+	if owner.Email == "" || !strings.EqualFold(owner.Email, recipient.Email) {
+		return ErrInvalidSelfSharing
+	}
+	if recipient.ReadOnly {
+		return ErrInvalidSelfSharing
+	}
+	return nil
+}
+
+// checkSelfSharingRecipient returns an error if the sharing is a self-sharing
+// that has been sent by someone else.
+func (s *Sharing) checkSelfSharingRecipient(inst *instance.Instance) error {
+	if !s.SelfSharing {
+		return nil
+	}
+	email, err := inst.SettingsEMail()
+	if err != nil {
+		return err
+	}
+	if email == "" || !strings.EqualFold(email, s.Members[0].Email) {
+		return ErrInvalidSelfSharing
+	}
+	return nil
+}
+
+// resolveConflicts applies the conflict rules to the documents sent by the
+// other instance that are already present on this instance. It returns the
+// documents to write, and the tombstones for the local revisions that have
+// lost.
+func (s *Sharing) resolveConflicts(inst *instance.Instance, doctype string, docs DocsList) (DocsList, DocsList, error) {
+	if len(docs) == 0 {
+		return docs, nil, nil
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i], _ = doc["_id"].(string)
+	}
+	locals := make([]map[string]interface{}, 0, len(docs))
+	req := couchdb.AllDocsRequest{Keys: ids}
+	if err := couchdb.GetAllDocs(inst, doctype, &req, &locals); err != nil {
+		return nil, nil, err
+	}
+
+	kept := make(DocsList, 0, len(docs))
+	var tombstones DocsList
+	for i, doc := range docs {
+		var local map[string]interface{}
+		if i < len(locals) {
+			local = locals[i]
+		}
+		rule := s.conflictRule(doctype, doc, local)
+		localRev, _ := local["_rev"].(string)
+		if rule == "" || localRev == "" || !isConflict(doc, localRev) {
+			kept = append(kept, doc)
+			continue
+		}
+		if !s.incomingWins(rule, doc, local) {
+			inst.Logger().WithNamespace("replicator").
+				Debugf("Conflict on %s/%s: the local version is kept", doctype, ids[i])
+			continue
+		}
+		kept = append(kept, doc)
+		if _, ok := doc["_deleted"]; !ok {
+			tombstones = append(tombstones, tombstoneFor(ids[i], localRev))
+		}
+	}
+	return kept, tombstones, nil
+}
+
+// conflictRule returns the conflict rule that applies to the document.
+func (s *Sharing) conflictRule(doctype string, doc, local map[string]interface{}) string {
+	for _, rule := range s.Rules {
+		if rule.Accept(doctype, doc) || (local != nil && rule.Accept(doctype, local)) {
+			return rule.Conflict
+		}
+	}
+	return ""
+}
+
+// isConflict returns true if the local revision is not an ancestor of the
+// revision of the document sent by the other instance.
+func isConflict(doc map[string]interface{}, localRev string) bool {
+	revs := revsMapToStruct(doc["_revisions"])
+	if revs == nil {
+		return false
+	}
+	for _, rev := range revsStructToChain(*revs) {
+		if rev == localRev {
+			return false
+		}
+	}
+	return true
+}
+
+// incomingWins returns true if the version sent by the other instance must
+// win against the local version.
+func (s *Sharing) incomingWins(rule string, doc, local map[string]interface{}) bool {
+	if rule == ConflictRuleOwner {
+		// The documents sent to the owner come from the recipient
+		return !s.Owner
+	}
+	return !updatedAt(doc).Before(updatedAt(local))
+}
+
+func updatedAt(doc map[string]interface{}) time.Time {
+	meta, _ := doc["cozyMetadata"].(map[string]interface{})
+	at, _ := meta["updatedAt"].(string)
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// tombstoneFor returns a tombstone for the given revision, to be inserted
+// with new_edits=false. It deletes the branch of the local revision, so that
+// CouchDB picks the other branch as the winner.
+func tombstoneFor(id, rev string) map[string]interface{} {
+	gen := revision.Generation(rev)
+	parts := strings.SplitN(rev, "-", 2)
+	hash := hex.EncodeToString(crypto.GenerateRandomBytes(16))
+	return map[string]interface{}{
+		"_id":      id,
+		"_rev":     fmt.Sprintf("%d-%s", gen+1, hash),
+		"_deleted": true,
+		"_revisions": map[string]interface{}{
+			"start": gen + 1,
+			"ids":   []interface{}{hash, parts[len(parts)-1]},
+		},
+	}
+}
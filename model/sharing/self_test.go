@@ -0,0 +1,61 @@
+package sharing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSelfSharingMembers(t *testing.T) {
+	s := Sharing{SelfSharing: true}
+	s.Members = []Member{
+		{Status: MemberStatusOwner, Email: "alice@example.net"},
+		{Status: MemberStatusMailNotSent, Email: "bob@example.net"},
+	}
+	assert.Equal(t, ErrInvalidSelfSharing, s.checkSelfSharingMembers())
+
+	s.Members[1].Email = "Alice@example.net"
+	assert.NoError(t, s.checkSelfSharingMembers())
+
+	s.Members[1].ReadOnly = true
+	assert.Equal(t, ErrInvalidSelfSharing, s.checkSelfSharingMembers())
+}
+
+func TestResolveConflictRules(t *testing.T) {
+	doc := map[string]interface{}{
+		"_id":  "layout",
+		"_rev": "3-ccc",
+		"_revisions": map[string]interface{}{
+			"start": float64(3),
+			"ids":   []interface{}{"ccc", "bbb", "aaa"},
+		},
+		"cozyMetadata": map[string]interface{}{
+			"updatedAt": "2023-03-02T10:00:00Z",
+		},
+	}
+	assert.False(t, isConflict(doc, "2-bbb"))
+	assert.True(t, isConflict(doc, "2-ddd"))
+
+	local := map[string]interface{}{
+		"_id":  "layout",
+		"_rev": "2-ddd",
+		"cozyMetadata": map[string]interface{}{
+			"updatedAt": "2023-03-02T11:00:00Z",
+		},
+	}
+	s := Sharing{Owner: true}
+	assert.False(t, s.incomingWins(ConflictRuleLastWrite, doc, local))
+	assert.True(t, s.incomingWins(ConflictRuleLastWrite, local, doc))
+	assert.False(t, s.incomingWins(ConflictRuleOwner, doc, local))
+	s.Owner = false
+	assert.True(t, s.incomingWins(ConflictRuleOwner, doc, local))
+
+	tombstone := tombstoneFor("layout", "2-ddd")
+	assert.Equal(t, true, tombstone["_deleted"])
+	assert.Contains(t, tombstone["_rev"], "3-")
+	revs := revsMapToStruct(map[string]interface{}{
+		"start": float64(3),
+		"ids":   tombstone["_revisions"].(map[string]interface{})["ids"],
+	})
+	assert.Equal(t, "2-ddd", revsStructToChain(*revs)[0])
+}
@@ -119,6 +119,8 @@ func (s *Sharing) Setup(inst *instance.Instance, m *Member) {
 		inst.Logger().WithNamespace("sharing").
 			Warnf("Error on setup replicate trigger (%s): %s", s.SID, err)
 	}
+	// Negotiate the protocol version with the stack of the new member
+	s.sendMessageAndLog(inst, m, NewMessage(MessageHello, nil))
 	if err := s.InitialReplication(inst, m); err != nil {
 		inst.Logger().WithNamespace("sharing").
 			Warnf("Error on initial replication (%s): %s", s.SID, err)
@@ -64,6 +64,9 @@ type Sharing struct {
 	Initial     bool      `json:"initial_sync,omitempty"`
 	ShortcutID  string    `json:"shortcut_id,omitempty"`
 	MovedFrom   string    `json:"moved_from,omitempty"`
+	// SelfSharing is true for a sharing of the settings of the apps between
+	// the instances of the same user (personal and family for example).
+	SelfSharing bool `json:"self_sharing,omitempty"`
 
 	Rules []Rule `json:"rules"`
 
@@ -112,6 +115,10 @@ func (s *Sharing) Clone() couchdb.Doc {
 		}
 		cloned.Credentials[i].XorKey = make([]byte, len(s.Credentials[i].XorKey))
 		copy(cloned.Credentials[i].XorKey, s.Credentials[i].XorKey)
+		if s.Credentials[i].Peer != nil {
+			peer := *s.Credentials[i].Peer
+			cloned.Credentials[i].Peer = &peer
+		}
 	}
 	return &cloned
 }
@@ -372,6 +379,9 @@ func (s *Sharing) Create(inst *instance.Instance) (*permission.Permission, error
 	if len(s.Members) < 2 {
 		return nil, ErrNoRecipients
 	}
+	if err := s.checkSelfSharingMembers(); err != nil {
+		return nil, err
+	}
 
 	if err := couchdb.CreateDoc(inst, s); err != nil {
 		return nil, err
@@ -398,6 +408,9 @@ func (s *Sharing) CreateRequest(inst *instance.Instance) error {
 	if len(s.Members) < 2 {
 		return ErrNoRecipients
 	}
+	if err := s.checkSelfSharingRecipient(inst); err != nil {
+		return err
+	}
 
 	s.Active = false
 	s.Owner = false
@@ -535,6 +548,10 @@ func (s *Sharing) RevokeRecipientBySelf(inst *instance.Instance, sharingDirTrash
 			return err
 		}
 	}
+	if err := s.RemoveShortcut(inst); err != nil {
+		inst.Logger().WithNamespace("sharing").
+			Warnf("RevokeRecipientBySelf failed to remove shortcut %s: %s", s.ID(), err)
+	}
 	s.Active = false
 
 	for i, m := range s.Members {
@@ -619,6 +636,9 @@ func (s *Sharing) RevokeByNotification(inst *instance.Instance) error {
 			return err
 		}
 	}
+	if err := s.RemoveShortcut(inst); err != nil {
+		return err
+	}
 
 	var err error
 	for i := 0; i < 3; i++ {
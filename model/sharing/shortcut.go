@@ -0,0 +1,171 @@
+package sharing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cozy/cozy-stack/client/request"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	vfs.RenameSharingFunc = renameSharing
+}
+
+// RecipientsMeta is sent with the list of members when the owner notifies the
+// recipients of a change in the sharing. It is used by the recipients to keep
+// their shortcut to the sharing in sync.
+type RecipientsMeta struct {
+	Description string `json:"description,omitempty"`
+	Revoked     bool   `json:"revoked,omitempty"`
+}
+
+// renameSharing is called when the main file/dir of a sharing is renamed on
+// the owner's instance: the description of the sharing is updated, and the
+// recipients are notified, so that they can rename their shortcut.
+func renameSharing(db prefixer.Prefixer, sharingID, name string) {
+	s, err := FindSharing(db, sharingID)
+	if err != nil || !s.Owner || !s.Active || s.Description == name {
+		return
+	}
+	inst, err := instance.Get(db.DomainName())
+	if err != nil {
+		return
+	}
+	s.Description = name
+	if err := couchdb.UpdateDoc(inst, s); err != nil {
+		inst.Logger().WithNamespace("sharing").
+			Warnf("Cannot update the description of %s: %s", s.SID, err)
+		return
+	}
+	go s.NotifyRecipients(inst, nil)
+}
+
+// UpdateDescription is called on a recipient when the owner has renamed the
+// shared file or directory. The shortcut to the sharing is renamed too,
+// except if the user has given it another name.
+func (s *Sharing) UpdateDescription(inst *instance.Instance, description string) error {
+	if s.Owner || description == "" || description == s.Description {
+		return nil
+	}
+	if s.ShortcutID != "" {
+		if err := s.renameShortcut(inst, description); err != nil {
+			inst.Logger().WithNamespace("sharing").
+				Infof("Cannot rename the shortcut %s: %s", s.ShortcutID, err)
+		}
+	}
+	s.Description = description
+	return couchdb.UpdateDoc(inst, s)
+}
+
+func (s *Sharing) renameShortcut(inst *instance.Instance, description string) error {
+	fs := inst.VFS()
+	file, err := fs.FileByID(s.ShortcutID)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			s.ShortcutID = ""
+			return nil
+		}
+		return err
+	}
+	if file.Trashed || !hasShortcutName(file.DocName, s.Description) {
+		return nil
+	}
+
+	name := description + ".url"
+	for i := 2; i < 100; i++ {
+		_, err = vfs.ModifyFileMetadata(fs, file, &vfs.DocPatch{Name: &name})
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		name = fmt.Sprintf("%s (%d).url", description, i)
+	}
+	return err
+}
+
+// hasShortcutName returns true if the name is the one given to the shortcut
+// when it was created for a sharing with the given description (with an
+// optional suffix for conflicts).
+func hasShortcutName(name, description string) bool {
+	if !strings.HasPrefix(name, description) || !strings.HasSuffix(name, ".url") {
+		return false
+	}
+	suffix := strings.TrimSuffix(strings.TrimPrefix(name, description), ".url")
+	if suffix == "" {
+		return true
+	}
+	var n int
+	_, err := fmt.Sscanf(suffix, " (%d)", &n)
+	return err == nil && suffix == fmt.Sprintf(" (%d)", n)
+}
+
+// RemoveShortcut is called on a recipient when the owner has revoked the
+// sharing before it has been synchronized: the shortcut is removed.
+func (s *Sharing) RemoveShortcut(inst *instance.Instance) error {
+	if s.Owner {
+		return ErrInvalidSharing
+	}
+	if s.ShortcutID == "" {
+		return nil
+	}
+	if parentID := s.cleanShortcutID(inst); parentID == "" && s.ShortcutID != "" {
+		return ErrInternalServerError
+	}
+	return nil
+}
+
+// NotifyShortcutRevocation is called on the owner's instance to inform a
+// member that has not synchronized the sharing that they have been revoked,
+// so that their shortcut can be removed.
+func (s *Sharing) NotifyShortcutRevocation(inst *instance.Instance, m *Member) error {
+	u, err := url.Parse(m.Instance)
+	if m.Instance == "" || err != nil {
+		return ErrInvalidURL
+	}
+	perms, err := permission.GetForSharePreview(inst, s.SID)
+	if err != nil {
+		return err
+	}
+	token := perms.Codes[m.Email]
+	if token == "" {
+		return ErrInvalidSharing
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"data": []Member{},
+		"meta": RecipientsMeta{Revoked: true},
+	})
+	if err != nil {
+		return err
+	}
+	res, err := request.Req(&request.Options{
+		Method: http.MethodPut,
+		Scheme: u.Scheme,
+		Domain: u.Host,
+		Path:   "/sharings/" + s.SID + "/recipients",
+		Headers: request.Headers{
+			echo.HeaderAccept:        jsonapi.ContentType,
+			echo.HeaderContentType:   jsonapi.ContentType,
+			echo.HeaderAuthorization: "Bearer " + token,
+		},
+		Body:       bytes.NewReader(body),
+		ParseError: ParseRequestError,
+	})
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
@@ -0,0 +1,18 @@
+package sharing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasShortcutName(t *testing.T) {
+	assert.True(t, hasShortcutName("Photos.url", "Photos"))
+	assert.True(t, hasShortcutName("Photos (2).url", "Photos"))
+	assert.True(t, hasShortcutName("Photos (12).url", "Photos"))
+	assert.False(t, hasShortcutName("Photos", "Photos"))
+	assert.False(t, hasShortcutName("My photos.url", "Photos"))
+	assert.False(t, hasShortcutName("Photos of 2023.url", "Photos"))
+	assert.False(t, hasShortcutName("Photos (x).url", "Photos"))
+	assert.False(t, hasShortcutName("Photos (2) (3).url", "Photos"))
+}
@@ -0,0 +1,154 @@
+package sharing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+)
+
+// The functions of this file are used by the warm standby replication: the
+// files and directories of an instance are copied from a primary stack to a
+// secondary stack, with their identifiers and revisions. It is the same
+// machinery as for a sharing, but without rules and conflicts, as the
+// secondary is just a mirror of the primary.
+
+// ApplyStandbyDir creates or updates a directory on the secondary stack with
+// the document received from the primary.
+func ApplyStandbyDir(inst *instance.Instance, target map[string]interface{}) error {
+	id, _ := target["_id"].(string)
+	if id == consts.RootDirID {
+		// The root directory is created when the VFS is initialized
+		return nil
+	}
+	_, indexer, err := extractNameAndIndexer(inst, target, nil)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var dir vfs.DirDoc
+	if err := json.Unmarshal(buf, &dir); err != nil {
+		return err
+	}
+
+	mu := config.Lock().ReadWrite(inst, "shared/"+consts.Files+"/"+id)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	fs := inst.VFS().UseSharingIndexer(indexer)
+	current, err := fs.DirByID(id)
+	if errors.Is(err, os.ErrNotExist) {
+		dir.DocRev = ""
+		return fs.CreateDir(&dir)
+	}
+	if err != nil {
+		return err
+	}
+	dir.DocRev = current.DocRev
+	return fs.UpdateDirDoc(current, &dir)
+}
+
+// SyncStandbyFile updates the metadata of a file on the secondary stack. It
+// returns true if the content of the file must be uploaded, because the file
+// is new or its content has changed.
+func SyncStandbyFile(inst *instance.Instance, target *FileDocWithRevisions) (bool, error) {
+	sid := consts.Files + "/" + target.DocID
+	mu := config.Lock().ReadWrite(inst, "shared/"+sid)
+	if err := mu.Lock(); err != nil {
+		return false, err
+	}
+	defer mu.Unlock()
+
+	current, err := inst.VFS().FileByID(target.DocID)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(target.MD5Sum, current.MD5Sum) {
+		return true, nil
+	}
+	if current.DocRev == target.DocRev {
+		return false, nil
+	}
+
+	indexer := newSharingIndexer(inst, &bulkRevs{
+		Rev:       target.DocRev,
+		Revisions: target.Revisions,
+	}, nil)
+	fs := inst.VFS().UseSharingIndexer(indexer)
+	newdoc := standbyFileDoc(target, current)
+	return false, fs.UpdateFileDoc(current, newdoc)
+}
+
+// UploadStandbyFile writes the content of a file on the secondary stack, with
+// its metadata.
+func UploadStandbyFile(inst *instance.Instance, target *FileDocWithRevisions, body io.ReadCloser) error {
+	defer body.Close()
+	sid := consts.Files + "/" + target.DocID
+	mu := config.Lock().ReadWrite(inst, "shared/"+sid)
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	current, err := inst.VFS().FileByID(target.DocID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	indexer := newSharingIndexer(inst, &bulkRevs{
+		Rev:       target.DocRev,
+		Revisions: target.Revisions,
+	}, nil)
+	fs := inst.VFS().UseSharingIndexer(indexer)
+	newdoc := standbyFileDoc(target, current)
+	file, err := fs.CreateFile(newdoc, current)
+	if err != nil {
+		return err
+	}
+	return copyFileContent(inst, file, body)
+}
+
+// DeleteStandbyFile removes a file or a directory (with its content) from the
+// secondary stack, when it has been deleted on the primary.
+func DeleteStandbyFile(inst *instance.Instance, id string) error {
+	fs := inst.VFS()
+	dir, file, err := fs.DirOrFileByID(id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		return fs.DestroyFile(file)
+	}
+	return fs.DestroyDirAndContent(dir, fs.EnsureErased)
+}
+
+// standbyFileDoc returns the file document to write on the secondary: it is
+// the document of the primary, but with the revision and the internal ID of
+// the current document (if any).
+func standbyFileDoc(target *FileDocWithRevisions, current *vfs.FileDoc) *vfs.FileDoc {
+	newdoc := target.FileDoc.Clone().(*vfs.FileDoc)
+	newdoc.DocRev = ""
+	newdoc.InternalID = ""
+	if current != nil {
+		newdoc.DocRev = current.DocRev
+		newdoc.InternalID = current.InternalID
+	}
+	newdoc.ResetFullpath()
+	return newdoc
+}
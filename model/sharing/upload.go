@@ -532,9 +532,15 @@ func (s *Sharing) HandleFileUpload(inst *instance.Instance, key string, body io.
 	}
 
 	if current == nil {
-		return s.UploadNewFile(inst, target, body)
+		err = s.UploadNewFile(inst, target, body)
+	} else {
+		err = s.UploadExistingFile(inst, target, current, body)
+	}
+	if errors.Is(err, vfs.ErrFileTooBig) {
+		cloned := s.Clone().(*Sharing)
+		go cloned.NotifyQuotaExceeded(inst)
 	}
-	return s.UploadExistingFile(inst, target, current, body)
+	return err
 }
 
 // UploadNewFile is used to receive a new file.
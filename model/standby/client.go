@@ -0,0 +1,140 @@
+package standby
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// DocHeader is the HTTP header used to send the JSON document of a file (with
+// its revisions) when its content is uploaded to the secondary stack. It is
+// encoded in base64.
+const DocHeader = "X-Cozy-Standby-Doc"
+
+// The secondary stack is usually on a private network, so the safehttp
+// client can't be used. There is no timeout on the client, as the uploads of
+// large files can be long, but the requests for the documents have one.
+var secondaryClient = &http.Client{}
+
+const docsTimeout = 2 * time.Minute
+
+// client is used by the primary stack to send the documents and files of an
+// instance to the admin API of the secondary stack.
+type client struct {
+	base *url.URL
+	inst *instance.Instance
+}
+
+func newClient(inst *instance.Instance) (*client, error) {
+	raw := config.GetConfig().Standby.URL
+	if raw == "" {
+		return nil, ErrNotConfigured
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &client{base: u, inst: inst}, nil
+}
+
+func (c *client) newRequest(method, path string, body io.Reader) (*http.Request, error) {
+	u := *c.base
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/instances/" + url.PathEscape(c.inst.Domain) + "/standby" + path
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if auth := c.base.User; auth != nil {
+		password, _ := auth.Password()
+		req.SetBasicAuth(auth.Username(), password)
+	}
+	return req, nil
+}
+
+func (c *client) do(req *http.Request, out interface{}) error {
+	res, err := secondaryClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("standby: %s %s: %s %s", req.Method, req.URL.Path, res.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func (c *client) doJSON(method, path string, in, out interface{}) error {
+	buf, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(method, path, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), docsTimeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return c.do(req, out)
+}
+
+// putInstance sends the document of the instance.
+func (c *client) putInstance(doc map[string]interface{}) error {
+	return c.doJSON(http.MethodPut, "/instance", doc, nil)
+}
+
+// postDocs sends a batch of documents for a doctype, with their revisions,
+// and returns the identifiers of the files whose content must be uploaded.
+func (c *client) postDocs(doctype string, docs []map[string]interface{}) ([]string, error) {
+	var out struct {
+		Missing []string `json:"missing"`
+	}
+	in := map[string]interface{}{"docs": docs}
+	if err := c.doJSON(http.MethodPost, "/docs/"+url.PathEscape(doctype), in, &out); err != nil {
+		return nil, err
+	}
+	return out.Missing, nil
+}
+
+// putFile uploads the content of a file, with its document.
+func (c *client) putFile(doc map[string]interface{}) error {
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var file vfs.FileDoc
+	if err := json.Unmarshal(buf, &file); err != nil {
+		return err
+	}
+	content, err := c.inst.VFS().OpenFile(&file)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	req, err := c.newRequest(http.MethodPut, "/files/"+url.PathEscape(file.DocID), content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = file.ByteSize
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(DocHeader, base64.StdEncoding.EncodeToString(buf))
+	return c.do(req, nil)
+}
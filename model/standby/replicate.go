@@ -0,0 +1,167 @@
+package standby
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// batchSize is the number of changes sent to the secondary in one request.
+const batchSize = 200
+
+// skippedDoctypes are the doctypes that are not replicated: the jobs are
+// transient, and the old versions of the files would need their content.
+var skippedDoctypes = map[string]bool{
+	consts.Jobs:          true,
+	consts.FilesVersions: true,
+}
+
+// Replicate pushes the changes of an instance since the last replication to
+// the secondary stack. It is called by the standby worker.
+func Replicate(inst *instance.Instance) error {
+	state, err := GetState(inst)
+	if err != nil {
+		return err
+	}
+	c, err := newClient(inst)
+	if err != nil {
+		return err
+	}
+
+	err = replicate(inst, c, state)
+	if err != nil {
+		state.LastError = err.Error()
+	} else {
+		now := time.Now()
+		state.LastReplication = &now
+		state.LastError = ""
+	}
+	if errs := couchdb.UpdateDoc(prefixer.GlobalPrefixer, state); errs != nil && err == nil {
+		err = errs
+	}
+	return err
+}
+
+func replicate(inst *instance.Instance, c *client, state *State) error {
+	var doc couchdb.JSONDoc
+	if err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.Instances, inst.ID(), &doc); err != nil {
+		return err
+	}
+	if err := c.putInstance(doc.M); err != nil {
+		return err
+	}
+
+	doctypes, err := couchdb.AllDoctypes(inst)
+	if err != nil {
+		return err
+	}
+	for _, doctype := range doctypes {
+		if skippedDoctypes[doctype] {
+			continue
+		}
+		if err := replicateDoctype(inst, c, state, doctype); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replicateDoctype sends the changes of a database by batches. The sequence
+// number is saved after each batch, so that a replication that has been
+// interrupted can restart where it stopped.
+func replicateDoctype(inst *instance.Instance, c *client, state *State, doctype string) error {
+	for {
+		res, err := couchdb.GetChanges(inst, &couchdb.ChangesRequest{
+			DocType: doctype,
+			Since:   state.Seqs[doctype],
+			Limit:   batchSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(res.Results) > 0 {
+			if err := replicateChanges(inst, c, doctype, res.Results); err != nil {
+				return err
+			}
+		}
+		if state.Seqs[doctype] != res.LastSeq {
+			state.Seqs[doctype] = res.LastSeq
+			if err := couchdb.UpdateDoc(prefixer.GlobalPrefixer, state); err != nil {
+				return err
+			}
+		}
+		if res.Pending == 0 || len(res.Results) == 0 {
+			return nil
+		}
+	}
+}
+
+func replicateChanges(inst *instance.Instance, c *client, doctype string, changes []couchdb.Change) error {
+	payload := make([]couchdb.IDRev, 0, len(changes))
+	for _, change := range changes {
+		if len(change.Changes) == 0 {
+			continue
+		}
+		payload = append(payload, couchdb.IDRev{
+			ID:  change.DocID,
+			Rev: change.Changes[0].Rev,
+		})
+	}
+	docs, err := couchdb.BulkGetDocs(inst, doctype, payload)
+	if err != nil {
+		return err
+	}
+	docs = filterDocs(doctype, docs)
+	if len(docs) == 0 {
+		return nil
+	}
+	missing, err := c.postDocs(doctype, docs)
+	if err != nil {
+		return err
+	}
+	for _, id := range missing {
+		if err := replicateFile(inst, c, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replicateFile uploads the content of a file. The last revision of the file
+// is sent, to be consistent with the content that is read.
+func replicateFile(inst *instance.Instance, c *client, id string) error {
+	docs, err := couchdb.BulkGetDocs(inst, consts.Files, []couchdb.IDRev{{ID: id}})
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	doc := docs[0]
+	if deleted, _ := doc["_deleted"].(bool); deleted {
+		return nil
+	}
+	if typ, _ := doc["type"].(string); typ != consts.FileType {
+		return nil
+	}
+	return c.putFile(doc)
+}
+
+// filterDocs removes the documents that must not be copied on the secondary,
+// like the trigger of the standby replication itself.
+func filterDocs(doctype string, docs []map[string]interface{}) []map[string]interface{} {
+	if doctype != consts.Triggers {
+		return docs
+	}
+	filtered := docs[:0]
+	for _, doc := range docs {
+		if worker, _ := doc["worker"].(string); worker == WorkerType {
+			continue
+		}
+		filtered = append(filtered, doc)
+	}
+	return filtered
+}
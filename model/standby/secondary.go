@@ -0,0 +1,183 @@
+package standby
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// ReceiveInstance is called on the secondary stack with the document of an
+// instance from the primary. The instance is created if needed, and it is
+// kept blocked until it is promoted.
+func ReceiveInstance(domain string, doc map[string]interface{}) (*instance.Instance, error) {
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var received instance.Instance
+	if err := json.Unmarshal(buf, &received); err != nil {
+		return nil, err
+	}
+	if received.Domain != domain {
+		return nil, errors.New("the domain of the instance does not match")
+	}
+	received.Blocked = true
+	received.BlockingReason = instance.BlockedStandby.Code
+
+	current, err := instance.GetFromCouch(domain)
+	if err == nil {
+		if !IsStandby(current) {
+			return nil, ErrNotStandby
+		}
+		// The CouchDB cluster is specific to the configuration of each stack
+		received.DocRev = current.DocRev
+		received.CouchCluster = current.CouchCluster
+		if err := instance.Update(&received); err != nil {
+			return nil, err
+		}
+		return &received, nil
+	}
+	if !errors.Is(err, instance.ErrNotFound) {
+		return nil, err
+	}
+
+	received.DocRev = ""
+	received.CouchCluster, err = lifecycle.ChooseCouchCluster(config.GetConfig().CouchDB.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	if err := couchdb.CreateNamedDocWithDB(prefixer.GlobalPrefixer, &received); err != nil {
+		return nil, err
+	}
+	if err := received.MakeVFS(); err != nil {
+		return nil, err
+	}
+	if err := couchdb.EnsureDBExist(&received, consts.Files); err != nil {
+		return nil, err
+	}
+	if err := received.VFS().InitFs(); err != nil {
+		return nil, err
+	}
+	return &received, nil
+}
+
+// ApplyDocs is called on the secondary stack with a batch of documents from
+// the primary, with their revisions. For io.cozy.files, it returns the
+// identifiers of the files whose content must be uploaded.
+func ApplyDocs(inst *instance.Instance, doctype string, docs []map[string]interface{}) ([]string, error) {
+	if !IsStandby(inst) {
+		return nil, ErrNotStandby
+	}
+	if err := couchdb.EnsureDBExist(inst, doctype); err != nil {
+		return nil, err
+	}
+	if doctype != consts.Files {
+		return nil, couchdb.BulkForceUpdateDocs(inst, doctype, docs)
+	}
+	return applyFiles(inst, docs)
+}
+
+// applyFiles uses the VFS to write the files and directories, as the
+// contents must be kept in sync with the documents. The deletions are made
+// first (a file moved out of a deleted directory will just be uploaded
+// again), then the directories from the top of the tree, and the files.
+func applyFiles(inst *instance.Instance, docs []map[string]interface{}) ([]string, error) {
+	var dirs, files []map[string]interface{}
+	for _, doc := range docs {
+		id, _ := doc["_id"].(string)
+		if deleted, _ := doc["_deleted"].(bool); deleted {
+			if err := sharing.DeleteStandbyFile(inst, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.HasPrefix(id, "_design/") {
+			if err := couchdb.BulkForceUpdateDocs(inst, consts.Files, []map[string]interface{}{doc}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if typ, _ := doc["type"].(string); typ == consts.DirType {
+			dirs = append(dirs, doc)
+		} else {
+			files = append(files, doc)
+		}
+	}
+
+	sort.SliceStable(dirs, func(i, j int) bool {
+		pi, _ := dirs[i]["path"].(string)
+		pj, _ := dirs[j]["path"].(string)
+		return strings.Count(pi, "/") < strings.Count(pj, "/")
+	})
+	for _, dir := range dirs {
+		if err := sharing.ApplyStandbyDir(inst, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	missing := []string{}
+	for _, doc := range files {
+		target, err := toFileDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		upload, err := sharing.SyncStandbyFile(inst, target)
+		if err != nil {
+			return nil, err
+		}
+		if upload {
+			missing = append(missing, target.DocID)
+		}
+	}
+	return missing, nil
+}
+
+// ApplyFile is called on the secondary stack to write the content of a file
+// sent by the primary. The header is the JSON document of the file, with its
+// revisions.
+func ApplyFile(inst *instance.Instance, id string, header []byte, body io.ReadCloser) error {
+	if !IsStandby(inst) {
+		body.Close()
+		return ErrNotStandby
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(header, &doc); err != nil {
+		body.Close()
+		return err
+	}
+	target, err := toFileDoc(doc)
+	if err != nil {
+		body.Close()
+		return err
+	}
+	if target.DocID != id {
+		body.Close()
+		return errors.New("the identifier of the file does not match")
+	}
+	return sharing.UploadStandbyFile(inst, target, body)
+}
+
+func toFileDoc(doc map[string]interface{}) (*sharing.FileDocWithRevisions, error) {
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var target sharing.FileDocWithRevisions
+	if err := json.Unmarshal(buf, &target); err != nil {
+		return nil, err
+	}
+	if target.FileDoc == nil || len(target.Revisions.IDs) == 0 {
+		return nil, errors.New("invalid file document")
+	}
+	return &target, nil
+}
@@ -0,0 +1,182 @@
+// Package standby is for the warm standby replication between two stacks: a
+// secondary stack continuously receives the CouchDB documents and the files
+// of some instances from the primary stack, and the copy of an instance can be
+// promoted on the secondary if the primary is lost. It is an alternative to a
+// CouchDB cluster and an object storage spread on several datacenters.
+package standby
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// WorkerType is the type of the worker that pushes the changes of an instance
+// to the secondary stack.
+const WorkerType = "standby"
+
+var (
+	// ErrNotConfigured is used when the URL of the secondary stack is missing
+	// in the configuration.
+	ErrNotConfigured = errors.New("the standby replication is not configured")
+	// ErrNotEnabled is used when the standby replication has not been enabled
+	// for an instance.
+	ErrNotEnabled = errors.New("the standby replication is not enabled for this instance")
+	// ErrNotStandby is used on the secondary stack when an instance exists
+	// but is not a copy made by the standby replication.
+	ErrNotStandby = errors.New("the instance is not a standby copy")
+)
+
+// State is the state of the standby replication of an instance, on the
+// primary stack. Its identifier is the domain of the instance, and it is
+// saved in the global database.
+type State struct {
+	DocID           string            `json:"_id,omitempty"`
+	DocRev          string            `json:"_rev,omitempty"`
+	TriggerID       string            `json:"trigger_id,omitempty"`
+	Seqs            map[string]string `json:"seqs"`
+	LastReplication *time.Time        `json:"last_replication,omitempty"`
+	LastError       string            `json:"last_error,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (s *State) ID() string { return s.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (s *State) Rev() string { return s.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (s *State) DocType() string { return consts.Standby }
+
+// Clone implements the couchdb.Doc interface
+func (s *State) Clone() couchdb.Doc {
+	cloned := *s
+	cloned.Seqs = make(map[string]string, len(s.Seqs))
+	for k, v := range s.Seqs {
+		cloned.Seqs[k] = v
+	}
+	if s.LastReplication != nil {
+		at := *s.LastReplication
+		cloned.LastReplication = &at
+	}
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (s *State) SetID(id string) { s.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (s *State) SetRev(rev string) { s.DocRev = rev }
+
+// GetState returns the state of the standby replication of an instance.
+func GetState(inst *instance.Instance) (*State, error) {
+	var state State
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.Standby, inst.Domain, &state)
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return nil, ErrNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if state.Seqs == nil {
+		state.Seqs = make(map[string]string)
+	}
+	return &state, nil
+}
+
+// Enable starts the standby replication of an instance: a trigger is created
+// to push the changes periodically to the secondary stack, and a first
+// replication is started.
+func Enable(inst *instance.Instance) (*State, error) {
+	cfg := config.GetConfig().Standby
+	if cfg.URL == "" {
+		return nil, ErrNotConfigured
+	}
+	state, err := GetState(inst)
+	if errors.Is(err, ErrNotEnabled) {
+		state = &State{
+			DocID: inst.Domain,
+			Seqs:  make(map[string]string),
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sched := job.System()
+	if state.TriggerID != "" {
+		if _, err := sched.GetTrigger(inst, state.TriggerID); err == nil {
+			return state, nil
+		}
+	}
+	infos := job.TriggerInfos{
+		Type:       "@every",
+		WorkerType: WorkerType,
+		Arguments:  cfg.Interval.String(),
+	}
+	t, err := job.NewTrigger(inst, infos, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := sched.AddTrigger(t); err != nil {
+		return nil, err
+	}
+	state.TriggerID = t.ID()
+	if err := couchdb.Upsert(prefixer.GlobalPrefixer, state); err != nil {
+		return nil, err
+	}
+
+	_, err = sched.PushJob(inst, &job.JobRequest{
+		WorkerType: WorkerType,
+		Manual:     true,
+	})
+	return state, err
+}
+
+// Disable stops the standby replication of an instance. The copy of the
+// instance on the secondary stack is kept.
+func Disable(inst *instance.Instance) error {
+	state, err := GetState(inst)
+	if err != nil {
+		return err
+	}
+	if state.TriggerID != "" {
+		err := job.System().DeleteTrigger(inst, state.TriggerID)
+		if err != nil && !errors.Is(err, job.ErrNotFoundTrigger) {
+			return err
+		}
+	}
+	return couchdb.DeleteDoc(prefixer.GlobalPrefixer, state)
+}
+
+// Promote is called on the secondary stack to make the copy of an instance
+// the live one, after a failover. The instance is unblocked, and its triggers
+// are added to the scheduler.
+func Promote(inst *instance.Instance) error {
+	if !IsStandby(inst) {
+		return ErrNotStandby
+	}
+	if err := lifecycle.Unblock(inst); err != nil {
+		return err
+	}
+	// The in-memory scheduler loads the triggers of all the instances when
+	// the stack starts, and the standby jobs are skipped by the workers.
+	if config.GetConfig().Jobs.Client == nil {
+		return nil
+	}
+	return job.System().RebuildRedis(inst)
+}
+
+// IsStandby returns true if the instance is a copy made by the standby
+// replication on the secondary stack, that has not been promoted.
+func IsStandby(inst *instance.Instance) bool {
+	return inst.Blocked && inst.BlockingReason == instance.BlockedStandby.Code
+}
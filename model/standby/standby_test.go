@@ -0,0 +1,53 @@
+package standby
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterDocs(t *testing.T) {
+	docs := []map[string]interface{}{
+		{"_id": "t1", "worker": "thumbnail"},
+		{"_id": "t2", "worker": WorkerType},
+		{"_id": "t3", "worker": "konnector"},
+	}
+	filtered := filterDocs(consts.Triggers, docs)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "t1", filtered[0]["_id"])
+	assert.Equal(t, "t3", filtered[1]["_id"])
+
+	docs = []map[string]interface{}{
+		{"_id": "c1", "worker": WorkerType},
+	}
+	assert.Len(t, filterDocs(consts.Contacts, docs), 1)
+}
+
+func TestToFileDoc(t *testing.T) {
+	doc := map[string]interface{}{
+		"_id":    "f1",
+		"_rev":   "2-bbb",
+		"type":   consts.FileType,
+		"name":   "foo.txt",
+		"dir_id": consts.RootDirID,
+		"size":   "3",
+		"md5sum": "rL0Y20zC+Fzt72VPzMSk2A==",
+		"_revisions": map[string]interface{}{
+			"start": 2,
+			"ids":   []interface{}{"bbb", "aaa"},
+		},
+	}
+	target, err := toFileDoc(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "f1", target.DocID)
+	assert.Equal(t, "2-bbb", target.DocRev)
+	assert.EqualValues(t, 3, target.ByteSize)
+	assert.Equal(t, 2, target.Revisions.Start)
+	assert.Equal(t, []string{"bbb", "aaa"}, target.Revisions.IDs)
+
+	delete(doc, "_revisions")
+	_, err = toFileDoc(doc)
+	assert.Error(t, err)
+}
@@ -20,8 +20,9 @@ var (
 type Operation string
 
 var (
-	EmailUpdate Operation = "email_update"
-	MagicLink   Operation = "magic_link"
+	EmailUpdate    Operation = "email_update"
+	EmailUpdateOld Operation = "email_update_old"
+	MagicLink      Operation = "magic_link"
 )
 
 // TokenService is a [Service] implementation based on [cache.Cache].
@@ -84,7 +85,7 @@ func generateKey(db prefixer.Prefixer, ns Operation, code string) (string, error
 	var nsStr string
 
 	switch ns {
-	case EmailUpdate, MagicLink:
+	case EmailUpdate, EmailUpdateOld, MagicLink:
 		nsStr = string(ns)
 	default:
 		return "", ErrInvalidNamespace
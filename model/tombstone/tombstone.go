@@ -0,0 +1,370 @@
+// Package tombstone is used to purge the tombstones of the deleted documents
+// from the CouchDB databases of an instance. When a document is deleted,
+// CouchDB keeps a stub with its identifier and revisions, so that the
+// deletion can be replicated. On long-lived instances, these stubs can take a
+// lot of space, and they can be purged once they are no longer needed by the
+// sharings and the replications.
+package tombstone
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/revision"
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// WorkerType is the type of the worker that purges the tombstones.
+const WorkerType = "purge-tombstones"
+
+// checkpointsID is the identifier of the local document where the
+// checkpoints are saved, in each database.
+const checkpointsID = "tombstones-checkpoints"
+
+// checkpointInterval is the minimal delay between two checkpoints, to avoid
+// having too many of them when the worker runs often.
+const checkpointInterval = time.Hour
+
+// changesBatchSize is the number of changes fetched in one request.
+const changesBatchSize = 1000
+
+// The reasons for skipping a database.
+const (
+	// SkippedReplication is used when a CouchDB replication is running for
+	// the database.
+	SkippedReplication = "active_replication"
+	// SkippedNoCheckpoint is used when the database has no checkpoint older
+	// than the minimal age of the tombstones.
+	SkippedNoCheckpoint = "no_checkpoint"
+)
+
+// ErrProtectedDoctype is used when the purge is asked for a database where
+// the tombstones are always kept.
+var ErrProtectedDoctype = errors.New("the tombstones of this doctype cannot be purged")
+
+// protectedDoctypes are the doctypes where the tombstones are never purged:
+// the io.cozy.shared documents are used by the replicator of the sharings.
+var protectedDoctypes = map[string]struct{}{
+	consts.Shared: {},
+}
+
+// Message is the message of the purge-tombstones worker. When no doctype is
+// given, all the databases of the instance are purged.
+type Message struct {
+	Doctypes []string `json:"doctypes,omitempty"`
+	DryRun   bool     `json:"dry_run,omitempty"`
+}
+
+// Report is what has been done for a database.
+type Report struct {
+	Doctype string `json:"doctype"`
+	// Skipped is the reason why the database has not been purged
+	Skipped string `json:"skipped,omitempty"`
+	// CheckpointAt is the date of the checkpoint: only the tombstones of the
+	// documents deleted before this date are purged
+	CheckpointAt *time.Time `json:"checkpoint_at,omitempty"`
+	// Tombstones is the number of tombstones older than the checkpoint
+	Tombstones int `json:"tombstones"`
+	// Kept is the number of tombstones kept for the active sharings
+	Kept int `json:"kept"`
+	// Purged is the number of tombstones that have been purged, and that are
+	// no longer in the database
+	Purged int `json:"purged"`
+	// Failed are the identifiers of the documents where the verification
+	// after the purge has failed
+	Failed []string `json:"failed,omitempty"`
+	DryRun bool     `json:"dry_run,omitempty"`
+}
+
+// checkpoint is the sequence number of a database at a given date.
+type checkpoint struct {
+	At  time.Time `json:"at"`
+	Seq string    `json:"seq"`
+}
+
+// CheckDoctype returns an error if the tombstones of the doctype cannot be
+// purged.
+func CheckDoctype(doctype string) error {
+	if _, ok := protectedDoctypes[doctype]; ok {
+		return ErrProtectedDoctype
+	}
+	return nil
+}
+
+// PurgeAll purges the tombstones of the doctypes of the message, or of all
+// the databases of the instance if the message has no doctype.
+func PurgeAll(inst *instance.Instance, msg *Message) ([]*Report, error) {
+	if !msg.DryRun {
+		if err := inst.CheckLegalHold(); err != nil {
+			return nil, err
+		}
+	}
+	doctypes := msg.Doctypes
+	if len(doctypes) == 0 {
+		all, err := couchdb.AllDoctypes(inst)
+		if err != nil {
+			return nil, err
+		}
+		doctypes = all
+	}
+
+	var reports []*Report
+	var errm error
+	for _, doctype := range doctypes {
+		if CheckDoctype(doctype) != nil && len(msg.Doctypes) == 0 {
+			continue
+		}
+		report, err := Purge(inst, doctype, msg.DryRun)
+		if err != nil {
+			errm = multierror.Append(errm, err)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	return reports, errm
+}
+
+// Purge removes the tombstones of the documents deleted before the last
+// checkpoint older than the minimal age, and checks that they are no longer
+// in the database. The tombstones of the documents still referenced by an
+// active sharing are kept. With dryRun, the tombstones are only counted.
+func Purge(inst *instance.Instance, doctype string, dryRun bool) (*Report, error) {
+	report := &Report{Doctype: doctype, DryRun: dryRun}
+	if err := CheckDoctype(doctype); err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		if err := inst.CheckLegalHold(); err != nil {
+			return nil, err
+		}
+	}
+
+	active, err := couchdb.HasActiveReplication(inst, doctype)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		report.Skipped = SkippedReplication
+		return report, nil
+	}
+
+	limit, err := updateCheckpoints(inst, doctype, time.Now())
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return report, nil
+		}
+		return nil, err
+	}
+	if limit == nil {
+		report.Skipped = SkippedNoCheckpoint
+		return report, nil
+	}
+	report.CheckpointAt = &limit.At
+
+	sharings, err := activeSharings(inst, doctype)
+	if err != nil {
+		return nil, err
+	}
+
+	maxGen := revision.Generation(limit.Seq)
+	since := ""
+	for {
+		res, err := couchdb.GetChanges(inst, &couchdb.ChangesRequest{
+			DocType: doctype,
+			Since:   since,
+			Limit:   changesBatchSize,
+			Style:   couchdb.ChangesStyleAllDocs,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		done := res.Pending == 0 || len(res.Results) == 0
+		tombstones := make(map[string][]string)
+		for _, change := range res.Results {
+			if revision.Generation(change.Seq) > maxGen {
+				done = true
+				break
+			}
+			if !change.Deleted || strings.HasPrefix(change.DocID, "_design") {
+				continue
+			}
+			revs := make([]string, 0, len(change.Changes))
+			for _, c := range change.Changes {
+				revs = append(revs, c.Rev)
+			}
+			tombstones[change.DocID] = revs
+		}
+		if err := purgeTombstones(inst, report, sharings, tombstones); err != nil {
+			return nil, err
+		}
+
+		if done {
+			break
+		}
+		since = res.LastSeq
+	}
+	return report, nil
+}
+
+// purgeTombstones purges a batch of tombstones, and verifies that they are no
+// longer in the database.
+func purgeTombstones(inst *instance.Instance, report *Report, sharings map[string]struct{}, tombstones map[string][]string) error {
+	report.Tombstones += len(tombstones)
+	if len(sharings) > 0 && len(tombstones) > 0 {
+		kept, err := keptForSharings(inst, report.Doctype, sharings, tombstones)
+		if err != nil {
+			return err
+		}
+		for _, id := range kept {
+			delete(tombstones, id)
+		}
+		report.Kept += len(kept)
+	}
+	if report.DryRun {
+		return nil
+	}
+
+	batch := make(map[string][]string, couchdb.PurgeBatchSize)
+	for id, revs := range tombstones {
+		batch[id] = revs
+		if len(batch) == couchdb.PurgeBatchSize {
+			if err := purgeBatch(inst, report, batch); err != nil {
+				return err
+			}
+			batch = make(map[string][]string, couchdb.PurgeBatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		return purgeBatch(inst, report, batch)
+	}
+	return nil
+}
+
+func purgeBatch(inst *instance.Instance, report *Report, batch map[string][]string) error {
+	if _, err := couchdb.Purge(inst, report.Doctype, batch); err != nil {
+		return err
+	}
+	// Hard delete verification: all the purged revisions must be missing
+	missing, err := couchdb.RevsDiff(inst, report.Doctype, batch)
+	if err != nil {
+		return err
+	}
+	for id, revs := range batch {
+		if len(missing[id]) == len(revs) {
+			report.Purged++
+		} else {
+			report.Failed = append(report.Failed, id)
+		}
+	}
+	return nil
+}
+
+// keptForSharings returns the identifiers of the documents that are still
+// referenced by an active sharing: their tombstones are needed to replicate
+// the deletion to the other members.
+func keptForSharings(inst *instance.Instance, doctype string, sharings map[string]struct{}, tombstones map[string][]string) ([]string, error) {
+	keys := make([]string, 0, len(tombstones))
+	for id := range tombstones {
+		keys = append(keys, doctype+"/"+id)
+	}
+	var refs []*sharing.SharedRef
+	req := &couchdb.AllDocsRequest{Keys: keys}
+	if err := couchdb.GetAllDocs(inst, consts.Shared, req, &refs); err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var kept []string
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		for sid := range ref.Infos {
+			if _, ok := sharings[sid]; ok {
+				kept = append(kept, strings.TrimPrefix(ref.SID, doctype+"/"))
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// activeSharings returns the identifiers of the active sharings with a rule
+// for the doctype.
+func activeSharings(inst *instance.Instance, doctype string) (map[string]struct{}, error) {
+	all, err := sharing.GetSharingsByDocType(inst, doctype)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	active := make(map[string]struct{}, len(all))
+	for id, s := range all {
+		if s.Active {
+			active[id] = struct{}{}
+		}
+	}
+	return active, nil
+}
+
+// updateCheckpoints adds a checkpoint with the current sequence number of the
+// database, and returns the last checkpoint older than the minimal age of
+// the tombstones (or nil if there is none). As the tombstones have no date,
+// the checkpoints are used to know when the documents have been deleted.
+func updateCheckpoints(inst *instance.Instance, doctype string, now time.Time) (*checkpoint, error) {
+	status, err := couchdb.DBStatus(inst, doctype)
+	if err != nil {
+		return nil, err
+	}
+	local, err := couchdb.GetLocal(inst, doctype, checkpointsID)
+	if err != nil {
+		if !couchdb.IsNotFoundError(err) {
+			return nil, err
+		}
+		local = make(map[string]interface{})
+	}
+	var checkpoints []checkpoint
+	if raw, ok := local["checkpoints"]; ok {
+		if data, err := json.Marshal(raw); err == nil {
+			_ = json.Unmarshal(data, &checkpoints)
+		}
+	}
+
+	if n := len(checkpoints); n == 0 || now.Sub(checkpoints[n-1].At) >= checkpointInterval {
+		checkpoints = append(checkpoints, checkpoint{At: now.UTC(), Seq: status.UpdateSeq})
+	}
+	limit, checkpoints := findLimit(checkpoints, now.Add(-config.GetConfig().CouchDB.TombstonesMinAge))
+
+	local["checkpoints"] = checkpoints
+	if err := couchdb.PutLocal(inst, doctype, checkpointsID, local); err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// findLimit returns the last checkpoint before the given date, and the
+// checkpoints to keep for the next runs (the older ones are useless).
+func findLimit(checkpoints []checkpoint, before time.Time) (*checkpoint, []checkpoint) {
+	idx := -1
+	for i, cp := range checkpoints {
+		if cp.At.After(before) {
+			break
+		}
+		idx = i
+	}
+	if idx < 0 {
+		return nil, checkpoints
+	}
+	limit := checkpoints[idx]
+	return &limit, checkpoints[idx:]
+}
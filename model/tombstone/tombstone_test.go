@@ -0,0 +1,39 @@
+package tombstone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDoctype(t *testing.T) {
+	assert.NoError(t, CheckDoctype(consts.Files))
+	assert.ErrorIs(t, CheckDoctype(consts.Shared), ErrProtectedDoctype)
+}
+
+func TestFindLimit(t *testing.T) {
+	now := time.Now()
+	checkpoints := []checkpoint{
+		{At: now.Add(-50 * 24 * time.Hour), Seq: "10-a"},
+		{At: now.Add(-40 * 24 * time.Hour), Seq: "20-b"},
+		{At: now.Add(-20 * 24 * time.Hour), Seq: "30-c"},
+		{At: now, Seq: "40-d"},
+	}
+
+	limit, kept := findLimit(checkpoints, now.Add(-60*24*time.Hour))
+	assert.Nil(t, limit)
+	assert.Len(t, kept, 4)
+
+	limit, kept = findLimit(checkpoints, now.Add(-30*24*time.Hour))
+	require.NotNil(t, limit)
+	assert.Equal(t, "20-b", limit.Seq)
+	assert.Equal(t, checkpoints[1:], kept)
+
+	limit, kept = findLimit(checkpoints, now)
+	require.NotNil(t, limit)
+	assert.Equal(t, "40-d", limit.Seq)
+	assert.Len(t, kept, 1)
+}
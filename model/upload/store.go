@@ -0,0 +1,143 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store keeps the state of the resumable uploads between two HTTP requests.
+type Store interface {
+	Save(db prefixer.Prefixer, s *Session) error
+	Get(db prefixer.Prefixer, id string) (*Session, error)
+	Delete(db prefixer.Prefixer, id string) error
+}
+
+// storeCleanInterval is the time interval between each cleanup of the
+// expired sessions in memory.
+var storeCleanInterval = 1 * time.Hour
+
+var globalStoreMu sync.Mutex
+var globalStore Store
+
+// GetStore returns the global Store.
+func GetStore() Store {
+	globalStoreMu.Lock()
+	defer globalStoreMu.Unlock()
+	if globalStore != nil {
+		return globalStore
+	}
+	cli := config.GetConfig().DownloadStorage
+	if cli == nil {
+		globalStore = newMemStore()
+	} else {
+		globalStore = newRedisStore(cli)
+	}
+	return globalStore
+}
+
+func storeKey(db prefixer.Prefixer, id string) string {
+	return "uploads:" + db.DBPrefix() + ":" + id
+}
+
+func newMemStore() Store {
+	store := &memStore{vals: make(map[string]*Session)}
+	go store.cleaner()
+	return store
+}
+
+type memStore struct {
+	mu   sync.Mutex
+	vals map[string]*Session
+}
+
+func (s *memStore) cleaner() {
+	for range time.Tick(storeCleanInterval) {
+		now := time.Now()
+		s.mu.Lock()
+		for k, v := range s.vals {
+			if now.After(v.ExpiresAt) {
+				delete(s.vals, k)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *memStore) Save(db prefixer.Prefixer, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cloned := *session
+	s.vals[storeKey(db, session.ID)] = &cloned
+	return nil
+}
+
+func (s *memStore) Get(db prefixer.Prefixer, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := storeKey(db, id)
+	session, ok := s.vals[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if time.Now().After(session.ExpiresAt) {
+		delete(s.vals, key)
+		return nil, ErrNotFound
+	}
+	cloned := *session
+	return &cloned, nil
+}
+
+func (s *memStore) Delete(db prefixer.Prefixer, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vals, storeKey(db, id))
+	return nil
+}
+
+type redisStore struct {
+	c   redis.UniversalClient
+	ctx context.Context
+}
+
+func newRedisStore(cli redis.UniversalClient) Store {
+	ctx := context.Background()
+	return &redisStore{cli, ctx}
+}
+
+func (s *redisStore) Save(db prefixer.Prefixer, session *Session) error {
+	v, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return ErrNotFound
+	}
+	return s.c.Set(s.ctx, storeKey(db, session.ID), v, ttl).Err()
+}
+
+func (s *redisStore) Get(db prefixer.Prefixer, id string) (*Session, error) {
+	b, err := s.c.Get(s.ctx, storeKey(db, id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{}
+	if err = json.Unmarshal(b, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *redisStore) Delete(db prefixer.Prefixer, id string) error {
+	return s.c.Del(s.ctx, storeKey(db, id)).Err()
+}
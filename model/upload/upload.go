@@ -0,0 +1,309 @@
+// Package upload is for the resumable uploads of large files, with the tus
+// protocol (https://tus.io/). The state of an upload is kept in redis (or in
+// memory), and the chunks are appended to a file in the uploads directory,
+// until the upload is complete and the file is created in the VFS.
+package upload
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/logger"
+)
+
+// TTL is the delay after which an upload that has not been completed expires.
+const TTL = 24 * time.Hour
+
+var (
+	// ErrNotFound is used when the upload does not exist, or has expired.
+	ErrNotFound = errors.New("The upload does not exist or has expired")
+	// ErrOffsetMismatch is used when the offset of a chunk is not the current
+	// offset of the upload.
+	ErrOffsetMismatch = errors.New("The offset does not match the current offset of the upload")
+	// ErrExceedsSize is used when more bytes than the size of the upload are
+	// sent.
+	ErrExceedsSize = errors.New("The chunk exceeds the size of the upload")
+	// ErrInvalidSize is used when the size of an upload is not valid.
+	ErrInvalidSize = errors.New("The size of the upload is invalid")
+)
+
+// Session is the state of a resumable upload.
+type Session struct {
+	ID string `json:"id"`
+	// Size is the total size of the file, in bytes.
+	Size int64 `json:"size"`
+	// Offset is the number of bytes that have been received.
+	Offset int64 `json:"offset"`
+
+	DirID        string                 `json:"dir_id"`
+	Name         string                 `json:"name"`
+	MIME         string                 `json:"mime,omitempty"`
+	Class        string                 `json:"class,omitempty"`
+	Executable   bool                   `json:"executable,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Metadata     vfs.Metadata           `json:"metadata,omitempty"`
+	CozyMetadata *vfs.FilesCozyMetadata `json:"cozy_metadata,omitempty"`
+
+	// FileID is the identifier of the file in the VFS, when the upload is
+	// complete.
+	FileID    string    `json:"file_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Complete returns true if the file has been created in the VFS.
+func (s *Session) Complete() bool {
+	return s.FileID != ""
+}
+
+// FileDoc returns the document for the file that will be created in the VFS
+// at the end of the upload.
+func (s *Session) FileDoc() (*vfs.FileDoc, error) {
+	doc, err := vfs.NewFileDoc(s.Name, s.DirID, s.Size, nil, s.MIME, s.Class,
+		s.CreatedAt, s.Executable, false, false, s.Tags)
+	if err != nil {
+		return nil, err
+	}
+	doc.Metadata = s.Metadata
+	if s.CozyMetadata != nil {
+		doc.CozyMetadata = s.CozyMetadata.Clone()
+	}
+	return doc, nil
+}
+
+// Create checks that the file can be created, and starts the upload.
+func Create(inst *instance.Instance, s *Session) error {
+	if s.Size < 0 {
+		return ErrInvalidSize
+	}
+	fs := inst.VFS()
+	doc, err := s.FileDoc()
+	if err != nil {
+		return err
+	}
+	if _, _, _, err := vfs.CheckAvailableDiskSpace(fs, doc); err != nil {
+		return err
+	}
+	parent, err := fs.DirByID(s.DirID)
+	if err != nil {
+		return err
+	}
+	exists, err := fs.DirChildExists(parent.DocID, s.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return os.ErrExist
+	}
+
+	cleanOldChunks()
+	now := time.Now().UTC()
+	s.ID = hex.EncodeToString(crypto.GenerateRandomBytes(16))
+	s.Offset = 0
+	s.FileID = ""
+	s.CreatedAt = now
+	s.ExpiresAt = now.Add(TTL)
+	f, err := os.OpenFile(chunksPath(inst, s.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := GetStore().Save(inst, s); err != nil {
+		_ = os.Remove(chunksPath(inst, s.ID))
+		return err
+	}
+	if s.Size == 0 {
+		return finalize(inst, s)
+	}
+	return nil
+}
+
+// Get returns the upload with the given identifier.
+func Get(inst *instance.Instance, id string) (*Session, error) {
+	return GetStore().Get(inst, id)
+}
+
+// Append writes a chunk of the file at the given offset, and creates the file
+// in the VFS when the last chunk has been received. The offset of the upload
+// is saved even if the chunk has been only partially received, so that the
+// client can resume the upload.
+func Append(inst *instance.Instance, id string, offset int64, r io.Reader) (*Session, error) {
+	mu := config.Lock().ReadWrite(inst, "uploads/"+id)
+	if err := mu.Lock(); err != nil {
+		return nil, err
+	}
+	defer mu.Unlock()
+
+	s, err := Get(inst, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Complete() {
+		return s, nil
+	}
+	if offset != s.Offset {
+		return s, ErrOffsetMismatch
+	}
+
+	if s.Offset < s.Size {
+		n, err := writeChunk(inst, s, r)
+		s.Offset += n
+		if errSave := GetStore().Save(inst, s); errSave != nil {
+			return nil, errSave
+		}
+		if err != nil {
+			return s, err
+		}
+	}
+	if s.Offset == s.Size {
+		if err := finalize(inst, s); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// Terminate cancels the upload.
+func Terminate(inst *instance.Instance, s *Session) error {
+	if err := GetStore().Delete(inst, s.ID); err != nil {
+		return err
+	}
+	if err := os.Remove(chunksPath(inst, s.ID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeChunk appends the bytes of the reader to the chunks file, without
+// exceeding the size of the upload.
+func writeChunk(inst *instance.Instance, s *Session, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(chunksPath(inst, s.ID), os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	// The bytes written after the last saved offset (for example, if the
+	// stack has been stopped in the middle of a chunk) are discarded.
+	if err := f.Truncate(s.Offset); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if _, err := f.Seek(s.Offset, io.SeekStart); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	remaining := s.Size - s.Offset
+	n, err := io.Copy(f, io.LimitReader(r, remaining))
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, err
+	}
+	if n == remaining {
+		// Check that the client has not sent more bytes than announced. The
+		// chunk is rejected, and the bytes will be truncated by the next one.
+		var buf [1]byte
+		if extra, _ := r.Read(buf[:]); extra > 0 {
+			return 0, ErrExceedsSize
+		}
+	}
+	return n, nil
+}
+
+// finalize creates the file in the VFS with the content of the chunks file.
+func finalize(inst *instance.Instance, s *Session) error {
+	doc, err := s.FileDoc()
+	if err != nil {
+		return err
+	}
+	chunks, err := os.Open(chunksPath(inst, s.ID))
+	if err != nil {
+		return err
+	}
+	defer chunks.Close()
+
+	file, err := inst.VFS().CreateFile(doc, nil)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, chunks)
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	s.FileID = doc.ID()
+	if err := GetStore().Save(inst, s); err != nil {
+		return err
+	}
+	if err := os.Remove(chunksPath(inst, s.ID)); err != nil {
+		inst.Logger().WithNamespace("upload").
+			Warnf("Cannot remove the chunks of %s: %s", s.ID, err)
+	}
+	return nil
+}
+
+// uploadsDir returns the directory where the chunks files are kept.
+func uploadsDir() string {
+	dir := config.GetConfig().Fs.UploadsDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "cozy-uploads")
+	}
+	return dir
+}
+
+func chunksPath(inst *instance.Instance, id string) string {
+	dir := uploadsDir()
+	_ = os.MkdirAll(dir, 0700)
+	return filepath.Join(dir, inst.DBPrefix()+"-"+id)
+}
+
+var lastCleanMu sync.Mutex
+var lastClean time.Time
+
+// cleanOldChunks removes the chunks files of the uploads that have expired.
+// It is called when an upload is created, at most once per hour.
+func cleanOldChunks() {
+	lastCleanMu.Lock()
+	if time.Since(lastClean) < time.Hour {
+		lastCleanMu.Unlock()
+		return
+	}
+	lastClean = time.Now()
+	lastCleanMu.Unlock()
+
+	dir := uploadsDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if time.Since(info.ModTime()) > TTL {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				logger.WithNamespace("upload").
+					Warnf("Cannot remove %s: %s", entry.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package upload
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStore(t *testing.T) {
+	inst := &instance.Instance{Domain: "upload.example.net"}
+	store := newMemStore()
+
+	s := &Session{ID: "abc", Size: 10, ExpiresAt: time.Now().Add(TTL)}
+	require.NoError(t, store.Save(inst, s))
+	s.Offset = 5
+
+	got, err := store.Get(inst, "abc")
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, got.Offset)
+
+	other := &instance.Instance{Domain: "other.example.net"}
+	_, err = store.Get(other, "abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Delete(inst, "abc"))
+	_, err = store.Get(inst, "abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	expired := &Session{ID: "old", ExpiresAt: time.Now().Add(-time.Second)}
+	require.NoError(t, store.Save(inst, expired))
+	_, err = store.Get(inst, "old")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestWriteChunk(t *testing.T) {
+	config.UseTestFile(t)
+	config.GetConfig().Fs.UploadsDir = t.TempDir()
+	inst := &instance.Instance{Domain: "chunks.example.net"}
+	s := &Session{ID: "chunked", Size: 10}
+	require.NoError(t, os.WriteFile(chunksPath(inst, s.ID), nil, 0600))
+
+	n, err := writeChunk(inst, s, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+	s.Offset += n
+
+	// The bytes after the offset are discarded by the next chunk
+	f, err := os.OpenFile(chunksPath(inst, s.ID), os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("garbage")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	n, err = writeChunk(inst, s, strings.NewReader("world!"))
+	assert.ErrorIs(t, err, ErrExceedsSize)
+	assert.EqualValues(t, 0, n)
+
+	n, err = writeChunk(inst, s, strings.NewReader("world"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, n)
+
+	content, err := os.ReadFile(chunksPath(inst, s.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "helloworld", string(content))
+}
@@ -0,0 +1,36 @@
+package useraction
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/notification"
+	"github.com/cozy/cozy-stack/model/notification/center"
+	"github.com/cozy/cozy-stack/pkg/consts"
+)
+
+// Notify sends a notification to the user for an action that has just been
+// opened. The name is the name of the konnector, as displayed to the user.
+func Notify(inst *instance.Instance, action *UserAction, name string) error {
+	title := inst.Translate("Notifications Konnector Action Title", name)
+	message := inst.Translate("Notifications Konnector Action "+action.Kind, name)
+	fix := inst.Translate("Notifications Konnector Action Fix")
+	n := &notification.Notification{
+		Title:      title,
+		Message:    message,
+		Slug:       consts.HomeSlug,
+		CategoryID: action.AccountID,
+		Content:    fmt.Sprintf("%s\n\n%s: %s\n", message, fix, action.DeepLink),
+		ContentHTML: fmt.Sprintf(`<p>%s</p><p><a href="%s">%s</a></p>`,
+			html.EscapeString(message), html.EscapeString(action.DeepLink), html.EscapeString(fix)),
+		Data: map[string]interface{}{
+			// For mobile push notification
+			"appName":      "",
+			"redirectLink": consts.HomeSlug + "/#/connected/" + action.Konnector + "/accounts/" + action.AccountID,
+			"kind":         action.Kind,
+		},
+		PreferredChannels: []string{"mobile"},
+	}
+	return center.PushStack(inst.DomainName(), center.NotificationKonnectorAction, n)
+}
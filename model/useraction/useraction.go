@@ -0,0 +1,295 @@
+// Package useraction is the action center for the konnectors: when a
+// konnector fails with an error that only the user can fix (the password has
+// changed, new terms must be accepted on the vendor website, etc.), a
+// persistent "action required" document is created for its account, with a
+// deep link to fix it. The action is resolved automatically on the next
+// successful execution of the konnector.
+package useraction
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// The kinds of actions required from the user.
+const (
+	// KindPasswordChanged is used when the konnector cannot log in with the
+	// credentials of the account.
+	KindPasswordChanged = "password_changed"
+	// KindNewTerms is used when new terms must be accepted on the vendor
+	// website.
+	KindNewTerms = "new_terms"
+	// KindReconnect is used when the account must be connected again (OAuth
+	// tokens revoked, two-factor authentication expired, etc.).
+	KindReconnect = "reconnect"
+	// KindVendorAction is used when the user must do something on the vendor
+	// website.
+	KindVendorAction = "vendor_action"
+)
+
+// The status of an action.
+const (
+	StatusOpen      = "open"
+	StatusResolved  = "resolved"
+	StatusDismissed = "dismissed"
+)
+
+// The reasons for an action to be resolved.
+const (
+	ResolvedByKonnector      = "konnector"
+	ResolvedByUser           = "user"
+	ResolvedByAccountDeleted = "account_deleted"
+)
+
+var (
+	// ErrNotOpen is used when trying to dismiss an action that is not open.
+	ErrNotOpen = errors.New("the action is not open")
+	// ErrInvalidStatus is used when the status is unknown.
+	ErrInvalidStatus = errors.New("invalid status")
+)
+
+// UserAction is an action required from the user to fix a konnector. There
+// is at most one document per account, and its identifier is the identifier
+// of the account.
+type UserAction struct {
+	DocID       string     `json:"_id,omitempty"`
+	DocRev      string     `json:"_rev,omitempty"`
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	Konnector   string     `json:"konnector"`
+	AccountID   string     `json:"account"`
+	TriggerID   string     `json:"trigger_id,omitempty"`
+	Error       string     `json:"error"`
+	DeepLink    string     `json:"deep_link"`
+	VendorLink  string     `json:"vendor_link,omitempty"`
+	Occurrences int        `json:"occurrences"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy  string     `json:"resolved_by,omitempty"`
+}
+
+// ID implements the couchdb.Doc interface
+func (a *UserAction) ID() string { return a.DocID }
+
+// Rev implements the couchdb.Doc interface
+func (a *UserAction) Rev() string { return a.DocRev }
+
+// DocType implements the couchdb.Doc interface
+func (a *UserAction) DocType() string { return consts.UserActions }
+
+// Clone implements the couchdb.Doc interface
+func (a *UserAction) Clone() couchdb.Doc {
+	cloned := *a
+	if a.ResolvedAt != nil {
+		at := *a.ResolvedAt
+		cloned.ResolvedAt = &at
+	}
+	return &cloned
+}
+
+// SetID implements the couchdb.Doc interface
+func (a *UserAction) SetID(id string) { a.DocID = id }
+
+// SetRev implements the couchdb.Doc interface
+func (a *UserAction) SetRev(rev string) { a.DocRev = rev }
+
+// Relationships implements the jsonapi.Object interface
+func (a *UserAction) Relationships() jsonapi.RelationshipMap { return nil }
+
+// Included implements the jsonapi.Object interface
+func (a *UserAction) Included() []jsonapi.Object { return nil }
+
+// Links implements the jsonapi.Object interface
+func (a *UserAction) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/user-actions/" + a.DocID}
+}
+
+// Fetch implements the permission.Fetcher interface
+func (a *UserAction) Fetch(field string) []string {
+	switch field {
+	case "konnector":
+		return []string{a.Konnector}
+	case "account":
+		return []string{a.AccountID}
+	case "kind":
+		return []string{a.Kind}
+	}
+	return nil
+}
+
+// IsOpen returns true if the action is still waiting for the user.
+func (a *UserAction) IsOpen() bool { return a.Status == StatusOpen }
+
+// KindFromError returns the kind of action required from the user for the
+// error of a konnector, or an empty string if the user cannot fix it (the
+// vendor website is down for example).
+func KindFromError(msg string) string {
+	code := strings.TrimSpace(msg)
+	if i := strings.IndexAny(code, " :"); i >= 0 {
+		code = code[:i]
+	}
+	switch {
+	case strings.HasPrefix(code, "LOGIN_FAILED"):
+		return KindPasswordChanged
+	case code == "USER_ACTION_NEEDED.CGU_FORM":
+		return KindNewTerms
+	case code == "USER_ACTION_NEEDED.OAUTH_OUTDATED",
+		code == "USER_ACTION_NEEDED.TWOFA_EXPIRED",
+		code == "USER_ACTION_NEEDED.WEBAUTH_REQUIRED",
+		code == "USER_ACTION_NEEDED.SCA_REQUIRED",
+		code == "USER_ACTION_NEEDED.PERMISSIONS_CHANGED":
+		return KindReconnect
+	case strings.HasPrefix(code, "USER_ACTION_NEEDED"):
+		return KindVendorAction
+	}
+	return ""
+}
+
+// Failure is the error of a konnector execution for an account.
+type Failure struct {
+	Konnector  string
+	AccountID  string
+	TriggerID  string
+	Error      string
+	VendorLink string
+}
+
+// Record creates or updates the action for the account of a failed konnector.
+// It returns nil if the error does not require an action from the user. The
+// opened flag is true when the action was not already open: it is the time to
+// notify the user.
+func Record(inst *instance.Instance, f Failure) (action *UserAction, opened bool, err error) {
+	kind := KindFromError(f.Error)
+	if kind == "" || f.AccountID == "" {
+		return nil, false, nil
+	}
+	now := time.Now().UTC()
+	action, err = Find(inst, f.AccountID)
+	if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+		return nil, false, err
+	}
+	if action == nil {
+		action = &UserAction{DocID: f.AccountID, CreatedAt: now}
+	}
+	if action.Status == StatusDismissed && action.Kind == kind {
+		// The user has already said that they don't want to see it
+		action.Occurrences++
+		action.Error = f.Error
+		action.UpdatedAt = now
+		if err := save(inst, action); err != nil {
+			return nil, false, err
+		}
+		return action, false, nil
+	}
+	opened = !action.IsOpen() || action.Kind != kind
+	if opened {
+		action.Occurrences = 0
+		action.ResolvedAt = nil
+		action.ResolvedBy = ""
+		if !action.IsOpen() {
+			action.CreatedAt = now
+		}
+	}
+	action.Kind = kind
+	action.Status = StatusOpen
+	action.Konnector = f.Konnector
+	action.AccountID = f.AccountID
+	action.TriggerID = f.TriggerID
+	action.Error = f.Error
+	action.VendorLink = f.VendorLink
+	action.DeepLink = deepLink(inst, f.Konnector, f.AccountID)
+	action.Occurrences++
+	action.UpdatedAt = now
+	if err := save(inst, action); err != nil {
+		return nil, false, err
+	}
+	return action, opened, nil
+}
+
+// Resolve closes the open or dismissed action for the account, if any.
+func Resolve(db prefixer.Prefixer, accountID, by string) error {
+	action, err := Find(db, accountID)
+	if err != nil {
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return nil
+		}
+		return err
+	}
+	if action.Status == StatusResolved {
+		return nil
+	}
+	now := time.Now().UTC()
+	action.Status = StatusResolved
+	action.ResolvedAt = &now
+	action.ResolvedBy = by
+	action.UpdatedAt = now
+	return couchdb.UpdateDoc(db, action)
+}
+
+// Dismiss is used when the user does not want to see the action anymore. It
+// will be opened again if the konnector fails with another kind of error, or
+// fails again after a success.
+func Dismiss(db prefixer.Prefixer, action *UserAction) error {
+	if !action.IsOpen() {
+		return ErrNotOpen
+	}
+	now := time.Now().UTC()
+	action.Status = StatusDismissed
+	action.ResolvedAt = &now
+	action.ResolvedBy = ResolvedByUser
+	action.UpdatedAt = now
+	return couchdb.UpdateDoc(db, action)
+}
+
+// Find returns the action for the given account.
+func Find(db prefixer.Prefixer, accountID string) (*UserAction, error) {
+	doc := &UserAction{}
+	if err := couchdb.GetDoc(db, consts.UserActions, accountID, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// List returns the actions of the instance, filtered by status if it is not
+// empty.
+func List(db prefixer.Prefixer, status string) ([]*UserAction, error) {
+	var docs []*UserAction
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	err := couchdb.GetAllDocs(db, consts.UserActions, req, &docs)
+	if couchdb.IsNoDatabaseError(err) {
+		return []*UserAction{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	filtered := docs[:0]
+	for _, doc := range docs {
+		if status == "" || doc.Status == status {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered, nil
+}
+
+func save(db prefixer.Prefixer, action *UserAction) error {
+	if action.DocRev == "" {
+		return couchdb.CreateNamedDocWithDB(db, action)
+	}
+	return couchdb.UpdateDoc(db, action)
+}
+
+// deepLink returns the link to the page of the account in the home app,
+// where the user can fix it.
+func deepLink(inst *instance.Instance, slug, accountID string) string {
+	u := inst.SubDomain(consts.HomeSlug)
+	u.Fragment = "/connected/" + slug + "/accounts/" + accountID
+	return u.String()
+}
@@ -0,0 +1,28 @@
+package useraction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindFromError(t *testing.T) {
+	assert.Equal(t, KindPasswordChanged, KindFromError("LOGIN_FAILED"))
+	assert.Equal(t, KindPasswordChanged, KindFromError("LOGIN_FAILED.TOO_MANY_ATTEMPTS"))
+	assert.Equal(t, KindNewTerms, KindFromError("USER_ACTION_NEEDED.CGU_FORM"))
+	assert.Equal(t, KindReconnect, KindFromError("USER_ACTION_NEEDED.OAUTH_OUTDATED"))
+	assert.Equal(t, KindReconnect, KindFromError("USER_ACTION_NEEDED.SCA_REQUIRED: please confirm"))
+	assert.Equal(t, KindVendorAction, KindFromError("USER_ACTION_NEEDED"))
+	assert.Equal(t, KindVendorAction, KindFromError("USER_ACTION_NEEDED.ACCOUNT_REMOVED"))
+	assert.Equal(t, "", KindFromError("VENDOR_DOWN"))
+	assert.Equal(t, "", KindFromError("exit status 1"))
+	assert.Equal(t, "", KindFromError(""))
+}
+
+func TestFetch(t *testing.T) {
+	a := &UserAction{Konnector: "orange", AccountID: "123", Kind: KindNewTerms}
+	assert.Equal(t, []string{"orange"}, a.Fetch("konnector"))
+	assert.Equal(t, []string{"123"}, a.Fetch("account"))
+	assert.Equal(t, []string{KindNewTerms}, a.Fetch("kind"))
+	assert.Nil(t, a.Fetch("error"))
+}
@@ -135,6 +135,9 @@ func (a *Archive) Serve(fs VFS, w http.ResponseWriter) error {
 				_, err = zw.Create(a.Name + "/" + name + "/")
 				return err
 			}
+			if !CanDownload(file) {
+				return nil
+			}
 			header := &zip.FileHeader{
 				Name:     a.Name + "/" + name,
 				Method:   zip.Deflate,
@@ -0,0 +1,89 @@
+package vfs
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// Blob is the document used to count the references to a storage object
+// shared by the files and versions with the same content, when the
+// deduplication is enabled. The storage object is deleted when there are no
+// more references to it.
+type Blob struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+	Size   int64  `json:"size,string"`
+	Refs   int    `json:"refs"`
+}
+
+// ID returns the blob qualified identifier
+func (b *Blob) ID() string { return b.DocID }
+
+// Rev returns the blob revision
+func (b *Blob) Rev() string { return b.DocRev }
+
+// DocType returns the blob document type
+func (b *Blob) DocType() string { return consts.FilesBlobs }
+
+// Clone implements couchdb.Doc
+func (b *Blob) Clone() couchdb.Doc {
+	cloned := *b
+	return &cloned
+}
+
+// SetID changes the blob qualified identifier
+func (b *Blob) SetID(id string) { b.DocID = id }
+
+// SetRev changes the blob revision
+func (b *Blob) SetRev(rev string) { b.DocRev = rev }
+
+// BlobID returns the identifier of the blob for a content with the given md5
+// checksum and size. The size is used in addition to the checksum to make the
+// collisions harder.
+func BlobID(md5sum []byte, size int64) string {
+	return hex.EncodeToString(md5sum) + "-" + strconv.FormatInt(size, 10)
+}
+
+// DedupStats gives some numbers about the deduplication of the files of an
+// instance.
+type DedupStats struct {
+	// Blobs is the number of storage objects used by the deduplicated files
+	// and versions.
+	Blobs int `json:"blobs"`
+	// References is the number of files and versions that use these storage
+	// objects.
+	References int `json:"references"`
+	// StoredBytes is the size of these storage objects.
+	StoredBytes int64 `json:"stored_bytes"`
+	// SavedBytes is the size that has not been stored thanks to the
+	// deduplication.
+	SavedBytes int64 `json:"saved_bytes"`
+}
+
+// GetDedupStats computes the statistics of the deduplication for the given
+// instance.
+func GetDedupStats(db prefixer.Prefixer) (*DedupStats, error) {
+	stats := &DedupStats{}
+	err := couchdb.ForeachDocs(db, consts.FilesBlobs, func(_ string, data json.RawMessage) error {
+		var blob Blob
+		if err := json.Unmarshal(data, &blob); err != nil {
+			return err
+		}
+		stats.Blobs++
+		stats.References += blob.Refs
+		stats.StoredBytes += blob.Size
+		if blob.Refs > 1 {
+			stats.SavedBytes += int64(blob.Refs-1) * blob.Size
+		}
+		return nil
+	})
+	if err != nil && !couchdb.IsNoDatabaseError(err) {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -233,7 +233,16 @@ func (c *couchdbIndexer) UpdateFileDoc(olddoc, newdoc *FileDoc) error {
 
 	newdoc.SetID(olddoc.ID())
 	newdoc.SetRev(olddoc.Rev())
-	return couchdb.UpdateDocWithOld(c.db, newdoc, olddoc)
+	if err := couchdb.UpdateDocWithOld(c.db, newdoc, olddoc); err != nil {
+		return err
+	}
+
+	// The shortcuts for a sharing are also referenced by the sharing, but
+	// they are not the main file of a sharing.
+	if newdoc.Class != "shortcut" && !newdoc.Trashed && olddoc.DocName != newdoc.DocName {
+		c.checkRenamedIsShared(newdoc.ReferencedBy, newdoc.DocName)
+	}
+	return nil
 }
 
 var DeleteNote = func(db prefixer.Prefixer, noteID string) {}
@@ -284,6 +293,10 @@ func (c *couchdbIndexer) UpdateDirDoc(olddoc, newdoc *DirDoc) error {
 		return err
 	}
 
+	if !newTrashed && olddoc.DocName != newdoc.DocName {
+		c.checkRenamedIsShared(newdoc.ReferencedBy, newdoc.DocName)
+	}
+
 	if isRestored {
 		if err := c.setTrashedForFilesInsideDir(newdoc, false); err != nil {
 			return err
@@ -826,6 +839,22 @@ func (c *couchdbIndexer) revokeSharing(sharingID string) {
 	RevokeSharingFunc(c.db, sharingID)
 }
 
+// RenameSharingFunc does nothing. It will be overridden from the sharing
+// package.
+var RenameSharingFunc = func(db prefixer.Prefixer, sharingID, name string) {}
+
+// checkRenamedIsShared will look for a renamed file or directory if it was
+// the main file/dir of a sharing. If it is the case, the sharing module is
+// called to update the description of the sharing and the shortcuts of the
+// recipients.
+func (c *couchdbIndexer) checkRenamedIsShared(refs []couchdb.DocReference, name string) {
+	for _, ref := range refs {
+		if ref.Type == consts.Sharings {
+			RenameSharingFunc(c.db, ref.ID, name)
+		}
+	}
+}
+
 func (c *couchdbIndexer) CheckIndexIntegrity(accumulate func(*FsckLog), failFast bool) error {
 	tree, err := c.BuildTree()
 	if err != nil {
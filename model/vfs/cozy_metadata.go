@@ -114,6 +114,12 @@ func (fcm *FilesCozyMetadata) ToJSONDoc() map[string]interface{} {
 	if fcm.CreatedOn != "" {
 		doc["createdOn"] = fcm.CreatedOn
 	}
+	if fcm.CreatedByUser != "" {
+		doc["createdByUser"] = fcm.CreatedByUser
+	}
+	if fcm.UpdatedByUser != "" {
+		doc["updatedByUser"] = fcm.UpdatedByUser
+	}
 
 	doc["updatedAt"] = fcm.UpdatedAt
 	if len(fcm.UpdatedByApps) > 0 {
@@ -0,0 +1,117 @@
+package vfs_test
+
+import (
+	"context"
+	"crypto/md5"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwiftDeduplication(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+
+	conf := config.GetConfig()
+	conf.Fs.Deduplication = true
+	conf.Fs.Versioning.MaxNumberToKeep = 20
+	conf.Fs.Versioning.MinDelayBetweenTwoVersions = 0
+	fs := makeSwiftFS(t)
+	require.NoError(t, couchdb.ResetDB(fs, consts.FilesBlobs))
+	require.NoError(t, couchdb.ResetDB(fs, consts.FilesVersions))
+	t.Cleanup(func() {
+		_ = couchdb.DeleteDB(fs, consts.FilesBlobs)
+		_ = couchdb.DeleteDB(fs, consts.FilesVersions)
+	})
+
+	blobID := func(content string) string {
+		sum := md5.Sum([]byte(content))
+		return vfs.BlobID(sum[:], int64(len(content)))
+	}
+	refs := func(t *testing.T, content string) int {
+		blob := &vfs.Blob{}
+		err := couchdb.GetDoc(fs, consts.FilesBlobs, blobID(content), blob)
+		if couchdb.IsNotFoundError(err) {
+			return 0
+		}
+		require.NoError(t, err)
+		return blob.Refs
+	}
+	blobExists := func(content string) bool {
+		container := "cozy-v3-" + fs.DBPrefix()
+		_, _, err := config.GetSwiftConnection().Object(context.Background(), container, "blobs/"+blobID(content))
+		return err == nil
+	}
+	write := func(t *testing.T, doc, olddoc *vfs.FileDoc, content string) *vfs.FileDoc {
+		f, err := fs.CreateFile(doc, olddoc)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		doc, err = fs.FileByID(doc.ID())
+		require.NoError(t, err)
+		return doc
+	}
+	overwrite := func(t *testing.T, olddoc *vfs.FileDoc, content string) *vfs.FileDoc {
+		newdoc := olddoc.Clone().(*vfs.FileDoc)
+		newdoc.ByteSize = int64(len(content))
+		newdoc.MD5Sum = nil
+		return write(t, newdoc, olddoc, content)
+	}
+
+	contentA := "content of the version A"
+	contentB := "content of the version B"
+
+	doc, err := vfs.NewFileDoc("dedup.txt", consts.RootDirID, int64(len(contentA)), nil, "text/plain", "text", time.Now(), false, false, false, nil)
+	require.NoError(t, err)
+	file := write(t, doc, nil, contentA)
+	assert.Equal(t, 1, refs(t, contentA))
+	assert.True(t, blobExists(contentA))
+
+	// A copy shares the blob, with its own internal ID
+	copied := vfs.CreateFileDocCopy(file, consts.RootDirID, "dedup-copy.txt")
+	require.NoError(t, fs.CopyFile(file, copied))
+	assert.Equal(t, 2, refs(t, contentA))
+	assert.NotEqual(t, file.InternalID, copied.InternalID)
+
+	// The old content is kept by a version
+	file = overwrite(t, file, contentB)
+	assert.Equal(t, 2, refs(t, contentA))
+	assert.Equal(t, 1, refs(t, contentB))
+
+	// Several versions of the same file can have the same content
+	file = overwrite(t, file, contentA)
+	file = overwrite(t, file, contentB)
+	versions, err := vfs.VersionsFor(fs, file.ID())
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+	assert.Equal(t, 3, refs(t, contentA))
+	assert.Equal(t, 2, refs(t, contentB))
+
+	// Trashing a file doesn't change its references
+	copied, err = vfs.TrashFile(fs, copied)
+	require.NoError(t, err)
+	assert.Equal(t, 3, refs(t, contentA))
+
+	// The blob is deleted only when its last reference is released
+	require.NoError(t, fs.DestroyFile(copied))
+	assert.Equal(t, 2, refs(t, contentA))
+	assert.True(t, blobExists(contentA))
+
+	require.NoError(t, fs.DestroyFile(file))
+	assert.Equal(t, 0, refs(t, contentA))
+	assert.Equal(t, 0, refs(t, contentB))
+	assert.False(t, blobExists(contentA))
+	assert.False(t, blobExists(contentB))
+}
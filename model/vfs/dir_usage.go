@@ -0,0 +1,391 @@
+package vfs
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// maxUsageConflicts is the number of times that the update of a DirUsage is
+// retried when there is a conflict.
+const maxUsageConflicts = 5
+
+// DirUsage is the document used to keep the size of the files inside a
+// directory with a quota. It has the same identifier as the directory, and
+// its size is updated incrementally when the files are changed, so that it is
+// not needed to compute it again for each upload.
+type DirUsage struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+	Size   int64  `json:"size"`
+}
+
+// ID returns the directory qualified identifier
+func (u *DirUsage) ID() string { return u.DocID }
+
+// Rev returns the usage revision
+func (u *DirUsage) Rev() string { return u.DocRev }
+
+// DocType returns the usage document type
+func (u *DirUsage) DocType() string { return consts.DirUsages }
+
+// Clone implements couchdb.Doc
+func (u *DirUsage) Clone() couchdb.Doc {
+	cloned := *u
+	return &cloned
+}
+
+// SetID changes the usage qualified identifier
+func (u *DirUsage) SetID(id string) { u.DocID = id }
+
+// SetRev changes the usage revision
+func (u *DirUsage) SetRev(rev string) { u.DocRev = rev }
+
+// dirQuota is a directory with a quota, and its usage.
+type dirQuota struct {
+	dir   *DirDoc
+	usage *DirUsage
+}
+
+// contains returns true if the given path is the path of the directory with
+// a quota, or the path of one of its descendants.
+func (q *dirQuota) contains(fullpath string) bool {
+	if fullpath == "" {
+		return false
+	}
+	return fullpath == q.dir.Fullpath || strings.HasPrefix(fullpath, q.dir.Fullpath+"/")
+}
+
+// remaining returns the number of bytes that can still be added in the
+// directory.
+func (q *dirQuota) remaining() int64 {
+	if left := q.dir.Quota - q.usage.Size; left > 0 {
+		return left
+	}
+	return 0
+}
+
+// listDirQuotas returns the directories of the VFS that have a quota.
+func listDirQuotas(db prefixer.Prefixer, indexer Indexer) ([]*dirQuota, error) {
+	var usages []*DirUsage
+	err := couchdb.GetAllDocs(db, consts.DirUsages, &couchdb.AllDocsRequest{}, &usages)
+	if err != nil {
+		if couchdb.IsNoDatabaseError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	quotas := make([]*dirQuota, 0, len(usages))
+	for _, usage := range usages {
+		dir, err := indexer.DirByID(usage.DocID)
+		if err != nil {
+			if couchdb.IsNotFoundError(err) || os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if dir.Quota > 0 {
+			quotas = append(quotas, &dirQuota{dir: dir, usage: usage})
+		}
+	}
+	return quotas, nil
+}
+
+// DirUsageSize returns the size of the files inside the directory, including
+// those in subdirectories. For a directory with a quota, this size is the one
+// kept incrementally in its DirUsage document.
+func DirUsageSize(fs VFS, dir *DirDoc) (int64, error) {
+	if dir.Quota <= 0 {
+		return fs.DirSize(dir)
+	}
+	usage := &DirUsage{}
+	err := couchdb.GetDoc(fs, consts.DirUsages, dir.DocID, usage)
+	if couchdb.IsNotFoundError(err) {
+		if usage, err = initDirUsage(fs, dir); err != nil {
+			return 0, err
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return usage.Size, nil
+}
+
+// SetDirQuota changes the quota of a directory. A quota of zero removes the
+// quota. The usage of the directory is computed when the quota is set, and
+// then it is updated incrementally.
+func SetDirQuota(fs VFS, olddoc *DirDoc, quota int64) (*DirDoc, error) {
+	id := olddoc.ID()
+	if id == consts.RootDirID || id == consts.TrashDirID || quota < 0 {
+		return nil, os.ErrInvalid
+	}
+	if strings.HasPrefix(olddoc.Fullpath, TrashDirName) {
+		return nil, ErrFileInTrash
+	}
+
+	newdoc := olddoc.Clone().(*DirDoc)
+	newdoc.Quota = quota
+	if err := fs.UpdateDirDoc(olddoc, newdoc); err != nil {
+		return nil, err
+	}
+
+	if quota == 0 {
+		usage := &DirUsage{}
+		err := couchdb.GetDoc(fs, consts.DirUsages, id, usage)
+		if err == nil {
+			err = couchdb.DeleteDoc(fs, usage)
+		}
+		if err != nil && !couchdb.IsNotFoundError(err) {
+			return nil, err
+		}
+		return newdoc, nil
+	}
+
+	if _, err := initDirUsage(fs, newdoc); err != nil {
+		return nil, err
+	}
+	return newdoc, nil
+}
+
+// initDirUsage computes the size of a directory, and saves it in its DirUsage
+// document.
+func initDirUsage(fs VFS, dir *DirDoc) (*DirUsage, error) {
+	size, err := fs.DirSize(dir)
+	if err != nil {
+		return nil, err
+	}
+	usage := &DirUsage{}
+	err = couchdb.GetDoc(fs, consts.DirUsages, dir.DocID, usage)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return nil, err
+	}
+	usage.Size = size
+	if usage.DocRev != "" {
+		err = couchdb.UpdateDoc(fs, usage)
+	} else {
+		usage.DocID = dir.DocID
+		err = couchdb.CreateNamedDocWithDB(fs, usage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// checkDirQuota returns the number of bytes that can be added in the
+// directory with the given path, or -1 if there is no limit. An error is
+// returned if size is greater than this number.
+func checkDirQuota(fs VFS, dirpath string, size int64) (int64, error) {
+	quotas, err := listDirQuotas(fs, fs)
+	if err != nil {
+		return 0, err
+	}
+	maxsize := int64(-1)
+	for _, q := range quotas {
+		if !q.contains(dirpath) {
+			continue
+		}
+		if left := q.remaining(); maxsize < 0 || left < maxsize {
+			maxsize = left
+		}
+	}
+	if maxsize >= 0 && size > maxsize {
+		return 0, ErrDirQuotaExceeded
+	}
+	return maxsize, nil
+}
+
+// checkDirQuotaForMove checks that the files of a file or directory moved
+// from the oldpath directory to the newpath directory don't exceed the quota
+// of the directories that contain newpath. The size is computed only if
+// there is a quota to check.
+func checkDirQuotaForMove(fs VFS, oldpath, newpath string, size func() (int64, error)) error {
+	quotas, err := listDirQuotas(fs, fs)
+	if err != nil || len(quotas) == 0 {
+		return err
+	}
+	var checked []*dirQuota
+	for _, q := range quotas {
+		if q.contains(newpath) && !q.contains(oldpath) {
+			checked = append(checked, q)
+		}
+	}
+	if len(checked) == 0 {
+		return nil
+	}
+	n, err := size()
+	if err != nil {
+		return err
+	}
+	for _, q := range checked {
+		if n > q.remaining() {
+			return ErrDirQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// usageState is the path of the parent directory and the size of a file or
+// directory in a realtime event.
+type usageState struct {
+	path string
+	size int64
+}
+
+// UpdateDirUsages applies the change of a file or directory, as published in
+// a realtime event, to the usage of the directories with a quota. For a
+// directory, only a move to another parent changes the usages, and its size
+// is computed from the index.
+func UpdateDirUsages(db prefixer.Prefixer, verb string, doc, olddoc realtime.Doc) error {
+	if doc == nil || doc.DocType() != consts.Files || verb == realtime.EventNotify {
+		return nil
+	}
+	indexer := NewCouchdbIndexer(db)
+	newdir, newfile := eventDirOrFile(doc)
+	olddir, oldfile := eventDirOrFile(olddoc)
+
+	var before, after usageState
+	switch {
+	case newfile != nil:
+		if verb == realtime.EventDelete {
+			before = fileUsageState(indexer, newfile)
+		} else {
+			after = fileUsageState(indexer, newfile)
+			if oldfile != nil {
+				before = fileUsageState(indexer, oldfile)
+			} else if verb == realtime.EventUpdate {
+				return nil
+			}
+		}
+	case newdir != nil:
+		if verb == realtime.EventDelete {
+			return removeDirUsage(db, newdir.DocID)
+		}
+		if verb != realtime.EventUpdate || olddir == nil || olddir.DirID == newdir.DirID {
+			return nil
+		}
+		// When a directory is moved to or from the trash, the files inside it
+		// are updated with their own events.
+		if strings.HasPrefix(olddir.Fullpath, TrashDirName) || strings.HasPrefix(newdir.Fullpath, TrashDirName) {
+			return nil
+		}
+		quotas, err := listDirQuotas(db, indexer)
+		if err != nil || len(quotas) == 0 {
+			return err
+		}
+		size, err := indexer.DirSize(newdir)
+		if err != nil {
+			return err
+		}
+		before = usageState{path: path.Dir(olddir.Fullpath), size: size}
+		after = usageState{path: path.Dir(newdir.Fullpath), size: size}
+		return applyUsageChange(db, quotas, before, after)
+	default:
+		return nil
+	}
+
+	if before == after {
+		return nil
+	}
+	quotas, err := listDirQuotas(db, indexer)
+	if err != nil {
+		return err
+	}
+	return applyUsageChange(db, quotas, before, after)
+}
+
+func applyUsageChange(db prefixer.Prefixer, quotas []*dirQuota, before, after usageState) error {
+	for _, q := range quotas {
+		var delta int64
+		if q.contains(after.path) {
+			delta += after.size
+		}
+		if q.contains(before.path) {
+			delta -= before.size
+		}
+		if delta == 0 {
+			continue
+		}
+		if err := addDirUsage(db, q.usage, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDirUsage adds delta to the size of the usage, and retries with a fresh
+// revision of the document if there is a conflict.
+func addDirUsage(db prefixer.Prefixer, usage *DirUsage, delta int64) error {
+	var err error
+	for i := 0; i < maxUsageConflicts; i++ {
+		usage.Size += delta
+		if usage.Size < 0 {
+			usage.Size = 0
+		}
+		err = couchdb.UpdateDoc(db, usage)
+		if !couchdb.IsConflictError(err) {
+			return err
+		}
+		id := usage.DocID
+		usage = &DirUsage{}
+		if err = couchdb.GetDoc(db, consts.DirUsages, id, usage); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func removeDirUsage(db prefixer.Prefixer, dirID string) error {
+	usage := &DirUsage{}
+	err := couchdb.GetDoc(db, consts.DirUsages, dirID, usage)
+	if err == nil {
+		err = couchdb.DeleteDoc(db, usage)
+	}
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return nil
+	}
+	return err
+}
+
+// fileUsageState returns the path and size of a file for its usage. The
+// trashed files are not counted.
+func fileUsageState(indexer Indexer, file *FileDoc) usageState {
+	if file.Trashed {
+		return usageState{}
+	}
+	fullpath, err := file.Path(indexer)
+	if err != nil {
+		return usageState{}
+	}
+	return usageState{path: path.Dir(fullpath), size: file.ByteSize}
+}
+
+// eventDirOrFile returns the directory or file of a realtime event. The
+// document can be a JSONDoc when the event comes from another stack, and it
+// is then converted.
+func eventDirOrFile(doc realtime.Doc) (*DirDoc, *FileDoc) {
+	switch d := doc.(type) {
+	case nil:
+		return nil, nil
+	case *DirDoc:
+		return d, nil
+	case *FileDoc:
+		return nil, d
+	}
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil
+	}
+	var dirOrFile DirOrFileDoc
+	if err := json.Unmarshal(buf, &dirOrFile); err != nil || dirOrFile.DirDoc == nil {
+		return nil, nil
+	}
+	dir, file := dirOrFile.Refine()
+	return dir, file
+}
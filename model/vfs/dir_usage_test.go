@@ -0,0 +1,57 @@
+package vfs
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirQuota(t *testing.T) {
+	q := &dirQuota{
+		dir:   &DirDoc{Fullpath: "/Projects", Quota: 100},
+		usage: &DirUsage{Size: 60},
+	}
+	assert.True(t, q.contains("/Projects"))
+	assert.True(t, q.contains("/Projects/2024/Reports"))
+	assert.False(t, q.contains("/Projects2"))
+	assert.False(t, q.contains("/"))
+	assert.False(t, q.contains(""))
+	assert.EqualValues(t, 40, q.remaining())
+
+	q.usage.Size = 120
+	assert.EqualValues(t, 0, q.remaining())
+}
+
+func TestEventDirOrFile(t *testing.T) {
+	dir, file := eventDirOrFile(nil)
+	assert.Nil(t, dir)
+	assert.Nil(t, file)
+
+	doc := couchdb.JSONDoc{
+		Type: consts.Files,
+		M: map[string]interface{}{
+			"_id":    "f1",
+			"type":   consts.FileType,
+			"name":   "report.pdf",
+			"dir_id": "d1",
+			"size":   "42",
+		},
+	}
+	dir, file = eventDirOrFile(&doc)
+	assert.Nil(t, dir)
+	if assert.NotNil(t, file) {
+		assert.Equal(t, "d1", file.DirID)
+		assert.EqualValues(t, 42, file.ByteSize)
+	}
+
+	doc.M["type"] = consts.DirType
+	doc.M["path"] = "/Projects"
+	delete(doc.M, "size")
+	dir, file = eventDirOrFile(&doc)
+	assert.Nil(t, file)
+	if assert.NotNil(t, dir) {
+		assert.Equal(t, "/Projects", dir.Fullpath)
+	}
+}
@@ -43,6 +43,11 @@ type DirDoc struct {
 
 	Metadata     Metadata           `json:"metadata,omitempty"`
 	CozyMetadata *FilesCozyMetadata `json:"cozyMetadata,omitempty"`
+
+	// Quota is the maximal number of bytes for the files inside this
+	// directory, including those in its subdirectories. Zero means that
+	// there is no quota.
+	Quota int64 `json:"quota,omitempty"`
 }
 
 // ID returns the directory qualified identifier
@@ -246,6 +251,10 @@ func ModifyDirMetadata(fs VFS, olddoc *DirDoc, patch *DocPatch) (*DirDoc, error)
 			return nil, ErrFileInTrash
 		}
 		newdoc, err = NewDirDoc(fs, *patch.Name, *patch.DirID, *patch.Tags)
+		if err == nil {
+			size := func() (int64, error) { return fs.DirSize(olddoc) }
+			err = checkDirQuotaForMove(fs, path.Dir(olddoc.Fullpath), path.Dir(newdoc.Fullpath), size)
+		}
 	} else {
 		newdoc, err = NewDirDocWithPath(*patch.Name, olddoc.DirID, path.Dir(olddoc.Fullpath), *patch.Tags)
 	}
@@ -260,6 +269,7 @@ func ModifyDirMetadata(fs VFS, olddoc *DirDoc, patch *DocPatch) (*DirDoc, error)
 	newdoc.NotSynchronizedOn = olddoc.NotSynchronizedOn
 	newdoc.Metadata = olddoc.Metadata
 	newdoc.CozyMetadata = olddoc.CozyMetadata
+	newdoc.Quota = olddoc.Quota
 
 	if err = fs.UpdateDirDoc(olddoc, newdoc); err != nil {
 		return nil, err
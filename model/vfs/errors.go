@@ -44,6 +44,9 @@ var (
 	ErrWrongCouchdbState = errors.New("Wrong couchdb reduce value")
 	// ErrFileTooBig is used when there is no more space left on the filesystem
 	ErrFileTooBig = errors.New("The file is too big and exceeds the disk quota")
+	// ErrDirQuotaExceeded is used when there is no more space left in a
+	// directory with a quota
+	ErrDirQuotaExceeded = errors.New("The file is too big and exceeds the quota of the directory")
 	// ErrMaxFileSize is used when a file is larger than the filesystem's maximum file size
 	ErrMaxFileSize = errors.New("The file is too big and exceeds the filesystem maximum file size")
 	// ErrFsckFailFast is used when the FSCK is stopped by the fail-fast option
@@ -52,4 +55,7 @@ var (
 	ErrWrongToken = errors.New("Wrong download token")
 	// ErrInvalidMetadataID is used when the metadata cannot be found from a MetadatID parameter
 	ErrInvalidMetadataID = errors.New("Invalid or expired MetadataID")
+	// ErrInfectedFile is used when trying to download a file where a virus
+	// has been found
+	ErrInfectedFile = errors.New("A virus has been found in this file")
 )
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/labstack/echo/v4"
@@ -212,6 +213,19 @@ func NewFileDoc(name, dirID string, size int64, md5Sum []byte, mimeType, class s
 	return doc, nil
 }
 
+// IsInfected returns true if the antivirus has found a virus in the content
+// of the file.
+func (f *FileDoc) IsInfected() bool {
+	av, ok := f.Metadata[AntivirusKey].(map[string]interface{})
+	return ok && av["status"] == AntivirusInfected
+}
+
+// CanDownload returns false if the content of the file must not be sent to
+// the user, as a virus has been found in it.
+func CanDownload(doc *FileDoc) bool {
+	return !doc.IsInfected() || config.GetConfig().Antivirus.AllowInfectedDownloads
+}
+
 // ServeFileContent replies to a http request using the content of a
 // file given its FileDoc.
 //
@@ -225,6 +239,9 @@ func ServeFileContent(fs VFS, doc *FileDoc, version *Version, filename, disposit
 	if filename == "" {
 		filename = doc.DocName
 	}
+	if version == nil && !CanDownload(doc) {
+		return ErrInfectedFile
+	}
 	header := w.Header()
 	header.Set(echo.HeaderContentType, doc.Mime)
 	if disposition != "" {
@@ -316,6 +333,21 @@ func ModifyFileMetadata(fs VFS, olddoc *FileDoc, patch *DocPatch) (*FileDoc, err
 	newdoc.CozyMetadata = olddoc.CozyMetadata
 	newdoc.InternalID = olddoc.InternalID
 
+	if newdoc.DirID != olddoc.DirID {
+		oldpath, err := olddoc.Path(fs)
+		if err != nil {
+			return nil, err
+		}
+		newpath, err := newdoc.Path(fs)
+		if err != nil {
+			return nil, err
+		}
+		size := func() (int64, error) { return olddoc.ByteSize, nil }
+		if err = checkDirQuotaForMove(fs, path.Dir(oldpath), path.Dir(newpath), size); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = fs.UpdateFileDoc(olddoc, newdoc); err != nil {
 		return nil, err
 	}
@@ -35,6 +35,14 @@ import (
 // metadata when the extractor is improved.
 const MetadataExtractorVersion = 2
 
+const (
+	// AntivirusKey is the key in the metadata of a file for the result of the
+	// scan by the antivirus, when a virus has been found.
+	AntivirusKey = "antivirus"
+	// AntivirusInfected is the status of a file where a virus has been found.
+	AntivirusInfected = "infected"
+)
+
 // Metadata is a list of metadata specific to each mimetype:
 // id3 for music, exif for jpegs, etc.
 type Metadata map[string]interface{}
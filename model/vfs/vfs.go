@@ -926,6 +926,20 @@ func CheckAvailableDiskSpace(fs VFS, doc *FileDoc) (newsize, maxsize, capsize in
 		}
 	}
 
+	if doc.DirID != "" && doc.DirID != consts.TrashDirID {
+		fullpath, err := fs.FilePath(doc)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		dirmax, err := checkDirQuota(fs, path.Dir(fullpath), newsize)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if dirmax >= 0 && (maxsize < 0 || dirmax < maxsize) {
+			maxsize = dirmax
+		}
+	}
+
 	return newsize, maxsize, capsize, nil
 }
 
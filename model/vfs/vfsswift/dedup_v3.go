@@ -0,0 +1,216 @@
+package vfsswift
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/lock"
+)
+
+// When the deduplication is enabled, the content of a file is moved after the
+// upload to a blob object, named after its md5 checksum and size, and the
+// internal_vfs_id of the file is the blob prefix followed by the identifier
+// of the blob and a random suffix. The files and versions with the same
+// content share this object, and the number of references is counted in an
+// io.cozy.files.blobs document. The object is deleted when the last reference
+// is released. The suffix keeps the internal IDs unique, as they are also used
+// for the identifiers of the versions: a file can have several versions with
+// the same content.
+//
+// The changes of the references are made with a lock dedicated to the blobs,
+// as the trash journals are erased without the VFS lock, and the VFS lock
+// can't be taken twice.
+const (
+	blobInternalPrefix    = "blob-"
+	blobInternalSeparator = "."
+	blobObjectPrefix      = "blobs/"
+	maxBlobConflicts      = 5
+)
+
+var errBlobConflict = errors.New("too many conflicts on the blob document")
+
+// newBlobInternalID returns a new internal_vfs_id for a file or a version
+// with the content of the given blob.
+func newBlobInternalID(blobID string) string {
+	return blobInternalPrefix + blobID + blobInternalSeparator + NewInternalID()
+}
+
+// blobIDFromInternalID returns the identifier of the blob used by a file or a
+// version, if any.
+func blobIDFromInternalID(internalID string) (string, bool) {
+	if !strings.HasPrefix(internalID, blobInternalPrefix) {
+		return "", false
+	}
+	blobID := strings.TrimPrefix(internalID, blobInternalPrefix)
+	if i := strings.LastIndex(blobID, blobInternalSeparator); i >= 0 {
+		blobID = blobID[:i]
+	}
+	return blobID, true
+}
+
+// blobIDFromObjectName returns the identifier of the blob for the given
+// object name, if it is a blob object.
+func blobIDFromObjectName(objName string) (string, bool) {
+	if !strings.HasPrefix(objName, blobObjectPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(objName, blobObjectPrefix), true
+}
+
+// blobsLock returns the lock for the changes of the references to the blobs.
+func (sfs *swiftVFSV3) blobsLock() lock.ErrorRWLocker {
+	return config.Lock().ReadWrite(sfs, "vfs-blobs")
+}
+
+// shareBlob adds a reference to an existing blob. It returns false if the
+// blob does not exist.
+func (sfs *swiftVFSV3) shareBlob(blobID string) (bool, error) {
+	mu := sfs.blobsLock()
+	if err := mu.Lock(); err != nil {
+		return false, err
+	}
+	defer mu.Unlock()
+	return sfs.acquireBlob(blobID)
+}
+
+// acquireBlob adds a reference to an existing blob. It returns false if the
+// blob does not exist.
+func (sfs *swiftVFSV3) acquireBlob(blobID string) (bool, error) {
+	for i := 0; i < maxBlobConflicts; i++ {
+		blob := &vfs.Blob{}
+		err := couchdb.GetDoc(sfs, consts.FilesBlobs, blobID, blob)
+		if couchdb.IsNotFoundError(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		blob.Refs++
+		err = couchdb.UpdateDoc(sfs, blob)
+		if !couchdb.IsConflictError(err) {
+			return err == nil, err
+		}
+	}
+	return false, errBlobConflict
+}
+
+// refBlob adds a reference to a blob, and creates its document if it does not
+// exist yet.
+func (sfs *swiftVFSV3) refBlob(blobID string, size int64) error {
+	mu := sfs.blobsLock()
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+	acquired, err := sfs.acquireBlob(blobID)
+	if err != nil || acquired {
+		return err
+	}
+	blob := &vfs.Blob{DocID: blobID, Size: size, Refs: 1}
+	err = couchdb.CreateNamedDocWithDB(sfs, blob)
+	if couchdb.IsConflictError(err) {
+		_, err = sfs.acquireBlob(blobID)
+	}
+	return err
+}
+
+// releaseBlob removes a reference to a blob. It returns true when it was the
+// last reference, and the blob object can be deleted.
+func (sfs *swiftVFSV3) releaseBlob(blobID string) (bool, error) {
+	for i := 0; i < maxBlobConflicts; i++ {
+		blob := &vfs.Blob{}
+		err := couchdb.GetDoc(sfs, consts.FilesBlobs, blobID, blob)
+		if couchdb.IsNotFoundError(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		blob.Refs--
+		if blob.Refs <= 0 {
+			err = couchdb.DeleteDoc(sfs, blob)
+		} else {
+			err = couchdb.UpdateDoc(sfs, blob)
+		}
+		if !couchdb.IsConflictError(err) {
+			return err == nil && blob.Refs <= 0, err
+		}
+	}
+	return false, errBlobConflict
+}
+
+// releaseBlobs removes a reference for each blob object in the list, and
+// returns the list of the objects that can be deleted: the objects that are
+// not blobs, and the blobs without references.
+func (sfs *swiftVFSV3) releaseBlobs(objNames []string) []string {
+	toDelete := make([]string, 0, len(objNames))
+	var mu lock.ErrorRWLocker
+	for _, objName := range objNames {
+		blobID, ok := blobIDFromObjectName(objName)
+		if !ok {
+			toDelete = append(toDelete, objName)
+			continue
+		}
+		if mu == nil {
+			mu = sfs.blobsLock()
+			if err := mu.Lock(); err != nil {
+				sfs.log.Warnf("Cannot release the blob %s: %s", blobID, err)
+				continue
+			}
+			defer mu.Unlock()
+		}
+		last, err := sfs.releaseBlob(blobID)
+		if err != nil {
+			// Keeping an object that is no longer used is better than losing
+			// the content of a file.
+			sfs.log.Warnf("Cannot release the blob %s: %s", blobID, err)
+			continue
+		}
+		if last {
+			toDelete = append(toDelete, objName)
+		}
+	}
+	return toDelete
+}
+
+// dedupContent replaces the object of a file that has just been uploaded by a
+// reference to the blob with the same content.
+func (sfs *swiftVFSV3) dedupContent(objName string, newdoc *vfs.FileDoc) error {
+	mu := sfs.blobsLock()
+	if err := mu.Lock(); err != nil {
+		return err
+	}
+	defer mu.Unlock()
+
+	blobID := vfs.BlobID(newdoc.MD5Sum, newdoc.ByteSize)
+	blobName := blobObjectPrefix + blobID
+	acquired, err := sfs.acquireBlob(blobID)
+	if err != nil {
+		return err
+	}
+	if acquired {
+		if err := sfs.c.ObjectDelete(sfs.ctx, sfs.container, objName); err != nil {
+			sfs.log.Infof("Cannot delete the duplicated object %s: %s", objName, err)
+		}
+	} else {
+		if err := sfs.c.ObjectMove(sfs.ctx, sfs.container, objName, sfs.container, blobName); err != nil {
+			return err
+		}
+		blob := &vfs.Blob{DocID: blobID, Size: newdoc.ByteSize, Refs: 1}
+		if err := couchdb.CreateNamedDocWithDB(sfs, blob); err != nil {
+			if !couchdb.IsConflictError(err) {
+				_ = sfs.c.ObjectDelete(sfs.ctx, sfs.container, blobName)
+				return err
+			}
+			if _, err := sfs.acquireBlob(blobID); err != nil {
+				return err
+			}
+		}
+	}
+	newdoc.InternalID = newBlobInternalID(blobID)
+	return nil
+}
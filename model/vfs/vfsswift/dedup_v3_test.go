@@ -0,0 +1,27 @@
+package vfsswift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobInternalID(t *testing.T) {
+	blobID := "d41d8cd98f00b204e9800998ecf8427e-42"
+
+	id1 := newBlobInternalID(blobID)
+	id2 := newBlobInternalID(blobID)
+	assert.NotEqual(t, id1, id2)
+
+	parsed, ok := blobIDFromInternalID(id1)
+	assert.True(t, ok)
+	assert.Equal(t, blobID, parsed)
+	assert.Equal(t, "blobs/"+blobID, MakeObjectNameV3("file-id", id2))
+
+	parsed, ok = blobIDFromInternalID("blob-" + blobID)
+	assert.True(t, ok)
+	assert.Equal(t, blobID, parsed)
+
+	_, ok = blobIDFromInternalID(NewInternalID())
+	assert.False(t, ok)
+}
@@ -84,6 +84,27 @@ func (sfs *swiftVFSV3) checkFiles(
 		fileIDs[f.DocID] = struct{}{}
 	}
 
+	// The files and versions with a deduplicated content share a blob object,
+	// so they are checked with this object and not by their own object name.
+	blobFiles := make(map[string][]*vfs.TreeFile)
+	for key, f := range entries {
+		if blobID, ok := blobIDFromInternalID(f.InternalID); ok {
+			blobFiles[blobID] = append(blobFiles[blobID], f)
+			delete(entries, key)
+		}
+	}
+	blobVersions := make(map[string][]*vfs.Version)
+	for key, v := range versions {
+		parts := strings.SplitN(v.DocID, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if blobID, ok := blobIDFromInternalID(parts[1]); ok {
+			blobVersions[blobID] = append(blobVersions[blobID], v)
+			delete(versions, key)
+		}
+	}
+
 	opts := &swift.ObjectsOpts{Limit: 5_000}
 	err = sfs.c.ObjectsWalk(sfs.ctx, sfs.container, opts, func(ctx context.Context, opts *swift.ObjectsOpts) (interface{}, error) {
 		objs, err := sfs.c.Objects(sfs.ctx, sfs.container, opts)
@@ -118,6 +139,52 @@ func (sfs *swiftVFSV3) checkFiles(
 				}
 				continue
 			}
+			if blobID, ok := blobIDFromObjectName(obj.Name); ok {
+				var md5sum []byte
+				md5sum, err = hex.DecodeString(obj.Hash)
+				if err != nil {
+					return nil, err
+				}
+				for _, f := range blobFiles[blobID] {
+					if !bytes.Equal(md5sum, f.MD5Sum) || f.ByteSize != obj.Bytes {
+						accumulate(&vfs.FsckLog{
+							Type:    vfs.ContentMismatch,
+							IsFile:  true,
+							FileDoc: f,
+							ContentMismatch: &vfs.FsckContentMismatch{
+								SizeFile:    obj.Bytes,
+								SizeIndex:   f.ByteSize,
+								MD5SumFile:  md5sum,
+								MD5SumIndex: f.MD5Sum,
+							},
+						})
+						if failFast {
+							return nil, errFailFast
+						}
+					}
+				}
+				for _, v := range blobVersions[blobID] {
+					if !bytes.Equal(md5sum, v.MD5Sum) || v.ByteSize != obj.Bytes {
+						accumulate(&vfs.FsckLog{
+							Type:       vfs.ContentMismatch,
+							IsVersion:  true,
+							VersionDoc: v,
+							ContentMismatch: &vfs.FsckContentMismatch{
+								SizeFile:    obj.Bytes,
+								SizeIndex:   v.ByteSize,
+								MD5SumFile:  md5sum,
+								MD5SumIndex: v.MD5Sum,
+							},
+						})
+						if failFast {
+							return nil, errFailFast
+						}
+					}
+				}
+				delete(blobFiles, blobID)
+				delete(blobVersions, blobID)
+				continue
+			}
 			docID, internalID := makeDocIDV3(obj.Name)
 			if v, ok := versions[docID+"/"+internalID]; ok {
 				var md5sum []byte
@@ -188,6 +255,18 @@ func (sfs *swiftVFSV3) checkFiles(
 		return err
 	}
 
+	// The blobs that have not been found in the storage
+	for _, files := range blobFiles {
+		for _, f := range files {
+			entries[f.DocID+"/"+f.InternalID] = f
+		}
+	}
+	for _, vers := range blobVersions {
+		for _, v := range vers {
+			versions[v.DocID] = v
+		}
+	}
+
 	// entries should contain only data that does not contain an associated
 	// index.
 	for _, f := range entries {
@@ -33,6 +33,7 @@ type swiftVFSV3 struct {
 	mu        lock.ErrorRWLocker
 	ctx       context.Context
 	log       *logger.Entry
+	dedup     bool
 }
 
 const swiftV3ContainerPrefix = "cozy-v3-"
@@ -62,6 +63,7 @@ func NewV3(db vfs.Prefixer, index vfs.Indexer, disk vfs.DiskThresholder, mu lock
 		mu:        mu,
 		ctx:       context.Background(),
 		log:       logger.WithDomain(db.DomainName()).WithNamespace("vfsswift"),
+		dedup:     config.GetConfig().Fs.Deduplication,
 	}, nil
 }
 
@@ -74,8 +76,12 @@ func NewInternalID() string {
 // creates a virtual subfolder by splitting the document ID, which should be 32
 // bytes long, on the 27nth byte. This avoid having a flat hierarchy in swift
 // with no bound. And it appends the internalID at the end to regroup all the
-// versions of a file in the same virtual subfolder.
+// versions of a file in the same virtual subfolder. For a deduplicated
+// content, it is the name of the shared blob object.
 func MakeObjectNameV3(docID, internalID string) string {
+	if blobID, ok := blobIDFromInternalID(internalID); ok {
+		return blobObjectPrefix + blobID
+	}
 	if len(docID) != 32 || len(internalID) != 16 {
 		return docID + "/" + internalID
 	}
@@ -269,6 +275,22 @@ func (sfs *swiftVFSV3) CopyFile(olddoc, newdoc *vfs.FileDoc) error {
 		return err
 	}
 	newdoc.DocID = uid.String()
+
+	// A deduplicated content is shared by the copy
+	if blobID, ok := blobIDFromInternalID(olddoc.InternalID); ok {
+		acquired, err := sfs.shareBlob(blobID)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			newdoc.InternalID = newBlobInternalID(blobID)
+			if err := sfs.Indexer.CreateNamedFileDoc(newdoc); err != nil {
+				sfs.releaseBlobs([]string{MakeObjectNameV3(newdoc.DocID, newdoc.InternalID)})
+				return err
+			}
+			return nil
+		}
+	}
 	newdoc.InternalID = NewInternalID()
 
 	// Copy the file
@@ -319,16 +341,26 @@ func (sfs *swiftVFSV3) DissociateFile(src, dst *vfs.FileDoc) error {
 	// Copy the file
 	srcName := MakeObjectNameV3(src.DocID, src.InternalID)
 	dstName := MakeObjectNameV3(dst.DocID, dst.InternalID)
-	headers := swift.Metadata{
-		"creation-name":  src.Name(),
-		"created-at":     src.CreatedAt.Format(time.RFC3339),
-		"dissociated-of": src.ID(),
-	}.ObjectHeaders()
-	if _, err := sfs.c.ObjectCopy(sfs.ctx, sfs.container, srcName, sfs.container, dstName, headers); err != nil {
-		return err
+	if srcName == dstName {
+		// The content is a blob shared with the source
+		blobID, _ := blobIDFromObjectName(srcName)
+		if err := sfs.refBlob(blobID, src.ByteSize); err != nil {
+			return err
+		}
+	} else {
+		headers := swift.Metadata{
+			"creation-name":  src.Name(),
+			"created-at":     src.CreatedAt.Format(time.RFC3339),
+			"dissociated-of": src.ID(),
+		}.ObjectHeaders()
+		if _, err := sfs.c.ObjectCopy(sfs.ctx, sfs.container, srcName, sfs.container, dstName, headers); err != nil {
+			return err
+		}
 	}
 	if err := sfs.Indexer.CreateNamedFileDoc(dst); err != nil {
-		_ = sfs.c.ObjectDelete(sfs.ctx, sfs.container, dstName)
+		for _, objName := range sfs.releaseBlobs([]string{dstName}) {
+			_ = sfs.c.ObjectDelete(sfs.ctx, sfs.container, objName)
+		}
 		return err
 	}
 
@@ -432,6 +464,7 @@ func (sfs *swiftVFSV3) destroyFileLocked(doc *vfs.FileDoc) error {
 			sfs.log.Warnf("DestroyFile failed on BatchDeleteVersions: %s", err)
 		}
 	}
+	objNames = sfs.releaseBlobs(objNames)
 	_, errb := sfs.c.BulkDelete(sfs.ctx, sfs.container, objNames)
 	if errb == swift.Forbidden {
 		for _, objName := range objNames {
@@ -477,6 +510,7 @@ func (sfs *swiftVFSV3) EnsureErased(journal vfs.TrashJournal) error {
 		sfs.log.Warnf("EnsureErased failed on BatchDeleteVersions: %s", err)
 		errm = multierror.Append(errm, err)
 	}
+	objNames = sfs.releaseBlobs(objNames)
 	if err := deleteContainerFiles(sfs.ctx, sfs.c, sfs.container, objNames); err != nil {
 		sfs.log.Warnf("EnsureErased failed on deleteContainerFiles: %s", err)
 		errm = multierror.Append(errm, err)
@@ -564,6 +598,12 @@ func (sfs *swiftVFSV3) ImportFileVersion(version *vfs.Version, content io.ReadCl
 		return err
 	}
 
+	if blobID, ok := blobIDFromInternalID(parts[1]); ok {
+		if err := sfs.refBlob(blobID, version.ByteSize); err != nil {
+			return err
+		}
+	}
+
 	return sfs.Indexer.CreateVersion(version)
 }
 
@@ -853,6 +893,12 @@ func (f *swiftFileCreationV3) Close() (err error) {
 	}
 	newdoc.Trashed = strings.HasPrefix(newpath, vfs.TrashDirName+"/")
 
+	if f.fs.dedup && newdoc.ByteSize > 0 {
+		if err = f.fs.dedupContent(f.name, newdoc); err != nil {
+			return err
+		}
+	}
+
 	var v *vfs.Version
 	if olddoc != nil {
 		v = vfs.NewVersion(olddoc)
@@ -863,6 +909,12 @@ func (f *swiftFileCreationV3) Close() (err error) {
 		err = f.fs.Indexer.CreateNamedFileDoc(newdoc)
 	}
 	if err != nil {
+		if _, ok := blobIDFromInternalID(newdoc.InternalID); ok {
+			blobName := MakeObjectNameV3(newdoc.DocID, newdoc.InternalID)
+			for _, objName := range f.fs.releaseBlobs([]string{blobName}) {
+				_ = f.fs.c.ObjectDelete(f.fs.ctx, f.fs.container, objName)
+			}
+		}
 		return err
 	}
 
@@ -882,7 +934,9 @@ func (f *swiftFileCreationV3) Close() (err error) {
 				internalID = parts[1]
 			}
 			objName := MakeObjectNameV3(newdoc.DocID, internalID)
-			_ = f.fs.c.ObjectDelete(f.fs.ctx, f.fs.container, objName)
+			for _, name := range f.fs.releaseBlobs([]string{objName}) {
+				_ = f.fs.c.ObjectDelete(f.fs.ctx, f.fs.container, name)
+			}
 		}
 		for _, old := range toClean {
 			_ = cleanOldVersion(f.fs, newdoc.DocID, old)
@@ -913,7 +967,12 @@ func cleanOldVersion(sfs *swiftVFSV3, fileID string, v *vfs.Version) error {
 		internalID = parts[1]
 	}
 	objName := MakeObjectNameV3(fileID, internalID)
-	return sfs.c.ObjectDelete(sfs.ctx, sfs.container, objName)
+	for _, name := range sfs.releaseBlobs([]string{objName}) {
+		if err := sfs.c.ObjectDelete(sfs.ctx, sfs.container, name); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (sfs *swiftVFSV3) ClearOldVersions() error {
@@ -938,6 +997,7 @@ func (sfs *swiftVFSV3) ClearOldVersions() error {
 		return err
 	}
 	vfs.DiskQuotaAfterDestroy(sfs, diskUsage, destroyed)
+	objNames = sfs.releaseBlobs(objNames)
 	return deleteContainerFiles(sfs.ctx, sfs.c, sfs.container, objNames)
 }
 
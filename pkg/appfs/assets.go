@@ -0,0 +1,88 @@
+package appfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// assetsCacheSize is the number of lists of assets kept in memory. Computing
+// the list requires to read all the files of an application, but it doesn't
+// change for a given version (and shasum).
+const assetsCacheSize = 128
+
+// Asset describes a file of an application, with its size and hash, so that a
+// client can precache it and check its integrity.
+type Asset struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+var assetsCache *lru.Cache[string, []Asset]
+var initAssetsCacheOnce sync.Once
+
+// AssetsList returns the list of the files of the given version of an
+// application, sorted by path, with their uncompressed size and their
+// SHA-256 hash. The list is cached in memory when the shasum is known, as the
+// files can't change for a given version and shasum.
+func AssetsList(s FileServer, slug, version, shasum string) ([]Asset, error) {
+	initAssetsCacheOnce.Do(func() {
+		assetsCache, _ = lru.New[string, []Asset](assetsCacheSize)
+	})
+	key := path.Join(slug, version, shasum)
+	if shasum != "" {
+		if assets, ok := assetsCache.Get(key); ok {
+			return assets, nil
+		}
+	}
+
+	names, err := s.FilesList(slug, version, shasum)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]Asset, 0, len(names))
+	for _, name := range names {
+		asset, err := hashAsset(s, slug, version, shasum, name)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].Path < assets[j].Path
+	})
+
+	if shasum != "" {
+		assetsCache.Add(key, assets)
+	}
+	return assets, nil
+}
+
+func hashAsset(s FileServer, slug, version, shasum, name string) (Asset, error) {
+	f, err := s.Open(slug, version, shasum, name)
+	if err != nil {
+		return Asset{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return Asset{}, err
+	}
+	name = "/" + strings.TrimPrefix(name, "/")
+	return Asset{
+		Path:        name,
+		Size:        size,
+		SHA256:      hex.EncodeToString(h.Sum(nil)),
+		ContentType: mime.TypeByExtension(path.Ext(name)),
+	}, nil
+}
@@ -0,0 +1,25 @@
+package appfs
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetsList(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/drive/1.0.0-abc/index.html", []byte("<html></html>"), 0640))
+	require.NoError(t, afero.WriteFile(fs, "/drive/1.0.0-abc/js/app.js", []byte("foo"), 0640))
+	s := NewAferoFileServer(fs, nil)
+
+	assets, err := AssetsList(s, "drive", "1.0.0", "abc")
+	require.NoError(t, err)
+	require.Len(t, assets, 2)
+	assert.Equal(t, "/index.html", assets[0].Path)
+	assert.EqualValues(t, 13, assets[0].Size)
+	assert.Equal(t, "/js/app.js", assets[1].Path)
+	assert.EqualValues(t, 3, assets[1].Size)
+	assert.Equal(t, "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", assets[1].SHA256)
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/andybalholm/brotli"
+	"github.com/cozy/cozy-stack/pkg/compress"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	web_utils "github.com/cozy/cozy-stack/pkg/utils"
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -455,11 +456,13 @@ func defaultMakePath(slug, version, shasum, file string) string {
 }
 
 func acceptBrotliEncoding(req *http.Request) bool {
-	return strings.Contains(req.Header.Get(echo.HeaderAcceptEncoding), "br")
+	acceptEncoding := req.Header.Get(echo.HeaderAcceptEncoding)
+	return compress.Negotiate(acceptEncoding, []string{compress.Brotli}) == compress.Brotli
 }
 
 func acceptGzipEncoding(req *http.Request) bool {
-	return strings.Contains(req.Header.Get(echo.HeaderAcceptEncoding), "gzip")
+	acceptEncoding := req.Header.Get(echo.HeaderAcceptEncoding)
+	return compress.Negotiate(acceptEncoding, []string{compress.Gzip}) == compress.Gzip
 }
 
 func containerName(appsType consts.AppType) string {
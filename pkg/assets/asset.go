@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/assets/cdn"
 	"github.com/cozy/cozy-stack/pkg/assets/dynamic"
 	"github.com/cozy/cozy-stack/pkg/assets/model"
 	"github.com/cozy/cozy-stack/pkg/assets/statik"
@@ -82,6 +83,7 @@ func Add(options []model.AssetOption) error {
 		for _, opt := range options {
 			key := fmt.Sprintf("dyn-assets:%s/%s", opt.Context, opt.Name)
 			cache.Clear(key)
+			cdn.PurgeAsset(opt.Context, opt.Name)
 		}
 	}
 	return err
@@ -95,6 +97,7 @@ func Remove(name, context string) error {
 		key := fmt.Sprintf("dyn-assets:%s/%s", context, name)
 		cache := config.GetConfig().CacheStorage
 		cache.Clear(key)
+		cdn.PurgeAsset(context, name)
 	}
 	return err
 }
@@ -0,0 +1,163 @@
+// Package cdn is used for putting a CDN in front of the assets of the stack.
+// The URLs of the assets are rewritten to use the CDN host, and the CDN cache
+// is purged when a dynamic asset is changed.
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/logger"
+)
+
+// The providers of purge API.
+const (
+	ProviderCloudflare = "cloudflare"
+	ProviderFastly     = "fastly"
+	ProviderWebhook    = "webhook"
+)
+
+// CloudflareAPI is the base URL of the Cloudflare API.
+var CloudflareAPI = "https://api.cloudflare.com/client/v4"
+
+// FastlyAPI is the base URL of the Fastly API.
+var FastlyAPI = "https://api.fastly.com"
+
+// purgeTimeout is the maximal duration for a purge request.
+const purgeTimeout = 30 * time.Second
+
+// ErrUnknownProvider is used when the provider for the purge is not known
+var ErrUnknownProvider = errors.New("Unknown CDN provider")
+
+var purgeClient = &http.Client{
+	Timeout: purgeTimeout,
+}
+
+// Origin returns the scheme and host of the CDN, to be used in the Content
+// Security Policy, or an empty string if no CDN is configured.
+func Origin() string {
+	u, err := url.Parse(config.GetConfig().CDN.URL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Scheme == "" {
+		return "https://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Rewrite returns the URL of an asset on the CDN, from its path on the stack
+// (like /assets/css/cozy.min.1a2b3c4d5e6f.css). It returns false if no CDN is
+// configured.
+func Rewrite(assetPath string) (string, bool) {
+	base := config.GetConfig().CDN.URL
+	if base == "" {
+		return "", false
+	}
+	return base + assetPath, true
+}
+
+// URLsFor returns the URLs without a version of an asset on the CDN. These
+// URLs must be purged when the asset is changed. The URLs with a version (a
+// shasum of the content) don't need to be purged, as they change with the
+// content.
+func URLsFor(contextName, name string) []string {
+	base := config.GetConfig().CDN.URL
+	if base == "" {
+		return nil
+	}
+	name = path.Join("/", name)
+	urls := []string{
+		base + path.Join("/assets/ext", url.PathEscape(contextName)) + name,
+	}
+	if contextName == config.DefaultInstanceContext {
+		urls = append(urls, base+path.Join("/assets", name))
+	}
+	return urls
+}
+
+// PurgeAsset purges the URLs of an asset from the CDN cache, in background.
+// The errors are only logged, as the asset has already been changed.
+func PurgeAsset(contextName, name string) {
+	cfg := config.GetConfig().CDN.Purge
+	if cfg.Provider == "" {
+		return
+	}
+	urls := URLsFor(contextName, name)
+	if len(urls) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), purgeTimeout)
+		defer cancel()
+		log := logger.WithNamespace("cdn")
+		if err := Purge(ctx, cfg, urls); err != nil {
+			log.Errorf("Cannot purge %v: %s", urls, err)
+			return
+		}
+		log.Infof("Purged %v", urls)
+	}()
+}
+
+// Purge sends a request to the purge API of the CDN for the given URLs.
+func Purge(ctx context.Context, cfg config.CDNPurge, urls []string) error {
+	switch cfg.Provider {
+	case ProviderCloudflare:
+		endpoint := fmt.Sprintf("%s/zones/%s/purge_cache", CloudflareAPI, url.PathEscape(cfg.ZoneID))
+		body := map[string]interface{}{"files": urls}
+		return post(ctx, endpoint, "Authorization", "Bearer "+cfg.Token, body)
+	case ProviderFastly:
+		for _, u := range urls {
+			endpoint := FastlyAPI + "/purge/" + strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+			if err := post(ctx, endpoint, "Fastly-Key", cfg.Token, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ProviderWebhook:
+		body := map[string]interface{}{"urls": urls}
+		auth := ""
+		if cfg.Token != "" {
+			auth = "Bearer " + cfg.Token
+		}
+		return post(ctx, cfg.URL, "Authorization", auth, body)
+	}
+	return ErrUnknownProvider
+}
+
+func post(ctx context.Context, endpoint, authHeader, auth string, body interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if auth != "" {
+		req.Header.Set(authHeader, auth)
+	}
+	res, err := purgeClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("the CDN has responded with the status code %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	config.UseTestFile(t)
+	cfg := config.GetConfig()
+
+	cfg.CDN.URL = ""
+	_, ok := Rewrite("/assets/css/cozy.min.css")
+	assert.False(t, ok)
+	assert.Empty(t, Origin())
+	assert.Empty(t, URLsFor("foo", "/logos/logo.svg"))
+
+	cfg.CDN.URL = "https://assets.example.org/cozy"
+	u, ok := Rewrite("/assets/css/cozy.min.1a2b3c.css")
+	assert.True(t, ok)
+	assert.Equal(t, "https://assets.example.org/cozy/assets/css/cozy.min.1a2b3c.css", u)
+	assert.Equal(t, "https://assets.example.org", Origin())
+
+	assert.Equal(t, []string{
+		"https://assets.example.org/cozy/assets/ext/foo/logos/logo.svg",
+	}, URLsFor("foo", "/logos/logo.svg"))
+	assert.Equal(t, []string{
+		"https://assets.example.org/cozy/assets/ext/default/logos/logo.svg",
+		"https://assets.example.org/cozy/assets/logos/logo.svg",
+	}, URLsFor(config.DefaultInstanceContext, "logos/logo.svg"))
+}
+
+func TestPurge(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		if gotAuth == "" {
+			gotAuth = r.Header.Get("Fastly-Key")
+		}
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if gotAuth == "Bearer wrong" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cloudflare, fastly := CloudflareAPI, FastlyAPI
+	CloudflareAPI, FastlyAPI = ts.URL+"/cloudflare", ts.URL+"/fastly"
+	defer func() { CloudflareAPI, FastlyAPI = cloudflare, fastly }()
+
+	ctx := context.Background()
+	urls := []string{"https://assets.example.org/assets/logos/logo.svg"}
+
+	err := Purge(ctx, config.CDNPurge{Provider: "cloudflare", Token: "tok", ZoneID: "zone"}, urls)
+	require.NoError(t, err)
+	assert.Equal(t, "/cloudflare/zones/zone/purge_cache", gotPath)
+	assert.Equal(t, "Bearer tok", gotAuth)
+	assert.Equal(t, urls, gotBody["files"])
+
+	err = Purge(ctx, config.CDNPurge{Provider: "fastly", Token: "key"}, urls)
+	require.NoError(t, err)
+	assert.Equal(t, "/fastly/purge/assets.example.org/assets/logos/logo.svg", gotPath)
+	assert.Equal(t, "key", gotAuth)
+
+	err = Purge(ctx, config.CDNPurge{Provider: "webhook", URL: ts.URL + "/hook", Token: "tok"}, urls)
+	require.NoError(t, err)
+	assert.Equal(t, "/hook", gotPath)
+	assert.Equal(t, urls, gotBody["urls"])
+
+	err = Purge(ctx, config.CDNPurge{Provider: "webhook", URL: ts.URL + "/hook", Token: "wrong"}, urls)
+	assert.Error(t, err)
+
+	err = Purge(ctx, config.CDNPurge{Provider: "akamai"}, urls)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
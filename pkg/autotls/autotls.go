@@ -0,0 +1,442 @@
+// Package autotls obtains and renews the TLS certificates of the stack with
+// ACME (like Let's Encrypt). The HTTP-01 and TLS-ALPN-01 challenges are used
+// for the certificates of a single domain, and the DNS-01 challenge, with a
+// DNS provider, for the wildcard certificates.
+package autotls
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// renewBefore is how early the certificates are renewed before they expire.
+const renewBefore = 30 * 24 * time.Hour
+
+// issueTimeout is the maximal duration for obtaining a certificate.
+const issueTimeout = 10 * time.Minute
+
+// accountKeyName is the key in the cache for the ACME account used with the
+// DNS-01 challenge.
+const accountKeyName = "dns01_account+key"
+
+// ErrHostNotAllowed is used when a certificate is asked for a host that is
+// not served by the stack.
+var ErrHostNotAllowed = errors.New("autotls: host not allowed")
+
+var log = logger.WithNamespace("autotls")
+
+// Options are the parameters for the Manager.
+type Options struct {
+	// Email is the contact address for the ACME account.
+	Email string
+	// DirectoryURL is the URL of the ACME directory (Let's Encrypt by
+	// default).
+	DirectoryURL string
+	// Cache is used to persist the certificates and the account keys.
+	Cache autocert.Cache
+	// HostPolicy returns an error if no certificate must be obtained for the
+	// host.
+	HostPolicy func(ctx context.Context, host string) error
+	// DNSProvider is used to publish the records for the DNS-01 challenge.
+	// If it is nil, the HTTP-01 and TLS-ALPN-01 challenges are used.
+	DNSProvider DNSProvider
+	// PropagationDelay is the time to wait after the publication of the DNS
+	// records and before asking the ACME server to check them.
+	PropagationDelay time.Duration
+	// CertNames returns the names for the certificate that will be used for
+	// the host with the DNS-01 challenge, like "*.example.com".
+	CertNames func(host string) []string
+}
+
+// Manager gives the TLS certificates for the TLS handshakes.
+type Manager struct {
+	opts     Options
+	autocert *autocert.Manager
+
+	mu      sync.Mutex
+	client  *acme.Client
+	certs   map[string]*tls.Certificate
+	pending map[string]*issuance
+}
+
+// dnsChallenge is a DNS-01 challenge with the TXT record published for it.
+type dnsChallenge struct {
+	chal     *acme.Challenge
+	authzURL string
+	fqdn     string
+	value    string
+}
+
+type issuance struct {
+	done chan struct{}
+	cert *tls.Certificate
+	err  error
+}
+
+// NewManager returns a new manager for the given options.
+func NewManager(opts Options) *Manager {
+	if opts.DirectoryURL == "" {
+		opts.DirectoryURL = autocert.DefaultACMEDirectory
+	}
+	if opts.CertNames == nil {
+		opts.CertNames = func(host string) []string { return []string{host} }
+	}
+	m := &Manager{
+		opts:    opts,
+		certs:   make(map[string]*tls.Certificate),
+		pending: make(map[string]*issuance),
+	}
+	m.autocert = &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       opts.Cache,
+		HostPolicy:  autocert.HostPolicy(opts.HostPolicy),
+		RenewBefore: renewBefore,
+		Email:       opts.Email,
+		Client:      &acme.Client{DirectoryURL: opts.DirectoryURL},
+	}
+	return m
+}
+
+// TLSConfig returns the TLS configuration for the HTTPS server.
+func (m *Manager) TLSConfig() *tls.Config {
+	protos := []string{"h2", "http/1.1"}
+	if m.opts.DNSProvider == nil {
+		protos = append(protos, acme.ALPNProto)
+	}
+	return &tls.Config{
+		GetCertificate: m.GetCertificate,
+		NextProtos:     protos,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+// HTTPHandler returns a handler for the HTTP server that responds to the
+// HTTP-01 challenges, and redirects the other requests to HTTPS.
+func (m *Manager) HTTPHandler() http.Handler {
+	return m.autocert.HTTPHandler(nil)
+}
+
+// GetCertificate returns the certificate for the TLS handshake.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if m.opts.DNSProvider == nil {
+		return m.autocert.GetCertificate(hello)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+	if host == "" {
+		return nil, errors.New("autotls: missing server name")
+	}
+	if m.opts.HostPolicy != nil {
+		if err := m.opts.HostPolicy(hello.Context(), host); err != nil {
+			return nil, err
+		}
+	}
+	names := m.opts.CertNames(host)
+	key := "dns01:" + strings.Join(names, ",")
+
+	cert := m.cachedCert(hello.Context(), key)
+	if cert != nil {
+		if time.Until(cert.Leaf.NotAfter) < renewBefore {
+			m.startIssuance(key, names)
+		}
+		return cert, nil
+	}
+	iss := m.startIssuance(key, names)
+	select {
+	case <-iss.done:
+		return iss.cert, iss.err
+	case <-hello.Context().Done():
+		return nil, hello.Context().Err()
+	}
+}
+
+// cachedCert returns the certificate from the memory, or else from the
+// persistent cache.
+func (m *Manager) cachedCert(ctx context.Context, key string) *tls.Certificate {
+	m.mu.Lock()
+	cert, ok := m.certs[key]
+	m.mu.Unlock()
+	if ok {
+		return cert
+	}
+	if m.opts.Cache == nil {
+		return nil
+	}
+	data, err := m.opts.Cache.Get(ctx, key)
+	if err != nil {
+		return nil
+	}
+	cert, err = decodeCert(data)
+	if err != nil || time.Now().After(cert.Leaf.NotAfter) {
+		return nil
+	}
+	m.mu.Lock()
+	m.certs[key] = cert
+	m.mu.Unlock()
+	return cert
+}
+
+// startIssuance starts obtaining a certificate in the background, unless it
+// is already in progress for the same names.
+func (m *Manager) startIssuance(key string, names []string) *issuance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if iss, ok := m.pending[key]; ok {
+		return iss
+	}
+	iss := &issuance{done: make(chan struct{})}
+	m.pending[key] = iss
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), issueTimeout)
+		defer cancel()
+		iss.cert, iss.err = m.obtain(ctx, key, names)
+		if iss.err != nil {
+			log.Errorf("Cannot obtain a certificate for %v: %s", names, iss.err)
+		} else {
+			log.Infof("New certificate obtained for %v", names)
+		}
+		m.mu.Lock()
+		delete(m.pending, key)
+		if iss.err == nil {
+			m.certs[key] = iss.cert
+		}
+		m.mu.Unlock()
+		close(iss.done)
+	}()
+	return iss
+}
+
+// obtain gets a new certificate from the ACME server with the DNS-01
+// challenge.
+func (m *Manager) obtain(ctx context.Context, key string, names []string) (*tls.Certificate, error) {
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(names...))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*dnsChallenge
+	defer func() {
+		for _, r := range records {
+			if err := m.opts.DNSProvider.CleanUp(context.Background(), r.fqdn, r.value); err != nil {
+				log.Warnf("Cannot clean up the DNS record %s: %s", r.fqdn, err)
+			}
+		}
+	}()
+	for _, u := range order.AuthzURLs {
+		z, err := client.GetAuthorization(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if z.Status == acme.StatusValid {
+			continue
+		}
+		var chal *acme.Challenge
+		for _, c := range z.Challenges {
+			if c.Type == "dns-01" {
+				chal = c
+			}
+		}
+		if chal == nil {
+			return nil, fmt.Errorf("autotls: no dns-01 challenge for %s", z.Identifier.Value)
+		}
+		value, err := client.DNS01ChallengeRecord(chal.Token)
+		if err != nil {
+			return nil, err
+		}
+		fqdn := "_acme-challenge." + strings.TrimPrefix(z.Identifier.Value, "*.") + "."
+		if err := m.opts.DNSProvider.Present(ctx, fqdn, value); err != nil {
+			return nil, err
+		}
+		records = append(records, &dnsChallenge{
+			chal:     chal,
+			authzURL: z.URI,
+			fqdn:     fqdn,
+			value:    value,
+		})
+	}
+
+	if len(records) > 0 && m.opts.PropagationDelay > 0 {
+		select {
+		case <-time.After(m.opts.PropagationDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	for _, r := range records {
+		if _, err := client.Accept(ctx, r.chal); err != nil {
+			return nil, err
+		}
+		if _, err := client.WaitAuthorization(ctx, r.authzURL); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		DNSNames: names,
+	}, privKey)
+	if err != nil {
+		return nil, err
+	}
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := newCert(der, privKey)
+	if err != nil {
+		return nil, err
+	}
+	if m.opts.Cache != nil {
+		data, err := encodeCert(cert)
+		if err == nil {
+			err = m.opts.Cache.Put(ctx, key, data)
+		}
+		if err != nil {
+			log.Warnf("Cannot save the certificate for %v: %s", names, err)
+		}
+	}
+	return cert, nil
+}
+
+// acmeClient returns the ACME client, with an account registered.
+func (m *Manager) acmeClient(ctx context.Context) (*acme.Client, error) {
+	m.mu.Lock()
+	client := m.client
+	m.mu.Unlock()
+	if client != nil {
+		return client, nil
+	}
+
+	key, err := m.accountKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client = &acme.Client{Key: key, DirectoryURL: m.opts.DirectoryURL}
+	account := &acme.Account{}
+	if m.opts.Email != "" {
+		account.Contact = []string{"mailto:" + m.opts.Email}
+	}
+	_, err = client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.client = client
+	m.mu.Unlock()
+	return client, nil
+}
+
+func (m *Manager) accountKey(ctx context.Context) (crypto.Signer, error) {
+	if m.opts.Cache != nil {
+		if data, err := m.opts.Cache.Get(ctx, accountKeyName); err == nil {
+			if block, _ := pem.Decode(data); block != nil {
+				if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+					return key, nil
+				}
+			}
+		}
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if m.opts.Cache != nil {
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		if err := m.opts.Cache.Put(ctx, accountKeyName, data); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+func newCert(der [][]byte, key crypto.Signer) (*tls.Certificate, error) {
+	if len(der) == 0 {
+		return nil, errors.New("autotls: empty certificate chain")
+	}
+	leaf, err := x509.ParseCertificate(der[0])
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{Certificate: der, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// encodeCert encodes the private key and the certificate chain in PEM, like
+// autocert does.
+func encodeCert(cert *tls.Certificate) ([]byte, error) {
+	key, ok := cert.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("autotls: unsupported private key")
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}); err != nil {
+		return nil, err
+	}
+	for _, b := range cert.Certificate {
+		if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: b}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeCert(data []byte) (*tls.Certificate, error) {
+	var key *ecdsa.PrivateKey
+	var der [][]byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "EC PRIVATE KEY":
+			k, err := x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		case "CERTIFICATE":
+			der = append(der, block.Bytes)
+		}
+	}
+	if key == nil {
+		return nil, errors.New("autotls: missing private key")
+	}
+	return newCert(der, key)
+}
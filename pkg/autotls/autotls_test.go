@@ -0,0 +1,88 @@
+package autotls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeCert(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.cozy.example"},
+		DNSNames:     []string{"*.cozy.example"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := newCert([][]byte{der}, key)
+	require.NoError(t, err)
+
+	data, err := encodeCert(cert)
+	require.NoError(t, err)
+	decoded, err := decodeCert(data)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, decoded.Certificate)
+	assert.Equal(t, []string{"*.cozy.example"}, decoded.Leaf.DNSNames)
+	assert.True(t, key.Equal(decoded.PrivateKey))
+
+	_, err = decodeCert([]byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestWebhookProvider(t *testing.T) {
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "_acme-challenge.cozy.example.", body["fqdn"])
+		calls = append(calls, r.URL.Path+" "+body["value"])
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	provider, err := NewDNSProvider("webhook", map[string]interface{}{"url": ts.URL + "/", "token": "tok"})
+	require.NoError(t, err)
+	require.NoError(t, provider.Present(context.Background(), "_acme-challenge.cozy.example.", "abc"))
+	require.NoError(t, provider.CleanUp(context.Background(), "_acme-challenge.cozy.example.", "abc"))
+	assert.Equal(t, []string{"/present abc", "/cleanup abc"}, calls)
+
+	_, err = NewDNSProvider("webhook", map[string]interface{}{})
+	assert.Error(t, err)
+	_, err = NewDNSProvider("unknown", nil)
+	assert.Error(t, err)
+}
+
+func TestExecProvider(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "hook.sh")
+	content := "#!/bin/sh\necho \"$1 $2 $3\" >> " + out + "\n"
+	require.NoError(t, os.WriteFile(script, []byte(content), 0700))
+
+	provider, err := NewDNSProvider("exec", map[string]interface{}{"command": script})
+	require.NoError(t, err)
+	require.NoError(t, provider.Present(context.Background(), "_acme-challenge.cozy.example.", "abc"))
+	require.NoError(t, provider.CleanUp(context.Background(), "_acme-challenge.cozy.example.", "abc"))
+	written, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "present _acme-challenge.cozy.example. abc\ncleanup _acme-challenge.cozy.example. abc\n", string(written))
+}
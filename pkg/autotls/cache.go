@@ -0,0 +1,75 @@
+package autotls
+
+import (
+	"context"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certDoc is a document used to persist an entry of the cache: a
+// certificate with its private key, or the key of the ACME account.
+type certDoc struct {
+	DocID  string `json:"_id,omitempty"`
+	DocRev string `json:"_rev,omitempty"`
+	Data   []byte `json:"data"`
+}
+
+func (d *certDoc) ID() string        { return d.DocID }
+func (d *certDoc) Rev() string       { return d.DocRev }
+func (d *certDoc) SetID(id string)   { d.DocID = id }
+func (d *certDoc) SetRev(rev string) { d.DocRev = rev }
+func (d *certDoc) DocType() string   { return consts.ACMECertificates }
+func (d *certDoc) Clone() couchdb.Doc {
+	cloned := *d
+	cloned.Data = make([]byte, len(d.Data))
+	copy(cloned.Data, d.Data)
+	return &cloned
+}
+
+// CouchCache is an autocert.Cache that persists the certificates in a global
+// CouchDB database. It allows several stacks to share the same certificates.
+type CouchCache struct{}
+
+// Get is used to implement the autocert.Cache interface.
+func (CouchCache) Get(_ context.Context, key string) ([]byte, error) {
+	doc := &certDoc{}
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.ACMECertificates, key, doc)
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.Data, nil
+}
+
+// Put is used to implement the autocert.Cache interface.
+func (CouchCache) Put(_ context.Context, key string, data []byte) error {
+	doc := &certDoc{}
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.ACMECertificates, key, doc)
+	if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+	doc.Data = data
+	if doc.DocRev == "" {
+		doc.DocID = key
+		return couchdb.CreateNamedDocWithDB(prefixer.GlobalPrefixer, doc)
+	}
+	return couchdb.UpdateDoc(prefixer.GlobalPrefixer, doc)
+}
+
+// Delete is used to implement the autocert.Cache interface.
+func (CouchCache) Delete(_ context.Context, key string) error {
+	doc := &certDoc{}
+	err := couchdb.GetDoc(prefixer.GlobalPrefixer, consts.ACMECertificates, key, doc)
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return couchdb.DeleteDoc(prefixer.GlobalPrefixer, doc)
+}
@@ -0,0 +1,140 @@
+package autotls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DNSProvider is the interface for the plugins that publish the TXT records
+// for the DNS-01 challenges. The fqdn is the name of the record, like
+// "_acme-challenge.example.com.", and the value is its content.
+type DNSProvider interface {
+	Present(ctx context.Context, fqdn, value string) error
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+// DNSProviderFactory creates a DNS provider from its configuration.
+type DNSProviderFactory func(opts map[string]interface{}) (DNSProvider, error)
+
+var (
+	providersMu sync.Mutex
+	providers   = map[string]DNSProviderFactory{
+		"exec":    newExecProvider,
+		"webhook": newWebhookProvider,
+	}
+)
+
+// RegisterDNSProvider adds a DNS provider that can be used in the
+// configuration with the given name.
+func RegisterDNSProvider(name string, factory DNSProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// NewDNSProvider returns the DNS provider for the given name.
+func NewDNSProvider(name string, opts map[string]interface{}) (DNSProvider, error) {
+	providersMu.Lock()
+	factory, ok := providers[name]
+	providersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("autotls: unknown DNS provider %q", name)
+	}
+	return factory(opts)
+}
+
+// execProvider runs a command to publish the records, like:
+//
+//	/usr/local/bin/dns-hook present _acme-challenge.example.com. <value>
+//	/usr/local/bin/dns-hook cleanup _acme-challenge.example.com. <value>
+type execProvider struct {
+	command string
+}
+
+func newExecProvider(opts map[string]interface{}) (DNSProvider, error) {
+	command, _ := opts["command"].(string)
+	if command == "" {
+		return nil, errors.New("autotls: the exec DNS provider requires a command")
+	}
+	return &execProvider{command: command}, nil
+}
+
+func (p *execProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.run(ctx, "present", fqdn, value)
+}
+
+func (p *execProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.run(ctx, "cleanup", fqdn, value)
+}
+
+func (p *execProvider) run(ctx context.Context, action, fqdn, value string) error {
+	cmd := exec.CommandContext(ctx, p.command, action, fqdn, value)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w (%s)", p.command, action, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// webhookProvider sends a POST request with the record as JSON to the
+// <url>/present and <url>/cleanup endpoints.
+type webhookProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newWebhookProvider(opts map[string]interface{}) (DNSProvider, error) {
+	u, _ := opts["url"].(string)
+	if u == "" {
+		return nil, errors.New("autotls: the webhook DNS provider requires an URL")
+	}
+	token, _ := opts["token"].(string)
+	return &webhookProvider{
+		url:    strings.TrimSuffix(u, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *webhookProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.post(ctx, "/present", fqdn, value)
+}
+
+func (p *webhookProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.post(ctx, "/cleanup", fqdn, value)
+}
+
+func (p *webhookProvider) post(ctx context.Context, path, fqdn, value string) error {
+	body, err := json.Marshal(map[string]string{"fqdn": fqdn, "value": value})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("%s%s: unexpected status %d (%s)", p.url, path, res.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+// Package backup is for the incremental backups of the instances, made from
+// the changes feeds of their doctypes. The backups are append-only: each pass
+// adds a new segment of changes for the doctypes with changes, and the
+// contents of the new files are stored once, by their md5sum.
+//
+// The layout of a backup for an instance is:
+//
+//	<domain>/checkpoint.json                  the last sequence for each doctype
+//	<domain>/changes/<doctype>/<time>.jsonl   the segments of changes
+//	<domain>/blobs/<md5sum>                   the contents of the files
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/client"
+	"github.com/cozy/cozy-stack/client/request"
+	"github.com/cozy/cozy-stack/pkg/consts"
+)
+
+// batchSize is the number of changes asked to the stack in one request.
+const batchSize = 1000
+
+// Entry is a line in a segment of changes.
+type Entry struct {
+	Seq     string                 `json:"seq"`
+	ID      string                 `json:"id"`
+	Rev     string                 `json:"rev,omitempty"`
+	Deleted bool                   `json:"deleted,omitempty"`
+	At      time.Time              `json:"at"`
+	Doc     map[string]interface{} `json:"doc,omitempty"`
+}
+
+// Checkpoint is the state of the backup of an instance: the last sequence
+// saved for each doctype.
+type Checkpoint struct {
+	Seqs      map[string]string `json:"seqs"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Options are the options for backing up instances.
+type Options struct {
+	Store Store
+	// Doctypes is the list of the doctypes to backup. All the readable
+	// doctypes are saved if it is empty.
+	Doctypes []string
+	// NewClient returns a client for the instance with the given scopes.
+	NewClient func(domain string, scopes ...string) (*client.Client, error)
+	// Logf is used to report the progress.
+	Logf func(format string, args ...interface{})
+}
+
+func (o *Options) logf(format string, args ...interface{}) {
+	if o.Logf != nil {
+		o.Logf(format, args...)
+	}
+}
+
+// Follow backs up the instances, and then waits for the interval before
+// saving the new changes, until the context is canceled. An error for an
+// instance is reported, but it doesn't stop the backup of the others.
+func Follow(ctx context.Context, opts *Options, domains []string, interval time.Duration) error {
+	for {
+		for _, domain := range domains {
+			if err := Run(opts, domain); err != nil {
+				opts.logf("Error while backing up %s: %s", domain, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Run saves the changes of the instance since the last checkpoint.
+func Run(opts *Options, domain string) error {
+	checkpoint, err := LoadCheckpoint(opts.Store, domain)
+	if err != nil {
+		return err
+	}
+
+	doctypes := opts.Doctypes
+	if len(doctypes) == 0 {
+		doctypes, err = listDoctypes(opts, domain)
+		if err != nil {
+			return err
+		}
+	}
+	scopes := make([]string, len(doctypes))
+	for i, doctype := range doctypes {
+		scopes[i] = doctype + ":GET"
+	}
+	c, err := opts.NewClient(domain, scopes...)
+	if err != nil {
+		return err
+	}
+
+	for _, doctype := range doctypes {
+		n, err := backupDoctype(opts, c, domain, doctype, checkpoint)
+		if err != nil {
+			return fmt.Errorf("%s: %w", doctype, err)
+		}
+		if n > 0 {
+			opts.logf("%s: %d changes saved for %s", domain, n, doctype)
+		}
+	}
+	return nil
+}
+
+// LoadCheckpoint returns the checkpoint of the backup of an instance.
+func LoadCheckpoint(store Store, domain string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{Seqs: make(map[string]string)}
+	r, err := store.Get(domain + "/checkpoint.json")
+	if err == ErrNotFound {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(checkpoint); err != nil {
+		return nil, err
+	}
+	if checkpoint.Seqs == nil {
+		checkpoint.Seqs = make(map[string]string)
+	}
+	return checkpoint, nil
+}
+
+func saveCheckpoint(store Store, domain string, checkpoint *Checkpoint) error {
+	checkpoint.UpdatedAt = time.Now().UTC()
+	buf, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return store.Put(domain+"/checkpoint.json", bytes.NewReader(buf))
+}
+
+func listDoctypes(opts *Options, domain string) ([]string, error) {
+	c, err := opts.NewClient(domain, consts.Doctypes)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Req(&request.Options{
+		Method: "GET",
+		Path:   "/data/_all_doctypes",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var doctypes []string
+	if err := json.NewDecoder(res.Body).Decode(&doctypes); err != nil {
+		return nil, err
+	}
+	return doctypes, nil
+}
+
+type changesResponse struct {
+	LastSeq string `json:"last_seq"`
+	Pending int    `json:"pending"`
+	Results []struct {
+		ID      string                 `json:"id"`
+		Seq     string                 `json:"seq"`
+		Deleted bool                   `json:"deleted"`
+		Doc     map[string]interface{} `json:"doc"`
+		Changes []struct {
+			Rev string `json:"rev"`
+		} `json:"changes"`
+	} `json:"results"`
+}
+
+// backupDoctype saves the changes of a doctype in segments, one for each
+// batch, and updates the checkpoint after each segment.
+func backupDoctype(opts *Options, c *client.Client, domain, doctype string, checkpoint *Checkpoint) (int, error) {
+	total := 0
+	for {
+		queries := url.Values{
+			"include_docs": {"true"},
+			"limit":        {fmt.Sprintf("%d", batchSize)},
+		}
+		if since := checkpoint.Seqs[doctype]; since != "" {
+			queries.Set("since", since)
+		}
+		res, err := c.Req(&request.Options{
+			Method:  "GET",
+			Path:    "/data/" + url.PathEscape(doctype) + "/_changes",
+			Queries: queries,
+		})
+		if err != nil {
+			return total, err
+		}
+		var changes changesResponse
+		err = json.NewDecoder(res.Body).Decode(&changes)
+		res.Body.Close()
+		if err != nil {
+			return total, err
+		}
+
+		var segment bytes.Buffer
+		now := time.Now().UTC()
+		enc := json.NewEncoder(&segment)
+		for _, change := range changes.Results {
+			if strings.HasPrefix(change.ID, "_design/") {
+				continue
+			}
+			entry := Entry{
+				Seq:     change.Seq,
+				ID:      change.ID,
+				Deleted: change.Deleted,
+				At:      now,
+				Doc:     change.Doc,
+			}
+			if len(change.Changes) > 0 {
+				entry.Rev = change.Changes[0].Rev
+			}
+			if entry.Deleted {
+				entry.Doc = nil
+			}
+			if doctype == consts.Files && !entry.Deleted {
+				if err := saveBlob(opts.Store, c, domain, entry.ID, entry.Doc); err != nil {
+					opts.logf("%s: cannot save the content of file %s: %s", domain, entry.ID, err)
+				}
+			}
+			if err := enc.Encode(entry); err != nil {
+				return total, err
+			}
+			total++
+		}
+
+		if segment.Len() > 0 {
+			name := fmt.Sprintf("%s/changes/%s/%020d.jsonl", domain, doctype, now.UnixNano())
+			if err := opts.Store.Put(name, &segment); err != nil {
+				return total, err
+			}
+		}
+		if changes.LastSeq != "" && changes.LastSeq != checkpoint.Seqs[doctype] {
+			checkpoint.Seqs[doctype] = changes.LastSeq
+			if err := saveCheckpoint(opts.Store, domain, checkpoint); err != nil {
+				return total, err
+			}
+		}
+		if changes.Pending == 0 || len(changes.Results) == 0 {
+			return total, nil
+		}
+	}
+}
+
+// BlobName returns the name of the object with the content of a file, from
+// the md5sum of the file document.
+func BlobName(domain string, doc map[string]interface{}) (string, bool) {
+	if typ, _ := doc["type"].(string); typ != consts.FileType {
+		return "", false
+	}
+	sum, _ := doc["md5sum"].(string)
+	raw, err := base64.StdEncoding.DecodeString(sum)
+	if err != nil || len(raw) == 0 {
+		return "", false
+	}
+	return domain + "/blobs/" + hex.EncodeToString(raw), true
+}
+
+// saveBlob downloads the content of a file if it is not already in the
+// backup. The content may have changed since the change was emitted: it is
+// then saved with its real md5sum, and the next change will reference it.
+func saveBlob(store Store, c *client.Client, domain, id string, doc map[string]interface{}) error {
+	name, ok := BlobName(domain, doc)
+	if !ok {
+		return nil
+	}
+	if exists, err := store.Exists(name); err != nil || exists {
+		return err
+	}
+
+	content, err := c.DownloadByID(id)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	tmp, err := os.CreateTemp("", "cozy-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	h := md5.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), content); err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	name = domain + "/blobs/" + hex.EncodeToString(h.Sum(nil))
+	return store.Put(name, tmp)
+}
@@ -0,0 +1,110 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSegment(t *testing.T, store Store, domain, doctype string, at time.Time, entries ...Entry) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		entry.At = at
+		require.NoError(t, enc.Encode(entry))
+	}
+	name := fmt.Sprintf("%s/changes/%s/%020d.jsonl", domain, doctype, at.UnixNano())
+	require.NoError(t, store.Put(name, &buf))
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Get("foo/bar")
+	assert.ErrorIs(t, err, ErrNotFound)
+	exists, err := store.Exists("foo/bar")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, store.Put("foo/bar", bytes.NewReader([]byte("bar"))))
+	require.NoError(t, store.Put("foo/baz", bytes.NewReader([]byte("baz"))))
+	exists, err = store.Exists("foo/bar")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	names, err := store.List("foo/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo/bar", "foo/baz"}, names)
+	names, err = store.List("qux/")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	checkpoint, err := LoadCheckpoint(store, "alice.cozy.localhost")
+	assert.NoError(t, err)
+	assert.Empty(t, checkpoint.Seqs)
+}
+
+func TestRestore(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+	domain := "alice.cozy.localhost"
+	t0 := time.Date(2023, 11, 20, 10, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	writeSegment(t, store, domain, "io.cozy.contacts", t0,
+		Entry{Seq: "1", ID: "c1", Doc: map[string]interface{}{"_id": "c1", "fullname": "Bob"}},
+		Entry{Seq: "2", ID: "c2", Doc: map[string]interface{}{"_id": "c2", "fullname": "Claude"}},
+	)
+	writeSegment(t, store, domain, "io.cozy.contacts", t1,
+		Entry{Seq: "3", ID: "c1", Doc: map[string]interface{}{"_id": "c1", "fullname": "Bobby"}},
+		Entry{Seq: "4", ID: "c2", Deleted: true},
+	)
+	// md5sum of "hello"
+	md5sum := "XUFAKrxLKna5cZ2REBfFkg=="
+	writeSegment(t, store, domain, consts.Files, t0,
+		Entry{Seq: "1", ID: "d1", Doc: map[string]interface{}{
+			"type": consts.DirType, "path": "/Documents",
+		}},
+		Entry{Seq: "2", ID: "f1", Doc: map[string]interface{}{
+			"type": consts.FileType, "name": "hello.txt", "dir_id": "d1", "md5sum": md5sum,
+		}},
+	)
+	blob, ok := BlobName(domain, map[string]interface{}{"type": consts.FileType, "md5sum": md5sum})
+	require.True(t, ok)
+	assert.Equal(t, domain+"/blobs/5d41402abc4b2a76b9719d911017c592", blob)
+	require.NoError(t, store.Put(blob, bytes.NewReader([]byte("hello"))))
+
+	doctypes, err := Doctypes(store, domain)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"io.cozy.contacts", consts.Files}, doctypes)
+
+	docs, err := Snapshot(store, domain, "io.cozy.contacts", t0.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, docs, 2)
+	assert.Equal(t, "Bob", docs["c1"]["fullname"])
+
+	docs, err = Snapshot(store, domain, "io.cozy.contacts", time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, docs, 1)
+	assert.Equal(t, "Bobby", docs["c1"]["fullname"])
+
+	dir := t.TempDir()
+	stats, err := Restore(&RestoreOptions{Store: store, Domain: domain, Dir: dir})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.Documents)
+	assert.Equal(t, 1, stats.Files)
+	content, err := os.ReadFile(filepath.Join(dir, "files", "Documents", "hello.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	_, err = os.Stat(filepath.Join(dir, "io.cozy.contacts", "c1.json"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/consts"
+)
+
+// maxLineSize is the maximal size of a line in a segment of changes.
+const maxLineSize = 16 << 20 // 16MB
+
+// RestoreOptions are the options for restoring the backup of an instance.
+type RestoreOptions struct {
+	Store  Store
+	Domain string
+	// At is the point in time of the restore: the changes saved after it are
+	// ignored. The zero value means the last state saved.
+	At time.Time
+	// Dir is the local directory where the documents and files are written.
+	Dir string
+	// Logf is used to report the progress.
+	Logf func(format string, args ...interface{})
+}
+
+func (o *RestoreOptions) logf(format string, args ...interface{}) {
+	if o.Logf != nil {
+		o.Logf(format, args...)
+	}
+}
+
+// RestoreStats are the numbers of documents and files restored.
+type RestoreStats struct {
+	Documents int
+	Files     int
+}
+
+// Snapshot replays the segments of changes of the doctype until the given
+// time, and returns the documents at this time, indexed by their IDs.
+func Snapshot(store Store, domain, doctype string, at time.Time) (map[string]map[string]interface{}, error) {
+	docs := make(map[string]map[string]interface{})
+	segments, err := store.List(domain + "/changes/" + doctype + "/")
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		if err := replaySegment(store, segment, at, docs); err != nil {
+			return nil, fmt.Errorf("%s: %w", segment, err)
+		}
+	}
+	return docs, nil
+}
+
+func replaySegment(store Store, name string, at time.Time, docs map[string]map[string]interface{}) error {
+	r, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return err
+		}
+		if !at.IsZero() && entry.At.After(at) {
+			return nil
+		}
+		if entry.Deleted {
+			delete(docs, entry.ID)
+		} else {
+			docs[entry.ID] = entry.Doc
+		}
+	}
+	return scanner.Err()
+}
+
+// Doctypes returns the doctypes that have been saved in the backup of an
+// instance.
+func Doctypes(store Store, domain string) ([]string, error) {
+	prefix := domain + "/changes/"
+	names, err := store.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	var doctypes []string
+	for _, name := range names {
+		doctype := strings.SplitN(strings.TrimPrefix(name, prefix), "/", 2)[0]
+		if len(doctypes) == 0 || doctypes[len(doctypes)-1] != doctype {
+			doctypes = append(doctypes, doctype)
+		}
+	}
+	return doctypes, nil
+}
+
+// Restore writes the state of an instance at a point in time in a local
+// directory: the documents are in <dir>/<doctype>/<id>.json, and the files
+// are in <dir>/files/, with their path in the instance.
+func Restore(opts *RestoreOptions) (*RestoreStats, error) {
+	stats := &RestoreStats{}
+	doctypes, err := Doctypes(opts.Store, opts.Domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, doctype := range doctypes {
+		docs, err := Snapshot(opts.Store, opts.Domain, doctype, opts.At)
+		if err != nil {
+			return nil, err
+		}
+		dir := filepath.Join(opts.Dir, doctype)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		for id, doc := range docs {
+			buf, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			filename := filepath.Join(dir, escapeID(id)+".json")
+			if err := os.WriteFile(filename, buf, 0600); err != nil {
+				return nil, err
+			}
+			stats.Documents++
+		}
+		if doctype == consts.Files {
+			n, err := restoreFiles(opts, docs)
+			if err != nil {
+				return nil, err
+			}
+			stats.Files = n
+		}
+	}
+	return stats, nil
+}
+
+func restoreFiles(opts *RestoreOptions, docs map[string]map[string]interface{}) (int, error) {
+	dirs := make(map[string]string)
+	for id, doc := range docs {
+		if typ, _ := doc["type"].(string); typ == consts.DirType {
+			dirs[id], _ = doc["path"].(string)
+		}
+	}
+	count := 0
+	for id, doc := range docs {
+		blob, ok := BlobName(opts.Domain, doc)
+		if !ok {
+			continue
+		}
+		dirID, _ := doc["dir_id"].(string)
+		name, _ := doc["name"].(string)
+		parent, ok := dirs[dirID]
+		if !ok || name == "" {
+			opts.logf("Cannot find the path of file %s", id)
+			continue
+		}
+		p := filepath.Join(opts.Dir, "files", filepath.FromSlash(path.Join(parent, name)))
+		if err := copyBlob(opts.Store, blob, p); err != nil {
+			opts.logf("Cannot restore the content of file %s: %s", id, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func copyBlob(store Store, blob, dst string) error {
+	r, err := store.Get(blob)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// escapeID escapes the characters of a document ID that can't be used in
+// a filename.
+func escapeID(id string) string {
+	return strings.NewReplacer("/", "%2F", "\\", "%5C").Replace(id)
+}
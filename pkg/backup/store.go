@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ncw/swift/v2"
+)
+
+// ErrNotFound is returned by a store when an object doesn't exist.
+var ErrNotFound = errors.New("backup: object not found")
+
+// Store is where the backups are written. The objects are never modified
+// after they have been written, except for the checkpoints.
+type Store interface {
+	// Put writes an object with the given name.
+	Put(name string, r io.Reader) error
+	// Get opens an object for reading.
+	Get(name string) (io.ReadCloser, error)
+	// Exists returns true if the object exists.
+	Exists(name string) (bool, error)
+	// List returns the names of the objects with the given prefix, sorted.
+	List(prefix string) ([]string, error)
+}
+
+// NewStore returns a store for the given destination: a local directory, or
+// a Swift container with a swift://container/prefix URL. For Swift, the
+// credentials are taken from the usual environment variables (ST_AUTH,
+// ST_USER, ST_KEY, or the OS_* variables for Keystone).
+func NewStore(dest string) (Store, error) {
+	if !strings.HasPrefix(dest, "swift://") {
+		if err := os.MkdirAll(dest, 0700); err != nil {
+			return nil, err
+		}
+		return &localStore{dir: dest}, nil
+	}
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, err
+	}
+	conn := &swift.Connection{}
+	if err := conn.ApplyEnvironment(); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if err := conn.Authenticate(ctx); err != nil {
+		return nil, err
+	}
+	if err := conn.ContainerCreate(ctx, u.Host, nil); err != nil {
+		return nil, err
+	}
+	return &swiftStore{
+		conn:      conn,
+		container: u.Host,
+		prefix:    strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+type localStore struct {
+	dir string
+}
+
+func (s *localStore) path(name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(name))
+}
+
+func (s *localStore) Put(name string, r io.Reader) error {
+	p := s.path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	// Write in a temporary file and rename it, to never have a partial
+	// object in the backup.
+	f, err := os.CreateTemp(filepath.Dir(p), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (s *localStore) Get(name string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *localStore) Exists(name string) (bool, error) {
+	_, err := os.Stat(s.path(name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *localStore) List(prefix string) ([]string, error) {
+	var names []string
+	root := s.path(prefix)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+type swiftStore struct {
+	conn      *swift.Connection
+	container string
+	prefix    string
+}
+
+func (s *swiftStore) objectName(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+func (s *swiftStore) Put(name string, r io.Reader) error {
+	ctx := context.Background()
+	_, err := s.conn.ObjectPut(ctx, s.container, s.objectName(name), r, false, "", "", nil)
+	return err
+}
+
+func (s *swiftStore) Get(name string) (io.ReadCloser, error) {
+	ctx := context.Background()
+	f, _, err := s.conn.ObjectOpen(ctx, s.container, s.objectName(name), false, nil)
+	if errors.Is(err, swift.ObjectNotFound) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *swiftStore) Exists(name string) (bool, error) {
+	ctx := context.Background()
+	_, _, err := s.conn.Object(ctx, s.container, s.objectName(name))
+	if errors.Is(err, swift.ObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *swiftStore) List(prefix string) ([]string, error) {
+	ctx := context.Background()
+	objects, err := s.conn.ObjectNamesAll(ctx, s.container, &swift.ObjectsOpts{
+		Prefix: s.objectName(prefix),
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		name := obj
+		if s.prefix != "" {
+			name = strings.TrimPrefix(obj, s.prefix+"/")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,118 @@
+// Package compress is used for the compression of the HTTP responses with
+// brotli, zstd or gzip, depending on the Accept-Encoding header of the
+// request.
+package compress
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The supported encodings.
+const (
+	Brotli   = "br"
+	Zstd     = "zstd"
+	Gzip     = "gzip"
+	Identity = "identity"
+)
+
+// DefaultEncodings is the list of the encodings, by order of preference.
+var DefaultEncodings = []string{Brotli, Zstd, Gzip}
+
+// brotliLevel is the quality used for brotli. The assets are compressed with
+// the best quality, but it is too slow for the dynamic responses, and 5 gives
+// a good compromise between the speed and the ratio.
+const brotliLevel = 5
+
+// ErrUnknownEncoding is used when an encoding is not supported
+var ErrUnknownEncoding = errors.New("Unknown encoding")
+
+// IsSupported returns true if the given encoding can be used.
+func IsSupported(encoding string) bool {
+	switch encoding {
+	case Brotli, Zstd, Gzip:
+		return true
+	}
+	return false
+}
+
+// Negotiate returns the encoding to use for a response, given the
+// Accept-Encoding header of the request, and the encodings supported by the
+// server by order of preference. The quality values of the header are used
+// first, and the preference of the server for the ties. It returns Identity
+// if no encoding is acceptable.
+func Negotiate(acceptEncoding string, supported []string) string {
+	best := Identity
+	bestQ := 0.0
+	bestRank := len(supported)
+	wildcard := -1.0
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseCoding(part)
+		if name == "" {
+			continue
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		qualities[name] = q
+	}
+	for rank, encoding := range supported {
+		q, ok := qualities[encoding]
+		if !ok {
+			if wildcard < 0 {
+				continue
+			}
+			q = wildcard
+		}
+		if q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && rank < bestRank) {
+			best, bestQ, bestRank = encoding, q, rank
+		}
+	}
+	return best
+}
+
+// parseCoding parses a coding of the Accept-Encoding header, like "br" or
+// "gzip;q=0.8".
+func parseCoding(part string) (string, float64) {
+	name, params, _ := strings.Cut(part, ";")
+	name = strings.ToLower(strings.TrimSpace(name))
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || strings.TrimSpace(key) != "q" {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", 0
+		}
+		q = f
+	}
+	return name, q
+}
+
+// NewWriter returns a writer that compresses its input with the given
+// encoding, and writes the result in w. The writer must be closed to flush
+// the compressed data.
+func NewWriter(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case Brotli:
+		return brotli.NewWriterLevel(w, brotliLevel), nil
+	case Zstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	}
+	return nil, ErrUnknownEncoding
+}
@@ -0,0 +1,130 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	all := DefaultEncodings
+	assert.Equal(t, Identity, Negotiate("", all))
+	assert.Equal(t, Gzip, Negotiate("gzip", all))
+	assert.Equal(t, Brotli, Negotiate("gzip, deflate, br", all))
+	assert.Equal(t, Brotli, Negotiate("gzip, deflate, br, zstd", all))
+	assert.Equal(t, Zstd, Negotiate("br;q=0.5, zstd, gzip;q=0.8", all))
+	assert.Equal(t, Gzip, Negotiate("br;q=0, gzip", all))
+	assert.Equal(t, Brotli, Negotiate("*", all))
+	assert.Equal(t, Zstd, Negotiate("*;q=0.5, zstd", all))
+	assert.Equal(t, Identity, Negotiate("*;q=0", all))
+	assert.Equal(t, Identity, Negotiate("deflate", all))
+	assert.Equal(t, Gzip, Negotiate("br, gzip", []string{Gzip}))
+	assert.Equal(t, Identity, Negotiate("gzip;q=foo", all))
+}
+
+func TestIsCompressible(t *testing.T) {
+	assert.True(t, IsCompressible("application/json"))
+	assert.True(t, IsCompressible("application/vnd.api+json; charset=utf-8"))
+	assert.True(t, IsCompressible("text/html; charset=UTF-8"))
+	assert.False(t, IsCompressible("image/jpeg"))
+	assert.False(t, IsCompressible("application/zip"))
+	assert.False(t, IsCompressible(""))
+}
+
+func TestResponseWriter(t *testing.T) {
+	body := strings.Repeat(`{"hello":"world"}`, 200)
+
+	t.Run("Brotli", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := NewResponseWriter(rec, Brotli, 1024)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, err := io.WriteString(w, body)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, Brotli, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Less(t, rec.Body.Len(), len(body))
+		decoded, err := io.ReadAll(brotli.NewReader(rec.Body))
+		require.NoError(t, err)
+		assert.Equal(t, body, string(decoded))
+	})
+
+	t.Run("Zstd", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := NewResponseWriter(rec, Zstd, 1024)
+		w.Header().Set("Content-Type", "application/json")
+		for i := 0; i < 10; i++ {
+			_, err := io.WriteString(w, body[:len(body)/10])
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, Zstd, rec.Header().Get("Content-Encoding"))
+		dec, err := zstd.NewReader(rec.Body)
+		require.NoError(t, err)
+		defer dec.Close()
+		decoded, err := io.ReadAll(dec)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(decoded))
+	})
+
+	t.Run("SmallResponse", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := NewResponseWriter(rec, Gzip, 1024)
+		w.Header().Set("Content-Type", "application/json")
+		_, err := io.WriteString(w, `{"ok":true}`)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"ok":true}`, rec.Body.String())
+	})
+
+	t.Run("NotCompressible", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := NewResponseWriter(rec, Gzip, 16)
+		w.Header().Set("Content-Type", "image/png")
+		data := bytes.Repeat([]byte{0x42}, 4096)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, data, rec.Body.Bytes())
+	})
+
+	t.Run("AlreadyEncoded", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := NewResponseWriter(rec, Gzip, 16)
+		w.Header().Set("Content-Type", "text/css")
+		w.Header().Set("Content-Encoding", Brotli)
+		_, err := io.WriteString(w, body)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, Brotli, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("NoContent", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := NewResponseWriter(rec, Gzip, 16)
+		w.WriteHeader(http.StatusNoContent)
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	})
+}
@@ -0,0 +1,217 @@
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/pkg/metrics"
+)
+
+// compressibleTypes is the list of the content types that are compressed.
+// The images, videos, archives, etc. are already compressed, and the files
+// downloaded from the VFS are not listed as they can be served with ranges.
+var compressibleTypes = []string{
+	"application/json",
+	"application/vnd.api+json",
+	"application/javascript",
+	"application/manifest+json",
+	"application/xml",
+	"image/svg+xml",
+	"text/css",
+	"text/html",
+	"text/javascript",
+	"text/plain",
+	"text/xml",
+}
+
+// IsCompressible returns true if a response with the given content type
+// should be compressed.
+func IsCompressible(contentType string) bool {
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range compressibleTypes {
+		if t == mediatype {
+			return true
+		}
+	}
+	return false
+}
+
+// ResponseWriter is an http.ResponseWriter that compresses the body of the
+// response. The body is buffered until MinSize bytes are written: the small
+// responses are not compressed, as the gain would not be worth the cost. The
+// response is not compressed either if its content type is not compressible,
+// or if it already has a Content-Encoding (like the pre-compressed assets).
+type ResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	enc     io.WriteCloser
+	counter *countingWriter
+	written int
+}
+
+// NewResponseWriter returns a ResponseWriter that compresses with the given
+// encoding the responses of at least minSize bytes. It must be closed at the
+// end of the response.
+func NewResponseWriter(w http.ResponseWriter, encoding string, minSize int) *ResponseWriter {
+	w.Header().Add("Vary", "Accept-Encoding")
+	return &ResponseWriter{
+		ResponseWriter: w,
+		encoding:       encoding,
+		minSize:        minSize,
+	}
+}
+
+// WriteHeader sends the headers of the response. It is delayed until it is
+// known if the response will be compressed or not.
+func (w *ResponseWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write writes some data of the body of the response.
+func (w *ResponseWriter) Write(p []byte) (int, error) {
+	if w.decided {
+		return w.write(p)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.buf.Write(p)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *ResponseWriter) write(p []byte) (int, error) {
+	w.written += len(p)
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide sends the headers, and flushes the buffered data, compressed or not.
+func (w *ResponseWriter) decide(large bool) error {
+	w.decided = true
+	headers := w.Header()
+	if large && w.shouldCompress(headers) {
+		enc, err := NewWriter(w.countOutput(), w.encoding)
+		if err != nil {
+			return err
+		}
+		w.enc = enc
+		headers.Set("Content-Encoding", w.encoding)
+		headers.Del("Content-Length")
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *ResponseWriter) shouldCompress(headers http.Header) bool {
+	if !IsSupported(w.encoding) {
+		return false
+	}
+	switch w.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	if headers.Get("Content-Encoding") != "" || headers.Get("Content-Range") != "" {
+		return false
+	}
+	if headers.Get("Accept-Ranges") == "bytes" {
+		return false
+	}
+	return IsCompressible(headers.Get("Content-Type"))
+}
+
+func (w *ResponseWriter) countOutput() io.Writer {
+	w.counter = &countingWriter{w: w.ResponseWriter}
+	return w.counter
+}
+
+// Flush sends the buffered data to the client.
+func (w *ResponseWriter) Flush() {
+	if !w.decided {
+		_ = w.decide(w.buf.Len() >= w.minSize)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, for the websockets.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer cannot be hijacked")
+	}
+	w.decided = true
+	return h.Hijack()
+}
+
+// Unwrap returns the original http.ResponseWriter, for the
+// http.ResponseController.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Close flushes the buffered data, and the compressed data. The metrics for
+// the compressed responses are updated.
+func (w *ResponseWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	w.enc = nil
+	metrics.HTTPCompressedResponses.WithLabelValues(w.encoding).Inc()
+	metrics.HTTPCompressionBytes.WithLabelValues(w.encoding, "in").Add(float64(w.written))
+	metrics.HTTPCompressionBytes.WithLabelValues(w.encoding, "out").Add(float64(w.counter.n))
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/cozy/cozy-stack/pkg/limits"
 	"github.com/cozy/cozy-stack/pkg/lock"
 	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
+	"github.com/cozy/cozy-stack/pkg/secrets"
 	"github.com/cozy/cozy-stack/pkg/tlsclient"
 	"github.com/cozy/cozy-stack/pkg/utils"
 	"github.com/cozy/gomail"
@@ -97,6 +99,7 @@ type Config struct {
 	AdminHost           string
 	AdminPort           int
 	AdminSecretFileName string
+	AdminAccounts       map[string]AdminAccount
 
 	Assets                string
 	Doctypes              string
@@ -105,6 +108,7 @@ type Config struct {
 	NoReplyAddr           string
 	NoReplyName           string
 	ReplyTo               string
+	ReturnPath            string
 	GeoDB                 string
 	PasswordResetInterval time.Duration
 
@@ -119,9 +123,21 @@ type Config struct {
 	Konnectors     Konnectors
 	Mail           *gomail.DialerOptions
 	MailPerContext map[string]interface{}
+	MailQueue      MailQueue
 	Move           Move
+	Standby        Standby
+	Geocoding      Geocoding
+	Search         Search
+	Antivirus      Antivirus
+	Compression    Compression
+	AccessStats    AccessStats
+	DataTrash      DataTrash
+	CDN            CDN
+	ACME           ACME
+	NetworkAccess  NetworkAccess
 	Notifications  Notifications
 	Flagship       Flagship
+	OAuth          OAuth
 
 	Lock              lock.Getter
 	Limiter           *limits.RateLimiter
@@ -137,6 +153,7 @@ type Config struct {
 	Office         map[string]Office
 	Registries     map[string][]*url.URL
 	Clouderies     map[string]ClouderyConfig
+	EgressProxies  map[string]EgressProxy
 
 	RemoteAllowCustomPort bool
 
@@ -166,8 +183,18 @@ type Fs struct {
 	DefaultLayout         int
 	CanQueryInfo          bool
 	AutoCleanTrashedAfter map[string]string
-	Versioning            FsVersioning
-	Contexts              map[string]interface{}
+	// StorageForecastAlert is the delay, per context, under which the user
+	// is warned that their quota will be reached at the current rate.
+	StorageForecastAlert map[string]string
+	Versioning           FsVersioning
+	// UploadsDir is the directory where the chunks of the resumable uploads
+	// are kept until the upload is complete. It must be shared by the
+	// stack servers.
+	UploadsDir string
+	// Deduplication enables the sharing of the storage objects for the
+	// files with the same content (only for Swift).
+	Deduplication bool
+	Contexts      map[string]interface{}
 }
 
 // FsVersioning contains the configuration for the versioning of files
@@ -188,6 +215,9 @@ type CouchDB struct {
 	Client   *http.Client
 	Global   CouchDBCluster
 	Clusters []CouchDBCluster
+	// TombstonesMinAge is the minimal age of the deleted documents before
+	// their tombstones can be purged.
+	TombstonesMinAge time.Duration
 }
 
 // Jobs contains the configuration values for the jobs and triggers
@@ -198,6 +228,7 @@ type Jobs struct {
 	AllowList             bool
 	Workers               []Worker
 	ImageMagickConvertCmd string
+	FFmpegCmd             string
 	// XXX for retro-compatibility
 	NbWorkers             int
 	DefaultDurationToKeep string
@@ -205,7 +236,27 @@ type Jobs struct {
 
 // Konnectors contains the configuration values for the konnectors
 type Konnectors struct {
-	Cmd string
+	Cmd   string
+	Proxy KonnectorsProxy
+	// BIWebhookSecrets are the secrets used to check the signatures of the
+	// webhooks sent by the bank aggregator, by context.
+	BIWebhookSecrets map[string]string
+}
+
+// KonnectorsProxy contains the configuration of the forward proxy used for
+// the HTTP requests of the konnectors. It is disabled when Listen is empty.
+type KonnectorsProxy struct {
+	// Listen is the address where the proxy listens (host:port)
+	Listen string
+	// URL is the URL used by the konnectors to reach the proxy. By default,
+	// it is http:// followed by the Listen address.
+	URL string
+	// DefaultRateLimit is the maximal number of requests per minute to a
+	// vendor, for all the instances of the stack (0 means no limit).
+	DefaultRateLimit int
+	// RateLimits can be used to override the default rate limit for some
+	// vendors (the key is the domain of the vendor).
+	RateLimits map[string]int
 }
 
 // Move contains the configuration for the move wizard
@@ -213,6 +264,137 @@ type Move struct {
 	URL string
 }
 
+// Standby contains the configuration for the warm standby replication. The
+// URL is the admin endpoint of the secondary stack (with the credentials of
+// an admin account), and Interval is the delay between two replications of
+// an instance.
+type Standby struct {
+	URL      string
+	Interval time.Duration
+}
+
+// Geocoding contains the configuration for the reverse geocoding of the
+// photos. The provider can be "offline" (the nearest city is looked up in a
+// GeoNames dump) or "nominatim" (a Nominatim compatible API is called).
+type Geocoding struct {
+	Provider   string
+	URL        string
+	CitiesFile string
+	UserAgent  string
+}
+
+// Search contains the configuration for the full-text search in the content
+// of the files. It is disabled when IndexDir is empty. The text is extracted
+// by the stack for the text and office files, and by an Apache Tika server for
+// the PDF and the images if TikaURL is set.
+type Search struct {
+	IndexDir string
+	TikaURL  string
+	// MaxFileSize is the size in bytes above which the content of a file is
+	// not indexed.
+	MaxFileSize int64
+}
+
+// Antivirus contains the configuration for scanning the content of the files
+// with a ClamAV daemon. It is disabled when Address is empty, else the address
+// can be a unix socket (unix:///run/clamav/clamd.ctl) or a TCP address
+// (tcp://localhost:3310).
+type Antivirus struct {
+	Address string
+	Timeout time.Duration
+	// MaxFileSize is the size in bytes above which the files are not scanned.
+	MaxFileSize int64
+	// AllowInfectedDownloads can be set to true to let the users download
+	// the files where a virus has been found.
+	AllowInfectedDownloads bool
+}
+
+// Compression contains the configuration for the compression of the HTTP
+// responses on the fly. The responses smaller than MinSize bytes are not
+// compressed, and the encodings are listed by order of preference.
+type Compression struct {
+	Disabled  bool
+	MinSize   int
+	Encodings []string
+}
+
+// AccessStats contains the configuration of the statistics of the accesses
+// to the doctypes by the apps. Only one access in SampleRate is recorded, and
+// it is counted as SampleRate accesses.
+type AccessStats struct {
+	Disabled   bool
+	SampleRate int
+}
+
+// DataTrash contains the configuration of the trash for the documents of the
+// data API. The trashed documents are destroyed after a delay that can be
+// configured per context.
+type DataTrash struct {
+	Doctypes              []string
+	AutoCleanTrashedAfter map[string]string
+}
+
+// MailQueue contains the configuration for the queue of the outgoing mails.
+// A mail that cannot be delivered is retried up to MaxAttempts times, with an
+// exponential backoff starting at RetryDelay.
+type MailQueue struct {
+	MaxAttempts int
+	RetryDelay  time.Duration
+	Bounces     MailBounces
+}
+
+// MailBounces contains the secrets used to authenticate the webhooks sent by
+// the mail providers for the bounces and complaints.
+type MailBounces struct {
+	Token             string
+	MailgunSigningKey string
+}
+
+// CDN contains the configuration for a CDN in front of the assets. When the
+// URL is set, the URLs of the assets in the HTML pages are on the CDN host.
+// The purge is used to invalidate the assets in the CDN cache when the
+// dynamic assets are changed.
+type CDN struct {
+	URL   string
+	Purge CDNPurge
+}
+
+// CDNPurge contains the configuration for the purge API of the CDN. The
+// provider can be "cloudflare", "fastly", or "webhook" (a JSON with the list
+// of URLs is posted to the given URL).
+type CDNPurge struct {
+	Provider string
+	URL      string
+	Token    string
+	ZoneID   string
+}
+
+// ACME contains the configuration for obtaining the TLS certificates with
+// ACME (Let's Encrypt). When it is enabled, the stack serves HTTPS on
+// HTTPSAddr, and HTTP on HTTPAddr for the HTTP-01 challenges and the
+// redirections. The DNS map is used for the wildcard certificates with the
+// DNS-01 challenge: it has the provider name and its options.
+type ACME struct {
+	Enabled      bool
+	Email        string
+	DirectoryURL string
+	HTTPAddr     string
+	HTTPSAddr    string
+	Domains      []string
+	DNS          map[string]interface{}
+}
+
+// NetworkAccess contains the rules for the IP addresses that can access the
+// admin API, and the proxies that are trusted for the X-Forwarded-For header.
+// The rules for the instances are in their contexts, and they are parsed when
+// the config is loaded in Contexts (a nil policy is used for a context
+// without rules).
+type NetworkAccess struct {
+	TrustedProxies netaccess.Networks
+	Admin          netaccess.Rules
+	Contexts       map[string]*netaccess.Policy
+}
+
 // Office contains the configuration for collaborative edition of office
 // documents
 type Office struct {
@@ -221,6 +403,19 @@ type Office struct {
 	OutboxSecret  string
 }
 
+// EgressProxy contains the configuration of the outbound proxy used for the
+// HTTP requests made by the stack for the instances of a context.
+type EgressProxy struct {
+	// URL is the URL of the proxy, with the credentials if any
+	URL *url.URL
+	// NoProxy is the list of hosts, domains and CIDR that must be reached
+	// without the proxy
+	NoProxy []string
+	// CAFile is the path of a PEM file with the certificates of the CA used
+	// by the proxy for TLS interception
+	CAFile string
+}
+
 // Notifications contains the configuration for the mobile push-notification
 // center, for Android and iOS
 type Notifications struct {
@@ -246,6 +441,72 @@ type Flagship struct {
 	APKPackageNames       []string
 	APKCertificateDigests []string
 	AppleAppIDs           []string
+	PlayIntegrity         PlayIntegrity
+}
+
+// PlayIntegrity contains the configuration for checking the verdicts of the
+// Play Integrity API. The verdicts can be decrypted and verified locally with
+// the keys from the Play Console, or via the Google API with the credentials
+// of a service account.
+type PlayIntegrity struct {
+	// DecryptionKey is the base64-encoded AES key for decrypting the tokens
+	DecryptionKey string
+	// VerificationKey is the base64-encoded EC public key for verifying the
+	// signature of the tokens
+	VerificationKey string
+	// CredentialsFile is the path to the JSON file with the credentials of a
+	// Google service account, used to call the decodeIntegrityToken API
+	CredentialsFile string
+}
+
+// OAuth contains the configuration for the OAuth clients.
+type OAuth struct {
+	// SecretRotationGracePeriod is the duration during which the previous
+	// secret of a client is still accepted after a rotation.
+	SecretRotationGracePeriod time.Duration
+	// CleanStaleClientsAfter is the delay, per context, after which a client
+	// that has not refreshed its token is considered as stale.
+	CleanStaleClientsAfter map[string]string
+	// StaleClientsNotice is the delay between the mail sent to the user about
+	// the stale clients and their deletion.
+	StaleClientsNotice time.Duration
+}
+
+// The roles that can be given to the admin accounts.
+const (
+	// AdminRoleOperator can use all the admin endpoints.
+	AdminRoleOperator = "operator"
+	// AdminRoleBilling can manage the offers, quotas and TOS of the instances.
+	AdminRoleBilling = "billing"
+	// AdminRoleSupport can look at the instances, and check and fix them.
+	AdminRoleSupport = "support"
+)
+
+// AdminAccount is an account for the admin API, with its own passphrase. Its
+// role limits the endpoints it can use, and if some contexts are given, only
+// the instances of these contexts can be managed.
+type AdminAccount struct {
+	Name           string
+	Role           string
+	Contexts       []string
+	SecretFileName string
+}
+
+// HasContext returns true if the account can manage the instances of the
+// given context.
+func (a *AdminAccount) HasContext(contextName string) bool {
+	if len(a.Contexts) == 0 {
+		return true
+	}
+	if contextName == "" {
+		contextName = DefaultInstanceContext
+	}
+	for _, ctx := range a.Contexts {
+		if ctx == contextName {
+			return true
+		}
+	}
+	return false
 }
 
 // SMS contains the configuration to send notifications by SMS.
@@ -275,10 +536,11 @@ type DeprecatedApp struct {
 
 // Worker contains the configuration fields for a specific worker type.
 type Worker struct {
-	WorkerType   string
-	Concurrency  *int
-	MaxExecCount *int
-	Timeout      *time.Duration
+	WorkerType     string
+	Concurrency    *int
+	MaxExecCount   *int
+	MaxPerInstance *int
+	Timeout        *time.Duration
 }
 
 // GetRedis returns a [redis.UniversalClient] for the given db.
@@ -406,6 +668,14 @@ func GetFranceConnect(contextName string) (map[string]interface{}, bool) {
 
 var defaultPasswordResetInterval = 15 * time.Minute
 
+// defaultCompressionMinSize is the size in bytes under which the HTTP
+// responses are not compressed.
+const defaultCompressionMinSize = 1024
+
+// defaultAccessStatsSampleRate is the default rate for sampling the accesses
+// to the doctypes.
+const defaultAccessStatsSampleRate = 10
+
 // PasswordResetInterval returns the minimal delay between two password reset
 func PasswordResetInterval() time.Duration {
 	return config.PasswordResetInterval
@@ -433,12 +703,26 @@ func Setup(cfgFile string) (err error) {
 	}
 
 	log.Debugf("Using config files: %s", cfgFiles)
+	if err := mergeConfigFiles(cfgFiles); err != nil {
+		return err
+	}
+	if err := UseViper(viper.GetViper()); err != nil {
+		return err
+	}
+	if secrets.Used() {
+		startSecretsRefresh(cfgFiles, viper.GetDuration("secrets.refresh_interval"))
+	}
+	return nil
+}
 
-	for _, cfgFile = range cfgFiles {
+// mergeConfigFiles executes the templates of the configuration files, and
+// merges them in the global viper.
+func mergeConfigFiles(cfgFiles []string) error {
+	for _, cfgFile := range cfgFiles {
 		tmplName := filepath.Base(cfgFile)
 		tmpl := template.New(tmplName)
 		tmpl = tmpl.Option("missingkey=zero")
-		tmpl, err = tmpl.Funcs(numericFuncsMap).ParseFiles(cfgFile)
+		tmpl, err := tmpl.Funcs(numericFuncsMap).Funcs(secretsFuncsMap).ParseFiles(cfgFile)
 		if err != nil {
 			return fmt.Errorf("Unable to open and parse configuration file "+
 				"template %s: %s", cfgFile, err)
@@ -469,18 +753,36 @@ func Setup(cfgFile string) (err error) {
 			}
 		}
 	}
-
-	return UseViper(viper.GetViper())
+	return nil
 }
 
 func applyDefaults(v *viper.Viper) {
 	v.SetDefault("password_reset_interval", defaultPasswordResetInterval)
 	v.SetDefault("jobs.imagemagick_convert_cmd", "convert")
+	v.SetDefault("jobs.ffmpeg_cmd", "ffmpeg")
 	v.SetDefault("jobs.defaultDurationToKeep", "2W")
 	v.SetDefault("assets_polling_disabled", false)
+	v.SetDefault("standby.interval", 5*time.Minute)
+	v.SetDefault("mail.queue.max_attempts", 6)
+	v.SetDefault("mail.queue.retry_delay", 5*time.Minute)
+	v.SetDefault("compression.min_size", defaultCompressionMinSize)
+	v.SetDefault("compression.encodings", []string{"br", "zstd", "gzip"})
+	v.SetDefault("access_stats.sample_rate", defaultAccessStatsSampleRate)
+	v.SetDefault("search.max_file_size", 50<<20)
+	v.SetDefault("antivirus.timeout", time.Minute)
+	v.SetDefault("antivirus.max_file_size", 100<<20)
 	v.SetDefault("assets_polling_interval", 2*time.Minute)
+	v.SetDefault("secrets.refresh_interval", time.Hour)
+	v.SetDefault("acme.http_addr", ":80")
+	v.SetDefault("acme.https_addr", ":443")
+	v.SetDefault("network_access.trusted_proxies", []string{"127.0.0.1", "::1"})
 	v.SetDefault("fs.versioning.max_number_of_versions_to_keep", 20)
 	v.SetDefault("fs.versioning.min_delay_between_two_versions", 15*time.Minute)
+	v.SetDefault("data_trash.doctypes", []string{"io.cozy.contacts", "io.cozy.contacts.groups", "io.cozy.bank.settings"})
+	v.SetDefault("data_trash.auto_clean_trashed_after", map[string]string{DefaultInstanceContext: "30D"})
+	v.SetDefault("oauth.secret_rotation_grace_period", 7*24*time.Hour)
+	v.SetDefault("oauth.stale_clients_notice", 14*24*time.Hour)
+	v.SetDefault("couchdb.tombstones_min_age", 30*24*time.Hour)
 }
 
 func envMap() map[string]string {
@@ -552,6 +854,16 @@ func UseViper(v *viper.Viper) error {
 		return err
 	}
 
+	egressProxies, err := makeEgressProxies(v)
+	if err != nil {
+		return err
+	}
+
+	networkAccess, err := makeNetworkAccess(v)
+	if err != nil {
+		return err
+	}
+
 	var subdomains SubdomainType
 	if subs := v.GetString("subdomains"); subs != "" {
 		switch subs {
@@ -644,9 +956,15 @@ func UseViper(v *viper.Viper) error {
 		adminSecretFile = defaultAdminSecretFileName
 	}
 
+	adminAccounts, err := makeAdminAccounts(v.GetStringMap("admin.accounts"))
+	if err != nil {
+		return err
+	}
+
 	jobs := Jobs{
 		Client:                jobsRedis,
 		ImageMagickConvertCmd: v.GetString("jobs.imagemagick_convert_cmd"),
+		FFmpegCmd:             v.GetString("jobs.ffmpeg_cmd"),
 		DefaultDurationToKeep: v.GetString("jobs.defaultDurationToKeep"),
 	}
 	{
@@ -681,6 +999,10 @@ func UseViper(v *viper.Viper) error {
 							if maxExecCount, ok := v.(int); ok {
 								w.MaxExecCount = &maxExecCount
 							}
+						case "max_per_instance":
+							if maxPerInstance, ok := v.(int); ok {
+								w.MaxPerInstance = &maxPerInstance
+							}
 						case "timeout":
 							if timeout, ok := v.(string); ok {
 								var d time.Duration
@@ -758,6 +1080,7 @@ func UseViper(v *viper.Viper) error {
 		AdminHost:           v.GetString("admin.host"),
 		AdminPort:           v.GetInt("admin.port"),
 		AdminSecretFileName: adminSecretFile,
+		AdminAccounts:       adminAccounts,
 
 		Subdomains:            subdomains,
 		Assets:                v.GetString("assets"),
@@ -766,6 +1089,7 @@ func UseViper(v *viper.Viper) error {
 		NoReplyAddr:           v.GetString("mail.noreply_address"),
 		NoReplyName:           v.GetString("mail.noreply_name"),
 		ReplyTo:               v.GetString("mail.reply_to"),
+		ReturnPath:            v.GetString("mail.return_path"),
 		GeoDB:                 v.GetString("geodb"),
 		PasswordResetInterval: v.GetDuration("password_reset_interval"),
 
@@ -779,20 +1103,83 @@ func UseViper(v *viper.Viper) error {
 			DefaultLayout:         defaultLayout,
 			CanQueryInfo:          v.GetBool("fs.can_query_info"),
 			AutoCleanTrashedAfter: v.GetStringMapString("fs.auto_clean_trashed_after"),
+			StorageForecastAlert:  v.GetStringMapString("fs.storage_forecast_alert"),
 			Versioning: FsVersioning{
 				MaxNumberToKeep:            v.GetInt("fs.versioning.max_number_of_versions_to_keep"),
 				MinDelayBetweenTwoVersions: v.GetDuration("fs.versioning.min_delay_between_two_versions"),
 			},
-			Contexts: v.GetStringMap("fs.contexts"),
+			UploadsDir:    v.GetString("fs.uploads_dir"),
+			Deduplication: v.GetBool("fs.deduplication"),
+			Contexts:      v.GetStringMap("fs.contexts"),
 		},
 		CouchDB: couch,
 		Jobs:    jobs,
 		Konnectors: Konnectors{
 			Cmd: v.GetString("konnectors.cmd"),
+			Proxy: KonnectorsProxy{
+				Listen:           v.GetString("konnectors.proxy.listen"),
+				URL:              v.GetString("konnectors.proxy.url"),
+				DefaultRateLimit: v.GetInt("konnectors.proxy.default_rate_limit"),
+				RateLimits:       makeRateLimits(v.GetStringMap("konnectors.proxy.rate_limits")),
+			},
+			BIWebhookSecrets: v.GetStringMapString("konnectors.bi_webhook_secrets"),
 		},
 		Move: Move{
 			URL: v.GetString("move.url"),
 		},
+		Standby: Standby{
+			URL:      v.GetString("standby.url"),
+			Interval: v.GetDuration("standby.interval"),
+		},
+		Geocoding: Geocoding{
+			Provider:   v.GetString("geocoding.provider"),
+			URL:        v.GetString("geocoding.url"),
+			CitiesFile: v.GetString("geocoding.cities_file"),
+			UserAgent:  v.GetString("geocoding.user_agent"),
+		},
+		Search: Search{
+			IndexDir:    v.GetString("search.index_dir"),
+			TikaURL:     v.GetString("search.tika_url"),
+			MaxFileSize: v.GetInt64("search.max_file_size"),
+		},
+		Antivirus: Antivirus{
+			Address:                v.GetString("antivirus.address"),
+			Timeout:                v.GetDuration("antivirus.timeout"),
+			MaxFileSize:            v.GetInt64("antivirus.max_file_size"),
+			AllowInfectedDownloads: v.GetBool("antivirus.allow_infected_downloads"),
+		},
+		Compression: Compression{
+			Disabled:  v.GetBool("compression.disabled"),
+			MinSize:   v.GetInt("compression.min_size"),
+			Encodings: v.GetStringSlice("compression.encodings"),
+		},
+		AccessStats: AccessStats{
+			Disabled:   v.GetBool("access_stats.disabled"),
+			SampleRate: v.GetInt("access_stats.sample_rate"),
+		},
+		DataTrash: DataTrash{
+			Doctypes:              v.GetStringSlice("data_trash.doctypes"),
+			AutoCleanTrashedAfter: v.GetStringMapString("data_trash.auto_clean_trashed_after"),
+		},
+		CDN: CDN{
+			URL: strings.TrimSuffix(v.GetString("cdn.url"), "/"),
+			Purge: CDNPurge{
+				Provider: v.GetString("cdn.purge.provider"),
+				URL:      v.GetString("cdn.purge.url"),
+				Token:    v.GetString("cdn.purge.token"),
+				ZoneID:   v.GetString("cdn.purge.zone_id"),
+			},
+		},
+		ACME: ACME{
+			Enabled:      v.GetBool("acme.enabled"),
+			Email:        v.GetString("acme.email"),
+			DirectoryURL: v.GetString("acme.directory_url"),
+			HTTPAddr:     v.GetString("acme.http_addr"),
+			HTTPSAddr:    v.GetString("acme.https_addr"),
+			Domains:      v.GetStringSlice("acme.domains"),
+			DNS:          v.GetStringMap("acme.dns"),
+		},
+		NetworkAccess: networkAccess,
 		Notifications: Notifications{
 			Development: v.GetBool("notifications.development"),
 
@@ -814,6 +1201,16 @@ func UseViper(v *viper.Viper) error {
 			APKPackageNames:       v.GetStringSlice("flagship.apk_package_names"),
 			APKCertificateDigests: v.GetStringSlice("flagship.apk_certificate_digests"),
 			AppleAppIDs:           v.GetStringSlice("flagship.apple_app_ids"),
+			PlayIntegrity: PlayIntegrity{
+				DecryptionKey:   v.GetString("flagship.play_integrity.decryption_key"),
+				VerificationKey: v.GetString("flagship.play_integrity.verification_key"),
+				CredentialsFile: v.GetString("flagship.play_integrity.credentials_file"),
+			},
+		},
+		OAuth: OAuth{
+			SecretRotationGracePeriod: v.GetDuration("oauth.secret_rotation_grace_period"),
+			CleanStaleClientsAfter:    v.GetStringMapString("oauth.clean_stale_clients_after"),
+			StaleClientsNotice:        v.GetDuration("oauth.stale_clients_notice"),
 		},
 		Lock:              lock.New(lockRedis),
 		SessionStorage:    sessionsRedis,
@@ -833,10 +1230,19 @@ func UseViper(v *viper.Viper) error {
 			LocalName:                 v.GetString("mail.local_name"),
 		},
 		MailPerContext: v.GetStringMap("mail.contexts"),
+		MailQueue: MailQueue{
+			MaxAttempts: v.GetInt("mail.queue.max_attempts"),
+			RetryDelay:  v.GetDuration("mail.queue.retry_delay"),
+			Bounces: MailBounces{
+				Token:             v.GetString("mail.bounces.token"),
+				MailgunSigningKey: v.GetString("mail.bounces.mailgun_signing_key"),
+			},
+		},
 		Contexts:       v.GetStringMap("contexts"),
 		Authentication: v.GetStringMap("authentication"),
 		Office:         office,
 		Registries:     regs,
+		EgressProxies:  egressProxies,
 
 		CSPAllowList:  cspAllowList,
 		CSPPerContext: cspPerContext,
@@ -892,7 +1298,7 @@ func UseViper(v *viper.Viper) error {
 }
 
 func makeCouch(v *viper.Viper) (CouchDB, error) {
-	var couch CouchDB
+	couch := CouchDB{TombstonesMinAge: v.GetDuration("couchdb.tombstones_min_age")}
 	couchClient, _, err := tlsclient.NewHTTPClient(tlsclient.HTTPEndpoint{
 		Timeout:             10 * time.Second,
 		MaxIdleConnsPerHost: 20,
@@ -1031,6 +1437,83 @@ func makeOffice(v *viper.Viper) (map[string]Office, error) {
 	return office, nil
 }
 
+func makeNetworkAccess(v *viper.Viper) (NetworkAccess, error) {
+	var na NetworkAccess
+	var err error
+	na.TrustedProxies, err = netaccess.ParseNetworks(v.GetStringSlice("network_access.trusted_proxies"))
+	if err != nil {
+		return na, fmt.Errorf("Invalid network_access.trusted_proxies: %w", err)
+	}
+	na.Admin, err = netaccess.NewRules(
+		v.GetStringSlice("network_access.admin.allow"),
+		v.GetStringSlice("network_access.admin.deny"),
+	)
+	if err != nil {
+		return na, fmt.Errorf("Invalid network_access.admin: %w", err)
+	}
+	na.Contexts = make(map[string]*netaccess.Policy)
+	for name, ctx := range v.GetStringMap("contexts") {
+		settings, _ := ctx.(map[string]interface{})
+		rules, ok := settings["network_access"].(map[string]interface{})
+		if !ok {
+			na.Contexts[name] = nil
+			continue
+		}
+		policy, err := netaccess.FromMap(rules)
+		if err != nil {
+			return na, fmt.Errorf("Invalid network_access for the context %s: %w", name, err)
+		}
+		na.Contexts[name] = policy
+	}
+	return na, nil
+}
+
+func makeEgressProxies(v *viper.Viper) (map[string]EgressProxy, error) {
+	proxies := make(map[string]EgressProxy)
+	for k, v := range v.GetStringMap("egress_proxy") {
+		ctx, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("Bad format in the egress_proxy section of the configuration file")
+		}
+		raw, ok := ctx["url"].(string)
+		if !ok {
+			return nil, errors.New("Bad format in the egress_proxy section of the configuration file")
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("Invalid URL for the egress proxy of %s: %q", k, raw)
+		}
+		var noProxy []string
+		if list, ok := ctx["no_proxy"].([]interface{}); ok {
+			for _, item := range list {
+				if host, ok := item.(string); ok {
+					noProxy = append(noProxy, host)
+				}
+			}
+		}
+		caFile, _ := ctx["ca_file"].(string)
+		proxies[k] = EgressProxy{
+			URL:     u,
+			NoProxy: noProxy,
+			CAFile:  caFile,
+		}
+	}
+	return proxies, nil
+}
+
+// GetEgressProxy returns the configuration of the outbound proxy for the
+// given context, or for the default context if the context has no proxy.
+func GetEgressProxy(contextName string) (EgressProxy, bool) {
+	if config == nil {
+		return EgressProxy{}, false
+	}
+	if proxy, ok := config.EgressProxies[contextName]; ok {
+		return proxy, true
+	}
+	proxy, ok := config.EgressProxies[DefaultInstanceContext]
+	return proxy, ok
+}
+
 func makeSMS(raw map[string]interface{}) map[string]SMS {
 	sms := make(map[string]SMS)
 	for name, val := range raw {
@@ -1049,6 +1532,41 @@ func makeSMS(raw map[string]interface{}) map[string]SMS {
 	return sms
 }
 
+func makeAdminAccounts(raw map[string]interface{}) (map[string]AdminAccount, error) {
+	accounts := make(map[string]AdminAccount, len(raw))
+	for name, val := range raw {
+		entry, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Invalid admin account %q", name)
+		}
+		role, _ := entry["role"].(string)
+		switch role {
+		case AdminRoleOperator, AdminRoleBilling, AdminRoleSupport:
+		default:
+			return nil, fmt.Errorf("Invalid role %q for the admin account %q", role, name)
+		}
+		secretFile, _ := entry["secret_filename"].(string)
+		if secretFile == "" {
+			return nil, fmt.Errorf("Missing secret_filename for the admin account %q", name)
+		}
+		var contexts []string
+		if list, ok := entry["contexts"].([]interface{}); ok {
+			for _, ctx := range list {
+				if str, ok := ctx.(string); ok {
+					contexts = append(contexts, str)
+				}
+			}
+		}
+		accounts[name] = AdminAccount{
+			Name:           name,
+			Role:           role,
+			Contexts:       contexts,
+			SecretFileName: secretFile,
+		}
+	}
+	return accounts, nil
+}
+
 func createTestViper() *viper.Viper {
 	v := viper.New()
 	v.SetConfigName("cozy.test")
@@ -1144,3 +1662,18 @@ func parseURL(u string) (*url.URL, *url.Userinfo, error) {
 	parsedURL.User = nil
 	return parsedURL, user, nil
 }
+
+func makeRateLimits(raw map[string]interface{}) map[string]int {
+	limits := make(map[string]int, len(raw))
+	for domain, val := range raw {
+		switch n := val.(type) {
+		case int:
+			limits[domain] = n
+		case int64:
+			limits[domain] = int(n)
+		case float64:
+			limits[domain] = int(n)
+		}
+	}
+	return limits
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/netaccess"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/gomail"
 	"github.com/sirupsen/logrus"
@@ -28,6 +30,7 @@ func TestConfigUnmarshal(t *testing.T) {
 	assert.Equal(t, cfg.NoReplyAddr, "foo@bar.baz")
 	assert.Equal(t, cfg.NoReplyName, "My Cozy")
 	assert.Equal(t, cfg.ReplyTo, "support@cozycloud.cc")
+	assert.Equal(t, cfg.ReturnPath, "bounces@bar.baz")
 	assert.Equal(t, cfg.GeoDB, "/geo/db/path")
 	assert.Equal(t, cfg.PasswordResetInterval, time.Hour)
 
@@ -52,6 +55,7 @@ func TestConfigUnmarshal(t *testing.T) {
 	one := 1
 	oneHour := time.Hour
 	assert.Equal(t, "some-cmd", cfg.Jobs.ImageMagickConvertCmd)
+	assert.Equal(t, "some-ffmpeg", cfg.Jobs.FFmpegCmd)
 	assert.Equal(t, "1H", cfg.Jobs.DefaultDurationToKeep)
 	assert.Equal(t, true, cfg.Jobs.AllowList)
 	assert.EqualValues(t, []Worker{
@@ -66,6 +70,57 @@ func TestConfigUnmarshal(t *testing.T) {
 	assert.Equal(t, "some-cmd", cfg.Konnectors.Cmd)
 	assert.Equal(t, "http://some-url", cfg.Move.URL)
 
+	// Geocoding
+	assert.Equal(t, Geocoding{
+		Provider:  "nominatim",
+		URL:       "http://nominatim.example.org",
+		UserAgent: "cozy-stack-test",
+	}, cfg.Geocoding)
+
+	// Search
+	assert.Equal(t, Search{
+		IndexDir:    "/var/lib/cozy/search",
+		TikaURL:     "http://tika.example.org:9998",
+		MaxFileSize: 50 << 20,
+	}, cfg.Search)
+
+	// Compression
+	assert.Equal(t, Compression{
+		MinSize:   2048,
+		Encodings: []string{"zstd", "gzip"},
+	}, cfg.Compression)
+
+	// CDN
+	assert.Equal(t, CDN{
+		URL: "https://assets.example.org",
+		Purge: CDNPurge{
+			Provider: "cloudflare",
+			Token:    "some-token",
+			ZoneID:   "some-zone",
+		},
+	}, cfg.CDN)
+
+	// ACME
+	assert.Equal(t, ACME{
+		Enabled:   true,
+		Email:     "admin@example.org",
+		HTTPAddr:  ":80",
+		HTTPSAddr: ":8443",
+		Domains:   []string{"example.org"},
+		DNS: map[string]interface{}{
+			"provider": "webhook",
+			"url":      "https://dns.example.org/",
+		},
+	}, cfg.ACME)
+
+	// Network access
+	assert.Len(t, cfg.NetworkAccess.TrustedProxies, 1)
+	assert.Equal(t, "10.0.0.1/32", cfg.NetworkAccess.TrustedProxies[0].String())
+	assert.Equal(t, []string{"10.0.0.0/8"}, cfg.NetworkAccess.Admin.Allow)
+	assert.Empty(t, cfg.NetworkAccess.Admin.Deny)
+	assert.NoError(t, cfg.NetworkAccess.Admin.Check(net.ParseIP("10.1.2.3")))
+	assert.Equal(t, netaccess.ErrForbidden, cfg.NetworkAccess.Admin.Check(net.ParseIP("192.0.2.1")))
+
 	// Notifications
 	assert.EqualValues(t, Notifications{
 		Development:            true,
@@ -100,6 +155,14 @@ func TestConfigUnmarshal(t *testing.T) {
 	assert.EqualValues(t, map[string]interface{}{
 		"my-context": map[string]interface{}{"host": "-"},
 	}, cfg.MailPerContext)
+	assert.Equal(t, MailQueue{
+		MaxAttempts: 4,
+		RetryDelay:  10 * time.Minute,
+		Bounces: MailBounces{
+			Token:             "some-bounces-token",
+			MailgunSigningKey: "some-signing-key",
+		},
+	}, cfg.MailQueue)
 
 	// Contexts
 	assert.EqualValues(t, map[string]interface{}{
@@ -266,3 +329,19 @@ func regsToStrings(regs []*url.URL) []string {
 	}
 	return ss
 }
+
+func TestSecretsInConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "couch_password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("s3cr3t\n"), 0600))
+	cfgFile := filepath.Join(dir, "cozy.yaml")
+	content := "secrets_test:\n  password: {{ secret \"file:" + secretFile + "\" }}\n"
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+
+	require.NoError(t, mergeConfigFiles([]string{cfgFile}))
+	assert.Equal(t, "s3cr3t", viper.GetString("secrets_test.password"))
+
+	content = "password: {{ secret \"file:" + filepath.Join(dir, "missing") + "\" }}\n"
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+	assert.Error(t, mergeConfigFiles([]string{cfgFile}))
+}
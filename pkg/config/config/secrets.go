@@ -0,0 +1,88 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/secrets"
+	"github.com/spf13/viper"
+)
+
+// secretsFuncsMap adds the secret function to the templates of the
+// configuration files, for resolving a value from a secrets backend:
+//
+//	password: {{ secret "vault:secret/data/cozy#mail_password" }}
+var secretsFuncsMap = map[string]interface{}{
+	"secret": secrets.Resolve,
+}
+
+var secretsRefreshOnce sync.Once
+
+// startSecretsRefresh fetches again the secrets periodically. When a secret
+// has been rotated, the configuration files are executed again, and the
+// parts of the configuration that are read on each usage are updated.
+func startSecretsRefresh(cfgFiles []string, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	secretsRefreshOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				changed, err := secrets.Refresh()
+				if err != nil {
+					log.Warnf("Cannot refresh the secrets: %s", err)
+				}
+				if !changed {
+					continue
+				}
+				if err := mergeConfigFiles(cfgFiles); err != nil {
+					log.Errorf("Cannot reload the config with the new secrets: %s", err)
+					continue
+				}
+				if err := reloadSecrets(viper.GetViper()); err != nil {
+					log.Errorf("Cannot reload the config with the new secrets: %s", err)
+					continue
+				}
+				log.Infof("The config has been reloaded with the new secrets")
+			}
+		}()
+	})
+}
+
+// reloadSecrets updates the parts of the configuration that can contain
+// secrets and are not used only when the stack starts. The other secrets, like
+// the Swift credentials or the redis password, require a restart.
+func reloadSecrets(v *viper.Viper) error {
+	couch, err := makeCouch(v)
+	if err != nil {
+		return err
+	}
+	config.CouchDB = couch
+
+	mail := *config.Mail
+	mail.Host = v.GetString("mail.host")
+	mail.Username = v.GetString("mail.username")
+	mail.Password = v.GetString("mail.password")
+	config.Mail = &mail
+	config.MailPerContext = v.GetStringMap("mail.contexts")
+	config.MailQueue.Bounces = MailBounces{
+		Token:             v.GetString("mail.bounces.token"),
+		MailgunSigningKey: v.GetString("mail.bounces.mailgun_signing_key"),
+	}
+
+	egressProxies, err := makeEgressProxies(v)
+	if err != nil {
+		return err
+	}
+	config.EgressProxies = egressProxies
+
+	config.Standby.URL = v.GetString("standby.url")
+	config.Konnectors.BIWebhookSecrets = v.GetStringMapString("konnectors.bi_webhook_secrets")
+	config.Authentication = v.GetStringMap("authentication")
+	config.Notifications.AndroidAPIKey = v.GetString("notifications.android_api_key")
+	config.Notifications.Contexts = makeSMS(v.GetStringMap("notifications.contexts"))
+	config.CDN.Purge.Token = v.GetString("cdn.purge.token")
+	return nil
+}
@@ -16,6 +16,9 @@ const (
 	// referencing a directory that contains the notes with collaborative
 	// edition.
 	NotesSlug = "notes"
+	// PhotosSlug is the slug of the photos app, which has the public pages
+	// for the shared albums.
+	PhotosSlug = "photos"
 )
 
 const (
@@ -27,6 +30,12 @@ const (
 	ClientsUsageID = "io.cozy.settings.clients-usage"
 	// DiskUsageID is the id of the settings JSON-API response for disk-usage
 	DiskUsageID = "io.cozy.settings.disk-usage"
+	// DiskUsageTrendID is the id of the settings JSON-API response for the
+	// trend of the disk-usage
+	DiskUsageTrendID = "io.cozy.settings.disk-usage.trend"
+	// AccessStatsID is the id of the settings JSON-API response for the
+	// statistics of the accesses to the doctypes
+	AccessStatsID = "io.cozy.settings.access-stats"
 	// InstanceSettingsID is the id of settings document for the instance
 	InstanceSettingsID = "io.cozy.settings.instance"
 	// CapabilitiesSettingsID is the id of the settings document with the
@@ -35,6 +44,9 @@ const (
 	// PassphraseParametersID is the id of settings document for the passphrase
 	// parameters used to hash the master password on client side.
 	PassphraseParametersID = "io.cozy.settings.passphrase"
+	// OnboardingSettingsID is the id of the settings document with the state
+	// of the onboarding driven by the flagship app.
+	OnboardingSettingsID = "io.cozy.settings.onboarding"
 	// FlagsSettingsID is the id of settings document with the feature flags.
 	FlagsSettingsID = "io.cozy.settings.flags"
 	// InstanceFlagsSettingsID is the id of the settings documents with the
@@ -52,6 +64,9 @@ const (
 	// DefaultFlagsSettingsID is the id of the settings documents with the
 	// default feature flags.
 	DefaultFlagsSettingsID = "io.cozy.settings.flags.default"
+	// SMTPSettingsID is the id of the settings document with the outgoing
+	// mail server configured for this instance.
+	SMTPSettingsID = "io.cozy.settings.smtp"
 )
 
 const (
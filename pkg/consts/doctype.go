@@ -14,6 +14,9 @@ const (
 	// AppsOpenParameters doc type for the parameters used by the flagship to
 	// open a webapp
 	AppsOpenParameters = "io.cozy.apps.open"
+	// AppsData doc type for the list of the documents created by an app, used
+	// before uninstalling it
+	AppsData = "io.cozy.apps.data"
 	// AppLogs doc type for logs sent by apps and konnectors
 	AppLogs = "io.cozy.apps.logs"
 	// Konnectors doc type for konnector application manifests
@@ -28,6 +31,9 @@ const (
 	ExportsRequests = "io.cozy.exports.requests"
 	// Imports doc type for global exports archives
 	Imports = "io.cozy.imports"
+	// Standby doc type for the state of the warm standby replication of an
+	// instance (in the global database)
+	Standby = "io.cozy.standby"
 	// Doctypes doc type for doctype list
 	Doctypes = "io.cozy.doctypes"
 	// Files doc type for type for files and directories
@@ -41,6 +47,9 @@ const (
 	// Thumbnails is a synthetic doctype for thumbnails, used for realtime
 	// events
 	Thumbnails = "io.cozy.files.thumbnails"
+	// FilesQualifications is a synthetic doctype for the files qualified
+	// automatically by the stack, used for realtime events
+	FilesQualifications = "io.cozy.files.qualifications"
 	// CertifiedCarbonCopy is a synthetic doctype, used for given permission to
 	// add the carbonCopy metadata on files
 	CertifiedCarbonCopy = "io.cozy.certified.carbon_copy"
@@ -50,20 +59,54 @@ const (
 	// DirSizes is a synthetic doctype, used for giving the size of a
 	// directory.
 	DirSizes = "io.cozy.files.sizes"
+	// DirUsages doc type is used for keeping the size of the directories
+	// with a quota.
+	DirUsages = "io.cozy.files.usages"
+	// FilesBlobs doc type is used for counting the references to the storage
+	// objects shared by the files with the same content.
+	FilesBlobs = "io.cozy.files.blobs"
 	// PhotosAlbums doc type for photos albums
 	PhotosAlbums = "io.cozy.photos.albums"
+	// PhotosAlbumsLinks doc type for the options and the views of the public
+	// links to the albums
+	PhotosAlbumsLinks = "io.cozy.photos.albums.links"
+	// PhotosHashes doc type for the perceptual hashes of the photos, used for
+	// finding the near-duplicates
+	PhotosHashes = "io.cozy.photos.hashes"
+	// PhotosSuggestions doc type for the groups of duplicates and bursts of
+	// photos that the user may want to clean up
+	PhotosSuggestions = "io.cozy.photos.suggestions"
+	// PhotosLocations doc type for the GPS coordinates and the place names of
+	// the photos
+	PhotosLocations = "io.cozy.photos.locations"
+	// PhotosGeoClusters doc type for the groups of photos taken near each
+	// other, for the map view
+	PhotosGeoClusters = "io.cozy.photos.geoclusters"
 	// Intents doc type for intents persisted in couchdb
 	Intents = "io.cozy.intents"
 	// Jobs doc type for queued jobs
 	Jobs = "io.cozy.jobs"
 	// JobEvents doc type for real time events sent by jobs
 	JobEvents = "io.cozy.jobs.events"
+	// JobsProgress doc type for the real time events with the progress of a
+	// job, like the number of files fetched by a konnector
+	JobsProgress = "io.cozy.jobs.progress"
+	// JobsStats doc type is used for the statistics about the durations of
+	// the jobs of a worker type
+	JobsStats = "io.cozy.jobs.stats"
 	// Support doc type for sending mail to the support
 	Support = "io.cozy.support"
 	// Notifications doc type for notifications
 	Notifications = "io.cozy.notifications"
+	// Reminders doc type for the reminders delivered by the notification
+	// center at a given time
+	Reminders = "io.cozy.reminders"
+	// Comments doc type for the comments written on the documents and files
+	Comments = "io.cozy.comments"
 	// OAuthAccessCodes doc type for OAuth2 access codes
 	OAuthAccessCodes = "io.cozy.oauth.access_codes"
+	// OAuthDeviceCodes doc type for the OAuth2 device authorization grant
+	OAuthDeviceCodes = "io.cozy.oauth.device_codes"
 	// OAuthClients doc type for OAuth2 clients
 	OAuthClients = "io.cozy.oauth.clients"
 	// Permissions doc type for permissions identifying a connection
@@ -101,6 +144,9 @@ const (
 	Accounts = "io.cozy.accounts"
 	// SoftDeletedAccounts doc type for old revisions of deleted accounts
 	SoftDeletedAccounts = "io.cozy.accounts.soft_deleted"
+	// AccountsDelegations doc type for the accounts delegated to other
+	// instances of the same context
+	AccountsDelegations = "io.cozy.accounts.delegations"
 	// AccountTypes doc type for account types
 	AccountTypes = "io.cozy.account_types"
 	// BitwardenProfiles doc type for Bitwarden profile
@@ -122,6 +168,9 @@ const (
 	// NotesTelepointers doc type is used for the position of the cursor in a
 	// note.
 	NotesTelepointers = "io.cozy.notes.telepointers"
+	// NotesPresences doc type is used for realtime events about the users
+	// that are viewing a note.
+	NotesPresences = "io.cozy.notes.presences"
 	// NotesEvents doc type is used for realtime events related to a note, like
 	// a change of title.
 	NotesEvents = "io.cozy.notes.events"
@@ -131,7 +180,62 @@ const (
 	NotesImages = "io.cozy.notes.images"
 	// OfficeURL doc type is used to return the URL where an office document can be edited.
 	OfficeURL = "io.cozy.office.url"
+	// OfficeSessions doc type is used for the realtime events about the users
+	// editing an office document.
+	OfficeSessions = "io.cozy.office.sessions"
 	// AuthConfirmations doc type used for realtime events when confirming
 	// authentication.
 	AuthConfirmations = "io.cozy.auth.confirmations"
+	// CMISCheckouts doc type is used to know which files have been checked
+	// out via the CMIS browser binding.
+	CMISCheckouts = "io.cozy.cmis.checkouts"
+	// GDriveSyncs doc type is used for the configuration and the state of
+	// the synchronizations with Google Drive.
+	GDriveSyncs = "io.cozy.gdrive.syncs"
+	// GDriveEntries doc type is used to keep the state of the synchronized
+	// files and folders at the last synchronization with Google Drive.
+	GDriveEntries = "io.cozy.gdrive.entries"
+	// MailsQueue doc type is used for the outgoing mails, with their
+	// delivery status.
+	MailsQueue = "io.cozy.mails.queue"
+	// MailsSuppressions doc type is used for the addresses that must not
+	// receive mails anymore, after a bounce or a complaint.
+	MailsSuppressions = "io.cozy.mails.suppressions"
+	// MailsDKIM doc type is used for the DKIM keys of the mail domains.
+	MailsDKIM = "io.cozy.mails.dkim"
+	// ACMECertificates doc type is used for the TLS certificates and the
+	// account key obtained via ACME.
+	ACMECertificates = "io.cozy.acme.certificates"
+	// AppPasswords doc type is used for the app-specific passwords, that
+	// can be used by the protocol clients instead of the passphrase.
+	AppPasswords = "io.cozy.app_passwords"
+	// UserActions doc type is used for the actions required from the user
+	// to fix a konnector (new password, new terms to accept, etc.).
+	UserActions = "io.cozy.user_actions"
+	// DoctypesSchemas doc type is used for the JSON schemas registered for
+	// the doctypes of an instance, by the apps or via the admin API.
+	DoctypesSchemas = "io.cozy.doctypes.schemas"
+	// Activities doc type is used for the timeline of the notable events of
+	// an instance (files added by konnectors, sharings accepted, etc.).
+	Activities = "io.cozy.activities"
+	// CalendarEvents doc type is used for the events of the calendars.
+	CalendarEvents = "io.cozy.calendar.events"
+	// CalendarFeeds doc type is used for the public ICS feeds of the
+	// calendars, that can be used to subscribe from another calendar service.
+	CalendarFeeds = "io.cozy.calendar.feeds"
+	// StorageSnapshots doc type is used for the daily snapshots of the disk
+	// usage of an instance, used to compute the trend and the forecast.
+	StorageSnapshots = "io.cozy.storage.snapshots"
+	// AccessStats doc type is used for the daily statistics of the accesses
+	// to the doctypes by the apps.
+	AccessStats = "io.cozy.access.stats"
+	// BIWebhooks doc type is used for the inbox of the webhooks received from
+	// the bank aggregator, with their processing state.
+	BIWebhooks = "io.cozy.bi.webhooks"
+	// KonnectorsStats doc type is used for the number of successes and
+	// failures of the konnectors, for each version.
+	KonnectorsStats = "io.cozy.konnectors.stats"
+	// OrganizationUsers doc type is used for the users of an instance in
+	// organization mode, with their credentials and role.
+	OrganizationUsers = "io.cozy.organization.users"
 )
@@ -5,12 +5,14 @@ import "time"
 // This is the list of possible audience values for JWT.
 const (
 	AppAudience               = "app"          // used by client-side apps
+	WebviewAudience           = "webview"      // used by apps in the webviews of the flagship app
 	KonnectorAudience         = "konn"         // used by konnectors
 	CLIAudience               = "cli"          // used by command line interface
 	ShareAudience             = "share"        // used for share by links code
 	RegistrationTokenAudience = "registration" // OAuth registration tokens
 	AccessTokenAudience       = "access"       // OAuth access tokens
 	RefreshTokenAudience      = "refresh"      // OAuth refresh tokens
+	DownloadAudience          = "download"     // signed URLs for downloading a file
 )
 
 // TokenValidityDuration is the duration where a token is valid in seconds (1 week)
@@ -18,6 +20,7 @@ var (
 	DefaultValidityDuration = 24 * time.Hour
 
 	AppTokenValidityDuration       = 24 * time.Hour
+	WebviewTokenValidityDuration   = 15 * time.Minute
 	KonnectorTokenValidityDuration = 30 * time.Minute
 	CLITokenValidityDuration       = 30 * time.Minute
 
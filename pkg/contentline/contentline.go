@@ -0,0 +1,172 @@
+// Package contentline can be used to read the content lines of the iCalendar
+// (RFC 5545) and vCard (RFC 6350) formats, that share the same syntax:
+//
+//	NAME;PARAM1=VALUE;PARAM2="QUOTED:VALUE":value
+//
+// The long lines are folded, by inserting a CRLF followed by a space or a tab.
+package contentline
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// MaxLineSize is the maximal size in bytes of an unfolded content line.
+const MaxLineSize = 1 << 20
+
+// ErrInvalidLine is used when a content line has no value.
+var ErrInvalidLine = errors.New("invalid content line")
+
+// Line is a content line, with the name and the parameters in upper case.
+type Line struct {
+	// Number is the number of the (first physical) line in the file.
+	Number int
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param returns the value of the parameter with the given name, or an empty
+// string if the parameter is missing.
+func (l *Line) Param(name string) string {
+	return l.Params[name]
+}
+
+// Read unfolds and parses all the content lines of r. The empty lines are
+// skipped.
+func Read(r io.Reader) ([]*Line, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxLineSize)
+
+	var lines []*Line
+	var current strings.Builder
+	start, number := 0, 0
+	flush := func() error {
+		if current.Len() == 0 {
+			return nil
+		}
+		line, err := parse(current.String())
+		current.Reset()
+		if err != nil {
+			return err
+		}
+		line.Number = start
+		lines = append(lines, line)
+		return nil
+	}
+
+	for scanner.Scan() {
+		number++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			current.WriteString(text[1:])
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		start = number
+		current.WriteString(text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parse splits a content line in its name, parameters and value. The colons
+// and semicolons inside quoted parameter values are not separators.
+func parse(text string) (*Line, error) {
+	line := &Line{Params: make(map[string]string)}
+	quoted := false
+	nameEnd, valueStart := -1, -1
+	var params []string
+	paramStart := -1
+	for i, c := range text {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			if nameEnd < 0 {
+				nameEnd = i
+			} else {
+				params = append(params, text[paramStart:i])
+			}
+			paramStart = i + 1
+		}
+		if !quoted && c == ':' {
+			valueStart = i + 1
+			break
+		}
+	}
+	if nameEnd <= 0 || valueStart < 0 {
+		return nil, ErrInvalidLine
+	}
+	line.Name = strings.ToUpper(text[:nameEnd])
+	// A group can prefix the name in the vCard format (item1.EMAIL)
+	if dot := strings.LastIndexByte(line.Name, '.'); dot >= 0 {
+		line.Name = line.Name[dot+1:]
+	}
+	for _, param := range params {
+		k, v, _ := strings.Cut(param, "=")
+		line.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	line.Value = text[valueStart:]
+	return line, nil
+}
+
+// Unescape decodes a TEXT value: \n (or \N) is a new line, and \\, \; and \,
+// are the escaped characters.
+func Unescape(value string) string {
+	if !strings.ContainsRune(value, '\\') {
+		return value
+	}
+	var b strings.Builder
+	escaped := false
+	for _, c := range value {
+		if !escaped {
+			if c == '\\' {
+				escaped = true
+			} else {
+				b.WriteRune(c)
+			}
+			continue
+		}
+		escaped = false
+		if c == 'n' || c == 'N' {
+			b.WriteByte('\n')
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// SplitValue splits a structured value (like the N or ADR properties of a
+// vCard) on the separator that is not escaped, and unescapes the components.
+func SplitValue(value string, sep rune) []string {
+	var parts []string
+	start := 0
+	escaped := false
+	for i, c := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == sep:
+			parts = append(parts, Unescape(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, Unescape(value[start:]))
+}
@@ -0,0 +1,49 @@
+package contentline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"DTSTART;TZID=\"Europe/Paris\";VALUE=DATE-TIME:20240301T120000\r\n" +
+		"DESCRIPTION:A long description\r\n" +
+		"  that is folded\r\n" +
+		"\r\n" +
+		"item1.EMAIL;type=INTERNET:bob@example.net\r\n" +
+		"URL:https://example.net/a:b\r\n" +
+		"END:VCALENDAR\r\n"
+	lines, err := Read(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, lines, 6)
+
+	assert.Equal(t, "BEGIN", lines[0].Name)
+	assert.Equal(t, "VCALENDAR", lines[0].Value)
+
+	assert.Equal(t, "DTSTART", lines[1].Name)
+	assert.Equal(t, "Europe/Paris", lines[1].Param("TZID"))
+	assert.Equal(t, "DATE-TIME", lines[1].Param("VALUE"))
+	assert.Equal(t, "20240301T120000", lines[1].Value)
+
+	assert.Equal(t, "A long description that is folded", lines[2].Value)
+	assert.Equal(t, 3, lines[2].Number)
+
+	assert.Equal(t, "EMAIL", lines[3].Name)
+	assert.Equal(t, "INTERNET", lines[3].Param("TYPE"))
+	assert.Equal(t, 6, lines[3].Number)
+
+	assert.Equal(t, "https://example.net/a:b", lines[4].Value)
+
+	_, err = Read(strings.NewReader("NO VALUE\r\n"))
+	assert.ErrorIs(t, err, ErrInvalidLine)
+}
+
+func TestUnescape(t *testing.T) {
+	assert.Equal(t, "Lunch, with Bob; at noon\nBring \\ cake",
+		Unescape(`Lunch\, with Bob\; at noon\nBring \\ cake`))
+	assert.Equal(t, []string{"Doe", "John;Jr", "", ""}, SplitValue(`Doe;John\;Jr;;`, ';'))
+}
@@ -460,6 +460,11 @@ func CreateDB(db prefixer.Prefixer, doctype string) error {
 			_ = DefineIndex(db, index)
 		}
 	}
+	if ContextIndexes != nil {
+		for _, index := range ContextIndexes(db, doctype) {
+			_ = DefineIndex(db, index)
+		}
+	}
 	return nil
 }
 
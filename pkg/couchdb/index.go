@@ -14,7 +14,12 @@ import (
 
 // IndexViewsVersion is the version of current definition of views & indexes.
 // This number should be incremented when this file changes.
-const IndexViewsVersion int = 36
+const IndexViewsVersion int = 40
+
+// ContextIndexes can be set to return the indexes that are declared in the
+// config of the context of an instance, for the custom doctypes. They are
+// created with the database.
+var ContextIndexes func(db prefixer.Prefixer, doctype string) []*mango.Index
 
 // Indexes is the index list required by an instance to run properly.
 var Indexes = []*mango.Index{
@@ -66,6 +71,22 @@ var Indexes = []*mango.Index{
 	// Used to lookup the bitwarden ciphers
 	mango.MakeIndex(consts.BitwardenCiphers, "by-folder-id", mango.IndexDef{Fields: []string{"folder_id"}}),
 	mango.MakeIndex(consts.BitwardenCiphers, "by-organization-id", mango.IndexDef{Fields: []string{"organization_id"}}),
+
+	// Used to find the device code from the user code typed on the
+	// verification page
+	mango.MakeIndex(consts.OAuthDeviceCodes, "by-user-code", mango.IndexDef{Fields: []string{"user_code"}}),
+
+	// Used to find the photos of a geo-cluster
+	mango.MakeIndex(consts.PhotosLocations, "by-geohash", mango.IndexDef{Fields: []string{"geohash"}}),
+
+	// Used to paginate the timeline of the activities, optionally filtered by
+	// doctype
+	mango.MakeIndex(consts.Activities, "by-date", mango.IndexDef{Fields: []string{"date"}}),
+	mango.MakeIndex(consts.Activities, "by-doctype-and-date", mango.IndexDef{Fields: []string{"doctype", "date"}}),
+
+	// Used to list the comments on a document, and the replies of a thread
+	mango.MakeIndex(consts.Comments, "by-target", mango.IndexDef{Fields: []string{"target.doctype", "target.id", "created_at"}}),
+	mango.MakeIndex(consts.Comments, "by-parent-id", mango.IndexDef{Fields: []string{"parent_id"}}),
 }
 
 // DiskUsageView is the view used for computing the disk usage for files
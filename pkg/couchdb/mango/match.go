@@ -0,0 +1,366 @@
+package mango
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// This file provides a go-side evaluation of a subset of the mango selectors,
+// for the documents that are not queried from CouchDB (like the documents in
+// the realtime events). The strings are compared by their bytes, not with the
+// UCA algorithm of CouchDB.
+
+var conditionOperators = map[string]struct{}{
+	"$eq":     {},
+	"$ne":     {},
+	"$gt":     {},
+	"$gte":    {},
+	"$lt":     {},
+	"$lte":    {},
+	"$in":     {},
+	"$nin":    {},
+	"$exists": {},
+	"$regex":  {},
+	"$size":   {},
+	"$not":    {},
+}
+
+// ValidateSelector returns an error if the selector uses an operator that is
+// not supported by Matches.
+func ValidateSelector(selector Map) error {
+	for key, value := range selector {
+		switch key {
+		case string(and), string(or), string(nor):
+			list, ok := value.([]interface{})
+			if !ok || len(list) == 0 {
+				return fmt.Errorf("%s expects a non-empty list of selectors", key)
+			}
+			for _, item := range list {
+				sub, ok := item.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("%s expects a list of selectors", key)
+				}
+				if err := ValidateSelector(sub); err != nil {
+					return err
+				}
+			}
+		case string(not):
+			sub, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s expects a selector", key)
+			}
+			if err := ValidateSelector(sub); err != nil {
+				return err
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return fmt.Errorf("unsupported operator %s", key)
+			}
+			if err := validateCondition(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateCondition(value interface{}) error {
+	cond, ok := value.(map[string]interface{})
+	if !ok || !isOperatorMap(cond) {
+		if ok {
+			return ValidateSelector(cond)
+		}
+		return nil
+	}
+	for op, arg := range cond {
+		if _, ok := conditionOperators[op]; !ok {
+			return fmt.Errorf("unsupported operator %s", op)
+		}
+		switch op {
+		case "$in", "$nin":
+			if _, ok := arg.([]interface{}); !ok {
+				return fmt.Errorf("%s expects a list", op)
+			}
+		case "$exists":
+			if _, ok := arg.(bool); !ok {
+				return fmt.Errorf("%s expects a boolean", op)
+			}
+		case "$regex":
+			pattern, ok := arg.(string)
+			if !ok {
+				return fmt.Errorf("%s expects a string", op)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return err
+			}
+		case "$size":
+			if _, ok := arg.(float64); !ok {
+				return fmt.Errorf("%s expects a number", op)
+			}
+		case "$not":
+			if err := validateCondition(arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the document, as decoded from JSON, matches the
+// selector. The selector must have been validated with ValidateSelector.
+func Matches(selector Map, doc map[string]interface{}) bool {
+	return matchSelector(selector, doc, "")
+}
+
+// FieldChanged returns true if the value of the field (with a dotted path for
+// the nested fields) is not the same in the two documents.
+func FieldChanged(old, doc map[string]interface{}, field string) bool {
+	before, foundBefore := lookup(old, field)
+	after, foundAfter := lookup(doc, field)
+	if foundBefore != foundAfter {
+		return true
+	}
+	return !equal(before, after)
+}
+
+func matchSelector(selector map[string]interface{}, doc map[string]interface{}, prefix string) bool {
+	for key, value := range selector {
+		switch key {
+		case string(and):
+			for _, item := range value.([]interface{}) {
+				if !matchSelector(item.(map[string]interface{}), doc, prefix) {
+					return false
+				}
+			}
+		case string(or):
+			found := false
+			for _, item := range value.([]interface{}) {
+				if matchSelector(item.(map[string]interface{}), doc, prefix) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		case string(nor):
+			for _, item := range value.([]interface{}) {
+				if matchSelector(item.(map[string]interface{}), doc, prefix) {
+					return false
+				}
+			}
+		case string(not):
+			if matchSelector(value.(map[string]interface{}), doc, prefix) {
+				return false
+			}
+		default:
+			field := key
+			if prefix != "" {
+				field = prefix + "." + key
+			}
+			if !matchField(doc, field, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchField(doc map[string]interface{}, field string, value interface{}) bool {
+	cond, ok := value.(map[string]interface{})
+	if ok && !isOperatorMap(cond) {
+		// {"a": {"b": 1}} is the same as {"a.b": 1}
+		return matchSelector(cond, doc, field)
+	}
+	actual, found := lookup(doc, field)
+	if !ok {
+		return found && equal(actual, value)
+	}
+	return matchCondition(actual, found, cond)
+}
+
+func matchCondition(actual interface{}, found bool, cond map[string]interface{}) bool {
+	for op, arg := range cond {
+		switch op {
+		case "$exists":
+			if found != arg.(bool) {
+				return false
+			}
+			continue
+		case "$not":
+			sub, ok := arg.(map[string]interface{})
+			if ok && isOperatorMap(sub) {
+				if matchCondition(actual, found, sub) {
+					return false
+				}
+			} else if found && equal(actual, arg) {
+				return false
+			}
+			continue
+		}
+
+		// The other operators never match a missing field
+		if !found {
+			return false
+		}
+		switch op {
+		case "$eq":
+			if !equal(actual, arg) {
+				return false
+			}
+		case "$ne":
+			if equal(actual, arg) {
+				return false
+			}
+		case "$gt":
+			if compare(actual, arg) <= 0 {
+				return false
+			}
+		case "$gte":
+			if compare(actual, arg) < 0 {
+				return false
+			}
+		case "$lt":
+			if compare(actual, arg) >= 0 {
+				return false
+			}
+		case "$lte":
+			if compare(actual, arg) > 0 {
+				return false
+			}
+		case "$in", "$nin":
+			in := false
+			for _, candidate := range arg.([]interface{}) {
+				if equal(actual, candidate) {
+					in = true
+					break
+				}
+			}
+			if in != (op == "$in") {
+				return false
+			}
+		case "$regex":
+			str, ok := actual.(string)
+			if !ok {
+				return false
+			}
+			if matched, _ := regexp.MatchString(arg.(string), str); !matched {
+				return false
+			}
+		case "$size":
+			list, ok := actual.([]interface{})
+			if !ok || float64(len(list)) != arg.(float64) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isOperatorMap(m map[string]interface{}) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for key := range m {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func lookup(doc map[string]interface{}, field string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(field, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func equal(a, b interface{}) bool {
+	if na, ok := toNumber(a); ok {
+		nb, ok := toNumber(b)
+		return ok && na == nb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// typeRank gives the order of the JSON types in the CouchDB collation.
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64, float32, int, int64:
+		return 2
+	case string:
+		return 3
+	case []interface{}:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func compare(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch va := a.(type) {
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		} else if !va {
+			return -1
+		}
+		return 1
+	case string:
+		return strings.Compare(va, b.(string))
+	case []interface{}:
+		vb := b.([]interface{})
+		for i := 0; i < len(va) && i < len(vb); i++ {
+			if c := compare(va[i], vb[i]); c != 0 {
+				return c
+			}
+		}
+		return len(va) - len(vb)
+	}
+	if na, ok := toNumber(a); ok {
+		nb, _ := toNumber(b)
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+	}
+	return 0
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
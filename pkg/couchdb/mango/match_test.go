@@ -0,0 +1,80 @@
+package mango
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseMap(t *testing.T, raw string) map[string]interface{} {
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(raw), &m))
+	return m
+}
+
+func TestMatches(t *testing.T) {
+	doc := parseMap(t, `{
+		"_id": "file1",
+		"type": "file",
+		"dir_id": "xyz",
+		"name": "photo.jpg",
+		"size": 1234,
+		"tags": ["holidays", "beach"],
+		"metadata": {"width": 800, "height": 600},
+		"trashed": false
+	}`)
+
+	cases := []struct {
+		selector string
+		expected bool
+	}{
+		{`{}`, true},
+		{`{"dir_id": "xyz"}`, true},
+		{`{"dir_id": "abc"}`, false},
+		{`{"dir_id": "xyz", "type": "directory"}`, false},
+		{`{"size": 1234}`, true},
+		{`{"size": {"$gt": 1000}}`, true},
+		{`{"size": {"$gt": 1000, "$lt": 1200}}`, false},
+		{`{"size": {"$gte": 1234, "$lte": 1234}}`, true},
+		{`{"name": {"$gt": "a"}}`, true},
+		{`{"size": {"$gt": "a"}}`, false},
+		{`{"metadata.width": 800}`, true},
+		{`{"metadata": {"height": {"$lt": 600}}}`, false},
+		{`{"tags": ["holidays", "beach"]}`, true},
+		{`{"tags": {"$size": 2}}`, true},
+		{`{"type": {"$in": ["file", "directory"]}}`, true},
+		{`{"type": {"$nin": ["file"]}}`, false},
+		{`{"type": {"$ne": "directory"}}`, true},
+		{`{"trashed": {"$eq": false}}`, true},
+		{`{"restore_path": {"$exists": false}}`, true},
+		{`{"restore_path": {"$exists": true}}`, false},
+		{`{"restore_path": {"$ne": "/"}}`, false},
+		{`{"name": {"$regex": "\\.jpe?g$"}}`, true},
+		{`{"name": {"$not": {"$regex": "^photo"}}}`, false},
+		{`{"$or": [{"dir_id": "abc"}, {"size": 1234}]}`, true},
+		{`{"$and": [{"dir_id": "xyz"}, {"size": {"$lt": 10}}]}`, false},
+		{`{"$nor": [{"dir_id": "abc"}, {"type": "directory"}]}`, true},
+		{`{"$not": {"dir_id": "xyz"}}`, false},
+	}
+	for _, c := range cases {
+		selector := parseMap(t, c.selector)
+		require.NoError(t, ValidateSelector(selector), c.selector)
+		assert.Equal(t, c.expected, Matches(selector, doc), c.selector)
+	}
+}
+
+func TestValidateSelector(t *testing.T) {
+	for _, raw := range []string{
+		`{"$where": "true"}`,
+		`{"size": {"$mod": [2, 0]}}`,
+		`{"$or": {"size": 1}}`,
+		`{"$and": []}`,
+		`{"type": {"$in": "file"}}`,
+		`{"name": {"$regex": "("}}`,
+		`{"name": {"$exists": "yes"}}`,
+	} {
+		assert.Error(t, ValidateSelector(parseMap(t, raw)), raw)
+	}
+}
@@ -0,0 +1,74 @@
+package couchdb
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cozy/cozy-stack/pkg/prefixer"
+)
+
+// PurgeBatchSize is the maximal number of documents that can be purged in one
+// request (it is the default value of purge_max_document_id_number in
+// CouchDB).
+const PurgeBatchSize = 100
+
+// Purge removes completely some revisions of documents from the database,
+// without leaving a tombstone. It returns the revisions that have been
+// purged, by document identifier.
+// https://docs.couchdb.org/en/stable/api/database/misc.html#db-purge
+func Purge(db prefixer.Prefixer, doctype string, revs map[string][]string) (map[string][]string, error) {
+	var out struct {
+		Purged map[string][]string `json:"purged"`
+	}
+	if err := makeRequest(db, doctype, http.MethodPost, "_purge", revs, &out); err != nil {
+		return nil, err
+	}
+	return out.Purged, nil
+}
+
+// RevsDiff returns the revisions that are missing in the database, among the
+// given revisions, by document identifier.
+// https://docs.couchdb.org/en/stable/api/database/misc.html#db-revs-diff
+func RevsDiff(db prefixer.Prefixer, doctype string, revs map[string][]string) (map[string][]string, error) {
+	var out map[string]struct {
+		Missing []string `json:"missing"`
+	}
+	if err := makeRequest(db, doctype, http.MethodPost, "_revs_diff", revs, &out); err != nil {
+		return nil, err
+	}
+	missing := make(map[string][]string, len(out))
+	for id, diff := range out {
+		missing[id] = diff.Missing
+	}
+	return missing, nil
+}
+
+// HasActiveReplication returns true if CouchDB is running a replication from
+// or to the database of the given doctype.
+// https://docs.couchdb.org/en/stable/api/server/common.html#active-tasks
+func HasActiveReplication(db prefixer.Prefixer, doctype string) (bool, error) {
+	var tasks []struct {
+		Type     string `json:"type"`
+		Database string `json:"database"`
+		Source   string `json:"source"`
+		Target   string `json:"target"`
+	}
+	if err := makeRequest(db, "", http.MethodGet, "_active_tasks", nil, &tasks); err != nil {
+		return false, err
+	}
+	dbname := EscapeCouchdbName(db.DBPrefix() + "/" + doctype)
+	escaped := url.PathEscape(dbname)
+	for _, task := range tasks {
+		if task.Type != "replication" {
+			continue
+		}
+		for _, endpoint := range []string{task.Source, task.Target} {
+			endpoint = strings.TrimSuffix(endpoint, "/")
+			if strings.HasSuffix(endpoint, "/"+escaped) || endpoint == dbname || endpoint == escaped {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
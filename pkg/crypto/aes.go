@@ -6,8 +6,14 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"strings"
 )
 
+// ErrInvalidCipherString is used when a cipher string can't be decrypted
+// (invalid format or MAC, or a wrong key).
+var ErrInvalidCipherString = errors.New("Invalid cipher string")
+
 func addPadding(payload []byte) []byte {
 	l := len(payload)
 	p := aes.BlockSize - (l % aes.BlockSize)
@@ -19,6 +25,23 @@ func addPadding(payload []byte) []byte {
 	return padded
 }
 
+func removePadding(payload []byte) ([]byte, error) {
+	l := len(payload)
+	if l == 0 || l%aes.BlockSize != 0 {
+		return nil, ErrInvalidCipherString
+	}
+	p := int(payload[l-1])
+	if p == 0 || p > aes.BlockSize {
+		return nil, ErrInvalidCipherString
+	}
+	for i := l - p; i < l; i++ {
+		if int(payload[i]) != p {
+			return nil, ErrInvalidCipherString
+		}
+	}
+	return payload[:l-p], nil
+}
+
 func encryptAES256(key, payload, iv []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -71,3 +94,47 @@ func EncryptWithAES256HMAC(encKey, macKey, payload, iv []byte) (string, error) {
 	cipherString := "2." + iv64 + "|" + dst64 + "|" + h64
 	return cipherString, nil
 }
+
+// DecryptWithAES256HMAC checks the MAC of a bitwarden cipher string with the
+// type 2 (AesCbc256_HmacSha256_B64), and decrypts it.
+func DecryptWithAES256HMAC(encKey, macKey []byte, cipherString string) ([]byte, error) {
+	if !strings.HasPrefix(cipherString, "2.") {
+		return nil, ErrInvalidCipherString
+	}
+	parts := strings.Split(strings.TrimPrefix(cipherString, "2."), "|")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCipherString
+	}
+	iv, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, ErrInvalidCipherString
+	}
+	dst, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(dst) == 0 || len(dst)%aes.BlockSize != 0 {
+		return nil, ErrInvalidCipherString
+	}
+	mac, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCipherString
+	}
+
+	hash := hmac.New(sha256.New, macKey)
+	if _, err := hash.Write(iv); err != nil {
+		return nil, err
+	}
+	if _, err := hash.Write(dst); err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, hash.Sum(nil)) {
+		return nil, ErrInvalidCipherString
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, len(dst))
+	mode := cipher.NewCBCDecrypter(block, iv)
+	mode.CryptBlocks(payload, dst)
+	return removePadding(payload)
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// The key derivation functions that can be used for hashing the passphrases.
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+)
+
+// Argon2id params, as recommended by the OWASP:
+// https://cheatsheetseries.owasp.org/cheatsheets/Password_Storage_Cheat_Sheet.html
+const defaultArgon2Memory = 64 * 1024 // KiB
+const defaultArgon2Iterations = 3
+const defaultArgon2Parallelism = 4
+
+// HashParams are the parameters for hashing a passphrase. The Argon2 params
+// are only used with the argon2id KDF, and the default values are used when
+// they are 0.
+type HashParams struct {
+	KDF               string
+	Argon2Memory      uint32 // in KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// DefaultHashParams are the parameters used when nothing else has been
+// configured: scrypt.
+var DefaultHashParams = HashParams{KDF: KDFScrypt}
+
+func (p HashParams) withDefaults() HashParams {
+	if p.KDF == "" {
+		p.KDF = KDFScrypt
+	}
+	if p.Argon2Memory == 0 {
+		p.Argon2Memory = defaultArgon2Memory
+	}
+	if p.Argon2Iterations == 0 {
+		p.Argon2Iterations = defaultArgon2Iterations
+	}
+	if p.Argon2Parallelism == 0 {
+		p.Argon2Parallelism = defaultArgon2Parallelism
+	}
+	return p
+}
+
+// argon2Hash is an argon2id hash, encoded in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<derived key>
+type argon2Hash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	dk          []byte
+}
+
+func (h *argon2Hash) UnmarshalText(hashbytes []byte) error {
+	vals := bytes.Split(hashbytes, sep)
+	// "", "argon2id", version, params, salt, derived key
+	if len(vals) != 6 || len(vals[0]) != 0 || string(vals[1]) != KDFArgon2id {
+		return ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(string(vals[2]), "v=%d", &version); err != nil || version != argon2.Version {
+		return ErrInvalidHash
+	}
+	_, err := fmt.Sscanf(string(vals[3]), "m=%d,t=%d,p=%d", &h.memory, &h.iterations, &h.parallelism)
+	if err != nil {
+		return ErrInvalidHash
+	}
+	h.salt, err = base64.RawStdEncoding.DecodeString(string(vals[4]))
+	if err != nil {
+		return ErrInvalidHash
+	}
+	h.dk, err = base64.RawStdEncoding.DecodeString(string(vals[5]))
+	if err != nil || len(h.dk) == 0 {
+		return ErrInvalidHash
+	}
+	return nil
+}
+
+func (h *argon2Hash) MarshalText() ([]byte, error) {
+	s := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.iterations, h.parallelism,
+		base64.RawStdEncoding.EncodeToString(h.salt),
+		base64.RawStdEncoding.EncodeToString(h.dk))
+	return []byte(s), nil
+}
+
+func (h *argon2Hash) Compare(passphrase []byte) error {
+	other := argon2.IDKey(passphrase, h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.dk)))
+	if subtle.ConstantTimeCompare(h.dk, other) == 1 {
+		return nil
+	}
+	return ErrMismatchedHashAndPassphrase
+}
+
+func (h *argon2Hash) NeedUpdate(params HashParams) bool {
+	if params.KDF != KDFArgon2id {
+		return true
+	}
+	return h.memory != params.Argon2Memory || h.iterations != params.Argon2Iterations ||
+		h.parallelism != params.Argon2Parallelism ||
+		len(h.salt) != defaultSaltLen || len(h.dk) != defaultDkLen
+}
+
+func generateArgon2(passphrase []byte, params HashParams) ([]byte, error) {
+	h := &argon2Hash{
+		memory:      params.Argon2Memory,
+		iterations:  params.Argon2Iterations,
+		parallelism: params.Argon2Parallelism,
+		salt:        GenerateRandomBytes(defaultSaltLen),
+	}
+	h.dk = argon2.IDKey(passphrase, h.salt, h.iterations, h.memory, h.parallelism, defaultDkLen)
+	return h.MarshalText()
+}
+
+// HashKDF returns the name of the key derivation function used for the given
+// hash, or an empty string if it is not a valid hash.
+func HashKDF(hash []byte) string {
+	switch {
+	case bytes.HasPrefix(hash, []byte("scrypt$")):
+		return KDFScrypt
+	case bytes.HasPrefix(hash, []byte("$argon2id$")):
+		return KDFArgon2id
+	}
+	return ""
+}
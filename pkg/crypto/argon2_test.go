@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var argon2Params = HashParams{KDF: KDFArgon2id, Argon2Memory: 1024, Argon2Iterations: 2, Argon2Parallelism: 1}
+
+func TestGenerateArgon2FromPassphrase(t *testing.T) {
+	val, err := GenerateFromPassphraseWithParams(pass, argon2Params)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(val, []byte("$argon2id$v=19$m=1024,t=2,p=1$")))
+	assert.Equal(t, KDFArgon2id, HashKDF(val))
+
+	needUpdate, err := CompareHashAndPassphraseWithParams(val, pass, argon2Params)
+	assert.NoError(t, err)
+	assert.False(t, needUpdate)
+
+	_, err = CompareHashAndPassphraseWithParams(val, []byte("wrong"), argon2Params)
+	assert.ErrorIs(t, err, ErrMismatchedHashAndPassphrase)
+
+	// Other params for argon2id
+	other := argon2Params
+	other.Argon2Iterations = 3
+	needUpdate, err = CompareHashAndPassphraseWithParams(val, pass, other)
+	assert.NoError(t, err)
+	assert.True(t, needUpdate)
+
+	// Back to scrypt
+	needUpdate, err = CompareHashAndPassphrase(val, pass)
+	assert.NoError(t, err)
+	assert.True(t, needUpdate)
+}
+
+func TestMigrateScryptToArgon2(t *testing.T) {
+	assert.Equal(t, KDFScrypt, HashKDF(goodhash))
+	needUpdate, err := CompareHashAndPassphraseWithParams(goodhash, pass, argon2Params)
+	assert.NoError(t, err)
+	assert.True(t, needUpdate)
+}
+
+func TestInvalidArgon2Hash(t *testing.T) {
+	_, err := CompareHashAndPassphraseWithParams([]byte("$argon2id$v=19$m=1024$xxx$yyy"), pass, argon2Params)
+	assert.ErrorIs(t, err, ErrInvalidHash)
+	assert.Empty(t, HashKDF([]byte("bcrypt")))
+}
@@ -111,8 +111,9 @@ func (h *scryptHash) Compare(passphrase []byte) error {
 	return ErrMismatchedHashAndPassphrase
 }
 
-func (h *scryptHash) NeedUpdate() bool {
-	return h.n != defaultN || h.p != defaultP || h.r != defaultR ||
+func (h *scryptHash) NeedUpdate(params HashParams) bool {
+	return params.KDF != KDFScrypt ||
+		h.n != defaultN || h.p != defaultP || h.r != defaultR ||
 		len(h.salt) != defaultSaltLen || len(h.dk) != defaultDkLen
 }
 
@@ -122,6 +123,17 @@ func (h *scryptHash) NeedUpdate() bool {
 // If the parameters provided are less than the minimum acceptable values,
 // an error will be returned.
 func GenerateFromPassphrase(passphrase []byte) ([]byte, error) {
+	return GenerateFromPassphraseWithParams(passphrase, DefaultHashParams)
+}
+
+// GenerateFromPassphraseWithParams is like GenerateFromPassphrase, but the
+// key derivation function (scrypt or argon2id) is chosen by the params.
+func GenerateFromPassphraseWithParams(passphrase []byte, params HashParams) ([]byte, error) {
+	params = params.withDefaults()
+	if params.KDF == KDFArgon2id {
+		return generateArgon2(passphrase, params)
+	}
+
 	h := &scryptHash{n: defaultN, r: defaultR, p: defaultP}
 	var err error
 
@@ -144,6 +156,27 @@ func GenerateFromPassphrase(passphrase []byte) ([]byte, error) {
 // needUpdate boolean indicating whether or not the passphrase hash has
 // outdated parameters and should be recomputed.
 func CompareHashAndPassphrase(hash []byte, passphrase []byte) (needUpdate bool, err error) {
+	return CompareHashAndPassphraseWithParams(hash, passphrase, DefaultHashParams)
+}
+
+// CompareHashAndPassphraseWithParams is like CompareHashAndPassphrase, but
+// the needUpdate boolean is computed with the given params. The hash can
+// have been made with scrypt or argon2id, whatever the params. It allows to
+// migrate transparently to another KDF on the next successful login.
+func CompareHashAndPassphraseWithParams(hash []byte, passphrase []byte, params HashParams) (needUpdate bool, err error) {
+	params = params.withDefaults()
+	if HashKDF(hash) == KDFArgon2id {
+		h := &argon2Hash{}
+		if err = h.UnmarshalText(hash); err != nil {
+			return false, err
+		}
+		if err = h.Compare(passphrase); err != nil {
+			return false, err
+		}
+		debug.FreeOSMemory()
+		return h.NeedUpdate(params), nil
+	}
+
 	h := &scryptHash{}
 	if err = h.UnmarshalText(hash); err != nil {
 		return false, err
@@ -158,5 +191,5 @@ func CompareHashAndPassphrase(hash []byte, passphrase []byte) (needUpdate bool,
 	// and https://groups.google.com/forum/#!topic/golang-nuts/I9R9MKUS9bo
 	debug.FreeOSMemory()
 
-	return h.NeedUpdate(), nil
+	return h.NeedUpdate(params), nil
 }
@@ -0,0 +1,291 @@
+// Package dkim is used to sign the outgoing mails with DKIM (RFC 6376). Only
+// the relaxed/relaxed canonicalization is supported, with the rsa-sha256 and
+// ed25519-sha256 (RFC 8463) algorithms.
+package dkim
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The supported algorithms for the keys.
+const (
+	AlgorithmRSA     = "rsa"
+	AlgorithmEd25519 = "ed25519"
+)
+
+// rsaKeySize is the size in bits of the generated RSA keys.
+const rsaKeySize = 2048
+
+var (
+	// ErrInvalidKey is used when the private key cannot be parsed.
+	ErrInvalidKey = errors.New("Invalid DKIM private key")
+	// ErrUnknownAlgorithm is used for an algorithm other than rsa or ed25519.
+	ErrUnknownAlgorithm = errors.New("Unknown DKIM algorithm")
+	// ErrInvalidMessage is used when the message has no header.
+	ErrInvalidMessage = errors.New("Invalid message")
+)
+
+// DefaultHeaders is the list of the headers that are signed, when they are
+// present in the message.
+var DefaultHeaders = []string{
+	"From", "Reply-To", "Subject", "Date", "To", "Cc", "Message-ID",
+	"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+}
+
+// Options are the parameters for signing a message.
+type Options struct {
+	Domain   string
+	Selector string
+	Signer   stdcrypto.Signer
+	Headers  []string
+	Time     time.Time
+}
+
+// GenerateKey generates a private key for the given algorithm, and returns
+// it encoded in PEM (PKCS#8).
+func GenerateKey(algorithm string) (string, error) {
+	var key interface{}
+	var err error
+	switch algorithm {
+	case AlgorithmRSA:
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeySize)
+	case AlgorithmEd25519:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", ErrUnknownAlgorithm
+	}
+	if err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	block := &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// ParseKey parses a private key encoded in PEM (PKCS#8 or PKCS#1 for RSA).
+func ParseKey(encoded string) (stdcrypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, ErrInvalidKey
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// DNSRecord returns the value of the TXT record to publish in the DNS for
+// the public key of the signer, at <selector>._domainkey.<domain>.
+func DNSRecord(signer stdcrypto.Signer) (string, error) {
+	switch pub := signer.Public().(type) {
+	case *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return "", err
+		}
+		return "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der), nil
+	case ed25519.PublicKey:
+		return "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(pub), nil
+	}
+	return "", ErrUnknownAlgorithm
+}
+
+// Sign returns the message with a DKIM-Signature header added at the top.
+func Sign(msg []byte, opts *Options) ([]byte, error) {
+	header, body, ok := splitMessage(msg)
+	if !ok {
+		return nil, ErrInvalidMessage
+	}
+
+	var algo string
+	switch opts.Signer.Public().(type) {
+	case *rsa.PublicKey:
+		algo = "rsa-sha256"
+	case ed25519.PublicKey:
+		algo = "ed25519-sha256"
+	default:
+		return nil, ErrUnknownAlgorithm
+	}
+
+	bodyHash := sha256.Sum256(RelaxedBody(body))
+
+	fields := parseHeader(header)
+	names := opts.Headers
+	if names == nil {
+		names = DefaultHeaders
+	}
+	var signedNames []string
+	var signed bytes.Buffer
+	for _, name := range names {
+		// When a header is present several times, the last one is signed
+		for i := len(fields) - 1; i >= 0; i-- {
+			if strings.EqualFold(fields[i].name, name) {
+				signed.WriteString(RelaxedHeader(fields[i].raw))
+				signed.WriteString("\r\n")
+				signedNames = append(signedNames, strings.ToLower(name))
+				break
+			}
+		}
+	}
+
+	at := opts.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	tags := []string{
+		"v=1",
+		"a=" + algo,
+		"c=relaxed/relaxed",
+		"d=" + opts.Domain,
+		"s=" + opts.Selector,
+		"t=" + strconv.FormatInt(at.Unix(), 10),
+		"h=" + strings.Join(signedNames, ":"),
+		"bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]),
+		"b=",
+	}
+	sigHeader := "DKIM-Signature: " + strings.Join(tags, "; ")
+	signed.WriteString(RelaxedHeader(sigHeader))
+	hashed := sha256.Sum256(signed.Bytes())
+
+	var sig []byte
+	var err error
+	if _, ok := opts.Signer.(ed25519.PrivateKey); ok {
+		sig, err = opts.Signer.Sign(rand.Reader, hashed[:], stdcrypto.Hash(0))
+	} else {
+		sig, err = opts.Signer.Sign(rand.Reader, hashed[:], stdcrypto.SHA256)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The tags are folded on several lines, which is equivalent for the
+	// relaxed canonicalization.
+	var out bytes.Buffer
+	out.Grow(len(msg) + 1024)
+	out.WriteString("DKIM-Signature: ")
+	out.WriteString(strings.Join(tags, ";\r\n\t"))
+	out.WriteString(base64.StdEncoding.EncodeToString(sig))
+	out.WriteString("\r\n")
+	out.Write(msg)
+	return out.Bytes(), nil
+}
+
+type headerField struct {
+	name string
+	raw  string
+}
+
+// splitMessage splits a message between its header and its body.
+func splitMessage(msg []byte) ([]byte, []byte, bool) {
+	if i := bytes.Index(msg, []byte("\r\n\r\n")); i >= 0 {
+		return msg[:i+2], msg[i+4:], true
+	}
+	return nil, nil, false
+}
+
+// parseHeader returns the header fields, with their continuation lines.
+func parseHeader(header []byte) []headerField {
+	var fields []headerField
+	lines := strings.SplitAfter(string(header), "\r\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(fields) > 0 {
+			fields[len(fields)-1].raw += line
+			continue
+		}
+		name := line
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			name = line[:i]
+		}
+		fields = append(fields, headerField{
+			name: strings.TrimSpace(name),
+			raw:  line,
+		})
+	}
+	for i := range fields {
+		fields[i].raw = strings.TrimSuffix(fields[i].raw, "\r\n")
+	}
+	return fields
+}
+
+// RelaxedHeader returns the relaxed canonicalization of a header field,
+// without the final CRLF (RFC 6376, section 3.4.2).
+func RelaxedHeader(field string) string {
+	i := strings.IndexByte(field, ':')
+	if i < 0 {
+		return strings.ToLower(strings.TrimSpace(field))
+	}
+	name := strings.ToLower(strings.TrimSpace(field[:i]))
+	value := strings.ReplaceAll(field[i+1:], "\r\n", "")
+	value = strings.TrimSpace(compressWSP(value))
+	return fmt.Sprintf("%s:%s", name, value)
+}
+
+// RelaxedBody returns the relaxed canonicalization of a body (RFC 6376,
+// section 3.4.4).
+func RelaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	var buf bytes.Buffer
+	empty := 0
+	for _, line := range lines {
+		line = strings.TrimRight(compressWSP(line), " ")
+		if line == "" {
+			empty++
+			continue
+		}
+		for ; empty > 0; empty-- {
+			buf.WriteString("\r\n")
+		}
+		buf.WriteString(line)
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes()
+}
+
+// compressWSP replaces the sequences of spaces and tabs by a single space.
+func compressWSP(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inWSP := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == ' ' || c == '\t' {
+			if !inWSP {
+				b.WriteByte(' ')
+			}
+			inWSP = true
+			continue
+		}
+		inWSP = false
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package dkim
+
+import (
+	stdcrypto "crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelaxedCanonicalization(t *testing.T) {
+	// Example from RFC 6376, section 3.4.5
+	assert.Equal(t, "a:X", RelaxedHeader("A: X"))
+	assert.Equal(t, "b:Y Z", RelaxedHeader("B : Y\t\r\n\tZ  "))
+	body := RelaxedBody([]byte(" C \r\nD \t E\r\n\r\n\r\n"))
+	assert.Equal(t, " C\r\nD E\r\n", string(body))
+	assert.Empty(t, RelaxedBody([]byte("\r\n\r\n")))
+}
+
+func TestSignAndVerify(t *testing.T) {
+	msg := "From: Cozy <noreply@cozy.example>\r\n" +
+		"To: alice@example.org\r\n" +
+		"Subject: Hello\r\n" +
+		" world\r\n" +
+		"Message-ID: <abc@alice.cozy.example>\r\n" +
+		"X-Cozy: alice.cozy.example\r\n" +
+		"\r\n" +
+		"Hi Alice,  \r\n\r\nSee you!\r\n\r\n"
+
+	for _, algo := range []string{AlgorithmRSA, AlgorithmEd25519} {
+		encoded, err := GenerateKey(algo)
+		require.NoError(t, err)
+		signer, err := ParseKey(encoded)
+		require.NoError(t, err)
+		record, err := DNSRecord(signer)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(record, "v=DKIM1; k="+algo+"; p="))
+
+		signed, err := Sign([]byte(msg), &Options{
+			Domain:   "cozy.example",
+			Selector: "cozy2023",
+			Signer:   signer,
+			Time:     time.Unix(1700000000, 0),
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(string(signed), msg))
+		verify(t, string(signed), signer.Public())
+	}
+
+	_, err := ParseKey("not a key")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = GenerateKey("dsa")
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
+
+// verify checks the DKIM signature of a message, like a receiving server
+// would do it.
+func verify(t *testing.T, msg string, pub stdcrypto.PublicKey) {
+	header, body, ok := splitMessage([]byte(msg))
+	require.True(t, ok)
+	fields := parseHeader(header)
+	require.Equal(t, "DKIM-Signature", fields[0].name)
+	sigField := fields[0].raw
+
+	tags := map[string]string{}
+	value := strings.ReplaceAll(sigField[strings.IndexByte(sigField, ':')+1:], "\r\n", "")
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		require.Len(t, kv, 2)
+		tags[kv[0]] = kv[1]
+	}
+	assert.Equal(t, "1", tags["v"])
+	assert.Equal(t, "relaxed/relaxed", tags["c"])
+	assert.Equal(t, "cozy.example", tags["d"])
+	assert.Equal(t, "cozy2023", tags["s"])
+	assert.Equal(t, "1700000000", tags["t"])
+	assert.Equal(t, "from:subject:to:message-id", tags["h"])
+
+	bh := sha256.Sum256(RelaxedBody(body))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(bh[:]), tags["bh"])
+
+	var data strings.Builder
+	for _, name := range strings.Split(tags["h"], ":") {
+		for _, f := range fields[1:] {
+			if strings.EqualFold(f.name, name) {
+				data.WriteString(RelaxedHeader(f.raw) + "\r\n")
+			}
+		}
+	}
+	unsigned := strings.Replace(sigField, tags["b"], "", 1)
+	data.WriteString(RelaxedHeader(unsigned))
+	hashed := sha256.Sum256([]byte(data.String()))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	require.NoError(t, err)
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		assert.Equal(t, "rsa-sha256", tags["a"])
+		assert.NoError(t, rsa.VerifyPKCS1v15(k, stdcrypto.SHA256, hashed[:], sig))
+	case ed25519.PublicKey:
+		assert.Equal(t, "ed25519-sha256", tags["a"])
+		assert.True(t, ed25519.Verify(k, hashed[:], sig))
+	default:
+		t.Fatalf("unexpected key type %T", pub)
+	}
+}
@@ -0,0 +1,260 @@
+// Package egress is used for the outbound requests of the stack when they
+// must go through a proxy, like for the stacks deployed inside corporate
+// networks. The proxy is configured per context, and the requests made for an
+// instance use the proxy of its context (or the proxy of the default context).
+package egress
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+type contextKey struct{}
+
+// WithContextName returns a context for the requests made for an instance of
+// the given context, so that they use the egress proxy of this context.
+func WithContextName(ctx context.Context, contextName string) context.Context {
+	return context.WithValue(ctx, contextKey{}, contextName)
+}
+
+// ContextName returns the name of the context added by WithContextName, or an
+// empty string.
+func ContextName(ctx context.Context) string {
+	name, _ := ctx.Value(contextKey{}).(string)
+	return name
+}
+
+var proxyDialer = &net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
+
+// proxyFunc returns the function that chooses if the proxy must be used for
+// an URL, with the no_proxy list of the configuration.
+func proxyFunc(cfg config.EgressProxy) func(*url.URL) (*url.URL, error) {
+	conf := httpproxy.Config{
+		HTTPProxy:  cfg.URL.String(),
+		HTTPSProxy: cfg.URL.String(),
+		NoProxy:    strings.Join(cfg.NoProxy, ","),
+	}
+	return conf.ProxyFunc()
+}
+
+// rootCAs returns the pool of the certificates of the system, with the
+// certificates of the CA file added, or nil if there is no CA file.
+func rootCAs(cfg config.EgressProxy) (*x509.CertPool, error) {
+	if cfg.CAFile == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("egress: no certificate found in %s", cfg.CAFile)
+	}
+	return pool, nil
+}
+
+// TLSConfig returns the TLS configuration that trusts the CA of the egress
+// proxy for the given context, or nil if no CA file has been configured.
+func TLSConfig(contextName string) (*tls.Config, error) {
+	cfg, ok := config.GetEgressProxy(contextName)
+	if !ok {
+		return nil, nil
+	}
+	pool, err := rootCAs(cfg)
+	if err != nil || pool == nil {
+		return nil, err
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// Wrap returns an http.RoundTripper that sends the requests through the egress
+// proxy of the context of the request (see WithContextName). The base
+// transport is used when there is no egress proxy, and is cloned for the
+// egress proxies.
+func Wrap(base *http.Transport) http.RoundTripper {
+	return &transport{
+		base:       base,
+		transports: make(map[string]*http.Transport),
+	}
+}
+
+type transport struct {
+	base       *http.Transport
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg, ok := config.GetEgressProxy(ContextName(req.Context()))
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	tr, err := t.transportFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return tr.RoundTrip(req)
+}
+
+// transportFor returns the transport for an egress proxy. The transports are
+// kept by configuration, so that a change of the credentials (rotation of the
+// secrets) creates a new transport.
+func (t *transport) transportFor(cfg config.EgressProxy) (*http.Transport, error) {
+	key := cfg.URL.String() + "|" + strings.Join(cfg.NoProxy, ",") + "|" + cfg.CAFile
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tr, ok := t.transports[key]; ok {
+		return tr, nil
+	}
+
+	pool, err := rootCAs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tr := t.base.Clone()
+	fn := proxyFunc(cfg)
+	tr.Proxy = func(req *http.Request) (*url.URL, error) {
+		return fn(req.URL)
+	}
+	// The proxy is trusted, even if it is on a private network
+	tr.DialContext = proxyDialer.DialContext
+	if pool != nil {
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tr.TLSClientConfig.RootCAs = pool
+	}
+	t.transports[key] = tr
+	return tr, nil
+}
+
+// DialContext opens a TCP connection to the given address, through a CONNECT
+// tunnel on the egress proxy of the context if there is one, or with the
+// fallback function else.
+func DialContext(
+	ctx context.Context,
+	contextName, addr string,
+	fallback func(ctx context.Context, network, addr string) (net.Conn, error),
+) (net.Conn, error) {
+	cfg, ok := config.GetEgressProxy(contextName)
+	if !ok {
+		return fallback(ctx, "tcp", addr)
+	}
+	proxyURL, err := proxyFunc(cfg)(&url.URL{Scheme: "https", Host: addr})
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return fallback(ctx, "tcp", addr)
+	}
+	return dialConnect(ctx, proxyURL, addr)
+}
+
+func dialConnect(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := proxyDialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{
+			ServerName: proxyURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("egress: the proxy has refused the tunnel to %s: %s", addr, res.Status)
+	}
+	if br.Buffered() > 0 {
+		conn.Close()
+		return nil, errors.New("egress: unexpected data after the CONNECT response")
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// ProxyEnv returns the environment variables for an external process, like a
+// konnector, to use the egress proxy of the context. The hosts of the noProxy
+// list are added to the no_proxy list of the configuration.
+func ProxyEnv(contextName string, noProxy ...string) []string {
+	cfg, ok := config.GetEgressProxy(contextName)
+	if !ok {
+		return nil
+	}
+	proxyURL := cfg.URL.String()
+	hosts := strings.Join(append(append([]string{}, cfg.NoProxy...), noProxy...), ",")
+	return []string{
+		"HTTP_PROXY=" + proxyURL,
+		"HTTPS_PROXY=" + proxyURL,
+		"NO_PROXY=" + hosts,
+		"http_proxy=" + proxyURL,
+		"https_proxy=" + proxyURL,
+		"no_proxy=" + hosts,
+	}
+}
+
+// CAEnv returns the environment variables for a node process to trust the CA
+// of the egress proxy of the context.
+func CAEnv(contextName string) []string {
+	cfg, ok := config.GetEgressProxy(contextName)
+	if !ok || cfg.CAFile == "" {
+		return nil
+	}
+	return []string{"NODE_EXTRA_CA_CERTS=" + cfg.CAFile}
+}
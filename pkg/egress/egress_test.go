@@ -0,0 +1,102 @@
+package egress
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgress(t *testing.T) {
+	config.UseTestFile(t)
+	conf := config.GetConfig()
+
+	var seen []*http.Request
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r)
+		if r.Method != http.MethodConnect {
+			_, _ = io.WriteString(w, "from proxy")
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		line, _ := bufio.NewReader(buf).ReadString('\n')
+		_, _ = conn.Write([]byte("echo " + line))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("cozy", "secret")
+	conf.EgressProxies = map[string]config.EgressProxy{
+		"corporate": {
+			URL:     proxyURL,
+			NoProxy: []string{"intranet.example"},
+		},
+	}
+	t.Cleanup(func() { conf.EgressProxies = nil })
+
+	t.Run("ProxyFunc", func(t *testing.T) {
+		fn := proxyFunc(conf.EgressProxies["corporate"])
+		u, err := fn(&url.URL{Scheme: "https", Host: "vendor.example"})
+		require.NoError(t, err)
+		assert.Equal(t, proxyURL.Host, u.Host)
+		u, err = fn(&url.URL{Scheme: "https", Host: "api.intranet.example"})
+		require.NoError(t, err)
+		assert.Nil(t, u)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		seen = nil
+		client := &http.Client{Transport: Wrap(http.DefaultTransport.(*http.Transport))}
+		ctx := WithContextName(context.Background(), "corporate")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://vendor.example/foo", nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, "from proxy", string(body))
+		require.Len(t, seen, 1)
+		assert.Equal(t, "http://vendor.example/foo", seen[0].RequestURI)
+		assert.Equal(t, "Basic Y296eTpzZWNyZXQ=", seen[0].Header.Get("Proxy-Authorization"))
+	})
+
+	t.Run("DialContext", func(t *testing.T) {
+		seen = nil
+		fallback := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, io.EOF
+		}
+		conn, err := DialContext(context.Background(), "corporate", "vendor.example:443", fallback)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = conn.Write([]byte("hello\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "echo hello\n", line)
+		require.Len(t, seen, 1)
+		assert.Equal(t, "vendor.example:443", seen[0].Host)
+
+		_, err = DialContext(context.Background(), "other", "vendor.example:443", fallback)
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		env := ProxyEnv("corporate", "alice.cozy.example")
+		assert.Contains(t, env, "HTTPS_PROXY="+proxyURL.String())
+		assert.Contains(t, env, "no_proxy=intranet.example,alice.cozy.example")
+		assert.Empty(t, ProxyEnv("other"))
+		assert.Empty(t, CAEnv("corporate"))
+	})
+}
@@ -0,0 +1,82 @@
+// Package geocoding is used for the reverse geocoding: it finds the name of
+// a place (city, country) from GPS coordinates.
+package geocoding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// The available providers.
+const (
+	// ProviderOffline looks for the nearest city in a GeoNames dump.
+	ProviderOffline = "offline"
+	// ProviderNominatim calls a Nominatim compatible API.
+	ProviderNominatim = "nominatim"
+)
+
+var (
+	// ErrNoPlace is used when no place has been found for the coordinates.
+	ErrNoPlace = errors.New("No place found for these coordinates")
+	// ErrNotConfigured is used when the reverse geocoding is not configured.
+	ErrNotConfigured = errors.New("The reverse geocoding is not configured")
+)
+
+// Place is the result of the reverse geocoding.
+type Place struct {
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// Name returns a human readable name for the place.
+func (p *Place) Name() string {
+	var parts []string
+	if p.City != "" {
+		parts = append(parts, p.City)
+	}
+	if p.Country != "" {
+		parts = append(parts, p.Country)
+	} else if p.CountryCode != "" {
+		parts = append(parts, strings.ToUpper(p.CountryCode))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Geocoder is the interface for the reverse geocoding providers.
+type Geocoder interface {
+	Reverse(lat, long float64) (*Place, error)
+}
+
+// New returns a geocoder for the given configuration.
+func New(cfg config.Geocoding) (Geocoder, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, ErrNotConfigured
+	case ProviderOffline:
+		return NewOffline(cfg.CitiesFile)
+	case ProviderNominatim:
+		return NewNominatim(cfg.URL, cfg.UserAgent), nil
+	}
+	return nil, fmt.Errorf("Unknown geocoding provider: %q", cfg.Provider)
+}
+
+var (
+	globalOnce     sync.Once
+	globalGeocoder Geocoder
+	globalErr      error
+)
+
+// Get returns the geocoder configured for the stack. It is initialized on the
+// first call (the cities file can be large).
+func Get() (Geocoder, error) {
+	globalOnce.Do(func() {
+		globalGeocoder, globalErr = New(config.GetConfig().Geocoding)
+	})
+	return globalGeocoder, globalErr
+}
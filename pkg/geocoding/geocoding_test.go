@@ -0,0 +1,81 @@
+package geocoding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cities = "2988507\tParis\tParis\t\t48.85341\t2.3488\tP\tPPLC\tFR\n" +
+	"2995469\tMarseille\tMarseille\t\t43.29695\t5.38107\tP\tPPLA\tFR\n" +
+	"2643743\tLondon\tLondon\t\t51.50853\t-0.12574\tP\tPPLC\tGB\n" +
+	"invalid line\n"
+
+func TestOffline(t *testing.T) {
+	o, err := LoadCities(strings.NewReader(cities))
+	require.NoError(t, err)
+
+	place, err := o.Reverse(48.8566, 2.3522)
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", place.City)
+	assert.Equal(t, "FR", place.CountryCode)
+	assert.Equal(t, "Paris, FR", place.Name())
+
+	// Near the border of a cell
+	place, err = o.Reverse(51.4, 0.01)
+	require.NoError(t, err)
+	assert.Equal(t, "London", place.City)
+
+	_, err = o.Reverse(0, 0)
+	assert.Equal(t, ErrNoPlace, err)
+}
+
+func TestDistance(t *testing.T) {
+	d := Distance(48.85341, 2.3488, 43.29695, 5.38107)
+	assert.InDelta(t, 661, d, 5)
+}
+
+func TestNominatim(t *testing.T) {
+	config.UseTestFile(t)
+	NominatimInterval = 0
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/reverse", r.URL.Path)
+		assert.Equal(t, "cozy-test", r.Header.Get("User-Agent"))
+		if r.URL.Query().Get("lat") == "0.000000" {
+			_, _ = w.Write([]byte(`{"error":"Unable to geocode"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"address":{"town":"Lyon","state":"Auvergne-Rhône-Alpes","country":"France","country_code":"fr"}}`))
+	}))
+	defer ts.Close()
+
+	n := NewNominatim(ts.URL, "cozy-test")
+	place, err := n.Reverse(45.7578, 4.8320)
+	require.NoError(t, err)
+	assert.Equal(t, &Place{
+		City:        "Lyon",
+		Region:      "Auvergne-Rhône-Alpes",
+		Country:     "France",
+		CountryCode: "FR",
+	}, place)
+	assert.Equal(t, "Lyon, France", place.Name())
+
+	// From the cache
+	_, err = n.Reverse(45.7578, 4.8320)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	_, err = n.Reverse(0, 0)
+	assert.Equal(t, ErrNoPlace, err)
+	_, err = n.Reverse(0, 0)
+	assert.Equal(t, ErrNoPlace, err)
+	assert.Equal(t, 2, calls)
+}
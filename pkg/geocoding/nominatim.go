@@ -0,0 +1,151 @@
+package geocoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// DefaultNominatimURL is the URL of the Nominatim API of OpenStreetMap.
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+
+// NominatimInterval is the minimal delay between two requests to the API, as
+// required by the usage policy of OpenStreetMap.
+var NominatimInterval = 1 * time.Second
+
+const nominatimCacheTTL = 30 * 24 * time.Hour
+
+// Nominatim is a geocoder that calls a Nominatim compatible API. The results
+// are cached, with the coordinates rounded to ~100m.
+type Nominatim struct {
+	URL       string
+	UserAgent string
+	client    *http.Client
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewNominatim returns a geocoder for the Nominatim API at the given URL.
+func NewNominatim(u, userAgent string) *Nominatim {
+	if u == "" {
+		u = DefaultNominatimURL
+	}
+	if userAgent == "" {
+		userAgent = "cozy-stack"
+	}
+	return &Nominatim{
+		URL:       strings.TrimSuffix(u, "/"),
+		UserAgent: userAgent,
+		client:    &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+type nominatimResult struct {
+	Error   string `json:"error"`
+	Address struct {
+		City        string `json:"city"`
+		Town        string `json:"town"`
+		Village     string `json:"village"`
+		Hamlet      string `json:"hamlet"`
+		State       string `json:"state"`
+		Country     string `json:"country"`
+		CountryCode string `json:"country_code"`
+	} `json:"address"`
+}
+
+// Reverse implements the Geocoder interface.
+func (n *Nominatim) Reverse(lat, long float64) (*Place, error) {
+	key := fmt.Sprintf("geocoding:%.3f:%.3f", lat, long)
+	cache := config.GetConfig().CacheStorage
+	if buf, ok := cache.Get(key); ok {
+		var place Place
+		if err := json.Unmarshal(buf, &place); err == nil {
+			if place == (Place{}) {
+				return nil, ErrNoPlace
+			}
+			return &place, nil
+		}
+	}
+
+	place, err := n.request(lat, long)
+	if err != nil && err != ErrNoPlace {
+		return nil, err
+	}
+	cached := place
+	if cached == nil {
+		cached = &Place{}
+	}
+	if buf, errm := json.Marshal(cached); errm == nil {
+		cache.Set(key, buf, nominatimCacheTTL)
+	}
+	return place, err
+}
+
+func (n *Nominatim) request(lat, long float64) (*Place, error) {
+	n.wait()
+	q := url.Values{
+		"format": {"jsonv2"},
+		"lat":    {strconv.FormatFloat(lat, 'f', 6, 64)},
+		"lon":    {strconv.FormatFloat(long, 'f', 6, 64)},
+		"zoom":   {"10"},
+	}
+	req, err := http.NewRequest(http.MethodGet, n.URL+"/reverse?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", n.UserAgent)
+	req.Header.Set("Accept", "application/json")
+	res, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected response from Nominatim: %d", res.StatusCode)
+	}
+	var result nominatimResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, ErrNoPlace
+	}
+	addr := result.Address
+	place := &Place{
+		City:        firstNonEmpty(addr.City, addr.Town, addr.Village, addr.Hamlet),
+		Region:      addr.State,
+		Country:     addr.Country,
+		CountryCode: strings.ToUpper(addr.CountryCode),
+	}
+	if *place == (Place{}) {
+		return nil, ErrNoPlace
+	}
+	return place, nil
+}
+
+// wait ensures that there is at most one request per NominatimInterval.
+func (n *Nominatim) wait() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if d := NominatimInterval - time.Since(n.last); d > 0 {
+		time.Sleep(d)
+	}
+	n.last = time.Now()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package geocoding
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxOfflineDistance is the maximal distance (in km) between the coordinates
+// and the nearest city for the offline geocoder.
+const MaxOfflineDistance = 50.0
+
+const earthRadius = 6371.0 // km
+
+type city struct {
+	name        string
+	countryCode string
+	lat, long   float64
+}
+
+type cell struct {
+	lat, long int
+}
+
+// Offline is a geocoder that looks for the nearest city in a GeoNames dump
+// (tab-separated values, like cities15000.txt). The cities are indexed in a
+// grid of 1° cells.
+type Offline struct {
+	cells map[cell][]*city
+}
+
+// NewOffline loads the cities from the given file.
+func NewOffline(filename string) (*Offline, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadCities(f)
+}
+
+// LoadCities reads the cities from a GeoNames dump.
+func LoadCities(r io.Reader) (*Offline, error) {
+	o := &Offline{cells: make(map[cell][]*city)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// The columns are: geonameid, name, asciiname, alternatenames,
+		// latitude, longitude, feature class, feature code, country code, ...
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 9 {
+			continue
+		}
+		lat, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			continue
+		}
+		long, err := strconv.ParseFloat(fields[5], 64)
+		if err != nil {
+			continue
+		}
+		c := &city{name: fields[1], countryCode: fields[8], lat: lat, long: long}
+		key := cellFor(lat, long)
+		o.cells[key] = append(o.cells[key], c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Reverse implements the Geocoder interface.
+func (o *Offline) Reverse(lat, long float64) (*Place, error) {
+	center := cellFor(lat, long)
+	var nearest *city
+	best := MaxOfflineDistance
+	for dlat := -1; dlat <= 1; dlat++ {
+		for dlong := -1; dlong <= 1; dlong++ {
+			key := cell{lat: center.lat + dlat, long: wrapLong(center.long + dlong)}
+			for _, c := range o.cells[key] {
+				if d := Distance(lat, long, c.lat, c.long); d <= best {
+					best = d
+					nearest = c
+				}
+			}
+		}
+	}
+	if nearest == nil {
+		return nil, ErrNoPlace
+	}
+	return &Place{City: nearest.name, CountryCode: nearest.countryCode}, nil
+}
+
+func cellFor(lat, long float64) cell {
+	return cell{lat: int(math.Floor(lat)), long: int(math.Floor(long))}
+}
+
+func wrapLong(long int) int {
+	if long < -180 {
+		return long + 360
+	}
+	if long >= 180 {
+		return long - 360
+	}
+	return long
+}
+
+// Distance returns the distance in km between two points, with the haversine
+// formula.
+func Distance(lat1, long1, lat2, long2 float64) float64 {
+	rad := math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlong := (long2 - long1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlong/2)*math.Sin(dlong/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
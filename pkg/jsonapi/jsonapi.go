@@ -3,13 +3,11 @@
 package jsonapi
 
 import (
-	"compress/gzip"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/labstack/echo/v4"
@@ -62,12 +60,9 @@ func WriteData(w io.Writer, o Object, links *LinksList) error {
 // single object as data
 func Data(c echo.Context, statusCode int, o Object, links *LinksList) error {
 	resp := c.Response()
-	w := compressedWriter(c.Request(), resp)
-	defer func() {
-		_ = w.Close()
-	}()
+	resp.Header().Set(echo.HeaderContentType, ContentType)
 	resp.WriteHeader(statusCode)
-	return WriteData(w, o, links)
+	return WriteData(resp, o, links)
 }
 
 // DataList can be called to send an multiple-value answer with a
@@ -102,35 +97,9 @@ func DataListWithMeta(c echo.Context, statusCode int, meta Meta, objs []Object,
 	}
 
 	resp := c.Response()
-	w := compressedWriter(c.Request(), resp)
-	defer func() {
-		_ = w.Close()
-	}()
+	resp.Header().Set(echo.HeaderContentType, ContentType)
 	resp.WriteHeader(statusCode)
-	return json.NewEncoder(w).Encode(doc)
-}
-
-func compressedWriter(req *http.Request, resp *echo.Response) io.WriteCloser {
-	headers := resp.Header()
-	headers.Set(echo.HeaderContentType, ContentType)
-	headers.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
-	if !acceptGzipEncoding(req) {
-		return &nopCloser{resp}
-	}
-	headers.Set(echo.HeaderContentEncoding, "gzip")
-	return gzip.NewWriter(resp)
-}
-
-// nopCloser adds a Close method to a io.Writer (io.NopCloser does that for
-// io.Reader).
-type nopCloser struct {
-	io.Writer
-}
-
-func (nopCloser) Close() error { return nil }
-
-func acceptGzipEncoding(req *http.Request) bool {
-	return strings.Contains(req.Header.Get(echo.HeaderAcceptEncoding), "gzip")
+	return json.NewEncoder(resp).Encode(doc)
 }
 
 // DataRelations can be called to send a Relations page,
@@ -56,6 +56,17 @@ func (i *InMemory) Increment(key string, timeLimit time.Duration) (int64, error)
 	return i.vals[key].val, nil
 }
 
+func (i *InMemory) Get(key string) (int64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ref, ok := i.vals[key]
+	if !ok || time.Now().After(ref.exp) {
+		return 0, nil
+	}
+	return ref.val, nil
+}
+
 func (i *InMemory) Reset(key string) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -2,6 +2,7 @@ package limits
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
@@ -41,6 +42,14 @@ func (r *Redis) Increment(key string, timeLimit time.Duration) (int64, error) {
 	return count.(int64), nil
 }
 
+func (r *Redis) Get(key string) (int64, error) {
+	count, err := r.Client.Get(r.ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
 func (r *Redis) Reset(key string) error {
 	_, err := r.Client.Del(r.ctx, key).Result()
 	return err
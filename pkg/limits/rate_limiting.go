@@ -75,6 +75,18 @@ const (
 	// MagicLinkType is used when sending emails with a magic link that can
 	// authenticate the user into a Cozy
 	MagicLinkType
+	// SharingQuotaMessageType is used when a recipient informs the sharer that
+	// its disk quota has been exceeded
+	SharingQuotaMessageType
+	// WebFingerType is used for counting the number of requests on the
+	// WebFinger endpoint made from an IP address
+	WebFingerType
+	// KonnectorVendorType is used for counting the number of requests made
+	// by the konnectors to a vendor, for all the instances of the stack
+	KonnectorVendorType
+	// OAuthClientAPIType is used for counting the number of requests made
+	// by an OAuth client to the API of an instance, per day
+	OAuthClientAPIType
 )
 
 type counterConfig struct {
@@ -222,6 +234,31 @@ var configs = []counterConfig{
 		Limit:  30,
 		Period: 1 * time.Hour,
 	},
+	// SharingQuotaMessageType
+	{
+		Prefix: "sharing-quota-message",
+		Limit:  1,
+		Period: 24 * time.Hour,
+	},
+	// WebFingerType
+	{
+		Prefix: "webfinger",
+		Limit:  100,
+		Period: 1 * time.Hour,
+	},
+	// KonnectorVendorType
+	{
+		Prefix: "konnector-vendor",
+		Limit:  600,
+		Period: 1 * time.Minute,
+	},
+	// OAuthClientAPIType (the limit is the quota of the client, and the key
+	// includes the day, so the period is a bit longer than a day)
+	{
+		Prefix: "oauth-client-api",
+		Limit:  0,
+		Period: 25 * time.Hour,
+	},
 }
 
 // Counter is an interface for counting number of attempts that can be used to
@@ -229,6 +266,7 @@ var configs = []counterConfig{
 // attacks.
 type Counter interface {
 	Increment(key string, timeLimit time.Duration) (int64, error)
+	Get(key string) (int64, error)
 	Reset(key string) error
 }
 
@@ -258,6 +296,12 @@ func (r *RateLimiter) CheckRateLimit(p prefixer.Prefixer, ct CounterType) error
 
 // CheckRateLimitKey allows to check the rate-limit for a key
 func (r *RateLimiter) CheckRateLimitKey(customKey string, ct CounterType) error {
+	return r.CheckRateLimitKeyWithLimit(customKey, ct, configs[ct].Limit)
+}
+
+// CheckRateLimitKeyWithLimit is like CheckRateLimitKey, but the limit is
+// given instead of the default one for the counter type.
+func (r *RateLimiter) CheckRateLimitKeyWithLimit(customKey string, ct CounterType, limit int64) error {
 	cfg := configs[ct]
 	key := cfg.Prefix + ":" + customKey
 
@@ -268,17 +312,32 @@ func (r *RateLimiter) CheckRateLimitKey(customKey string, ct CounterType) error
 
 	// The first time we reach the limit, we provide a specific error message.
 	// This allows to log a warning only once if needed.
-	if val == cfg.Limit+1 {
+	if val == limit+1 {
 		return ErrRateLimitReached
 	}
 
-	if val > cfg.Limit {
+	if val > limit {
 		return ErrRateLimitExceeded
 	}
 
 	return nil
 }
 
+// IncrementKey increments the counter for the given type and key, and returns
+// its new value. It can be used when the caller needs the value of the
+// counter, like for sending it in the headers of a response.
+func (r *RateLimiter) IncrementKey(customKey string, ct CounterType) (int64, error) {
+	cfg := configs[ct]
+	return r.counter.Increment(cfg.Prefix+":"+customKey, cfg.Period)
+}
+
+// GetCountKey returns the value of the counter for the given type and key,
+// without incrementing it.
+func (r *RateLimiter) GetCountKey(customKey string, ct CounterType) (int64, error) {
+	cfg := configs[ct]
+	return r.counter.Get(cfg.Prefix + ":" + customKey)
+}
+
 // ResetCounter sets again to zero the counter for the given type and instance.
 func (r *RateLimiter) ResetCounter(p prefixer.Prefixer, ct CounterType) {
 	cfg := configs[ct]
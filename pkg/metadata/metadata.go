@@ -37,6 +37,10 @@ type CozyMetadata struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 	// List of objects representing the applications which modified the cozy document
 	UpdatedByApps []*UpdatedByAppEntry `json:"updatedByApps,omitempty"`
+	// Identifier of the user of an organization who created the document
+	CreatedByUser string `json:"createdByUser,omitempty"`
+	// Identifier of the user of an organization who last modified the document
+	UpdatedByUser string `json:"updatedByUser,omitempty"`
 }
 
 // New initializes a new CozyMetadata structure
@@ -95,6 +99,9 @@ func (cm *CozyMetadata) EnsureCreatedFields(defaultMetadata *CozyMetadata) {
 	if cm.UpdatedByApps == nil {
 		cm.UpdatedByApps = defaultMetadata.UpdatedByApps
 	}
+	if cm.CreatedByUser == "" {
+		cm.CreatedByUser = defaultMetadata.CreatedByUser
+	}
 }
 
 // ChangeUpdatedAt updates the UpdatedAt timestamp
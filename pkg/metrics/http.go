@@ -17,6 +17,37 @@ var HTTPTotalDurations = prometheus.NewSummaryVec(
 	[]string{"method", "code"},
 )
 
+// HTTPCompressedResponses is a counter of the responses compressed on the
+// fly, labelled by encoding.
+var HTTPCompressedResponses = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "http",
+		Subsystem: "compression",
+		Name:      "responses",
+
+		Help: "Number of responses compressed on the fly, labelled by encoding",
+	},
+	[]string{"encoding"},
+)
+
+// HTTPCompressionBytes is a counter of the bytes before ("in") and after
+// ("out") the compression of the responses, labelled by encoding. The ratio
+// of the two gives the efficiency of the compression.
+var HTTPCompressionBytes = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "http",
+		Subsystem: "compression",
+		Name:      "bytes",
+
+		Help: "Number of bytes before (in) and after (out) the compression of the responses, labelled by encoding",
+	},
+	[]string{"encoding", "direction"},
+)
+
 func init() {
-	prometheus.MustRegister(HTTPTotalDurations)
+	prometheus.MustRegister(
+		HTTPTotalDurations,
+		HTTPCompressedResponses,
+		HTTPCompressionBytes,
+	)
 }
@@ -0,0 +1,268 @@
+// Package netaccess is for the network access rules: lists of IP ranges
+// (CIDR) that are allowed or denied to access the stack.
+package netaccess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Category is a kind of routes that can have its own rules.
+type Category string
+
+const (
+	// CategoryDefault is used for the routes without more specific rules.
+	CategoryDefault Category = "default"
+	// CategoryAuth is used for the authentication endpoints (/auth/*).
+	CategoryAuth Category = "auth"
+	// CategoryPublic is used for the public shares (with a sharecode).
+	CategoryPublic Category = "public"
+)
+
+// ErrForbidden is returned when the IP address is not allowed by the rules.
+var ErrForbidden = errors.New("Access from this IP address is not allowed")
+
+// Rules are the lists of the allowed and denied networks. An address in a
+// denied network is always rejected. If the allow list is not empty, only
+// the addresses in one of its networks are accepted.
+type Rules struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+
+	// The networks are parsed once, by NewRules or when the rules are
+	// unmarshaled from JSON, and not on each request.
+	allow    Networks
+	deny     Networks
+	compiled bool
+}
+
+// NewRules returns the rules for the given lists of networks, with the
+// networks already parsed.
+func NewRules(allow, deny []string) (Rules, error) {
+	r := Rules{Allow: allow, Deny: deny}
+	err := r.compile()
+	return r, err
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and parses the networks. The
+// invalid networks are ignored here, Validate can be used to report them.
+func (r *Rules) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Allow []string `json:"allow"`
+		Deny  []string `json:"deny"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*r = Rules{Allow: raw.Allow, Deny: raw.Deny}
+	_ = r.compile()
+	return nil
+}
+
+func (r *Rules) compile() error {
+	var errAllow, errDeny error
+	r.allow, errAllow = ParseNetworks(r.Allow)
+	r.deny, errDeny = ParseNetworks(r.Deny)
+	r.compiled = true
+	if errAllow != nil {
+		return errAllow
+	}
+	return errDeny
+}
+
+// IsEmpty returns true if there is no rule.
+func (r Rules) IsEmpty() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0
+}
+
+// Validate checks that the networks are valid CIDR or IP addresses.
+func (r Rules) Validate() error {
+	for _, list := range [][]string{r.Allow, r.Deny} {
+		for _, network := range list {
+			if _, err := parseNetwork(network); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Check returns ErrForbidden if the IP address is not allowed by the rules.
+func (r Rules) Check(ip net.IP) error {
+	if r.IsEmpty() {
+		return nil
+	}
+	if ip == nil {
+		return ErrForbidden
+	}
+	if !r.compiled {
+		_ = r.compile()
+	}
+	if r.deny.Contains(ip) {
+		return ErrForbidden
+	}
+	if len(r.Allow) > 0 && !r.allow.Contains(ip) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Policy is a set of rules, with the rules for a category of routes that
+// replace the default ones when they are defined.
+type Policy struct {
+	Default Rules `json:"default,omitempty"`
+	Auth    Rules `json:"auth,omitempty"`
+	Public  Rules `json:"public,omitempty"`
+}
+
+// IsEmpty returns true if the policy has no rule.
+func (p *Policy) IsEmpty() bool {
+	return p == nil || (p.Default.IsEmpty() && p.Auth.IsEmpty() && p.Public.IsEmpty())
+}
+
+// Validate checks that all the rules of the policy are valid.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, rules := range []Rules{p.Default, p.Auth, p.Public} {
+		if err := rules.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RulesFor returns the rules that apply to the given category of routes.
+func (p *Policy) RulesFor(category Category) Rules {
+	if p == nil {
+		return Rules{}
+	}
+	switch category {
+	case CategoryAuth:
+		if !p.Auth.IsEmpty() {
+			return p.Auth
+		}
+	case CategoryPublic:
+		if !p.Public.IsEmpty() {
+			return p.Public
+		}
+	}
+	return p.Default
+}
+
+// Check returns ErrForbidden if the IP address is not allowed to access the
+// routes of the given category.
+func (p *Policy) Check(category Category, ip net.IP) error {
+	return p.RulesFor(category).Check(ip)
+}
+
+// FromMap builds a policy from a map, like the ones from the settings of a
+// context in the config file.
+func FromMap(m map[string]interface{}) (*Policy, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ParseIP parses an IP address, that can have a port, like the remote
+// address of an HTTP request.
+func ParseIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(strings.TrimSpace(addr))
+}
+
+// Networks is a list of parsed networks.
+type Networks []*net.IPNet
+
+// ParseNetworks parses a list of CIDR or IP addresses. The invalid ones are
+// skipped, and the first error is returned with the valid networks.
+func ParseNetworks(list []string) (Networks, error) {
+	var firstErr error
+	networks := make(Networks, 0, len(list))
+	for _, network := range list {
+		n, err := parseNetwork(network)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		networks = append(networks, n)
+	}
+	return networks, firstErr
+}
+
+// Contains returns true if the IP address is in one of the networks.
+func (ns Networks) Contains(ip net.IP) bool {
+	for _, n := range ns {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetwork(network string) (*net.IPNet, error) {
+	network = strings.TrimSpace(network)
+	if !strings.Contains(network, "/") {
+		ip := net.ParseIP(network)
+		if ip == nil {
+			return nil, fmt.Errorf("netaccess: invalid address %q", network)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, n, err := net.ParseCIDR(network)
+	if err != nil {
+		return nil, fmt.Errorf("netaccess: invalid network %q", network)
+	}
+	return n, nil
+}
+
+// ClientIP returns the IP address of the client of the request. The
+// X-Forwarded-For header is used only when the request comes from one of the
+// trusted proxies, and the address is the last one in this header that is not
+// a trusted proxy.
+func ClientIP(r *http.Request, trustedProxies Networks) net.IP {
+	ip := ParseIP(r.RemoteAddr)
+	if ip == nil || !trustedProxies.Contains(ip) {
+		return ip
+	}
+	var forwarded []string
+	for _, header := range r.Header.Values(echo.HeaderXForwardedFor) {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+	for k := len(forwarded) - 1; k >= 0; k-- {
+		hop := ParseIP(forwarded[k])
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !trustedProxies.Contains(hop) {
+			break
+		}
+	}
+	return ip
+}
@@ -0,0 +1,96 @@
+package netaccess
+
+import (
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	empty := Rules{}
+	assert.NoError(t, empty.Check(net.ParseIP("1.2.3.4")))
+
+	rules := Rules{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32", "192.0.2.7"},
+		Deny:  []string{"10.1.0.0/16"},
+	}
+	require.NoError(t, rules.Validate())
+	assert.NoError(t, rules.Check(net.ParseIP("10.2.3.4")))
+	assert.NoError(t, rules.Check(net.ParseIP("192.0.2.7")))
+	assert.NoError(t, rules.Check(net.ParseIP("2001:db8::1")))
+	assert.Equal(t, ErrForbidden, rules.Check(net.ParseIP("10.1.2.3")))
+	assert.Equal(t, ErrForbidden, rules.Check(net.ParseIP("192.0.2.8")))
+	assert.Equal(t, ErrForbidden, rules.Check(nil))
+
+	deny := Rules{Deny: []string{"203.0.113.0/24"}}
+	assert.NoError(t, deny.Check(net.ParseIP("198.51.100.1")))
+	assert.Equal(t, ErrForbidden, deny.Check(net.ParseIP("203.0.113.9")))
+
+	invalid := Rules{Allow: []string{"10.0.0.0/33"}}
+	assert.Error(t, invalid.Validate())
+	_, err := NewRules([]string{"10.0.0.0/33"}, nil)
+	assert.Error(t, err)
+}
+
+func TestRulesParsedOnce(t *testing.T) {
+	rules, err := NewRules([]string{"10.0.0.0/8"}, []string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	assert.Len(t, rules.allow, 1)
+	assert.Len(t, rules.deny, 1)
+	assert.NoError(t, rules.Check(net.ParseIP("10.2.3.4")))
+	assert.Equal(t, ErrForbidden, rules.Check(net.ParseIP("10.1.2.3")))
+
+	var p Policy
+	require.NoError(t, json.Unmarshal([]byte(`{"auth":{"allow":["192.0.2.0/24"]}}`), &p))
+	assert.True(t, p.Auth.compiled)
+	assert.Len(t, p.Auth.allow, 1)
+	assert.NoError(t, p.Check(CategoryAuth, net.ParseIP("192.0.2.9")))
+	assert.Equal(t, ErrForbidden, p.Check(CategoryAuth, net.ParseIP("198.51.100.1")))
+
+	// The parsed networks are not serialized
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"default":{},"auth":{"allow":["192.0.2.0/24"]},"public":{}}`, string(b))
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := FromMap(map[string]interface{}{
+		"default": map[string]interface{}{"allow": []interface{}{"10.0.0.0/8"}},
+		"public":  map[string]interface{}{"deny": []interface{}{"203.0.113.0/24"}},
+	})
+	require.NoError(t, err)
+	outside := ParseIP("198.51.100.1:4321")
+	assert.Equal(t, ErrForbidden, p.Check(CategoryDefault, outside))
+	assert.Equal(t, ErrForbidden, p.Check(CategoryAuth, outside))
+	assert.NoError(t, p.Check(CategoryPublic, outside))
+	assert.Equal(t, ErrForbidden, p.Check(CategoryPublic, net.ParseIP("203.0.113.9")))
+
+	var none *Policy
+	assert.True(t, none.IsEmpty())
+	assert.NoError(t, none.Check(CategoryAuth, outside))
+
+	_, err = FromMap(map[string]interface{}{
+		"auth": map[string]interface{}{"allow": []interface{}{"not-an-ip"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseNetworks([]string{"127.0.0.1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "198.51.100.1:1234"
+	req.Header.Set("X-Forwarded-For", "192.0.2.1")
+	assert.Equal(t, "198.51.100.1", ClientIP(req, trusted).String())
+
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "192.0.2.1, 198.51.100.7, 10.0.0.2")
+	assert.Equal(t, "198.51.100.7", ClientIP(req, trusted).String())
+
+	req.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "127.0.0.1", ClientIP(req, trusted).String())
+}
@@ -1,8 +1,10 @@
 package realtime
 
 import (
+	"strings"
 	"sync"
 
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 )
@@ -64,11 +66,11 @@ func (h *memHub) subscribe(sub *Subscriber, key string) {
 
 		h.addTopic(sub, key)
 
-		w := &toWatch{sub, ""}
+		w := &toWatch{sub: sub}
 		for {
 			it, exists := h.topics[key]
 			if !exists {
-				it = newTopic()
+				it = newTopic(doctypeOfKey(key))
 				h.topics[key] = it
 			}
 
@@ -98,7 +100,7 @@ func (h *memHub) unsubscribe(sub *Subscriber, key string) {
 
 		h.removeTopic(sub, key)
 
-		w := &toWatch{sub, ""}
+		w := &toWatch{sub: sub}
 		select {
 		case it.unsubscribe <- w:
 			if running := <-it.running; !running {
@@ -115,17 +117,24 @@ func (h *memHub) unsubscribe(sub *Subscriber, key string) {
 }
 
 func (h *memHub) watch(sub *Subscriber, key, id string) {
+	h.watchTopic(key, &toWatch{sub: sub, id: id})
+}
+
+func (h *memHub) watchSelector(sub *Subscriber, key string, selector mango.Map) {
+	h.watchTopic(key, &toWatch{sub: sub, selector: selector})
+}
+
+func (h *memHub) watchTopic(key string, w *toWatch) {
 	h.Lock()
 	go func() {
 		defer h.Unlock()
 
-		h.addTopic(sub, key)
+		h.addTopic(w.sub, key)
 
-		w := &toWatch{sub, id}
 		for {
 			it, exists := h.topics[key]
 			if !exists {
-				it = newTopic()
+				it = newTopic(doctypeOfKey(key))
 				h.topics[key] = it
 			}
 
@@ -144,6 +153,14 @@ func (h *memHub) watch(sub *Subscriber, key, id string) {
 }
 
 func (h *memHub) unwatch(sub *Subscriber, key, id string) {
+	h.unwatchTopic(key, &toWatch{sub: sub, id: id})
+}
+
+func (h *memHub) unwatchSelector(sub *Subscriber, key string, selector mango.Map) {
+	h.unwatchTopic(key, &toWatch{sub: sub, selector: selector})
+}
+
+func (h *memHub) unwatchTopic(key string, w *toWatch) {
 	h.Lock()
 	go func() {
 		defer h.Unlock()
@@ -153,7 +170,6 @@ func (h *memHub) unwatch(sub *Subscriber, key, id string) {
 			return
 		}
 
-		w := &toWatch{sub, id}
 		select {
 		case it.unsubscribe <- w:
 			if running := <-it.running; !running {
@@ -210,3 +226,9 @@ func (h *memHub) removeTopic(sub *Subscriber, key string) {
 func topicKey(db prefixer.Prefixer, doctype string) string {
 	return db.DBPrefix() + ":" + doctype
 }
+
+// doctypeOfKey returns the doctype part of a topic key. The prefix can
+// contain a colon (a domain with a port), but not the doctype.
+func doctypeOfKey(key string) string {
+	return key[strings.LastIndex(key, ":")+1:]
+}
@@ -0,0 +1,52 @@
+package realtime
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// subscriptionsGauge is the number of subscribers for the topics of the
+// hub, labelled by doctype ("*" for the firehose).
+var subscriptionsGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "realtime",
+		Subsystem: "subscriptions",
+		Name:      "count",
+
+		Help: `Number of subscriptions to the realtime hub of this process, labelled by doctype.`,
+	},
+	[]string{"doctype"},
+)
+
+// droppedEventsCounter is the number of events that have not been sent to a
+// subscriber because it was too slow, labelled by doctype.
+var droppedEventsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "realtime",
+		Subsystem: "events",
+		Name:      "dropped",
+
+		Help: `Number of realtime events dropped because a subscriber was too slow, labelled by doctype.`,
+	},
+	[]string{"doctype"},
+)
+
+// bufferUsageHistogram is the ratio of the buffer of a subscriber that is
+// used when an event is sent to it. A ratio close to 1 means that the
+// consumer (a websocket for example) can't keep up.
+var bufferUsageHistogram = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Namespace: "realtime",
+		Subsystem: "subscribers",
+		Name:      "buffer_usage",
+
+		Help: `Ratio of the buffer of a realtime subscriber that is used when an event is sent to it.`,
+
+		Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+	},
+)
+
+func init() {
+	prometheus.MustRegister(
+		subscriptionsGauge,
+		droppedEventsCounter,
+		bufferUsageHistogram,
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 )
 
@@ -79,6 +80,8 @@ type Hub interface {
 	unsubscribe(sub *Subscriber, key string)
 	watch(sub *Subscriber, key, id string)
 	unwatch(sub *Subscriber, key, id string)
+	watchSelector(sub *Subscriber, key string, selector mango.Map)
+	unwatchSelector(sub *Subscriber, key string, selector mango.Map)
 	close(sub *Subscriber)
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -215,6 +216,77 @@ func TestSubscribeWatchUnwatch(t *testing.T) {
 	assert.Equal(t, "id2", e.Doc.ID())
 }
 
+type testFileDoc struct {
+	id    string
+	dirID string
+}
+
+func (t *testFileDoc) ID() string      { return t.id }
+func (t *testFileDoc) DocType() string { return "io.cozy.testfiles" }
+func (t *testFileDoc) MarshalJSON() ([]byte, error) {
+	j := `{"_id":"` + t.id + `", "dir_id":"` + t.dirID + `"}`
+	return []byte(j), nil
+}
+
+func TestWatchSelector(t *testing.T) {
+	h := newMemHub()
+	sub := h.Subscriber(testingDB)
+	defer sub.Close()
+
+	selector := mango.Map{"dir_id": "xyz"}
+	sub.WatchSelector("io.cozy.testfiles", selector)
+	time.Sleep(1 * time.Millisecond)
+
+	h.Publish(testingDB, EventCreate, &testFileDoc{id: "id1", dirID: "abc"}, nil)
+	h.Publish(testingDB, EventCreate, &testFileDoc{id: "id2", dirID: "xyz"}, nil)
+	e := <-sub.Channel
+	assert.Equal(t, "id2", e.Doc.ID())
+
+	// A file moved out of the directory is still sent
+	h.Publish(testingDB, EventUpdate,
+		&testFileDoc{id: "id2", dirID: "abc"},
+		&testFileDoc{id: "id2", dirID: "xyz"})
+	e = <-sub.Channel
+	assert.Equal(t, "id2", e.Doc.ID())
+	assert.Equal(t, EventUpdate, e.Verb)
+
+	sub.Watch("io.cozy.testfiles", "id3")
+	time.Sleep(1 * time.Millisecond)
+	sub.UnwatchSelector("io.cozy.testfiles", mango.Map{"dir_id": "xyz"})
+	time.Sleep(1 * time.Millisecond)
+
+	h.Publish(testingDB, EventCreate, &testFileDoc{id: "id4", dirID: "xyz"}, nil)
+	h.Publish(testingDB, EventCreate, &testFileDoc{id: "id3", dirID: "abc"}, nil)
+	e = <-sub.Channel
+	assert.Equal(t, "id3", e.Doc.ID())
+}
+
+func TestMemSlowSubscriber(t *testing.T) {
+	h := newMemHub()
+	slow := h.Subscriber(testingDB)
+	slow.AllowDrops()
+	defer slow.Close()
+	slow.Subscribe("io.cozy.testobject")
+	time.Sleep(1 * time.Millisecond)
+
+	n := cap(slow.Channel) + 5
+	for i := 0; i < n; i++ {
+		h.Publish(testingDB, EventCreate, &testDoc{doctype: "io.cozy.testobject", id: "foo"}, nil)
+	}
+
+	select {
+	case <-slow.Lossy:
+	case <-time.After(time.Second):
+		t.Fatal("the slow subscriber has not been told about the dropped events")
+	}
+	total := 0
+	assert.Eventually(t, func() bool {
+		total += slow.TakeDropped()["io.cozy.testobject"]
+		return total == 5
+	}, time.Second, time.Millisecond)
+	assert.Len(t, slow.Channel, cap(slow.Channel))
+}
+
 func TestRedisRealtime(t *testing.T) {
 	if testing.Short() {
 		t.Skip("a redis is required for this test: test skipped due to the use of --short flag")
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	redis "github.com/redis/go-redis/v9"
@@ -21,7 +22,7 @@ type redisHub struct {
 
 func newRedisHub(c redis.UniversalClient) *redisHub {
 	ctx := context.Background()
-	firehose := newTopic()
+	firehose := newTopic("*")
 	mem := newMemHub()
 	hub := &redisHub{c, ctx, mem, firehose}
 	go hub.start()
@@ -144,7 +145,7 @@ func (h *redisHub) Subscriber(db prefixer.Prefixer) *Subscriber {
 
 func (h *redisHub) SubscribeFirehose() *Subscriber {
 	sub := newSubscriber(h, globalPrefixer)
-	h.firehose.subscribe <- &toWatch{sub, ""}
+	h.firehose.subscribe <- &toWatch{sub: sub}
 	return sub
 }
 
@@ -153,7 +154,7 @@ func (h *redisHub) subscribe(sub *Subscriber, key string) {
 }
 
 func (h *redisHub) unsubscribe(sub *Subscriber, key string) {
-	h.firehose.unsubscribe <- &toWatch{sub, ""}
+	h.firehose.unsubscribe <- &toWatch{sub: sub}
 	<-h.firehose.running
 }
 
@@ -165,6 +166,14 @@ func (h *redisHub) unwatch(sub *Subscriber, key, id string) {
 	panic("not reachable code")
 }
 
+func (h *redisHub) watchSelector(sub *Subscriber, key string, selector mango.Map) {
+	panic("not reachable code")
+}
+
+func (h *redisHub) unwatchSelector(sub *Subscriber, key string, selector mango.Map) {
+	panic("not reachable code")
+}
+
 func (h *redisHub) close(sub *Subscriber) {
 	h.unsubscribe(sub, "*")
 }
@@ -1,6 +1,9 @@
 package realtime
 
 import (
+	"sync"
+
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 )
 
@@ -8,8 +11,16 @@ import (
 type Subscriber struct {
 	prefixer.Prefixer
 	Channel EventsChan
+	// Lossy receives a signal when some events have been dropped because the
+	// subscriber was too slow. It is nil if the subscriber doesn't allow
+	// drops.
+	Lossy   chan struct{}
 	hub     Hub
 	running chan struct{}
+
+	lossy   bool
+	mu      sync.Mutex
+	dropped map[string]int // number of dropped events by doctype
 }
 
 // EventsChan is a chan of events
@@ -24,6 +35,41 @@ func newSubscriber(hub Hub, db prefixer.Prefixer) *Subscriber {
 	}
 }
 
+// AllowDrops tells the hub that the events can be dropped for this
+// subscriber when its buffer is full, instead of blocking the hub until the
+// subscriber reads them. It is used for the clients like the websockets that
+// can be slow, and that can reload their data when they are told that some
+// events have been lost. It must be called before subscribing.
+func (sub *Subscriber) AllowDrops() {
+	sub.lossy = true
+	sub.Lossy = make(chan struct{}, 1)
+}
+
+// TakeDropped returns the number of events dropped since the last call, by
+// doctype.
+func (sub *Subscriber) TakeDropped() map[string]int {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	dropped := sub.dropped
+	sub.dropped = nil
+	return dropped
+}
+
+func (sub *Subscriber) drop(e *Event) {
+	doctype := e.Doc.DocType()
+	droppedEventsCounter.WithLabelValues(doctype).Inc()
+	sub.mu.Lock()
+	if sub.dropped == nil {
+		sub.dropped = make(map[string]int)
+	}
+	sub.dropped[doctype]++
+	sub.mu.Unlock()
+	select {
+	case sub.Lossy <- struct{}{}:
+	default:
+	}
+}
+
 // Subscribe adds a listener for events on a whole doctype
 func (sub *Subscriber) Subscribe(doctype string) {
 	if sub.hub == nil {
@@ -60,6 +106,27 @@ func (sub *Subscriber) Unwatch(doctype, id string) {
 	sub.hub.unwatch(sub, key, id)
 }
 
+// WatchSelector adds a listener for events on the documents of a doctype that
+// match the selector. The selector must have been validated with
+// mango.ValidateSelector.
+func (sub *Subscriber) WatchSelector(doctype string, selector mango.Map) {
+	if sub.hub == nil {
+		return
+	}
+	key := topicKey(sub, doctype)
+	sub.hub.watchSelector(sub, key, selector)
+}
+
+// UnwatchSelector removes a listener for events on the documents of a doctype
+// that match the selector.
+func (sub *Subscriber) UnwatchSelector(doctype string, selector mango.Map) {
+	if sub.hub == nil {
+		return
+	}
+	key := topicKey(sub, doctype)
+	sub.hub.unwatchSelector(sub, key, selector)
+}
+
 // Close will unsubscribe to all topics and the subscriber should no longer be
 // used after that.
 func (sub *Subscriber) Close() {
@@ -1,16 +1,30 @@
 package realtime
 
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+)
+
 type filter struct {
-	whole bool // true if the events for the whole doctype should be sent
-	ids   []string
+	whole     bool // true if the events for the whole doctype should be sent
+	ids       []string
+	selectors []mango.Map
+}
+
+func (f filter) isEmpty() bool {
+	return !f.whole && len(f.ids) == 0 && len(f.selectors) == 0
 }
 
 type toWatch struct {
-	sub *Subscriber
-	id  string // empty string means the whole doctype
+	sub      *Subscriber
+	id       string    // empty string means the whole doctype
+	selector mango.Map // if not nil, only the matching documents are sent
 }
 
 type topic struct {
+	doctype     string                 // used for the metrics
 	broadcast   chan *Event            // input
 	subs        map[*Subscriber]filter // output
 	subscribe   chan *toWatch
@@ -18,8 +32,9 @@ type topic struct {
 	running     chan bool
 }
 
-func newTopic() *topic {
+func newTopic(doctype string) *topic {
 	topic := &topic{
+		doctype:     doctype,
 		broadcast:   make(chan *Event, 10),
 		subs:        make(map[*Subscriber]filter),
 		subscribe:   make(chan *toWatch),
@@ -49,6 +64,7 @@ func (t *topic) loop() {
 }
 
 func (t *topic) publish(e *Event) {
+	var docs []map[string]interface{}
 	for s, f := range t.subs {
 		ok := false
 		if f.whole {
@@ -61,18 +77,48 @@ func (t *topic) publish(e *Event) {
 				}
 			}
 		}
-		if ok {
-			select {
-			case s.Channel <- e:
-			case <-s.running: // the subscriber has been closed
+		if !ok && len(f.selectors) > 0 {
+			if docs == nil {
+				docs = decodeDocs(e)
 			}
+			ok = matchAny(f.selectors, docs)
+		}
+		if ok {
+			t.send(s, e)
 		}
 	}
 }
 
+// send gives the event to the subscriber. If the subscriber allows it and
+// its buffer is full, the event is dropped instead of blocking the topic (and
+// so the hub), and the subscriber is told about it.
+func (t *topic) send(s *Subscriber, e *Event) {
+	bufferUsageHistogram.Observe(float64(len(s.Channel)) / float64(cap(s.Channel)))
+	if !s.lossy {
+		select {
+		case s.Channel <- e:
+		case <-s.running: // the subscriber has been closed
+		}
+		return
+	}
+	select {
+	case s.Channel <- e:
+	case <-s.running:
+	default:
+		s.drop(e)
+	}
+}
+
 func (t *topic) doSubscribe(w *toWatch) {
-	f := t.subs[w.sub]
-	if w.id == "" {
+	f, exists := t.subs[w.sub]
+	if !exists {
+		subscriptionsGauge.WithLabelValues(t.doctype).Inc()
+	}
+	if w.selector != nil {
+		if indexOfSelector(f.selectors, w.selector) < 0 {
+			f.selectors = append(f.selectors, w.selector)
+		}
+	} else if w.id == "" {
 		f.whole = true
 	} else {
 		f.ids = append(f.ids, w.id)
@@ -81,20 +127,85 @@ func (t *topic) doSubscribe(w *toWatch) {
 }
 
 func (t *topic) doUnsubscribe(w *toWatch) {
-	if w.id == "" {
-		delete(t.subs, w.sub)
+	if _, ok := t.subs[w.sub]; !ok {
+		return
+	}
+	if w.id == "" && w.selector == nil {
+		t.remove(w.sub)
 	} else if f, ok := t.subs[w.sub]; ok {
-		ids := f.ids[:0]
-		for _, id := range f.ids {
-			if id != w.id {
-				ids = append(ids, id)
+		if w.selector != nil {
+			if idx := indexOfSelector(f.selectors, w.selector); idx >= 0 {
+				f.selectors = append(f.selectors[:idx], f.selectors[idx+1:]...)
 			}
-		}
-		if len(ids) == 0 && !f.whole {
-			delete(t.subs, w.sub)
 		} else {
+			ids := f.ids[:0]
+			for _, id := range f.ids {
+				if id != w.id {
+					ids = append(ids, id)
+				}
+			}
 			f.ids = ids
+		}
+		if f.isEmpty() {
+			t.remove(w.sub)
+		} else {
 			t.subs[w.sub] = f
 		}
 	}
 }
+
+func (t *topic) remove(sub *Subscriber) {
+	delete(t.subs, sub)
+	subscriptionsGauge.WithLabelValues(t.doctype).Dec()
+}
+
+func indexOfSelector(selectors []mango.Map, selector mango.Map) int {
+	for i, s := range selectors {
+		if reflect.DeepEqual(s, selector) {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeDocs returns the document of the event, and the old version of the
+// document if any, as maps that can be evaluated against the selectors. The
+// old version is used to also send the event for a document that no longer
+// matches the selector (for example, a file moved to another directory).
+func decodeDocs(e *Event) []map[string]interface{} {
+	docs := make([]map[string]interface{}, 0, 2)
+	for _, doc := range []Doc{e.Doc, e.OldDoc} {
+		if doc == nil {
+			continue
+		}
+		if v := reflect.ValueOf(doc); v.Kind() == reflect.Ptr && v.IsNil() {
+			continue
+		}
+		buf, err := json.Marshal(doc)
+		if err != nil {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(buf, &m); err == nil {
+			docs = append(docs, m)
+		}
+	}
+	return docs
+}
+
+// MatchesSelector returns true if the document of the event, or its old
+// version, matches the selector.
+func (e *Event) MatchesSelector(selector mango.Map) bool {
+	return matchAny([]mango.Map{selector}, decodeDocs(e))
+}
+
+func matchAny(selectors []mango.Map, docs []map[string]interface{}) bool {
+	for _, selector := range selectors {
+		for _, doc := range docs {
+			if mango.Matches(selector, doc) {
+				return true
+			}
+		}
+	}
+	return false
+}
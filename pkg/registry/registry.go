@@ -2,6 +2,7 @@ package registry
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,7 +15,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/egress"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/httpcache"
 	"github.com/labstack/echo/v4"
@@ -55,25 +58,69 @@ var errApplicationNotFound = errors.New("registry: application not found")
 var (
 	proxyClient = &http.Client{
 		Timeout:   10 * time.Second,
-		Transport: httpcache.NewMemoryCacheTransport(32),
+		Transport: newCacheTransport(32),
 	}
 
 	maintenanceClient = &http.Client{
 		Timeout:   10 * time.Second,
-		Transport: httpcache.NewMemoryCacheTransport(32),
+		Transport: newCacheTransport(32),
 	}
 
 	appClient = &http.Client{
 		Timeout:   5 * time.Second,
-		Transport: httpcache.NewMemoryCacheTransport(256),
+		Transport: newCacheTransport(256),
 	}
 
 	latestVersionClient = &http.Client{
 		Timeout:   5 * time.Second,
-		Transport: httpcache.NewMemoryCacheTransport(256),
+		Transport: newCacheTransport(256),
+	}
+
+	statsClient = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: registryTransport,
 	}
 )
 
+// registryTransport sends the requests to a registry through the egress proxy
+// of the context that has declared this registry.
+var registryTransport = egress.Wrap(http.DefaultTransport.(*http.Transport))
+
+func newCacheTransport(maxEntries int) *httpcache.Transport {
+	t := httpcache.NewMemoryCacheTransport(maxEntries)
+	t.Transport = registryTransport
+	return t
+}
+
+// registryContext returns the name of the context that has declared the
+// registry, for using its egress proxy. The default context is preferred, as
+// its registries are also added to the other contexts.
+func registryContext(registry *url.URL) string {
+	cfg := config.GetConfig()
+	if cfg == nil {
+		return config.DefaultInstanceContext
+	}
+	raw := registry.String()
+	for _, u := range cfg.Registries[config.DefaultInstanceContext] {
+		if u.String() == raw {
+			return config.DefaultInstanceContext
+		}
+	}
+	for name, urls := range cfg.Registries {
+		for _, u := range urls {
+			if u.String() == raw {
+				return name
+			}
+		}
+	}
+	return config.DefaultInstanceContext
+}
+
+func newRegistryRequest(method string, registry, u *url.URL, body io.Reader) (*http.Request, error) {
+	ctx := egress.WithContextName(context.Background(), registryContext(registry))
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
 // CacheControl defines whether or not to use caching for the request made to
 // the registries.
 type CacheControl int
@@ -105,6 +152,42 @@ func GetVersion(slug, version string, registries []*url.URL) (*Version, error) {
 	return v, nil
 }
 
+// SendStats sends the statistics of a version of an application to the first
+// registry that knows this version, with a POST request on
+// /registry/:slug/:version/stats.
+func SendStats(slug, version string, stats interface{}, registries []*url.URL) error {
+	body, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	ref := &url.URL{Path: fmt.Sprintf("/registry/%s/%s/stats",
+		url.PathEscape(slug),
+		url.PathEscape(version))}
+	for _, registry := range registries {
+		u := registry.ResolveReference(ref)
+		u.Path = path.Join(registry.Path, ref.Path)
+		req, err := newRegistryRequest(http.MethodPost, registry, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		resp, err := statsClient.Do(req)
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if resp.StatusCode/100 != 2 {
+			return echo.NewHTTPError(resp.StatusCode)
+		}
+		return nil
+	}
+	return errVersionNotFound
+}
+
 // GetLatestVersion returns the latest version available from the list of
 // registries by resolving them in sequence using the specified application
 // slug and channel name.
@@ -502,7 +585,7 @@ func fetchUntilFound(client *http.Client, registries []*url.URL, requestURI stri
 func fetch(client *http.Client, registry, ref *url.URL, cache CacheControl) (resp *http.Response, ok bool, err error) {
 	u := registry.ResolveReference(ref)
 	u.Path = path.Join(registry.Path, ref.Path)
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := newRegistryRequest(http.MethodGet, registry, u, nil)
 	if err != nil {
 		return
 	}
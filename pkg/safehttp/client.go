@@ -5,6 +5,7 @@
 package safehttp
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -21,6 +22,13 @@ var safeDialer = &net.Dialer{
 	Control:   safeControl,
 }
 
+// DialContext opens a TCP connection to the given address, with the same
+// checks as the http clients of this package (public IP address, and port 80
+// or 443).
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return safeDialer.DialContext(ctx, network, addr)
+}
+
 var safeTransport = &http.Transport{
 	// Default values for http.DefaultClient
 	Proxy:                 http.ProxyFromEnvironment,
@@ -0,0 +1,227 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// AWSKMSEndpoint is the URL of the AWS KMS API, with a placeholder for the
+	// region. It is a variable for testing purpose.
+	AWSKMSEndpoint = "https://kms.%s.amazonaws.com/"
+	// GCPKMSAPI is the URL of the Google Cloud KMS API. It is a variable for
+	// testing purpose.
+	GCPKMSAPI = "https://cloudkms.googleapis.com/v1/"
+	// GCPMetadataTokenURL is the URL used to get an access token for the
+	// service account of the VM on Google Cloud.
+	GCPMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// awsKMSBackend decrypts a secret with AWS KMS. The reference is the
+// ciphertext encoded in base64, as given by "aws kms encrypt": the key is
+// identified by the ciphertext. The region and the credentials are taken from
+// the AWS_REGION (or AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+type awsKMSBackend struct{}
+
+func (awsKMSBackend) Fetch(ctx context.Context, ciphertext string) (string, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	creds := awsCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if region == "" || creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return "", errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	body, err := json.Marshal(map[string]string{"CiphertextBlob": ciphertext})
+	if err != nil {
+		return "", err
+	}
+	u := AWSKMSEndpoint
+	if strings.Contains(u, "%s") {
+		u = fmt.Sprintf(u, region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	signV4(req, body, creds, region, "kms", time.Now())
+
+	var payload struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := doJSON(req, &payload); err != nil {
+		return "", err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(payload.Plaintext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// gcpKMSBackend decrypts a secret with Google Cloud KMS. The reference is the
+// name of the key and the ciphertext encoded in base64, separated by a #:
+// "gcpkms:projects/p/locations/l/keyRings/r/cryptoKeys/k#CiQA...". The access
+// token is taken from the GOOGLE_OAUTH_ACCESS_TOKEN environment variable, or
+// else from the metadata server for the service account of the VM.
+type gcpKMSBackend struct{}
+
+func (gcpKMSBackend) Fetch(ctx context.Context, ref string) (string, error) {
+	key, ciphertext := splitField(ref, "")
+	if key == "" || ciphertext == "" {
+		return "", errors.New("the reference must be <key>#<ciphertext>")
+	}
+	token, err := gcpToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+	if err != nil {
+		return "", err
+	}
+	u := GCPKMSAPI + strings.TrimPrefix(key, "/") + ":decrypt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var payload struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := doJSON(req, &payload); err != nil {
+		return "", err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(payload.Plaintext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func gcpToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, GCPMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var payload struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(req, &payload); err != nil {
+		return "", fmt.Errorf("cannot get an access token: %w", err)
+	}
+	return payload.AccessToken, nil
+}
+
+func doJSON(req *http.Request, payload interface{}) error {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected response: %d %s", res.StatusCode, body)
+	}
+	return json.NewDecoder(res.Body).Decode(payload)
+}
+
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// signV4 signs the request with the AWS Signature Version 4. The host and
+// all the headers of the request are signed.
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
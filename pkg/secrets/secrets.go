@@ -0,0 +1,182 @@
+// Package secrets is used to resolve the sensitive values of the
+// configuration (credentials, private keys, etc.) from a secrets backend,
+// like HashiCorp Vault, AWS KMS, GCP KMS or the systemd credentials, instead
+// of writing them in plaintext in the configuration file.
+//
+// A secret is referenced by a string with the name of the backend as the
+// scheme, like "vault:secret/data/cozy#couchdb_password". The values are
+// remembered, so that they can be fetched again to detect a rotation.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// fetchTimeout is the maximal duration for fetching a secret.
+const fetchTimeout = 10 * time.Second
+
+var (
+	// ErrUnknownBackend is used when the scheme of a reference is not a known
+	// backend.
+	ErrUnknownBackend = errors.New("Unknown secrets backend")
+	// ErrNotFound is used when the secret does not exist in the backend.
+	ErrNotFound = errors.New("Secret not found")
+)
+
+// Backend is the interface for fetching a secret from a backend. The
+// reference is given without the scheme.
+type Backend interface {
+	Fetch(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	mu       sync.Mutex
+	backends = map[string]Backend{
+		"env":     envBackend{},
+		"file":    fileBackend{},
+		"systemd": systemdBackend{},
+		"vault":   vaultBackend{},
+		"awskms":  awsKMSBackend{},
+		"gcpkms":  gcpKMSBackend{},
+	}
+	resolved = map[string]string{}
+)
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+// Register adds a backend for the given scheme.
+func Register(scheme string, backend Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	backends[scheme] = backend
+}
+
+// Resolve returns the value of the secret for the given reference.
+func Resolve(ref string) (string, error) {
+	value, err := fetch(ref)
+	if err != nil {
+		return "", err
+	}
+	mu.Lock()
+	resolved[ref] = value
+	mu.Unlock()
+	return value, nil
+}
+
+// Used returns true if at least one secret has been resolved.
+func Used() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(resolved) > 0
+}
+
+// Refresh fetches again all the secrets that have been resolved, and returns
+// true if at least one of them has changed. A secret that cannot be fetched
+// keeps its previous value.
+func Refresh() (bool, error) {
+	mu.Lock()
+	refs := make([]string, 0, len(resolved))
+	for ref := range resolved {
+		refs = append(refs, ref)
+	}
+	mu.Unlock()
+
+	changed := false
+	var errm error
+	for _, ref := range refs {
+		value, err := fetch(ref)
+		if err != nil {
+			errm = multierror.Append(errm, err)
+			continue
+		}
+		mu.Lock()
+		if resolved[ref] != value {
+			resolved[ref] = value
+			changed = true
+		}
+		mu.Unlock()
+	}
+	return changed, errm
+}
+
+func fetch(ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, ":")
+	if !ok {
+		return "", fmt.Errorf("secrets: invalid reference %q", ref)
+	}
+	mu.Lock()
+	backend, ok := backends[scheme]
+	mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("secrets: %w: %q", ErrUnknownBackend, scheme)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	value, err := backend.Fetch(ctx, rest)
+	if err != nil {
+		return "", fmt.Errorf("secrets: cannot fetch %s secret %q: %w", scheme, rest, err)
+	}
+	return value, nil
+}
+
+// splitField splits a reference like "path#field".
+func splitField(ref, defaultField string) (string, string) {
+	if i := strings.LastIndexByte(ref, '#'); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, defaultField
+}
+
+// envBackend reads the secret from an environment variable: "env:NAME".
+type envBackend struct{}
+
+func (envBackend) Fetch(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// fileBackend reads the secret from a file: "file:/path/to/secret".
+type fileBackend struct{}
+
+func (fileBackend) Fetch(_ context.Context, path string) (string, error) {
+	return readFile(path)
+}
+
+// systemdBackend reads the secret from the credentials given by systemd to
+// the service (LoadCredential= or LoadCredentialEncrypted=): "systemd:name".
+type systemdBackend struct{}
+
+func (systemdBackend) Fetch(_ context.Context, name string) (string, error) {
+	dir := os.Getenv("CREDENTIALS_DIRECTORY")
+	if dir == "" {
+		return "", errors.New("CREDENTIALS_DIRECTORY is not set")
+	}
+	if name == "" || strings.ContainsAny(name, "/\\") || name == ".." {
+		return "", fmt.Errorf("invalid credential name %q", name)
+	}
+	return readFile(filepath.Join(dir, name))
+}
+
+func readFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,153 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBackends(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "couch"), []byte("s3cr3t\n"), 0600))
+
+	value, err := Resolve("file:" + filepath.Join(dir, "couch"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	value, err = Resolve("systemd:couch")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+	_, err = Resolve("systemd:../couch")
+	assert.Error(t, err)
+
+	t.Setenv("COZY_TEST_SECRET", "foo")
+	value, err = Resolve("env:COZY_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", value)
+
+	_, err = Resolve("env:COZY_TEST_NO_SUCH_SECRET")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Resolve("unknown:foo")
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+}
+
+func TestRefresh(t *testing.T) {
+	mu.Lock()
+	resolved = map[string]string{}
+	mu.Unlock()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0600))
+	_, err := Resolve("file:" + path)
+	require.NoError(t, err)
+	assert.True(t, Used())
+
+	changed, err := Refresh()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, os.WriteFile(path, []byte("v2"), 0600))
+	changed, err = Refresh()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// A secret that cannot be fetched keeps its value
+	require.NoError(t, os.Remove(path))
+	changed, err = Refresh()
+	assert.Error(t, err)
+	assert.False(t, changed)
+}
+
+func TestVault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/cozy":
+			_, _ = w.Write([]byte(`{"data":{"data":{"couchdb_password":"p4ss"},"metadata":{"version":3}}}`))
+		case "/v1/kv/cozy":
+			_, _ = w.Write([]byte(`{"data":{"value":"v4lue"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	t.Setenv("VAULT_ADDR", ts.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	value, err := Resolve("vault:secret/data/cozy#couchdb_password")
+	require.NoError(t, err)
+	assert.Equal(t, "p4ss", value)
+	value, err = Resolve("vault:kv/cozy")
+	require.NoError(t, err)
+	assert.Equal(t, "v4lue", value)
+	_, err = Resolve("vault:secret/data/cozy#other")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Resolve("vault:secret/data/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAWSKMS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=AKID/")
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-3/kms/aws4_request")
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "Y2lwaGVy", body["CiphertextBlob"])
+		plaintext := base64.StdEncoding.EncodeToString([]byte("swift-password"))
+		_, _ = w.Write([]byte(`{"Plaintext":"` + plaintext + `"}`))
+	}))
+	defer ts.Close()
+	AWSKMSEndpoint = ts.URL
+	t.Setenv("AWS_REGION", "eu-west-3")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	value, err := Resolve("awskms:Y2lwaGVy")
+	require.NoError(t, err)
+	assert.Equal(t, "swift-password", value)
+}
+
+func TestGCPKMS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/projects/p/locations/global/keyRings/r/cryptoKeys/k:decrypt", r.URL.Path)
+		assert.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+		plaintext := base64.StdEncoding.EncodeToString([]byte("apns-key"))
+		_, _ = w.Write([]byte(`{"plaintext":"` + plaintext + `"}`))
+	}))
+	defer ts.Close()
+	GCPKMSAPI = ts.URL + "/v1/"
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "ya29.token")
+
+	value, err := Resolve("gcpkms:projects/p/locations/global/keyRings/r/cryptoKeys/k#Y2lwaGVy")
+	require.NoError(t, err)
+	assert.Equal(t, "apns-key", value)
+}
+
+func TestSignV4(t *testing.T) {
+	// Example "get-vanilla" from the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	creds := awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	at := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, nil, creds, "us-east-1", "service", at)
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
@@ -0,0 +1,97 @@
+package accounts
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/account"
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type backupRequest struct {
+	Key    string `json:"key"`
+	Bundle string `json:"bundle,omitempty"`
+}
+
+func exportBackup(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := checkFlagship(c); err != nil {
+		return err
+	}
+	req, err := bindBackupRequest(c, false)
+	if err != nil {
+		return err
+	}
+	bundle, err := account.ExportBackup(inst, req.raw)
+	if err != nil {
+		return wrapBackupError(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"bundle": bundle})
+}
+
+func restoreBackup(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := checkFlagship(c); err != nil {
+		return err
+	}
+	req, err := bindBackupRequest(c, true)
+	if err != nil {
+		return err
+	}
+	restored, err := account.RestoreBackup(inst, req.raw, req.Bundle)
+	if err != nil {
+		return wrapBackupError(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"restored": restored})
+}
+
+type decodedBackupRequest struct {
+	backupRequest
+	raw []byte
+}
+
+func bindBackupRequest(c echo.Context, withBundle bool) (*decodedBackupRequest, error) {
+	var req backupRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return nil, jsonapi.BadJSON()
+	}
+	raw, err := base64.StdEncoding.DecodeString(req.Key)
+	if err != nil || len(raw) != account.BackupKeyLen {
+		return nil, jsonapi.InvalidParameter("key", account.ErrInvalidBackupKey)
+	}
+	if withBundle && req.Bundle == "" {
+		return nil, jsonapi.InvalidParameter("bundle", errors.New("missing bundle"))
+	}
+	return &decodedBackupRequest{backupRequest: req, raw: raw}, nil
+}
+
+// checkFlagship returns an error if the request has not been made by the
+// flagship app with a token that has the full access to the instance, as the
+// backup contains all the credentials of the accounts.
+func checkFlagship(c echo.Context) error {
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	client, ok := pdoc.Client.(*oauth.Client)
+	if !ok || pdoc.Type != permission.TypeOauth || !client.Flagship || !pdoc.Permissions.IsMaximal() {
+		return jsonapi.Forbidden(errors.New("only the flagship app can backup the accounts"))
+	}
+	return nil
+}
+
+func wrapBackupError(err error) error {
+	switch {
+	case errors.Is(err, account.ErrInvalidBackupKey):
+		return jsonapi.InvalidParameter("key", err)
+	case errors.Is(err, account.ErrInvalidBackup):
+		return jsonapi.InvalidParameter("bundle", err)
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/account"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type apiDelegation struct {
+	*account.Delegation
+}
+
+func (d *apiDelegation) MarshalJSON() ([]byte, error)           { return json.Marshal(d.Delegation) }
+func (d *apiDelegation) Relationships() jsonapi.RelationshipMap { return nil }
+func (d *apiDelegation) Included() []jsonapi.Object             { return nil }
+func (d *apiDelegation) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/accounts/delegations/" + d.ID()}
+}
+
+type delegationAttrs struct {
+	AccountID string `json:"account_id"`
+	Recipient string `json:"recipient"`
+}
+
+func listDelegations(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.AccountsDelegations); err != nil {
+		return err
+	}
+	list, err := account.ListDelegations(inst)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(list))
+	for i, d := range list {
+		objs[i] = &apiDelegation{d}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func createDelegation(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.AccountsDelegations); err != nil {
+		return err
+	}
+	var attrs delegationAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return err
+	}
+	if attrs.AccountID == "" || attrs.Recipient == "" {
+		return jsonapi.BadRequest(errors.New("missing account_id or recipient"))
+	}
+	d, err := account.Delegate(inst, attrs.AccountID, attrs.Recipient)
+	if err != nil {
+		return wrapDelegationError(err)
+	}
+	return jsonapi.Data(c, http.StatusCreated, &apiDelegation{d}, nil)
+}
+
+func revokeDelegation(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.AccountsDelegations); err != nil {
+		return err
+	}
+	d, err := account.GetDelegation(inst, c.Param("delegation-id"))
+	if err != nil {
+		return wrapDelegationError(err)
+	}
+	if err := account.RevokeDelegation(inst, d); err != nil {
+		return wrapDelegationError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func wrapDelegationError(err error) error {
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	switch {
+	case errors.Is(err, account.ErrDelegationDisabled):
+		return jsonapi.Forbidden(err)
+	case errors.Is(err, account.ErrInvalidRecipient),
+		errors.Is(err, account.ErrDelegatedAccount):
+		return jsonapi.BadRequest(err)
+	}
+	return err
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
 	"github.com/cozy/cozy-stack/web/auth"
 	"github.com/cozy/cozy-stack/web/middlewares"
 	"github.com/cozy/cozy-stack/web/oidc"
@@ -115,6 +116,9 @@ func redirect(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusBadRequest,
 				"using ?access_token with instance-less redirect")
 		}
+		if err := middlewares.CheckInstanceNetworkAccess(c, i, netaccess.CategoryAuth); err != nil {
+			return err
+		}
 
 		acc = &account.Account{
 			AccountType: accountTypeID,
@@ -137,6 +141,9 @@ func redirect(c echo.Context) error {
 				return errors.New("bad state")
 			}
 		}
+		if err := middlewares.CheckInstanceNetworkAccess(c, i, netaccess.CategoryAuth); err != nil {
+			return err
+		}
 
 		clientState = state.ClientState
 		slug = state.Slug
@@ -401,9 +408,16 @@ func checkIDToken(c echo.Context) bool {
 // Careful, the normal middlewares NeedInstance and LoadSession are not applied
 // to this group in web/routing
 func Routes(router *echo.Group) {
-	router.GET("/:accountType/start", start, middlewares.NeedInstance, middlewares.LoadSession, checkLogin)
+	checkAPI := middlewares.CheckNetworkAccess(netaccess.CategoryDefault)
+	checkAuth := middlewares.CheckNetworkAccess(netaccess.CategoryAuth)
+	router.GET("/delegations", listDelegations, middlewares.NeedInstance, checkAPI)
+	router.POST("/delegations", createDelegation, middlewares.NeedInstance, checkAPI)
+	router.DELETE("/delegations/:delegation-id", revokeDelegation, middlewares.NeedInstance, checkAPI)
+	router.POST("/backup", exportBackup, middlewares.NeedInstance, checkAPI)
+	router.POST("/restore", restoreBackup, middlewares.NeedInstance, checkAPI)
+	router.GET("/:accountType/start", start, middlewares.NeedInstance, checkAuth, middlewares.LoadSession, checkLogin)
 	router.GET("/:accountType/redirect", redirect)
-	router.GET("/:accountType/:accountid/manage", manage, middlewares.NeedInstance, middlewares.LoadSession, checkLogin)
-	router.POST("/:accountType/:accountid/refresh", refresh, middlewares.NeedInstance)
-	router.GET("/:accountType/:accountid/reconnect", reconnect, middlewares.NeedInstance, middlewares.LoadSession, checkLogin)
+	router.GET("/:accountType/:accountid/manage", manage, middlewares.NeedInstance, checkAuth, middlewares.LoadSession, checkLogin)
+	router.POST("/:accountType/:accountid/refresh", refresh, middlewares.NeedInstance, checkAPI)
+	router.GET("/:accountType/:accountid/reconnect", reconnect, middlewares.NeedInstance, checkAuth, middlewares.LoadSession, checkLogin)
 }
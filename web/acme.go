@@ -0,0 +1,88 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/pkg/autotls"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+)
+
+// defaultPropagationDelay is the time to wait for the DNS records of the
+// DNS-01 challenges to be visible by the ACME server.
+const defaultPropagationDelay = time.Minute
+
+// newACMEManager returns the manager for the TLS certificates obtained via
+// ACME, with the parameters from the config.
+func newACMEManager() (*autotls.Manager, error) {
+	cfg := config.GetConfig().ACME
+	opts := autotls.Options{
+		Email:        cfg.Email,
+		DirectoryURL: cfg.DirectoryURL,
+		Cache:        autotls.CouchCache{},
+		HostPolicy:   acmeHostPolicy,
+		CertNames:    acmeCertNames,
+	}
+	if name, _ := cfg.DNS["provider"].(string); name != "" {
+		provider, err := autotls.NewDNSProvider(name, cfg.DNS)
+		if err != nil {
+			return nil, err
+		}
+		opts.DNSProvider = provider
+		opts.PropagationDelay = defaultPropagationDelay
+		if delay, ok := cfg.DNS["propagation_delay"].(string); ok {
+			if d, err := time.ParseDuration(delay); err == nil {
+				opts.PropagationDelay = d
+			}
+		}
+	}
+	return autotls.NewManager(opts), nil
+}
+
+// acmeHostPolicy allows the certificates for the instances, their apps, and
+// the domains listed in the config.
+func acmeHostPolicy(_ context.Context, host string) error {
+	for _, domain := range config.GetConfig().ACME.Domains {
+		if strings.EqualFold(domain, host) {
+			return nil
+		}
+	}
+	if _, err := lifecycle.GetInstance(host); err == nil {
+		return nil
+	}
+	if parent, slug, _ := config.SplitCozyHost(host); slug != "" {
+		if _, err := lifecycle.GetInstance(parent); err == nil {
+			return nil
+		}
+	}
+	return autotls.ErrHostNotAllowed
+}
+
+// acmeCertNames returns the names of the wildcard certificate for a host
+// when the DNS-01 challenge is used. With nested subdomains, a certificate
+// is made for each instance with its apps (example.com and *.example.com).
+// With flat subdomains, a single certificate is used for all the instances
+// and their apps of a domain (*.mycozy.cloud).
+func acmeCertNames(host string) []string {
+	for _, domain := range config.GetConfig().ACME.Domains {
+		if strings.EqualFold(domain, host) {
+			return []string{host}
+		}
+	}
+	instanceHost := host
+	if _, err := lifecycle.GetInstance(host); err != nil {
+		if parent, slug, _ := config.SplitCozyHost(host); slug != "" {
+			instanceHost = parent
+		}
+	}
+	if config.GetConfig().Subdomains == config.NestedSubdomains {
+		return []string{instanceHost, "*." + instanceHost}
+	}
+	parts := strings.SplitN(instanceHost, ".", 2)
+	if len(parts) < 2 {
+		return []string{host}
+	}
+	return []string{"*." + parts[1]}
+}
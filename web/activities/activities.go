@@ -0,0 +1,81 @@
+// Package activities is for the timeline of the notable events of an
+// instance, like the files added by the konnectors or the sharings accepted.
+package activities
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/activity"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultLimit = 50
+
+type apiActivity struct {
+	*activity.Activity
+}
+
+func (a *apiActivity) Relationships() jsonapi.RelationshipMap { return nil }
+func (a *apiActivity) Included() []jsonapi.Object             { return nil }
+func (a *apiActivity) Links() *jsonapi.LinksList              { return nil }
+func (a *apiActivity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Activity)
+}
+
+func listActivities(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Activities); err != nil {
+		return err
+	}
+
+	var doctypes []string
+	if filter := c.QueryParam("filter[doctype]"); filter != "" {
+		for _, doctype := range strings.Split(filter, ",") {
+			if doctype = strings.TrimSpace(doctype); doctype != "" {
+				doctypes = append(doctypes, doctype)
+			}
+		}
+	}
+	bookmark := c.QueryParam("page[cursor]")
+	limit, err := strconv.ParseInt(c.QueryParam("page[limit]"), 10, 64)
+	if err != nil || limit <= 0 || limit > consts.MaxItemsPerPageForMango {
+		limit = defaultLimit
+	}
+
+	list, bookmark, err := activity.List(inst, doctypes, int(limit), bookmark)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(list))
+	for i, a := range list {
+		objs[i] = &apiActivity{a}
+	}
+
+	links := &jsonapi.LinksList{}
+	if bookmark != "" && len(objs) == int(limit) {
+		v := url.Values{}
+		v.Set("page[cursor]", bookmark)
+		if limit != defaultLimit {
+			v.Set("page[limit]", fmt.Sprintf("%d", limit))
+		}
+		if len(doctypes) > 0 {
+			v.Set("filter[doctype]", strings.Join(doctypes, ","))
+		}
+		links.Next = "/activities?" + v.Encode()
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, links)
+}
+
+// Routes sets the routing for the timeline of the activities.
+func Routes(router *echo.Group) {
+	router.GET("", listActivities)
+}
@@ -347,6 +347,19 @@ func deleteHandler(installerType consts.AppType) echo.HandlerFunc {
 			return err
 		}
 
+		disposition := c.QueryParam("data")
+		if disposition == "" {
+			disposition = app.DataKeep
+		}
+		if err := app.CheckDataDisposition(disposition); err != nil {
+			return jsonapi.InvalidParameter("data", err)
+		}
+		if disposition != app.DataKeep {
+			if err := instance.CheckLegalHold(); err != nil {
+				return jsonapi.Forbidden(err)
+			}
+		}
+
 		// Check if there is a mobile client attached to this app
 		if installerType == consts.WebappType {
 			oauthClient, err := oauth.FindClientBySoftwareID(instance, "registry://"+slug)
@@ -366,11 +379,23 @@ func deleteHandler(installerType consts.AppType) echo.HandlerFunc {
 				if err != nil {
 					return wrapAppsError(err)
 				}
-				deleteKonnectorWithAccounts(instance, man, toDelete)
+				dataMsg := newDataMessage(man, disposition)
+				deleteKonnectorWithAccounts(instance, man, toDelete, dataMsg)
 				return jsonapi.Data(c, http.StatusAccepted, &apiApp{man}, nil)
 			}
 		}
 
+		// The doctypes of the application must be known before the manifest
+		// is deleted
+		var dataMsg *app.DataMessage
+		if disposition != app.DataKeep {
+			man, err := app.GetBySlug(instance, slug, installerType)
+			if err != nil {
+				return wrapAppsError(err)
+			}
+			dataMsg = newDataMessage(man, disposition)
+		}
+
 		inst, err := app.NewInstaller(instance, app.Copier(installerType, instance),
 			&app.InstallerOptions{
 				Operation:  app.Delete,
@@ -386,10 +411,76 @@ func deleteHandler(installerType consts.AppType) echo.HandlerFunc {
 		if err != nil {
 			return wrapAppsError(err)
 		}
+		if dataMsg != nil {
+			if _, err := app.PushDataJob(instance, dataMsg); err != nil {
+				return err
+			}
+			return jsonapi.Data(c, http.StatusAccepted, &apiApp{man}, nil)
+		}
 		return jsonapi.Data(c, http.StatusOK, &apiApp{man}, nil)
 	}
 }
 
+func newDataMessage(man app.Manifest, disposition string) *app.DataMessage {
+	return &app.DataMessage{
+		Slug:        man.Slug(),
+		Type:        man.DocType(),
+		Doctypes:    app.DataDoctypes(man),
+		Disposition: disposition,
+	}
+}
+
+// apiAppData is the list of the documents created by an application, with
+// the choices for them on uninstall.
+type apiAppData struct {
+	man    app.Manifest
+	counts []app.DataCount
+}
+
+func (d *apiAppData) ID() string                             { return d.man.ID() }
+func (d *apiAppData) Rev() string                            { return "" }
+func (d *apiAppData) DocType() string                        { return consts.AppsData }
+func (d *apiAppData) SetID(id string)                        {}
+func (d *apiAppData) SetRev(rev string)                      {}
+func (d *apiAppData) Clone() couchdb.Doc                     { return d }
+func (d *apiAppData) Relationships() jsonapi.RelationshipMap { return nil }
+func (d *apiAppData) Included() []jsonapi.Object             { return nil }
+func (d *apiAppData) Links() *jsonapi.LinksList              { return nil }
+func (d *apiAppData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Slug         string          `json:"slug"`
+		Doctypes     []app.DataCount `json:"doctypes"`
+		Dispositions []string        `json:"dispositions"`
+	}{
+		Slug:         d.man.Slug(),
+		Doctypes:     d.counts,
+		Dispositions: []string{app.DataKeep, app.DataExport, app.DataDelete},
+	})
+}
+
+// dataHandler is the first phase of the uninstall: it lists the documents
+// created by the application, so that the user can choose what to do with
+// them before the DELETE request.
+func dataHandler(appType consts.AppType) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		instance := middlewares.GetInstance(c)
+		slug := c.Param("slug")
+		source := "registry://" + slug
+		if err := middlewares.AllowInstallApp(c, appType, source, permission.DELETE); err != nil {
+			return err
+		}
+		man, err := app.GetBySlug(instance, slug, appType)
+		if err != nil {
+			return wrapAppsError(err)
+		}
+		counts, err := app.CountData(instance, slug, app.DataDoctypes(man))
+		if err != nil {
+			return err
+		}
+		return jsonapi.Data(c, http.StatusOK, &apiAppData{man: man, counts: counts}, nil)
+	}
+}
+
 func findAccountsToDelete(instance *instance.Instance, slug string) ([]account.CleanEntry, error) {
 	jobsSystem := job.System()
 	triggers, err := jobsSystem.GetAllTriggers(instance)
@@ -433,7 +524,7 @@ func findAccountsToDelete(instance *instance.Instance, slug string) ([]account.C
 	return toDelete, nil
 }
 
-func deleteKonnectorWithAccounts(instance *instance.Instance, man *app.KonnManifest, toDelete []account.CleanEntry) {
+func deleteKonnectorWithAccounts(instance *instance.Instance, man *app.KonnManifest, toDelete []account.CleanEntry, dataMsg *app.DataMessage) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -477,6 +568,10 @@ func deleteKonnectorWithAccounts(instance *instance.Instance, man *app.KonnManif
 		_, err = inst.RunSync()
 		if err != nil {
 			log.Errorf("Cannot uninstall the konnector: %v", err)
+			return
+		}
+		if _, err := app.PushDataJob(instance, dataMsg); err != nil {
+			log.Errorf("Cannot push the job for the data of the konnector: %v", err)
 		}
 	}()
 }
@@ -546,6 +641,9 @@ func listWebappsHandler(c echo.Context) error {
 	if err := middlewares.AllowWholeType(c, permission.GET, consts.Apps); err != nil {
 		return err
 	}
+	if middlewares.CheckListingETag(c, consts.Apps) {
+		return nil
+	}
 
 	// Adding the startKey if it is given in the request
 	startKey := c.QueryParam("start_key")
@@ -581,6 +679,9 @@ func listKonnectorsHandler(c echo.Context) error {
 	if err := middlewares.AllowWholeType(c, permission.GET, consts.Konnectors); err != nil {
 		return err
 	}
+	if middlewares.CheckListingETag(c, consts.Konnectors) {
+		return nil
+	}
 
 	// Adding the startKey if it is given in the request
 	var startKey string
@@ -728,7 +829,9 @@ func (o *apiOpenParams) SetRev(rev string)  {}
 func (o *apiOpenParams) Clone() couchdb.Doc { return o }
 func (o *apiOpenParams) MarshalJSON() ([]byte, error) {
 	data := map[string]interface{}{}
-	data["Cookie"] = o.cookie
+	if o.cookie != "" {
+		data["Cookie"] = o.cookie
+	}
 	data["Token"] = o.params.Token
 	data["Domain"] = o.params.Domain()
 	data["SubDomain"] = o.params.SubDomain
@@ -771,6 +874,19 @@ func openWebapp(c echo.Context) error {
 		return wrapAppsError(err)
 	}
 
+	// With a webview token, no session is created: the webview has only a
+	// short-lived token for this app.
+	if c.QueryParam("WebviewToken") == "true" {
+		token, err := middlewares.BuildWebviewToken(c, webapp, c.QueryParam("Scope"))
+		if err != nil {
+			return err
+		}
+		params := buildServeParams(c, inst, webapp, true, "")
+		params.Token = token
+		obj := &apiOpenParams{slug: slug, params: params}
+		return jsonapi.Data(c, http.StatusOK, obj, nil)
+	}
+
 	var cookie *http.Cookie
 	sess, err := session.FromCookie(c, inst)
 	if err == nil {
@@ -812,11 +928,14 @@ func WebappsRoutes(router *echo.Group) {
 	router.POST("/:slug", installHandler(consts.WebappType))
 	router.PUT("/:slug", updateHandler(consts.WebappType))
 	router.DELETE("/:slug", deleteHandler(consts.WebappType))
+	router.GET("/:slug/data", dataHandler(consts.WebappType))
 	router.GET("/:slug/icon", iconHandler(consts.WebappType))
 	router.GET("/:slug/icon/:version", iconHandler(consts.WebappType))
 	router.GET("/:slug/open", openWebapp)
 	router.GET("/:slug/download", downloadHandler(consts.WebappType))
 	router.GET("/:slug/download/:version", downloadHandler(consts.WebappType))
+	router.GET("/:slug/precache", precacheHandler)
+	router.GET("/:slug/precache/:version", precacheHandler)
 	router.POST("/:slug/logs", logsHandler(consts.WebappType))
 }
 
@@ -827,9 +946,11 @@ func KonnectorRoutes(router *echo.Group) {
 	router.POST("/:slug", installHandler(consts.KonnectorType))
 	router.PUT("/:slug", updateHandler(consts.KonnectorType))
 	router.DELETE("/:slug", deleteHandler(consts.KonnectorType))
+	router.GET("/:slug/data", dataHandler(consts.KonnectorType))
 	router.GET("/:slug/icon", iconHandler(consts.KonnectorType))
 	router.GET("/:slug/icon/:version", iconHandler(consts.KonnectorType))
 	router.POST("/:slug/trigger", createTrigger)
+	router.GET("/:slug/stats", statsHandler)
 	router.GET("/:slug/download", downloadHandler(consts.KonnectorType))
 	router.GET("/:slug/download/:version", downloadHandler(consts.KonnectorType))
 	router.POST("/:slug/logs", logsHandler(consts.KonnectorType))
@@ -0,0 +1,101 @@
+package apps
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/appfs"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// precacheManifest is the list of the assets of a webapp that the flagship
+// app can download (on Wi-Fi) to serve them locally.
+type precacheManifest struct {
+	Slug      string        `json:"slug"`
+	Version   string        `json:"version"`
+	Checksum  string        `json:"checksum,omitempty"`
+	TotalSize int64         `json:"total_size"`
+	Assets    []appfs.Asset `json:"assets"`
+	Signature string        `json:"signature,omitempty"`
+}
+
+// precacheHandler returns the signed manifest of the assets of a webapp. The
+// ETag depends on the version and checksum of the app, so that the client
+// can invalidate its cache when the app is updated.
+func precacheHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	slug := c.Param("slug")
+	man, err := app.GetWebappBySlug(inst, slug)
+	if err != nil {
+		return wrapAppsError(err)
+	}
+	if err := middlewares.Allow(c, permission.GET, man); err != nil {
+		return err
+	}
+
+	version := c.Param("version")
+	if version == "" {
+		version = man.Version()
+	}
+	if version != man.Version() {
+		err := errors.New("code for this version is not available")
+		return jsonapi.PreconditionFailed("version", err)
+	}
+
+	var etag string
+	if man.Checksum() != "" {
+		etag = `"` + man.Version() + "-" + man.Checksum() + `"`
+		c.Response().Header().Set("Etag", etag)
+		if utils.CheckPreconditions(c.Response(), c.Request(), etag) {
+			return nil
+		}
+	}
+
+	var fs appfs.FileServer
+	if man.FromAppsDir {
+		fs = app.FSForAppDir(slug)
+	} else {
+		fs = app.AppsFileServer(inst)
+	}
+	assets, err := appfs.AssetsList(fs, slug, man.Version(), man.Checksum())
+	if err != nil {
+		return wrapAppsError(err)
+	}
+
+	manifest := &precacheManifest{
+		Slug:     slug,
+		Version:  man.Version(),
+		Checksum: man.Checksum(),
+		Assets:   assets,
+	}
+	for _, asset := range assets {
+		manifest.TotalSize += asset.Size
+	}
+	if manifest.Signature, err = signPrecacheManifest(inst, manifest); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, manifest)
+}
+
+// signPrecacheManifest computes a HMAC-SHA256 of the manifest (without the
+// signature), with a key from the OAuth secret of the instance.
+func signPrecacheManifest(inst *instance.Instance, manifest *precacheManifest) (string, error) {
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, inst.OAuthSecret)
+	mac.Write([]byte("precache:"))
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
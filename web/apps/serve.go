@@ -29,6 +29,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/registry"
 	"github.com/cozy/cozy-stack/web/auth"
 	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/cozy/cozy-stack/web/public"
 	"github.com/cozy/cozy-stack/web/settings"
 	"github.com/cozy/cozy-stack/web/statik"
 	"github.com/labstack/echo/v4"
@@ -280,10 +281,10 @@ func ServeAppFile(c echo.Context, i *instance.Instance, fs appfs.FileServer, web
 		return err
 	}
 
-	// XXX: Force include Warnings template in all app indexes
+	// XXX: Force include Warnings and OpenGraph templates in all app indexes
 	tmplText := string(buf)
 	if closeTagIdx := strings.Index(tmplText, "</head>"); closeTagIdx >= 0 {
-		tmplText = tmplText[:closeTagIdx] + "\n{{.Warnings}}\n" + tmplText[closeTagIdx:]
+		tmplText = tmplText[:closeTagIdx] + "\n{{.Warnings}}\n{{.OpenGraph}}\n" + tmplText[closeTagIdx:]
 	} else {
 		needsOpenTag := true
 		if openTagIdx := strings.Index(tmplText, "<head>"); openTagIdx >= 0 {
@@ -300,7 +301,7 @@ func ServeAppFile(c echo.Context, i *instance.Instance, fs appfs.FileServer, web
 				tmplText += "\n<head>"
 			}
 
-			tmplText += "\n{{.Warnings}}\n</head>\n" + after
+			tmplText += "\n{{.Warnings}}\n{{.OpenGraph}}\n</head>\n" + after
 		}
 	}
 
@@ -369,6 +370,8 @@ func buildServeParams(
 		webapp:     webapp,
 		instance:   inst,
 		isLoggedIn: isLoggedIn,
+		sharecode:  c.QueryParam("sharecode"),
+		pageURL:    c.Request().URL,
 	}
 }
 
@@ -477,6 +480,8 @@ type serveParams struct {
 	webapp     *app.WebappManifest
 	instance   *instance.Instance
 	isLoggedIn bool
+	sharecode  string
+	pageURL    *url.URL
 }
 
 func (s serveParams) CozyData() (string, error) {
@@ -604,9 +609,42 @@ func (s serveParams) Warnings() (template.HTML, error) {
 	return warningsHTML(s.instance, s.isLoggedIn)
 }
 
+// OpenGraph returns the OpenGraph meta tags and the oEmbed discovery link
+// for the share by link pages, so that the chat and social apps can show a
+// preview of the shared file.
+func (s serveParams) OpenGraph() (template.HTML, error) {
+	if s.isLoggedIn || s.sharecode == "" {
+		return "", nil
+	}
+	preview := public.GetSharePreview(s.instance, s.sharecode)
+	if preview == nil {
+		return "", nil
+	}
+	link := s.instance.SubDomain(s.webapp.Slug())
+	link.Path = s.pageURL.Path
+	link.RawQuery = url.Values{"sharecode": {s.sharecode}}.Encode()
+	buf := new(bytes.Buffer)
+	err := openGraphTemplate.Execute(buf, echo.Map{
+		"SiteName":    s.instance.TemplateTitle(),
+		"Title":       preview.Title,
+		"Description": preview.Description,
+		"URL":         link.String(),
+		"Image":       preview.ImageURL,
+		"OEmbed": s.instance.PageURL("/public/oembed", url.Values{
+			"url":    {link.String()},
+			"format": {"json"},
+		}),
+	})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
 var clientTemplate *template.Template
 var barTemplate *template.Template
 var warningsTemplate *template.Template
+var openGraphTemplate *template.Template
 
 // BuildTemplates ensure that cozy-client-js and the bar can be injected in templates
 func BuildTemplates() {
@@ -627,6 +665,18 @@ func BuildTemplates() {
 {{end}}
 {{end}}`,
 	))
+
+	openGraphTemplate = template.Must(template.New("open-graph").Parse(`
+<meta property="og:type" content="website" />
+<meta property="og:site_name" content="{{.SiteName}}" />
+<meta property="og:title" content="{{.Title}}" />
+<meta property="og:description" content="{{.Description}}" />
+<meta property="og:url" content="{{.URL}}" />
+{{if .Image}}<meta property="og:image" content="{{.Image}}" />
+<meta name="twitter:card" content="summary_large_image" />
+{{else}}<meta name="twitter:card" content="summary" />
+{{end}}<link rel="alternate" type="application/json+oembed" href="{{.OEmbed}}" title="{{.Title}}" />`,
+	))
 }
 
 func cozyclientjsHTML(i *instance.Instance) (template.HTML, error) {
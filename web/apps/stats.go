@@ -0,0 +1,47 @@
+package apps
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/konnectorstats"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// apiKonnectorStats is the statistics of a version of a konnector, with its
+// success rate.
+type apiKonnectorStats struct {
+	*konnectorstats.Stats
+	Rate float64 `json:"success_rate"`
+}
+
+func (s *apiKonnectorStats) Relationships() jsonapi.RelationshipMap { return nil }
+func (s *apiKonnectorStats) Included() []jsonapi.Object             { return nil }
+func (s *apiKonnectorStats) Links() *jsonapi.LinksList              { return nil }
+
+// statsHandler returns the number of successes and failures of each version
+// of a konnector on this instance.
+func statsHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	slug := c.Param("slug")
+	man, err := app.GetKonnectorBySlug(inst, slug)
+	if err != nil {
+		return wrapAppsError(err)
+	}
+	if err := middlewares.Allow(c, permission.GET, man); err != nil {
+		return err
+	}
+
+	all, err := konnectorstats.List(inst, slug)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(all))
+	for i, stats := range all {
+		objs[i] = &apiKonnectorStats{Stats: stats, Rate: stats.SuccessRate()}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/report"
+	"github.com/cozy/cozy-stack/model/risk"
 	"github.com/cozy/cozy-stack/model/session"
 	csettings "github.com/cozy/cozy-stack/model/settings"
 	build "github.com/cozy/cozy-stack/pkg/config"
@@ -32,6 +34,9 @@ const (
 	// TwoFactorExceededErrorKey is the key for translating the message showed to the
 	// user when there were too many attempts
 	TwoFactorExceededErrorKey = "Login Two factor attempts error"
+	// RiskBlockedErrorKey is the key for translating the message showed to the
+	// user when the risk hook has blocked the login
+	RiskBlockedErrorKey = "Login Risk blocked error"
 )
 
 func wantsJSON(c echo.Context) bool {
@@ -107,8 +112,12 @@ func Home(c echo.Context) error {
 
 // SetCookieForNewSession creates a new session and sets the cookie on echo context
 func SetCookieForNewSession(c echo.Context, duration session.Duration) (string, error) {
+	return setCookieForNewSession(c, duration, "")
+}
+
+func setCookieForNewSession(c echo.Context, duration session.Duration, userID string) (string, error) {
 	instance := middlewares.GetInstance(c)
-	session, err := session.New(instance, duration)
+	session, err := session.NewForUser(instance, duration, userID)
 	if err != nil {
 		return "", err
 	}
@@ -224,6 +233,7 @@ func renderLoginForm(c echo.Context, i *instance.Instance, code int, credsErrors
 		"MagicLink":         i.MagicLink,
 		"OAuth":             hasOAuth,
 		"FranceConnect":     hasFranceConnect,
+		"LoginUI":           csettings.GetLoginUI(i),
 	})
 }
 
@@ -265,6 +275,13 @@ func loginForm(c echo.Context) error {
 
 // newSession generates a new session, and puts a cookie for it
 func newSession(c echo.Context, inst *instance.Instance, redirect *url.URL, duration session.Duration, logMessage string) error {
+	return newUserSession(c, inst, redirect, duration, logMessage, "")
+}
+
+// newUserSession generates a new session for a user of an instance in
+// organization mode (or for the owner if userID is empty), and puts a cookie
+// for it
+func newUserSession(c echo.Context, inst *instance.Instance, redirect *url.URL, duration session.Duration, logMessage, userID string) error {
 	var clientID string
 	if hasRedirectToAuthorize(inst, redirect) {
 		// NOTE: the login scope is used by external clients for authentication.
@@ -276,14 +293,22 @@ func newSession(c echo.Context, inst *instance.Instance, redirect *url.URL, dura
 		duration = session.ShortRun
 	}
 
-	sessionID, err := SetCookieForNewSession(c, duration)
+	sessionID, err := setCookieForNewSession(c, duration, userID)
 	if err != nil {
 		return err
 	}
 
-	if err = session.StoreNewLoginEntry(inst, sessionID, clientID, c.Request(), logMessage, true); err != nil {
+	if userID != "" {
+		err = session.StoreNewUserLoginEntry(inst, sessionID, userID, clientID, c.Request(), logMessage)
+	} else {
+		err = session.StoreNewLoginEntry(inst, sessionID, clientID, c.Request(), logMessage, true)
+	}
+	if err != nil {
 		inst.Logger().Errorf("Could not store session history %q: %s", sessionID, err)
 	}
+	if report.MonthlyEnabled(inst) {
+		report.EnsureMonthlyTrigger(inst)
+	}
 
 	return nil
 }
@@ -291,7 +316,7 @@ func newSession(c echo.Context, inst *instance.Instance, redirect *url.URL, dura
 func migrateToHashedPassphrase(inst *instance.Instance, settings *settings.Settings, passphrase []byte, iterations int) {
 	salt := inst.PassphraseSalt()
 	pass, masterKey := crypto.HashPassWithPBKDF2(passphrase, salt, iterations)
-	hash, err := crypto.GenerateFromPassphrase(pass)
+	hash, err := crypto.GenerateFromPassphraseWithParams(pass, inst.PassphraseHashParams())
 	if err != nil {
 		inst.Logger().Errorf("Could not hash the passphrase: %s", err.Error())
 		return
@@ -336,6 +361,8 @@ func login(c echo.Context) error {
 	sess, ok := middlewares.GetSession(c)
 	if ok { // The user was already logged-in
 		sessionID = sess.ID()
+	} else if email := c.FormValue("email"); email != "" && inst.Organization {
+		return loginOrganizationUser(c, inst, email, passphrase, longRunSession, redirect)
 	} else if instance.CheckPassphrase(inst, passphrase) == nil {
 		ua := user_agent.New(c.Request().UserAgent())
 		browser, _ := ua.Browser()
@@ -349,12 +376,26 @@ func login(c echo.Context) error {
 			migrateToHashedPassphrase(inst, settings, passphrase, iterations)
 		}
 
+		// The risk hook of the context can block the login, or ask for a
+		// verification by mail, like for the 2FA.
+		decision := risk.Evaluate(inst, newRiskRequest(c, risk.EventLogin, nil))
+		if decision == risk.Block {
+			errorMessage := inst.Translate(RiskBlockedErrorKey)
+			if wantsJSON(c) {
+				return c.JSON(http.StatusForbidden, echo.Map{
+					"error": errorMessage,
+				})
+			}
+			return renderLoginForm(c, inst, http.StatusForbidden, errorMessage, redirect)
+		}
+
 		// In case the second factor authentication mode is "mail", we also
 		// check that the mail has been confirmed. If not, 2FA is not
 		// activated.
 		// If device is trusted, skip the 2FA.
 		// If the email has already been verified, skip the 2FA too.
-		if inst.HasAuthMode(instance.TwoFactorMail) && !isTrustedDevice(c, inst) && !hasEmailVerified(c, inst) {
+		needs2FA := inst.HasAuthMode(instance.TwoFactorMail) && !isTrustedDevice(c, inst) && !hasEmailVerified(c, inst)
+		if needs2FA || decision == risk.Verify {
 			twoFactorToken, err := lifecycle.SendTwoFactorPasscode(inst)
 			if err != nil {
 				return err
@@ -374,19 +415,7 @@ func login(c echo.Context) error {
 			return c.Redirect(http.StatusSeeOther, inst.PageURL("/auth/twofactor", v))
 		}
 	} else { // Bad login passphrase
-		errorMessage := inst.Translate(CredentialsErrorKey)
-		err := config.GetRateLimiter().CheckRateLimit(inst, limits.AuthType)
-		if limits.IsLimitReachedOrExceeded(err) {
-			if err = LoginRateExceeded(inst); err != nil {
-				inst.Logger().WithNamespace("auth").Warn(err.Error())
-			}
-		}
-		if wantsJSON(c) {
-			return c.JSON(http.StatusUnauthorized, echo.Map{
-				"error": errorMessage,
-			})
-		}
-		return renderLoginForm(c, inst, http.StatusUnauthorized, errorMessage, redirect)
+		return badCredentials(c, inst, redirect)
 	}
 
 	// Successful authentication
@@ -410,6 +439,38 @@ func login(c echo.Context) error {
 	return c.Redirect(http.StatusSeeOther, redirect.String())
 }
 
+// badCredentials is the response for a login with a bad passphrase. The
+// failed attempts are counted for the rate limit of the instance.
+func badCredentials(c echo.Context, inst *instance.Instance, redirect *url.URL) error {
+	errorMessage := inst.Translate(CredentialsErrorKey)
+	err := config.GetRateLimiter().CheckRateLimit(inst, limits.AuthType)
+	if limits.IsLimitReachedOrExceeded(err) {
+		if err = LoginRateExceeded(inst); err != nil {
+			inst.Logger().WithNamespace("auth").Warn(err.Error())
+		}
+	}
+	if wantsJSON(c) {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": errorMessage,
+		})
+	}
+	return renderLoginForm(c, inst, http.StatusUnauthorized, errorMessage, redirect)
+}
+
+// newRiskRequest returns the request for the risk hook, with the IP address
+// and user-agent of the client.
+func newRiskRequest(c echo.Context, event risk.Event, details map[string]interface{}) *risk.Request {
+	req := &risk.Request{
+		Event:     event,
+		UserAgent: c.Request().UserAgent(),
+		Details:   details,
+	}
+	if ip := middlewares.ClientIP(c); ip != nil {
+		req.IP = ip.String()
+	}
+	return req
+}
+
 // addLogoutCookie adds a cookie for logged-out users on instances in a context
 // where OIDC is configured. It allows to redirects the user on the next request
 // to a special page instead of sending them to the OIDC page (which can logs
@@ -575,18 +636,9 @@ func AppRedirection(inst *instance.Instance, redirect string) (*url.URL, error)
 
 // Routes sets the routing for the status service
 func Routes(router *echo.Group) {
-	noCSRF := middlewares.CSRFWithConfig(middlewares.CSRFConfig{
-		TokenLookup:    "form:csrf_token",
-		CookieMaxAge:   3600, // 1 hour
-		CookieHTTPOnly: true,
-		CookieSecure:   !build.IsDevRelease(),
-		CookieSameSite: http.SameSiteStrictMode,
-		CookiePath:     "/auth",
-	})
-
 	// Login/logout
-	router.GET("/login", loginForm, noCSRF, middlewares.CheckOnboardingNotFinished)
-	router.POST("/login", login, noCSRF, middlewares.CheckOnboardingNotFinished)
+	router.GET("/login", loginForm, middlewares.CheckCSRF, middlewares.CheckOnboardingNotFinished)
+	router.POST("/login", login, middlewares.CheckCSRF, middlewares.CheckOnboardingNotFinished)
 	router.POST("/login/flagship", loginFlagship, middlewares.CheckOnboardingNotFinished)
 	router.DELETE("/login/others", logoutOthers)
 	router.OPTIONS("/login/others", logoutPreflight)
@@ -594,47 +646,60 @@ func Routes(router *echo.Group) {
 	router.OPTIONS("/login", logoutPreflight)
 
 	// Magic links
-	router.POST("/magic_link", sendMagicLink, noCSRF)
-	router.GET("/magic_link", loginWithMagicLink, noCSRF)
-	router.POST("/magic_link/twofactor", loginWithMagicLinkAndPassword, noCSRF)
+	router.POST("/magic_link", sendMagicLink, middlewares.CheckCSRF)
+	router.GET("/magic_link", loginWithMagicLink, middlewares.CheckCSRF)
+	router.POST("/magic_link/twofactor", loginWithMagicLinkAndPassword, middlewares.CheckCSRF)
 	router.POST("/magic_link/flagship", magicLinkFlagship)
 
 	// Passphrase
-	router.GET("/passphrase_reset", passphraseResetForm, noCSRF)
-	router.POST("/passphrase_reset", passphraseReset, noCSRF)
-	router.GET("/passphrase_renew", passphraseRenewForm, noCSRF)
-	router.POST("/passphrase_renew", passphraseRenew, noCSRF)
-	router.GET("/passphrase", passphraseForm, noCSRF)
-	router.POST("/hint", sendHint)
+	router.GET("/passphrase_reset", passphraseResetForm, middlewares.CheckCSRF)
+	router.POST("/passphrase_reset", passphraseReset, middlewares.CheckCSRF)
+	router.GET("/passphrase_renew", passphraseRenewForm, middlewares.CheckCSRF)
+	router.POST("/passphrase_renew", passphraseRenew, middlewares.CheckCSRF)
+	router.GET("/passphrase", passphraseForm, middlewares.CheckCSRF)
+	router.POST("/hint", sendHint, middlewares.CheckCSRF)
 
 	// Confirmation by typing
-	router.GET("/confirm", confirmForm, noCSRF)
-	router.POST("/confirm", confirmAuth, noCSRF)
+	router.GET("/confirm", confirmForm, middlewares.CheckCSRF)
+	router.POST("/confirm", confirmAuth, middlewares.CheckCSRF)
 	router.GET("/confirm/:code", confirmCode)
 
 	// Register OAuth clients
 	router.POST("/register", registerClient, middlewares.AcceptJSON, middlewares.ContentTypeJSON)
 	router.GET("/register/:client-id", readClient, middlewares.AcceptJSON, checkRegistrationToken)
+	router.GET("/register/:client-id/quota", readClientQuota, middlewares.AcceptJSON, checkRegistrationToken)
 	router.PUT("/register/:client-id", updateClient, middlewares.AcceptJSON, middlewares.ContentTypeJSON)
+	router.PATCH("/register/:client-id", narrowClientScope, middlewares.AcceptJSON, middlewares.ContentTypeJSON)
 	router.DELETE("/register/:client-id", deleteClient)
+	router.POST("/register/:client-id/rotate", rotateClientSecret, middlewares.AcceptJSON)
 	router.POST("/clients/:client-id/challenge", postChallenge, checkRegistrationToken)
 	router.POST("/clients/:client-id/attestation", postAttestation)
-	router.POST("/clients/:client-id/flagship", confirmFlagship)
+	router.POST("/clients/:client-id/flagship", confirmFlagship, middlewares.CheckCSRF)
 
 	// OAuth flow
 	authHandler := NewAuthorizeHandler(config.GetConfig().DeprecatedApps)
-	authHandler.Register(router.Group("/authorize", noCSRF))
+	authHandler.Register(router.Group("/authorize", middlewares.CheckCSRF))
 
 	router.POST("/access_token", accessToken)
+
+	// OpenID Connect
+	router.GET("/userinfo", userInfo)
+	router.POST("/userinfo", userInfo)
+
+	// Device authorization grant (RFC 8628)
+	router.POST("/device/code", deviceCode)
+	router.GET("/device", deviceForm, middlewares.CheckCSRF)
+	router.POST("/device", deviceAuthorize, middlewares.CheckCSRF)
 	router.POST("/secret_exchange", secretExchange)
 
 	// Flagship app
 	router.POST("/session_code", CreateSessionCode)
 	router.POST("/tokens/konnectors/:slug", buildKonnectorToken)
+	router.POST("/tokens/webviews/:slug", buildWebviewToken)
 
 	// 2FA
-	router.GET("/twofactor", twoFactorForm)
-	router.POST("/twofactor", twoFactor)
+	router.GET("/twofactor", twoFactorForm, middlewares.CheckCSRF)
+	router.POST("/twofactor", twoFactor, middlewares.CheckCSRF)
 
 	// Share by link protected by password
 	router.POST("/share-by-link/password", checkPasswordForShareByLink)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -1352,6 +1353,86 @@ func TestAuth(t *testing.T) {
 		assertValidToken(t, testInstance, obj.Value("access_token").String().Raw(), "access", clientID, "files:read")
 	})
 
+	t.Run("NarrowScopeBadJSON", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.PATCH("/auth/register/"+clientID).
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithHeader("Content-Type", "application/json").
+			WithHeader("Authorization", "Bearer "+registrationToken).
+			WithBytes([]byte(`{"scope":`)).
+			Expect().Status(400).
+			JSON().Object().
+			ValueEqual("error", "invalid_request")
+	})
+
+	t.Run("NarrowScopeExpansion", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		for _, scope := range []string{"files", "files:read contacts:read", "files:read openid"} {
+			e.PATCH("/auth/register/"+clientID).
+				WithHost(domain).
+				WithHeader("Accept", "application/json").
+				WithHeader("Authorization", "Bearer "+registrationToken).
+				WithJSON(map[string]interface{}{
+					"scope":         scope,
+					"refresh_token": refreshToken,
+				}).
+				Expect().Status(403).
+				JSON().Object().
+				ValueEqual("error", "consent_required")
+		}
+	})
+
+	t.Run("NarrowScopeSuccess", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		oldAccess := e.POST("/auth/access_token").
+			WithFormField("grant_type", "refresh_token").
+			WithFormField("client_id", clientID).
+			WithFormField("client_secret", clientSecret).
+			WithFormField("refresh_token", refreshToken).
+			WithHost(domain).
+			Expect().Status(200).
+			JSON().Object().
+			Value("access_token").String().NotEmpty().Raw()
+
+		scope := "files:read:io.cozy.files.root-dir"
+		obj := e.PATCH("/auth/register/"+clientID).
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithHeader("Authorization", "Bearer "+registrationToken).
+			WithJSON(map[string]interface{}{
+				"scope":         scope,
+				"refresh_token": refreshToken,
+			}).
+			Expect().Status(200).
+			JSON().Object()
+
+		obj.ValueEqual("scope", scope)
+		assertValidToken(t, testInstance, obj.Value("access_token").String().Raw(), "access", clientID, scope)
+		assertValidToken(t, testInstance, obj.Value("refresh_token").String().Raw(), "refresh", clientID, scope)
+
+		// The tokens issued before the narrowing are revoked
+		e.POST("/auth/access_token").
+			WithFormField("grant_type", "refresh_token").
+			WithFormField("client_id", clientID).
+			WithFormField("client_secret", clientSecret).
+			WithFormField("refresh_token", refreshToken).
+			WithHost(domain).
+			Expect().Status(400).
+			JSON().Object().
+			ValueEqual("error", "invalid refresh token")
+
+		client, err := oauth.FindClient(testInstance, clientID)
+		require.NoError(t, err)
+		_, ok := client.ValidToken(testInstance, consts.AccessTokenAudience, oldAccess)
+		assert.False(t, ok)
+
+		refreshToken = obj.Value("refresh_token").String().NotEmpty().Raw()
+	})
+
 	t.Run("OAuthWithPKCE", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -1436,6 +1517,243 @@ func TestAuth(t *testing.T) {
 		assertValidToken(t, testInstance, obj.Value("refresh_token").String().Raw(), "refresh", clientID, "files:read")
 	})
 
+	t.Run("RotateClientSecret", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		oldGrace := conf.OAuth.SecretRotationGracePeriod
+		conf.OAuth.SecretRotationGracePeriod = time.Hour
+		t.Cleanup(func() { conf.OAuth.SecretRotationGracePeriod = oldGrace })
+
+		obj := e.POST("/auth/register").
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithJSON(map[string]interface{}{
+				"redirect_uris": []string{"https://example.org/oauth/callback"},
+				"client_name":   "cozy-test-rotate",
+				"software_id":   "github.com/cozy/cozy-test-rotate",
+			}).
+			Expect().Status(201).
+			JSON().Object()
+		rotateID := obj.Value("client_id").String().NotEmpty().Raw()
+		oldSecret := obj.Value("client_secret").String().NotEmpty().Raw()
+		rotateToken := obj.Value("registration_access_token").String().NotEmpty().Raw()
+
+		client, err := oauth.FindClient(testInstance, rotateID)
+		require.NoError(t, err)
+		refresh, err := client.CreateJWT(testInstance, consts.RefreshTokenAudience, "files:read")
+		require.NoError(t, err)
+		refreshWith := func(secret string) *httpexpect.Response {
+			return e.POST("/auth/access_token").
+				WithFormField("grant_type", "refresh_token").
+				WithFormField("client_id", rotateID).
+				WithFormField("client_secret", secret).
+				WithFormField("refresh_token", refresh).
+				WithHost(domain).
+				Expect()
+		}
+
+		e.POST("/auth/register/"+rotateID+"/rotate").
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			Expect().Status(401)
+
+		obj = e.POST("/auth/register/"+rotateID+"/rotate").
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithHeader("Authorization", "Bearer "+rotateToken).
+			Expect().Status(200).
+			JSON().Object()
+		newSecret := obj.Value("client_secret").String().NotEmpty().NotEqual(oldSecret).Raw()
+		obj.ValueEqual("client_id", rotateID)
+		obj.NotContainsKey("previous_client_secret")
+		obj.Value("previous_client_secret_expires_at").Number().Gt(time.Now().Unix())
+
+		// The previous secret is never sent back
+		e.GET("/auth/register/"+rotateID).
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithHeader("Authorization", "Bearer "+rotateToken).
+			Expect().Status(200).
+			Body().NotContains(oldSecret)
+		e.PUT("/auth/register/"+rotateID).
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithHeader("Authorization", "Bearer "+rotateToken).
+			WithJSON(map[string]interface{}{
+				"client_id":        rotateID,
+				"redirect_uris":    []string{"https://example.org/oauth/callback"},
+				"client_name":      "cozy-test-rotate",
+				"software_id":      "github.com/cozy/cozy-test-rotate",
+				"software_version": "v0.2.0",
+			}).
+			Expect().Status(200).
+			Body().NotContains(oldSecret)
+
+		// Both secrets are accepted during the grace period
+		refreshWith(newSecret).Status(200)
+		refreshWith(oldSecret).Status(200)
+
+		// And only the new one after it
+		client, err = oauth.FindClient(testInstance, rotateID)
+		require.NoError(t, err)
+		client.PreviousSecretExpiresAt = time.Now().Add(-time.Minute).Unix()
+		client.ClientID = ""
+		require.NoError(t, couchdb.UpdateDoc(testInstance, client))
+		refreshWith(oldSecret).Status(400).
+			JSON().Object().
+			ValueEqual("error", "invalid client_secret")
+		refreshWith(newSecret).Status(200)
+
+		// Without a grace period, the previous secret is rejected at once
+		conf.OAuth.SecretRotationGracePeriod = 0
+		obj = e.POST("/auth/register/"+rotateID+"/rotate").
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithHeader("Authorization", "Bearer "+rotateToken).
+			Expect().Status(200).
+			JSON().Object()
+		obj.NotContainsKey("previous_client_secret_expires_at")
+		lastSecret := obj.Value("client_secret").String().NotEqual(newSecret).Raw()
+		refreshWith(newSecret).Status(400)
+		refreshWith(lastSecret).Status(200)
+	})
+
+	t.Run("PublicClientPKCE", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		/* Values taken from https://datatracker.ietf.org/doc/html/rfc7636#appendix-B */
+		challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+		verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+		obj := e.POST("/auth/register").
+			WithHost(domain).
+			WithHeader("Accept", "application/json").
+			WithJSON(map[string]interface{}{
+				"redirect_uris":              []string{"https://example.org/oauth/callback"},
+				"client_name":                "cozy-test-public",
+				"software_id":                "github.com/cozy/cozy-test-public",
+				"token_endpoint_auth_method": "none",
+			}).
+			Expect().Status(201).
+			JSON().Object()
+		obj.ValueEqual("token_endpoint_auth_method", "none")
+		publicID := obj.Value("client_id").String().NotEmpty().Raw()
+
+		/* The authorize page and form refuse a request without challenge */
+		e.GET("/auth/authorize").
+			WithQuery("response_type", "code").
+			WithQuery("state", "123456").
+			WithQuery("redirect_uri", "https://example.org/oauth/callback").
+			WithQuery("scope", "files:read").
+			WithQuery("client_id", publicID).
+			WithCookie(session.CookieName(testInstance), sessionID).
+			WithHost(domain).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().Status(400)
+
+		resBody := e.GET("/auth/authorize").
+			WithQuery("response_type", "code").
+			WithQuery("state", "123456").
+			WithQuery("redirect_uri", "https://example.org/oauth/callback").
+			WithQuery("scope", "files:read").
+			WithQuery("client_id", publicID).
+			WithQuery("code_challenge", challenge).
+			WithQuery("code_challenge_method", "S256").
+			WithCookie(session.CookieName(testInstance), sessionID).
+			WithHost(domain).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().Status(200).
+			ContentType("text/html", "utf-8").
+			Body()
+		matches := resBody.Match(`<input type="hidden" name="csrf_token" value="(\w+)"`)
+		matches.Length().Equal(2)
+		csrfToken = matches.Index(1).Raw()
+
+		e.POST("/auth/authorize").
+			WithFormField("state", "123456").
+			WithFormField("client_id", publicID).
+			WithFormField("scope", "files:read").
+			WithFormField("redirect_uri", "https://example.org/oauth/callback").
+			WithFormField("csrf_token", csrfToken).
+			WithFormField("response_type", "code").
+			WithHost(domain).
+			WithCookie("_csrf", csrfToken).
+			WithCookie(session.CookieName(testInstance), sessionID).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().Status(400)
+
+		e.POST("/auth/authorize").
+			WithFormField("state", "123456").
+			WithFormField("client_id", publicID).
+			WithFormField("scope", "files:read").
+			WithFormField("redirect_uri", "https://example.org/oauth/callback").
+			WithFormField("csrf_token", csrfToken).
+			WithFormField("response_type", "code").
+			WithFormField("code_challenge", challenge).
+			WithFormField("code_challenge_method", "S256").
+			WithHost(domain).
+			WithCookie("_csrf", csrfToken).
+			WithCookie(session.CookieName(testInstance), sessionID).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().Status(302)
+
+		var results []oauth.AccessCode
+		allReq := &couchdb.AllDocsRequest{}
+		require.NoError(t, couchdb.GetAllDocs(testInstance, consts.OAuthAccessCodes, allReq, &results))
+		var publicCode string
+		for _, result := range results {
+			if result.ClientID == publicID && result.Challenge != "" {
+				publicCode = result.Code
+			}
+		}
+		require.NotEmpty(t, publicCode)
+
+		/* The public client doesn't send a secret, but must send the verifier */
+		e.POST("/auth/access_token").
+			WithFormField("grant_type", "authorization_code").
+			WithFormField("client_id", publicID).
+			WithFormField("code", publicCode).
+			WithHost(domain).
+			Expect().Status(400).
+			JSON().Object().
+			ValueEqual("error", "invalid code_verifier")
+
+		obj = e.POST("/auth/access_token").
+			WithFormField("grant_type", "authorization_code").
+			WithFormField("client_id", publicID).
+			WithFormField("code", publicCode).
+			WithFormField("code_verifier", verifier).
+			WithHost(domain).
+			Expect().Status(200).
+			JSON().Object()
+		obj.ValueEqual("scope", "files:read")
+		assertValidToken(t, testInstance, obj.Value("access_token").String().Raw(), "access", publicID, "files:read")
+
+		/* An access code without challenge is refused for a public client */
+		client, err := oauth.FindClient(testInstance, publicID)
+		require.NoError(t, err)
+		accessCode, err := oauth.CreateAccessCode(testInstance, client, "files:read", "")
+		require.NoError(t, err)
+		e.POST("/auth/access_token").
+			WithFormField("grant_type", "authorization_code").
+			WithFormField("client_id", publicID).
+			WithFormField("code", accessCode.Code).
+			WithHost(domain).
+			Expect().Status(400).
+			JSON().Object().
+			ValueEqual("error", "the code_challenge is mandatory for a public client")
+
+		/* A confidential client must still send its secret */
+		e.POST("/auth/access_token").
+			WithFormField("grant_type", "refresh_token").
+			WithFormField("client_id", clientID).
+			WithFormField("refresh_token", refreshToken).
+			WithHost(domain).
+			Expect().Status(400).
+			JSON().Object().
+			ValueEqual("error", "the client_secret parameter is mandatory")
+	})
+
 	t.Run("ConfirmFlagship", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -1446,6 +1764,16 @@ func TestAuth(t *testing.T) {
 			WithFormField("code", code).
 			WithFormField("token", string(token)).
 			WithHost(domain).
+			Expect().Status(400)
+
+		csrfToken := getLoginCSRFToken(e)
+
+		e.POST("/auth/clients/"+clientID+"/flagship").
+			WithFormField("code", code).
+			WithFormField("token", string(token)).
+			WithFormField("csrf_token", csrfToken).
+			WithCookie("_csrf", csrfToken).
+			WithHost(domain).
 			Expect().Status(204)
 
 		client, err := oauth.FindClient(testInstance, clientID)
@@ -2215,6 +2543,231 @@ func TestAuth(t *testing.T) {
 			obj.ValueEqual("token_type", "bearer")
 		})
 	})
+	t.Run("DeviceCode", func(t *testing.T) {
+		client := &oauth.Client{
+			RedirectURIs: []string{"https://example.org/oauth/callback"},
+			ClientName:   "My TV",
+			SoftwareID:   "github.com/example/tv",
+		}
+		require.Nil(t, client.Create(testInstance, oauth.NotPending))
+		sess, err := session.New(testInstance, session.LongRun)
+		require.NoError(t, err)
+		cookie, err := sess.ToCookie()
+		require.NoError(t, err)
+
+		askCode := func(e *httpexpect.Expect) (string, string) {
+			obj := e.POST("/auth/device/code").
+				WithFormField("client_id", client.ClientID).
+				WithFormField("client_secret", client.ClientSecret).
+				WithFormField("scope", "io.cozy.files:GET").
+				WithHost(domain).
+				Expect().Status(200).
+				JSON().Object()
+			obj.ValueEqual("verification_uri", "https://"+domain+"/auth/device")
+			obj.Value("verification_uri_complete").String().Contains("user_code=")
+			obj.ValueEqual("expires_in", int(oauth.DeviceCodeTTL.Seconds()))
+			obj.ValueEqual("interval", oauth.DeviceCodeInterval)
+			userCode := obj.Value("user_code").String().Match(`^[A-Z]{4}-[A-Z]{4}$`).Raw()[0]
+			return obj.Value("device_code").String().NotEmpty().Raw(), userCode
+		}
+		poll := func(e *httpexpect.Expect, deviceCode string) *httpexpect.Response {
+			return e.POST("/auth/access_token").
+				WithFormField("grant_type", oauth.DeviceCodeGrantType).
+				WithFormField("client_id", client.ClientID).
+				WithFormField("client_secret", client.ClientSecret).
+				WithFormField("device_code", deviceCode).
+				WithHost(domain).
+				Expect()
+		}
+		// confirm displays the verification page for the user code, and
+		// returns the CSRF token of the form.
+		confirm := func(e *httpexpect.Expect, userCode string) string {
+			body := e.GET("/auth/device").
+				WithQuery("user_code", userCode).
+				WithCookie(session.CookieName(testInstance), cookie.Value).
+				WithHost(domain).
+				Expect().Status(200).
+				ContentType("text/html", "utf-8").
+				Body()
+			body.Contains("My TV")
+			body.Contains(userCode)
+			return body.Match(`<input type="hidden" name="csrf_token" value="(\w+)"`).Index(1).Raw()
+		}
+		authorize := func(e *httpexpect.Expect, userCode, action string) *httpexpect.Response {
+			token := confirm(e, userCode)
+			return e.POST("/auth/device").
+				WithFormField("csrf_token", token).
+				WithFormField("user_code", userCode).
+				WithFormField("action", action).
+				WithCookie(session.CookieName(testInstance), cookie.Value).
+				WithHost(domain).
+				Expect()
+		}
+
+		t.Run("CodeErrors", func(t *testing.T) {
+			e := testutils.CreateTestClient(t, ts.URL)
+
+			e.POST("/auth/device/code").
+				WithFormField("scope", "io.cozy.files").
+				WithHost(domain).
+				Expect().Status(400)
+
+			e.POST("/auth/device/code").
+				WithFormField("client_id", "not-a-client").
+				WithFormField("scope", "io.cozy.files").
+				WithHost(domain).
+				Expect().Status(400).
+				JSON().Object().
+				ValueEqual("error", "the client must be registered")
+
+			e.POST("/auth/device/code").
+				WithFormField("client_id", client.ClientID).
+				WithFormField("scope", "io.cozy.files").
+				WithHost(domain).
+				Expect().Status(400).
+				JSON().Object().
+				ValueEqual("error", "the client_secret parameter is mandatory")
+
+			e.POST("/auth/device/code").
+				WithFormField("client_id", client.ClientID).
+				WithFormField("client_secret", "wrong").
+				WithFormField("scope", "io.cozy.files").
+				WithHost(domain).
+				Expect().Status(400).
+				JSON().Object().
+				ValueEqual("error", "invalid client_secret")
+
+			e.POST("/auth/device/code").
+				WithFormField("client_id", client.ClientID).
+				WithFormField("client_secret", client.ClientSecret).
+				WithHost(domain).
+				Expect().Status(400).
+				JSON().Object().
+				ValueEqual("error", "the scope parameter is mandatory")
+
+			e.POST("/auth/device/code").
+				WithFormField("client_id", client.ClientID).
+				WithFormField("client_secret", client.ClientSecret).
+				WithFormField("scope", "openid io.cozy.files").
+				WithHost(domain).
+				Expect().Status(400).
+				JSON().Object().
+				ValueEqual("error", "invalid_scope")
+		})
+
+		t.Run("VerificationPage", func(t *testing.T) {
+			e := testutils.CreateTestClient(t, ts.URL)
+
+			e.GET("/auth/device").
+				WithHost(domain).
+				WithRedirectPolicy(httpexpect.DontFollowRedirects).
+				Expect().Status(303).
+				Header("Location").Contains("/auth/login")
+
+			e.GET("/auth/device").
+				WithCookie(session.CookieName(testInstance), cookie.Value).
+				WithHost(domain).
+				Expect().Status(200).
+				Body().Contains(`name="user_code"`)
+
+			e.GET("/auth/device").
+				WithQuery("user_code", "BCDF-GHJK").
+				WithCookie(session.CookieName(testInstance), cookie.Value).
+				WithHost(domain).
+				Expect().Status(400).
+				Body().Contains("This code is invalid or has expired.")
+
+			e.POST("/auth/device").
+				WithFormField("user_code", "BCDF-GHJK").
+				WithFormField("action", "approve").
+				WithCookie(session.CookieName(testInstance), cookie.Value).
+				WithHost(domain).
+				Expect().Status(400)
+		})
+
+		t.Run("Approve", func(t *testing.T) {
+			e := testutils.CreateTestClient(t, ts.URL)
+			deviceCode, userCode := askCode(e)
+
+			poll(e, deviceCode).Status(400).
+				JSON().Object().ValueEqual("error", "authorization_pending")
+			res := poll(e, deviceCode)
+			res.Status(400).
+				JSON().Object().ValueEqual("error", "slow_down")
+			res.Header("Retry-After").Equal("10")
+
+			// The user code can be typed in lower case and without the dash
+			authorize(e, strings.ToLower(strings.ReplaceAll(userCode, "-", "")), "approve").
+				Status(200).
+				Body().Contains("Your device is connected")
+
+			obj := poll(e, deviceCode).Status(200).JSON().Object()
+			obj.ValueEqual("token_type", "bearer")
+			obj.ValueEqual("scope", "io.cozy.files:GET")
+			obj.NotContainsKey("id_token")
+			assertValidToken(t, testInstance, obj.Value("access_token").String().Raw(), "access", client.ClientID, "io.cozy.files:GET")
+			assertValidToken(t, testInstance, obj.Value("refresh_token").String().Raw(), "refresh", client.ClientID, "io.cozy.files:GET")
+
+			// The device code can be used only once
+			poll(e, deviceCode).Status(400).
+				JSON().Object().ValueEqual("error", "invalid_grant")
+		})
+
+		t.Run("Deny", func(t *testing.T) {
+			e := testutils.CreateTestClient(t, ts.URL)
+			deviceCode, userCode := askCode(e)
+
+			authorize(e, userCode, "deny").
+				Status(200).
+				Body().Contains("The access has been denied")
+
+			poll(e, deviceCode).Status(400).
+				JSON().Object().ValueEqual("error", "access_denied")
+			poll(e, deviceCode).Status(400).
+				JSON().Object().ValueEqual("error", "invalid_grant")
+		})
+
+		t.Run("Expired", func(t *testing.T) {
+			e := testutils.CreateTestClient(t, ts.URL)
+			deviceCode, userCode := askCode(e)
+
+			dc := &oauth.DeviceCode{}
+			require.NoError(t, couchdb.GetDoc(testInstance, consts.OAuthDeviceCodes, deviceCode, dc))
+			dc.ExpiresAt = crypto.Timestamp() - 1
+			require.NoError(t, couchdb.UpdateDoc(testInstance, dc))
+
+			e.GET("/auth/device").
+				WithQuery("user_code", userCode).
+				WithCookie(session.CookieName(testInstance), cookie.Value).
+				WithHost(domain).
+				Expect().Status(400)
+
+			poll(e, deviceCode).Status(400).
+				JSON().Object().ValueEqual("error", "expired_token")
+			poll(e, deviceCode).Status(400).
+				JSON().Object().ValueEqual("error", "invalid_grant")
+		})
+
+		t.Run("Clean", func(t *testing.T) {
+			e := testutils.CreateTestClient(t, ts.URL)
+			deviceCode, _ := askCode(e)
+
+			// A device code that has not expired is kept
+			require.NoError(t, oauth.CleanDeviceCode(testInstance, deviceCode))
+			dc := &oauth.DeviceCode{}
+			require.NoError(t, couchdb.GetDoc(testInstance, consts.OAuthDeviceCodes, deviceCode, dc))
+
+			// And it is removed after its expiration, even if it was never polled
+			dc.ExpiresAt = crypto.Timestamp() - 1
+			require.NoError(t, couchdb.UpdateDoc(testInstance, dc))
+			require.NoError(t, oauth.CleanDeviceCode(testInstance, deviceCode))
+			err := couchdb.GetDoc(testInstance, consts.OAuthDeviceCodes, deviceCode, dc)
+			assert.True(t, couchdb.IsNotFoundError(err))
+
+			// The cleaning of a code that has already been removed is a no-op
+			assert.NoError(t, oauth.CleanDeviceCode(testInstance, deviceCode))
+		})
+	})
 }
 
 func getLoginCSRFToken(e *httpexpect.Expect) string {
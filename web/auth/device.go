@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// deviceCode is the device authorization endpoint of RFC 8628: a client
+// without a browser asks for a device code and a user code. The user code is
+// then typed by the user on the verification page, on another device.
+func deviceCode(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	err := config.GetRateLimiter().CheckRateLimit(inst, limits.OAuthClientType)
+	if limits.IsLimitReachedOrExceeded(err) {
+		return echo.NewHTTPError(http.StatusNotFound, "Not found")
+	}
+
+	clientID := c.FormValue("client_id")
+	clientSecret := c.FormValue("client_secret")
+	scope := c.FormValue("scope")
+	if clientID == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the client_id parameter is mandatory",
+		})
+	}
+	client, err := oauth.FindClient(inst, clientID)
+	if err != nil {
+		if couchErr, isCouchErr := couchdb.IsCouchError(err); isCouchErr && couchErr.StatusCode >= 500 {
+			return err
+		}
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the client must be registered",
+		})
+	}
+	if clientSecret == "" && !client.IsPublic() {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the client_secret parameter is mandatory",
+		})
+	}
+	if clientSecret != "" && !client.CheckSecret(clientSecret) {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid client_secret",
+		})
+	}
+	if scope == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the scope parameter is mandatory",
+		})
+	}
+	// The scopes of OpenID Connect are refused, as the device page doesn't
+	// ask the consent of the user for them.
+	if _, openid, err := permission.SplitScopeString(scope); err != nil || len(openid) > 0 {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid_scope",
+		})
+	}
+
+	dc, err := oauth.CreateDeviceCode(inst, client, scope)
+	if err != nil {
+		return err
+	}
+	verification := inst.PageURL("/auth/device", nil)
+	complete := inst.PageURL("/auth/device", url.Values{
+		"user_code": {dc.FormattedUserCode()},
+	})
+	return c.JSON(http.StatusOK, echo.Map{
+		"device_code":               dc.Code,
+		"user_code":                 dc.FormattedUserCode(),
+		"verification_uri":          verification,
+		"verification_uri_complete": complete,
+		"expires_in":                int(oauth.DeviceCodeTTL.Seconds()),
+		"interval":                  dc.Interval,
+	})
+}
+
+// deviceForm is the verification page, where the user types the user code
+// displayed by the device, and then approves or denies the request.
+func deviceForm(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if !middlewares.IsLoggedIn(c) {
+		u := inst.PageURL("/auth/login", url.Values{
+			"redirect": {inst.FromURL(c.Request().URL)},
+		})
+		return c.Redirect(http.StatusSeeOther, u)
+	}
+
+	params := echo.Map{
+		"Domain":      inst.ContextualDomain(),
+		"ContextName": inst.ContextName,
+		"Locale":      inst.Locale,
+		"Title":       inst.TemplateTitle(),
+		"Favicon":     middlewares.Favicon(inst),
+	}
+	userCode := c.QueryParam("user_code")
+	if userCode == "" {
+		return c.Render(http.StatusOK, "device.html", params)
+	}
+	params["UserCode"] = userCode
+
+	dc, err := oauth.FindDeviceCodeByUserCode(inst, userCode)
+	if err != nil {
+		params["Error"] = "Device Invalid code"
+		return c.Render(http.StatusBadRequest, "device.html", params)
+	}
+	client, err := oauth.FindClient(inst, dc.ClientID)
+	if err != nil {
+		params["Error"] = "Device Invalid code"
+		return c.Render(http.StatusBadRequest, "device.html", params)
+	}
+	permissions, err := permission.UnmarshalScopeString(dc.Scope)
+	if err != nil {
+		return renderError(c, http.StatusBadRequest, "Error Invalid scope")
+	}
+	params["UserCode"] = dc.FormattedUserCode()
+	params["Client"] = client
+	params["Permissions"] = permissions
+	return c.Render(http.StatusOK, "device.html", params)
+}
+
+// deviceAuthorize is called when the user approves or denies the request of
+// the device.
+func deviceAuthorize(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if !middlewares.IsLoggedIn(c) {
+		return renderError(c, http.StatusUnauthorized, "Error Must be authenticated")
+	}
+
+	dc, err := oauth.FindDeviceCodeByUserCode(inst, c.FormValue("user_code"))
+	if err != nil {
+		return renderError(c, http.StatusBadRequest, "Device Invalid code")
+	}
+	defer LockOAuthClient(inst, dc.ClientID)()
+
+	params := echo.Map{
+		"Domain":      inst.ContextualDomain(),
+		"ContextName": inst.ContextName,
+		"Locale":      inst.Locale,
+		"Title":       inst.TemplateTitle(),
+		"Favicon":     middlewares.Favicon(inst),
+	}
+	if c.FormValue("action") != "approve" {
+		if err := dc.Deny(inst); err != nil {
+			return err
+		}
+		params["Done"] = "Device Denied"
+		return c.Render(http.StatusOK, "device.html", params)
+	}
+
+	client, err := oauth.FindClient(inst, dc.ClientID)
+	if err != nil {
+		return renderError(c, http.StatusBadRequest, "Device Invalid code")
+	}
+	if err := dc.Approve(inst, client); err != nil {
+		return err
+	}
+	params["Done"] = "Device Approved"
+	return c.Render(http.StatusOK, "device.html", params)
+}
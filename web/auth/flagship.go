@@ -1,12 +1,12 @@
 package auth
 
 import (
-	"crypto/subtle"
 	"encoding/json"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/app"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/oauth"
@@ -20,6 +20,12 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// The headers used by the flagship app on iOS to send an App Attest assertion.
+const (
+	AttestChallengeHeader = "X-Cozy-Attest-Challenge"
+	AttestAssertionHeader = "X-Cozy-Attest-Assertion"
+)
+
 // CreateSessionCode is the handler for creating a session code by the flagship
 // app.
 func CreateSessionCode(c echo.Context) error {
@@ -85,6 +91,12 @@ const (
 
 func canCreateSessionCode(c echo.Context, inst *instance.Instance) canCreateSessionCodeResult {
 	if err := middlewares.AllowMaximal(c); err == nil {
+		pdoc, _ := middlewares.GetPermission(c)
+		if client, ok := pdoc.Client.(*oauth.Client); ok {
+			if err := checkAssertion(c, inst, client); err != nil {
+				return cannotCreateSessionCode
+			}
+		}
 		return allowedToCreateSessionCode
 	}
 
@@ -105,6 +117,23 @@ func canCreateSessionCode(c echo.Context, inst *instance.Instance) canCreateSess
 	return allowedToCreateSessionCode
 }
 
+// checkAssertion verifies the App Attest assertion sent in the headers of the
+// request, for a client that has been attested on iOS.
+func checkAssertion(c echo.Context, inst *instance.Instance, client *oauth.Client) error {
+	if !client.RequiresAssertion(inst) {
+		return nil
+	}
+	req := c.Request()
+	challenge := req.Header.Get(AttestChallengeHeader)
+	assertion := req.Header.Get(AttestAssertionHeader)
+	if err := client.CheckAssertion(inst, challenge, assertion); err != nil {
+		inst.Logger().WithNamespace("oauth").
+			Infof("Invalid assertion for %s client: %s", client.ID(), err)
+		return err
+	}
+	return nil
+}
+
 func postChallenge(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 	err := config.GetRateLimiter().CheckRateLimit(inst, limits.OAuthClientType)
@@ -230,7 +259,7 @@ func loginFlagship(c echo.Context) error {
 			"error": "the client must be registered",
 		})
 	}
-	if subtle.ConstantTimeCompare([]byte(args.ClientSecret), []byte(client.ClientSecret)) == 0 {
+	if !client.CheckSecret(args.ClientSecret) {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": "invalid client_secret",
 		})
@@ -271,3 +300,32 @@ func loginFlagship(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, out)
 }
+
+type webviewTokenParameters struct {
+	Scope string `json:"scope"`
+}
+
+// buildWebviewToken is used by the flagship app to get a short-lived token
+// for an app that it opens in a webview, instead of injecting the session
+// cookie in the webview.
+func buildWebviewToken(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	webapp, err := app.GetWebappBySlug(inst, c.Param("slug"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+
+	var args webviewTokenParameters
+	if err := c.Bind(&args); err != nil {
+		return jsonapi.Errorf(http.StatusBadRequest, "%s", err)
+	}
+	token, err := middlewares.BuildWebviewToken(c, webapp, args.Scope)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, echo.Map{
+		"token":      token,
+		"scope":      args.Scope,
+		"expires_in": int(consts.WebviewTokenValidityDuration.Seconds()),
+	})
+}
@@ -1,7 +1,6 @@
 package auth
 
 import (
-	"crypto/subtle"
 	"net/http"
 
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
@@ -187,7 +186,7 @@ func magicLinkFlagship(c echo.Context) error {
 			"error": "the client must be registered",
 		})
 	}
-	if subtle.ConstantTimeCompare([]byte(args.ClientSecret), []byte(client.ClientSecret)) == 0 {
+	if !client.CheckSecret(args.ClientSecret) {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": "invalid client_secret",
 		})
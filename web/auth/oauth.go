@@ -2,7 +2,6 @@ package auth
 
 import (
 	"crypto/sha256"
-	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -51,6 +50,7 @@ type authorizeParams struct {
 	resType         string
 	challenge       string
 	challengeMethod string
+	nonce           string
 	client          *oauth.Client
 	webapp          *webappParams
 }
@@ -104,6 +104,9 @@ func checkAuthorizeParams(c echo.Context, params *authorizeParams) (bool, error)
 	if !params.client.AcceptRedirectURI(params.redirectURI) {
 		return true, renderError(c, http.StatusBadRequest, "Error Incorrect redirect_uri")
 	}
+	if params.client.IsPublic() && params.challenge == "" {
+		return true, renderError(c, http.StatusBadRequest, "Error No challenge code")
+	}
 
 	params.scope = strings.TrimSpace(params.scope)
 	if params.scope == "*" {
@@ -175,6 +178,7 @@ func (a *AuthorizeHTTPHandler) authorizeForm(c echo.Context) error {
 		resType:         c.QueryParam("response_type"),
 		challenge:       c.QueryParam("code_challenge"),
 		challengeMethod: c.QueryParam("code_challenge_method"),
+		nonce:           c.QueryParam("nonce"),
 	}
 
 	isLoggedIn := middlewares.IsLoggedIn(c)
@@ -366,6 +370,8 @@ func (a *AuthorizeHTTPHandler) authorizeForm(c echo.Context) error {
 		"Scope":            params.scope,
 		"Challenge":        params.challenge,
 		"ChallengeMethod":  params.challengeMethod,
+		"Nonce":            params.nonce,
+		"OpenIDScopes":     oauth.OpenIDScopes(params.scope),
 		"Permissions":      permissions,
 		"ReadOnly":         readOnly,
 		"CSRF":             c.Get("csrf"),
@@ -385,16 +391,25 @@ func (a *AuthorizeHTTPHandler) authorize(c echo.Context) error {
 		resType:         c.FormValue("response_type"),
 		challenge:       c.FormValue("code_challenge"),
 		challengeMethod: c.FormValue("code_challenge_method"),
+		nonce:           c.FormValue("nonce"),
 	}
 
 	if hasError, err := checkAuthorizeParams(c, &params); hasError {
 		return err
 	}
 
-	if !middlewares.IsLoggedIn(c) {
+	sess, ok := middlewares.GetSession(c)
+	if !ok {
 		return renderError(c, http.StatusUnauthorized, "Error Must be authenticated")
 	}
 
+	// On an instance in organization mode, the client is bound to the user
+	// who has authorized it.
+	if params.client.UserID != "" && params.client.UserID != sess.UserID {
+		return renderError(c, http.StatusForbidden, "Error Invalid client_id")
+	}
+	params.client.UserID = sess.UserID
+
 	u, err := url.ParseRequestURI(params.redirectURI)
 	if err != nil {
 		return renderError(c, http.StatusBadRequest, "Error Invalid redirect_uri")
@@ -443,7 +458,7 @@ func createAccessCode(c echo.Context, params authorizeParams, u *url.URL, q url.
 		q.Set("cozy_url", params.instance.Domain)
 	}
 
-	access, err := oauth.CreateAccessCode(params.instance, params.client, params.scope, params.challenge)
+	access, err := oauth.CreateOpenIDAccessCode(params.instance, params.client, params.scope, params.challenge, params.nonce)
 	if err != nil {
 		return err
 	}
@@ -861,6 +876,7 @@ type AccessTokenReponse struct {
 	Scope   string `json:"scope"`
 	Access  string `json:"access_token"`
 	Refresh string `json:"refresh_token,omitempty"`
+	IDToken string `json:"id_token,omitempty"`
 }
 
 func LockOAuthClient(inst *instance.Instance, clientID string) func() {
@@ -886,11 +902,6 @@ func accessToken(c echo.Context) error {
 			"error": "the client_id parameter is mandatory",
 		})
 	}
-	if clientSecret == "" {
-		return c.JSON(http.StatusBadRequest, echo.Map{
-			"error": "the client_secret parameter is mandatory",
-		})
-	}
 	defer LockOAuthClient(instance, clientID)()
 
 	client, err := oauth.FindClient(instance, clientID)
@@ -902,7 +913,13 @@ func accessToken(c echo.Context) error {
 			"error": "the client must be registered",
 		})
 	}
-	if subtle.ConstantTimeCompare([]byte(clientSecret), []byte(client.ClientSecret)) == 0 {
+	// The public clients can omit their secret, as they use PKCE
+	if clientSecret == "" && !client.IsPublic() {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the client_secret parameter is mandatory",
+		})
+	}
+	if clientSecret != "" && !client.CheckSecret(clientSecret) {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": "invalid client_secret",
 		})
@@ -932,6 +949,11 @@ func accessToken(c echo.Context) error {
 				"error": "invalid code",
 			})
 		}
+		if accessCode.Challenge == "" && client.IsPublic() {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": "the code_challenge is mandatory for a public client",
+			})
+		}
 		if accessCode.Challenge != "" {
 			sum := sha256.Sum256([]byte(verifier))
 			challenge := base64.RawURLEncoding.EncodeToString(sum[:])
@@ -942,12 +964,23 @@ func accessToken(c echo.Context) error {
 			}
 		}
 		out.Scope = accessCode.Scope
+		// The user has consented to these scopes of OpenID Connect on the
+		// authorize page, and they are saved with the last activity below.
+		client.OpenIDScopes = oauth.OpenIDScopes(out.Scope)
 		out.Refresh, err = client.CreateJWT(instance, consts.RefreshTokenAudience, out.Scope)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, echo.Map{
 				"error": "Can't generate refresh token",
 			})
 		}
+		if oauth.HasOpenIDScope(out.Scope) {
+			out.IDToken, err = client.CreateIDToken(instance, out.Scope, accessCode.Nonce)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, echo.Map{
+					"error": "Can't generate ID token",
+				})
+			}
+		}
 		// Delete the access code, it can be used only once
 		err = couchdb.DeleteDoc(instance, accessCode)
 		if err != nil {
@@ -955,6 +988,48 @@ func accessToken(c echo.Context) error {
 				"[oauth] Failed to delete the access code: %s", err)
 		}
 
+	case oauth.DeviceCodeGrantType:
+		code := c.FormValue("device_code")
+		if code == "" {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": "the device_code parameter is mandatory",
+			})
+		}
+		dc := &oauth.DeviceCode{}
+		if err = couchdb.GetDoc(instance, consts.OAuthDeviceCodes, code, dc); err != nil || dc.ClientID != client.CouchID {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": "invalid_grant",
+			})
+		}
+		// The errors are the ones defined by RFC 8628, and the client can
+		// continue to poll on authorization_pending and slow_down.
+		if err = dc.Poll(instance); err != nil {
+			switch err {
+			case oauth.ErrSlowDown:
+				c.Response().Header().Set("Retry-After", strconv.Itoa(dc.Interval))
+				fallthrough
+			case oauth.ErrAuthorizationPending, oauth.ErrAccessDenied, oauth.ErrExpiredToken:
+				return c.JSON(http.StatusBadRequest, echo.Map{
+					"error": err.Error(),
+				})
+			}
+			return err
+		}
+		// The user is not asked to consent to the scopes of OpenID Connect
+		// on the device page, and no ID token is issued for this grant.
+		if err = oauth.CheckOpenIDScopes(dc.Scope, nil); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": "invalid_scope",
+			})
+		}
+		out.Scope = dc.Scope
+		out.Refresh, err = client.CreateJWT(instance, consts.RefreshTokenAudience, out.Scope)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{
+				"error": "Can't generate refresh token",
+			})
+		}
+
 	case "refresh_token":
 		token := c.FormValue("refresh_token")
 		claims, ok := client.ValidToken(instance, consts.RefreshTokenAudience, token)
@@ -966,6 +1041,11 @@ func accessToken(c echo.Context) error {
 				"error": "invalid refresh token",
 			})
 		}
+		if err := checkAssertion(c, instance, client); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": err.Error(),
+			})
+		}
 
 		// Code below is used to transform an old OAuth client token scope to
 		// the new linked-app scope
@@ -974,6 +1054,19 @@ func accessToken(c echo.Context) error {
 		} else {
 			out.Scope = claims.Scope
 		}
+		if err = oauth.CheckOpenIDScopes(out.Scope, client.OpenIDScopes); err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"error": "invalid_scope",
+			})
+		}
+		if oauth.HasOpenIDScope(out.Scope) {
+			out.IDToken, err = client.CreateIDToken(instance, out.Scope, "")
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, echo.Map{
+					"error": "Can't generate ID token",
+				})
+			}
+		}
 
 	default:
 		return c.JSON(http.StatusBadRequest, echo.Map{
@@ -988,14 +1081,30 @@ func accessToken(c echo.Context) error {
 		})
 	}
 
-	// Update the last_refreshed_at field of the OAuth client
-	client.LastRefreshedAt = time.Now()
-	_ = couchdb.UpdateDoc(instance, client)
+	// Update the last_refreshed_at and last_activity fields of the OAuth client
+	_ = client.UpdateLastActivity(instance, clientActivity(c, instance, client))
 
 	_ = session.RemoveLoginRegistration(instance.ContextualDomain(), clientID)
 	return c.JSON(http.StatusOK, out)
 }
 
+// clientActivity returns the network information of the request, for the
+// last activity of the client. The IP address is geolocated only when it has
+// changed since the previous refresh.
+func clientActivity(c echo.Context, inst *instance.Instance, client *oauth.Client) *oauth.ClientActivity {
+	req := c.Request()
+	act := &oauth.ClientActivity{
+		IP:        session.ClientIP(req),
+		UserAgent: req.UserAgent(),
+	}
+	if prev := client.LastActivity; prev.SameNetwork(act.IP) {
+		act.City, act.Subdivision, act.Country = prev.City, prev.Subdivision, prev.Country
+	} else {
+		act.City, act.Subdivision, act.Country = session.Geolocate(act.IP, inst.Locale)
+	}
+	return act
+}
+
 func buildKonnectorToken(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 	slug := c.Param("slug")
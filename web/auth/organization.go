@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/organization"
+	"github.com/cozy/cozy-stack/model/risk"
+	"github.com/cozy/cozy-stack/model/session"
+	"github.com/labstack/echo/v4"
+)
+
+// loginOrganizationUser is the login of a user of an instance in organization
+// mode, with their email and passphrase. The second factor is not available
+// for these users, so the logins that the risk hook wants to verify are
+// refused.
+func loginOrganizationUser(c echo.Context, inst *instance.Instance, email string, passphrase []byte, longRunSession bool, redirect *url.URL) error {
+	user, err := organization.Authenticate(inst, email, passphrase)
+	if errors.Is(err, organization.ErrInvalidCredentials) {
+		inst.Logger().WithNamespace("loginaudit").
+			Infof("Failed login attempt for the user %s of the organization", email)
+		return badCredentials(c, inst, redirect)
+	}
+	if err != nil {
+		return err
+	}
+
+	details := map[string]interface{}{"user_id": user.ID()}
+	if decision := risk.Evaluate(inst, newRiskRequest(c, risk.EventLogin, details)); decision != risk.Allow {
+		user.Logger(inst).Infof("Login of %s refused by the risk hook (%s)", user.Email, decision)
+		errorMessage := inst.Translate(RiskBlockedErrorKey)
+		if wantsJSON(c) {
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error": errorMessage,
+			})
+		}
+		return renderLoginForm(c, inst, http.StatusForbidden, errorMessage, redirect)
+	}
+
+	duration := session.NormalRun
+	if longRunSession {
+		duration = session.LongRun
+	}
+	if err := newUserSession(c, inst, redirect, duration, "password", user.ID()); err != nil {
+		return err
+	}
+	if wantsJSON(c) {
+		return c.JSON(http.StatusOK, echo.Map{
+			"redirect": redirect.String(),
+		})
+	}
+	return c.Redirect(http.StatusSeeOther, redirect.String())
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/risk"
+	csettings "github.com/cozy/cozy-stack/model/settings"
 	"github.com/cozy/cozy-stack/model/sharing"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
@@ -102,6 +104,7 @@ func passphraseForm(c echo.Context) error {
 		"Salt":           string(inst.PassphraseSalt()),
 		"RegisterToken":  registerToken,
 		"CryptoPolyfill": cryptoPolyfill,
+		"LoginUI":        csettings.GetLoginUI(inst),
 	})
 }
 
@@ -136,8 +139,13 @@ func sendHint(c echo.Context) error {
 func passphraseReset(c echo.Context) error {
 	i := middlewares.GetInstance(c)
 	from := c.FormValue("from")
-	if err := lifecycle.RequestPassphraseReset(i, from); err != nil && !errors.Is(err, instance.ErrResetAlreadyRequested) {
-		return err
+	// The reset is done via a link sent by mail, so a verification asked by
+	// the risk hook is already done. When the hook blocks the reset, the
+	// response is the same to not give any hint to an attacker.
+	if risk.Evaluate(i, newRiskRequest(c, risk.EventPassphraseReset, nil)) != risk.Block {
+		if err := lifecycle.RequestPassphraseReset(i, from); err != nil && !errors.Is(err, instance.ErrResetAlreadyRequested) {
+			return err
+		}
 	}
 	// Disconnect the user if it is logged in. The idea is that if the user
 	// (maybe by accident) asks for a passphrase reset while logged in, we log
@@ -209,6 +217,7 @@ func passphraseRenewForm(c echo.Context) error {
 		"ResetToken":     hex.EncodeToString(token),
 		"CSRF":           c.Get("csrf"),
 		"CryptoPolyfill": cryptoPolyfill,
+		"LoginUI":        csettings.GetLoginUI(inst),
 	})
 }
 
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/risk"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -33,6 +34,18 @@ func registerClient(c echo.Context) error {
 				"Not authorized to create client with given parameters")
 		}
 	}
+	// The risk hook can't ask for a verification here, as the user is not
+	// involved in the registration: it is handled like a block.
+	details := map[string]interface{}{
+		"client_name": client.ClientName,
+		"client_kind": client.ClientKind,
+		"software_id": client.SoftwareID,
+	}
+	if decision := risk.Evaluate(instance, newRiskRequest(c, risk.EventOAuthClient, details)); decision != risk.Allow {
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "The registration of this client has been refused",
+		})
+	}
 	if err := client.Create(instance); err != nil {
 		return c.JSON(err.Code, err)
 	}
@@ -42,9 +55,20 @@ func registerClient(c echo.Context) error {
 func readClient(c echo.Context) error {
 	client := c.Get("client").(*oauth.Client)
 	client.TransformIDAndRev()
+	client.PreviousSecret = ""
 	return c.JSON(http.StatusOK, client)
 }
 
+func readClientQuota(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	client := c.Get("client").(*oauth.Client)
+	usage, err := client.GetAPIUsage(instance)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, usage)
+}
+
 func updateClient(c echo.Context) error {
 	instance := middlewares.GetInstance(c)
 	err := config.GetRateLimiter().CheckRateLimit(instance, limits.OAuthClientType)
@@ -74,6 +98,120 @@ func updateClient(c echo.Context) error {
 	if err := client.Update(instance, oldClient); err != nil {
 		return c.JSON(err.Code, err)
 	}
+	client.PreviousSecret = ""
+	return c.JSON(http.StatusOK, client)
+}
+
+// narrowClientScope allows a client to reduce its scope without asking again
+// the consent of the user. The previous tokens are revoked, and new tokens are
+// sent for the narrowed scope.
+func narrowClientScope(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	err := config.GetRateLimiter().CheckRateLimit(instance, limits.OAuthClientType)
+	if limits.IsLimitReachedOrExceeded(err) {
+		return echo.NewHTTPError(http.StatusNotFound, "Not found")
+	}
+
+	clientID := c.Param("client-id")
+	defer LockOAuthClient(instance, clientID)()
+
+	client, err := oauth.FindClient(instance, clientID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Client not found",
+		})
+	}
+	if err := checkClientToken(c, client); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if oauth.IsLinkedApp(client.SoftwareID) {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the scope of a linked app can't be changed",
+		})
+	}
+
+	var body struct {
+		Scope        string `json:"scope"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid_request",
+		})
+	}
+	if body.Scope == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "the scope parameter is mandatory",
+		})
+	}
+	claims, ok := client.ValidToken(instance, consts.RefreshTokenAudience, body.RefreshToken)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid refresh token",
+		})
+	}
+	if err := client.NarrowScope(instance, claims.Scope, body.Scope); err != nil {
+		if errors.Is(err, oauth.ErrScopeExpansion) {
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"error": err.Error(),
+			})
+		}
+		if couchErr, isCouchErr := couchdb.IsCouchError(err); isCouchErr && couchErr.StatusCode >= 500 {
+			return err
+		}
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid_scope",
+		})
+	}
+
+	out := AccessTokenReponse{
+		Type:  "bearer",
+		Scope: body.Scope,
+	}
+	out.Refresh, err = client.CreateJWT(instance, consts.RefreshTokenAudience, out.Scope)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Can't generate refresh token",
+		})
+	}
+	out.Access, err = client.CreateJWT(instance, consts.AccessTokenAudience, out.Scope)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Can't generate access token",
+		})
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func rotateClientSecret(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	err := config.GetRateLimiter().CheckRateLimit(instance, limits.OAuthClientType)
+	if limits.IsLimitReachedOrExceeded(err) {
+		return echo.NewHTTPError(http.StatusNotFound, "Not found")
+	}
+
+	clientID := c.Param("client-id")
+	defer LockOAuthClient(instance, clientID)()
+
+	client, err := oauth.FindClient(instance, clientID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Client not found",
+		})
+	}
+	if err := checkClientToken(c, client); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := client.RotateSecret(instance); err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	client.PreviousSecret = ""
 	return c.JSON(http.StatusOK, client)
 }
 
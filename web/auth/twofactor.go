@@ -87,15 +87,18 @@ func twoFactorForm(c echo.Context) error {
 // twoFactor handles a the twoFactor POST request
 func twoFactor(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
-	if !inst.HasAuthMode(instance.TwoFactorMail) {
+
+	// Retreiving data from request
+	token := []byte(c.FormValue("two-factor-token"))
+
+	// The form is also used when the risk hook asks for a verification by
+	// mail, even if the 2FA is not enabled for the instance.
+	if !inst.HasAuthMode(instance.TwoFactorMail) && len(token) == 0 {
 		errorMessage := inst.Translate(TwoFactorErrorKey)
 		return c.JSON(http.StatusUnauthorized, echo.Map{
 			"error": errorMessage,
 		})
 	}
-
-	// Retreiving data from request
-	token := []byte(c.FormValue("two-factor-token"))
 	passcode := c.FormValue("two-factor-passcode")
 	generateTrustedDeviceToken, _ := strconv.ParseBool(c.FormValue("two-factor-generate-trusted-device-token"))
 
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// userInfo is the UserInfo endpoint of OpenID Connect: it returns the claims
+// about the user for an access token with the openid scope.
+// See https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
+func userInfo(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil || pdoc.Type != permission.TypeOauth {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid_token",
+		})
+	}
+	claims, ok := c.Get("claims").(permission.Claims)
+	if !ok || !oauth.HasOpenIDScope(claims.Scope) {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="insufficient_scope"`)
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "insufficient_scope",
+		})
+	}
+	c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+	return c.JSON(http.StatusOK, oauth.GetUserInfo(inst, claims.Scope))
+}
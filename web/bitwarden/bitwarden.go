@@ -438,6 +438,11 @@ func refreshToken(c echo.Context) error {
 			"error": "the client must be registered",
 		})
 	}
+	if !client.CheckTokenGeneration(claims) {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "invalid refresh token",
+		})
+	}
 
 	// Create the credentials
 	access, err := bitwarden.CreateAccessJWT(inst, client)
@@ -532,6 +537,7 @@ func Routes(router *echo.Group) {
 	ciphers.POST("/:id", UpdateCipher)
 	ciphers.PUT("/:id", UpdateCipher)
 	ciphers.POST("/import", ImportCiphers)
+	ciphers.POST("/import/:format", ImportExternalCiphers)
 
 	ciphers.DELETE("/:id", DeleteCipher)
 	ciphers.POST("/:id/delete", DeleteCipher)
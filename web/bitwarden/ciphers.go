@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/cozy/cozy-stack/model/bitwarden"
+	"github.com/cozy/cozy-stack/model/bitwarden/importer"
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/pkg/consts"
@@ -960,3 +961,52 @@ func ImportCiphers(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+// ImportExternalCiphers is used to import the export of another password
+// manager. The export is sent in the body of the request, and it is converted
+// to ciphers on the server, in the Cozy organization.
+func ImportExternalCiphers(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.BitwardenCiphers); err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "invalid token",
+		})
+	}
+
+	format := c.Param("format")
+	if !importer.IsSupported(format) {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": importer.ErrUnknownFormat.Error(),
+		})
+	}
+
+	res, err := importer.Import(inst, format, c.Request().Body)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, importer.ErrTooLarge), errors.Is(err, importer.ErrTooManyEntries):
+			status = http.StatusRequestEntityTooLarge
+		case errors.Is(err, importer.ErrInvalidFile):
+			status = http.StatusUnprocessableEntity
+		}
+		return c.JSON(status, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if res.Imported > 0 {
+		// Send in the realtime hub an event to force a sync
+		go func() {
+			time.Sleep(1 * time.Second)
+			payload := couchdb.JSONDoc{
+				M: map[string]interface{}{
+					"import": true,
+				},
+				Type: consts.BitwardenCiphers,
+			}
+			realtime.GetHub().Publish(inst, realtime.EventNotify, &payload, nil)
+		}()
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
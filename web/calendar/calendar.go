@@ -0,0 +1,153 @@
+// Package calendar is for the API to manage the public ICS feeds of the
+// calendars, and for serving these feeds.
+package calendar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/calendar"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// feedCacheControl is the Cache-Control header for the ICS feeds: the
+// calendar services poll them regularly, and they can use the ETag to check
+// if the feed has changed.
+const feedCacheControl = "private, max-age=900"
+
+// apiFeed is used to show the URL of the feed on creation.
+type apiFeed struct {
+	*calendar.Feed
+	url string
+}
+
+func (f *apiFeed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*calendar.Feed
+		URL string `json:"url,omitempty"`
+	}{
+		Feed: f.Feed,
+		URL:  f.url,
+	})
+}
+
+type feedAttrs struct {
+	Name       string `json:"name"`
+	CalendarID string `json:"calendar_id"`
+	Privacy    string `json:"privacy"`
+}
+
+func listFeeds(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.CalendarFeeds); err != nil {
+		return err
+	}
+	list, err := calendar.List(inst)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(list))
+	for i, f := range list {
+		objs[i] = &apiFeed{Feed: f}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func createFeed(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.CalendarFeeds); err != nil {
+		return err
+	}
+	var attrs feedAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return err
+	}
+	f := &calendar.Feed{
+		Name:       attrs.Name,
+		CalendarID: attrs.CalendarID,
+		Privacy:    attrs.Privacy,
+	}
+	token, err := calendar.Create(inst, f)
+	if err != nil {
+		return wrapError(err)
+	}
+	obj := &apiFeed{Feed: f, url: calendar.URL(inst, token)}
+	return jsonapi.Data(c, http.StatusCreated, obj, nil)
+}
+
+func revokeFeed(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.CalendarFeeds); err != nil {
+		return err
+	}
+	f, err := calendar.Get(inst, c.Param("feed-id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := calendar.Revoke(inst, f); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ServeFeed is the public route for the ICS feed: the secret token of the
+// feed is in the URL, like /public/calendar/<token>.ics.
+func ServeFeed(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	token := strings.TrimSuffix(c.Param("file"), ".ics")
+	f, err := calendar.FindByToken(inst, token)
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidToken) {
+			return jsonapi.NotFound(err)
+		}
+		return err
+	}
+	events, err := calendar.Events(inst, f)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := calendar.WriteICS(buf, inst.Domain, f, events); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	h := c.Response().Header()
+	h.Set("Etag", etag)
+	h.Set(echo.HeaderCacheControl, feedCacheControl)
+	if utils.CheckPreconditions(c.Response(), c.Request(), etag) {
+		return nil
+	}
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+func wrapError(err error) error {
+	if couchdb.IsNotFoundError(err) {
+		return jsonapi.NotFound(err)
+	}
+	switch {
+	case errors.Is(err, calendar.ErrMissingName),
+		errors.Is(err, calendar.ErrInvalidPrivacy):
+		return jsonapi.InvalidAttribute("attributes", err)
+	}
+	return err
+}
+
+// Routes sets the routing for the calendar feeds.
+func Routes(router *echo.Group) {
+	router.GET("/feeds", listFeeds)
+	router.POST("/feeds", createFeed)
+	router.DELETE("/feeds/:feed-id", revokeFeed)
+}
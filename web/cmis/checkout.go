@@ -0,0 +1,76 @@
+package cmis
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+)
+
+// Checkout is used to persist the fact that a file has been checked out by a
+// CMIS client. Its identifier is the identifier of the file.
+type Checkout struct {
+	DocID        string    `json:"_id,omitempty"`
+	DocRev       string    `json:"_rev,omitempty"`
+	CheckedOutBy string    `json:"checked_out_by,omitempty"`
+	CheckedOutAt time.Time `json:"checked_out_at"`
+}
+
+// ID returns the checkout qualified identifier
+func (c *Checkout) ID() string { return c.DocID }
+
+// Rev returns the checkout revision
+func (c *Checkout) Rev() string { return c.DocRev }
+
+// DocType returns the checkout document type
+func (c *Checkout) DocType() string { return consts.CMISCheckouts }
+
+// Clone implements couchdb.Doc
+func (c *Checkout) Clone() couchdb.Doc {
+	cloned := *c
+	return &cloned
+}
+
+// SetID changes the checkout qualified identifier
+func (c *Checkout) SetID(id string) { c.DocID = id }
+
+// SetRev changes the checkout revision
+func (c *Checkout) SetRev(rev string) { c.DocRev = rev }
+
+// findCheckout returns the checkout for the given file, or nil if the file is
+// not checked out.
+func findCheckout(inst *instance.Instance, fileID string) (*Checkout, error) {
+	doc := &Checkout{}
+	err := couchdb.GetDoc(inst, consts.CMISCheckouts, fileID, doc)
+	if couchdb.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// listCheckouts returns all the checkouts of the instance.
+func listCheckouts(inst *instance.Instance) ([]*Checkout, error) {
+	var docs []*Checkout
+	req := &couchdb.AllDocsRequest{Limit: 1000}
+	err := couchdb.GetAllDocs(inst, consts.CMISCheckouts, req, &docs)
+	if couchdb.IsNoDatabaseError(err) {
+		return nil, nil
+	}
+	return docs, err
+}
+
+func createCheckout(inst *instance.Instance, fileID, by string) (*Checkout, error) {
+	doc := &Checkout{
+		DocID:        fileID,
+		CheckedOutBy: by,
+		CheckedOutAt: time.Now().UTC(),
+	}
+	if err := couchdb.CreateNamedDocWithDB(inst, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,826 @@
+// Package cmis exposes the VFS through a minimal implementation of the
+// browser binding of CMIS 1.1, so that document management tools can read and
+// write the files of a Cozy. Folders and documents are mapped on directories
+// and files, and the checkin/checkout of documents is mapped on the versions
+// of the files.
+package cmis
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	build "github.com/cozy/cozy-stack/pkg/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/files"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// RepositoryID is the identifier of the only repository exposed by an
+// instance.
+const RepositoryID = "cozy"
+
+// cmisVersion is the version of the specification that is supported.
+const cmisVersion = "1.1"
+
+// maxItems is the default value for the maxItems parameter of the children
+// selector, and maxItemsLimit is the maximal value accepted for it.
+const (
+	maxItems      = 100
+	maxItemsLimit = 1000
+)
+
+var (
+	errObjectNotFound = errors.New("object not found")
+	errCheckedOut     = errors.New("the document is checked out")
+	errNotCheckedOut  = errors.New("the document is not checked out")
+	errNotSupported   = errors.New("this operation is not supported")
+)
+
+// cmisError is the JSON representation of an error for the browser binding.
+type cmisError struct {
+	Status    int    `json:"-"`
+	Exception string `json:"exception"`
+	Message   string `json:"message"`
+}
+
+func (e *cmisError) Error() string { return e.Message }
+
+func newError(status int, exception string, err error) *cmisError {
+	return &cmisError{Status: status, Exception: exception, Message: err.Error()}
+}
+
+// wrapError converts an error from the VFS, CouchDB or the permissions to the
+// exceptions defined by CMIS.
+func wrapError(err error) *cmisError {
+	if ce, ok := err.(*cmisError); ok {
+		return ce
+	}
+	if couchdb.IsNotFoundError(err) {
+		return newError(http.StatusNotFound, "objectNotFound", err)
+	}
+	if he, ok := err.(*echo.HTTPError); ok {
+		msg := fmt.Sprintf("%v", he.Message)
+		switch he.Code {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return newError(he.Code, "permissionDenied", errors.New(msg))
+		}
+		return newError(he.Code, "runtime", errors.New(msg))
+	}
+	err = files.WrapVfsError(err)
+	if je, ok := err.(*jsonapi.Error); ok {
+		detail := errors.New(je.Detail)
+		switch je.Status {
+		case http.StatusNotFound:
+			return newError(je.Status, "objectNotFound", detail)
+		case http.StatusConflict:
+			return newError(je.Status, "contentAlreadyExists", detail)
+		case http.StatusPreconditionFailed:
+			return newError(http.StatusConflict, "updateConflict", detail)
+		case http.StatusRequestEntityTooLarge:
+			return newError(http.StatusConflict, "storage", detail)
+		case http.StatusForbidden:
+			return newError(je.Status, "permissionDenied", detail)
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return newError(http.StatusBadRequest, "invalidArgument", detail)
+		}
+		return newError(je.Status, "runtime", detail)
+	}
+	return newError(http.StatusInternalServerError, "runtime", err)
+}
+
+func sendError(c echo.Context, err error) error {
+	ce := wrapError(err)
+	return c.JSON(ce.Status, ce)
+}
+
+// repositoryInfo returns the description of the repository of the instance.
+func repositoryInfo(inst *instance.Instance) map[string]interface{} {
+	base := inst.PageURL("/cmis/"+RepositoryID, nil)
+	return map[string]interface{}{
+		"repositoryId":          RepositoryID,
+		"repositoryName":        inst.ContextualDomain(),
+		"repositoryDescription": "Cozy Drive",
+		"vendorName":            "Cozy Cloud",
+		"productName":           "cozy-stack",
+		"productVersion":        build.Version,
+		"rootFolderId":          consts.RootDirID,
+		"repositoryUrl":         base,
+		"rootFolderUrl":         base + "/root",
+		"cmisVersionSupported":  cmisVersion,
+		"latestChangeLogToken":  "",
+		"changesIncomplete":     true,
+		"capabilities": map[string]interface{}{
+			"capabilityContentStreamUpdatability": "anytime",
+			"capabilityChanges":                   "none",
+			"capabilityRenditions":                "none",
+			"capabilityGetDescendants":            false,
+			"capabilityGetFolderTree":             false,
+			"capabilityMultifiling":               false,
+			"capabilityUnfiling":                  false,
+			"capabilityVersionSpecificFiling":     false,
+			"capabilityPWCSearchable":             false,
+			"capabilityPWCUpdatable":              true,
+			"capabilityAllVersionsSearchable":     false,
+			"capabilityOrderBy":                   "none",
+			"capabilityQuery":                     "none",
+			"capabilityJoin":                      "none",
+			"capabilityACL":                       "none",
+		},
+	}
+}
+
+// Repositories returns the list of the repositories, with only one repository
+// for the instance.
+func Repositories(c echo.Context) error {
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return sendError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		RepositoryID: repositoryInfo(inst),
+	})
+}
+
+// Repository handles the GET requests on the repository URL: they are the
+// selectors that use an objectId.
+func Repository(c echo.Context) error {
+	if c.Param("repository") != RepositoryID {
+		return sendError(c, newError(http.StatusNotFound, "objectNotFound", errObjectNotFound))
+	}
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return sendError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+	selector := c.QueryParam("cmisselector")
+	objectID := c.QueryParam("objectId")
+	if selector == "" || selector == "repositoryInfo" {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			RepositoryID: repositoryInfo(inst),
+		})
+	}
+	if selector == "checkedout" {
+		return checkedOut(c, inst)
+	}
+	if objectID == "" {
+		return sendError(c, newError(http.StatusBadRequest, "invalidArgument", errors.New("objectId is missing")))
+	}
+	return handleSelector(c, inst, selector, objectID)
+}
+
+// Root handles the GET requests on the root folder URL: the object is
+// identified by its path.
+func Root(c echo.Context) error {
+	if c.Param("repository") != RepositoryID {
+		return sendError(c, newError(http.StatusNotFound, "objectNotFound", errObjectNotFound))
+	}
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return sendError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+	objectID := c.QueryParam("objectId")
+	if objectID == "" {
+		dir, file, err := inst.VFS().DirOrFileByPath(objectPath(c))
+		if err != nil {
+			return sendError(c, err)
+		}
+		if dir != nil {
+			objectID = dir.DocID
+		} else {
+			objectID = file.DocID
+		}
+	}
+	return handleSelector(c, inst, c.QueryParam("cmisselector"), objectID)
+}
+
+func objectPath(c echo.Context) string {
+	p := c.Param("*")
+	return path.Clean("/" + p)
+}
+
+func handleSelector(c echo.Context, inst *instance.Instance, selector, objectID string) error {
+	id, versionID, pwc := splitObjectID(objectID)
+	dir, file, err := inst.VFS().DirOrFileByID(id)
+	if err != nil {
+		return sendError(c, err)
+	}
+	if err := checkPerm(c, permission.GET, dir, file); err != nil {
+		return sendError(c, err)
+	}
+
+	if dir != nil {
+		switch selector {
+		case "", "children":
+			return children(c, inst, dir)
+		case "object", "properties":
+			return c.JSON(http.StatusOK, folderObject(dir))
+		case "parent", "folderParent":
+			return parentOf(c, inst, dir.DirID, dir.DocID)
+		}
+		return sendError(c, newError(http.StatusBadRequest, "notSupported", errNotSupported))
+	}
+
+	checkout, err := findCheckout(inst, file.DocID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	var version *vfs.Version
+	if versionID != "" {
+		version, err = vfs.FindVersion(inst, versionID)
+		if err != nil {
+			return sendError(c, err)
+		}
+	}
+	if pwc && checkout == nil {
+		return sendError(c, newError(http.StatusNotFound, "objectNotFound", errNotCheckedOut))
+	}
+
+	switch selector {
+	case "", "content":
+		disposition := "inline"
+		if c.QueryParam("download") == "attachment" {
+			disposition = "attachment"
+		}
+		err = vfs.ServeFileContent(inst.VFS(), file, version, "", disposition, c.Request(), c.Response())
+		if err != nil {
+			return sendError(c, err)
+		}
+		return nil
+	case "object", "properties":
+		switch {
+		case version != nil:
+			label, err := versionLabelFor(inst, file.DocID, versionID)
+			if err != nil {
+				return sendError(c, err)
+			}
+			return c.JSON(http.StatusOK, versionObject(file, version, label))
+		case pwc:
+			return c.JSON(http.StatusOK, pwcObject(file, checkout))
+		}
+		return c.JSON(http.StatusOK, documentObject(file, checkout))
+	case "parents":
+		return parentOf(c, inst, file.DirID, file.DocID)
+	case "versions":
+		return versions(c, inst, file, checkout)
+	}
+	return sendError(c, newError(http.StatusBadRequest, "notSupported", errNotSupported))
+}
+
+func children(c echo.Context, inst *instance.Instance, dir *vfs.DirDoc) error {
+	limit := maxItems
+	if max, err := strconv.Atoi(c.QueryParam("maxItems")); err == nil && max > 0 {
+		limit = max
+	}
+	if limit > maxItemsLimit {
+		limit = maxItemsLimit
+	}
+	skip := 0
+	if count, err := strconv.Atoi(c.QueryParam("skipCount")); err == nil && count > 0 {
+		skip = count
+	}
+
+	fs := inst.VFS()
+	total, err := fs.DirLength(dir)
+	if err != nil {
+		return sendError(c, err)
+	}
+	cursor := couchdb.NewSkipCursor(limit, skip)
+	docs, err := fs.DirBatch(dir, cursor)
+	if err != nil {
+		return sendError(c, err)
+	}
+
+	list := objectList{
+		Objects:      make([]objectInFolder, 0, len(docs)),
+		HasMoreItems: cursor.HasMore(),
+		NumItems:     total,
+	}
+	for _, doc := range docs {
+		d, f := doc.Refine()
+		if d != nil {
+			list.Objects = append(list.Objects, objectInFolder{Object: folderObject(d)})
+			continue
+		}
+		checkout, err := findCheckout(inst, f.DocID)
+		if err != nil {
+			return sendError(c, err)
+		}
+		list.Objects = append(list.Objects, objectInFolder{Object: documentObject(f, checkout)})
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+func parentOf(c echo.Context, inst *instance.Instance, parentID, childID string) error {
+	if childID == consts.RootDirID {
+		return sendError(c, newError(http.StatusBadRequest, "invalidArgument", errors.New("the root folder has no parent")))
+	}
+	parent, err := inst.VFS().DirByID(parentID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	if c.QueryParam("cmisselector") == "parents" {
+		return c.JSON(http.StatusOK, []objectInFolder{{Object: folderObject(parent)}})
+	}
+	return c.JSON(http.StatusOK, folderObject(parent))
+}
+
+func versions(c echo.Context, inst *instance.Instance, file *vfs.FileDoc, checkout *Checkout) error {
+	olds, err := sortedVersions(inst, file.DocID)
+	if err != nil {
+		return sendError(c, err)
+	}
+	// The versions are returned from the most recent to the oldest, as
+	// required by the specification.
+	list := make([]*object, 0, len(olds)+2)
+	if checkout != nil {
+		list = append(list, pwcObject(file, checkout))
+	}
+	list = append(list, documentObject(file, checkout))
+	for i := len(olds) - 1; i >= 0; i-- {
+		list = append(list, versionObject(file, olds[i], strconv.Itoa(i+1)))
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+// versionLabelFor returns the label of an old version, ie its position in the
+// list of the versions of the file.
+func versionLabelFor(inst *instance.Instance, fileID, versionID string) (string, error) {
+	olds, err := sortedVersions(inst, fileID)
+	if err != nil {
+		return "", err
+	}
+	for i, old := range olds {
+		if old.DocID == versionID {
+			return strconv.Itoa(i + 1), nil
+		}
+	}
+	return "", newError(http.StatusNotFound, "objectNotFound", errObjectNotFound)
+}
+
+// sortedVersions returns the old versions of a file, from the oldest to the
+// most recent.
+func sortedVersions(inst *instance.Instance, fileID string) ([]*vfs.Version, error) {
+	olds, err := vfs.VersionsFor(inst, fileID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(olds, func(i, j int) bool {
+		return olds[i].UpdatedAt.Before(olds[j].UpdatedAt)
+	})
+	return olds, nil
+}
+
+func checkedOut(c echo.Context, inst *instance.Instance) error {
+	checkouts, err := listCheckouts(inst)
+	if err != nil {
+		return sendError(c, err)
+	}
+	list := objectList{Objects: []objectInFolder{}}
+	for _, checkout := range checkouts {
+		file, err := inst.VFS().FileByID(checkout.DocID)
+		if err != nil {
+			continue
+		}
+		if checkPerm(c, permission.GET, nil, file) != nil {
+			continue
+		}
+		list.Objects = append(list.Objects, objectInFolder{Object: pwcObject(file, checkout)})
+	}
+	list.NumItems = len(list.Objects)
+	return c.JSON(http.StatusOK, list)
+}
+
+// Action handles the POST requests of the browser binding. The action is
+// given by the cmisaction form field.
+func Action(c echo.Context) error {
+	if c.Param("repository") != RepositoryID {
+		return sendError(c, newError(http.StatusNotFound, "objectNotFound", errObjectNotFound))
+	}
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return sendError(c, err)
+	}
+	inst := middlewares.GetInstance(c)
+
+	objectID := c.FormValue("objectId")
+	if objectID == "" && c.Param("*") != "" {
+		dir, file, err := inst.VFS().DirOrFileByPath(objectPath(c))
+		if err != nil {
+			return sendError(c, err)
+		}
+		if dir != nil {
+			objectID = dir.DocID
+		} else {
+			objectID = file.DocID
+		}
+	}
+	if objectID == "" {
+		objectID = consts.RootDirID
+	}
+
+	var obj *object
+	var err error
+	switch c.FormValue("cmisaction") {
+	case "createFolder":
+		obj, err = createFolder(c, inst, objectID)
+	case "createDocument":
+		obj, err = createDocument(c, inst, objectID)
+	case "update":
+		obj, err = updateProperties(c, inst, objectID)
+	case "setContent":
+		obj, err = setContent(c, inst, objectID)
+	case "delete", "deleteTree":
+		if err = deleteObject(c, inst, objectID); err == nil {
+			return c.NoContent(http.StatusOK)
+		}
+	case "checkOut":
+		obj, err = checkOut(c, inst, objectID)
+	case "cancelCheckOut":
+		if err = cancelCheckOut(c, inst, objectID); err == nil {
+			return c.NoContent(http.StatusOK)
+		}
+	case "checkIn":
+		obj, err = checkIn(c, inst, objectID)
+	default:
+		err = newError(http.StatusBadRequest, "notSupported", errNotSupported)
+	}
+	if err != nil {
+		return sendError(c, err)
+	}
+	status := http.StatusOK
+	switch c.FormValue("cmisaction") {
+	case "createFolder", "createDocument", "checkOut":
+		status = http.StatusCreated
+	}
+	return c.JSON(status, obj)
+}
+
+// properties parses the propertyId[n] and propertyValue[n] form fields.
+func properties(c echo.Context) map[string]string {
+	props := make(map[string]string)
+	for i := 0; ; i++ {
+		id := c.FormValue(fmt.Sprintf("propertyId[%d]", i))
+		if id == "" {
+			break
+		}
+		props[id] = c.FormValue(fmt.Sprintf("propertyValue[%d]", i))
+	}
+	return props
+}
+
+func createFolder(c echo.Context, inst *instance.Instance, parentID string) (*object, error) {
+	name := properties(c)["cmis:name"]
+	if name == "" {
+		return nil, newError(http.StatusBadRequest, "constraint", errors.New("cmis:name is required"))
+	}
+	fs := inst.VFS()
+	dir, err := vfs.NewDirDoc(fs, name, parentID, nil)
+	if err != nil {
+		return nil, err
+	}
+	dir.CozyMetadata, _ = files.CozyMetadataFromClaims(c, false)
+	if err := checkPerm(c, permission.POST, dir, nil); err != nil {
+		return nil, err
+	}
+	if err := fs.CreateDir(dir); err != nil {
+		return nil, err
+	}
+	return folderObject(dir), nil
+}
+
+// contentStream returns the content stream sent by the client in the content
+// field of a multipart form, with its mime-type and length.
+func contentStream(c echo.Context) (io.ReadCloser, string, int64, error) {
+	fh, err := c.FormFile("content")
+	if err != nil {
+		return nil, "", 0, newError(http.StatusBadRequest, "constraint", errors.New("the content stream is missing"))
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return f, fh.Header.Get(echo.HeaderContentType), fh.Size, nil
+}
+
+func writeContent(inst *instance.Instance, newdoc, olddoc *vfs.FileDoc, content io.Reader) error {
+	file, err := inst.VFS().CreateFile(newdoc, olddoc)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, content)
+	if cerr := file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func createDocument(c echo.Context, inst *instance.Instance, parentID string) (*object, error) {
+	name := properties(c)["cmis:name"]
+	if name == "" {
+		return nil, newError(http.StatusBadRequest, "constraint", errors.New("cmis:name is required"))
+	}
+	content, contentType, size, err := contentStream(c)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	mime, class := vfs.ExtractMimeAndClassFromFilename(name)
+	if contentType != "" && contentType != echo.MIMEOctetStream {
+		mime, class = vfs.ExtractMimeAndClass(contentType)
+	}
+	doc, err := vfs.NewFileDoc(name, parentID, size, nil, mime, class,
+		time.Now(), false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc.CozyMetadata, _ = files.CozyMetadataFromClaims(c, true)
+	if err := checkPerm(c, permission.POST, nil, doc); err != nil {
+		return nil, err
+	}
+	if err := writeContent(inst, doc, nil, content); err != nil {
+		return nil, err
+	}
+	return documentObject(doc, nil), nil
+}
+
+func updateProperties(c echo.Context, inst *instance.Instance, objectID string) (*object, error) {
+	id, versionID, _ := splitObjectID(objectID)
+	if versionID != "" {
+		return nil, newError(http.StatusConflict, "constraint", errors.New("an old version cannot be updated"))
+	}
+	name, ok := properties(c)["cmis:name"]
+	if !ok || name == "" {
+		return nil, newError(http.StatusBadRequest, "invalidArgument", errors.New("only cmis:name can be updated"))
+	}
+	fs := inst.VFS()
+	dir, file, err := fs.DirOrFileByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return nil, err
+	}
+	patch := &vfs.DocPatch{Name: &name}
+	if dir != nil {
+		if err := checkChangeToken(c, dir.DocRev); err != nil {
+			return nil, err
+		}
+		dir, err = vfs.ModifyDirMetadata(fs, dir, patch)
+		if err != nil {
+			return nil, err
+		}
+		return folderObject(dir), nil
+	}
+	if err := checkChangeToken(c, file.DocRev); err != nil {
+		return nil, err
+	}
+	file, err = vfs.ModifyFileMetadata(fs, file, patch)
+	if err != nil {
+		return nil, err
+	}
+	checkout, err := findCheckout(inst, file.DocID)
+	if err != nil {
+		return nil, err
+	}
+	return documentObject(file, checkout), nil
+}
+
+// checkChangeToken implements the optimistic locking of CMIS: if the client
+// sends a change token, it must match the current revision of the object.
+func checkChangeToken(c echo.Context, rev string) error {
+	token := c.FormValue("changeToken")
+	if token != "" && token != rev {
+		return newError(http.StatusConflict, "updateConflict", errors.New("the change token does not match"))
+	}
+	return nil
+}
+
+// setContent replaces the content of a document. The previous content is kept
+// as an old version of the file by the VFS. If the document is checked out,
+// only its private working copy can be updated.
+func setContent(c echo.Context, inst *instance.Instance, objectID string) (*object, error) {
+	id, versionID, pwc := splitObjectID(objectID)
+	if versionID != "" {
+		return nil, newError(http.StatusConflict, "constraint", errors.New("an old version cannot be updated"))
+	}
+	olddoc, err := inst.VFS().FileByID(id)
+	if err != nil {
+		return nil, err
+	}
+	checkout, err := findCheckout(inst, olddoc.DocID)
+	if err != nil {
+		return nil, err
+	}
+	if checkout != nil && !pwc {
+		return nil, newError(http.StatusConflict, "updateConflict", errCheckedOut)
+	}
+	if checkout == nil && pwc {
+		return nil, newError(http.StatusConflict, "updateConflict", errNotCheckedOut)
+	}
+	if err := checkChangeToken(c, olddoc.DocRev); err != nil {
+		return nil, err
+	}
+	newdoc, err := replaceContent(c, inst, olddoc)
+	if err != nil {
+		return nil, err
+	}
+	if pwc {
+		return pwcObject(newdoc, checkout), nil
+	}
+	return documentObject(newdoc, checkout), nil
+}
+
+func replaceContent(c echo.Context, inst *instance.Instance, olddoc *vfs.FileDoc) (*vfs.FileDoc, error) {
+	content, contentType, size, err := contentStream(c)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	mime, class := olddoc.Mime, olddoc.Class
+	if contentType != "" && contentType != echo.MIMEOctetStream {
+		mime, class = vfs.ExtractMimeAndClass(contentType)
+	}
+	newdoc, err := vfs.NewFileDoc(olddoc.DocName, olddoc.DirID, size, nil, mime, class,
+		olddoc.CreatedAt, olddoc.Executable, false, olddoc.Encrypted, olddoc.Tags)
+	if err != nil {
+		return nil, err
+	}
+	newdoc.SetID(olddoc.ID())
+	newdoc.ReferencedBy = olddoc.ReferencedBy
+	if olddoc.CozyMetadata != nil {
+		newdoc.CozyMetadata = olddoc.CozyMetadata.Clone()
+	}
+	if err := checkPerm(c, permission.PUT, nil, olddoc); err != nil {
+		return nil, err
+	}
+	if err := writeContent(inst, newdoc, olddoc, content); err != nil {
+		return nil, err
+	}
+	return newdoc, nil
+}
+
+func deleteObject(c echo.Context, inst *instance.Instance, objectID string) error {
+	id, versionID, pwc := splitObjectID(objectID)
+	if pwc {
+		return cancelCheckOut(c, inst, objectID)
+	}
+	fs := inst.VFS()
+	dir, file, err := fs.DirOrFileByID(id)
+	if err != nil {
+		return err
+	}
+	if err := checkPerm(c, permission.DELETE, dir, file); err != nil {
+		return err
+	}
+	if versionID != "" {
+		if err := inst.CheckLegalHold(); err != nil {
+			return err
+		}
+		version, err := vfs.FindVersion(inst, versionID)
+		if err != nil {
+			return err
+		}
+		return fs.CleanOldVersion(file.DocID, version)
+	}
+	if dir != nil {
+		if dir.DocID == consts.RootDirID {
+			return newError(http.StatusConflict, "constraint", errors.New("the root folder cannot be deleted"))
+		}
+		if c.FormValue("cmisaction") != "deleteTree" {
+			empty, err := dir.IsEmpty(fs)
+			if err != nil {
+				return err
+			}
+			if !empty {
+				return newError(http.StatusConflict, "constraint", vfs.ErrDirNotEmpty)
+			}
+		}
+		_, err = vfs.TrashDir(fs, dir)
+		return err
+	}
+	checkout, err := findCheckout(inst, file.DocID)
+	if err != nil {
+		return err
+	}
+	if checkout != nil {
+		return newError(http.StatusConflict, "updateConflict", errCheckedOut)
+	}
+	_, err = vfs.TrashFile(fs, file)
+	return err
+}
+
+// checkOut creates a private working copy for a document. The PWC is not a
+// separate file: it shares its content with the document, and the checkIn
+// will create a new version of the file.
+func checkOut(c echo.Context, inst *instance.Instance, objectID string) (*object, error) {
+	id, versionID, pwc := splitObjectID(objectID)
+	if versionID != "" || pwc {
+		return nil, newError(http.StatusConflict, "constraint", errors.New("only the latest version can be checked out"))
+	}
+	file, err := inst.VFS().FileByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPerm(c, permission.PUT, nil, file); err != nil {
+		return nil, err
+	}
+	by := ""
+	if pdoc, err := middlewares.GetPermission(c); err == nil {
+		by = pdoc.SourceID
+	}
+	checkout, err := createCheckout(inst, file.DocID, by)
+	if couchdb.IsConflictError(err) {
+		return nil, newError(http.StatusConflict, "versioning", errCheckedOut)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pwcObject(file, checkout), nil
+}
+
+func cancelCheckOut(c echo.Context, inst *instance.Instance, objectID string) error {
+	id, _, _ := splitObjectID(objectID)
+	file, err := inst.VFS().FileByID(id)
+	if err != nil {
+		return err
+	}
+	if err := checkPerm(c, permission.PUT, nil, file); err != nil {
+		return err
+	}
+	checkout, err := findCheckout(inst, file.DocID)
+	if err != nil {
+		return err
+	}
+	if checkout == nil {
+		return newError(http.StatusConflict, "versioning", errNotCheckedOut)
+	}
+	return couchdb.DeleteDoc(inst, checkout)
+}
+
+// checkIn ends the checkout of a document. If a content stream is sent, it
+// becomes the new content of the file, and the previous one is kept as a
+// version.
+func checkIn(c echo.Context, inst *instance.Instance, objectID string) (*object, error) {
+	id, _, _ := splitObjectID(objectID)
+	file, err := inst.VFS().FileByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPerm(c, permission.PUT, nil, file); err != nil {
+		return nil, err
+	}
+	checkout, err := findCheckout(inst, file.DocID)
+	if err != nil {
+		return nil, err
+	}
+	if checkout == nil {
+		return nil, newError(http.StatusConflict, "versioning", errNotCheckedOut)
+	}
+	if _, err := c.FormFile("content"); err == nil {
+		file, err = replaceContent(c, inst, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if name := properties(c)["cmis:name"]; name != "" && name != file.DocName {
+		file, err = vfs.ModifyFileMetadata(inst.VFS(), file, &vfs.DocPatch{Name: &name})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := couchdb.DeleteDoc(inst, checkout); err != nil {
+		return nil, err
+	}
+	return documentObject(file, nil), nil
+}
+
+func checkPerm(c echo.Context, v permission.Verb, d *vfs.DirDoc, f *vfs.FileDoc) error {
+	if d != nil {
+		return middlewares.AllowVFS(c, v, d)
+	}
+	return middlewares.AllowVFS(c, v, f)
+}
+
+// Routes sets the routing for the CMIS browser binding
+func Routes(router *echo.Group) {
+	router.GET("", Repositories)
+	router.GET("/", Repositories)
+	router.GET("/:repository", Repository)
+	router.POST("/:repository", Action)
+	router.GET("/:repository/root", Root)
+	router.POST("/:repository/root", Action)
+	router.GET("/:repository/root/*", Root)
+	router.POST("/:repository/root/*", Action)
+}
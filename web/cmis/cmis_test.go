@@ -0,0 +1,274 @@
+package cmis
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMIS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	config.GetConfig().Fs.Versioning.MaxNumberToKeep = 20
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+	_, token := setup.GetTestClient(consts.Files)
+	_, readOnlyToken := setup.GetTestClient(consts.Files + ":GET")
+	_, contactsToken := setup.GetTestClient(consts.Contacts)
+
+	ts := setup.GetTestServer("/cmis", Routes)
+	t.Cleanup(ts.Close)
+
+	createFolder := func(e *httpexpect.Expect, parentID, name string) string {
+		return e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithFormField("cmisaction", "createFolder").
+			WithFormField("objectId", parentID).
+			WithFormField("propertyId[0]", "cmis:name").
+			WithFormField("propertyValue[0]", name).
+			Expect().Status(201).
+			JSON().Object().
+			Value("succinctProperties").Object().
+			Value("cmis:objectId").String().NotEmpty().Raw()
+	}
+	createDocument := func(e *httpexpect.Expect, parentID, name, content string) string {
+		return e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithMultipart().
+			WithFormField("cmisaction", "createDocument").
+			WithFormField("objectId", parentID).
+			WithFormField("propertyId[0]", "cmis:name").
+			WithFormField("propertyValue[0]", name).
+			WithFileBytes("content", name, []byte(content)).
+			Expect().Status(201).
+			JSON().Object().
+			Value("succinctProperties").Object().
+			Value("cmis:objectId").String().NotEmpty().Raw()
+	}
+
+	t.Run("Children", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		dirID := createFolder(e, consts.RootDirID, "Children")
+		createDocument(e, dirID, "a.txt", "a")
+		createDocument(e, dirID, "b.txt", "b")
+		createFolder(e, dirID, "c")
+
+		obj := e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "children").
+			WithQuery("objectId", dirID).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.HasValue("numItems", 3)
+		obj.HasValue("hasMoreItems", false)
+		obj.Value("objects").Array().Length().IsEqual(3)
+
+		obj = e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "children").
+			WithQuery("objectId", dirID).
+			WithQuery("maxItems", 2).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.HasValue("numItems", 3)
+		obj.HasValue("hasMoreItems", true)
+		obj.Value("objects").Array().Length().IsEqual(2)
+
+		obj = e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "children").
+			WithQuery("objectId", dirID).
+			WithQuery("maxItems", 2).
+			WithQuery("skipCount", 2).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.HasValue("hasMoreItems", false)
+		obj.Value("objects").Array().Length().IsEqual(1)
+
+		// A too large maxItems is clamped
+		obj = e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "children").
+			WithQuery("objectId", dirID).
+			WithQuery("maxItems", 1000000).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("objects").Array().Length().IsEqual(3)
+
+		// The folder can also be identified by its path
+		obj = e.GET("/cmis/cozy/root/Children").
+			WithQuery("cmisselector", "children").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.HasValue("numItems", 3)
+	})
+
+	t.Run("CheckOutCheckIn", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		dirID := createFolder(e, consts.RootDirID, "Versioning")
+		fileID := createDocument(e, dirID, "report.txt", "version 1")
+
+		props := e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithFormField("cmisaction", "checkOut").
+			WithFormField("objectId", fileID).
+			Expect().Status(201).
+			JSON().Object().
+			Value("succinctProperties").Object()
+		props.HasValue("cmis:objectId", fileID+pwcSuffix)
+		props.HasValue("cmis:isPrivateWorkingCopy", true)
+
+		// A document can't be checked out twice
+		e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithFormField("cmisaction", "checkOut").
+			WithFormField("objectId", fileID).
+			Expect().Status(409).
+			JSON().Object().
+			HasValue("exception", "versioning")
+
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "checkedout").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object().
+			HasValue("numItems", 1)
+
+		props = e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithMultipart().
+			WithFormField("cmisaction", "checkIn").
+			WithFormField("objectId", fileID+pwcSuffix).
+			WithFileBytes("content", "report.txt", []byte("version 2")).
+			Expect().Status(200).
+			JSON().Object().
+			Value("succinctProperties").Object()
+		props.HasValue("cmis:objectId", fileID)
+		props.HasValue("cmis:isVersionSeriesCheckedOut", false)
+
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "content").
+			WithQuery("objectId", fileID).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Body().IsEqual("version 2")
+
+		// The previous content is kept as a version
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "versions").
+			WithQuery("objectId", fileID).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Array().Length().IsEqual(2)
+
+		e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithFormField("cmisaction", "checkIn").
+			WithFormField("objectId", fileID).
+			Expect().Status(409).
+			JSON().Object().
+			HasValue("exception", "versioning")
+
+		// The checkout can be canceled
+		e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithFormField("cmisaction", "checkOut").
+			WithFormField("objectId", fileID).
+			Expect().Status(201)
+		e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			WithFormField("cmisaction", "cancelCheckOut").
+			WithFormField("objectId", fileID+pwcSuffix).
+			Expect().Status(200)
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "checkedout").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object().
+			HasValue("numItems", 0)
+	})
+
+	t.Run("Permissions", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		dirID := createFolder(e, consts.RootDirID, "Permissions")
+		fileID := createDocument(e, dirID, "secret.txt", "secret")
+
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "children").
+			WithQuery("objectId", dirID).
+			WithHost(inst.Domain).
+			Expect().Status(401).
+			JSON().Object().
+			HasValue("exception", "permissionDenied")
+
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "object").
+			WithQuery("objectId", fileID).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+contactsToken).
+			Expect().Status(403).
+			JSON().Object().
+			HasValue("exception", "permissionDenied")
+
+		// A read-only token can list a folder, but not modify it
+		e.GET("/cmis/cozy").
+			WithQuery("cmisselector", "children").
+			WithQuery("objectId", dirID).
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+readOnlyToken).
+			Expect().Status(200).
+			JSON().Object().
+			HasValue("numItems", 1)
+
+		e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+readOnlyToken).
+			WithFormField("cmisaction", "createFolder").
+			WithFormField("objectId", dirID).
+			WithFormField("propertyId[0]", "cmis:name").
+			WithFormField("propertyValue[0]", "forbidden").
+			Expect().Status(403).
+			JSON().Object().
+			HasValue("exception", "permissionDenied")
+
+		e.POST("/cmis/cozy").
+			WithHost(inst.Domain).
+			WithHeader("Authorization", "Bearer "+readOnlyToken).
+			WithFormField("cmisaction", "checkOut").
+			WithFormField("objectId", fileID).
+			Expect().Status(403).
+			JSON().Object().
+			HasValue("exception", "permissionDenied")
+
+		checkout, err := findCheckout(inst, fileID)
+		require.NoError(t, err)
+		assert.Nil(t, checkout)
+	})
+}
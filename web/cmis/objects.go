@@ -0,0 +1,150 @@
+package cmis
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+)
+
+// Base types of the CMIS domain model that are mapped on the VFS.
+const (
+	baseTypeFolder   = "cmis:folder"
+	baseTypeDocument = "cmis:document"
+)
+
+// pwcSuffix is appended to the identifier of a file to build the identifier
+// of its private working copy (PWC) when the file is checked out.
+const pwcSuffix = ";pwc"
+
+// object is the browser binding representation of a CMIS object, with the
+// succinct form for the properties.
+type object struct {
+	Properties map[string]interface{} `json:"succinctProperties"`
+}
+
+// objectInFolder is an item of the list returned by the children selector.
+type objectInFolder struct {
+	Object *object `json:"object"`
+}
+
+// objectList is the response of the children and checkedout selectors.
+type objectList struct {
+	Objects      []objectInFolder `json:"objects"`
+	HasMoreItems bool             `json:"hasMoreItems"`
+	NumItems     int              `json:"numItems"`
+}
+
+// cmisDate converts a time to the format used by the browser binding, ie the
+// number of milliseconds since the epoch.
+func cmisDate(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func createdBy(meta *vfs.FilesCozyMetadata) string {
+	if meta == nil || meta.UploadedBy == nil {
+		return ""
+	}
+	return meta.UploadedBy.Slug
+}
+
+func folderObject(dir *vfs.DirDoc) *object {
+	props := map[string]interface{}{
+		"cmis:objectId":                  dir.DocID,
+		"cmis:baseTypeId":                baseTypeFolder,
+		"cmis:objectTypeId":              baseTypeFolder,
+		"cmis:name":                      dir.DocName,
+		"cmis:path":                      dir.Fullpath,
+		"cmis:creationDate":              cmisDate(dir.CreatedAt),
+		"cmis:lastModificationDate":      cmisDate(dir.UpdatedAt),
+		"cmis:changeToken":               dir.DocRev,
+		"cmis:allowedChildObjectTypeIds": []string{baseTypeFolder, baseTypeDocument},
+	}
+	if dir.DocID == consts.RootDirID {
+		props["cmis:name"] = ""
+		props["cmis:parentId"] = nil
+	} else {
+		props["cmis:parentId"] = dir.DirID
+	}
+	if by := createdBy(dir.CozyMetadata); by != "" {
+		props["cmis:createdBy"] = by
+	}
+	return &object{Properties: props}
+}
+
+func documentObject(file *vfs.FileDoc, checkout *Checkout) *object {
+	props := map[string]interface{}{
+		"cmis:objectId":                  file.DocID,
+		"cmis:baseTypeId":                baseTypeDocument,
+		"cmis:objectTypeId":              baseTypeDocument,
+		"cmis:name":                      file.DocName,
+		"cmis:parentId":                  file.DirID,
+		"cmis:creationDate":              cmisDate(file.CreatedAt),
+		"cmis:lastModificationDate":      cmisDate(file.UpdatedAt),
+		"cmis:changeToken":               file.DocRev,
+		"cmis:contentStreamLength":       file.ByteSize,
+		"cmis:contentStreamMimeType":     file.Mime,
+		"cmis:contentStreamFileName":     file.DocName,
+		"cmis:versionSeriesId":           file.DocID,
+		"cmis:versionLabel":              versionLabel(file.DocRev),
+		"cmis:isLatestVersion":           true,
+		"cmis:isMajorVersion":            true,
+		"cmis:isLatestMajorVersion":      true,
+		"cmis:isImmutable":               false,
+		"cmis:isPrivateWorkingCopy":      false,
+		"cmis:isVersionSeriesCheckedOut": checkout != nil,
+	}
+	if checkout != nil {
+		props["cmis:versionSeriesCheckedOutId"] = file.DocID + pwcSuffix
+		props["cmis:versionSeriesCheckedOutBy"] = checkout.CheckedOutBy
+	}
+	if by := createdBy(file.CozyMetadata); by != "" {
+		props["cmis:createdBy"] = by
+	}
+	return &object{Properties: props}
+}
+
+func pwcObject(file *vfs.FileDoc, checkout *Checkout) *object {
+	obj := documentObject(file, checkout)
+	obj.Properties["cmis:objectId"] = file.DocID + pwcSuffix
+	obj.Properties["cmis:isLatestVersion"] = false
+	obj.Properties["cmis:isPrivateWorkingCopy"] = true
+	obj.Properties["cmis:versionLabel"] = "pwc"
+	return obj
+}
+
+func versionObject(file *vfs.FileDoc, version *vfs.Version, label string) *object {
+	obj := documentObject(file, nil)
+	obj.Properties["cmis:objectId"] = version.DocID
+	obj.Properties["cmis:lastModificationDate"] = cmisDate(version.UpdatedAt)
+	obj.Properties["cmis:changeToken"] = version.DocRev
+	obj.Properties["cmis:contentStreamLength"] = version.ByteSize
+	obj.Properties["cmis:versionLabel"] = label
+	obj.Properties["cmis:isLatestVersion"] = false
+	obj.Properties["cmis:isLatestMajorVersion"] = false
+	obj.Properties["cmis:isImmutable"] = true
+	return obj
+}
+
+// versionLabel builds a human readable label from a CouchDB revision: it is
+// the generation number of the revision.
+func versionLabel(rev string) string {
+	if idx := strings.Index(rev, "-"); idx >= 0 {
+		return rev[:idx]
+	}
+	return rev
+}
+
+// splitObjectID parses an objectId from a CMIS client. It returns the
+// identifier of the file or directory, the identifier of the old version if
+// the object is a version, and if the object is a private working copy.
+func splitObjectID(objectID string) (id, versionID string, pwc bool) {
+	if strings.HasSuffix(objectID, pwcSuffix) {
+		return strings.TrimSuffix(objectID, pwcSuffix), "", true
+	}
+	if idx := strings.Index(objectID, "/"); idx > 0 {
+		return objectID[:idx], objectID, false
+	}
+	return objectID, "", false
+}
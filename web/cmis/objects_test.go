@@ -0,0 +1,55 @@
+package cmis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitObjectID(t *testing.T) {
+	id, versionID, pwc := splitObjectID("9152d568b1b4d68fea3ba8a1e4a20a5e")
+	assert.Equal(t, "9152d568b1b4d68fea3ba8a1e4a20a5e", id)
+	assert.Empty(t, versionID)
+	assert.False(t, pwc)
+
+	id, versionID, pwc = splitObjectID("9152d568b1b4d68fea3ba8a1e4a20a5e;pwc")
+	assert.Equal(t, "9152d568b1b4d68fea3ba8a1e4a20a5e", id)
+	assert.Empty(t, versionID)
+	assert.True(t, pwc)
+
+	id, versionID, pwc = splitObjectID("9152d568b1b4d68fea3ba8a1e4a20a5e/3-ce56aaf")
+	assert.Equal(t, "9152d568b1b4d68fea3ba8a1e4a20a5e", id)
+	assert.Equal(t, "9152d568b1b4d68fea3ba8a1e4a20a5e/3-ce56aaf", versionID)
+	assert.False(t, pwc)
+}
+
+func TestDocumentObject(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	file := &vfs.FileDoc{
+		DocID:     "9152d568b1b4d68fea3ba8a1e4a20a5e",
+		DocRev:    "3-ce56aaf",
+		DocName:   "report.odt",
+		DirID:     "io.cozy.files.root-dir",
+		Mime:      "application/vnd.oasis.opendocument.text",
+		ByteSize:  42,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	obj := documentObject(file, nil)
+	assert.Equal(t, "cmis:document", obj.Properties["cmis:baseTypeId"])
+	assert.Equal(t, "report.odt", obj.Properties["cmis:name"])
+	assert.Equal(t, "3", obj.Properties["cmis:versionLabel"])
+	assert.Equal(t, int64(1672628645000), obj.Properties["cmis:lastModificationDate"])
+	assert.Equal(t, false, obj.Properties["cmis:isVersionSeriesCheckedOut"])
+	assert.NotContains(t, obj.Properties, "cmis:versionSeriesCheckedOutId")
+
+	checkout := &Checkout{DocID: file.DocID, CheckedOutBy: "io.cozy.apps/drive"}
+	obj = pwcObject(file, checkout)
+	assert.Equal(t, "9152d568b1b4d68fea3ba8a1e4a20a5e;pwc", obj.Properties["cmis:objectId"])
+	assert.Equal(t, true, obj.Properties["cmis:isPrivateWorkingCopy"])
+	assert.Equal(t, true, obj.Properties["cmis:isVersionSeriesCheckedOut"])
+	assert.Equal(t, "io.cozy.apps/drive", obj.Properties["cmis:versionSeriesCheckedOutBy"])
+}
@@ -0,0 +1,311 @@
+// Package comments is for the API to write comments on the documents and the
+// files. The permissions on the comments follow the permissions on the
+// commented document.
+package comments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/comment"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultLimit = 50
+
+// maxAuthorNameLength is the maximal length of the name given by a person who
+// comments via a sharing link.
+const maxAuthorNameLength = 100
+
+type apiComment struct {
+	*comment.Comment
+}
+
+func (c *apiComment) Relationships() jsonapi.RelationshipMap { return nil }
+func (c *apiComment) Included() []jsonapi.Object             { return nil }
+func (c *apiComment) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{
+		Self: fmt.Sprintf("/comments/%s/%s/%s", c.Target.DocType, c.Target.ID, c.ID()),
+	}
+}
+
+func (c *apiComment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Comment)
+}
+
+// isShareLink returns true if the permission is for a person who has been
+// given a sharing link, and not for the owner of the instance.
+func isShareLink(pdoc *permission.Permission) bool {
+	switch pdoc.Type {
+	case permission.TypeShareByLink, permission.TypeSharePreview, permission.TypeShareInteract:
+		return true
+	}
+	return false
+}
+
+// source returns the identifier of the app, or of the sharing link, for the
+// permission of the request.
+func source(pdoc *permission.Permission) string {
+	if isShareLink(pdoc) {
+		return pdoc.ID()
+	}
+	return pdoc.SourceID
+}
+
+// allowOnTarget checks that the permission of the request allows the verb on
+// the commented document.
+func allowOnTarget(c echo.Context, v permission.Verb, target comment.Target) error {
+	inst := middlewares.GetInstance(c)
+	if target.DocType == consts.Comments {
+		return jsonapi.InvalidParameter("doctype", comment.ErrInvalidTarget)
+	}
+	if target.DocType == consts.Files {
+		dir, file, err := inst.VFS().DirOrFileByID(target.ID)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return jsonapi.NotFound(err)
+			}
+			return err
+		}
+		var fetcher vfs.Fetcher = dir
+		if file != nil {
+			fetcher = file
+		}
+		return middlewares.AllowVFS(c, v, fetcher)
+	}
+	if err := permission.CheckReadable(target.DocType); err != nil {
+		return err
+	}
+	doc := &couchdb.JSONDoc{}
+	if err := couchdb.GetDoc(inst, target.DocType, target.ID, doc); err != nil {
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return jsonapi.NotFound(errors.New("document not found"))
+		}
+		return err
+	}
+	doc.Type = target.DocType
+	return middlewares.Allow(c, v, doc)
+}
+
+// allowToComment checks that the request can read the commented document, and
+// can write comments on it: either with a permission on the comments doctype,
+// or with a permission to write on the document.
+func allowToComment(c echo.Context, target comment.Target) error {
+	if err := allowOnTarget(c, permission.GET, target); err != nil {
+		return err
+	}
+	if middlewares.AllowWholeType(c, permission.POST, consts.Comments) == nil {
+		return nil
+	}
+	return allowOnTarget(c, permission.POST, target)
+}
+
+func targetFromParams(c echo.Context) comment.Target {
+	return comment.Target{DocType: c.Param("doctype"), ID: c.Param("id")}
+}
+
+func listComments(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	target := targetFromParams(c)
+	if err := allowOnTarget(c, permission.GET, target); err != nil {
+		return err
+	}
+
+	bookmark := c.QueryParam("page[cursor]")
+	limit, err := strconv.ParseInt(c.QueryParam("page[limit]"), 10, 64)
+	if err != nil || limit <= 0 || limit > consts.MaxItemsPerPageForMango {
+		limit = defaultLimit
+	}
+	list, bookmark, err := comment.List(inst, target, int(limit), bookmark)
+	if err != nil {
+		return wrapError(err)
+	}
+	objs := make([]jsonapi.Object, len(list))
+	for i, com := range list {
+		objs[i] = &apiComment{com}
+	}
+
+	links := &jsonapi.LinksList{}
+	if bookmark != "" && len(objs) == int(limit) {
+		v := url.Values{}
+		v.Set("page[cursor]", bookmark)
+		if limit != defaultLimit {
+			v.Set("page[limit]", fmt.Sprintf("%d", limit))
+		}
+		links.Next = fmt.Sprintf("/comments/%s/%s?%s", target.DocType, target.ID, v.Encode())
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, links)
+}
+
+type createAttrs struct {
+	Content  string            `json:"content"`
+	ParentID string            `json:"parent_id"`
+	Mentions []comment.Mention `json:"mentions"`
+	Author   struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+func createComment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	target := targetFromParams(c)
+	if err := allowToComment(c, target); err != nil {
+		return err
+	}
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	var attrs createAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+
+	com := &comment.Comment{
+		Target:   target,
+		ParentID: attrs.ParentID,
+		Content:  attrs.Content,
+		Mentions: attrs.Mentions,
+		SourceID: source(pdoc),
+	}
+	if isShareLink(pdoc) {
+		if len(attrs.Author.Name) > maxAuthorNameLength {
+			return jsonapi.InvalidAttribute("author", errors.New("the name is too long"))
+		}
+		com.Author.Name = attrs.Author.Name
+	} else {
+		com.Author = ownerAuthor(inst)
+	}
+	if err := comment.Create(inst, com); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusCreated, &apiComment{com}, nil)
+}
+
+// ownerAuthor returns the author for a comment written by the owner of the
+// instance.
+func ownerAuthor(inst *instance.Instance) comment.Author {
+	author := comment.Author{Owner: true}
+	author.Name, _ = inst.SettingsPublicName()
+	author.Email, _ = inst.SettingsEMail()
+	return author
+}
+
+// getComment returns the comment of the request, after checking that it is
+// attached to the document in the URL, and that the request can comment it.
+func getComment(c echo.Context) (*comment.Comment, *permission.Permission, error) {
+	inst := middlewares.GetInstance(c)
+	target := targetFromParams(c)
+	if err := allowToComment(c, target); err != nil {
+		return nil, nil, err
+	}
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	com, err := comment.Get(inst, c.Param("comment-id"))
+	if err != nil {
+		return nil, nil, wrapError(err)
+	}
+	if com.Target != target {
+		return nil, nil, jsonapi.NotFound(errors.New("comment not found"))
+	}
+	return com, pdoc, nil
+}
+
+// isAuthor returns true if the comment has been written with the same kind of
+// permission: by the owner, or via the same sharing link.
+func isAuthor(com *comment.Comment, pdoc *permission.Permission) bool {
+	if isShareLink(pdoc) {
+		return com.SourceID == pdoc.ID()
+	}
+	return com.Author.Owner
+}
+
+type patchAttrs struct {
+	Content  *string            `json:"content"`
+	Mentions *[]comment.Mention `json:"mentions"`
+	Resolved *bool              `json:"resolved"`
+}
+
+func updateComment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	com, pdoc, err := getComment(c)
+	if err != nil {
+		return err
+	}
+	var attrs patchAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	// Everybody who can comment can resolve a thread, but only the author
+	// can edit a comment.
+	if (attrs.Content != nil || attrs.Mentions != nil) && !isAuthor(com, pdoc) {
+		return middlewares.ErrForbidden
+	}
+	patch := &comment.Patch{
+		Content:  attrs.Content,
+		Mentions: attrs.Mentions,
+		Resolved: attrs.Resolved,
+	}
+	if err := comment.Update(inst, com, patch); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiComment{com}, nil)
+}
+
+func deleteComment(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	com, pdoc, err := getComment(c)
+	if err != nil {
+		return err
+	}
+	// The owner can moderate the comments written via the sharing links.
+	if isShareLink(pdoc) && !isAuthor(com, pdoc) {
+		return middlewares.ErrForbidden
+	}
+	if err := comment.Delete(inst, com); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func wrapError(err error) error {
+	if couchdb.IsNotFoundError(err) {
+		return jsonapi.NotFound(err)
+	}
+	switch {
+	case errors.Is(err, comment.ErrMissingContent),
+		errors.Is(err, comment.ErrContentTooLong):
+		return jsonapi.InvalidAttribute("content", err)
+	case errors.Is(err, comment.ErrTooManyMentions):
+		return jsonapi.InvalidAttribute("mentions", err)
+	case errors.Is(err, comment.ErrInvalidParent):
+		return jsonapi.InvalidAttribute("parent_id", err)
+	case errors.Is(err, comment.ErrResolveReply):
+		return jsonapi.InvalidAttribute("resolved", err)
+	case errors.Is(err, comment.ErrInvalidTarget):
+		return jsonapi.BadRequest(err)
+	}
+	return err
+}
+
+// Routes sets the routing for the comments.
+func Routes(router *echo.Group) {
+	router.GET("/:doctype/:id", listComments)
+	router.POST("/:doctype/:id", createComment)
+	router.PATCH("/:doctype/:id/:comment-id", updateComment)
+	router.DELETE("/:doctype/:id/:comment-id", deleteComment)
+}
@@ -0,0 +1,268 @@
+package comments
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/comment"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/cozy/cozy-stack/web/errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComments(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+	_, token := setup.GetTestClient(consts.Files)
+	_, readOnlyToken := setup.GetTestClient(consts.Files + ":GET")
+	_, commenterToken := setup.GetTestClient(consts.Files + ":GET " + consts.Comments)
+	_, eventsToken := setup.GetTestClient("io.cozy.events")
+
+	ts := setup.GetTestServer("/comments", Routes)
+	ts.Config.Handler.(*echo.Echo).HTTPErrorHandler = errors.ErrorHandler
+	t.Cleanup(ts.Close)
+
+	fs := inst.VFS()
+	filedoc, err := vfs.NewFileDoc("report.txt", consts.RootDirID, 6, nil, "text/plain", "text", time.Now(), false, false, false, nil)
+	require.NoError(t, err)
+	f, err := fs.CreateFile(filedoc, nil)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("report"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	fileURL := "/comments/" + consts.Files + "/" + filedoc.ID()
+
+	shareLink := func(t *testing.T, verbs permission.VerbSet) string {
+		code, err := inst.MakeJWT(consts.ShareAudience, "email", "", "", time.Now())
+		require.NoError(t, err)
+		pdoc := &permission.Permission{
+			Type: permission.TypeShareByLink,
+			Permissions: permission.Set{
+				permission.Rule{Type: consts.Files, Verbs: verbs, Values: []string{filedoc.ID()}},
+			},
+			Codes: map[string]string{"email": code},
+		}
+		require.NoError(t, couchdb.CreateDoc(inst, pdoc))
+		return code
+	}
+	post := func(e *httpexpect.Expect, url, tok string, attrs map[string]interface{}) *httpexpect.Response {
+		return e.POST(url).
+			WithHeader("Authorization", "Bearer "+tok).
+			WithHeader("Content-Type", "application/vnd.api+json").
+			WithJSON(map[string]interface{}{
+				"data": map[string]interface{}{
+					"type":       consts.Comments,
+					"attributes": attrs,
+				},
+			}).
+			Expect()
+	}
+	patch := func(e *httpexpect.Expect, url, tok string, attrs map[string]interface{}) *httpexpect.Response {
+		return e.PATCH(url).
+			WithHeader("Authorization", "Bearer "+tok).
+			WithHeader("Content-Type", "application/vnd.api+json").
+			WithJSON(map[string]interface{}{
+				"data": map[string]interface{}{
+					"type":       consts.Comments,
+					"attributes": attrs,
+				},
+			}).
+			Expect()
+	}
+	jsonapiObject := func(res *httpexpect.Response) *httpexpect.Object {
+		return res.JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).Object()
+	}
+
+	var threadID string
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		obj := jsonapiObject(post(e, fileURL, token, map[string]interface{}{
+			"content": "Could you check the figures?",
+		}).Status(201))
+		data := obj.Value("data").Object()
+		data.HasValue("type", consts.Comments)
+		threadID = data.Value("id").String().NotEmpty().Raw()
+		attrs := data.Value("attributes").Object()
+		attrs.HasValue("content", "Could you check the figures?")
+		attrs.Path("$.target.id").IsEqual(filedoc.ID())
+		attrs.Path("$.author.owner").IsEqual(true)
+		data.Path("$.links.self").IsEqual(fileURL + "/" + threadID)
+
+		obj = jsonapiObject(post(e, fileURL, commenterToken, map[string]interface{}{
+			"content":   "Done, they were wrong.",
+			"parent_id": threadID,
+		}).Status(201))
+		obj.Path("$.data.attributes.parent_id").IsEqual(threadID)
+
+		obj = jsonapiObject(e.GET(fileURL).
+			WithHeader("Authorization", "Bearer "+readOnlyToken).
+			Expect().Status(200))
+		obj.Value("data").Array().Length().IsEqual(2)
+		obj.Path("$.data[0].id").IsEqual(threadID)
+
+		obj = jsonapiObject(e.GET(fileURL).
+			WithQuery("page[limit]", 1).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200))
+		obj.Value("data").Array().Length().IsEqual(1)
+		next := obj.Path("$.links.next").String().NotEmpty().Raw()
+
+		obj = jsonapiObject(e.GET(next).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200))
+		obj.Value("data").Array().Length().IsEqual(1)
+		obj.Path("$.data[0].attributes.parent_id").IsEqual(threadID)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.POST(fileURL).
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/vnd.api+json").
+			WithBytes([]byte("not json")).
+			Expect().Status(400)
+		post(e, fileURL, token, map[string]interface{}{"content": ""}).Status(422)
+		post(e, fileURL, token, map[string]interface{}{
+			"content": strings.Repeat("a", comment.MaxContentLength+1),
+		}).Status(422)
+		post(e, fileURL, token, map[string]interface{}{
+			"content":   "reply",
+			"parent_id": "not-a-comment",
+		}).Status(422)
+
+		// A comment can't be attached to another comment, or to a missing file
+		post(e, "/comments/"+consts.Comments+"/"+threadID, token, map[string]interface{}{
+			"content": "meta",
+		}).Status(422)
+		post(e, "/comments/"+consts.Files+"/not-a-file", token, map[string]interface{}{
+			"content": "missing",
+		}).Status(404)
+
+		// The comment must be attached to the document of the URL
+		otherURL := "/comments/" + consts.Files + "/" + consts.RootDirID + "/" + threadID
+		patch(e, otherURL, token, map[string]interface{}{"resolved": true}).Status(404)
+	})
+
+	t.Run("Permissions", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.GET(fileURL).Expect().Status(401)
+		post(e, fileURL, readOnlyToken, map[string]interface{}{"content": "read only"}).Status(403)
+		e.GET(fileURL).
+			WithHeader("Authorization", "Bearer "+eventsToken).
+			Expect().Status(403)
+
+		// The comments on a document follow the permissions on its doctype
+		event := couchdb.JSONDoc{Type: "io.cozy.events", M: map[string]interface{}{"title": "Meeting"}}
+		require.NoError(t, couchdb.CreateDoc(inst, &event))
+		eventURL := "/comments/io.cozy.events/" + event.ID()
+		post(e, eventURL, eventsToken, map[string]interface{}{"content": "I'll be late"}).Status(201)
+		post(e, eventURL, token, map[string]interface{}{"content": "Me too"}).Status(403)
+		jsonapiObject(e.GET(eventURL).
+			WithHeader("Authorization", "Bearer "+eventsToken).
+			Expect().Status(200)).
+			Value("data").Array().Length().IsEqual(1)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		commentURL := fileURL + "/" + threadID
+
+		obj := jsonapiObject(patch(e, commentURL, token, map[string]interface{}{
+			"content": "Could you check the figures of Q2?",
+		}).Status(200))
+		obj.Path("$.data.attributes.content").IsEqual("Could you check the figures of Q2?")
+		obj.Path("$.data.attributes.edited_at").String().NotEmpty()
+
+		// Another app of the owner can resolve the thread
+		obj = jsonapiObject(patch(e, commentURL, commenterToken, map[string]interface{}{
+			"resolved": true,
+		}).Status(200))
+		obj.Path("$.data.attributes.resolved").IsEqual(true)
+
+		// Only the threads can be resolved
+		replyID := jsonapiObject(post(e, fileURL, token, map[string]interface{}{
+			"content":   "Another reply",
+			"parent_id": threadID,
+		}).Status(201)).Path("$.data.id").String().Raw()
+		patch(e, fileURL+"/"+replyID, token, map[string]interface{}{"resolved": true}).Status(422)
+	})
+
+	t.Run("ShareByLink", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		readLink := shareLink(t, permission.Verbs(permission.GET))
+		writeLink := shareLink(t, permission.Verbs(permission.GET, permission.POST))
+		otherLink := shareLink(t, permission.Verbs(permission.GET, permission.POST))
+
+		e.GET(fileURL).
+			WithHeader("Authorization", "Bearer "+readLink).
+			Expect().Status(200)
+		post(e, fileURL, readLink, map[string]interface{}{"content": "Hi"}).Status(403)
+
+		post(e, fileURL, writeLink, map[string]interface{}{
+			"content": "Hi",
+			"author":  map[string]interface{}{"name": strings.Repeat("B", maxAuthorNameLength+1)},
+		}).Status(422)
+		obj := jsonapiObject(post(e, fileURL, writeLink, map[string]interface{}{
+			"content": "Hi",
+			"author":  map[string]interface{}{"name": "Bob"},
+		}).Status(201))
+		attrs := obj.Path("$.data.attributes").Object()
+		attrs.Path("$.author.name").IsEqual("Bob")
+		attrs.Path("$.author").Object().NotContainsKey("owner")
+		linkCommentID := obj.Path("$.data.id").String().Raw()
+		linkCommentURL := fileURL + "/" + linkCommentID
+
+		// A sharing link can't edit or delete the comments of the others
+		patch(e, fileURL+"/"+threadID, writeLink, map[string]interface{}{"content": "Hacked"}).Status(403)
+		e.DELETE(fileURL+"/"+threadID).
+			WithHeader("Authorization", "Bearer "+writeLink).
+			Expect().Status(403)
+		e.DELETE(linkCommentURL).
+			WithHeader("Authorization", "Bearer "+otherLink).
+			Expect().Status(403)
+
+		// But it can edit its own comments
+		patch(e, linkCommentURL, writeLink, map[string]interface{}{"content": "Hello"}).Status(200)
+		e.DELETE(linkCommentURL).
+			WithHeader("Authorization", "Bearer "+writeLink).
+			Expect().Status(204)
+
+		// And the owner can moderate the comments written via a link
+		otherID := jsonapiObject(post(e, fileURL, otherLink, map[string]interface{}{
+			"content": "Spam",
+		}).Status(201)).Path("$.data.id").String().Raw()
+		patch(e, fileURL+"/"+otherID, token, map[string]interface{}{"content": "Not spam"}).Status(403)
+		e.DELETE(fileURL+"/"+otherID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.DELETE(fileURL+"/"+threadID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+		e.DELETE(fileURL+"/"+threadID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+	})
+}
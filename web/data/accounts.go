@@ -124,12 +124,15 @@ func updateAccount(c echo.Context) error {
 		// This is not the expected type for a JSON doc but it should work since it
 		// will be marshalled when saved.
 		doc.M["cozyMetadata"] = CozyMetadataFromClaims(c)
+	} else {
+		setOwnership(c, &doc, false)
 	}
 
 	errUpdate := couchdb.UpdateDoc(instance, &doc)
 	if errUpdate != nil {
 		return fixErrorNoDatabaseIsWrongDoctype(errUpdate)
 	}
+	account.SyncDelegations(instance, doc)
 
 	perm, err := middlewares.GetPermission(c)
 	if err != nil {
@@ -190,7 +193,7 @@ func CozyMetadataFromClaims(c echo.Context) *metadata.CozyMetadata {
 	if claims := c.Get("claims"); claims != nil {
 		cl := claims.(permission.Claims)
 		switch cl.AudienceString() {
-		case consts.AppAudience, consts.KonnectorAudience:
+		case consts.AppAudience, consts.KonnectorAudience, consts.WebviewAudience:
 			slug = cl.Subject
 		case consts.AccessTokenAudience:
 			if perms, err := middlewares.GetPermission(c); err == nil {
@@ -220,5 +223,10 @@ func CozyMetadataFromClaims(c echo.Context) *metadata.CozyMetadata {
 		}
 	}
 
+	if user, ok := middlewares.GetOrganizationUser(c); ok {
+		cm.CreatedByUser = user.ID()
+		cm.UpdatedByUser = user.ID()
+	}
+
 	return cm
 }
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// readOnlyPosts are the routes with a POST method that don't write documents.
+var readOnlyPosts = []string{
+	"/_find",
+	"/_all_docs",
+	"/_changes",
+	"/_bulk_get",
+	"/_revs_diff",
+	"/_ensure_full_commit",
+	"/_index",
+}
+
+// checkCustomDoctype restricts the verbs that the apps, konnectors and OAuth
+// clients can use on the custom doctypes declared in the context config.
+func checkCustomDoctype(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		inst := middlewares.GetInstance(c)
+		custom, ok := doctype.Find(inst.ContextName, c.Param("doctype"))
+		if !ok {
+			return next(c)
+		}
+		pdoc, err := middlewares.GetPermission(c)
+		if err != nil || pdoc.Type == permission.TypeCLI {
+			return next(c)
+		}
+		verb := permission.Verb(c.Request().Method)
+		if verb == permission.POST {
+			for _, suffix := range readOnlyPosts {
+				if strings.HasSuffix(c.Path(), suffix) {
+					verb = permission.GET
+				}
+			}
+		}
+		if !custom.Allows(verb) {
+			return echo.NewHTTPError(http.StatusForbidden,
+				c.Request().Method+" is not allowed on "+custom.Doctype)
+		}
+		return next(c)
+	}
+}
+
+// validateDoc checks the document against the JSON schema of its doctype, if
+// there is one: registered on the instance, or from the custom doctypes of the
+// context config.
+func validateDoc(c echo.Context, doc *couchdb.JSONDoc) error {
+	inst := middlewares.GetInstance(c)
+	validator, err := doctype.FindValidator(inst, inst.ContextName, doc.DocType())
+	if err != nil || validator == nil {
+		return err
+	}
+	return validator.Validate(doc.M)
+}
+
+// schemaReport lists the existing documents of the doctype that don't match
+// its JSON schema.
+func schemaReport(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	typ := c.Param("doctype")
+	if err := permission.CheckReadable(typ); err != nil {
+		return err
+	}
+	if err := middlewares.AllowWholeType(c, permission.GET, typ); err != nil {
+		return err
+	}
+	limit := 100
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l >= 0 {
+		limit = l
+	}
+	validator, err := doctype.FindValidator(inst, inst.ContextName, typ)
+	if err != nil {
+		return err
+	}
+	if validator == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "no schema for "+typ)
+	}
+	report, err := validator.CheckDocuments(inst, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, report)
+}
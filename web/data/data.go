@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/cozy/cozy-stack/model/doctype"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -140,6 +141,11 @@ func createDoc(c echo.Context) error {
 		return err
 	}
 
+	if err := validateDoc(c, &doc); err != nil {
+		return err
+	}
+	setOwnership(c, &doc, true)
+
 	if err := couchdb.CreateDoc(instance, &doc); err != nil {
 		return err
 	}
@@ -161,6 +167,11 @@ func createNamedDoc(c echo.Context, doc couchdb.JSONDoc) error {
 		return err
 	}
 
+	if err := validateDoc(c, &doc); err != nil {
+		return err
+	}
+	setOwnership(c, &doc, true)
+
 	err = couchdb.CreateNamedDocWithDB(instance, &doc)
 	if err != nil {
 		return fixErrorNoDatabaseIsWrongDoctype(err)
@@ -175,6 +186,24 @@ func createNamedDoc(c echo.Context, doc couchdb.JSONDoc) error {
 	})
 }
 
+// setOwnership records in the cozyMetadata of the document the user of the
+// organization who creates or modifies it.
+func setOwnership(c echo.Context, doc *couchdb.JSONDoc, creation bool) {
+	user, ok := middlewares.GetOrganizationUser(c)
+	if !ok {
+		return
+	}
+	md, ok := doc.M["cozyMetadata"].(map[string]interface{})
+	if !ok {
+		md = make(map[string]interface{})
+		doc.M["cozyMetadata"] = md
+	}
+	if creation {
+		md["createdByUser"] = user.ID()
+	}
+	md["updatedByUser"] = user.ID()
+}
+
 // UpdateDoc updates the document given in the request or creates a new one with
 // the given id.
 func UpdateDoc(c echo.Context) error {
@@ -234,6 +263,11 @@ func UpdateDoc(c echo.Context) error {
 		}
 	}
 
+	if err := validateDoc(c, &doc); err != nil {
+		return err
+	}
+	setOwnership(c, &doc, false)
+
 	errUpdate := couchdb.UpdateDoc(instance, &doc)
 	if errUpdate != nil {
 		return fixErrorNoDatabaseIsWrongDoctype(errUpdate)
@@ -285,6 +319,13 @@ func DeleteDoc(c echo.Context) error {
 		return err
 	}
 
+	if c.QueryParam("trash") == "true" {
+		return trashDoc(c, &doc)
+	}
+	if err := checkLegalHold(instance); err != nil {
+		return err
+	}
+
 	err = couchdb.DeleteDoc(instance, &doc)
 	if err != nil {
 		return fixErrorNoDatabaseIsWrongDoctype(err)
@@ -310,6 +351,9 @@ func DeleteDatabase(c echo.Context) error {
 	if err := middlewares.AllowWholeType(c, permission.DELETE, doctype); err != nil {
 		return err
 	}
+	if err := checkLegalHold(instance); err != nil {
+		return err
+	}
 	if err := couchdb.DeleteDB(instance, doctype); err != nil {
 		return err
 	}
@@ -397,6 +441,9 @@ func allDocs(c echo.Context) error {
 	if err := middlewares.AllowWholeType(c, permission.GET, doctype); err != nil {
 		return err
 	}
+	if middlewares.CheckListingETag(c, doctype) {
+		return nil
+	}
 
 	if c.QueryParam("Fields") == "" && c.QueryParam("DesignDocs") == "" {
 		// Fast path, just proxy the request/response
@@ -442,6 +489,9 @@ func normalDocs(c echo.Context) error {
 	if err := middlewares.AllowWholeType(c, permission.GET, doctype); err != nil {
 		return err
 	}
+	if middlewares.CheckListingETag(c, doctype) {
+		return nil
+	}
 	skip, err := strconv.ParseInt(c.QueryParam("skip"), 10, 64)
 	if err != nil || skip < 0 {
 		skip = 0
@@ -559,6 +609,14 @@ func couchdbStyleErrorHandler(next echo.HandlerFunc) echo.HandlerFunc {
 			return c.JSON(je.Status, echo.Map{"error": je.Error()})
 		}
 
+		if ve, ok := err.(*doctype.ValidationError); ok {
+			return c.JSON(http.StatusUnprocessableEntity, echo.Map{
+				"error":  "invalid_document",
+				"reason": ve.Error(),
+				"errors": ve.Details,
+			})
+		}
+
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"error": err.Error(),
 		})
@@ -574,7 +632,7 @@ func Routes(router *echo.Group) {
 	router.GET("/_all_doctypes", allDoctypes)
 
 	// API Routes under /:doctype
-	group := router.Group("/:doctype", ValidDoctype)
+	group := router.Group("/:doctype", ValidDoctype, checkCustomDoctype)
 
 	replicationRoutes(group)
 	files.ReferencesRoutes(group)
@@ -589,6 +647,12 @@ func Routes(router *echo.Group) {
 	group.GET("/_normal_docs", normalDocs)
 	group.POST("/_index", defineIndex)
 	group.POST("/_find", findDocuments)
+	group.GET("/_schema_report", schemaReport)
+
+	group.GET("/_trash", listTrashed)
+	group.DELETE("/_trash", emptyTrash)
+	group.POST("/_trash/:docid", restoreTrashed)
+	group.DELETE("/_trash/:docid", destroyTrashed)
 
 	group.GET("/_design/:designdocid", getDesignDoc)
 	group.GET("/_design_docs", getDesignDocs)
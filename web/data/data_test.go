@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cozy/cozy-stack/model/doctype"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -987,6 +988,48 @@ func TestData(t *testing.T) {
 			ValueEqual("rev", rev)
 	})
 
+	t.Run("TrashAndRestoreDoc", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		doc := getDocForTest(Type, testInstance)
+
+		e.DELETE("/data/"+Type+"/"+doc.ID()).
+			WithQuery("rev", doc.Rev()).
+			WithQuery("trash", "true").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(400)
+
+		conf := config.GetConfig()
+		doctypes := conf.DataTrash.Doctypes
+		conf.DataTrash.Doctypes = []string{Type}
+		defer func() { conf.DataTrash.Doctypes = doctypes }()
+
+		e.DELETE("/data/"+Type+"/"+doc.ID()).
+			WithQuery("rev", doc.Rev()).
+			WithQuery("trash", "true").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object().
+			ValueEqual("trashed", true)
+
+		obj := e.GET("/data/"+Type+"/_trash").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		obj.Value("docs").Array().Length().Equal(1)
+		obj.Path("$.docs[0]._id").Equal(doc.ID())
+
+		e.POST("/data/"+Type+"/_trash/"+doc.ID()).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object().
+			Path("$.data").Object().
+			NotContainsKey("trashed")
+
+		e.POST("/data/"+Type+"/_trash/"+doc.ID()).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(400)
+	})
+
 	t.Run("DeleteDatabase", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -1005,6 +1048,74 @@ func TestData(t *testing.T) {
 			WithHeader("Authorization", "Bearer "+token).
 			Expect().Status(403)
 	})
+	t.Run("Schema", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		typ := "io.cozy.anothertype"
+		_ = couchdb.ResetDB(testInstance, typ)
+
+		invalid := getDocForTest(typ, testInstance)
+		_, err := doctype.RegisterSchema(testInstance, typ, doctype.SourceAdmin, map[string]interface{}{
+			"type":       "object",
+			"required":   []interface{}{"title"},
+			"properties": map[string]interface{}{"title": map[string]interface{}{"type": "string"}},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = doctype.UnregisterSchema(testInstance, typ, doctype.SourceAdmin) })
+
+		e.POST("/data/"+typ+"/").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{ "title": 42 }`)).
+			Expect().Status(422).
+			JSON().Object().
+			ValueEqual("error", "invalid_document").
+			Value("errors").Array().NotEmpty()
+
+		obj := e.POST("/data/"+typ+"/").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{ "title": "foo" }`)).
+			Expect().Status(201).
+			JSON().Object()
+		id := obj.Value("id").String().NotEmpty().Raw()
+		rev := obj.Value("rev").String().NotEmpty().Raw()
+
+		e.PUT("/data/"+typ+"/"+id).
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithJSON(map[string]interface{}{"_id": id, "_rev": rev}).
+			Expect().Status(422).
+			JSON().Object().
+			ValueEqual("error", "invalid_document")
+
+		e.PUT("/data/"+typ+"/"+id).
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithJSON(map[string]interface{}{"_id": id, "_rev": rev, "title": "bar"}).
+			Expect().Status(200)
+
+		// The documents created before the schema are listed in the report
+		report := e.GET("/data/"+typ+"/_schema_report").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON().Object()
+		report.ValueEqual("doctype", typ)
+		report.ValueEqual("invalid", 1)
+		report.Value("violations").Array().Length().Equal(1)
+		report.Path("$.violations[0].id").Equal(invalid.ID())
+
+		e.GET("/data/io.cozy.events/_schema_report").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+
+		// Without a schema, the documents are no longer validated
+		require.NoError(t, doctype.UnregisterSchema(testInstance, typ, doctype.SourceAdmin))
+		e.POST("/data/"+typ+"/").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{ "title": 42 }`)).
+			Expect().Status(201)
+	})
 }
 
 func getDocForTest(t string, instance *instance.Instance) *couchdb.JSONDoc {
@@ -0,0 +1,201 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// checkTrash returns an error if the documents of the doctype can't be put in
+// the trash.
+func checkTrash(inst *instance.Instance, typ string) error {
+	if !doctype.HasTrash(inst.ContextName, typ) {
+		return echo.NewHTTPError(http.StatusBadRequest, doctype.ErrNoTrash.Error())
+	}
+	return nil
+}
+
+// checkLegalHold returns a 403 error if the documents can't be destroyed
+// because the instance is under a legal hold. Putting them in the trash is
+// still allowed.
+func checkLegalHold(inst *instance.Instance) error {
+	if err := inst.CheckLegalHold(); err != nil {
+		return jsonapi.Forbidden(err)
+	}
+	return nil
+}
+
+// trashDoc puts a document in the trash, instead of destroying it. It is used
+// for DELETE /data/:doctype/:docid?trash=true.
+func trashDoc(c echo.Context, doc *couchdb.JSONDoc) error {
+	inst := middlewares.GetInstance(c)
+	if err := checkTrash(inst, doc.DocType()); err != nil {
+		return err
+	}
+	if err := doctype.Trash(inst, doc); err != nil {
+		if err == doctype.ErrAlreadyTrashed {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+	ensureCleanOldTrashedDocsTrigger(inst)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"ok":      true,
+		"id":      doc.ID(),
+		"rev":     doc.Rev(),
+		"type":    doc.DocType(),
+		"trashed": true,
+	})
+}
+
+func getTrashedDoc(c echo.Context, verb permission.Verb) (*couchdb.JSONDoc, error) {
+	inst := middlewares.GetInstance(c)
+	typ := c.Param("doctype")
+	if err := permission.CheckWritable(typ); err != nil {
+		return nil, err
+	}
+	if err := checkTrash(inst, typ); err != nil {
+		return nil, err
+	}
+	doc := &couchdb.JSONDoc{}
+	if err := couchdb.GetDoc(inst, typ, c.Get("docid").(string), doc); err != nil {
+		return nil, fixErrorNoDatabaseIsWrongDoctype(err)
+	}
+	doc.Type = typ
+	if err := middlewares.Allow(c, verb, doc); err != nil {
+		return nil, err
+	}
+	if !doctype.IsTrashed(doc) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, doctype.ErrNotTrashed.Error())
+	}
+	return doc, nil
+}
+
+// listTrashed returns the documents of the doctype that are in the trash.
+func listTrashed(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	typ := c.Param("doctype")
+	if err := permission.CheckReadable(typ); err != nil {
+		return err
+	}
+	if err := checkTrash(inst, typ); err != nil {
+		return err
+	}
+	if err := middlewares.AllowWholeType(c, permission.GET, typ); err != nil {
+		return err
+	}
+	docs, err := doctype.ListTrashed(inst, typ)
+	if err != nil {
+		return err
+	}
+	list := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		list[i] = doc.ToMapWithType()
+	}
+	return c.JSON(http.StatusOK, echo.Map{"docs": list})
+}
+
+// restoreTrashed takes a document out of the trash.
+func restoreTrashed(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	doc, err := getTrashedDoc(c, permission.PUT)
+	if err != nil {
+		return err
+	}
+	if err := doctype.Restore(inst, doc); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"ok":   true,
+		"id":   doc.ID(),
+		"rev":  doc.Rev(),
+		"type": doc.DocType(),
+		"data": doc.ToMapWithType(),
+	})
+}
+
+// destroyTrashed destroys a document that is in the trash.
+func destroyTrashed(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	doc, err := getTrashedDoc(c, permission.DELETE)
+	if err != nil {
+		return err
+	}
+	if err := checkLegalHold(inst); err != nil {
+		return err
+	}
+	if err := couchdb.DeleteDoc(inst, doc); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"ok":      true,
+		"id":      doc.ID(),
+		"rev":     doc.Rev(),
+		"type":    doc.DocType(),
+		"deleted": true,
+	})
+}
+
+// emptyTrash destroys all the documents of the doctype that are in the
+// trash.
+func emptyTrash(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	typ := c.Param("doctype")
+	if err := permission.CheckWritable(typ); err != nil {
+		return err
+	}
+	if err := checkTrash(inst, typ); err != nil {
+		return err
+	}
+	if err := middlewares.AllowWholeType(c, permission.DELETE, typ); err != nil {
+		return err
+	}
+	if err := checkLegalHold(inst); err != nil {
+		return err
+	}
+	n, err := doctype.PurgeTrashed(inst, typ, time.Time{})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, echo.Map{"ok": true, "deleted": n})
+}
+
+func ensureCleanOldTrashedDocsTrigger(inst *instance.Instance) {
+	// 1. Check if we need a trigger for clean-old-trashed-docs worker
+	if _, ok := doctype.AutoCleanTrashedAfter(inst.ContextName); !ok {
+		return
+	}
+
+	// 2. Check if the trigger already exists
+	sched := job.System()
+	infos := job.TriggerInfos{
+		Type:       "@cron",
+		WorkerType: "clean-old-trashed-docs",
+	}
+	if sched.HasTrigger(inst, infos) {
+		return
+	}
+
+	// 3. Create the trigger
+	now := time.Now()
+	hours := (now.Hour() + 12) % 24
+	infos.Arguments = fmt.Sprintf("0 %d %d * * *", now.Minute(), hours)
+	trigger, err := job.NewTrigger(inst, infos, nil)
+	if err != nil {
+		inst.Logger().Errorf("Cannot create clean-old-trashed-docs trigger: %s", err)
+		return
+	}
+	if err = sched.AddTrigger(trigger); err != nil {
+		inst.Logger().Errorf("Cannot create clean-old-trashed-docs trigger: %s", err)
+	}
+}
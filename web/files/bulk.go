@@ -0,0 +1,120 @@
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/filesbulk"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/jobs"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// BulkHandler handles POST requests on /files/_bulk
+//
+// It can be used to move, copy, trash or restore many files and directories
+// in a single request. All the operations are checked before any of them is
+// executed, and a large batch is executed by a job.
+func BulkHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fs := inst.VFS()
+
+	var msg filesbulk.Message
+	if err := json.NewDecoder(c.Request().Body).Decode(&msg); err != nil {
+		return jsonapi.BadJSON()
+	}
+	msg.UpdatedBy = nil
+	if err := msg.Check(); err != nil {
+		return wrapBulkError(err)
+	}
+	if msg.HasTrash() {
+		if err := inst.CheckLegalHold(); err != nil {
+			return WrapVfsError(err)
+		}
+	}
+
+	var errs []*jsonapi.Error
+	for i := range msg.Operations {
+		op := &msg.Operations[i]
+		dir, file, dest, err := op.Load(fs)
+		if err != nil {
+			jsonapiError := wrapBulkErrorJSONAPI(err)
+			jsonapiError.Source.Parameter = "id"
+			jsonapiError.Source.Pointer = op.ID
+			errs = append(errs, jsonapiError)
+			continue
+		}
+		if err := checkBulkPerm(c, op, dir, file, dest); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return jsonapi.DataErrorList(c, errs...)
+	}
+
+	fcm, _ := CozyMetadataFromClaims(c, false)
+	if len(fcm.UpdatedByApps) > 0 {
+		msg.UpdatedBy = fcm.UpdatedByApps[0]
+	}
+
+	if len(msg.Operations) <= filesbulk.SyncLimit {
+		report := filesbulk.Execute(inst, &msg, nil)
+		return c.JSON(http.StatusOK, report)
+	}
+
+	jobMsg, err := job.NewMessage(&msg)
+	if err != nil {
+		return err
+	}
+	j, err := job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: filesbulk.WorkerType,
+		Message:    jobMsg,
+	})
+	if err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusAccepted, jobs.NewAPIJob(j), nil)
+}
+
+// checkBulkPerm checks that the permissions allow to apply the operation on
+// the file or directory, and to write in the destination directory for a
+// move or a copy. For a copy, the source file must also be readable.
+func checkBulkPerm(c echo.Context, op *filesbulk.Operation, dir *vfs.DirDoc, file *vfs.FileDoc, dest *vfs.DirDoc) error {
+	if op.Op == filesbulk.OpCopy {
+		if err := checkPerm(c, permission.GET, nil, file); err != nil {
+			return err
+		}
+		newdoc := vfs.CreateFileDocCopy(file, dest.ID(), op.Name)
+		return checkPerm(c, permission.POST, nil, newdoc)
+	}
+	if err := checkPerm(c, permission.PATCH, dir, file); err != nil {
+		return err
+	}
+	if dest != nil {
+		return checkPerm(c, permission.PATCH, dest, nil)
+	}
+	return nil
+}
+
+func wrapBulkError(err error) error {
+	switch {
+	case errors.Is(err, filesbulk.ErrNoOperation),
+		errors.Is(err, filesbulk.ErrTooManyOperations),
+		errors.Is(err, filesbulk.ErrInvalidOperation),
+		errors.Is(err, filesbulk.ErrMissingDestination):
+		return jsonapi.InvalidParameter("operations", err)
+	}
+	return WrapVfsError(err)
+}
+
+func wrapBulkErrorJSONAPI(err error) *jsonapi.Error {
+	if errors.Is(err, filesbulk.ErrCopyDir) {
+		return jsonapi.InvalidParameter("id", err)
+	}
+	return wrapVfsErrorJSONAPI(err)
+}
@@ -0,0 +1,224 @@
+package files
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/filesbulk"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/cozy/cozy-stack/web/errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/cozy/cozy-stack/worker/filesbulk"
+)
+
+func TestBulk(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	require.NoError(t, loadLocale(), "Could not load default locale translations")
+
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+
+	config.GetConfig().Fs.URL = &url.URL{
+		Scheme: "file",
+		Host:   "localhost",
+		Path:   t.TempDir(),
+	}
+
+	testInstance := setup.GetTestInstance()
+	client, token := setup.GetTestClient(consts.Files)
+	ts := setup.GetTestServer("/files", Routes)
+	ts.Config.Handler.(*echo.Echo).HTTPErrorHandler = errors.ErrorHandler
+	t.Cleanup(ts.Close)
+
+	createDir := func(e *httpexpect.Expect, parentID, name string) string {
+		return e.POST("/files/"+parentID).
+			WithQuery("Name", name).
+			WithQuery("Type", "directory").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.id").String().NotEmpty().Raw()
+	}
+	createFile := func(e *httpexpect.Expect, parentID, name string) string {
+		return e.POST("/files/"+parentID).
+			WithQuery("Name", name).
+			WithQuery("Type", "file").
+			WithHeader("Content-Type", "text/plain").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte(name)).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.id").String().NotEmpty().Raw()
+	}
+	bulk := func(e *httpexpect.Expect, tok, body string) *httpexpect.Response {
+		return e.POST("/files/_bulk").
+			WithHeader("Authorization", "Bearer "+tok).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(body)).
+			Expect()
+	}
+	parentOf := func(e *httpexpect.Expect, id string) string {
+		return e.GET("/files/"+id).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.attributes.dir_id").String().Raw()
+	}
+	isTrashed := func(e *httpexpect.Expect, id string) bool {
+		return e.GET("/files/"+id).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.attributes.trashed").Boolean().Raw()
+	}
+
+	t.Run("Execute", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		srcID := createDir(e, consts.RootDirID, "bulk-src")
+		destID := createDir(e, consts.RootDirID, "bulk-dest")
+		movedID := createFile(e, srcID, "moved.txt")
+		copiedID := createFile(e, srcID, "copied.txt")
+		trashedID := createFile(e, srcID, "trashed.txt")
+
+		body := fmt.Sprintf(`{"operations": [
+			{"op": "move", "id": "%s", "dir_id": "%s", "name": "renamed.txt"},
+			{"op": "copy", "id": "%s", "dir_id": "%s"},
+			{"op": "trash", "id": "%s"}
+		]}`, movedID, destID, copiedID, destID, trashedID)
+		obj := bulk(e, token, body).Status(200).JSON().Object()
+		obj.HasValue("total", 3)
+		obj.HasValue("done", 3)
+		obj.HasValue("failed", 0)
+		obj.NotContainsKey("errors")
+		copyID := obj.Path("$.copies.1").String().NotEmpty().Raw()
+
+		require.Equal(t, destID, parentOf(e, movedID))
+		require.Equal(t, srcID, parentOf(e, copiedID))
+		require.Equal(t, destID, parentOf(e, copyID))
+		require.True(t, isTrashed(e, trashedID))
+
+		body = fmt.Sprintf(`{"operations": [{"op": "restore", "id": "%s"}]}`, trashedID)
+		bulk(e, token, body).Status(200).JSON().Object().HasValue("done", 1)
+		require.False(t, isTrashed(e, trashedID))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		dirID := createDir(e, consts.RootDirID, "bulk-invalid")
+		fileID := createFile(e, dirID, "file.txt")
+
+		bulk(e, token, `not json`).Status(400)
+		bulk(e, token, `{"operations": []}`).Status(422)
+		bulk(e, token, `{"operations": [{"op": "delete", "id": "`+fileID+`"}]}`).Status(422)
+		bulk(e, token, `{"operations": [{"op": "move", "id": "`+fileID+`"}]}`).Status(422)
+
+		// The directories can't be copied
+		body := fmt.Sprintf(`{"operations": [{"op": "copy", "id": "%s", "dir_id": "%s"}]}`,
+			dirID, consts.RootDirID)
+		bulk(e, token, body).Status(422)
+
+		// A batch with an invalid operation is rejected as a whole
+		body = fmt.Sprintf(`{"operations": [
+			{"op": "trash", "id": "%s"},
+			{"op": "move", "id": "not-a-file", "dir_id": "%s"}
+		]}`, fileID, consts.RootDirID)
+		errs := bulk(e, token, body).Status(404).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Value("errors").Array()
+		errs.Length().IsEqual(1)
+		errs.Value(0).Object().Path("$.source.pointer").IsEqual("not-a-file")
+		require.False(t, isTrashed(e, fileID))
+
+		// A file already in the trash can't be trashed again
+		body = fmt.Sprintf(`{"operations": [{"op": "trash", "id": "%s"}]}`, fileID)
+		bulk(e, token, body).Status(200)
+		bulk(e, token, body).Status(400)
+
+		// A directory can't be moved inside itself
+		subID := createDir(e, dirID, "sub")
+		body = fmt.Sprintf(`{"operations": [{"op": "move", "id": "%s", "dir_id": "%s"}]}`,
+			dirID, subID)
+		bulk(e, token, body).Status(412)
+	})
+
+	t.Run("Permissions", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		insideID := createDir(e, consts.RootDirID, "bulk-inside")
+		subID := createDir(e, insideID, "sub")
+		outsideID := createDir(e, consts.RootDirID, "bulk-outside")
+		insideFileID := createFile(e, insideID, "inside.txt")
+		outsideFileID := createFile(e, outsideID, "outside.txt")
+
+		scope := consts.Files + ":ALL:" + insideID
+		scoped, err := testInstance.MakeJWT(consts.AccessTokenAudience, client.ClientID, scope, "", time.Now())
+		require.NoError(t, err)
+
+		// Moving a file out of the directory, or into it from outside
+		body := fmt.Sprintf(`{"operations": [{"op": "move", "id": "%s", "dir_id": "%s"}]}`,
+			insideFileID, outsideID)
+		bulk(e, scoped, body).Status(403)
+		body = fmt.Sprintf(`{"operations": [{"op": "move", "id": "%s", "dir_id": "%s"}]}`,
+			outsideFileID, insideID)
+		bulk(e, scoped, body).Status(403)
+
+		// Copying a file from outside, or to outside
+		body = fmt.Sprintf(`{"operations": [{"op": "copy", "id": "%s", "dir_id": "%s"}]}`,
+			outsideFileID, insideID)
+		bulk(e, scoped, body).Status(403)
+		body = fmt.Sprintf(`{"operations": [{"op": "copy", "id": "%s", "dir_id": "%s"}]}`,
+			insideFileID, outsideID)
+		bulk(e, scoped, body).Status(403)
+
+		// Trashing a file outside of the directory, with an allowed operation
+		// in the same batch
+		body = fmt.Sprintf(`{"operations": [
+			{"op": "move", "id": "%s", "dir_id": "%s"},
+			{"op": "trash", "id": "%s"}
+		]}`, insideFileID, subID, outsideFileID)
+		bulk(e, scoped, body).Status(403)
+		require.Equal(t, insideID, parentOf(e, insideFileID))
+		require.False(t, isTrashed(e, outsideFileID))
+
+		// The operations inside the directory are allowed. The move fails
+		// during the execution as the copy has taken its name, but it doesn't
+		// cancel the copy.
+		body = fmt.Sprintf(`{"operations": [
+			{"op": "copy", "id": "%s", "dir_id": "%s"},
+			{"op": "move", "id": "%s", "dir_id": "%s"}
+		]}`, insideFileID, subID, insideFileID, subID)
+		obj := bulk(e, scoped, body).Status(200).JSON().Object()
+		obj.HasValue("done", 1)
+		obj.HasValue("failed", 1)
+		obj.Path("$.errors[0].index").IsEqual(1)
+		copyID := obj.Path("$.copies.0").String().NotEmpty().Raw()
+		require.Equal(t, subID, parentOf(e, copyID))
+		require.Equal(t, insideID, parentOf(e, insideFileID))
+	})
+
+	t.Run("Job", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		dirID := createDir(e, consts.RootDirID, "bulk-job")
+		fileID := createFile(e, dirID, "file.txt")
+
+		ops := make([]string, filesbulk.SyncLimit+1)
+		for i := range ops {
+			ops[i] = fmt.Sprintf(`{"op": "move", "id": "%s", "dir_id": "%s"}`, fileID, dirID)
+		}
+		body := `{"operations": [` + strings.Join(ops, ",") + `]}`
+		bulk(e, token, body).Status(202).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.attributes.worker").IsEqual(filesbulk.WorkerType)
+	})
+}
@@ -5,6 +5,7 @@ package files
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -20,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/activity"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/note"
@@ -136,9 +138,18 @@ func createFileHandler(c echo.Context, fs vfs.VFS) (*file, error) {
 	if err != nil {
 		return nil, wrapVfsError(err)
 	}
+	if meta := doc.CozyMetadata; meta != nil && meta.UploadedBy != nil && isKonnector(c) {
+		activity.RecordFilesAdded(inst, meta.UploadedBy.Slug)
+	}
 	return NewFile(doc, inst), nil
 }
 
+// isKonnector returns true if the request has been made by a konnector.
+func isKonnector(c echo.Context) bool {
+	pdoc, err := middlewares.GetPermission(c)
+	return err == nil && pdoc.Type == permission.TypeKonnector
+}
+
 func createDirHandler(c echo.Context, fs vfs.VFS) (*dir, error) {
 	path := c.QueryParam("Path")
 	tags := utils.SplitTrimString(c.QueryParam("Tags"), TagSeparator)
@@ -467,6 +478,9 @@ func DeleteFileVersionMetadata(c echo.Context) error {
 	if err = checkPerm(c, permission.DELETE, nil, file); err != nil {
 		return WrapVfsError(err)
 	}
+	if err = inst.CheckLegalHold(); err != nil {
+		return WrapVfsError(err)
+	}
 	docID := fileID + "/" + c.Param("version-id")
 	version, err := vfs.FindVersion(inst, docID)
 	if err != nil {
@@ -584,7 +598,11 @@ func ClearOldVersions(c echo.Context) error {
 		return err
 	}
 
-	fs := middlewares.GetInstance(c).VFS()
+	inst := middlewares.GetInstance(c)
+	if err := inst.CheckLegalHold(); err != nil {
+		return WrapVfsError(err)
+	}
+	fs := inst.VFS()
 	if err := fs.ClearOldVersions(); err != nil {
 		return WrapVfsError(err)
 	}
@@ -669,8 +687,11 @@ func applyPatch(c echo.Context, fs vfs.VFS, patch *docPatch) (err error) {
 	}
 
 	if patch.Delete {
+		inst := middlewares.GetInstance(c)
+		if err = inst.CheckLegalHold(); err != nil {
+			return WrapVfsError(err)
+		}
 		if dir != nil {
-			inst := middlewares.GetInstance(c)
 			err = fs.DestroyDirAndContent(dir, pushTrashJob(inst))
 		} else {
 			err = fs.DestroyFile(file)
@@ -717,10 +738,10 @@ func applyPatches(c echo.Context, fs vfs.VFS, patches []*docPatch) (errors []*js
 		}
 		var errp error
 		if patch.Delete {
-			if dir != nil {
-				inst := middlewares.GetInstance(c)
+			inst := middlewares.GetInstance(c)
+			if errp = inst.CheckLegalHold(); errp == nil && dir != nil {
 				errp = fs.DestroyDirAndContent(dir, pushTrashJob(inst))
-			} else if file != nil {
+			} else if errp == nil && file != nil {
 				errp = fs.DestroyFile(file)
 			}
 		} else if patch.Trash {
@@ -778,6 +799,9 @@ func ReadMetadataFromIDHandler(c echo.Context) error {
 	}
 
 	if dir != nil {
+		if middlewares.CheckListingETag(c, consts.Files) {
+			return nil
+		}
 		return dirData(c, http.StatusOK, dir)
 	}
 	return FileData(c, http.StatusOK, file, true, nil)
@@ -802,12 +826,16 @@ func GetChildrenHandler(c echo.Context) error {
 		return jsonapi.Errorf(http.StatusBadRequest, "cant read children of file %v", fileID)
 	}
 
+	if middlewares.CheckListingETag(c, consts.Files) {
+		return nil
+	}
 	return dirDataList(c, http.StatusOK, dir)
 }
 
 type apiDiskSize struct {
 	DocID string `json:"id,omitempty"`
 	Size  int64  `json:"size,string"`
+	Quota int64  `json:"quota,string,omitempty"`
 }
 
 func (d *apiDiskSize) ID() string                             { return d.DocID }
@@ -821,7 +849,9 @@ func (d *apiDiskSize) Included() []jsonapi.Object             { return nil }
 func (d *apiDiskSize) Links() *jsonapi.LinksList              { return nil }
 
 // GetDirSize returns the size of a directory (the sum of the size of the files
-// in this directory, including those in subdirectories).
+// in this directory, including those in subdirectories). For a directory with
+// a quota, the size is kept up-to-date incrementally, and the quota is also
+// given.
 func GetDirSize(c echo.Context) error {
 	fs := middlewares.GetInstance(c).VFS()
 	fileID := c.Param("file-id")
@@ -834,15 +864,48 @@ func GetDirSize(c echo.Context) error {
 		return err
 	}
 
-	size, err := fs.DirSize(dir)
+	size, err := vfs.DirUsageSize(fs, dir)
 	if err != nil {
 		return WrapVfsError(err)
 	}
 
-	result := apiDiskSize{DocID: fileID, Size: size}
+	result := apiDiskSize{DocID: fileID, Size: size, Quota: dir.Quota}
 	return jsonapi.Data(c, http.StatusOK, &result, nil)
 }
 
+// SetDirQuota changes the quota of a directory. It requires a permission on
+// the whole io.cozy.files doctype, as an app that can only access the
+// directory should not be able to change its quota.
+func SetDirQuota(c echo.Context) error {
+	fs := middlewares.GetInstance(c).VFS()
+	if err := middlewares.AllowWholeType(c, permission.PATCH, consts.Files); err != nil {
+		return err
+	}
+
+	var attrs struct {
+		Quota int64 `json:"quota"`
+	}
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if attrs.Quota < 0 {
+		return jsonapi.InvalidAttribute("quota", errors.New("The quota must be positive"))
+	}
+
+	dir, err := fs.DirByID(c.Param("file-id"))
+	if err != nil {
+		return WrapVfsError(err)
+	}
+	dir, err = vfs.SetDirQuota(fs, dir, attrs.Quota)
+	if errors.Is(err, os.ErrInvalid) {
+		return jsonapi.BadRequest(errors.New("A quota cannot be set on the root or the trash"))
+	}
+	if err != nil {
+		return WrapVfsError(err)
+	}
+	return dirData(c, http.StatusOK, dir)
+}
+
 // ReadMetadataFromPathHandler handles all GET requests on
 // /files/metadata aiming at getting file metadata from its path.
 func ReadMetadataFromPathHandler(c echo.Context) error {
@@ -1037,20 +1100,68 @@ func ThumbnailHandler(c echo.Context) error {
 	err = fs.ServeThumbContent(c.Response(), c.Request(), doc, format)
 	if err != nil {
 		if !errors.Is(err, os.ErrInvalid) {
-			msg, _ := job.NewMessage(thumbnail.ImageMessage{
-				File:   doc,
-				Format: format,
-			})
-			_, _ = job.System().PushJob(instance, &job.JobRequest{
-				WorkerType: "thumbnail",
-				Message:    msg,
-			})
+			j, errp := pushThumbnailJob(instance, doc, format)
+			if errp == nil && c.QueryParam("wait") == "true" && waitThumbnail(c, j) {
+				err = fs.ServeThumbContent(c.Response(), c.Request(), doc, format)
+				if err == nil {
+					return nil
+				}
+			}
 		}
 		return serveThumbnailPlaceholder(c.Response(), c.Request(), doc, format)
 	}
 	return nil
 }
 
+// thumbnailWaitTimeout is the maximal duration a request for a thumbnail
+// can wait for its generation, with the wait parameter.
+var thumbnailWaitTimeout = 10 * time.Second
+
+// thumbnailJobTTL is the duration during which a job that generates a
+// thumbnail is considered in progress. It is the timeout of the worker.
+const thumbnailJobTTL = 2 * time.Minute
+
+// pushThumbnailJob pushes a job to generate the thumbnail of a file, unless a
+// generation is already in progress for this version of the file: the
+// identifier of the job is kept in the cache to avoid pushing the same job
+// again for each request on the thumbnail.
+func pushThumbnailJob(inst *instance.Instance, doc *vfs.FileDoc, format string) (*job.Job, error) {
+	cache := config.GetConfig().CacheStorage
+	key := thumbnailJobKey(inst, doc, format)
+	if jobID, ok := cache.Get(key); ok {
+		return &job.Job{JobID: string(jobID)}, nil
+	}
+	msg, err := job.NewMessage(thumbnail.ImageMessage{
+		File:   doc,
+		Format: format,
+	})
+	if err != nil {
+		return nil, err
+	}
+	j, err := job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: "thumbnail",
+		Message:    msg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(key, []byte(j.ID()), thumbnailJobTTL)
+	return j, nil
+}
+
+func thumbnailJobKey(inst *instance.Instance, doc *vfs.FileDoc, format string) string {
+	return "thumbnail-job:" + inst.Domain + ":" + doc.ID() + ":" + doc.Rev() + ":" + format
+}
+
+// waitThumbnail waits for the job that generates a thumbnail, and returns true
+// if it has been successful. The wait is stopped if the client goes away.
+func waitThumbnail(c echo.Context, j *job.Job) bool {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), thumbnailWaitTimeout)
+	defer cancel()
+	state, err := j.WaitContext(ctx, middlewares.GetInstance(c))
+	return err == nil && state == job.Done
+}
+
 func serveThumbnailPlaceholder(res http.ResponseWriter, req *http.Request, doc *vfs.FileDoc, format string) error {
 	if !utils.IsInArray(format, vfs.ThumbnailFormatNames) {
 		return echo.NewHTTPError(http.StatusNotFound, "Format does not exist")
@@ -1399,6 +1510,9 @@ func ClearTrashHandler(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	if err = inst.CheckLegalHold(); err != nil {
+		return WrapVfsError(err)
+	}
 
 	files, _ := fs.FilesUsage()
 	versions, _ := fs.VersionsUsage()
@@ -1454,6 +1568,9 @@ func DestroyFileHandler(c echo.Context) error {
 	if err = CheckIfMatch(c, rev); err != nil {
 		return WrapVfsError(err)
 	}
+	if err = inst.CheckLegalHold(); err != nil {
+		return WrapVfsError(err)
+	}
 
 	if dir != nil {
 		err = inst.VFS().DestroyDirAndContent(dir, pushTrashJob(inst))
@@ -1555,7 +1672,7 @@ func FindFilesMango(c echo.Context) error {
 	for _, dof := range results {
 		_, f := dof.Refine()
 		if f != nil {
-			if f.Class == "image" || f.Class == "pdf" {
+			if f.Class == "image" || f.Class == "pdf" || f.Class == "video" {
 				thumbIDs = append(thumbIDs, f.ID())
 			}
 		}
@@ -1882,7 +1999,9 @@ func Routes(router *echo.Group) {
 	router.DELETE("/versions", ClearOldVersions)
 
 	router.POST("/_find", FindFilesMango)
+	router.POST("/_bulk", BulkHandler)
 	router.GET("/_changes", ChangesFeed)
+	router.GET("/_search", SearchHandler)
 
 	router.HEAD("/:file-id", HeadDirOrFile)
 
@@ -1890,6 +2009,7 @@ func Routes(router *echo.Group) {
 	router.GET("/:file-id", ReadMetadataFromIDHandler)
 	router.GET("/:file-id/relationships/contents", GetChildrenHandler)
 	router.GET("/:file-id/size", GetDirSize)
+	router.PUT("/:file-id/quota", SetDirQuota)
 
 	router.PATCH("/metadata", ModifyMetadataByPathHandler)
 	router.PATCH("/:file-id", ModifyMetadataByIDHandler)
@@ -1911,6 +2031,9 @@ func Routes(router *echo.Group) {
 	router.POST("/downloads", FileDownloadCreateHandler)
 	router.GET("/downloads/:secret/:fake-name", FileDownloadHandler)
 
+	router.POST("/:file-id/signed-url", SignedURLCreateHandler)
+	router.GET("/signed/:token/:fake-name", SignedURLDownloadHandler)
+
 	router.POST("/:file-id/relationships/referenced_by", AddReferencedHandler)
 	router.DELETE("/:file-id/relationships/referenced_by", RemoveReferencedHandler)
 
@@ -1925,6 +2048,8 @@ func Routes(router *echo.Group) {
 
 	router.DELETE("/:file-id", TrashHandler)
 	router.GET("/fsck", fsckHandler)
+
+	tusRoutes(router)
 }
 
 // WrapVfsError returns a formatted error from a golang error emitted by the vfs
@@ -1944,6 +2069,8 @@ func wrapVfsErrorJSONAPI(err error) *jsonapi.Error {
 
 func wrapVfsError(err error) *jsonapi.Error {
 	switch err {
+	case instance.ErrLegalHold:
+		return jsonapi.Forbidden(err)
 	case ErrDocTypeInvalid:
 		return jsonapi.InvalidAttribute("type", err)
 	case os.ErrExist:
@@ -1970,15 +2097,17 @@ func wrapVfsError(err error) *jsonapi.Error {
 		return jsonapi.PreconditionFailed("Content-Length", err)
 	case vfs.ErrConflict:
 		return jsonapi.Conflict(err)
-	case vfs.ErrFileInTrash, vfs.ErrNonAbsolutePath,
+	case vfs.ErrFileInTrash, vfs.ErrFileNotInTrash, vfs.ErrNonAbsolutePath,
 		vfs.ErrDirNotEmpty:
 		return jsonapi.BadRequest(err)
-	case vfs.ErrFileTooBig, vfs.ErrMaxFileSize:
+	case vfs.ErrFileTooBig, vfs.ErrMaxFileSize, vfs.ErrDirQuotaExceeded:
 		return jsonapi.Errorf(http.StatusRequestEntityTooLarge, "%s", err)
 	case vfs.ErrWrongToken:
 		return jsonapi.BadRequest(err)
 	case vfs.ErrInvalidMetadataID:
 		return jsonapi.InvalidParameter("MetadataID", err)
+	case vfs.ErrInfectedFile:
+		return jsonapi.Forbidden(err)
 	}
 	if _, ok := err.(*jsonapi.Error); !ok {
 		logger.WithNamespace("files").Warnf("Not wrapped error: %s", err)
@@ -2199,12 +2328,14 @@ func updateDirCozyMetadata(c echo.Context, dir *vfs.DirDoc) {
 		fcm.CreatedAt = dir.CreatedAt
 		fcm.CreatedByApp = ""
 		fcm.CreatedByAppVersion = ""
+		fcm.CreatedByUser = ""
 		dir.CozyMetadata = fcm
 	} else {
 		dir.CozyMetadata.UpdatedAt = fcm.UpdatedAt
 		if len(fcm.UpdatedByApps) > 0 {
 			dir.CozyMetadata.UpdatedByApp(fcm.UpdatedByApps[0])
 		}
+		dir.CozyMetadata.UpdatedByUser = fcm.UpdatedByUser
 	}
 }
 
@@ -2215,6 +2346,7 @@ func updateFileCozyMetadata(c echo.Context, file *vfs.FileDoc, setUploadFields b
 		fcm.CreatedAt = file.CreatedAt
 		fcm.CreatedByApp = ""
 		fcm.CreatedByAppVersion = ""
+		fcm.CreatedByUser = ""
 		uploadedAt := file.CreatedAt
 		fcm.UploadedAt = &uploadedAt
 		file.CozyMetadata = fcm
@@ -2225,6 +2357,7 @@ func updateFileCozyMetadata(c echo.Context, file *vfs.FileDoc, setUploadFields b
 		if len(fcm.UpdatedByApps) > 0 {
 			file.CozyMetadata.UpdatedByApp(fcm.UpdatedByApps[0])
 		}
+		file.CozyMetadata.UpdatedByUser = fcm.UpdatedByUser
 		if setUploadFields {
 			file.CozyMetadata.UploadedAt = fcm.UploadedAt
 			file.CozyMetadata.UploadedBy = fcm.UploadedBy
@@ -2258,7 +2391,7 @@ func CozyMetadataFromClaims(c echo.Context, setUploadFields bool) (*vfs.FilesCoz
 	if claims := c.Get("claims"); claims != nil {
 		cl := claims.(permission.Claims)
 		switch cl.AudienceString() {
-		case consts.AppAudience, consts.KonnectorAudience:
+		case consts.AppAudience, consts.KonnectorAudience, consts.WebviewAudience:
 			slug = cl.Subject
 		case consts.AccessTokenAudience:
 			if perms, err := middlewares.GetPermission(c); err == nil {
@@ -2294,6 +2427,11 @@ func CozyMetadataFromClaims(c echo.Context, setUploadFields bool) (*vfs.FilesCoz
 		}
 	}
 
+	if user, ok := middlewares.GetOrganizationUser(c); ok {
+		fcm.CreatedByUser = user.ID()
+		fcm.UpdatedByUser = user.ID()
+	}
+
 	if setUploadFields {
 		uploadedAt := fcm.CreatedAt
 		fcm.UploadedAt = &uploadedAt
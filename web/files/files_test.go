@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/vfs"
@@ -1897,6 +1898,40 @@ func TestFiles(t *testing.T) {
 			Expect().Status(200)
 	})
 
+	t.Run("GetDirMetadataWithETag", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		dirID := e.POST("/files/").
+			WithQuery("Name", "getdirwithetag").
+			WithQuery("Type", "directory").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.id").String().NotEmpty().Raw()
+
+		etag := e.GET("/files/"+dirID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Header("Etag").NotEmpty().Raw()
+
+		e.GET("/files/"+dirID).
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("If-None-Match", etag).
+			Expect().Status(304)
+
+		e.POST("/files/"+dirID).
+			WithQuery("Name", "newchild").
+			WithQuery("Type", "directory").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(201)
+
+		e.GET("/files/"+dirID).
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("If-None-Match", etag).
+			Expect().Status(200).
+			Header("Etag").NotEqual(etag)
+	})
+
 	t.Run("Versions", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -3236,6 +3271,57 @@ func TestFiles(t *testing.T) {
 			Header("Content-Type").Equal("image/jpeg")
 	})
 
+	t.Run("ThumbnailWait", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		rawPDF, err := os.ReadFile("../../tests/fixtures/dev-desktop.pdf")
+		require.NoError(t, err)
+
+		pdfID := e.POST("/files/").
+			WithQuery("Name", "wait-thumbnail.pdf").
+			WithQuery("Type", "file").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/pdf").
+			WithBytes(rawPDF).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().
+			Path("$.data.id").String().NotEmpty().Raw()
+
+		obj := e.GET("/files/"+pdfID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object()
+		links := obj.Path("$.data.links").Object()
+		large := links.Value("large").String().NotEmpty().Raw()
+		medium := links.Value("medium").String().NotEmpty().Raw()
+
+		// A generation in progress is not pushed again: the request waits for
+		// it, and gives the placeholder after the timeout.
+		doc, err := testInstance.VFS().FileByID(pdfID)
+		require.NoError(t, err)
+		cache := config.GetConfig().CacheStorage
+		key := thumbnailJobKey(testInstance, doc, "large")
+		cache.Set(key, []byte("job-in-progress"), time.Minute)
+		timeout := thumbnailWaitTimeout
+		thumbnailWaitTimeout = 500 * time.Millisecond
+		e.GET(large).
+			WithQuery("wait", "true").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404).
+			Header("Content-Type").Equal("image/png")
+		thumbnailWaitTimeout = timeout
+		cache.Clear(key)
+
+		// Else, the thumbnail is generated while the request waits
+		e.GET(medium).
+			WithQuery("wait", "true").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			Header("Content-Type").Equal("image/jpeg")
+	})
+
 	t.Run("GetFileByPublicLink", func(t *testing.T) {
 		var publicToken string
 		var err error
@@ -3611,6 +3697,82 @@ func TestFiles(t *testing.T) {
 		data.Value("attributes").Object().ValueEqual("size", "90")
 	})
 
+	t.Run("LegalHold", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		cfg := config.GetConfig()
+		oldDelay := cfg.Fs.Versioning.MinDelayBetweenTwoVersions
+		cfg.Fs.Versioning.MinDelayBetweenTwoVersions = 10 * time.Millisecond
+		t.Cleanup(func() { cfg.Fs.Versioning.MinDelayBetweenTwoVersions = oldDelay })
+
+		fileID := e.POST("/files/").
+			WithQuery("Name", "held").
+			WithQuery("Type", "file").
+			WithHeader("Content-Type", "text/plain").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("one")).
+			Expect().Status(201).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.id").String().NotEmpty().Raw()
+
+		time.Sleep(20 * time.Millisecond)
+
+		versionID := e.PUT("/files/"+fileID).
+			WithHeader("Content-Type", "text/plain").
+			WithHeader("Authorization", "Bearer "+token).
+			WithBytes([]byte("two")).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.relationships.old_versions.data[0].id").String().NotEmpty().Raw()
+
+		require.NoError(t, instance.SetLegalHold(testInstance, "Case 42", "jane"))
+		t.Cleanup(func() { _ = instance.ReleaseLegalHold(testInstance, "jane") })
+
+		e.DELETE("/files/"+versionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(403)
+
+		e.DELETE("/files/versions").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(403)
+
+		e.POST("/files/_bulk").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"operations": [{"op": "trash", "id": "` + fileID + `"}]}`)).
+			Expect().Status(403)
+
+		// The file can still be put in the trash, but not destroyed
+		e.DELETE("/files/"+fileID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200)
+
+		e.DELETE("/files/trash/"+fileID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(403)
+
+		e.DELETE("/files/trash").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(403)
+
+		// The version and the file are still there
+		e.GET("/files/"+fileID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.relationships.old_versions.data").Array().Length().Equal(1)
+
+		require.NoError(t, instance.ReleaseLegalHold(testInstance, "jane"))
+
+		e.DELETE("/files/"+versionID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+
+		e.DELETE("/files/trash/"+fileID).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+	})
+
 	t.Run("DeprecatePreviewAndIcon", func(t *testing.T) {
 		testutils.TODO(t, "2024-03-01", "Remove the deprecated preview and icon for PDF files")
 	})
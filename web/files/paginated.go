@@ -53,6 +53,8 @@ type fileJSON struct {
 	ReferencedBy *interface{} `json:"referenced_by,omitempty"`
 	// Include the path if asked for
 	Fullpath string `json:"path,omitempty"`
+	// Search is the relevance of the file for a full-text search
+	Search *searchMatch `json:"search,omitempty"`
 }
 
 func newDir(doc *vfs.DirDoc) *dir {
@@ -133,7 +135,7 @@ func dirData(c echo.Context, statusCode int, doc *vfs.DirDoc) error {
 	for _, child := range children {
 		_, f := child.Refine()
 		if f != nil {
-			if f.Class == "image" || f.Class == "pdf" {
+			if f.Class == "image" || f.Class == "pdf" || f.Class == "video" {
 				thumbIDs = append(thumbIDs, f.ID())
 			}
 		}
@@ -378,7 +380,7 @@ func (f *file) MarshalJSON() ([]byte, error) {
 
 func (f *file) Links() *jsonapi.LinksList {
 	links := jsonapi.LinksList{Self: "/files/" + f.doc.DocID}
-	if f.doc.Class == "image" || f.doc.Class == "pdf" {
+	if f.doc.Class == "image" || f.doc.Class == "pdf" || f.doc.Class == "video" {
 		if f.thumbSecret == "" {
 			if secret, err := vfs.GetStore().AddThumb(f.instance, f.doc.DocID); err == nil {
 				f.thumbSecret = secret
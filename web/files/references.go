@@ -153,7 +153,7 @@ func ListReferencesHandler(c echo.Context) error {
 				return err
 			}
 			if f, ok := docs[i].(*file); ok {
-				if f.doc.Class == "image" || f.doc.Class == "pdf" {
+				if f.doc.Class == "image" || f.doc.Class == "pdf" || f.doc.Class == "video" {
 					thumbIDs = append(thumbIDs, f.ID())
 				}
 			}
@@ -0,0 +1,75 @@
+package files
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/search"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultSearchLimit = 30
+	maxSearchLimit     = 100
+)
+
+// searchMatch is added to the attributes of the files in the results of a
+// full-text search.
+type searchMatch struct {
+	Score      float64  `json:"score"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// SearchHandler is the route GET /files/_search, for the full-text search in
+// the names and the content of the files.
+func SearchHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if !search.Enabled() {
+		return jsonapi.NotFound(search.ErrDisabled)
+	}
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Files); err != nil {
+		return err
+	}
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return jsonapi.InvalidParameter("q", errors.New("The query is missing"))
+	}
+	limit := defaultSearchLimit
+	if l, err := strconv.Atoi(c.QueryParam("page[limit]")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	hits, err := search.Search(inst, query, limit)
+	if err != nil {
+		return err
+	}
+	fs := inst.VFS()
+	fp := vfs.NewFilePatherWithCache(fs)
+	out := make([]jsonapi.Object, 0, len(hits))
+	for _, hit := range hits {
+		doc, err := fs.FileByID(hit.ID)
+		if err != nil || doc.Trashed {
+			// The index can be late
+			continue
+		}
+		file := NewFile(doc, inst)
+		file.IncludePath(fp)
+		file.jsonDoc.Search = &searchMatch{
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		}
+		out = append(out, file)
+	}
+	count := len(out)
+	return jsonapi.DataListWithMeta(c, http.StatusOK, jsonapi.Meta{Count: &count}, out, nil)
+}
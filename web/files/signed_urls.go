@@ -0,0 +1,240 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultSignedURLTTL is the validity of a signed URL when no TTL is given.
+	defaultSignedURLTTL = 10 * time.Minute
+	// maxSignedURLTTL is the maximal validity of a signed URL.
+	maxSignedURLTTL = 24 * time.Hour
+)
+
+var (
+	errSignedURLInvalid = errors.New("The signed URL is invalid or has expired")
+	errSignedURLRange   = errors.New("The requested range is not allowed by the signed URL")
+)
+
+// signedURLClaims are the claims of the token in a signed URL. The subject is
+// the identifier of the file.
+type signedURLClaims struct {
+	jwt.RegisteredClaims
+	// Range is the range of bytes that can be downloaded, as start-end
+	// (inclusive), or empty for the whole file.
+	Range string `json:"rng,omitempty"`
+	// IP is the only IP address allowed to use the URL, if not empty.
+	IP string `json:"ip,omitempty"`
+}
+
+type byteRange struct {
+	start, end int64 // inclusive
+}
+
+// parseByteRange parses a single range, with or without the bytes= prefix,
+// for a file of the given size. The returned range is absolute.
+func parseByteRange(s string, size int64) (*byteRange, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "bytes=")
+	if strings.Contains(s, ",") {
+		return nil, errors.New("multiple ranges are not supported")
+	}
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid range %q", s)
+	}
+	var r byteRange
+	if parts[0] == "" {
+		// Suffix range: the last n bytes
+		n, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid range %q", s)
+		}
+		if n > size {
+			n = size
+		}
+		r.start, r.end = size-n, size-1
+	} else {
+		start, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid range %q", s)
+		}
+		r.start, r.end = start, size-1
+		if parts[1] != "" {
+			end, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid range %q", s)
+			}
+			if end < r.end {
+				r.end = end
+			}
+		}
+	}
+	if r.start >= size {
+		return nil, fmt.Errorf("range %q is after the end of the file", s)
+	}
+	return &r, nil
+}
+
+func (r *byteRange) String() string {
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+func (r *byteRange) contains(other *byteRange) bool {
+	return r.start <= other.start && other.end <= r.end
+}
+
+// SignedURLCreateHandler handles POST requests on /files/:file-id/signed-url.
+// It returns a short-lived URL for downloading the file without cookies nor
+// token, optionally limited to a range of bytes and to the IP address of the
+// client.
+func SignedURLCreateHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	doc, err := inst.VFS().FileByID(c.Param("file-id"))
+	if err != nil {
+		return WrapVfsError(err)
+	}
+	if err := checkPerm(c, permission.GET, nil, doc); err != nil {
+		return err
+	}
+
+	ttl := defaultSignedURLTTL
+	if param := c.QueryParam("TTL"); param != "" {
+		ttl, err = time.ParseDuration(param)
+		if err != nil || ttl <= 0 || ttl > maxSignedURLTTL {
+			return jsonapi.InvalidParameter("TTL", fmt.Errorf("the TTL must be a duration between 0 and %s", maxSignedURLTTL))
+		}
+	}
+
+	now := time.Now()
+	claims := signedURLClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{consts.DownloadAudience},
+			Issuer:    inst.Domain,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			Subject:   doc.ID(),
+		},
+	}
+	if param := c.QueryParam("Range"); param != "" {
+		r, err := parseByteRange(param, doc.ByteSize)
+		if err != nil {
+			return jsonapi.InvalidParameter("Range", err)
+		}
+		claims.Range = r.String()
+	}
+	if c.QueryParam("BindIP") == "true" {
+		ip := middlewares.ClientIP(c)
+		if ip == nil {
+			return jsonapi.InvalidParameter("BindIP", errors.New("the IP address of the client is unknown"))
+		}
+		claims.IP = ip.String()
+	}
+
+	secret, err := inst.PickKey(consts.DownloadAudience)
+	if err != nil {
+		return err
+	}
+	token, err := crypto.NewJWT(secret, claims)
+	if err != nil {
+		return err
+	}
+
+	filename := c.QueryParam("Filename")
+	if filename == "" {
+		filename = doc.DocName
+	}
+	links := &jsonapi.LinksList{
+		Related: "/files/signed/" + token + "/" + filename,
+	}
+	return FileData(c, http.StatusOK, doc, false, links)
+}
+
+func parseSignedURLToken(inst *instance.Instance, token string) (*signedURLClaims, error) {
+	claims := &signedURLClaims{}
+	err := crypto.ParseJWT(token, func(token *jwt.Token) (interface{}, error) {
+		return inst.PickKey(consts.DownloadAudience)
+	}, claims)
+	if err != nil {
+		return nil, errSignedURLInvalid
+	}
+	if claims.Issuer != inst.Domain || len(claims.Audience) != 1 ||
+		claims.Audience[0] != consts.DownloadAudience || claims.ExpiresAt == nil {
+		return nil, errSignedURLInvalid
+	}
+	return claims, nil
+}
+
+// SignedURLDownloadHandler handles GET requests on
+// /files/signed/:token/:fake-name, to download a file via a signed URL.
+func SignedURLDownloadHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	claims, err := parseSignedURLToken(inst, c.Param("token"))
+	if err != nil {
+		return jsonapi.NewError(http.StatusForbidden, err.Error())
+	}
+	if claims.IP != "" {
+		ip := middlewares.ClientIP(c)
+		if ip == nil || ip.String() != claims.IP {
+			return jsonapi.NewError(http.StatusForbidden, errSignedURLInvalid.Error())
+		}
+	}
+
+	doc, err := inst.VFS().FileByID(claims.Subject)
+	if err != nil {
+		return WrapVfsError(err)
+	}
+	if doc.Trashed {
+		return jsonapi.NotFound(errors.New("The file is in the trash"))
+	}
+
+	req := c.Request()
+	if claims.Range != "" {
+		allowed, err := parseByteRange(claims.Range, doc.ByteSize)
+		if err != nil {
+			return jsonapi.NewError(http.StatusRequestedRangeNotSatisfiable, errSignedURLRange.Error())
+		}
+		if header := req.Header.Get("Range"); header != "" {
+			asked, err := parseByteRange(header, doc.ByteSize)
+			if err != nil || !allowed.contains(asked) {
+				return jsonapi.NewError(http.StatusRequestedRangeNotSatisfiable, errSignedURLRange.Error())
+			}
+		} else {
+			req.Header.Set("Range", "bytes="+allowed.String())
+		}
+	}
+
+	disposition := "inline"
+	if c.QueryParam("Dl") == "1" {
+		disposition = "attachment"
+	} else {
+		addCSPRuleForDirectLink(c, doc.Class, doc.Mime)
+	}
+	// A CDN can keep the response until the URL expires, except when the URL
+	// is bound to the IP address of the client.
+	cacheControl := "public"
+	if claims.IP != "" {
+		cacheControl = "private"
+	}
+	maxAge := int(time.Until(claims.ExpiresAt.Time).Seconds())
+	c.Response().Header().Set(echo.HeaderCacheControl, fmt.Sprintf("%s, max-age=%d", cacheControl, maxAge))
+	err = vfs.ServeFileContent(inst.VFS(), doc, nil, c.Param("fake-name"), disposition, req, c.Response())
+	if err != nil {
+		return WrapVfsError(err)
+	}
+	return nil
+}
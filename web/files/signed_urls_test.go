@@ -0,0 +1,73 @@
+package files
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRange(t *testing.T) {
+	r, err := parseByteRange("bytes=0-99", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "0-99", r.String())
+
+	r, err = parseByteRange("500-", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "500-999", r.String())
+
+	r, err = parseByteRange("bytes=-100", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "900-999", r.String())
+
+	r, err = parseByteRange("bytes=900-2000", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "900-999", r.String())
+
+	for _, invalid := range []string{"", "abc", "10-5", "bytes=0-1,5-6", "bytes=1000-", "-0"} {
+		_, err = parseByteRange(invalid, 1000)
+		assert.Error(t, err, invalid)
+	}
+
+	allowed := &byteRange{start: 100, end: 199}
+	assert.True(t, allowed.contains(&byteRange{start: 100, end: 199}))
+	assert.True(t, allowed.contains(&byteRange{start: 150, end: 160}))
+	assert.False(t, allowed.contains(&byteRange{start: 50, end: 160}))
+	assert.False(t, allowed.contains(&byteRange{start: 150, end: 200}))
+}
+
+func TestParseSignedURLToken(t *testing.T) {
+	inst := &instance.Instance{Domain: "alice.cozy.example", SessSecret: []byte("some-secret")}
+	other := &instance.Instance{Domain: "bob.cozy.example", SessSecret: []byte("other-secret")}
+	sign := func(audience string, ttl time.Duration) string {
+		secret, _ := inst.PickKey(consts.AppAudience)
+		token, err := crypto.NewJWT(secret, signedURLClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Audience:  jwt.ClaimStrings{audience},
+				Issuer:    inst.Domain,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+				Subject:   "file-id",
+			},
+			Range: "0-99",
+		})
+		require.NoError(t, err)
+		return token
+	}
+
+	claims, err := parseSignedURLToken(inst, sign(consts.DownloadAudience, time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "file-id", claims.Subject)
+	assert.Equal(t, "0-99", claims.Range)
+
+	_, err = parseSignedURLToken(inst, sign(consts.DownloadAudience, -time.Minute))
+	assert.Equal(t, errSignedURLInvalid, err)
+	_, err = parseSignedURLToken(inst, sign(consts.AppAudience, time.Minute))
+	assert.Equal(t, errSignedURLInvalid, err)
+	_, err = parseSignedURLToken(other, sign(consts.DownloadAudience, time.Minute))
+	assert.Equal(t, errSignedURLInvalid, err)
+}
@@ -0,0 +1,271 @@
+package files
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/upload"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// The headers of the tus protocol, see https://tus.io/protocols/resumable-upload
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,creation-with-upload,expiration,termination"
+
+	headerTusResumable   = "Tus-Resumable"
+	headerTusVersion     = "Tus-Version"
+	headerTusExtension   = "Tus-Extension"
+	headerUploadLength   = "Upload-Length"
+	headerUploadOffset   = "Upload-Offset"
+	headerUploadMetadata = "Upload-Metadata"
+	headerUploadExpires  = "Upload-Expires"
+	// headerFileID is not in the protocol: it is sent when the upload is
+	// complete, with the identifier of the created file.
+	headerFileID = "Cozy-File-Id"
+
+	tusContentType = "application/offset+octet-stream"
+)
+
+var tusExposedHeaders = strings.Join([]string{
+	headerTusResumable, headerTusVersion, headerTusExtension, headerUploadLength,
+	headerUploadOffset, headerUploadExpires, headerFileID, echo.HeaderLocation,
+}, ", ")
+
+// tusMiddleware checks the version of the protocol used by the client, and
+// adds the tus headers to the response.
+func tusMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := c.Response().Header()
+		header.Set(headerTusResumable, tusVersion)
+		header.Set(echo.HeaderAccessControlExposeHeaders, tusExposedHeaders)
+		if c.Request().Method == http.MethodOptions {
+			return next(c)
+		}
+		if c.Request().Header.Get(headerTusResumable) != tusVersion {
+			header.Set(headerTusVersion, tusVersion)
+			return jsonapi.Errorf(http.StatusPreconditionFailed, "Unsupported version of the tus protocol")
+		}
+		return next(c)
+	}
+}
+
+// TusOptionsHandler handles the OPTIONS requests for discovering the
+// features of the tus server.
+func TusOptionsHandler(c echo.Context) error {
+	header := c.Response().Header()
+	header.Set(headerTusVersion, tusVersion)
+	header.Set(headerTusExtension, tusExtensions)
+	if inst := middlewares.GetInstance(c); inst != nil {
+		if max := inst.VFS().MaxFileSize(); max > 0 {
+			header.Set("Tus-Max-Size", strconv.FormatInt(max, 10))
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// parseUploadMetadata parses the Upload-Metadata header: a comma-separated
+// list of keys and base64-encoded values.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, " ", 2)
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			value = string(decoded)
+		}
+		meta[parts[0]] = value
+	}
+	return meta, nil
+}
+
+// TusCreationHandler starts a resumable upload. The name of the file, its
+// directory, etc. are given in the Upload-Metadata header.
+func TusCreationHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	size, err := strconv.ParseInt(c.Request().Header.Get(headerUploadLength), 10, 64)
+	if err != nil || size < 0 {
+		return jsonapi.InvalidParameter(headerUploadLength, upload.ErrInvalidSize)
+	}
+	meta, err := parseUploadMetadata(c.Request().Header.Get(headerUploadMetadata))
+	if err != nil {
+		return jsonapi.InvalidParameter(headerUploadMetadata, err)
+	}
+
+	session := &upload.Session{
+		Size:       size,
+		DirID:      meta["dir_id"],
+		Name:       meta["filename"],
+		Executable: meta["executable"] == "true",
+	}
+	if session.DirID == "" {
+		session.DirID = consts.RootDirID
+	}
+	if session.Name == "" {
+		session.Name = meta["name"]
+	}
+	if filetype := meta["filetype"]; filetype != "" {
+		session.MIME, session.Class = vfs.ExtractMimeAndClass(filetype)
+	} else {
+		session.MIME, session.Class = vfs.ExtractMimeAndClassFromFilename(session.Name)
+	}
+	if tags := meta["tags"]; tags != "" {
+		session.Tags = strings.Split(tags, TagSeparator)
+	}
+	if secret := meta["metadata_id"]; secret != "" {
+		m, err := vfs.GetStore().GetMetadata(inst, secret)
+		if err != nil {
+			return WrapVfsError(err)
+		}
+		session.Metadata = *m
+	}
+	session.CozyMetadata, _ = CozyMetadataFromClaims(c, true)
+
+	doc, err := session.FileDoc()
+	if err != nil {
+		return WrapVfsError(err)
+	}
+	if err := checkPerm(c, permission.POST, nil, doc); err != nil {
+		return err
+	}
+	if err := upload.Create(inst, session); err != nil {
+		return wrapTusError(err)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderLocation, inst.PageURL("/files/upload/tus/"+session.ID, nil))
+	header.Set(headerUploadExpires, session.ExpiresAt.Format(http.TimeFormat))
+
+	// creation-with-upload: the first chunk can be sent with the request
+	if c.Request().Header.Get(echo.HeaderContentType) == tusContentType && size > 0 {
+		session, err = upload.Append(inst, session.ID, 0, c.Request().Body)
+		if session != nil {
+			setTusOffsetHeaders(c, session)
+		}
+		if err != nil {
+			return wrapTusError(err)
+		}
+	} else {
+		setTusOffsetHeaders(c, session)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// getTusSession returns the upload of the request, after checking that the
+// request can create the file.
+func getTusSession(c echo.Context) (*upload.Session, error) {
+	inst := middlewares.GetInstance(c)
+	session, err := upload.Get(inst, c.Param("upload-id"))
+	if err != nil {
+		return nil, wrapTusError(err)
+	}
+	doc, err := session.FileDoc()
+	if err != nil {
+		return nil, WrapVfsError(err)
+	}
+	if err := checkPerm(c, permission.POST, nil, doc); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func setTusOffsetHeaders(c echo.Context, session *upload.Session) {
+	header := c.Response().Header()
+	header.Set(headerUploadOffset, strconv.FormatInt(session.Offset, 10))
+	header.Set(headerUploadLength, strconv.FormatInt(session.Size, 10))
+	header.Set(headerUploadExpires, session.ExpiresAt.Format(http.TimeFormat))
+	if session.Complete() {
+		header.Set(headerFileID, session.FileID)
+	}
+}
+
+// TusHeadHandler returns the offset of an upload, so that the client can
+// resume it.
+func TusHeadHandler(c echo.Context) error {
+	session, err := getTusSession(c)
+	if err != nil {
+		return err
+	}
+	setTusOffsetHeaders(c, session)
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.NoContent(http.StatusOK)
+}
+
+// TusPatchHandler receives a chunk of the file. When the last chunk has been
+// received, the file is created in the VFS, and its identifier is sent in
+// the Cozy-File-Id header.
+func TusPatchHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if c.Request().Header.Get(echo.HeaderContentType) != tusContentType {
+		return jsonapi.Errorf(http.StatusUnsupportedMediaType, "The content type must be %s", tusContentType)
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get(headerUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return jsonapi.InvalidParameter(headerUploadOffset, upload.ErrOffsetMismatch)
+	}
+	session, err := getTusSession(c)
+	if err != nil {
+		return err
+	}
+	session, err = upload.Append(inst, session.ID, offset, c.Request().Body)
+	if session != nil {
+		setTusOffsetHeaders(c, session)
+	}
+	if err != nil {
+		return wrapTusError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// TusDeleteHandler cancels an upload.
+func TusDeleteHandler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	session, err := getTusSession(c)
+	if err != nil {
+		return err
+	}
+	if err := upload.Terminate(inst, session); err != nil {
+		return wrapTusError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func wrapTusError(err error) error {
+	switch {
+	case errors.Is(err, upload.ErrNotFound):
+		return jsonapi.NotFound(err)
+	case errors.Is(err, upload.ErrOffsetMismatch):
+		return jsonapi.Conflict(err)
+	case errors.Is(err, upload.ErrExceedsSize):
+		return jsonapi.Errorf(http.StatusRequestEntityTooLarge, "%s", err)
+	case errors.Is(err, upload.ErrInvalidSize):
+		return jsonapi.InvalidParameter(headerUploadLength, err)
+	}
+	return WrapVfsError(err)
+}
+
+// tusRoutes sets the routing for the resumable uploads.
+func tusRoutes(router *echo.Group) {
+	group := router.Group("/upload/tus", tusMiddleware)
+	group.OPTIONS("", TusOptionsHandler)
+	group.POST("", TusCreationHandler)
+	group.HEAD("/:upload-id", TusHeadHandler)
+	group.PATCH("/:upload-id", TusPatchHandler)
+	group.DELETE("/:upload-id", TusDeleteHandler)
+}
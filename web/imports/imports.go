@@ -0,0 +1,147 @@
+// Package imports is for the API to import the records of CSV, ICS and vCard
+// files as documents.
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/calendar"
+	"github.com/cozy/cozy-stack/model/contact"
+	"github.com/cozy/cozy-stack/model/dataimport"
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/contentline"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/files"
+	"github.com/cozy/cozy-stack/web/jobs"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// importsDoctype is the type of the JSON-API objects for the imports. There
+// are no documents saved with this doctype.
+const importsDoctype = "io.cozy.imports"
+
+// apiReport is the JSON-API representation of the report of a dry-run.
+type apiReport struct {
+	*dataimport.Report
+}
+
+func (r *apiReport) ID() string                             { return "" }
+func (r *apiReport) Rev() string                            { return "" }
+func (r *apiReport) DocType() string                        { return importsDoctype }
+func (r *apiReport) Clone() couchdb.Doc                     { cloned := *r; return &cloned }
+func (r *apiReport) SetID(_ string)                         {}
+func (r *apiReport) SetRev(_ string)                        {}
+func (r *apiReport) Relationships() jsonapi.RelationshipMap { return nil }
+func (r *apiReport) Included() []jsonapi.Object             { return nil }
+func (r *apiReport) Links() *jsonapi.LinksList              { return nil }
+
+// checkPermissions checks that the request can read the file, and create (or
+// update for the dedup=update mode) the documents of the doctype.
+func checkPermissions(c echo.Context, opts *dataimport.Options) error {
+	inst := middlewares.GetInstance(c)
+	if err := permission.CheckWritable(opts.Doctype); err != nil {
+		return err
+	}
+	verbs := []permission.Verb{permission.POST}
+	if opts.Dedup == dataimport.DedupUpdate {
+		verbs = append(verbs, permission.PUT)
+	}
+	for _, verb := range verbs {
+		if err := middlewares.AllowWholeType(c, verb, opts.Doctype); err != nil {
+			return err
+		}
+	}
+
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	if custom, ok := doctype.Find(inst.ContextName, opts.Doctype); ok && pdoc.Type != permission.TypeCLI {
+		for _, verb := range verbs {
+			if !custom.Allows(verb) {
+				return jsonapi.Forbidden(errors.New(string(verb) + " is not allowed on " + custom.Doctype))
+			}
+		}
+	}
+
+	file, err := inst.VFS().FileByID(opts.FileID)
+	if err != nil {
+		return files.WrapVfsError(err)
+	}
+	return middlewares.AllowVFS(c, permission.GET, file)
+}
+
+// createImport imports the records of a file: the report is returned
+// directly for a dry-run, else a job is pushed to create the documents.
+func createImport(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	var opts dataimport.Options
+	if _, err := jsonapi.Bind(c.Request().Body, &opts); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if err := opts.Check(); err != nil {
+		return wrapError(err)
+	}
+	if err := checkPermissions(c, &opts); err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		report, err := dataimport.Import(inst, &opts, nil)
+		if err != nil {
+			return wrapError(err)
+		}
+		return jsonapi.Data(c, http.StatusOK, &apiReport{report}, nil)
+	}
+
+	msg, err := job.NewMessage(&opts)
+	if err != nil {
+		return err
+	}
+	j, err := job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: dataimport.WorkerType,
+		Message:    msg,
+	})
+	if err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusAccepted, jobs.NewAPIJob(j), nil)
+}
+
+func wrapError(err error) error {
+	var parseErr *csv.ParseError
+	switch {
+	case errors.Is(err, dataimport.ErrInvalidFormat):
+		return jsonapi.InvalidAttribute("format", err)
+	case errors.Is(err, dataimport.ErrInvalidDoctype):
+		return jsonapi.InvalidAttribute("doctype", err)
+	case errors.Is(err, dataimport.ErrInvalidMapping):
+		return jsonapi.InvalidAttribute("mapping", err)
+	case errors.Is(err, dataimport.ErrInvalidSeparator):
+		return jsonapi.InvalidAttribute("separator", err)
+	case errors.Is(err, dataimport.ErrInvalidDedup):
+		return jsonapi.InvalidAttribute("dedup", err)
+	case errors.Is(err, dataimport.ErrNotAFile):
+		return jsonapi.InvalidAttribute("file_id", err)
+	case errors.Is(err, dataimport.ErrTooManyRecords),
+		errors.Is(err, dataimport.ErrFileTooLarge):
+		return jsonapi.Errorf(http.StatusRequestEntityTooLarge, "%s", err)
+	case errors.Is(err, calendar.ErrInvalidICS),
+		errors.Is(err, contact.ErrInvalidVCard),
+		errors.Is(err, contentline.ErrInvalidLine),
+		errors.As(err, &parseErr):
+		return jsonapi.Errorf(http.StatusUnprocessableEntity, "%s", err)
+	}
+	return files.WrapVfsError(err)
+}
+
+// Routes sets the routing for the imports.
+func Routes(router *echo.Group) {
+	router.POST("", createImport)
+}
@@ -8,12 +8,14 @@ import (
 	"github.com/cozy/cozy-stack/model/account"
 	"github.com/cozy/cozy-stack/model/app"
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
+	"github.com/cozy/cozy-stack/model/doctype"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/stack"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
 	"github.com/labstack/echo/v4"
 )
 
@@ -313,6 +315,30 @@ func indexesFixer(c echo.Context) error {
 	if err := lifecycle.DefineViewsAndIndex(inst); err != nil {
 		return err
 	}
+	if err := doctype.DefineIndexes(inst, inst.ContextName); err != nil {
+		return err
+	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// passphraseKDFFixer reports the KDF used for the passphrase hash of the
+// instance, and if it is not the one configured for its context. The hash
+// is migrated on the next successful login of the user.
+func passphraseKDFFixer(c echo.Context) error {
+	domain := c.Param("domain")
+	inst, err := lifecycle.GetInstance(domain)
+	if err != nil {
+		return err
+	}
+
+	params := inst.PassphraseHashParams()
+	kdf := crypto.HashKDF(inst.PassphraseHash)
+	return c.JSON(http.StatusOK, echo.Map{
+		"domain":   inst.Domain,
+		"context":  inst.ContextName,
+		"kdf":      kdf,
+		"expected": params.KDF,
+		"outdated": kdf != "" && kdf != params.KDF,
+	})
+}
@@ -13,17 +13,21 @@ import (
 	"time"
 
 	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/diskusage"
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/oauth"
 	"github.com/cozy/cozy-stack/model/session"
 	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/crypto"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
 	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/cozy-stack/web/middlewares"
 	"github.com/labstack/echo/v4"
 )
 
@@ -87,6 +91,13 @@ func createHandler(c echo.Context) error {
 		}
 		opts.MagicLink = &ml
 	}
+	if organization := c.QueryParam("Organization"); organization != "" {
+		org, err := strconv.ParseBool(organization)
+		if err != nil {
+			return wrapError(err)
+		}
+		opts.Organization = &org
+	}
 	if layout := c.QueryParam("SwiftLayout"); layout != "" {
 		opts.SwiftLayout, err = strconv.Atoi(layout)
 		if err != nil {
@@ -133,6 +144,7 @@ func createHandler(c echo.Context) error {
 	}
 	in.CLISecret = nil
 	in.OAuthSecret = nil
+	in.OIDCKey = nil
 	in.SessSecret = nil
 	in.PassphraseHash = nil
 	return jsonapi.Data(c, http.StatusCreated, &apiInstance{in}, nil)
@@ -146,6 +158,7 @@ func showHandler(c echo.Context) error {
 	}
 	in.CLISecret = nil
 	in.OAuthSecret = nil
+	in.OIDCKey = nil
 	in.SessSecret = nil
 	in.PassphraseHash = nil
 	return jsonapi.Data(c, http.StatusOK, &apiInstance{in}, nil)
@@ -184,6 +197,9 @@ func modifyHandler(c echo.Context) error {
 	if magicLink, err := strconv.ParseBool(c.QueryParam("MagicLink")); err == nil {
 		opts.MagicLink = &magicLink
 	}
+	if organization, err := strconv.ParseBool(c.QueryParam("Organization")); err == nil {
+		opts.Organization = &organization
+	}
 	// Deprecated: the Debug parameter should no longer be used, but is kept
 	// for compatibility.
 	if debug, err := strconv.ParseBool(c.QueryParam("Debug")); err == nil {
@@ -252,10 +268,22 @@ func listHandler(c echo.Context) error {
 		return wrapError(err)
 	}
 
+	// The accounts limited to some contexts can only see their instances
+	if account, ok := middlewares.GetAdminAccount(c); ok && len(account.Contexts) > 0 {
+		filtered := instances[:0]
+		for _, in := range instances {
+			if account.HasContext(in.ContextName) {
+				filtered = append(filtered, in)
+			}
+		}
+		instances = filtered
+	}
+
 	objs := make([]jsonapi.Object, len(instances))
 	for i, in := range instances {
 		in.CLISecret = nil
 		in.OAuthSecret = nil
+		in.OIDCKey = nil
 		in.SessSecret = nil
 		in.PassphraseHash = nil
 		objs[i] = &apiInstance{in}
@@ -557,6 +585,26 @@ type diskUsageResult struct {
 	Trashed       int64 `json:"trashed,string,omitempty"`
 }
 
+func diskUsageTrend(c echo.Context) error {
+	domain := c.Param("domain")
+	instance, err := lifecycle.GetInstance(domain)
+	if err != nil {
+		return err
+	}
+	days := 0
+	if param := c.QueryParam("days"); param != "" {
+		days, err = strconv.Atoi(param)
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	trend, err := diskusage.GetTrend(instance, days)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, trend)
+}
+
 func diskUsage(c echo.Context) error {
 	domain := c.Param("domain")
 	instance, err := lifecycle.GetInstance(domain)
@@ -598,6 +646,27 @@ func diskUsage(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+type dedupResult struct {
+	Enabled bool `json:"enabled"`
+	*vfs.DedupStats
+}
+
+func dedupStats(c echo.Context) error {
+	domain := c.Param("domain")
+	instance, err := lifecycle.GetInstance(domain)
+	if err != nil {
+		return err
+	}
+	stats, err := vfs.GetDedupStats(instance)
+	if err != nil {
+		return err
+	}
+	fsURL := config.FsURL()
+	enabled := config.GetConfig().Fs.Deduplication &&
+		(fsURL.Scheme == config.SchemeSwift || fsURL.Scheme == config.SchemeSwiftSecure)
+	return c.JSON(http.StatusOK, &dedupResult{Enabled: enabled, DedupStats: stats})
+}
+
 func showPrefix(c echo.Context) error {
 	domain := c.Param("domain")
 
@@ -674,6 +743,8 @@ func wrapError(err error) error {
 		return jsonapi.BadRequest(err)
 	case instance.ErrBadTOSVersion:
 		return jsonapi.BadRequest(err)
+	case instance.ErrLegalHold:
+		return jsonapi.Forbidden(err)
 	}
 	return err
 }
@@ -714,6 +785,24 @@ func Routes(router *echo.Group) {
 	router.POST("/:domain/session_code/check", checkSessionCode)
 	router.POST("/:domain/email_verified_code", createEmailVerifiedCode)
 	router.DELETE("/:domain/sessions", cleanSessions)
+	router.POST("/:domain/tombstones/purge", purgeTombstones)
+	router.GET("/:domain/network-access", getNetworkAccess)
+	router.PUT("/:domain/network-access", putNetworkAccess)
+	router.DELETE("/:domain/network-access", deleteNetworkAccess)
+	router.GET("/:domain/network-access/check", checkNetworkAccess)
+	router.GET("/:domain/legal-hold", getLegalHold)
+	router.PUT("/:domain/legal-hold", putLegalHold)
+	router.DELETE("/:domain/legal-hold", deleteLegalHold)
+	router.GET("/:domain/legal-hold/content", listHeldContent)
+
+	// Warm standby replication
+	router.GET("/:domain/standby", getStandby)
+	router.POST("/:domain/standby", enableStandby)
+	router.DELETE("/:domain/standby", disableStandby)
+	router.PUT("/:domain/standby/instance", receiveStandbyInstance)
+	router.POST("/:domain/standby/docs/:doctype", receiveStandbyDocs)
+	router.PUT("/:domain/standby/files/:file-id", receiveStandbyFile)
+	router.POST("/:domain/standby/promote", promoteStandby)
 
 	// Advanced features for instances
 	router.GET("/:domain/last-activity", lastActivity)
@@ -721,6 +810,8 @@ func Routes(router *echo.Group) {
 	router.GET("/:domain/exports/:export-id/data", dataExporter)
 	router.POST("/:domain/import", importer)
 	router.GET("/:domain/disk-usage", diskUsage)
+	router.GET("/:domain/disk-usage/trend", diskUsageTrend)
+	router.GET("/:domain/dedup", dedupStats)
 	router.GET("/:domain/prefix", showPrefix)
 	router.GET("/:domain/swift-prefix", getSwiftBucketName)
 	router.GET("/:domain/sharings/:sharing-id/unxor/:doc-id", unxorID)
@@ -734,6 +825,24 @@ func Routes(router *echo.Group) {
 	router.GET("/contexts/:name", showContext)
 	router.GET("/with-app-version/:slug/:version", appVersion)
 
+	// Mails
+	router.GET("/:domain/mails/dead", listDeadMails)
+	router.POST("/:domain/mails/dead/:id/retry", retryDeadMail)
+	router.GET("/mails/suppressions/:context", listMailSuppressions)
+	router.PUT("/mails/suppressions/:context/:email", addMailSuppression)
+	router.DELETE("/mails/suppressions/:context/:email", deleteMailSuppression)
+	router.GET("/mails/dkim", listDKIMKeys)
+	router.POST("/mails/dkim/:domain", generateDKIMKey)
+	router.POST("/mails/dkim/:domain/activate", activateDKIMKey)
+	router.DELETE("/mails/dkim/:domain", deleteDKIMKey)
+	router.GET("/mails/templates", listMailTemplates)
+	router.GET("/mails/templates/:context/:name", previewMailTemplate)
+
+	// JSON schemas
+	router.GET("/:domain/schemas", listSchemas)
+	router.PUT("/:domain/schemas/:doctype", putSchema)
+	router.DELETE("/:domain/schemas/:doctype", deleteSchema)
+
 	// Checks
 	router.GET("/:domain/fsck", fsckHandler)
 	router.POST("/:domain/checks/triggers", checkTriggers)
@@ -745,4 +854,5 @@ func Routes(router *echo.Group) {
 	router.POST("/:domain/fixers/orphan-account", orphanAccountFixer)
 	router.POST("/:domain/fixers/service-triggers", serviceTriggersFixer)
 	router.POST("/:domain/fixers/indexes", indexesFixer)
+	router.POST("/:domain/fixers/passphrase-kdf", passphraseKDFFixer)
 }
@@ -0,0 +1,141 @@
+package instances
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type heldFile struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func adminAccountName(c echo.Context) string {
+	if account, ok := middlewares.GetAdminAccount(c); ok {
+		return account.Name
+	}
+	return ""
+}
+
+func getLegalHold(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if inst.LegalHold == nil {
+		return jsonapi.NotFound(errors.New("The instance is not under a legal hold"))
+	}
+	return c.JSON(http.StatusOK, inst.LegalHold)
+}
+
+func putLegalHold(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if body.Reason == "" {
+		return jsonapi.InvalidParameter("reason", errors.New("The reason is missing"))
+	}
+	if err := instance.SetLegalHold(inst, body.Reason, adminAccountName(c)); err != nil {
+		return wrapError(err)
+	}
+	return c.JSON(http.StatusOK, inst.LegalHold)
+}
+
+func deleteLegalHold(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := instance.ReleaseLegalHold(inst, adminAccountName(c)); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listHeldContent returns the files and documents in the trash, whose purge
+// has been deferred by the legal hold.
+func listHeldContent(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if inst.LegalHold == nil {
+		return jsonapi.NotFound(errors.New("The instance is not under a legal hold"))
+	}
+
+	fs := inst.VFS()
+	trash, err := fs.DirByID(consts.TrashDirID)
+	if err != nil {
+		return err
+	}
+	files := []heldFile{}
+	iter := fs.DirIterator(trash, &vfs.IteratorOptions{ByFetch: 1000})
+	for {
+		dir, file, err := iter.Next()
+		if errors.Is(err, vfs.ErrIteratorDone) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if dir != nil {
+			files = append(files, heldFile{
+				ID:        dir.ID(),
+				Type:      consts.DirType,
+				Name:      dir.DocName,
+				UpdatedAt: dir.UpdatedAt,
+			})
+		} else if file != nil {
+			files = append(files, heldFile{
+				ID:        file.ID(),
+				Type:      consts.FileType,
+				Name:      file.DocName,
+				Size:      file.ByteSize,
+				UpdatedAt: file.UpdatedAt,
+			})
+		}
+	}
+
+	documents := make(map[string][]string)
+	for _, typ := range doctype.TrashDoctypes(inst.ContextName) {
+		docs, err := doctype.ListTrashed(inst, typ)
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			continue
+		}
+		ids := make([]string, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.ID()
+		}
+		documents[typ] = ids
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"legal_hold": inst.LegalHold,
+		"files":      files,
+		"documents":  documents,
+	})
+}
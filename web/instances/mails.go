@@ -0,0 +1,230 @@
+package instances
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/mailqueue"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/dkim"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/worker/mails"
+	"github.com/labstack/echo/v4"
+)
+
+func listDeadMails(c echo.Context) error {
+	inst, err := instance.GetFromCouch(c.Param("domain"))
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	msgs, err := mailqueue.ListDeadLetters(inst)
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		msg.Raw = nil
+	}
+	if msgs == nil {
+		msgs = []*mailqueue.Message{}
+	}
+	return c.JSON(http.StatusOK, msgs)
+}
+
+func retryDeadMail(c echo.Context) error {
+	inst, err := instance.GetFromCouch(c.Param("domain"))
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	msg, err := mailqueue.Get(inst, c.Param("id"))
+	if err != nil {
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return jsonapi.NotFound(err)
+		}
+		return err
+	}
+	err = mailqueue.Retry(c.Request().Context(), inst, msg)
+	if err == mailqueue.ErrNotDead {
+		return jsonapi.BadRequest(err)
+	}
+	msg.Raw = nil
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, msg)
+	}
+	return c.JSON(http.StatusOK, msg)
+}
+
+func listMailSuppressions(c echo.Context) error {
+	docs, err := mailqueue.ListSuppressions(c.Param("context"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, docs)
+}
+
+func addMailSuppression(c echo.Context) error {
+	err := mailqueue.Suppress(c.Param("context"), c.Param("email"), mailqueue.ReasonManual)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func deleteMailSuppression(c echo.Context) error {
+	err := mailqueue.Unsuppress(c.Param("context"), c.Param("email"))
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+type dkimKeyPairView struct {
+	Selector    string     `json:"selector"`
+	Algorithm   string     `json:"algorithm"`
+	DNSName     string     `json:"dns_name"`
+	DNSRecord   string     `json:"dns_record"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+}
+
+type dkimKeyView struct {
+	Domain    string           `json:"domain"`
+	Active    *dkimKeyPairView `json:"active,omitempty"`
+	Pending   *dkimKeyPairView `json:"pending,omitempty"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// newDKIMKeyView returns the DKIM configuration of a domain without the
+// private keys, but with the DNS records to publish.
+func newDKIMKeyView(key *mailqueue.DKIMKey) *dkimKeyView {
+	view := &dkimKeyView{Domain: key.Domain, UpdatedAt: key.UpdatedAt}
+	pairView := func(pair *mailqueue.DKIMKeyPair) *dkimKeyPairView {
+		if pair == nil {
+			return nil
+		}
+		record, _ := pair.DNSRecord()
+		return &dkimKeyPairView{
+			Selector:    pair.Selector,
+			Algorithm:   pair.Algorithm,
+			DNSName:     pair.DNSName(key.Domain),
+			DNSRecord:   record,
+			CreatedAt:   pair.CreatedAt,
+			ActivatedAt: pair.ActivatedAt,
+		}
+	}
+	view.Active = pairView(key.Active)
+	view.Pending = pairView(key.Pending)
+	return view
+}
+
+func listDKIMKeys(c echo.Context) error {
+	keys, err := mailqueue.ListDKIMKeys()
+	if err != nil {
+		return err
+	}
+	views := make([]*dkimKeyView, len(keys))
+	for i, key := range keys {
+		views[i] = newDKIMKeyView(key)
+	}
+	return c.JSON(http.StatusOK, views)
+}
+
+func generateDKIMKey(c echo.Context) error {
+	var body struct {
+		Algorithm string `json:"algorithm"`
+		Selector  string `json:"selector"`
+	}
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&body); err != nil {
+			return jsonapi.BadRequest(err)
+		}
+	}
+	key, err := mailqueue.GenerateDKIMKey(c.Param("domain"), body.Algorithm, body.Selector)
+	if err == dkim.ErrUnknownAlgorithm {
+		return jsonapi.InvalidParameter("algorithm", err)
+	}
+	if err == mailqueue.ErrInvalidSelector {
+		return jsonapi.InvalidParameter("selector", err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, newDKIMKeyView(key))
+}
+
+func activateDKIMKey(c echo.Context) error {
+	key, err := mailqueue.ActivateDKIMKey(c.Param("domain"))
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	if err == mailqueue.ErrNoPendingKey {
+		return jsonapi.BadRequest(err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, newDKIMKeyView(key))
+}
+
+func deleteDKIMKey(c echo.Context) error {
+	err := mailqueue.DeleteDKIMKey(c.Param("domain"))
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func listMailTemplates(c echo.Context) error {
+	return c.JSON(http.StatusOK, mails.TemplateNames())
+}
+
+// previewMailTemplate renders a mail template with sample data. The
+// templates overridden by the context are used, and the instance given by
+// the domain query parameter is used for the URLs, if any.
+func previewMailTemplate(c echo.Context) error {
+	inst := &instance.Instance{
+		Domain:      "jean.cozy.example",
+		ContextName: c.Param("context"),
+		Locale:      consts.DefaultLocale,
+	}
+	if domain := c.QueryParam("domain"); domain != "" {
+		var err error
+		inst, err = instance.GetFromCouch(domain)
+		if err != nil {
+			return jsonapi.NotFound(err)
+		}
+		if inst.ContextName != c.Param("context") {
+			return jsonapi.BadRequest(errors.New("The instance is not in this context"))
+		}
+	}
+
+	subject, parts, err := mails.PreviewMail(inst, c.Param("name"), c.QueryParam("layout"), c.QueryParam("locale"))
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	contentType := "text/html"
+	if c.QueryParam("format") == "text" {
+		contentType = "text/plain"
+	}
+	for _, part := range parts {
+		if part.Type == contentType {
+			c.Response().Header().Set("X-Mail-Subject", mime.QEncoding.Encode("utf-8", subject))
+			if contentType == "text/html" {
+				return c.HTML(http.StatusOK, part.Body)
+			}
+			return c.String(http.StatusOK, part.Body)
+		}
+	}
+	return c.JSON(http.StatusUnprocessableEntity, echo.Map{
+		"error": "The template cannot be rendered in " + contentType,
+	})
+}
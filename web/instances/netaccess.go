@@ -0,0 +1,89 @@
+package instances
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
+	"github.com/labstack/echo/v4"
+)
+
+func getNetworkAccess(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	policy := inst.NetworkAccess
+	if policy == nil {
+		policy = &netaccess.Policy{}
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func putNetworkAccess(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	var policy netaccess.Policy
+	if err := json.NewDecoder(c.Request().Body).Decode(&policy); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if err := policy.Validate(); err != nil {
+		return jsonapi.BadRequest(err)
+	}
+	if policy.IsEmpty() {
+		inst.NetworkAccess = nil
+	} else {
+		inst.NetworkAccess = &policy
+	}
+	if err := instance.Update(inst); err != nil {
+		return wrapError(err)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func deleteNetworkAccess(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if inst.NetworkAccess != nil {
+		inst.NetworkAccess = nil
+		if err := instance.Update(inst); err != nil {
+			return wrapError(err)
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// checkNetworkAccess tells if an IP address can access the instance, with
+// the rules of its context and its own rules.
+func checkNetworkAccess(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	ip := netaccess.ParseIP(c.QueryParam("ip"))
+	if ip == nil {
+		return jsonapi.BadRequest(errors.New("Invalid ip parameter"))
+	}
+	category := netaccess.Category(c.QueryParam("category"))
+	switch category {
+	case "":
+		category = netaccess.CategoryDefault
+	case netaccess.CategoryDefault, netaccess.CategoryAuth, netaccess.CategoryPublic:
+	default:
+		return jsonapi.BadRequest(errors.New("Invalid category parameter"))
+	}
+	allowed := inst.CheckNetworkAccess(category, ip) == nil
+	return c.JSON(http.StatusOK, echo.Map{
+		"ip":       ip.String(),
+		"category": category,
+		"allowed":  allowed,
+	})
+}
@@ -0,0 +1,64 @@
+package instances
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+func listSchemas(c echo.Context) error {
+	inst, err := instance.GetFromCouch(c.Param("domain"))
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	schemas, err := doctype.ListSchemas(inst)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, schemas)
+}
+
+func putSchema(c echo.Context) error {
+	inst, err := instance.GetFromCouch(c.Param("domain"))
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	typ := c.Param("doctype")
+	if err := permission.CheckWritable(typ); err != nil {
+		return jsonapi.InvalidParameter("doctype", err)
+	}
+	var schema map[string]interface{}
+	if err := json.NewDecoder(c.Request().Body).Decode(&schema); err != nil {
+		return jsonapi.BadJSON()
+	}
+	doc, err := doctype.RegisterSchema(inst, typ, doctype.SourceAdmin, schema)
+	if errors.Is(err, doctype.ErrInvalidSchema) {
+		return jsonapi.InvalidAttribute("schema", err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+func deleteSchema(c echo.Context) error {
+	inst, err := instance.GetFromCouch(c.Param("domain"))
+	if err != nil {
+		return jsonapi.NotFound(err)
+	}
+	err = doctype.UnregisterSchema(inst, c.Param("doctype"), doctype.SourceAdmin)
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,98 @@
+package instances
+
+import (
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/doctype"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/cozy/cozy-stack/web/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemas(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+
+	ts := setup.GetTestServer("/instances", Routes)
+	ts.Config.Handler.(*echo.Echo).HTTPErrorHandler = errors.ErrorHandler
+	t.Cleanup(ts.Close)
+
+	const typ = "com.example.notes"
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"title"},
+	}
+
+	t.Run("PutListDelete", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.GET("/instances/" + inst.Domain + "/schemas").
+			Expect().Status(200).
+			JSON().Array().IsEmpty()
+
+		obj := e.PUT("/instances/" + inst.Domain + "/schemas/" + typ).
+			WithJSON(schema).
+			Expect().Status(200).
+			JSON().Object()
+		obj.HasValue("_id", typ)
+		obj.HasValue("source", doctype.SourceAdmin)
+
+		arr := e.GET("/instances/" + inst.Domain + "/schemas").
+			Expect().Status(200).
+			JSON().Array()
+		arr.Length().IsEqual(1)
+		arr.Value(0).Object().HasValue("_id", typ)
+
+		// The validator is refreshed when the schema is updated
+		v, err := doctype.FindValidator(inst, inst.ContextName, typ)
+		require.NoError(t, err)
+		require.NotNil(t, v)
+		assert.Error(t, v.Validate(map[string]interface{}{"author": "jane"}))
+
+		e.PUT("/instances/" + inst.Domain + "/schemas/" + typ).
+			WithJSON(map[string]interface{}{"required": []interface{}{"author"}}).
+			Expect().Status(200)
+		v, err = doctype.FindValidator(inst, inst.ContextName, typ)
+		require.NoError(t, err)
+		require.NotNil(t, v)
+		assert.NoError(t, v.Validate(map[string]interface{}{"author": "jane"}))
+
+		e.DELETE("/instances/" + inst.Domain + "/schemas/" + typ).
+			Expect().Status(204)
+		v, err = doctype.FindValidator(inst, inst.ContextName, typ)
+		require.NoError(t, err)
+		assert.Nil(t, v)
+
+		e.DELETE("/instances/" + inst.Domain + "/schemas/" + typ).
+			Expect().Status(404)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.PUT("/instances/" + inst.Domain + "/schemas/" + typ).
+			WithJSON(map[string]interface{}{"type": 42}).
+			Expect().Status(422)
+
+		e.PUT("/instances/" + inst.Domain + "/schemas/io.cozy.jobs").
+			WithJSON(schema).
+			Expect().Status(422)
+
+		e.PUT("/instances/"+inst.Domain+"/schemas/"+typ).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"type":`)).
+			Expect().Status(400)
+
+		e.GET("/instances/no-such-domain.example.org/schemas").
+			Expect().Status(404)
+	})
+}
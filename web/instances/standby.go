@@ -0,0 +1,123 @@
+package instances
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/standby"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+func wrapStandbyError(err error) error {
+	switch {
+	case errors.Is(err, standby.ErrNotConfigured):
+		return jsonapi.BadRequest(err)
+	case errors.Is(err, standby.ErrNotEnabled):
+		return jsonapi.NotFound(err)
+	case errors.Is(err, standby.ErrNotStandby):
+		return jsonapi.Conflict(err)
+	}
+	return wrapError(err)
+}
+
+// The routes below are used on the primary stack.
+
+func getStandby(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	state, err := standby.GetState(inst)
+	if err != nil {
+		return wrapStandbyError(err)
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+func enableStandby(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	state, err := standby.Enable(inst)
+	if err != nil {
+		return wrapStandbyError(err)
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+func disableStandby(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := standby.Disable(inst); err != nil {
+		return wrapStandbyError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// The routes below are used on the secondary stack.
+
+func receiveStandbyInstance(c echo.Context) error {
+	var doc map[string]interface{}
+	if err := json.NewDecoder(c.Request().Body).Decode(&doc); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if _, err := standby.ReceiveInstance(c.Param("domain"), doc); err != nil {
+		return wrapStandbyError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func receiveStandbyDocs(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	var body struct {
+		Docs []map[string]interface{} `json:"docs"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
+		return jsonapi.BadJSON()
+	}
+	missing, err := standby.ApplyDocs(inst, c.Param("doctype"), body.Docs)
+	if err != nil {
+		return wrapStandbyError(err)
+	}
+	if missing == nil {
+		missing = []string{}
+	}
+	return c.JSON(http.StatusOK, echo.Map{"missing": missing})
+}
+
+func receiveStandbyFile(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	header, err := base64.StdEncoding.DecodeString(c.Request().Header.Get(standby.DocHeader))
+	if err != nil || len(header) == 0 {
+		return jsonapi.BadRequest(errors.New("The document of the file is missing"))
+	}
+	err = standby.ApplyFile(inst, c.Param("file-id"), header, c.Request().Body)
+	if err != nil {
+		return wrapStandbyError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func promoteStandby(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := standby.Promote(inst); err != nil {
+		return wrapStandbyError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
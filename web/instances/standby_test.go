@@ -0,0 +1,200 @@
+package instances
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/standby"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/cozy/cozy-stack/web/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/cozy/cozy-stack/worker/standby"
+)
+
+func TestStandby(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+
+	ts := setup.GetTestServer("/instances", Routes)
+	ts.Config.Handler.(*echo.Echo).HTTPErrorHandler = errors.ErrorHandler
+	t.Cleanup(ts.Close)
+
+	conf := config.GetConfig()
+	oldStandby := conf.Standby
+	t.Cleanup(func() { conf.Standby = oldStandby })
+
+	t.Run("Primary", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		url := "/instances/" + inst.Domain + "/standby"
+
+		e.GET(url).Expect().Status(404)
+		e.DELETE(url).Expect().Status(404)
+
+		conf.Standby.URL = ""
+		e.POST(url).Expect().Status(400)
+
+		// The secondary is the same stack here: the replication fails as the
+		// instance is not a standby copy, and the error is kept in the state.
+		conf.Standby.URL = ts.URL
+		conf.Standby.Interval = time.Hour
+		obj := e.POST(url).Expect().Status(200).JSON().Object()
+		triggerID := obj.Value("trigger_id").String().NotEmpty().Raw()
+
+		obj = e.POST(url).Expect().Status(200).JSON().Object()
+		obj.HasValue("trigger_id", triggerID)
+
+		require.Eventually(t, func() bool {
+			state, err := standby.GetState(inst)
+			return err == nil && state.LastError != ""
+		}, 10*time.Second, 100*time.Millisecond)
+		obj = e.GET(url).Expect().Status(200).JSON().Object()
+		obj.HasValue("trigger_id", triggerID)
+		obj.Value("last_error").String().Contains("409")
+
+		e.DELETE(url).Expect().Status(204)
+		e.GET(url).Expect().Status(404)
+	})
+
+	t.Run("Secondary", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+		domain := "standby-" + hex.EncodeToString(crypto.GenerateRandomBytes(4)) + ".cozy.localhost"
+		url := "/instances/" + domain + "/standby"
+		t.Cleanup(func() { _ = lifecycle.Destroy(domain) })
+
+		// The document of the instance, as sent by the primary stack
+		buf, err := json.Marshal(inst)
+		require.NoError(t, err)
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf, &doc))
+		delete(doc, "_rev")
+		doc["_id"] = hex.EncodeToString(crypto.GenerateRandomBytes(16))
+		doc["domain"] = domain
+		doc["prefix"] = "cozy" + hex.EncodeToString(crypto.GenerateRandomBytes(16))
+
+		e.PUT(url + "/instance").
+			WithBytes([]byte("not json")).
+			Expect().Status(400)
+		e.PUT(url + "/instance").WithJSON(doc).Expect().Status(204)
+		e.PUT(url + "/instance").WithJSON(doc).Expect().Status(204)
+
+		copied, err := lifecycle.GetInstance(domain)
+		require.NoError(t, err)
+		assert.True(t, standby.IsStandby(copied))
+		assert.Equal(t, instance.BlockedStandby.Code, copied.BlockingReason)
+
+		// The live instances can't be overwritten
+		e.PUT("/instances/" + inst.Domain + "/standby/instance").
+			WithJSON(doc).
+			Expect().Status(409)
+		e.POST("/instances/" + inst.Domain + "/standby/docs/" + consts.Contacts).
+			WithJSON(map[string]interface{}{"docs": []interface{}{}}).
+			Expect().Status(409)
+
+		// The documents are written with their revisions
+		contact := map[string]interface{}{
+			"_id":  "standby-contact",
+			"_rev": "2-bbb",
+			"_revisions": map[string]interface{}{
+				"start": 2,
+				"ids":   []interface{}{"bbb", "aaa"},
+			},
+			"fullname": "Bob",
+		}
+		e.POST(url + "/docs/" + consts.Contacts).
+			WithBytes([]byte("not json")).
+			Expect().Status(400)
+		e.POST(url + "/docs/" + consts.Contacts).
+			WithJSON(map[string]interface{}{"docs": []interface{}{contact}}).
+			Expect().Status(200).
+			JSON().Object().
+			Value("missing").Array().IsEmpty()
+		var saved couchdb.JSONDoc
+		require.NoError(t, couchdb.GetDoc(copied, consts.Contacts, "standby-contact", &saved))
+		assert.Equal(t, "2-bbb", saved.Rev())
+		assert.Equal(t, "Bob", saved.M["fullname"])
+
+		// The content of the new files is asked to the primary
+		content := "foo"
+		sum := md5.Sum([]byte(content))
+		file := map[string]interface{}{
+			"_id":  "standby-file",
+			"_rev": "1-aaa",
+			"_revisions": map[string]interface{}{
+				"start": 1,
+				"ids":   []interface{}{"aaa"},
+			},
+			"type":       consts.FileType,
+			"name":       "foo.txt",
+			"dir_id":     consts.RootDirID,
+			"size":       "3",
+			"md5sum":     base64.StdEncoding.EncodeToString(sum[:]),
+			"mime":       "text/plain",
+			"class":      "text",
+			"created_at": time.Now().Format(time.RFC3339),
+			"updated_at": time.Now().Format(time.RFC3339),
+		}
+		e.POST(url + "/docs/" + consts.Files).
+			WithJSON(map[string]interface{}{"docs": []interface{}{file}}).
+			Expect().Status(200).
+			JSON().Object().
+			Value("missing").Array().ContainsOnly("standby-file")
+
+		header, err := json.Marshal(file)
+		require.NoError(t, err)
+		e.PUT(url + "/files/standby-file").
+			WithBytes([]byte(content)).
+			Expect().Status(400)
+		e.PUT(url+"/files/standby-file").
+			WithHeader(standby.DocHeader, base64.StdEncoding.EncodeToString(header)).
+			WithBytes([]byte(content)).
+			Expect().Status(204)
+
+		copied, err = lifecycle.GetInstance(domain)
+		require.NoError(t, err)
+		fileDoc, err := copied.VFS().FileByID("standby-file")
+		require.NoError(t, err)
+		assert.Equal(t, "foo.txt", fileDoc.DocName)
+		f, err := copied.VFS().OpenFile(fileDoc)
+		require.NoError(t, err)
+		got, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		assert.Equal(t, content, string(got))
+
+		// The same document is now up-to-date
+		e.POST(url + "/docs/" + consts.Files).
+			WithJSON(map[string]interface{}{"docs": []interface{}{file}}).
+			Expect().Status(200).
+			JSON().Object().
+			Value("missing").Array().IsEmpty()
+
+		// Promoting the copy unblocks it, and it is no longer a standby
+		e.POST("/instances/" + inst.Domain + "/standby/promote").Expect().Status(409)
+		e.POST(url + "/promote").Expect().Status(204)
+		copied, err = lifecycle.GetInstance(domain)
+		require.NoError(t, err)
+		assert.False(t, copied.Blocked)
+		e.PUT(url + "/instance").WithJSON(doc).Expect().Status(409)
+		e.POST(url + "/promote").Expect().Status(409)
+	})
+}
@@ -0,0 +1,65 @@
+package instances
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/tombstone"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+// purgeTombstones purges the tombstones of the deleted documents of an
+// instance. With dry-run, the tombstones are only counted, and the reports
+// are returned immediately. Else, a job is pushed for the purge.
+func purgeTombstones(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return wrapError(err)
+	}
+
+	msg := &tombstone.Message{}
+	for _, doctype := range strings.Split(c.QueryParam("doctypes"), ",") {
+		if doctype == "" {
+			continue
+		}
+		if err := tombstone.CheckDoctype(doctype); err != nil {
+			return jsonapi.InvalidParameter("doctypes", err)
+		}
+		msg.Doctypes = append(msg.Doctypes, doctype)
+	}
+	if dryRun := c.QueryParam("dry-run"); dryRun != "" {
+		msg.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return jsonapi.InvalidParameter("dry-run", err)
+		}
+	}
+	if !msg.DryRun {
+		if err := inst.CheckLegalHold(); err != nil {
+			return wrapError(err)
+		}
+	}
+	if msg.DryRun {
+		reports, err := tombstone.PurgeAll(inst, msg)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, reports)
+	}
+
+	m, err := job.NewMessage(msg)
+	if err != nil {
+		return err
+	}
+	j, err := job.System().PushJob(inst, &job.JobRequest{
+		WorkerType: tombstone.WorkerType,
+		Message:    m,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusAccepted, j)
+}
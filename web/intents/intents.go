@@ -81,6 +81,7 @@ func createIntent(c echo.Context) error {
 	intent.SetID("")
 	intent.SetRev("")
 	intent.Services = nil
+	intent.Transfer = nil
 	if err = intent.Save(instance); err != nil {
 		return wrapIntentsError(err)
 	}
@@ -128,6 +129,9 @@ func wrapIntentsError(err error) error {
 	if couchdb.IsNotFoundError(err) {
 		return jsonapi.NotFound(err)
 	}
+	if wrapped := wrapTransferError(err); wrapped != nil {
+		return wrapped
+	}
 	return jsonapi.InternalServerError(err)
 }
 
@@ -135,4 +139,13 @@ func wrapIntentsError(err error) error {
 func Routes(router *echo.Group) {
 	router.POST("", createIntent)
 	router.GET("/:id", getIntent)
+
+	router.GET("/:id/transfer", getTransfer)
+	router.DELETE("/:id/transfer", deleteTransfer)
+	router.PUT("/:id/transfer/parts/:name", uploadPart)
+	router.GET("/:id/transfer/parts/:name", downloadPart)
+	router.POST("/:id/transfer/files", addFiles)
+	router.GET("/:id/transfer/files/:file-id", downloadFile)
+	router.POST("/:id/transfer/documents", addDocuments)
+	router.GET("/:id/transfer/documents/:doctype", getDocuments)
 }
@@ -0,0 +1,210 @@
+package intents
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/intent"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type transferIDs struct {
+	Doctype string   `json:"doctype,omitempty"`
+	IDs     []string `json:"ids"`
+}
+
+// fetchIntent loads the intent from the id param, and checks that the
+// requesting app is its client (if asClient is true) or one of its services.
+func fetchIntent(c echo.Context, asClient, asService bool) (*instance.Instance, *intent.Intent, error) {
+	inst := middlewares.GetInstance(c)
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusForbidden)
+	}
+	in := &intent.Intent{}
+	if err = couchdb.GetDoc(inst, consts.Intents, c.Param("id"), in); err != nil {
+		return nil, nil, wrapIntentsError(err)
+	}
+	if asClient && pdoc.SourceID == in.Client {
+		return inst, in, nil
+	}
+	if asService {
+		for _, service := range in.Services {
+			if pdoc.SourceID == consts.Apps+"/"+service.Slug {
+				return inst, in, nil
+			}
+		}
+	}
+	return nil, nil, echo.NewHTTPError(http.StatusForbidden)
+}
+
+func getTransfer(c echo.Context) error {
+	_, in, err := fetchIntent(c, true, true)
+	if err != nil {
+		return err
+	}
+	if err := in.CheckTransfer(); err != nil {
+		return wrapIntentsError(err)
+	}
+	return c.JSON(http.StatusOK, in.Transfer)
+}
+
+func deleteTransfer(c echo.Context) error {
+	inst, in, err := fetchIntent(c, true, true)
+	if err != nil {
+		return err
+	}
+	if err := in.EndTransfer(inst); err != nil {
+		return wrapIntentsError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func uploadPart(c echo.Context) error {
+	inst, in, err := fetchIntent(c, true, false)
+	if err != nil {
+		return err
+	}
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+	part, err := in.AddPart(inst, c.Param("name"), contentType, c.Request().Body)
+	if err != nil {
+		return wrapIntentsError(err)
+	}
+	return c.JSON(http.StatusCreated, part)
+}
+
+func downloadPart(c echo.Context) error {
+	inst, in, err := fetchIntent(c, false, true)
+	if err != nil {
+		return err
+	}
+	part, r, err := in.OpenPart(inst, c.Param("name"))
+	if err != nil {
+		return wrapIntentsError(err)
+	}
+	defer r.Close()
+	c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(part.Size, 10))
+	return c.Stream(http.StatusOK, part.ContentType, r)
+}
+
+func addFiles(c echo.Context) error {
+	inst, in, err := fetchIntent(c, true, false)
+	if err != nil {
+		return err
+	}
+	var body transferIDs
+	if err := c.Bind(&body); err != nil {
+		return jsonapi.BadRequest(err)
+	}
+	if len(body.IDs) == 0 {
+		return jsonapi.InvalidParameter("ids", errors.New("ids is missing"))
+	}
+	fs := inst.VFS()
+	for _, id := range body.IDs {
+		file, err := fs.FileByID(id)
+		if err != nil {
+			return wrapIntentsError(err)
+		}
+		if err := middlewares.AllowVFS(c, permission.GET, file); err != nil {
+			return err
+		}
+	}
+	if err := in.AddFiles(inst, body.IDs); err != nil {
+		return wrapIntentsError(err)
+	}
+	return c.JSON(http.StatusOK, in.Transfer)
+}
+
+func downloadFile(c echo.Context) error {
+	inst, in, err := fetchIntent(c, false, true)
+	if err != nil {
+		return err
+	}
+	if err := in.CheckTransfer(); err != nil {
+		return wrapIntentsError(err)
+	}
+	id := c.Param("file-id")
+	if !in.HasFile(id) {
+		return jsonapi.NotFound(errors.New("The file is not in the payload of the intent"))
+	}
+	fs := inst.VFS()
+	file, err := fs.FileByID(id)
+	if err != nil {
+		return wrapIntentsError(err)
+	}
+	return vfs.ServeFileContent(fs, file, nil, "", "attachment", c.Request(), c.Response())
+}
+
+func addDocuments(c echo.Context) error {
+	inst, in, err := fetchIntent(c, true, false)
+	if err != nil {
+		return err
+	}
+	var body transferIDs
+	if err := c.Bind(&body); err != nil {
+		return jsonapi.BadRequest(err)
+	}
+	if body.Doctype == "" {
+		return jsonapi.InvalidParameter("doctype", errors.New("doctype is missing"))
+	}
+	if len(body.IDs) == 0 {
+		return jsonapi.InvalidParameter("ids", errors.New("ids is missing"))
+	}
+	if err := permission.CheckReadable(body.Doctype); err != nil {
+		return err
+	}
+	for _, id := range body.IDs {
+		var doc couchdb.JSONDoc
+		if err := couchdb.GetDoc(inst, body.Doctype, id, &doc); err != nil {
+			return wrapIntentsError(err)
+		}
+		doc.Type = body.Doctype
+		if err := middlewares.Allow(c, permission.GET, &doc); err != nil {
+			return err
+		}
+	}
+	if err := in.AddDocuments(inst, body.Doctype, body.IDs); err != nil {
+		return wrapIntentsError(err)
+	}
+	return c.JSON(http.StatusOK, in.Transfer)
+}
+
+func getDocuments(c echo.Context) error {
+	inst, in, err := fetchIntent(c, false, true)
+	if err != nil {
+		return err
+	}
+	docs, err := in.GetDocuments(inst, c.Param("doctype"))
+	if err != nil {
+		return wrapIntentsError(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"docs": docs})
+}
+
+func wrapTransferError(err error) error {
+	switch {
+	case errors.Is(err, intent.ErrTransferExpired):
+		return jsonapi.NewError(http.StatusGone, err.Error())
+	case errors.Is(err, intent.ErrTransferTooLarge):
+		return jsonapi.NewError(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, intent.ErrInvalidPartName):
+		return jsonapi.BadRequest(err)
+	case errors.Is(err, intent.ErrNoTransfer),
+		errors.Is(err, intent.ErrPartNotFound),
+		errors.Is(err, os.ErrNotExist):
+		return jsonapi.NotFound(err)
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package jobs
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -28,18 +30,33 @@ import (
 	"github.com/labstack/echo/v4"
 
 	// import workers
+	_ "github.com/cozy/cozy-stack/worker/antivirus"
+	_ "github.com/cozy/cozy-stack/worker/appdata"
 	_ "github.com/cozy/cozy-stack/worker/archive"
+	_ "github.com/cozy/cozy-stack/worker/bi"
+	_ "github.com/cozy/cozy-stack/worker/dataimport"
+	_ "github.com/cozy/cozy-stack/worker/diskusage"
 	"github.com/cozy/cozy-stack/worker/exec"
+	_ "github.com/cozy/cozy-stack/worker/filesbulk"
+	_ "github.com/cozy/cozy-stack/worker/gdrive"
+	_ "github.com/cozy/cozy-stack/worker/konnectorstats"
 	_ "github.com/cozy/cozy-stack/worker/log"
 	_ "github.com/cozy/cozy-stack/worker/mails"
 	_ "github.com/cozy/cozy-stack/worker/migrations"
 	_ "github.com/cozy/cozy-stack/worker/moves"
 	_ "github.com/cozy/cozy-stack/worker/notes"
 	_ "github.com/cozy/cozy-stack/worker/oauth"
+	_ "github.com/cozy/cozy-stack/worker/photos"
 	_ "github.com/cozy/cozy-stack/worker/push"
+	_ "github.com/cozy/cozy-stack/worker/qualification"
+	_ "github.com/cozy/cozy-stack/worker/reminder"
+	_ "github.com/cozy/cozy-stack/worker/report"
+	_ "github.com/cozy/cozy-stack/worker/search"
 	_ "github.com/cozy/cozy-stack/worker/share"
 	_ "github.com/cozy/cozy-stack/worker/sms"
+	_ "github.com/cozy/cozy-stack/worker/standby"
 	_ "github.com/cozy/cozy-stack/worker/thumbnail"
+	_ "github.com/cozy/cozy-stack/worker/tombstone"
 	_ "github.com/cozy/cozy-stack/worker/trash"
 )
 
@@ -72,14 +89,24 @@ type (
 		t *job.TriggerInfos
 		s *job.TriggerState
 	}
+	// apiBIConnection is the status of the webhooks for a BI connection
+	apiBIConnection struct {
+		s *bi.ConnectionStatus
+	}
+	// apiJobsStats are the statistics about the durations of the jobs of a
+	// worker type
+	apiJobsStats struct {
+		s *job.Stats
+	}
 	apiTriggerRequest struct {
-		Type            string          `json:"type"`
-		Arguments       string          `json:"arguments"`
-		WorkerType      string          `json:"worker"`
-		Message         json.RawMessage `json:"message"`
-		WorkerArguments json.RawMessage `json:"worker_arguments"`
-		Debounce        string          `json:"debounce"`
-		Options         *job.JobOptions `json:"options"`
+		Type            string           `json:"type"`
+		Arguments       string           `json:"arguments"`
+		WorkerType      string           `json:"worker"`
+		Message         json.RawMessage  `json:"message"`
+		WorkerArguments json.RawMessage  `json:"worker_arguments"`
+		Debounce        string           `json:"debounce"`
+		Options         *job.JobOptions  `json:"options"`
+		Filter          *job.EventFilter `json:"filter"`
 	}
 )
 
@@ -110,6 +137,11 @@ func (q apiQueue) Fetch(field string) []string {
 	}
 }
 
+// NewAPIJob creates a jsonapi representation of a job.
+func NewAPIJob(j *job.Job) jsonapi.Object {
+	return apiJob{j}
+}
+
 // NewAPITrigger creates a jsonapi representation of a trigger.
 func NewAPITrigger(infos *job.TriggerInfos, inst *instance.Instance) jsonapi.Object {
 	return apiTrigger{infos, inst}
@@ -151,8 +183,43 @@ func (t apiTriggerState) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.s)
 }
 
+func (b apiBIConnection) ID() string                             { return strconv.Itoa(b.s.ConnectionID) }
+func (b apiBIConnection) Rev() string                            { return "" }
+func (b apiBIConnection) DocType() string                        { return consts.BIWebhooks }
+func (b apiBIConnection) Clone() couchdb.Doc                     { return b }
+func (b apiBIConnection) SetID(_ string)                         {}
+func (b apiBIConnection) SetRev(_ string)                        {}
+func (b apiBIConnection) Relationships() jsonapi.RelationshipMap { return nil }
+func (b apiBIConnection) Included() []jsonapi.Object             { return nil }
+func (b apiBIConnection) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/jobs/webhooks/bi/connections/" + b.ID()}
+}
+
+func (b apiBIConnection) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.s)
+}
+
+func (s apiJobsStats) ID() string                             { return s.s.WorkerType }
+func (s apiJobsStats) Rev() string                            { return "" }
+func (s apiJobsStats) DocType() string                        { return consts.JobsStats }
+func (s apiJobsStats) Clone() couchdb.Doc                     { return s }
+func (s apiJobsStats) SetID(_ string)                         {}
+func (s apiJobsStats) SetRev(_ string)                        {}
+func (s apiJobsStats) Relationships() jsonapi.RelationshipMap { return nil }
+func (s apiJobsStats) Included() []jsonapi.Object             { return nil }
+func (s apiJobsStats) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/jobs/queue/" + s.ID() + "/stats"}
+}
+
+func (s apiJobsStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.s)
+}
+
 const bearerAuthScheme = "Bearer "
 
+// maxBIWebhookSize is the maximal size of the body of a BI webhook.
+const maxBIWebhookSize = 1 << 20
+
 func getQueue(c echo.Context) error {
 	instance := middlewares.GetInstance(c)
 	workerType := c.Param("worker-type")
@@ -175,13 +242,32 @@ func getQueue(c echo.Context) error {
 	return jsonapi.DataList(c, http.StatusOK, objs, nil)
 }
 
-func pushJob(c echo.Context) error {
+// statsPeriod is the period used for the statistics of the jobs.
+const statsPeriod = 24 * time.Hour
+
+// getQueueStats returns the median and 95th percentile of the execution and
+// queue-wait durations for the jobs of the last 24 hours.
+func getQueueStats(c echo.Context) error {
 	instance := middlewares.GetInstance(c)
+	workerType := c.Param("worker-type")
 
-	req := apiJobRequest{}
-	if _, err := jsonapi.Bind(c.Request().Body, &req); err != nil {
+	o := apiQueue{workerType: workerType}
+	if err := middlewares.Allow(c, permission.GET, o); err != nil {
+		return err
+	}
+
+	stats, err := job.GetStats(instance, workerType, time.Now().Add(-statsPeriod))
+	if err != nil {
 		return wrapJobsError(err)
 	}
+	return jsonapi.Data(c, http.StatusOK, apiJobsStats{stats}, nil)
+}
+
+// maxBatchJobs is the maximal number of jobs that can be pushed in a single
+// request with the batch route.
+const maxBatchJobs = 1000
+
+func (req *apiJobRequest) toJobRequest(workerType string) *job.JobRequest {
 	var opts *job.JobOptions
 	if req.Options != nil {
 		opts = &job.JobOptions{
@@ -189,15 +275,18 @@ func pushJob(c echo.Context) error {
 			Timeout:      time.Duration(req.Options.Timeout) * time.Second,
 		}
 	}
-
-	jr := &job.JobRequest{
-		WorkerType:  c.Param("worker-type"),
+	return &job.JobRequest{
+		WorkerType:  workerType,
 		Options:     opts,
 		Manual:      req.Manual,
 		ForwardLogs: req.ForwardLogs,
 		Message:     job.Message(req.Arguments),
 	}
+}
 
+// checkPushJob checks that the job request can be pushed with the permissions
+// of the request.
+func checkPushJob(c echo.Context, jr *job.JobRequest) error {
 	if err := middlewares.Allow(c, permission.POST, jr); err != nil {
 		return err
 	}
@@ -214,6 +303,20 @@ func pushJob(c echo.Context) error {
 			return err
 		}
 	}
+	return nil
+}
+
+func pushJob(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+
+	req := apiJobRequest{}
+	if _, err := jsonapi.Bind(c.Request().Body, &req); err != nil {
+		return wrapJobsError(err)
+	}
+	jr := req.toJobRequest(c.Param("worker-type"))
+	if err := checkPushJob(c, jr); err != nil {
+		return err
+	}
 
 	j, err := job.System().PushJob(instance, jr)
 	if err != nil {
@@ -223,6 +326,47 @@ func pushJob(c echo.Context) error {
 	return jsonapi.Data(c, http.StatusAccepted, apiJob{j}, nil)
 }
 
+func pushJobs(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+
+	objs, err := jsonapi.BindCompound(c.Request().Body)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	if len(objs) == 0 {
+		return jsonapi.BadRequest(errors.New("No job to push"))
+	}
+	if len(objs) > maxBatchJobs {
+		return jsonapi.BadRequest(fmt.Errorf("Too many jobs (the maximum is %d)", maxBatchJobs))
+	}
+
+	workerType := c.Param("worker-type")
+	jrs := make([]*job.JobRequest, len(objs))
+	for i, obj := range objs {
+		req := apiJobRequest{}
+		if obj.Attributes != nil {
+			if err := json.Unmarshal(*obj.Attributes, &req); err != nil {
+				return jsonapi.BadJSON()
+			}
+		}
+		jrs[i] = req.toJobRequest(workerType)
+		if err := checkPushJob(c, jrs[i]); err != nil {
+			return err
+		}
+	}
+
+	jobs, err := job.System().PushJobs(instance, jrs)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+
+	list := make([]jsonapi.Object, len(jobs))
+	for i, j := range jobs {
+		list[i] = apiJob{j}
+	}
+	return jsonapi.DataList(c, http.StatusAccepted, list, nil)
+}
+
 func contactSupport(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 
@@ -294,6 +438,7 @@ func newTrigger(c echo.Context) error {
 		Arguments:  req.Arguments,
 		Debounce:   req.Debounce,
 		Options:    req.Options,
+		Filter:     req.Filter,
 		Metadata:   md,
 	}, msg)
 	if err != nil {
@@ -486,8 +631,15 @@ func fireBIWebhook(c echo.Context) error {
 	}
 	token := strings.TrimPrefix(header, bearerAuthScheme)
 
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxBIWebhookSize))
+	if err != nil {
+		return jsonapi.BadRequest(err)
+	}
+	if err := bi.VerifySignature(inst.ContextName, c.Request(), body); err != nil {
+		return jsonapi.Forbidden(err)
+	}
 	var payload map[string]interface{}
-	if err := c.Bind(&payload); err != nil {
+	if err := json.Unmarshal(body, &payload); err != nil {
 		return jsonapi.BadRequest(err)
 	}
 
@@ -496,14 +648,6 @@ func fireBIWebhook(c echo.Context) error {
 		return jsonapi.BadRequest(err)
 	}
 
-	// The stack will create or delete accounts and triggers on some webhooks,
-	// so it is safer to avoid concurrency on this part of the code.
-	mutex := config.Lock().ReadWrite(inst, "bi")
-	if err := mutex.Lock(); err != nil {
-		return err
-	}
-	defer mutex.Unlock()
-
 	call := &bi.WebhookCall{
 		Instance: inst,
 		Token:    token,
@@ -511,12 +655,50 @@ func fireBIWebhook(c echo.Context) error {
 		Event:    biEvent,
 		Payload:  payload,
 	}
-	if err := call.Fire(); err != nil {
+	inboxID := bi.InboxID(biEvent, c.Request().Header.Get("Idempotency-Key"), body)
+	if err := call.Handle(inboxID); err != nil {
 		return jsonapi.BadRequest(err)
 	}
 	return c.NoContent(http.StatusNoContent)
 }
 
+func getBIConnections(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Accounts); err != nil {
+		return err
+	}
+	statuses, err := bi.ListConnectionStatuses(inst)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	objs := make([]jsonapi.Object, len(statuses))
+	for i, s := range statuses {
+		objs[i] = apiBIConnection{s}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func getBIConnection(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Accounts); err != nil {
+		return err
+	}
+	connID, err := strconv.Atoi(c.Param("conn-id"))
+	if err != nil {
+		return jsonapi.InvalidParameter("conn-id", err)
+	}
+	statuses, err := bi.ListConnectionStatuses(inst)
+	if err != nil {
+		return wrapJobsError(err)
+	}
+	for _, s := range statuses {
+		if s.ConnectionID == connID {
+			return jsonapi.Data(c, http.StatusOK, apiBIConnection{s}, nil)
+		}
+	}
+	return jsonapi.NotFound(errors.New("No webhook received for this connection"))
+}
+
 func fireWebhook(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 	err := config.GetRateLimiter().CheckRateLimit(inst, limits.WebhookTriggerType)
@@ -537,6 +719,10 @@ func fireWebhook(c echo.Context) error {
 	if err != nil {
 		return wrapJobsError(err)
 	}
+	signature := c.Request().Header.Get(job.WebhookSignatureHeader)
+	if err := webhook.VerifySignature(signature, payload); err != nil {
+		return wrapJobsError(err)
+	}
 
 	manual := false
 	if c.QueryParam("Manual") == "true" {
@@ -624,6 +810,49 @@ func getJob(c echo.Context) error {
 	return jsonapi.Data(c, http.StatusOK, apiJob{j}, nil)
 }
 
+// defaultWaitTimeout and maxWaitTimeout are the default and maximal
+// durations for long-polling a job.
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 2 * time.Minute
+)
+
+// waitJob is a long-polling fallback for the clients that can't use the
+// realtime: it responds when the job is done or errored, or when the timeout
+// is reached (with the job still queued or running).
+func waitJob(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	j, err := job.Get(inst, c.Param("job-id"))
+	if err != nil {
+		return err
+	}
+	if err := middlewares.Allow(c, permission.GET, j); err != nil {
+		return err
+	}
+
+	timeout := defaultWaitTimeout
+	if param := c.QueryParam("timeout"); param != "" {
+		timeout, err = time.ParseDuration(param)
+		if err != nil || timeout <= 0 {
+			return jsonapi.InvalidParameter("timeout", errors.New("Invalid duration"))
+		}
+		if timeout > maxWaitTimeout {
+			timeout = maxWaitTimeout
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+	defer cancel()
+	_, err = j.WaitContext(ctx, inst)
+	if err != nil && !errors.Is(err, job.ErrWaitTimeout) {
+		return err
+	}
+	if j, err = job.Get(inst, j.ID()); err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, apiJob{j}, nil)
+}
+
 func patchJob(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 	j, err := job.Get(inst, c.Param("job-id"))
@@ -810,7 +1039,9 @@ func purgeJobs(c echo.Context) error {
 // Routes sets the routing for the jobs service
 func Routes(router *echo.Group) {
 	router.GET("/queue/:worker-type", getQueue)
+	router.GET("/queue/:worker-type/stats", getQueueStats)
 	router.POST("/queue/:worker-type", pushJob)
+	router.POST("/queue/:worker-type/batch", pushJobs)
 	router.POST("/support", contactSupport)
 
 	router.POST("/triggers", newTrigger)
@@ -823,15 +1054,24 @@ func Routes(router *echo.Group) {
 	router.DELETE("/triggers/:trigger-id", deleteTrigger)
 
 	router.POST("/webhooks/bi", fireBIWebhook)
+	router.GET("/webhooks/bi/connections", getBIConnections)
+	router.GET("/webhooks/bi/connections/:conn-id", getBIConnection)
 	router.POST("/webhooks/:trigger-id", fireWebhook)
 
 	router.POST("/clean", cleanJobs)
 	router.DELETE("/purge", purgeJobs)
 	router.GET("/:job-id", getJob)
+	router.GET("/:job-id/wait", waitJob)
 	router.PATCH("/:job-id", patchJob)
 }
 
 func wrapJobsError(err error) error {
+	if errors.Is(err, job.ErrInvalidRetryPolicy) {
+		return jsonapi.InvalidAttribute("options", err)
+	}
+	if errors.Is(err, job.ErrInvalidEventFilter) {
+		return jsonapi.InvalidAttribute("filter", err)
+	}
 	switch err {
 	case job.ErrNotFoundTrigger,
 		job.ErrNotFoundJob,
@@ -843,6 +1083,8 @@ func wrapJobsError(err error) error {
 	case limits.ErrRateLimitReached,
 		limits.ErrRateLimitExceeded:
 		return jsonapi.BadRequest(err)
+	case job.ErrInvalidSignature:
+		return jsonapi.Forbidden(err)
 	}
 	return err
 }
@@ -125,6 +125,35 @@ func TestJobs(t *testing.T) {
 		assert.Equal(t, 42*time.Second, job.Options.Timeout)
 	})
 
+	t.Run("WaitJob", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		obj := e.POST("/jobs/queue/print").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{
+        "data": {
+          "attributes": { "arguments": "foobar" }
+        }
+      }`)).
+			Expect().Status(202).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object()
+		jobID := obj.Path("$.data.id").String().NotEmpty().Raw()
+
+		e.GET("/jobs/"+jobID+"/wait").
+			WithQuery("timeout", "10s").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Path("$.data.attributes.state").Equal("done")
+
+		e.GET("/jobs/"+jobID+"/wait").
+			WithQuery("timeout", "foo").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(422)
+	})
+
 	t.Run("CreateManualJob", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -172,6 +201,54 @@ func TestJobs(t *testing.T) {
 						Expect().Status(404)
 	})
 
+	t.Run("CreateJobsBatch", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		arr := e.POST("/jobs/queue/print/batch").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{
+        "data": [
+          { "attributes": { "arguments": "foo" } },
+          { "attributes": { "arguments": "bar" } },
+          { "attributes": { "arguments": "baz" } }
+        ]
+      }`)).
+			Expect().Status(202).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object().Value("data").Array()
+		arr.Length().Equal(3)
+		for _, item := range arr.Iter() {
+			item.Object().Value("id").String().NotEmpty()
+			item.Object().Path("$.attributes.worker").Equal("print")
+		}
+
+		e.POST("/jobs/queue/print/batch").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"data": []}`)).
+			Expect().Status(400)
+
+		// The token has no permission for this worker type
+		e.POST("/jobs/queue/sendmail/batch").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"data": [{"attributes": {"arguments": "foo"}}]}`)).
+			Expect().Status(403)
+
+		// A batch is limited to 1000 jobs
+		items := make([]string, 1001)
+		for i := range items {
+			items[i] = `{"attributes": {"arguments": "foo"}}`
+		}
+		e.POST("/jobs/queue/print/batch").
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/json").
+			WithBytes([]byte(`{"data": [` + strings.Join(items, ",") + `]}`)).
+			Expect().Status(400).
+			Body().Contains("Too many jobs")
+	})
+
 	t.Run("AddGetAndDeleteTriggerAt", func(t *testing.T) {
 		var triggerID string
 		at := time.Now().Add(1100 * time.Millisecond).Format(time.RFC3339)
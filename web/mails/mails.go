@@ -0,0 +1,93 @@
+// Package mails is for the webhooks used by the mail providers to report the
+// bounces and complaints for the mails sent by the stack.
+package mails
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/mailqueue"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/safehttp"
+	"github.com/labstack/echo/v4"
+)
+
+// maxBodySize is the maximal size of the body of a webhook.
+const maxBodySize = 1 << 20
+
+func readBody(c echo.Context) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(c.Request().Body, maxBodySize))
+}
+
+// sesBounces is the handler for the notifications of Amazon SES, sent via
+// an Amazon SNS topic. The token from the configuration must be given in
+// the query-string of the URL of the subscription.
+func sesBounces(c echo.Context) error {
+	token := config.GetConfig().MailQueue.Bounces.Token
+	given := c.QueryParam("token")
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(given)) != 1 {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": "invalid token"})
+	}
+	body, err := readBody(c)
+	if err != nil {
+		return err
+	}
+	report, confirmation, err := mailqueue.ParseSES(body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	if confirmation != nil {
+		res, err := safehttp.DefaultClient.Get(confirmation.SubscribeURL)
+		if err != nil {
+			return c.JSON(http.StatusBadGateway, echo.Map{"error": err.Error()})
+		}
+		res.Body.Close()
+		logger.WithNamespace("mailqueue").Infof("SNS subscription confirmed: %d", res.StatusCode)
+		return c.NoContent(http.StatusNoContent)
+	}
+	return handleReport(c, report)
+}
+
+// mailgunBounces is the handler for the webhooks of Mailgun. Their signature
+// is checked with the signing key from the configuration.
+func mailgunBounces(c echo.Context) error {
+	body, err := readBody(c)
+	if err != nil {
+		return err
+	}
+	key := config.GetConfig().MailQueue.Bounces.MailgunSigningKey
+	report, err := mailqueue.ParseMailgun(body, key)
+	if err == mailqueue.ErrInvalidSignature {
+		return c.JSON(http.StatusForbidden, echo.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return handleReport(c, report)
+}
+
+func handleReport(c echo.Context, report *mailqueue.Report) error {
+	if report == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+	err := mailqueue.HandleReport(report)
+	if err == mailqueue.ErrInvalidMessageID {
+		// The provider must not retry for a message that is not known by
+		// the stack, so a 2xx status code is used.
+		logger.WithNamespace("mailqueue").
+			Infof("Report for an unknown message: %q", report.MessageID)
+		return c.NoContent(http.StatusNoContent)
+	}
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Routes sets the routing for the webhooks of the mail providers.
+func Routes(router *echo.Group) {
+	router.POST("/bounces/ses", sesBounces)
+	router.POST("/bounces/mailgun", mailgunBounces)
+}
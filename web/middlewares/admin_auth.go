@@ -0,0 +1,279 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+const adminAccountKey = "admin_account"
+
+// legacyAdminAccount is the account used when the request is authenticated
+// with the passphrase of the admin secret file: it can do everything.
+var legacyAdminAccount = config.AdminAccount{
+	Name: "admin",
+	Role: config.AdminRoleOperator,
+}
+
+// adminRules are the endpoints of the admin API that can be used by the
+// roles, as a method and a route path. A path ending with a * matches all the
+// routes with this prefix. The operators can use all the endpoints.
+var adminRules = map[string][]string{
+	config.AdminRoleSupport: {
+		"GET /version",
+		"GET /instances",
+		"GET /instances/count",
+		"GET /instances/:domain",
+		"GET /instances/:domain/debug",
+		"POST /instances/:domain/debug",
+		"DELETE /instances/:domain/debug",
+		"GET /instances/:domain/feature/flags",
+		"GET /instances/:domain/feature/sets",
+		"DELETE /instances/:domain/sessions",
+		"GET /instances/:domain/network-access",
+		"GET /instances/:domain/network-access/check",
+		"GET /instances/:domain/legal-hold",
+		"GET /instances/:domain/legal-hold/content",
+		"GET /instances/:domain/last-activity",
+		"GET /instances/:domain/disk-usage",
+		"GET /instances/:domain/disk-usage/trend",
+		"GET /instances/:domain/prefix",
+		"GET /instances/:domain/swift-prefix",
+		"GET /instances/:domain/mails/dead",
+		"POST /instances/:domain/mails/dead/:id/retry",
+		"GET /instances/:domain/schemas",
+		"GET /instances/:domain/fsck",
+		"POST /instances/:domain/checks/*",
+		"POST /instances/:domain/fixers/*",
+	},
+	config.AdminRoleBilling: {
+		"GET /version",
+		"GET /instances",
+		"GET /instances/count",
+		"GET /instances/:domain",
+		"PATCH /instances/:domain",
+		"GET /instances/:domain/feature/flags",
+		"PATCH /instances/:domain/feature/flags",
+		"GET /instances/:domain/feature/sets",
+		"PUT /instances/:domain/feature/sets",
+		"GET /instances/:domain/last-activity",
+		"GET /instances/:domain/disk-usage",
+		"GET /instances/:domain/disk-usage/trend",
+	},
+}
+
+// adminRuleParams restricts the parameters of the query-string that can be
+// used by a role on some routes. The billing accounts can change the disk
+// quota and the TOS of an instance with PATCH /instances/:domain, but not its
+// email, context, etc.
+var adminRuleParams = map[string]map[string][]string{
+	config.AdminRoleBilling: {
+		"PATCH /instances/:domain": {"DiskQuota", "TOSSigned", "TOSLatest", "FromCloudery"},
+	},
+}
+
+// unscopedAdminRoutes are the routes that can be used by the accounts limited
+// to some contexts, even if they are not related to an instance or a context.
+// The handler of GET /instances filters the instances itself.
+var unscopedAdminRoutes = map[string]struct{}{
+	"GET /version":   {},
+	"GET /instances": {},
+}
+
+// AdminAuth authenticates the requests to the admin API with the HTTP basic
+// authentication. The username is the name of an admin account from the
+// config, with its own secret file, and the endpoint must be allowed for its
+// role and contexts. For compatibility, the passphrase of the admin secret
+// file can be used with any other username to have an operator account. Each
+// request is logged in the audit log with the account that has made it.
+func AdminAuth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			account, err := authenticateAdmin(c)
+			if err != nil {
+				return err
+			}
+			c.Set(adminAccountKey, account)
+
+			err = authorizeAdmin(c, account)
+			if err == nil {
+				err = next(c)
+			}
+			auditAdminAction(c, account, err)
+			return err
+		}
+	}
+}
+
+// GetAdminAccount returns the admin account that has made the request. It
+// returns false if the request has not been authenticated with an account,
+// like in development mode.
+func GetAdminAccount(c echo.Context) (*config.AdminAccount, bool) {
+	account, ok := c.Get(adminAccountKey).(*config.AdminAccount)
+	return account, ok
+}
+
+func authenticateAdmin(c echo.Context) (*config.AdminAccount, error) {
+	username, passphrase, ok := c.Request().BasicAuth()
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "missing basic auth")
+	}
+
+	cfg := config.GetConfig()
+	if account, ok := cfg.AdminAccounts[username]; ok {
+		if err := checkSecretFile(account.SecretFileName, passphrase); err != nil {
+			return nil, err
+		}
+		return &account, nil
+	}
+
+	if err := checkSecretFile(cfg.AdminSecretFileName, passphrase); err != nil {
+		return nil, err
+	}
+	account := legacyAdminAccount
+	return &account, nil
+}
+
+func authorizeAdmin(c echo.Context, account *config.AdminAccount) error {
+	route := c.Request().Method + " " + c.Path()
+	if account.Role != config.AdminRoleOperator && !matchAdminRules(adminRules[account.Role], route) {
+		return echo.NewHTTPError(http.StatusForbidden, "this action is not allowed for the role "+account.Role)
+	}
+	if allowed, ok := adminRuleParams[account.Role][route]; ok {
+		for param := range c.QueryParams() {
+			if !containsParam(allowed, param) {
+				return echo.NewHTTPError(http.StatusForbidden, "the parameter "+param+" is not allowed for the role "+account.Role)
+			}
+		}
+	}
+	if len(account.Contexts) == 0 {
+		return nil
+	}
+
+	contextName, scoped, err := adminRequestContext(c)
+	if err != nil {
+		return err
+	}
+	if !scoped {
+		if _, ok := unscopedAdminRoutes[route]; ok {
+			return nil
+		}
+	} else if account.HasContext(contextName) {
+		return nil
+	}
+	return echo.NewHTTPError(http.StatusForbidden, "this action is not allowed outside of the contexts of the account")
+}
+
+func matchAdminRules(rules []string, route string) bool {
+	for _, rule := range rules {
+		if strings.HasSuffix(rule, "*") {
+			if strings.HasPrefix(route, strings.TrimSuffix(rule, "*")) {
+				return true
+			}
+		} else if rule == route {
+			return true
+		}
+	}
+	return false
+}
+
+func containsParam(params []string, param string) bool {
+	for _, p := range params {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
+
+// adminRequestContext returns the context of the instance or of the context
+// targeted by the request. The boolean is false if the request is not about
+// an instance or a context.
+func adminRequestContext(c echo.Context) (string, bool, error) {
+	path := c.Path()
+	if domain := c.Param("domain"); domain != "" && strings.HasPrefix(path, "/instances/:domain") {
+		inst, err := lifecycle.GetInstance(domain)
+		if err != nil {
+			return "", false, echo.NewHTTPError(http.StatusForbidden, "this instance cannot be managed by the account")
+		}
+		return inst.ContextName, true, nil
+	}
+	if contextName := c.Param("context"); contextName != "" {
+		return contextName, true, nil
+	}
+	if path == "/instances/contexts/:name" {
+		return c.Param("name"), true, nil
+	}
+	if c.Request().Method == http.MethodPost && path == "/instances" {
+		return c.QueryParam("ContextName"), true, nil
+	}
+	return "", false, nil
+}
+
+func auditAdminAction(c echo.Context, account *config.AdminAccount, err error) {
+	domain := "admin"
+	if d := c.Param("domain"); d != "" && strings.HasPrefix(c.Path(), "/instances/:domain") {
+		domain = d
+	}
+	fields := logger.Fields{
+		"account": account.Name,
+		"role":    account.Role,
+		"method":  c.Request().Method,
+		"path":    c.Request().URL.Path,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		var he *echo.HTTPError
+		var je *jsonapi.Error
+		if errors.As(err, &he) {
+			fields["status"] = he.Code
+		} else if errors.As(err, &je) {
+			fields["status"] = je.Status
+		}
+	} else if c.Response().Committed {
+		fields["status"] = c.Response().Status
+	}
+	logger.WithDomain(domain).WithNamespace("adminaudit").WithFields(fields).Info("Admin action")
+}
+
+// checkSecretFile checks that the passphrase matches the hash saved in the
+// secret file with the given name.
+func checkSecretFile(secretFileName, passphrase string) error {
+	shadowFile, err := config.FindConfigFile(secretFileName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	f, err := os.Open(shadowFile)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	b = bytes.TrimSpace(b)
+
+	needUpdate, err := crypto.CompareHashAndPassphrase(b, []byte(passphrase))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, "bad passphrase")
+	}
+	if needUpdate {
+		logger.
+			WithDomain("admin").
+			Warnf("Passphrase hash from %q needs update and should be regenerated", secretFileName)
+	}
+	return nil
+}
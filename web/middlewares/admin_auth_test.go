@@ -0,0 +1,126 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAuth(t *testing.T) {
+	config.UseTestFile(t)
+	cfg := config.GetConfig()
+
+	dir := t.TempDir()
+	paths := config.Paths
+	config.Paths = []string{dir}
+	t.Cleanup(func() {
+		config.Paths = paths
+		cfg.AdminAccounts = nil
+	})
+	for name, pass := range map[string]string{
+		cfg.AdminSecretFileName: "root",
+		"cozy-admin-alice":      "alice",
+		"cozy-admin-bob":        "bob",
+		"cozy-admin-carol":      "carol",
+	} {
+		hash, err := crypto.GenerateFromPassphrase([]byte(pass))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), hash, 0600))
+	}
+	cfg.AdminAccounts = map[string]config.AdminAccount{
+		"alice": {Name: "alice", Role: config.AdminRoleSupport, SecretFileName: "cozy-admin-alice"},
+		"bob":   {Name: "bob", Role: config.AdminRoleOperator, SecretFileName: "cozy-admin-bob", Contexts: []string{"beta"}},
+		"carol": {Name: "carol", Role: config.AdminRoleBilling, SecretFileName: "cozy-admin-carol"},
+	}
+
+	e := echo.New()
+	g := e.Group("/instances", middlewares.AdminAuth())
+	handler := func(c echo.Context) error {
+		account, ok := middlewares.GetAdminAccount(c)
+		require.True(t, ok)
+		return c.String(http.StatusOK, account.Name)
+	}
+	g.GET("/count", handler)
+	g.GET("/:domain/debug", handler)
+	g.DELETE("/:domain", handler)
+	g.PATCH("/:domain", handler)
+	g.GET("/contexts/:name", handler)
+
+	do := func(method, path, user, pass string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.SetBasicAuth(user, pass)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Authentication", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/instances/count", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/instances/count", "alice", "root").Code)
+		assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/instances/count", "admin", "alice").Code)
+
+		rec = do(http.MethodGet, "/instances/count", "", "root")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "admin", rec.Body.String())
+	})
+
+	t.Run("Role", func(t *testing.T) {
+		rec := do(http.MethodGet, "/instances/alice.cozy.localhost/debug", "alice", "alice")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "alice", rec.Body.String())
+
+		rec = do(http.MethodDelete, "/instances/alice.cozy.localhost", "alice", "alice")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = do(http.MethodDelete, "/instances/alice.cozy.localhost", "admin", "root")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Params", func(t *testing.T) {
+		rec := do(http.MethodPatch, "/instances/alice.cozy.localhost?DiskQuota=1000000&TOSSigned=1.0.0", "carol", "carol")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = do(http.MethodPatch, "/instances/alice.cozy.localhost?Email=carol@example.org", "carol", "carol")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = do(http.MethodPatch, "/instances/alice.cozy.localhost?DiskQuota=1000000&ContextName=beta", "carol", "carol")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = do(http.MethodPatch, "/instances/alice.cozy.localhost?Email=carol@example.org", "admin", "root")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Contexts", func(t *testing.T) {
+		rec := do(http.MethodGet, "/instances/contexts/beta", "bob", "bob")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = do(http.MethodGet, "/instances/contexts/default", "bob", "bob")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = do(http.MethodGet, "/instances/count", "bob", "bob")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestAdminAccountHasContext(t *testing.T) {
+	account := config.AdminAccount{Role: config.AdminRoleBilling}
+	assert.True(t, account.HasContext("beta"))
+
+	account.Contexts = []string{"beta", config.DefaultInstanceContext}
+	assert.True(t, account.HasContext("beta"))
+	assert.True(t, account.HasContext(""))
+	assert.False(t, account.HasContext("other"))
+}
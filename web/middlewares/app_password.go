@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/apppassword"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/labstack/echo/v4"
+)
+
+// AppPasswordAuth is a middleware for the protocol bridges (WebDAV, CalDAV,
+// etc.) that authenticates the requests with an app password for the given
+// protocol, sent via HTTP basic authentication. The username is ignored. The
+// permissions of the request are limited to the doctypes of the app password.
+func AppPasswordAuth(protocol string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			inst := GetInstance(c)
+			challenge := fmt.Sprintf(`Basic realm="%s", charset="UTF-8"`, inst.Domain)
+			_, secret, ok := c.Request().BasicAuth()
+			if !ok {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing basic auth")
+			}
+			if err := inst.MovedError(); err != nil {
+				return err
+			}
+			appPassword, err := apppassword.Authenticate(inst, protocol, secret)
+			if err == apppassword.ErrInvalidPassword {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			} else if err != nil {
+				return err
+			}
+			c.Set(contextPermissionDoc, &permission.Permission{
+				Type:        permission.TypeAppPassword,
+				SourceID:    consts.AppPasswords + "/" + appPassword.ID(),
+				Permissions: appPassword.Permissions(),
+			})
+			return next(c)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"html/template"
 
 	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/settings"
 	build "github.com/cozy/cozy-stack/pkg/config"
 	"github.com/labstack/echo/v4"
 )
@@ -66,6 +67,20 @@ func ThemeCSS(i *instance.Instance) template.HTML {
 	return template.HTML(buf.String())
 }
 
+// DisplayAttrs returns the attributes for the html tag of the pages rendered
+// by the stack, with the display preferences of the user (dark mode,
+// contrast, font size). The CSS of the pages uses them.
+func DisplayAttrs(i *instance.Instance) template.HTMLAttr {
+	prefs, err := settings.GetDisplayPrefs(i)
+	if err != nil {
+		prefs = settings.ContextDisplayPrefs(i)
+	}
+	return template.HTMLAttr(fmt.Sprintf(`data-theme="%s" data-contrast="%s" data-font-size="%s"`,
+		template.HTMLEscapeString(prefs.Theme),
+		template.HTMLEscapeString(prefs.Contrast),
+		template.HTMLEscapeString(prefs.FontSize)))
+}
+
 // Favicon returns a helper to insert the favicons in an HTML template.
 func Favicon(i *instance.Instance) template.HTML {
 	buf := new(bytes.Buffer)
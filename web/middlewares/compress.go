@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/pkg/compress"
+	"github.com/labstack/echo/v4"
+)
+
+// CompressOptions contains the options for the Compress middleware.
+type CompressOptions struct {
+	// MinSize is the size in bytes under which the responses are not
+	// compressed.
+	MinSize int
+	// Encodings is the list of the encodings that can be used, by order of
+	// preference.
+	Encodings []string
+}
+
+// Compress returns a middleware that compresses the responses on the fly,
+// with brotli, zstd or gzip, depending on the Accept-Encoding header of the
+// request. The responses that already have a Content-Encoding, like the
+// pre-compressed assets, are sent as is.
+func Compress(opts CompressOptions) echo.MiddlewareFunc {
+	var encodings []string
+	for _, encoding := range opts.Encodings {
+		if compress.IsSupported(encoding) {
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) == 0 {
+		encodings = compress.DefaultEncodings
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Method == http.MethodHead {
+				return next(c)
+			}
+			encoding := compress.Negotiate(req.Header.Get(echo.HeaderAcceptEncoding), encodings)
+			if encoding == compress.Identity {
+				return next(c)
+			}
+
+			res := c.Response()
+			original := res.Writer
+			w := compress.NewResponseWriter(original, encoding, opts.MinSize)
+			res.Writer = w
+			defer func() {
+				_ = w.Close()
+				res.Writer = original
+			}()
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"html/template"
+	"mime"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	build "github.com/cozy/cozy-stack/pkg/config"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// CSRFCookieName is the name of the cookie used to bind an anti-CSRF token
+	// to a browser that has no session yet (login form, sharing discovery,
+	// etc.).
+	CSRFCookieName = "_csrf"
+	// CSRFFormField is the name of the form field where the token is sent.
+	CSRFFormField = "csrf_token"
+
+	csrfContextKey  = "csrf"
+	csrfTokenLength = 32
+	csrfCookieAge   = 86400
+)
+
+// CSRFToken returns the anti-CSRF token that must be sent with the forms
+// rendered for the current request. When the user is logged in, the token is
+// derived from the session, so it changes with each session and can't be
+// guessed without the session cookie. Otherwise, a random token is kept in a
+// cookie, and the form must submit the same value (double-submit cookie).
+func CSRFToken(c echo.Context) string {
+	if token, ok := c.Get(csrfContextKey).(string); ok && token != "" {
+		return token
+	}
+	inst, ok := GetInstanceSafe(c)
+	if !ok {
+		return ""
+	}
+
+	var token string
+	if sess, ok := GetSession(c); ok {
+		token = sessionCSRFToken(inst, sess.ID())
+	} else if cookie, err := c.Cookie(CSRFCookieName); err == nil && cookie.Value != "" {
+		token = cookie.Value
+	} else {
+		token = utils.RandomString(csrfTokenLength)
+		c.SetCookie(&http.Cookie{
+			Name:     CSRFCookieName,
+			Value:    token,
+			Path:     "/",
+			MaxAge:   csrfCookieAge,
+			Secure:   !build.IsDevRelease(),
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+	c.Set(csrfContextKey, token)
+	// Protect clients from caching the response
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderCookie)
+	return token
+}
+
+// CSRFField returns the hidden input with the anti-CSRF token, to be included
+// in the HTML forms. It is available in the templates as {{csrfField}}.
+func CSRFField(c echo.Context) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + CSRFFormField +
+		`" value="` + template.HTMLEscapeString(CSRFToken(c)) + `" />`)
+}
+
+// CheckCSRF is a middleware that verifies the anti-CSRF token for the
+// requests with an unsafe method. The token can be sent in the csrf_token
+// form field or in the X-CSRF-Token header. The requests authenticated with
+// an Authorization header, and the JSON requests (they can't be sent
+// cross-origin without a CORS preflight), are not checked.
+func CheckCSRF(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			CSRFToken(c)
+			return next(c)
+		}
+		if req.Header.Get(echo.HeaderAuthorization) != "" || isJSONRequest(req) {
+			return next(c)
+		}
+
+		clientToken := req.Header.Get(echo.HeaderXCSRFToken)
+		if clientToken == "" {
+			clientToken = c.FormValue(CSRFFormField)
+		}
+		if clientToken == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "missing csrf token in the form parameter")
+		}
+		if !validCSRFToken(c, clientToken) {
+			return echo.NewHTTPError(http.StatusForbidden, "invalid csrf token")
+		}
+		CSRFToken(c)
+		return next(c)
+	}
+}
+
+// validCSRFToken accepts the token bound to the session, but also the token
+// from the cookie, as the form may have been rendered before the user has
+// logged in (or in another tab before the session has been created).
+func validCSRFToken(c echo.Context, clientToken string) bool {
+	if inst, ok := GetInstanceSafe(c); ok {
+		if sess, ok := GetSession(c); ok {
+			expected := sessionCSRFToken(inst, sess.ID())
+			if validateCSRFToken(expected, clientToken) {
+				return true
+			}
+		}
+	}
+	cookie, err := c.Cookie(CSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return validateCSRFToken(cookie.Value, clientToken)
+}
+
+func validateCSRFToken(token, clientToken string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(clientToken)) == 1
+}
+
+func sessionCSRFToken(inst *instance.Instance, sessionID string) string {
+	mac := hmac.New(sha256.New, inst.SessionSecret())
+	_, _ = mac.Write([]byte("csrf:" + sessionID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isJSONRequest(req *http.Request) bool {
+	mediatype, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return false
+	}
+	return mediatype == echo.MIMEApplicationJSON || mediatype == "application/vnd.api+json"
+}
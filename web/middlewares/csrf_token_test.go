@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/session"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCSRF(t *testing.T) {
+	inst := &instance.Instance{Domain: "alice.cozy.localhost", SessSecret: []byte("0123456789abcdef")}
+	e := echo.New()
+	h := CheckCSRF(func(c echo.Context) error {
+		return c.String(http.StatusOK, CSRFToken(c))
+	})
+
+	newContext := func(method string, form url.Values, cookie string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(form.Encode()))
+		if method == http.MethodPost {
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: cookie})
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("instance", inst)
+		return c, rec
+	}
+
+	t.Run("AnonymousToken", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, nil, "")
+		require.NoError(t, h(c))
+		token := rec.Body.String()
+		assert.NotEmpty(t, token)
+		assert.Contains(t, rec.Header().Get(echo.HeaderSetCookie), CSRFCookieName+"="+token)
+
+		c, rec = newContext(http.MethodPost, url.Values{CSRFFormField: {token}}, token)
+		require.NoError(t, h(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		c, _ = newContext(http.MethodPost, url.Values{CSRFFormField: {"invalid"}}, token)
+		err := h(c)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+
+		c, _ = newContext(http.MethodPost, nil, token)
+		err = h(c)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+
+		c, _ = newContext(http.MethodPost, url.Values{CSRFFormField: {token}}, "")
+		assert.Error(t, h(c))
+	})
+
+	t.Run("SessionToken", func(t *testing.T) {
+		sess := &session.Session{DocID: "7b8d6e3c5e7d"}
+		c, rec := newContext(http.MethodGet, nil, "")
+		c.Set(sessionKey, sess)
+		require.NoError(t, h(c))
+		token := rec.Body.String()
+		assert.Equal(t, sessionCSRFToken(inst, sess.ID()), token)
+		assert.Empty(t, rec.Header().Get(echo.HeaderSetCookie))
+
+		c, rec = newContext(http.MethodPost, url.Values{CSRFFormField: {token}}, "")
+		c.Set(sessionKey, sess)
+		require.NoError(t, h(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		other := &session.Session{DocID: "1f2e3d4c5b6a"}
+		c, _ = newContext(http.MethodPost, url.Values{CSRFFormField: {token}}, "")
+		c.Set(sessionKey, other)
+		assert.Error(t, h(c))
+	})
+
+	t.Run("HeaderToken", func(t *testing.T) {
+		c, _ := newContext(http.MethodPost, nil, "xyz")
+		c.Request().Header.Set(echo.HeaderXCSRFToken, "xyz")
+		assert.NoError(t, h(c))
+	})
+
+	t.Run("SkipJSON", func(t *testing.T) {
+		c, _ := newContext(http.MethodPost, nil, "")
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		assert.NoError(t, h(c))
+	})
+
+	t.Run("CSRFField", func(t *testing.T) {
+		c, _ := newContext(http.MethodGet, nil, "abc")
+		assert.Equal(t, `<input type="hidden" name="csrf_token" value="abc" />`, string(CSRFField(c)))
+	})
+}
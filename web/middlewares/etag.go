@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/labstack/echo/v4"
+)
+
+// listingETagPeriod is the maximal duration during which the ETag of a
+// listing stays the same when nothing has changed. It is shorter than the
+// validity of the secrets that can be used in the links of the listings (for
+// the thumbnails for example), so that a client doesn't keep expired links.
+const listingETagPeriod = 5 * time.Minute
+
+// CheckListingETag computes a weak ETag for a listing of documents of the
+// given doctypes, from the update sequence of their databases, the URL of the
+// request and the permission used. It is cheaper than building the listing,
+// and it changes when a document of these doctypes is created, updated or
+// deleted. The ETag is put in the response, and if the client has sent it in
+// the If-None-Match header, a 304 Not Modified response is written and true
+// is returned: the handler must stop there.
+//
+// It must be called after the permissions have been checked.
+func CheckListingETag(c echo.Context, doctypes ...string) bool {
+	inst := GetInstance(c)
+	req := c.Request()
+	h := sha256.New()
+	for _, doctype := range doctypes {
+		status, err := couchdb.DBStatus(inst, doctype)
+		if couchdb.IsNoDatabaseError(err) {
+			fmt.Fprintf(h, "%s:\n", doctype)
+			continue
+		}
+		if err != nil {
+			return false
+		}
+		fmt.Fprintf(h, "%s:%s\n", doctype, status.UpdateSeq)
+	}
+	fmt.Fprintf(h, "%s\n%s\n", req.URL.RequestURI(), req.Header.Get(echo.HeaderAccept))
+	if pdoc, err := GetPermission(c); err == nil {
+		fmt.Fprintf(h, "%s:%s\n", pdoc.ID(), pdoc.Rev())
+	}
+	fmt.Fprintf(h, "%d\n", time.Now().Unix()/int64(listingETagPeriod/time.Second))
+
+	sum := h.Sum(nil)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Response().Header().Set("Etag", etag)
+	return utils.CheckPreconditions(c.Response(), req, etag)
+}
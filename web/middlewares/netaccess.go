@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// CheckNetworkAccess is a middleware that rejects the requests from the IP
+// addresses that are not allowed by the network access rules of the context
+// and of the instance. The requests made with a valid code of a share by link
+// use the rules for the public shares.
+func CheckNetworkAccess(category netaccess.Category) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			i := GetInstance(c)
+			if err := CheckInstanceNetworkAccess(c, i, networkAccessCategory(c, i, category)); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// CheckInstanceNetworkAccess can be used by the handlers that find the
+// instance by themselves (like the OAuth redirections on a shared domain) to
+// apply the network access rules of the given category.
+func CheckInstanceNetworkAccess(c echo.Context, i *instance.Instance, category netaccess.Category) error {
+	ip := ClientIP(c)
+	if err := i.CheckNetworkAccess(category, ip); err != nil {
+		i.Logger().WithNamespace("netaccess").
+			Infof("Access denied for %s on %s (%s)", ip, c.Request().URL.Path, category)
+		return echo.NewHTTPError(http.StatusForbidden, err)
+	}
+	return nil
+}
+
+// networkAccessCategory returns CategoryPublic when the request is made with
+// the code of a share (in the sharecode parameter for the pages, or as the
+// token for the API), and this code is valid. Else, the category of the
+// routes is used: a code that is not checked must not change the rules.
+func networkAccessCategory(c echo.Context, i *instance.Instance, category netaccess.Category) netaccess.Category {
+	code := c.QueryParam("sharecode")
+	if code == "" {
+		code = GetRequestToken(c)
+	}
+	if code == "" {
+		return category
+	}
+	token, err := TransformShortcodeToJWT(i, code)
+	if err != nil {
+		return category
+	}
+	var claims permission.Claims
+	err = crypto.ParseJWT(token, func(t *jwt.Token) (interface{}, error) {
+		return i.PickKey(consts.ShareAudience)
+	}, &claims)
+	if err != nil || claims.AudienceString() != consts.ShareAudience ||
+		claims.Issuer != i.Domain || claims.Expired() {
+		return category
+	}
+	pdoc, err := permission.GetForShareCode(i, token)
+	if err != nil || pdoc.Expired() {
+		return category
+	}
+	switch pdoc.Type {
+	case permission.TypeShareByLink, permission.TypeSharePreview, permission.TypeShareInteract:
+		return netaccess.CategoryPublic
+	}
+	return category
+}
+
+// CheckAdminNetworkAccess is a middleware that rejects the requests to the
+// admin API from the IP addresses that are not allowed by the config.
+func CheckAdminNetworkAccess(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ip := ClientIP(c)
+		if err := config.GetConfig().NetworkAccess.Admin.Check(ip); err != nil {
+			logger.WithDomain("admin").WithNamespace("netaccess").
+				Infof("Access denied for %s on %s", ip, c.Request().URL.Path)
+			return echo.NewHTTPError(http.StatusForbidden, err)
+		}
+		return next(c)
+	}
+}
+
+// ClientIP returns the IP address of the client, with the X-Forwarded-For
+// header if the request comes from a trusted proxy.
+func ClientIP(c echo.Context) net.IP {
+	return netaccess.ClientIP(c.Request(), config.GetConfig().NetworkAccess.TrustedProxies)
+}
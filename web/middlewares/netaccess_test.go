@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckNetworkAccess(t *testing.T) {
+	config.UseTestFile(t)
+	policy, err := netaccess.FromMap(map[string]interface{}{
+		"default": map[string]interface{}{"allow": []interface{}{"10.0.0.0/8"}},
+		"public":  map[string]interface{}{"allow": []interface{}{"198.51.100.0/24"}},
+	})
+	require.NoError(t, err)
+	inst := &instance.Instance{
+		Domain:        "alice.cozy.localhost",
+		OAuthSecret:   []byte("0123456789abcdef"),
+		NetworkAccess: policy,
+	}
+	e := echo.New()
+	h := CheckNetworkAccess(netaccess.CategoryDefault)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	check := func(remoteAddr, target, authorization string) error {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = remoteAddr
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.Set("instance", inst)
+		return h(c)
+	}
+	forbidden := func(t *testing.T, err error) {
+		t.Helper()
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	}
+
+	assert.NoError(t, check("10.1.2.3:1234", "/files/", ""))
+	forbidden(t, check("198.51.100.1:1234", "/files/", ""))
+
+	// A sharecode that has not been validated doesn't give the public rules
+	forbidden(t, check("198.51.100.1:1234", "/files/?sharecode=x", ""))
+	forbidden(t, check("198.51.100.1:1234", "/files/", "Bearer x"))
+
+	// Nor a token for the share audience that is not signed by the instance
+	forged, err := crypto.NewJWT([]byte("not-the-oauth-secret"), permission.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience: jwt.ClaimStrings{consts.ShareAudience},
+			Issuer:   inst.Domain,
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			Subject:  "email",
+		},
+	})
+	require.NoError(t, err)
+	forbidden(t, check("198.51.100.1:1234", "/files/?sharecode="+forged, ""))
+	forbidden(t, check("198.51.100.1:1234", "/files/", "Bearer "+forged))
+
+	// Nor a signed token without a share by link for it
+	signed, err := inst.MakeJWT(consts.ShareAudience, "email", "", "", time.Now())
+	require.NoError(t, err)
+	forbidden(t, check("198.51.100.1:1234", "/files/?sharecode="+signed, ""))
+}
@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/organization"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+const contextOrganizationUser = "organization_user"
+
+// GetOrganizationUser returns the user of an instance in organization mode
+// who makes the request. It returns false for the owner of the instance, and
+// for the requests without valid permissions.
+func GetOrganizationUser(c echo.Context) (*organization.User, bool) {
+	if _, err := GetPermission(c); err != nil {
+		return nil, false
+	}
+	user, ok := c.Get(contextOrganizationUser).(*organization.User)
+	return user, ok && user != nil
+}
+
+// organizationUserID returns the identifier of the user of the organization
+// for the request, from its session for an app token, or from the OAuth
+// client for an access token.
+func organizationUserID(c echo.Context, pdoc *permission.Permission) string {
+	if client, ok := pdoc.Client.(*oauth.Client); ok && client.UserID != "" {
+		return client.UserID
+	}
+	if claims, ok := c.Get("claims").(permission.Claims); ok && claims.SessionID != "" {
+		if sess, ok := GetSession(c); ok {
+			return sess.UserID
+		}
+	}
+	return ""
+}
+
+// restrictToOrganizationUser limits the permissions of the request to what
+// the role of the user of the organization allows.
+func restrictToOrganizationUser(c echo.Context, inst *instance.Instance, pdoc *permission.Permission) (*permission.Permission, error) {
+	userID := organizationUserID(c, pdoc)
+	if userID == "" {
+		return pdoc, nil
+	}
+	user, err := organization.Find(inst, userID)
+	if err != nil && !couchdb.IsNotFoundError(err) {
+		return nil, err
+	}
+	if err != nil || user.Disabled || !inst.Organization {
+		logger.WithNamespace("permissions").
+			Debugf("invalid token: no active user %s in the organization", userID)
+		return nil, permission.ErrInvalidToken
+	}
+	c.Set(contextOrganizationUser, user)
+	restricted := *pdoc
+	restricted.Permissions = user.Restrict(pdoc.Permissions)
+	return &restricted, nil
+}
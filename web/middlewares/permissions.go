@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/cozy/cozy-stack/model/accessstats"
 	"github.com/cozy/cozy-stack/model/app"
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	"github.com/cozy/cozy-stack/model/instance"
@@ -23,6 +24,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
@@ -134,6 +136,63 @@ func GetForOauth(instance *instance.Instance, claims *permission.Claims, client
 	return pdoc, nil
 }
 
+// getForWebview returns a non-persisted permissions doc for the token of an
+// app opened in a webview of the flagship app: the permissions of the app,
+// restricted by the scope of the token. The token is revoked with the OAuth
+// client of the flagship app.
+func getForWebview(instance *instance.Instance, claims *permission.Claims) (*permission.Permission, error) {
+	client, err := oauth.FindClient(instance, claims.AuthorizedParty)
+	if err != nil || !client.Flagship {
+		logger.WithNamespace("permissions").
+			Debugf("invalid token: no flagship client for webview")
+		return nil, permission.ErrInvalidToken
+	}
+	pdoc, err := permission.GetForWebapp(instance, claims.Subject)
+	if err != nil {
+		logger.WithNamespace("permissions").
+			Debugf("invalid token: no permission for webapp - %s", err)
+		return nil, err
+	}
+	set := pdoc.Permissions
+	if claims.Scope != "" {
+		set, err = permission.UnmarshalScopeString(claims.Scope)
+		if err != nil || !set.IsSubSetOf(pdoc.Permissions) {
+			return nil, permission.ErrInvalidToken
+		}
+	}
+	return &permission.Permission{
+		Type:        permission.TypeWebapp,
+		SourceID:    pdoc.SourceID,
+		Permissions: set,
+		Metadata:    pdoc.Metadata,
+	}, nil
+}
+
+// BuildWebviewToken creates a short-lived token for a webapp opened in a
+// webview of the flagship app. Only the flagship app can request it, and the
+// scope can restrict the permissions of the webapp.
+func BuildWebviewToken(c echo.Context, webapp *app.WebappManifest, scope string) (string, error) {
+	pdoc, err := GetPermission(c)
+	if err != nil {
+		return "", err
+	}
+	client, ok := pdoc.Client.(*oauth.Client)
+	if !ok || pdoc.Type != permission.TypeOauth || !client.Flagship || !pdoc.Permissions.IsMaximal() {
+		return "", jsonapi.Forbidden(errors.New("only the flagship app can create webview tokens"))
+	}
+	if scope != "" {
+		set, err := permission.UnmarshalScopeString(scope)
+		if err != nil {
+			return "", jsonapi.InvalidParameter("scope", err)
+		}
+		if !set.IsSubSetOf(webapp.Permissions()) {
+			return "", jsonapi.InvalidParameter("scope",
+				errors.New("the scope is larger than the permissions of the app"))
+		}
+	}
+	return GetInstance(c).BuildWebviewToken(webapp.Slug(), client.ID(), scope)
+}
+
 var shortCodeRegexp = regexp.MustCompile(`^(\d{6}|(\w|\d){12})\.?$`)
 
 // ExtractClaims parse a JWT, and extracts its claims (if valid).
@@ -280,6 +339,15 @@ func ParseJWT(c echo.Context, instance *instance.Instance, token string) (*permi
 			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
 			return nil, permission.ErrInvalidToken
 		}
+		if !client.CheckTokenGeneration(*claims) {
+			logger.WithNamespace("permissions").
+				Debugf("invalid token: revoked for client %s", client.ID())
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return nil, permission.ErrInvalidToken
+		}
+		if err := checkAPIQuota(c, instance, client); err != nil {
+			return nil, err
+		}
 		return GetForOauth(instance, claims, client)
 
 	case consts.CLIAudience:
@@ -295,6 +363,9 @@ func ParseJWT(c echo.Context, instance *instance.Instance, token string) (*permi
 		}
 		return pdoc, nil
 
+	case consts.WebviewAudience:
+		return getForWebview(instance, claims)
+
 	case consts.KonnectorAudience:
 		pdoc, err := permission.GetForKonnector(instance, claims.Subject)
 		if err != nil {
@@ -408,6 +479,11 @@ func GetPermission(c echo.Context) (*permission.Permission, error) {
 		return nil, err
 	}
 
+	pdoc, err = restrictToOrganizationUser(c, inst, pdoc)
+	if err != nil {
+		return nil, err
+	}
+
 	c.Set(contextPermissionDoc, pdoc)
 	return pdoc, nil
 }
@@ -422,6 +498,7 @@ func AllowWholeType(c echo.Context, v permission.Verb, doctype string) error {
 	if !pdoc.Permissions.AllowWholeType(v, doctype) {
 		return ErrForbidden
 	}
+	recordAccess(c, pdoc, v, doctype)
 	return nil
 }
 
@@ -434,6 +511,7 @@ func Allow(c echo.Context, v permission.Verb, o permission.Fetcher) error {
 	if !pdoc.Permissions.Allow(v, o) {
 		return ErrForbidden
 	}
+	recordAccess(c, pdoc, v, o.DocType())
 	return nil
 }
 
@@ -447,6 +525,7 @@ func AllowOnFields(c echo.Context, v permission.Verb, o permission.Fetcher, fiel
 	if !pdoc.Permissions.AllowOnFields(v, o, fields...) {
 		return ErrForbidden
 	}
+	recordAccess(c, pdoc, v, o.DocType())
 	return nil
 }
 
@@ -459,6 +538,7 @@ func AllowTypeAndID(c echo.Context, v permission.Verb, doctype, id string) error
 	if !pdoc.Permissions.AllowID(v, doctype, id) {
 		return ErrForbidden
 	}
+	recordAccess(c, pdoc, v, doctype)
 	return nil
 }
 
@@ -476,9 +556,22 @@ func AllowVFS(c echo.Context, v permission.Verb, o vfs.Fetcher) error {
 	if err != nil {
 		return ErrForbidden
 	}
+	recordAccess(c, pdoc, v, consts.Files)
 	return nil
 }
 
+// recordAccess counts the access to the doctype for the statistics shown to
+// the user.
+func recordAccess(c echo.Context, pdoc *permission.Permission, v permission.Verb, doctype string) {
+	source := accessstats.Source(pdoc)
+	if source == "" {
+		return
+	}
+	if inst, ok := GetInstanceSafe(c); ok {
+		accessstats.Record(inst, source, doctype, v)
+	}
+}
+
 // CanWriteToAnyDirectory checks that the context permission allows to write to
 // a directory on the VFS.
 func CanWriteToAnyDirectory(c echo.Context) error {
@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/labstack/echo/v4"
+)
+
+// checkAPIQuota counts the request made with an access token of an OAuth
+// client, and rejects it with a 429 Too Many Requests if the daily quota of
+// the client has been exceeded. The usage is sent in the X-RateLimit-*
+// headers of the response.
+func checkAPIQuota(c echo.Context, inst *instance.Instance, client *oauth.Client) error {
+	usage, err := client.CountAPIRequest(inst)
+	if usage == nil {
+		// The quota is not enforced when the counter is not available
+		inst.Logger().WithNamespace("oauth").
+			Warnf("Cannot count the API request of client %s: %s", client.ID(), err)
+		return nil
+	}
+	if usage.Unlimited() {
+		return nil
+	}
+	header := c.Response().Header()
+	header.Set("X-RateLimit-Limit", strconv.FormatInt(usage.Limit, 10))
+	header.Set("X-RateLimit-Remaining", strconv.FormatInt(usage.Remaining, 10))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(usage.ResetAt.Unix(), 10))
+	if errors.Is(err, oauth.ErrAPIQuotaExceeded) {
+		retry := int64(time.Until(usage.ResetAt).Seconds()) + 1
+		header.Set("Retry-After", strconv.FormatInt(retry, 10))
+		return jsonapi.NewError(http.StatusTooManyRequests, err.Error())
+	}
+	return nil
+}
@@ -143,6 +143,35 @@ func GetNoteText(c echo.Context) error {
 	return c.String(http.StatusOK, content)
 }
 
+// ExportNote is the API handler for GET /notes/:id/export?format=xxx. It
+// renders the note as a PDF or in markdown (a zip with the images if there are
+// some), and sends it as an attachment.
+func ExportNote(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fileID := c.Param("id")
+	file, err := inst.VFS().FileByID(fileID)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := middlewares.AllowVFS(c, permission.GET, file); err != nil {
+		return err
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = note.ExportMarkdown
+	}
+	export, err := note.ExportNote(inst, file, format)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	disposition := vfs.ContentDisposition("attachment", export.Filename)
+	c.Response().Header().Set(echo.HeaderContentDisposition, disposition)
+	return c.Blob(http.StatusOK, export.Mime, export.Content)
+}
+
 // GetSteps is the API handler for GET /notes/:id/steps?Version=xxx. It returns
 // the steps since the given version. If the version is too old, and the steps
 // are no longer available, it returns a 412 response with the whole document
@@ -274,6 +303,83 @@ func PutTelepointer(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// GetPresences is the API handler for GET /notes/:id/presence. It returns the
+// list of the users viewing the note, with their colors and the last position
+// of their pointers.
+func GetPresences(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fileID := c.Param("id")
+	file, err := inst.VFS().FileByID(fileID)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := middlewares.AllowVFS(c, permission.GET, file); err != nil {
+		return err
+	}
+
+	presences, err := note.ListPresences(inst, file.ID())
+	if err != nil {
+		return wrapError(err)
+	}
+	return presencesData(c, presences)
+}
+
+// PutPresence is the API handler for PUT /notes/:id/presence. It is used as a
+// heartbeat by the clients viewing the note, and it returns the list of the
+// users viewing it.
+func PutPresence(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fileID := c.Param("id")
+	file, err := inst.VFS().FileByID(fileID)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := middlewares.AllowVFS(c, permission.GET, file); err != nil {
+		return err
+	}
+
+	presence := &note.Presence{}
+	if _, err := jsonapi.Bind(c.Request().Body, presence); err != nil {
+		return err
+	}
+
+	presences, err := note.UpdatePresence(inst, file.ID(), presence)
+	if err != nil {
+		return wrapError(err)
+	}
+	return presencesData(c, presences)
+}
+
+// DeletePresence is the API handler for DELETE /notes/:id/presence/:session-id.
+// It is used by a client when the user stops viewing the note.
+func DeletePresence(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	fileID := c.Param("id")
+	file, err := inst.VFS().FileByID(fileID)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := middlewares.AllowVFS(c, permission.GET, file); err != nil {
+		return err
+	}
+
+	if err := note.LeavePresence(inst, file.ID(), c.Param("session-id")); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func presencesData(c echo.Context, presences []*note.Presence) error {
+	objs := make([]jsonapi.Object, len(presences))
+	for i, p := range presences {
+		objs[i] = p
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
 // ForceNoteSync is the API handler for POST /notes/:id/sync. It forces writing
 // the note to the VFS
 func ForceNoteSync(c echo.Context) error {
@@ -449,10 +555,14 @@ func Routes(router *echo.Group) {
 	router.GET("", ListNotes)
 	router.GET("/:id", GetNote)
 	router.GET("/:id/text", GetNoteText)
+	router.GET("/:id/export", ExportNote)
 	router.GET("/:id/steps", GetSteps)
 	router.PATCH("/:id", PatchNote)
 	router.PUT("/:id/title", ChangeTitle)
 	router.PUT("/:id/telepointer", PutTelepointer)
+	router.GET("/:id/presence", GetPresences)
+	router.PUT("/:id/presence", PutPresence)
+	router.DELETE("/:id/presence/:session-id", DeletePresence)
 	router.POST("/:id/sync", ForceNoteSync)
 	router.GET("/:id/open", OpenNoteURL)
 	router.PUT("/:id/schema", UpdateNoteSchema)
@@ -466,8 +576,10 @@ func wrapError(err error) *jsonapi.Error {
 		return jsonapi.InvalidAttribute("schema", err)
 	case note.ErrInvalidFile, sharing.ErrCannotOpenFile:
 		return jsonapi.NotFound(err)
-	case note.ErrNoSteps, note.ErrInvalidSteps:
+	case note.ErrNoSteps, note.ErrInvalidSteps, note.ErrMissingSessionID:
 		return jsonapi.BadRequest(err)
+	case note.ErrInvalidExportFormat:
+		return jsonapi.InvalidParameter("format", err)
 	case note.ErrCannotApply:
 		return jsonapi.Conflict(err)
 	case os.ErrNotExist, vfs.ErrParentDoesNotExist, vfs.ErrParentInTrash:
@@ -483,7 +595,7 @@ func wrapError(err error) *jsonapi.Error {
 func getCreatedBy(c echo.Context) string {
 	if claims, ok := c.Get("claims").(permission.Claims); ok {
 		switch claims.AudienceString() {
-		case consts.AppAudience, consts.KonnectorAudience:
+		case consts.AppAudience, consts.KonnectorAudience, consts.WebviewAudience:
 			return claims.Subject
 		}
 	}
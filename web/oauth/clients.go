@@ -43,7 +43,59 @@ func deleteClients(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"count": len(clients)})
 }
 
+type clientQuota struct {
+	APIQuota int64           `json:"api_quota"`
+	Usage    *oauth.APIUsage `json:"usage"`
+}
+
+func getClientQuota(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return err
+	}
+	client, err := oauth.FindClient(inst, c.Param("client-id"))
+	if err != nil {
+		return err
+	}
+	usage, err := client.GetAPIUsage(inst)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, clientQuota{APIQuota: client.APIQuota, Usage: usage})
+}
+
+func setClientQuota(c echo.Context) error {
+	inst, err := lifecycle.GetInstance(c.Param("domain"))
+	if err != nil {
+		return err
+	}
+	var args struct {
+		APIQuota *int64 `json:"api_quota"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&args); err != nil || args.APIQuota == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Missing api_quota")
+	}
+	if *args.APIQuota < oauth.UnlimitedAPIQuota {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid api_quota")
+	}
+	client, err := oauth.FindClient(inst, c.Param("client-id"))
+	if err != nil {
+		return err
+	}
+	client.APIQuota = *args.APIQuota
+	if err := couchdb.UpdateDoc(inst, client); err != nil {
+		return err
+	}
+	usage, err := client.GetAPIUsage(inst)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, clientQuota{APIQuota: client.APIQuota, Usage: usage})
+}
+
 // Routes sets the routing for the oauth clients (admin)
 func Routes(router *echo.Group) {
 	router.DELETE("/:domain/clients", deleteClients)
+	router.GET("/:domain/clients/:client-id/quota", getClientQuota)
+	router.PUT("/:domain/clients/:client-id/quota", setClientQuota)
 }
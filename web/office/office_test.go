@@ -222,6 +222,60 @@ func TestOffice(t *testing.T) {
 		assert.Equal(t, "onlyoffice-server", conflict.CozyMetadata.UpdatedByApps[0].Slug)
 		assert.NotEqual(t, conflictRev, conflict.Rev())
 	})
+
+	t.Run("Callbacks sent again", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		obj := e.GET("/office/"+fileID+"/open").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object()
+		key = obj.Path("$.data.attributes.onlyoffice.document.key").String().NotEmpty().Raw()
+
+		callback := func(status int, url string) {
+			e.POST("/office/callback").
+				WithHeader("Content-Type", "application/json").
+				WithBytes([]byte(fmt.Sprintf(`{
+      "key": "%s",
+      "status": %d,
+      "url": "%s",
+      "users": ["6d5a81d0"]
+    }`, key, status, url))).
+				Expect().Status(200).
+				JSON().Object().ValueEqual("error", 0.0)
+		}
+
+		// The editors are tracked
+		callback(1, "")
+
+		// A force save sent twice is saved only once
+		callback(6, ooURL+"/dl/1")
+		saved, err := inst.VFS().FileByID(fileID)
+		require.NoError(t, err)
+		callback(6, ooURL+"/dl/1")
+		again, err := inst.VFS().FileByID(fileID)
+		require.NoError(t, err)
+		assert.Equal(t, saved.Rev(), again.Rev())
+
+		// A final save sent twice is accepted, and saved only once
+		callback(2, ooURL+"/dl/2")
+		saved, err = inst.VFS().FileByID(fileID)
+		require.NoError(t, err)
+		callback(2, ooURL+"/dl/2")
+		again, err = inst.VFS().FileByID(fileID)
+		require.NoError(t, err)
+		assert.Equal(t, saved.Rev(), again.Rev())
+
+		// The key is not reused after the end of the session
+		obj = e.GET("/office/"+fileID+"/open").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200).
+			JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).
+			Object()
+		newkey := obj.Path("$.data.attributes.onlyoffice.document.key").String().NotEmpty().Raw()
+		assert.NotEqual(t, key, newkey)
+	})
 }
 
 func createFile(t *testing.T, inst *instance.Instance) string {
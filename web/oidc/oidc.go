@@ -2,7 +2,6 @@ package oidc
 
 import (
 	"crypto/rsa"
-	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -25,6 +24,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/limits"
 	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
 	"github.com/cozy/cozy-stack/web/auth"
 	"github.com/cozy/cozy-stack/web/middlewares"
 	"github.com/cozy/cozy-stack/web/statik"
@@ -305,7 +305,7 @@ func AccessToken(c echo.Context) error {
 			"error": "the client must be registered",
 		})
 	}
-	if subtle.ConstantTimeCompare([]byte(reqBody.ClientSecret), []byte(client.ClientSecret)) == 0 {
+	if !client.CheckSecret(reqBody.ClientSecret) {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": "invalid client_secret",
 		})
@@ -843,12 +843,15 @@ func renderError(c echo.Context, inst *instance.Instance, code int, msg string)
 // Careful, the normal middlewares NeedInstance and LoadSession are not applied
 // to this group in web/routing
 func Routes(router *echo.Group) {
-	router.GET("/start", Start, middlewares.NeedInstance, middlewares.CheckOnboardingNotFinished)
-	router.GET("/franceconnect", StartFranceConnect, middlewares.NeedInstance, middlewares.CheckOnboardingNotFinished)
+	checkAuth := middlewares.CheckNetworkAccess(netaccess.CategoryAuth)
+	router.GET("/start", Start, middlewares.NeedInstance, checkAuth, middlewares.CheckOnboardingNotFinished)
+	router.GET("/franceconnect", StartFranceConnect, middlewares.NeedInstance, checkAuth, middlewares.CheckOnboardingNotFinished)
+	// The redirect is on a shared domain, and it only redirects to the login
+	// route of the instance, where the rules are checked.
 	router.GET("/redirect", Redirect)
-	router.GET("/login", Login, middlewares.NeedInstance)
-	router.POST("/twofactor", TwoFactor, middlewares.NeedInstance)
-	router.POST("/access_token", AccessToken, middlewares.NeedInstance)
+	router.GET("/login", Login, middlewares.NeedInstance, checkAuth)
+	router.POST("/twofactor", TwoFactor, middlewares.NeedInstance, checkAuth, middlewares.CheckCSRF)
+	router.POST("/access_token", AccessToken, middlewares.NeedInstance, checkAuth)
 }
 
 // GetDelegatedCode is mostly a proxy for the userinfo request made by the
@@ -191,13 +191,13 @@ func TestOidc(t *testing.T) {
 		queryWithToken := redirectURL.Query()
 		queryWithToken.Add("token", "foo")
 
-		body := e.GET("/oidc/login").
+		res := e.GET("/oidc/login").
 			WithHost(testInstance.Domain).
 			WithRedirectPolicy(httpexpect.DontFollowRedirects).
 			WithQueryString(queryWithToken.Encode()).
-			Expect().Status(200).
-			ContentType("text/html").
-			Body()
+			Expect().Status(200)
+		csrfToken := res.Cookie("_csrf").Value().NotEmpty().Raw()
+		body := res.ContentType("text/html").Body()
 
 		body.Contains(`<form id="oidc-twofactor-form"`)
 		matches := body.Match(`name="access-token" value="(\w+)"`)
@@ -208,6 +208,8 @@ func TestOidc(t *testing.T) {
 		u = e.POST("/oidc/twofactor").
 			WithHost(testInstance.Domain).
 			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			WithCookie("_csrf", csrfToken).
+			WithFormField("csrf_token", csrfToken).
 			WithFormField("access-token", accessToken).
 			WithFormField("trusted-device-token", "").
 			WithFormField("redirect", "").
@@ -0,0 +1,235 @@
+// Package organization is for the routes to manage the users of an instance
+// in organization mode.
+package organization
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/organization"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// apiUser is used to hide the hash of the passphrase in the responses.
+type apiUser struct {
+	*organization.User
+}
+
+func (u *apiUser) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*organization.User
+		PassphraseHash []byte `json:"passphrase_hash,omitempty"`
+	}{
+		User: u.User,
+	})
+}
+
+type userAttrs struct {
+	Email      string            `json:"email"`
+	Name       string            `json:"name"`
+	Role       organization.Role `json:"role"`
+	Passphrase string            `json:"passphrase"`
+}
+
+type patchAttrs struct {
+	Name     *string            `json:"name"`
+	Role     *organization.Role `json:"role"`
+	Disabled *bool              `json:"disabled"`
+}
+
+type passphraseAttrs struct {
+	Current    string `json:"current_passphrase"`
+	Passphrase string `json:"passphrase"`
+}
+
+func listUsers(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.OrganizationUsers); err != nil {
+		return err
+	}
+	users, err := organization.List(inst)
+	if err != nil {
+		return wrapError(err)
+	}
+	objs := make([]jsonapi.Object, len(users))
+	for i, u := range users {
+		objs[i] = &apiUser{u}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func createUser(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.OrganizationUsers); err != nil {
+		return err
+	}
+	var attrs userAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	u := &organization.User{
+		Email: attrs.Email,
+		Name:  attrs.Name,
+		Role:  attrs.Role,
+	}
+	if err := organization.Create(inst, u, []byte(attrs.Passphrase)); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusCreated, &apiUser{u}, nil)
+}
+
+func getUser(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.OrganizationUsers); err != nil {
+		return err
+	}
+	u, err := organization.Find(inst, c.Param("id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiUser{u}, nil)
+}
+
+func patchUser(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PATCH, consts.OrganizationUsers); err != nil {
+		return err
+	}
+	u, err := organization.Find(inst, c.Param("id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	var attrs patchAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if attrs.Name != nil {
+		u.Name = *attrs.Name
+	}
+	if attrs.Role != nil {
+		u.Role = *attrs.Role
+	}
+	if attrs.Disabled != nil {
+		u.Disabled = *attrs.Disabled
+	}
+	if err := organization.Update(inst, u); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiUser{u}, nil)
+}
+
+func resetPassphrase(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.OrganizationUsers); err != nil {
+		return err
+	}
+	u, err := organization.Find(inst, c.Param("id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	var attrs passphraseAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if err := organization.SetPassphrase(inst, u, []byte(attrs.Passphrase)); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func deleteUser(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.OrganizationUsers); err != nil {
+		return err
+	}
+	u, err := organization.Find(inst, c.Param("id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := organization.Delete(inst, u); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getMe returns the user of the organization who makes the request.
+func getMe(c echo.Context) error {
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return err
+	}
+	u, ok := middlewares.GetOrganizationUser(c)
+	if !ok {
+		return jsonapi.NotFound(errors.New("the request is not made by a user of the organization"))
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiUser{u}, nil)
+}
+
+// updateMyPassphrase allows a user of the organization to change their own
+// passphrase.
+func updateMyPassphrase(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return err
+	}
+	u, ok := middlewares.GetOrganizationUser(c)
+	if !ok {
+		return jsonapi.NotFound(errors.New("the request is not made by a user of the organization"))
+	}
+	var attrs passphraseAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	if _, err := crypto.CompareHashAndPassphrase(u.PassphraseHash, []byte(attrs.Current)); err != nil {
+		return jsonapi.Forbidden(errors.New("invalid current passphrase"))
+	}
+	if err := organization.SetPassphrase(inst, u, []byte(attrs.Passphrase)); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func checkEnabled(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !middlewares.GetInstance(c).Organization {
+			return jsonapi.Forbidden(organization.ErrNotEnabled)
+		}
+		return next(c)
+	}
+}
+
+func wrapError(err error) error {
+	switch {
+	case errors.Is(err, organization.ErrNotEnabled):
+		return jsonapi.Forbidden(err)
+	case errors.Is(err, organization.ErrInvalidEmail):
+		return jsonapi.InvalidAttribute("email", err)
+	case errors.Is(err, organization.ErrInvalidRole):
+		return jsonapi.InvalidAttribute("role", err)
+	case errors.Is(err, organization.ErrPassphraseTooShort):
+		return jsonapi.InvalidAttribute("passphrase", err)
+	case errors.Is(err, organization.ErrEmailTaken):
+		return jsonapi.Conflict(err)
+	case couchdb.IsNotFoundError(err):
+		return jsonapi.NotFound(err)
+	}
+	return err
+}
+
+// Routes sets the routing for the users of an organization.
+func Routes(router *echo.Group) {
+	router.GET("/users", listUsers, checkEnabled)
+	router.POST("/users", createUser, checkEnabled)
+	router.GET("/users/:id", getUser, checkEnabled)
+	router.PATCH("/users/:id", patchUser, checkEnabled)
+	router.PUT("/users/:id/passphrase", resetPassphrase, checkEnabled)
+	router.DELETE("/users/:id", deleteUser, checkEnabled)
+	router.GET("/me", getMe, checkEnabled)
+	router.PUT("/me/passphrase", updateMyPassphrase, checkEnabled)
+}
@@ -0,0 +1,253 @@
+package photos
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/photo"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/metadata"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/justincampbell/bigduration"
+	"github.com/labstack/echo/v4"
+)
+
+// publicPath is the path of the public page of an album in the Photos
+// application.
+const publicPath = "/public"
+
+type apiAlbumLink struct {
+	*photo.AlbumLink
+	ShortCode string     `json:"shortcode,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired,omitempty"`
+	Password  bool       `json:"password,omitempty"`
+	URL       string     `json:"url,omitempty"`
+}
+
+func (l *apiAlbumLink) Included() []jsonapi.Object { return nil }
+func (l *apiAlbumLink) Links() *jsonapi.LinksList  { return nil }
+func (l *apiAlbumLink) Relationships() jsonapi.RelationshipMap {
+	return jsonapi.RelationshipMap{
+		"album": jsonapi.Relationship{
+			Data: couchdb.DocReference{ID: l.AlbumID, Type: consts.PhotosAlbums},
+		},
+	}
+}
+
+func newAPIAlbumLink(inst *instance.Instance, link *photo.AlbumLink, perm *permission.Permission) *apiAlbumLink {
+	res := &apiAlbumLink{AlbumLink: link}
+	if perm == nil {
+		return res
+	}
+	res.ExpiresAt = perm.ExpiresAt
+	res.Expired = perm.Expired()
+	res.Password = perm.Password != nil
+	if code := perm.ShortCodes[photo.AlbumLinkCode]; code != "" {
+		res.ShortCode = code
+		u := inst.SubDomain(consts.PhotosSlug)
+		u.Path = publicPath
+		u.RawQuery = url.Values{"sharecode": {code}}.Encode()
+		res.URL = u.String()
+	}
+	return res
+}
+
+// CreateAlbumLink is the API handler for POST /photos/albums/:id/links. It
+// creates a share-by-link permission for the album and its photos, with the
+// options of the public page.
+func CreateAlbumLink(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	albumID := c.Param("id")
+	if err := middlewares.AllowTypeAndID(c, permission.POST, consts.PhotosAlbums, albumID); err != nil {
+		return err
+	}
+	parent, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	if _, err := getAlbum(inst, albumID); err != nil {
+		return err
+	}
+
+	var attrs struct {
+		Download *bool  `json:"download"`
+		Password string `json:"password"`
+	}
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return err
+	}
+	download := attrs.Download == nil || *attrs.Download
+
+	var expiresAt *time.Time
+	if ttl := c.QueryParam("ttl"); ttl != "" {
+		d, err := bigduration.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return jsonapi.InvalidParameter("ttl", errors.New("Invalid duration"))
+		}
+		at := time.Now().Add(d)
+		expiresAt = &at
+	}
+
+	code, err := inst.CreateShareCode(photo.AlbumLinkCode)
+	if err != nil {
+		return err
+	}
+	codes := map[string]string{photo.AlbumLinkCode: code}
+	shortcodes := map[string]string{
+		photo.AlbumLinkCode: crypto.GenerateRandomString(consts.ShortCodeLen),
+	}
+
+	slug := consts.PhotosSlug
+	if claims, ok := c.Get("claims").(permission.Claims); ok && claims.Subject != "" {
+		slug = claims.Subject
+	}
+	md, err := metadata.NewWithApp(slug, "", permission.DocTypeVersion)
+	if err != nil {
+		return err
+	}
+	subdoc := permission.Permission{
+		Permissions: photo.AlbumRules(albumID),
+		Metadata:    md,
+	}
+	if attrs.Password != "" {
+		subdoc.Password = attrs.Password
+	}
+
+	perm, err := permission.CreateShareSet(inst, parent, parent.SourceID, codes, shortcodes, subdoc, expiresAt)
+	if err != nil {
+		return err
+	}
+	link, err := photo.CreateAlbumLink(inst, perm, albumID, download)
+	if err != nil {
+		_ = perm.Revoke(inst)
+		return err
+	}
+	return jsonapi.Data(c, http.StatusCreated, newAPIAlbumLink(inst, link, perm), nil)
+}
+
+// ListAlbumLinks is the API handler for GET /photos/albums/:id/links. It
+// returns the public links of the album, with their number of views.
+func ListAlbumLinks(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	albumID := c.Param("id")
+	if err := middlewares.AllowTypeAndID(c, permission.POST, consts.PhotosAlbums, albumID); err != nil {
+		return err
+	}
+	links, err := photo.ListAlbumLinks(inst, albumID)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, 0, len(links))
+	for _, link := range links {
+		var perm permission.Permission
+		if err := couchdb.GetDoc(inst, consts.Permissions, link.ID(), &perm); err != nil {
+			if couchdb.IsNotFoundError(err) {
+				// The permission has been revoked from another place
+				continue
+			}
+			return err
+		}
+		objs = append(objs, newAPIAlbumLink(inst, link, &perm))
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+// DeleteAlbumLink is the API handler for DELETE
+// /photos/albums/:id/links/:link-id. It revokes a public link.
+func DeleteAlbumLink(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	albumID := c.Param("id")
+	if err := middlewares.AllowTypeAndID(c, permission.POST, consts.PhotosAlbums, albumID); err != nil {
+		return err
+	}
+	link, err := photo.GetAlbumLink(inst, c.Param("link-id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if link.AlbumID != albumID {
+		return jsonapi.NotFound(errors.New("Link not found"))
+	}
+	if err := photo.DeleteAlbumLink(inst, link); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RecordAlbumView is the API handler for POST /photos/albums/:id/views. It is
+// called by the public page of an album to count the views of the link.
+func RecordAlbumView(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	albumID := c.Param("id")
+	if err := middlewares.AllowTypeAndID(c, permission.GET, consts.PhotosAlbums, albumID); err != nil {
+		return err
+	}
+	perm, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	if perm.Type != permission.TypeShareByLink {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if _, err := photo.RecordAlbumView(inst, perm.ID()); err != nil && !couchdb.IsNotFoundError(err) {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DownloadAlbum is the API handler for GET /photos/albums/:id/download. It
+// streams a zip with all the photos of the album. For a public link, the
+// download must have been allowed when the link was created.
+func DownloadAlbum(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	albumID := c.Param("id")
+	if err := middlewares.AllowTypeAndID(c, permission.GET, consts.PhotosAlbums, albumID); err != nil {
+		return err
+	}
+	perm, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	if perm.Type == permission.TypeShareByLink {
+		link, err := photo.GetAlbumLink(inst, perm.ID())
+		if err == nil && !link.Download {
+			return jsonapi.Forbidden(errors.New("The download is disabled for this link"))
+		}
+		if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+			return err
+		}
+	}
+
+	album, err := getAlbum(inst, albumID)
+	if err != nil {
+		return err
+	}
+	name, _ := album.M["name"].(string)
+	archive, err := photo.AlbumArchive(inst, albumID, name)
+	if err != nil {
+		return err
+	}
+	return archive.Serve(inst.VFS(), c.Response())
+}
+
+func getAlbum(inst *instance.Instance, albumID string) (*couchdb.JSONDoc, error) {
+	var album couchdb.JSONDoc
+	if err := couchdb.GetDoc(inst, consts.PhotosAlbums, albumID, &album); err != nil {
+		return nil, wrapError(err)
+	}
+	return &album, nil
+}
+
+func wrapError(err error) error {
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	return err
+}
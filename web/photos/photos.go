@@ -0,0 +1,85 @@
+// Package photos is for the routes used by the Photos application, like the
+// geo-clusters for the map view and the public links to the albums.
+package photos
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/photo"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// ListGeoClusters is the API handler for GET /photos/geoclusters. It returns
+// the clusters of photos for the given zoom level and bounding box.
+func ListGeoClusters(c echo.Context) error {
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.PhotosGeoClusters); err != nil {
+		return err
+	}
+
+	zoom := 0
+	if z := c.QueryParam("zoom"); z != "" {
+		var err error
+		zoom, err = strconv.Atoi(z)
+		if err != nil || zoom < 0 {
+			return jsonapi.InvalidParameter("zoom", errors.New("Invalid zoom level"))
+		}
+	}
+	var bbox *photo.BBox
+	if b := c.QueryParam("bbox"); b != "" {
+		var err error
+		bbox, err = parseBBox(b)
+		if err != nil {
+			return jsonapi.InvalidParameter("bbox", err)
+		}
+	}
+
+	inst := middlewares.GetInstance(c)
+	clusters, err := photo.ListClusters(inst, photo.PrecisionForZoom(zoom), bbox)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(clusters))
+	for i, cluster := range clusters {
+		objs[i] = cluster
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+// parseBBox parses a bounding box in the west,south,east,north format.
+func parseBBox(value string) (*photo.BBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("The bounding box must be west,south,east,north")
+	}
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		coords[i] = f
+	}
+	bbox := &photo.BBox{West: coords[0], South: coords[1], East: coords[2], North: coords[3]}
+	if bbox.South > bbox.North {
+		return nil, errors.New("The south must be lower than the north")
+	}
+	return bbox, nil
+}
+
+// Routes sets the routing for the photos.
+func Routes(router *echo.Group) {
+	router.GET("/geoclusters", ListGeoClusters)
+
+	router.POST("/albums/:id/links", CreateAlbumLink)
+	router.GET("/albums/:id/links", ListAlbumLinks)
+	router.DELETE("/albums/:id/links/:link-id", DeleteAlbumLink)
+	router.POST("/albums/:id/views", RecordAlbumView)
+	router.GET("/albums/:id/download", DownloadAlbum)
+}
@@ -5,11 +5,13 @@ package public
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/cozy/cozy-stack/model/bitwarden/settings"
 	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/assets"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/web/middlewares"
@@ -17,9 +19,17 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// Avatar returns the default avatar currently.
+// Avatar returns the image chosen by the user as their avatar, or a fallback
+// if there is none (or if the profile is private).
 func Avatar(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
+	if profile, err := csettings.GetProfile(inst); err == nil && profile.IsPublic() && profile.AvatarID != "" {
+		fs := inst.VFS()
+		file, err := fs.FileByID(profile.AvatarID)
+		if err == nil && !file.Trashed && file.Class == "image" {
+			return vfs.ServeFileContent(fs, file, nil, "", "inline", c.Request(), c.Response())
+		}
+	}
 	switch c.QueryParam("fallback") {
 	case "404":
 		// Nothing
@@ -43,6 +53,25 @@ func Avatar(c echo.Context) error {
 	return echo.NewHTTPError(http.StatusNotFound, "Page not found")
 }
 
+// Profile returns the public profile of the instance owner. It is used for
+// the sharings, to show who is the sharer to the recipients. When the profile
+// is private, only the display name is given.
+func Profile(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	profile, err := csettings.GetProfile(inst)
+	if err != nil {
+		return err
+	}
+	res := echo.Map{
+		"display_name": profile.DisplayName,
+		"avatar":       inst.PageURL("/public/avatar", url.Values{"fallback": {"initials"}}),
+	}
+	if profile.IsPublic() && profile.Bio != "" {
+		res["bio"] = profile.Bio
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
 // Prelogin returns information that could be useful to show a login page (like
 // in the flagship app).
 func Prelogin(c echo.Context) error {
@@ -74,11 +103,29 @@ func Prelogin(c echo.Context) error {
 	})
 }
 
+// LoginUI returns the composition of the login and onboarding pages, as
+// configured for the context of the instance. It is used by the flagship app
+// to show the same choices as the login page of the stack.
+func LoginUI(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	ui := csettings.GetLoginUI(inst)
+	logo := statik.AssetPath("", ui.Logo, inst.ContextName)
+	if strings.HasPrefix(logo, "/") && !strings.HasPrefix(logo, "//") {
+		logo = inst.PageURL(logo, nil)
+	}
+	ui.Logo = logo
+	return c.JSON(http.StatusOK, ui)
+}
+
 // Routes sets the routing for the public service
 func Routes(router *echo.Group) {
 	cacheControl := middlewares.CacheControl(middlewares.CacheOptions{
 		MaxAge: 24 * time.Hour,
 	})
 	router.GET("/avatar", Avatar, cacheControl)
+	router.GET("/profile", Profile)
 	router.GET("/prelogin", Prelogin)
+	router.GET("/login-ui", LoginUI)
+	router.GET("/oembed", OEmbed)
+	router.GET("/share-preview/image", SharePreviewImage)
 }
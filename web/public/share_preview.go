@@ -0,0 +1,257 @@
+package public
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// The thumbnail format used for the previews, and its bounding box.
+const (
+	previewThumbFormat = "medium"
+	previewThumbWidth  = 1280
+	previewThumbHeight = 720
+)
+
+// OpenGraphConfig is the configuration of the previews of the share by link
+// pages, that can be set per context with the open_graph parameter.
+type OpenGraphConfig struct {
+	// Enabled can be used to disable the previews.
+	Enabled bool
+	// ShowName is true if the name of the shared file can be put in the
+	// preview. When false, a generic title is used.
+	ShowName bool
+	// Thumbnail is true if the thumbnail of a shared image can be put in the
+	// preview. It is false by default, as the chat applications will often
+	// make a copy of the image.
+	Thumbnail bool
+}
+
+// GetOpenGraphConfig returns the configuration for the previews of the share
+// by link pages of the given instance.
+func GetOpenGraphConfig(inst *instance.Instance) OpenGraphConfig {
+	cfg := OpenGraphConfig{Enabled: true, ShowName: true}
+	settings, ok := inst.SettingsContext()
+	if !ok {
+		return cfg
+	}
+	og, ok := settings["open_graph"].(map[string]interface{})
+	if !ok {
+		return cfg
+	}
+	if enabled, ok := og["enabled"].(bool); ok {
+		cfg.Enabled = enabled
+	}
+	if showName, ok := og["show_name"].(bool); ok {
+		cfg.ShowName = showName
+	}
+	if thumbnail, ok := og["thumbnail"].(bool); ok {
+		cfg.Thumbnail = thumbnail
+	}
+	return cfg
+}
+
+// SharePreview is the metadata about a share by link, used for the OpenGraph
+// meta tags and the oEmbed endpoint.
+type SharePreview struct {
+	Title       string
+	Description string
+	Author      string
+	// Mime is the content type of the shared file (empty for a directory).
+	Mime        string
+	ImageURL    string
+	ImageWidth  int
+	ImageHeight int
+}
+
+// GetSharePreview returns the preview for the share by link with the given
+// sharecode, or nil if there is no preview to show for it. When the link is
+// protected by a password, only a generic preview is returned.
+func GetSharePreview(inst *instance.Instance, sharecode string) *SharePreview {
+	cfg := GetOpenGraphConfig(inst)
+	if !cfg.Enabled || sharecode == "" {
+		return nil
+	}
+	pdoc, err := shareByLinkPermission(inst, sharecode)
+	if err != nil {
+		return nil
+	}
+
+	author, err := csettings.PublicName(inst)
+	if err != nil {
+		author = strings.Split(inst.Domain, ".")[0]
+	}
+	preview := &SharePreview{
+		Title:       inst.Translate("Share Preview Title", author),
+		Description: inst.Translate("Share Preview Description", author),
+		Author:      author,
+	}
+	if pdoc.Password != nil {
+		return preview
+	}
+
+	dir, file := sharedFileOrDir(inst, pdoc)
+	switch {
+	case file != nil:
+		preview.Mime = file.Mime
+		if cfg.ShowName {
+			preview.Title = file.DocName
+		}
+		if cfg.Thumbnail && file.Class == "image" {
+			preview.ImageURL = inst.PageURL("/public/share-preview/image", url.Values{
+				"sharecode": {sharecode},
+			})
+			preview.ImageWidth, preview.ImageHeight = thumbSize(file)
+		}
+	case dir != nil:
+		if cfg.ShowName {
+			preview.Title = dir.DocName
+		}
+	}
+	return preview
+}
+
+// shareByLinkPermission returns the permission document of a share by link,
+// for the given sharecode (or shortcode).
+func shareByLinkPermission(inst *instance.Instance, sharecode string) (*permission.Permission, error) {
+	token, err := middlewares.TransformShortcodeToJWT(inst, sharecode)
+	if err != nil {
+		return nil, err
+	}
+	pdoc, err := permission.GetForShareCode(inst, token)
+	if err != nil {
+		return nil, err
+	}
+	if pdoc.Type != permission.TypeShareByLink || pdoc.Expired() {
+		return nil, permission.ErrExpiredToken
+	}
+	return pdoc, nil
+}
+
+// sharedFileOrDir returns the file or directory shared by the permission, if
+// there is only one and it can be read via the link (a drop box, where the
+// link only allows to upload files, doesn't give access to the folder name).
+func sharedFileOrDir(inst *instance.Instance, pdoc *permission.Permission) (*vfs.DirDoc, *vfs.FileDoc) {
+	for _, rule := range pdoc.Permissions {
+		if rule.Type != consts.Files || rule.Selector != "" || len(rule.Values) != 1 {
+			continue
+		}
+		if !rule.Verbs.Contains(permission.GET) {
+			continue
+		}
+		dir, file, err := inst.VFS().DirOrFileByID(rule.Values[0])
+		if err != nil {
+			return nil, nil
+		}
+		if file != nil && file.Trashed {
+			return nil, nil
+		}
+		if dir != nil && strings.HasPrefix(dir.Fullpath, vfs.TrashDirName) {
+			return nil, nil
+		}
+		return dir, file
+	}
+	return nil, nil
+}
+
+// thumbSize returns the size of the medium thumbnail for the given image, or
+// 0x0 if it is not known.
+func thumbSize(file *vfs.FileDoc) (int, int) {
+	width := metadataInt(file.Metadata, "width")
+	height := metadataInt(file.Metadata, "height")
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	if width > previewThumbWidth {
+		height = height * previewThumbWidth / width
+		width = previewThumbWidth
+	}
+	if height > previewThumbHeight {
+		width = width * previewThumbHeight / height
+		height = previewThumbHeight
+	}
+	return width, height
+}
+
+func metadataInt(meta vfs.Metadata, key string) int {
+	switch v := meta[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// SharePreviewImage serves the thumbnail of an image shared by link, when the
+// thumbnails are allowed in the previews.
+func SharePreviewImage(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	preview := GetSharePreview(inst, c.QueryParam("sharecode"))
+	if preview == nil || preview.ImageURL == "" {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+	pdoc, err := shareByLinkPermission(inst, c.QueryParam("sharecode"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+	_, file := sharedFileOrDir(inst, pdoc)
+	if file == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+	err = inst.ThumbsFS().ServeThumbContent(c.Response(), c.Request(), file, previewThumbFormat)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+	return nil
+}
+
+// OEmbed implements the oEmbed endpoint for the share by link pages. See
+// https://oembed.com/
+func OEmbed(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" {
+		return echo.NewHTTPError(http.StatusNotImplemented, "Only the json format is supported")
+	}
+	inst := middlewares.GetInstance(c)
+	u, err := url.Parse(c.QueryParam("url"))
+	if err != nil || u.Host == "" {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+	host, _, _ := config.SplitCozyHost(u.Host)
+	if !strings.EqualFold(host, inst.Domain) {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+	preview := GetSharePreview(inst, u.Query().Get("sharecode"))
+	if preview == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Page not found")
+	}
+
+	res := echo.Map{
+		"version":       "1.0",
+		"type":          "link",
+		"title":         preview.Title,
+		"author_name":   preview.Author,
+		"provider_name": inst.TemplateTitle(),
+		"provider_url":  inst.PageURL("/", nil),
+		"cache_age":     int(time.Hour.Seconds()),
+	}
+	if preview.ImageURL != "" && preview.ImageWidth > 0 {
+		res["thumbnail_url"] = preview.ImageURL
+		res["thumbnail_width"] = preview.ImageWidth
+		res["thumbnail_height"] = preview.ImageHeight
+	}
+	return c.JSON(http.StatusOK, res)
+}
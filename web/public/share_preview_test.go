@@ -0,0 +1,215 @@
+package public
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/permission"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/utils"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/cozy/cozy-stack/web/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharePreview(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance(&lifecycle.Options{ContextName: "preview"})
+
+	conf := config.GetConfig()
+	og := map[string]interface{}{}
+	conf.Contexts["preview"] = map[string]interface{}{"open_graph": og}
+	t.Cleanup(func() { delete(conf.Contexts, "preview") })
+
+	ts := setup.GetTestServer("/public", Routes)
+	ts.Config.Handler.(*echo.Echo).HTTPErrorHandler = errors.ErrorHandler
+	t.Cleanup(ts.Close)
+
+	author, err := csettings.PublicName(inst)
+	require.NoError(t, err)
+	genericTitle := inst.Translate("Share Preview Title", author)
+
+	fs := inst.VFS()
+	dir, err := vfs.Mkdir(fs, "/Photos", nil)
+	require.NoError(t, err)
+	filedoc, err := vfs.NewFileDoc("cat.png", dir.ID(), 3, nil, "image/png", "image", time.Now(), false, false, false, nil)
+	require.NoError(t, err)
+	filedoc.Metadata = vfs.Metadata{"width": 2000, "height": 1000}
+	f, err := fs.CreateFile(filedoc, nil)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("cat"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	share := func(t *testing.T, id string, verbs permission.VerbSet, password string, expiresAt *time.Time) string {
+		code := utils.RandomString(32)
+		pdoc := &permission.Permission{
+			Type: permission.TypeShareByLink,
+			Permissions: permission.Set{
+				permission.Rule{Type: consts.Files, Verbs: verbs, Values: []string{id}},
+			},
+			Codes:     map[string]string{"email": code},
+			ExpiresAt: expiresAt,
+		}
+		if password != "" {
+			pdoc.Password = password
+		}
+		require.NoError(t, couchdb.CreateDoc(inst, pdoc))
+		return code
+	}
+	setOpenGraph := func(showName, thumbnail bool) {
+		og["show_name"] = showName
+		og["thumbnail"] = thumbnail
+	}
+
+	fileCode := share(t, filedoc.ID(), permission.Verbs(permission.GET), "", nil)
+	dirCode := share(t, dir.ID(), permission.Verbs(permission.GET), "", nil)
+
+	t.Run("File", func(t *testing.T) {
+		setOpenGraph(true, false)
+		preview := GetSharePreview(inst, fileCode)
+		require.NotNil(t, preview)
+		assert.Equal(t, "cat.png", preview.Title)
+		assert.Equal(t, "image/png", preview.Mime)
+		assert.Equal(t, author, preview.Author)
+		assert.Empty(t, preview.ImageURL)
+
+		preview = GetSharePreview(inst, dirCode)
+		require.NotNil(t, preview)
+		assert.Equal(t, "Photos", preview.Title)
+		assert.Empty(t, preview.Mime)
+	})
+
+	t.Run("ShowName", func(t *testing.T) {
+		setOpenGraph(false, false)
+		preview := GetSharePreview(inst, fileCode)
+		require.NotNil(t, preview)
+		assert.Equal(t, genericTitle, preview.Title)
+
+		preview = GetSharePreview(inst, dirCode)
+		require.NotNil(t, preview)
+		assert.Equal(t, genericTitle, preview.Title)
+	})
+
+	t.Run("Thumbnail", func(t *testing.T) {
+		setOpenGraph(true, true)
+		preview := GetSharePreview(inst, fileCode)
+		require.NotNil(t, preview)
+		assert.Contains(t, preview.ImageURL, "/public/share-preview/image?sharecode="+fileCode)
+		assert.Equal(t, 1280, preview.ImageWidth)
+		assert.Equal(t, 640, preview.ImageHeight)
+
+		// No thumbnail has been generated for this image
+		e := testutils.CreateTestClient(t, ts.URL)
+		e.GET("/public/share-preview/image").
+			WithQuery("sharecode", fileCode).
+			WithHost(inst.Domain).
+			Expect().Status(404)
+
+		setOpenGraph(true, false)
+		e.GET("/public/share-preview/image").
+			WithQuery("sharecode", fileCode).
+			WithHost(inst.Domain).
+			Expect().Status(404)
+	})
+
+	t.Run("Password", func(t *testing.T) {
+		setOpenGraph(true, true)
+		code := share(t, filedoc.ID(), permission.Verbs(permission.GET), "secret", nil)
+		preview := GetSharePreview(inst, code)
+		require.NotNil(t, preview)
+		assert.Equal(t, genericTitle, preview.Title)
+		assert.Empty(t, preview.Mime)
+		assert.Empty(t, preview.ImageURL)
+	})
+
+	t.Run("DropBox", func(t *testing.T) {
+		setOpenGraph(true, false)
+		code := share(t, dir.ID(), permission.Verbs(permission.POST), "", nil)
+		preview := GetSharePreview(inst, code)
+		require.NotNil(t, preview)
+		assert.Equal(t, genericTitle, preview.Title)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		setOpenGraph(true, false)
+		past := time.Now().Add(-time.Hour)
+		code := share(t, filedoc.ID(), permission.Verbs(permission.GET), "", &past)
+		assert.Nil(t, GetSharePreview(inst, code))
+		assert.Nil(t, GetSharePreview(inst, "not-a-sharecode"))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		og["enabled"] = false
+		defer delete(og, "enabled")
+		assert.Nil(t, GetSharePreview(inst, fileCode))
+	})
+
+	t.Run("OEmbed", func(t *testing.T) {
+		setOpenGraph(true, false)
+		e := testutils.CreateTestClient(t, ts.URL)
+		shareURL := inst.SubDomain(consts.DriveSlug)
+		shareURL.RawQuery = "sharecode=" + fileCode
+
+		obj := e.GET("/public/oembed").
+			WithQuery("url", shareURL.String()).
+			WithHost(inst.Domain).
+			Expect().Status(200).
+			JSON().Object()
+		obj.HasValue("version", "1.0")
+		obj.HasValue("type", "link")
+		obj.HasValue("title", "cat.png")
+		obj.HasValue("author_name", author)
+		obj.NotContainsKey("thumbnail_url")
+
+		e.GET("/public/oembed").
+			WithQuery("url", shareURL.String()).
+			WithQuery("format", "xml").
+			WithHost(inst.Domain).
+			Expect().Status(501)
+
+		// The url must be on the instance of the request
+		e.GET("/public/oembed").
+			WithQuery("url", "https://drive.example.org/?sharecode="+fileCode).
+			WithHost(inst.Domain).
+			Expect().Status(404)
+
+		e.GET("/public/oembed").
+			WithQuery("url", "/?sharecode="+fileCode).
+			WithHost(inst.Domain).
+			Expect().Status(404)
+	})
+
+	t.Run("Trashed", func(t *testing.T) {
+		setOpenGraph(true, false)
+		file, err := fs.FileByID(filedoc.ID())
+		require.NoError(t, err)
+		_, err = vfs.TrashFile(fs, file)
+		require.NoError(t, err)
+		preview := GetSharePreview(inst, fileCode)
+		require.NotNil(t, preview)
+		assert.Equal(t, genericTitle, preview.Title)
+		assert.Empty(t, preview.Mime)
+
+		folder, err := fs.DirByID(dir.ID())
+		require.NoError(t, err)
+		_, err = vfs.TrashDir(fs, folder)
+		require.NoError(t, err)
+		preview = GetSharePreview(inst, dirCode)
+		require.NotNil(t, preview)
+		assert.Equal(t, genericTitle, preview.Title)
+	})
+}
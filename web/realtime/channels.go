@@ -0,0 +1,139 @@
+package realtime
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+)
+
+// channelSub is a subscription made on a websocket, with the channel chosen
+// by the client (it can be empty).
+type channelSub struct {
+	channel  string
+	doctype  string
+	id       string
+	selector mango.Map
+}
+
+func (s channelSub) sameTarget(other channelSub) bool {
+	return s.doctype == other.doctype && s.id == other.id &&
+		reflect.DeepEqual(s.selector, other.selector)
+}
+
+func (s channelSub) isWhole() bool {
+	return s.id == "" && s.selector == nil
+}
+
+func (s channelSub) matches(e *realtime.Event) bool {
+	if s.doctype != e.Doc.DocType() {
+		return false
+	}
+	if s.selector != nil {
+		return e.MatchesSelector(s.selector)
+	}
+	return s.id == "" || s.id == e.Doc.ID()
+}
+
+// channelSubs keeps the subscriptions of a websocket, to know the channels
+// that must be echoed in the events. The hub knows only the subscriptions
+// without their channels, so a subscription is sent to the hub only for the
+// first channel, and removed from the hub with the last one.
+type channelSubs struct {
+	mu   sync.Mutex
+	subs []channelSub
+}
+
+// add registers the subscription, and returns true if the hub must be told
+// about it.
+func (cs *channelSubs) add(sub channelSub) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	first := true
+	for _, s := range cs.subs {
+		if s.sameTarget(sub) {
+			if s.channel == sub.channel {
+				return false
+			}
+			first = false
+		}
+	}
+	cs.subs = append(cs.subs, sub)
+	return first
+}
+
+// remove unregisters the subscription. Like for the hub, removing the
+// subscription to a whole doctype also removes the subscriptions on its
+// documents, but only for the same channel. It returns the subscriptions that
+// the hub must forget, and the ones that must be sent again to the hub after
+// that (unsubscribing from a whole doctype in the hub removes all the
+// subscriptions on this doctype, including the ones of the other channels).
+func (cs *channelSubs) remove(sub channelSub) (unsub, restore []channelSub) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var removed []channelSub
+	kept := cs.subs[:0]
+	for _, s := range cs.subs {
+		if s.channel == sub.channel &&
+			(s.sameTarget(sub) || (sub.isWhole() && s.doctype == sub.doctype)) {
+			removed = append(removed, s)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	cs.subs = kept
+
+	for _, s := range removed {
+		if !containsTarget(cs.subs, s) {
+			unsub = append(unsub, s)
+		}
+	}
+	for _, s := range unsub {
+		if s.isWhole() {
+			for _, other := range cs.subs {
+				if other.doctype == s.doctype && !containsTarget(restore, other) {
+					restore = append(restore, other)
+				}
+			}
+			return []channelSub{s}, restore
+		}
+	}
+	return unsub, nil
+}
+
+func containsTarget(subs []channelSub, sub channelSub) bool {
+	for _, s := range subs {
+		if s.sameTarget(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// channelsFor returns the channels of the subscriptions that match the
+// event. An empty string is used for the subscriptions without channel.
+func (cs *channelSubs) channelsFor(e *realtime.Event) []string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	var channels []string
+	for _, s := range cs.subs {
+		if !s.matches(e) {
+			continue
+		}
+		dup := false
+		for _, c := range channels {
+			if c == s.channel {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			channels = append(channels, s.channel)
+		}
+	}
+	if len(channels) == 0 {
+		channels = []string{""}
+	}
+	return channels
+}
@@ -2,16 +2,19 @@ package realtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/couchdb/mango"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/prefixer"
@@ -32,7 +35,7 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 
 	// Maximum message size allowed from peer
-	maxMessageSize = 1024
+	maxMessageSize = 4096
 )
 
 var upgrader = websocket.Upgrader{
@@ -46,8 +49,10 @@ var upgrader = websocket.Upgrader{
 type command struct {
 	Method  string `json:"method"`
 	Payload struct {
-		Type string `json:"type"`
-		ID   string `json:"id"`
+		Type     string    `json:"type"`
+		ID       string    `json:"id"`
+		Selector mango.Map `json:"selector,omitempty"`
+		Channel  string    `json:"channel,omitempty"`
 	} `json:"payload"`
 }
 
@@ -59,9 +64,23 @@ type wsResponsePayload struct {
 
 type wsResponse struct {
 	Event   string            `json:"event"`
+	Channel string            `json:"channel,omitempty"`
 	Payload wsResponsePayload `json:"payload"`
 }
 
+// lossyEvent is sent when some events have been dropped because the client
+// was too slow to read them: it should reload its data for these doctypes.
+const lossyEvent = "LOSSY"
+
+type wsLossyPayload struct {
+	Dropped map[string]int `json:"dropped"`
+}
+
+type wsLossy struct {
+	Event   string         `json:"event"`
+	Payload wsLossyPayload `json:"payload"`
+}
+
 type wsErrorPayload struct {
 	Status string      `json:"status"`
 	Code   string      `json:"code"`
@@ -122,6 +141,18 @@ func missingType(cmd *command) *wsError {
 	}
 }
 
+func invalidSelector(cmd *command, err error) *wsError {
+	return &wsError{
+		Event: "error",
+		Payload: wsErrorPayload{
+			Status: "400 Bad Request",
+			Code:   "bad request",
+			Title:  fmt.Sprintf("The selector is invalid: %s", err),
+			Source: cmd,
+		},
+	}
+}
+
 func sendErr(ctx context.Context, errc chan *wsError, e *wsError) {
 	select {
 	case errc <- e:
@@ -148,8 +179,38 @@ func authorized(i *instance.Instance, perms permission.Set, permType, id string)
 	}
 }
 
+// canSubscribe tells if the permissions allow to receive the events for the
+// given doctype (and id if not empty).
+func canSubscribe(i *instance.Instance, perms permission.Set, doctype, id string) bool {
+	// XXX: no permissions are required for io.cozy.sharings.initial_sync
+	// and io.cozy.auth.confirmations
+	if doctype == consts.SharingsInitialSync || doctype == consts.AuthConfirmations {
+		return true
+	}
+	permType := doctype
+	permID := id
+	// XXX: thumbnails is a synthetic doctype, listening to its events
+	// requires a permissions on io.cozy.files. Same for note events, the
+	// qualifications, and the office sessions.
+	if permType == consts.Thumbnails || permType == consts.NotesEvents ||
+		permType == consts.FilesQualifications || permType == consts.OfficeSessions {
+		permType = consts.Files
+	}
+	// XXX: the progress events have the identifier of their job, and a
+	// permission on the jobs is required to listen to them.
+	if permType == consts.JobsProgress {
+		permType = consts.Jobs
+	}
+	// XXX: the passphrase settings document is synthetic, and a
+	// permission on the instance settings is enough to watch it.
+	if permType == consts.Settings && permID == consts.PassphraseParametersID {
+		permID = consts.InstanceSettingsID
+	}
+	return authorized(i, perms, permType, permID)
+}
+
 func readPump(ctx context.Context, c echo.Context, i *instance.Instance, ws *websocket.Conn,
-	ds *realtime.Subscriber, errc chan *wsError, withAuthentication bool) {
+	ds *realtime.Subscriber, subs *channelSubs, errc chan *wsError, withAuthentication bool) {
 	defer close(errc)
 
 	var err error
@@ -197,45 +258,63 @@ func readPump(ctx context.Context, c echo.Context, i *instance.Instance, ws *web
 			sendErr(ctx, errc, missingType(cmd))
 			continue
 		}
-		permType := cmd.Payload.Type
-		permID := cmd.Payload.ID
-		// XXX: thumbnails is a synthetic doctype, listening to its events
-		// requires a permissions on io.cozy.files. Same for note events.
-		if permType == consts.Thumbnails || permType == consts.NotesEvents {
-			permType = consts.Files
-		}
-		// XXX: the passphrase settings document is synthetic, and a
-		// permission on the instance settings is enough to watch it.
-		if permType == consts.Settings && permID == consts.PassphraseParametersID {
-			permID = consts.InstanceSettingsID
-		}
-		// XXX: no permissions are required for io.cozy.sharings.initial_sync
-		// and io.cozy.auth.confirmations
-		if withAuthentication &&
-			cmd.Payload.Type != consts.SharingsInitialSync &&
-			cmd.Payload.Type != consts.AuthConfirmations {
-			if !authorized(i, pdoc.Permissions, permType, permID) {
-				sendErr(ctx, errc, forbidden(cmd))
+		if cmd.Payload.Selector != nil {
+			if cmd.Payload.ID != "" {
+				sendErr(ctx, errc, invalidSelector(cmd, errors.New("it can't be used with an id")))
+				continue
+			}
+			if err := mango.ValidateSelector(cmd.Payload.Selector); err != nil {
+				sendErr(ctx, errc, invalidSelector(cmd, err))
 				continue
 			}
 		}
+		if withAuthentication && !canSubscribe(i, pdoc.Permissions, cmd.Payload.Type, cmd.Payload.ID) {
+			sendErr(ctx, errc, forbidden(cmd))
+			continue
+		}
 
+		sub := channelSub{
+			channel:  cmd.Payload.Channel,
+			doctype:  cmd.Payload.Type,
+			id:       cmd.Payload.ID,
+			selector: cmd.Payload.Selector,
+		}
 		if method == "SUBSCRIBE" {
-			if cmd.Payload.ID == "" {
-				ds.Subscribe(cmd.Payload.Type)
-			} else {
-				ds.Watch(cmd.Payload.Type, cmd.Payload.ID)
+			if subs.add(sub) {
+				subscribe(ds, sub)
 			}
 		} else if method == "UNSUBSCRIBE" {
-			if cmd.Payload.ID == "" {
-				ds.Unsubscribe(cmd.Payload.Type)
-			} else {
-				ds.Unwatch(cmd.Payload.Type, cmd.Payload.ID)
+			unsub, restore := subs.remove(sub)
+			for _, s := range unsub {
+				unsubscribe(ds, s)
+			}
+			for _, s := range restore {
+				subscribe(ds, s)
 			}
 		}
 	}
 }
 
+func subscribe(ds *realtime.Subscriber, sub channelSub) {
+	if sub.selector != nil {
+		ds.WatchSelector(sub.doctype, sub.selector)
+	} else if sub.id == "" {
+		ds.Subscribe(sub.doctype)
+	} else {
+		ds.Watch(sub.doctype, sub.id)
+	}
+}
+
+func unsubscribe(ds *realtime.Subscriber, sub channelSub) {
+	if sub.selector != nil {
+		ds.UnwatchSelector(sub.doctype, sub.selector)
+	} else if sub.id == "" {
+		ds.Unsubscribe(sub.doctype)
+	} else {
+		ds.Unwatch(sub.doctype, sub.id)
+	}
+}
+
 // Ws is the API handler for realtime via a websocket connection.
 func Ws(c echo.Context) error {
 	var db prefixer.Prefixer
@@ -265,11 +344,13 @@ func Ws(c echo.Context) error {
 	})
 
 	ds := realtime.GetHub().Subscriber(db)
+	ds.AllowDrops()
 	defer ds.Close()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	errc := make(chan *wsError)
-	go readPump(ctx, c, inst, ws, ds, errc, withAuthentication)
+	subs := &channelSubs{}
+	go readPump(ctx, c, inst, ws, ds, subs, errc, withAuthentication)
 
 	ticker := time.NewTicker(pingPeriod)
 	defer ticker.Stop()
@@ -287,16 +368,32 @@ func Ws(c echo.Context) error {
 				return nil
 			}
 		case e := <-ds.Channel:
+			// The event is sent once for each channel that has subscribed
+			// to it, so that the client can dispatch it to its components.
+			for _, channel := range subs.channelsFor(e) {
+				if err := ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+					return err
+				}
+				res := wsResponse{
+					Event:   e.Verb,
+					Channel: channel,
+					Payload: wsResponsePayload{
+						Type: e.Doc.DocType(),
+						ID:   e.Doc.ID(),
+						Doc:  e.Doc,
+					},
+				}
+				if err := ws.WriteJSON(res); err != nil {
+					return nil
+				}
+			}
+		case <-ds.Lossy:
 			if err := ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
 				return err
 			}
-			res := wsResponse{
-				Event: e.Verb,
-				Payload: wsResponsePayload{
-					Type: e.Doc.DocType(),
-					ID:   e.Doc.ID(),
-					Doc:  e.Doc,
-				},
+			res := wsLossy{
+				Event:   lossyEvent,
+				Payload: wsLossyPayload{Dropped: ds.TakeDropped()},
 			}
 			if err := ws.WriteJSON(res); err != nil {
 				return nil
@@ -330,6 +427,11 @@ func Notify(c echo.Context) error {
 	}
 	payload.SetID(id)
 	payload.Type = doctype
+	if doctype == consts.JobsProgress {
+		if ok, err := notifyJobProgress(c, inst, id, payload.M); ok || err != nil {
+			return err
+		}
+	}
 	if err := middlewares.Allow(c, permission.POST, &payload); err != nil {
 		return err
 	}
@@ -338,8 +440,26 @@ func Notify(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// notifyJobProgress lets a konnector publish the progress of its own running
+// job, without a permission on io.cozy.jobs.progress. It returns false if the
+// request doesn't come from a konnector, to check the permissions as usual.
+func notifyJobProgress(c echo.Context, inst *instance.Instance, jobID string, data map[string]interface{}) (bool, error) {
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil || pdoc.Type != permission.TypeKonnector {
+		return false, nil
+	}
+	slug := strings.TrimPrefix(pdoc.SourceID, consts.Konnectors+"/")
+	j, err := job.Get(inst, jobID)
+	if err != nil || !j.IsRunningKonnector(slug) {
+		return true, middlewares.ErrForbidden
+	}
+	job.PublishProgress(inst, j, data)
+	return true, c.NoContent(http.StatusNoContent)
+}
+
 // Routes set the routing for the realtime service
 func Routes(router *echo.Group) {
 	router.GET("/", Ws)
+	router.GET("/sse", SSE)
 	router.POST("/:doctype/:id", Notify)
 }
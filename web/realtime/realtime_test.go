@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
 	"github.com/cozy/cozy-stack/pkg/realtime"
 	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
 )
 
 type testDoc struct {
@@ -169,6 +171,62 @@ func TestRealtime(t *testing.T) {
 		payload.ValueEqual("id", "bar-two")
 	})
 
+	t.Run("WSSelector", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		ws := e.GET("/realtime/").
+			WithWebsocketUpgrade().
+			Expect().Status(http.StatusSwitchingProtocols).
+			Websocket()
+		defer ws.Disconnect()
+
+		ws.WriteText(fmt.Sprintf(`{"method": "AUTH", "payload": "%s"}`, token))
+
+		obj := ws.WriteText(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.foos", "selector": {"$where": "true"} }}`).
+			Expect().TextMessage().
+			JSON().Object()
+		obj.ValueEqual("event", "error")
+		obj.Value("payload").Object().ValueEqual("status", "400 Bad Request")
+
+		ws.WriteText(`{"method": "SUBSCRIBE", "payload": { "type": "io.cozy.foos", "selector": {"dir_id": "xyz"} }}`)
+		h := realtime.GetHub()
+		time.Sleep(30 * time.Millisecond)
+
+		h.Publish(inst, realtime.EventCreate, &couchdb.JSONDoc{
+			Type: "io.cozy.foos",
+			M:    map[string]interface{}{"_id": "foo-abc", "dir_id": "abc"},
+		}, nil)
+		// No event
+
+		h.Publish(inst, realtime.EventCreate, &couchdb.JSONDoc{
+			Type: "io.cozy.foos",
+			M:    map[string]interface{}{"_id": "foo-xyz", "dir_id": "xyz"},
+		}, nil)
+
+		obj = ws.Expect().TextMessage().JSON().Object()
+		obj.ValueEqual("event", "CREATED")
+		payload := obj.Value("payload").Object()
+		payload.ValueEqual("type", "io.cozy.foos")
+		payload.ValueEqual("id", "foo-xyz")
+	})
+
+	t.Run("SSEErrors", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.GET("/realtime/sse").
+			WithQuery("type", "io.cozy.foos").
+			Expect().Status(401)
+
+		e.GET("/realtime/sse").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(400)
+
+		e.GET("/realtime/sse").
+			WithQuery("type", "io.cozy.forbidden").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(403)
+	})
+
 	t.Run("WSNotify", func(t *testing.T) {
 		e := testutils.CreateTestClient(t, ts.URL)
 
@@ -206,3 +264,74 @@ func (t *testDoc) MarshalJSON() ([]byte, error) {
 	j := `{"_id":"` + t.id + `", "_type":"` + t.doctype + `"}`
 	return []byte(j), nil
 }
+
+func TestSSEStream(t *testing.T) {
+	s := &sseStream{id: "stream", done: make(chan struct{})}
+	push := func(id string) {
+		s.push(&realtime.Event{Verb: realtime.EventCreate, Doc: &testDoc{id: id, doctype: "io.cozy.foos"}})
+	}
+
+	out, missed, complete := s.attach(0, false)
+	assert.Empty(t, missed)
+	assert.True(t, complete)
+	push("foo-1")
+	ev := <-out
+	assert.Equal(t, "stream.1", s.eventID(ev))
+	assert.Equal(t, "foo-1", ev.payload.ID)
+
+	// The client is disconnected, and resumes the stream after 2 events
+	s.out = nil
+	push("foo-2")
+	push("foo-3")
+	_, missed, complete = s.attach(1, true)
+	assert.True(t, complete)
+	if assert.Len(t, missed, 2) {
+		assert.Equal(t, "foo-2", missed[0].payload.ID)
+		assert.Equal(t, "foo-3", missed[1].payload.ID)
+	}
+
+	// Too many events for the buffer
+	s.out = nil
+	for i := 0; i < sseBufferSize+5; i++ {
+		push(fmt.Sprintf("bar-%d", i))
+	}
+	_, missed, complete = s.attach(3, true)
+	assert.False(t, complete)
+	assert.Len(t, missed, sseBufferSize)
+}
+
+func TestChannelSubs(t *testing.T) {
+	subs := &channelSubs{}
+	whole := channelSub{channel: "list", doctype: "io.cozy.foos"}
+	one := channelSub{channel: "detail", doctype: "io.cozy.foos", id: "foo-1"}
+	again := channelSub{channel: "preview", doctype: "io.cozy.foos", id: "foo-1"}
+
+	assert.True(t, subs.add(whole))
+	assert.True(t, subs.add(one))
+	assert.False(t, subs.add(again))
+	assert.False(t, subs.add(again))
+
+	e1 := &realtime.Event{Verb: realtime.EventUpdate, Doc: &testDoc{id: "foo-1", doctype: "io.cozy.foos"}}
+	e2 := &realtime.Event{Verb: realtime.EventUpdate, Doc: &testDoc{id: "foo-2", doctype: "io.cozy.foos"}}
+	assert.Equal(t, []string{"list", "detail", "preview"}, subs.channelsFor(e1))
+	assert.Equal(t, []string{"list"}, subs.channelsFor(e2))
+
+	unsub, _ := subs.remove(again)
+	assert.Empty(t, unsub)
+	unsub, restore := subs.remove(whole)
+	assert.Equal(t, []channelSub{whole}, unsub)
+	assert.Equal(t, []channelSub{one}, restore)
+	assert.Equal(t, []string{"detail"}, subs.channelsFor(e1))
+	assert.Equal(t, []string{""}, subs.channelsFor(e2))
+
+	unsub, _ = subs.remove(whole)
+	assert.Empty(t, unsub)
+
+	// Unsubscribing from the whole doctype removes the subscriptions on its
+	// documents for the same channel
+	assert.True(t, subs.add(channelSub{channel: "detail", doctype: "io.cozy.foos"}))
+	unsub, restore = subs.remove(channelSub{channel: "detail", doctype: "io.cozy.foos"})
+	assert.Equal(t, []channelSub{{channel: "detail", doctype: "io.cozy.foos"}}, unsub)
+	assert.Empty(t, restore)
+	assert.Equal(t, []string{""}, subs.channelsFor(e1))
+}
@@ -0,0 +1,345 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/pkg/realtime"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// sseBufferSize is the number of events kept by a stream for resuming it
+	sseBufferSize = 100
+
+	// sseResumeWindow is how long a stream is kept after its client has been
+	// disconnected, waiting for a reconnection with the Last-Event-ID header
+	sseResumeWindow = 2 * time.Minute
+
+	// sseResetEvent is sent when the stream can't be resumed and some events
+	// may have been lost: the client should reload its data
+	sseResetEvent = "RESET"
+)
+
+type sseEvent struct {
+	seq     uint64
+	verb    string
+	payload wsResponsePayload
+}
+
+// sseStream is the server-side part of a SSE connection. It survives to the
+// disconnection of its client for some time, and keeps the last events, so
+// that the client can resume it where it has stopped.
+type sseStream struct {
+	id     string
+	domain string
+	source string
+	key    string
+	sub    *realtime.Subscriber
+	done   chan struct{}
+
+	mu     sync.Mutex
+	seq    uint64
+	buffer []*sseEvent
+	out    chan *sseEvent // nil when no client is connected
+	timer  *time.Timer
+	closed bool
+}
+
+var sseStreams = struct {
+	sync.Mutex
+	m map[string]*sseStream
+}{m: make(map[string]*sseStream)}
+
+type sseSubscription struct {
+	doctype string
+	id      string
+}
+
+// parseSSESubscriptions reads the type parameters of the query string: a
+// doctype, or a doctype and an id separated by a slash.
+func parseSSESubscriptions(c echo.Context) ([]sseSubscription, error) {
+	var subs []sseSubscription
+	for _, param := range c.QueryParams()["type"] {
+		parts := strings.SplitN(param, "/", 2)
+		sub := sseSubscription{doctype: parts[0]}
+		if len(parts) == 2 {
+			sub.id = parts[1]
+		}
+		if sub.doctype == "" {
+			return nil, errors.New("The type parameter is invalid")
+		}
+		subs = append(subs, sub)
+	}
+	if len(subs) == 0 {
+		return nil, errors.New("The type parameter is mandatory")
+	}
+	return subs, nil
+}
+
+func sseSubscriptionsKey(subs []sseSubscription) string {
+	keys := make([]string, len(subs))
+	for i, sub := range subs {
+		keys[i] = sub.doctype + "/" + sub.id
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func newSSEStream(inst *instance.Instance, source string, subs []sseSubscription) *sseStream {
+	ds := realtime.GetHub().Subscriber(inst)
+	for _, sub := range subs {
+		if sub.id == "" {
+			ds.Subscribe(sub.doctype)
+		} else {
+			ds.Watch(sub.doctype, sub.id)
+		}
+	}
+	s := &sseStream{
+		id:     crypto.GenerateRandomString(16),
+		domain: inst.Domain,
+		source: source,
+		key:    sseSubscriptionsKey(subs),
+		sub:    ds,
+		done:   make(chan struct{}),
+	}
+	sseStreams.Lock()
+	sseStreams.m[s.id] = s
+	sseStreams.Unlock()
+	go s.pump()
+	return s
+}
+
+// findSSEStream returns the stream and the sequence number of the last
+// event received by the client, from the Last-Event-ID header.
+func findSSEStream(lastEventID, domain, source, key string) (*sseStream, uint64) {
+	parts := strings.SplitN(lastEventID, ".", 2)
+	if len(parts) != 2 {
+		return nil, 0
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, 0
+	}
+	sseStreams.Lock()
+	s, ok := sseStreams.m[parts[0]]
+	sseStreams.Unlock()
+	if !ok || s.domain != domain || s.source != source || s.key != key {
+		return nil, 0
+	}
+	return s, seq
+}
+
+func (s *sseStream) pump() {
+	for {
+		select {
+		case e := <-s.sub.Channel:
+			s.push(e)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *sseStream) push(e *realtime.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	ev := &sseEvent{
+		seq:  s.seq,
+		verb: e.Verb,
+		payload: wsResponsePayload{
+			Type: e.Doc.DocType(),
+			ID:   e.Doc.ID(),
+			Doc:  e.Doc,
+		},
+	}
+	s.buffer = append(s.buffer, ev)
+	if len(s.buffer) > sseBufferSize {
+		s.buffer = append(s.buffer[:0], s.buffer[1:]...)
+	}
+	if s.out != nil {
+		select {
+		case s.out <- ev:
+		default:
+			// The client is too slow: it is disconnected, and it will get the
+			// missing events from the buffer when it reconnects.
+			close(s.out)
+			s.out = nil
+		}
+	}
+}
+
+// attach connects a client to the stream. It returns the channel of the new
+// events, the buffered events that the client has missed since lastSeq (if
+// resume is true), and false if some events have been lost.
+func (s *sseStream) attach(lastSeq uint64, resume bool) (chan *sseEvent, []*sseEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, false
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.out != nil {
+		// Only one client can be connected to a stream
+		close(s.out)
+	}
+	s.out = make(chan *sseEvent, sseBufferSize)
+	if !resume {
+		return s.out, nil, true
+	}
+	complete := lastSeq <= s.seq &&
+		(len(s.buffer) == 0 || s.buffer[0].seq <= lastSeq+1)
+	var missed []*sseEvent
+	for _, ev := range s.buffer {
+		if ev.seq > lastSeq {
+			missed = append(missed, ev)
+		}
+	}
+	return s.out, missed, complete
+}
+
+// detach is called when the client is disconnected. The stream is kept for
+// some time, in case the client reconnects.
+func (s *sseStream) detach(out chan *sseEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out == out {
+		s.out = nil
+	}
+	if s.out == nil && s.timer == nil && !s.closed {
+		s.timer = time.AfterFunc(sseResumeWindow, s.close)
+	}
+}
+
+func (s *sseStream) close() {
+	s.mu.Lock()
+	if s.closed || s.out != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.timer = nil
+	s.mu.Unlock()
+
+	sseStreams.Lock()
+	delete(sseStreams.m, s.id)
+	sseStreams.Unlock()
+	close(s.done)
+	s.sub.Close()
+}
+
+func writeSSEEvent(w http.ResponseWriter, id, event string, data interface{}) error {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, buf); err != nil {
+		return err
+	}
+	w.(http.Flusher).Flush()
+	return nil
+}
+
+// SSE is the API handler for realtime via Server-Sent Events, for the
+// clients that can't use a websocket. The subscriptions are given in the
+// query string, and a client can resume a stream with the Last-Event-ID
+// header.
+func SSE(c echo.Context) error {
+	inst, ok := middlewares.GetInstanceSafe(c)
+	if !ok {
+		return jsonapi.NotFound(errors.New("The SSE endpoint requires an instance"))
+	}
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	subs, err := parseSSESubscriptions(c)
+	if err != nil {
+		return jsonapi.BadRequest(err)
+	}
+	for _, sub := range subs {
+		if !canSubscribe(inst, pdoc.Permissions, sub.doctype, sub.id) {
+			return jsonapi.Forbidden(fmt.Errorf("The application can't subscribe to %s", sub.doctype))
+		}
+	}
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	s, lastSeq := findSSEStream(lastEventID, inst.Domain, pdoc.SourceID, sseSubscriptionsKey(subs))
+	resume := s != nil
+	var out chan *sseEvent
+	var missed []*sseEvent
+	complete := false
+	if s != nil {
+		out, missed, complete = s.attach(lastSeq, true)
+	}
+	if out == nil {
+		s = newSSEStream(inst, pdoc.SourceID, subs)
+		out, _, _ = s.attach(0, false)
+		resume = false
+	}
+	defer s.detach(out)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	if lastEventID != "" && (!resume || !complete) {
+		if err := writeSSEEvent(res, "", sseResetEvent, echo.Map{}); err != nil {
+			return nil
+		}
+	}
+	for _, ev := range missed {
+		if err := writeSSEEvent(res, s.eventID(ev), ev.verb, ev.payload); err != nil {
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	ctx := c.Request().Context()
+	for {
+		select {
+		case ev, ok := <-out:
+			if !ok {
+				return nil
+			}
+			if err := writeSSEEvent(res, s.eventID(ev), ev.verb, ev.payload); err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			if _, err := res.Write([]byte(": ping\n\n")); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *sseStream) eventID(ev *sseEvent) string {
+	return s.id + "." + strconv.FormatUint(ev.seq, 10)
+}
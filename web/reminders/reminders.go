@@ -0,0 +1,191 @@
+// Package reminders is for the API that the apps can use to create reminders,
+// delivered by the notification center at the right time.
+package reminders
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/reminder"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type apiReminder struct {
+	*reminder.Reminder
+}
+
+func (r *apiReminder) Relationships() jsonapi.RelationshipMap { return nil }
+func (r *apiReminder) Included() []jsonapi.Object             { return nil }
+func (r *apiReminder) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/reminders/" + r.ID()}
+}
+
+func (r *apiReminder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Reminder)
+}
+
+// source returns the source ID and the slug of the app for the permission of
+// the request.
+func source(pdoc *permission.Permission) (string, string) {
+	switch pdoc.Type {
+	case permission.TypeWebapp, permission.TypeKonnector:
+		return pdoc.SourceID, strings.TrimPrefix(pdoc.SourceID, consts.Apps+"/")
+	case permission.TypeOauth:
+		if c, ok := pdoc.Client.(*oauth.Client); ok {
+			return pdoc.SourceID, oauth.GetLinkedAppSlug(c.SoftwareID)
+		}
+	}
+	return pdoc.SourceID, ""
+}
+
+func createReminder(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.Reminders); err != nil {
+		return err
+	}
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	r := &reminder.Reminder{}
+	if _, err := jsonapi.Bind(c.Request().Body, r); err != nil {
+		return err
+	}
+	r.SourceID, r.Slug = source(pdoc)
+	if err := reminder.Create(inst, r); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusCreated, &apiReminder{r}, nil)
+}
+
+func listReminders(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Reminders); err != nil {
+		return err
+	}
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return err
+	}
+	sourceID, _ := source(pdoc)
+	list, err := reminder.List(inst, sourceID)
+	if err != nil {
+		return wrapError(err)
+	}
+	objs := make([]jsonapi.Object, len(list))
+	for i, r := range list {
+		objs[i] = &apiReminder{r}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+// getReminder returns the reminder of the request, if it has been created by
+// the same source.
+func getReminder(c echo.Context, verb permission.Verb) (*reminder.Reminder, error) {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, verb, consts.Reminders); err != nil {
+		return nil, err
+	}
+	pdoc, err := middlewares.GetPermission(c)
+	if err != nil {
+		return nil, err
+	}
+	r, err := reminder.Get(inst, c.Param("reminder-id"))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if sourceID, _ := source(pdoc); r.SourceID != sourceID {
+		return nil, jsonapi.NotFound(errors.New("reminder not found"))
+	}
+	return r, nil
+}
+
+func showReminder(c echo.Context) error {
+	r, err := getReminder(c, permission.GET)
+	if err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiReminder{r}, nil)
+}
+
+func deleteReminder(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	r, err := getReminder(c, permission.DELETE)
+	if err != nil {
+		return err
+	}
+	if err := reminder.Delete(inst, r); err != nil {
+		return wrapError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+type snoozeAttrs struct {
+	Until    *time.Time `json:"until"`
+	Duration string     `json:"duration"`
+}
+
+func snoozeReminder(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	r, err := getReminder(c, permission.PUT)
+	if err != nil {
+		return err
+	}
+	var attrs snoozeAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return err
+	}
+	var until time.Time
+	switch {
+	case attrs.Until != nil:
+		until = *attrs.Until
+	case attrs.Duration != "":
+		d, err := time.ParseDuration(attrs.Duration)
+		if err != nil {
+			return jsonapi.BadRequest(err)
+		}
+		until = time.Now().Add(d)
+	default:
+		return jsonapi.BadRequest(errors.New("missing until or duration"))
+	}
+	if err := reminder.Snooze(inst, r, until); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiReminder{r}, nil)
+}
+
+func wrapError(err error) error {
+	if couchdb.IsNotFoundError(err) {
+		return jsonapi.NotFound(err)
+	}
+	switch {
+	case errors.Is(err, reminder.ErrMissingTitle),
+		errors.Is(err, reminder.ErrInvalidDate),
+		errors.Is(err, reminder.ErrInvalidTimezone),
+		errors.Is(err, reminder.ErrInvalidRecurrence),
+		errors.Is(err, reminder.ErrInvalidChannel):
+		return jsonapi.InvalidAttribute("attributes", err)
+	case errors.Is(err, reminder.ErrInPast),
+		errors.Is(err, reminder.ErrInvalidSnooze):
+		return jsonapi.BadRequest(err)
+	}
+	return err
+}
+
+// Routes sets the routing for the reminders.
+func Routes(router *echo.Group) {
+	router.POST("", createReminder)
+	router.GET("", listReminders)
+	router.GET("/:reminder-id", showReminder)
+	router.DELETE("/:reminder-id", deleteReminder)
+	router.POST("/:reminder-id/snooze", snoozeReminder)
+}
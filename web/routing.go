@@ -9,23 +9,31 @@ import (
 
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/stack"
+	"github.com/cozy/cozy-stack/pkg/assets/cdn"
 	build "github.com/cozy/cozy-stack/pkg/config"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/jsonapi"
 	"github.com/cozy/cozy-stack/pkg/metrics"
+	"github.com/cozy/cozy-stack/pkg/netaccess"
 	"github.com/cozy/cozy-stack/web/accounts"
+	"github.com/cozy/cozy-stack/web/activities"
 	"github.com/cozy/cozy-stack/web/apps"
 	"github.com/cozy/cozy-stack/web/auth"
 	"github.com/cozy/cozy-stack/web/bitwarden"
+	"github.com/cozy/cozy-stack/web/calendar"
+	"github.com/cozy/cozy-stack/web/cmis"
+	"github.com/cozy/cozy-stack/web/comments"
 	"github.com/cozy/cozy-stack/web/compat"
 	"github.com/cozy/cozy-stack/web/conncheck"
 	"github.com/cozy/cozy-stack/web/contacts"
 	"github.com/cozy/cozy-stack/web/data"
 	"github.com/cozy/cozy-stack/web/errors"
 	"github.com/cozy/cozy-stack/web/files"
+	"github.com/cozy/cozy-stack/web/imports"
 	"github.com/cozy/cozy-stack/web/instances"
 	"github.com/cozy/cozy-stack/web/intents"
 	"github.com/cozy/cozy-stack/web/jobs"
+	"github.com/cozy/cozy-stack/web/mails"
 	"github.com/cozy/cozy-stack/web/middlewares"
 	"github.com/cozy/cozy-stack/web/move"
 	"github.com/cozy/cozy-stack/web/notes"
@@ -33,10 +41,13 @@ import (
 	"github.com/cozy/cozy-stack/web/oauth"
 	"github.com/cozy/cozy-stack/web/office"
 	"github.com/cozy/cozy-stack/web/oidc"
+	"github.com/cozy/cozy-stack/web/organization"
 	"github.com/cozy/cozy-stack/web/permissions"
+	"github.com/cozy/cozy-stack/web/photos"
 	"github.com/cozy/cozy-stack/web/public"
 	"github.com/cozy/cozy-stack/web/realtime"
 	"github.com/cozy/cozy-stack/web/registry"
+	"github.com/cozy/cozy-stack/web/reminders"
 	"github.com/cozy/cozy-stack/web/remote"
 	"github.com/cozy/cozy-stack/web/settings"
 	"github.com/cozy/cozy-stack/web/sharings"
@@ -45,7 +56,9 @@ import (
 	"github.com/cozy/cozy-stack/web/status"
 	"github.com/cozy/cozy-stack/web/swift"
 	"github.com/cozy/cozy-stack/web/tools"
+	"github.com/cozy/cozy-stack/web/useractions"
 	"github.com/cozy/cozy-stack/web/version"
+	"github.com/cozy/cozy-stack/web/webdav"
 	"github.com/cozy/cozy-stack/web/wellknown"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -79,6 +92,7 @@ func SetupAppsHandler(appsHandler echo.HandlerFunc) echo.HandlerFunc {
 		middlewares.Accept(middlewares.AcceptOptions{
 			DefaultContentTypeOffer: echo.MIMETextHTML,
 		}),
+		middlewares.CheckNetworkAccess(netaccess.CategoryDefault),
 		middlewares.CheckInstanceBlocked,
 		middlewares.CheckInstanceDeleting,
 		middlewares.CheckTOSDeadlineExpired,
@@ -114,6 +128,19 @@ func SetupAppsHandler(appsHandler echo.HandlerFunc) echo.HandlerFunc {
 		// Add CSO exception for starting a move from settings
 		formAction := config.GetConfig().Move.URL
 
+		// Add CSP exceptions for the assets served by the CDN
+		defaultSrc := config.GetConfig().CSPAllowList["default"]
+		imgSrc := config.GetConfig().CSPAllowList["img"] + " " + cspImgSrcAllowList
+		styleSrc := config.GetConfig().CSPAllowList["style"]
+		fontSrc := config.GetConfig().CSPAllowList["font"]
+		if origin := cdn.Origin(); origin != "" {
+			defaultSrc = origin + " " + defaultSrc
+			imgSrc = origin + " " + imgSrc
+			scriptSrc = origin + " " + scriptSrc
+			styleSrc = origin + " " + styleSrc
+			fontSrc = origin + " " + fontSrc
+		}
+
 		secure := middlewares.Secure(&middlewares.SecureConfig{
 			HSTSMaxAge:        hstsMaxAge,
 			CSPDefaultSrc:     []middlewares.CSPSource{middlewares.CSPSrcSelf, middlewares.CSPSrcParent, middlewares.CSPSrcWS},
@@ -126,12 +153,12 @@ func SetupAppsHandler(appsHandler echo.HandlerFunc) echo.HandlerFunc {
 			CSPBaseURI:        []middlewares.CSPSource{middlewares.CSPSrcSelf},
 			CSPFormAction:     []middlewares.CSPSource{middlewares.CSPSrcParent},
 
-			CSPDefaultSrcAllowList: config.GetConfig().CSPAllowList["default"],
-			CSPImgSrcAllowList:     config.GetConfig().CSPAllowList["img"] + " " + cspImgSrcAllowList,
+			CSPDefaultSrcAllowList: defaultSrc,
+			CSPImgSrcAllowList:     imgSrc,
 			CSPScriptSrcAllowList:  config.GetConfig().CSPAllowList["script"] + " " + scriptSrc,
 			CSPConnectSrcAllowList: config.GetConfig().CSPAllowList["connect"] + " " + cspScriptSrcAllowList,
-			CSPStyleSrcAllowList:   config.GetConfig().CSPAllowList["style"],
-			CSPFontSrcAllowList:    config.GetConfig().CSPAllowList["font"],
+			CSPStyleSrcAllowList:   styleSrc,
+			CSPFontSrcAllowList:    fontSrc,
 			CSPMediaSrcAllowList:   config.GetConfig().CSPAllowList["media"],
 			CSPFrameSrcAllowList:   config.GetConfig().CSPAllowList["frame"] + " " + frameSrc,
 			CSPFormActionAllowList: config.GetConfig().CSPAllowList["form"] + " " + formAction,
@@ -187,6 +214,13 @@ func SetupRoutes(router *echo.Echo, services *stack.Services) error {
 		BlockList: []string{"/auth/"},
 	}))
 
+	if cfg := config.GetConfig().Compression; !cfg.Disabled {
+		router.Use(middlewares.Compress(middlewares.CompressOptions{
+			MinSize:   cfg.MinSize,
+			Encodings: cfg.Encodings,
+		}))
+	}
+
 	// non-authentified HTML routes for authentication (login, OAuth, ...)
 	{
 		mws := []echo.MiddlewareFunc{
@@ -200,16 +234,24 @@ func SetupRoutes(router *echo.Echo, services *stack.Services) error {
 			middlewares.CheckInstanceDeleting,
 		}
 
-		router.GET("/", auth.Home, mws...)
-		auth.Routes(router.Group("/auth", mws...))
-		public.Routes(router.Group("/public", mws...))
-		wellknown.Routes(router.Group("/.well-known", mws...))
+		authMws := append([]echo.MiddlewareFunc{}, mws...)
+		authMws = append(authMws, middlewares.CheckNetworkAccess(netaccess.CategoryAuth))
+		publicMws := append([]echo.MiddlewareFunc{}, mws...)
+		publicMws = append(publicMws, middlewares.CheckNetworkAccess(netaccess.CategoryPublic))
+		router.GET("/", auth.Home, authMws...)
+		auth.Routes(router.Group("/auth", authMws...))
+		public.Routes(router.Group("/public", publicMws...))
+		router.GET("/public/calendar/:file", calendar.ServeFeed, publicMws...)
+		defaultMws := append([]echo.MiddlewareFunc{}, mws...)
+		defaultMws = append(defaultMws, middlewares.CheckNetworkAccess(netaccess.CategoryDefault))
+		wellknown.Routes(router.Group("/.well-known", defaultMws...))
 	}
 
 	// authentified JSON API routes
 	{
 		mwsNotBlocked := []echo.MiddlewareFunc{
 			middlewares.NeedInstance,
+			middlewares.CheckNetworkAccess(netaccess.CategoryDefault),
 			middlewares.LoadSession,
 			middlewares.Accept(middlewares.AcceptOptions{
 				DefaultContentTypeOffer: jsonapi.ContentType,
@@ -225,16 +267,26 @@ func SetupRoutes(router *echo.Echo, services *stack.Services) error {
 		contacts.Routes(router.Group("/contacts", mws...))
 		intents.Routes(router.Group("/intents", mws...))
 		jobs.Routes(router.Group("/jobs", mws...))
+		imports.Routes(router.Group("/imports", mws...))
 		notifications.Routes(router.Group("/notifications", mws...))
+		reminders.Routes(router.Group("/reminders", mws...))
+		comments.Routes(router.Group("/comments", mws...))
+		activities.Routes(router.Group("/activities", mws...))
+		calendar.Routes(router.Group("/calendar", mws...))
 		move.Routes(router.Group("/move", mws...))
 		permissions.Routes(router.Group("/permissions", mws...))
 		realtime.Routes(router.Group("/realtime", mws...))
 		notes.Routes(router.Group("/notes", mws...))
 		office.Routes(router.Group("/office", mws...))
+		organization.Routes(router.Group("/organization", mws...))
 		remote.Routes(router.Group("/remote", mws...))
 		sharings.Routes(router.Group("/sharings", mws...))
 		bitwarden.Routes(router.Group("/bitwarden", mws...))
 		shortcuts.Routes(router.Group("/shortcuts", mws...))
+		cmis.Routes(router.Group("/cmis", mws...))
+		webdav.Routes(router.Group("/webdav", mws...))
+		photos.Routes(router.Group("/photos", mws...))
+		useractions.Routes(router.Group("/user-actions", mws...))
 
 		// The settings routes needs not to be blocked
 		apps.WebappsRoutes(router.Group("/apps", mwsNotBlocked...))
@@ -255,8 +307,10 @@ func SetupRoutes(router *echo.Echo, services *stack.Services) error {
 	// other non-authentified routes
 	{
 		conncheck.Routes(router.Group("/connection_check"))
+		mails.Routes(router.Group("/mails"))
 		status.Routes(router.Group("/status"))
 		version.Routes(router.Group("/version"))
+		wellknown.DiscoveryRoutes(router)
 	}
 
 	// dev routes
@@ -291,8 +345,9 @@ func SetupAdminRoutes(router *echo.Echo) error {
 			Format: "time=${time_rfc3339}\tstatus=${status}\tmethod=${method}\thost=${host}\turi=${uri}\tbytes_out=${bytes_out}\n",
 		}))
 	} else {
-		mws = append(mws, middlewares.BasicAuth(config.GetConfig().AdminSecretFileName))
+		mws = append(mws, middlewares.AdminAuth())
 	}
+	mws = append([]echo.MiddlewareFunc{middlewares.CheckAdminNetworkAccess}, mws...)
 
 	instances.Routes(router.Group("/instances", mws...))
 	apps.AdminRoutes(router.Group("/konnectors", mws...))
@@ -5,6 +5,7 @@ package web
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/utils"
 	"github.com/cozy/cozy-stack/web/apps"
+	"github.com/cozy/cozy-stack/worker/exec"
 	"github.com/sirupsen/logrus"
 
 	"github.com/labstack/echo/v4"
@@ -148,9 +150,15 @@ func ListenAndServe(services *stack.Services) (*Servers, error) {
 	}
 
 	servers := NewServers()
-	err = servers.Start(major, "major", config.ServerAddr())
-	if err != nil {
-		return nil, fmt.Errorf("failed to start major server: %w", err)
+	if config.GetConfig().ACME.Enabled {
+		if err = startACMEServers(servers, major); err != nil {
+			return nil, fmt.Errorf("failed to start major server: %w", err)
+		}
+	} else {
+		err = servers.Start(major, "major", config.ServerAddr())
+		if err != nil {
+			return nil, fmt.Errorf("failed to start major server: %w", err)
+		}
 	}
 
 	err = servers.Start(admin, "admin", config.AdminServerAddr())
@@ -158,6 +166,13 @@ func ListenAndServe(services *stack.Services) (*Servers, error) {
 		return nil, fmt.Errorf("failed to start admin server: %w", err)
 	}
 
+	if addr := config.GetConfig().Konnectors.Proxy.Listen; addr != "" {
+		err = servers.Start(exec.NewKonnectorProxy(), "konnectors-proxy", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start konnectors proxy: %w", err)
+		}
+	}
+
 	return servers, nil
 }
 
@@ -182,12 +197,40 @@ func NewServers() *Servers {
 	}
 }
 
+// startACMEServers starts the major server on HTTPS with the certificates
+// obtained via ACME, and a server on HTTP for the HTTP-01 challenges and the
+// redirections to HTTPS.
+func startACMEServers(servers *Servers, major http.Handler) error {
+	manager, err := newACMEManager()
+	if err != nil {
+		return err
+	}
+	cfg := config.GetConfig().ACME
+	if err := servers.StartTLS(major, "major", cfg.HTTPSAddr, manager.TLSConfig()); err != nil {
+		return err
+	}
+	if cfg.HTTPAddr != "" {
+		return servers.Start(manager.HTTPHandler(), "acme", cfg.HTTPAddr)
+	}
+	return nil
+}
+
 // Start the server 'e' to the given addrs.
 //
 // The 'addrs' arguments must be in the format `"host:port"`. If the host
 // is not a valid IPv4/IPv6/hostname or if the port not present an error is
 // returned.
 func (s *Servers) Start(handler http.Handler, name string, addr string) error {
+	return s.start(handler, name, addr, nil)
+}
+
+// StartTLS is like Start, but the server accepts TLS connections with the
+// given configuration.
+func (s *Servers) StartTLS(handler http.Handler, name string, addr string, tlsConfig *tls.Config) error {
+	return s.start(handler, name, addr, tlsConfig)
+}
+
+func (s *Servers) start(handler http.Handler, name string, addr string, tlsConfig *tls.Config) error {
 	addrs := []string{}
 
 	if len(addr) == 0 {
@@ -217,6 +260,9 @@ func (s *Servers) Start(handler http.Handler, name string, addr string) error {
 		if err != nil {
 			return err
 		}
+		if tlsConfig != nil {
+			l = tls.NewListener(l, tlsConfig)
+		}
 
 		writer := logger.WithNamespace("stack").Writer()
 		logger := log.New(writer, "", 0)
@@ -225,6 +271,7 @@ func (s *Servers) Start(handler http.Handler, name string, addr string) error {
 			Handler:           handler,
 			ReadHeaderTimeout: ReadHeaderTimeout,
 			ErrorLog:          logger,
+			TLSConfig:         tlsConfig,
 		}
 
 		s.serversByName[name] = server
@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cozy/cozy-stack/model/accessstats"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type apiAccessStats struct {
+	*accessstats.Summary
+}
+
+func (j *apiAccessStats) ID() string                             { return consts.AccessStatsID }
+func (j *apiAccessStats) Rev() string                            { return "" }
+func (j *apiAccessStats) DocType() string                        { return consts.Settings }
+func (j *apiAccessStats) Clone() couchdb.Doc                     { return j }
+func (j *apiAccessStats) SetID(_ string)                         {}
+func (j *apiAccessStats) SetRev(_ string)                        {}
+func (j *apiAccessStats) Relationships() jsonapi.RelationshipMap { return nil }
+func (j *apiAccessStats) Included() []jsonapi.Object             { return nil }
+func (j *apiAccessStats) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/settings/access-stats"}
+}
+
+// Settings objects permissions are only on ID
+func (j *apiAccessStats) Fetch(field string) []string { return nil }
+
+func (h *HTTPHandler) accessStats(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	result := &apiAccessStats{}
+	if err := middlewares.Allow(c, permission.GET, result); err != nil {
+		return err
+	}
+
+	days := 0
+	if param := c.QueryParam("days"); param != "" {
+		var err error
+		days, err = strconv.Atoi(param)
+		if err != nil {
+			return jsonapi.InvalidParameter("days", err)
+		}
+	}
+	summary, err := accessstats.GetSummary(inst, days, c.QueryParam("doctype"))
+	if err != nil {
+		return err
+	}
+	result.Summary = summary
+	return jsonapi.Data(c, http.StatusOK, result, nil)
+}
@@ -0,0 +1,98 @@
+package settings
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/apppassword"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// apiAppPassword is used to hide the hash of the secret in the responses,
+// and to show the secret on creation.
+type apiAppPassword struct {
+	*apppassword.AppPassword
+	secret string
+}
+
+func (a *apiAppPassword) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*apppassword.AppPassword
+		SecretHash string `json:"secret_hash,omitempty"`
+		Secret     string `json:"secret,omitempty"`
+	}{
+		AppPassword: a.AppPassword,
+		Secret:      a.secret,
+	})
+}
+
+type appPasswordAttrs struct {
+	Name     string   `json:"name"`
+	Protocol string   `json:"protocol"`
+	Doctypes []string `json:"doctypes"`
+	ReadOnly bool     `json:"read_only"`
+}
+
+func (h *HTTPHandler) listAppPasswords(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.AppPasswords); err != nil {
+		return err
+	}
+	list, err := apppassword.List(inst)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(list))
+	for i, a := range list {
+		objs[i] = &apiAppPassword{AppPassword: a}
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func (h *HTTPHandler) createAppPassword(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.AppPasswords); err != nil {
+		return err
+	}
+	var attrs appPasswordAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+	a := &apppassword.AppPassword{
+		Name:     attrs.Name,
+		Protocol: attrs.Protocol,
+		Doctypes: attrs.Doctypes,
+		ReadOnly: attrs.ReadOnly,
+	}
+	if err := a.Validate(); err != nil {
+		return jsonapi.InvalidAttribute("attributes", err)
+	}
+	secret, err := apppassword.Create(inst, a)
+	if err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusCreated, &apiAppPassword{AppPassword: a, secret: secret}, nil)
+}
+
+func (h *HTTPHandler) revokeAppPassword(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.AppPasswords); err != nil {
+		return err
+	}
+	a, err := apppassword.Find(inst, c.Param("id"))
+	if err != nil {
+		if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+			return jsonapi.NotFound(err)
+		}
+		return err
+	}
+	if err := apppassword.Revoke(inst, a); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -99,6 +99,27 @@ func (h *HTTPHandler) revokeClient(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (h *HTTPHandler) revokeClientTokens(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.OAuthClients); err != nil {
+		return err
+	}
+
+	clientID := c.Param("id")
+	defer auth.LockOAuthClient(instance, clientID)()
+
+	client, err := oauth.FindClient(instance, clientID)
+	if err != nil {
+		return err
+	}
+
+	if err := client.RevokeTokens(instance); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (h *HTTPHandler) synchronized(c echo.Context) error {
 	instance := middlewares.GetInstance(c)
 
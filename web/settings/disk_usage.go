@@ -4,7 +4,9 @@ package settings
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/cozy/cozy-stack/model/diskusage"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
@@ -36,13 +38,32 @@ func (j *apiDiskUsage) Links() *jsonapi.LinksList {
 // Settings objects permissions are only on ID
 func (j *apiDiskUsage) Fetch(field string) []string { return nil }
 
+type apiDiskUsageTrend struct {
+	*diskusage.Trend
+}
+
+func (j *apiDiskUsageTrend) ID() string                             { return consts.DiskUsageTrendID }
+func (j *apiDiskUsageTrend) Rev() string                            { return "" }
+func (j *apiDiskUsageTrend) DocType() string                        { return consts.Settings }
+func (j *apiDiskUsageTrend) Clone() couchdb.Doc                     { return j }
+func (j *apiDiskUsageTrend) SetID(_ string)                         {}
+func (j *apiDiskUsageTrend) SetRev(_ string)                        {}
+func (j *apiDiskUsageTrend) Relationships() jsonapi.RelationshipMap { return nil }
+func (j *apiDiskUsageTrend) Included() []jsonapi.Object             { return nil }
+func (j *apiDiskUsageTrend) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/settings/disk-usage/trend"}
+}
+
+// Settings objects permissions are only on ID
+func (j *apiDiskUsageTrend) Fetch(field string) []string { return nil }
+
 // checkAccessToDiskUsage validates the access control for the disk-usage. It
 // checks if there is an explicit permission on this document, but also allow
 // every request from the logged-in user as this route is used by the cozy-bar
 // from all the client-side apps. And there is a third case where it is
 // allowed: when an anonymous user comes from a shared by link directory with
 // write access.
-func checkAccessToDiskUsage(c echo.Context, result *apiDiskUsage) error {
+func checkAccessToDiskUsage(c echo.Context, result permission.Fetcher) error {
 	if err := middlewares.Allow(c, permission.GET, result); err == nil {
 		return nil
 	}
@@ -84,5 +105,30 @@ func (h *HTTPHandler) diskUsage(c echo.Context) error {
 	result.Quota = quota
 	result.Files = files
 	result.Versions = versions
+	diskusage.EnsureSnapshotTrigger(instance)
 	return jsonapi.Data(c, http.StatusOK, &result, nil)
 }
+
+func (h *HTTPHandler) diskUsageTrend(c echo.Context) error {
+	instance := middlewares.GetInstance(c)
+	result := &apiDiskUsageTrend{}
+	if err := checkAccessToDiskUsage(c, result); err != nil {
+		return err
+	}
+
+	days := 0
+	if param := c.QueryParam("days"); param != "" {
+		var err error
+		days, err = strconv.Atoi(param)
+		if err != nil {
+			return jsonapi.InvalidParameter("days", err)
+		}
+	}
+	trend, err := diskusage.GetTrend(instance, days)
+	if err != nil {
+		return err
+	}
+	diskusage.EnsureSnapshotTrigger(instance)
+	result.Trend = trend
+	return jsonapi.Data(c, http.StatusOK, result, nil)
+}
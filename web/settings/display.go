@@ -0,0 +1,66 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// getDisplayPrefs handle GET /settings/display
+func (h *HTTPHandler) getDisplayPrefs(c echo.Context) error {
+	// Any request with a token can ask for the display preferences, as the
+	// apps (even on a public share) should use them for consistency.
+	if _, err := middlewares.GetPermission(c); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+
+	inst := middlewares.GetInstance(c)
+	prefs, err := h.svc.GetDisplayPrefs(inst)
+	if err != nil {
+		return jsonapi.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// putDisplayPrefs handle PUT /settings/display
+func (h *HTTPHandler) putDisplayPrefs(c echo.Context) error {
+	type body struct {
+		Theme    *string `json:"theme"`
+		Contrast *string `json:"contrast"`
+		FontSize *string `json:"font_size"`
+	}
+
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+
+	var args body
+	if err := c.Bind(&args); err != nil {
+		return jsonapi.BadJSON()
+	}
+
+	inst := middlewares.GetInstance(c)
+	prefs, err := h.svc.UpdateDisplayPrefs(inst, &csettings.UpdateDisplayPrefsCmd{
+		Theme:    args.Theme,
+		Contrast: args.Contrast,
+		FontSize: args.FontSize,
+	})
+	switch {
+	case err == nil:
+		return c.JSON(http.StatusOK, prefs)
+	case errors.Is(err, csettings.ErrInvalidTheme):
+		return jsonapi.InvalidAttribute("theme", err)
+	case errors.Is(err, csettings.ErrInvalidContrast):
+		return jsonapi.InvalidAttribute("contrast", err)
+	case errors.Is(err, csettings.ErrInvalidFontSize):
+		return jsonapi.InvalidAttribute("font_size", err)
+	default:
+		return jsonapi.InternalServerError(err)
+	}
+}
@@ -0,0 +1,153 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/account"
+	"github.com/cozy/cozy-stack/model/gdrive"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+type gdriveSyncAttrs struct {
+	RemoteFolderID string `json:"remote_folder_id"`
+	DirID          string `json:"dir_id"`
+	ConflictPolicy string `json:"conflict_policy"`
+	Frequency      string `json:"frequency"`
+	Enabled        *bool  `json:"enabled"`
+}
+
+func (h *HTTPHandler) listGDriveSyncs(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.GDriveSyncs); err != nil {
+		return err
+	}
+	syncs, err := gdrive.List(inst)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(syncs))
+	for i, s := range syncs {
+		objs[i] = s
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func (h *HTTPHandler) getGDriveSync(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.GDriveSyncs); err != nil {
+		return err
+	}
+	s, err := gdrive.Find(inst, c.Param("account-id"))
+	if err != nil {
+		return wrapGDriveError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, s, nil)
+}
+
+func (h *HTTPHandler) putGDriveSync(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.GDriveSyncs); err != nil {
+		return err
+	}
+
+	var attrs gdriveSyncAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+
+	accountID := c.Param("account-id")
+	acc := &account.Account{}
+	if err := couchdb.GetDoc(inst, consts.Accounts, accountID, acc); err != nil {
+		if couchdb.IsNotFoundError(err) {
+			return jsonapi.NotFound(err)
+		}
+		return err
+	}
+	if acc.Oauth == nil {
+		return jsonapi.BadRequest(gdrive.ErrNoOAuth)
+	}
+
+	s, err := gdrive.Find(inst, accountID)
+	if err != nil && !couchdb.IsNotFoundError(err) && !couchdb.IsNoDatabaseError(err) {
+		return err
+	}
+	if s == nil {
+		s = &gdrive.Sync{DocID: accountID, Enabled: true}
+	} else if (attrs.DirID != "" && attrs.DirID != s.DirID) ||
+		(attrs.RemoteFolderID != "" && attrs.RemoteFolderID != s.RemoteFolderID) {
+		// Changing the synchronized folders would make the known entries
+		// point to the wrong files.
+		return jsonapi.Conflict(errors.New("The synchronized folders cannot be changed"))
+	}
+	if attrs.RemoteFolderID != "" {
+		s.RemoteFolderID = attrs.RemoteFolderID
+	}
+	if attrs.DirID != "" {
+		s.DirID = attrs.DirID
+	}
+	if attrs.ConflictPolicy != "" {
+		s.ConflictPolicy = attrs.ConflictPolicy
+	}
+	if attrs.Frequency != "" {
+		s.Frequency = attrs.Frequency
+	}
+	if attrs.Enabled != nil {
+		s.Enabled = *attrs.Enabled
+	}
+	if err := s.Validate(); err != nil {
+		return jsonapi.InvalidAttribute("attributes", err)
+	}
+	if _, err := inst.VFS().DirByID(s.DirID); err != nil {
+		return jsonapi.InvalidAttribute("dir_id", err)
+	}
+	if err := gdrive.Save(inst, s); err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, s, nil)
+}
+
+func (h *HTTPHandler) deleteGDriveSync(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.GDriveSyncs); err != nil {
+		return err
+	}
+	s, err := gdrive.Find(inst, c.Param("account-id"))
+	if err != nil {
+		return wrapGDriveError(err)
+	}
+	if err := gdrive.Delete(inst, s); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *HTTPHandler) syncGDriveNow(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.GDriveSyncs); err != nil {
+		return err
+	}
+	s, err := gdrive.Find(inst, c.Param("account-id"))
+	if err != nil {
+		return wrapGDriveError(err)
+	}
+	if !s.Enabled {
+		return jsonapi.Conflict(errors.New("The synchronization is disabled"))
+	}
+	if _, err := gdrive.PushJob(inst, s); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func wrapGDriveError(err error) error {
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	return err
+}
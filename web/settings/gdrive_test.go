@@ -0,0 +1,247 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/account"
+	"github.com/cozy/cozy-stack/model/gdrive"
+	"github.com/cozy/cozy-stack/model/job"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/cozy/cozy-stack/web/errors"
+	"github.com/gavv/httpexpect/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGDriveSyncs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+
+	testInstance := setup.GetTestInstance()
+	_, token := setup.GetTestClient(consts.GDriveSyncs)
+	_, otherToken := setup.GetTestClient(consts.Files)
+
+	svc := csettings.NewServiceMock(t)
+	ts := setupRouter(t, testInstance, svc)
+	ts.Config.Handler.(*echo.Echo).HTTPErrorHandler = errors.ErrorHandler
+
+	fs := testInstance.VFS()
+	dir, err := vfs.NewDirDoc(fs, "Google Drive", consts.RootDirID, nil)
+	require.NoError(t, err)
+	require.NoError(t, fs.CreateDir(dir))
+
+	// The account has no access token: the synchronizations fail before
+	// calling Google Drive.
+	acc := &account.Account{
+		AccountType: "google",
+		Oauth:       &account.OauthInfo{},
+	}
+	require.NoError(t, couchdb.CreateDoc(testInstance, acc))
+	noOAuth := &account.Account{AccountType: "google"}
+	require.NoError(t, couchdb.CreateDoc(testInstance, noOAuth))
+	syncURL := "/settings/gdrive/" + acc.ID()
+
+	put := func(e *httpexpect.Expect, url, tok string, attrs map[string]interface{}) *httpexpect.Response {
+		return e.PUT(url).
+			WithHeader("Authorization", "Bearer "+tok).
+			WithHeader("Content-Type", "application/vnd.api+json").
+			WithJSON(map[string]interface{}{
+				"data": map[string]interface{}{
+					"type":       consts.GDriveSyncs,
+					"attributes": attrs,
+				},
+			}).
+			Expect()
+	}
+	jsonapiObject := func(res *httpexpect.Response) *httpexpect.Object {
+		return res.JSON(httpexpect.ContentOpts{MediaType: "application/vnd.api+json"}).Object()
+	}
+
+	var triggerID string
+
+	t.Run("Permissions", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.GET("/settings/gdrive").Expect().Status(401)
+		e.GET("/settings/gdrive").
+			WithHeader("Authorization", "Bearer "+otherToken).
+			Expect().Status(403)
+		put(e, syncURL, otherToken, map[string]interface{}{"dir_id": dir.ID()}).Status(403)
+		e.DELETE(syncURL).
+			WithHeader("Authorization", "Bearer "+otherToken).
+			Expect().Status(403)
+		e.POST(syncURL+"/sync").
+			WithHeader("Authorization", "Bearer "+otherToken).
+			Expect().Status(403)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.GET(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+		e.DELETE(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+		e.POST(syncURL+"/sync").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+		put(e, "/settings/gdrive/not-an-account", token, map[string]interface{}{
+			"dir_id": dir.ID(),
+		}).Status(404)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.PUT(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			WithHeader("Content-Type", "application/vnd.api+json").
+			WithBytes([]byte("not json")).
+			Expect().Status(400)
+		put(e, "/settings/gdrive/"+noOAuth.ID(), token, map[string]interface{}{
+			"dir_id": dir.ID(),
+		}).Status(400)
+		put(e, syncURL, token, map[string]interface{}{}).Status(422)
+		put(e, syncURL, token, map[string]interface{}{"dir_id": "not-a-dir"}).Status(422)
+		put(e, syncURL, token, map[string]interface{}{
+			"dir_id":          dir.ID(),
+			"conflict_policy": "first_wins",
+		}).Status(422)
+		put(e, syncURL, token, map[string]interface{}{
+			"dir_id":    dir.ID(),
+			"frequency": "1m",
+		}).Status(422)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		obj := jsonapiObject(put(e, syncURL, token, map[string]interface{}{
+			"dir_id": dir.ID(),
+		}).Status(200))
+		data := obj.Value("data").Object()
+		data.HasValue("type", consts.GDriveSyncs)
+		data.HasValue("id", acc.ID())
+		data.Path("$.links.self").IsEqual(syncURL)
+		attrs := data.Value("attributes").Object()
+		attrs.HasValue("dir_id", dir.ID())
+		attrs.HasValue("remote_folder_id", "root")
+		attrs.HasValue("conflict_policy", gdrive.KeepBoth)
+		attrs.HasValue("frequency", gdrive.DefaultFrequency)
+		attrs.HasValue("enabled", true)
+		triggerID = attrs.Value("trigger_id").String().NotEmpty().Raw()
+
+		trigger, err := job.System().GetTrigger(testInstance, triggerID)
+		require.NoError(t, err)
+		assert.Equal(t, "@every", trigger.Infos().Type)
+		assert.Equal(t, gdrive.DefaultFrequency, trigger.Infos().Arguments)
+
+		obj = jsonapiObject(e.GET(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200))
+		obj.Path("$.data.attributes.trigger_id").IsEqual(triggerID)
+
+		obj = jsonapiObject(e.GET("/settings/gdrive").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200))
+		obj.Value("data").Array().Length().IsEqual(1)
+		obj.Path("$.data[0].id").IsEqual(acc.ID())
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		// The synchronized folders can't be changed
+		put(e, syncURL, token, map[string]interface{}{
+			"dir_id": consts.RootDirID,
+		}).Status(409)
+		put(e, syncURL, token, map[string]interface{}{
+			"remote_folder_id": "another-folder",
+		}).Status(409)
+
+		// Changing the frequency replaces the trigger
+		obj := jsonapiObject(put(e, syncURL, token, map[string]interface{}{
+			"conflict_policy": gdrive.NewestWins,
+			"frequency":       "1h",
+		}).Status(200))
+		attrs := obj.Path("$.data.attributes").Object()
+		attrs.HasValue("dir_id", dir.ID())
+		attrs.HasValue("conflict_policy", gdrive.NewestWins)
+		attrs.HasValue("frequency", "1h")
+		newTriggerID := attrs.Value("trigger_id").String().NotEmpty().Raw()
+		assert.NotEqual(t, triggerID, newTriggerID)
+		_, err := job.System().GetTrigger(testInstance, triggerID)
+		assert.ErrorIs(t, err, job.ErrNotFoundTrigger)
+		triggerID = newTriggerID
+	})
+
+	t.Run("SyncNow", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.POST(syncURL+"/sync").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(202)
+		require.Eventually(t, func() bool {
+			queued, err := job.GetQueuedJobs(testInstance, gdrive.WorkerType)
+			if err != nil || len(queued) > 0 {
+				return false
+			}
+			s, err := gdrive.Find(testInstance, acc.ID())
+			return err == nil && s.LastSyncAt != nil
+		}, 10*time.Second, 100*time.Millisecond)
+		attrs := jsonapiObject(e.GET(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(200)).Path("$.data.attributes").Object()
+		attrs.HasValue("status", gdrive.StatusErrored)
+		attrs.HasValue("last_error", gdrive.ErrNoOAuth.Error())
+		attrs.Value("last_sync_at").String().NotEmpty()
+
+		// A disabled synchronization has no trigger and can't be executed
+		obj := jsonapiObject(put(e, syncURL, token, map[string]interface{}{
+			"enabled": false,
+		}).Status(200))
+		obj.Path("$.data.attributes.enabled").IsEqual(false)
+		obj.Path("$.data.attributes").Object().NotContainsKey("trigger_id")
+		_, err := job.System().GetTrigger(testInstance, triggerID)
+		assert.ErrorIs(t, err, job.ErrNotFoundTrigger)
+		e.POST(syncURL+"/sync").
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(409)
+
+		obj = jsonapiObject(put(e, syncURL, token, map[string]interface{}{
+			"enabled": true,
+		}).Status(200))
+		triggerID = obj.Path("$.data.attributes.trigger_id").String().NotEmpty().Raw()
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		e := testutils.CreateTestClient(t, ts.URL)
+
+		e.DELETE(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(204)
+		e.GET(syncURL).
+			WithHeader("Authorization", "Bearer "+token).
+			Expect().Status(404)
+		_, err := job.System().GetTrigger(testInstance, triggerID)
+		assert.ErrorIs(t, err, job.ErrNotFoundTrigger)
+
+		// The files are kept
+		_, err = testInstance.VFS().DirByID(dir.ID())
+		assert.NoError(t, err)
+	})
+}
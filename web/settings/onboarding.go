@@ -0,0 +1,72 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/onboarding"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+func wrapOnboardingError(err error) error {
+	switch {
+	case errors.Is(err, onboarding.ErrPassphraseNotRegistered):
+		return jsonapi.PreconditionFailed("passphrase", err)
+	case errors.Is(err, onboarding.ErrAlreadyFinished):
+		return jsonapi.Conflict(err)
+	case errors.Is(err, onboarding.ErrTooManyApps):
+		return jsonapi.InvalidAttribute("apps", err)
+	}
+	return err
+}
+
+func (h *HTTPHandler) getOnboarding(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	// Before the passphrase has been chosen, the flagship app has only the
+	// register token to authenticate its requests.
+	if !middlewares.CheckRegisterToken(c, inst) {
+		if err := middlewares.AllowWholeType(c, permission.GET, consts.Settings); err != nil {
+			return err
+		}
+	}
+	state, err := onboarding.Get(inst)
+	if err != nil {
+		return wrapOnboardingError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, state, nil)
+}
+
+func (h *HTTPHandler) installOnboardingApps(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+	var args struct {
+		Apps []string `json:"apps"`
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&args); err != nil {
+		return jsonapi.BadJSON()
+	}
+	state, err := onboarding.InstallApps(inst, args.Apps)
+	if err != nil {
+		return wrapOnboardingError(err)
+	}
+	return jsonapi.Data(c, http.StatusAccepted, state, nil)
+}
+
+func (h *HTTPHandler) completeOnboarding(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+	state, err := onboarding.Finish(inst)
+	if err != nil {
+		return wrapOnboardingError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, state, nil)
+}
@@ -3,7 +3,6 @@
 package settings
 
 import (
-	"crypto/subtle"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -14,6 +13,7 @@ import (
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/onboarding"
 	"github.com/cozy/cozy-stack/model/permission"
 	"github.com/cozy/cozy-stack/model/session"
 	"github.com/cozy/cozy-stack/model/sharing"
@@ -167,7 +167,7 @@ func (h *HTTPHandler) registerPassphraseFlagship(c echo.Context) error {
 			"error": "the client must be registered",
 		})
 	}
-	if subtle.ConstantTimeCompare([]byte(args.ClientSecret), []byte(client.ClientSecret)) == 0 {
+	if !client.CheckSecret(args.ClientSecret) {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"error": "invalid client_secret",
 		})
@@ -185,6 +185,10 @@ func (h *HTTPHandler) registerPassphraseFlagship(c echo.Context) error {
 	if err != nil {
 		return jsonapi.BadRequest(err)
 	}
+	if err := onboarding.PassphraseRegistered(inst); err != nil {
+		inst.Logger().WithNamespace("onboarding").
+			Warnf("Cannot update the state of the onboarding: %s", err)
+	}
 
 	if args.Hint != "" {
 		setting, err := settings.Get(inst)
@@ -0,0 +1,28 @@
+package settings
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/photo"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// rescanPhotos pushes the jobs to compute the perceptual hashes and the
+// locations of all the photos, and to rebuild the suggestions of duplicates
+// and bursts, and the geo-clusters.
+func (h *HTTPHandler) rescanPhotos(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.POST, consts.PhotosSuggestions); err != nil {
+		return err
+	}
+	if _, err := photo.PushJob(inst, &photo.Message{Rescan: true}); err != nil {
+		return err
+	}
+	if _, err := photo.PushGeoJob(inst, &photo.GeoMessage{Rebuild: true}); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
@@ -0,0 +1,81 @@
+package settings
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// getProfile handle GET /settings/profile
+func (h *HTTPHandler) getProfile(c echo.Context) error {
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Settings); err != nil {
+		return err
+	}
+
+	inst := middlewares.GetInstance(c)
+	profile, err := h.svc.GetProfile(inst)
+	if err != nil {
+		return jsonapi.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// putProfile handle PUT /settings/profile
+func (h *HTTPHandler) putProfile(c echo.Context) error {
+	type body struct {
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
+		AvatarID    *string `json:"avatar_id"`
+		Visibility  *string `json:"visibility"`
+	}
+
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+
+	var args body
+	if err := c.Bind(&args); err != nil {
+		return jsonapi.BadJSON()
+	}
+
+	inst := middlewares.GetInstance(c)
+
+	// The avatar must be an image of the VFS. An empty string can be used to
+	// remove the avatar.
+	if args.AvatarID != nil && *args.AvatarID != "" {
+		file, err := inst.VFS().FileByID(*args.AvatarID)
+		if err != nil {
+			return jsonapi.InvalidAttribute("avatar_id", err)
+		}
+		if file.Trashed || file.Class != "image" {
+			return jsonapi.InvalidAttribute("avatar_id", errors.New("The avatar must be an image"))
+		}
+	}
+
+	profile, err := h.svc.UpdateProfile(inst, &csettings.UpdateProfileCmd{
+		DisplayName: args.DisplayName,
+		Bio:         args.Bio,
+		AvatarID:    args.AvatarID,
+		Visibility:  args.Visibility,
+	})
+	switch {
+	case err == nil:
+		if args.DisplayName != nil {
+			go sharing.BroadcastMemberRenamed(inst, profile.DisplayName)
+		}
+		return c.JSON(http.StatusOK, profile)
+	case errors.Is(err, csettings.ErrInvalidVisibility):
+		return jsonapi.InvalidAttribute("visibility", err)
+	case errors.Is(err, csettings.ErrBioTooLong):
+		return jsonapi.InvalidAttribute("bio", err)
+	default:
+		return jsonapi.InternalServerError(err)
+	}
+}
@@ -0,0 +1,55 @@
+package settings
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/report"
+	csettings "github.com/cozy/cozy-stack/model/settings"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// getReportPrefs handle GET /settings/reports
+func (h *HTTPHandler) getReportPrefs(c echo.Context) error {
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Settings); err != nil {
+		return err
+	}
+
+	inst := middlewares.GetInstance(c)
+	prefs, err := h.svc.GetReportPrefs(inst)
+	if err != nil {
+		return jsonapi.InternalServerError(err)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// putReportPrefs handle PUT /settings/reports
+func (h *HTTPHandler) putReportPrefs(c echo.Context) error {
+	type body struct {
+		Monthly *bool `json:"monthly"`
+	}
+
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+
+	var args body
+	if err := c.Bind(&args); err != nil {
+		return jsonapi.BadJSON()
+	}
+
+	inst := middlewares.GetInstance(c)
+	prefs, err := h.svc.UpdateReportPrefs(inst, &csettings.UpdateReportPrefsCmd{
+		Monthly: args.Monthly,
+	})
+	if err != nil {
+		return jsonapi.InternalServerError(err)
+	}
+	if prefs.Monthly {
+		report.EnsureMonthlyTrigger(inst)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
@@ -241,15 +241,26 @@ func isMovedError(err error) bool {
 // Register all the `/settings` routes to the given router.
 func (h *HTTPHandler) Register(router *echo.Group) {
 	router.GET("/disk-usage", h.diskUsage)
+	router.GET("/disk-usage/trend", h.diskUsageTrend)
 	router.GET("/clients-usage", h.clientsUsage)
+	router.GET("/access-stats", h.accessStats)
 
 	router.POST("/email", h.postEmail)
 	router.POST("/email/resend", h.postEmailResend)
 	router.DELETE("/email", h.deleteEmail)
 	router.GET("/email/confirm", h.getEmailConfirmation)
 
+	router.GET("/profile", h.getProfile)
+	router.PUT("/profile", h.putProfile)
+
+	router.GET("/display", h.getDisplayPrefs)
+	router.PUT("/display", h.putDisplayPrefs)
+
+	router.GET("/reports", h.getReportPrefs)
+	router.PUT("/reports", h.putReportPrefs)
+
 	router.GET("/passphrase", h.getPassphraseParameters)
-	router.POST("/passphrase", h.registerPassphrase)
+	router.POST("/passphrase", h.registerPassphrase, middlewares.CheckCSRF)
 	router.POST("/passphrase/flagship", h.registerPassphraseFlagship)
 	router.PUT("/passphrase", h.updatePassphrase)
 	router.POST("/passphrase/check", h.checkPassphrase)
@@ -270,9 +281,30 @@ func (h *HTTPHandler) Register(router *echo.Group) {
 
 	router.GET("/clients", h.listClients)
 	router.DELETE("/clients/:id", h.revokeClient)
+	router.DELETE("/clients/:id/tokens", h.revokeClientTokens)
 	router.GET("/clients/limit-exceeded", h.limitExceeded)
 	router.POST("/synchronized", h.synchronized)
 
+	router.GET("/app-passwords", h.listAppPasswords)
+	router.POST("/app-passwords", h.createAppPassword)
+	router.DELETE("/app-passwords/:id", h.revokeAppPassword)
+
+	router.GET("/smtp", h.getSMTP)
+	router.PUT("/smtp", h.putSMTP)
+	router.DELETE("/smtp", h.deleteSMTP)
+	router.POST("/smtp/test", h.testSMTP)
+
+	router.GET("/gdrive", h.listGDriveSyncs)
+	router.GET("/gdrive/:account-id", h.getGDriveSync)
+	router.PUT("/gdrive/:account-id", h.putGDriveSync)
+	router.DELETE("/gdrive/:account-id", h.deleteGDriveSync)
+	router.POST("/gdrive/:account-id/sync", h.syncGDriveNow)
+
+	router.POST("/photos/rescan", h.rescanPhotos)
+
+	router.GET("/onboarding", h.getOnboarding)
+	router.POST("/onboarding/apps", h.installOnboardingApps)
+	router.POST("/onboarding/finish", h.completeOnboarding)
 	router.GET("/onboarded", h.onboarded)
 	router.GET("/install_flagship_app", h.installFlagshipApp)
 	router.GET("/context", h.context)
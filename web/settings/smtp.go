@@ -0,0 +1,141 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/mailqueue"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// apiSMTP is used to hide the encrypted password in the responses.
+type apiSMTP struct {
+	*mailqueue.SMTPSettings
+}
+
+func (s *apiSMTP) Relationships() jsonapi.RelationshipMap { return nil }
+func (s *apiSMTP) Included() []jsonapi.Object             { return nil }
+func (s *apiSMTP) Links() *jsonapi.LinksList {
+	return &jsonapi.LinksList{Self: "/settings/smtp"}
+}
+
+func (s *apiSMTP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*mailqueue.SMTPSettings
+		EncryptedPassword string `json:"password_encrypted,omitempty"`
+		HasPassword       bool   `json:"has_password"`
+	}{
+		SMTPSettings: s.SMTPSettings,
+		HasPassword:  s.SMTPSettings.EncryptedPassword != "",
+	})
+}
+
+type smtpAttrs struct {
+	Host        string  `json:"host"`
+	Port        int     `json:"port"`
+	Username    string  `json:"username"`
+	Password    *string `json:"password"`
+	UseSSL      bool    `json:"use_ssl"`
+	DisableTLS  bool    `json:"disable_tls"`
+	FromAddress string  `json:"from_address"`
+	FromName    string  `json:"from_name"`
+}
+
+func (h *HTTPHandler) getSMTP(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.Settings); err != nil {
+		return err
+	}
+	s, err := mailqueue.GetSMTPSettings(inst)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return jsonapi.NotFound(mailqueue.ErrSMTPNotConfigured)
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiSMTP{s}, nil)
+}
+
+// putSMTP saves the SMTP server of the instance. The password is kept if it
+// is not given, so that the other fields can be changed without asking it
+// again to the user.
+func (h *HTTPHandler) putSMTP(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+	var attrs smtpAttrs
+	if _, err := jsonapi.Bind(c.Request().Body, &attrs); err != nil {
+		return jsonapi.BadJSON()
+	}
+
+	s, err := mailqueue.GetSMTPSettings(inst)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		s = &mailqueue.SMTPSettings{}
+	}
+	s.Host = attrs.Host
+	s.Port = attrs.Port
+	s.Username = attrs.Username
+	s.UseSSL = attrs.UseSSL
+	s.DisableTLS = attrs.DisableTLS
+	s.FromAddress = attrs.FromAddress
+	s.FromName = attrs.FromName
+	if err := s.Validate(); err != nil {
+		return jsonapi.InvalidAttribute("attributes", err)
+	}
+	if attrs.Password != nil {
+		if err := s.SetPassword(*attrs.Password); err != nil {
+			return err
+		}
+	}
+	if err := mailqueue.SaveSMTPSettings(inst, s); err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, &apiSMTP{s}, nil)
+}
+
+func (h *HTTPHandler) deleteSMTP(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.DELETE, consts.Settings); err != nil {
+		return err
+	}
+	if err := mailqueue.DeleteSMTPSettings(inst); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// testSMTP sends a mail to the user with the SMTP server of the instance, and
+// returns the error of the server if it has failed.
+func (h *HTTPHandler) testSMTP(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.PUT, consts.Settings); err != nil {
+		return err
+	}
+	s, err := mailqueue.GetSMTPSettings(inst)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return jsonapi.NotFound(mailqueue.ErrSMTPNotConfigured)
+	}
+	email, err := inst.SettingsEMail()
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return jsonapi.BadRequest(errors.New("The instance has no email address"))
+	}
+	if err := mailqueue.SendSMTPTest(c.Request().Context(), inst, s, email); err != nil {
+		return jsonapi.BadGateway(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,35 @@
+package sharings
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/sharing"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// ReceiveMessage is used by the stack of another member of the sharing to
+// send a message (protocol negotiation or out-of-band event). The response is
+// a hello message with the protocol version and capabilities of this stack.
+func ReceiveMessage(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	sharingID := c.Param("sharing-id")
+	s, err := sharing.FindSharing(inst, sharingID)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	member, err := requestMember(c, s)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	var msg sharing.Message
+	if err := c.Bind(&msg); err != nil || msg.Type == "" {
+		return jsonapi.BadJSON()
+	}
+	reply, err := s.ReceiveMessage(inst, member, &msg)
+	if err != nil {
+		return wrapErrors(err)
+	}
+	return c.JSON(http.StatusOK, reply)
+}
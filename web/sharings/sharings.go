@@ -415,11 +415,25 @@ func PutRecipients(c echo.Context) error {
 	}
 
 	var body struct {
-		Members []sharing.Member `json:"data"`
+		Members []sharing.Member        `json:"data"`
+		Meta    *sharing.RecipientsMeta `json:"meta"`
 	}
 	if err = json.NewDecoder(c.Request().Body).Decode(&body); err != nil {
 		return wrapErrors(err)
 	}
+	if body.Meta != nil && body.Meta.Revoked {
+		// The owner has revoked this member before the sharing has been
+		// synchronized, we just have to remove the shortcut
+		if err = s.RemoveShortcut(inst); err != nil {
+			return wrapErrors(err)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}
+	if body.Meta != nil {
+		if err = s.UpdateDescription(inst, body.Meta.Description); err != nil {
+			return wrapErrors(err)
+		}
+	}
 	if err = s.UpdateRecipients(inst, body.Members); err != nil {
 		return wrapErrors(err)
 	}
@@ -535,10 +549,22 @@ func GetDiscovery(c echo.Context) error {
 	return renderDiscoveryForm(c, inst, http.StatusOK, sharingID, state, sharecode, m)
 }
 
+// checkDiscoveryCSRF checks the anti-CSRF token of the discovery form. The
+// requests with a sharecode are sent by the preview page of an application,
+// on another origin: there is no anti-CSRF token for them, but we can rely on
+// the sharecode being secret.
+func checkDiscoveryCSRF(next echo.HandlerFunc) echo.HandlerFunc {
+	check := middlewares.CheckCSRF(next)
+	return func(c echo.Context) error {
+		if c.FormValue("sharecode") != "" {
+			return next(c)
+		}
+		return check(c)
+	}
+}
+
 // PostDiscovery is called when the recipient has given its Cozy URL. Either an
 // error is returned or the recipient will be redirected to their cozy.
-//
-// Note: we don't have an anti-CSRF system, we rely on shareCode being secret.
 func PostDiscovery(c echo.Context) error {
 	inst := middlewares.GetInstance(c)
 	sharingID := c.Param("sharing-id")
@@ -736,6 +762,7 @@ func Routes(router *echo.Group) {
 	router.DELETE("/:sharing-id/recipients/self", RevokeRecipientBySelf)                                     // On the recipient
 	router.DELETE("/:sharing-id/answer", RevocationOwnerNotif, checkSharingWritePermissions)                 // On the sharer
 	router.POST("/:sharing-id/public-key", ReceivePublicKey)
+	router.POST("/:sharing-id/messages", ReceiveMessage, checkSharingPermissions)
 
 	// Delegated routes for open sharing
 	router.POST("/:sharing-id/recipients/delegated", AddRecipientsDelegated, checkSharingWritePermissions)
@@ -746,8 +773,8 @@ func Routes(router *echo.Group) {
 	router.GET("/:sharing-id/recipients/:index/avatar", GetAvatar)
 
 	// Register the URL of their Cozy for recipients
-	router.GET("/:sharing-id/discovery", GetDiscovery)
-	router.POST("/:sharing-id/discovery", PostDiscovery)
+	router.GET("/:sharing-id/discovery", GetDiscovery, middlewares.CheckCSRF)
+	router.POST("/:sharing-id/discovery", PostDiscovery, checkDiscoveryCSRF)
 	router.POST("/:sharing-id/preview-url", GetPreviewURL)
 
 	// Replicator routes
@@ -876,7 +903,7 @@ func wrapErrors(err error) error {
 		return jsonapi.BadRequest(err)
 	case sharing.ErrInvalidURL:
 		return jsonapi.InvalidParameter("url", err)
-	case sharing.ErrInvalidSharing, sharing.ErrInvalidRule:
+	case sharing.ErrInvalidSharing, sharing.ErrInvalidRule, sharing.ErrInvalidSelfSharing:
 		return jsonapi.BadRequest(err)
 	case sharing.ErrMemberNotFound:
 		return jsonapi.NotFound(err)
@@ -164,15 +164,24 @@ func TestSharings(t *testing.T) {
 		// to the tsA host.
 		eA := httpexpect.Default(t, tsA.URL)
 
-		eA.GET(u.Path).
+		res := eA.GET(u.Path).
 			WithQuery("state", state).
-			Expect().Status(200).
-			ContentType("text/html", "utf-8").
+			Expect().Status(200)
+		res.ContentType("text/html", "utf-8").
 			Body().
 			Contains("Connect to your Cozy").
 			Contains(`<input type="hidden" name="state" value="` + state)
+		csrfToken := res.Cookie("_csrf").Value().NotEmpty().Raw()
+
+		eA.POST(u.Path).
+			WithFormField("state", state).
+			WithFormField("slug", tsB.URL).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().Status(400)
 
 		redirectHeader := eA.POST(u.Path).
+			WithCookie("_csrf", csrfToken).
+			WithFormField("csrf_token", csrfToken).
 			WithFormField("state", state).
 			WithFormField("slug", tsB.URL).
 			WithRedirectPolicy(httpexpect.DontFollowRedirects).
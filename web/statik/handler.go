@@ -15,7 +15,9 @@ import (
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/assets"
+	"github.com/cozy/cozy-stack/pkg/assets/cdn"
 	modelAsset "github.com/cozy/cozy-stack/pkg/assets/model"
+	"github.com/cozy/cozy-stack/pkg/compress"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/i18n"
@@ -33,6 +35,7 @@ var (
 		"compat.html",
 		"confirm_auth.html",
 		"confirm_flagship.html",
+		"device.html",
 		"error.html",
 		"import.html",
 		"install_flagship_app.html",
@@ -103,15 +106,17 @@ func NewDirRenderer(assetsPath string) (AssetRenderer, error) {
 	t := template.New("stub")
 	h := http.StripPrefix(assetsPrefix, http.FileServer(dir(assetsPath)))
 	middlewares.FuncsMap = template.FuncMap{
-		"t":         fmt.Sprintf,
-		"tHTML":     fmt.Sprintf,
-		"split":     strings.Split,
-		"replace":   strings.Replace,
-		"hasSuffix": strings.HasSuffix,
-		"asset":     basicAssetPath,
-		"ext":       fileExtension,
-		"basename":  basename,
-		"filetype":  filetype,
+		"t":            fmt.Sprintf,
+		"tHTML":        fmt.Sprintf,
+		"split":        strings.Split,
+		"replace":      strings.Replace,
+		"hasSuffix":    strings.HasSuffix,
+		"asset":        basicAssetPath,
+		"ext":          fileExtension,
+		"basename":     basename,
+		"filetype":     filetype,
+		"csrfField":    csrfField,
+		"displayAttrs": func() template.HTMLAttr { return "" },
 	}
 
 	var err error
@@ -129,15 +134,17 @@ func NewRenderer() (AssetRenderer, error) {
 	t := template.New("stub")
 
 	middlewares.FuncsMap = template.FuncMap{
-		"t":         fmt.Sprintf,
-		"tHTML":     fmt.Sprintf,
-		"split":     strings.Split,
-		"replace":   strings.Replace,
-		"hasSuffix": strings.HasSuffix,
-		"asset":     AssetPath,
-		"ext":       fileExtension,
-		"basename":  basename,
-		"filetype":  filetype,
+		"t":            fmt.Sprintf,
+		"tHTML":        fmt.Sprintf,
+		"split":        strings.Split,
+		"replace":      strings.Replace,
+		"hasSuffix":    strings.HasSuffix,
+		"asset":        AssetPath,
+		"ext":          fileExtension,
+		"basename":     basename,
+		"filetype":     filetype,
+		"csrfField":    csrfField,
+		"displayAttrs": func() template.HTMLAttr { return "" },
 	}
 
 	for _, name := range templatesList {
@@ -168,8 +175,12 @@ func (r *renderer) Render(w io.Writer, name string, data interface{}, c echo.Con
 	i, ok := middlewares.GetInstanceSafe(c)
 	if ok {
 		funcMap = template.FuncMap{
-			"t":     i.Translate,
-			"tHTML": i18n.TranslatorHTML(i.Locale, i.ContextName),
+			"t":         i.Translate,
+			"tHTML":     i18n.TranslatorHTML(i.Locale, i.ContextName),
+			"csrfField": func() template.HTML { return middlewares.CSRFField(c) },
+			"displayAttrs": func() template.HTMLAttr {
+				return middlewares.DisplayAttrs(i)
+			},
 		}
 	} else {
 		lang := GetLanguageFromHeader(c.Request().Header)
@@ -209,6 +220,10 @@ func (r *renderer) Render(w io.Writer, name string, data interface{}, c echo.Con
 	if !config.GetConfig().CSPDisabled {
 		middlewares.AppendCSPRule(c, "default-src", "'self'")
 		middlewares.AppendCSPRule(c, "img-src", "'self' data:")
+		if origin := cdn.Origin(); origin != "" {
+			middlewares.AppendCSPRule(c, "default-src", origin)
+			middlewares.AppendCSPRule(c, "img-src", origin)
+		}
 	}
 
 	return t.Funcs(funcMap).ExecuteTemplate(w, name, data)
@@ -234,6 +249,11 @@ func AssetPath(domain, name string, context ...string) string {
 		if !f.IsCustom {
 			context = nil
 		}
+		// Only the assets with a shasum in their URL can be served by the
+		// CDN, as the others are not purged when the asset is changed.
+		if u, ok := cdn.Rewrite(assetPath("", name, context...)); ok {
+			return u
+		}
 	}
 	return assetPath(domain, name, context...)
 }
@@ -329,7 +349,8 @@ func (h *Handler) ServeFile(w http.ResponseWriter, r *http.Request, f *modelAsse
 	headers.Set(echo.HeaderVary, echo.HeaderOrigin)
 	headers.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
 
-	acceptsBrotli := strings.Contains(r.Header.Get(echo.HeaderAcceptEncoding), "br")
+	acceptEncoding := r.Header.Get(echo.HeaderAcceptEncoding)
+	acceptsBrotli := compress.Negotiate(acceptEncoding, []string{compress.Brotli}) == compress.Brotli
 	if acceptsBrotli {
 		headers.Set(echo.HeaderContentEncoding, "br")
 		headers.Set(echo.HeaderContentLength, f.BrotliSize())
@@ -420,6 +441,12 @@ func isLongHexString(s string) bool {
 	return true
 }
 
+// csrfField is replaced by the anti-CSRF hidden input when a template is
+// rendered for a request.
+func csrfField() template.HTML {
+	return ""
+}
+
 func fileExtension(filename string) string {
 	return path.Ext(filename)
 }
@@ -36878,156 +36878,172 @@ QhLgmBiQnZW0rJmxK5aWmL2zxSt/UcaKBA==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/en.po
-Size: 35688
+Size: 40188
 
-G2eLAKwHeEM+qouoY4sFHXZTax9zKhZFERHgXzDOWlaqZtWeqg82XzMunatO6Uh9
-ABaYvUWgAgQccsB64VZblKY3vN61j4uyhNyr892zTP6gD2z94tQAqQlLJTgZ4mut
-k190Cgzb+mPOTBWd1ndpOgmyk0xuXKZkqoj0JnHhCTqBLhy21dB3KV/X4et/0T4x
-3BImNjmxVu+bqUJjg8RHkTE+u0hJTM5Mv6nCwpRuAVwJhpC1x5P8VY158wEsFmdc
-7YFuaYyPJGaylSvNFEYgk0ivkX785C55pf6SVcTSEJBN7u/lJ5vj9LTlnhzt+PjT
-FpfXKzVNSfqzuKf778/jSd7fv/p0BZx8aveT/AAOxVB2IibWIwP3b3ChfQwgcbLO
-D0xV3Zb3tzcDKbULOt/DY96jqDgyHnBf4JC5sMwVd/kPYbWgxnWvQ+AsRf98wm8i
-IQl4oXlJZhnfyVH/4a1h2P6ik5r8Fsl7Jgh35op7qiv9lDiYhw1glhS+eq+6JjIP
-9TJcdsytMcRp6yLlw/jmDvGVek0nml1SLCcZKOtmKaGiSotHKn8NURZlJ61N7v4o
-9ZhgqKFV4lgWxE97PjpcDnOJIwHNVwY27pzm4Pg6o/i4pIiPRnmjvrrVSZu47tB0
-PyHMcFV/PJKgZk7abk3a1vS0oR82KloyMx/4B6N8MMZHej/YDSfmdILPbEW6Ma0z
-wGWVpWEqyL4rNzHm0o71+aHGyFyJNHmoxt8JVTmJkPohEQ0UitXMtVFPL+l4kc6w
-OecjB6BsQT3c+xBlNnELNjBusnlKjmdmi5tpA4f7K0lm7mmuWZyADDMT7HLfDaAB
-VrqC0C7JUoXN09bpmHSbl6kxEPi+KLBzKL1bvH8fFbA2XfaJ9Zpv/m6mb80oe0x+
-/xd9jRQqwqSyvW4JljGWoQawcQ1h9LfX3F6jlEN2BPj2QYgSilm6Fw4b8dP11FNX
-+OehjkY3W6mCnd+MwVm/Fk82TnrGWY2iFVr834O6S6L4KhArWNjTWW5Td679hMbe
-RuC2mwDQVdXN9UhyANsyn6+JROH/eSeqEM1uqptGkgekE0xXL/INXtyZh2J3BgqD
-2/sthA22UF12bByHvxuSdBJiwIO2T1iZMzGrlb0/YetHayzbV29whodXGDeeo9yh
-5S4qPLRJe8xwh3mVYEXXewrrfBXCEg9rQxx5rYursjgdAlaE8qnudFECUHbEttBf
-wfbiSrDYfnp7Oefi8mRmMOadVxdpyNvTTmMAtzjZFXp+Yx0k5gRTrGlpzOZ3FQpx
-1dt6sUi+8zyzpMLFGumL2E619iH6Qd1wnQaaEmybfIwS0VNb4n2jRqBtwCp3Lk20
-K9arC3qiWG70iN0S51X01NIqhQmWOjSxKcIEnkhphdyAOALthUd875MosjgWn5TB
-Fi96kBijR8Mi/UXsQ7qg6JfTo81898bLuLNNAyL2LnzQIYV1xpXMzw5AYfDgCHvw
-ob+N0ll01e40opHu9U+LvHeqCq2AUCPLBxg97nLlGeuOJty+vwP7gSvDS7ZyHVpw
-kbwkN7uPlTQbCndXcETjEaUWI77k7LG8Nm6SHUQG+66jpNEJkyFm5wrVLm6B5jHO
-ae5v9L+XJ1bEPNzG+b8HdcdPJsP45+nYaqTSCmiNUQMVSSEy5IKsfUEOkNQCweWj
-4kOWWAzRpJbTTCB54I+01KqCVLruHKL75GSELyAG/AMFmhew4YPPL8UyZOcSfKRe
-aOA/TztZnMphzY0Lrcuqzxg8AmOM910BYZrC+I8kp6+qFTCcmeZj/lOgbejIy3bG
-Qj0Y1SAjKW7wQSvM80qaiMmDEeAEVsKxhVO2I96c7Bkcab5AvwvWNHxa1NKT5roC
-8qLSqSRzPI3gcAWOoMoSfR0w1nFFXT30DTjNoLjFLtSsEf5QXA7Ms0aUFeDFrZI0
-gFiyNDvDVFCNq6Bot0/riw7OtGUmzec6I/bIMn4ULe5EYKwQjXbZwzq73qoDBhPV
-FAsdsPnvUaNW+7v7cLvJYJkDD1cvsjHcJT2vByXz66/jfPHSGDyBWffyKORlAiB0
-chMFv7LOC1ZEY+5ud8pZsQF2RQJcvqFPSFOuucFny3JFKMpEPxavnVmRR2P3MwUn
-t5034mF/2AnimDfgCCtq1orYxW0DyV+HM1oZU9s3pfILV6jvgnOSn6ZiTgjp8jhx
-PGduay9K39iXkbHaSlAPyDjCOGZ+ZROIo+1BlPXgJQVdF4tBKSRIwzLEH5ieba/v
-vQ/o9gCx6IzdNJNFkLOdfEXyNuQrAIicrVsxM6/2RDEuqi/qXBTZGmpY+DQvWB2a
-3vq0q/e85OZbnZEo/9rrefVPC2ztoGVnLPpNYuGV9bqgA/YromBF1shdkxBEry+w
-DnAkKJJcmBdA2LA8g/7i6VHRlSqKzCEECq6g+CWp4csQ6kKtTBbBxOWw29uoF2nj
-BfdlvyKT3VaizQ2y2sTKsYrS3WqWkHmpGCITJOptpm2dQXIXaoK75YpMBOI4N/XE
-t1C6WfWvkHHjnlZfh9mlPB4P0cK+MASoHLAivfUdetO2FfW8XbBwarCoOjako4NY
-vEYSpcUrbg/1aPYdZ98t90EhEzUFUQNq9G0sx7JLL4An/cJV604etEY3iXGht9Ly
-Ex7TEqRCNIjAIU5vctkLLeqNIs6kWYj/UGXEImWONzDoD0l7KVKAQswP4boB0dLs
-KZe8zgVfB3hgNUXx4pUXW0RpHgMUqExITnYS02cbeT4l2do5Doo7nlq51kyThBft
-peI7cjD2g0to2AjflZOsz+5kWXDyBgsZzfJ2wnKEeQIzG/Op8MEylHAFAW44VpwM
-dUC4s3cAE3sbK2LMkXDUrz+iAEjYJ4aC3bE68K9fXsaoucqQl98ONXp3Z0MZFF0t
-LtIUMNVuNmhFRqaYVkv3jZ5JDFHZ+UiPF9XSDUO9Rgp3LZb/A3ZaTuJdRQc0lZYO
-zvZmaVSA4BsVVLCgSlFd4uvnscF4QTS5eHz0yDpCsDn2ZUc1oV9KIm7Atlz2yRjZ
-O4gemEWgpeUQ26iWYA0np3mQsKY87TxtY9UooSzWWRoTctZVr/+ZrvOF5LrOvnER
-w5Cw/6/zN04eRQE/nBDH5kIsbN0v+TwKxDXheEffpqVIV7Y6wGki6QrcwcvNTnnW
-a8O3D8c88Za+rVls4kqIvb2bJJSEGyy9nfbvzBJPmYEfxABgJ1To0y5tzv8tOB5u
-gyMkW7CRHjtMqr1Cuw02+jmjqpgLu+YUWBwn7UIvtCCc/yhYILtwymIj7Aqsj2v6
-6slYpwGT4jHX6J5Z+7xrtMLFADl0a7XGKkr3/fdb1C43iSmTRUzZL8YW9I488TaG
-x4pxLOk8aRChP+CJ/K+O3ya9r3xMzZeDOmztXHSJoiTLbVco+01/etbYgVxTqvoZ
-hkRzG4EHFkO2YEImTkphyBpH2hh6GsXB4TXPbSPHTEzivFOfqM9U0NIr6QId7HZZ
-o786GSA6Ze0cFxLCnZQurmvX5tSKByxsnfAmyOX+Dd+kjl/X0jkINRic3zaJEXTn
-58c8K8Xxhz95cyNIu/aITGDVfPdwGH6eAvyYV6KXVCRwhE2X//7dbulduRK4+heO
-G8jX3SUZDliuIsoMuRAKWoMrxzS3hLCqWaaLEiZZOtGe5nUaSMS7osy1wRZTQUWe
-ktVaeb1ebbZmgrRKcuXmQmo+eCkmrBQ/0/LofZMRShkVrtuO7CGYJqstetJy4M/+
-wtf4ZP6TBeBNxTCk0awVjFCFE9ti+t1FJM7xFMJaEP08vdkCCudnqOcGdjSrotTk
-4LdJUaujH+yOSGkr6CijObOaFwFrRH1vqKz6eHRCWBQHjz8rzx0pfhuuwnJVaNSc
-C5skgHLnGXVsmoDhvAydqUjh7aCCbyBOJswTWFlomEA4zs10v980DZbYustGOzDR
-5wyHJxBAN9yo+lw4Uri08e+HUKzM/g4pMcgoeqHjmSPqzgeCHEd+GjPJ9mF4QsGh
-JESVo/jIOPFHDU32Pij9xpUlp4VA3gfVpA415wVZYdQoDi8GzOuEY1tZ51ABfCIy
-sYE1j8yjgGoBpYJJ0zdE5GXjZ1/8TrJniFWHE69FQcNqSIOJIbbmofE2xR2IHqVP
-JEkIKWfAwsNctwwar/Ym57cGIFhNmhjCcQayAXGnEbeZOgnq0tZaCXQUh96L8rrp
-gFrd6c9RXMgayR5EcMoW336nHqLIFSkTeR1+3aMPTXsRKBvm9atMtOOmfSLmVl9d
-Y9CVRU3l6e0NBBvIZ6/N2EpImfWCUsJB9n1nDCBdofdDZtnFSX8OADu1d6CBh29g
-DaDUReTQwpI4niv1bbar8YBDBxTGy3PBqnJ2eCLedmv+mMOMHNXhLm0l1u7Rn1fa
-e+yu/svSe11g1UEdnpHil3hmcVe+IOsjA4els86U9K7qn8uKVd6nVjkdtadjNWU2
-uvfEgzGMGTi0q8EYGTXthkzKNHB7nORwJG9D/3oo96eaEYV4KQKWMZzcaZbJ0WdU
-WOZ0nhxpCmnxYblxkheN3C+envcxLH3H3Q7A2DY66nM0JOesO0vGlI5NH5FIQOgI
-XuSs2+xXwN6EZweBoXEYlDBOQieGijAFwbH6chcSQ4Zn6TwVwqwkyymFFZL4JuLV
-SD/vCcJJU52kwYpjwk8/cg7++t84qCv+SCntF5VGhY+hQ0K74meqLJitqkfhjPtl
-HewhCCS1CKxG4ejCOLIFVFadlsQU1NgxZv0CapAQPGxR3nZEWYB4KfjZSB1+PMS9
-/EiuFJKPefNwpSNy0MUchPXurc+XJ5hNZQoUbrA0RRreIP4dDR/lmcfbIccD1HHx
-FzRbaTg2/HAgd4ulTCq/5P3Es0zLmqJ4sq6nkJh7I8O+SSLe3OyGsCqwnoOgTa7n
-PWq/Mbor24PZB2lGnxRnk9DxIg5YRL4DAD09Dov/R7wzzoAD3fk3gWUuDBm1NykA
-drb2TsDaep3wYIi/Uf9AHqaNb1laoFJgwb4DrHwsBfDLzCyW8oiH/u+AENaFSkF1
-VgX5DGdWC3mFWozyVVro7gtclS9O9UEGVjQs9l9XsSwdNJUQtjLg3orfC+vur6mf
-UHKLmzlXobkTaVDIVm/ntfLbG6nOyGKHXWZwdDBA91hCU3D3HuFVoKLtIvnCsSIQ
-1XwRAXK6t6vvSpfLCW1wUQam+kmSmDW9YcZhHWrbbfSTUI8Pk3DXWarYyV3dvljF
-zR2/+I9kanpmYkLMEH7jQqNadQ+fAYuaRd7mqRK8chgFtrwv/GHUsHHgA2/EILCM
-zpv5P6FbzhrdXnKwFpt21h1RtFZwrB8O0QV3P2/B1Txra4xE9f6C/7RHXun94XFB
-QJ8pgHTqcXri5Z0ZVfwVZOVcO1l5UKiTZmRHKkAuYk3p22Ry97Nlmef+ubFhsRDL
-QLgNbfWWZe7hdYaSViLjj/YoK/iBuiIX1mlnob+mTBOA/Ulr7rfcm8KJRFCMmwqw
-BT/4xWOsGZenNbq4egUSJhhZl65Hcxg3L1ucfmz/g4w3a3uOG1hNx/Rz4GqWj+3m
-yvXUsSJcw0wYDyh9J29QJDjcK+ir1zey7MuREW4DAl0MGp9s3GTDrOFe1ZDQi/Vj
-oVnuPwRj+Z89s5SGLI5hePE4hRdm2zW/qtubDxZ+Z7JTGH/moMnt75YBqi0N/hir
-9bxZgcNuJVmpfa8GM5frbQGsesBVhaRRSjYEyPtCcvYvaZ6t1kHoURsiuP+3P29f
-IgDIx+z3p0V7c/31uP0dBb1xeYeFCWMNjCjw+/DAydrF8xq53NJ110IX0ChGsX1S
-4EEIZbSxZbbAsdDDEw68R6v2Qxl59TMhxPVBKDPxxh7BvhszyODpmukgCE55D3ep
-6/fyzOhczs2LSOgiEbqVRNx0f5RUzqBInK7S0nSwPj5GCo1ViG2qP5IDVJ4XQ5IV
-SjHpLE1kZnuDtOL9ux94j5x/isUlF5Fb+7udAFDFLIo1YWR5JovaKDmbL3gxO3Jy
-w6GHCTetI1aTQ1NtqQY2CE0TJ3JglYmM7WwVNmvIysGNYq5JEk3Z95FVaaZc8TG5
-GYvwvaD6C1XJ5z6Z2kzMZVO7wCiZ4BOl1xVtV1ewFx2XVEPdtSpC88bB8ufbZgJ7
-ie8QI0rVQLWxcFlFXxZWcMUSi5WTjXP8gHUJrsDpze6673qWW5SOd27X8XZtRj0G
-eXvzKo7PMKVX4JqCS4+cJeJ49ajAUGrrSlJySFlLTFvWjINpSDbyKOfrWqpNcLr0
-u4BZ2CQaON7nSS+TSdNh9LChblx5miZwlW7MfderTOrpY6QvaA/cIMBWFqyYmLHA
-rkhrcNXA2BGl/tKgcqlNofgIpXeaEuq4heOFyt7AUBoGTz+rO13B6KBRkSQz6/06
-rFUNHQUBPm7sfhznL+209DSIESdg0vJra8rp3B9Kbzko+50goy62rs1AqskoAtP7
-jzg7I7+nl7DqReL1pAL0Nlzz0l9Q0KJUciMvMaI0XMfE/UDuotPswcDqB8r7sK3h
-stgL1+27sLNlgcNNt5pcMAOpPnQCkh5GCzpp6EZWlZI/bOjGmk6ETmpQ0zeHA/7z
-uAjPvahWWOKyoex6vYBVrkWbXwsUbU9a8OxpimwH4BnHBwgoJ7ebsOjVcqUL+zzv
-wSqqg0YpaqLlg8PX8xjON+WaNsUtTPILVbmRjjEofSUG2W3fliZuGwVw1YNDbnxF
-ignApcsXLKYkRvUyfIJa9I7rxcvfxRfed5DQJ9MV552dv/eUHAlqb0tc9OkIOYl8
-4lEujpRmC4QOr1xEuLcRxphRA15HCQix/Y8ij9XeaoHlrayViMxg7Hp/yfCn9duA
-mdTfUXJ4w3/5cF7lX8Bk+C+AeK38UpyH/1IopS8UkHJzrCDzLwV3nBtfIHMcvywT
-W/z1RAvi0izbDauqElIwUiXtSx/L2RIaLxdwn6npjJGKg1V2n1qvImuqe4zi9NqY
-q93eyFjS03P/YYT2YqPzI0fyefHszoD4Ps+2F6flnm9yI4h5JzOesixeKxFumPgw
-oll1k5RDP1f3YWZFUSfk26T6xfVlAuspXeNqQanNO5xgfTZPX3JjDFtFCvbDylRy
-wO4TZcmLBlbfkp/kUhTEQ6P5ehWLH2RDorCnAb+TtAPMlQz9TujELfrWFlevH2SM
-AfcqQmLGRr24NQXkbsHn3JwLr/eCeKGfE0xl0j2VQC5Uy9KyvJQCq3jEDlPGN+6s
-/Fyw0L7EnOLKFXp3Lfkp7+wN/W4VeObpqsjAZKVZH8DptYg7mFrOO1z5siXwzyI1
-7rEzZU6XGO5LckisMHAoaxrOc+OeiddImYv7ysWNt5Tl9YUPLDFHBXViNUE4Emfb
-w0CMGvlqC6l1q4p9OGyqBdQNN279YNXQYqEBZGEIcQ19w281WZaxN8XljaH6VNZI
-nVhujwAxp0qTMxzcVtJBV2h9zvkxFZdCHcRnIN1+4XEz7NtF3d6tvzlWqOn3i/VE
-PlQxPkzJeyFEhNM5+TiUBA+TrvsH+GA5RqrDU3vwBeyybz3QsGCKnTzrbsKogMLO
-Yf3zRhgKppvcqZzxyP7qWqaAJOcmhQfAI4qNHJCgUlChjs28V1f5DUykK2DiAdC9
-SdW/+8v1pJwlAlcibs4fSeooz+ExK4LGJczh1j+4kTFb3MmNeiV0sbrnhDUXl/lj
-3Tefeai5yU5+H0b/DBJrSZ1pLbtKrxvHgRV1am5LR/4XFmCFjOKdHYYJPM9u0Anq
-QPK8h3rW9SqW1zCOBmdFF0t52k6bWHrJIxkKbLsdG7e1h3VaypCWmTL+yMhIfb9C
-DkCxgb0QTggzTeWORZsb55w8Gbe8SWfO+A8sZTpZmfks/BjZL00PMZkQifBkVOO5
-sKWj93af/SSoEqmoDuFjpKphsY+2t9R7xfWwAjeeDkmvjifLSZ4h6vlggTE41TDy
-TnzU0x+2bveWBeOf+JRhpzIheL3lKGQ9nbnV7Nuj0qMAuqt17WxYr0ZAZTWbbrNS
-A1tkdlwlrQNsO9YaYE0YrQxB96O1N727+kkM+DYafE+/z7wFx7bjieQk/ugOcEew
-pjrWHowzVkGw4cvBXpgwyr/Lw4F59r8cvbkfFjz41J0/w7adGwywtugoOdDkZ87M
-aaVrNdP2d9oIjLTChWpMF2cUVISZQTi+m5T6kLbmajbRfYEanJQ5qjf2qie5rm/b
-w5k4L0Sv769dieNAULuHR5QQEHe5Qr6mi+9fEnM7+mSaU3l4fsrPClwDMwjulshR
-5JRbUtGpq56J6+ZCmN5N1gSO1ITqRNh57ak/EhY770OMe7AN9DDQc+VbY+BZJnC8
-szBUFl7sVWBTlX/miVj3uhYfbN/+1gYpCOZzRymimJM6JTV1i+o0EQYKJG4X8LXN
-n1u2Ojz2EvGeeZ/Y8TZOd2K871N66VLOe5eXOIIYiThfq8BnoKtv3TXk20oOMHcq
-m2t8JsftzZKskeGHBkX+HROsRuxyLsVkiEAjrMmgmLVYC2AlCRYWkvE4y3EUpbj+
-bBZzQQ3P56KxzKrNxXFC+vx275tLEXhYbdV4GvugSHZ+KpL1ELu4zw//HYKcY7YQ
-EAyYa39zk2A3eBUdI8ZCCVUqbImTw6HHZjG6jER+SLg68sOH7HpLv6ckULwWw/C3
-We0Yvt6l0WKl5WZPdueHtTsXxKDUgrdR/TDiQ+AiNgDq+qCdmLUkOHxAU8rQZ8RM
-Mhsm7ImFVlgi7NNmnJkKuuHCA0e/5fpuusATYHhmjYotED1Bbg6JDZLwtvqePssO
-qTsr25wqi7vPu0rV886e637lGNCcjFX9LuEwa57qfvUUi0Lo1HpMaL/gnnYKZQmi
-8gs6MxrCbmQBbCUx1hDatcmVL6eOAgMTJp/ghYUKOVvzg6ba+50NBvKvOni6IzwE
-2PsJSxvYEoYziem7fiz93cN54wBOZnP2vqmxC4QpfW+/xmzCw8p8/OEmembGq/Jf
-06lRWzPxWAR4JH/WykDIxpwjx1xaYTNJO0elPKwpZQHT6YOcvxRj0ZPT6qdZERGH
-Gmm7J7uctpxcQp6cfLA1nkn78CPBt+LNz9hP7l+zqKzG+j986Vf3uC53eU+uqfKY
-K809hYN4kjY0wQunQ52jUH2P6jR7NVYnoBjrRgWTT6AwhPVPREqIVHta++4uI143
-AD15p3hDbqSsmuBQcqoDhsAHIsL2cY9Z5QWNzTBVjeTt0ZxklwTrJlSYTdeBHWy+
-Pi43Mtxq928wNPSjL5WnGZNyvgOSNieO7r5bubOd4EnLOTP0D+w2RMxK80P57hKo
-nCSBmQ1G/R1Dm1+ITx5QaNlqLWg1xwunCeTnuPjjrXpo4V3aczQDZLOtz9QEJvkt
-Yofhjs+XLc0aTjFNVY+6AvFjnKFzabzGXBsPFAfsNjRzISh9Zuo6PhiXx+Vy85Yp
-oRGMTFoTO1sqIPPKV2KixDmbT4kYnBGDhjIXBnSQfmRwII+dVvsKHFLabn6aaWyQ
-hIzV+vOjfcSyG6qCKUplZQTL7M+gx9WrBniKoRkaNb+glsURq3mGvr1Ye6RltgA=
+G/ucAKwHeMM5qeMwlbXEnOWqyaMlJ0MIHewh8mOrVzJTzaoWV9kf5Jv5MqSic2g/
+6QRRDuCSAyxF6gIEHHLAeuFWW5SmN7zetY+LsoTs5/TdvUw/Rb90SJ+C3c+lcWlF
+wGGU+CQ/TrgM2/pzjszFNBdWtZP8INbkq17qqM9VJyEfTBbrEEZ482zRtYHqzMfr
+vWq4I0yliTrL981SobFBrCgyxmfGJeldd9drgEPuADucWYBW1u6d5Bdo8/qTHA4l
+kmvAddLcgStvI5N55EozhRFPSaR90Rn/uEInV/4gsSyPJVsj4f5W/rIpnj9v+S9H
+Ob6/tsXl+nS1FKWfikd+3z4dL/G9/dmXS+DZR3a/2Q3gUAhFEzEtPTJw/4IulDEC
+MQ7W+fsjVTfl/NmbAZfasZ1f4TbvUEQcGQd4LtiRV2GZK57y37LUgirX/R6Bawz+
++YAklpDQrZJ7JLHMR7LVf3trGLbfqKQmryJ53wDhjlxx37qX/kgYzGkDOMsSX31W
+9yYyD7UynHfMrS2I09pF8odxzQ3iX+vWVKLYbDGdZKDMbAmhokKLM5U/JeRF0aS1
+qbuepZ7QG6L7oWnSm+ZHfpgGLF56gCMvam0ZDqsdJQdFSCyim7ae93uPUxpKGZUC
++wM/agSUcJcF/5NduVbsOdAlvEIql4GNJxc57XybcFycCr4bVZD6Ea2+sAnvNi32
+GcIZ7eqOezlUBUtMarK3arE1Sy1iYYIEO8IIBIv2I5kksg9FZpwxLqeuOTzAiL8x
+caTPVtiNibUBwusqEVb97YPIdaS5tW19bqg2NVeirR5p5+7tqlYSJfiFIiIuJMOZ
+ayP/nup5l9a87c5bDkEGhHy75yLScravlD8ecvKgAi8tF7czBg43uBLN3FTesjgB
+GSYm1vttBlCMlTAixFcStUuFmxtVGimjJwb4Pivw7pDat/r4NXKwNn0OysBUmrtH
+pDvNKJt8/vonPYUlKsKoNB5vDedhLmExYesg4spuzdVlYcwhOQJ8+yBEEcVr0ldO
+lgTQ9crNLrHQQx11+2zZCn4EM4hgAWE8WrosHma16lYmE/4W1l3E8CrQVKiwF5PU
+pvEImjENvQvA/QsA0FXUzfWObAe2ZQ8+qRP5//XtxoUotsieGsWLWDrBdDWD77PJ
+ldYXq9oo9G7/Nx821EI22s2iOPzNEGkL0yGgHBJm8szNS2Xvb1j70STR9uVbHKLi
+DUbjOEodmq+jzENa5rXpxsBQsKLreZE5PoQyxuPyCEeerOOiLLsOAStC+Upn2jEB
+KCq9LfS/IgdxRWSIQsD9mnJxfjJDMPPF06M0Zu+op9EDXZ3kCh0/dRtE5hybrWlu
+z6Z3FQqxBdhysQrXeZpZWufqFukTaqNa+xYBp76PN53rbRs9jQaqrdbEBzqRRtuI
+mXFeqmiXrdcXeKVbHnYIdY/LSmpsaZHCCFF3NKEpwgSeiGmF3IA4CvPxE64PSBCZ
+n4yPKmFHiw52raBFwzTDCfWcNCj65fhoQ/f9zWXS9jggavXKRyFYmCgd5252gfSD
+O0dQIoj2NopnEba7W4hKutc9zVI/btUoAwDeQOhJkysvuXc0Yo46fSCg+Dy8lhWu
+Iwsukpa0z95jJdWGwt0THFF5RLHFiK95gzJvjZ9oB5XEfmkvaXSyShLkBYVqZ+dA
+tJkUVPcPu9+vI0Na/dwGA1+GdcdPRsPk72nfamTiCmhNiB0ViSGyRIPMlUEKENsF
+weWt4kLmiAzVp5bSTOH5/j9pVoqCFLruFKLn5GiKjzEd/oE8zTPY8N7nl2IesmMJ
+3lLPNHCfx53MruWwlkaN0mTVRwwegQnF156AMFVh/Eei0xfVChhOTPO9/CkoGil5
+3tGY2AerIKQnxRU+iJ15WkkVMb/fA5zjhXBiWerbEW6O9gyOVF8gQAaTMj4uavFJ
+Y10BeaJ0KMlLtka4uQJHUGWWxA4YM1ySh4/egNMEimvs/pI1ol8Ky6F51Ii8Ary4
+VpIKEHOuRrVNBdW4CIp4/rS8aOdMa2YS3a4jYo8s40fB4kYE1hZRb5cdzNQJY+0w
+mKCmWGiHzf8d7eSCf/AQ327SWWbPw+mXrA93qvj1MBlfv43mjef24Airja9PRF4G
+AELoN0HwS0N5wp2I5N31TjkpNlAu6YTjhj7BVbmmBh/uyw0RKRPdWPwPTYo8GHuf
+KDg677gST9vDThAXuwKHX2G1VsUurBuIgTwc0UqfWj/tNIUsa/VNcE7y01jMCSFd
+DueNxsxKdFf6xoGcjNWmsnpAxgHGNvMQLRZ3titR1o2nArouZrNSSGDnZYgfkfS0
+KSvff4VuN9Aao4mfarII8mxbZyTvQ2cAEEZhN+VnPuyRbpzoN+pcFNgaapi5Ne+Y
+3lo8c2mnH5llyNc6I8j/2up5+2XI2mmICZqAdpPWIMuEY9AA+yldrKXWwN2SEERv
+d5g7OOIVsV4sAxBsmM5Bb3h9V3SqjQKzOKDgcg/m1PYsD6Es1PJkEUycD7udjbqB
+Nk7wXPYQpvI2lW5uYDantSjrROGzZg6c57rB80Fz1cGwrdNLbkKNd3ev6JFAS+ZN
+OfE1lGrb/hwyrt3R+vt2dCknFCKamReBhsrIrEgf+6hjvenSXrYZ01IT5rWHRj3a
+iNl3RFGaveL6ULdmf+Pku3sPZDKRsxBVoEZgyKWYdukF8Au/uIvWnTxorYYS60jv
+MeMzPKYlSIFoEIFDHN+0Zy+0qDWKlladxfgPVXoskudYA0O/T9pKkQQXWr0VzRsQ
+Lc0eM8qTnPd1gIdWQxTPH3rSIkjLFGBPZUByuS222zbyfEqytWMcZHe8NnStmiYW
+NVIG4xpyMCZimdOwAX48r7M8u6N9wdEnrCw0y2/TSznDfDtlFF5owxvLUMIZBOzD
+oeJoqAPCk70dmNjZxGBlkfijbv0WBUDcSjEUqPcq4N9/+ROGNVca8vTbkTrn7nAu
+g6yr2UWqAqbanQ1KluFiZq0Mfdgx8VHqekQSREaldEOIH5HEYIvlf4E7pcC4KanA
+xqWmw2778zjqhxBVKihgvkhRWeLr66hBf0FE0Xh8dMuMwJkdu7K9msgtJZlUYFs+
+x2CMDDZEC8w83FJziHFXaziHk9M8iNtUXjefphk2iiiLdRbHhJzdq9f9mY6Md2JM
+u/baQdueqf+v8xFHj6KAX46Ii3IhFvze93wcBeKacOyhbdNcpDNbHeA0knQG7vDp
+Zoc8s1rw7cMxj7y1b3MWm7giYs/eTSJK/A2m3q5a/pqJONPDx5oAYkdNxVVLm/Jn
+DY7zhXAHWSZspMUOo2o/iOeBpqJHVR39wqY5BRaHSZvQ4xaE848FC5gvrmQYOTvG
++XGNXz2a7NRjkpzmKt1H19rvGiVzEUDx3ZrdsU4U3v/hFmtl/MSQySKmyy8mFvSO
+PPI2Bu3V6rKk8aROhP5iUed/dH6Y6K7ynopPB3nYunPSKRZ1rhnu8uWgbU+vGdlR
+KEbXAMOQYG4TcGY0RIcUEnHe4IfMcaSVoadRHB5O89Q2jNi0yp1VDYr8TwWtrZJM
+0MHwmCn1wzEx0TF357gQF/G8aHJdmzYnFz1Y59YJb0483b8hjfoEdC6dvVCLx/lz
+k+led/681CulOH70J6+vOYGnD0gEli14zrb9+RzgS70WwarCQiTrjPntb3aa3+oz
+gct/4Wgg33YrOQ6YriLKDO0hFLQGV7ZpaglhVZPMTEpotHSivcibOJCAdwWZS4PN
+poKKvKastfx6u9m2aoLY4qXlOXNYnfFUTFgoPpXqR22bDFfNyO3dVMqHYBqzuQh6
+y4H/5helxi9zn0wAbxrCEkgzVzAiGVRsTOoHF81xkKrgNgPv6tW7SxBxgIl6cGN3
+ZmWQGiP/NktqdfQL9Y4Ut4KOrpTntfJFwBpQ3xoqrwFOrwjzEuEEuvLYkcK34TtM
+b4VI0KVwnQRQbjyjhk0jMByXoTEVNsJdYqwbiKMJ4wSWdhpGELZzNd3tNo+DNa/d
+RVMQbAx6lEIcBKAbblR8jg/ljm0MCCEUK3RglyUfZxS8cOczJ9icDwQ5DvwidpDt
+/fCEgiOJj8oI8uJx/JvqmyhvKD3iwpLTQsCwhGJSh5qvBRnTa5QdTyLGdbKsW5fO
+oQD4SGRiA4tOWSaC5BpKAZOqb4jAi+Zqn/0uq4e4VYcTz0VBRGxIg4khtuqhrW2K
+GxDdSj+IkhBSvgAL5+nuEors2j/sfi9Awmrs0GCjMJANiMd0lEbqxGlMusES6MgO
+vRdd66Ydat1Pf++kC5dGsgPh/LLZt39XD1EYo3QReR1+3aH3TVsRSEvm+auM/2Pn
+kAi5FbjXFujKpKau6e31BBrws49mLSbY5HpBKeEg+/tYChBdofdbRtnFQX8OAKrm
+O9DA+SeYIqTSCONOmBMnc2FbN/p2POBwB/LjT/PBrHSv8IQ/715GU5jRTnW4azXL
+3bnGT7eveL/6g9kPu8DqDnV4RgwhwpmFXdcFWRcZOEyddcak36v+c8kslfexVY5H
+belYzpoN7nkEjG7MwL7dDEXPqIlnZFKmgZvv0gNH0jZ0r5tydyraUYiXwiFq4fTv
+Gidy9BNlliWV/3bSGNLsw4zvxPAa7b/674Urw9R33OwAjK2jozZHfXK7dSfJhBQS
+6gMSMQjdQYsr6zb3J2BvQryBQNc49EoWTkKoh7IwBd6x/HXnE0OGY2k8FYJhXDS0
+OFmiRlyohn17X2BOWugkDsZ+EX76na/gr7/FXt3wi8W0n1QaORdD+4R6xY9UmbNc
+ZadiN26XtbMHLxDVwnEb+aMT40gWUFl1WBJTUOOdMeoXUIP44GGL9Lk7lA2Ip0FG
+I3X4cRf3+kNXpRB/zNMH4zbLQdfiIMx3b/0+vUJuylOgcP3UFGmoBv961H2Ul05v
+l+1n2OPkFyeaS/2x/ocdubuWelT5Ke+fP8NhWS2Il209BsbcGVkmTiz95mY12irH
+fQcIohr+kW89kUpY77mp+l1SJbBe9kHS5U3+59ycTKdS6oK7PK/jkxwCSRhcFqw1
++BzT43pj9k1S6ec1A2HI15EdWDxBBwDYVLiO/531M+4BB3oLdlDyPQwFGKnYC/b4
+1urjw+lxh4eDIDCiN8jBovFXZkWCqIXulwnKthzAZ3OzXIimOO2ChwSfMcQ56m5V
+kL/mxepCv0IkSfn6HiS3GXuVL07sRAZWpFv2X9epzHo0cRy2HOPZitvjdXfXRH8o
+pcgN+qvQ3EFMKGQrM/VWSfoksSVZ6KDhB1sHA3RuiaLgHhzhKFCRNJLcsa0IRKWO
+RICc3PPql5IUcxoh9igDU9kwSciazDazYx1q0/T6Cqjbh4m428xW7OSpblcsXug7
+P/mnaGoyfmIa0hBu40yjEo2PngOLqkVWsVXxXhdHBXbUr4xrTGwYeMNTMQgsI29o
+eQxNKWvkqsnGWmjaEY9EwRpjWz8cImnufdqiw3HA4ndsni+4T1vkcfcP9wsi0lI/
+SCeaqCdcfjejBqGCrMg54uM2CeUBjWRLBcgJakzfl83mZssyj7t0Y2OxzstAuI9t
+9ZYl7tHTBCWJUMYd6YcruIGoKOfXVc9C/bREE4D9UWuetwtPZREVQTH7VICtlH47
+zm9nnJ+mVE77CiSMjbImXbfmMB5Zbsn6kv4HGc7W9L0bWE2+92/B5SwvqblyOXWr
+KG5hJozbkh80NAhxHO4B0vDthZ73ZcsItwGBroa0LRs2UVY23IcacXpSX+bI5fkj
+eCj/vXiWZMiSGYYPj8OnYUPP9EvlqvPGwuNM/siapaW50W0flw6qzQ1+G4tUfYTD
+YcsSrVS/V71ZUl0mwCqDXcV3GoFwQ4D8bohyv0v1bCU+QobdEN79v41fdwEA5Av2
++KRrd7l+XLS3UcjsyxssDBhrYER44ouIRbg9y3WjPbdS2iQABmP8UaiuChwIjY+U
+imZzMytdPLGDd+gklugaZP2JaYh9EMrrj2OHWHkcr+3BS2XTQctc8BHuWsft9RnB
+uZ6fd2EuRiR0y7fYeTwxWWdQJEw3KVE8WBcvFQt5WOiEKruTPdTlOoaaLERukheb
+sPv2emmkCOyx573yL1IsTl20heYfbAdQDLYINYWB6xkbbaPkbD7hefhol4c2/Yhg
+EZhihVA0saJq3ISQY3EQDVYOyiQbNW1nDVkwu5EpNk+CKTo3WYxp34J++s1pdDmo
+Uqbqd3kgjm2mQ7OZY1jjmeATxdeNbVdXrIy6ILGGsmvFs+aVg2UtsNUE9LjvMj1I
+RU+1svBJRXcLq/t6bwubU1z+LRb5OK8Dr6WmNVDkFIsbuB2fgImKMOx9wsYsKD4L
+I8RlbJDi/UkVgMI8oGXmjlP7kHVdVYJTLti56+EKt6J0sbNBGW/f9NRDkLcsT+Dw
+DJOlBa7JzXR+OhHGmyd6hhxbl+STf2dtLimGuhx80IHo6fLsaSRVU11CiLCi3UQa
+2DKWsY9kOHgYPKitjgtPk7evLLi563qR8Y7ikhJ8fv8GAhXGsPhvxgK6x6Z4ERPa
+EaT+3KDM001s/4hYzJqo97ja49n0Xs+QG4aOP6ugRMHo8ECRpIMZ8IsFrAD2yAsw
+G0DHeJy+pM/sV2J6nGAloZsA1uX4/b4U80G/2zlx0MWivDNoazQKV//BE87OlPbF
+xa96lngygzvI+2vfuzMKapRKauQ5RkTz6+inH8jjtOseq6z9kOgAlIdeAo3TTrvJ
+bs0Eh6tuNWziDNp6ghIkPoyuAZKDj6QqRX9Y0U1SGhE6dEiN3xwOmCTiLLz0pEpm
+ifOGrintBazMV1r9GqCoe/KMZw8GZjuAr+s/QEAFgFV1pFfPOvGhwJo1HfP3pJus
+JqJo2H89Wuhc9d2iQ9jCKF/AyFnX4VTTjtR4wiMPbro/UuPN5QYA919erxsSGdXL
+8BFq0buoF88kGl9YuyehTwZiLnt3Ps7L9gFqqSbud+lIdgkT7Z18FClNFnDG3jAI
+cHcljD6jejwjcrGxlZ3CNNg+q57Ftaxn281g7Hl7yfAX1duQidTdULJ/w9+9P0/w
+OzAZ/g4Q/yvvivPwd4VSuiODlKtjBZnfFdxFrnyBzEW825WW8f0XLcCpWFZxovI8
+cjBSJO1737rINTIRMFgiqQZqRnI41lVt0F6O20KPGMXxtTGXOhXJJNmrC39g6Kjx
+kI5CHtHnZQh0esTP+bWlcVzu+0YDQsg7V4zqutpbBfwNIx+maqsAnbLv36xHP7Os
+qAPybZbt4qxMqkJK17hZZGyzHiHMxOfxS/sY83ERg/2wMrkxsK5GF19GHatN+c0C
+jnaYLnZ/VsWuDlH7FbY0WNkm9QAvnYcQMjTiFn2thTMq4oQC7k2EhIxN53mudk7g
+Ci6X5m726wMwZ3fzdpYy6ZmKJ8fNfW65dM+qWHEIPW7GNZ6s/B5bPLkSo6UrVwiH
+tuSnvLE39Lt19OO7V2kbJinNTBAOYkf2g0HzrEfO5y2Bfw3L7oAcj3Y6mfRdZbuE
+6t6VIQ67khrnE3xGzJzsSeOe1OTl2cTTt8xBQZlYH8DBi2NaYiBG10G1htSyVcU+
+7DbVPOqGG9d+sB1q1YmpWRlAnKKq1VfDgBl7k12eGir0Z51d5ucVWYjRYhqdYee2
+Eg86F+9TzvepOBdqJz4D6bTyx9WwrxdViWJ/daxQ079/qCvyoYzuZEDvRx/hT+fg
+40jiPQwn7+/gY3E5Yh2O2ilOsBP8dU/DjCnWKM38+FEBBf18/eNGmOOmqiSVGX5k
+bzprLSBpdxPDA+ARhUY2iFcpqFAQbN6qK5MRBtIVMHEH6EFs9Y/ucmE+1wBcggZH
+aSVllMfwGBVBLBjGcLMXVxfOdq1ypV7xXWxberu1m1XGj3XXfHyv5ib6Ml+E/hEk
+5pI641p0t8+s9AMrMv+c4lT+d0xgOeFi9SPDeI7kzukEdSB52kOG8KyKfUOMo8FZ
+0cVUntbTJpSePU66Atv+hgrCdvK0JQ9pninjj4SMdEwo5AAUm7EMNpQw0ZQ5XkQO
+csrJS97LqnBzFg9gKcPJyshn5UfPfi2dyDUhEuEl38YrzktbH/SQ48SrEqmoDuGl
+YpUVZM9puZR7xfWoAjceDkmrjpekSl6H7eVggclFVT/yRnzUFXI3kzv7nfEP6cos
+nDM+eOH6yGQ9jbkVP90jd6YAuqt27axYbwZAZVmwTiWwgS3cWa6Q1gE2vdAGWOOY
+LENQrc/2pk9Xf4jVwgaDn+l3mdfgUO6fsPfij6opvIMq8o3tRk3YGASblx1odcWh
+L3Q5OLRI/j8lvb4fFjy5VB22Yd0+/rZEJtFGBcK6Of6tcRW9p/XvguGZKMWNZO2u
+ziio8DODcHHV7XqftubC0UT3BbKa0mVwvaFXYd51ofAeztx5Jnpyb3rFFQeC2jlP
+cEBA9sulRjaBkf+U6MtRk7EfysPzS3pW4BqYgXd3Y3uW03Wxik5dPlJcNlfCwHWy
+2XGkhoqHHPmi6xQWZ+x+COj3QNn6MNBzCXET9GO54HBnfqjABrEKgw3C/pQ4mqpR
+XlzwUSRsbaAUaz5CmyKKOalTYlMVwaeRMJAncb2A+7b+bj/u8NhLwHvGfWIt3yTa
+gfGBT/GlUznPKS9xB6ZH4vJWBj4DXX3trj7fL9vBPKbcXMMzP25Pj8nmHz4BL/Kv
+BWG9gS0Bkk2G8DTCmsz2mTY1A5ajoBl7mcsrBiit/afllaBbB5sUm+NtWk9CNpAv
+dvJtSlhIdRm2UuyilidF3D7qsTEEZFuO9j4yegoiG7ZyFy3tN++ommKZcBoZvQoQ
+C7zlRKpBfwXNRDm/dqYHyE753dOmtEjvpa9hFHsDHuKQ9eARerr459tab8jmH+Mj
+6XaLDp9oYGTMtzt+4JgKpRcCOdCSrvYo/nJ614xwyu41BGGSw8pOMScizdlwTJBv
+HEAIE5VMiWonPe6bdQLYNU22hjZKzSSdmvzIk0qAwRXuiFl62nMwp14FRCxBXjr5
+gzzCsS2F2FzwZN2l1O5d/YwRJLsQyDCnQZBuQSPwyfrTnuuwgEFDrbLp9sMYi0sX
+0UpFNqW1DBDE0I67L5WVOMMQJieHVoJ25RMM5/DRDXvCVP2eMy1wzUN6em1m5k3N
+0Ez1/s4JsS6AOyymCMU/Kdyulm1lIfKpsyVBn3vdjSU9eKVFjc2ErU7DqaIQUuT9
+L4XnYaWkZo3Z05Xa+aNI1n3s4k3EZwmCmH8q+dSAufXX1xH2kFMRnmdsB1JtGVlO
+qKPHBuu6hER6iL9KLcK3mHNAjysFireibd+aPb3hx+Napenoc/dO7GaBHWrHmpjd
+VX4PuGHEh8BFrHPVomCJuS0Kjh7QdDbp1WLnWU1g+2JxFmyE93EzyY0F1ST2/cPe
+/eNHEiPXc3DMosKbJ3rqCtgnNhXIW9F+9QwPxLq1f9OgibOs3DEu2D1wsdYDRJCr
+mpqKTzicFXE9FnrwcyF0qj3mlAc4rrw1IMfEeQycgTvh0HMVu8rGTlm7nmn85aCu
+YPrN3BO8YLCeGFJ72FQtlbOORv69NS92hgcPe39gAw/bqPNoIr0/nUt35+w3duBk
+0HLvp5qhQ5jS3+3P6I3rGffbf1SiZia80WqhA2Gqa+aesgBT/65ZPRCSmHK0M+dW
+WDPVjh4v92vBOINpd1qOLJCx6ElpddPs+wl9DTU/k8V8W15fdjZ/cMF2Mif1w8cI
+admbX0ur3L0mUVk/y7+569d3tE63ecfOVHjMlbqkwnU0LzUBx4sugpxymSm+U7vu
+xVh3wSyzauCafwKFIcw+YfohkllvLS+9DvjYQNToXeAOuZGxN4h9yWcqMNQ/lBC2
+ZyRpXQsaG0itqnbaeVblPQnWDjEMGu3BDuYYXZQbmVS49zeYAP2Sl8LTzLz6Vgck
+bUrcuaNbvrON4GXL0ez0d+w2QK8NKhxalEDl0xggtoA81NG1+Qz45R6FNmfXvFZD
+GXEAb54ujj/epp3m3+nivRkgm+n0VeP05FHEDt0dny5bKas/xTjVYYMC8X2coVNp
+snaGlHmg2GD1Ky5FkIJ6de94Y5wfL5VaCRfESjAyNlMs4KqAzAtfifECZ1NhQcKA
+hoMTosV1mDvpRgYb8tBpsa/AIW1E5rcZrYkxZqyvis/DF/N7qoDOKJZ18XhmWBGd
+8boa4AUH9hX1u8UMWxywmmMIko7FomtIukYE44m8WSn8YZxgi7PB7pYAiIrCaKwn
+IgqHHkU0JNy0hnfO5wRQLUYAgpVT7aBIh8FmmemjDDEQR2LFxiqEHet41NNbDL6m
+NVWMXiJbrzAZbSWEc2+x4QFb/YbxlG2S3vRRn/FaF+VpawnzngXuwKAb9/AT9wSi
+e1g7CUPcw7ZSDb+GBTabKLnDYeCqCKzKOoMtytJ2tAwTOQf1NDejuul6YOSVqarQ
+U+Hw0wCriPHjBgQLt2QsHuKmSl3wJ3Zz2MheJGXZ0PMGahvuxGs2gygzjSjeuVZJ
+4aIrzlTJgr1DM/nEYg9jbEsn1i//s+gFz7WEY7cepcQHrN8j+hNK6U/iqB2zgWs4
+FcHjQp29FNl5pjcBTYql8GK/0PmpglTOFMDSEVCMNzrKogQH
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/es.po
@@ -37174,196 +37190,214 @@ ZgvLHBo9Y7bAbLFAvPE3pS1S
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/fr.po
-Size: 40520
+Size: 45337
 
-G0eeADwN0GPjRypkqsMYHU+Z5cbq5KWfj3FselkkR3HIt+abiL5pUTreTfH9CSr6
-KuuXRD3bclbauyunFWK1vvlmiBa7n637oqAglucdjZzOjcraqm6ddjhdxI7n/70B
-69c4WvROUP8umGRbmkt/Rj2MWI7TtHz0l15G6hnVvFqVW4iYmaavtzxDtyI7TSly
-KeXNw4J3uoAQLYga5/+1Jg2iUsYXPv29+TwE3VQnvzTAagN0FB3p+bdZOesUjo3Y
-lstcgKjbUl0RNBz/j3GOqZrF633VbB1CKq90KPqcunPtpjlteDvC/yCGAcQYBHVz
-AsFLlIp0UTfat+8tBH6CNBWHQXcKF1J0jp0rtx4XdUixaNx0tHNR+iH/K+u4M85H
-XduevpAfIYQAiXu95meiyev7GSa/O33RNrs68SrO/Jdqr1c7iV/pqR3+GebXl6lb
-xW7z/oTKmzvgKUqX7t2b9Rz/17av5Pn3BcWtqu360A+YcdVeOLgJ3FcAeuziX/1+
-6n8r6/rkjB3Q8y+OoDTxqzASgJ+c+RURb+LQThx+g4xMWXu2hy/yMvkrYcsWSqEe
-zR93/rZm9dyM+DX1a8KmI3fEh65j4Rrlc2H8osfSIaToxs76uOndstQOxTLMeYIa
-NOxXtcXxxEXF/OaiKxYa/6Pt0T+eJTVQKmqhu+Cjl8cGlKjdNcjqd/+lMEfNDOlP
-VzdeWAuJK44N81s97naJe6vjCrjul0E9uLP9OySj6KN17xa785w/8vzPD/o4uX4/
-3uH3tfcz7tfb6ezHxSqeA4LRXixHKuBvdPxpBz36fuzhxBJyDx0labSABpsqjYvM
-QIlcHPHdYxXs6H/dC/2komHruOzkd/Rc1RaO/GSp48Nj8YGoyqfe+aH4h/R1oIde
-HoHZloJzuJ6Oz7eAk9iU2rsK++KOVKU5YCRAJoTdHzntX64iy3ip7YHrh4PuBc9t
-jHSiTKg/fIIT1MnM7VInx8KnJAhIDIFhQTIUJfSPc5IqjIHEaCVEqtTGCgMK8ljt
-o+Kvrj6Gz1Aa2L42hprTV/9gON67Y3AZ/uSEKayGmYGGBO9cnw40vB9iohv32eWD
-qrFDly5i2FTMzdDKWB2gSU/qcnwH+/S6kfLMvtdUnKD2fMe8kaOjOH4VVIEOePzY
-IP4FhjF0DDqarebG58ZSMzNcBWN1n0dhfEMXhxqd0Tkx1y5hDM5ORCcAkuyEiY5h
-KDftwnt3yKFGMX5CyjuwD4Mdn47EPAWhVRMAhYIeriZt5YzxUVDFr+ucCYLhz7hv
-CId4qOLIe8deUL0NKph54aDP1ASl2CxkT0JBkpSZq3fKlYeNAgDie/YJqEtkDpir
-2dXwUxlhiZ2LQ4nxZaWOZzeH6hc/mDqawKDsAUeX/P8xGDIc8XfXx4hqK20pf5k5
-CrgmJow1BHUaccv8dEYH880+EpIUz9cWmZDsxHJf2X613aWWj1GBf/V99AlrgcCC
-OBhxaEU31p1dzxSqjS2IH0nbo7aaLiPDg1QdtQrqli6ZmB0SlbCmAa3lbwghE5NS
-Yu7qJXDCQFBtOihAdAgGbNcuLAjayT7t5+Dr4WgLj8M86sKTmz7qqtCHGt+HMzkB
-syHJGIM5GfuO/88YGAO53BWkxAb6gzsK6MiOXQk61nwEiey6pmu6eeYBsisXaNZF
-/1gJBUh1B3XcDJngHizFULtVAqW/7Dmf2EgM5tpPjnokzefSRBgUHgRYmXoHc665
-vksRcpd5MVVg4DgEiu67a90BjJYrZ8Vl4L2lMzaKS/J5pd1VwMgjhHpR4OGj4HEy
-FiQENlGoDk3ODyH4kw3RtM22fj1GEu55rgMGnvM2a+rsbR3fvWDAGEG913383LzP
-g1GOz2wi742NXKrGCFfjmLoaOmuc+bIeiEzJdI37t2I2Tcxe13ayt8Bfd3Kpa5ej
-4ZyckreFRovO853NJlvIMAY1llQN+8XARIkbmDpoUCbrUpM+XA1uBTrwboLiL+SQ
-F4W/yBw1BSB4wq9kxsgysiSfVslv3b7s6I++WdUpYUSnk8/vsFeo6+Sjannz7UrG
-/DEu5abQ7MVhOvu7cvkruptu504fgltNC43ApgUp0CZgbkL9QNPffcjOAIr62uOM
-YvcD59xIaXCPMwtW4sEEqiSt2/pgI4ofRyQ27t0nyDZRnYoIbxttYcAcC+4PqjPD
-fgRKswXF1bHMgmOwexI/YjsAAdbwUwEdDbsmZUSvEvsOrPJorX5PGP5fTzN961x/
-9WSSXCkJ4VXtXwHvgZGKvvHIPlgo40WkiUsBVTBWFBTFHCQu4FGCX00EuPX0RZ4C
-KRCUQljC/loUJIRCFlPrTXOQmx0ouwD4fAqax3JmTUe3zj16TQ9lYQiSQxUl8Jq8
-oCdxUlzEaTa3hno7Wv0EAXRGTjZLxkgpLAFokp2dRWFPve5uAlUqnvndO1GoWUNS
-1tD28Y4CJXeSaHwGx2yUxBHgRyfp8KOU+r+4m7XKradC0XEJf4XdbZQEV95Zjhln
-WrR81inwuOiD+BajD7BjcMby9+uK/iC71LavK88x0a6BGk8lZ17owgp8fjWwKz73
-QllmBxB46LE5gSUO7TloPp0w4zVVIfNYjxGhehafIRKjhLeLM3KgpPYk31QDY+t+
-NGCjnvqiAtmbNgurkLuquET7lQZVSBsvNcz3FLE4EVJLaXQVpbDwdxiWgTbtQkez
-kh/gWwXvj0oB7qXJ4Cf9Q8d0kpqNv/cVHEWp7dshbiZ48MBD1DCw4EmpcMJa/ZKC
-jllHgiRpkd0xK74jMIR0lgkPBKZmDJwCpE80rZDTNqiFVpywbSkq0WHHHaclckoI
-qyoNj0luD47kidrIbXAszJIZq7MSwCiZCD1MVqjTnFTYR7IUtQcYJR0NnXMBTWLZ
-e9iVTt0OUKVi7Z4nv21fnAmSgeh4M8oHaeZAF+kkoXqMejo1xrvxos83hnPnknqb
-GtRLVsZujzww84tcBgc80D1kB5mEwI5vvnxALu3MC+Nkpic9dHMKYeyvoeMMxDTv
-fmQcZ0jeEsTF/bUgioRGADR1qQeTvbFQHA5OPC8DnL2DbnJ9//2L17JT+88aNuSX
-zQZBC37Z83YP6GBGMoHldX//2/qLEmES/z4k3aaVzYgaIQlZOPkQCDPwKtxsHorx
-VS4DFMveDwPP8qGcoFZR/5BWgo3W0kziHhaiStsLTgYPKcGmssifEaAlhtbTyfSP
-m6q7cIifkNvxhgPW/ZdniOxzASH38PSw7ShhcMYiT9TdWLGoiTN2AV15gPnU/WGV
-qcuHQk4dIvUM9bnXwbV2icyrIV7uWsepg9hGY6p9aKSTtW3MWWMiwrmBtdIoqnrE
-DbA4bkFBWA7j4njngVspMJaW61zrtwYB7jPgJu9jCauthqQFRi9HpUX48Rx/H0qF
-OGuIJE06dXea12LOcpDoj6pnhuJMFdW0krJ86Gk+cwWWcKe1ZwlBcp8fvUYrmsbe
-p8hFDcaJrk44SQzND3qLR2LjCIalkX4j1yBBIcqU5gp23KKkCNkxA2DCFKIJOF0G
-MY5nWeWWICZ+M2NHybyKed1x0+PBBEqpNNkCTeAy4AKX1mrkZvrL6hXnwo9WzZkf
-mxZhZxLycu60ZaC5cohQx9aoT+SElQsTVlZjAHqaRDz7N6c6/WbX6Ht2bMM4p5OQ
-oNLScasIlOOwDuNW3niTY5+G0UvCwWzXT+lfUeog7ytRAURvutRewq3eS9OExKSc
-MXWTDdDq3ebLCmXQANFHeBdFpNLvgbsuUNWz52p07MeriRcLdOk/dYjVszFjIccK
-XQpbdXEriqKSqRuJkOZQJshZV9McwLim58xkUAZhIt2MoqLyMpcY5j1EzG0yDOcM
-HkboNcgRmZUjqCrZ/uI4WbRICoJaGr8SkVrrPC4jff0O81Q9V3T7hE0cg6cEN6Tk
-PJLmm/qTQ78ahR1w/ELVTbEWrHSUzdPxdO5BYsTNiz76cpEP6H6eFXsqL3PVi3oC
-IHqlrzGegzFyJjI14hxTau7ej+v6UNwEtOQ23QTzGd1ZhD7agqHbOBjSXhwMPiW5
-zk6ZFJPzIhMCP+UY+6Ce3MkAZ5Eoap12wXvaZT3OcFYxkjezOL3yTI0jW7vkQTRP
-ZQqo6KCo66YBMcuPIPe31rPvXsEKjAdqr0ck9PmaTNWRD5lEX8LbiLEMNtp7Imcp
-KdhHyCSCw5DE0CyadJlSyJMN7ZbMri2uHCmIXCArD32daWrzI6x2/NFq7YD+d+6v
-vDKtAFX2WeFCkar7vjlhK4kTllnQySGnSHQHwEFYIEuvv0cILWOAHPhU/n69jt4Y
-OLEH/Rf+WykpyMqHUU1+3Y7K+wcyg7fl6ReoWj+VOdpoq2zDVOvcLOrieU/yV2pV
-/zZSSWyMWcwwVAkbuVuemn8PwIhfElA6n3Wcty6URaX66Ub+0e2pDXAr2Y0U/3dy
-Ojg0UEUKjkLjU4EEuXYcM+IkngBV4r1lwqCNtH572cvYg7SbZdjIsLQXoEl2XftM
-dMSbEzuEUqt0ud7a6etWzT/KROZxEWzssO33vXYz0GgUwdrWklky0SvgPm7MihO8
-pRqlQgAxJ70yB2AfhaBPIGNREf2XVD9TDblX1RHTDGKa/F8XhYhaTRJCXtOYoo4h
-XhPrGWlWmepypIns1Yk1qQ5tg3fydG7y4pHC9Ftn3pE8HxxV+sArR/WO3lhLwsSH
-eHVWr9g9dVjrXe1f5yhZgj+vzDb0HaxuKFN4vPUoZjawa5CjHlWoleskkVwSg0PO
-Gf8RRGZCXml31sEZettaE+jjq3l83js7isaWKi5QOzuOscPorQ7xb8dTsVe/m8KS
-hVixVTP5ASGLH4q5HWRaFYHw/tz5jfEQiejDUAIhn6fd9RQ0aDh3tOv4k3PCIqOD
-LCQJXwAzVizShVsv1PFxXzrnr/QlzDdIyYayzCs8bNaWqJbQHi7+9rX6YrrRSHqs
-FOnipqUuOG1qtugBPb/S6LY5yT36ryGrs4SGkkHOoy/ggpsZcvGajuTON6/D/9cT
-Pq1Y8iGzAWM/LZQE+cZ2OWUOpAJ2bicehTGm57sCmOpU3jtB01aQthsnqGzaJ1IA
-KTRO7Dl67zgIe70dlwSQDnRo844bTjSBh9rVQl6/LwRCVFZwKfgbuCjulRphv7e/
-Jtlnu3eNbGoeEbEinDpmgPK6+UoUanBANKVOw3yrNrRFyQwgvic0nMJcySRXuMla
-Ijwa1/ikLmjXBF9tEDdbMofTsjtZ6KnJpdbLfP1UNEkbY9+HBAglx84Ftm+n8TQU
-ozBNwr4Y4hUckcGv5iw4LUCeIXRGkeaBmINeDtPLskuFx8jo6N0u44nsUhkaK+cH
-t4uylB22aeTz17njVHjwtasS2gI7wOrGFItFYW67I620TcTF9pYmTEPa1EgPl2bu
-9cHI5t4is5nI/vUJul04w6JEyo7LlM0vqkYuFrMvxz1SqQkmpYivyHseXikJuW3V
-EGcVMICShuoDm39DgKmbkdl4pae1hdGEpX2psqZWDeDN26bH4WZ/9SErtCOILFSG
-OO/01kaPAdGema5nmLeAxJ4WUPboR5baRxvkHCsXlQnG1rKlIWnYQ9lU9jpEUrvL
-WLOGI74cuMH5DPtSULEYYE46bdO6xBBTlhPXmZMPRq3cOHeTIJhsWSCrCllk7Ec0
-d0LIYn4zaBhUt30ft9Zu0+LmMxpSkJF+3LWZ1gs7aQipXc0eQAWV7NjZusUfR4F2
-U2UEJ7nhNROURsPqFZRtCmyTI+laeeULnPCOY43LK2zxjkQU6MlkAmjKkTeWe68L
-bJewZkVulLy3EftTK9S/F5nvRdRiLjRidNdRXm48gotlUrnQaG8hLjlbNhTeToiv
-KIkt+zXOJDqFjWjYjNtA+PFbeyhSSyvG8Btp6MSHHscO7BhvXQuBJG8Ml/JXs4KV
-w72g652PhSv3K0u/4xDOO3qc9kGmQ50glfcpymZ8o4cjJc4JZqj1eoLIdmn+UuXj
-vZeRC3vquLz8ZZqmBycQdWVmGDNvfY6SrL/zec7gomtV/7B/0INsfUVHeUFSZWdj
-gh+UOP+m2QryIZIBmg5rfAtVfguQH9UBbh1LzA3lxxAh4B7BpU3BzgPGzSRpo8OT
-ICadQDNNvLiK7nMqqWkyB07O2IMkKI/PbyFaF9rjiGzzxKBlXZAaLmtrfJGLQuNM
-x/aJ2CpgZ5G2ZelTtOgamJ3SidG1NWPtjXUPD4wfE+LP42mct97BQud852FkpAe8
-x8SRBMLWb8+pn1CniFhbgh30Zvs6JBSFBgDoJ068VND9QyszhKENJ2w2D1zt4oME
-8z1rfBqInUOQojWUgGUai/xE/KPBAcV5yff+KQd8ZGmCPit2pGb8rAQkFchTKPb4
-Ruw0uZboYLg3KE19gW9AFlV0EaxrsCK6VfyYP5oMZVaEmNDGAkvIx502Dgz48nlV
-bEWtuoTgTbZZXqzQEc8Ng4dldr9kTDVl4ZC2zWaMw9bQlLKUfLV3wjQ1yybJIgn0
-hLX+TF6xqhXgSvfAtFyGtv+tuZKGcIbp0LAG33FNNH+UV2mI3hrYSmgmJHBLDY6l
-oo9mn87Nr9+owRO4M63II+lOqx/KqkAp0KsVHApNKowIMuDx1lwn8JeyrzS5Wu/c
-lVqTstOjOQdwgNJMSMxM58e0XPyDawVuOlME7GJTNMp5A6871gV78BiJ5kO/tsF5
-VX2fi/hmcUPKmQFiO3mvJ5k///FeRr6N8FFUS+UBcLEZIVdALd8dwbVbHleWMUq1
-3/f99O5nj7kMe4V9/xY5Q0tGU0/qt0vg00Tzmn4M/uK0WAAHpotx0JXH7r/LtI2M
-VmeSuTE3xOiF9siP9uu/PyKGyTHfh0Gdwc1eZmY345C7VJMQDemE6nuBp7eAC3wJ
-W2tf1gZFQq7yFHB0zkfagX0HKU70NNjIhX/fruxFUJ+j+cEDABUnL1DxexghNVT5
-NkpKAA6eCZcwEjzZ2c/AwizQLd04RkB73lfmWNIK3fniRnLQl+b/T7O/yi84trRF
-eUvgYfMRzQ/NItJlPLXqCcgKomv0tb6AkNovB5VRPmYO7Xh6SQhNudq81i/TRoig
-c/ozew9WQHtPyvfRcxZe85PmIflRpHKLD/lls5WV2i1TDqYsnvBJVqCBXf+S+ofA
-jnxsRrjIZmkd2G7RsX1FT3LdiS9FSsdTwGMljnBAWRV60it8JD9MJ3e+luj2hZe+
-QCwpRMehcHaAym+jnuDg8FSnembutk3Olsy/uboo9owaRX/6X2E1wPvS9oBjpXpd
-JmeO8QStD3RArNlAgP+kZ3XVsuMVUU3x4TcPQBP8g4zHcoDsHKquiu1fYBuZYfcj
-upnQgz0s38hA2gtp/tXPYamkvv2DUjvPy2tyCA/18Rf7Ej4xqBUFScTeR3ilOwyN
-zHr40X7CunU37SnxicMpfCIf4Gdvdann5KWuWHk2v5Yw9HqscZJsNhrC45U+1hHH
-Crqu+g6zUmk7A2EG2e6aYEA2xv6/S6v6kVBoZs1eJrq3KhOMx9kZ8RWZ1Rr/t4t/
-9vQcgM7R+Hc9e4FeRXXmBEw9AWmgvszJsGPvR3z/f7+GV3nww+7//RyuZX343Hi/
-CvdxrSdueexr94BeeOR1/OVFtgqZdg3WsbCY/gmSQ7RrJWqeGsQ5lxGapLzgZzmS
-PAW9puXAe7UVWDvms004FgZhciYQv/mGHTQ23BCduseqLWA2d9Lox/XorSzoHrKK
-ioR6ZKmTzuZ60TyTvw0uH4cPqzFUcMQxiH2j3B42dspIhxmi9HcnAm5UiaCITZm4
-NQ1fu35kSzQtUVaGHvYxftOLUP9TInAWrL8zvp58l03LS8s1UGNSalowVknw+jS9
-MseGg3fvD5D71J08SbHODLtBm5Q1T8invhjMibnCQjc1/RZzjo21aWLPXZJlWgAf
-1xRIIRvaOKPIjkgNwsl+92NaOqwf45pj1Mjt2Qt4xzJcTmhNFeLvb2MUEjVlvTKy
-ST5e3WQFczrTxCEnP60P705HkpwM5PM62xEFdVzOoC2YNOulXT/YlV86lM2a8XKC
-zUcGb/j+I0SUxn33U+LsTqqXUvkTre/J5APRwPu+P/UHmZ9IzFhJJsReS7nbcMec
-Rt/YM47tMMQFWQh2XPykolm4Cqaxo+VBtXs5ron0vVhiIf39ostOTg+FIvWLwNkx
-5b1MqlEhyWZR8IYk7IZWlENn0uckUmB4AUzD8reudDB/o+p2NGrmdTJphorEf07o
-nUb+sXRIdJzZAm97QuNE3aGUiyMfBbWlIMatC2otgR0glDTF7YlLkM4u+sWq0PXq
-9QLOVkpYuK/QWAmiJXQJJufBNqy8GQ0bXWgZAyHtywpWrWuJHpnYPuZHlI9L7tBD
-lWa2RBzmu3jttBZG4O4dQ66RJnqHf5aEXAyyXXmUcLkQlGkqgn3PTqPGNsBvq2f3
-V2W/Of80qJMcpN111m1L82ityskZ25vY5i4NR8WiccE8Kc+lTfkZJsnbm0X1DzXh
-XQMoNUsvtkOL6bVmkulWVRabg0/KPQ2HzXTsVnF0/1caAmnjNuWPbNhs25jG5VwM
-qfCmSx8K5mq6QIboRuwlyBoqNhXFGEgm8YVkbzJy2slNdLT2CcqeZMZHkmgFB9WT
-gu3YsHUOzbKfo4SQ3gG8cDDNVTQhr4Sd5LJm1R7myUe1vD25CHb+21viWixnXbJw
-0nf/SSdyo77FNt32W0lq8yEn3aEk+hhlMThPhTFsHYGb7KfD2teWJ8sjys61kFK6
-GunTFceFXXPbXTlktzxcxD4MmGmwDFQFL0PGmW09LaEOtbR1xWZ770ykTeTz7PhT
-4PrW7Zsg/f2/js9/9YS1r2bK3EwAM90pZoDeMu+UqH90V05q/68xoH/UV8lD/68C
-JfuaxkB/z8MR5F1t4P4+Z9/35Jy8jXhVOqP5fpPIpAcQFFfqJdf5mfBsD0j91D61
-FWbHweJa8TyQXpWEB6i0guiBiUiHqhJtl+2vS4AtCIRiJWoRdT053RQl197MjAUf
-i1Vp7UKfz4ZxVB5zFES8YbrkdGEdKTcFqWqiYm4JXtWCVyrqGiVJztpcqioxKamN
-yL4r4mz357FDztiwXUQRsi6Is/6s9oFLQJJaTLrKZEHIuJFIfeQDTJ06SLOn0Wqy
-7BfPpDlk8R+WDxoZS9OQBSyAEWWJtCUgqqaNs/M0uyL6kF3pCCXqNQi5qKSrNbOE
-FcqEoWcJin9ksqSDp0hxs22x8fqlKFTBjrvlofcHY1XWrgT4PtVz7rxRKSXGtfzi
-vnMmpNn0uCskL/+scUQctLiCH+TuSeXFFY4WbdbiTwfz06AV6ZrMPp+myjqams49
-a5RoK247VCWBaYvANFK7LwoE3BKKcnTajO+JId7kt8xqOdZlW8oloxbrRMck19TI
-r60rZg4X2XaVvS5h9hqmGlGr5hKevDztFGMxuqE5UUnq5OEhk1ROjlkYaiJIw/5A
-1lF0/KZWTbws/VbBBFPL1r35sxlMSlsfvjzoumD2bXjCbbxiqTjkHzFfHVNwTcZL
-glsx+lg9TmiKtcTQhNrJJxcJJH3GPtQfquIwxeSe4hG9higLMfuJ6N1gWpsCGe0S
-0O4VUmQ5SY4PwwVu9xCnxGNkmcR5YPLPoWOoztEbwqZF1Xh5I3c+UfzIdKZj0Kub
-NDxqGdrH+hD+BJi4WyaxLY/E4IJGyz5HOek7ezEG72s65iqC7ZnyVTjQptb+hFk5
-nO9ghi/jbDpCF7RLyhjE2KwGPDucVYOTSzzyX8wUMu1Obdfja9m9KjJ+oYnky5C8
-lbN7SaR4PTNhuSRbKCcsdcROvjjQokqdeCMUh08BwBcorj6IhXSdPKP2G+ALLhTa
-etIhPluqV8WjGDYVJS4L92JwYHUVrznYfZ4/CVTxbnGPDcblZ5I8Ooo5JVEASbY5
-JV9CX6cz+USiddLEIlmzmi2JlRoiT5FLUtNvm1MuyOAtiLgtT95Wk12HJCLYRcmw
-j+wqu5wxj4nAS1p9glg+RlUv/pfqPKycaGvT8QaePQPH2s8wpl7+1AorXJdGjkrF
-aRAl0R2jUz7oh/FKpeHmrOQBiDVr7gWeM9VPk9bP7ZyiDlHDF2KYXrdHbBDJ6+a2
-SXHz2f3FOhlplqJAR2lNbGwuilfKuHzaOJUfo9VzBHXtapYJeu3AlBtMtvMpR1RZ
-1DyPkJ92dgCFbd1E+6fWoNlyXWwI+b3hb17wYUd9GjQ/6oUg++VTH4AkTeSCwwkF
-trGqbmym4wmc7j5WsYOoq0sIGVz8NHtXpvafeQOd8dwczyUA4m69Vqt2O+I7qo/b
-+KkZZEEgSIdadKS6CKq2kUDtPtc0HLl89XIuwZR/Xnd7rPYWjkwmLcprbvEhHTX4
-wTVd1w9AIR1NNM9/f3tgiFPQ1B47WW+bvuCQuIqVKlha7TG7kGjpDf/iLjakyltk
-HLTe7+z5RjsBdFDkqVi+gnviDxTq2Nff0uSxXu398Y8euIalR891dmKrJQ9JjB05
-daOmE+Z3pWYsdwbbjYMNFBfyqjEsbxP09CyAWcFf3PpIyl4yycvmhw/wtkc96Jiu
-3XXntBrsoC3NPHiuCBtFal3b2rcgnayJ94AofYtckpPdPe3oMxiMfCm07X9MQkXM
-KwmaQWWvvPK1barnRoXk/v+mHculh4y0fVnYiUnNFODTOUHaSbdNl0dbr3U9PRWW
-eRzZVRsVsDROiDnGgFGokUEvuBNCseco1kQNuXp0eS8y35KYcSQnPEvDNLhZF5gY
-vIU+MeQq3nuIkHVijCW5Vr45Yf5L7W7pEWayhnbknME4NDibgse6sJ+m9RCyMKyv
-l3uti4Tq058c2125kqurXZde4f9rfUmQJNwfb6bNC335YJz5yMOiGAXoMC4uijJK
-Na80NZZG+80+ixQyzCKAHJmUP3G4wejoR+rFNK+QHX1cFPP2bfw0ScIsf0guJMd4
-if/k/bEfl7AxauuTJtzbvp7gxJcnv/jg892WBdscVl1n+ZAcGyKmII9oQij3EQZF
-RjswXvCZ5RSi5IYmUrnX1zPu8FiUDdRGmHgvSApcPJHAy4Nc6/GjDa/2NzQeIaw4
-7Fxh+KXZlGcLN3Q0qHJcxoqaansKqOPwhZQavJ2ZEFAKSrHx2ci7UW/fJVuuzblq
-L/uB/Vr2d2xUmFwiE72YzuQOE7f2ZUfiyMP+mhuXaIFuVcd9O9iHFAeaQHmdyWJD
-MYyLEkPTeWOMq6UgPSLerFGqyS97BTtPnuUBjuCgDD+pvLGTZsc2pkrzuMubiKss
-MsR7n6Q9OS90vapVtNEm+k93erjf4XGMuRfeThX4rgEusgyBruBR+Q8ybIw890u2
-M4XJPO22uXzzJq0ax+evr/B6IR6964Gd5Z5fgs3T7Fg+7lvNxKDWlKhUoqeRsNhU
-1WoW6FE+E9baya3ySTWDNl05dntM01EuXNNaUXaX9LNldDE6KAJocWLLoXF+mpGY
-a4IXtbeTD67h7ffAqMPjZs3tZetJ9+AKxMolUFyp/nVQumXRxGf3pDhKkkvDrt0L
-tlmaYi6Lz7t7WExU2YlvEArA/veqnjaXhPYSlq/YYkwOgPaWSXD4EBjYRxZbE8eO
-EdUFMATUetGdaplOw5lS8GTAiOrV1cyMlCH0Ml2ID7GkuLjYJxfGmZYZsWFwMzIb
-r1bxWmai7lQLoNNPexughM0DYzsJaKf1B1cYdutM1ifDONW8vbCN9VHjlUJtwE/e
-6/pG+os9wm+8k6rFgx7ufZpr1mDMr5dylc8r387jz93FkvugyZjR5hB5yxdDLqyi
-xQ6eD+6hVkoWV+OX3aJWHgy64uV2Wpn524K2OL7XYuYbl1P9xhOHzqQyc8jVlf7x
-wQm7Z5VPYQvp1v8KHH9kj4Ex4NsELcZjrAt7nJ3pTV/9TFfHqMAtanoPTSDuSFie
-cThPhc0FGKkuwh5XHGDTbVcrFBjzMXtTpU+3MflPRd5itlNbnWYxRYr4ibxsYro8
-cODVLMS9MdEPERiml6DCP7o28A0sG72SQrf3l5PetRZ/8LJqvgiGP924Vn54EY3a
-WfWFJn+3OXGzQBjlEg==
+GxixRFE6Wg3A0wA9Nn6kQqY6jMXxlFlurE7eU/IxxrHpZZEcxeEITY7yhD7Tt4zS
+gh0tQI4c3z91kDgyIPSSb6X2UWztdM/dOU89UWbrm2+GaLH72bovCgpied7RyOlk
+VNZWdSvKcLqIHc//ewPWr3G06J2g/l0wyXa4pj5GpR2CHF+geMH7ZKTKKIRYlVuI
+bKbq6y3fMWso6WKGMrec9k0DoC0XhGhCVOf/VV0bRKWML/zr7814CHpJdtdbOmC1
+AXoVHek77Y6ccQrHRmxyjswlQNRtqa4IGo7/xzjHVM3i3/uqve3PTqlyt6VD0efU
+rWs3zeqEe0Z4D8AwgBiDAHe+SPAnSjtDcTPXuufcex4BEKTBOEm7I9LSTzF1di49
+LuqQYtG46Wjn0uX3Im79G3AGxxduTVNZJY3tCeSOmMmlb63HMPPvet+zBhAREdCk
+2wv/jh3R+v7MUH7Qr6jPri58FXd9l7bv9ziFw5hdXf/1+/dv0yuL0TbvX6ry5X68
+y+jyffFxHYb/u/oZPP89oLhjbTvb9N1m3b0XTh9F/A5Ajz36s9tf+79Ndf2SjFXS
+81/OobQ4zIwE4C/J/LyIN3N5J2e/oU6u4If68CO+TS4tHLmHUqgn84+y8Ym/6HkG
+OZz6NWHzlqvlQ/dxyEb5SphDeCwdQrJu+rq36+2RubafyDAfUNRg4DHbXeMHj2Sz
+m5uvjMr/08nq569GDawFtdmH4FOX2VAlancOsvqHPynMajfD9mfRN121FpArLjvm
+b/ay1yXurS6r4BY/9evB6/3lkI2VH23xw3x3WcnPPP/7Hj9PrqPP9/h7fnrmw3rX
+c+x8FTdoMDrzZZoK+Adtf89GT36YfjyxhNRDR0kcLcDBFuXGI6agxJ0443ujQ9jV
+/2M/9BeXDFnHiVMf6mejtnCkJyvaPjymN0RWvujED+lP4teBHnoyALMj1cjhxSY+
+3gopFEPa36O3bxhJ17SCmAjIhKC9quh910qy1Ka2B65PDnoDHM8xphdKRPSLT3BG
+nErcmjo1Dv1IggDFEAYs2AwpRP9hTtHFEDhGKyOxpjZWWFBwjNU5L70u+mPkCqVR
+20/GsDn93B+sZNEdvcu4UxNV2I2aRcMG73m57Nnx/ggnadzntJ9Six0ozDwISp5/
+nQUwT13+Ig6DHEhsw1NHdAnEJIWDCQIdreU5LRmL3oj9ya3s987ubNUDNMHAmMML
+zX4AU5YxtjD4EIJxCtFWyIZXF9iNZCqTbu0gn74nxFriojiBQfu180McHcX2xyAK
+tOuJbQOhGoa2oQuLo4Fch59NpydmPAvjeJdHZnzs+aFGZ3VN5totnN/Q9v68EUQ5
+BRWTfig372LRHXyokY0fFvKOOIch129HBKkCdoAWAhQy2s6uV1VzmCwLaH+Lwv/o
+jHEuqB6fkppYc1WbcW3/wd0qQiQvCuzUo/OggZkXDoYAcftYbAK/50gwSpmp+jG5
+6sBSAGA1glUcxjmyAtTVitTwc2SEpdGpDiWypCO1PWttjF75wdTWBAZXzZWCg2X+
+LxND+0k/LeYYVW2lJ/4/YUkB15SE6TJTtg03348VTIg/4ueEKOH51ScTzk5Wupqd
+z9jsppaP0ZCX/j76RFsAGLFpdsrQkTR2o3uaJVGbXsCPmvpJW00vjf6XVB2F1WVz
+JDGrIpVo+4Em9a8IkcmknMxdvQQlCrxq8yGBYsIYtNO7GDHa2b4cnsKvRdIW3oZ5
+1EXt5o+6GvZQ4+twpiZobkSZIcxJ7Kv/nzEYArncMlKwQTrkagaJ7NKV6gzqCJFZ
+1/yabp55gOzKBcX2yH+iRAFi3UEdt0IziveaMNRvlbDkUi/57CllsNJ5ctTBxmY7
+xfWAebDB0an3l89T11djIVsAj1JDBq6LAOt+56w6gKPlqtlkGTyJbFtY7JJy3rGx
+Chh5ijDex/H4xXiMLYPDw1YKoyMx9b0Id6oRzKnm1v1TOLHa0IuBxbhsy67O3tbx
+wQsG5lZED/WEn/jNxoQ08Fwl78VGK8RqgatlTIsSOmuczcw+gKSkV8mdYDGbJhZv
+UXe2t8C/6uBS966k4T45ZW+HLBbd8V0Exy1hmIYaGkcN+xtApMRLmTpkUKZ9U8Ml
+aQ2T5lHE6hNEfqGEbNZ8jGWGNgeCNE7ZhLPLL2l2jswYF7uKoz/6japOCxOcqT5/
+m5MgrlOPquXLtyutTaBlKZdCsxeH6PzvyJWPgM23ty6/bG41LZzT1hPM0BZiAYX6
+gaZ//z47C2T1iw6zSt0PnHKRUqwCPSUm8TCjq5Suu/LgJhG/BUiOQ7wiyLZSnddk
+8ZCOsWBOlw53qjvFyyIM2HyH6mofwyYM+FHaAQjYjx8NyGg4PaWgeJV4GIWjnFhH
+77OO/5M2vSfn7mTPs9SqSUiuar8YvA4TS343pH2w0GWNQqO5gBklRwpGYw6CFfAk
+xnMLA9xp/qJNgRoIuBD2yP8iEhw+Lsvy9dIc3IC7ZTOAT3ehfSzPqtjo1rlHr8lF
+o+HSxBCUYGvy/knZkmIWJ83cGurtZv0DBEiBOW+WrOFU2KOhqXauZ4Vz6nXvJ0BN
+7Bnq3om3WlZkvIa2jx/oH3OTRIM4uMxFIY4APzlKh68o9Z+5m7X41jeh6LjEvsLu
+VpHgKju7ZXWhJWnplEIeF70H12r0AblGcBxOeHHBP8UutePqKrNW2jVo4/nJhRe9
+cIR/Xw2s5d9eKHm2C2NDT88FLFloX4v2ewEbXvMNLGO9ho3R3+LfkIqJfNFbs3Cg
+4/mcv1RDYutpNGCLXnQmhtwIW4Rd5K5Kc7QqGiImXTxq2O4psDgFMnY6qSpKSOHf
+YlhBdHoXiRalfIFvMd6PKgLcryXLnvQPHdMktRo/bRScSFPbb/vcwvHggYeiYUjB
+F6fDHdbqZxVMZh0JSNZqdsem+GrEMLn2J/wiGDXTEAWonyhKktM2qIWOuDTbUlqi
+S64PjLLkcAirKg2PVW4fHWkTdTC3wbEYSyZWZyXAHGtS9BB7iW3dJYVzJEuoPcCI
+dJy3nQtoDiuLod1x+vYCVSrW7nny2/ajiYAMRsebwR/UmQMdoRNC4zHqvdQY7+p9
+WL8s3LsW6m1tUC9MGbs98sDM73IZ7OUg7iG71HkL7PiG7RFKaRdeDCeTnvTQzemE
+sc9jxwmUad7H4SQnIG8b4sV93hSW0AiAIrHxYLI/DsnCwTj6BGLZO/Qm1/e/ffda
+NrX/UZOGfN5cELHga3Fv94AOYyQTWF73979aN0yEqfwrIN3WlS3NBJeEL5yWRAgJ
+BdF2y41QjK9yDSDtez8AY/9QzqbCaomYxuG12N0jizGG97Bm4n4m2MyL/IgA7THp
+NJ+ifyZU3YUR/ITfjo+f4NT/8hUi70KKIPdRP5wHyDA4schf1N1YkdVkGbuAHj3F
+/W7xW1RSlw+F1qhQ2SbRnxv2rrVbZFkN9/KidpwplR1sZA6MDZ2cPGQGwUkJ524c
+58Bi5opugPedWJTOchgX6wMpXC9BJHgNHf/mYGC8Rm72PvbIdUWRvahr19IWliWd
+eR85kpgYeqS3O3U3zScRdz+I+pf3WZI0qaKajrws732ZzXwATRhR+mWB8dxno1di
+xVH5DRQubjBOcXXCSUpo/qav9FzcNDZDbqRvaaWTkN9lenNFOl4ppwQ+AAiQhHmM
+VuDsOYnH8eyrXCghOpyJHT3zEeZTR80vDzO6ogwt26EJVga8wEt7LXVzz/9yLKju
+fjtqRn5sXYSdSSjL85YNQ8yj9R3qeKTXZylRvrBi5awM0KdJwbPfmer0rV2jP3lk
+G8aYTsKDSnvCOyYgH8dNGK/iG292PMMw8R7uINr1J/TSNHWA94dgAeLiwNRewlu9
+S9MsR1DOCN1kA7R6t2GsUAYNEH2Kd0sglX4PVh+DzEM7VhNjv1VMrvvQ4//QIY6e
+jRkLrROxSONWXdyKFCoJ3QhCiqHMgosehTnAcE3HzGRQBtuoBI7CanmJJYa4h7i5
+TYPhub1HEHoN/YhsyhHUiGw/O84LllRByLLjK1ZS2zqPJ8hff8K8W8dVSz2igWPI
+/HRDSsaRYrupmhL60yjsDWduJXsWa8Gio2z+Wodz94iBm/dh6StFPqC38LrU03mL
+VW/pJQB1xWW1nMPc6kxhasZJa2ru3m/p+HDZBLHkNt2C7Iz+XJlRmoKh2jjMC340
+MvS01BqdMjUml0UCAn/CceyDemoXC5ylorh1moz3tMt6nOAUYaTF2TS98jc1QnZi
+KTdRNpXpoKILqa6bBsTsP4LSv6qO/ZkNU2DGU3t9IElPbBFTR95rCl0Z30eMY7DR
+2ZGSpbxg7ydT8A7DxFAUTbpMYfJcw2lYZtemK0cYkQvk6JmvE4U2389aJ56v8T36
+OfM+k6cjEJUNYy6wNPrYmDtsJZqwPEanh5zi0R1AC8ICWbgkPxZoGQNkwGf++/M6
+7huCEY76Gh01kinIKYdVTf7cjpH3RzQGX536PxhOmcxxjLayUlfmKi6b95xLqzWb
+3aCSzBiTzTBUCcelNzk1/z4CVX5JQOly1mXcuhATlepnseZf3V50PN+R70bY/9s5
+HRwasEhh3VN9V0iFPH8BZ8K2AaCRe2+CGbRREnM/e1l6UHezDxsNls4cNMnF9D6J
+VrY5mUPotUrz9VVdfQj8/Q2JzLMieO7G1T/3222+SXN1rF1ZM9dC9FfkPm7MwgmL
+vxpcIYBYkv46B2CfRMgnkLGouP5Lqp+1hnyMqhBTBDGt/l+cRCCtJg0hq2nMU8cQ
+r4XzjGJTmeqy0SD7jtgKqkPb4AOTet7kRSOF3ltn2VE9742lDxYZGb2bN9bmmLFE
+tjqnV9x/6tDWQ3Q4PjepEvyhstjQd3C6oYTw+Eiq2NjArkEu9aSVWnlMEvklcXMo
+Ocs/gsgk5NvsLUe4Q+9oK4A+s9YC1jfOrpLZVsUF1s7qMXZYvdU+/zaeEfv1eyks
+TXgrzmqmZU1oAhNh7ojMSVKPFlmZ3wRfkIR8GCYQynnPPQ5BQ4Zz17sufy4liowv
+ZAlJOANMnVgUM7deouOxvnTJv82HEG8QzgZe5jEPm7Xlqm01O7EVtK/jt9NTR9Lv
+SqkublrRC4ZNzRY9YOMrzXTUltyj/xUkdZVoaFniPvoCLqyaQyvWppG87t3ryP/r
+2T6dWPJe6wNlfz0kC/WN7XJeHUIF5tx1PApDZtKvRaCqM3nvjJaNI+1+lEBF2Ecl
+gBRWJtYP3jsOQa8+yGMRcAcS2bwzypqgYEOtjVC2v+cOIeIVvBT0BFYU90rNmI64
+U4vvs9O5Rja1RESmCFPHBlBeNz8Spob1lObFuc6gVRtq2skSAJ5mJda4ucMoN7jJ
+WiI5mpH4Q13Q2gmu2lButmcOw7LXaejpaaXW13j9fDRVG0vfe0Xgy46nFnzfTuNp
+YKMYTWK+GO4VHJHBfWsalhYIzxgSUaQ4EFvQk9C8LJsrPEbGdettHs9m98rwWDl7
+c5uVJXbY5kygf5k7TocDXU/XhLZALkg9OGGxNMyrH8konYE4PX3UhGl4m5q5YKdZ
+enXh32CUIW4hmX8VQbeZM670SNm4TN/8lhpymQD8Lbgullsgagr9irbnwUok5LZV
+w51VMEtKGmouWO8bAky9GYXVO6462VhNWNpXxGtq1QDefLv0OLzZ5wqKQgc4kSXK
+gPN13rbRY0C0I9P1uuRtIrenBZQXKKSJ5zdbYoyVWWWBSWfZthNnEuIQKTpYjhMr
+csSXBTdMpg8zIyMsBphzl03OLjHclMOLO8jEyaKVe3s/BN40y4JajYQljP1Y584a
+TczfCA1L6rE/h528tbcbFzdfUJCCdA7ors2UXrhOQoitOXcXF1QSy2fpFn8cBdxN
+Iys40Q1feIKw0zB7BSebA1vtgXStbMgDF7x1rDq/gsd6RKJAxSyzCKccKZd503VB
+TgtzVuRGya6a2G8xQ/1PIdNFlGIuNBronuK83XgGp2lSudDIVRIXnS0bCntH4stK
+Gpb1MiUFjDK2J/McFX7myQqXIkuRGn4zTp1401vIhQN8p0YIJCmXeJzXZgUrm3tB
+15PKiDu7ZvmHDuG8owJtH2Te1AcyUqbNyVQ3flgxcTaI62WvCSLLpflzlbf3fjVu
+ha51fvnzNN0enAHqSsw4HrxVGyWLiOePOYPGsWPdw/NMD7L0FW3lBUmFnV0T/KBE
+lzmtVpBKlAzQtFnzS6jyS4C8qLh+6Fj0aCg/BlUB13mXTgfpQpgxi6SNDs+EmDSC
+Jk5sKybuc0aK02QFOTVjD5SgvHF+myBdaM8jssUTA5d1wdZwoq35RW4VmlHq6U/E
+NgLlLNKyLBVFi5qOWceeCF1bK9beWNc54Pg2afx5Po3r1qs8TM0TTyMVH/C6iSMF
+DFm/9UsvEaeRWNuMHPRlZV8UmQYA+FMhLwX0lqGHsozFDSfDbJ642tkHCPM9a34a
+Grt8QoriUIIh03TkG9Y/mhxQnI9d9C85cJG5Cfys2JGa8TMTkBQgL6HY8xuR0+RS
+oo3h3gScKoFvQBZWdCGsR2AV6VbGY+5oMphZEWKCGwskIZ921TwV4Msfq+Je1K6L
+CN4ii+Vphg69NgwKo1mblLHUlFOHlGw21zjcG1ooScnv8E41LVplE7SIAj2rzT+T
+l62RQbsyFUpN2uD2f1WulOGRYTo0mBS8ZTPt5+IaB/3cxDPSTEhgJe9N/ql4e4he
+aTTrKbhegJMjUUuRD5RVbpnnmxfvQbF1katZbnDKAAHKwkHdl8qRVo8q51+yUkPB
+QcnsiEwQ6IZ+JB4aPNQ1OIMp3homNhnw2GHaWb5n02TodVpfzL7UIt8bzazcpbSg
+o+cE+TFNsj/RguOloghY8akwxrNbtavrQh+yJtT50M9RuBfGiuaPiFvu9zXw3l6X
+LuqtGiiNU/goctfyPL44ZalHoHSBO4IX3fK4K4+ROP8z319+4pvHXqrObeOflYq6
+ZDT1yEW8DX6ZbV6rqEGLX0zdwPn1Vhz06PHM7zJFPJXNLFnic0NUhz8kn7G1hZ0Y
+McwN+TiAdAHlh5mJvUyk2BzlqwjSmZZ7gxc75gvDKxY6f6INREIKDCPgqDKRmBwr
+BymqDWOwyrBqdVfW7Rhf1DLnLginlheoaKNUlRqKfIWWFoCDuahtLITnOl4mbK4M
+XUhjGwHtOR3NceAKo5KtDXRQw+kXzhb/5F9QN2pTJLchw+ZXND80q5EuKyvEnoDM
+53pCX9thndSuakNVOpYO7/jxtghapAD1Ra6qTRCBdfZP7d1bhfQ+Kd9HT1k4Eqt4
+SMGPYiu3dZ9eHgYNeU416kMjzy05FGjKrn9b8yywleZTVRdZuq4Lt5vattLqSQpV
+8QCx4ngKeKxkCweUY6Vne4WP5Ifp5q4jvO7ceLE9aIInqnOFiwdU9j7qCQ5qaGOs
+l+betjXmksZvri6K9dWq6O+9NBYDnLa3A6xkA1SePGuMJ2i1YT7EWsxn+NffNi5a
+1h8jI3xf/eZ5dGL8INPKHCDX+2oPxfYf4Nxn8Ekl3k3owT4rj5Lz9kIavut0LA3x
+ujootTf2grg5hIcqfGUNz2dKetQDXimoEF6ZalyVdBI/WiWsV+3mPS3ecFaIP8gH
++Ilb3dpb8tYe2PkWPsIz1EWt6+R97dJkY7zS2zriOAJnjW1j2SrDXxMeINtdE0zI
+ptn/vzTHItG2ltbZx3r9FeUE46n2Rnw7u9o2/lvjnn16zqNXGPz3cfECff60nRMw
+9QTMSPvNTkYczyri+8K79u/TwLAv2v25VMvj4e3ivRapeTxef+a5r7cHtOGRLvhv
+KRa5yBPPsLbt1vnUbR63XUfRpnBCdIwZoYlYDdqvFQEtsGdNIjrFrcBKv6ApibI5
+0MQzgfilUOygIn+iWQMb0u8Fi9JzQZ21Nx9lev14NiqmTbLAUTKZpwXzQmpDOH8c
+qrimYeGIfhP7Rqk9IHbzSIcZo1TbJ3R65OygiA3Svs3lVXt4ZCGa5yjzdI/7FL+p
+DKl6TATOpu074/1LnrMpQGppOGouvFmbhiIJyqt6D+XccPCuxAJSn7izQixm/WFt
+bnMyAw1ZOojk/jgL9tJN2qBNXCpkpiBRQKaTTG6JoqHnVGVDUW0k2VFTg3CyT/6a
+lg7rr3GtIdzw9iIMnDAPJwHmr4LVFl6ITOJdObZXbTYf39FkPnm60Pono59m6/fi
+kW5OBhrLU9cQBFEnz6CzYS488174mfJtGl/E0wO2qkLtJPYZlbB26exAubf/w0bp
+MtobWcJcCN6bH5v8ch7iKAyIvDQb6yexS7iIDVL2sqwLbPau7g1f+e6ucdy4zwIX
+H6r+fsiPtL4n0YcuAe8b/1JNZf4i4GGzJIKFCrW5BX/MdsW1NDvaWm1ckHV3LNlP
+fMSFi6CHXZnkVToQuSSS92KJhYRMis5koTcU3P5fj5wdMO/npBKVDtfMCnYCxN7R
+ioIwQkzH1BAYqirTsLx7lx2suuZ2pol9tJspWWns2leEzqmJQtP+hBGfxUQ7CW91
+XX/NyY78KhiLs2LccUadJEhLRwl8GJ64pNLZWb9lZXpsMkrA2Zwzm3cFqjl12kyX
+YI4rWdCaHUDh6mIa5JCOe6Igen2SmJGJgG5+RPl1yR16rFIWnBqHYWevjWuhDdyb
+xiHXmLgRxn+NQm4NsvXN1FEvCHN8DdXkLB9HVdugfls9u78oq57XpUGdcxqfKVhh
+q0NQ9mHnprgUctVYEtq0hS2IZxy8Ujrv13BydLaI4h+LK6MGqIiVRATcttJLzWym
+24cSKx58me9pOCxLZu8VN/d/ZUcglvGWfM6GzQK46bqcW0NGOPm694G6mi9gWHzT
+qqwsQkBL4N4CNGnUT0JRjVOYczaxt89KQk9LPiZ6ZdCiPscIOI5bpdDM+xVKMPiK
+laN4VNGGjSIx765ZtAe8/FksP4JcDDv/+Qfj2hoXnbPwEt+9LxrJi/qsFQ/Yz3WS
+7eCULPVl0ccIT/2QMmPcKgJ3s58Oa1/biUMe4civeUzpauAXFxxnds24mLNvIz9c
+jX0ghxApBFoV/BozLiyQbJHsaE87VdwURDiRtBv5PGUTKXCVdfsmSH//r+PzX/PD
+2lcTM/cggAfdqcEAPefdGal/764a1eqvDOjf66ukofqrQMm+pmugv+fhCPKuNnB/
+n7PxPTmjdxGvEWNzvnIvtrg4O4TdlBzazIRnq+mqkkXa5uqeIRPjRT1G2nQOT1DJ
+au/uQqRjWaTtvP2rInAEwVCsQkVD91NTl1bS3m1mLPiYppXamT6cpTcpP1YkWGPD
+dM7FmXVYBgapYqJsbjOq/4LqNOoaBSVnaW6XRSZFtZGVjRFxsftzrTVWa18QUoQY
+r3GWn7V/oNlVZnpKFpkYYdU7iZRHPsDUS1fShL5iJmhW3mi2OaSWIti4agz7SSSm
+DWCEFSYtroqMhzPsY5q16J+2O7X1qF6DKhfl9GhJLNWKSM6mrwmIvzGxO+LJUnRw
+T2tYeBwFG+yWJx46vS2WFe0uIB93G+eul6lGSXFN3r50zlRpLu1dIdnkelxHRIuQ
+K/gezzVDkyscGUpX5M/8wU8TzCa2iH0+LYR2PR3P9RVKtBU3r3CJYNoGahrJhhRF
+BVxQIh2dHGA+G9KNfstDLYfxwO1cEmoNnWibpOGX/NK6PXM4y66Z7HURs0+wtBO1
+K3YLSBXZdTGOYGpDoFaSOnl4yGwq54ZYL5v1kp/YTTuKrttc9I5dQbgiVDAVbd2Z
+PnOAybhV8O6prptmBZxn3scr7Bni+BHT1Q3VqMk4DL8jow8Th9KmWHawZsWaaDlL
+APUl51B/GGWHSSZ3ZI/mWpEk6OEnVu9mbmtTIJVnjnavkGqWk83xAQzgdg9xV2WM
+LLNxHliUyMQxjM7Rc6Sh33Dd2XlCHym+MV3pGLQJnmZGLVN7ze3iR8Csu2U1tslA
+DB5obGpdpaTy4cU0TiG6HlUEAclIoeZAq7X2I2alcDjCDO/GxdTWL9UuSWMQicga
+1rODrAqcfOmZ/1aWkGmdf2tPrBXjWZbxwV2ywk1SqU86ULHF66uJXJq4Lc+1ND92
+8obxbm88VnY+5BAkAOgVaHSmIaTrxTNrfylcwYBC7l7tc2yqHisI1frGxZg9+j+x
+013E3PKZfZ4fBS54L7nHBuNShkpqR4PML7cAgra5JF9CXxcn8otAjPmx2Hw01Gxz
+yHUqdabb0vC3ZX43zeAtiOgKK7uyzWVokwjWCFnxM7mR1NW0w0LgY6M+gK2co0YH
+3ExlHsx72ryS7DS3Z+JY+53G1NdfKcZh9Otqbbqi2YpR9MbopA/6YXyb0tDFV9IE
+xFo19wLPWeqnReuv65rCDlHDd6xhyrhUkDAl1bBXJ6UtV/e3ajTSQ4pCavNrYdOh
+Lzbn8rPDpsnaqkw8SdW1i1kW6JWWXd5hcjWkOaLKas3zGvIDGEDBlSLls3gMmtUr
+iIQon5r57AUfvFhUoPlRJwTxUTE6QyVpIT0xTijDV1LhLITlCZzuPo5lR6MefXFI
+Mc7e6lyYsZLXlzIVz1M7YgIgesh2XLRLib+2+ngfvzSDbIoVpEutdmR0ETRyiIL2
++1z9BTjKjw6hFPRNDHXd3JE/7zjIJMvRplsd6ahBWbNpX2EAMulmgnn5t+fNhcag
+9eS5k/V8CdhEF3HECpZmas3NJLYP47dAZEMa+VQZB72KRnu90UaANlLqtOU96NB+
+d74uXf27NnmpXBJ4+mcPXEOOp+86e2OrKA+pGtu40vWnEHPr+zNs8oGLf+C0dGFH
+amKpRKEfLyNQHQeP1j4mZS+a5BPzxQd4W+0jdEwv2n3XlAmdo+XAvTxBCVTau662
+byVqAhTdEAq/LS7oZHdPq12WgpWuqNpWH5O0IuaVCM3Aslde6bo61fMUu3n13+Ql
+YHrKSC4Dg/czNZeBX6EJ1E66XZona3/rfqrTLPM8clFsCmCbjIjuKNgkl06gF9wZ
+X+op0pyoIVVPLt8E5tskZhzJBc9SaxrcrC9YGHwlPdLnSt57OwHrVTEOdK10M2IV
+fQVnGW1dROkamLBi8PgVjCSxC/40KKSHuj9sn/PaZVbEFB/lsnHgzPOYxSX+/2Rj
+f5aEyb36vPPHw6SGi5h1ma3Db/ngzJQAho3oF/Y2hucpdgt27iyiO5GIDS+Z83wq
+KNMRitcimzcTLNkRclVNX20pZoOGdzHwMAtlEkEhcDGUyZvWgsaah5od/f6xb+dc
+MNTS1uoxd1EwMsVxFUZhLVtwnV70gkU5cKsZnsoC+Pmr0Rw8MYDzIq6cTrSUxMYz
+QK0DI5dqyp6Tk4tYqf1Ht50HMEDYtQH9vgiLeGVkOdcL4X7QdvcJeVNk0YCue598
+NXi5gmkpdjSe1KNX7dZSKAqX8owM8zUwnHjBgdijYb7mDuzJgflg/RJapPxX6oWs
+UUYjW8wvzfonETZ/b1gAgsMr5G8tAvDzPedUMWCmjvsVHuSDkV/XEZXdwrMnAN0n
+Jmqnr7wnu+88Ik/WJ5n15QNpv4ujZPIRE4PmNMVguoBMXZci5ryqAHP0wXaiWw9m
+yKl7VW+jWwzW7WHxFy3AOGL4tiF36Fh7NguY2YFpJYzckrQYbdLBdb+9MTqdcIDb
+iu2NhrF+dMxGTXnOFnl4d9v/1/p0E8T98WYKcvO7Cwb5Bk8VOwK0/8vLrNRYsxXY
+6WmRo9VjlkKCeeUjZynOjxwey4D6lHIxZYZFteEjmbffxy8TFJbzB7qAjnE08ayZ
+vYqv4IOt8UZ8hh1XT3AikMnHOf+g26KgfijWhbkJR67GylpYhmnBg9xDRrKMnL3f
+dExpk7mYG5osRn5PjdGZfOGxjHzuY7fzInCaf4JN973I2zevrl2pmwnMJ5HafkTx
+UaCV10bVuhqStCrD2n3kOg6O2j6dckPbmxIGpfCo8LFcaR7t7U8bipgEO20BK1Cd
+Ji6HT/RkroTai16mnNm90lUcyJH1qxNPLdFCuzW67tfAOcQv2UJk9ng22FQMMyxk
+iItpmnW15MIabGkGF3P5CLtwZsDMLaVmFiluTna6qpXwmHAmmNPsr+jIIkHsZjG5
+/7/Zg8VZ5dMf/SkBH+9PeBxj7hd7bggKGYF4VvpAt3kRfkAGH4wz38VzQkTzntvl
+tdkfxObl5y9ppfWmevTjD3YnE9+lNvfIZdr5N5tJr7Y5sZJgPWSURSrqiPmFR8Ti
+NHqkL3YJne7VwbWQlqPcvOE6ouwu6XNhdNF3cOWjTVlb9qf5ZkdiWWot+PP5vWtY
+4tidKjweF4btUgqxXN5GrFoC6UL12yhcLIu2avjGBNca5XXHrt0ztrU22by8jpjz
+kUfW3UACBu7gauuo2o89FBO17DgUgycCmNbNBoc3gRGXUkMwezxrxOgCGAJqv4tT
+jKZBH24pmAeiIeIEa209LJz7lnmfMRaMi1uXHDHO9FIZa7tpRSbj20obmzEmyrUA
+2qv3QcsJfsrjfhKqXSw2cUSxyRUSuh3HWLMnkxfrXOOgBC/gGx9W74VCnK01XbzX
+qBT3PNvbG4qHBtN+HTVi/lj5Nk68MTQgHzSZ79sjRKavjXkMFa3h4IMwDrUw2VqL
+XxzTHnlKa9amMNuZ6VugrRHfd2Fma0zi8tX8Uk5UeXDIxZX+9MHh6Rvf9LCQbtUX
+4Mwb7sxqwK8WtBqP4bPBLU6lpavos3c4RgEuiKnZP1FxG950+niuFTaNo1NZBP+z
+8sHa+65ND9NsKDdCTR9uo+Y/FXmbuJ/aXMRbKTDiH+QlE/HywIED51G9Mbcfsk6a
+Ng+Lv0qGYLdDnDCUTLd9P0t7lMhnNnnsi2D8K6cS88PLirCdVFdotkWRE3e0Dp41
+52rcq5bl1sPMJv7JtcT7iOw5HANiPd6K6+KJRaZaKyBS+TmkTeJoWy6DztadWNNm
+kb+Xj2ZqQes84cdVs2ZTCVZoNzBM/zeuOk7RYo2w1+3oRoUGPBUh1EokXJ61U53M
+jxGZpRwik00iyXZYUx6gp8HzurpHDMlh/nvFyoW+Yek7dpVXpe6r+/OUghBsa/MB
+9th9c7AjX4aNFs5pxsvHk8cKu/1RyPEd+RhxusKLg7gptJqOiyo2mRwsR++nDCzd
+TotFbGVZCGcp7AWUjpK74CTbJZOFzUWNsHupYYAZ0Jo1JRzFKJzTm2o1ROAlsECZ
+xZSLFebG3vaPv4R3tABQ5rWYeVUHfEUA6qUGxgj0d8eeb/MC/uuNCX9nuXDBSCij
+WdoYUyK7wjFcxekysNSSZEmMR7idRhYQ0i65JgPvahZ80mEFMc1/HyhJhkHTFQE=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /locales/ja.po
@@ -37794,6 +37828,31 @@ ixaAe3t0ICJNYWlsIE1hZ2ljIExpbmsgSW50cm8ifX0KCnt7Lk1hZ2ljTGlua319
 CgM=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/monthly_report.mjml
+Size: 1674
+
+G4kGACwKbNfEGznkuV1pfnZnSgetE3j2+oBDDjWgTL5r0I65VMe68GzpIRldxMdy
+TkShFUR4+vjw1zQ9gmP49S4Xlfo3Gl2FrULIRzicCniOPFOMbqT9MUX87ABtnHr6
+tQkZWrccUasNitRVfR5AfzxruPjKEZcs/3c4cXOmEL0VqArJ4syKQUQvEudubpKO
+nOS93mh6sbzvyt2l1VeO9AQc1YP5kITC8eQd8K+YdEVxcwNBPUUMCu46ygUVO2lX
+A88jdgWblFYFg5HkiGO0DyvlmdfYBFjxFNI9K1dsyI1dkDIAf7et5tLODXFTAe/4
+FjJgtVW/kbKUBLiulB9NjJsum13zo8yGsuG3aWTXA0f/ZBA6orLGOSw6gwsSwYTC
+gulwuRx4vWH6nI/6p/8SeS+DbgEdZT3izSNqlxCUyvCXYwj1lTc+pI0kwR+uilC/
+Yn1thWN1cd+iXIBHGnszFW2DGGcbqp1uTY8+bT70cGCDSSNVnmWUdU6e2HZ9HDyg
+k6RBaCzxDk5wR6PBNkCPVOPEITU=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/monthly_report.text
+Size: 887
+
+G3YDYJwFdrtR1gmxp1LKRgt3rfdamhSq21I9o78kQxKkH5wgHgUVizxAuk9hT+fg
+TdAQI8lg53XxHqD4XbugYUJpmNdO+imX2XUubt3P3o2rk/ywBih/Eip51uC9Oz6Q
+V+hNdCOph0EOITTYqDY7Mr+h/HPWCKVgXnI4JbjR02R2goNxXxBuCqjIgibAPco3
+QMIyg0SnfEs/pIZcUwKiR2u8XFv0OFRsSzqW60PmW6OBIGi/eY8QkkY/ncIS+AHt
+iHCOVNHciIAfoZWTo3GZZ/u4yfVJjF44xjDeiU0ntsvsdaA/EUkXv8Gt7QBWmql5
+Yf/qhQA=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/move_confirm.mjml
 Size: 590
 
@@ -37887,6 +37946,25 @@ G2cAoCyOdxI085D0GnmYNHgt9xT6UiTJKfvk3sqFrkHK3O44xgH7tcg1yEKLDuZ7
 jJ2PkilC9COzX4Mf63HhcBbRuUFhALAnAg==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/notifications_diskforecast.mjml
+Size: 887
+
+G3YDAOTZWv4vidE59lk0JRFn+P9/E1j/uq9tUfpPIJ0oGmMBJ2IJpiLeAaabD/oi
+5hgPhyVQ1famnqa2oIJyK1UUTOfSc4Tu25Y5XkgueXHEsFj+73XitCkKZjeXeCjK
+x5kVA5PuEfIsmrZDJ+s3pGYe5Phx5S5y8H9HXiIn4KFP9nGWPG30whWSJIEeh4MB
+rblUVl1+5oUXAgRzzJU84EwcfZWzsphIV4O5dpTgtAdV1y8OaHSzTVFpcVtEI1QX
+3B1lKwKkBwVaCg1EHy2AwEmxVU8gcAAU1YI1flDNJRMI0ZyJph6hTpf/O+TuoynQ
+VSgA
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/notifications_diskforecast.text
+Size: 222
+
+G90AwIzDOBa8mEnVt9xT6Hvb1hX5nmOn2ZhCBR2FtGGgTg7YvxW0JZQmUOBp8wsX
+1A6JomV5tb4rIgBxIfPLcjWKzq5xDYGapINUoIqeujgb6BMSRhjVSjmehVgm9b8a
+1yEA0dXSGVihlXsG20+WSho=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/notifications_diskquota.mjml
 Size: 839
 
@@ -37979,13 +38057,15 @@ ed+xJFGhYnHRd4u0UGP2nkwxncBPZaiXRKFdx6w2tYMA
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /mails/sharing_request.mjml
-Size: 661
+Size: 829
 
-G5QCIMTUabghKDpuW8ULFZEx1r9lo7YVcj3bwzRZEosMiWT20tG29pCe9cri3Gx6
-R4QhFaqGMRayBjBz8TzQyZrf0Ntyg1IFFjnV99QFYTxrW3xsxKJc/7/ZxGVcllJt
-Jl2grpxXXo7C3CDUUyjnkCTZVdrlHN7+eRcRJP24I63q5XGfB89tQLyYFnEEzwfD
-+hgDiemLEbVkeUw5z7JdXdJkxgt/gFUmkzWWx4pYIiEzuXO0+E8KjLK19D1nNrAA
-Dl0OXpmSqnLpTE8vZpDfiAM0KZOkZHyS21BLIuxmIBzgBDQAIiux0KmXyswB
+GzwDIMTX5l5vRl2e73XEanKK4j8ANrXv1xZyvS/7zj0KEBkS6UwahMZ0zK29wlf9
+RLa7bVOJEqQikWmMjao3qLrVaU+NdXOhtxUdCppZJOY3GteZxvPcEmsjNovL/7eb
+OOUMF2w2ixz0zI/nbHFg5iahHkLZ+ySVoublGN7Xx2tegpuPO/IQeRrc58FzGwhe
+bIu4hLHBZt3XQOLxxYnafFw2nEfZztY02PHCCaS2Iru1ZrmoVj31nXmFx2aqI3u0
+UXb9FqqQLs3pPmtcPmq83HOCaBH++CKivUVTceEQ/7M6L1XZ83CeY4F+5E4ZmyrF
+IG7bPz6waC+LMXJvBUz4BV0j7z4EL2jncf9BlXufA3KHBBAl85sa870p7w5yEC5w
+AhoA9cwH
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /mails/sharing_request.text
@@ -38013,6 +38093,25 @@ G7IAOKyOd1hOjBOhqLBXjmv1SFsrgOz1KRe6FEmi6qawNDf5JteXImx9Ojlg/vdL
 nkRckECAucFRxEUvPo4y2vgTP6uwFmTVMjci+x3E+8FpPdQQMfn+cl/8RDaBEFAP
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/stale_clients.mjml
+Size: 699
+
+G7oCAIzUWC04215O04lROXyg1UvV94dQHWNyRNDP30w0zSTl+X6b5sJlbVqLCtMT
+kjO39kmCQD+V6N2aQ0Jmksbcj1HgWg9EtD+0NrdgEphbxqJAPtl+R+i7Vv14unEx
+wikwm95nOXFkVDOSm2yfUIu2Pck6ahI22uqZFliOLksycg1zCnB5O1IPKPy7Iynz
+buBFT/Hfk76YSJ0ZBcVwUijchvQmiS0sdLS0BcK//KPXVtG55ypmC69gNZaIf4KH
+zChRsIox3w2tHNn/B5kkKvrCh6Fh8PgRJXvBDmwTzK3iM6MrLFrxPkRATwdgQHlR
+CQgbo5rcNhqp1txMfOw1RqiKnrEaZI7wGKGWCw==
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/stale_clients.text
+Size: 224
+
+G98AQJwH2bniTKTQyVtRPhXKvRYFdVuq4/SHLryKwVAdKByLN513n8Kezgiz2W0B
+422HURa0lgQYB57qq73e8HU8MEpxKfEiIP04ZjhXkHPfSJamU9mtSw/E6FOqOkD/
+RFKevKt4KaOzmkKgTFsQtOaZ5sNoPzaacxl4bISXBaUUmfES
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /mails/support_request.mjml
 Size: 368
 
@@ -38079,6 +38178,24 @@ MF/RNs+LDRFsuG4MkyHq8IFhwW1iEgbmsX12Cv4GYSf3D1EV5UYGav5rTYh68TkD
 1cirGtBSODI=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
+Name: /mails/update_email_old.mjml
+Size: 647
+
+G4YCwIzDOBb81gzS3tOpnnEET0lne4TchD7nk6aCSN/UTQ7Ufu2mdeFY1EXRWmBZ
+OnXXsmt6awYp2iHxwBATkQjG4tEIw+zU0zwJG3W02JfQGZaep99eFMGjZYXTIubF
+8n+FxIERF3zFDN2bPh9nVgxs9J+G6sNw1daBzhfkEtDhceUG2f/VkUbkBHzBQ7Ia
+4TgxS8eyIGJskOX26m1btGqbzllZCx3oQGVSQixNwgTeDISKeXGaVM/J8yFx7SSi
+nbL3BRv1MfQbKamIEiaKEIk+wnFugoQSkckfDgyB4IjIHnhCvWouwwA=
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /mails/update_email_old.text
+Size: 165
+
+G6QAICwLeMMs8W88B5mWqsawNfDgn7R1n8KeyHKha5EYmSfZ5ESWIGx9FVZWaXff
+2Dam8SY845HxjGlQzqQnS2/PPGAziel/oLvA0mvXDwfFsTaIS/JZgrxFULSFeIYO
+YjPpOIyXkuNzAg==
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
 Name: /manifest.webmanifest
 Size: 180
 
@@ -38753,19 +38870,20 @@ hycdADJYIXnVdQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/confirm-flagship.js
-Size: 1501
+Size: 1625
 
-G9wFIBwHdiyzieTGPL3JtKn8Cboi21IcVJQFUGnFM9ZKlhSYkDJyudASrbdc6E31
-8TjUJdDVeYRD4QTfnHwT6ZzZFNG9h7aZ4C+8W0gEtwHKDPzZ7Sxd4gLPg6NkNZzF
-bQ+NAeK+m5cWi2c+ku6AJgmwptKAJGpKPKbisiinrOdJqhT5GOJb2rtAlrrmz0ia
-fUVOy5hCK6ehMdrpJ1IWKMFkPoz+LAUfcqZqtO4Dr5OKq0toKG5Ccy1S7YxA06SP
-xXPWlWuVE1Zg4138f86hD+9SOUhjrJeIiqRsNKdjtJJHk/gCdSJ7wUVz3tvAnLOD
-w9VOUFOfAhJGpjw5dL/Ruja6Tk0lQjk3uz5Nuc6oK8tvWioYAC41c0DwOCQBLkPL
-eZ4F4T/0kN/szU/iKU3SbinDZnZAc9imigNObvgKLaoPpMV+vBP4QTnocyHUdUXx
-xsP3owvScyOlJC/iz5wQCO73E5NddkjWVFIeo7wclEh9CBz4CyVQ64D/DZOEKdGu
-79J0mu/LtPVF+HNPUAOgq+Gxrp9t7PptOiW1ZEFeRkunyba9/4AOAXybCUKHkG/B
-IIKo0Uzts+gmD4RQs+ZRXkBM1qcep6Bm85gIGwsnX4ZH5l1WnsmS02kygKPpIrQn
-JnrfAFvT2D5kArZ7KLMR88q31GZfN4F9zQA=
+G1gGQIzDOBZ80XykPd93TR+j/jDJe5dTUyjUFCnA9gbcdkDNkgLT1aK1kSM578zu
+G2rJO5H0d5h2vJIomdB4rOZrmYBKXuRcUeD/yDODU3wJ2BWQ0jMvfFpv8fvhRFHV
+o3rtLBGwvpxjaq+41oHrDMiz/GIThggiNtwmR4qSLLLpOm8IaQKyTeWtIEfe0hdJ
+GT1FFssXMsuGnYFq8TMQnbzeYiiGxPB42CONdXAJwpfc2EyFJJmfXspnla+FAuld
+c9GBuqBhbqrAR6/+EwUGXHsMoMBriFQBgwG6K+WlbtiwmGJM6gO9Ub/nY5wHGIsB
+FG1kUNKXrGiwynmoFGueFhZHpgBJQfG7VL4SM1Ll44G2bYVVaHBT/4vNnd8Y2Ita
+L3veYdgju4dj/UVpZhp14EWJGACdLp9prQb8mzcVcGSus++1CIXmW4PIz/cB3/bd
+j3Xwzp/TbnmMTXD4zlkI0tTqf0WVmgspSAiZKDrdYtDdDajVVPG1jcYWmglPm2YZ
+OrDXqLv57hxE84YJp3CyUogn8TpwxMpRSIUHTi+dE3YW6N1k8v9PfoN1Qt82Rzlp
+x5KXxGarrI3WQzgxbzk+rtLZ3K74XHcPNluKAg+6wexu9mCSlto8yalqUm4tWubR
+yUqCNapyDxNvCyLW7cKVTboIu+hL1NtJX3wI+p+brAArStRVQsDyj9aS55i6LTCN
+lP66gFxb0oUcYgI=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/import.js
@@ -38778,54 +38896,55 @@ wYnBkGaJUaJUQjZ2bgRHkNML+wA5TP4WMskE
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/login.js
-Size: 3530
+Size: 3551
 
-G8kNAJwFdiwb0HZg3DzIC0mLrGZqf021cgLGf+BU/Rg5VeqcWyWQMj8pBkilsPRJ
-CgpTK/e+GpYKDRi+1Um/NT2X1pjRSjrbaRUFhaYDGsADUSYoj+F8F6EyRVzkMy2c
-360jp9ATGq78dlLrjS/WOwwDrk5YMVfp9oAIPTVvwu+ifD9l4UoDTRgNdn5RIBO5
-6OAnUJOiSOxDyAcXdFcBrQ46VKZtZ6IxbjwWr3fXebZpzcxIKWiDebFnWgjbSAcU
-JnHx5gNn7uyw4nwvDZI76uVY94KTEl6E7XcZ6jUWqQXvnLMycFRkFkgaH+OdXBSz
-xTH6mSwhJKB/9d9n+83eu4/ESQvocV7tG/MYy8ygau7BBGie9pxgzEgTqE+nMYXQ
-aA/pR632XAOps/0G+6LznlUC+nFeAf7yZLsWteYz/UNo6ijkWMESyXV+VlgkiDHB
-+oogABWirbV+wj0S6tOOpE/UkEB0SfdJgkWp2Gc4nc+kKTxJNUCbeFb4IatrGoGz
-KTvTyzGvkwZiE0pUlYJOeDcsKwQyEISo/G7YSP3Om5uf9a8H9x9fIO4hFncIUA7v
-gNUTwo1/DJLevEU9zh6S+bmAjE7+oC2+RYtv+l//oHA9qbwgxY8nrBKVsbIYzxsa
-yLi7+8Jn7m7msI2NC6S02Y3u71yDNifYAqbQqvks+LuqtdpVlCEQTg5AIxphGmL6
-hMKXDHJHrsdEwZ2PCnb6g8NErEtdfprIBQhC9WOQgYRRM9m1v+9n4u5Phhf8AES1
-S9+QvD0UHbXgltPcC01pRUYWZfXc2s7OrKiAO+O0hShkXdWvFmzOrgZ/KIQv7KMx
-zZB+YZZlsTPdazZMKSXe3MC0FgkMGLDwsS5khKo7iv7S7DAmKEno6ZExZZf2J2ea
-VTqrE+VgI/+AZYwQpjvKfuyeSTOhKqUptLl478zpdn5WA4yjvOKEpxqKDbY7PxOF
-ubfUcP0CO2yfy+w0o72M/FxPN7OcpAjChGpWw0xkfeQGLZ0Ixi23ooEog0QRlF3v
-xF8SuEywFoSaQP5knXnnQ7FfnvJY5MuzZ8ObeW+Oir6kWsZb0axpODgeQ+a1TDs6
-8QrRcFQIF625c/iMpzsFm6mgWggcY+MNYyfjyi63k0zS+1AAaTMB1hlXFZp1JwIX
-jFyMzDDIv7V0TnIckuCZPT3+kpjVydjDPC1KClKfQj40edAEOnu6bAyz/vnyqgjs
-jpynrsKijOuVGaOGUk2GWkoxmMeJJNlWxKjhQU+pmzmCwFwal4Msq9g27oLTUdZT
-iPX51j/bCiHPa1y9W5GtFLrlfhYJ0RXTilbUPhrVMOn9wKXESGsQ
+G94NAJwFdiwb0HZg3DzIC0mLLFV1/2W6VIqIXkybhNCZvaUXvuRKQ60FSLKkdPVJ
+CgpTK/f+kKVKhLPc5pJfGiWPqlodj9okRy0KFJYuLMIjFZLHcL6LMIaAy1emh/PD
+Teg0BkLHlR/3miiR8n+BccTRZVYsdLndIMJIzZuIuyjf5yxcbaAJo8HBz6nIJF90
+8hNoSVEk9inkjUu6a4DWAB0qn9t51hgXHovnq/9FnvRm5ilDWvG+EpzUre7yQEa3
+3uVN5s3vsdOdniGagDEKQGESJ0yPnLu9w7rzvcwiRBIteIwnCEBKeODTu8q3gCxS
+C94+55Vn+WQWKC+3xGVW+ayYLRbzJucRb1CqDj1JvkyW4vpMM3IDevyu141vMWZm
+0D9HYAI0Tzu5jsa8kwKN6TCGELr3oVCpK5/rSrW3TLAsh/lZImDE5xUQL0+2atFE
+fIbvCU0DRT5WsERyHJ+XlhzE28N6QxGABqHfBEPCPRJa3s4GUtKRA9HtBloSLCra
+3gp1bsqr8CR9ARXjWeGFrAJqBM4W98x4yLyimlY6W6KpFBTF/bCskMhAEET5flih
+vvf4+GX9P8L94zXUGcTqFAEa4x5Y5SHcwouc05snaCJLLhbRJWR2IxvtGy5aPNM/
+AAil64yWmXt+NxpHdPVKIWUEZNzVZSl5uJsFLIxJgZQWq+HZaWhQ8QSbwAzaNM9D
+vKsm0sWKMgOkkxPQDKN9OozUKY0vVnMl2IBPCrc/adjZC8B0UpeGfJbIBQhCjWPI
+A0mjWt7lx7tbdvfPwwtxAKI6q7cC0Z5RAi3jWsrcpZS0IiOLsrqQtrMzKyrgDpm2
+EAV7VnlqwQ4GSxjLC4X0hXU0psPSN+a3KhdmIM6GqbrE42OYtjeBVwMWXurIEKrE
+leKl5jAiKOXQ2YkxmUv7lY1mhc4aRDnZsB9wl5HCdEXmxx6Z1AhVKE2pzcm987Hb
+xV69MhZ5xQlX9R4b7Iy+UJQPON9ycwPnbJ2rfPPtexn5uTFxZjlJvYQJ1deG78j6
+yE1vOskY92MWTauKSRRCOfRO/CSBy6fYQqYmkN/85+LEl8vfTRGr4ncr7I0z76cm
+TU9Uy3jXmjUNJ8djyBcw045OokI0LArhoG18Nq9weapgMw1Ua4Z97Lxl3culaOlr
+JW0mwCITqkKz4UTggHccI98i5F/9Ohdw7HdemJ2ZXkl97eQtxZxV1QdpeSEPmjy9
+Av3OumwMs/F5/a/07LZsU5dgUfH1yoxRQ1UnQy1VG3zxiZSzrZBRQ0Bn9MksIHCX
++oPpPG/YNu6C09HeU5D6fD+hbTWTizhX92vylcIA3udRJrq6W9GLWo8mPaF6P8Up
+cdIaBA==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/new-password.js
-Size: 3452
+Size: 3468
 
-G3sNIJwFdiwb0HJkai7Fo2V+71r6GPWrJguTorrXtKyutgooO0uUHFCAVApLwUiZ
-uETDQTDJSZYyx5D8/ow527ICWMCrQ3T+1wWorMCe/sjw5qs3aRbhoNBv5Z9nG5NL
-cCWOR2xZVw0+MtvVSsENvQ8NFg9ZZO0Bo9TLhhVQyqAmQoNk6gyRSSh3rACo2JY6
-aRTa0nyyrPogCgAR46kSxxhbPPRcSADsWIJB+t0WoD1y416Z7WdIRZrL+9gwa/pw
-U4za/UXXVwWZLSpVaQZm91JsaPi6RHJB1nKYexZP8hJWgMWJv+L8nM+AqvzZ6IS0
-wuYdDLH9/Zuh8x+RrmrzPivYalyWNR7usdwg/7QmBEtFfYj37y+n1S1qT2SCPK8D
-bkcNlHi6An4KtpzTW3VgjJbOOthrCRPEMikpMO9eL8EkW5TWdUhT/6MVnPC36aU8
-hjh2sC+Max7bVpOpeg9awWiqv/RNVns2XRhyjqKkSiTSG6SAdqHqXk0ZO/vArvSU
-KfZQrJIyqzXLqHLzKhT0zeepD9gnVQwqEYj5VsCrRYWIPdpMSQBiqy3l95t1CPKB
-yRyjUp4xF6jIM7aJS2+lHj7Unt5WAoJwl2RpL+9bS9lVZ88djCP48TItXiqHhrkG
-TbGmlEPXG0oRY2zLm83GmQ/wam6/q6sEVvycfVEmDbW3I9KVeL2gB3yRKEgvTd+T
-jSmYoit8ASflOooNRbB3qq+AGtphASg3SbxVNVvJePMcC197t5SdpuKIWJQmIvc7
-yb6nkd3KK70FFpj5xlVjKXMjWJ4cFK3wHQiC8pCNZDQn+bkYJQpVitMTLmXAab1B
-DsKrPjVQrSlY7YpLdDYLIEAMoMIOcRJnI8xfEyGpqdlU7zSyo5KcguUmmfIfEqio
-3CQmEoVmJT/nVNmqGuJRMFkpgg+R6YIM2KXGVaYCn+wlrGz9K/uhU6nCJf9BmluS
-hQHWRtE5mWrszsUZWM23EObe2ECcujdA1osmOM5Rq6SG3HId2Am/N5XBP7hRLb15
-uqoV1e1VLq67yvikXZQ/V+F/3CyZ9KH6a/2C/F8n3MZgWR/q5Aek8V99gb8jAsCX
-RFMRkSEnnkRUqpvQVeoPt7hBuRqH3DuK8y0P6HSByzJ+tYLwjXVlcYrG9an/0HO2
-1JNpVmuDRQuv1EmL1FaccEwNvsymUgKu7pJVLq8d3aZl9rtJvIGoixRplbqf3F6V
-GbYHqJMXkY89DRu8KA==
+G4sNAJwFdiwb0HJkai7Fo2X+//zpzjLd1B5foDrllNandO0kBu+bBCIWIJXCUjBS
+Ji0bXrw2P8km1FKSvT1K6wqHkAjPY6j2H+UoCpj0u0WecL5+lboIO4EHV/6crHTG
+wRbY77FnYTX5SG/XCwHbdz60LJ4ycAMC49TxSkmgXEG1ECSE03iITEzJWQnAYnsa
+p1GwRfpmUXaBFyCEj0dLnGJqESDoAgFhp+IN0vMtkPbM5d0BA6tBdZor/EQ/l/Rh
+pxhs/xfiQBl4rlKpUjNw9ij1hp5gl4gvoPUqLD2NF3kOG8DFsUesn2eLpDxTna78
+UG0gVfxQKj/Kvi4JjfU9aGbn+zfN5/9MuprVF69gNXJ5UAd5wJBDTs5LAiG2nNDo
+I/yVVCr3qIKRTvKyAQiIaiwRdTX+HEzFp+/uwEBaXRtgbiYAwbmJKKHv/Z6DDhcw
+V1XATg2SxjjuH9NLiQyO7WhfWhsem0aSLjuTWqXAqcCpa6s9nR70GUmRUjVC6RtT
+QLyQdb8kp6x/oFd+SBWbLJZJhRGbpVS/0saOukJPjcIhqWQQR1BMuApmLspFFNSm
+SjwSq3G5ckDXIAkAUOYUcXmmXKMib+EmNr6ePuIbyqe/4QCvPMIudYL3ZrwBYFMe
+tCMY9grJX+LDX3e0ipxPufJlHyi5kIm1Wq1W1n1ILeZN510l0OI38YtSaaq8IpGw
+xAwGQaBnURBRnn5JG1MzRVjokZNK6dZGIpg72VdBDp0wgKjUYbxXOVvW+DYdS187
+O+ONRGMXWQwUnocdmD9S82bjpd4GA6DF2pZDwfM2WZ4kFDfoFEGgHrNmjYaTX4pW
+olalWD0hUwFALRwkIXwMVBMVm0KrYnGVzqYBEGADUuGOPPDzLufXREhKNRvtk5o3
+VJVTgLlpU/mDAxlV6tBErNB48ksOlr3KIe4Wk6UiCOycnqAU2KbGZaYcX+w5bGwB
+VvwgWrav4jlspCGTzA1wHRQ8J1V15rlIA1gTLpi5d9YTpx4NJAtGQxzpqFpSq7dS
+enZCOJgy4b/AUT19m7qasWr3qqd3XDx+ehcl0MX8x87ZcRfKv8ZPyf+1rAYhLO1j
+HQyBDA94Y6A5EQj0Gk1VhIekeByVqezCaKofbnGDdNURua8Y54cmMC4DNyrUxxeE
+LuzKIhV17DP/oVNurRemsdYBgyFgKUoLqsM8fEz2vkynhj2uHJJlLh8m3SF5bniT
+iAOkIRK4NSKjPKCV6beHiINPlY+9LxvMKA==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/oauth-clients.js
@@ -38938,27 +39057,27 @@ RfGYdLU8UlCDWFVncQH9WDZ6fDc0r1uJllmignkKLivn3IM29VdSoSLj46UsZZM9
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /scripts/twofactor.js
-Size: 3117
+Size: 3246
 
-GywMAJyFsWMtcG2wWQ3iM6aO+Z9O3bVML42PLnxIm7j81KlNbQbbkt0liji5Bkil
-sBSM0X1rP4sQamFsv5x8s4RJEksze7u4hwKlUcnESOLxEDc7dsCdvvb0POH8YMyM
-xkHhwZUvz2I+tWEyx/GILTGr3Wu7/U4pTLzrA0L005HzfUtghgbWKUTPXK1wHW4h
-uHh/RNQcAPU27XoV7+EoXAuURkQmBK4QRB9Y/IFLBtJ7UoF7fpg5QT2vFmZP8iSt
-jGIGwestKBiIcjKKgiDoPdpR/Aqh9QENRe62jJkgQsJKrumAer7/Z3BLgNvgbnBs
-GzMk3JwT6zlBzHU++S1UUOJKR3/Rv7UX3O91TaD3aCo0bwIFXkP6RCsA3Pa8m5aY
-W4OyJrFIRrZRG5xYvtwO4dCtSVLMDevwl3gG3RJKuiEFKMhtkD5AP6CBMZtK5HIx
-GImcnqWUSImpHB3jd86Cu5CrerKEa8TvxQEBxnAI/Ba729H/z7c7p7ebI1EhEGcn
-AfrfebUIya9wWbYXIfgNJMnECHAJoWQXbWuWQsYw209M+7bOq6xBjaJ4p4kdd18X
-/ed80a/JXHj3+90jkPI++i3A5zsnKSZhK+DcZCewiI3qmp+IiS8MPep9N46fbFzv
-sSPGXqwMEboVFtrjKmR9eSdJsIzW/SIRAYVusFRhwrMmfuKkmpT7pP2aoyVppXR3
-R47oFf6CYC9SXF7bcowxVi7w0K1bRCuAFr0zopcK5ImMxHkPxvFBSgWP5osQvS52
-ae/SvwrUoAyxTVkXvsmsvCLd4GdEIYuk9CeTzhrrwmK07kvqRxvLqRDTa58AQwuq
-ZGkqs6AhTxXRVRYIaPCWROMplw1VUJISQTtEHs7++IgHQHlpC3MEkduSpCMPH2PS
-PR+irxiXVnY7XU1VFWgQNS+VpWTqYG8SIgI0JaEP5B0AOQYvbHzdfIgndVl+WjhF
-5gcqx1R2hl+hhRLszb5LKV+4LDllhNsJm7lrAQd4g9+A5X9/L/qQj4xJybDzzlId
-bTg133HNprMwMA0Xqvz++dyGWmEEvfX5fBwcm6UOSi0I/8UBa1CXenuwIdXGhjhU
-nE0yQpif365Vn4vd2CvY9DKzqe064wmjDjBtrXyUS8/9z1FMKZ88xzxpQauTjz2X
-zy+CdUUB
+G60MAKwK7IbbB5huwCqsgXhFq2LPIP9Tp7uW6ZX8rWt+eMr1F6euganggJSwZJBP
+wQBJlpSuPEFJKRijCmI6WmsWIdQ0ti8nLw7JrGqaWUHEM6VRycRIYpFN5wJJX+Lg
+6z0DnO+NDaNxUei58u1dbC5tWKxxveJKxOpWWm9/UgoL76qAEP1yZnxfEpi2gQ0h
+eqZqjfNwDYWL3SOS9gCor3nZqNiFI3gWKI2IRIg8IYh+MPsjjwyk7yQCt/w2e4J6
+nG3MnfhJUplEDILXVxAwYOVkUgqCoO+oR+knhNYPVBTpbJmyQYSMhVynA+L5+mHt
+jgC3yWXtWDcMzT9X5XLcEEoacckfyC7M+zKlCtmPRQDMVhb2n+4nLcocpc8LYlOB
+slFFBSV2eXYc/XTCoCPaJYESod7Rvg1U8Gpl/dqWdZ5Jc4nutgidM4tkBCXpghtL
+5Osh5L42SSpzKT38J95AHRhQmwKk7TpIE6BR0cAYmmXyOQxG8kODPGVSxWSOn+k3
+UfY+5KveLOMZcSE7wFsZCoERZNs9Oxn2DTTtOmIVAnGoE6FMPV+9SEHpLZDdixDU
+tgwRE8DFH5OSda05SjKG2KHEnKHs+RqCVDpzDVE7IrRIXavVmtTFlm6bakZj9HM2
+F7/8+dQLVCbk3wGcjrPSokY2O85O9wC7XOCuvCUmxjjuae89OIFkkxpPXAU2Yq0O
+ETphI3EuQ9aZ9JIEy0jqHxIRUChrSxkmPGsWjtzEOVs7aH6Su2ZJlvruySFFjZdQ
+7AkKDNaPHGN01otcl/si2YpIiybN6E0FAlVG4sALA4lRqkWjhVaIXn77pHHp3xrU
+okVUyxp15E8Wgy9SD37HFJJIqv5mUVpjXdjM9tWAqtnBcizG6XVvA0MRrhTFUosa
+8poRnWWBgBJ3ycZTZh6yoDgljHaI3KK/+YI+oPZoK5ZwPNclSU1uQceofyFEnxEu
+z3R9OpvKapAgKt8qm5LJg/2T4EWgLAt9IK8AyJh4YdPz5kM8qquVt4MTZO5TOqbC
+Q683n2luXJieM8L1FJm5boACfMFFcIFidfzTM2NShp13lvJoXbBZyDYbT0PbPHyo
+8wT8uRC34hL05ufreXBsjtqRdSD0F6OtQV27v8WGWB9LAlFRNmskVLO1rteqyUVv
+7Bcs+5m5bFkqT1zqANPVyka5qMw/kGQuoD2F2GpRq9vzXZcZKMG5pgA=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /security.txt
@@ -38969,44 +39088,50 @@ IvFUxn9ccV1JGRY69tQ0HkyVW0egb2BLnsYJR0MA
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /styles/cirrus.css
-Size: 10759
+Size: 12067
 
-GwYqABwFbutBXB2BTOtxLuAb/NvOJ/R41/64qEiLK6C5bEvd6nYD5BFDtBWDbvo6
-05/WKFN9af0P89feOfCBFcAnQ7pfTj8vrflDQ+YZpZuAIPD3SdrJ+XxpFZVSkWRp
-N707paLDQR2TDlg4Cswmm13hy8TeiFG6SpL5uvSHPj53lKam5t60WHrU28gmdtko
-WxPwKJYSX6kq5aTf6CCCFdyU4G/0ApU6FGSIP/bz0KOOcGYg9f4jj2TWJ1k69xEY
-sjbIUTi4HHcQyZRLg5lW1WYpodeUWdw3LTc/5CEHbYuTXbndsRMbkDjAFBNU1jaj
-eeb6R1eZfhBTrpSShD1ca0gGgua9FJL3tJoV2BA0BykkB2R7hQhwPshB40OLR0nR
-YchYzFcLg9mjJFMYAM4MxaDMaQc9CSWSArsAh3nqwMeMqWiphkcOOiMHW2QoMLUj
-M5TDMjMqsBUmguajFJKPQXGukUtCJGaqhMC8URlolB87VOWOxFyVEOISfEsr2ofu
-eOCd4DaBTvVOFRhKeoKl+hlH//yAvxMlKOfLERSMjQrFFhNBq0KSSTSoU0Eoo52/
-fqpaGalDyEixW/SHxFDZV+Dx1pRZTFLsteE8DdGSR6rUVSPhJQ9IANVDVqpZZNWA
-fyYDNZhUI+ElD712rh2GaMk9egCV4pQMaKJWipAM1PKrlPbJgGaj6gaIyVOvL+6G
-IVryGLVxRWRRSm11Yo8Rtqcek6AX2uVuAN81335iR0GbzRd23kTnXwjAMfIaMXqL
-J9+Ndy8+FSWeb8RPZuUzP7jIOLq8i1gIos83g1qKdmqxqfJ/oe+knlO8QL7pbntB
-cxTpEaoXLsQ/j1Lj+Fp29H10il33KyUEL84WNKbsTXBD5erOAsrdRI+a3rHA/KAt
-ED0vm1GoizJEQ9epo+eSQ4j6gKT0ySDmuojyje36pUK6N8VhzR0p4bMCTZhjrAsb
-+6I3LRQ3UNFMkdoSpWB6YLNFayuVh8wUqa1RGqYHNlu0tlG5ZKZIbYsyMD2w2aK1
-ncpTZorU9igL0wObLVo7qLxkZk5QgHGbkfyPXVNt1eeKYYONz/zv/eg4Rsqd3ojx
-0xqg5tv4qRkXaLw3v3I/Qs3Q4NXPOUUozfIa3R0Yrev1hnPFQNQ5uPiNRTRollXl
-sCYhhXuLyGIry5tHG0+3KMkiTYs500vguxmmw3Juw1+ubM744jH3uBCH8yxVAMkS
-P3NRDgcTBdqhWDHXsmqJtYwt11Qzdc2HB5czcvn2ffcYEstg6BTVUVe9i+88X1EJ
-jRmJzg1Xq5zmHUCo8Hmx4tnJ+v7OgHKKYg4lLEZSYknjhRSP0KVww0UXkfk3X6rJ
-j/T3o//tbD/SfIeV1xMlcaSNKmr69Ne3ktBwvRt1lGG5C6Sl/9NAl7WV6Jz+MxW3
-sEC8MG8pp8tNUdaOCpI8LbjVqiGdvNGrjZyWtrHp515K8n44Y20SC8DeFI5uoH38
-3ZYE05d9dJ2m56adDsqpB1tLmGyaWFdSec3r7DNzIxk4GFT51JcV/wUckdwwbv1D
-rCwlu+fVCEOYagcgiQ7O1oIVK4BqkFG0c+QEBqboItBk4t9h14kOiItgztM99eaV
-ByCJjriacLIqB7IF3kR1p4Emlw0GOO3RGfu19ownH9k2qC0f2cfVtkASnZ3VMzcL
-+ntKuyumIpONwdob1z7QWkTGU7mcn3xB5mG1LZDk5/VKd123mGniKifSCCyZbH1z
-jOlEqGoBSPJmTVOs+JINlEi5eZTyivaS6WZL8RbytPAa0N47XwO9HeMJLuHrM6HO
-4B9stJH8Z6V7ShmacphOIKQimnwfeKpM5F8xLclJg1xExhBJ9x8Jkqh19rFfJGey
-VbSxs+WZLSW9C2OKLpHPjHp5Y5AGkkgu67rTwBepaudXpLVjPNGFim7rc7QNnsQz
-Tb9/rNsDZUSRkOQ3pSwWfLv0tsASnZ23lvr1UFeJgFEklLOxawc4mVWHURQ+CTHX
-GmBja80551UX43m9t32YPWi0Wd/JfgfWqeBue/MQ663p60pPXnLOzS/PDtYuvT9L
-xR56Mrp0d1/+jtACBa99YSHwc4MJzjqa5hP9xq4ZPkipgL8l7PmwNerwjC9yo8jC
-9lzDfnrny86Sy+k7q4A83Ss9ycE5fDI7Em7BJHQ9MtKG3tSL0d+l8s2ilAtZ/8lc
-2wXD8Uro8FJkT+zn56MP5yLaMCSZE75RTaVBhyOfBofHkzpA3DVk58mhdsjGpoz3
-kzgTcwauU13J3+k05jrxY/AvZfcc
+GyIvACwKbGO24A0R4wgPO9nZoXPwGRGszU5Jnz2FrCuLZS4ktP/plOUh1yfJjV5x
+SIzskqzh60x/WqNM9aX1P8xfe+fAB1Y4jAzpJdKVb2lhVi0r3xlpDr4BUFAA+Y0Y
++SVAhOsquzpDOzvvPJIxDnVPdZW8X1mDLHoFFyVPgDgS1GuYns2s7b8vIMRYVKDf
+drw2T3383NEhsqmpNxVLH/UO1SQtm1RrAV5gKfFhNSlP+qaDtUjBTQn+Rl+gUoeM
+rOMv/zr0UUecWXB9/9FVIv2kqI3zxDB4HVCjcNQa9yA6Uy4dZllVZzIL+miZxfum
+r5sf0vCDjk0prdzpOIkNSBxhiika61TRMvH4kzamf4lWtOfScbiCaw3FINA8eCF5
+kNUcQEagGbyQDKj2ARHgPPpB6+OIZ0mJIUgs0o3CIHlIsoANwEnQDUpKd9CTIJEC
+yBU4pOmFtwiLaJkNJ4aYiSGJjACiaSJBPyyJicAO2Ao0T15IngpxHlFLSiQSDUIg
+bTQGEfLjDlW5I5FqEEItwc+2dAw98cQ7xX0CVfV+a06Q9AIr7TNO+fUBXyBKUE6X
+IwjGQUJxZCIYJSSZxYA2FRk19PNPTLNVRukIMkps2Y/MDI39DDLe0TKLZo4rbTht
+19HMkzTqoVHwzCMKIPTYVGoJOTTgl2aQDlNoFDzzuNLs2s06mnnACGCmOJsBXdSZ
+IphBen4zlYMZ0G0MTUA0zyt9cd6so5mnqo/ryhZS6pwTe6qwc+51FvJGu/QO8FPz
+03/sqOizZWGXTXT5jQA8Rm4jRu/xlIfx+uZTIfFyJ34Tlc98cpEDdHkvohDEmO+y
+Z9EsjWyq/C30nTJyqhcod93TKGi3ojxK9cIL8ctHqXF8LXv5vGhiV7+VguDinEVj
+ZK9BDY2rXhw5T2f6uOkPC+AYy5C8LpsF1EUNrGHoNDFyackoPdHcJzGVMtMhoj+l
+oZ8dOJqywtE7SiJXBdliTm/deW/f1ZsWiiep2M8u55bYI/R9zfvV1dxKe0z97HJu
+jT2Ovq95v7qa22hPqJ9dzm2xJ9H3Ne9XV3M77enUzy7n9thT6Pua96uruYP29Opn
+og0Bxj6j+B9rV+3w5ymWHTZe+Ov7kTnGnCe9EeuXNSDdtwOnblyh8T7zdD9BzTDg
+3c+pIlRmZY3uHWiK2/XJLmLB6m68+I0lecispMqhJsHCfYs428H8tuWNl1tkkrDz
+ImV5C3xvxnJYcFd888rmhF88pl4LcTjPSgNgSvzGRXk4mCjQDtWKuZFNS61lHLmm
+hlfXfHjwzWYtv73u/2FmDQRVUV0N1afoXuyvqJnGDGNww2aVZT4FhAafi1XNNtv7
+d0bOVo4tOWExTI49nRdRPEKXwo5LLCLpD1+qyfP098P3sM7NBVh5PVE6jpRCUe3z
+v76VDg3Xu1FHCZZzIC39E/TmthKd6j+teJYF7JV1K7hc3ssoawdI/rJgrzWCO3+n
+NxrZ5naw5advJfl+OGNtETvAvikc3UBX8XdbZmhf76Pr9PJM43QTXHqwtRTFFor1
+SrquqVWhtTaKgYOgwedVWfEfwHHxDePWf4iVJWfveTXMCGbYEUiuo9pasGIdUAMq
+SnSN/BUDU3QRaD7232HOrAPiRTDnxZ5X5pU3QHKdcDNhsQYHsQW+R1T3RPPzBgNc
+9KTG/qgD8fwTbYPR/Il9PGwHJNdFrZ6tVTA/Utq7YhKZNAZHJ+0fRC0i8UKuz0+9
+EPNw2A5I/ssJpYeuu4Hp16vckUZg+XhbNcdYToIaFoDk79YMxYrvSVAitdZRqSvR
+SxZbLd078DPCayD6oL4GYXviea7l+zP5CHJ4pVw/889KfnJpm3KYT8FkIJr/UHiq
+/Jp/xbTEp2z8LBLDxd3/SJCOemfn+0VSIltFOztnn8nmFHglpuda+cyEy5seRSC5
++EquOwN8kWatfkVhe+J5riK6k8/RbfAknmnx4+PYESgRXUyK31QwW/DtitsBy3VR
+b63w22GsEgGTiym1sUcHOJnNDpMr/C3EXGuAjb01dc6bXY2X9d7pafag0aa+k34H
+yang3fbmodZbQ13d6iWnbn5tdrAx9P7kih56vnzl4b7yA6EVCt70xkLg5wYTXHI0
+bc9U/P0e54OUCngA1Dk+PBl1eMUXrdFlYftcw3764MtqyWX57ixAPt0rPcnBOXwT
+HQnPomWYemakk72pF2N+SOU3i+bcKflPttouCMeVMOGlSE/sz8+bXjgvokTIfzh6
+qqbSYGNefBk8fK7UAeJdQ9vVi0Od4I2mjO934kysGbhNTRX/pNOYDuKX8C+t3HP0
+jsQvyA/AVbxijoXeOsQtePjyfa5ei8bRD6YGvfGW3sy3V9eiAtuIcFO9kk9MKr6j
+B9JLKuCPdiheuYqCirjE/xJxYWYzljjMptWCeWfAqT7JyoGQGvZOvoVjbn6Qwcdz
+FjU8YOpia6VLnB3n+harIOpfvoWevFW61LgZ+4k04eTZv6nNroDYsTijAGoUJYAq
+DTqpnrxNQbmmMpo+i5e0Tg1r4xPRuL414k8RyZe8qT3FJ19CGVY1Ov8NHMUtXPeY
+jEXwZM4F8oA7eHjHw4SAU7m4zm+adNSnk72H0HD0gR/h74781Hf/A0f2aP12+0vo
+JRzsbznu1/0P
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /styles/theme.css
@@ -39016,585 +39141,627 @@ GyYA6I2Uq/VkrfISJKYVQNzywAYcOBRoMug55RR0sU5DRqz0BhgC
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/authorize.html
-Size: 7639
+Size: 7995
 
-G9YdUZTs1SiEzgO7zcKhSDElxRFfbon11Vpb/ffzanqiHWThTAwbLBkOVrRpgtTs
-uale7/IvjQKidM0Qk9a/5j4rI39dJVDKnwok3AlV4c6dsPfe283MHjAnKeams5u3
-PwUCV4dCXStPqAL5Ck/gXIU7JbuNpYfVuZ3dfoJBQgjBFO20aAYayNqY1UOJjkhF
-cK9FB5FbQO7pUCK/5JtjcMuI7YxeiCLtlNcX23VwYQ4Q7+tv2OFGhqc0tzGrhz6d
-K1Jd2+QdcCWklE4dUQ9Kza1Fi2XKe7/Ic8xVqKMtAWcSPZLFiyUyLamnE4pBECwM
-3BRMnkZvYSF+pknZ7Q56U8esDpE2KNKiX5tNTMLkh6rWkSEWFAgMdkR4PehmD2W6
-+Of9ruunujTcQw66+AK4jtA3hMCgsk/42bmcGgfG3w2UyFhak2YkBKHVD5Zn4tNr
-crrxsQJ5zFAxuxVACYD5GCrSx/ZOd+itKisF++iQ7L1Vn7yfvkqTA818Qn8TTPGA
-6pwnjcxnN3GM1UfLS3WlTrHQOb1AgM6IguZTKdtNY0mvmwUS3OuQBMKm5/j56ezl
-SoGFjITu9UGpR/QXv13RyxO0KCwNlOrZL1FEFV1pfF8w6Jb4Kp5Ad/b16XLb9pLU
-Dfgl6mRFogK4t0B1kBgqWqkqqFnMLJD+V00Ea3nAoDxyOhCeiCJu+ktzmYF6T+Oo
-aUTgek08d+FB4VKloYrJs4xXkvKyH3Mf8JDD6UkLYNWicKovnFeLsogtHPxFkaIP
-ccQXkWPY4EvDnJIbMaUFF9UMljO0P+BnFek3ZDQQav8B1JTGQNElH4qfkJuzoZac
-63oaWYOHSrBQmgcmFjPNI+rZDHIRS+pp+W8n/bAp0lP1yCGBh4eqGEYEMoIwWYpi
-x8//+LieTuYi2Nf2hvn1eFgk2sCg8taN2orsiGvbthXolJDsoE41x/X471k22OC7
-4OccN4OqCNfMMzLjbq6fnrnoo+iiE4Gm+NK11gh6F2//gLkHVu6XvkW+dQrjXAW/
-3inZvRjq574d18IN16sr50Q1fSL4CcWd+aAfwxg/8GKczA/4gi30j3qUJ0RUH1+T
-vr/v1Ti7+SCvUH3zt5/ZK92HeI3MCHA4XMn015SmeJ2X5OveqeKHfKlB5u966Kbj
-IGUO5px/X5xq+7D8I++tC7x/nmq5B36E1xvX/vpo3lXt3a+vlCdUkF+fwTs1GQTR
-mSdkiNgyNjYTnX0+p7NpaVVnz3Mnr9I4/c0/2kQCAvKekeaM3pBO2e5BNTv43ZCj
-siW5/3Dqgg/lk9ueCBY9F6Oed6gNcxh4YQUSmZxRd5wyf/lq91e95DLakI/p7Ukg
-S8sqJqxuHs5Pv7rjyD5OjQ2qvd5ofujd74X6Q/M5ndflBvPFqQNoUw4oI9WLJPWD
-cCSWTD9L1qx72svC38XW9OH7fLcu3d/UT4EWf/fbcNj7mX7mIv9yRQY2/a9DX3xI
-Qx2GuruY9d0xgCj1sqtMZG2pY2EAuJOLo/dkC5fgo1TfJiG90kopQr5ZLgd/VzDZ
-cpS5b0XujnnQtGBFugZ76AF8kOwuyx1cHLsXJoAmerMY/Oq4fIdTtQeKh0ekzcwy
-MDXDFG2b4jKAFLt2C9KytBqOvfeIi6mys7FD2RhZpiNu9PGtLTmyYx6AZR+UwXlT
-Xv+4Z15XsWTWaQF77huLlV4xs7ektyuExWVws+s+YMjqNJc13NUvmfWxj+ge86uq
-JsgqTuoyq0bABftdhR7yIe9J61pu+IdW3wywtHl7CO0UzeCjbIbpy60Nmhkrmxha
-vcTwuroXhYWf/Rxhhx/p1bSwrufGHsP1gusefOml+ba0X7uWhkTq7nz6xv6bMtUW
-r2P9Q1ylG0l/p5i4MLQ3ixj7rIMLVp1VAeT52vLSbrZqSqnq++jej5nCsr7NUS5g
-FTarR13InXbRZwyhuWjG9Td6GMXdws5s5oFiHVfVtzP0cN5Lpo+QCA9j5xt7ceUx
-cysrd3ZenchwhhhCIqge1Ra8YNcu5OAByoMAcgI5KnHaoghLyn1BMHzOdskdsmZv
-h/XZaQyWPeScKLBq+8asbTofuvd8y9vT0Vnc6pHfBa3gHPSVzoyVi6qDNg3xaSB/
-DvPT3eE8kBfHfa6BR6beHXE4wp112aULa2aIMHPSq21nfHs1Q0ho1xiFD2VbVEpc
-9JVSw4eL6Es23lKX40IjADySt1emJ7oSdUKGsOR40AU9PJTQ88woG4x4PDabB1iC
-erVtJGVdrat20quSsrncUHeGYHsWNWdh4UbeNX3hdX/VjvXxLBbJd+0l1LxBvUxU
-JZd9LUGxF3p58yjuLscqMO0zSlJjN/15Jb5IbejOaKHA1877ahNp9hW9Lc7YEbM/
-gahATUC23SnCQ89NbOKfpNWmMhNJLakLM6+r6f4X9DYGSmvDvx7tKMhlhjDteDOf
-APqNe4e5KtpjyK2BXMPTTEVjofkFjkNDIQskAzGXZULNTKb34+DUGw0ihhwLIklu
-tyNuE3Mw7f+AJLRJJIJYmw8b6y5tYPY66PsSFRkQ2N0QhB3tixWz5jY1u+4jYGuX
-ekDCpvnyTClO+orI1Ls/Rbs5zuDQwGyGpHiVMpg2o8IfDo1Ri8DqANZNP/hb4YKM
-SzAluoOgH6tu6xq+TidAk91aX6XMoIh6z4MsK8fb3LeMItylEw2e1hLsPAAwZGBB
-6Amp7y3txj3VwRt6aHOYAQqdNwpZLMXxFTIlL176wEg6OuWEN/PCaFRTryJrPYml
-gsoloa2Ek/ckIIZoRel4pHBOHpJGZ0YVugKrKLDx6xFCR369/FlUVdKSG9NFR3/0
-SemPSjLPwp8/YqXJ5DAix7apSLZchnia/diESds0Vz3zRAfzMBE7bqOptEuTYiU9
-FOKL+nVDNqT/mmz4Z+yPwStnjEgTsJDgiSAZBqdKhk2C+Nt44U0nYA6Fx3jAqRH1
-4GyvadZSRgFoEU+kAfiQ64utlZzWi6h3oAiI7EqIIEZDsCIzM/BwfAQaq8ZaeAE=
+GzofUZTt1X4UlXsLQqsD3hA1/QnHAIyEIZoIFYfqTy2xjH68hbfSUZs569/PS6MF
+lANNloDuQuhQUWOezx3pAzm4m4dtQYQB/YUMOfLmgcXU7qZ6vcu/NAqIAhtw5BEH
+NHF3zRCTVr9a+zx3yp8KuAj9hYpTuddveqvmAEGGcHp6dgPADtWvCIVGxLmwtV/J
+/N+YqxdvbXFDP36GWAxrC/kfaskl/fvoDU9UQuLRaBAqeRXTTujEyvuxt2dX+7Xd
+Xw0TEVGythVUAw2wOWaxKdF2oCA8ArxvHVbTKns9eow8A5JTXA0SlJAuwVUlHZpu
+iQEZTPy77RqwJEmYGdKr9MBR/0TC3FlsC3WrUn6XJCbIFFHnsSTGQci9o6jRUH/7
+hx50bkDsfgmk+sIVhCtZdA9PTifkgjN0Bmx60+PqdRbgD/aSphkgYiEs9pm2qfQI
+3LbOSUiHQyF9JyeaDAAMfYr7OPj1ICZ6eDV/j3WBnz1eIQO9GwOyTBBAAgchMyB8
+99xPtQXz/wR5TJdL8iyIINh073kWXlq088MPFeixvjLtDLhEeFxCefzS9viH1aJa
+SpQHJ2hfLTqyfh9W+GRAdb6ivQqWX4h8V7En+3QnLtE6bVWxHvRjHITQyhPA2SBI
+GWte24TbH8pkV+BakkoW+eot/5ICd41ZHNuHv0Yv5VAPSFtghR8akLKBZKOABhqG
++9q/QZPAG3hyerGvO+t2EE9WsGvUaFkR/7lXMzh+Y6C40NC/wsMmgdo/NAVq/z+S
+84FzX3d+EIeHkxDEXeF5r0p4sfRk4HMpgbrKHJSk6ENv+z1wnaoi1LQY/3Rsic16
+PhQuWWr1QZJ8Dt02O8DD3MBnPCDFUMRpPDYuBflWqkfyRyQpQcLIZ6dLKPB7/VyT
+GTDjInOshsIzKi/4PUTCETUOoc5wAlVJnkM2pw3DJ3B7NjLNraYlzk1YKEEHiXtj
+ojHj/AzxnD9SNA5pUr6EU0o9ZrVoyUCBwmFx5LBLwzQvBkal1+h/fCynk3ML+qPt
+MHozH7pCBRry8tioLwXvmLqua56uCdEO0WvaleP/z9JB/2yAnXLeGXIm3nDPM2bM
+zfXTPWgT6R0rgpj53dL3kb+Ll+Cae2DlfunbkG+dwvipBn69U7L5MtTPfTuuhdmu
+V1cmL9LT9EvwE4o780E/hjF+4MU4mR/wBVvoH/UoT4igfl6Tvr/v1Ti7+SCvUH3z
+t5/ZK92HeI3MyGE/XMn016QoXueFfN07VfyQLzXI/F0P3XQMxkzgzvn3xam2D8o/
+eW9d4P3zVMs98CO83rj210fzrmrvfn2N8oQK8uszeKcmAyCeeUKGCE3b2Ew8+3xO
+Z9WUqrNn/+RVGqdo4D8TIh0Cec+Is9Yb0tKGg2oa+d2Qo95FOT2cumDTlk7Qngjn
+46kYe96hNkxhpIVCJDpJGaP/KCV/+Wr3V73kMlqft/T2JC6pxsoQVpeH89Ov7jiy
+jaiNQbWXW88PvftlqD8wf+m8LjeYL8wTDn5VdpSR6rkD1VfhSCi6nyUr5572srAH
+bE3v3+e7den+Xj8FWvzdb8Nh72f6mYv8SxUaNv2vIIkPaVdB1t3FLA9jADH1sqtM
+YBOpI2F0gMnF8T3ZwsX7OtW3UVArrZQi8s1yOdCuYLL5SN+3IrdxHjQtuId4DXvo
+AWw82oHljp+F4cIEwcQ3i8GvDoscTtUWnj3ckDYz89LUDFFMbIrK0CXj2i2MS6oa
+PvU+Jy6mgU7GKWVDPJPo2qiR8K0tPtI4D8Cy716Ddymvf9wzr6uYM3vXofW+sVjp
+wpm9ZG/3CwowuGm+DxiyWnFZw+Z+yayPC0T36FZVTWAUJ3V61Qi4sN9V6MGFvCeV
+t9ygQ6tvBpwnvD2wnaLpfZ3NMH52bdDMRtnEMO4lhLfqXhQWfvZzhB1+pFfTwpq5
+G3vk9YLrHkh6aV5T/dqjdHDK3fmEDos3Zawl1qfeGFfpBrLYKUaOQ3uzCHHBOpiw
+6qyKg+Nry6ludk+TVI314X0RMwWlos2RDViFNXrUBXvaRd+Jgc1FM2ax0UMosIWd
+XOaBYh1VjbUTdD/vJdNHl4iHsfON87jyqG9l5Tbz6kTyDDEER+jpsWczXti1C9l7
+QHkQByuQo6zTFtUGRbovBP1b2yU3s2Zvh4rsNJbLHnQqBVaxeV7G2iZB956Ns6ej
+DW71iLqgFYzAQulMWDaqDloF8Wkwffb56e5wFp4WN32ucY+m3h1xMHhnXXbu8iUz
+BqhPerUthuzVDF3YrjHyz7ItKiaAvpIcXi+iL9r+Q17OBUd4s3h75TZ8epNVJ2Qs
+hNKmB13wjxx6mxnkhGGPl2b1AOVIvdo2otJT66oD9Kqk7P7cUHeGsa3VnItil99N
+Qb5402CTHYV8jMWyTX0pkjfEy0ZFcu2oCcE+yOXTI7eZLxXObF9qUmK39r4Q31If
+fSELBPjRcENvI9W+1vvhcB+Zlgqtmtw9Nqq06h/dPqXx4Cq9OpWNJGtSd2ZeNpH9
+72ZtDFr5LceglxhBL2TOTgT4l/fAechpXfSWEKrxcRadsW56wXhg6LMmGkhchRG1
+iczHZXDcjomvtEAFSFJqL0Pu1Nlb8iIiFWz9SPikTb9Zc2+DvNUhbXeMpH2wzRAW
+uigno+hSPHZpc9VV2zjeX56wj7XYyWXTaKgn57QTc4mkxnJVRMO3rGuqFpK/6vMp
+Ztq+RX3qLfgQ5X6IzQdDEEuMiZGqlyoWUwOzKs04u+ZvwNaIsDsRcxPMql6GG4VS
++p+KAgEJgVpBpfFpIr1A0IWa6hO3r4MJ0FS21E9JQoid9nkQhsl4ndtaRqWYUwv7
+DkAbC5yB/VrgCfSwt6BxW9GJHr3gPchwRQeZOvNFpw97/O5SlsrDenT7PHvXp7ag
+LSLIvWuxL4IdhAMkNcCtuaAaHyiOe0SmfWPKm2qW5NCjmgCxsXTCpgpKdNWWZ7pB
+pVv49P4aJBU4/v0TC5/yCEZk6DoWqS93kJfo6K1bUCq7uhsf6VDBE9m5lWZuV7Xt
+IG1u8kOdrEiHoPRJhxe8fwm+O8kCtAGlkyjbfXUTy2IbBfu/OfnaKKicsR0NDj9p
+vHv9013lIz1wDk9WAnTo9XdPcs7shdPujAOBPQgFsCjbI6nyQcP5ABJSzWrRAQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/authorize_move.html
-Size: 3891
+Size: 3846
 
-GzIPAJwHdnMG5cYpBKZCjSkP+TVdX7UM/Qun5X0RqS+yxAkZlhMiDQJjszf79kCy
-T0Thau9PD8JgJEaiBMps/+UevTtwuH57S2kliUJFVeGa9pGeiVFZxKszipRBuccW
-yoEO2vg4Rn2JNujZFzFC5AlIPtIOwPc7HcV7pP8+Xq+scHJpPstNczZ1oxYlrjhv
-XLZbAqMUY1zJzzRARyxINEw9Rp2iqMvU2Rmb3wtDIg68dhwTZu1FJB73SZXVEn/S
-ZdNV0SxNmKNffDZgwuR2mR0C0v/EVB1wNqeL33tfjy+aVc4deD/5MZpa7cKZW6nH
-V6MJjNoNZaGf1eSY6/lYyBLeSTllKKK9L92yb5LIFfLLAE55TeEz8kcY2IBSsWmc
-xBzD0jfJwZdq7FJDYawh1RCB8AmBma+8dkStyajU/bwenJlC5kP62AvVomavBkCa
-uC9kb3IhlGZIqAjXy4Q6MnV6wr4igGbEAW3ealzp+e5ZOYIT78yEu1Q/6k/6/3l+
-Lmbs8j11D4/OjPfuk35/HqASRZ/UBCqyRfixac+k9Iq5XRM/o9BiotIAVzDVBMuU
-MyPBmioXnfUi4uo//EiON4mb3TI6zSRbKpiJhG+AIZGzxyJG9mfwCxVXKGbihwQe
-lS9L01tTLrL46IWUh7WaGQVTnYByGO7moYS2P+GrXgbsAtVHxX3dkaEyt0IK+Daq
-rJJFtJU9VghsIAyUIqDcty2iUcp3kb0ugBJHTQaRen5ya4cgblt7IAfRtOYAC0aH
-xJYJYXvHZIcIP9JwQ3xIKJSTJNUEn+/CAhPCECTFs5MSysSb5jnWWRImYF3Rzx8r
-yA6qVK2G/VZYNlQpRLl48odyI8oVDAmTYoFuqyeRWwqXZMEszKlgs7GxvmAI23OP
-OexaSBIBN5oFLLpyIkzyiYWSMLmJsguS54T/I8l8mTDkNSo4Tp2wlCnsJa6gV3nJ
-wBNIqTdxChiAgbaQ2tSmUglUk8VmNWSnD5K3wg6QIo3tgX357S2Jc2lp+ADlXSeV
-2S7GRY6jYURRD4qFRu/9PRB3E4x4TIL3CrJtA7PS+vsF9NQp6Gu8BI6VDAvLayvt
-KQwX6sIlttmJsiKNm2CtYvqW3dKIc+Ryi2ucojcgpgs6aIj9MUG1nlK20YW6lJRs
-LzUV6p7m6zCNFWVKor2mqtGAAd9laUaSMSbgaKtXPihgtkbMuWyoZDwOoLG098DO
-sPPSZ0tbrYmxyYDxQQQiqwWvfxS/s6UAqXjRUHu2llUnHarrdsjX2IQJ3v01Z/Wo
-Q3iWRn/NrQu9t1+2DXnVtjPgy2tWCZvMYvmmIdopTxqS8fL2DnHe3WGAHmN370lo
-FfqqWHwG
+GwUPIJwHdnMG5bROIDAVakx5yK/p+qpl6B+cll/E6h9Z4iQZlhOOIUFgbLJvD6QV
+RHz6/P1+v2aI0AolkgKp/KNf7kPMG83vuQdxSyRWQtLcN3ZmS9plOJ1bF4JGKHZZ
+QjbQIOuMUVUlGjOJ4sr4fnTlveMk1GLkCSp+vilKbHQmyNdI995fXyYo+iTdxcCU
+bE0rRg47SiuHnS1xYQwS7cRHbLDD8BwsPUdVSQqrUO1NxP7aKMaTyHNjzJmNF0pd
+bpA66QV+I2PVZarXJkjhN56IFym7UjmdAor/xLYjbgKT4fg+1+1Dy9a5HW+7j6qr
+tQMTq8JMv8ojiNSLGr6RdWeZ83lfy3I+k3pLC1R9m9slxwSRKPiXA+zxhryn7BAp
+sogpyaJyVHuUat/kO9/otkxFTcFKZC0ERsgMZ7D1xjG5EpNW7dOyOQECYYRfQ61U
+DZm1FQhiR36uuOMTkjghgmmAfzONi7jVFf5bwqcQBmfpZvqRnm7vmWNA/kYBzCn7
+nnz+7/vpPu1YsquqKh6S2IU8utsQ2ADKAt9eH1jdYDyCyZVjL+K5DJBVpJYAKkEI
+0pGdPqetxDh5oeT0d9/z/XXukkvEeYkJ8yxjZMoLYElU7DFoyPoSfWJWFCiO8Bog
+deLRMa0pkQuTRy+khiszjkHVlC4lWy2vLBLX8AO+naW/DtBzSJw3I8Gk5Q+wsLeq
+nbTMdM2csUJgC/Ge8n+6tprLZFiUgsAcAuWOHTRbj/nJzXQK4maYdmQfAEsCUF9U
+yXWUB90asdlC+CGFi+I1IJuNulEJkrsGQyC4HghaCgetZrEr1W30xZSwAetZ9/Qu
+gzYgU7JVQ94Moz2lPBQrHH9oNqJZwZKwBRYYr7gRxR2JOVK13ISaM1jcmB6yhO2x
+zu425cRxACtJARpahosCJasn8VRCbgT4a8B/hmSwyRkaGGYFuw9YKgX6cCs+iQak
+2BEjzwkxn8H9TUOth4lBYhI0iQfCKspqHKhBuVUaWb9aA+P8+1ii6JH87QbatUbP
+0o1PihRErc5cxg9R+o3r10CBDYCwhJR7yaCtFtHPrL0eUHVvEl9zsHh2pWBJZoEW
+WeZ4C77MnXyD1WhaMrDylqx/L9kqDjfLLZe4hE53HV46ZIKBIu/zU637pCsZpiU1
+Rbc870Gsk2TtRzFTTEpZl0hVFtDkxxRNNNLBBhKt8CIGGcvSeDnl9oSL+/5TifYZ
+pBlUR3q3YjCG5Bo3AW9G4LGUV/tniTsd10e0i6LekqloBtGmVcZ2xRoBqObc2HFK
+96p4R1H019y4MPvpcxpC1Q5Dgnt6xzpnW5pLX9hDK+ZGpTEaQW8T59oVxmcJdvXu
+SFWuo5IZYg==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/authorize_sharing.html
-Size: 3975
+Size: 3946
 
-G4YPAByHsdukmRaULz4W4ijfV2d7l0lPPaB/wLyH5LKdGFJ2g/TtKew9F7q0vrX8
-+a9RAuEZzyAdk7e5pZaSXPbuN1RtrlkcDolVKKz+RqJDms0dqdHQs70E+IGKW/9M
-/RIdUTG+bDCEyD2QfHhbBToacZxQzeU7NRgiB94l9lkXO8yQBC7zyz2GuGECDtea
-678btJb1dUjiUbakoLppR3TQJs5t46080+AfRROZa9wc3RJlzUePMWMUyXJFOZ0g
-/ye6a5i7z0XT0Suty6cWXedqvx68xpE2FWsA2NrHdKzWki4lGyAPMgeVH/GBDhK/
-x6U6//R+N4KS4rAfOyxz3QxEDDjYYrjCDwH2tgHegXqhE4WRZVJsKSaYrxxbnmYo
-q3JemmIpfvTHYtoVaFdH5wZYNDmpQ08WVQjhohDlYzpg/8EaLdihalvwQLw2DYBy
-3hK9yurQ2Ro+q3HLDvqQN0mom/3asz7JHKX5wrtWYrK9BfqzZrr7+32MMW1xY51u
-JF2Z839r36P+vdwzhWnUZ3qROcWtpRn/obNPnV1oIZ1jn4pFE2q+LTMnvonFMvrD
-+Bg23IhwjDUbdnaSAiPjUR2gIiS1V+MUrX6cmRTcTwSURqDFxgXwVLcAModPL8QS
-ehtFUQ3UgHAsPWgGTnbeJ8PHYexRNvkOVXcwoBF2trdVzezW7e2K7O6w7VhLt22b
-H12tSJ+wAkL1KqXeEGaeSiZDMXpHlZxwrhPaYy/dvhhcbYosKHWxpSqWMgtL5dpF
-cySUM6wE4ypvnXkhhipQu8B32w56pBVJwAwlECUXnwkCFO02sjJNVnISAUgATig3
-Fd9OmJASxmC1OMcYoLsyt9fzNkZ/ypCe7cupfsuqNG+Bygm/tb0FkPXg+aZUh+J8
-Kg35gOkADRZ8KaBDuXq0m1ZedJXvcGwVOVoxW7DacGQXW1Eb33zOfP/gw0Ud1ont
-YufETMyreWodUc1ikTyfGoM89TsNQAsbgaWpjkFTj/caWU8BZKt2xVad25yLbYkE
-iq4Iy1amVzXD1PJizh9pA7G0ywfeDknNZIVGb5DYt5kyJPhU5bGfPrKGYo7cnRJv
-L+s6KFkmD6tsqsQIhnMpa3fQ5ihHyQeC/kY+LvUgVcL+C2WEA6EClzxA2h+L871G
-82DovgnONz1f0IJBuxZNSWvnsQNTWIo5NeIsA7u2wbhSeJsBWNAwj+rH662/glI5
-FxAqPtCwvZG/2YR2sl+FssI2KSPY5t+nKgZQSQE3o3kgYNWUbXR4/QtqY6NqcpKp
-5Y8mmCE/b/ZLb/tP+IQ/ftjzHGO/+qdOixQ=
+G2kPIByFcTPdXAOTGER956O3Tc39l0lHShX4a9tSJ2d3E58nrgc4TUeD9O0pDCKz
+39v86VMC4RnPIB2TTailvvyE0pprFocr1SoUVp+R2Biu73QDLDwW5TADFbEaZtkp
+UacOCbgLvY+O3lsX2g3oY+QCiE64qkBIH5BcICqnQ9VbogQGR/5+2yEtRAlnhvCM
+PnCkevFn81l+fN8ZKr89kUnJlLzO45IoBYl7i4VKQ7nukT7SuTw5+CVKqlceEnSR
+RZMU7f8f5v+kNg1trixHhVJiEX7Swljbu33dWQ4zrT00ALi1zcmUhkPdUlZhmhQC
+Kn3CvRTIDuJIl7n5O+U1ZY+ZOBQyazKsRwCIaBBWBqBvH3PRWlC/y7BQulInjggI
+JhsOPU/TC4t22ZpcKXh0VRHNGeiUDfdcnMLZdIQnr/QCws8JId5ce5x8DqsJh9Ad
+15xy16oC0C5DM93Jen9W/WvlKb2Y4fF88vRqt+eU0FI4e+reEHvNdSPGqBImOxJT
+wrYyy9jhD5LxcwlQO5pQfpoc/SeEovahov7hPIHzCGuCOscJpPN8LNvGMXsQx2CJ
+Q4whLow6EokQyi6c7DgCCsaXbASDYFTS/UZR2tfYX/4kVPUReDDvEh5lX8LIhtVW
+IKHDUIS5UwBCqvRZgEiY7UsBiA04hnh/CTtTVfCDwyiSNDbKHp/WGZ1wLDUn0SbI
+d1NrEMeX4qnkpMirR+ZfIY36EDiorOAn2lo7HEfbM73tmyLzWlocqRCltCJMpbjQ
+BAnHNGjCoZEXMf+OqMkpnNOx+AwuliqMgAoSIEQOz+wDyFnR0M7UQYuvI4AcgB+U
+mNaP4sWTwQSOri8CDOBc6c+T+ScO4FRAu6iXPZ1SKgUKNrY6FSkIrHvAN5kyTsuI
+pHrncUw/dYXY0S/VtmCsgNv/uDRtCN1hQytgV3QHHdbrLae1yKfMdfF9dUXv9WzZ
+XzO6rop/vUe5Cf8uOpkaPTXV2BohK1QEtqY0Ri056JQnxAygampDtuTSVHk9xRE4
+OTlsiwT8qektWVJcEdeZCxTt7AFXT2R684x1QFyf1bQ+vts7h5PoCQsoIShtIuE6
+UPde03bxXg0bRmIQwishZZeCHKgoWTmG+EjGie6jFJjc0g0JPyi/Ld4/2uKKsq6a
+ct/ARx6ab/jZWxNWn4XoEgnNNjkghSUXSxGlGMR49QLF0OZ3gDtDO6oZ6M3ZI6kV
+WwQV7Weo3nSLMh+bhP3Ktx1GoBvCSCwxVfSCsgRAGA0AHLv87KC9q3/LwQGTyQym
+Zns0SgxVudXNvG0QoYKM66NfToTQNf6e08wJ
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/compat.html
-Size: 3133
+Size: 3150
 
-GzwMIBwHjtuQ20sgOHGP+fHbKtVZT+8bEFcvxAsJ88GsQfr2FHafwolI3//9VIka
-U2PRmiT3vdKQFRAlRfIjtBN+1k+rnW/UnoP5bkepiL3nkQ1UwGa2TTQluhIe7RUd
-RJ4B6ruKfJILN2VnyiTYqV6I4F38utdTw2IfkLB/88gdbuAebG1+6+n8Z9LGepoz
-pZ2EOx9fpPBJPMskEqMkDN7hsww82e6Nm22uT4y7RGH01o8Gl+NmmF+vC7x/UqYV
-bHGWkfL6W4C/1U/bdui2c9/EYGlLR7G0dWZTII2Q702OJkYGNzfSPH7AoLEuGWgg
-uKpOwZOI34u0lmez7izDUICuhAKRIx52bVgOESCqUoTMINIcFt0Yn1GUP/zV60J/
-RT0E6ESllEsKx6V79UPI4zsVI/cGiIpwptcsLHhWuxkohT5YisdLD2UAi4fCd1AE
-Cxgq5WWw4k835j9gk+yR0oN1v6HGziU2JQZl4I4qF0FkPeeo2J5oUOVcO32Tuz6b
-xSoOoOX/weuPfbzJLcGcUhNNiOoPvO2EhXOOeBasHbIUMcgCS8ss0sEkyCJ64y/y
-Aot4hk1GuQqexdpLMmFcpC2ekGpC1YnwXA9TeM0kcaSdjiaMui1AXDajmpCd0zfM
-KmbXlW20bk+Xu6RNqxzqnMJ7F96eKoYHIKkPgi1sVWOLdpCgtmRXQAmQM9W+BMCB
-DEHj4GtsiNKSnQhEITeILqdHrE/QSlFQPKWVN82Ua6TXDAzFPsz4tUheDNNOdLLh
-39VfXGg9zJnCR8sOlv4OMeky3oDn4OYrMYNv9ApbFCoUmKBZSYq6U7v8hTOxLOLB
-tXmIXt3/+pXp12GwjIn19DHpFFfwEYUK+cQmceBtvS+z7kwD466TjQY=
+G00MIIzUUc3m2XyfufQ1Ki93t6nfshwLOaFKnBKkQPZ8a29T4ow5Y+8Aa5K8XUop
+qrSyydXmadUiNFiPVsw3iudgvttRKmLvaWQDFbCZ6zKbEq2kqkxXwPvuZ57HSfXT
+oMfIE0CfN0FybiC5gFQJh6pbIoQhnt/qw4HFXiKRITxyDxy4B5+b1zUT/7u0sZkm
+UalXTcnT8VYKsCQEmVVilEwLeJit0NQ7vXHTz/WZAzFRoL1xVYbLkTUu7s8D0T9p
+yw62OOux5PW3AH9pXI7jMm3nXuboaUuqBtNb5z4F0gjF3uRoYmRwEySC/ICFY116
+oJLgqjiNTyJ+a5a9xXbeWYFLCaYWGkWOeNjVYylEgLhLMbQHseiwOa05q2rOH/76
+86C/pl8SdKJj8i0Kx63w6seUx3cqiu5NkDXpTq+9MOlZD/dAS/TBdDzeRmgTeDxk
+voNmWMDSaTODFX8KJkRgG+2BNoJ3D/TaucKnwkWbuKPaRhBZDkfF+sIBlS/V1SF3
+fT6LRQJAy/9LNB/7ZJNagimlJqoR9SF42wgP5xzJLlg7ZClikCeWplqklEmQWfTG
+X5QDHskOn6x0FTyztZd0w7hI4zwm1YaqU+W5GW5pNpPEkXY62zAMtwBx+Y5iQnZK
+3zDrnF3fttF+PF2eirHsatic5HsX3sDKhgcg7Y+CLYxXY4uGkaA2Z1dAiZAzVcdE
+wIEsQ+Pgq3CI0pydCEQmN4gupUesb3Bsm4LsKa20aaZsJL32wHLswxRgixTlsPVY
+J1v+XfyTC62HOZf4YDvB098xISdPNuA5uvnqmItv9ApblDJkmqAVeld1p4b6C6wa
+yxLeuXoP0av5++evTL8Og3lCrKePSae4go8oZUgnNokDb+t9Wk2s2uK4qSFfDQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/confirm_auth.html
-Size: 3562
+Size: 3517
 
-G+kNAByFcTPdXANdRPnzM0O+uvR5CbLMX0YHvBbQP8SbmytOZ5QE2evrIaMkKb7/
-e905lTiFELa5yUvXVIzp6ljz5Yc1pkOoaoWWGInJaLMDaY9j0725BD3QxNYVo7Il
-WlCn+XpgCJEnAH9vQFEKwFnDWWPcmcEQpfB8Zx+W2chwFzD44zWGuJEAUq7eorJy
-gg3U3nS/vRbkvS51boy5SB1Iybs11tgpyKXwZnNF9bJLBNEbT3PEaJqdyvX7QfFP
-5n6JnP3ldXX9rZh/tOy9f8i8p6heK+3AdFqFrQFqI+e2mtMB6qJf7XK2PFumXDKG
-M0OupOcWr5ybkTSvVT4Bzpgm0Aw8NKhSoRhA8kUAz4W6almzYVnu+xyBRHYR8mEe
-QQL+sgBP68ihiZKvOSgvUZkcb9zw1nOEsVTAUz9ZqjXrbQMcRasc7d8if4TuPRM1
-UOhZlp4QKnxwDjhfGDFXsLQtN7okHpEQ6CsHp0S7AsiISnGUpaLmVC19qSp5UWh5
-qA+1r6C8EFA5p2baF3XZuJ1sdxl/9iTmT7zsoSscyM0gRI6RHNPbo5JeFj3F+mv1
-YY7F3Zd1bJXim3un5i6NH7N/Fv87RYfWU1fg3CWm4/u2w+gk7eNurX13pu6IuaK5
-gHmauQRJoBVjIJauxaQO5Jzj+cGdzX55w2OuAGFHUbhE+735EeoqBbJ3xay4zSza
-Y8yiL1Q8otrykJMJzQX8ZyFDXS6QDaCktF6wxmDPX3L1hv5Zk0C0oqcbBypjY2ql
-BA2Ju4Xd5G2acmxkAPneM56T3n8Nwsu3hQSxBunLQck6hgA6MXUanmJAXWGPYF50
-LKzYYqyBmKUDqsYKdvq8CJkqB2kowJK989iP8Cp8cAKKIMkm5ZSEbi0zlZDstQS/
-NMEYksPS4/OAwJS8xOoAhzuEPVx/agiRHm3LLlIA1NkndewjuRHkG4Epf1cL3sb6
-hhiqdCOw9RlxTY6xXqp0pqEC5CCu5qFWPjZ/cKqJuN7Y4lZRdnBKRSjmCx9T3FxZ
-6E1GkcjIuI6mxA3U9F8HXfhx+KVnmPUgyadf/XYuysLSO9hyLlvtWX2tpzcXc0pl
-AiiCVF7Cowm/CusGQ/VSwfCvTgoz/hUQCaBEuXPcB34ZxMEsfJGBVAnSj7hprL36
-dy0+XekSPnRbH6RdeXbMKUZrG/xnC+vPOn10weOkIx89iM5lLgMm7wTYK5ZJbc38
-NnGb+OyucISWGlbviFbi5ipR6wM=
+G7wNABwHdqMP2R6UjRFWe8z/tjQfo/58/ygpMleEO+FuC2wnLCWVIUH2+nIoScq3
+9nt/prJOVQj7cpLdR6iK5Ig3N7cEZEz72aqvZW0Mte5hFZXAgX3T9THagYpaYymi
+L1GfrTMdheCvfnOOqf51YJSRewCg7wLrnCQt47KyIKrxEoUbPPT3umpiGCasDeEW
+o+BIAqloS0V0e0KnqO11Q76cDlg2dmYpbUo2k3CAyyyFKZtuuXefy4nXYSKZvfQ6
+E4auNuj9542OIOJ7EOd4Gcmu70X53f0c450TzRXx4mm71vPK7hrptZ6VNy0TVJJ8
++cvp6TEytenK8g26znrr9iuXZig5b9oN8o1ZBO4gQpVMm4oFSL4Z4IlsjapNh2Zt
+nFskEmdG0IiBBRnY6ywiraCAGnoAR1JZg5lMN9TElUwwhAlJamdLXNPRVgFatEnT
+8Tnae57eRNEncqYmfkZgeIfLON8Y4gw07etVL5l7YwI8SyMosqMBnEaPeVSknARZ
+X/u8qXku4XTnnG1kUCYhUqGgWj0W2XA8Tp15Wf70RQioeB1E01uQh4GQTJECE/v9
+klznIubaWx9DnXOH2+dhlT7FoYvmPus5w0Y1WwPUhC20S2HH1qPdRE1DS/WP6UzT
+Ec6Q66BIwfMw7oOlZGKHWXrJAV7TvNx90kwr7BKV7sSovsteyH7M3bM1OVAXVixW
+7guRVyV1xD8vUWBbBq4vyWMW/4aQzqlNMPcQqGH0hFZKesy1sNeZXJ8FyErTWwVQ
+HavbaPh9SgwWDpN3XkpzvwGYbLMPnRj0ZUgvXwAS0OgaVGvIIivj7meKp+6vBuAV
+jkhmkWP/Q1djGTwnP+5p2GZOXOfgJqXh4DixRHSZ2gTPwwcXUHJyVuqMmtCjBQYD
+cqdixKUZprD98ekZgiCgGpMdQGEDcrvs28IvtIBXDoFP74NtWMYxcAUBn4EwZf49
+/eeleSCMyX9D0Mor9Iwvxd5MvdDw6tPwQDNoKd7l3jhxIuQbd6L1UhaNV/SGGbZM
+herKfmy0FInbi/tQJVZQZYF2u/BT6/ELzE0bKafNfr0U/z++iSy03tE2H7me2J3O
+tdYJQuEc7TyeIPiv7HcosjcDxT8kKXrmL4JRR7vIXGIY2HWQALWyjT61DqjJw6qx
+/Cu+2YnY9g8Rw+8rS9Irq2tyHUvrJ+J7z/7Pb+/m3UHqxIcvSbbJ9QmjDyLYi1ZI
+r838zm9rxOIuMkENDUt3hpaYpYpsyAE=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/confirm_flagship.html
-Size: 2356
+Size: 2393
+
+G1gJAIzDuOFb2sXQLPEgdqb68/cuk+WxkpMPgMPMD9EpWpcge30/fyyBR3amM7yX
+F1G0Ssz/r3O0tmjgBKUJzmQ4214VCSy9CBWvoh1oUcuauXGJ1jKA6ZMJfvYYwqzA
+3YJJRu4ACr0jUYzOGc+buMIrx2cHB1lMAArwzNSEW2syRmYegWXS7SEx7IQkPOYr
+TrHDGZgvBTM3lhSOQzUM2H1ziFklk3zcLFLNAjl/OcIeez79SxZW53ezbQKIXzjA
+hFG0HZJhv5P4n3RVT5zSeT27/hblD02qeV7pYz5z81obC5TlGwVYXrz+IaI+Q1T9
+3DKP6rpgkd6yOCBdMfZ2c5dq4yNU8NYB5kOWVsQQLJZ/jwgzCLJHYssONkcXSCV4
+O1qF0hvwTW/mnv/d+XwZnogKYCIVJckFDyP18oqzOKxo2uxkKtSAXgjcAZg88UNZ
+onM+1l8CKBA7DleIFZO+lNHWiVTWx9ZDf/ikRYFmwCtKKiDaXvM3G9T5S3lC5ZF0
+KXU7OaZurpm2zALxvMry1T5b1Lr/jV049DLLw8BZJfjHhxJ3sTwvdfGonL5YWWai
+MFCOWeYMzHWAhIC6DTVRAi8LEOthRNecA6uNN3JZdOdG/Hgjyo+5kU0zUd4KJcCk
+Tw6sx0oZqUzB5nw+QBs5PDjgUZtjplQ0HhZNbklaAcDnc23BdUEyBiAd0oxFK7Q+
+FQfHZfpMzIxPkvLpcHPk60Auf5ZM5GA/+sjSPkarxI4Fz+AsPywDZlSoRsYT/xLD
+6uubLGuhqiB2W/gTmdsF49KEYkX2YzWxp7w4Ty+slGGwcdshnznPhsE5ju+Ca0OP
+uIuV1ZlMmGm2eJ2//eJy4P/Y3RlKKMPRLshbOx6tp47PEnieR1Z1KvVbF44hYaC1
+6MNoZHU0QmNl44Mp9P3eWnmMMFk3MmW5mHW1inulcS7bamMA
+-----END COZY ASSET-----
+-----BEGIN COZY ASSET-----
+Name: /templates/device.html
+Size: 4228
 
-GzMJAIzDuOFb2sXQxPIgdr5qe/cuk+Wx0otTABpmDtwncq5kuUH69lxaNaZCR7k4
-Gydze5CkBGDxPsyqPB6NqrCqRtaGONteNUIzeheoSRbdgYG0FbMwLtG9zuPLLaYQ
-eQfU3xUxb2bTqbiIy7e/dxhjFo83xZlPzTANploUXTE2Ol3sCEMbY674V0ygoxlY
-R+u3MFIURplq9sy8H0CWyQLfNkuqXaPgHk+zQG1U/9Jl042FuTQRoh/dY8FgclRZ
-/v8h/5P5eCGc042Iuv6W5B8t482m18e1W5i1WluvWaMrvurLqFA7JRlmVGaOeVO/
-hyzpBaMfctW2h123XJpRQoVsDOBa62pBHI+E7ilwl3ibQmzaoeTweIywZ6lvI7HX
-J2IW7v49f7nPRYjxbb6iTpQGN0NyfcUcDTNasjCZcZjQS4EXHpMIR4uQhjvnQX4E
-5J+vWNXaFP0xio4F1PAjqH/GMSP3CT98NfbLaJ9Gu5hXmv9hj9qvZTo1a8SzIVsb
-/3dLUR97VM0IMWyFc9sVNGFs9bDJ84znyDy34uAlyNITDaN6b4UF2/HSIwgwjyAn
-SeRAETpTjN6cc6DYuJEx6XItsroR6UfKWKkd6mWDDeFvFMfMY3+UFBOnxZ+v0Q92
-YtRRmT2lZLQLgUzpSAwCSM9DsyGXBWIc+KJqx1IPw5JxSNvyooMFGUVSOdNtbrxG
-nLxvM4mjXeSjC/JoHRcTNXEIXf0R2CZXoBwVz/pbyM2zvuliFLIKYt9GP6HcKhiX
-HooZ1Y9ion+7+kUf9Mo8sElnwdotOC0CznZ8GZwbetQTzFx1pTP2W7kt6PcYvAn8
-j/YuUAKHZ+aNb+V4jJ08PmvgMOdZzV+0b1vuhYZRrcUfrvuponEVMxsapLD3mYky
-dOw8Ln9i0mpxJUyU7plGpyyr8wE=
+G4MQIIzDOIa8tCstTiMk6TTVVv02lWVdddLkGbsvqf2FJEvgHEZOEKg9v28tEyZK
+RcX4WJPUr+pa6J0AodpIrO7qmeSAFbAFFgqtvndanZEXDRsjtds0a7ziQIHzV9IW
+wMCE2FKMZlKifafKJPR+9xbCIqrPCXOMPAPYaYcyP8KC5ExSuR4msyVKYTDzH/V0
+Rgss4cZgfzEHjviXfG7GaH5u36+qv0uYSdWUss7rkpgmY/dO8kkdinIMK53rmcUv
+UVV94apMF010SDEeD8j/k74eaLO3sF69f+v4TYt6WTbyvXI0y0zbVU0Y3CnmZEr9
+1I8261AnhUBSPzF8miwOYqbLq/l7LDjbPE7jjClb6gxhxAARCcLCAPR9F+HnBro/
+g0LoIqQ4FASTtUvPS+lZ1f5MiJWKJ/WV6WegTYcO19DGr8v6DzVo0BLl0aHYN/uA
+y+u+3uKMJnezqyRsHmvP7tcHpzrMyq6D6pKq8/B91Oe4INZXEPcjVfhi2GbPRtqM
+hh04fUu/Cc6SiTwhFFGj9ZZ3mOZNp+RGh0SjWepaNtWp7IOtqqL2ONuE6kTeKPsq
+y7oo32l0W4UsfqpJjUjd7dTQkMRz51/GqshdjNFMs0nTXc6ovtJFuMbLOvJC3lbq
+o3td8VIUcosbpLLCd3ni6ltdwhKZGA65mlE1d7KBwmxompPHL/g556oSc4HzTL5u
+tDUQA250YDjbdtGBdqSSuvVlHmA891a2ZGcV9hN0/8REJM9YiEalV9RKB6usG8Hz
+5eW06UyMNjbH2zFo4MdsmBhMOFOl4DRYm7bcqhW5mN6OT2R6BRRitYbct/tyB4Jq
+qL9Se6oy+d54X+/TvLVm1apgC42eswEZOxj8PG6w5IVo359bcjFTL9Pkxx5lGkpT
+JtTZ+UWFQS7rQCz/E9YKU94+0NoIwtkWR9Np2xroyqfKdDquDceSSOVCmOP1Onc9
+jF5VsM3p7vd6i66kHc5OT+v68OfpZ/yO1cs3J+6q0vxm64bDRmzjooTXkS6dT6pN
+JFesc08hlGsBQiINfjUvmiVTcqwrhDuXtEgf11WLnkb0FT/UUhdwn8oh4l+vslVl
+AogDsVDCHhdcC3VQlK7G5D4znK0IzHRAIm5LUA8xSiNkHKS60VGWj06cS3QogS7A
+HRf/0KNmZdrtozOw5ySTTK7rAFbApr42s3hKU5lhCSUGk1OBMQgjtHwbTc9pWZVz
+E0XYQxcC1RcBQ2WhxPcSsgV32xTUtTdZ167lJVYlpbrJb8Gahfp5RiWoK3maEYbW
+qL2DKxkjEvEqD8hCaU4NTs+fUMYBalfoIvyjME44JTdYnPKfkhXz5jSAENAzUoAN
+rmfopXPyLQ/Z1Os9HztQBwH1ej5MqtrkzkfgQ0V40N1bG2HV+RQemuiNAw==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/error.html
-Size: 2474
+Size: 2491
 
-G6kJABwHuSnzmmtBpYh8DDJVR3uXybI6vvNAONEGFJco6ZN1CcKzlzAgNv6/++nx
-rRMTwjbv3p8hQJJmROkUEAjPwooZORuD2fIrBesk1x55oNValZm7LNEsBfgg4Roi
-5wD7nZKuiyaFeAmP7+TqVMFyRXJmckNMrWstEivIRqr95cZkxaAr5qtcQYcVCEOj
-mXv8Zyvg3n3WmvcwMt3sIwSN5MLiQl4UsezwEt0nXY67dSYlyYcOcMOiwWm++v8/
-+fwnbdk1Dr6sRZj+Vsvv+lVO0xyONZu7SbVWIBGP2ykpmvrdLjNy1923TlE9QEub
-UxZes7XzY5bBsA3DccoPRkUuliwxY2RbmZdQS3Ddlf0ix4aTB1nJS2jFCpktBBmy
-SXZf2iYpejQFSuEz5TZER4TvnAE7/MFp//9Hp00/+5HAYq2w2P3laxO0W7EDi3Hc
-2vmtspMs0LeTz0LL7JR6eZC3mfRHtqQp4luyxPLlSDj8fPsprZdVkPnU1TRziC5v
-nx0wUZtZz07PaWnJofrLXJ2gc1am4Z5SrFGxIq3nz0yyc8XXYWxyaUvak13MYiGW
-CMQYZ9GxhoF+ke2uArLE18oriOB5VqrTRDv7egwALPEPiXmlwmn83DGnRJiD0nJB
-OryrmmSz0S/s9OMYM+A+TCwyaawKkMJ0mFHuAKif0ERBji4yscQN4e0AACUec9aQ
-2baeVEnAYXZse7zO4qPpJehGPl3YKrA2n7Cobba4Z0e0YrWuoWA4rJeecwAWnlMm
-IjYEyqOnAMxSkRn52af7O4DFI2e1a9SFGibvhCPgkVmRcwEYTwXO26PXoruFUkt4
-FB0+MwcmYCpMjkJbdyE+KMZ8ljoA1zU8IbLz2YIBn5ZFT/zOkVAL6t3zI8sAi4rz
-+bTQLGzR/vB/pnA+Zhgc2mneWQUGLs4ZTCQt
+G7oJIBwHuYn1lnbBFC3ycZCZNuuvqTSaKyMnz9R9RR2QdUR2ULMNspfEM2FAbKxc
+6nCbAQNArbuVUkvR6ZXW5KDSCsDpgIIQGJXgbPSjp9SzRxooKqs0c08lGq4Ak1l9
+793FOAXgOuEVI3sAO+m/Ec0VTgr5Iu7v8YMpwezTcmcTs9iKVzECO5aVVFd7dREH
+WXfsLb1gB/SApcHMvf28GXD3PrzHJ45MNfsNQSO5MLmA5yKXFTb6M+qy3bs1Kck+
+cIDrJY1O+O93W/n7J23Z1c1kmXOU/lbFb/pfTtMyLKsz9xZrLUAiPpcpKtf0u1Vm
+5N6tdIrqAeJsdvm3K5Z2vsvUF1RhOVK6IEpyUWSKWQPV8ryG4sBVV/ZzjhUnDrKf
+l9CKVbIrCNJj+7/uZ99EggfToAxAhryn6LCwyhmwyx889LstPTT90neELdgy89Yv
+XpugrZJdWIjl5s5Pld3E7+Z98/E3YYZKvHzp2wy6zZK0hXxNpljCbAmPn27/pPVy
+gDFWnU2zDNEF7rEDxilpzeDMkBaXBKq5jIPxOQdlG/JJxSEV+9J68owEO0d8H3eN
+nn0Jc7KDWfwbc4j/F+NMWlYz0CxnvytALHlaaR+Qd8lSnSY6v3IKABTxX4qBif4k
+/uiYkCLOAWk5kw57VRBUEvyCTg9HmAF3YkORQV1VeBSkw4ByBjz9ZEYHPeYvNpT8
+xnjbADCJx+xDYLYcJVQUgNjtSg/YUfw2vQThnF8XtIqrJCMKNc0Sd+2IXkTlBgmE
+x+C8GQJD4TliACIRUB4+BWCVCs3+++T7yzPA0pF90DIXZS2MbgMdwBJZkhN/xFeB
+p3bpxXO3TBoB19HiE8vABIwEyVIoay3E13/l4Sx2+K1pWHGk5zPl//NRGPTkTbaE
+Xqzd3WvZA1ddqf9RgFmwufnBr0jpfKwwExifeWsVGHh6ZzCRxAA=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/import.html
-Size: 1409
+Size: 1426
 
-G4AFIBwHdqMP2V4GgryMjA9ZOZeyN/Gbou2jUgEqQ4JQ7XWfwp5I//l7hxp4TL13
-bmMuKlzj/vf/poZHY2nggWqCmYwt9Ts26GMWYWA0UKNY7br4okRb5rFXrCByAcC3
-h/GkYL5xzGQIdpq1EMnu8OtTRaKW9AGEfX6SK9wE6WHn7i/e5/1Hcm3E76jEkPXr
-EtNkZuJibpZFQSY+HYNlzCV4bksiZJ79MXVljPRR3XlA/kkTWrVBFotc6bcU/qgK
-47hMu8YXzyJt+Ih87RbGoFKcfJJa5NecjTab1JRoWYonnyTOqChiwB+i8gbvwqkf
-eegiJtQt90hpTA3gAyd6queFqZ0iGE9FdIwih8+JaXUMlPzy786D/nW3aN2AJ87I
-gMVI3EL5x9NvmuhY1Kj8bJTe4xDfJebGgAgJiImzjUSjkVnB/L44GuRIAPI8tFVC
-PVv5KUDeJRmclu7MRhzNVnxOPkWj6aJCBxnUzeLink8UYNDDOXAfN8ts9JqFo18z
-sQW8eM6IQsl4vw3mkh/8AUlV6pW3PsiEa9BXKGXIvUAQCvZvy4mxhJVlqiBp8qvE
-Yg==
+G5EFIBwHdqMP2V4EUVpGiw/ZapqPUQx+L9V+Vyq1cvnGkCB7fUFl0dpiJXRyu9vB
+RKLJ3b+TrJPEKiFDqJUSsTFc+07QPnFhaMgGSorlVd2sRJ3aZrpBvU8P7w+xrwFL
+jJwBeL5FxslVkJxgVAqH0mKJcAzy/GOXWC3uJRQZxBNfAgf0sGctdZ937fRbi2ei
+CpFl1Vyk9uTk3hiHYSggR1elc0FX/BILqie3WV0eRR3K9f9D8Sdzv6hNZP7QlX5L
+4beW/XGcsiumrsy0lh0QuOU8J6kUIJ9EF/1Kvq7MxjUFrZAE8ifkGyUKPwAJVNvh
+PXPqe9evIUt58xsSFWQGntAJT+WZY1SdAjGXxU8fivwckXfLG2x+ea3/H72m9ax0
+I4CxhgZMhfnNXaA8fpPiZ6pCa2h99B6APM9B2A+ICEJunJwLzBVSK+jfp44aSoLA
+wC2VVdwroTYETl6jom+1tKc2EuFtocuoSzBXdD6hAwa1c1zc6WIFBCwSOeg+nE9f
+V4saDlQ98RxfYlGJTMnAtnUVm48fIPqFr4XtExlzDXwlpTS5FxG4g/3LQXib5cow
+VSitcqoIzg==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/install_flagship_app.html
-Size: 1617
+Size: 1634
 
-G1AGIJzHAwAAADIV4xrHgyk5l7K5+oztHpUqjrn7FPZE9p4L3cug+vljRjKBAeiP
-t8iyrh+r1iw98APVEzyTseV3jqhjtEokAxnJ/JeqEnXYx14BDURuAAD4noB0kqrf
-uB6dUW/HtEIEs1O33paGchEXoH6/90Qa3AA9GCt2fV59RNcybod9JTYpHTrCAY/N
-tQnfyximu2qMOaPX2xL2mQd/TF4SIpV0dx5U/6QpWrkpmd63pb8l8Y/qYhyXaRe8
-1EVa6hH4azaJSWUywCVhifxCszV6I4oCbU+MR57AzvAo9MQfoJoMeMdW/eoVnS+Y
-usaeEQynhvBBT/Dk14URWgUEVBwcdezhs2xaMwFK/vLvzgP/uluMahRPRn0NuiZv
-6v7j4TcmOLoMZ35Wh3cD2XeCuXoigpFsYmGj2BjGRhjQHmLBC5R9v1JrvKfRU2HR
-tVBFptR4bJJeFGvKE+QRG0Ong68wEfjTd4hKdxgIMRMUl32PoOkXRFp4z7cDIuHb
-xjAIpGUdS04FtrsGxSNVT/rsd9gBayjySv5LFTMxvq4/9uhmJDZjGzBEepY2O1zJ
-bRap3i5V0W8h5tExzhiEmlhCzl3NbtAwp9sOvlKLk/JClECNBF/LSVDpbGNgrQA=
+G2EGIIzDOIa8tK9Y4pCVc/kadRO/KdpnpYqY9OlmGwSGaq8PfyxJTuAA9Me7jP5x
+tyrN0gFvoDrB6RDn3ZFqYPNrNAYKwuKqtleiSk0auULeTw/vD0deA4YYeQbg+SEa
+nEwjktsIKi+HwmCJSAye+VddYlksS3hl2DyyIXBAj3jmVO3enyftt05mYhIii6q5
+c7UX6/f6TFiGHO5gmuic045+iRbVWyeNXFZFjcr1/0PxT+Z+kc3J7GEq/S3B31r2
+x3G6bfJqx5lWIgMOt9jMySU5JCfxF/1Svq7CxpSCdsMEsgf8GxmFHYAEqsPhPU3q
+97ZfQ+PlzW5IVHAz8IQf8ZD3HKNMCtRcWj9tqPLD1r1bQ8LmL6/1/5PXtJ6VNg4w
++8MAhsL8ah6hPL4T6meowtzQ2uTVAX+egbAdEOGE39g7F5grpFHY0Q5m8RHk27Y0
+B3xjKKjefl2k6FtVB9OYbMLaXJdJl2Cu6GxKhQuWoKnqxfSFR5GRekX5fNukasYN
+acXoTp4GTOCHD+ISK2aWngZx/A0GMjCNWl/8jC18RVVRiqvaPjUp4F4kd+TqBFjT
+FHAkYdaOOy7sepxc33XGftvAFNlgRHahkwqU3Mz+B69ztnCLjzzhtIoQJzAjwZeD
+M2RfaJcGfC0=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/instance_blocked.html
-Size: 1481
+Size: 1498
 
-G8gFIIzDOIa8tKe2xCGbTPM1iuCh6+1vpTrGJJcgVHsWrWnCzkYIvbcTYLRE95+w
-CreqCsiyUBXW1sjaWK3XZWLUgPYukA1UaJYy06ZEUxnAL4cOIvcA+N4hn2QdN06y
-GUc7RS9EuJ3D+tTT10uEAMd9+SQ63IAeziqaaZ/3AXa9SNhRrCSX8tQRYVIxN8hu
-lHEvH6xzzHl0tCWKmXcOsL4iRVokw31J/E+6qtebyVyuL/0tjd81qeZ5W3aZTYdI
-qwIO+VqZxWSd3BSS2CK/3JqlQU2GglbieOIJcUaNwiX4A6rd4J28+rlWg8tC3fAo
-gVSWTuCDT3iq14XN8QokVEqOThnhs2PZCwZQ8pf/cF/0b4ctNY3xZNO1YNSNbjb+
-gZ8UqTGaytpnnfTuFuldQK5LAMEjUmJvF0uXSuiC5felfJhjjqM322wt9Xj6sRp6
-6mIJHzJ8jZQPKgPpUjqn6OAVxG+ZrcRFjkBea04NCw8MA5k6PNu2KicdSG0PQCXo
-bXWzMzDARkkssdcs61NAL0n1QtaCyGY23yIgCkF2y2zKWQTnoeiu/isFrFOtYwvd
-XFrtR+aavtcw/0xFIYOvEPAWCTsmsKXAN+uFgcZeRx0iDQ==
+G9kFIIzDOIa8tKe2xCGbTPM1iuCh6+1vpTrGJJcgVHv+8/u5zVtdCPm+eyau1ez9
+v3lyIJHEqocCoVZKxMZqvS4Towa0d4FsoEKzlJk2JerMACYz/X76ruu8AC+HHiN3
+ADzfIeNkHUhOsirHQ0W3RHgGB/6pp6+XCBJODPJJ9MABPdxZNNM+7wP8e5HApGhK
+Tue5JMKkcm+Q3TjkXj5YJ53z6PBLFFVvHWB9RRK1SIb7kvifdFWvN5O5XF/6Wxq/
+aVLN87bsMpuOmVYFHAK3Ms/JOrkpJLGJfrk1S4OaDAWtxALFE/KNGoVLAAlUu+E9
+efVTrQaXpbzhUQKpLJ3gCZ/wVK8cm+MVSLmUPp2S5Dt12QsGYPOX/3Bf9G+HLTWN
+AWbTtWDUjW42hoKfFMkzmspiaJ307hZxXmCwSxDCI2JjbxdLl0roguX3pXyYYxak
+N9tsLXWB+rYaeupiCR8yno2UDyoD6VI6p+jgFRbQMluJyyABxdasG1YiGAZyeXi2
+bVVOOrLaHgBT0NvqZmdggI2SeGSvWdangF6S6oWsBRnObL5BQBSCMJfZlNUIVkTR
+Xf1XClinWscWurm02o/MRn2vcf+ZikIGXyHxLRJ2TGBLga/WCwOtv446ZBw=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/login.html
-Size: 5991
+Size: 6807
 
-G2YXACwKbOcO4c7lNYIo2LyoRX7vWvoaNS/+J2VXVsR2Xy4zC10M3bE3PeVagJkN
-mAiaqkH69uwmjJGUVXv7eYrySITESJRAmduecOkdKabZ7f05WWISDpW0R3oKo7Ax
-Ju1l7oAD0vxRYQdmuR3cbPRYoidkl7o8YIbIC4D8nhDn7QzSxaNU/c6cnJMk0pvR
-WYwtZJrNWtS+EtpY6OIoM+wx4ZX4iAk6mkC74cg3elAUhkzN3gXz8QAZQ+BHNxsb
-oyTS6vEOZUitH7+ytekafZamRNHox2HOGJy8iRvXK8z+k74ZMpd4tvze3/X2wa4J
-IffE4W/0qdXm3VVWf+JXlwnC2l2TYGc0J8d8PM4rxcYL9XOKD6j2uXUrtekTVegv
-A3g0CVfkZFOSHraYWMmuXChCoEHydhFePGoODcgZ+KLNqTYJ1hXOa3sY8tALTfKk
-XBb4f6jFIC4rQaXaySZJkKS9yL9b74N274qVEA/b09DXuD8Oy8AVG+0IZBOEyoQK
-YkMMSBbk3XyEp1IXMJe5XnQxlIX45vcxTvb6Qnefdw2RfVgYYqI2fx7bSqooAAJ/
-TPHLWh4QLsQmv+aRYyOAYM1PjM7dTaFF4B/ds0Hma4zGIb1CrB5AWB+dotcOY6Jq
-Hkf0iK++2PmtDY8BGQQk1KUN0HgCPVMZExY1GevKL7jKkmKbvugDFePqnXkyw62L
-3yRSGrg5tpMqbJB3VLXD0zwXmvoZqZlUw/aYKSWheB5aVhiTFH5TN6NRZuKDE46J
-EXuckDyvXfn4OpGTCv4J4k+BbZBou9tpXj+4G9cruuvGzAWm2EYz3rDNrK9L4bcZ
-0N/IKDtZ0hd9OAagzfWgawywaANtQd/oDX2jGGo1vXKy5RL1K5wcbJAvG+gvFtQc
-Tz1usD5VqUVRRfpNfUr1MXqmfoZDwg6rJBBbkBqaL6fdVsruafJTPePs8WKfk/CN
-7IR0NXrqLYMCUbz0vI2gCNqfMGDxlbczVnQM1QHdSq9ggh1d4EgTCh/yqtxJEhN1
-1t2ki/f9fr/QZAN1/9ybbHICtrbtcFmXflYgSVmHCa3dkGfXFdFdPm/cRzPn0AEZ
-iDx2i86Ds1EcO3TT2LAzZcXXuwhS+YiW1DPN8cPw8UpeQIFfnv3576jEbwhk5+I6
-UWmN8A7bM9gnIu1AgFrfSslQqt13qzHPdRmMunzPdzskEsYzyGep1tG/rirC+9jM
-vxoqDHr8APOExx1Jb+eYc2GSj5B+BV6YuVnShMyki4Kw9bmBedSFYYPAyz2jq4i8
-dCBkuCQlVD6Aa2HHk/0KeAEeoEVdupVJ7YCPIvy4IWvhrp4R3g86Gownbz8UhR4c
-K0l76+Of3HK2thHLY6V7zur86KEZc549slSnbhYgfSsmpaVULQRhuqZfV41IgzFI
-R2erqTgK6fVXS4VabaZ6uYOjaelpOWxLH+1t5yebGFF5VqNgi/cRpZ66xqpMGy+a
-uLAd/cILK0VW1XhDPS5ou9LW1hNPuQV1w5Ku2CWsy/qyjFRy8q7suHT+cKJ3nZKV
-wCfBdS/OAiYSDt4FkakHbRcnEDlTxHvnAlB6Ax9XEkFqj9ck0sgHYYNbyOXUkFVJ
-QO/RWYV1znjb0I6hPVjBRDySiAzyYIsFfusVbjZ0X7R/Blfm0dgLs0gL870mitjF
-KF0JNw10qckpLVdmrxgxFN8nYe2B97/e5FSrNC5u4G8u1oiLLZK2h20m6YO/SWTn
-7UOFkAd4tlResWs9SMwFECsYVz0lmUiOmg/txzQkpvmMWyIPJruqoLQ2VJOFF/V7
-ajsIIlRDsfgvOVJx6Yr3KqRWlbUI0qLB7+yT/sAQm/elA+zQkHXZm9LmvNgnDEea
-GDU/0/DmTzbrRNgQ1VPK7MaK3sX3+w+1T33lQHMgdM0tMgpHoCc03YtqWBLDnXU7
-fyMCsUlkGSr8IaspjW9jtyqbrtuV+c2/0djYu4TlF23RsFXSsU/W6J0gVHfYM4xA
-OnLv4Dlaq6LLHQ==
+G5YaAKwK7DbaCzvVJmewGJLHQpD/Tq36baovqr3wYHJ3IcR2hOE7rIzxAR4n0Tc3
+VbcFmNmAiaCpGoTHbm/27YEkzBCT37uMeZpGIiTKYQzKXP5M5kqytNom+btHadWV
+prClW4lWPIzCxmhRl6sYizCgnh8t7MCC2rJ1lXOJDuRM0LoS/N6LlELMnt6wZOQF
+QKCfV1ecF7jNzxAFXrc/kaTS0pHxfGzh22IZozaeMMv1nmyJ4RRK5/HPWLGjCbRd
+Vr7KWVKYQ3Vlpvf9iBlTycevq46RE0m8PFco3kvxp7hZXZYX2xRY/JFngjC69nTG
+LzOK/8Q1HXGJN1LN9XdRfmXThDC0xIqv8rLWrpnllfoObEJC+vpQJZgX1aVlnhgv
+lUjHJ+IPLt7A+qW5K6VJxBX6twNcldgWZF1TkpzHGPpWvDEpCQkHUTxEeHUYHZDI
+evQ2PaRaJZhwts630EJAWqZBwNTzgkDomg3ishxUzK1rEgfROoodetaHoAVZrKAC
+cTzMhfXnYwV3tmBnGpLMQcqcV+hcFwOiBrTTz/BAfx6pRzyuMzGUkxFpTpDCK9ii
+o0Pa81x57FKxkAwgw9N565u1DduzJOR3PLKvHJRS/htj8OqurOWoHqDnxolxjZGN
+0lVijaBXNaLx5DbdWZIKauWfD3Lm50d/Nz3ddj5xbH5w75MjkqCG937Vl5c6PDo9
+ICahWrBQESfBWSpjwhYmZ5uiVRxjAcaGHxJ0oN+rs0wWtdS210SiSg09BLs3KrhV
+QtaeJRWa+hmp6UXFFqk+ZcnOPNSay1o5/Jy9APC3s7SQY5HPFMbjn+uxI4vo0PmO
+m64+6l4VliXlmpRUSPaDV36Z6VXrB5ungJer1dM2mKJLIUPO3z+i1J5h+GmyfTcs
+uAAye1uULU/0Q4LjA2h1BCzVYFMKWrOkcFFSwFfLbiWWI9vJzdgPpsiPDfCzBuXd
+0uqM/bdq1fQQ8Df1geodnCVXYEPNFgsJACLAA8ie99ZeyuEh+XpncXze5obE9k5O
+zrysG9R5XIClXi4lnmh7wkDHT1ZPX9CzqAxoVnoDE3PFiC2wK76URTmYOCZqtTtN
+Nyf8/XWmXgdq73k16eTk7B2bawZb4VmBOJF2C3aHgebQZDGcXqddo0Xn11LuiD0W
+sKTBBYefG7WPb6xRZcEnGa9zyxk7oWXS7ql7H2qEDVBmADBjnejZv+6ddfkBgfSF
+TSMqrxERwM4MzonIdzBgtbx1M7lSuk9WfrjIMijx9MfwMJcjYzyD/g1crFX/e1YQ
+3sbRvG3Dhc5OabAO+pye9bwyaH5F632ANJUlZXCwn5wAW//tw+tswnYy/15/fi7m
+e98zTMIhjDkE7nQWbcX4vaRYrREKdgl1bSfMM3jmeFa/RG9CDdeKcTnG6tbGucai
+QLIA8ABaFb4nbA0VgdZAjYLXO/3K9DqyCjE/BdeF5enwiBu2sdUfoHEEv1nA4gn/
+hzHrah3hKxjqRy7p+OBwtNWNPEiqz74IkLYVj4GlBCKVMN2ycSgmHMLWp5Pv1RNw
+BtLr3xwWRlL0xNQb2HsOLC3HadJGi2Q36PkxlZE6A66WhCjtuetsQTR7k8S142gX
+nlktsho6U2s/0nqFo70XnnIm6sZyuGHX6ilqnZc1nGfvltfh4uEJ0F7PM8PlRME2
+7wImEpa9CqxSluk2rg+zTuEfrReA0zOGMDe6AttHnkgjL4MOXj+TU51tByCPVUWF
+bex43VCPTk+WMRGPJCKR+GJZu/42OntR0Xzemm2eGbtnPzFyNTM7a4JDg3dvBC8c
+oOSFCbNGFidFZqh+TtDEwHv5txdDqhGdiRsMJ1RLxMUWSTvCsSjpnTtRZOeBXYUw
+dHBcirxit3HciQsgljFuRkIyEYpCh7djmPGUf8WtsQeTXa1UKQ8tk9m9ji/FephG
+6K6KxT3NNRWXrvhspakVsVeVtBgw81wCFUxj87Y0Bcs063h8FGP3PmxzCd0WoVJH
+RJjW/saKltm6JPSihBZg7gji8/9Xeio4eg1vFxJbtrDaDPRP2RlRjC4+oMwZ8j7T
+TdhBWisfr1L3CmCNc1iQ3LES3tJ1rLKswL5kN5Nz9rbTZvTODdW9JHNDtue1PJJC
+Volhvux5d1Gls0WXhZo9au6T/+7beeDqZjLi+95FpaMzCetvWqOxFdLAhOxpfZ5Q
+3LET9Mjk9H1CiLFSVUdoAQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/magic_link_twofactor.html
-Size: 4282
+Size: 4237
 
-G7kQAJwFdqMz2V4EYRl8PQ/MPOTr1G9aEfuD37fre9d+IrHTSBMmpSXIXl8riPz0
-6SGjJCn7jr2p2ekZOeFZiSnzkzT50A6I3RynzRAUkppCj16d9PfOqFMZzab6go+m
-KeOOl2XYgVFoXdtm+hIt4mOfYQqROwB+b0nymgLPYk2lb/T6OJ286PrG9Uy7pmoa
-TbXoyorqxlFP58DwFSNZ0UecQIc9UBn1tplOUdhlav+xmtdvkFkaeP62ORGOytDj
-Rm2rmpePeGy6YObSBDX6wo8NGCW5VNqtC5L/pCnbwMkdD7/3d91eNC1DGGTb02Zm
-rXbocVy+l1fBBBLtvPXQJHbmmAtlzDUnT5qntILax9YtpcarWuF+GcDeyJQ/gT+U
-wDy+lHxrHGmOSOmUG2JhIQ4hc9jGqcMLEa1WAvw5mOkqI4NzUysCVdey5dgVqCD8
-PvRFlQJa723AECR7S63n1BXFG8MLfx1i2sqGShkBDLCv2k10xd6dd/roiLdTqYTs
-H5rL1qtoeSf+NlSE4x4mQMAyw7ddxxqGz1hV3Wum4NW5CN49S7gqQN/xEWvwvDJT
-bReVUckffrrx3YXjiwJZwlvXOoz4uwgNqUwixgfZmIafaHUCjggfeCgYPRBm7I1V
-wFRbVJrMchsxsEqee2Tbq6Y4FNP3DbHsdcYOsX1RHOeWHKVVDcDXU1N2VttXs3tc
-FEEazMk7Tku6Y5oFMKw0QTcOg1yAgpanLL98dOuiPupuoAjBNdcAyUY1JwM6yDbS
-kK2En0c4rz7wsKiRBhWgpGsx2oGZA28VrreMyiVTEkffmVbNgmsrPX0YoPj5S+Ey
-zW7AwE6pHGluMVncFJe6NdkkOCnQ5USiqiMVR6zKrKkZn/mb9+GGcD9ONUNk2tCL
-ACvfl5beawhFdiHZRFEuwPIAcPX4L5AMFU7AttDs651wZgTyYDevM9uR4DFI1+oR
-fQFy0FznnIt9EkzQ19ygV3ndZQPZktl90XGpdfDvfvdK1Dtsy25mk6u5l2xsYaQy
-antDUj8wwY+Svw5kVei+FqXMcwAzzWOesnSVZaKfTeL7VC5mZEnHpKpKim4y8wXf
-zZxsnzUoSmJYGUsm2cuOSwpO4+UylzCDXQNNh3lhkHYbUVVb62aA/1NDYOI2lq6W
-HvZFJoQ0lW1NDzACQcJqz1fbk3PcBRemm1b26UoVBDas9CU5BgIy80b5WNlBIrah
-3nYo2M9USclb4d8OpeJT3KQ26rLxibK4yORVI8AF+6gAOwYxc3tad1H3bhHE1L3Y
-SR5k61qJOG5GJVNCGfDIEEvKJEM4fo6ARBBHaN/URIjdTPeNXGaeyC7nrNjVU3ar
-zkammoj2rDJMYgJ9yJ4tqZmJorjp9luwqEsysP9ZzEUGY2KimLdb8qZa9bbUg95k
-KORC7J+mSjDL6pcf4cgfsvSx++7qJSvr+jjwmZVYJ03qMX3hHK1WIgUtadjDLUJy
-Vz1ADb1rd0cJs1KVbMQD
+G4wQIBwF7layXQRR8BF9f8zPqe+eZULsD37Nlr5HGbYIm3Oj2OLuV0SC7PUlTJKU
+3zeWqtjoGBkjo0SUufkDR3sBQheHnd0JgiqCqkKPXlX6vhpVFXE6oBOGF8ty5jaH
+A5iBWWITtRpXolVqa/Uk6f3qS6Rw+/2FJUbeAPD8XJI4UKjJT+jyLh9fieH7n/Y7
+H5i7bbYUo7Qd95X73vZp4Rpk2fF3WGBHw3ObTVeNkxRcqG621b6/wSzSyLtrdcwk
+ytDlfm27XpM/0LnqkllrE/XwZ97W6UKyO/Xr84HuP0lzTpuD6XR8f9fzJ+3nUi58
+nKqaVa3d2c6qucmvYQSLet2GH2ArLN8xfB01x29aGpCs6vrc2uXCNFWnkF8OcFrI
+V8/gEBJZx1eKN5WD9pjUbriLTBbyAKGLVm4rfpioDXvAocDOxCwE1gOZBayn7VA7
+EKkkfJt8U619TrpZMBVJP1frPfcEcUdy6S9TRufZRLkehAnxkw+SPcHbkxwJ8+OW
+as9l8DO8p50bAHaKPGQYQB7nGwe2EmRVQV+TRneAKQGS28MKgRrQYHqHou+aB5VX
+UUbJfvPXXb9daT862GQElfFeIOo+JK8GFsz4cbpPvjCORKyP3jfxI+Mqg/evCuob
+VVGanuqoL/FEXR/kyE3Z5ImEkV8h86brdYfdWuJhyj5MWXkQ/nxo5tVqi2n7hoPy
+4IR1c+dFKbuaRQihapC03SK8K7I8dCMhv/lZnw/1E9eLz48CawewWnRxNkWDHTOd
+N+zxEwbX1fsmbGQQmwbUbgvzF4QVaFoV2rQcSUppZeNvbKr0wHHcnT9KUOX6lXRY
+CjdhqqZShTxbWJ9OpkPXWk8ddgo1tYzQVayjQALjjlqJWD/FXkwe57uNdBHyB74O
+aJe2xvJnj4EkylZL3DdgSxA6NvF/HVmYHIM/wZCbvmGv0u01HjBX/mKpI0UZx8g+
+pwoMxpFFaURBCXxJprGqaycM8J/QIVHY0QlEl9+mEopDrdOzbGk9m7JDLIvcRCM3
+09C/KmXPSn8C9NMIvJekyrsErlnHWmLzpzvwnCl8W5slyKsUVnRNx7TewmrRNyv3
+ccRuTLMn2gVbpc0HzsrazcPlAbe0ytwATBfpQaDhMaC60dOz4P53zU8hT1lmF9ln
+vi1EkIE5d/QEs44ErLV/iuNI2Am4siS0szgPqx8wVDLTjZjaJ6iaVuNwF8s3Rvo4
+nEG/ch0nbo1/yxKt58lF64N7nH7jHJKXXWmMt2TxNGCZADIvplUXO2ybHJZe2p1V
+wY4y7qNZKyiFAqp4p/ZqQSQVwfEp8IgRjsF+IAnCnqW9HQ9YJba0rSZx79TTkZOR
+jSTIe40R8hLyCvtoXSTyDEWWxB/CQ1uqRfuPIi42vZLyxLo9IpYbpj+XT3xIUDiA
+GTADC0NW5Gmfwcgf8thk/VvjY5hjbKe9dWHrOPXxyo9aoaMqo4ElT2R4Tintkafn
+kff4XiBgUaGKw3I=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/move_confirm.html
-Size: 1460
+Size: 1477
 
-G7MFIBwH7n7p/gkFYpitpinL4DfdflcqtXJpz5AgVHvdp7An8ueP5SGe8gm+uIx8
-/yK39lGUNtBAdYKzJc7OlYh9iNHb1igGKppFVL2uRHOl2TMGiNwD4PuIcpJebpyw
-GUU7ZRQiu53C+rZHqBeGAMV9ecABN0zPzkpfvV5fNdn1OmHHcwyZVk1FSkcem+tm
-P8pYZa+fx5zNk7YEMfPOzfTFFKmVz/8b2T8Z20lvIrP4vvS3NH7XvN22XTax1ROR
-ljfhr9UiJulkpZDkX+QXd2VhNzSUaUWOh3dmZ5ZRWIA/THUcvINXP/fa2WembnxB
-RAVkBHxIDW6mL8xKr+CECsnRDAmfPXLUbFDyl9/8v+k3zHthGuHJvm9Bv59eDPLj
-+TMlObYXqIbyomcr2HfEXAsQwQY2cXDMMBaInbD5FkBQB6ScnMHaYsjTj+080Zgh
-vkn8mmgSaiKMBZ2Z4gNbbZ9TH5XcTnAlcn/4wgwQJJ0qJ8IgKJVIXeWqndURV5Jy
-x6zKd76Y/hk6n6HW55yGTwTUSc3JoNq4u7IYybjCCCmPUQOC+NUdb5i/dDwl+hMZ
-hAG/Ja3WCiUiMCS2Nzth06/rxnAv
+G8QFIIzDOIa8tK845ttv6jEqF//Ytlsp5swH2uaSIFR7R4caxChH8P8uc8z7zOmh
+3eoGaUG1grUpzZ8ruTqlxmcboxgogHlU/a5EycqM6YJ4P72c28Xs7jFi5ALg50sU
+nOSK5IhVIR4KwxLRMyDwT90iLB4kkBjogY/AQXp0Z1jV7/W+Wf49TmDiLES6VTOR
+ypEv95o5iENa2ctnOqfz1S+xqHp2M8blSVSqWP4/5P9k6mZsINMEvvS3gN+06Pb9
+kI1v9XWmxc08ArdY5gQkLYUk/kQ/v6tKu3FDkZZkgfyO8s0wCg0YElItx++ZVz/6
+3RKwlNe+IqYSMoGfUIDjdI4Z6RWYcln6VEPlZ42cDZuxzV8+y/9Hn3E5StOAwcwH
+FrQH2UWhUB4/E9JneYl6rL70rIU8z5mwBixCB7mxccoxlUicMPmWcUUZkGp2CmuT
+MVDfdstMU47kRhlaR5tSG2Mq6dQUH9Bq85z6oCi3giu5OMhfqMEVASlzIQrDYgpF
+patyVkdca4o9tTLv5cv0V9HZzKjlWaniE8L7KDgYlJsOV5VDG0cYQRU0SphmfnGH
+GxQ4DXcV/0DGwgDfAtRYoQQEisT2ai9s1tHrqEecAQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/move_delegated_auth.html
-Size: 2953
+Size: 2990
 
-G4gLIBwH5ZYzZdoI8T7cZqVNq/ZGZWbWAJw2cRfU+/QseLI5Qfb6sk9EYWmsdU2Z
-ECJMbi8mJPftDrWk0SshUQm1UCKP4Syf4TiTUHGR30cx0CCrPx61Tol2qdfPogeR
-J0B6WNXQ7IYbp0JGamf6QmTaSdZ3TZu4UAKk+/qAPdwwPJM12aMk0SylxypVyr2S
-SVZlSCNQub5cfD8iBWUFBtAYJQpUs35zoq5JieKFG/VcsjYQJlDYF389Xoyoo072
-foP3T3RkcBOYG7ve37p+6ynK86rYNHvUhpx26+taJVx0dPt43eWKOny+qA/7cmtx
-DgRZTIVyI1qXdXOjbVuKpVAlSFl4pv9apWI6JZmbKC8WsTwGb297cLRpJEqC3kTr
-8OQk8pQYCRwjv06MxQHtBbMjropRECNX77OUpbuL6rgoNXnCX8T4BMLgjK/97/W+
-uSXzBCLsBMAckx/eoV8vOzFBDiIPAJqaRI6V4zsXOzpPrVDnmPMqHeRtUmsuD9CP
-KK0jhp+qKz33kdtLi+s40tUUMihBdTrjAGLk+1pk66JIsNmhRYxCI/Ohg9z97VID
-cYYTekjOJ8Ay1+7ictmx/z33G61TW3H9KD86WQ/ktbr0kXEQiK9J1A39lAkc6KBz
-J8ojEnUjgEMpYaMK06RvaDdYpldJ5FXOJ9nqzTSJcRH5CNLZ4c9hh8IWNJP20Gg9
-LWE8aGJ+M2xiTjk3oT208WV05QaYRQIfPJ9zsU5IXkMzugdYsh5xEyciyI1YvBEF
-c19hKSRBCawZkX4oC1ggWBRadEGz0SBirLiCxq9ByY0CzHsg68E0EAEC6wTsAImr
-wapg3KBZEWzPusUolJFWgOSvOeSWrwaGP2+CAlrVlp0bjcvN6GSgnNZTixtYJsFf
-umIxLXIEGaIEcbU0a86i/b1H5FNtnStWpoxkWnQSzXlSMP7kIyOFJKx22I80w59h
-2DWQeW4vRQJ79BiRUOKD5zhKwm0vwJhb9PhtBIGQcaB9qL4zPZRhyPCWfWTDpWQX
-UFRAS7srfvjUiKxRm1aOP6k0oVAa/XMqHbvFs8HyNNGA3EuDyJu/iBs+iihI5kZU
-0A39INITjCqap/RkvK8km65i5HwZF2Kd2R4aFrEggjevTQ4=
+G60LIBwH5ZYzZdoI8T7c5r/qbFq/PFZ6BYL1rtuhEzvAhqKVBNnr6z4RH4X7jf26
+pkwI8TO5fRFDEo9kvt2hljR6JSTxUAsl8hCn+5+ppIw4EL+tIxtokDWcazYlminr
+I+fwfbSxVpv6jx4jV0Bw6qUUHm4gOWVVioemWyJ7BgX+WYsOLgwSSgx6jz1wGJ7d
+OZ6zxApXRoTMZGyfKYjfbEQzqhwfW9cVYiGOGznQFBNFaorDedaKWknzxM1Fl2qO
+ygQx+Mbrw4spd5Bfvl/w/kkeF7gJzE2397cuz+rHWt/MupVrDqV2ar1RySp9ul2i
+pfKmBV8wrcEwl5p7yHCzYLEd0TpezmXdNltLtkpSZOGfArBtpXCn0L0x+yiR7OPQ
+9laMo5AjaRTiTawFT0pSU1mA4jHy4yRhHHkvyJ94KaZJTG2jn5ZbtVcLF5GXK/zF
+Ap9AGJyJnf65LvtrSlOMsBIAc0h9aIc+vN6JFDqIPABoDIleFyeOKn5V1qgpOd/o
+oAFjud54jIccGopY8ialLswD9CJsWZFAkMknPY5Cl4/a7QSIbxpcTFDtToKAVHq/
+GZctlm9YXKFLAiYHh6K93PwVroF0xNwAaP4YBNcxvjRWHPla837RLitvwjxiWjtb
+ljzW4joyUYHoGspv3E+cYkN7zcKIkSRbkgLbUuxGCyYof6FX+kzPUmBoTifV7Yua
+woRFPgdpnvly1Keoi1xQ7qFdhy123CiBPxl1MKcrNZZ76OHL5jfVIzhI4I37q1S8
+b5PbyAUGe/j1eyR8ByOC3ASLnxBBJBcvBbKUxJ9PZDTiBmIK5pcmPdBpt8kxNriC
+ErJByeUF5jU468IsZBYCEQaEBUm/waUgAaHsEayMXVJI2DiXgOT/hcglX21MfhqM
+LFrLS6HcXC8nY1+K2W0YLU4gvoQ++IJ5uegVpI+S5KvliXwW7e+5OKDmTkshCxvz
+zLSbZioTTD+FuAiFFER72A10wu9h2DngWpePcgP7YQUzyBbCYwLZ5Y4XYnoXPQmO
+IBBnbGgXMncsJwtqGoYTHVlzVtpZFCOgdbvLo/iiEYmsllYOPSiaULRF/HNROv5K
+Z712N1aK7nWDhDf/t274XERWMldyBVxZDxI9waj8e1pP5HWRbLqI4PNkYtja4m4a
+FpEqglev4A4=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/move_in_progress.html
-Size: 1562
+Size: 1579
 
-GxkGIJwHdrOF4xLpY6qbCOQv5j5Vjv6Y1n1NaSLKkSAwe92nsCcyqH64n6EOfGwv
-VhFY8f4ysShKB36qx3iCpzKcd6+goWz+IaqBCmFBVemUqCs3e0UPIlcAfM9RT9IM
-N064jJKdoi9E9jvF9aHIk4UxQGlfHrGHG6ZnbyWu8n7bFNfDxB2PMaRdNV7XfCMx
-cx3sJBnTetCMY84sQ1uClHlxM7mYI1XS8bmR/JO+HmQTmdEJpb8l+KtpvSz7ug2v
-Moi0jIl9raxikiQTxSTPkV9kyzO3oVKmFSUePtic6UdhBP4w1XzwDkH9LPXosFH3
-+wm+DGuPYRxyAfvpFmZgUHBGhexoQA6vlPUo2aDkD//xuenfjXumjfBk2nGgx4mv
-2uHH83eK7OjJMPGzBno1wXxHzDUCEcwwE2v7BH2GwAuj7zxLjhxmQN6czCvapRj5
-AZInKKlLlXMCHwkca6lCqnz0GZ1B6mCHTnNepHrcAn5GWxDfGACJJKl4wqs6ipQq
-1wXWzbwycGNTTz3UfEq/8WL6yxe0QeE27JqCVfuNQEKpjTSX+33Lsx4dj123q4Rs
-A/vVNG20injLJqj1NdB/IgPn8UcSNVREEoE2P35sVjadfo1NCpWZavsWEg==
+GyoGIBwHdmyyZro9tuMgW03zMYrB76Xa70qlVi7fGBIEZi+ofrhfoh74sb24isCK
+9y8Ti6J0wJvqGCc4leG8ewUNZfMPUQ1UCAuquk6JcrUZ05Xlfffw/hCza0AfI1cA
+ns9RcdIMSE44ldKhordEDgyK/EOXWBZGCWUGecQ+cJie/ZlQde+3zcrvYSKTRyHS
+rpqL1J6c3evgMA2Z5KAZ6ZzZDX4JkurFzVguZlGVcv3/UPyTuV9kE5kxDKW/JfhL
+y/44TtmGVzfMtIxZwOBW1jlJkolikmfRL+Lrym2olGlFEYgPtm/6URgBSEw1H95D
+UD+7fg3Zyvv9hkQFmbGsSy1gPx3HDAwKzrmQPw3I8pVO3i2bweYPr/X/o9e0npU2
+Apjp0IGeML9qByjP3ynyp6fC1NAa6NUEex5B2AiIMMNurJ0LzBVSL4y+8xw1lJiB
+gXMqr2iXYmwISJ6gom9Vzkl9JICtpcuoSzBXdAapgx06zXmRKnYL+BmNQ3xjAEaS
+pOIHcVVapFS5LrB6e8vAzVE9/VIVKj3XL9N/wKANCrcZu6ak1X4joFFqI83l+fR1
+1efjMVV/lRhuYL+ato1WEW/ZJrVexwJAZOA8/kiihopIItDmx4+DsFm3YGOTQoWo
+2r6lBg==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/move_link.html
-Size: 1656
+Size: 1673
 
-G3cGIBwHdnMOeW0Ggmix+NCTtVJldDj9KKLrJ2YJArPXfXsKIjfH7CbdhNBp0g6Q
-LWHTjRXq8cP5Fw6FtW/km5TX68hoFmkKztEayIQlh4EHJZqwJl+MEUSeAPe9RXuS
-LDdraBkWOzYWImHHvD42l2SJHMCyr5/ECDdET7AKDfz7vDtpXM+Td1AqASnOOiIE
-HOYGUCgyruIu5zHn5qktQck8++RyRY20MpK+I/2fRF4sG8mcQir9LcEfanh5XhW7
-9ANPIq0ymfiat2KiJGmM0EJ/akXcAid0JCYKJzkcotrOn0PavmcvETCKG0xJZlIR
-kb/AEz3NPb/M2GXFhPrnpDrSzkXtIH7xl23Sd7ANk8pUhx5kXbAwImg3NcV4+o1V
-wIhJizvrgHcXBezCyzrJB7gpMLyOdJoB+PzBs4HPYVgVZe43T5xA4rQKh7J+3SMA
-XQ4FSneDFZsTpbr/GnJVcGWKTHKH8kCGrmPtQ8+tBKynvaF1XA71PRCZ+BoNJWAn
-UIho9EkhWqJCKpCnoIglxQcHuVQut0lDUrXrMFt5UfBqVcDBykMTc+i7npWykbwA
-MjMYGP5SoWCSaEfR6cIJNpftXOXEYf2G/+eGWEz/whbgMEHj5mkWwQ9QiOgr5slc
-1QYJlMMI+oUNs4ZI2yoCAw==
+G4gGIBwHdqMP2V4GoiQvPrQ2l59Rl/p224MEVsDxA8CmCQKzl317SfvuZ2/STQid
+Jr8DZEvYdGOFmuD5Ew6FtWfkPdR5TRVI0FLRKVoDGbE4Mw1K1OwJUMz0fjq0bd2I
+rcYYI3dAPF+jxckwpCWoKtohGy2RhIHK37cxaQmVoDOoJzEGDsETn7mZfp83RfN7
+GmWCmQLrOC+JgKK81we6DTmauzHTOSdNfglM9egC6AoXNXOKeUL7n2RRThvB7LqU
+/hbhD3Wiuu6aXXKmaaaVhCbh5u2cSMmojCiF/lRs3gQnjCw6CDtGIIJaL8CDbN8p
+KnRI63pLXHCxyTCA0Amf+r5hE3gnVwWHtKPTNFPTB0AC5S+nYp7UKS06tzgMKct6
+DUO6dZOTkKffmEeGXFz+WZt6d2AGL8KuHYOCEzPF88zGNQOfz5GvqDTtmrYOpAcK
+ljis5EPWfjkCgLJsS1bamb3cFYWqAa0vXKlwgZkLbmMPVNFl8D4M5ZLActlfWuXL
+tnr3ZS5/NeZWQTdRSHHUaSOawnpJkEtQJJfigw1jLKdbZ0CzWn5YXbkpuC0XsFHh
+AVEd+q6q0mhGz8CZnhkYIFNywSjRjtLVmQU2Vd15kQPXw1v9PzfgRf8vbBIOHWu8
+ekqN4AcwIvqKOpnKbRBABgx/3NRh0iBqa6Vk
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/move_vault.html
-Size: 1888
+Size: 1905
 
-G18HAIzEOBZykz6EPVOX8icoMr/kJ+xG5VX5jzEtNwgMz97/OWa2AB0NcMKl8HOV
-V9GLCLtKu3fgB6rTIc67MqimolmkA1O4TTHrukS3UhG6W7QQeQXgdxkJJXU4RaQ0
-dncpzRBF82zZnW4OM7ICdvzJZ2pxIwuIvhaZ9e9Ls0h4nY3lobNErfLYEQml47kx
-Mm4mMD90kLmg7m4Jnea9ixBj8pI2cX+diBAJhoM50+k4esP1+NO4nKZ13s+cdR9p
-qyKWCHYpR8asVDUkRv5NCuP8zowk6Y5+II6QlQfoaLifddkbisZdHuBKMLcAjGQO
-7Sn9yqB58ZroAf3wJCf1vOUkAoz/fPrrVJ+mXxN5DCHFRomjJry7u4yXH1N84GiC
-+c761IcAYuwEs36AQBAxQ2u7ILBE8B63CQLtJGPLRuErXBomR7sSJqXkM5oL5xEa
-dsmL4LPIF7fJvCjKnvz8D9dmKab0Ec/iYTfaN2uK59EnH6I7h4cZgeFkXOpbRzJk
-xdqDxO9pIoNwzNr1O3IH1JRczG5ItZjSze6KbMZQHb54J+5hTATE8yYWgT+DaLNX
-YE6u+tkzsR8sz/TNLzrsgEFCbPWNL8IpfxhjnodGq4gHhEcQgRjqbMTFI63F4OK0
-rV/IXkJkdmBZy/jFN+/6KT26h19gnaONy3d24FnhA1O4kfHHaiaRSnhtLVFN
+G3AHABwFbiy2xZZLmuVHe7669HkJisxfchN2o/Kq/OZ0rn9YbhAYnr3Zt6fwf2Oz
+IaGSCJGSrYaTf5jbBBFpM217C3kLtaJyZL8nqRnFrQHqgV1obhE7LFF1pciIAP51
+935ndTkxysgLALDzbxohLCVNkFtpQuwaLxFCg8b+vnMIRuKE5obOMxkFByyAR0OL
+fX/ZVAX4ILEJ8xC5KpIxV95YxddELhFp+MTmOqe101/CafXOFQGTPCpSLP9/yDE1
+XAY4NcdmrHdZj18tun0/eO+92ImmxdSJxj0t1VEo5hoiBv7tKPTwETo0epzVoCAw
+NhvD48D9aLvFkV7XuiJSgicwSCfCbrrD+nge8irOkWrkmmrL34YoU/7zXP7/zHNc
+jlIe5ZRJ50Sby+7yacjDj11Z0lZiAWhV5oMGKjzhXTVYQQtVUTh5pIIc8cOpROqd
+TrJJtok2MkON47gCkGnKGZtt+NCtu04r+DmRiEuJeEPtlR8/2TH6fksVYU6uO9sy
+roniRz6poP+UrozU+gc8nURHJ4eZpl8j6tgTfYTjtl51KPdMY4fCj3RmFCq2yR2o
+GfPuUBU7SA92xeojE4tLgj6i+fGYkWRNBgcRn0bmlvEXNh8CDhThLDc+i7DyoUlk
+wTS2VQZEDy1KNewzawSjZ8Rg62S7ugweimiSwAaX8YNXMy97dZTPj4H6HMc4fKcJ
+fCB+oCbkzPhtz0SHytsZ3RM=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/need_onboarding.html
-Size: 1525
+Size: 1542
 
-G/QFIBwHdowzsp0c3yfjINOmImo9P2fSdPIW4Mxpd1qfIFR73aewJ/Ln7+ejTnDE
-Ezyx6NSqc9/WVD5KjPxAH1S/4F/E83ZYpBn4CSoDFWIxM26VaKj2dooORA4A871C
-dZKxv3FCMkp2SleIrDvF9a4sooUxQGlfHrGDG4ZntbI37v26V1xPEnc8xJB+s1yk
-VmI31+uCJGOXg3EYcw7u2xKkzL17o4s5Uqdc/z8U/2TuF9oEZgtC6W8RfmrZH8dL
-tomNe5FW9OJfq5WYRMlOMclT5JfUupINmTKsKPHwwe6MFkQJum5Qq5l3COpL7tfA
-OXXdG2IVZIb5kAS20yxMOyiM+RSyoxU5vJHl3TrPSv5yX/8/uk/rq+JG9mQxEDAY
-5FdT/+P5OyU7BiuM/KyVXu1w39Hm2mARHHATW+cCc4VEhel3YAQNQOpFTWKLTp5+
-7NeF5gLJXdqvni6lLsZcwVmlBydMfv1NyT2Xf5oj8Vh1cEu1XOuKMqbVbde7iLhz
-28yKGnp1oJVNaNq3jbGn+fwFO37T8COF1fRAJpjkwHqoxrLuyksceSmOXb3ExgUR
-FlSQO7S/hZ7ImsoEmTz0NiEzfAGRyB/ZGZwkmgnAlFutQ8CMbTFsa9wf
+GwUGIIzDOIa8tKe2xKEnU32NInjom933m9C9F3YJCUK1d3A51AQjTvBGY09d639X
+tDyaODZIgWqCLeK5m3E9UEExUEQsYcatErVW7ghm9D5dVXdxPwV0MbIFxOcrsJy5
+R3ICUckfijpLZGOQ4687xrTQSSgwCE/YBQ7Ds555M+7zonv6rcQxeRAiw2aZSKXA
++V4/hn7IKUfzQOdc3Psl8Kobd0e6GEQNiuX/o/xPpm6mTWCO0Eq/RfimRbfvL9ml
+Nu5nWtk9kOEWlzmJkhNckifRL61VKRsyZVhBEohPzt9IQVRIlxVqvvgeTH3M3RJi
+Lq9/pURJMlF8IiSwmoZjMqbgkAvh005BvpXl3aB7tPnlvvx/cB+XV8mNIpjpUMBw
+mN0sPZTn30ThM1zS2NDa4d1J+XmMhB0URbgo39g55TSVlKqgfg+xogmSalaL2IJu
+oN52ywxTTulDGKENtAdoE5pKODv/4KY0oNlMAZ/zoudIPRYd/lIj06qEgGnH3WIb
+E3evW62oYTYPtHIITee6NfdFn3/gnKAafqSw2D9QnExyYE1Vc+J34iUI7j6O836p
+jVMmTLkgdEj/Cn2VtSQSQu9htApkhh/AEfkr5w6VODMBWEKrvTeMbJtiKwP5IQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/new_app_available.html
-Size: 1841
+Size: 1858
 
-GzAHAIyTVPU9zZQ1lb3Utw/bJDvC+Aei7lPYE+nHHyvgJVuRZdg3M38jw+IyflwG
-VVGFHrCf4OkQ510hNaAyX0M90ECrTJP0S7TCWWwXMITIIwDAfp8AxJQacZzmNZY7
-YzBECTwn9mlbDYySgIVfP9MQN8Ig4Wp/kt+Xo4v8ei2JB7FEUHnaEVlIOTdNisxE
-q4eayFxMRrcE0bz2xYBJJb2sbBzY/CdJkIIznZqO3t96/KgVlGVT7WvTJONI6y9C
-AdusYzLURCSZbcQsg4JgyCMRZGHXezjVZen9ztSOrQ6LWSMYZWAwkxQ4xGKw8pMD
-7mOUibbhhxxbRlax4oiYEFxdW7YToK7Kwup2FvFjku0Aw4JTGPcwi4g+oQMTN6Ts
-1+EQRXg7qXn9YUJis4b8uNPGcd0WBX1yj6+DaeIKick6EjjONnzwqQb1P7fo4XQ4
-5jkULEz7k3wNPZ7v7klMXsOZxMeBLZAL/3SEhCnIryxllNPY+WcGLpwC0q6ZoWA8
-hyctyO2BiJyJyVXd2jqZUeqzO6ZyzXXuxf5vyH6doNKjoM8npfYOpQjLjwlD9SPS
-B+qH/89tsSqQ/z3GeRC+Q55+IfbQsaaqaaqkhbCfxqlOyUd7M+QbP0H71FUR6F/d
-GoDsDGV0jNpap3f29ea2dEQRuD4bVrToq+7EUHYE
+G0EHAIzEOBZykwbOMm0tf0ZN6rvPkh1h/AFBC2Tv548l8JGdyDHci6vs9Y9lUBVV
+6HSgPMHpGK3vxFmA6PUq6oESWnIcVb9ENVuEcGfA345VVZQiO41BRh4ACv0Yks4y
+JjkhrMSI0nCJnBqU+ydtF2CYJyQN4hkHwWEHHNHCOKrfl8MiBfhqchP4FOJOE5MI
+QRm+AZoxkat8WKY651bjXwJevXIRAkYZNTLToWfjn8R+Ak50lgnr/a3Ht5p+UdTl
+Pn1UI02riGgsbrnSSVCDiFNLj5gpGUFiUCQIwaoIUa7z4vydqmxLPhbyguiFr7Mj
+tkBYhZA2YA90RxcTbcEPYTa1LjrlI6QE44ris5UAxbKdt1pvIX1ksm1oGjg5cHcw
+i4hTPpBx7bP9GjzCBC/EFa8/DMQWL5Adt9t4Lionm0du8JbgaHCF2OAlMtjDSr09
+jhWoh7oFD7vFMcsgYA7aH+ct0D3Z7o7Y4DWcTHyULSBH/8kYCv1QvxKXYdGjdj/p
+O7BzqIbOIPkjPjxOhFwdCeeZGH0V1a/WMZiBas/YBFnrnguEddmvEZT7HDT5uMTa
+gVmh+NGri75T9ZL67v9zE6E6kfndzYUSfAd++oHIRcuL+TxPLdyC2I/jVCUUot3p
+/A0/gfTx1UIgfdVLAJRvYLTDuK5Fem1vcHpNhxTW65NBSSK92R2rMSYB
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/oauth_clients_limit_exceeded.html
-Size: 2699
+Size: 2716
 
-G4oKABwFbqwNuR4GgSw3/iMkmbV0LWU3fT09+pfsCOMPADdtEBiRvd2nsCfSr9/v
-R/JEaoRQV869iPl0lX1iCfNkkgiVXP50Yv6EBo2MrGuRLp9ek0LBDoygBxrXRphl
-u0Q7fGW76ELkDkC/CyhKruBkRNC03JnOEBF5zeyjtoZjkgVa+PdepYsbWAiJVE/j
-Z+zec5/ZFokwfZa/P/YrzD1KTmP4Ir3MA5roJSkUdwutgLW2FzdEujbZbyYux7eu
-zGGpvw3ccpnh/JM8LhxXPDfQvfJ6faobN03fHsfOsodq80qkcauQqlMLKS89O0OB
-Ra2Vai9F07Xk1vwz9T6Ywi0O9JKnRGkRwqQAXuh8r25thjF2E9uwBOkF5EcpCTwI
-O7cCKUcGfXApNqzKmVMK7u7t9Z51cC9dgNVwbzn9LFoBKsWXZnpmQCCy7cATvV3H
-YqL7J/kynXdY/1iQezBU56JNGPbSt8Yor4/MIcqdk4tAO1jChnNH4leWfTxY9jz2
-XUStPGIFGYSZcBN/khYvexV+YP1urw4hPlrkYxMAM70ald7LAN9+n1Z57/8Ni9XD
-ItZp2TkOSPbW7tL/52anw66mFrFtlrntT+GEJTwXD3/82Y6iJVQ89QtP0YbINrAj
-+fvbyz1kIY0wSu7AjLa2P3fFqSviW2eHR0h7KIge8ZVBtMDTm8GNA+OW3NYRpPjP
-7rNE8WeU2CIDf05Ej1V4h9aVSAQW/WFwukJkz9fyDiYPQeZPJOuDfrp6KK2qtECS
-csZXhXZVWoPbggzla0xIS9xq7Vy4sh3C16mRloStnxjHulnaDCMOEsw8gvkzlHqz
-eMc6K/fq3eBDs4wSccXw3PzVpBW0ZZqYM8tKu+8ijNXuq0kraF/10Ghl
+G5sKABwFbqwNuR4GoSQ3/q3qar9lsnKz08PxcSPUHuCW0yfLDQIjsjf79hT8vv1+
+P3kiNUKoM7N3FzE/XWWeWMI8mSRChRz+6cT8CQ0ai3T51BSUwQ4M4AcqasNEsl2i
+pY4JICL41U2t88IuN3QycgsQ6Bk8J1uSEiKspiCq7hKRGib3D7vYE5M8MbVh91U6
+wQGCTyy7Z5fTu8+9h7ZIlalJfv6wl2n7IFmPAY70EA15ogekldwlmhLXslzsIOpa
+Zc+auGDfOBOEpULXM8P/H+p/0qWeuIGzQ+GrrdeHmjTP63IcPck+rM2xDfNce6iG
+1HzoBqtaLMGoxiIHpKzatt6aPn/rOKjSLgR6wHpVpghh2gDPVWcr20bcj5qTQiSY
+M5BHRrSAgd+5BYFS8IteNCIcqHLmlALd3f3i0wR3Cwqg+rPl9NNgGcQWX5rgmUCJ
+kVyGpuhwrc4Jzk/yZaqrePpmXmdxbzxnncOwW+A1RPC6eBiETmNyEWhDHbGOqIL4
+HIZeICx5uVlrhHYgscYM4neiXtxJ2sTsYQpC824bDz4cNfCRCYCagI28Y+nB7fc1
+I3v3f1msLxahTktOyYPsz92FX++POO2qmmrAVoHMrYOKaqziXf/sfj6VZm5D7rHr
+LXOP6Gsr6if5+dvrLLYBfnBfOo2HaJsW6i65rX18K8o/QqYPg+gzXw1EC0q+CdWz
+Z9yi21qBFP7ZdTIx/gyLLTLw50T0SL09tGxtJKjod0Nde5E9n+sqHlj0rfsF4x/y
+09ZDaXelGU0r++vLghpLq3drkb56jQVplZdm+euNXL7D68SgScKW9xiHumnZjF9x
+kPA7C6B+9JfebO+xE8v9flfwcDWDRNxAOG3+Yl4Ebu00MjJLBrXWiKO5+2JeBG6B
+9cC4aQ==
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/oidc_login.html
-Size: 1650
+Size: 1667
 
-G3EGABwH5ZYzZdoI8e00M6ciqhN/KxN2A9gCkjnldPmcIHt93aewJ9IP905pEULC
-v5c7xtgWHG4nga2WmqPD1qgKq2pkdZjrRH9T6jRQmY1QDjRsKzhn+hL1Vd7OsYXI
-A4Dfa4gp3nGc5jXwnbEZogo8EnvTKbSMkgDMr/9pixs2gEDX78xrZtmT16Mknjpk
-SLdzmWplyei5cRaeCeqPDzIXMrtbcljz5N4spizpUiz/H/J/MnWz5aALSDT9LZt/
-tOj2/dB3KWf2kbbWcyu4Zjz+BeQVVzGQUNnLZa++G5bpiU84PExHN4up8E2QuEFd
-1V/ZVmX4BMcoK/mxyNrGTfqKZLdGdthWaCVTpEsAFyx1vhQXikYTxCEvAJ7tGwAw
-CuFiksfhgo+mW4T1zfYVEZXQCciGvwzsDXX2dGIQcbDCD/ihGE0Nwt3Pgf+PHuNy
-lA4C7ObFl0uSLZId3MtiAzHSJc7P1k8TQSAMtQoHAFph7P19ciFQQBSE6CGJKcEQ
-z4OgSdMPca0mJkMASl4UirZxDOFAOLYQ61j8S5tQG2EqacoREG9LIAwkWWnDKNoq
-u/cP0lEY4RphEEQBS22NO4HfJgd4xhwSmm+ZSowNWYhAxye7fF3KVUoWVmnkLLEW
-G3zSLO8tolueooIwz/vljSWaKhu2Ud5ICQkKwYpve+V1S1sb5Qs=
+G4IGIBwHjtuQ24sgNFGvmTkVUZ35W5mwG8AWkMwpZ9XnBunbU9h9Ij4KD9dU2goh
+2z8qMLk6TtKyIg80tBMahVUzcjrEeTdOnQYmNkNjoOZZxlrdK1GzcEV38u+zlzGr
+uD6GGLkD8HwPOccjklOiinKoDZZImYGFf+jm+wVFgpVBPcMQOMRP6Szf6t/3bZf+
+nqUwaRIiw9YmIoUhbfeGFcuQV0480TmfHv2SJapHd+UvVFGbbPpfSP/J0Ix+I5uH
+c+lvefyhWbOum+xzt3qcaXtdQXnnisgnD+/CwvwR4bFejspeKYnc1AyI+9nsemMr
+RsZLwkBL+Zc3RZ48wTWoWm5QjN28wX6R6tdJNlMzq1SSeA4gQz+tI2QFsSiKmV5k
+d5l4PABK4dtME1lC8FI3Eyv7s39GQDlkANLhWV7mDnfmYmJQsbCjijxZMzQWSLdv
+J/4Xffppy8ND8FvnSG5xQpER3gtVQ5B4jvm1ddOnF4gDXYk9ADE/xga/uAQQMHmh
+ikCDSiok7vh/l4YLYVtO/OYDcDLFFwzz6MOEcUwJVrH5lzqiOsCQ05DCI9GWNGhA
+Eq99L3g0/UIiY1Pk4BK+5+UAa3FFOBHPdQFwfHBC4GfPkKOvyD9Mc3gw5H2xasVk
+kJTOrSFl1Xq/LNvZiyqXrZgkLPJu3iNBMW39BtKbqRyRQaDi3VZUelPbO8YY
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/oidc_twofactor.html
-Size: 654
+Size: 674
 
-G40CQIzUUc3m2Z6b3ZIcHQnNYmjO0NSghQ107mcEyLBTwAD9PQvjwLL9z9eHM9tW
-SAQCgZ/P+ZsjIGexQp85vuBzOBZywmcIJLTvwv/3oNB0258x6Evk6rWZe4nwGh3P
-Yy9IbIX7lGjNYZJBa0CEbCsfVl1hqIxCisjZMG2cWQpY2YAvd4hEb0TtcmqG52hN
-Ha3USLyVFgbnqi6DlSoKZDcHRlZVHcpt2VSMPrO4jW4kWbMPb+j4j7BZIPxAyaE/
-01Zwf2HlMKRl
+G6ECwCwK7IbpVJ/wikidjLjizzQ13VyqYp+G9pIemv5VUB6OmCBqe7ABh5hAYBFP
+t4LIUJ9Mm7ubugn4gL4W9fgcSJeNMXh6mNm2QiJQbHt8zt8cATiLFa7M0QV/wZGQ
+E/AXAgjlu/h/SWiq7RstZDJf3Zd5jkhwww4PSLTCNUo03zC5YE+ghGiLdFhxhTAZ
+5fSQw5kurluCVW7j1yxBbVLpclqG5ShNlkqNxFp5YVC2ag2VKgpidxxRVVWD8n5s
+mCx14K28m8e82ZKZ7EYtLNnuD3Fo+mfYrGAHpQ7+KcXzBpZDpGk=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/passphrase_choose.html
-Size: 4348
+Size: 4857
 
-G/sQIBwHbqzF1OYcc/Nzqj+tJzI/47TxtzZV7a44JqKJw3YaBbLXfSJ7Cu1z7/+q
-+x2lcBr9lOCeTTYzyaXVfgqtFMt4hDBIj5HYGK7vshCHJzq/L6MaaAJbd70WXYku
-Uln34wkinwHeYWt45uvGqZglsTPPQsy8p2H9qLt1WBgCSfb1Fk+4ofvE88Zfi05O
-6AJ1f1ZsX84wFOkUjtNbSCynQt1dKqTo9HyH7zW3oHjVJZHQt54VLnLmXJ2/nNH+
-J1a7sJMzvp3ev3X6rZ2OceLlhtfipdIempHqQ/4reTxl1SrdL2P5YpcX8nwQ1PCd
-+NzhC6nqtraaItOTUOQv97cxqZ69AmdbnVqQ/SoM3bHd0FyS/EPG29OjbkoKK3tA
-GHF1XWOSa+ktKitY7Bu1S8hhYn1iIvHTML+lyix9zqG3RcLLQKFhlkdlmpl5HAoi
-cCcZnCCGh8R8GQqdkZC9MxnQNp1URN2egzOGJtBFXxY26IOveqBPSYH+mE+UkSUq
-NeA3SXM+XpF8t/AImKOg8hWS/L7jOFTkjKFh8yu8/P+9JxwKVYeDRWFYtspBQAE9
-cTMfN7a8z/VTZLkshwr3s4MFRus5jhoK4rK9OU1H0Ob7Qnsp6jdnB2yoB1uUpHnL
-iz9i2McDFlpSNlUUd8i5KzKVYf78ksuZXoyfenrimFyTxH2yMSkKFXl1tIWVPT4P
-cKXbHjVaqCENheRCaBuGgCpl/zjuaNzA9mRbPJq17VLsx4EXv25mzN8Bs1Hh0e1T
-C0S5FEWLpW9DcVr4hdw0qNqCPD3+9pBdY8N4wxRZS3RO3dCCdVlGPxI9EiokEGyu
-65vHwtyL5iCiF0hoFR0VUEcwGgHxkl7zt40EMLP2dhMZU6NFcHZZ6Ykex6M4OWFR
-qbXpqhS0rHpa6e1uFkAbq3gdVXt8CH1LPGsmik/LdQvUFi0p4zVqhxzpIpZyCD0O
-MOpeySyYUrG8bFI3u18qtTJMt/t1t5LHlW73Q50VPqgyWwzURbUvjvkZkvolo3S5
-eYMvfut6b3gictbuaYzFvibqBrBZXcrfUBrO02o305wkC6k6nLrQ9HJXNwkxfj/p
-IDpL7YOdksKO29qKtnSrx+KV4X4QvHAVPrSFsk96ekKlhGai/TfU0t32GQB0gHlM
-Qsp5SpkU9krVZ1uujND4YoGWgAGswsblfvm3+yNZZByicAcwepXIUN6XkDhVWAVy
-Y02P4v6agC1wFpDrzcJWhKu2AhcAFT4kTsZruLrd+ySF3nj5eBkK+y2C4F4ZISu9
-EQVrUpa5HY0kSwEWwznqszdDxZMAnmu2OCBNOaCOOWNLNpXUNxNjzy5bMJknUyOG
-kcUQ1sobL1H1qnIJN6oB/ybeayNWhtKpVI2WHRmqoz74Sk3+a17iOST/48150MZk
-YR97ZNmw7fz5B2qNVyuOKtv9QpmgYmyvcni2xLn251JkXLuD0WSimLmtgA==
+G/gSACwO7Ab3J5yZ1YUtXxgUWU21qr3h6xWO5adUfa6tdLZ4QGI4HBkKZK9F+nwU
+Zt+eQjFJ+dXeWt1ZyErhNHqV2X7Tb6r+/L0lxTA9fSEmh6V8SFajHRjHYzjfoQ4s
+QhfVjXKggXY256pboue5QDkD/NV9SlGKj15GtgEMfRKijjmpn/JryIimv0SNjZD6
+U13PgSFNAjXkV/SCow1oQC+cq86e0FHU+EKQ90cDnox9N+dGhBNVCvARpTNTLb84
+9rlWNRwmybOrXgAMWj2gmy5ntP+JNyPw4I4j2Pu7Xp+1MzHu5Xh+rgaeNmmBqw4U
+yUvShyOiGzgqevCX78thp6iRa6WJnBjl3ZZfg29qyhU6u3/nE/ceySjlyshHEGQF
+02L7b+gygSKQ69P9X9kqihM9mBo57dnGJefS/aicYHN3MGMCzYn984nUZyMykpV9
+9LafntuqmYZCIyJ/41ZDMx1EE4InJE+2Jv8joUsyNM0E78g0pKsmceTDtcAzpgZq
++93Gwn4wmy1NNwW60JxRgZd8KoGjEzf3OzbEP2nOGMxd+HTE+dTcSdx27Nhjfywi
+nC7tkBxp46LgmUodxUpfAx37j2hBNZqVMl6uLlpa1RVcJQhXXmPsQkId+Xv/XU18
+P74Brd2QrOe4v7LNnwcsqId4jNPIuCanozCH9C3kEs2rxvCbx+lypkc37XucQbYO
+0IhEmsLIT7xJqHP7131N9arTTem2rkKYvAWd50SPqRqu6EeNcjmUqQRR3wWdyaDN
+dIUvDisaFvA9+RZ/9/n2DNYUnNhwkYPonGwmknjNO5REA5+6FXWIBKUgxgJIJaHA
+IOyyxsobuTk0gtG+yFn88/KCjpjPZv73qI2YIg7A1RoLYyChW+h2yn8OUR5RpG+h
+pGP0GjJ5NntXAjsnTVgGHUR71ehC3HUrQsFOsEWDBWBUzW3EZmqmKtCWEJvfLeiU
+FYYGa5+T0IVMwR6x4iMrOoaoEV1PhQyIgkOOEdulVanJBgbTs2y9LdSqHuBixBla
+7+Z2vOlOYlDRj3vgM2Nymx3i7KeWtMOlSGrJ9FN3iYOD8C02n9A7tZZRaI7D3Saa
+BpKiGqN+wMz0oN6Me5uT0FBWExU/aNzd0yDBy+uTCaqTr7vw+8TMY1jLvC09B+Cw
+ZLhJpHRdIYCKE++htAG2pOxMyjAJdQ3FWiZwbo/pMQ5JIYiaAPwE93RrTsw15XyB
++owGPGNRDP6xj3F1uEibUAkeuNESqiStnCziRwsLcvC216Q00CfcFowWPLe0c+tC
+eu2EYICr8D8ZpX+Gs+HexCmU3e4zTDe3DxFybs8w2ezrobBG8ai1gcQZJZjPz1Eq
+r1HgLnNwLZ/jgUhlSj11xpJ8EtWDqXaA3c6ZJMpQFWMzo8/XjszVgFHRQfUGm/Ri
+VgkPdf82OxEoqhoXVCWFcv5fOuQRf2pW9ExJ3CMFmRiFiOfhBY3JDwB6eJK+k3oa
+iJy5aX1LZHLi/R6gj6PdopZe6WNoLKu/HVK36m4xGC7i/ei/yDivD4xMc//GYzyH
+NH1O7lyLNZMC+NP/ohvxXYPlB0PUW57M+NUn2AWO3u1lgFZU9/07ytq+exgV0PNa
+sQ4Y
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/passphrase_reset.html
-Size: 3671
+Size: 3666
 
-G1YOIBwFbqwxtZlDEKYOufzeuVJ23vjfslwCTWeXkl5ShgRR2Os+hT2RV3OYZ2Vr
-fK3qwu0UAQ6TIihgTeDwhbWZ9238tN1W1WCRR+DbzaEZqEVWP03GqERL5GLrmEDk
-GgDfe8AopTcxzhmpnTYVIpedZL3fyo4LJUC6v/KAE9woeipz2yfj8Xrnorp2kR1o
-JZSUFh1RQYabW8amyhRUV7WMuUJjbkugmae+WLxokVl+2TjgC0CQjLiJTC1GL1nX
-J/3psmyqTeOTMYu0rkX8a72JSTEVkCT5P/JrqqXIGybKtCtqPLyzO2Oi+BJ1lpNA
-EMuenvtRZh0BVD7MJw/QJRFRTtt0QMZYbwQAmF9OpfjDB50mPo8w5ykP5YzxYF8I
-VFcT78CRxy+KmTt0100OKJE8DzPgVdAtmx3m571mq8ZBdmrSoDlZSQJVAigsZLSU
-b8U3FQaclKkotRkQQDcvGjozTydyOOnoxTIPPDTrjgI2wK88QOJko2r/mWrlH4ds
-HOgQZ41IjQB4fZMPMsP9dWKLpgOEd7bDm5R8kQi4RViJx73KoxwSICz0qPP84aSi
-RShmGJOTF5iDzWoEmjze7KlsRbvv+qmuOq33Us1CPTIGSqc1PjWOhoEutdQIfZ+m
-ViZljMurqlaTMFzap4VljSCw3zlNxu3j6gXTeadXI6YqA512fJEH35WYb2V4Xwfb
-sn6Lnb1Fdh2JhLuFen4v2NQxw6dRGliHmeKVu11ENEQDubgnKRzezBPFd4NJU0sx
-aR/kI1sLZQ/6DZk5ikVGoW8etnkUOo826/MGvJDAEbtEd8hUMEUwxA/LsDXxiUqV
-Oo1goVpMzxX/KI/YmC+DzbjbR3Fe8JRXZLbS/6vK4OVSarv2w/12PkdtccGiAv9N
-iwbG5w5tsX5mKnA196BzJctq4cGWQ7NPKd0Ks2cpJcuw8R07u25Wbs5tOCCI1qoE
-UbBRcGd/nya2LmuRIgKkTMcfGbhV5A2Lwqb/BFqKCJKGmdGLV2ijw1O0o4hAVdLk
-DXeBFa8XOkRk4LPxW+5ucvHqiEDaA3kh262jnu+ZDhY=
+G1EOIBwF7layXQ6COXyyHfN750rZeeN/y3IJNJ1dSnpJGRqkb09h94n4KLyaS/8l
+ZWt8rerC7ZQQ1WGSIihgTeBYWJuprWiatI0qZ8jC+9SjM9DIrGWajFGJ5sqlaJry
++21X12W19DHFyBUAz3eBc0qRElxV0kNjskRmBgl/r5WdF4qEjEE/4BQ4TM/t7P5k
+PF5vX/LfPsIENYWm07IkasmIe6vZ1KHS6qoWOldmzH4JVPXEl8oXTTTfLxsHfAEZ
+0pE3kanl6qXr+qg/XZZNtWn7ZMwzrX9pAm6zm5NyKiVJ8r/o115LUTcslGk1F4h3
+jm9sFJ+iznISCGLZ03MPyqwbYFkCeyoGlklGVNNuHZAxGhwhAtaXS6n48MGkSRYi
+Fj4Vo50JHg4MiZpr4h1E9vhFBXOH7qYNAiWSF2OOvArLZbPN/Lw3jNc6DE9NK7Qs
+K0mgSoCNZQyf8q0Eq8KA4zIVrbYjBOjmeUNn5ulYDqccs1n2oYkO3WGoA0ArBkbO
+Nar2n/EzfxyycaBDnDWiNELktU0+yBz41xhbDB0gAHQdXqXki0TAbYIO0EPKoxwS
+ICzzqIv9AaeiRShmGLWTl6CD3dkINMW82VXZinbX9VNddVrvxe+CY5kGpdMan1rH
+y8AYW1zE8KeplUmZ4PYqX2sThk8Ha1npIsQP9E2Tcfu4YYnpqvI9v6oMxheVLa5N
+f2PzLRjd44tiODm2SqcghjvYlsXjCufmkGlvIuFuISDYDTZ1woBslBI6O1e+eHeL
+jgZ9IHd3pYSDm3mh+G4saWoppgGEehRooU6A3kNlDmOpotATH7bFlLqYNmvzBsKV
+iDP2ye6AqWCKYEsYxmFnxwcqVeo0goV2Cb24+EeFztZ6WWzJ324q8oZnXm4b8tf9
++G0rPCsI3YxsYFRuyiI6GwwzPAGm4YPOlQzoIHitgWaIUiPNkJnv6TJVfMdxrp/d
+2msbnWnHaieSF44OdGt/nya2F2eTYoKYPCfc1rmDKBjohE3/BP6JCNKGgvGLV2jj
+Q0i0o5hgUsrkDff9qlgvb4jIIDrjt9ynySd+IwKJ/ItDtjvHOt91+q0A
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/share_by_link_password.html
-Size: 2552
+Size: 2569
 
-G/cJIByFcbNTFFeQlbmLpi1UD7JqTh+jbs5vgFTLqi7C4X+wQSKwnU/oQXZb4JHk
-dZ2v+/Zc6G6/111tGa7GcwiNNj/JS7qnZds18tK5PGrapeA8wlqMREO89mbrHDqw
-g2Kg51jjaK0S3ZMXfih0IPIEYN9jEV6hl31YU8Hbt9xuGxpuDDNtmFDqdbTokoqg
-se6+3MIMI1LRJ+w+h81TEjVqbUotXqR7J1kNIIaEoSFNFEnVivNcCEsOH3iQdCm1
-50wA5Ee/sLtIwDviZt8Q/ZMqr90mYzzUvb91/9I47/tp2LVm1ke1/YtCHfdLpORS
-knLSTcBfa5TCEYV9BH0D0cYa0yCJOIyYzbdRVyHEI6oT72/Biv0rCzAt9AQPGDFn
-RPWEOwJxO+uQAFeU02hiB/L0rZo3Giub11uYJDBUYGu+E6tpSmenOnMQYBRx8J6z
-6jCn7OLfv3ybfaPv/ZPwjzjdsBbIhTq/iHTjuBqsuywwcrZxek+ieIEyIQGOlUav
-33sXAWZDxlZCrbrF+lbe1FRFsB6atd7IbJmZT5iME/YkVCdHJx7dPABMhLwUDVmp
-6BI32nURq4ItZJ4SOuSvCShWgLokFnLHfhYO6JosOOzTAoPhyVOz+R+2sq+QC9Zi
-4P+wj7wbIbzLk3312+M1Z9ElCbeEGEPpCore9/dRCfxTjq2EIDi74QTFuSk3Kphu
-chRRRrF4t0OjxRFvu8gJbDE1JDLWd5GkoZEhSo4nYcKnq+AtBaEdR+82/dsKWkGw
-/ubo236ZZlEJhJnpg9HDQoREC/4Mzu0tgvDfA6zbrVBCdeHVqMdOaR9t020xj/fw
-PU7vp5CbxPgMc8FSP5UeuoWHVmH9onakQ7J3TBLdZDEpOna+Or0ILwheIrFrqg0W
-VePPqxxu98hKmo6BSIpwsJtYibMMvGM7gK+RGVUKHKuNy/ZiE+9yKRRDPqZuf/XO
-FV8aNtRiQES7NkJHKgEB
+GwgKAJwHxk3zQCjSeQiayMV7kN87p69R987/AqkRugpHS8ZXSjdOg8Ao7O09F7qX
+tde339jvUbX90nmETC637bbvWvFqsruv1hGvlqBDqJUSWWJX+cWdp+AGugMd2yo/
+jUt0UNtMWPjTfBh6Y08cJhl5BDD0vkgx0+t5rikP343SMkLpx4inoQlvnckYbeQR
+sswbB5ZhEhLx6DNOscMGKNIqNO7UpC3vG0l8QGuIKlr2RJZcPTnNw7mo+cQLqYs1
+uyZA7Wu32WIk6TWH5vtB9U/kUltOcLwcvr/1+NTDpe8fpq5d81jbtB0KutuNSlZF
+KSntJv3VBq1sUdSAZFBB9WGWcRBJGDDeId6ovRANIJ5H5l8htw7mFdgYuoIthszC
+qNlyByD+52sj4JNy7k0QQaK+di+Nx+rn6RYhKRgBo3PtmM1wO3+0M08B1hEGNzro
+mueJW47+y7L5fmh5bx7KQeJ93fPlxMqfRN5xYD3MPK+wurZh+hBFBwdKiQh4WBwz
+g29dCJgxeSrxtpo660uXpiapED01sz1zjukcQhRomJBPvLX3c+IV0AOMCVAWo2Ut
+ZVsBpK0XQSvIhexUvIccNwJVC9AuisPesXkuDaYvM+0mvRBwPHnRjP+bre4FF8V6
+DQAs5sjNEYfXZbqX/ar/7EWrxOPiZfSm7XR0fngbonA/cXDFC+ZilxwU5uHeqHLa
+DVREqcUC3+8dLaC4aiIpcFTVBZG6rpUkHz0poiR7FCAuYZn3GMR4GDPgdO8zGCnB
+9z+H3++YFxjVQti9Phg+7KyQcMG/5lLfJKgDG4CiXo4iqgm3ek1qpY/UjbjJy6GB
+H8L0YR/6o6Hh6GWwulYrzZRLXn6F7ydVk1bJ7jFNNJPGpPr4E9ZqSnhF7CUaO0U+
+iEiG32clPDaSL24axkRSjU09sBNoWZzH6oCxEZDqLuxrHMy6bJAeIjgzV5OQsdF/
+rbPGFYcV92pQot44oj11EwE=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/sharing_discovery.html
-Size: 3163
+Size: 3330
 
-G1oMIBwHdqMP2X4YRx4WcZHzVZu/d5ms7M8pjvAZtR9iciXLCQKzl317TH62/Nmv
-CBqjEMLelJuQ1uu+nf206kpz3SE0xCOkwUhUhvPuUGShDbNbQjUQhHWKcK9EOxJl
-djMGELkDmh6/CmnOxxsXxExgB0MhatqlWV+3XcjiJiDYD7c8wI2612QNLPx3/6Iy
-1wch3bOkW0UC5xJPTHMbjIVMldvk7zFXzWNbImQ+uDJyuUSWi7rLGeE/aYpWtjir
-tLn0twR/aVSM4+SW3QuPIm1EzfBrrGKKJPUVASKTCSsLWvSWOCvCvw9nn++wpciA
-3BwDCyqa1icp2DoXAisBtKXnPqsMWQFcvqjQoBB6vnAbE8R5qNEMDLhelJ/F3FRC
-oZq7o1ksQs9LLjpreOrGHksxXAONKFu7xDvwOc1XtQ/pmErogeXsDplR1f0/3HaX
-M93W3RQHKFrysI3lBRs8Y2PGt2dB1SyMkdbJiW6rUDVhe1IJbVcNCvy8CbFJSKg5
-N+RrypdoYncNS0isgbGZFvFk4KJvu97hO0pJl1GtSNol0H4myIJHadbiFr7SRpch
-HoeaFAEyzbT2EsxQqgTnIrhX4f/vGMwDBzvDhHfiiEWFfniVcpfiqHgWqRU+ePwb
-NDHqjESKlLqR+P+nNsCD/NF5C8rrxapwKNp4rJaTNlY4H6inzHTNZkMSgt16q634
-dUV+0CDVRfWWCgdHm34Un5umsQZMjU7xEp5Yn0jK1E348BskbUxHNEVb3gVGxA+u
-wjzfjlK1gcgL/LPjtTt+xQxtEUK3mN315MeEEnCCxKMZWnwBTa8msDEY+ZGSPfQB
-lpqaTRo8ia6yWWe0s0cUViwowAdiWaFSDRZLYC1Z+H7UraYtINhpeUE1ILqtRM2w
-N8zwTiZuxliCbHNZzbJXwsxhApTNFOmeLMwMX2DmpIGSZfrMSyq6xJWfd6IQiQlW
-grFBRrsWQT/4KVkG/daWCzmK7GFpn+qwY8IdY6CRcV+W+aRluFwb7CKIzwRLZe8a
-Y9FdjmKXh81NvK3Gp3gsTgW7ehBTUPuh0hPyxi8g7EuTHIeu78kPJ55bqPzzmllp
-ETORrwJHR2Od3VSD0tND0ddgAmKdCxLzIoS4VNsi8bUqmm3trWZyR6S95BC0sqgx
-8HUSa5CDccLG0uWq19PxkSrZ
+GwENIBwHdqMP2X4YRx4WcZHzVZu/d5ms7C8ujv0AoP2Qkhy0kRMEZq/7ivaYHq21
+LJYpiRDqjdy87WNqOzuHedMk3ggZOrGRGyWyjM13Kewwl0YmcAMVsDbvdatEw4lQ
+dGd4X107Z6y4WNHFyD1Anh/AcYp7JOcllehQdZbIyKCKf9lxAwsrCTGDf8YucDg8
+57P3Xv99Pyv0d5KKCWQKWadhSdSTtntrFdNQzj7igc453fslINU7FwousmilqDuf
+EP6TpmhhU7AsY+lvAf7UqDBmtPuOve5n2qBYMbi1z0mQOFaAMtG6EOZgXpxn2Xv9
++3Pq+Q5mK6Hshhi0wKDheJTCfEeuEJaAMxT7qFDEAJDhgIs0EChecKsBzdlH1qRt
+3jUK4IhXTrqn5fnFRlwoTBZSV8IdTK0BRT3XRcfKmF3dYyuGbSA2+Ry/wk0BjWrO
+IgoEURbCYqG2U6ZEFMQ3t935RLd1N8Z/SKJ0P6fyDAdFpPf5UlTJo9kx0jpZ6DMH
+/wWVThYi0cFO7mlCHDJiHnIs43KsxDKXchnknDtKFGcTL0mmoPOoOWFHVvI3B+kb
+F3HbDicdhq4Tyqcv4Ffle8q3aOKU1m7xRx46ccJ7R4qIeejwra4jXLYrEX9XXp6Q
+FkCCAA1N6i+rKe9oV6R79UaMCRYEHC2UI4FnYRoCE+awmD768sBCHaJd/ssZm9KE
+2OHetMQYPIJkv1T9/iGaGHVG3hcpdYb0/88y5tT20TlFj/e5orAo2nQs9SdtLLiv
+tx4z1dAd9Z3PgtL2b/o3FMTRerM6qs/AsphsmHc/V42mrpp5bQzQ/5GSDOSMw/g/
+v0DSxjSjKVopRIQMZsxFWOPtIB6YSjwxYHS/9EKXEXtgG0DXqYvr0ZmEMnCEJKLY
+A7qEthA4g3WBMePYJtUxlEEB1SYZeYJVjdYHUjtG1MC2SxQqhqZlJhUHYxuItXRj
+x+GwnDfPD0iGC3DU4DMLB7YrzDDAFRMxkiE5nRebqIyPHOxPa4MIpVuwNDJsgFGQ
+Wkr2yTdWVnVIfbBxRyIZDNYsDEuFaFMn8fipgm7iicucraOIpy0XamWTmLghoqFe
+dFa4pEVyuTS4SKAuInv29uMUk+wyVAv9bGjis8PIRm5PBJtyUOeg6kOkJ8CNzSAY
+i7McJl07kv0/YWxp4R87kOVFjP5GDq6ENheiD1F/8BPltVIBoaYaBi4s5ifvMpBe
+8STlLEPEiQNFW755dVbyuhFPZLfQTSzVutJi1Wo3+kD1AgA=
 -----END COZY ASSET-----
 -----BEGIN COZY ASSET-----
 Name: /templates/twofactor.html
-Size: 3770
+Size: 3807
 
-G7kOIBwH5ZYzZdoIKWzeQf471fYs0xfjSQ+ulLrmvF3FBtspgGlpyA3St6cwYHy/
-/d5sFSlXIWxzk8k5jwrEwvBkMv8XYYGFX6GAhTVr5C7DqTHT/6RpCdEKvWWEgQbZ
-dCl8VqIjeozdTlhA5C1Qe8h1sA8H3cJaNHifHp12wbUV2CwGZi41Cy0KqZg1Hro5
-wIUKY1GJD1g+J8AzieZe+DxRmGPq9piVj6ePeQb4YCkyRp2Ie8dDTHBaFT8Iq6ar
-eSNNxNBMxvbihSD3atxui/o/MTOLm8FKQa1/C/FnbWYh5LiZfeEtq9071qtqENew
-L0UaigJ+OIq2MffHVcfIeMOYH2iDabfBLQ9MRUwhPwvgXNKqeKr9VtLaQfgLfDk5
-eRyujkrqpsypF/CcVKbGFPSeID9tOh7a33OtlORCMiFzmFevLz1EAhcrmbf4myl8
-CmFwlk9ff9TH860OesNPCmCO9fTLWa+iOduUpKk1LyWrB3rfxe0Amn4sV2v3mltU
-KatXpC13EbKFGmbkXlaGPoXCnLDdFLb8TKleQUSEYyK1LtvaVLsE0BeR3EAK7VJv
-6HkZwa2nw5cwEw3sraKKp+qDX9G0ZBzc9D0VaHgy+g1mbpWSiI0VpuySMhomsXq3
-9gOw9FygNengh8Rd3BHyK8b4JKdBh2Xd9rhACtGgbU8x/5qrwZbe0zaN9OSnfOZE
-cV8acIg5VZz0FHBHk4l5JiO8AiWDzhlt9I1+8uR2W3pauqz6x17E5UT5dc0RF44g
-pprZdLd0iJ2KL/fH1B/CKDI1jibyazeoWMQ2M9XaTd5A66DDjf6jQdsMacLU2PWY
-+sk42ZOTgXTuUOVJKYYHopQjfVvhr0Oy3MsILwzh4PYaB/vlsoV3AwCDUkaHmM+p
-MK9ddmyAyEMjXyiIu59LDh5cK3da8z6rkWZGA1L+1SP3fe3j4meP0YBOGqdSWJXN
-3VnjpDWnLKM74515b1n0B1VxAooE2r+/zFriT0ErbdNMLuNs4Wx3fdkcntlaqBG5
-0L7zwO9GYKchg7Ovwtqq6sDYgPm6w9prXC3CxWhp7/V350VbBelvlTzDVQg1uQ1O
-hAzTj27TrXtjviJAEVLy3kt42T6jOp2WyAqGJHLQk+aOhmwYKhkO9SF8XvL6zppd
-mczH51SAy3cMX1LHyoT2VTkyvxIovuKjpVw+QbhseacGZG2NctaWMxl9BtKtbmSy
-kgwMtJUlXojXU0Xh8C1tkiXaw6dQVkH/dWynySRVHIZYsNiaMlvxqBI1oqG7Jl0F
-UfBSCrFhmGhcVmOp/CxryVf1+ofn0XGor7Pah0V9a/eWDGIB
+G94OIJwH5VZnDMiTlDB5C/nv1Nxvmb4YT3qQUup68Zbq0iIDprgiCmSv9/pe4AaM
+77ffm60i5SqEbW4yOedRgdARTibvl3iJhF+hgIU1a+Qu4tyBXq1ZatEpVsMNNMhG
+Q+CjEm0uY4wCfD+699662M2GTDHyFkRzItZJrteWs1yL8vvp4UkX3HyB7mJgJlsz
+FaPUHdPKY9f7uNCCzDvxHhPsBHiuswmBjySFUajuxtR+PMDM08gHQ0idKz1xc7mH
+jfQK9yQsq67kc20iCn/vMYYXnN0u1+uC7D+RrcLNYLmorX8L8WfNW2sHtx6Pz2rt
+wVhSRc+/YZeL0OMEfK8TM8vc78aaUequGTcAbdD11tvlzBREFPLDAU74siieisM5
+P2lIgwxvJ4eXw/ZR1l1OB98IeA7KZAMFBKEgP2vAHmbI56otiQWrggkx8h6FywQu
+ljpv8dsW+BTC4Mwf33/S+9O1tILEDwpgDuXu7VofIkpPKMi817yQbB4oAhe3A2jS
+mE0m/RKbJaFWUvqd7s7bQUEuM3IvqoaAhQQdUvUOtvgINql15c5W42OC5rEF2wgV
+RbJfNqVBewmgL8LrngpUXTnT4yysngbW59AlFZSyoooH/YOGMf/J2APqeMgwd8ro
+15y5EoIn1uuYonOKqBgX680qGcDSY4ZZTgdjJe7i3tJ3jIZKDqgOE8Tt0ZYKOIkZ
+gIqNoLHqkektrfhIdn7KWy2K+1KPA8yp4qQHkzvSTXQ4aeo5FKmVQ/0xGlA/edTr
+Qo+dHor02NS4mii/Ll7i0k2FmGZm/d7QPrY8vtwcUXMAWZDMcDgmgHaDhoUDNJZM
+ehgIZiHaG6ORadBWD0Myw7ZNZs+M4x1ZIggX9k2elrrEGVpzJIML/AlJZpvUwVSD
+z7g6YX+vXLbwrpdgUEo/E/OaCvParmYZIjOODCYVLm4sWYGwv9wB0vtUCd/KEpDy
+/yC573MP599bRhkdlroIYXk399vckqtOSEa3xoTzPmvVP2oVR6BIoP17bUX80ZZF
+qXybdm7ccRVNDjX2tqoVSrg3tGve97sR2FGk1iNQZm1UdKCXwWRWYxE3rhThYjS0
+89Jdq1QFpL/R6ulNrD/KM+qEXzH6oOdu3SvTFQEKk5KJn8MU9wnV6TRHVFAuwT9M
+Uyf8lRwGsaVQH8KnJS91luxKZD4+pwFcumP4nDpWIrQfDkW6jUBOGB9szdWvEC5b
+vWOFam2s5qx+52r0CUg3upZhT5IZSCtbecGpTxWFfby0ShaXEC8hrIL8a1pGSXpR
+HHIsWGxLmY1YWYkWUf9egy6nKFgp2dYME/Xwqky6X0tb8kU9gd5/ZziUV1Enh0Xt
+bfea9HQB
 -----END COZY ASSET-----
 `
 	fs.Register(data)
@@ -0,0 +1,81 @@
+// Package useractions exposes the action center of the konnectors: the
+// actions required from the user to fix their accounts.
+package useractions
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/useraction"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/jsonapi"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+func listActions(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	if err := middlewares.AllowWholeType(c, permission.GET, consts.UserActions); err != nil {
+		return err
+	}
+	status := c.QueryParam("status")
+	switch status {
+	case "", useraction.StatusOpen, useraction.StatusResolved, useraction.StatusDismissed:
+	default:
+		return jsonapi.InvalidParameter("status", useraction.ErrInvalidStatus)
+	}
+	actions, err := useraction.List(inst, status)
+	if err != nil {
+		return err
+	}
+	objs := make([]jsonapi.Object, len(actions))
+	for i, a := range actions {
+		objs[i] = a
+	}
+	return jsonapi.DataList(c, http.StatusOK, objs, nil)
+}
+
+func getAction(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	action, err := useraction.Find(inst, c.Param("id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := middlewares.Allow(c, permission.GET, action); err != nil {
+		return err
+	}
+	return jsonapi.Data(c, http.StatusOK, action, nil)
+}
+
+func dismissAction(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	action, err := useraction.Find(inst, c.Param("id"))
+	if err != nil {
+		return wrapError(err)
+	}
+	if err := middlewares.Allow(c, permission.PATCH, action); err != nil {
+		return err
+	}
+	if err := useraction.Dismiss(inst, action); err != nil {
+		return wrapError(err)
+	}
+	return jsonapi.Data(c, http.StatusOK, action, nil)
+}
+
+func wrapError(err error) error {
+	if couchdb.IsNotFoundError(err) || couchdb.IsNoDatabaseError(err) {
+		return jsonapi.NotFound(err)
+	}
+	if err == useraction.ErrNotOpen {
+		return jsonapi.Conflict(err)
+	}
+	return err
+}
+
+// Routes sets the routing for the action center.
+func Routes(router *echo.Group) {
+	router.GET("", listActions)
+	router.GET("/:id", getAction)
+	router.POST("/:id/dismiss", dismissAction)
+}
@@ -0,0 +1,414 @@
+package webdav
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/web/files"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/webdav"
+)
+
+// fileSystem is the implementation of webdav.FileSystem for the VFS of an
+// instance. It is created for each request, as the permissions are checked
+// with the echo context.
+type fileSystem struct {
+	c  echo.Context
+	fs vfs.VFS
+	// infos is a cache of the children listed in a directory, as the
+	// PROPFIND handler calls Stat for each of them.
+	infos map[string]os.FileInfo
+}
+
+func newFileSystem(c echo.Context, inst *instance.Instance) *fileSystem {
+	return &fileSystem{
+		c:     c,
+		fs:    inst.VFS(),
+		infos: make(map[string]os.FileInfo),
+	}
+}
+
+// isHidden returns true for the paths that are not exposed via WebDAV: the
+// trash is only available via the API.
+func isHidden(name string) bool {
+	return name == vfs.TrashDirName || strings.HasPrefix(name, vfs.TrashDirName+"/")
+}
+
+// wrapError converts the errors of the permissions to the errors understood
+// by the WebDAV handler.
+func wrapError(err error) error {
+	if err == middlewares.ErrForbidden {
+		return os.ErrPermission
+	}
+	return err
+}
+
+func (dfs *fileSystem) checkPerm(v permission.Verb, d *vfs.DirDoc, f *vfs.FileDoc) error {
+	var err error
+	if d != nil {
+		err = middlewares.AllowVFS(dfs.c, v, d)
+	} else {
+		err = middlewares.AllowVFS(dfs.c, v, f)
+	}
+	return wrapError(err)
+}
+
+func (dfs *fileSystem) lookup(name string) (*vfs.DirDoc, *vfs.FileDoc, error) {
+	if isHidden(name) {
+		return nil, nil, os.ErrNotExist
+	}
+	return dfs.fs.DirOrFileByPath(name)
+}
+
+// Mkdir creates a directory.
+func (dfs *fileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if isHidden(name) {
+		return os.ErrPermission
+	}
+	parent, err := dfs.fs.DirByPath(path.Dir(name))
+	if err != nil {
+		return err
+	}
+	dir, err := vfs.NewDirDocWithPath(path.Base(name), parent.DocID, parent.Fullpath, nil)
+	if err != nil {
+		return err
+	}
+	dir.CozyMetadata, _ = files.CozyMetadataFromClaims(dfs.c, false)
+	if err := dfs.checkPerm(permission.POST, dir, nil); err != nil {
+		return err
+	}
+	return dfs.fs.CreateDir(dir)
+}
+
+// OpenFile opens a directory or a file for reading, or a file for writing
+// when the O_TRUNC flag is given (a new version of the file is uploaded).
+func (dfs *fileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	dir, file, err := dfs.lookup(name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	write := flag&os.O_TRUNC != 0 || (file == nil && flag&os.O_CREATE != 0)
+
+	if dir != nil {
+		if write {
+			return nil, os.ErrInvalid
+		}
+		if err := dfs.checkPerm(permission.GET, dir, nil); err != nil {
+			return nil, err
+		}
+		return &davFile{dfs: dfs, dir: dir}, nil
+	}
+
+	if !write {
+		if file == nil {
+			return nil, os.ErrNotExist
+		}
+		if err := dfs.checkPerm(permission.GET, nil, file); err != nil {
+			return nil, err
+		}
+		return &davFile{dfs: dfs, file: file}, nil
+	}
+	return dfs.create(name, file)
+}
+
+// create returns a file for writing the content of a new file, or a new
+// version of an existing file.
+func (dfs *fileSystem) create(name string, olddoc *vfs.FileDoc) (webdav.File, error) {
+	if isHidden(name) {
+		return nil, os.ErrPermission
+	}
+	var newdoc *vfs.FileDoc
+	var err error
+	size := dfs.expectedSize(name)
+	if olddoc != nil {
+		newdoc, err = vfs.NewFileDoc(olddoc.DocName, olddoc.DirID, size, nil,
+			olddoc.Mime, olddoc.Class, olddoc.CreatedAt, olddoc.Executable,
+			false, olddoc.Encrypted, olddoc.Tags)
+		if err != nil {
+			return nil, err
+		}
+		newdoc.SetID(olddoc.ID())
+		newdoc.ReferencedBy = olddoc.ReferencedBy
+		if olddoc.CozyMetadata != nil {
+			newdoc.CozyMetadata = olddoc.CozyMetadata.Clone()
+		}
+		if err := dfs.checkPerm(permission.PUT, nil, olddoc); err != nil {
+			return nil, err
+		}
+	} else {
+		parent, err := dfs.fs.DirByPath(path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		filename := path.Base(name)
+		mime, class := vfs.ExtractMimeAndClassFromFilename(filename)
+		newdoc, err = vfs.NewFileDoc(filename, parent.DocID, size, nil, mime, class,
+			time.Now(), false, false, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		newdoc.CozyMetadata, _ = files.CozyMetadataFromClaims(dfs.c, true)
+		if err := dfs.checkPerm(permission.POST, nil, newdoc); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := dfs.fs.CreateFile(newdoc, olddoc)
+	if err != nil {
+		return nil, err
+	}
+	return &davFile{dfs: dfs, file: newdoc, content: content, writing: true}, nil
+}
+
+// expectedSize returns the size of the content uploaded for the given path,
+// if it is known, so that the VFS can reject an incomplete upload. Else, it
+// returns -1.
+func (dfs *fileSystem) expectedSize(name string) int64 {
+	req := dfs.c.Request()
+	if req.Method != http.MethodPut {
+		return -1
+	}
+	if req.ContentLength >= 0 {
+		return req.ContentLength
+	}
+	// The Finder of macOS sends the content in chunks, with the size in this
+	// header
+	if expected := req.Header.Get("X-Expected-Entity-Length"); expected != "" {
+		if size, err := strconv.ParseInt(expected, 10, 64); err == nil && size >= 0 {
+			return size
+		}
+	}
+	return -1
+}
+
+// RemoveAll moves a file or a directory to the trash.
+func (dfs *fileSystem) RemoveAll(ctx context.Context, name string) error {
+	dir, file, err := dfs.lookup(name)
+	if err != nil {
+		return err
+	}
+	if err := dfs.checkPerm(permission.DELETE, dir, file); err != nil {
+		return err
+	}
+	if dir != nil {
+		if dir.DocID == consts.RootDirID {
+			return os.ErrPermission
+		}
+		_, err = vfs.TrashDir(dfs.fs, dir)
+		return err
+	}
+	_, err = vfs.TrashFile(dfs.fs, file)
+	return err
+}
+
+// Rename moves and/or renames a file or a directory.
+func (dfs *fileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	if isHidden(newName) {
+		return os.ErrPermission
+	}
+	dir, file, err := dfs.lookup(oldName)
+	if err != nil {
+		return err
+	}
+	if err := dfs.checkPerm(permission.PATCH, dir, file); err != nil {
+		return err
+	}
+	parent, err := dfs.fs.DirByPath(path.Dir(newName))
+	if err != nil {
+		return err
+	}
+	name := path.Base(newName)
+	patch := &vfs.DocPatch{Name: &name}
+	if path.Dir(oldName) != path.Dir(newName) {
+		if err := dfs.checkPerm(permission.POST, parent, nil); err != nil {
+			return err
+		}
+		patch.DirID = &parent.DocID
+	}
+	if dir != nil {
+		if dir.DocID == consts.RootDirID {
+			return os.ErrPermission
+		}
+		_, err = vfs.ModifyDirMetadata(dfs.fs, dir, patch)
+		return err
+	}
+	_, err = vfs.ModifyFileMetadata(dfs.fs, file, patch)
+	return err
+}
+
+// Stat returns the information about a file or a directory.
+func (dfs *fileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	if info, ok := dfs.infos[name]; ok {
+		return info, nil
+	}
+	dir, file, err := dfs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := dfs.checkPerm(permission.GET, dir, file); err != nil {
+		return nil, err
+	}
+	if dir != nil {
+		return dir, nil
+	}
+	return &fileInfo{file}, nil
+}
+
+// fileInfo adds the ETag and the content type of a file to its os.FileInfo,
+// so that the WebDAV handler doesn't have to compute them.
+type fileInfo struct {
+	*vfs.FileDoc
+}
+
+// ETag returns the md5sum of the file, or ErrNotImplemented if it is not
+// known yet (during an upload).
+func (fi *fileInfo) ETag(ctx context.Context) (string, error) {
+	if len(fi.MD5Sum) == 0 {
+		return "", webdav.ErrNotImplemented
+	}
+	return `"` + hex.EncodeToString(fi.MD5Sum) + `"`, nil
+}
+
+// ContentType returns the mime-type of the file.
+func (fi *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if fi.Mime == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return fi.Mime, nil
+}
+
+// davFile is the implementation of webdav.File for a directory, or a file
+// opened for reading or writing.
+type davFile struct {
+	dfs     *fileSystem
+	dir     *vfs.DirDoc
+	file    *vfs.FileDoc
+	content vfs.File
+	writing bool
+	// children and offset are used for listing the content of a directory
+	children []os.FileInfo
+	offset   int
+}
+
+func (f *davFile) open() error {
+	if f.content != nil {
+		return nil
+	}
+	if f.file == nil {
+		return os.ErrInvalid
+	}
+	if !vfs.CanDownload(f.file) {
+		return os.ErrPermission
+	}
+	content, err := f.dfs.fs.OpenFile(f.file)
+	if err != nil {
+		return err
+	}
+	f.content = content
+	return nil
+}
+
+func (f *davFile) Read(p []byte) (int, error) {
+	if f.writing {
+		return 0, os.ErrInvalid
+	}
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.content.Read(p)
+}
+
+func (f *davFile) Seek(offset int64, whence int) (int64, error) {
+	if f.writing {
+		return 0, os.ErrInvalid
+	}
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.content.Seek(offset, whence)
+}
+
+func (f *davFile) Write(p []byte) (int, error) {
+	if !f.writing {
+		return 0, os.ErrInvalid
+	}
+	return f.content.Write(p)
+}
+
+func (f *davFile) Close() error {
+	if f.content == nil {
+		return nil
+	}
+	return f.content.Close()
+}
+
+func (f *davFile) Stat() (os.FileInfo, error) {
+	if f.dir != nil {
+		return f.dir, nil
+	}
+	return &fileInfo{f.file}, nil
+}
+
+// Readdir returns the content of the directory, without the trash.
+func (f *davFile) Readdir(count int) ([]os.FileInfo, error) {
+	if f.dir == nil {
+		return nil, os.ErrInvalid
+	}
+	if f.children == nil {
+		children, err := f.listChildren()
+		if err != nil {
+			return nil, err
+		}
+		f.children = children
+	}
+	remaining := f.children[f.offset:]
+	if count <= 0 {
+		f.offset = len(f.children)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	f.offset += count
+	return remaining[:count], nil
+}
+
+func (f *davFile) listChildren() ([]os.FileInfo, error) {
+	children := []os.FileInfo{}
+	iter := f.dfs.fs.DirIterator(f.dir, &vfs.IteratorOptions{ByFetch: 256})
+	for {
+		d, file, err := iter.Next()
+		if err == vfs.ErrIteratorDone {
+			return children, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var info os.FileInfo
+		if d != nil {
+			if d.DocID == consts.TrashDirID {
+				continue
+			}
+			info = d
+		} else {
+			info = &fileInfo{file}
+		}
+		children = append(children, info)
+		f.dfs.infos[path.Join(f.dir.Fullpath, info.Name())] = info
+	}
+}
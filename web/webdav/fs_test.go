@@ -0,0 +1,61 @@
+package webdav
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+func TestIsHidden(t *testing.T) {
+	assert.True(t, isHidden("/.cozy_trash"))
+	assert.True(t, isHidden("/.cozy_trash/foo.txt"))
+	assert.False(t, isHidden("/"))
+	assert.False(t, isHidden("/.cozy_trash_old"))
+	assert.False(t, isHidden("/Documents/.cozy_trash"))
+}
+
+func TestFileInfo(t *testing.T) {
+	ctx := context.Background()
+	fi := &fileInfo{&vfs.FileDoc{DocName: "foo.txt", Mime: "text/plain"}}
+	_, err := fi.ETag(ctx)
+	assert.Equal(t, webdav.ErrNotImplemented, err)
+	mime, err := fi.ContentType(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", mime)
+
+	fi.MD5Sum = []byte{0xca, 0xfe, 0xba, 0xbe}
+	etag, err := fi.ETag(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `"cafebabe"`, etag)
+	assert.Equal(t, "foo.txt", fi.Name())
+	assert.False(t, fi.IsDir())
+}
+
+func TestReaddir(t *testing.T) {
+	children := []os.FileInfo{
+		&vfs.DirDoc{DocName: "Documents"},
+		&fileInfo{&vfs.FileDoc{DocName: "a.txt"}},
+		&fileInfo{&vfs.FileDoc{DocName: "b.txt"}},
+	}
+	f := &davFile{dir: &vfs.DirDoc{DocName: "/"}, children: children}
+	infos, err := f.Readdir(2)
+	require.NoError(t, err)
+	assert.Len(t, infos, 2)
+	infos, err = f.Readdir(2)
+	require.NoError(t, err)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "b.txt", infos[0].Name())
+	_, err = f.Readdir(2)
+	assert.Equal(t, io.EOF, err)
+
+	f = &davFile{dir: &vfs.DirDoc{DocName: "/"}, children: children}
+	infos, err = f.Readdir(0)
+	require.NoError(t, err)
+	assert.Len(t, infos, 3)
+}
@@ -0,0 +1,96 @@
+// Package webdav exposes the VFS of an instance through a WebDAV server, so
+// that the users can mount their Cozy as a network drive in their file
+// manager (Finder, Explorer, Nautilus, etc.) without a dedicated client.
+package webdav
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/cozy/cozy-stack/model/apppassword"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/webdav"
+)
+
+// prefix is the path where the WebDAV server is mounted.
+const prefix = "/webdav"
+
+// methods is the list of the HTTP methods used by the WebDAV clients.
+var methods = []string{
+	http.MethodOptions,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodDelete,
+	"PROPFIND",
+	"PROPPATCH",
+	"MKCOL",
+	"COPY",
+	"MOVE",
+	"LOCK",
+	"UNLOCK",
+}
+
+var (
+	lockSystemsMu sync.Mutex
+	lockSystems   = make(map[string]webdav.LockSystem)
+)
+
+// lockSystemFor returns the lock system for the instance. The locks are kept
+// in memory, and expire after the timeout asked by the client.
+func lockSystemFor(inst *instance.Instance) webdav.LockSystem {
+	lockSystemsMu.Lock()
+	defer lockSystemsMu.Unlock()
+	ls, ok := lockSystems[inst.Domain]
+	if !ok {
+		ls = webdav.NewMemLS()
+		lockSystems[inst.Domain] = ls
+	}
+	return ls
+}
+
+// authenticate checks that the request has a token (for an app or an OAuth
+// client), or an app password for WebDAV sent via HTTP basic authentication.
+func authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	withAppPassword := middlewares.AppPasswordAuth(apppassword.ProtocolWebDAV)(next)
+	return func(c echo.Context) error {
+		if _, _, ok := c.Request().BasicAuth(); ok {
+			return withAppPassword(c)
+		}
+		if _, err := middlewares.GetPermission(c); err != nil {
+			inst := middlewares.GetInstance(c)
+			challenge := fmt.Sprintf(`Basic realm="%s", charset="UTF-8"`, inst.Domain)
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		return next(c)
+	}
+}
+
+// Handler serves the WebDAV requests.
+func Handler(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	h := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: newFileSystem(c, inst),
+		LockSystem: lockSystemFor(inst),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				inst.Logger().WithNamespace("webdav").
+					Debugf("%s %s: %s", r.Method, r.URL.Path, err)
+			}
+		},
+	}
+	h.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// Routes sets the routing for the WebDAV server
+func Routes(router *echo.Group) {
+	router.Match(methods, "", Handler, authenticate)
+	router.Match(methods, "/*", Handler, authenticate)
+}
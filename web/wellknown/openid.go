@@ -0,0 +1,66 @@
+package wellknown
+
+import (
+	"net/http"
+
+	"github.com/cozy/cozy-stack/model/oauth"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/web/middlewares"
+	"github.com/labstack/echo/v4"
+)
+
+// OpenIDConfiguration is the discovery document of OpenID Connect.
+// See https://openid.net/specs/openid-connect-discovery-1_0.html
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	RegistrationEndpoint              string   `json:"registration_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// OpenIDDiscovery returns the discovery document of OpenID Connect for the
+// instance, where the instance is the identity provider.
+func OpenIDDiscovery(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+	return c.JSON(http.StatusOK, OpenIDConfiguration{
+		Issuer:                            oauth.Issuer(inst),
+		AuthorizationEndpoint:             inst.PageURL("/auth/authorize", nil),
+		TokenEndpoint:                     inst.PageURL("/auth/access_token", nil),
+		UserInfoEndpoint:                  inst.PageURL("/auth/userinfo", nil),
+		JWKSURI:                           inst.PageURL("/.well-known/jwks.json", nil),
+		RegistrationEndpoint:              inst.PageURL("/auth/register", nil),
+		ScopesSupported:                   []string{permission.ScopeOpenID, permission.ScopeProfile, permission.ScopeEmail},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "nonce",
+			"name", "preferred_username", "locale", "website", "email",
+		},
+	})
+}
+
+// JWKS returns the public keys used to sign the ID tokens of the instance.
+func JWKS(c echo.Context) error {
+	inst := middlewares.GetInstance(c)
+	keys, err := oauth.GetJWKS(inst)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+	return c.JSON(http.StatusOK, echo.Map{"keys": keys})
+}
@@ -0,0 +1,213 @@
+package wellknown
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/sharing"
+	build "github.com/cozy/cozy-stack/pkg/config"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/idna"
+)
+
+// The relations and properties used in the JRD documents to describe the
+// endpoints and capabilities of an instance.
+const (
+	RelOAuthRegistration = "https://cozy.io/rel/oauth/registration"
+	RelOAuthAuthorize    = "https://cozy.io/rel/oauth/authorize"
+	RelOAuthToken        = "https://cozy.io/rel/oauth/token"
+	RelSharings          = "https://cozy.io/rel/sharings"
+
+	PropStackVersion           = "https://cozy.io/ns/stack/version"
+	PropSharingProtocolVersion = "https://cozy.io/ns/sharing/protocol-version"
+	PropSharingCapabilities    = "https://cozy.io/ns/sharing/capabilities"
+)
+
+// MIMEJRD is the content-type of the WebFinger responses.
+const MIMEJRD = "application/jrd+json"
+
+// JRD is a JSON Resource Descriptor, as defined in RFC 7033.
+type JRD struct {
+	Subject    string             `json:"subject,omitempty"`
+	Aliases    []string           `json:"aliases,omitempty"`
+	Properties map[string]*string `json:"properties,omitempty"`
+	Links      []Link             `json:"links,omitempty"`
+}
+
+// Link is a link in a JRD document.
+type Link struct {
+	Rel      string `json:"rel"`
+	Type     string `json:"type,omitempty"`
+	Href     string `json:"href,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+// WebFinger returns the JRD document of an instance, with its OAuth and
+// sharing endpoints and the versions of the protocols that it supports. The
+// resource can be an email-like identifier (acct:alice@cozy.example.net) or
+// the URL of the instance. It can be queried on the host of the instance, or
+// on the domain of the acct: identifier.
+// See https://www.rfc-editor.org/rfc/rfc7033
+func WebFinger(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+	resource := c.QueryParam("resource")
+	if resource == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "The resource parameter is missing")
+	}
+
+	err := config.GetRateLimiter().CheckRateLimitKey(c.RealIP(), limits.WebFingerType)
+	if limits.IsLimitReachedOrExceeded(err) {
+		return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests")
+	}
+
+	host, err := idna.ToUnicode(c.Request().Host)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	candidates, ok := resourceDomains(resource, host)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "Unknown resource")
+	}
+	var inst *instance.Instance
+	for _, domain := range candidates {
+		if i, err := lifecycle.GetInstance(domain); err == nil {
+			inst = i.WithContextualDomain(domain)
+			break
+		}
+	}
+	if inst == nil || inst.Blocked || inst.Deleting {
+		return echo.NewHTTPError(http.StatusNotFound, "Unknown resource")
+	}
+
+	jrd := instanceJRD(inst, resource)
+	if rels := c.QueryParams()["rel"]; len(rels) > 0 {
+		jrd.Links = filterLinks(jrd.Links, rels)
+	}
+	c.Response().Header().Set(echo.HeaderContentType, MIMEJRD)
+	return c.JSON(http.StatusOK, jrd)
+}
+
+// resourceDomains returns the domains of the instances that can be described
+// by the resource, when it is queried on the given host. An instance can
+// only be found from a resource that designates the host: for an acct: URI,
+// the host must be its domain, and the instance is either on this domain or
+// on a subdomain named after the user part.
+func resourceDomains(resource, host string) ([]string, bool) {
+	if strings.HasPrefix(resource, "acct:") {
+		acct, err := url.PathUnescape(strings.TrimPrefix(resource, "acct:"))
+		if err != nil {
+			return nil, false
+		}
+		at := strings.LastIndex(acct, "@")
+		if at <= 0 || at == len(acct)-1 {
+			return nil, false
+		}
+		user, domain := strings.ToLower(acct[:at]), strings.ToLower(acct[at+1:])
+		if domain != host || strings.ContainsAny(user, "./:") {
+			return nil, false
+		}
+		return []string{domain, user + "." + domain}, true
+	}
+
+	u, err := url.Parse(resource)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, false
+	}
+	domain := strings.ToLower(u.Host)
+	if domain != host {
+		return nil, false
+	}
+	return []string{domain}, true
+}
+
+func instanceJRD(inst *instance.Instance, subject string) *JRD {
+	protocol := strconv.Itoa(sharing.ProtocolVersion)
+	capabilities := strings.Join(sharing.Capabilities, " ")
+	version := build.Version
+	return &JRD{
+		Subject: subject,
+		Aliases: []string{inst.PageURL("/", nil)},
+		Properties: map[string]*string{
+			PropStackVersion:           &version,
+			PropSharingProtocolVersion: &protocol,
+			PropSharingCapabilities:    &capabilities,
+		},
+		Links: []Link{
+			{Rel: RelOAuthRegistration, Type: echo.MIMEApplicationJSON, Href: inst.PageURL("/auth/register", nil)},
+			{Rel: RelOAuthAuthorize, Type: echo.MIMETextHTML, Href: inst.PageURL("/auth/authorize", nil)},
+			{Rel: RelOAuthToken, Type: echo.MIMEApplicationJSON, Href: inst.PageURL("/auth/access_token", nil)},
+			{Rel: RelSharings, Type: "application/vnd.api+json", Href: inst.PageURL("/sharings/", nil)},
+		},
+	}
+}
+
+func filterLinks(links []Link, rels []string) []Link {
+	filtered := make([]Link, 0, len(links))
+	for _, link := range links {
+		for _, rel := range rels {
+			if link.Rel == rel {
+				filtered = append(filtered, link)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// xrd is the XML format of the host-meta document, as defined in RFC 6415.
+type xrd struct {
+	XMLName xml.Name  `xml:"http://docs.oasis-open.org/ns/xri/xrd-1.0 XRD"`
+	Links   []xrdLink `xml:"Link"`
+}
+
+type xrdLink struct {
+	Rel      string `xml:"rel,attr"`
+	Type     string `xml:"type,attr,omitempty"`
+	Template string `xml:"template,attr,omitempty"`
+}
+
+func webfingerTemplate(c echo.Context) string {
+	scheme := "https"
+	if build.IsDevRelease() {
+		scheme = "http"
+	}
+	return scheme + "://" + c.Request().Host + "/.well-known/webfinger?resource={uri}"
+}
+
+// HostMeta returns the host-meta document in the XRD format, with a link to
+// the WebFinger endpoint.
+// See https://www.rfc-editor.org/rfc/rfc6415
+func HostMeta(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+	doc := xrd{Links: []xrdLink{
+		{Rel: "lrdd", Type: MIMEJRD, Template: webfingerTemplate(c)},
+	}}
+	c.Response().Header().Set(echo.HeaderContentType, "application/xrd+xml; charset=UTF-8")
+	return c.XML(http.StatusOK, doc)
+}
+
+// HostMetaJSON returns the host-meta document in the JRD format.
+func HostMetaJSON(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderAccessControlAllowOrigin, "*")
+	doc := JRD{Links: []Link{
+		{Rel: "lrdd", Type: MIMEJRD, Template: webfingerTemplate(c)},
+	}}
+	c.Response().Header().Set(echo.HeaderContentType, MIMEJRD)
+	return c.JSON(http.StatusOK, doc)
+}
+
+// DiscoveryRoutes sets the routing for the discovery endpoints. They don't
+// need an instance, as they can be queried on the domain of an email-like
+// identifier, which can be a parent domain of the instances.
+func DiscoveryRoutes(router *echo.Echo) {
+	router.GET("/.well-known/webfinger", WebFinger)
+	router.GET("/.well-known/host-meta", HostMeta)
+	router.GET("/.well-known/host-meta.json", HostMetaJSON)
+}
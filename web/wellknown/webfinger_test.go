@@ -0,0 +1,34 @@
+package wellknown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceDomains(t *testing.T) {
+	host := "cozy.example.net"
+
+	domains, ok := resourceDomains("acct:alice@cozy.example.net", host)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"cozy.example.net", "alice.cozy.example.net"}, domains)
+
+	domains, ok = resourceDomains("acct:Alice%40Cozy.Example.Net", "cozy.example.net")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"cozy.example.net", "alice.cozy.example.net"}, domains)
+
+	domains, ok = resourceDomains("https://alice.cozy.example.net/", "alice.cozy.example.net")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"alice.cozy.example.net"}, domains)
+
+	_, ok = resourceDomains("acct:alice@other.example.net", host)
+	assert.False(t, ok)
+	_, ok = resourceDomains("acct:bob.alice@cozy.example.net", host)
+	assert.False(t, ok)
+	_, ok = resourceDomains("acct:@cozy.example.net", host)
+	assert.False(t, ok)
+	_, ok = resourceDomains("https://bob.cozy.example.net/", host)
+	assert.False(t, ok)
+	_, ok = resourceDomains("mailto:alice@cozy.example.net", host)
+	assert.False(t, ok)
+}
@@ -19,4 +19,6 @@ func ChangePassword(c echo.Context) error {
 func Routes(router *echo.Group) {
 	router.GET("/change-password", ChangePassword)
 	router.HEAD("/change-password", ChangePassword)
+	router.GET("/openid-configuration", OpenIDDiscovery)
+	router.GET("/jwks.json", JWKS)
 }
@@ -0,0 +1,60 @@
+package antivirus
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/antivirus"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/vfs"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   antivirus.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 3,
+		Reserved:     true,
+		Timeout:      10 * time.Minute,
+		WorkerFunc:   Worker,
+	})
+}
+
+type fileEvent struct {
+	Verb   string       `json:"verb"`
+	Doc    vfs.FileDoc  `json:"doc"`
+	OldDoc *vfs.FileDoc `json:"old,omitempty"`
+}
+
+// Worker scans the content of a file that has been created or modified with
+// the antivirus.
+func Worker(ctx *job.WorkerContext) error {
+	if !antivirus.Enabled() {
+		return nil
+	}
+	var evt fileEvent
+	if err := ctx.UnmarshalEvent(&evt); err != nil {
+		return err
+	}
+	if evt.Verb == "DELETED" || evt.Doc.Trashed {
+		return nil
+	}
+	if evt.OldDoc != nil && string(evt.Doc.MD5Sum) == string(evt.OldDoc.MD5Sum) {
+		return nil
+	}
+
+	// The file may have been modified since the event
+	doc, err := ctx.Instance.VFS().FileByID(evt.Doc.ID())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if string(doc.MD5Sum) != string(evt.Doc.MD5Sum) {
+		return nil
+	}
+	return antivirus.ScanFile(ctx.Instance, doc)
+}
@@ -0,0 +1,41 @@
+// Package appdata is for the worker that removes the documents created by an
+// application after it has been uninstalled.
+package appdata
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   app.DataWorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      1 * time.Hour,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker exports (if asked) and deletes the documents created by an
+// uninstalled application.
+func Worker(ctx *job.WorkerContext) error {
+	var msg app.DataMessage
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	report, err := app.DisposeData(ctx.Instance, &msg)
+	if err != nil {
+		ctx.Logger().Errorf("Cannot remove the data of %s: %s", msg.Slug, err)
+		return err
+	}
+	for _, removed := range report.Removed {
+		ctx.Logger().Infof("%d documents of %s removed for %s",
+			removed.Count, removed.Doctype, msg.Slug)
+	}
+	return nil
+}
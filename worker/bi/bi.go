@@ -0,0 +1,34 @@
+// Package bi is for the worker that replays the webhooks of the bank
+// aggregator that have failed.
+package bi
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/bi"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   bi.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerReplay,
+	})
+}
+
+// WorkerReplay is the bi-webhook worker function. It replays a webhook from
+// the inbox that has failed.
+func WorkerReplay(ctx *job.WorkerContext) error {
+	var msg struct {
+		InboxID string `json:"inbox_id"`
+	}
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	return bi.Replay(ctx.Instance, msg.InboxID)
+}
@@ -0,0 +1,49 @@
+package dataimport
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/dataimport"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   dataimport.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      30 * time.Minute,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker imports the records of a CSV, ICS or vCard file as documents. The
+// progress is published as realtime events, and the report is attached to the
+// job as its result.
+func Worker(ctx *job.WorkerContext) error {
+	var opts dataimport.Options
+	if err := ctx.UnmarshalMessage(&opts); err != nil {
+		return err
+	}
+	opts.DryRun = false
+	report, err := dataimport.Import(ctx.Instance, &opts, func(done, total int) {
+		ctx.PublishProgress(map[string]interface{}{
+			"done":  done,
+			"total": total,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	ctx.Logger().Infof("Import of %s: %d created, %d updated, %d skipped, %d invalid",
+		opts.Doctype, report.Created, report.Updated, report.Skipped, report.Invalid)
+	if err := ctx.SetResult(report); errors.Is(err, job.ErrResultTooLarge) {
+		// The errors are the only part of the report that can be large
+		report.Errors = nil
+		err = ctx.SetResult(report)
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package diskusage
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/diskusage"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/notification/center"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   diskusage.SnapshotWorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerSnapshot,
+	})
+}
+
+// WorkerSnapshot takes a snapshot of the disk usage of the instance, and
+// warns the user if their quota will be reached soon at the current rate.
+func WorkerSnapshot(ctx *job.WorkerContext) error {
+	inst := ctx.Instance
+	if _, err := diskusage.TakeSnapshot(inst); err != nil {
+		return err
+	}
+	if err := diskusage.CleanOldSnapshots(inst); err != nil {
+		ctx.Logger().Warnf("Cannot clean the old snapshots: %s", err)
+	}
+
+	delay, ok := diskusage.ForecastAlertDelay(inst.ContextName)
+	if !ok {
+		return nil
+	}
+	trend, err := diskusage.GetTrend(inst, diskusage.DefaultTrendDays)
+	if err != nil {
+		return err
+	}
+	days := 0
+	if trend.DaysUntilFull != nil {
+		days = *trend.DaysUntilFull
+	}
+	return center.PushDiskForecast(inst, days, trend.ShouldAlert(delay))
+}
@@ -16,12 +16,15 @@ import (
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/instance/lifecycle"
 	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/konnectorstats"
 	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/model/useraction"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/appfs"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
 	"github.com/cozy/cozy-stack/pkg/couchdb"
+	"github.com/cozy/cozy-stack/pkg/egress"
 	"github.com/cozy/cozy-stack/pkg/logger"
 	"github.com/cozy/cozy-stack/pkg/metadata"
 	"github.com/cozy/cozy-stack/pkg/realtime"
@@ -53,6 +56,9 @@ const (
 	konnectorMsgTypeWarning  = "warning"
 	konnectorMsgTypeError    = "error"
 	konnectorMsgTypeCritical = "critical"
+	// konnectorMsgTypeResult is used by the konnectors and services to attach
+	// a result to their job
+	konnectorMsgTypeResult = "result"
 )
 
 // KonnectorMessage is the message structure sent to the konnector worker.
@@ -580,6 +586,24 @@ func (w *konnectorWorker) PrepareCmdEnv(ctx *job.WorkerContext, i *instance.Inst
 	if triggerID, ok := ctx.TriggerID(); ok {
 		env = append(env, "COZY_TRIGGER_ID="+triggerID)
 	}
+	if proxyURL := KonnectorProxyURL(ctx.ID(), token); proxyURL != "" {
+		// The requests to the stack must not go through the proxy
+		noProxy := i.ContextualDomain()
+		env = append(env,
+			"HTTP_PROXY="+proxyURL,
+			"HTTPS_PROXY="+proxyURL,
+			"NO_PROXY="+noProxy,
+			"http_proxy="+proxyURL,
+			"https_proxy="+proxyURL,
+			"no_proxy="+noProxy,
+		)
+	} else {
+		// Without the konnectors proxy, the konnector uses directly the
+		// egress proxy of the context
+		env = append(env, egress.ProxyEnv(i.ContextName, i.ContextualDomain())...)
+	}
+	// The TLS connections are made by the konnector, even via the proxies
+	env = append(env, egress.CAEnv(i.ContextName)...)
 	return
 }
 
@@ -589,13 +613,17 @@ func (w *konnectorWorker) Logger(ctx *job.WorkerContext) logger.Logger {
 
 func (w *konnectorWorker) ScanOutput(ctx *job.WorkerContext, i *instance.Instance, line []byte) error {
 	var msg struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-		NoRetry bool   `json:"no_retry"`
+		Type    string          `json:"type"`
+		Message string          `json:"message"`
+		NoRetry bool            `json:"no_retry"`
+		Result  json.RawMessage `json:"result"`
 	}
 	if err := json.Unmarshal(line, &msg); err != nil {
 		return fmt.Errorf("Could not parse stdout as JSON: %q", string(line))
 	}
+	if msg.Type == konnectorMsgTypeResult {
+		return setResult(ctx, w.Logger(ctx), msg.Result)
+	}
 
 	// Truncate very long messages
 	if len(msg.Message) > 4000 {
@@ -632,6 +660,15 @@ func (w *konnectorWorker) ScanOutput(ctx *job.WorkerContext, i *instance.Instanc
 	return nil
 }
 
+// setResult attaches the result sent on stdout by a konnector or a service to
+// its job. A result that is too large is ignored, with a warning.
+func setResult(ctx *job.WorkerContext, log logger.Logger, result json.RawMessage) error {
+	if err := ctx.SetResult(result); err != nil {
+		log.Warnf("Cannot set the result of the job: %s", err)
+	}
+	return nil
+}
+
 func (w *konnectorWorker) Error(i *instance.Instance, err error) error {
 	if w.err != nil {
 		return w.err
@@ -671,5 +708,54 @@ func (w *konnectorWorker) Commit(ctx *job.WorkerContext, errjob error) error {
 	} else {
 		log.Infof("Konnector failure: %s", errjob)
 	}
+	w.updateUserAction(ctx, errjob)
+	if w.man != nil && (w.msg == nil || !w.msg.AccountDeleted) {
+		if err := konnectorstats.Record(ctx.Instance, w.slug, w.man.Version(), errjob); err != nil {
+			log.Warnf("Cannot record the stats: %s", err)
+		}
+	}
 	return nil
 }
+
+// updateUserAction opens an action in the action center when the konnector
+// has failed with an error that the user must fix, and resolves it when the
+// konnector has succeeded.
+func (w *konnectorWorker) updateUserAction(ctx *job.WorkerContext, errjob error) {
+	if w.msg == nil || w.msg.Account == "" {
+		return
+	}
+	inst := ctx.Instance
+	log := w.Logger(ctx).WithField("account_id", w.msg.Account)
+	if errjob == nil || w.msg.AccountDeleted {
+		by := useraction.ResolvedByKonnector
+		if w.msg.AccountDeleted {
+			by = useraction.ResolvedByAccountDeleted
+		}
+		if err := useraction.Resolve(inst, w.msg.Account, by); err != nil {
+			log.Warnf("Cannot resolve the user action: %s", err)
+		}
+		return
+	}
+
+	failure := useraction.Failure{
+		Konnector: w.slug,
+		AccountID: w.msg.Account,
+		Error:     errjob.Error(),
+	}
+	failure.TriggerID, _ = ctx.TriggerID()
+	name := w.slug
+	if w.man != nil {
+		name = w.man.Name()
+		failure.VendorLink, _ = w.man.VendorLink().(string)
+	}
+	action, opened, err := useraction.Record(inst, failure)
+	if err != nil {
+		log.Warnf("Cannot record the user action: %s", err)
+		return
+	}
+	if opened {
+		if err := useraction.Notify(inst, action, name); err != nil {
+			log.Warnf("Cannot notify the user action: %s", err)
+		}
+	}
+}
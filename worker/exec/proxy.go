@@ -0,0 +1,356 @@
+package exec
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/app"
+	"github.com/cozy/cozy-stack/model/feature"
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/instance/lifecycle"
+	"github.com/cozy/cozy-stack/model/permission"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/pkg/crypto"
+	"github.com/cozy/cozy-stack/pkg/egress"
+	"github.com/cozy/cozy-stack/pkg/limits"
+	"github.com/cozy/cozy-stack/pkg/logger"
+	"github.com/cozy/cozy-stack/pkg/safehttp"
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// proxySessionTTL is the duration during which the proxy keeps in memory the
+// informations about a konnector execution (allowed domains, etc.).
+const proxySessionTTL = 1 * time.Minute
+
+// recordFlag is the feature flag that can be used to record the metadata of
+// the HTTP requests made by the konnectors of an instance.
+const recordFlag = "konnectors.proxy.record"
+
+var errProxyAuth = errors.New("invalid proxy credentials")
+
+// hopHeaders are the headers that must not be forwarded by a proxy.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// KonnectorProxy is a forward proxy for the HTTP requests of the konnectors.
+// The konnectors use it via the HTTP_PROXY and HTTPS_PROXY env variables,
+// with the job ID and the konnector token as credentials. It checks that the
+// requested domain is allowed by the manifest of the konnector, applies the
+// rate limits per vendor (shared by all the instances of the stack), and can
+// record the metadata of the requests for debugging.
+type KonnectorProxy struct {
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	sessions map[string]*proxySession
+}
+
+type proxySession struct {
+	domain    string
+	context   string
+	slug      string
+	allowed   []string
+	record    bool
+	expiresAt time.Time
+}
+
+// NewKonnectorProxy returns a new proxy for the konnectors.
+func NewKonnectorProxy() *KonnectorProxy {
+	return &KonnectorProxy{
+		transport: egress.Wrap(&http.Transport{
+			DialContext:           safehttp.DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}),
+		sessions: make(map[string]*proxySession),
+	}
+}
+
+// KonnectorProxyURL returns the URL of the proxy to use for the given job,
+// with the credentials, or an empty string if the proxy is disabled.
+func KonnectorProxyURL(jobID, token string) string {
+	cfg := config.GetConfig().Konnectors.Proxy
+	if cfg.Listen == "" {
+		return ""
+	}
+	raw := cfg.URL
+	if raw == "" {
+		raw = "http://" + cfg.Listen
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.User = url.UserPassword(jobID, token)
+	return u.String()
+}
+
+func (p *KonnectorProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	jobID, sess, err := p.authenticate(r)
+	if err != nil {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="cozy-konnectors"`)
+		http.Error(w, "Proxy authentication required", http.StatusProxyAuthRequired)
+		return
+	}
+	log := logger.WithDomain(sess.domain).WithNamespace("konnector-proxy").
+		WithField("slug", sess.slug).
+		WithField("job_id", jobID)
+
+	if r.Method != http.MethodConnect && r.URL.Scheme != "http" {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	host := strings.ToLower(r.URL.Hostname())
+	vendor, ok := sess.vendorFor(host)
+	if !ok {
+		log.Warnf("Domain %s is not allowed", host)
+		http.Error(w, "Domain not allowed", http.StatusForbidden)
+		return
+	}
+	if err := checkVendorRateLimit(vendor); err != nil {
+		if errors.Is(err, limits.ErrRateLimitReached) {
+			log.Warnf("Rate limit reached for %s", vendor)
+		}
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	start := time.Now()
+	var status int
+	var sent, received int64
+	if r.Method == http.MethodConnect {
+		status, sent, received = p.tunnel(w, r, sess)
+	} else {
+		status, sent, received = p.forward(w, r, sess)
+	}
+	if sess.record {
+		log.WithFields(logger.Fields{
+			"method":      r.Method,
+			"url":         redactURL(r.URL),
+			"status":      status,
+			"duration_ms": time.Since(start).Milliseconds(),
+			"bytes_sent":  sent,
+			"bytes_recv":  received,
+		}).Info("konnector request")
+	}
+}
+
+// forward sends a plain HTTP request to the vendor, and copies its response.
+func (p *KonnectorProxy) forward(w http.ResponseWriter, r *http.Request, sess *proxySession) (int, int64, int64) {
+	out := r.Clone(egress.WithContextName(r.Context(), sess.context))
+	out.RequestURI = ""
+	removeHopHeaders(out.Header)
+	res, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, "Bad gateway", http.StatusBadGateway)
+		return http.StatusBadGateway, 0, 0
+	}
+	defer res.Body.Close()
+
+	removeHopHeaders(res.Header)
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	received, _ := io.Copy(w, res.Body)
+	sent := r.ContentLength
+	if sent < 0 {
+		sent = 0
+	}
+	return res.StatusCode, sent, received
+}
+
+// tunnel is used for the CONNECT requests (HTTPS): the proxy only sees the
+// domain, and copies the bytes in both directions. The tunnel goes through the
+// egress proxy of the context if there is one.
+func (p *KonnectorProxy) tunnel(w http.ResponseWriter, r *http.Request, sess *proxySession) (int, int64, int64) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Tunneling not supported", http.StatusInternalServerError)
+		return http.StatusInternalServerError, 0, 0
+	}
+	upstream, err := egress.DialContext(r.Context(), sess.context, r.URL.Host, safehttp.DialContext)
+	if err != nil {
+		http.Error(w, "Bad gateway", http.StatusBadGateway)
+		return http.StatusBadGateway, 0, 0
+	}
+	defer upstream.Close()
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return http.StatusInternalServerError, 0, 0
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return http.StatusOK, 0, 0
+	}
+
+	var sent int64
+	done := make(chan struct{})
+	go func() {
+		sent, _ = io.Copy(upstream, buf)
+		if tcp, ok := upstream.(*net.TCPConn); ok {
+			_ = tcp.CloseWrite()
+		}
+		close(done)
+	}()
+	received, _ := io.Copy(conn, upstream)
+	_ = conn.Close()
+	<-done
+	return http.StatusOK, sent, received
+}
+
+// authenticate checks the credentials given by the konnector, and returns the
+// job ID and the session for the konnector execution.
+func (p *KonnectorProxy) authenticate(r *http.Request) (string, *proxySession, error) {
+	auth := r.Header.Get("Proxy-Authorization")
+	const prefix = "Basic "
+	if !strings.HasPrefix(auth, prefix) {
+		return "", nil, errProxyAuth
+	}
+	raw, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", nil, errProxyAuth
+	}
+	jobID, token, ok := strings.Cut(string(raw), ":")
+	if !ok || token == "" {
+		return "", nil, errProxyAuth
+	}
+
+	p.mu.Lock()
+	sess, ok := p.sessions[token]
+	p.mu.Unlock()
+	if ok && time.Now().Before(sess.expiresAt) {
+		return jobID, sess, nil
+	}
+
+	sess, err = newProxySession(token)
+	if err != nil {
+		return "", nil, err
+	}
+	p.mu.Lock()
+	now := time.Now()
+	for key, s := range p.sessions {
+		if now.After(s.expiresAt) {
+			delete(p.sessions, key)
+		}
+	}
+	p.sessions[token] = sess
+	p.mu.Unlock()
+	return jobID, sess, nil
+}
+
+func newProxySession(token string) (*proxySession, error) {
+	var claims permission.Claims
+	var inst *instance.Instance
+	err := crypto.ParseJWT(token, func(t *jwt.Token) (interface{}, error) {
+		c := t.Claims.(*permission.Claims)
+		if c.AudienceString() != consts.KonnectorAudience {
+			return nil, permission.ErrInvalidAudience
+		}
+		i, err := lifecycle.GetInstance(c.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		inst = i
+		return i.PickKey(consts.KonnectorAudience)
+	}, &claims)
+	if err != nil || claims.Expired() {
+		return nil, errProxyAuth
+	}
+
+	man, err := app.GetKonnectorBySlug(inst, claims.Subject)
+	if err != nil {
+		return nil, errProxyAuth
+	}
+	record := false
+	if flags, err := feature.GetFlags(inst); err == nil {
+		record, _ = flags.M[recordFlag].(bool)
+	}
+	return &proxySession{
+		domain:    inst.Domain,
+		context:   inst.ContextName,
+		slug:      man.Slug(),
+		allowed:   man.AllowedDomains(),
+		record:    record,
+		expiresAt: time.Now().Add(proxySessionTTL),
+	}, nil
+}
+
+// vendorFor returns the vendor for the given host, which is the allowed
+// domain that matches it. The subdomains of an allowed domain are allowed
+// too. If the konnector has no list of allowed domains, all the domains are
+// allowed, and the vendor is the host.
+func (s *proxySession) vendorFor(host string) (string, bool) {
+	if len(s.allowed) == 0 {
+		return host, host != ""
+	}
+	for _, domain := range s.allowed {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "*."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+// checkVendorRateLimit checks the rate limit for the requests to a vendor.
+// The counter is shared by all the instances (and stack processes if redis
+// is used).
+func checkVendorRateLimit(vendor string) error {
+	cfg := config.GetConfig().Konnectors.Proxy
+	limit, ok := cfg.RateLimits[vendor]
+	if !ok {
+		limit = cfg.DefaultRateLimit
+	}
+	if limit <= 0 {
+		return nil
+	}
+	return config.GetRateLimiter().
+		CheckRateLimitKeyWithLimit(vendor, limits.KonnectorVendorType, int64(limit))
+}
+
+// redactURL returns the URL without the userinfo and the values of the
+// query-string, as they can contain credentials.
+func redactURL(u *url.URL) string {
+	redacted := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path,
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			query[key] = []string{"REDACTED"}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+}
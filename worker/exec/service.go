@@ -205,12 +205,16 @@ func (w *serviceWorker) Logger(ctx *job.WorkerContext) logger.Logger {
 
 func (w *serviceWorker) ScanOutput(ctx *job.WorkerContext, i *instance.Instance, line []byte) error {
 	var msg struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
+		Type    string          `json:"type"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
 	}
 	if err := json.Unmarshal(line, &msg); err != nil {
 		return fmt.Errorf("Could not parse stdout as JSON: %q", string(line))
 	}
+	if msg.Type == konnectorMsgTypeResult {
+		return setResult(ctx, w.Logger(ctx), msg.Result)
+	}
 
 	// Truncate very long messages
 	if len(msg.Message) > 4000 {
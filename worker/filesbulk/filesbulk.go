@@ -0,0 +1,51 @@
+package filesbulk
+
+import (
+	"errors"
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/filesbulk"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   filesbulk.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      1 * time.Hour,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker executes a batch of operations on the files. The progress is
+// published as realtime events, and the report is attached to the job as its
+// result.
+func Worker(ctx *job.WorkerContext) error {
+	var msg filesbulk.Message
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if err := msg.Check(); err != nil {
+		return err
+	}
+	report := filesbulk.Execute(ctx.Instance, &msg, func(done, total int) {
+		ctx.PublishProgress(map[string]interface{}{
+			"done":  done,
+			"total": total,
+		})
+	})
+	ctx.Logger().Infof("Bulk operations on files: %d done, %d failed",
+		report.Done, report.Failed)
+	err := ctx.SetResult(report)
+	if errors.Is(err, job.ErrResultTooLarge) {
+		// The copies and errors are the only parts of the report that can be
+		// large
+		report.Copies = nil
+		report.Errors = nil
+		err = ctx.SetResult(report)
+	}
+	return err
+}
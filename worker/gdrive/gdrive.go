@@ -0,0 +1,36 @@
+package gdrive
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/gdrive"
+	"github.com/cozy/cozy-stack/model/job"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   gdrive.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      1 * time.Hour,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker is the worker that executes a synchronization between a directory
+// of the Cozy and a folder of Google Drive.
+func Worker(ctx *job.WorkerContext) error {
+	var msg struct {
+		SyncID string `json:"sync_id"`
+	}
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if err := gdrive.Run(ctx.Instance, msg.SyncID); err != nil {
+		ctx.Logger().Warnf("Synchronization %s has failed: %s", msg.SyncID, err)
+		return err
+	}
+	return nil
+}
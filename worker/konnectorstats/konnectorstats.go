@@ -0,0 +1,26 @@
+package konnectorstats
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/konnectorstats"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   konnectorstats.ReportWorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerReport,
+	})
+}
+
+// WorkerReport sends the anonymized statistics of the konnectors to the
+// registry.
+func WorkerReport(ctx *job.WorkerContext) error {
+	return konnectorstats.SendReports(ctx.Instance)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/mailqueue"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/mail"
 	"github.com/cozy/cozy-stack/pkg/utils"
@@ -22,6 +23,14 @@ func init() {
 		Concurrency: runtime.NumCPU(),
 		WorkerFunc:  SendMail,
 	})
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   mailqueue.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      60 * time.Second,
+		WorkerFunc:   RetryMail,
+	})
 	initMailTemplates()
 }
 
@@ -53,27 +62,14 @@ func SendMail(ctx *job.WorkerContext) error {
 			replyTo = reply
 		}
 	}
-	ctxName := ctx.Instance.ContextName
-	cfgPerContext := config.GetConfig().MailPerContext
-	if ctxConfig, ok := cfgPerContext[ctxName].(map[string]interface{}); ok {
-		if host, ok := ctxConfig["host"].(string); ok && host != "" {
-			port, _ := ctxConfig["port"].(int)
-			username, _ := ctxConfig["username"].(string)
-			password, _ := ctxConfig["username"].(string)
-			UseSSL, _ := ctxConfig["use_ssl"].(bool)
-			disableTLS, _ := ctxConfig["disable_tls"].(bool)
-			skipCertValid, _ := ctxConfig["skip_certificate_validation"].(bool)
-			LocalName, _ := ctxConfig["local_name"].(string)
-			opts.Dialer = &gomail.DialerOptions{
-				Host:                      host,
-				Port:                      port,
-				Username:                  username,
-				Password:                  password,
-				NativeTLS:                 UseSSL,
-				DisableTLS:                disableTLS,
-				SkipCertificateValidation: skipCertValid,
-				LocalName:                 LocalName,
-			}
+	// The mails sent with the SMTP server of the user come from their domain
+	if smtp, err := mailqueue.GetSMTPSettings(ctx.Instance); err == nil && smtp != nil {
+		if smtp.FromAddress != "" {
+			from = smtp.FromAddress
+			replyTo = ""
+		}
+		if smtp.FromName != "" {
+			name = smtp.FromName
 		}
 	}
 	switch opts.Mode {
@@ -133,6 +129,18 @@ func SendMail(ctx *job.WorkerContext) error {
 	return err
 }
 
+// RetryMail is the mailqueue worker function. It retries the delivery of a
+// mail from the queue.
+func RetryMail(ctx *job.WorkerContext) error {
+	var msg struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	return mailqueue.Process(ctx, ctx.Instance, msg.MessageID)
+}
+
 func pendingAddress(i *instance.Instance) (*mail.Address, error) {
 	doc, err := i.SettingsDocument()
 	if err != nil {
@@ -176,11 +184,7 @@ func doSendMail(ctx *job.WorkerContext, opts *mail.Options, domain string) error
 		return errors.New("Missing mail sender")
 	}
 	email := gomail.NewMessage()
-	dialerOptions := opts.Dialer
-	if dialerOptions == nil {
-		dialerOptions = config.GetConfig().Mail
-	}
-	if dialerOptions.Host == "-" {
+	if dialerOptions(ctx, opts).Host == "-" {
 		return nil
 	}
 	var date time.Time
@@ -247,11 +251,7 @@ func doSendMail(ctx *job.WorkerContext, opts *mail.Options, domain string) error
 		}))
 	}
 
-	dialer := gomail.NewDialer(dialerOptions)
-	if deadline, ok := ctx.Deadline(); ok {
-		dialer.SetDeadline(deadline)
-	}
-	return dialer.DialAndSend(email)
+	return deliver(ctx, opts, email)
 }
 
 func addPart(mail *gomail.Message, part *mail.Part) error {
@@ -265,11 +265,7 @@ func addPart(mail *gomail.Message, part *mail.Part) error {
 
 func sendSupportMail(ctx *job.WorkerContext, opts *mail.Options, domain string) error {
 	email := gomail.NewMessage()
-	dialerOptions := opts.Dialer
-	if dialerOptions == nil {
-		dialerOptions = config.GetConfig().Mail
-	}
-	if dialerOptions.Host == "-" {
+	if dialerOptions(ctx, opts).Host == "-" {
 		return nil
 	}
 	var date time.Time
@@ -296,9 +292,50 @@ func sendSupportMail(ctx *job.WorkerContext, opts *mail.Options, domain string)
 	body, _ := opts.TemplateValues["Body"].(string)
 	email.AddAlternative("text/plain", intro+body+"\n")
 
-	dialer := gomail.NewDialer(dialerOptions)
-	if deadline, ok := ctx.Deadline(); ok {
-		dialer.SetDeadline(deadline)
+	return deliver(ctx, opts, email)
+}
+
+// dialerOptions returns the options for the SMTP server: the dialer from the
+// options of the job if any, or else the server configured for the instance
+// or its context.
+func dialerOptions(ctx *job.WorkerContext, opts *mail.Options) *gomail.DialerOptions {
+	if opts.Dialer != nil {
+		return opts.Dialer
+	}
+	if ctx.Instance == nil {
+		return config.GetConfig().Mail
 	}
-	return dialer.DialAndSend(email)
+	return mailqueue.InstanceDialerOptions(ctx.Instance)
+}
+
+// deliver puts the mail in the queue, and makes a first attempt to send it.
+// If this attempt fails with a temporary error, the mail will be retried
+// later by the mailqueue worker. A dialer given in the options of the job is
+// used directly, without the queue, as its credentials must not be persisted.
+func deliver(ctx *job.WorkerContext, opts *mail.Options, email *gomail.Message) error {
+	if opts.Dialer != nil || ctx.Instance == nil {
+		var contextName string
+		if ctx.Instance != nil {
+			contextName = ctx.Instance.ContextName
+		}
+		dialer := mailqueue.NewDialer(contextName, dialerOptions(ctx, opts))
+		if deadline, ok := ctx.Deadline(); ok {
+			dialer.SetDeadline(deadline)
+		}
+		return dialer.DialAndSend(email)
+	}
+
+	msg, err := mailqueue.Enqueue(ctx.Instance, ctx.JobID(), email)
+	if err != nil {
+		ctx.SetNoRetry()
+		return err
+	}
+	if err = mailqueue.Deliver(ctx, ctx.Instance, msg); err != nil {
+		if msg.State == mailqueue.StateDead {
+			ctx.SetNoRetry()
+			return err
+		}
+		ctx.Logger().Infof("Mail %s will be retried: %s", msg.ID(), err)
+	}
+	return nil
 }
@@ -2,19 +2,28 @@ package mails
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"io"
+	"sort"
 	text "text/template"
+	"time"
 
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/pkg/assets"
+	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/i18n"
 	"github.com/cozy/cozy-stack/pkg/mail"
 )
 
 const templateTitleVar = "template_title"
 
+// mjmlCacheTTL is the duration for which the HTML compiled from a MJML
+// document is kept in cache.
+const mjmlCacheTTL = 24 * time.Hour
+
 func initMailTemplates() {
 	mailTemplater = MailTemplater{
 		"passphrase_hint":              subjectEntry{"Mail Hint Subject", nil},
@@ -38,8 +47,12 @@ func initMailTemplates() {
 		"sharing_to_confirm":           subjectEntry{"Mail Sharing Member To Confirm Subject", nil},
 		"notifications_sharing":        subjectEntry{"Notification Sharing Subject", nil},
 		"notifications_diskquota":      subjectEntry{"Notifications Disk Quota Subject", nil},
+		"notifications_diskforecast":   subjectEntry{"Notifications Disk Forecast Subject", nil},
 		"notifications_oauthclients":   subjectEntry{"Notifications OAuth Clients Subject", nil},
+		"stale_clients":                subjectEntry{"Mail Stale Clients Subject", nil},
+		"monthly_report":               subjectEntry{"Mail Monthly Report Subject", []string{"Month"}},
 		"update_email":                 subjectEntry{"Mail Update Email Subject", nil},
+		"update_email_old":             subjectEntry{"Mail Update Email Old Subject", nil},
 	}
 }
 
@@ -49,6 +62,16 @@ func RenderMail(ctx *job.WorkerContext, name, layout, locale, recipientName stri
 	return mailTemplater.Execute(ctx, name, layout, locale, recipientName, templateValues)
 }
 
+// TemplateNames returns the sorted list of the names of the mail templates.
+func TemplateNames() []string {
+	names := make([]string, 0, len(mailTemplater))
+	for name := range mailTemplater {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // MailTemplater is the list of templates for emails.
 type MailTemplater map[string]subjectEntry
 
@@ -90,7 +113,13 @@ func (m MailTemplater) Execute(ctx *job.WorkerContext, name, layout, locale stri
 		data["InstanceURL"] = ctx.Instance.PageURL("/", nil)
 	}
 
+	// The templates can be overridden by the context, via the dynamic assets.
+	// If an overridden template is broken, we fallback on the default one.
 	txt, err := buildText(name, context, locale, data)
+	if err != nil && context != config.DefaultInstanceContext {
+		ctx.Logger().Warnf("Cannot use the text template %q of context %q: %s", name, context, err)
+		txt, err = buildText(name, config.DefaultInstanceContext, locale, data)
+	}
 	if err != nil {
 		return "", nil, err
 	}
@@ -100,7 +129,12 @@ func (m MailTemplater) Execute(ctx *job.WorkerContext, name, layout, locale stri
 
 	// If we can generate the HTML, we should still send the mail with the text
 	// part.
-	if html, err := buildHTML(name, layout, ctx, context, locale, data); err == nil {
+	html, err := buildHTML(name, layout, ctx, context, locale, data)
+	if err != nil && context != config.DefaultInstanceContext {
+		ctx.Logger().Warnf("Cannot use the MJML template %q of context %q: %s", name, context, err)
+		html, err = buildHTML(name, layout, ctx, config.DefaultInstanceContext, locale, data)
+	}
+	if err == nil {
 		parts = append(parts, &mail.Part{Body: html, Type: "text/html"})
 	} else {
 		ctx.Logger().Errorf("Cannot generate HTML mail: %s", err)
@@ -150,13 +184,33 @@ func buildHTML(name string, layout string, ctx *job.WorkerContext, context, loca
 	if err := t.Execute(buf, data); err != nil {
 		return "", err
 	}
-	html, err := execMjml(ctx, buf.Bytes())
+	html, err := compileMjml(ctx, buf.Bytes())
 	if err != nil {
 		return "", err
 	}
 	return string(html), nil
 }
 
+// compileMjml transforms a MJML document to HTML. As it requires to spawn a
+// node process, the result is cached, with the hash of the MJML document as
+// the key.
+func compileMjml(ctx *job.WorkerContext, doc []byte) ([]byte, error) {
+	sum := sha256.Sum256(doc)
+	key := "mails:mjml:" + hex.EncodeToString(sum[:])
+	cache := config.GetConfig().CacheStorage
+	if r, ok := cache.GetCompressed(key); ok {
+		if html, err := io.ReadAll(r); err == nil && len(html) > 0 {
+			return html, nil
+		}
+	}
+	html, err := execMjml(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetCompressed(key, html, mjmlCacheTTL)
+	return html, nil
+}
+
 func loadTemplate(name, context string) ([]byte, error) {
 	f, err := assets.Open(name, context)
 	if err != nil {
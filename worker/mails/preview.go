@@ -0,0 +1,134 @@
+package mails
+
+import (
+	"fmt"
+
+	"github.com/cozy/cozy-stack/model/instance"
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/pkg/mail"
+)
+
+// sampleData is the data used to preview the mail templates. The instance
+// URL and the locale are added when rendering.
+var sampleData = map[string]map[string]interface{}{
+	"passphrase_hint": {
+		"PublicName": "Jean Dupont",
+		"Hint":       "The name of my first cat",
+		"CozyPass":   false,
+	},
+	"passphrase_reset": {
+		"PublicName":          "Jean Dupont",
+		"PassphraseResetLink": "https://jean.cozy.example/auth/passphrase_renew?token=sample",
+		"CozyPass":            false,
+	},
+	"archiver": {
+		"PublicName":  "Jean Dupont",
+		"ArchiveLink": "https://jean-settings.cozy.example/#/exports/sample",
+	},
+	"import_success": {
+		"PublicName":       "Jean Dupont",
+		"CozyLink":         "https://jean-home.cozy.example/",
+		"AppsNotInstalled": "Banks, Notes",
+	},
+	"import_error":       {},
+	"export_error":       {},
+	"move_error":         {},
+	"magic_link":         {"PublicName": "Jean Dupont", "MagicLink": "https://jean.cozy.example/auth/magic_link?code=sample"},
+	"two_factor":         {"TwoFactorPasscode": "123456"},
+	"support_request":    {"Name": "Jean Dupont", "Body": "I cannot find my photos."},
+	"new_registration":   {"DevicesLink": "https://jean-settings.cozy.example/#/connectedDevices"},
+	"confirm_flagship":   {"PublicName": "Jean Dupont", "Code": "123456"},
+	"alert_account":      {"Domain": "jean.cozy.example", "Error": "The account has been blocked"},
+	"update_email":       {"PublicName": "Jean Dupont", "EmailUpdateLink": "https://jean.cozy.example/settings/email/confirm?token=sample"},
+	"update_email_old":   {"PublicName": "Jean Dupont", "NewEmail": "jean@example.org", "EmailUpdateLink": "https://jean-settings.cozy.example/#/profile"},
+	"move_success":       {"PublicName": "Jean Dupont", "CozyLink": "https://jean-home.cozy.example/", "AppsNotInstalled": ""},
+	"sharing_to_confirm": {"PublicName": "Jean Dupont", "MemberName": "Alice Martin", "Link": "https://jean-drive.cozy.example/#/folder/sample"},
+	"two_factor_mail_confirmation": {
+		"TwoFactorActivationPasscode": "123456",
+	},
+	"move_confirm": {
+		"PublicName":  "Jean Dupont",
+		"Source":      "jean.old-hoster.example",
+		"Target":      "jean.cozy.example",
+		"ConfirmLink": "https://jean.cozy.example/move/authorize?token=sample",
+	},
+	"new_connection": {
+		"Browser":              "Firefox",
+		"OS":                   "Linux",
+		"IP":                   "203.0.113.42",
+		"Country":              "France",
+		"Time":                 "Monday 2 January 2023 15:04",
+		"ChangePassphraseLink": "https://jean-settings.cozy.example/#/profile/password",
+		"ActivateTwoFALink":    "https://jean-settings.cozy.example/#/profile",
+	},
+	"sharing_request": {
+		"SharerPublicName": "Alice Martin",
+		"SharerEmail":      "alice@example.org",
+		"SharerAvatarURL":  "",
+		"Action":           "share",
+		"DocType":          "folder",
+		"Description":      "Holidays",
+		"SharingLink":      "https://jean.cozy.example/sharings/sample/discovery",
+	},
+	"notifications_sharing": {
+		"SharerPublicName": "Alice Martin",
+		"Action":           "share",
+		"TargetType":       "folder",
+		"TargetName":       "Holidays",
+		"SharingLink":      "https://jean-drive.cozy.example/#/folder/sample",
+	},
+	"notifications_diskquota": {
+		"CozyDriveLink": "https://jean-drive.cozy.example/",
+		"OffersLink":    "https://jean-settings.cozy.example/#/storage",
+	},
+	"notifications_diskforecast": {
+		"DaysUntilFull": 42,
+		"CozyDriveLink": "https://jean-drive.cozy.example/",
+		"OffersLink":    "https://jean-settings.cozy.example/#/storage",
+	},
+	"stale_clients": {
+		"Clients":      []string{"Cozy Drive (Desktop)", "Cozy Pass (Android)"},
+		"DeletionDate": "the Jan 2 2023 at 15h04",
+		"DevicesLink":  "https://jean-settings.cozy.example/#/connectedDevices",
+	},
+	"monthly_report": {
+		"Month":            "September 2026",
+		"Files":            []string{"Orange: 3", "EDF: 12"},
+		"StorageUsed":      "4.2 GB",
+		"StorageQuota":     "5.0 GB",
+		"StorageDelta":     "+120 MB",
+		"DevicesConnected": []string{"Cozy Drive (Desktop)"},
+		"DevicesRevoked":   []string{"Cozy Pass (Android)"},
+		"SharingsAccepted": []string{"Holidays (Alice Martin)"},
+		"SettingsLink":     "https://jean-settings.cozy.example/",
+	},
+	"notifications_oauthclients": {
+		"ClientName":   "Cozy Drive (Desktop)",
+		"ClientsLimit": "2",
+		"DevicesLink":  "https://jean-settings.cozy.example/#/connectedDevices",
+		"OffersLink":   "https://jean-settings.cozy.example/#/storage",
+	},
+}
+
+// PreviewMail renders a mail template with sample data, for the context of
+// the given instance. It allows to check the templates overridden by a
+// context before they are used for real mails.
+func PreviewMail(inst *instance.Instance, name, layout, locale string) (string, []*mail.Part, error) {
+	sample, ok := sampleData[name]
+	if !ok {
+		return "", nil, fmt.Errorf("Could not find email named %q", name)
+	}
+	if layout == "" {
+		layout = mail.DefaultLayout
+	}
+	if locale == "" {
+		locale = inst.Locale
+	}
+	data := make(map[string]interface{}, len(sample))
+	for k, v := range sample {
+		data[k] = v
+	}
+	j := &job.Job{JobID: "preview", Domain: inst.Domain}
+	ctx := job.NewWorkerContext("preview", j, inst)
+	return RenderMail(ctx, name, layout, locale, "Jean Dupont", data)
+}
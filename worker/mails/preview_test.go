@@ -0,0 +1,16 @@
+package mails
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleData(t *testing.T) {
+	for _, name := range TemplateNames() {
+		assert.Contains(t, sampleData, name, "no sample data for the mail template %q", name)
+	}
+	for name := range sampleData {
+		assert.Contains(t, mailTemplater, name, "sample data for an unknown mail template %q", name)
+	}
+}
@@ -18,14 +18,27 @@ func init() {
 		Timeout:      30 * time.Second,
 		WorkerFunc:   WorkerClean,
 	})
+
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   oauth.CleanStaleClientsWorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerCleanStale,
+	})
 }
 
-// WorkerClean is used to clean unused OAuth clients.
+// WorkerClean is used to clean unused OAuth clients, and the device codes
+// that have expired.
 func WorkerClean(ctx *job.WorkerContext) error {
 	var msg oauth.CleanMessage
 	if err := ctx.UnmarshalMessage(&msg); err != nil {
 		return err
 	}
+	if msg.DeviceCode != "" {
+		return oauth.CleanDeviceCode(ctx.Instance, msg.DeviceCode)
+	}
 	client, err := oauth.FindClient(ctx.Instance, msg.ClientID)
 	if err != nil {
 		if couchdb.IsNotFoundError(err) {
@@ -38,3 +51,17 @@ func WorkerClean(ctx *job.WorkerContext) error {
 	}
 	return nil
 }
+
+// WorkerCleanStale is used to warn the user about the OAuth clients that have
+// not been used for a long time, and to delete them after a notice delay.
+func WorkerCleanStale(ctx *job.WorkerContext) error {
+	report, err := oauth.CleanStaleClients(ctx.Instance)
+	if err != nil {
+		return err
+	}
+	if len(report.Notified) > 0 || len(report.Deleted) > 0 {
+		ctx.Logger().Infof("Stale clients: %d notified, %d deleted",
+			len(report.Notified), len(report.Deleted))
+	}
+	return nil
+}
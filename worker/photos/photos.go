@@ -0,0 +1,61 @@
+package photos
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/photo"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   photo.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      6 * time.Hour,
+		WorkerFunc:   Worker,
+	})
+
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   photo.GeoWorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      12 * time.Hour,
+		WorkerFunc:   GeoWorker,
+	})
+}
+
+// Worker is the worker that computes the perceptual hashes of the photos, and
+// groups the near-duplicates and the bursts in suggestions.
+func Worker(ctx *job.WorkerContext) error {
+	var msg photo.Message
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if msg.Rescan {
+		return photo.Rescan(ctx.Instance)
+	}
+	if msg.FileID == "" {
+		return nil
+	}
+	return photo.Process(ctx.Instance, msg.FileID)
+}
+
+// GeoWorker is the worker that enriches the photos with the names of the
+// places where they have been taken, and computes the geo-clusters.
+func GeoWorker(ctx *job.WorkerContext) error {
+	var msg photo.GeoMessage
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if msg.Rebuild {
+		return photo.RebuildClusters(ctx.Instance)
+	}
+	if msg.FileID == "" {
+		return nil
+	}
+	return photo.Locate(ctx.Instance, msg.FileID)
+}
@@ -0,0 +1,40 @@
+package qualification
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/qualification"
+	"github.com/cozy/cozy-stack/model/vfs"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   qualification.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      30 * time.Second,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker is the worker that qualifies automatically the new files, with the
+// rules from the context of the instance.
+func Worker(ctx *job.WorkerContext) error {
+	var event struct {
+		Doc vfs.FileDoc `json:"doc"`
+	}
+	if err := ctx.UnmarshalEvent(&event); err != nil {
+		return err
+	}
+	qualified, err := qualification.Qualify(ctx.Instance, event.Doc.ID())
+	if err != nil {
+		return err
+	}
+	if qualified {
+		ctx.Logger().Debugf("File %s has been qualified", event.Doc.ID())
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package reminder
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/reminder"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   reminder.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      30 * time.Second,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker is the reminder worker function. It delivers a reminder via the
+// notification center, and schedules its next occurrence.
+func Worker(ctx *job.WorkerContext) error {
+	var msg reminder.Message
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	return reminder.Deliver(ctx.Instance, &msg)
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/report"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   report.MonthlyWorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerMonthly,
+	})
+}
+
+// WorkerMonthly sends the report of the previous month to the user, if they
+// have opted in. No mail is sent if nothing has happened during the month.
+func WorkerMonthly(ctx *job.WorkerContext) error {
+	inst := ctx.Instance
+	if !report.MonthlyEnabled(inst) {
+		return nil
+	}
+	month := report.MonthOf(time.Now()).AddDate(0, -1, 0)
+	monthly, err := report.BuildMonthly(inst, month)
+	if err != nil {
+		return err
+	}
+	if monthly.Empty() {
+		ctx.Logger().Debugf("Nothing to report for %s", month.Format("2006-01"))
+		return nil
+	}
+	return report.SendMonthly(inst, monthly)
+}
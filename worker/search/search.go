@@ -0,0 +1,70 @@
+package search
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/search"
+	"github.com/cozy/cozy-stack/model/vfs"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   search.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Message is the message for the search-index worker, to rebuild the whole
+// index of an instance.
+type Message struct {
+	Reindex bool `json:"reindex"`
+}
+
+type fileEvent struct {
+	Verb   string       `json:"verb"`
+	Doc    vfs.FileDoc  `json:"doc"`
+	OldDoc *vfs.FileDoc `json:"old,omitempty"`
+}
+
+// Worker updates the full-text index of an instance when a file is created,
+// modified or deleted, or rebuilds the whole index.
+func Worker(ctx *job.WorkerContext) error {
+	if !search.Enabled() {
+		return nil
+	}
+	var msg Message
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if msg.Reindex {
+		ctx.Logger().Infof("Rebuilding the search index")
+		return search.Reindex(ctx.Instance)
+	}
+
+	var evt fileEvent
+	if err := ctx.UnmarshalEvent(&evt); err != nil {
+		return err
+	}
+	if evt.Verb == "DELETED" {
+		return search.RemoveFile(ctx.Instance, evt.Doc.ID())
+	}
+	if evt.OldDoc != nil && sameContent(&evt.Doc, evt.OldDoc) {
+		return nil
+	}
+	return search.IndexFile(ctx.Instance, &evt.Doc)
+}
+
+// sameContent returns true if the changes of a file don't have an effect on
+// the index, like adding a tag.
+func sameContent(doc, old *vfs.FileDoc) bool {
+	return doc.DocName == old.DocName &&
+		doc.Trashed == old.Trashed &&
+		doc.ByteSize == old.ByteSize &&
+		string(doc.MD5Sum) == string(old.MD5Sum)
+}
@@ -41,6 +41,15 @@ func init() {
 		Timeout:      1 * time.Hour,
 		WorkerFunc:   WorkerUpload,
 	})
+
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "escrow",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      5 * time.Minute,
+		WorkerFunc:   WorkerEscrow,
+	})
 }
 
 // WorkerTrack is used to update the io.cozy.shared database when a document
@@ -95,3 +104,9 @@ func WorkerUpload(ctx *job.WorkerContext) error {
 	}
 	return s.Upload(ctx.Instance, msg.Errors)
 }
+
+// WorkerEscrow is used to transfer the shared folders of an instance to the
+// escrow owner of its context, before the instance is destroyed.
+func WorkerEscrow(ctx *job.WorkerContext) error {
+	return sharing.TransferToEscrow(ctx, ctx.Instance)
+}
@@ -0,0 +1,29 @@
+package standby
+
+import (
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/standby"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   standby.WorkerType,
+		Concurrency:  4,
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      2 * time.Hour,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker is the worker that pushes the changes of an instance to the
+// secondary stack of the warm standby replication.
+func Worker(ctx *job.WorkerContext) error {
+	if err := standby.Replicate(ctx.Instance); err != nil {
+		ctx.Logger().Warnf("Standby replication has failed: %s", err)
+		return err
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/cozy/cozy-stack/model/instance"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/note"
+	"github.com/cozy/cozy-stack/model/photo"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/config/config"
 	"github.com/cozy/cozy-stack/pkg/consts"
@@ -49,7 +50,7 @@ func init() {
 		Concurrency:  runtime.NumCPU(),
 		MaxExecCount: 2,
 		Reserved:     true,
-		Timeout:      30 * time.Second,
+		Timeout:      2 * time.Minute,
 		WorkerFunc:   Worker,
 	})
 
@@ -63,7 +64,7 @@ func init() {
 	})
 }
 
-// Worker is a worker that creates thumbnails for photos and images.
+// Worker is a worker that creates thumbnails for photos, images and videos.
 func Worker(ctx *job.WorkerContext) error {
 	var msg ImageMessage
 	if err := ctx.UnmarshalMessage(&msg); err != nil {
@@ -92,6 +93,9 @@ func Worker(ctx *job.WorkerContext) error {
 		return err
 	}
 	if img.Verb != "DELETED" && img.Doc.Trashed {
+		if img.OldDoc != nil && !img.OldDoc.Trashed {
+			pushPhotoJob(ctx, &img.Doc)
+		}
 		return nil
 	}
 	if img.OldDoc != nil && sameImg(&img.Doc, img.OldDoc) {
@@ -107,18 +111,42 @@ func Worker(ctx *job.WorkerContext) error {
 
 	switch img.Verb {
 	case "CREATED":
-		return generateThumbnails(ctx, &img.Doc)
+		err := generateThumbnails(ctx, &img.Doc)
+		pushPhotoJob(ctx, &img.Doc)
+		return err
 	case "UPDATED":
 		if err := removeThumbnails(ctx.Instance, &img.Doc); err != nil {
 			log.Debugf("failed to remove thumbnails for %s: %s", img.Doc.ID(), err)
 		}
-		return generateThumbnails(ctx, &img.Doc)
+		err := generateThumbnails(ctx, &img.Doc)
+		pushPhotoJob(ctx, &img.Doc)
+		return err
 	case "DELETED":
+		pushPhotoJob(ctx, &img.Doc)
 		return removeThumbnails(ctx.Instance, &img.Doc)
 	}
 	return fmt.Errorf("unknown type %s for event", img.Verb)
 }
 
+// pushPhotoJob pushes a job to look for the duplicates and the bursts with
+// the given photo, and another one for the geo-clusters if the photo has GPS
+// coordinates.
+func pushPhotoJob(ctx *job.WorkerContext, doc *vfs.FileDoc) {
+	if doc.Class != "image" {
+		return
+	}
+	msg := &photo.Message{FileID: doc.ID()}
+	if _, err := photo.PushJob(ctx.Instance, msg); err != nil {
+		ctx.Logger().Warnf("Cannot push a job for duplicates of %s: %s", doc.ID(), err)
+	}
+	if photo.HasGPS(doc) {
+		geo := &photo.GeoMessage{FileID: doc.ID()}
+		if _, err := photo.PushGeoJob(ctx.Instance, geo); err != nil {
+			ctx.Logger().Warnf("Cannot push a job for the location of %s: %s", doc.ID(), err)
+		}
+	}
+}
+
 func sameImg(doc, old *vfs.FileDoc) bool {
 	// XXX It is needed for a file that has just been uploaded. The first
 	// revision will have the size and md5sum, but is marked as trashed,
@@ -137,8 +165,8 @@ type thumbnailMsg struct {
 	WithMetadata bool `json:"with_metadata"`
 }
 
-// WorkerCheck is a worker function that checks all the images to generate
-// missing thumbnails.
+// WorkerCheck is a worker function that checks all the images and videos to
+// generate missing thumbnails.
 func WorkerCheck(ctx *job.WorkerContext) error {
 	var msg thumbnailMsg
 	if err := ctx.UnmarshalMessage(&msg); err != nil {
@@ -151,7 +179,7 @@ func WorkerCheck(ctx *job.WorkerContext) error {
 		if err != nil {
 			return err
 		}
-		if dir != nil || img.Class != "image" {
+		if dir != nil || (img.Class != "image" && img.Class != "video") {
 			return nil
 		}
 		allExists := true
@@ -232,15 +260,9 @@ func generateSingleThumbnail(ctx *job.WorkerContext, img *vfs.FileDoc, format st
 		return nil
 	}
 
-	var in io.Reader
-	in, err = ctx.Instance.VFS().OpenFile(img)
-	if err != nil {
-		return err
-	}
-
 	var env []string
+	var tempDir string
 	{
-		var tempDir string
 		tempDir, err = os.MkdirTemp("", "magick")
 		if err == nil {
 			defer os.RemoveAll(tempDir)
@@ -248,6 +270,15 @@ func generateSingleThumbnail(ctx *job.WorkerContext, img *vfs.FileDoc, format st
 			env = []string{envTempDir}
 		}
 	}
+
+	in, err := openSource(ctx, img, tempDir)
+	if errors.Is(err, errNoVideoFrame) {
+		// No thumbnail for this video, but there is no need to retry the job
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 	_, err = recGenerateThumb(ctx, in, fs, img, format, env, true)
 	return err
 }
@@ -258,15 +289,10 @@ func generateThumbnails(ctx *job.WorkerContext, img *vfs.FileDoc) error {
 	}
 
 	fs := ctx.Instance.ThumbsFS()
-	var in io.Reader
-	in, err := ctx.Instance.VFS().OpenFile(img)
-	if err != nil {
-		return err
-	}
-
 	var env []string
+	var tempDir string
 	{
-		var tempDir string
+		var err error
 		tempDir, err = os.MkdirTemp("", "magick")
 		if err == nil {
 			defer os.RemoveAll(tempDir)
@@ -275,7 +301,16 @@ func generateThumbnails(ctx *job.WorkerContext, img *vfs.FileDoc) error {
 		}
 	}
 
-	if img.Class == "image" {
+	in, err := openSource(ctx, img, tempDir)
+	if errors.Is(err, errNoVideoFrame) {
+		// No thumbnail for this video, but there is no need to retry the job
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if img.Class == "image" || img.Class == "video" {
 		in, err = recGenerateThumb(ctx, in, fs, img, "large", env, false)
 		if err != nil {
 			return err
@@ -303,14 +338,120 @@ func generateThumbnails(ctx *job.WorkerContext, img *vfs.FileDoc) error {
 
 func checkByteSize(img *vfs.FileDoc) bool {
 	// Do not try to generate thumbnails for images that weight more than 100MB
-	// (or 5MB for PSDs)
+	// (or 5MB for PSDs, and 1GB for videos)
 	var limit int64 = 100 * 1024 * 1024
 	if img.Mime == "image/vnd.adobe.photoshop" {
 		limit = 5 * 1024 * 1024
+	} else if img.Class == "video" {
+		limit = 1024 * 1024 * 1024
 	}
 	return img.ByteSize < limit
 }
 
+// openSource returns a reader for the image that will be used to generate the
+// thumbnails: the file itself for an image or a PDF, and a poster frame for a
+// video.
+func openSource(ctx *job.WorkerContext, img *vfs.FileDoc, tempDir string) (io.Reader, error) {
+	if img.Class == "video" {
+		return extractVideoFrame(ctx, img, tempDir)
+	}
+	return ctx.Instance.VFS().OpenFile(img)
+}
+
+// errNoVideoFrame is used when ffmpeg has not been able to extract a frame
+// from a video (ffmpeg is not installed, the codec is not supported, etc.)
+var errNoVideoFrame = errors.New("no frame extracted from the video")
+
+// extractVideoFrame uses ffmpeg to extract a poster frame from a video, in the
+// PNG format.
+func extractVideoFrame(ctx *job.WorkerContext, video *vfs.FileDoc, tempDir string) (io.Reader, error) {
+	f, err := ctx.Instance.VFS().OpenFile(video)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return extractFrame(ctx, f, video.ID(), tempDir)
+}
+
+// extractFrame copies the video to a temporary file, as ffmpeg needs to seek
+// in it (the index of a MP4 file is often written at the end of the file),
+// and runs ffmpeg on it. The copy is stopped if the job is canceled or
+// reaches its timeout.
+func extractFrame(ctx *job.WorkerContext, video io.Reader, fileID, tempDir string) (io.Reader, error) {
+	if tempDir == "" {
+		return nil, errors.New("no temporary directory for the video")
+	}
+	tmp, err := os.CreateTemp(tempDir, "video")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(tmp, &contextReader{ctx: ctx, r: video})
+	if errc := tmp.Close(); errc != nil && err == nil {
+		err = errc
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ffmpegCmd := config.GetConfig().Jobs.FFmpegCmd
+	if ffmpegCmd == "" {
+		ffmpegCmd = "ffmpeg"
+	}
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", tmp.Name(),
+		"-an", // Ignore the audio streams
+		"-sn", // Ignore the subtitles streams
+		// Reduce the size of the frames to limit the memory usage, and then
+		// select a representative frame among the first ones (and not a black
+		// frame from a fade-in)
+		"-vf", "scale=w='min(1920,iw)':h='min(1080,ih)':force_original_aspect_ratio=decrease,thumbnail=25",
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-vcodec", "png",
+		"-", // Send the output on stdout
+	}
+	var stdout, stderr bytes.Buffer
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctxWithTimeout, ffmpegCmd, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// Truncate very long messages
+		msg := stderr.String()
+		if len(msg) > 4000 {
+			msg = msg[:4000]
+		}
+		ctx.Logger().
+			WithField("stderr", msg).
+			WithField("file_id", fileID).
+			Errorf("ffmpeg failed: %s", err)
+		return nil, fmt.Errorf("%w: %s", errNoVideoFrame, err)
+	}
+	if stdout.Len() == 0 {
+		return nil, errNoVideoFrame
+	}
+	return &stdout, nil
+}
+
+// contextReader is a reader that stops reading when its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
 func recGenerateThumb(ctx *job.WorkerContext, in io.Reader, fs vfs.Thumbser, img *vfs.FileDoc, format string, env []string, noOuput bool) (r io.Reader, err error) {
 	defer func() {
 		if inCloser, ok := in.(io.Closer); ok {
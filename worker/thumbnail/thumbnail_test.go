@@ -0,0 +1,148 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"image"
+	_ "image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/vfs"
+	"github.com/cozy/cozy-stack/pkg/config/config"
+	"github.com/cozy/cozy-stack/pkg/consts"
+	"github.com/cozy/cozy-stack/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const videoFixture = "../../tests/fixtures/video.y4m"
+
+func needFFmpeg(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg is required for this test: test skipped")
+	}
+}
+
+func withFFmpegCmd(t *testing.T, cmd string) {
+	t.Helper()
+	conf := config.GetConfig()
+	previous := conf.Jobs.FFmpegCmd
+	conf.Jobs.FFmpegCmd = cmd
+	t.Cleanup(func() { conf.Jobs.FFmpegCmd = previous })
+}
+
+func TestExtractFrame(t *testing.T) {
+	config.UseTestFile(t)
+	j := &job.Job{JobID: "1", Domain: "cozy.example.com"}
+	ctx := job.NewWorkerContext("0", j, nil)
+
+	t.Run("Frame", func(t *testing.T) {
+		needFFmpeg(t)
+		video, err := os.ReadFile(videoFixture)
+		require.NoError(t, err)
+
+		out, err := extractFrame(ctx, bytes.NewReader(video), "video-id", t.TempDir())
+		require.NoError(t, err)
+		conf, format, err := image.DecodeConfig(out)
+		require.NoError(t, err)
+		assert.Equal(t, "png", format)
+		assert.Equal(t, 32, conf.Width)
+		assert.Equal(t, 24, conf.Height)
+	})
+
+	t.Run("NotAVideo", func(t *testing.T) {
+		needFFmpeg(t)
+		in := bytes.NewReader([]byte("this is not a video"))
+		_, err := extractFrame(ctx, in, "video-id", t.TempDir())
+		assert.ErrorIs(t, err, errNoVideoFrame)
+	})
+
+	t.Run("NoFFmpeg", func(t *testing.T) {
+		withFFmpegCmd(t, filepath.Join(t.TempDir(), "no-ffmpeg"))
+		video, err := os.ReadFile(videoFixture)
+		require.NoError(t, err)
+
+		_, err = extractFrame(ctx, bytes.NewReader(video), "video-id", t.TempDir())
+		assert.ErrorIs(t, err, errNoVideoFrame)
+	})
+
+	t.Run("NoTempDir", func(t *testing.T) {
+		_, err := extractFrame(ctx, bytes.NewReader(nil), "video-id", "")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errNoVideoFrame)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		canceled, cancel := ctx.WithTimeout(time.Minute)
+		cancel()
+		video, err := os.ReadFile(videoFixture)
+		require.NoError(t, err)
+
+		_, err = extractFrame(canceled, bytes.NewReader(video), "video-id", t.TempDir())
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestVideoThumbnails(t *testing.T) {
+	if testing.Short() {
+		t.Skip("an instance is required for this test: test skipped due to the use of --short flag")
+	}
+
+	config.UseTestFile(t)
+	testutils.NeedCouchdb(t)
+	setup := testutils.NewSetup(t, t.Name())
+	inst := setup.GetTestInstance()
+	j := &job.Job{JobID: "1", Domain: inst.Domain}
+	ctx := job.NewWorkerContext("0", j, inst)
+
+	createVideo := func(t *testing.T, name string) *vfs.FileDoc {
+		video, err := os.ReadFile(videoFixture)
+		require.NoError(t, err)
+		fs := inst.VFS()
+		doc, err := vfs.NewFileDoc(name, consts.RootDirID, int64(len(video)), nil,
+			"video/x-yuv4mpeg", "video", time.Now(), false, false, false, nil)
+		require.NoError(t, err)
+		f, err := fs.CreateFile(doc, nil)
+		require.NoError(t, err)
+		_, err = f.Write(video)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		doc, err = fs.FileByID(doc.ID())
+		require.NoError(t, err)
+		return doc
+	}
+
+	t.Run("Generated", func(t *testing.T) {
+		needFFmpeg(t)
+		if _, err := exec.LookPath("convert"); err != nil {
+			t.Skip("imagemagick is required for this test: test skipped")
+		}
+		doc := createVideo(t, "generated.y4m")
+		require.NoError(t, generateThumbnails(ctx, doc))
+		for _, format := range vfs.ThumbnailFormatNames {
+			exists, err := inst.ThumbsFS().ThumbExists(doc, format)
+			require.NoError(t, err)
+			assert.True(t, exists, format)
+		}
+	})
+
+	t.Run("FFmpegFailure", func(t *testing.T) {
+		withFFmpegCmd(t, filepath.Join(t.TempDir(), "no-ffmpeg"))
+		doc := createVideo(t, "failure.y4m")
+
+		// The job is not failed (and retried), the video has just no thumbnail
+		require.NoError(t, generateThumbnails(ctx, doc))
+		require.NoError(t, generateSingleThumbnail(ctx, doc, "small"))
+		for _, format := range vfs.ThumbnailFormatNames {
+			exists, err := inst.ThumbsFS().ThumbExists(doc, format)
+			require.NoError(t, err)
+			assert.False(t, exists, format)
+		}
+	})
+}
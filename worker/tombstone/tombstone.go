@@ -0,0 +1,41 @@
+package tombstone
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/cozy/cozy-stack/model/job"
+	"github.com/cozy/cozy-stack/model/tombstone"
+)
+
+func init() {
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   tombstone.WorkerType,
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 1,
+		Reserved:     true,
+		Timeout:      2 * time.Hour,
+		WorkerFunc:   Worker,
+	})
+}
+
+// Worker is used to purge the tombstones of the deleted documents from the
+// CouchDB databases of an instance.
+func Worker(ctx *job.WorkerContext) error {
+	var msg tombstone.Message
+	if err := ctx.UnmarshalMessage(&msg); err != nil {
+		return err
+	}
+	if !msg.DryRun && ctx.Instance.IsUnderLegalHold() {
+		ctx.Logger().Infof("Purge of the tombstones deferred by the legal hold")
+		return nil
+	}
+	reports, err := tombstone.PurgeAll(ctx.Instance, &msg)
+	for _, report := range reports {
+		if report.Purged > 0 || len(report.Failed) > 0 {
+			ctx.Logger().Infof("Tombstones of %s: %d purged, %d kept, %d failed",
+				report.Doctype, report.Purged, report.Kept, len(report.Failed))
+		}
+	}
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/cozy/cozy-stack/model/doctype"
 	"github.com/cozy/cozy-stack/model/job"
 	"github.com/cozy/cozy-stack/model/vfs"
 	"github.com/cozy/cozy-stack/pkg/config/config"
@@ -33,6 +34,15 @@ func init() {
 		Timeout:      2 * time.Hour,
 		WorkerFunc:   WorkerCleanOldTrashed,
 	})
+
+	job.AddWorker(&job.WorkerConfig{
+		WorkerType:   "clean-old-trashed-docs",
+		Concurrency:  runtime.NumCPU(),
+		MaxExecCount: 2,
+		Reserved:     true,
+		Timeout:      1 * time.Hour,
+		WorkerFunc:   WorkerCleanOldTrashedDocs,
+	})
 }
 
 // WorkerTrashFiles is a worker to remove files in Swift after they have been
@@ -69,6 +79,10 @@ func WorkerCleanOldTrashed(ctx *job.WorkerContext) error {
 		return err
 	}
 	before := time.Now().Add(-delay)
+	if ctx.Instance.IsUnderLegalHold() {
+		ctx.Logger().Infof("Purge of the trash deferred by the legal hold")
+		return nil
+	}
 
 	var list []*vfs.DirOrFileDoc
 	sel := mango.And(
@@ -103,6 +117,35 @@ func WorkerCleanOldTrashed(ctx *job.WorkerContext) error {
 	return errm
 }
 
+// WorkerCleanOldTrashedDocs is a worker used to automatically destroy the
+// documents of the data API that are in the trash for too long. The threshold
+// is configurable per context in the config file, via the
+// data_trash.auto_clean_trashed_after parameter.
+func WorkerCleanOldTrashedDocs(ctx *job.WorkerContext) error {
+	delay, ok := doctype.AutoCleanTrashedAfter(ctx.Instance.ContextName)
+	if !ok {
+		return nil
+	}
+	before := time.Now().Add(-delay)
+	if ctx.Instance.IsUnderLegalHold() {
+		ctx.Logger().Infof("Purge of the trashed documents deferred by the legal hold")
+		return nil
+	}
+
+	var errm error
+	for _, typ := range doctype.TrashDoctypes(ctx.Instance.ContextName) {
+		n, err := doctype.PurgeTrashed(ctx.Instance, typ, before)
+		if err != nil {
+			errm = multierror.Append(errm, fmt.Errorf("%s: %w", typ, err))
+			continue
+		}
+		if n > 0 {
+			ctx.Logger().Infof("%d trashed documents destroyed for %s", n, typ)
+		}
+	}
+	return errm
+}
+
 func pushTrashJob(fs vfs.VFS) func(vfs.TrashJournal) error {
 	return func(journal vfs.TrashJournal) error {
 		return fs.EnsureErased(journal)